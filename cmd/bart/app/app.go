@@ -29,6 +29,14 @@ type BartApp struct {
 	multiClass            bool
 	serverTimeoutSeconds  int
 	serverMaxRequestBytes int
+	sessionTTLSeconds     int
+	sessionMaxBytes       int
+	encoderCacheMaxBytes  int
+	ragPassages           string
+	safetyBlocklist       string
+	safetyBlocklistAction string
+	safetyPIIAction       string
+	halfPrecision         string
 }
 
 // NewBartApp returns a new BartApp object, which can be used as either client or server.
@@ -6,12 +6,17 @@ package app
 
 import (
 	"fmt"
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/nlp/retrieval"
+	"github.com/nlpodyssey/spago/pkg/nlp/safety"
 	"github.com/nlpodyssey/spago/pkg/nlp/tokenizers/bpetokenizer"
 	"github.com/nlpodyssey/spago/pkg/nlp/tokenizers/sentencepiece"
 	"github.com/nlpodyssey/spago/pkg/nlp/transformers/bart/head/conditionalgeneration"
 	"github.com/nlpodyssey/spago/pkg/nlp/transformers/bart/head/sequenceclassification"
 	"github.com/nlpodyssey/spago/pkg/nlp/transformers/bart/loader"
 	"github.com/nlpodyssey/spago/pkg/nlp/transformers/bart/server"
+	"github.com/nlpodyssey/spago/pkg/nlp/transformers/generation"
 	"github.com/nlpodyssey/spago/pkg/nlp/transformers/huggingface"
 	"github.com/nlpodyssey/spago/pkg/utils/httputils"
 	"github.com/urfave/cli/v2"
@@ -20,6 +25,7 @@ import (
 	"os/user"
 	"path"
 	"path/filepath"
+	"time"
 )
 
 func newServerCommandFor(app *BartApp) *cli.Command {
@@ -92,6 +98,48 @@ func newServerCommandFlagsFor(app *BartApp) []cli.Flag {
 			Value:       httputils.DefaultMaxRequestBytes,
 			Destination: &app.serverMaxRequestBytes,
 		},
+		&cli.IntFlag{
+			Name:        "session-ttl",
+			Usage:       "Enables the generation sessions, retained for the given number of seconds since their last use.",
+			Destination: &app.sessionTTLSeconds,
+		},
+		&cli.IntFlag{
+			Name:        "session-max-size",
+			Usage:       "Maximum number of bytes retained by the generation sessions (0 means no limit).",
+			Value:       1 << 30,
+			Destination: &app.sessionMaxBytes,
+		},
+		&cli.IntFlag{
+			Name:        "encoder-cache-size",
+			Usage:       "Enables the cache of the encoded inputs of the generation requests, retaining up to the given number of bytes.",
+			Destination: &app.encoderCacheMaxBytes,
+		},
+		&cli.StringFlag{
+			Name:        "rag-passages",
+			Usage:       "Enables the retrieval-augmented generation (/rag endpoint) on the passages of the given file, one per line.",
+			Destination: &app.ragPassages,
+		},
+		&cli.StringFlag{
+			Name:        "safety-blocklist",
+			Usage:       "Filters the generated texts with the words and phrases of the given file, one per line.",
+			Destination: &app.safetyBlocklist,
+		},
+		&cli.StringFlag{
+			Name:        "safety-blocklist-action",
+			Usage:       "Action on the blocklisted words and phrases (\"flag\", \"redact\" or \"reject\").",
+			Value:       "reject",
+			Destination: &app.safetyBlocklistAction,
+		},
+		&cli.StringFlag{
+			Name:        "safety-pii",
+			Usage:       "Filters the personal data of the generated texts with the given action (\"flag\", \"redact\" or \"reject\").",
+			Destination: &app.safetyPIIAction,
+		},
+		&cli.StringFlag{
+			Name:        "half-precision",
+			Usage:       "Stores the model parameters in half precision (\"float16\" or \"bfloat16\") to reduce memory usage.",
+			Destination: &app.halfPrecision,
+		},
 	}
 }
 
@@ -109,6 +157,10 @@ func newServerCommandActionFor(app *BartApp) func(c *cli.Context) error {
 		}
 		defer model.Close()
 
+		if err := convertToHalfPrecision(model, app.halfPrecision); err != nil {
+			return err
+		}
+
 		var bpeTokenizer *bpetokenizer.BPETokenizer
 		var spTokenizer *sentencepiece.Tokenizer
 
@@ -149,6 +201,29 @@ func newServerCommandActionFor(app *BartApp) func(c *cli.Context) error {
 		s := server.NewServer(model, bpeTokenizer, spTokenizer)
 		s.TimeoutSeconds = app.serverTimeoutSeconds
 		s.MaxRequestBytes = app.serverMaxRequestBytes
+		if app.sessionTTLSeconds > 0 {
+			s.Sessions = generation.NewSessionCache(time.Duration(app.sessionTTLSeconds)*time.Second, app.sessionMaxBytes)
+		}
+		if app.encoderCacheMaxBytes > 0 {
+			s.EncoderCache = generation.NewSessionCache(0, app.encoderCacheMaxBytes)
+		}
+		if app.ragPassages != "" {
+			f, err := os.Open(app.ragPassages)
+			if err != nil {
+				return err
+			}
+			passages, err := retrieval.LoadPassages(f)
+			_ = f.Close()
+			if err != nil {
+				return err
+			}
+			s.Retriever = retrieval.NewBM25Index(passages, retrieval.DefaultBM25Config())
+		}
+		guard, err := app.newSafetyGuard()
+		if err != nil {
+			return err
+		}
+		s.Guard = guard
 		s.StartDefaultHTTPServer(app.address, app.tlsCert, app.tlsKey, app.tlsDisable)
 		s.StartDefaultServer(app.grpcAddress, app.tlsCert, app.tlsKey, app.tlsDisable)
 
@@ -193,3 +268,50 @@ func pullModel(app *BartApp) error {
 
 	return nil
 }
+
+// convertToHalfPrecision stores the model parameters in the given half
+// precision format. It does nothing if the format is empty.
+func convertToHalfPrecision(model nn.Model, format string) error {
+	if format == "" {
+		return nil
+	}
+	halfPrecision, err := mat.ParseHalfPrecision(format)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Storing the parameters in %s...\n", halfPrecision)
+	nn.ConvertToHalfPrecision(model, halfPrecision)
+	return nil
+}
+
+// newSafetyGuard returns the Guard of the safety flags, or nil if none is set.
+func (app *BartApp) newSafetyGuard() (*safety.Guard, error) {
+	rules := make([]safety.Rule, 0)
+	if app.safetyBlocklist != "" {
+		action, err := safety.ParseAction(app.safetyBlocklistAction)
+		if err != nil {
+			return nil, err
+		}
+		f, err := os.Open(app.safetyBlocklist)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		blocklist, err := safety.LoadBlocklist("blocklist", f)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, safety.Rule{Filter: blocklist, Action: action})
+	}
+	if app.safetyPIIAction != "" {
+		action, err := safety.ParseAction(app.safetyPIIAction)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, safety.Rule{Filter: safety.NewPatternFilter("pii", safety.PIIPatterns()), Action: action})
+	}
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	return safety.NewGuard(rules...), nil
+}
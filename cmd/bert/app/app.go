@@ -29,6 +29,10 @@ type BertApp struct {
 	question              string
 	serverTimeoutSeconds  int
 	serverMaxRequestBytes int
+	halfPrecision         string
+	postProcessor         string
+	canary                canaryOptions
+	batch                 batchOptions
 }
 
 // NewBertApp returns BertApp objects. The app can be used as both a client and a server.
@@ -42,6 +46,8 @@ func NewBertApp() *BertApp {
 	app.Commands = []*cli.Command{
 		newClientCommandFor(app),
 		newServerCommandFor(app),
+		newCanaryCommandFor(app),
+		newBatchCommandFor(app),
 	}
 	return app
 }
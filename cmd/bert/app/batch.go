@@ -0,0 +1,225 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"os/user"
+	"path"
+	"syscall"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/nlp/transformers/bert"
+	"github.com/nlpodyssey/spago/pkg/nlp/transformers/bert/grpcapi"
+	"github.com/nlpodyssey/spago/pkg/utils/batch"
+	"github.com/urfave/cli/v2"
+)
+
+// batchOptions contains the flags of the batch command.
+type batchOptions struct {
+	pipeline        string
+	input           string
+	output          string
+	format          string
+	checkpoint      string
+	workers         int
+	checkpointEvery int
+	mergeEntities   bool
+	confidence      bool
+}
+
+func newBatchCommandFor(app *BertApp) *cli.Command {
+	return &cli.Command{
+		Name:  "batch",
+		Usage: "Process a corpus with a pipeline of the model, without starting a server.",
+		Description: "Run the " + programName + " as an offline job streaming a corpus (JSON Lines or CSV) through a " +
+			"pipeline: \"classify\" (fields \"text\" and \"text2\"), \"tag\" (field \"text\"), \"answer\" (fields " +
+			"\"question\" and \"passage\") or \"encode\" (field \"text\"). Each record is written to the output " +
+			"(JSON Lines) with the field \"result\", or \"error\" if it failed. The progress is checkpointed, so " +
+			"that an interrupted job resumes when it is run again with the same output.",
+		Flags:  newBatchCommandFlagsFor(app),
+		Action: newBatchCommandActionFor(app),
+	}
+}
+
+func newBatchCommandFlagsFor(app *BertApp) []cli.Flag {
+	usr, err := user.Current()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:        "repo",
+			Usage:       "Specifies the path to the models.",
+			Value:       path.Join(usr.HomeDir, ".spago"),
+			Destination: &app.repo,
+		},
+		&cli.StringFlag{
+			Name:        "model",
+			Required:    true,
+			Usage:       "Specifies the model name.",
+			Destination: &app.model,
+		},
+		&cli.StringFlag{
+			Name:        "pipeline",
+			Required:    true,
+			Usage:       "Specifies the pipeline (\"classify\", \"tag\", \"answer\" or \"encode\").",
+			Destination: &app.batch.pipeline,
+		},
+		&cli.StringFlag{
+			Name:        "input",
+			Aliases:     []string{"i"},
+			Required:    true,
+			Usage:       "Specifies the path of the corpus.",
+			Destination: &app.batch.input,
+		},
+		&cli.StringFlag{
+			Name:        "output",
+			Aliases:     []string{"o"},
+			Required:    true,
+			Usage:       "Specifies the path of the output.",
+			Destination: &app.batch.output,
+		},
+		&cli.StringFlag{
+			Name:        "format",
+			Usage:       "Specifies the format of the corpus (\"jsonl\" or \"csv\"); by default, it depends on its extension.",
+			Destination: &app.batch.format,
+		},
+		&cli.StringFlag{
+			Name:        "checkpoint",
+			Usage:       "Specifies the path of the checkpoint; by default, the output path with the \".checkpoint\" suffix.",
+			Destination: &app.batch.checkpoint,
+		},
+		&cli.IntFlag{
+			Name:        "workers",
+			Usage:       "Number of records processed concurrently; by default, the number of CPUs.",
+			Destination: &app.batch.workers,
+		},
+		&cli.IntFlag{
+			Name:        "checkpoint-every",
+			Value:       1000,
+			Usage:       "Number of records processed between checkpoints.",
+			Destination: &app.batch.checkpointEvery,
+		},
+		&cli.BoolFlag{
+			Name:        "merge-entities",
+			Usage:       "Merges the tokens of the entities (\"tag\" pipeline).",
+			Destination: &app.batch.mergeEntities,
+		},
+		&cli.BoolFlag{
+			Name:        "confidence",
+			Usage:       "Adds the confidence of each token (\"tag\" pipeline).",
+			Destination: &app.batch.confidence,
+		},
+	}
+}
+
+func newBatchCommandActionFor(app *BertApp) func(c *cli.Context) error {
+	return func(c *cli.Context) error {
+		opts := app.batch
+		modelPath, err := prepareModel(app.repo, app.model)
+		if err != nil {
+			return err
+		}
+		model, err := bert.LoadModel(modelPath)
+		if err != nil {
+			return fmt.Errorf("error during model loading (%v)", err)
+		}
+		defer model.Close()
+
+		process, err := batchProcessor(bert.NewServer(model), opts)
+		if err != nil {
+			return err
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+		defer signal.Stop(signals)
+		go func() {
+			if _, ok := <-signals; ok {
+				log.Println("Interrupted: waiting for the records in progress...")
+				cancel()
+			}
+		}()
+
+		stats, err := batch.Run(ctx, batch.Config{
+			Input:           opts.input,
+			Format:          batch.Format(opts.format),
+			Output:          opts.output,
+			Checkpoint:      opts.checkpoint,
+			Workers:         opts.workers,
+			CheckpointEvery: opts.checkpointEvery,
+			Progress: func(stats batch.Stats) {
+				log.Printf("Processed %d records (%d failed)", stats.Skipped+stats.Processed, stats.Failed)
+			},
+		}, process)
+		if err == context.Canceled {
+			return cli.Exit(fmt.Sprintf("batch: interrupted after %d records: run it again to resume",
+				stats.Skipped+stats.Processed), 1)
+		}
+		return err
+	}
+}
+
+// batchProcessor returns the batch.Processor of the pipeline.
+func batchProcessor(server *bert.Server, opts batchOptions) (batch.Processor, error) {
+	switch opts.pipeline {
+	case "classify":
+		return func(ctx context.Context, r batch.Record) (interface{}, error) {
+			reply, err := server.Classify(ctx, &grpcapi.ClassifyRequest{
+				Text:  r.String("text"),
+				Text2: r.String("text2"),
+			})
+			if err != nil {
+				return nil, err
+			}
+			distribution := make([]bert.ClassConfidencePair, len(reply.Distribution))
+			for i, pair := range reply.Distribution {
+				distribution[i] = bert.ClassConfidencePair{Class: pair.Class, Confidence: mat.Float(pair.Confidence)}
+			}
+			return &bert.ClassifyResponse{
+				Class:        reply.Class,
+				Confidence:   mat.Float(reply.Confidence),
+				Distribution: distribution,
+			}, nil
+		}, nil
+	case "tag":
+		options := bert.LabelerOptionsType{
+			MergeEntities: opts.mergeEntities,
+			Confidence:    opts.confidence,
+		}
+		return func(_ context.Context, r batch.Record) (interface{}, error) {
+			return server.Label(r.String("text"), options).Tokens, nil
+		}, nil
+	case "answer":
+		return func(ctx context.Context, r batch.Record) (interface{}, error) {
+			answers, err := server.Answer(ctx, &grpcapi.AnswerRequest{
+				Question: r.String("question"),
+				Passage:  r.String("passage"),
+			})
+			if err != nil {
+				return nil, err
+			}
+			return answers.Answers, nil
+		}, nil
+	case "encode":
+		return func(ctx context.Context, r batch.Record) (interface{}, error) {
+			reply, err := server.Encode(ctx, &grpcapi.EncodeRequest{Text: r.String("text")})
+			if err != nil {
+				return nil, err
+			}
+			return reply.Vector, nil
+		}, nil
+	default:
+		return nil, fmt.Errorf("invalid pipeline %q", opts.pipeline)
+	}
+}
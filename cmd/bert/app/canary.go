@@ -0,0 +1,200 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/user"
+	"path"
+
+	"github.com/nlpodyssey/spago/pkg/ml/canary"
+	"github.com/nlpodyssey/spago/pkg/nlp/transformers/bert"
+	"github.com/nlpodyssey/spago/pkg/nlp/transformers/bert/grpcapi"
+	"github.com/urfave/cli/v2"
+)
+
+// canaryOptions contains the flags of the canary command.
+type canaryOptions struct {
+	baseline        string
+	candidate       string
+	dataset         string
+	format          string
+	maxAccuracyDrop float64
+	maxMacroF1Drop  float64
+	alpha           float64
+	bootstraps      int
+	seed            int64
+}
+
+func newCanaryCommandFor(app *BertApp) *cli.Command {
+	return &cli.Command{
+		Name:  "canary",
+		Usage: "Compare two classification models on a dataset, without starting a server.",
+		Description: "Run the " + programName + " as a headless job evaluating a baseline and a candidate model on a " +
+			"labeled dataset (JSON Lines with the fields \"text\", \"text2\" and \"label\"). It prints the comparison " +
+			"report and exits with status 1 if the candidate fails the gate, so that it can gate a deployment in a " +
+			"CI/CD pipeline.",
+		Flags:  newCanaryCommandFlagsFor(app),
+		Action: newCanaryCommandActionFor(app),
+	}
+}
+
+func newCanaryCommandFlagsFor(app *BertApp) []cli.Flag {
+	usr, err := user.Current()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:        "repo",
+			Usage:       "Specifies the path to the models.",
+			Value:       path.Join(usr.HomeDir, ".spago"),
+			Destination: &app.repo,
+		},
+		&cli.StringFlag{
+			Name:        "baseline",
+			Required:    true,
+			Usage:       "Specifies the name of the baseline model (e.g. the deployed one).",
+			Destination: &app.canary.baseline,
+		},
+		&cli.StringFlag{
+			Name:        "candidate",
+			Required:    true,
+			Usage:       "Specifies the name of the candidate model.",
+			Destination: &app.canary.candidate,
+		},
+		&cli.StringFlag{
+			Name:        "dataset",
+			Required:    true,
+			Usage:       "Specifies the path of the labeled dataset.",
+			Destination: &app.canary.dataset,
+		},
+		&cli.StringFlag{
+			Name:        "output",
+			Aliases:     []string{"o"},
+			Value:       "text",
+			Usage:       "Specifies the format of the report (\"text\" or \"json\").",
+			Destination: &app.canary.format,
+		},
+		&cli.Float64Flag{
+			Name:        "max-accuracy-drop",
+			Usage:       "Maximum tolerated decrease of the accuracy.",
+			Destination: &app.canary.maxAccuracyDrop,
+		},
+		&cli.Float64Flag{
+			Name:        "max-f1-drop",
+			Usage:       "Maximum tolerated decrease of the macro F1.",
+			Destination: &app.canary.maxMacroF1Drop,
+		},
+		&cli.Float64Flag{
+			Name:        "alpha",
+			Value:       0.05,
+			Usage:       "Significance level of the decreases failing the gate (0 to ignore the significance).",
+			Destination: &app.canary.alpha,
+		},
+		&cli.IntFlag{
+			Name:        "bootstraps",
+			Value:       1000,
+			Usage:       "Number of resamples of the bootstrap.",
+			Destination: &app.canary.bootstraps,
+		},
+		&cli.Int64Flag{
+			Name:        "seed",
+			Value:       1,
+			Usage:       "Seed of the bootstrap.",
+			Destination: &app.canary.seed,
+		},
+	}
+}
+
+func newCanaryCommandActionFor(app *BertApp) func(c *cli.Context) error {
+	return func(c *cli.Context) error {
+		opts := app.canary
+		if opts.format != "text" && opts.format != "json" {
+			return fmt.Errorf("invalid output format %q", opts.format)
+		}
+
+		f, err := os.Open(opts.dataset)
+		if err != nil {
+			return err
+		}
+		examples, err := canary.ReadDataset(f)
+		_ = f.Close()
+		if err != nil {
+			return err
+		}
+
+		baseline, err := loadClassifier(app.repo, opts.baseline)
+		if err != nil {
+			return err
+		}
+		defer baseline.Close()
+		candidate, err := loadClassifier(app.repo, opts.candidate)
+		if err != nil {
+			return err
+		}
+		defer candidate.Close()
+
+		report, err := canary.Evaluate(examples, classifierPredictor(baseline), classifierPredictor(candidate), canary.Options{
+			Bootstraps: opts.bootstraps,
+			Seed:       opts.seed,
+		})
+		if err != nil {
+			return err
+		}
+		if opts.format == "json" {
+			err = report.WriteJSON(os.Stdout)
+		} else {
+			err = report.WriteText(os.Stdout)
+		}
+		if err != nil {
+			return err
+		}
+
+		failures := report.Failures(canary.Gate{
+			MaxAccuracyDrop: opts.maxAccuracyDrop,
+			MaxMacroF1Drop:  opts.maxMacroF1Drop,
+			Alpha:           opts.alpha,
+		})
+		if len(failures) > 0 {
+			return cli.Exit(fmt.Sprintf("canary: the candidate failed the gate: %v", failures), 1)
+		}
+		return nil
+	}
+}
+
+func loadClassifier(repo, name string) (*bert.Model, error) {
+	modelPath, err := prepareModel(repo, name)
+	if err != nil {
+		return nil, err
+	}
+	model, err := bert.LoadModel(modelPath)
+	if err != nil {
+		return nil, fmt.Errorf("error during model loading (%v)", err)
+	}
+	if model.Classifier == nil {
+		model.Close()
+		return nil, fmt.Errorf("the model %q has no classifier", name)
+	}
+	return model, nil
+}
+
+func classifierPredictor(model *bert.Model) canary.Predictor {
+	server := bert.NewServer(model)
+	return func(ex canary.Example) (string, error) {
+		reply, err := server.Classify(context.Background(), &grpcapi.ClassifyRequest{
+			Text:  ex.Text,
+			Text2: ex.Text2,
+		})
+		if err != nil {
+			return "", err
+		}
+		return reply.Class, nil
+	}
+}
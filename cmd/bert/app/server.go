@@ -6,6 +6,9 @@ package app
 
 import (
 	"fmt"
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/nlp/embeddings/postprocessing"
 	"github.com/nlpodyssey/spago/pkg/nlp/transformers/bert"
 	"github.com/nlpodyssey/spago/pkg/nlp/transformers/huggingface"
 	"github.com/nlpodyssey/spago/pkg/utils/httputils"
@@ -87,6 +90,16 @@ func newServerCommandFlagsFor(app *BertApp) []cli.Flag {
 			Value:       httputils.DefaultMaxRequestBytes,
 			Destination: &app.serverMaxRequestBytes,
 		},
+		&cli.StringFlag{
+			Name:        "half-precision",
+			Usage:       "Stores the model parameters in half precision (\"float16\" or \"bfloat16\") to reduce memory usage.",
+			Destination: &app.halfPrecision,
+		},
+		&cli.StringFlag{
+			Name:        "embeddings-postprocessor",
+			Usage:       "Specifies the path of the post-processor (e.g. whitening) to apply to the encoded sentences.",
+			Destination: &app.postProcessor,
+		},
 	}
 }
 
@@ -94,33 +107,9 @@ const defaultModelFile = "spago_model.bin"
 
 func newServerCommandActionFor(app *BertApp) func(c *cli.Context) error {
 	return func(c *cli.Context) error {
-		modelPath := filepath.Join(app.repo, app.model)
-
-		if _, err := os.Stat(modelPath); os.IsNotExist(err) {
-			fmt.Printf("Unable to find `%s` locally.\n", modelPath)
-			fmt.Printf("Pulling `%s` from Hugging Face models hub...\n", app.model)
-			// make sure the models path exists
-			if _, err := os.Stat(app.repo); os.IsNotExist(err) {
-				if err := os.MkdirAll(app.repo, 0755); err != nil {
-					return err
-				}
-			}
-			err = huggingface.NewDownloader(app.repo, app.model, false).Download()
-			if err != nil {
-				return err
-			}
-			fmt.Printf("Converting model...\n")
-			err = huggingface.NewConverter(app.repo, app.model).Convert()
-			if err != nil {
-				return err
-			}
-		} else if _, err := os.Stat(path.Join(modelPath, defaultModelFile)); os.IsNotExist(err) {
-			fmt.Printf("Unable to find `%s` in the model directory.\n", defaultModelFile)
-			fmt.Printf("Assuming there is a Hugging Face model to convert...\n")
-			err = huggingface.NewConverter(app.repo, app.model).Convert()
-			if err != nil {
-				return err
-			}
+		modelPath, err := prepareModel(app.repo, app.model)
+		if err != nil {
+			return err
 		}
 
 		model, err := bert.LoadModel(modelPath)
@@ -130,6 +119,10 @@ func newServerCommandActionFor(app *BertApp) func(c *cli.Context) error {
 		defer model.Close()
 		fmt.Printf("Config: %+v\n", model.Config)
 
+		if err := convertToHalfPrecision(model, app.halfPrecision); err != nil {
+			return err
+		}
+
 		if !app.tlsDisable {
 			fmt.Printf("TLS Cert path is %s\n", app.tlsCert)
 			fmt.Printf("TLS private key path is %s\n", app.tlsKey)
@@ -150,8 +143,64 @@ func newServerCommandActionFor(app *BertApp) func(c *cli.Context) error {
 		server := bert.NewServer(model)
 		server.TimeoutSeconds = app.serverTimeoutSeconds
 		server.MaxRequestBytes = app.serverMaxRequestBytes
+		if app.postProcessor != "" {
+			server.EmbeddingsPostProcessor, err = postprocessing.Load(app.postProcessor)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Post-processing the encoded sentences with %s.\n", server.EmbeddingsPostProcessor.Config.Method)
+		}
 		server.StartDefaultServer(app.address, app.grpcAddress, app.tlsCert, app.tlsKey, app.tlsDisable)
 
 		return nil
 	}
 }
+
+// prepareModel returns the path of the model in the repo, downloading it
+// from the Hugging Face models hub and converting it if needed.
+func prepareModel(repo, model string) (string, error) {
+	modelPath := filepath.Join(repo, model)
+
+	if _, err := os.Stat(modelPath); os.IsNotExist(err) {
+		fmt.Printf("Unable to find `%s` locally.\n", modelPath)
+		fmt.Printf("Pulling `%s` from Hugging Face models hub...\n", model)
+		// make sure the models path exists
+		if _, err := os.Stat(repo); os.IsNotExist(err) {
+			if err := os.MkdirAll(repo, 0755); err != nil {
+				return "", err
+			}
+		}
+		err = huggingface.NewDownloader(repo, model, false).Download()
+		if err != nil {
+			return "", err
+		}
+		fmt.Printf("Converting model...\n")
+		err = huggingface.NewConverter(repo, model).Convert()
+		if err != nil {
+			return "", err
+		}
+	} else if _, err := os.Stat(path.Join(modelPath, defaultModelFile)); os.IsNotExist(err) {
+		fmt.Printf("Unable to find `%s` in the model directory.\n", defaultModelFile)
+		fmt.Printf("Assuming there is a Hugging Face model to convert...\n")
+		err = huggingface.NewConverter(repo, model).Convert()
+		if err != nil {
+			return "", err
+		}
+	}
+	return modelPath, nil
+}
+
+// convertToHalfPrecision stores the model parameters in the given half
+// precision format. It does nothing if the format is empty.
+func convertToHalfPrecision(model nn.Model, format string) error {
+	if format == "" {
+		return nil
+	}
+	halfPrecision, err := mat.ParseHalfPrecision(format)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Storing the parameters in %s...\n", halfPrecision)
+	nn.ConvertToHalfPrecision(model, halfPrecision)
+	return nil
+}
@@ -0,0 +1,145 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package app
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nlpodyssey/spago/pkg/nlp/transformers/golden"
+	"github.com/urfave/cli/v2"
+)
+
+const (
+	programName = "golden"
+)
+
+// New returns a new CLI App for generating and verifying the golden outputs
+// of the pretrained models.
+func New() *cli.App {
+	var (
+		model     string
+		fixture   string
+		inputs    string
+		tolerance float64
+	)
+	modelFlag := &cli.StringFlag{
+		Name:        "model",
+		Aliases:     []string{"m"},
+		Usage:       "The path of the pretrained model.",
+		Required:    true,
+		Destination: &model,
+	}
+	fixtureFlag := &cli.StringFlag{
+		Name:        "fixture",
+		Usage:       "The path of the fixture (default: golden.json in the model directory).",
+		Destination: &fixture,
+	}
+	fixturePath := func() string {
+		if fixture != "" {
+			return fixture
+		}
+		return filepath.Join(model, golden.DefaultFixtureFile)
+	}
+
+	app := cli.NewApp()
+	app.Name = programName
+	app.HelpName = programName
+	app.Usage = "Generate and verify the golden outputs of the pretrained models"
+	app.HideVersion = true
+	app.Commands = []*cli.Command{
+		{
+			Name:  "generate",
+			Usage: "Generate the golden outputs of a model with the current version of the library.",
+			Flags: []cli.Flag{
+				modelFlag,
+				fixtureFlag,
+				&cli.StringFlag{
+					Name:        "inputs",
+					Usage:       "A text file with an input per line (default: a few built-in texts).",
+					Destination: &inputs,
+				},
+				&cli.Float64Flag{
+					Name:        "tolerance",
+					Usage:       "The maximum absolute difference allowed when verifying the outputs.",
+					Value:       golden.DefaultTolerance,
+					Destination: &tolerance,
+				},
+			},
+			Action: func(c *cli.Context) error {
+				texts := golden.DefaultInputs
+				if inputs != "" {
+					var err error
+					if texts, err = readLines(inputs); err != nil {
+						return err
+					}
+				}
+				runner, err := golden.NewRunner(model)
+				if err != nil {
+					return err
+				}
+				defer runner.Close()
+				f, err := golden.Generate(runner, texts, tolerance)
+				if err != nil {
+					return err
+				}
+				if err := f.Save(fixturePath()); err != nil {
+					return err
+				}
+				fmt.Printf("Stored %d golden cases of %s in %s\n", len(f.Cases), f.Architecture, fixturePath())
+				return nil
+			},
+		},
+		{
+			Name:  "verify",
+			Usage: "Verify that the current version of the library reproduces the golden outputs of a model.",
+			Flags: []cli.Flag{modelFlag, fixtureFlag},
+			Action: func(c *cli.Context) error {
+				f, err := golden.Load(fixturePath())
+				if err != nil {
+					return err
+				}
+				runner, err := golden.NewRunner(model)
+				if err != nil {
+					return err
+				}
+				defer runner.Close()
+				mismatches, err := f.Verify(runner)
+				if err != nil {
+					return err
+				}
+				for _, m := range mismatches {
+					fmt.Println(m)
+				}
+				if len(mismatches) > 0 {
+					return cli.Exit(fmt.Sprintf("%d outputs are not reproduced within %g", len(mismatches), f.Tolerance), 1)
+				}
+				fmt.Printf("All the %d golden cases of %s are reproduced within %g\n", len(f.Cases), f.Architecture, f.Tolerance)
+				return nil
+			},
+		},
+	}
+	return app
+}
+
+// readLines returns the non-empty lines of a text file.
+func readLines(filename string) ([]string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
@@ -0,0 +1,71 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mat32
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDense_WriteArrowTensor(t *testing.T) {
+	d := NewDense(2, 3, []Float{1, 2, 3, 4, 5, 6})
+	buf := new(bytes.Buffer)
+	require.NoError(t, d.WriteArrowTensor(buf))
+	data := buf.Bytes()
+
+	assert.Equal(t, arrowContinuation, binary.LittleEndian.Uint32(data))
+	metadataLen := int(binary.LittleEndian.Uint32(data[4:]))
+	assert.Equal(t, 0, (8+metadataLen)%8)
+	assert.Len(t, data, 8+metadataLen+(6*floatSize+7)&^7)
+
+	decoded, err := ReadArrowTensor(buf)
+	require.NoError(t, err)
+	assert.Equal(t, 2, decoded.Rows())
+	assert.Equal(t, 3, decoded.Columns())
+	assert.Equal(t, d.Data(), decoded.Data())
+}
+
+func TestDecodeArrowTensor_Legacy(t *testing.T) {
+	buf := new(bytes.Buffer)
+	require.NoError(t, NewVecDense([]Float{1, 2, 3}).WriteArrowTensor(buf))
+	// the messages before Arrow 0.15 have no continuation marker
+	d, err := DecodeArrowTensor(buf.Bytes()[4:])
+	require.NoError(t, err)
+	assert.Equal(t, 3, d.Rows())
+	assert.Equal(t, 1, d.Columns())
+	assert.Equal(t, []Float{1, 2, 3}, d.Data())
+}
+
+func TestDecodeArrowTensor_ZeroCopy(t *testing.T) {
+	buf := new(bytes.Buffer)
+	require.NoError(t, NewVecDense([]Float{1, 2}).WriteArrowTensor(buf))
+	data := buf.Bytes()
+	d, err := DecodeArrowTensor(data)
+	require.NoError(t, err)
+	if littleEndianHost {
+		assert.False(t, d.fromPool)
+		for i := range data[len(data)-8:] {
+			data[len(data)-8+i] = 0 // the body, padded to 8 bytes
+		}
+		assert.Equal(t, Float(0), d.Data()[len(d.Data())-1])
+	}
+}
+
+func TestDecodeArrowTensor_Errors(t *testing.T) {
+	_, err := DecodeArrowTensor([]byte{1, 2})
+	assert.EqualError(t, err, "mat32: invalid Arrow tensor message")
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, NewVecDense([]Float{1, 2, 3}).WriteArrowTensor(buf))
+	data := buf.Bytes()
+	_, err = DecodeArrowTensor(data[:len(data)-8])
+	assert.EqualError(t, err, "mat32: truncated Arrow tensor data")
+	_, err = DecodeArrowTensor(data[:20])
+	assert.EqualError(t, err, "mat32: invalid Arrow tensor message")
+}
@@ -0,0 +1,121 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mat32
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type countingBackend struct {
+	GoBackend
+	mul, inverse int
+}
+
+func (b *countingBackend) Mul(x, y, out *Dense) {
+	b.mul++
+	b.GoBackend.Mul(x, y, out)
+}
+
+func (b *countingBackend) Inverse(x, out *Dense) {
+	b.inverse++
+	b.GoBackend.Inverse(x, out)
+}
+
+func TestSetBackend(t *testing.T) {
+	prev := CurrentBackend()
+	defer SetBackend(prev)
+
+	b := &countingBackend{}
+	SetBackend(b)
+	assert.Same(t, b, CurrentBackend())
+
+	x := NewDense(2, 2, []Float{4, 7, 2, 6})
+	y := x.Mul(NewDense(2, 2, []Float{1, 0, 0, 1}))
+	assertSliceEqualApprox(t, []Float{4, 7, 2, 6}, y.Data())
+	assert.Equal(t, 1, b.mul)
+
+	inv := x.Inverse()
+	assertSliceEqualApprox(t, []Float{0.6, -0.7, -0.2, 0.4}, inv.Data())
+	assert.Equal(t, 1, b.inverse)
+
+	assert.Panics(t, func() { SetBackend(nil) })
+}
+
+func TestGoBackend_Mul(t *testing.T) {
+	a := NewDense(2, 3, []Float{1, 2, 3, 4, 5, 6})
+
+	t.Run("matrix-matrix", func(t *testing.T) {
+		out := NewEmptyDense(2, 2)
+		GoBackend{}.Mul(a, NewDense(3, 2, []Float{1, 2, 3, 4, 5, 6}), out)
+		assertSliceEqualApprox(t, []Float{22, 28, 49, 64}, out.Data())
+	})
+
+	t.Run("matrix-vector", func(t *testing.T) {
+		out := NewEmptyVecDense(2)
+		GoBackend{}.Mul(a, NewVecDense([]Float{1, 0, 1}), out)
+		assertSliceEqualApprox(t, []Float{4, 10}, out.Data())
+	})
+}
+
+func TestSetMatMulThreads(t *testing.T) {
+	defer SetMatMulThreads(0)
+
+	SetMatMulThreads(3)
+	assert.Equal(t, 3, MatMulThreads())
+	SetMatMulThreads(0)
+	assert.Equal(t, runtime.GOMAXPROCS(0), MatMulThreads())
+	SetMatMulThreads(-1)
+	assert.Equal(t, runtime.GOMAXPROCS(0), MatMulThreads())
+}
+
+func TestGoBackend_MulParallel(t *testing.T) {
+	defer SetMatMulThreads(0)
+
+	// the sizes are not multiple of the blocks size, to test the edges
+	a := newBenchmarkMatrix(150, 130)
+	b := newBenchmarkMatrix(130, 170)
+
+	SetMatMulThreads(1)
+	expected := NewEmptyDense(150, 170)
+	GoBackend{}.Mul(a, b, expected)
+
+	for _, threads := range []int{2, 4} {
+		SetMatMulThreads(threads)
+		out := NewEmptyDense(150, 170)
+		GoBackend{}.Mul(a, b, out)
+		assert.InDeltaSlice(t, expected.Data(), out.Data(), 1.0e-4)
+	}
+}
+
+func BenchmarkGoBackend_Mul(b *testing.B) {
+	defer SetMatMulThreads(0)
+
+	for _, size := range []int{256, 1024, 2048} {
+		for _, threads := range []int{1, 2, 4, 8} {
+			b.Run(fmt.Sprintf("%dx%d/threads=%d", size, size, threads), func(b *testing.B) {
+				SetMatMulThreads(threads)
+				x := newBenchmarkMatrix(size, size)
+				y := newBenchmarkMatrix(size, size)
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					GoBackend{}.Mul(x, y, NewEmptyDense(size, size))
+				}
+			})
+		}
+	}
+}
+
+// newBenchmarkMatrix returns a new matrix filled with deterministic values in [-0.5, 0.5).
+func newBenchmarkMatrix(rows, cols int) *Dense {
+	data := make([]Float, rows*cols)
+	for i := range data {
+		data[i] = Float((i*7919)%1000)/1000 - 0.5
+	}
+	return NewDense(rows, cols, data)
+}
@@ -0,0 +1,280 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mat32
+
+import (
+	"fmt"
+	"math"
+	"math/cmplx"
+)
+
+// ComplexDense is a matrix of complex values, e.g. the spectra of the FFT
+// functions, or the rotations of the rotary embeddings.
+// It is kept apart from the Matrix interface and the float path: the values
+// are converted from and to the float matrices explicitly.
+type ComplexDense struct {
+	rows int
+	cols int
+	data []Complex
+}
+
+// NewComplexDense returns a new rows × cols complex matrix, populated with a
+// copy of the data, in row-major order.
+func NewComplexDense(rows, cols int, data []Complex) *ComplexDense {
+	if len(data) != rows*cols {
+		panic(fmt.Sprintf("mat32: wrong matrix dimensions. Elements size must be: %d", rows*cols))
+	}
+	d := NewEmptyComplexDense(rows, cols)
+	copy(d.data, data)
+	return d
+}
+
+// NewEmptyComplexDense returns a new rows × cols complex matrix of zeros.
+func NewEmptyComplexDense(rows, cols int) *ComplexDense {
+	if rows < 0 || cols < 0 {
+		panic("mat32: negative values for rows and cols are not allowed")
+	}
+	return &ComplexDense{rows: rows, cols: cols, data: make([]Complex, rows*cols)}
+}
+
+// NewComplexFromReal returns a new complex matrix with the values of the
+// given matrices as real and imaginary parts. The imaginary part can be nil.
+func NewComplexFromReal(re, im Matrix) *ComplexDense {
+	if im != nil && !SameDims(re, im) {
+		panic("mat32: matrices with not compatible size")
+	}
+	d := NewEmptyComplexDense(re.Dims())
+	reData := re.Data()
+	var imData []Float
+	if im != nil {
+		imData = im.Data()
+	}
+	for i := range d.data {
+		var v Float
+		if imData != nil {
+			v = imData[i]
+		}
+		d.data[i] = complex(reData[i], v)
+	}
+	return d
+}
+
+// NewComplexFromPolar returns a new complex matrix with the given magnitudes
+// and phases: m[i][j] = r[i][j] * exp(i * theta[i][j]). The magnitudes can be
+// nil, meaning ones (e.g. the rotations of the rotary embeddings).
+func NewComplexFromPolar(r, theta Matrix) *ComplexDense {
+	if r != nil && !SameDims(r, theta) {
+		panic("mat32: matrices with not compatible size")
+	}
+	d := NewEmptyComplexDense(theta.Dims())
+	thetaData := theta.Data()
+	for i := range d.data {
+		magnitude := 1.0
+		if r != nil {
+			magnitude = float64(r.Data()[i])
+		}
+		d.data[i] = Complex(cmplx.Rect(magnitude, float64(thetaData[i])))
+	}
+	return d
+}
+
+// Rows returns the number of rows of the matrix.
+func (d *ComplexDense) Rows() int {
+	return d.rows
+}
+
+// Columns returns the number of columns of the matrix.
+func (d *ComplexDense) Columns() int {
+	return d.cols
+}
+
+// Dims returns the number of rows and columns of the matrix.
+func (d *ComplexDense) Dims() (rows, cols int) {
+	return d.rows, d.cols
+}
+
+// Size returns the number of elements of the matrix.
+func (d *ComplexDense) Size() int {
+	return len(d.data)
+}
+
+// Data returns the underlying data of the matrix, in row-major order.
+func (d *ComplexDense) Data() []Complex {
+	return d.data
+}
+
+// At returns the value at row i and column j.
+func (d *ComplexDense) At(i, j int) Complex {
+	d.checkIndices(i, j)
+	return d.data[i*d.cols+j]
+}
+
+// Set sets the value at row i and column j.
+func (d *ComplexDense) Set(i, j int, v Complex) {
+	d.checkIndices(i, j)
+	d.data[i*d.cols+j] = v
+}
+
+func (d *ComplexDense) checkIndices(i, j int) {
+	if i < 0 || i >= d.rows || j < 0 || j >= d.cols {
+		panic("mat32: index out of range")
+	}
+}
+
+// Clone returns a new matrix, copying all its values.
+func (d *ComplexDense) Clone() *ComplexDense {
+	return NewComplexDense(d.rows, d.cols, d.data)
+}
+
+// SameComplexDims reports whether the two complex matrices have the same
+// dimensions.
+func SameComplexDims(a, b *ComplexDense) bool {
+	return a.rows == b.rows && a.cols == b.cols
+}
+
+// Add returns the element-wise sum of the matrices.
+func (d *ComplexDense) Add(other *ComplexDense) *ComplexDense {
+	return d.apply(other, func(a, b Complex) Complex { return a + b })
+}
+
+// Sub returns the element-wise difference of the matrices.
+func (d *ComplexDense) Sub(other *ComplexDense) *ComplexDense {
+	return d.apply(other, func(a, b Complex) Complex { return a - b })
+}
+
+// Prod returns the element-wise product of the matrices (e.g. to rotate
+// the pairs of features of the rotary embeddings, or to multiply the spectra
+// of two signals).
+func (d *ComplexDense) Prod(other *ComplexDense) *ComplexDense {
+	return d.apply(other, func(a, b Complex) Complex { return a * b })
+}
+
+// Div returns the element-wise division of the matrices.
+func (d *ComplexDense) Div(other *ComplexDense) *ComplexDense {
+	return d.apply(other, func(a, b Complex) Complex { return a / b })
+}
+
+func (d *ComplexDense) apply(other *ComplexDense, f func(a, b Complex) Complex) *ComplexDense {
+	if !SameComplexDims(d, other) {
+		panic("mat32: matrices with not compatible size")
+	}
+	y := NewEmptyComplexDense(d.rows, d.cols)
+	for i, v := range d.data {
+		y.data[i] = f(v, other.data[i])
+	}
+	return y
+}
+
+// Scale returns the matrix multiplied by the scalar.
+func (d *ComplexDense) Scale(c Complex) *ComplexDense {
+	y := NewEmptyComplexDense(d.rows, d.cols)
+	for i, v := range d.data {
+		y.data[i] = v * c
+	}
+	return y
+}
+
+// Mul returns the matrix product d × other.
+func (d *ComplexDense) Mul(other *ComplexDense) *ComplexDense {
+	if d.cols != other.rows {
+		panic("mat32: matrices with not compatible size")
+	}
+	y := NewEmptyComplexDense(d.rows, other.cols)
+	for i := 0; i < d.rows; i++ {
+		row := y.data[i*other.cols : (i+1)*other.cols]
+		for k, a := range d.data[i*d.cols : (i+1)*d.cols] {
+			if a == 0 {
+				continue
+			}
+			for j, b := range other.data[k*other.cols : (k+1)*other.cols] {
+				row[j] += a * b
+			}
+		}
+	}
+	return y
+}
+
+// Conj returns the element-wise complex conjugate of the matrix.
+func (d *ComplexDense) Conj() *ComplexDense {
+	y := NewEmptyComplexDense(d.rows, d.cols)
+	for i, v := range d.data {
+		y.data[i] = conj(v)
+	}
+	return y
+}
+
+// T returns the transpose of the matrix (not conjugated).
+func (d *ComplexDense) T() *ComplexDense {
+	y := NewEmptyComplexDense(d.cols, d.rows)
+	for i := 0; i < d.rows; i++ {
+		for j := 0; j < d.cols; j++ {
+			y.data[j*d.rows+i] = d.data[i*d.cols+j]
+		}
+	}
+	return y
+}
+
+// H returns the conjugate transpose of the matrix.
+func (d *ComplexDense) H() *ComplexDense {
+	return d.T().Conj()
+}
+
+// Real returns the real parts of the elements.
+func (d *ComplexDense) Real() *Dense {
+	return d.toReal(func(v Complex) Float { return real(v) })
+}
+
+// Imag returns the imaginary parts of the elements.
+func (d *ComplexDense) Imag() *Dense {
+	return d.toReal(func(v Complex) Float { return imag(v) })
+}
+
+// Abs returns the magnitudes of the elements.
+func (d *ComplexDense) Abs() *Dense {
+	return d.toReal(func(v Complex) Float {
+		return Float(math.Hypot(float64(real(v)), float64(imag(v))))
+	})
+}
+
+// Phase returns the phases of the elements, in the range [-Pi, Pi].
+func (d *ComplexDense) Phase() *Dense {
+	return d.toReal(func(v Complex) Float {
+		return Float(math.Atan2(float64(imag(v)), float64(real(v))))
+	})
+}
+
+func (d *ComplexDense) toReal(f func(v Complex) Float) *Dense {
+	y := NewEmptyDense(d.rows, d.cols)
+	yData := y.Data()
+	for i, v := range d.data {
+		yData[i] = f(v)
+	}
+	return y
+}
+
+// FFTRows returns the matrix with the discrete Fourier transform of each row
+// (see FFT).
+func (d *ComplexDense) FFTRows() *ComplexDense {
+	return d.mapRows(FFT)
+}
+
+// IFFTRows returns the matrix with the inverse discrete Fourier transform of
+// each row (see IFFT).
+func (d *ComplexDense) IFFTRows() *ComplexDense {
+	return d.mapRows(IFFT)
+}
+
+func (d *ComplexDense) mapRows(f func(x []Complex) []Complex) *ComplexDense {
+	y := NewEmptyComplexDense(d.rows, d.cols)
+	for i := 0; i < d.rows; i++ {
+		copy(y.data[i*d.cols:(i+1)*d.cols], f(d.data[i*d.cols:(i+1)*d.cols]))
+	}
+	return y
+}
+
+// String returns a string representation of the matrix.
+func (d *ComplexDense) String() string {
+	return fmt.Sprintf("%d×%d %v", d.rows, d.cols, d.data)
+}
@@ -0,0 +1,105 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mat32
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewComplexDense(t *testing.T) {
+	data := []Complex{1 + 2i, 3, -1i, 0.5}
+	d := NewComplexDense(2, 2, data)
+	data[0] = 0
+	assert.Equal(t, Complex(1+2i), d.At(0, 0))
+	assert.Equal(t, Complex(-1i), d.At(1, 0))
+	rows, cols := d.Dims()
+	assert.Equal(t, 2, rows)
+	assert.Equal(t, 2, cols)
+	assert.Equal(t, 4, d.Size())
+
+	d.Set(1, 1, 2i)
+	assert.Equal(t, Complex(2i), d.Data()[3])
+
+	assert.Panics(t, func() { NewComplexDense(2, 2, data[:3]) })
+	assert.Panics(t, func() { d.At(2, 0) })
+	assert.Panics(t, func() { NewEmptyComplexDense(-1, 2) })
+}
+
+func TestNewComplexFromReal(t *testing.T) {
+	d := NewComplexFromReal(NewVecDense([]Float{1, 2}), NewVecDense([]Float{3, -4}))
+	assert.Equal(t, []Complex{1 + 3i, 2 - 4i}, d.Data())
+	assert.Equal(t, []Float{1, 2}, d.Real().Data())
+	assert.Equal(t, []Float{3, -4}, d.Imag().Data())
+	assert.InDeltaSlice(t, []Float{Sqrt(10), 2 * Sqrt(5)}, d.Abs().Data(), 1.0e-6)
+
+	d = NewComplexFromReal(NewVecDense([]Float{1, 2}), nil)
+	assert.Equal(t, []Complex{1, 2}, d.Data())
+
+	assert.Panics(t, func() { NewComplexFromReal(NewVecDense([]Float{1, 2}), NewVecDense([]Float{1})) })
+}
+
+func TestNewComplexFromPolar(t *testing.T) {
+	theta := NewVecDense([]Float{0, math.Pi / 2, -math.Pi / 4})
+	d := NewComplexFromPolar(NewVecDense([]Float{1, 2, Sqrt(2)}), theta)
+	assert.InDeltaSlice(t, []Float{1, 0, 1}, d.Real().Data(), 1.0e-6)
+	assert.InDeltaSlice(t, []Float{0, 2, -1}, d.Imag().Data(), 1.0e-6)
+	assert.InDeltaSlice(t, theta.Data(), d.Phase().Data(), 1.0e-6)
+
+	unit := NewComplexFromPolar(nil, theta)
+	assert.InDeltaSlice(t, []Float{1, 1, 1}, unit.Abs().Data(), 1.0e-6)
+}
+
+func TestComplexDense_RotaryEmbeddings(t *testing.T) {
+	// the pairs (x[2k], x[2k+1]) of the features are rotated by the angles
+	x := NewComplexDense(1, 2, []Complex{1 + 0i, 0 + 1i})
+	rotation := NewComplexFromPolar(nil, NewDense(1, 2, []Float{math.Pi / 2, math.Pi / 2}))
+	y := x.Prod(rotation)
+	assert.InDeltaSlice(t, []Float{0, -1}, y.Real().Data(), 1.0e-6)
+	assert.InDeltaSlice(t, []Float{1, 0}, y.Imag().Data(), 1.0e-6)
+}
+
+func TestComplexDense_ElementWise(t *testing.T) {
+	a := NewComplexDense(1, 2, []Complex{1 + 1i, 2})
+	b := NewComplexDense(1, 2, []Complex{1i, 2 - 2i})
+	assert.Equal(t, []Complex{1 + 2i, 4 - 2i}, a.Add(b).Data())
+	assert.Equal(t, []Complex{1, 2i}, a.Sub(b).Data())
+	assert.Equal(t, []Complex{-1 + 1i, 4 - 4i}, a.Prod(b).Data())
+	assert.Equal(t, []Complex{1 - 1i, 0.5 + 0.5i}, a.Div(b).Data())
+	assert.Equal(t, []Complex{2i, 4i}, a.Scale(2i).Add(NewComplexDense(1, 2, []Complex{2, 0})).Data())
+	assert.Equal(t, []Complex{1 - 1i, 2}, a.Conj().Data())
+	assert.Panics(t, func() { a.Add(NewEmptyComplexDense(2, 1)) })
+}
+
+func TestComplexDense_Mul(t *testing.T) {
+	a := NewComplexDense(2, 2, []Complex{
+		1, 1i,
+		0, 2,
+	})
+	b := NewComplexDense(2, 1, []Complex{
+		1i,
+		1 + 1i,
+	})
+	assert.Equal(t, []Complex{-1 + 2i, 2 + 2i}, a.Mul(b).Data())
+	assert.Panics(t, func() { a.Mul(a.Mul(b).T()) })
+
+	h := a.H()
+	assert.Equal(t, []Complex{1, 0, -1i, 2}, h.Data())
+	assert.Equal(t, []Complex{1, 0, 1i, 2}, a.T().Data())
+}
+
+func TestComplexDense_FFTRows(t *testing.T) {
+	d := NewComplexDense(2, 4, []Complex{
+		1, 0, 0, 0,
+		1, 1, 1, 1,
+	})
+	f := d.FFTRows()
+	assert.Equal(t, []Complex{1, 1, 1, 1, 4, 0, 0, 0}, f.Data())
+	back := f.IFFTRows()
+	assert.InDeltaSlice(t, d.Real().Data(), back.Real().Data(), 1.0e-6)
+	assert.InDeltaSlice(t, d.Imag().Data(), back.Imag().Data(), 1.0e-6)
+}
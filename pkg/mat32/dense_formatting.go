@@ -17,6 +17,7 @@ var _ fmt.Formatter = &Dense{}
 // Format implements custom formatting for represeinting a Dense matrix.
 // Thanks to this method, a Dense matrix implements the fmt.Formatter interface.
 func (d *Dense) Format(f fmt.State, c rune) {
+	d.sync()
 	if c == 'v' {
 		if f.Flag('#') {
 			fmt.Fprintf(f, "&%#v", *d)
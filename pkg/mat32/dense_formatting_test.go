@@ -69,10 +69,10 @@ func TestDense_Format(t *testing.T) {
 
 	run("Go-syntax representation", NewScalar(1.2), "%#v",
 		"&mat32.Dense{rows:1, cols:1, size:1, data:[]float32{1.2}, "+
-			"viewOf:(*mat32.Dense)(nil), fromPool:true}")
+			"viewOf:(*mat32.Dense)(nil), fromPool:true, device:mat32.Device(nil), memory:(*mat32.deviceMemory)(nil)}")
 
 	run("Default format with field names", NewScalar(1.2), "%+v",
-		"{rows:1 cols:1 size:1 data:[1.2] viewOf:<nil> fromPool:true}")
+		"{rows:1 cols:1 size:1 data:[1.2] viewOf:<nil> fromPool:true device:<nil> memory:<nil>}")
 
 	run("decimalless scientific notation", NewScalar(0), "%b", "[0p-149]")
 
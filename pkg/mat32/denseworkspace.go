@@ -53,6 +53,8 @@ func GetDenseWorkspace(r, c int) *Dense {
 	w.rows = r
 	w.cols = c
 	w.size = size
+	w.device = nil
+	w.memory = nil
 	return w
 }
 
@@ -67,6 +69,8 @@ func GetEmptyDenseWorkspace(r, c int) *Dense {
 	w.rows = r
 	w.cols = c
 	w.size = size
+	w.device = nil
+	w.memory = nil
 	if !isNew {
 		zero(w.data)
 	}
@@ -90,6 +94,7 @@ func ReleaseDense(w *Dense) {
 	if !w.fromPool {
 		panic("mat32: only matrices originated from the workspace can return to it")
 	}
+	w.release()
 	densePool[bits(uint64(cap(w.data)))].Put(w)
 }
 
@@ -0,0 +1,246 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mat32
+
+import (
+	"sync/atomic"
+	"unsafe"
+
+	"github.com/nlpodyssey/spago/pkg/mat32/internal"
+	"github.com/nlpodyssey/spago/pkg/mat32/internal/asm/f32"
+)
+
+// Device represents a processing unit where the operations provided by a
+// Backend, and the element-wise operations of the Dense matrices, are
+// executed.
+//
+// Every Dense matrix is associated with a Device (Host by default): the
+// operations of a Dense matrix listed by this interface are executed on its
+// Device, and the matrices resulting from its arithmetic and element-wise
+// operations are associated with the same Device. All the other operations
+// are executed on the host.
+//
+// A Device other than the Host can keep the results of its operations in its
+// own memory, so that a chain of operations on the device doesn't transfer
+// the intermediate values: they are copied back to the host data of the
+// matrix only when it's accessed by an operation executed on the host (e.g.
+// Data, At or Sum).
+type Device interface {
+	Backend
+	// Name returns a short human-readable name of the device.
+	Name() string
+	// Add performs the element-wise addition a+b, storing the result in out,
+	// which can be a itself.
+	Add(a, b, out *Dense)
+	// Sub performs the element-wise subtraction a-b, storing the result in
+	// out, which can be a itself.
+	Sub(a, b, out *Dense)
+	// Prod performs the element-wise product a⊙b, storing the result in out,
+	// which can be a itself.
+	Prod(a, b, out *Dense)
+	// Div performs the element-wise division a/b, storing the result in out,
+	// which can be a itself.
+	Div(a, b, out *Dense)
+	// ProdScalar multiplies all the elements of a by n, storing the result in
+	// out, which can be a itself.
+	ProdScalar(a *Dense, n Float, out *Dense)
+	// Softmax computes the softmax of all the elements of a, storing the
+	// result in out, which has the same size.
+	Softmax(a, out *Dense)
+}
+
+// Host is the default Device. It executes the operations on the CPU,
+// delegating the ones of the Backend to the current Backend (see SetBackend).
+var Host Device = hostDevice{}
+
+type hostDevice struct{}
+
+// Name returns the name of the Host device.
+func (hostDevice) Name() string {
+	return "host"
+}
+
+// Mul delegates the matrix multiplication to the current Backend.
+func (hostDevice) Mul(a, b, out *Dense) {
+	syncAll(a, b, out)
+	backend.Mul(a, b, out)
+}
+
+// Inverse delegates the matrix inversion to the current Backend.
+func (hostDevice) Inverse(a, out *Dense) {
+	syncAll(a, out)
+	backend.Inverse(a, out)
+}
+
+// Add performs the element-wise addition a+b, storing the result in out.
+func (hostDevice) Add(a, b, out *Dense) {
+	syncAll(a, b, out)
+	if out == a {
+		f32.AxpyUnitary(1.0, b.data, a.data)
+		return
+	}
+	f32.AxpyUnitaryTo(out.data, 1.0, b.data, a.data)
+}
+
+// Sub performs the element-wise subtraction a-b, storing the result in out.
+func (hostDevice) Sub(a, b, out *Dense) {
+	syncAll(a, b, out)
+	if out == a {
+		f32.AxpyUnitary(-1.0, b.data, a.data)
+		return
+	}
+	f32.AxpyUnitaryTo(out.data, -1.0, b.data, a.data)
+}
+
+// Prod performs the element-wise product a⊙b, storing the result in out.
+func (hostDevice) Prod(a, b, out *Dense) {
+	syncAll(a, b, out)
+	// Avoid bounds checks in loop
+	aData := a.data
+	bData := b.data
+	outData := out.data
+	lastIndex := len(bData) - 1
+	if lastIndex < 0 {
+		return
+	}
+	_ = outData[lastIndex]
+	_ = aData[lastIndex]
+	for i := lastIndex; i >= 0; i-- {
+		outData[i] = aData[i] * bData[i]
+	}
+}
+
+// Div performs the element-wise division a/b, storing the result in out.
+func (hostDevice) Div(a, b, out *Dense) {
+	syncAll(a, b, out)
+	if out == a {
+		for i, val := range b.data {
+			a.data[i] *= 1.0 / val
+		}
+		return
+	}
+	internal.DivTo(out.data, a.data, b.data)
+}
+
+// ProdScalar multiplies all the elements of a by n, storing the result in out.
+func (hostDevice) ProdScalar(a *Dense, n Float, out *Dense) {
+	syncAll(a, out)
+	if out == a {
+		f32.ScalUnitary(n, a.data)
+		return
+	}
+	f32.ScalUnitaryTo(out.data, n, a.data)
+}
+
+// Softmax computes the softmax of all the elements of a, storing the result
+// in out.
+func (hostDevice) Softmax(a, out *Dense) {
+	syncAll(a, out)
+	if a.size == 0 {
+		return
+	}
+	maximum := a.data[len(a.data)-1]
+	for _, v := range a.data {
+		if maximum <= v {
+			maximum = v
+		}
+	}
+	var sum Float = 0.0
+	for i, v := range a.data {
+		e := Exp(v - maximum)
+		out.data[i] = e
+		sum += e
+	}
+	for i := range out.data {
+		out.data[i] /= sum
+	}
+}
+
+// residentDevice is implemented by the devices keeping the results of their
+// operations in their own memory (see Device).
+type residentDevice interface {
+	// copyToHost copies the values of the matrix held by the device, if more
+	// recent, to its host data, releasing them.
+	copyToHost(d *Dense)
+	// release releases the values of the matrix held by the device, if any.
+	release(d *Dense)
+}
+
+// deviceMemory refers to the values of a Dense matrix held in the memory of
+// a residentDevice. It is shared by the copies of the Dense struct, so that
+// any of them can synchronize the host data.
+type deviceMemory struct {
+	// stale is 1 when the host data of the matrix is older than the values
+	// held by the device (atomic).
+	stale int32
+	// handle is the device-specific reference to the values, only accessed
+	// by the device.
+	handle unsafe.Pointer
+}
+
+// isStale reports whether the host data is older than the values held by the
+// device.
+func (m *deviceMemory) isStale() bool {
+	return m != nil && atomic.LoadInt32(&m.stale) == 1
+}
+
+// sync copies the values of the matrix held by its device, if more recent,
+// back to the host data, before an operation executed on the host. The
+// values of a view are the ones of the matrix it is a view of.
+func (d *Dense) sync() {
+	if d.viewOf != nil {
+		d.viewOf.sync()
+		return
+	}
+	if !d.memory.isStale() {
+		return
+	}
+	if dev, ok := d.device.(residentDevice); ok {
+		dev.copyToHost(d)
+	}
+}
+
+// syncAll calls sync on each matrix.
+func syncAll(ms ...*Dense) {
+	for _, m := range ms {
+		m.sync()
+	}
+}
+
+// release releases the values of the matrix held by its device, if any,
+// before the matrix is reused.
+func (d *Dense) release() {
+	if d.memory == nil {
+		return
+	}
+	if dev, ok := d.device.(residentDevice); ok {
+		dev.release(d)
+	}
+	d.memory = nil
+}
+
+// Device returns the Device associated with the matrix.
+func (d *Dense) Device() Device {
+	if d.device == nil {
+		return Host
+	}
+	return d.device
+}
+
+// ToDevice returns a copy of the matrix, associated with the given Device.
+func (d *Dense) ToDevice(device Device) *Dense {
+	out := d.Clone().(*Dense)
+	if device != Host {
+		out.device = device
+	} else {
+		out.device = nil
+	}
+	return out
+}
+
+// ToHost returns a copy of the matrix, associated with the Host device.
+func (d *Dense) ToHost() *Dense {
+	return d.ToDevice(Host)
+}
@@ -0,0 +1,517 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build cuda
+
+package mat32
+
+/*
+#cgo LDFLAGS: -lcublas -lcudart -lcuda -lnvrtc
+#include <stdlib.h>
+#include <cuda.h>
+#include <cuda_runtime.h>
+#include <cublas_v2.h>
+#include <nvrtc.h>
+
+#define BLOCK_SIZE 256
+
+static CUresult launchBinary(CUfunction f, int n, void *a, void *b, void *out) {
+	void *args[] = {&n, &a, &b, &out};
+	return cuLaunchKernel(f, (n + BLOCK_SIZE - 1) / BLOCK_SIZE, 1, 1, BLOCK_SIZE, 1, 1, 0, NULL, args, NULL);
+}
+
+static CUresult launchScale(CUfunction f, int n, float k, void *a, void *out) {
+	void *args[] = {&n, &k, &a, &out};
+	return cuLaunchKernel(f, (n + BLOCK_SIZE - 1) / BLOCK_SIZE, 1, 1, BLOCK_SIZE, 1, 1, 0, NULL, args, NULL);
+}
+
+static CUresult launchSoftmax(CUfunction f, int n, void *a, void *out) {
+	void *args[] = {&n, &a, &out};
+	return cuLaunchKernel(f, 1, 1, 1, BLOCK_SIZE, 1, 1, 0, NULL, args, NULL);
+}
+*/
+import "C"
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+// cudaKernels is the source of the element-wise kernels, compiled with NVRTC
+// when the device is created. The softmax is computed by a single block,
+// reducing the maximum and the sum in shared memory.
+const cudaKernels = `
+typedef float T;
+#define BLOCK_SIZE 256
+
+extern "C" __global__ void add(int n, const T *a, const T *b, T *out) {
+	int i = blockIdx.x * blockDim.x + threadIdx.x;
+	if (i < n) out[i] = a[i] + b[i];
+}
+
+extern "C" __global__ void sub(int n, const T *a, const T *b, T *out) {
+	int i = blockIdx.x * blockDim.x + threadIdx.x;
+	if (i < n) out[i] = a[i] - b[i];
+}
+
+extern "C" __global__ void prod(int n, const T *a, const T *b, T *out) {
+	int i = blockIdx.x * blockDim.x + threadIdx.x;
+	if (i < n) out[i] = a[i] * b[i];
+}
+
+extern "C" __global__ void div(int n, const T *a, const T *b, T *out) {
+	int i = blockIdx.x * blockDim.x + threadIdx.x;
+	if (i < n) out[i] = a[i] / b[i];
+}
+
+extern "C" __global__ void scale(int n, T k, const T *a, T *out) {
+	int i = blockIdx.x * blockDim.x + threadIdx.x;
+	if (i < n) out[i] = a[i] * k;
+}
+
+extern "C" __global__ void softmax(int n, const T *a, T *out) {
+	__shared__ T buf[BLOCK_SIZE];
+	int t = threadIdx.x;
+	T m = a[0];
+	for (int i = t; i < n; i += BLOCK_SIZE) m = fmax(m, a[i]);
+	buf[t] = m;
+	__syncthreads();
+	for (int s = BLOCK_SIZE / 2; s > 0; s >>= 1) {
+		if (t < s) buf[t] = fmax(buf[t], buf[t + s]);
+		__syncthreads();
+	}
+	m = buf[0];
+	__syncthreads();
+	T sum = 0;
+	for (int i = t; i < n; i += BLOCK_SIZE) {
+		T e = exp(a[i] - m);
+		out[i] = e;
+		sum += e;
+	}
+	buf[t] = sum;
+	__syncthreads();
+	for (int s = BLOCK_SIZE / 2; s > 0; s >>= 1) {
+		if (t < s) buf[t] += buf[t + s];
+		__syncthreads();
+	}
+	sum = buf[0];
+	for (int i = t; i < n; i += BLOCK_SIZE) out[i] /= sum;
+}
+`
+
+// CUDADevice is a Device executing the operations on an NVIDIA GPU: the
+// matrix multiplications through cuBLAS, and the element-wise operations and
+// the softmax through kernels compiled at run time. It is only available when
+// building with the "cuda" tag.
+//
+// The results of the operations are kept in the GPU memory, so that the
+// operands of the next ones are not copied from the host again; they are
+// copied back to the host only when accessed by an operation executed on the
+// host. Operations whose operands have different sizes, and the matrix
+// inversion, are executed on the host.
+type CUDADevice struct {
+	mu      sync.Mutex
+	ordinal int
+	ctx     C.CUcontext
+	device  C.CUdevice
+	handle  C.cublasHandle_t
+	module  C.CUmodule
+	kernels map[string]C.CUfunction
+}
+
+var _ Device = &CUDADevice{}
+
+// NewCUDADevice returns a new CUDADevice for the GPU with the given ordinal.
+// Call Close to release the resources once the device is no longer needed.
+func NewCUDADevice(ordinal int) (*CUDADevice, error) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	d := &CUDADevice{ordinal: ordinal, kernels: map[string]C.CUfunction{}}
+	if err := cuError(C.cuInit(0)); err != nil {
+		return nil, err
+	}
+	if err := cuError(C.cuDeviceGet(&d.device, C.int(ordinal))); err != nil {
+		return nil, err
+	}
+	if err := cuError(C.cuDevicePrimaryCtxRetain(&d.ctx, d.device)); err != nil {
+		return nil, err
+	}
+	if err := cuError(C.cuCtxSetCurrent(d.ctx)); err != nil {
+		C.cuDevicePrimaryCtxRelease(d.device)
+		return nil, err
+	}
+	if status := C.cublasCreate(&d.handle); status != C.CUBLAS_STATUS_SUCCESS {
+		C.cuDevicePrimaryCtxRelease(d.device)
+		return nil, fmt.Errorf("mat32: cublasCreate failed with status %d", int(status))
+	}
+	if err := d.loadKernels(); err != nil {
+		C.cublasDestroy(d.handle)
+		C.cuDevicePrimaryCtxRelease(d.device)
+		return nil, err
+	}
+	return d, nil
+}
+
+// loadKernels compiles the element-wise kernels and loads them in the
+// current context.
+func (d *CUDADevice) loadKernels() error {
+	src := C.CString(cudaKernels)
+	defer C.free(unsafe.Pointer(src))
+	name := C.CString("kernels.cu")
+	defer C.free(unsafe.Pointer(name))
+
+	var prog C.nvrtcProgram
+	if res := C.nvrtcCreateProgram(&prog, src, name, 0, nil, nil); res != C.NVRTC_SUCCESS {
+		return fmt.Errorf("mat32: NVRTC error: %s", C.GoString(C.nvrtcGetErrorString(res)))
+	}
+	defer C.nvrtcDestroyProgram(&prog)
+	if res := C.nvrtcCompileProgram(prog, 0, nil); res != C.NVRTC_SUCCESS {
+		var logSize C.size_t
+		C.nvrtcGetProgramLogSize(prog, &logSize)
+		log := make([]byte, logSize)
+		if logSize > 0 {
+			C.nvrtcGetProgramLog(prog, (*C.char)(unsafe.Pointer(&log[0])))
+		}
+		return fmt.Errorf("mat32: NVRTC error: %s\n%s", C.GoString(C.nvrtcGetErrorString(res)), log)
+	}
+	var ptxSize C.size_t
+	C.nvrtcGetPTXSize(prog, &ptxSize)
+	ptx := make([]byte, ptxSize)
+	C.nvrtcGetPTX(prog, (*C.char)(unsafe.Pointer(&ptx[0])))
+
+	if err := cuError(C.cuModuleLoadData(&d.module, unsafe.Pointer(&ptx[0]))); err != nil {
+		return err
+	}
+	for _, kernel := range []string{"add", "sub", "prod", "div", "scale", "softmax"} {
+		cname := C.CString(kernel)
+		var fn C.CUfunction
+		err := cuError(C.cuModuleGetFunction(&fn, d.module, cname))
+		C.free(unsafe.Pointer(cname))
+		if err != nil {
+			C.cuModuleUnload(d.module)
+			return err
+		}
+		d.kernels[kernel] = fn
+	}
+	return nil
+}
+
+// Name returns the name of the device, in the form "cuda:<ordinal>".
+func (d *CUDADevice) Name() string {
+	return fmt.Sprintf("cuda:%d", d.ordinal)
+}
+
+// Close releases the resources allocated by the device. The values of the
+// matrices associated with the device must be copied back to the host (e.g.
+// by Data) before.
+func (d *CUDADevice) Close() error {
+	d.enter()
+	defer d.exit()
+	if status := C.cublasDestroy(d.handle); status != C.CUBLAS_STATUS_SUCCESS {
+		return fmt.Errorf("mat32: cublasDestroy failed with status %d", int(status))
+	}
+	if err := cuError(C.cuModuleUnload(d.module)); err != nil {
+		return err
+	}
+	return cuError(C.cuDevicePrimaryCtxRelease(d.device))
+}
+
+// enter locks the device and makes its context current on the calling
+// thread, which is locked until exit.
+func (d *CUDADevice) enter() {
+	d.mu.Lock()
+	runtime.LockOSThread()
+	if err := cuError(C.cuCtxSetCurrent(d.ctx)); err != nil {
+		runtime.UnlockOSThread()
+		d.mu.Unlock()
+		panic(err)
+	}
+}
+
+func (d *CUDADevice) exit() {
+	runtime.UnlockOSThread()
+	d.mu.Unlock()
+}
+
+// prepare copies back to the host the values of the views and of the
+// matrices associated with other devices, which are not read from the GPU
+// memory. It must be called before enter.
+func (d *CUDADevice) prepare(ms ...*Dense) {
+	for _, m := range ms {
+		if m.viewOf != nil || m.device != Device(d) {
+			m.sync()
+		}
+	}
+}
+
+// resident reports whether the values of the matrix are held by the GPU.
+func (d *CUDADevice) resident(m *Dense) bool {
+	return m.viewOf == nil && m.device == Device(d) && m.memory != nil && m.memory.handle != nil
+}
+
+// operand returns the GPU memory holding the values of the matrix, copying
+// them from the host if needed; in that case temporary is true, and the
+// memory must be freed after the operation.
+func (d *CUDADevice) operand(m *Dense) (ptr unsafe.Pointer, temporary bool) {
+	if d.resident(m) {
+		return m.memory.handle, false
+	}
+	return toGPU(m.data), true
+}
+
+// output returns the GPU memory where to store the values of out.
+func (d *CUDADevice) output(out *Dense) unsafe.Pointer {
+	if d.resident(out) {
+		return out.memory.handle
+	}
+	return allocGPU(out.size)
+}
+
+// store makes ptr the GPU memory holding the values of out. The values are
+// copied to the host instead when out is a view, or it's not associated with
+// this device.
+func (d *CUDADevice) store(out *Dense, ptr unsafe.Pointer) {
+	if out.viewOf != nil || out.device != Device(d) {
+		fromGPU(out.data, ptr)
+		C.cudaFree(ptr)
+		return
+	}
+	if out.memory == nil {
+		out.memory = &deviceMemory{}
+		runtime.SetFinalizer(out.memory, d.free)
+	}
+	if out.memory.handle != nil && out.memory.handle != ptr {
+		C.cudaFree(out.memory.handle)
+	}
+	out.memory.handle = ptr
+	atomic.StoreInt32(&out.memory.stale, 1)
+}
+
+// free releases the GPU memory of a matrix which is no longer referenced.
+func (d *CUDADevice) free(m *deviceMemory) {
+	if m.handle == nil {
+		return
+	}
+	d.enter()
+	defer d.exit()
+	if m.handle != nil {
+		C.cudaFree(m.handle)
+		m.handle = nil
+	}
+}
+
+func (d *CUDADevice) copyToHost(m *Dense) {
+	d.enter()
+	defer d.exit()
+	if m.memory.handle == nil {
+		return
+	}
+	fromGPU(m.data, m.memory.handle)
+	C.cudaFree(m.memory.handle)
+	m.memory.handle = nil
+	atomic.StoreInt32(&m.memory.stale, 0)
+}
+
+func (d *CUDADevice) release(m *Dense) {
+	d.free(m.memory)
+	atomic.StoreInt32(&m.memory.stale, 0)
+}
+
+// Mul performs the matrix multiplication a×b on the GPU, storing the result
+// in out. It panics if any CUDA or cuBLAS call fails.
+func (d *CUDADevice) Mul(a, b, out *Dense) {
+	if a.size == 0 || b.size == 0 {
+		return
+	}
+	d.prepare(a, b, out)
+	d.enter()
+	defer d.exit()
+
+	da, tmpA := d.operand(a)
+	if tmpA {
+		defer C.cudaFree(da)
+	}
+	db, tmpB := d.operand(b)
+	if tmpB {
+		defer C.cudaFree(db)
+	}
+	dc := d.output(out)
+
+	// cuBLAS expects column-major matrices: since a row-major matrix is
+	// the column-major representation of its transpose, C = AB is computed
+	// as Cᵀ = BᵀAᵀ.
+	alpha, beta := C.float(1.0), C.float(0.0)
+	status := C.cublasSgemm(
+		d.handle,
+		C.CUBLAS_OP_N,
+		C.CUBLAS_OP_N,
+		C.int(b.cols), // m
+		C.int(a.rows), // n
+		C.int(a.cols), // k
+		&alpha,
+		(*C.float)(db),
+		C.int(b.cols), // lda
+		(*C.float)(da),
+		C.int(a.cols), // ldb
+		&beta,
+		(*C.float)(dc),
+		C.int(out.cols), // ldc
+	)
+	if status != C.CUBLAS_STATUS_SUCCESS {
+		panic(fmt.Sprintf("mat32: cublasSgemm failed with status %d", int(status)))
+	}
+	d.store(out, dc)
+}
+
+// Inverse computes the inverse of the square matrix a on the host, storing
+// the result in out.
+func (d *CUDADevice) Inverse(a, out *Dense) {
+	syncAll(a, out)
+	GoBackend{}.Inverse(a, out)
+}
+
+// Add performs the element-wise addition a+b on the GPU, storing the result
+// in out.
+func (d *CUDADevice) Add(a, b, out *Dense) {
+	d.binary("add", a, b, out, hostDevice.Add)
+}
+
+// Sub performs the element-wise subtraction a-b on the GPU, storing the
+// result in out.
+func (d *CUDADevice) Sub(a, b, out *Dense) {
+	d.binary("sub", a, b, out, hostDevice.Sub)
+}
+
+// Prod performs the element-wise product a⊙b on the GPU, storing the result
+// in out.
+func (d *CUDADevice) Prod(a, b, out *Dense) {
+	d.binary("prod", a, b, out, hostDevice.Prod)
+}
+
+// Div performs the element-wise division a/b on the GPU, storing the result
+// in out.
+func (d *CUDADevice) Div(a, b, out *Dense) {
+	d.binary("div", a, b, out, hostDevice.Div)
+}
+
+// binary executes the kernel of an element-wise operation between a and b,
+// or the given host function if the sizes of the matrices are different.
+func (d *CUDADevice) binary(kernel string, a, b, out *Dense, host func(hostDevice, *Dense, *Dense, *Dense)) {
+	if a.size != b.size || a.size != out.size {
+		host(hostDevice{}, a, b, out)
+		return
+	}
+	if a.size == 0 {
+		return
+	}
+	d.prepare(a, b, out)
+	d.enter()
+	defer d.exit()
+
+	da, tmpA := d.operand(a)
+	if tmpA {
+		defer C.cudaFree(da)
+	}
+	db, tmpB := d.operand(b)
+	if tmpB {
+		defer C.cudaFree(db)
+	}
+	dc := d.output(out)
+	mustCU(C.launchBinary(d.kernels[kernel], C.int(a.size), da, db, dc))
+	d.store(out, dc)
+}
+
+// ProdScalar multiplies all the elements of a by n on the GPU, storing the
+// result in out.
+func (d *CUDADevice) ProdScalar(a *Dense, n Float, out *Dense) {
+	if a.size != out.size {
+		hostDevice{}.ProdScalar(a, n, out)
+		return
+	}
+	if a.size == 0 {
+		return
+	}
+	d.prepare(a, out)
+	d.enter()
+	defer d.exit()
+
+	da, tmpA := d.operand(a)
+	if tmpA {
+		defer C.cudaFree(da)
+	}
+	dc := d.output(out)
+	mustCU(C.launchScale(d.kernels["scale"], C.int(a.size), C.float(n), da, dc))
+	d.store(out, dc)
+}
+
+// Softmax computes the softmax of all the elements of a on the GPU, storing
+// the result in out.
+func (d *CUDADevice) Softmax(a, out *Dense) {
+	if a.size != out.size {
+		hostDevice{}.Softmax(a, out)
+		return
+	}
+	if a.size == 0 {
+		return
+	}
+	d.prepare(a, out)
+	d.enter()
+	defer d.exit()
+
+	da, tmpA := d.operand(a)
+	if tmpA {
+		defer C.cudaFree(da)
+	}
+	dc := d.output(out)
+	mustCU(C.launchSoftmax(d.kernels["softmax"], C.int(a.size), da, dc))
+	d.store(out, dc)
+}
+
+func allocGPU(size int) unsafe.Pointer {
+	var ptr unsafe.Pointer
+	mustCUDA(C.cudaMalloc(&ptr, C.size_t(size*C.sizeof_float)))
+	return ptr
+}
+
+func toGPU(data []Float) unsafe.Pointer {
+	ptr := allocGPU(len(data))
+	mustCUDA(C.cudaMemcpy(ptr, unsafe.Pointer(&data[0]), C.size_t(len(data)*C.sizeof_float), C.cudaMemcpyHostToDevice))
+	return ptr
+}
+
+func fromGPU(dst []Float, ptr unsafe.Pointer) {
+	mustCUDA(C.cudaMemcpy(unsafe.Pointer(&dst[0]), ptr, C.size_t(len(dst)*C.sizeof_float), C.cudaMemcpyDeviceToHost))
+}
+
+func cudaError(err C.cudaError_t) error {
+	if err == C.cudaSuccess {
+		return nil
+	}
+	return fmt.Errorf("mat32: CUDA error: %s", C.GoString(C.cudaGetErrorString(err)))
+}
+
+func mustCUDA(err C.cudaError_t) {
+	if e := cudaError(err); e != nil {
+		panic(e)
+	}
+}
+
+func cuError(res C.CUresult) error {
+	if res == C.CUDA_SUCCESS {
+		return nil
+	}
+	var msg *C.char
+	C.cuGetErrorString(res, &msg)
+	return fmt.Errorf("mat32: CUDA error: %s", C.GoString(msg))
+}
+
+func mustCU(res C.CUresult) {
+	if err := cuError(res); err != nil {
+		panic(err)
+	}
+}
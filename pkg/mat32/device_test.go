@@ -0,0 +1,246 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mat32
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"unsafe"
+)
+
+// fakeDevice executes the operations on the host, counting them.
+type fakeDevice struct {
+	hostDevice
+	ops map[string]int
+}
+
+func newFakeDevice() *fakeDevice {
+	return &fakeDevice{ops: map[string]int{}}
+}
+
+func (d *fakeDevice) Name() string {
+	return "fake"
+}
+
+func (d *fakeDevice) Mul(a, b, out *Dense) {
+	d.ops["Mul"]++
+	d.hostDevice.Mul(a, b, out)
+}
+
+func (d *fakeDevice) Inverse(a, out *Dense) {
+	d.ops["Inverse"]++
+	d.hostDevice.Inverse(a, out)
+}
+
+func (d *fakeDevice) Add(a, b, out *Dense) {
+	d.ops["Add"]++
+	d.hostDevice.Add(a, b, out)
+}
+
+func (d *fakeDevice) Sub(a, b, out *Dense) {
+	d.ops["Sub"]++
+	d.hostDevice.Sub(a, b, out)
+}
+
+func (d *fakeDevice) Prod(a, b, out *Dense) {
+	d.ops["Prod"]++
+	d.hostDevice.Prod(a, b, out)
+}
+
+func (d *fakeDevice) Div(a, b, out *Dense) {
+	d.ops["Div"]++
+	d.hostDevice.Div(a, b, out)
+}
+
+func (d *fakeDevice) ProdScalar(a *Dense, n Float, out *Dense) {
+	d.ops["ProdScalar"]++
+	d.hostDevice.ProdScalar(a, n, out)
+}
+
+func (d *fakeDevice) Softmax(a, out *Dense) {
+	d.ops["Softmax"]++
+	d.hostDevice.Softmax(a, out)
+}
+
+// fakeResidentDevice keeps the results of its operations in its own
+// "memory" (Go slices), counting the transfers from and to the host.
+type fakeResidentDevice struct {
+	fakeDevice
+	uploads, downloads, releases int
+}
+
+func newFakeResidentDevice() *fakeResidentDevice {
+	return &fakeResidentDevice{fakeDevice: fakeDevice{ops: map[string]int{}}}
+}
+
+func (d *fakeResidentDevice) operand(m *Dense) []Float {
+	if m.memory != nil && m.memory.handle != nil {
+		return *(*[]Float)(m.memory.handle)
+	}
+	d.uploads++
+	m.sync()
+	return append([]Float(nil), m.data...)
+}
+
+func (d *fakeResidentDevice) store(out *Dense, values []Float) {
+	if out.memory == nil {
+		out.memory = &deviceMemory{}
+	}
+	out.memory.handle = unsafe.Pointer(&values)
+	out.memory.stale = 1
+}
+
+func (d *fakeResidentDevice) elementWise(a, b, out *Dense, f func(x, y Float) Float) {
+	x, y := d.operand(a), d.operand(b)
+	values := make([]Float, len(x))
+	for i := range values {
+		values[i] = f(x[i], y[i])
+	}
+	d.store(out, values)
+}
+
+func (d *fakeResidentDevice) Add(a, b, out *Dense) {
+	d.ops["Add"]++
+	d.elementWise(a, b, out, func(x, y Float) Float { return x + y })
+}
+
+func (d *fakeResidentDevice) Prod(a, b, out *Dense) {
+	d.ops["Prod"]++
+	d.elementWise(a, b, out, func(x, y Float) Float { return x * y })
+}
+
+func (d *fakeResidentDevice) ProdScalar(a *Dense, n Float, out *Dense) {
+	d.ops["ProdScalar"]++
+	x := d.operand(a)
+	values := make([]Float, len(x))
+	for i, v := range x {
+		values[i] = v * n
+	}
+	d.store(out, values)
+}
+
+func (d *fakeResidentDevice) copyToHost(m *Dense) {
+	d.downloads++
+	copy(m.data, *(*[]Float)(m.memory.handle))
+	m.memory.handle = nil
+	m.memory.stale = 0
+}
+
+func (d *fakeResidentDevice) release(m *Dense) {
+	d.releases++
+	m.memory.handle = nil
+}
+
+func TestDense_ToDevice(t *testing.T) {
+	dev := newFakeDevice()
+	a := NewDense(2, 2, []Float{1, 2, 3, 4})
+	assert.Equal(t, Host, a.Device())
+
+	b := a.ToDevice(dev)
+	assert.Equal(t, Device(dev), b.Device())
+	assert.Equal(t, a.Data(), b.Data())
+	assert.Equal(t, Device(dev), b.Clone().(*Dense).Device())
+
+	c := b.Mul(NewDense(2, 2, []Float{1, 0, 0, 1})).(*Dense)
+	assertSliceEqualApprox(t, []Float{1, 2, 3, 4}, c.Data())
+	assert.Equal(t, 1, dev.ops["Mul"])
+	assert.Equal(t, Device(dev), c.Device())
+
+	inv := b.Inverse().(*Dense)
+	assertSliceEqualApprox(t, []Float{-2, 1, 1.5, -0.5}, inv.Data())
+	assert.Equal(t, 1, dev.ops["Inverse"])
+	assert.Equal(t, Device(dev), inv.Device())
+
+	h := c.ToHost()
+	assert.Equal(t, Host, h.Device())
+	h.Mul(NewDense(2, 2, []Float{1, 0, 0, 1}))
+	assert.Equal(t, 1, dev.ops["Mul"])
+}
+
+func TestDense_DeviceOperations(t *testing.T) {
+	dev := newFakeDevice()
+	a := NewDense(2, 2, []Float{1, 2, 3, 4}).ToDevice(dev)
+	b := NewDense(2, 2, []Float{2, 2, 4, 8})
+
+	outputs := []Matrix{
+		a.Add(b),
+		a.Sub(b),
+		a.Prod(b),
+		a.Div(b),
+		a.ProdScalar(2),
+		a.Softmax(),
+		a.ZerosLike(),
+		a.OnesLike(),
+		a.T(),
+		a.Reshape(4, 1),
+		a.ExtractRow(0),
+		a.ExtractColumn(0),
+		a.Range(0, 2),
+		a.Abs(),
+		a.Pow(2),
+		a.Sqrt(),
+		a.Maximum(b),
+		a.Minimum(b),
+		a.MatMulT(b),
+		a.TMatMul(b),
+	}
+	for i, out := range outputs {
+		assert.Equal(t, Device(dev), out.(*Dense).Device(), "output %d", i)
+	}
+	assert.Equal(t, map[string]int{
+		"Add": 1, "Sub": 1, "Prod": 1, "Div": 1, "ProdScalar": 1, "Softmax": 1, "Mul": 2,
+	}, dev.ops)
+
+	assertSliceEqualApprox(t, []Float{3, 4, 7, 12}, outputs[0].Data())
+	assertSliceEqualApprox(t, []Float{0.5, 1, 0.75, 0.5}, outputs[3].Data())
+	assertSliceEqualApprox(t, []Float{0.0320586, 0.0871443, 0.2368828, 0.6439142}, outputs[5].Data())
+	assertSliceEqualApprox(t, []Float{6, 20, 14, 44}, outputs[18].Data())
+
+	a.AddInPlace(b)
+	a.SubInPlace(b)
+	a.ProdInPlace(b)
+	a.DivInPlace(b)
+	a.ProdScalarInPlace(2)
+	a.ProdMatrixScalarInPlace(b, 0.5)
+	assert.Equal(t, 2, dev.ops["Add"])
+	assert.Equal(t, 2, dev.ops["Sub"])
+	assert.Equal(t, 2, dev.ops["Prod"])
+	assert.Equal(t, 2, dev.ops["Div"])
+	assert.Equal(t, 3, dev.ops["ProdScalar"])
+	assertSliceEqualApprox(t, []Float{1, 1, 2, 4}, a.Data())
+}
+
+func TestDense_DeviceResidency(t *testing.T) {
+	dev := newFakeResidentDevice()
+	a := NewDense(2, 2, []Float{1, 2, 3, 4}).ToDevice(dev)
+	b := NewDense(2, 2, []Float{2, 2, 4, 8}).ToDevice(dev)
+
+	c := a.Add(b).(*Dense)
+	d := c.Prod(c).(*Dense)
+	d.ProdScalarInPlace(0.5)
+	assert.Equal(t, 2, dev.uploads) // a and b, but not the intermediate results
+	assert.Equal(t, 0, dev.downloads)
+
+	assert.Equal(t, []Float{4.5, 8, 24.5, 72}, d.Data())
+	assert.Equal(t, 1, dev.downloads)
+	assert.Equal(t, Float(109), d.Sum())
+	assert.Equal(t, 1, dev.downloads)
+
+	assert.Equal(t, Float(3), c.At(0, 0))
+	assert.Equal(t, 2, dev.downloads)
+
+	e := c.ProdScalar(2).(*Dense)
+	assert.Equal(t, 3, dev.uploads) // c is held by the host again
+	ReleaseDense(e)
+	assert.Equal(t, 1, dev.releases)
+	assert.Nil(t, GetDenseWorkspace(2, 2).memory)
+}
+
+func TestGetDenseWorkspace_ResetsDevice(t *testing.T) {
+	d := NewEmptyDense(3, 3).ToDevice(newFakeDevice())
+	ReleaseDense(d)
+	assert.Equal(t, Host, GetDenseWorkspace(3, 3).Device())
+	assert.Equal(t, Host, GetEmptyDenseWorkspace(3, 3).Device())
+}
@@ -7,6 +7,7 @@ package mat32
 import (
 	"encoding/binary"
 	"encoding/gob"
+	"errors"
 	"fmt"
 	"io"
 	"math"
@@ -19,6 +20,7 @@ func init() {
 
 // MarshalBinary marshals a Dense matrix into binary form.
 func (d Dense) MarshalBinary() ([]byte, error) {
+	d.sync()
 	data := make([]byte, 8+d.size*4)
 	binary.LittleEndian.PutUint32(data, uint32(d.rows))
 	binary.LittleEndian.PutUint32(data[4:], uint32(d.cols))
@@ -65,6 +67,40 @@ func (s *Sparse) UnmarshalBinary(data []byte) error {
 	return nil
 }
 
+// MarshalBinary marshals a Float16Dense matrix into binary form, keeping the
+// values in its 16-bit format.
+func (d Float16Dense) MarshalBinary() ([]byte, error) {
+	data := make([]byte, 9+len(d.data)*2)
+	binary.LittleEndian.PutUint32(data, uint32(d.rows))
+	binary.LittleEndian.PutUint32(data[4:], uint32(d.cols))
+	data[8] = byte(d.format)
+	for i, v := range d.data {
+		binary.LittleEndian.PutUint16(data[9+i*2:], v)
+	}
+	return data, nil
+}
+
+// UnmarshalBinary unmarshals a binary representation of a Float16Dense matrix.
+func (d *Float16Dense) UnmarshalBinary(data []byte) error {
+	if len(data) < 9 {
+		return errors.New("mat32: invalid Float16Dense encoding")
+	}
+	d.rows = int(binary.LittleEndian.Uint32(data))
+	d.cols = int(binary.LittleEndian.Uint32(data[4:]))
+	d.format = HalfPrecision(data[8])
+	if d.format != Float16 && d.format != BFloat16 {
+		return fmt.Errorf("mat32: unknown half precision format %d", d.format)
+	}
+	if len(data) != 9+d.rows*d.cols*2 {
+		return errors.New("mat32: invalid Float16Dense encoding")
+	}
+	d.data = make([]uint16, d.rows*d.cols)
+	for i := range d.data {
+		d.data[i] = binary.LittleEndian.Uint16(data[9+i*2:])
+	}
+	return nil
+}
+
 const (
 	binaryNilMatrix byte = iota
 	binaryDenseMatrix
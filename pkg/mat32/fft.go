@@ -0,0 +1,188 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mat32
+
+import "math"
+
+// Complex is the complex type of the FFT functions of the mat32 package. It
+// is an alias for complex64.
+type Complex = complex64
+
+// FFT returns the discrete Fourier transform of x:
+// y[k] = sum_j x[j] * exp(-2πi jk/n).
+// The lengths which are powers of two are computed with the radix-2
+// Cooley-Tukey algorithm, the other ones with the Bluestein algorithm: the
+// cost is always O(n log n).
+func FFT(x []Complex) []Complex {
+	y := make([]Complex, len(x))
+	copy(y, x)
+	fft(y, false)
+	return y
+}
+
+// IFFT returns the inverse discrete Fourier transform of x, normalized so
+// that IFFT(FFT(x)) = x.
+func IFFT(x []Complex) []Complex {
+	y := make([]Complex, len(x))
+	copy(y, x)
+	fft(y, true)
+	scale := 1 / Float(len(y))
+	for i, v := range y {
+		y[i] = complex(real(v)*scale, imag(v)*scale)
+	}
+	return y
+}
+
+// RFFT returns the first n/2+1 elements of the n-points discrete Fourier
+// transform of the real signal x, padded with zeros to the length n (the
+// other elements are their complex conjugates).
+// It panics if n is less than the length of x.
+func RFFT(x []Float, n int) []Complex {
+	if n < len(x) {
+		panic("mat32: the length of the FFT is shorter than the signal")
+	}
+	a := make([]Complex, n)
+	for i, v := range x {
+		a[i] = complex(v, 0)
+	}
+	fft(a, false)
+	return a[:n/2+1]
+}
+
+// IRFFT returns the real signal of length n whose RFFT is x, which must
+// contain n/2+1 elements.
+func IRFFT(x []Complex, n int) []Float {
+	if len(x) != n/2+1 {
+		panic("mat32: the spectrum must contain n/2+1 elements")
+	}
+	a := make([]Complex, n)
+	copy(a, x)
+	for k := 1; k < (n+1)/2; k++ {
+		a[n-k] = conj(x[k])
+	}
+	fft(a, true)
+	y := make([]Float, n)
+	for i, v := range a {
+		y[i] = real(v) / Float(n)
+	}
+	return y
+}
+
+// Convolve returns the (full) linear convolution of a and b, of length
+// len(a)+len(b)-1, computed in the frequency domain:
+// y[k] = sum_j a[j] * b[k-j].
+func Convolve(a, b []Float) []Float {
+	if len(a) == 0 || len(b) == 0 {
+		return []Float{}
+	}
+	length := len(a) + len(b) - 1
+	n := NextPowerOfTwo(length)
+	fa, fb := RFFT(a, n), RFFT(b, n)
+	for i, v := range fb {
+		fa[i] *= v
+	}
+	return IRFFT(fa, n)[:length]
+}
+
+// NextPowerOfTwo returns the smallest power of two not less than n (and at
+// least 1), the most efficient length of an FFT of a signal of length n.
+func NextPowerOfTwo(n int) int {
+	p := 1
+	for p < n {
+		p <<= 1
+	}
+	return p
+}
+
+func conj(c Complex) Complex {
+	return complex(real(c), -imag(c))
+}
+
+// fft computes the unnormalized (inverse) discrete Fourier transform of a in
+// place.
+func fft(a []Complex, inverse bool) {
+	n := len(a)
+	switch {
+	case n <= 1:
+		return
+	case n&(n-1) == 0:
+		radix2FFT(a, inverse)
+	default:
+		bluesteinFFT(a, inverse)
+	}
+}
+
+// radix2FFT computes the unnormalized (inverse) discrete Fourier transform of
+// a in place, with the iterative radix-2 Cooley-Tukey algorithm. The length of
+// a must be a power of two.
+func radix2FFT(a []Complex, inverse bool) {
+	n := len(a)
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			a[i], a[j] = a[j], a[i]
+		}
+	}
+	sign := -1.0
+	if inverse {
+		sign = 1.0
+	}
+	twiddles := make([]Complex, n/2)
+	for size := 2; size <= n; size <<= 1 {
+		half := size / 2
+		for k := range twiddles[:half] {
+			sin, cos := math.Sincos(sign * 2 * math.Pi * float64(k) / float64(size))
+			twiddles[k] = complex(Float(cos), Float(sin))
+		}
+		for start := 0; start < n; start += size {
+			for k, w := range twiddles[:half] {
+				u, v := a[start+k], a[start+k+half]*w
+				a[start+k], a[start+k+half] = u+v, u-v
+			}
+		}
+	}
+}
+
+// bluesteinFFT computes the unnormalized (inverse) discrete Fourier transform
+// of a in place, of any length, expressing it as a convolution computed with
+// radix-2 FFTs.
+func bluesteinFFT(a []Complex, inverse bool) {
+	n := len(a)
+	sign := -1.0
+	if inverse {
+		sign = 1.0
+	}
+	// chirp[k] = exp(±πi k²/n); k² is reduced modulo 2n to keep the precision
+	chirp := make([]Complex, n)
+	for k := range chirp {
+		sin, cos := math.Sincos(sign * math.Pi * float64((k*k)%(2*n)) / float64(n))
+		chirp[k] = complex(Float(cos), Float(sin))
+	}
+	m := NextPowerOfTwo(2*n - 1)
+	x := make([]Complex, m)
+	y := make([]Complex, m)
+	for k, c := range chirp {
+		x[k] = a[k] * c
+	}
+	y[0] = conj(chirp[0])
+	for k := 1; k < n; k++ {
+		y[k] = conj(chirp[k])
+		y[m-k] = y[k]
+	}
+	radix2FFT(x, false)
+	radix2FFT(y, false)
+	for i := range x {
+		x[i] *= y[i]
+	}
+	radix2FFT(x, true)
+	scale := 1 / Float(m)
+	for k, c := range chirp {
+		a[k] = x[k] * c * complex(scale, 0)
+	}
+}
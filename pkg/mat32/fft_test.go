@@ -0,0 +1,88 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mat32
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// naiveDFT computes the discrete Fourier transform by definition.
+func naiveDFT(x []Complex) []Complex {
+	n := len(x)
+	y := make([]Complex, n)
+	for k := range y {
+		var re, im float64
+		for j, v := range x {
+			sin, cos := math.Sincos(-2 * math.Pi * float64(j*k) / float64(n))
+			re += float64(real(v))*cos - float64(imag(v))*sin
+			im += float64(real(v))*sin + float64(imag(v))*cos
+		}
+		y[k] = complex(Float(re), Float(im))
+	}
+	return y
+}
+
+func testSignal(n int) []Complex {
+	x := make([]Complex, n)
+	for i := range x {
+		x[i] = complex(Float(math.Sin(float64(i)*0.7)+0.1*float64(i)), Float(math.Cos(float64(i)*1.3)))
+	}
+	return x
+}
+
+func assertComplexInDelta(t *testing.T, expected, actual []Complex, delta float64) {
+	t.Helper()
+	if !assert.Len(t, actual, len(expected)) {
+		return
+	}
+	for i := range expected {
+		assert.InDelta(t, real(expected[i]), real(actual[i]), delta, "real part of element %d", i)
+		assert.InDelta(t, imag(expected[i]), imag(actual[i]), delta, "imaginary part of element %d", i)
+	}
+}
+
+func TestFFT(t *testing.T) {
+	for _, n := range []int{0, 1, 2, 5, 8, 12, 16, 31} {
+		x := testSignal(n)
+		y := FFT(x)
+		assertComplexInDelta(t, naiveDFT(x), y, 1.0e-3)
+		assertComplexInDelta(t, x, IFFT(y), 1.0e-4)
+	}
+	x := testSignal(4)
+	_ = FFT(x)
+	assert.Equal(t, testSignal(4), x, "the input is not modified")
+}
+
+func TestRFFT(t *testing.T) {
+	x := []Float{1, 2, 3, 4, 5}
+	for _, n := range []int{5, 6, 8} {
+		c := make([]Complex, n)
+		for i, v := range x {
+			c[i] = complex(v, 0)
+		}
+		y := RFFT(x, n)
+		assertComplexInDelta(t, naiveDFT(c)[:n/2+1], y, 1.0e-4)
+		assert.InDeltaSlice(t, append(x, make([]Float, n-len(x))...), IRFFT(y, n), 1.0e-5)
+	}
+	assert.Panics(t, func() { RFFT(x, 4) })
+	assert.Panics(t, func() { IRFFT(make([]Complex, 3), 8) })
+}
+
+func TestConvolve(t *testing.T) {
+	a := []Float{1, 2, 3}
+	b := []Float{0.5, -1, 2, 0.25}
+	assert.InDeltaSlice(t, []Float{0.5, 0, 1.5, 1.25, 6.5, 0.75}, Convolve(a, b), 1.0e-5)
+	assert.Empty(t, Convolve(a, nil))
+}
+
+func TestNextPowerOfTwo(t *testing.T) {
+	assert.Equal(t, 1, NextPowerOfTwo(0))
+	assert.Equal(t, 1, NextPowerOfTwo(1))
+	assert.Equal(t, 8, NextPowerOfTwo(5))
+	assert.Equal(t, 8, NextPowerOfTwo(8))
+}
@@ -0,0 +1,60 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package floatutils
+
+import (
+	"testing"
+
+	"github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDotSimilarity(t *testing.T) {
+	assert.InDelta(t, 3, DotSimilarity([]float32{1, 2, 3}, []float32{0.4, -0.5, 1.2}), 1.0e-6)
+	assert.Panics(t, func() { DotSimilarity([]float32{1, 2}, []float32{1}) })
+}
+
+func TestCosineSimilarity(t *testing.T) {
+	assert.InDelta(t, 1, CosineSimilarity([]float32{1, 2, 3}, []float32{2, 4, 6}), 1.0e-6)
+	assert.InDelta(t, -1, CosineSimilarity([]float32{1, 2, 3}, []float32{-1, -2, -3}), 1.0e-6)
+	assert.InDelta(t, 0, CosineSimilarity([]float32{1, 0}, []float32{0, 5}), 1.0e-6)
+	assert.Equal(t, float32(0), CosineSimilarity([]float32{0, 0}, []float32{1, 2}))
+}
+
+func TestEuclideanDistance(t *testing.T) {
+	assert.InDelta(t, 5, EuclideanDistance([]float32{1, 2}, []float32{4, 6}), 1.0e-6)
+	assert.InDelta(t, 25, SquaredEuclideanDistance([]float32{1, 2}, []float32{4, 6}), 1.0e-6)
+	assert.Panics(t, func() { EuclideanDistance([]float32{1, 2}, []float32{1}) })
+}
+
+func TestPairwiseDistances(t *testing.T) {
+	vectors := [][]float32{{0, 0}, {3, 4}, {6, 8}}
+	assert.Equal(t, [][]float32{
+		{0, 5, 10},
+		{5, 0, 5},
+		{10, 5, 0},
+	}, PairwiseDistances(vectors, EuclideanDistance))
+}
+
+func TestPairwiseCosineSimilarities(t *testing.T) {
+	vectors := [][]float32{{1, 0}, {1, 1}, {0, 0}, {-2, 0}}
+	actual := PairwiseCosineSimilarities(vectors)
+	for i, x := range vectors {
+		for j, y := range vectors {
+			assert.InDelta(t, CosineSimilarity(x, y), actual[i][j], 1.0e-6)
+		}
+	}
+}
+
+func TestPairwiseRowDistances(t *testing.T) {
+	m := mat32.NewDense(3, 2, []float32{0, 0, 3, 4, 6, 8})
+	rows := Rows(m)
+	assert.Equal(t, [][]float32{{0, 0}, {3, 4}, {6, 8}}, rows)
+	rows[1][0] = 3 // the rows share the data of the matrix
+	assert.Equal(t, float32(3), m.At(1, 0))
+
+	actual := PairwiseRowDistances(m, EuclideanDistance)
+	assert.Equal(t, []float32{0, 5, 10, 5, 0, 5, 10, 5, 0}, actual.Data())
+}
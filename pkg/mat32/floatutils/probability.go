@@ -0,0 +1,39 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package floatutils
+
+import (
+	"math"
+	"sort"
+)
+
+// Entropy returns the entropy (in nats) of the given probability
+// distribution. The zero probabilities don't contribute to it.
+func Entropy(p []float32) float32 {
+	var h float64
+	for _, e := range p {
+		if e > 0 {
+			h -= float64(e) * math.Log(float64(e))
+		}
+	}
+	return float32(h)
+}
+
+// ArgMaxK returns the indices of the k greatest values of the given slice,
+// in descending order of value (the ties in order of index). If k is greater
+// than the length of the slice, all the indices are returned.
+func ArgMaxK(v []float32, k int) []int {
+	indices := make([]int, len(v))
+	for i := range indices {
+		indices[i] = i
+	}
+	sort.SliceStable(indices, func(i, j int) bool {
+		return v[indices[i]] > v[indices[j]]
+	})
+	if k < len(indices) {
+		indices = indices[:k]
+	}
+	return indices
+}
@@ -0,0 +1,25 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package floatutils
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEntropy(t *testing.T) {
+	assert.InDelta(t, 0, Entropy([]float32{0, 1, 0}), 1.0e-6)
+	assert.InDelta(t, math.Log(2), Entropy([]float32{0.5, 0, 0.5}), 1.0e-6)
+	assert.InDelta(t, math.Log(4), Entropy([]float32{0.25, 0.25, 0.25, 0.25}), 1.0e-6)
+}
+
+func TestArgMaxK(t *testing.T) {
+	v := []float32{0.1, 0.4, 0.2, 0.4, -1}
+	assert.Equal(t, []int{1, 3}, ArgMaxK(v, 2))
+	assert.Equal(t, []int{1, 3, 2, 0, 4}, ArgMaxK(v, 10))
+	assert.Equal(t, []int{}, ArgMaxK(v, 0))
+}
@@ -142,10 +142,19 @@ func Dgemm(tA, tB Transpose, m, n, k int, alpha float32, a []float32, lda int, b
 		}
 	}
 
-	dgemmParallel(aTrans, bTrans, m, n, k, a, lda, b, ldb, c, ldc, alpha)
+	dgemmParallel(aTrans, bTrans, m, n, k, a, lda, b, ldb, c, ldc, alpha, runtime.GOMAXPROCS(0))
 }
 
-func dgemmParallel(aTrans, bTrans bool, m, n, k int, a []float32, lda int, b []float32, ldb int, c []float32, ldc int, alpha float32) {
+// DgemmParallel is a parallel matrix multiply, which uses at most the given
+// number of worker goroutines on blockSize×blockSize sub-blocks of c.
+func DgemmParallel(aTrans, bTrans bool, m, n, k int, a []float32, lda int, b []float32, ldb int, c []float32, ldc int, alpha float32, workers int) {
+	if workers < 1 {
+		workers = 1
+	}
+	dgemmParallel(aTrans, bTrans, m, n, k, a, lda, b, ldb, c, ldc, alpha, workers)
+}
+
+func dgemmParallel(aTrans, bTrans bool, m, n, k int, a []float32, lda int, b []float32, ldb int, c []float32, ldc int, alpha float32, workers int) {
 	// dgemmParallel computes a parallel matrix multiplication by partitioning
 	// a and b into sub-blocks, and updating c with the multiplication of the sub-block
 	// In all cases,
@@ -177,16 +186,15 @@ func dgemmParallel(aTrans, bTrans bool, m, n, k int, a []float32, lda int, b []f
 
 	maxKLen := k
 	parBlocks := blocks(m, blockSize) * blocks(n, blockSize)
-	if parBlocks < minParBlock {
+	if parBlocks < minParBlock || workers == 1 {
 		// The matrix multiplication is small in the dimensions where it can be
 		// computed concurrently. Just do it in serial.
 		DgemmSerial(aTrans, bTrans, m, n, k, a, lda, b, ldb, c, ldc, alpha)
 		return
 	}
 
-	// workerLimit acts a number of maximum concurrent workers,
-	// with the limit set to the number of procs available.
-	workerLimit := make(chan struct{}, runtime.GOMAXPROCS(0))
+	// workerLimit acts a number of maximum concurrent workers.
+	workerLimit := make(chan struct{}, workers)
 
 	// wg is used to wait for all
 	var wg sync.WaitGroup
@@ -0,0 +1,39 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mat32
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLayerNorm(t *testing.T) {
+	x := []Float{0.4, 0.8, -0.7, -0.5}
+	w := []Float{0.4, 0.0, -0.3, 0.8}
+	b := []Float{0.9, 0.2, -0.9, 0.2}
+	y := make([]Float, 4)
+	xHat := make([]Float, 4)
+	std := LayerNorm(x, w, b, 1e-12, y, xHat)
+	assert.InDeltaSlice(t, []Float{1.157863, 0.2, -0.561554, -0.444658}, y, 1.0e-6)
+
+	gx := make([]Float, 4)
+	LayerNormBackward([]Float{-1.0, -0.2, 0.4, 0.6}, w, xHat, std, gx)
+	assert.InDeltaSlice(t, []Float{-0.496261, 0.280677, -0.408772, 0.624355}, gx, 1.0e-5)
+}
+
+func TestSoftmaxCrossEntropy(t *testing.T) {
+	logits := []Float{0.1, 0.2, 0.3, 1000}
+	loss, logSumExp := SoftmaxCrossEntropy(logits, 1)
+	assert.InDelta(t, 999.8, loss, 1.0e-3)
+
+	gx := make([]Float, 4)
+	SoftmaxCrossEntropyBackward(logits, 1, logSumExp, 0.5, gx)
+	assert.InDeltaSlice(t, []Float{0, -0.5, 0, 0.5}, gx, 1.0e-6)
+
+	logits = []Float{-0.2, 0.5, 0.1}
+	loss, _ = SoftmaxCrossEntropy(logits, 2)
+	assert.InDelta(t, 1.1733, loss, 1.0e-5)
+}
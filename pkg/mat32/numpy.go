@@ -0,0 +1,213 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mat32
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unsafe"
+)
+
+// floatSize is the size in bytes of the Float type.
+const floatSize = int(unsafe.Sizeof(Float(0)))
+
+// littleEndianHost reports whether the host stores the numbers in
+// little-endian byte order.
+var littleEndianHost = func() bool {
+	x := uint16(1)
+	return *(*byte)(unsafe.Pointer(&x)) == 1
+}()
+
+var numPyMagic = []byte("\x93NUMPY")
+
+var (
+	numPyDescr        = regexp.MustCompile(`'descr'\s*:\s*'([<>|=])([a-z])(\d+)'`)
+	numPyFortranOrder = regexp.MustCompile(`'fortran_order'\s*:\s*(True|False)`)
+	numPyShape        = regexp.MustCompile(`'shape'\s*:\s*\(([^)]*)\)`)
+)
+
+// WriteNumPy writes the matrix in the NumPy .npy format (version 1.0), as a
+// 2-D array of the Float type in C order (e.g. a column vector of size n is
+// written with shape (n, 1)), which can be loaded with numpy.load().
+func (d *Dense) WriteNumPy(w io.Writer) error {
+	d.sync()
+	header := fmt.Sprintf("{'descr': '<f%d', 'fortran_order': False, 'shape': (%d, %d), }",
+		floatSize, d.rows, d.cols)
+	// the header is padded so that the data is aligned to 64 bytes
+	prefix := len(numPyMagic) + 4
+	header += strings.Repeat(" ", (64-(prefix+len(header)+1)%64)%64) + "\n"
+	buf := make([]byte, prefix+len(header)+d.size*floatSize)
+	copy(buf, numPyMagic)
+	buf[6], buf[7] = 1, 0
+	binary.LittleEndian.PutUint16(buf[8:], uint16(len(header)))
+	copy(buf[prefix:], header)
+	putFloats(buf[prefix+len(header):], d.data, floatSize, binary.LittleEndian)
+	_, err := w.Write(buf)
+	return err
+}
+
+// ReadNumPy reads a matrix in the NumPy .npy format (see DecodeNumPy).
+func ReadNumPy(r io.Reader) (*Dense, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeNumPy(data)
+}
+
+// DecodeNumPy decodes a matrix from the content of a file in the NumPy .npy
+// format (e.g. written by numpy.save()). The array must be of float32 or
+// float64 values, converted to Float, in any byte order and in C or Fortran
+// order. The 0-D arrays are decoded as 1×1 matrices, and the 1-D arrays as
+// column vectors.
+//
+// When the array is of the Float type in the byte order of the host, in C
+// order, and its data is suitably aligned in memory, the returned matrix
+// shares the memory of data (zero-copy), which must not be modified
+// afterwards; such a matrix doesn't come from the workspace, so it must not
+// be released.
+func DecodeNumPy(data []byte) (*Dense, error) {
+	if len(data) < 10 || !bytes.Equal(data[:6], numPyMagic) {
+		return nil, errors.New("mat32: invalid NumPy format")
+	}
+	var headerLen, offset int
+	switch data[6] {
+	case 1:
+		headerLen, offset = int(binary.LittleEndian.Uint16(data[8:])), 10
+	case 2, 3:
+		if len(data) < 12 {
+			return nil, errors.New("mat32: invalid NumPy format")
+		}
+		headerLen, offset = int(binary.LittleEndian.Uint32(data[8:])), 12
+	default:
+		return nil, fmt.Errorf("mat32: unsupported NumPy format version %d.%d", data[6], data[7])
+	}
+	if len(data) < offset+headerLen {
+		return nil, errors.New("mat32: truncated NumPy header")
+	}
+	header := string(data[offset : offset+headerLen])
+
+	descr := numPyDescr.FindStringSubmatch(header)
+	if descr == nil {
+		return nil, errors.New("mat32: missing NumPy data type")
+	}
+	size, _ := strconv.Atoi(descr[3])
+	if descr[2] != "f" || (size != 4 && size != 8) {
+		return nil, fmt.Errorf("mat32: unsupported NumPy data type %q", descr[1]+descr[2]+descr[3])
+	}
+	var order binary.ByteOrder = binary.LittleEndian
+	if descr[1] == ">" || (descr[1] == "=" && !littleEndianHost) {
+		order = binary.BigEndian
+	}
+	fortranOrder := numPyFortranOrder.FindStringSubmatch(header)
+	if fortranOrder == nil {
+		return nil, errors.New("mat32: missing NumPy array order")
+	}
+	shapeMatch := numPyShape.FindStringSubmatch(header)
+	if shapeMatch == nil {
+		return nil, errors.New("mat32: missing NumPy array shape")
+	}
+	var shape []int
+	for _, s := range strings.Split(shapeMatch[1], ",") {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("mat32: invalid NumPy array shape (%s)", shapeMatch[1])
+		}
+		shape = append(shape, n)
+	}
+	rows, cols := 1, 1
+	switch len(shape) {
+	case 0:
+	case 1:
+		rows = shape[0]
+	case 2:
+		rows, cols = shape[0], shape[1]
+	default:
+		return nil, fmt.Errorf("mat32: unsupported NumPy array with %d dimensions", len(shape))
+	}
+	columnMajor := fortranOrder[1] == "True" && rows > 1 && cols > 1
+	return decodeFloats(data[offset+headerLen:], rows, cols, size, order, columnMajor)
+}
+
+// decodeFloats returns a new matrix decoding the elements of the given size
+// in bytes and byte order, stored by row or by column. The returned matrix
+// shares the memory of data, if possible (see DecodeNumPy).
+func decodeFloats(data []byte, rows, cols, size int, order binary.ByteOrder, columnMajor bool) (*Dense, error) {
+	n := rows * cols
+	if len(data) < n*size {
+		return nil, errors.New("mat32: truncated data")
+	}
+	if !columnMajor && size == floatSize && (order == binary.LittleEndian) == littleEndianHost {
+		if floats, ok := aliasFloats(data[:n*size]); ok {
+			return &Dense{rows: rows, cols: cols, size: n, data: floats}, nil
+		}
+	}
+	d := NewEmptyDense(rows, cols)
+	if !columnMajor {
+		getFloats(d.data, data, size, order)
+		return d, nil
+	}
+	t := make([]Float, n)
+	getFloats(t, data, size, order)
+	for j := 0; j < cols; j++ {
+		for i := 0; i < rows; i++ {
+			d.data[i*cols+j] = t[j*rows+i]
+		}
+	}
+	return d, nil
+}
+
+// aliasFloats returns the Float values stored in data in the byte order of
+// the host, without copying them, if the data is suitably aligned.
+func aliasFloats(data []byte) ([]Float, bool) {
+	if len(data) == 0 {
+		return []Float{}, true
+	}
+	if uintptr(unsafe.Pointer(&data[0]))%uintptr(floatSize) != 0 {
+		return nil, false
+	}
+	var floats []Float
+	h := (*reflect.SliceHeader)(unsafe.Pointer(&floats))
+	h.Data = uintptr(unsafe.Pointer(&data[0]))
+	h.Len = len(data) / floatSize
+	h.Cap = h.Len
+	return floats, true
+}
+
+// putFloats encodes the values into dst, with the given size in bytes and
+// byte order.
+func putFloats(dst []byte, src []Float, size int, order binary.ByteOrder) {
+	for i, v := range src {
+		if size == 4 {
+			order.PutUint32(dst[i*4:], math.Float32bits(float32(v)))
+		} else {
+			order.PutUint64(dst[i*8:], math.Float64bits(float64(v)))
+		}
+	}
+}
+
+// getFloats decodes the values from src, with the given size in bytes and
+// byte order.
+func getFloats(dst []Float, src []byte, size int, order binary.ByteOrder) {
+	for i := range dst {
+		if size == 4 {
+			dst[i] = Float(math.Float32frombits(order.Uint32(src[i*4:])))
+		} else {
+			dst[i] = Float(math.Float64frombits(order.Uint64(src[i*8:])))
+		}
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mat32
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newNumPy returns the content of a .npy file (version 1.0) with the given
+// header and data.
+func newNumPy(header string, data []byte) []byte {
+	header += strings.Repeat(" ", (64-(10+len(header)+1)%64)%64) + "\n"
+	buf := append([]byte("\x93NUMPY\x01\x00"), byte(len(header)), byte(len(header)>>8))
+	return append(append(buf, header...), data...)
+}
+
+func TestDense_WriteNumPy(t *testing.T) {
+	d := NewDense(2, 3, []Float{1, 2, 3, 4, 5, 6})
+	buf := new(bytes.Buffer)
+	require.NoError(t, d.WriteNumPy(buf))
+	data := buf.Bytes()
+
+	assert.Equal(t, "\x93NUMPY\x01\x00", string(data[:8]))
+	headerLen := int(binary.LittleEndian.Uint16(data[8:]))
+	assert.Equal(t, 0, (10+headerLen)%64)
+	header := string(data[10 : 10+headerLen])
+	expected := fmt.Sprintf("{'descr': '<f%d', 'fortran_order': False, 'shape': (2, 3), }", floatSize)
+	assert.Equal(t, expected, strings.TrimRight(header, " \n"))
+	assert.True(t, strings.HasSuffix(header, "\n"))
+	assert.Len(t, data, 10+headerLen+6*floatSize)
+
+	decoded, err := ReadNumPy(buf)
+	require.NoError(t, err)
+	assert.Equal(t, 2, decoded.Rows())
+	assert.Equal(t, 3, decoded.Columns())
+	assert.Equal(t, d.Data(), decoded.Data())
+}
+
+func TestDecodeNumPy_ZeroCopy(t *testing.T) {
+	buf := new(bytes.Buffer)
+	require.NoError(t, NewVecDense([]Float{1, 2, 3}).WriteNumPy(buf))
+	data := buf.Bytes()
+	d, err := DecodeNumPy(data)
+	require.NoError(t, err)
+	if littleEndianHost {
+		assert.False(t, d.fromPool)
+		for i := len(data) - floatSize; i < len(data); i++ {
+			data[i] = 0 // the last element
+		}
+		assert.Equal(t, []Float{1, 2, 0}, d.Data())
+	}
+}
+
+func TestDecodeNumPy(t *testing.T) {
+	f8 := make([]byte, 6*8)
+	f4be := make([]byte, 6*4)
+	for i, v := range []float64{1, 2, 3, 4, 5, 6} {
+		binary.LittleEndian.PutUint64(f8[i*8:], math.Float64bits(v))
+		binary.BigEndian.PutUint32(f4be[i*4:], math.Float32bits(float32(v)))
+	}
+	testCases := []struct {
+		header   string
+		data     []byte
+		rows     int
+		cols     int
+		expected []Float
+	}{
+		{"{'descr': '<f8', 'fortran_order': False, 'shape': (2, 3), }", f8, 2, 3, []Float{1, 2, 3, 4, 5, 6}},
+		{"{'descr': '>f4', 'fortran_order': False, 'shape': (3, 2), }", f4be, 3, 2, []Float{1, 2, 3, 4, 5, 6}},
+		{"{'descr': '<f8', 'fortran_order': True, 'shape': (2, 3), }", f8, 2, 3, []Float{1, 3, 5, 2, 4, 6}},
+		{"{'descr': '<f8', 'fortran_order': True, 'shape': (6,), }", f8, 6, 1, []Float{1, 2, 3, 4, 5, 6}},
+		{"{'descr': '<f8', 'fortran_order': False, 'shape': (), }", f8, 1, 1, []Float{1}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.header, func(t *testing.T) {
+			d, err := DecodeNumPy(newNumPy(tc.header, tc.data))
+			require.NoError(t, err)
+			assert.Equal(t, tc.rows, d.Rows())
+			assert.Equal(t, tc.cols, d.Columns())
+			assert.Equal(t, tc.expected, d.Data())
+		})
+	}
+}
+
+func TestDecodeNumPy_Errors(t *testing.T) {
+	testCases := []struct {
+		data     []byte
+		expected string
+	}{
+		{[]byte("not numpy"), "mat32: invalid NumPy format"},
+		{newNumPy("{'descr': '<i8', 'fortran_order': False, 'shape': (1,), }", make([]byte, 8)), `mat32: unsupported NumPy data type "<i8"`},
+		{newNumPy("{'descr': '<f8', 'fortran_order': False, 'shape': (1, 1, 1), }", make([]byte, 8)), "mat32: unsupported NumPy array with 3 dimensions"},
+		{newNumPy("{'descr': '<f8', 'fortran_order': False, 'shape': (2,), }", make([]byte, 8)), "mat32: truncated data"},
+		{newNumPy("{'descr': '<f8', 'shape': (1,), }", make([]byte, 8)), "mat32: missing NumPy array order"},
+	}
+	for _, tc := range testCases {
+		_, err := DecodeNumPy(tc.data)
+		assert.EqualError(t, err, tc.expected)
+	}
+}
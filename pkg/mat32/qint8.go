@@ -0,0 +1,304 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mat32
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// QuantizationScheme is the enumeration-like type used to distinguish how the
+// quantization parameters (scale and zero-point) are shared among the values
+// of a QInt8Dense.
+type QuantizationScheme int
+
+const (
+	// PerTensor uses a single scale and zero-point for the whole matrix.
+	PerTensor QuantizationScheme = iota
+	// PerRow uses a scale and zero-point for each row of the matrix, which is
+	// more accurate for weight matrices whose rows have different ranges.
+	PerRow
+)
+
+// String returns the name of the scheme.
+func (s QuantizationScheme) String() string {
+	switch s {
+	case PerTensor:
+		return "per-tensor"
+	case PerRow:
+		return "per-row"
+	default:
+		return fmt.Sprintf("QuantizationScheme(%d)", int(s))
+	}
+}
+
+// QInt8Dense is a compact storage for a dense matrix, whose values are
+// quantized to 8-bit integers with the affine mapping
+//  value = scale * (q - zeroPoint)
+// using a quarter of the memory of a Dense.
+// It is not a Matrix: the values must be dequantized into a Dense before
+// being used in any computation, except for the int8 matrix multiplication
+// provided by Mul and MulDense.
+type QInt8Dense struct {
+	rows       int
+	cols       int
+	scheme     QuantizationScheme
+	data       []int8
+	scales     []Float // one for PerTensor, one for each row for PerRow
+	zeroPoints []int8
+}
+
+// QuantizeDense returns a new QInt8Dense with the values of m, quantized with
+// the given scheme. The range of each group of values is extended to include
+// zero, so that zero is always represented exactly.
+func QuantizeDense(m Matrix, scheme QuantizationScheme) *QInt8Dense {
+	rows, cols := m.Dims()
+	values := m.Data()
+	q := &QInt8Dense{
+		rows:   rows,
+		cols:   cols,
+		scheme: scheme,
+		data:   make([]int8, len(values)),
+	}
+	switch scheme {
+	case PerTensor:
+		q.scales = make([]Float, 1)
+		q.zeroPoints = make([]int8, 1)
+		q.quantizeGroup(0, values, q.data)
+	case PerRow:
+		q.scales = make([]Float, rows)
+		q.zeroPoints = make([]int8, rows)
+		for i := 0; i < rows; i++ {
+			q.quantizeGroup(i, values[i*cols:(i+1)*cols], q.data[i*cols:(i+1)*cols])
+		}
+	default:
+		panic(fmt.Sprintf("mat32: unknown quantization scheme %v", scheme))
+	}
+	return q
+}
+
+// quantizeGroup quantizes the values into out, setting the k-th scale and zero-point.
+func (q *QInt8Dense) quantizeGroup(k int, values []Float, out []int8) {
+	var min, max Float
+	for _, v := range values {
+		if v < min {
+			min = v
+		}
+		if v > max {
+			max = v
+		}
+	}
+	scale, zeroPoint := quantizationParams(min, max)
+	q.scales[k], q.zeroPoints[k] = scale, zeroPoint
+	for i, v := range values {
+		out[i] = quantize(v, scale, zeroPoint)
+	}
+}
+
+// quantizationParams returns the scale and zero-point mapping the range
+// [min, max], which must include zero, onto [-128, 127].
+func quantizationParams(min, max Float) (scale Float, zeroPoint int8) {
+	if max == min {
+		return 1, 0
+	}
+	scale = (max - min) / 255
+	return scale, clampInt8(Round(-128 - min/scale))
+}
+
+func quantize(v, scale Float, zeroPoint int8) int8 {
+	return clampInt8(Round(v/scale) + Float(zeroPoint))
+}
+
+func clampInt8(v Float) int8 {
+	switch {
+	case v < math.MinInt8:
+		return math.MinInt8
+	case v > math.MaxInt8:
+		return math.MaxInt8
+	default:
+		return int8(v)
+	}
+}
+
+// Rows returns the number of rows of the matrix.
+func (q *QInt8Dense) Rows() int {
+	return q.rows
+}
+
+// Columns returns the number of columns of the matrix.
+func (q *QInt8Dense) Columns() int {
+	return q.cols
+}
+
+// Dims returns the number of rows and columns of the matrix.
+func (q *QInt8Dense) Dims() (r, c int) {
+	return q.rows, q.cols
+}
+
+// Size returns the size of the matrix (rows × columns).
+func (q *QInt8Dense) Size() int {
+	return len(q.data)
+}
+
+// Scheme returns the quantization scheme.
+func (q *QInt8Dense) Scheme() QuantizationScheme {
+	return q.scheme
+}
+
+// Params returns the scale and the zero-point used to quantize the i-th row.
+func (q *QInt8Dense) Params(i int) (scale Float, zeroPoint int8) {
+	if i >= q.rows {
+		panic("mat32: index out of range")
+	}
+	k := q.group(i)
+	return q.scales[k], q.zeroPoints[k]
+}
+
+// group returns the index of the quantization parameters of the i-th row.
+func (q *QInt8Dense) group(i int) int {
+	if q.scheme == PerRow {
+		return i
+	}
+	return 0
+}
+
+// At returns the dequantized value at row i and column j.
+func (q *QInt8Dense) At(i, j int) Float {
+	if i >= q.rows || j >= q.cols {
+		panic("mat32: index out of range")
+	}
+	k := q.group(i)
+	return q.scales[k] * Float(int32(q.data[i*q.cols+j])-int32(q.zeroPoints[k]))
+}
+
+// Dequantize returns a new Dense matrix with the dequantized values.
+func (q *QInt8Dense) Dequantize() *Dense {
+	out := NewEmptyDense(q.rows, q.cols)
+	q.DequantizeInto(out)
+	return out
+}
+
+// DequantizeInto dequantizes the values into the out matrix, which must have the same size.
+func (q *QInt8Dense) DequantizeInto(out *Dense) {
+	if out.Size() != len(q.data) {
+		panic("mat32: incompatible matrix size")
+	}
+	outData := out.Data()
+	for i := 0; i < q.rows; i++ {
+		k := q.group(i)
+		scale, zeroPoint := q.scales[k], int32(q.zeroPoints[k])
+		for j := i * q.cols; j < (i+1)*q.cols; j++ {
+			outData[j] = scale * Float(int32(q.data[j])-zeroPoint)
+		}
+	}
+}
+
+// Mul performs the matrix multiplication q × other, accumulating the products
+// of the 8-bit integers in 32-bit integers, and returns the dequantized result.
+// The other matrix must be quantized with the PerTensor scheme.
+func (q *QInt8Dense) Mul(other *QInt8Dense) *Dense {
+	if q.cols != other.rows {
+		panic("mat32: matrices with not compatible size")
+	}
+	if other.scheme != PerTensor {
+		panic("mat32: the right operand must be quantized per tensor")
+	}
+	n, k := other.cols, q.cols
+	otherScale, otherZero := other.scales[0], int32(other.zeroPoints[0])
+
+	// The sums of the columns of other are needed to correct for the zero-point of q.
+	colSums := make([]int32, n)
+	for p := 0; p < k; p++ {
+		for j, v := range other.data[p*n : (p+1)*n] {
+			colSums[j] += int32(v)
+		}
+	}
+
+	out := NewEmptyDense(q.rows, n)
+	outData := out.Data()
+	acc := make([]int32, n)
+	for i := 0; i < q.rows; i++ {
+		for j := range acc {
+			acc[j] = 0
+		}
+		var rowSum int32
+		for p, a := range q.data[i*k : (i+1)*k] {
+			if a == 0 {
+				continue
+			}
+			a := int32(a)
+			rowSum += a
+			for j, b := range other.data[p*n : (p+1)*n] {
+				acc[j] += a * int32(b)
+			}
+		}
+		g := q.group(i)
+		zero := int32(q.zeroPoints[g])
+		scale := q.scales[g] * otherScale
+		// Σ (a - za)(b - zb) = Σ ab - zb Σ a - za Σ b + k za zb
+		offset := int32(k)*zero*otherZero - otherZero*rowSum
+		for j, v := range acc {
+			outData[i*n+j] = scale * Float(v+offset-zero*colSums[j])
+		}
+	}
+	return out
+}
+
+// MulDense performs the matrix multiplication q × other, quantizing other
+// with the PerTensor scheme on the fly (dynamic quantization).
+func (q *QInt8Dense) MulDense(other Matrix) *Dense {
+	return q.Mul(QuantizeDense(other, PerTensor))
+}
+
+// MarshalBinary marshals a QInt8Dense matrix into binary form.
+func (q QInt8Dense) MarshalBinary() ([]byte, error) {
+	groups := len(q.scales)
+	data := make([]byte, 12+groups*5+len(q.data))
+	binary.LittleEndian.PutUint32(data, uint32(q.rows))
+	binary.LittleEndian.PutUint32(data[4:], uint32(q.cols))
+	binary.LittleEndian.PutUint32(data[8:], uint32(q.scheme))
+	offset := 12
+	for i, scale := range q.scales {
+		binary.LittleEndian.PutUint32(data[offset:], math.Float32bits(scale))
+		data[offset+4] = byte(q.zeroPoints[i])
+		offset += 5
+	}
+	for i, v := range q.data {
+		data[offset+i] = byte(v)
+	}
+	return data, nil
+}
+
+// UnmarshalBinary unmarshals a binary representation of a QInt8Dense matrix.
+func (q *QInt8Dense) UnmarshalBinary(data []byte) error {
+	if len(data) < 12 {
+		return fmt.Errorf("mat32: invalid QInt8Dense binary data")
+	}
+	q.rows = int(binary.LittleEndian.Uint32(data))
+	q.cols = int(binary.LittleEndian.Uint32(data[4:]))
+	q.scheme = QuantizationScheme(binary.LittleEndian.Uint32(data[8:]))
+	groups := 1
+	if q.scheme == PerRow {
+		groups = q.rows
+	}
+	size := q.rows * q.cols
+	if len(data) != 12+groups*5+size {
+		return fmt.Errorf("mat32: invalid QInt8Dense binary data")
+	}
+	q.scales = make([]Float, groups)
+	q.zeroPoints = make([]int8, groups)
+	offset := 12
+	for i := range q.scales {
+		q.scales[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[offset:]))
+		q.zeroPoints[i] = int8(data[offset+4])
+		offset += 5
+	}
+	q.data = make([]int8, size)
+	for i := range q.data {
+		q.data[i] = int8(data[offset+i])
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mat32
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuantizeDense(t *testing.T) {
+	m := NewDense(2, 3, []Float{
+		0.1, -0.2, 0.3,
+		-40, 50, 0,
+	})
+	for _, scheme := range []QuantizationScheme{PerTensor, PerRow} {
+		t.Run(scheme.String(), func(t *testing.T) {
+			q := QuantizeDense(m, scheme)
+			assert.Equal(t, scheme, q.Scheme())
+			r, c := q.Dims()
+			assert.Equal(t, 2, r)
+			assert.Equal(t, 3, c)
+			assert.Equal(t, 6, q.Size())
+			assert.Equal(t, Float(0), q.At(1, 2), "zero must be exact")
+
+			d := q.Dequantize()
+			for i := 0; i < 2; i++ {
+				scale, _ := q.Params(i)
+				for j := 0; j < 3; j++ {
+					assert.InDelta(t, m.At(i, j), d.At(i, j), float64(scale))
+					assert.InDelta(t, m.At(i, j), q.At(i, j), float64(scale))
+				}
+			}
+		})
+	}
+}
+
+func TestQuantizeDense_PerRowAccuracy(t *testing.T) {
+	m := NewDense(2, 2, []Float{0.01, -0.02, 100, -200})
+	perTensor := QuantizeDense(m, PerTensor).Dequantize()
+	perRow := QuantizeDense(m, PerRow).Dequantize()
+	assert.Equal(t, Float(0), perTensor.At(0, 0), "small values are lost per tensor")
+	assert.InDelta(t, 0.01, perRow.At(0, 0), 1.0e-3)
+	assert.InDelta(t, -0.02, perRow.At(0, 1), 1.0e-3)
+}
+
+func TestQuantizeDense_Constant(t *testing.T) {
+	q := QuantizeDense(NewEmptyDense(2, 2), PerRow)
+	assert.Equal(t, []Float{0, 0, 0, 0}, q.Dequantize().Data())
+}
+
+func TestQInt8Dense_Mul(t *testing.T) {
+	a := NewDense(2, 3, []Float{
+		1, -2, 3,
+		0.5, 0.25, -1,
+	})
+	b := NewDense(3, 2, []Float{
+		1, 2,
+		-1, 0.5,
+		2, -3,
+	})
+	expected := a.Mul(b).Data()
+	for _, scheme := range []QuantizationScheme{PerTensor, PerRow} {
+		t.Run(scheme.String(), func(t *testing.T) {
+			out := QuantizeDense(a, scheme).MulDense(b)
+			r, c := out.Dims()
+			assert.Equal(t, 2, r)
+			assert.Equal(t, 2, c)
+			for i, v := range out.Data() {
+				assert.InDelta(t, expected[i], v, 0.1)
+			}
+		})
+	}
+}
+
+func TestQInt8Dense_MulIsExactOnQuantizedValues(t *testing.T) {
+	a := QuantizeDense(NewDense(2, 2, []Float{1, -3, 2, 0.7}), PerRow)
+	b := QuantizeDense(NewVecDense([]Float{-1.5, 4}), PerTensor)
+	expected := a.Dequantize().Mul(b.Dequantize()).Data()
+	assert.InDeltaSlice(t, expected, a.Mul(b).Data(), 1.0e-4)
+}
+
+func TestQInt8Dense_MulPanics(t *testing.T) {
+	a := QuantizeDense(NewEmptyDense(2, 3), PerTensor)
+	assert.Panics(t, func() { a.Mul(QuantizeDense(NewEmptyDense(2, 2), PerTensor)) })
+	assert.Panics(t, func() { a.Mul(QuantizeDense(NewEmptyDense(3, 2), PerRow)) })
+}
+
+func TestQInt8Dense_MarshalBinary(t *testing.T) {
+	m := NewDense(2, 3, []Float{0.1, -0.2, 0.3, -40, 50, 0})
+	for _, scheme := range []QuantizationScheme{PerTensor, PerRow} {
+		t.Run(scheme.String(), func(t *testing.T) {
+			q := QuantizeDense(m, scheme)
+			data, err := q.MarshalBinary()
+			require.NoError(t, err)
+			decoded := new(QInt8Dense)
+			require.NoError(t, decoded.UnmarshalBinary(data))
+			assert.Equal(t, q, decoded)
+		})
+	}
+	assert.Error(t, new(QInt8Dense).UnmarshalBinary([]byte{1, 2}))
+}
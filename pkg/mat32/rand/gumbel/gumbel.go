@@ -0,0 +1,31 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gumbel
+
+import (
+	"github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/mat32/rand"
+)
+
+// Distribution creates a new matrix initialized with standard Gumbel noise.
+// Adding Gumbel noise to log-probabilities and taking the argmax is equivalent
+// to sampling from the categorical distribution (the Gumbel-max trick).
+func Distribution(r, c int, generator *rand.LockedRand) mat32.Matrix {
+	out := mat32.NewEmptyDense(r, c)
+	data := out.Data()
+	for i := range data {
+		data[i] = Next(generator)
+	}
+	return out
+}
+
+// Next returns a random sample drawn from the standard Gumbel distribution.
+func Next(generator *rand.LockedRand) mat32.Float {
+	u := mat32.Float(generator.Float32())
+	for u == 0 {
+		u = mat32.Float(generator.Float32())
+	}
+	return -mat32.Log(-mat32.Log(u))
+}
@@ -0,0 +1,26 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gumbel
+
+import (
+	"testing"
+
+	"github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/mat32/rand"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDistribution(t *testing.T) {
+	m := Distribution(100, 100, rand.NewLockedRand(42))
+	assert.Equal(t, 100, m.Rows())
+	assert.Equal(t, 100, m.Columns())
+
+	var sum mat32.Float
+	for _, v := range m.Data() {
+		sum += v
+	}
+	const eulerGamma = 0.5772 // the mean of the standard Gumbel distribution
+	assert.InDelta(t, eulerGamma, sum/10000, 0.05)
+}
@@ -11,19 +11,52 @@ import (
 
 // LockedRand is an implementation of rand.Rand that is concurrency-safe.
 // It is just a wrap of the standard rand.Rand with its operations protected by a sync.Mutex.
+// Its state can be captured and restored with MarshalBinary and UnmarshalBinary
+// (see also CaptureState).
 type LockedRand struct {
-	lk sync.Mutex
-	r  *rand.Rand
+	lk  sync.Mutex
+	r   *rand.Rand
+	src *rand.PCGSource
 }
 
 // NewLockedRand creates a new LockedRand that implements all Rand functions that is safe
 // for concurrent use.
 func NewLockedRand(seed uint64) *LockedRand {
+	src := &rand.PCGSource{}
+	src.Seed(seed)
 	return &LockedRand{
-		r: rand.New(rand.NewSource(seed)),
+		r:   rand.New(src),
+		src: src,
 	}
 }
 
+// Spawn returns a new LockedRand seeded with a number drawn from lr.
+// Giving each concurrent task its own spawned generator makes the sequence of
+// random numbers independent of the goroutine scheduling.
+func (lr *LockedRand) Spawn() *LockedRand {
+	return NewLockedRand(lr.Uint64())
+}
+
+// MarshalBinary returns the state of the generator, so that the same sequence
+// of random numbers can be drawn after restoring it with UnmarshalBinary.
+// The bytes buffered by Read are not part of the state.
+func (lr *LockedRand) MarshalBinary() ([]byte, error) {
+	lr.lk.Lock()
+	defer lr.lk.Unlock()
+	return lr.src.MarshalBinary()
+}
+
+// UnmarshalBinary restores the state of the generator returned by MarshalBinary.
+func (lr *LockedRand) UnmarshalBinary(data []byte) error {
+	lr.lk.Lock()
+	defer lr.lk.Unlock()
+	if err := lr.src.UnmarshalBinary(data); err != nil {
+		return err
+	}
+	lr.r = rand.New(lr.src) // discards the bytes buffered by Read
+	return nil
+}
+
 // Seed uses the provided seed value to initialize the generator to a deterministic state.
 // Seed should not be called concurrently with any other Rand method.
 func (lr *LockedRand) Seed(seed uint64) {
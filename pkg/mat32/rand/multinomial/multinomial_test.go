@@ -0,0 +1,28 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package multinomial
+
+import (
+	"testing"
+
+	"github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/mat32/rand"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDistribution(t *testing.T) {
+	samples := Distribution([]mat32.Float{1, 0, 3}, 10000, rand.NewLockedRand(42))
+	counts := make([]int, 3)
+	for _, s := range samples {
+		counts[s]++
+	}
+	assert.Equal(t, 0, counts[1])
+	assert.InDelta(t, 0.25, float64(counts[0])/10000, 0.02)
+	assert.InDelta(t, 0.75, float64(counts[2])/10000, 0.02)
+
+	assert.Equal(t, samples, Distribution([]mat32.Float{1, 0, 3}, 10000, rand.NewLockedRand(42)))
+	assert.Panics(t, func() { Distribution([]mat32.Float{0, 0}, 1, rand.NewLockedRand(42)) })
+	assert.Panics(t, func() { Distribution([]mat32.Float{1, -1}, 1, rand.NewLockedRand(42)) })
+}
@@ -5,6 +5,7 @@
 package normal
 
 import (
+	"github.com/nlpodyssey/spago/pkg/mat32"
 	"github.com/nlpodyssey/spago/pkg/mat32/rand"
 )
 
@@ -29,3 +30,43 @@ func New(std, mean float32, generator *rand.LockedRand) *Normal {
 func (u Normal) Next() float32 {
 	return u.generator.NormFloat32()*u.Std + u.Mean
 }
+
+// Truncated is a source of normally distributed random numbers, restricted to
+// the interval [Min, Max].
+type Truncated struct {
+	Normal
+	Min float32
+	Max float32
+}
+
+// NewTruncated returns a new Truncated, initialized with the given standard
+// deviation, mean, min and max parameters.
+func NewTruncated(std, mean, min, max float32, generator *rand.LockedRand) *Truncated {
+	return &Truncated{
+		Normal: *New(std, mean, generator),
+		Min:    min,
+		Max:    max,
+	}
+}
+
+// Next returns a random sample drawn from the distribution, by rejection of
+// the samples which fall outside the interval.
+func (u Truncated) Next() float32 {
+	for {
+		if x := u.Normal.Next(); x >= u.Min && x <= u.Max {
+			return x
+		}
+	}
+}
+
+// TruncatedDistribution creates a new matrix initialized with a normal
+// distribution truncated at two standard deviations from the mean.
+func TruncatedDistribution(r, c int, std, mean float32, generator *rand.LockedRand) mat32.Matrix {
+	dist := NewTruncated(std, mean, mean-2*std, mean+2*std, generator)
+	out := mat32.NewEmptyDense(r, c)
+	data := out.Data()
+	for i := range data {
+		data[i] = dist.Next()
+	}
+	return out
+}
@@ -0,0 +1,27 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package normal
+
+import (
+	"testing"
+
+	"github.com/nlpodyssey/spago/pkg/mat32/rand"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTruncatedDistribution(t *testing.T) {
+	m := TruncatedDistribution(100, 100, 0.5, 1, rand.NewLockedRand(42))
+	for _, v := range m.Data() {
+		assert.True(t, v >= 0 && v <= 2)
+	}
+}
+
+func TestTruncated_Next(t *testing.T) {
+	dist := NewTruncated(1, 0, -0.1, 0.1, rand.NewLockedRand(42))
+	for i := 0; i < 100; i++ {
+		x := dist.Next()
+		assert.True(t, x >= -0.1 && x <= 0.1)
+	}
+}
@@ -0,0 +1,41 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rand
+
+import "fmt"
+
+// State is the state of a set of named generators (e.g. the ones shuffling
+// the data, of the dropout and of the samplers), which can be stored in the
+// checkpoints of a training, so that the resumed training draws the same
+// random numbers as the uninterrupted one.
+type State map[string][]byte
+
+// CaptureState returns the state of the given generators.
+func CaptureState(generators map[string]*LockedRand) (State, error) {
+	state := make(State, len(generators))
+	for name, generator := range generators {
+		data, err := generator.MarshalBinary()
+		if err != nil {
+			return nil, fmt.Errorf("rand: cannot capture the state of %q: %w", name, err)
+		}
+		state[name] = data
+	}
+	return state, nil
+}
+
+// Restore restores the state of the given generators. It returns an error if
+// the state of any of them is missing or invalid.
+func (s State) Restore(generators map[string]*LockedRand) error {
+	for name, generator := range generators {
+		data, ok := s[name]
+		if !ok {
+			return fmt.Errorf("rand: missing state of %q", name)
+		}
+		if err := generator.UnmarshalBinary(data); err != nil {
+			return fmt.Errorf("rand: cannot restore the state of %q: %w", name, err)
+		}
+	}
+	return nil
+}
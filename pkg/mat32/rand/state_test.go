@@ -0,0 +1,49 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rand
+
+import (
+	"bytes"
+	"encoding/gob"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockedRand_MarshalBinary(t *testing.T) {
+	a := NewLockedRand(42)
+	a.Uint64()
+	data, err := a.MarshalBinary()
+	require.NoError(t, err)
+	expected := []uint64{a.Uint64(), a.Uint64(), a.Uint64()}
+
+	b := NewLockedRand(1)
+	b.Read(make([]byte, 3)) // the buffered bytes are discarded
+	require.NoError(t, b.UnmarshalBinary(data))
+	assert.Equal(t, expected, []uint64{b.Uint64(), b.Uint64(), b.Uint64()})
+
+	assert.Error(t, b.UnmarshalBinary([]byte{1, 2, 3}))
+}
+
+func TestState(t *testing.T) {
+	shuffle, dropout := NewLockedRand(1), NewLockedRand(2)
+	state, err := CaptureState(map[string]*LockedRand{"shuffle": shuffle, "dropout": dropout})
+	require.NoError(t, err)
+	expected := []int{shuffle.Intn(100), dropout.Intn(100)}
+
+	// the state can be stored in a checkpoint
+	buf := new(bytes.Buffer)
+	require.NoError(t, gob.NewEncoder(buf).Encode(state))
+	var decoded State
+	require.NoError(t, gob.NewDecoder(buf).Decode(&decoded))
+
+	shuffle, dropout = NewLockedRand(3), NewLockedRand(4)
+	require.NoError(t, decoded.Restore(map[string]*LockedRand{"shuffle": shuffle, "dropout": dropout}))
+	assert.Equal(t, expected, []int{shuffle.Intn(100), dropout.Intn(100)})
+
+	err = decoded.Restore(map[string]*LockedRand{"sampler": NewLockedRand(5)})
+	assert.EqualError(t, err, `rand: missing state of "sampler"`)
+}
@@ -0,0 +1,80 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mat32
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SafetyMode is the enumeration-like type used to distinguish how the Dense
+// operations verify their operands. The mode is chosen at build time.
+type SafetyMode int
+
+const (
+	// Checked is the default mode: the operations verify the dimensions of
+	// their operands and the indices, panicking on a mismatch.
+	Checked SafetyMode = iota
+	// Unchecked compiles out the verifications of the hot-path operations
+	// (element access, element-wise arithmetic and matrix multiplication),
+	// to be used in production builds whose code is known to be correct.
+	// An invalid operation may then panic with an index out of range, or
+	// silently produce a wrong result. It is enabled by the "mat_unchecked"
+	// build tag.
+	Unchecked
+	// Debug performs the same verifications as Checked, reporting the
+	// operation and the shapes of its operands in the panic messages.
+	// It is enabled by the "mat_debug" build tag.
+	Debug
+)
+
+// String returns the name of the safety mode.
+func (m SafetyMode) String() string {
+	switch m {
+	case Checked:
+		return "checked"
+	case Unchecked:
+		return "unchecked"
+	case Debug:
+		return "debug"
+	default:
+		return fmt.Sprintf("SafetyMode(%d)", int(m))
+	}
+}
+
+// Safety returns the SafetyMode the package has been built with.
+func Safety() SafetyMode {
+	return safetyMode
+}
+
+// checksEnabled reports whether the hot-path verifications are performed.
+// Being a constant, the verifications are removed by the compiler otherwise.
+const checksEnabled = safetyMode != Unchecked
+
+// dimsError returns the panic message of an operation with incompatible
+// operands, which reports their shapes in Debug mode.
+func dimsError(msg, op string, operands ...Matrix) string {
+	if safetyMode != Debug {
+		return msg
+	}
+	return withShapes(msg, op, operands...)
+}
+
+// indexError returns the panic message of an access to the matrix m with an
+// index out of range, which reports the index and the shape in Debug mode.
+func indexError(msg, op string, index int, m Matrix) string {
+	if safetyMode != Debug {
+		return msg
+	}
+	return fmt.Sprintf("%s (%s: index %d, shape %d×%d)", msg, op, index, m.Rows(), m.Columns())
+}
+
+func withShapes(msg, op string, operands ...Matrix) string {
+	shapes := make([]string, len(operands))
+	for i, m := range operands {
+		shapes[i] = fmt.Sprintf("%d×%d", m.Rows(), m.Columns())
+	}
+	return fmt.Sprintf("%s (%s: %s)", msg, op, strings.Join(shapes, ", "))
+}
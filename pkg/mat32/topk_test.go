@@ -0,0 +1,46 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mat32
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArgSort(t *testing.T) {
+	m := NewDense(2, 3, []Float{
+		0.3, -0.1, 0.8,
+		0.3, NaN(), 0.0,
+	})
+	assert.Equal(t, []int{1, 5, 0, 3, 2, 4}, ArgSort(m, false))
+	assert.Equal(t, []int{2, 0, 3, 5, 1, 4}, ArgSort(m, true))
+}
+
+func TestTopK(t *testing.T) {
+	v := NewVecDense([]Float{0.1, 0.9, -0.4, 0.5, 0.2})
+
+	t.Run("k less than size", func(t *testing.T) {
+		values, indices := TopK(v, 3)
+		assert.Equal(t, []int{1, 3, 4}, indices)
+		assert.Equal(t, []Float{0.9, 0.5, 0.2}, values.Data())
+		assert.Equal(t, 3, values.Rows())
+		assert.Equal(t, 1, values.Columns())
+	})
+
+	t.Run("k greater than size", func(t *testing.T) {
+		_, indices := TopK(v, 10)
+		assert.Equal(t, []int{1, 3, 4, 0, 2}, indices)
+	})
+
+	t.Run("negative k", func(t *testing.T) {
+		assert.Panics(t, func() { TopK(v, -1) })
+	})
+}
+
+func TestGather(t *testing.T) {
+	m := NewDense(2, 2, []Float{1, 2, 3, 4})
+	assert.Equal(t, []Float{4, 1, 4}, Gather(m, []int{3, 0, 3}).Data())
+}
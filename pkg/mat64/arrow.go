@@ -0,0 +1,193 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mat64
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	flatbuffers "github.com/google/flatbuffers/go"
+)
+
+// The values of the Apache Arrow IPC format used by the tensor messages
+// (see Message.fbs, Schema.fbs and Tensor.fbs of the Arrow format).
+const (
+	arrowContinuation    uint32 = 0xFFFFFFFF
+	arrowMetadataV5      int16  = 4
+	arrowHeaderTensor    byte   = 4
+	arrowTypeFloatPoint  byte   = 3
+	arrowPrecisionHalf   int16  = 0
+	arrowPrecisionFloat  int16  = 1
+	arrowPrecisionDouble int16  = 2
+)
+
+// WriteArrowTensor writes the matrix as an Apache Arrow tensor of the Float
+// type, in row-major order, in the encapsulated IPC message format, which can
+// be read with pyarrow.ipc.read_tensor().
+func (d *Dense) WriteArrowTensor(w io.Writer) error {
+	d.sync()
+	dataLen := d.size * floatSize
+	bodyLen := (dataLen + 7) &^ 7
+
+	b := flatbuffers.NewBuilder(256)
+	b.StartObject(1) // FloatingPoint
+	precision := arrowPrecisionFloat
+	if floatSize == 8 {
+		precision = arrowPrecisionDouble
+	}
+	b.PrependInt16Slot(0, precision, arrowPrecisionHalf)
+	floatingPoint := b.EndObject()
+
+	dims := make([]flatbuffers.UOffsetT, 2)
+	for i, size := range []int{d.rows, d.cols} {
+		b.StartObject(2) // TensorDim
+		b.PrependInt64Slot(0, int64(size), -1)
+		dims[i] = b.EndObject()
+	}
+	b.StartVector(4, len(dims), 4)
+	for i := len(dims) - 1; i >= 0; i-- {
+		b.PrependUOffsetT(dims[i])
+	}
+	shape := b.EndVector(len(dims))
+	b.StartVector(8, 2, 8)
+	b.PrependInt64(int64(floatSize))
+	b.PrependInt64(int64(d.cols * floatSize))
+	strides := b.EndVector(2)
+
+	b.StartObject(5) // Tensor
+	b.Prep(8, 16)    // Buffer
+	b.PrependInt64(int64(dataLen))
+	b.PrependInt64(0)
+	b.PrependStructSlot(4, b.Offset(), 0)
+	b.PrependUOffsetTSlot(3, strides, 0)
+	b.PrependUOffsetTSlot(2, shape, 0)
+	b.PrependUOffsetTSlot(1, floatingPoint, 0)
+	b.PrependByteSlot(0, arrowTypeFloatPoint, 0)
+	tensor := b.EndObject()
+
+	b.StartObject(5) // Message
+	b.PrependInt64Slot(3, int64(bodyLen), -1)
+	b.PrependUOffsetTSlot(2, tensor, 0)
+	b.PrependByteSlot(1, arrowHeaderTensor, 0)
+	b.PrependInt16Slot(0, arrowMetadataV5, -1)
+	b.Finish(b.EndObject())
+	metadata := b.FinishedBytes()
+
+	// the metadata is padded so that the body is aligned to 8 bytes
+	metadataLen := (8+len(metadata)+7)&^7 - 8
+	buf := make([]byte, 8+metadataLen+bodyLen)
+	binary.LittleEndian.PutUint32(buf, arrowContinuation)
+	binary.LittleEndian.PutUint32(buf[4:], uint32(metadataLen))
+	copy(buf[8:], metadata)
+	putFloats(buf[8+metadataLen:], d.data, floatSize, binary.LittleEndian)
+	_, err := w.Write(buf)
+	return err
+}
+
+// ReadArrowTensor reads a matrix from an Apache Arrow tensor (see
+// DecodeArrowTensor).
+func ReadArrowTensor(r io.Reader) (*Dense, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeArrowTensor(data)
+}
+
+// DecodeArrowTensor decodes a matrix from an Apache Arrow tensor in the
+// encapsulated IPC message format (e.g. written by
+// pyarrow.ipc.write_tensor()), with or without the continuation marker. The
+// tensor must be of float32 or float64 values, converted to Float, in
+// row-major or column-major order. The 1-D tensors are decoded as column
+// vectors.
+//
+// When the tensor is of the Float type, in row-major order, and its data is
+// suitably aligned in memory, the returned matrix shares the memory of data
+// (zero-copy), which must not be modified afterwards; such a matrix doesn't
+// come from the workspace, so it must not be released.
+func DecodeArrowTensor(data []byte) (_ *Dense, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New("mat64: invalid Arrow tensor message")
+		}
+	}()
+	if len(data) < 8 {
+		return nil, errors.New("mat64: invalid Arrow tensor message")
+	}
+	pos := 0
+	if binary.LittleEndian.Uint32(data) == arrowContinuation {
+		pos = 4
+	}
+	metadataLen := int(binary.LittleEndian.Uint32(data[pos:]))
+	pos += 4
+	metadata, body := data[pos:pos+metadataLen], data[pos+metadataLen:]
+
+	msg := flatbuffers.Table{Bytes: metadata, Pos: flatbuffers.GetUOffsetT(metadata)}
+	if msg.GetByteSlot(6, 0) != arrowHeaderTensor {
+		return nil, errors.New("mat64: the Arrow message is not a tensor")
+	}
+	var tensor flatbuffers.Table
+	msg.Union(&tensor, flatbuffers.UOffsetT(msg.Offset(8)))
+
+	if tensor.GetByteSlot(4, 0) != arrowTypeFloatPoint {
+		return nil, errors.New("mat64: unsupported Arrow tensor type")
+	}
+	var floatingPoint flatbuffers.Table
+	tensor.Union(&floatingPoint, flatbuffers.UOffsetT(tensor.Offset(6)))
+	var size int
+	switch floatingPoint.GetInt16Slot(4, arrowPrecisionHalf) {
+	case arrowPrecisionFloat:
+		size = 4
+	case arrowPrecisionDouble:
+		size = 8
+	default:
+		return nil, errors.New("mat64: unsupported Arrow tensor precision")
+	}
+
+	o := flatbuffers.UOffsetT(tensor.Offset(8))
+	shape := make([]int, tensor.VectorLen(o))
+	for i := range shape {
+		dim := flatbuffers.Table{Bytes: metadata, Pos: tensor.Indirect(tensor.Vector(o) + flatbuffers.UOffsetT(i*4))}
+		shape[i] = int(dim.GetInt64Slot(4, 0))
+	}
+	rows, cols := 1, 1
+	switch len(shape) {
+	case 1:
+		rows = shape[0]
+	case 2:
+		rows, cols = shape[0], shape[1]
+	default:
+		return nil, fmt.Errorf("mat64: unsupported Arrow tensor with %d dimensions", len(shape))
+	}
+
+	columnMajor := false
+	if o := flatbuffers.UOffsetT(tensor.Offset(10)); o != 0 && len(shape) == 2 {
+		if tensor.VectorLen(o) != 2 {
+			return nil, errors.New("mat64: invalid Arrow tensor strides")
+		}
+		strides := [2]int64{tensor.GetInt64(tensor.Vector(o)), tensor.GetInt64(tensor.Vector(o) + 8)}
+		switch {
+		case strides == [2]int64{int64(cols * size), int64(size)}:
+		case strides == [2]int64{int64(size), int64(rows * size)}:
+			columnMajor = rows > 1 && cols > 1
+		default:
+			return nil, errors.New("mat64: unsupported Arrow tensor strides")
+		}
+	}
+
+	buffer := flatbuffers.UOffsetT(tensor.Offset(12))
+	if buffer == 0 {
+		return nil, errors.New("mat64: missing Arrow tensor data")
+	}
+	offset := int(tensor.GetInt64(tensor.Pos + buffer))
+	length := int(tensor.GetInt64(tensor.Pos + buffer + 8))
+	if offset < 0 || length < rows*cols*size || offset+length > len(body) {
+		return nil, errors.New("mat64: truncated Arrow tensor data")
+	}
+	return decodeFloats(body[offset:offset+length], rows, cols, size, binary.LittleEndian, columnMajor)
+}
@@ -0,0 +1,164 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mat64
+
+import (
+	"runtime"
+	"sync/atomic"
+
+	"github.com/nlpodyssey/spago/pkg/mat64/internal/asm/f64"
+)
+
+// Backend provides the implementation of the most computationally expensive
+// Dense operations. The default backend is written in pure Go; an optimized
+// one, bridging to a system BLAS/LAPACK library (e.g. OpenBLAS or MKL), is
+// available when building with the "cblas" tag.
+type Backend interface {
+	// Mul performs the matrix multiplication a×b, storing the result in out.
+	// The dimensions of the matrices are already verified by the caller,
+	// and out is always zero-initialized.
+	Mul(a, b, out *Dense)
+	// Inverse computes the inverse of the square matrix a, storing the result
+	// in out.
+	Inverse(a, out *Dense)
+}
+
+// backend is the Backend currently in use.
+var backend Backend = GoBackend{}
+
+// SetBackend sets the Backend used by all Dense matrices.
+// It is not safe for concurrent use: it should be called once, at
+// initialization time, before any matrix operation takes place.
+func SetBackend(b Backend) {
+	if b == nil {
+		panic("mat64: backend cannot be nil")
+	}
+	backend = b
+}
+
+// CurrentBackend returns the Backend currently in use.
+func CurrentBackend() Backend {
+	return backend
+}
+
+// matMulParallelThreshold is the minimum number of multiply-add operations
+// (m×n×k) for which GoBackend.Mul computes a matrix-matrix multiplication in
+// parallel, since smaller ones don't pay back the goroutines overhead.
+const matMulParallelThreshold = 128 * 128 * 128
+
+// matMulThreads is the maximum number of goroutines used by GoBackend.Mul.
+var matMulThreads = int32(runtime.GOMAXPROCS(0))
+
+// SetMatMulThreads sets the maximum number of goroutines used by GoBackend to
+// multiply large matrices, which are split into cache-friendly blocks
+// computed concurrently. A value of 1 disables the parallel multiplication,
+// while zero or a negative value resets the default, runtime.GOMAXPROCS(0).
+func SetMatMulThreads(n int) {
+	if n <= 0 {
+		n = runtime.GOMAXPROCS(0)
+	}
+	atomic.StoreInt32(&matMulThreads, int32(n))
+}
+
+// MatMulThreads returns the maximum number of goroutines used by GoBackend to
+// multiply large matrices.
+func MatMulThreads() int {
+	return int(atomic.LoadInt32(&matMulThreads))
+}
+
+var _ Backend = GoBackend{}
+
+// GoBackend is the default Backend, implemented in pure Go.
+type GoBackend struct{}
+
+// Mul performs the matrix multiplication a×b, storing the result in out.
+// Large matrix-matrix multiplications are computed in parallel (see
+// SetMatMulThreads).
+func (GoBackend) Mul(a, b, out *Dense) {
+	if out.cols == 1 {
+		f64.GemvN(
+			uintptr(a.rows), // m
+			uintptr(a.cols), // n
+			1.0,             // alpha
+			a.data,          // a
+			uintptr(a.cols), // lda
+			b.data,          // x
+			1.0,             // incX
+			0.0,             // beta
+			out.data,        // y
+			1.0,             // incY
+		)
+		return
+	}
+	gemm(false, false, a, b, out)
+}
+
+// gemm computes the matrix multiplication op(a)×op(b), where op transposes
+// its argument if the corresponding flag is true, adding the result to out.
+// Large multiplications are computed in parallel (see SetMatMulThreads).
+func gemm(aTrans, bTrans bool, a, b, out *Dense) {
+	k := a.cols
+	if aTrans {
+		k = a.rows
+	}
+	if threads := MatMulThreads(); threads > 1 && out.rows*out.cols*k >= matMulParallelThreshold {
+		f64.DgemmParallel(
+			aTrans,
+			bTrans,
+			out.rows, // m
+			out.cols, // n
+			k,        // k
+			a.data,   // a
+			a.cols,   // lda
+			b.data,   // b
+			b.cols,   // ldb
+			out.data, // c
+			out.cols, // ldc
+			1.0,      // alpha
+			threads,  // workers
+		)
+		return
+	}
+	f64.DgemmSerial(
+		aTrans,
+		bTrans,
+		out.rows, // m
+		out.cols, // n
+		k,        // k
+		a.data,   // a
+		a.cols,   // lda
+		b.data,   // b
+		b.cols,   // ldb
+		out.data, // c
+		out.cols, // ldc
+		1.0,      // alpha
+	)
+}
+
+// Inverse computes the inverse of the square matrix a by LU decomposition,
+// storing the result in out.
+func (GoBackend) Inverse(a, out *Dense) {
+	n := a.cols
+	s := NewEmptyDense(n, n)
+	l, u, p := a.LU()
+	for b := 0; b < n; b++ {
+		// find solution of Ly = b
+		for i := 0; i < l.Rows(); i++ {
+			sum := 0.0
+			for j := 0; j < i; j++ {
+				sum += l.data[i*n+j] * s.data[j*n+b]
+			}
+			s.data[i*n+b] = p.data[i*n+b] - sum
+		}
+		// find solution of Ux = y
+		for i := n - 1; i >= 0; i-- {
+			sum := 0.0
+			for j := i + 1; j < n; j++ {
+				sum += u.data[i*n+j] * out.data[j*n+b]
+			}
+			out.data[i*n+b] = (1.0 / u.data[i*n+i]) * (s.data[i*n+b] - sum)
+		}
+	}
+}
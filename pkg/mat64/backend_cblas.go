@@ -0,0 +1,181 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build cblas
+
+package mat64
+
+/*
+#cgo LDFLAGS: -lopenblas
+#include <cblas.h>
+#include <lapacke.h>
+*/
+import "C"
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// CBLASBackend is a Backend bridging to a system BLAS/LAPACK library through
+// cgo. It is only available when building with the "cblas" tag, in which
+// case it is also set as the default Backend.
+//
+// By default it links against OpenBLAS; a different implementation (e.g.
+// Intel MKL) can be used by overriding the linker flags with the CGO_LDFLAGS
+// environment variable.
+type CBLASBackend struct{}
+
+var (
+	_ Backend              = CBLASBackend{}
+	_ DecompositionBackend = CBLASBackend{}
+)
+
+func init() {
+	SetBackend(CBLASBackend{})
+}
+
+// Mul performs the matrix multiplication a×b, storing the result in out.
+// Matrix-vector multiplications are delegated to the GoBackend, since they
+// don't take any significant advantage from BLAS.
+func (CBLASBackend) Mul(a, b, out *Dense) {
+	if out.cols == 1 || a.size == 0 || b.size == 0 {
+		GoBackend{}.Mul(a, b, out)
+		return
+	}
+	C.cblas_dgemm(
+		C.CblasRowMajor,
+		C.CblasNoTrans,
+		C.CblasNoTrans,
+		C.int(a.rows), // m
+		C.int(b.cols), // n
+		C.int(a.cols), // k
+		C.double(1.0), // alpha
+		(*C.double)(unsafe.Pointer(&a.data[0])),
+		C.int(a.cols), // lda
+		(*C.double)(unsafe.Pointer(&b.data[0])),
+		C.int(b.cols), // ldb
+		C.double(0.0), // beta
+		(*C.double)(unsafe.Pointer(&out.data[0])),
+		C.int(out.cols), // ldc
+	)
+}
+
+// Inverse computes the inverse of the square matrix a, storing the result
+// in out. It panics if the matrix is singular.
+func (CBLASBackend) Inverse(a, out *Dense) {
+	n := a.cols
+	if n == 0 {
+		return
+	}
+	copy(out.data, a.data)
+	ipiv := make([]C.lapack_int, n)
+	data := (*C.double)(unsafe.Pointer(&out.data[0]))
+	if info := C.LAPACKE_dgetrf(C.LAPACK_ROW_MAJOR, C.lapack_int(n), C.lapack_int(n), data, C.lapack_int(n), &ipiv[0]); info != 0 {
+		panic("mat64: LU factorization failed, the matrix may be singular")
+	}
+	if info := C.LAPACKE_dgetri(C.LAPACK_ROW_MAJOR, C.lapack_int(n), data, C.lapack_int(n), &ipiv[0]); info != 0 {
+		panic("mat64: matrix inversion failed, the matrix is singular")
+	}
+}
+
+// SVD computes the thin singular value decomposition of a with the LAPACK
+// divide and conquer driver (gesdd).
+func (CBLASBackend) SVD(a *Dense) (u *Dense, s []Float, vt *Dense, err error) {
+	if a.size == 0 {
+		return GoBackend{}.SVD(a)
+	}
+	m, n := a.rows, a.cols
+	k := m
+	if n < k {
+		k = n
+	}
+	data := a.Clone().(*Dense).data
+	u, s, vt = NewEmptyDense(m, k), make([]Float, k), NewEmptyDense(k, n)
+	info := C.LAPACKE_dgesdd(C.LAPACK_ROW_MAJOR, C.char('S'), C.lapack_int(m), C.lapack_int(n),
+		(*C.double)(unsafe.Pointer(&data[0])), C.lapack_int(n),
+		(*C.double)(unsafe.Pointer(&s[0])),
+		(*C.double)(unsafe.Pointer(&u.data[0])), C.lapack_int(k),
+		(*C.double)(unsafe.Pointer(&vt.data[0])), C.lapack_int(n))
+	if info != 0 {
+		return nil, nil, nil, ErrNoConvergence
+	}
+	return u, s, vt, nil
+}
+
+// EigenSym computes the eigendecomposition of the symmetric matrix a with the
+// LAPACK divide and conquer driver (syevd), reading its upper triangle.
+func (CBLASBackend) EigenSym(a *Dense) (values []Float, vectors *Dense, err error) {
+	if a.size == 0 {
+		return GoBackend{}.EigenSym(a)
+	}
+	n := a.cols
+	vectors = a.Clone().(*Dense)
+	values = make([]Float, n)
+	info := C.LAPACKE_dsyevd(C.LAPACK_ROW_MAJOR, C.char('V'), C.char('U'), C.lapack_int(n),
+		(*C.double)(unsafe.Pointer(&vectors.data[0])), C.lapack_int(n),
+		(*C.double)(unsafe.Pointer(&values[0])))
+	if info != 0 {
+		return nil, nil, ErrNoConvergence
+	}
+	return values, vectors, nil
+}
+
+// QR computes the thin QR decomposition of a with the LAPACK Householder
+// routines (geqrf and orgqr).
+func (CBLASBackend) QR(a *Dense) (q, r *Dense, err error) {
+	if a.size == 0 {
+		return GoBackend{}.QR(a)
+	}
+	m, n := a.rows, a.cols
+	k := m
+	if n < k {
+		k = n
+	}
+	w := a.Clone().(*Dense)
+	tau := make([]Float, k)
+	data := (*C.double)(unsafe.Pointer(&w.data[0]))
+	if info := C.LAPACKE_dgeqrf(C.LAPACK_ROW_MAJOR, C.lapack_int(m), C.lapack_int(n), data, C.lapack_int(n),
+		(*C.double)(unsafe.Pointer(&tau[0]))); info != 0 {
+		return nil, nil, fmt.Errorf("mat64: QR factorization failed with info %d", int(info))
+	}
+	r = NewEmptyDense(k, n)
+	for i := 0; i < k; i++ {
+		copy(r.data[i*n+i:(i+1)*n], w.data[i*n+i:(i+1)*n])
+	}
+	if info := C.LAPACKE_dorgqr(C.LAPACK_ROW_MAJOR, C.lapack_int(m), C.lapack_int(k), C.lapack_int(k), data, C.lapack_int(n),
+		(*C.double)(unsafe.Pointer(&tau[0]))); info != 0 {
+		return nil, nil, fmt.Errorf("mat64: QR factorization failed with info %d", int(info))
+	}
+	q = NewEmptyDense(m, k)
+	for i := 0; i < m; i++ {
+		copy(q.data[i*k:(i+1)*k], w.data[i*n:i*n+k])
+	}
+	normalizeQRSigns(q, r)
+	return q, r, nil
+}
+
+// Cholesky computes the Cholesky decomposition of a with the LAPACK routine
+// potrf, reading its lower triangle.
+func (CBLASBackend) Cholesky(a *Dense) (l *Dense, err error) {
+	if a.size == 0 {
+		return GoBackend{}.Cholesky(a)
+	}
+	n := a.cols
+	l = a.Clone().(*Dense)
+	info := C.LAPACKE_dpotrf(C.LAPACK_ROW_MAJOR, C.char('L'), C.lapack_int(n),
+		(*C.double)(unsafe.Pointer(&l.data[0])), C.lapack_int(n))
+	if info > 0 {
+		return nil, ErrNotPositiveDefinite
+	}
+	if info < 0 {
+		return nil, fmt.Errorf("mat64: Cholesky factorization failed with info %d", int(info))
+	}
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			l.data[i*n+j] = 0
+		}
+	}
+	return l, nil
+}
@@ -0,0 +1,546 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mat64
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+var (
+	// ErrNotSquare is returned by the decompositions requiring a square matrix.
+	ErrNotSquare = errors.New("mat64: matrix must be square")
+	// ErrNotPositiveDefinite is returned by the Cholesky decomposition of a
+	// matrix which is not (numerically) positive definite.
+	ErrNotPositiveDefinite = errors.New("mat64: matrix is not positive definite")
+	// ErrNoConvergence is returned by the iterative decompositions which
+	// don't converge.
+	ErrNoConvergence = errors.New("mat64: decomposition failed to converge")
+	// ErrNotFinite is returned by the decompositions of a matrix containing
+	// NaN or infinite values.
+	ErrNotFinite = errors.New("mat64: matrix contains NaN or infinite values")
+)
+
+// DecompositionBackend is an optional interface implemented by the backends
+// providing the matrix decompositions. When the current Backend doesn't
+// implement it, the decompositions of a Dense matrix are computed by the
+// GoBackend.
+// The matrices are already verified to be finite (and square, if required)
+// by the methods of Dense.
+type DecompositionBackend interface {
+	// SVD computes the thin singular value decomposition a = u × diag(s) × vt.
+	// With k = min(rows, columns), u is rows×k, s has size k and vt is
+	// k×columns. The singular values are sorted in descending order.
+	SVD(a *Dense) (u *Dense, s []Float, vt *Dense, err error)
+	// EigenSym computes the eigendecomposition of the symmetric matrix a,
+	// returning the eigenvalues in ascending order, and the corresponding
+	// eigenvectors as the columns of a matrix.
+	EigenSym(a *Dense) (values []Float, vectors *Dense, err error)
+	// QR computes the thin QR decomposition a = q × r. With
+	// k = min(rows, columns), q is a rows×k matrix with orthonormal columns,
+	// and r is a k×columns upper triangular matrix with non-negative diagonal.
+	QR(a *Dense) (q, r *Dense, err error)
+	// Cholesky computes the decomposition a = l × lᵀ of the symmetric positive
+	// definite matrix a, where l is lower triangular with positive diagonal.
+	// It reads the lower triangle of a.
+	Cholesky(a *Dense) (l *Dense, err error)
+}
+
+var _ DecompositionBackend = GoBackend{}
+
+// decompositionBackend returns the current Backend if it implements
+// DecompositionBackend, otherwise the GoBackend.
+func decompositionBackend() DecompositionBackend {
+	if b, ok := backend.(DecompositionBackend); ok {
+		return b
+	}
+	return GoBackend{}
+}
+
+// SVD computes the thin singular value decomposition d = u × diag(s) × vt.
+// With k = min(rows, columns), u is rows×k, s has size k and vt is k×columns.
+// The singular values are sorted in descending order.
+func (d *Dense) SVD() (u *Dense, s []Float, vt *Dense, err error) {
+	if err := checkFinite(d); err != nil {
+		return nil, nil, nil, err
+	}
+	return decompositionBackend().SVD(d)
+}
+
+// EigenSym computes the eigendecomposition of the symmetric matrix d,
+// returning the eigenvalues in ascending order, and the corresponding
+// eigenvectors as the columns of a matrix. The symmetry is not verified.
+func (d *Dense) EigenSym() (values []Float, vectors *Dense, err error) {
+	if err := checkSquareFinite(d); err != nil {
+		return nil, nil, err
+	}
+	return decompositionBackend().EigenSym(d)
+}
+
+// QR computes the thin QR decomposition d = q × r. With k = min(rows, columns),
+// q is a rows×k matrix with orthonormal columns, and r is a k×columns upper
+// triangular matrix with non-negative diagonal.
+func (d *Dense) QR() (q, r *Dense, err error) {
+	if err := checkFinite(d); err != nil {
+		return nil, nil, err
+	}
+	return decompositionBackend().QR(d)
+}
+
+// Cholesky computes the decomposition d = l × lᵀ of the symmetric positive
+// definite matrix d (e.g. a covariance matrix), where l is lower triangular
+// with positive diagonal. Only the lower triangle of d is read.
+// It returns ErrNotPositiveDefinite if the decomposition doesn't exist; a
+// small multiple of the identity (a "jitter") can be added to a positive
+// semi-definite matrix to make it positive definite.
+func (d *Dense) Cholesky() (l *Dense, err error) {
+	if err := checkSquareFinite(d); err != nil {
+		return nil, err
+	}
+	return decompositionBackend().Cholesky(d)
+}
+
+// CholeskySolve solves the system (l × lᵀ) × x = b, given the Cholesky factor
+// l of the matrix of the system (see Dense.Cholesky), by forward and backward
+// substitution. The columns of b are the right-hand sides.
+func CholeskySolve(l, b *Dense) (*Dense, error) {
+	n := l.rows
+	if l.cols != n {
+		return nil, ErrNotSquare
+	}
+	if b.rows != n {
+		return nil, fmt.Errorf("mat64: the right-hand side has %d rows, expected %d", b.rows, n)
+	}
+	l.sync()
+	for i := 0; i < n; i++ {
+		if l.data[i*n+i] <= 0 {
+			return nil, ErrNotPositiveDefinite
+		}
+	}
+	cols := b.cols
+	x := b.Clone().(*Dense)
+	for c := 0; c < cols; c++ {
+		// l × y = b
+		for i := 0; i < n; i++ {
+			sum := x.data[i*cols+c]
+			for k := 0; k < i; k++ {
+				sum -= l.data[i*n+k] * x.data[k*cols+c]
+			}
+			x.data[i*cols+c] = sum / l.data[i*n+i]
+		}
+		// lᵀ × x = y
+		for i := n - 1; i >= 0; i-- {
+			sum := x.data[i*cols+c]
+			for k := i + 1; k < n; k++ {
+				sum -= l.data[k*n+i] * x.data[k*cols+c]
+			}
+			x.data[i*cols+c] = sum / l.data[i*n+i]
+		}
+	}
+	return x, nil
+}
+
+// ConditionNumber returns the condition number of the matrix in the 2-norm,
+// i.e. the ratio of its largest to its smallest singular value.
+// It is +Inf for a singular (or rank-deficient) matrix.
+func (d *Dense) ConditionNumber() (Float, error) {
+	_, s, _, err := d.SVD()
+	if err != nil {
+		return 0, err
+	}
+	if len(s) == 0 {
+		return 0, nil
+	}
+	if s[len(s)-1] == 0 {
+		return Inf(1), nil
+	}
+	return s[0] / s[len(s)-1], nil
+}
+
+// PInv returns the Moore-Penrose pseudo-inverse of the matrix, computed from
+// its singular value decomposition. The singular values not greater than
+// max(rows, columns) × ε × the largest one are treated as zero, so that the
+// pseudo-inverse of a rank-deficient matrix is well-defined; for a full-rank
+// square matrix it's the inverse.
+func (d *Dense) PInv() (*Dense, error) {
+	u, s, vt, err := d.SVD()
+	if err != nil {
+		return nil, err
+	}
+	out := NewEmptyDense(d.cols, d.rows)
+	if len(s) == 0 {
+		return out, nil
+	}
+	tolerance := Float(maxInt(d.rows, d.cols)) * jacobiEpsilon * s[0]
+	k := len(s)
+	// out = v × diag(1/s) × uᵀ
+	for i := 0; i < d.cols; i++ {
+		for j := 0; j < d.rows; j++ {
+			var sum Float
+			for l := 0; l < k; l++ {
+				if s[l] > tolerance {
+					sum += vt.data[l*d.cols+i] * u.data[j*k+l] / s[l]
+				}
+			}
+			out.data[i*d.rows+j] = sum
+		}
+	}
+	return out, nil
+}
+
+// checkFinite returns ErrNotFinite if the matrix contains NaN or infinite
+// values, which would prevent the convergence of the decompositions.
+func checkFinite(d *Dense) error {
+	d.sync()
+	for _, v := range d.data {
+		if v != v || IsInf(v, 0) {
+			return ErrNotFinite
+		}
+	}
+	return nil
+}
+
+// checkSquareFinite returns ErrNotSquare if the matrix is not square, or
+// ErrNotFinite if it contains NaN or infinite values.
+func checkSquareFinite(d *Dense) error {
+	if d.rows != d.cols {
+		return ErrNotSquare
+	}
+	return checkFinite(d)
+}
+
+const (
+	// jacobiMaxSweeps is the maximum number of sweeps of the Jacobi methods.
+	jacobiMaxSweeps = 100
+	// jacobiEpsilon is the relative tolerance of the Jacobi methods.
+	jacobiEpsilon Float = 1.0e-14
+)
+
+// SVD computes the thin singular value decomposition of a with the one-sided
+// Jacobi method.
+func (GoBackend) SVD(a *Dense) (u *Dense, s []Float, vt *Dense, err error) {
+	if a.rows < a.cols {
+		// a = (aᵀ)ᵀ = (u' s v'ᵀ)ᵀ = v' s u'ᵀ
+		ut, s, v, err := GoBackend{}.SVD(a.T().(*Dense))
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		return v.T().(*Dense), s, ut.T().(*Dense), nil
+	}
+	m, n := a.rows, a.cols
+	// the columns of a, progressively orthogonalized, and the accumulated rotations
+	cols := make([][]Float, n)
+	rots := make([][]Float, n)
+	for j := 0; j < n; j++ {
+		cols[j] = make([]Float, m)
+		for i := 0; i < m; i++ {
+			cols[j][i] = a.data[i*n+j]
+		}
+		rots[j] = make([]Float, n)
+		rots[j][j] = 1
+	}
+
+	// the columns whose squared norm is below negligible are numerically zero,
+	// so they are not rotated (the rounding errors would never vanish)
+	var negligible Float
+	for _, col := range cols {
+		negligible += dot(col, col)
+	}
+	negligible *= jacobiEpsilon * jacobiEpsilon
+	converged := false
+	for sweep := 0; sweep < jacobiMaxSweeps && !converged; sweep++ {
+		rotated := false
+		for p := 0; p < n-1; p++ {
+			for q := p + 1; q < n; q++ {
+				alpha, beta, gamma := dot(cols[p], cols[p]), dot(cols[q], cols[q]), dot(cols[p], cols[q])
+				if gamma == 0 || Abs(gamma) <= jacobiEpsilon*Sqrt(alpha*beta) || alpha <= negligible || beta <= negligible {
+					continue
+				}
+				rotated = true
+				c, s := jacobiRotation((beta - alpha) / (2 * gamma))
+				rotate(cols[p], cols[q], c, s)
+				rotate(rots[p], rots[q], c, s)
+			}
+		}
+		converged = !rotated
+	}
+	if !converged {
+		return nil, nil, nil, ErrNoConvergence
+	}
+
+	norms := make([]Float, n)
+	for j, col := range cols {
+		norms[j] = Sqrt(dot(col, col))
+	}
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return norms[order[i]] > norms[order[j]]
+	})
+
+	u = NewEmptyDense(m, n)
+	vt = NewEmptyDense(n, n)
+	s = make([]Float, n)
+	if n == 0 {
+		return u, s, vt, nil
+	}
+	tolerance := jacobiEpsilon * norms[order[0]]
+	basis := make([][]Float, 0, n)
+	for k, j := range order {
+		s[k] = norms[j]
+		copy(vt.data[k*n:(k+1)*n], rots[j])
+		var col []Float
+		if norms[j] > tolerance && norms[j] > 0 {
+			col = make([]Float, m)
+			for i, v := range cols[j] {
+				col[i] = v / norms[j]
+			}
+		} else {
+			s[k] = 0
+			col = orthonormalComplement(basis, m)
+		}
+		basis = append(basis, col)
+		for i, v := range col {
+			u.data[i*n+k] = v
+		}
+	}
+	return u, s, vt, nil
+}
+
+// EigenSym computes the eigendecomposition of the symmetric matrix a with
+// the cyclic Jacobi method.
+func (GoBackend) EigenSym(a *Dense) (values []Float, vectors *Dense, err error) {
+	n := a.cols
+	w := a.Clone().(*Dense).data
+	v := I(n).data
+
+	var norm Float
+	for _, x := range w {
+		norm += x * x
+	}
+	converged := false
+	for sweep := 0; sweep < jacobiMaxSweeps; sweep++ {
+		var off Float
+		for p := 0; p < n-1; p++ {
+			for q := p + 1; q < n; q++ {
+				off += w[p*n+q] * w[p*n+q]
+			}
+		}
+		if off <= jacobiEpsilon*jacobiEpsilon*norm {
+			converged = true
+			break
+		}
+		for p := 0; p < n-1; p++ {
+			for q := p + 1; q < n; q++ {
+				apq := w[p*n+q]
+				if apq == 0 {
+					continue
+				}
+				c, s := jacobiRotation((w[q*n+q] - w[p*n+p]) / (2 * apq))
+				for k := 0; k < n; k++ { // columns
+					akp, akq := w[k*n+p], w[k*n+q]
+					w[k*n+p], w[k*n+q] = c*akp-s*akq, s*akp+c*akq
+				}
+				for k := 0; k < n; k++ { // rows
+					apk, aqk := w[p*n+k], w[q*n+k]
+					w[p*n+k], w[q*n+k] = c*apk-s*aqk, s*apk+c*aqk
+				}
+				for k := 0; k < n; k++ {
+					vkp, vkq := v[k*n+p], v[k*n+q]
+					v[k*n+p], v[k*n+q] = c*vkp-s*vkq, s*vkp+c*vkq
+				}
+			}
+		}
+	}
+
+	if !converged {
+		return nil, nil, ErrNoConvergence
+	}
+
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return w[order[i]*n+order[i]] < w[order[j]*n+order[j]]
+	})
+	values = make([]Float, n)
+	vectors = NewEmptyDense(n, n)
+	for k, j := range order {
+		values[k] = w[j*n+j]
+		for i := 0; i < n; i++ {
+			vectors.data[i*n+k] = v[i*n+j]
+		}
+	}
+	return values, vectors, nil
+}
+
+// QR computes the thin QR decomposition of a with Householder reflections.
+func (GoBackend) QR(a *Dense) (q, r *Dense, err error) {
+	m, n := a.rows, a.cols
+	k := m
+	if n < k {
+		k = n
+	}
+	w := a.Clone().(*Dense).data
+	reflectors := make([][]Float, k)
+	for j := 0; j < k; j++ {
+		var norm Float
+		for i := j; i < m; i++ {
+			norm += w[i*n+j] * w[i*n+j]
+		}
+		if norm == 0 {
+			continue
+		}
+		norm = Sqrt(norm)
+		if w[j*n+j] < 0 {
+			norm = -norm
+		}
+		v := make([]Float, m-j)
+		for i := j; i < m; i++ {
+			v[i-j] = w[i*n+j]
+		}
+		v[0] += norm
+		reflectors[j] = v
+		applyReflector(v, w, j, j, n)
+	}
+
+	r = NewEmptyDense(k, n)
+	for i := 0; i < k; i++ {
+		copy(r.data[i*n+i:(i+1)*n], w[i*n+i:(i+1)*n])
+	}
+	q = NewEmptyDense(m, k)
+	for i := 0; i < k; i++ {
+		q.data[i*k+i] = 1
+	}
+	for j := k - 1; j >= 0; j-- {
+		if reflectors[j] != nil {
+			applyReflector(reflectors[j], q.data, j, 0, k)
+		}
+	}
+	normalizeQRSigns(q, r)
+	return q, r, nil
+}
+
+// Cholesky computes the Cholesky decomposition of a with the
+// Cholesky–Banachiewicz algorithm.
+func (GoBackend) Cholesky(a *Dense) (l *Dense, err error) {
+	n := a.rows
+	l = NewEmptyDense(n, n)
+	for i := 0; i < n; i++ {
+		for j := 0; j <= i; j++ {
+			sum := a.data[i*n+j]
+			for k := 0; k < j; k++ {
+				sum -= l.data[i*n+k] * l.data[j*n+k]
+			}
+			if i != j {
+				l.data[i*n+j] = sum / l.data[j*n+j]
+				continue
+			}
+			if !(sum > 0) { // also NaN
+				return nil, ErrNotPositiveDefinite
+			}
+			l.data[i*n+i] = Sqrt(sum)
+		}
+	}
+	return l, nil
+}
+
+// applyReflector applies the Householder reflection H = I - 2vvᵀ/vᵀv to the
+// rows from row0 and the columns from col0 of the matrix data, with the given
+// number of columns.
+func applyReflector(v, data []Float, row0, col0, cols int) {
+	vv := dot(v, v)
+	for c := col0; c < cols; c++ {
+		var d Float
+		for i, x := range v {
+			d += x * data[(row0+i)*cols+c]
+		}
+		f := 2 * d / vv
+		for i, x := range v {
+			data[(row0+i)*cols+c] -= f * x
+		}
+	}
+}
+
+// normalizeQRSigns makes the diagonal of r non-negative, changing the signs of
+// the corresponding rows of r and columns of q.
+func normalizeQRSigns(q, r *Dense) {
+	k := q.cols
+	for i := 0; i < k; i++ {
+		if r.data[i*r.cols+i] >= 0 {
+			continue
+		}
+		for j := i; j < r.cols; j++ {
+			r.data[i*r.cols+j] = -r.data[i*r.cols+j]
+		}
+		for j := 0; j < q.rows; j++ {
+			q.data[j*k+i] = -q.data[j*k+i]
+		}
+	}
+}
+
+// jacobiRotation returns the cosine and sine of the Jacobi rotation for the
+// given cot(2θ), choosing the smaller angle.
+func jacobiRotation(cot2 Float) (c, s Float) {
+	t := 1 / (Abs(cot2) + Sqrt(1+cot2*cot2))
+	if cot2 < 0 {
+		t = -t
+	}
+	c = 1 / Sqrt(1+t*t)
+	return c, c * t
+}
+
+// rotate applies a plane rotation to the vectors x and y.
+func rotate(x, y []Float, c, s Float) {
+	for i, a := range x {
+		b := y[i]
+		x[i], y[i] = c*a-s*b, s*a+c*b
+	}
+}
+
+func dot(x, y []Float) Float {
+	var sum Float
+	for i, v := range x {
+		sum += v * y[i]
+	}
+	return sum
+}
+
+// orthonormalComplement returns a unit vector of the given size orthogonal to
+// the orthonormal vectors of the basis, obtained by Gram-Schmidt from the
+// first suitable vector of the standard basis.
+func orthonormalComplement(basis [][]Float, size int) []Float {
+	out := make([]Float, size)
+	for e := 0; e < size; e++ {
+		for i := range out {
+			out[i] = 0
+		}
+		out[e] = 1
+		for _, b := range basis {
+			p := b[e] // dot(out, b) before the projections, since out = eₑ
+			for i := range out {
+				out[i] -= p * b[i]
+			}
+		}
+		// a second pass improves the orthogonality
+		for _, b := range basis {
+			p := dot(out, b)
+			for i := range out {
+				out[i] -= p * b[i]
+			}
+		}
+		if norm := Sqrt(dot(out, out)); norm > 0.5 {
+			for i := range out {
+				out[i] /= norm
+			}
+			return out
+		}
+	}
+	return out
+}
@@ -0,0 +1,201 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mat64
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDense_QR(t *testing.T) {
+	for _, a := range []*Dense{
+		NewDense(3, 3, []Float{12, -51, 4, 6, 167, -68, -4, 24, -41}),
+		NewDense(4, 2, []Float{1, 2, 3, 4, 5, 6, 7, 8}),
+		NewDense(2, 3, []Float{1, 2, 3, 4, 5, 6}),
+	} {
+		q, r, err := a.QR()
+		assert.NoError(t, err)
+		k := minInt(a.Rows(), a.Columns())
+		assert.Equal(t, []int{a.Rows(), k}, []int{q.Rows(), q.Columns()})
+		assert.Equal(t, []int{k, a.Columns()}, []int{r.Rows(), r.Columns()})
+		assert.InDeltaSlice(t, a.Data(), q.Mul(r).Data(), 1.0e-4)
+		assert.InDeltaSlice(t, I(k).Data(), q.T().Mul(q).Data(), 1.0e-5)
+		for i := 0; i < r.Rows(); i++ {
+			assert.True(t, r.At(i, i) >= 0)
+			for j := 0; j < i; j++ {
+				assert.Equal(t, Float(0), r.At(i, j))
+			}
+		}
+	}
+
+	q, r, err := NewDense(3, 3, []Float{12, -51, 4, 6, 167, -68, -4, 24, -41}).QR()
+	assert.NoError(t, err)
+	assert.InDeltaSlice(t, []Float{14, 21, -14, 0, 175, -70, 0, 0, 35}, r.Data(), 1.0e-4)
+	assert.InDeltaSlice(t, []Float{
+		6.0 / 7, -69.0 / 175, -58.0 / 175,
+		3.0 / 7, 158.0 / 175, 6.0 / 175,
+		-2.0 / 7, 6.0 / 35, -33.0 / 35,
+	}, q.Data(), 1.0e-5)
+}
+
+func TestDense_SVD(t *testing.T) {
+	for _, a := range []*Dense{
+		NewDense(3, 2, []Float{3, 2, 2, 3, 2, -2}),
+		NewDense(2, 3, []Float{3, 2, 2, 2, 3, -2}),
+		NewDense(3, 3, []Float{1, 2, 3, 2, 4, 6, 1, 0, 1}), // rank 2
+	} {
+		u, s, vt, err := a.SVD()
+		assert.NoError(t, err)
+		k := minInt(a.Rows(), a.Columns())
+		assert.Equal(t, []int{a.Rows(), k}, []int{u.Rows(), u.Columns()})
+		assert.Len(t, s, k)
+		assert.Equal(t, []int{k, a.Columns()}, []int{vt.Rows(), vt.Columns()})
+		for i := 1; i < k; i++ {
+			assert.True(t, s[i-1] >= s[i])
+		}
+		rec := u.Mul(diag(s)).Mul(vt)
+		assert.InDeltaSlice(t, a.Data(), rec.Data(), 1.0e-4)
+		assert.InDeltaSlice(t, I(k).Data(), u.T().Mul(u).Data(), 1.0e-5)
+		assert.InDeltaSlice(t, I(k).Data(), vt.Mul(vt.T()).Data(), 1.0e-5)
+	}
+
+	_, s, _, err := NewDense(2, 3, []Float{3, 2, 2, 2, 3, -2}).SVD()
+	assert.NoError(t, err)
+	assert.InDeltaSlice(t, []Float{5, 3}, s, 1.0e-5)
+
+	_, _, _, err = NewDense(2, 2, []Float{1, NaN(), 0, 1}).SVD()
+	assert.Equal(t, ErrNotFinite, err)
+}
+
+func TestDense_EigenSym(t *testing.T) {
+	a := NewDense(3, 3, []Float{
+		2, -1, 0,
+		-1, 2, -1,
+		0, -1, 2,
+	})
+	values, vectors, err := a.EigenSym()
+	assert.NoError(t, err)
+	sqrt2 := Sqrt(2)
+	assert.InDeltaSlice(t, []Float{2 - sqrt2, 2, 2 + sqrt2}, values, 1.0e-5)
+	for k, value := range values {
+		v := vectors.ExtractColumn(k)
+		assert.InDeltaSlice(t, v.ProdScalar(value).Data(), a.Mul(v).Data(), 1.0e-5)
+	}
+	assert.InDeltaSlice(t, I(3).Data(), vectors.T().Mul(vectors).Data(), 1.0e-5)
+	_, _, err = NewEmptyDense(2, 3).EigenSym()
+	assert.Equal(t, ErrNotSquare, err)
+	_, _, err = NewDense(1, 1, []Float{Inf(1)}).EigenSym()
+	assert.Equal(t, ErrNotFinite, err)
+}
+
+func TestDense_Cholesky(t *testing.T) {
+	a := NewDense(3, 3, []Float{
+		4, 12, -16,
+		12, 37, -43,
+		-16, -43, 98,
+	})
+	l, err := a.Cholesky()
+	assert.NoError(t, err)
+	assert.InDeltaSlice(t, []Float{
+		2, 0, 0,
+		6, 1, 0,
+		-8, 5, 3,
+	}, l.Data(), 1.0e-5)
+	assert.InDeltaSlice(t, a.Data(), l.Mul(l.T()).Data(), 1.0e-4)
+
+	_, err = NewDense(2, 2, []Float{1, 2, 2, 1}).Cholesky()
+	assert.Equal(t, ErrNotPositiveDefinite, err)
+	_, err = NewEmptyDense(2, 3).Cholesky()
+	assert.Equal(t, ErrNotSquare, err)
+	_, err = NewDense(1, 1, []Float{NaN()}).Cholesky()
+	assert.Equal(t, ErrNotFinite, err)
+}
+
+func TestCholeskySolve(t *testing.T) {
+	a := NewDense(3, 3, []Float{
+		4, 12, -16,
+		12, 37, -43,
+		-16, -43, 98,
+	})
+	l, err := a.Cholesky()
+	assert.NoError(t, err)
+	b := NewDense(3, 2, []Float{
+		1, 0,
+		2, 1,
+		3, -1,
+	})
+	x, err := CholeskySolve(l, b)
+	assert.NoError(t, err)
+	assert.InDeltaSlice(t, b.Data(), a.Mul(x).Data(), 1.0e-3)
+
+	_, err = CholeskySolve(l, NewEmptyDense(2, 1))
+	assert.Error(t, err)
+	_, err = CholeskySolve(NewEmptyDense(2, 3), b)
+	assert.Equal(t, ErrNotSquare, err)
+	_, err = CholeskySolve(NewEmptyDense(3, 3), b)
+	assert.Equal(t, ErrNotPositiveDefinite, err)
+}
+
+func TestDense_ConditionNumber(t *testing.T) {
+	for _, tc := range []struct {
+		a        *Dense
+		expected Float
+	}{
+		{I(3), 1},
+		{NewDense(2, 2, []Float{4, 0, 0, 1}), 4},
+	} {
+		c, err := tc.a.ConditionNumber()
+		assert.NoError(t, err)
+		assert.InDelta(t, tc.expected, c, 1.0e-5)
+	}
+	c, err := NewDense(2, 2, []Float{1, 2, 2, 4}).ConditionNumber()
+	assert.NoError(t, err)
+	assert.Equal(t, Inf(1), c)
+
+	_, err = NewDense(1, 1, []Float{NaN()}).ConditionNumber()
+	assert.Equal(t, ErrNotFinite, err)
+}
+
+func diag(v []Float) *Dense {
+	out := NewEmptyDense(len(v), len(v))
+	for i, x := range v {
+		out.Set(i, i, x)
+	}
+	return out
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func TestDense_PInv(t *testing.T) {
+	// a full-rank square matrix: the inverse
+	a := NewDense(2, 2, []Float{4, 7, 2, 6})
+	p, err := a.PInv()
+	assert.NoError(t, err)
+	assert.InDeltaSlice(t, []Float{0.6, -0.7, -0.2, 0.4}, p.Data(), 1.0e-5)
+
+	// a tall full-rank matrix: the left inverse
+	a = NewDense(3, 2, []Float{1, 0, 0, 1, 1, 1})
+	p, err = a.PInv()
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2, 3}, []int{p.Rows(), p.Columns()})
+	assert.InDeltaSlice(t, I(2).Data(), p.Mul(a).Data(), 1.0e-5)
+
+	// a rank-deficient matrix: the Moore-Penrose conditions hold
+	a = NewDense(2, 3, []Float{1, 2, 3, 2, 4, 6})
+	p, err = a.PInv()
+	assert.NoError(t, err)
+	assert.InDeltaSlice(t, a.Data(), a.Mul(p).Mul(a).Data(), 1.0e-4)
+	assert.InDeltaSlice(t, p.Data(), p.Mul(a).Mul(p).Data(), 1.0e-4)
+	assert.InDeltaSlice(t, []Float{0.2, 0.4, 0.4, 0.8}, a.Mul(p).Data(), 1.0e-4)
+
+	_, err = NewDense(1, 1, []Float{NaN()}).PInv()
+	assert.Equal(t, ErrNotFinite, err)
+}
@@ -24,6 +24,8 @@ type Dense struct {
 	data     []Float
 	viewOf   *Dense // default nil
 	fromPool bool
+	device   Device        // default nil (Host)
+	memory   *deviceMemory // default nil (values held by the host)
 }
 
 func init() {
@@ -103,13 +105,16 @@ func (d *Dense) SetData(data []Float) {
 	if len(data) != d.size {
 		panic(fmt.Sprintf("mat64: incompatible data size. Expected: %d Found: %d", d.size, len(data)))
 	}
+	d.sync()
 	copy(d.data, data)
 }
 
 // ZerosLike returns a new Dense matrix with the same dimensions of the receiver,
 // initialized with zeroes.
 func (d *Dense) ZerosLike() Matrix {
-	return NewEmptyDense(d.rows, d.cols)
+	out := NewEmptyDense(d.rows, d.cols)
+	out.device = d.device
+	return out
 }
 
 // OnesLike returns a new Dense matrix with the same dimensions of the receiver,
@@ -120,12 +125,16 @@ func (d *Dense) OnesLike() Matrix {
 	for i := range data {
 		data[i] = 1.0
 	}
+	out.device = d.device
 	return out
 }
 
 // Clone returns a new Dense matrix, copying all its values from the receiver.
 func (d *Dense) Clone() Matrix {
-	return NewDense(d.rows, d.cols, d.data)
+	d.sync()
+	out := NewDense(d.rows, d.cols, d.data)
+	out.device = d.device
+	return out
 }
 
 // Copy copies the data from the other matrix to the receiver.
@@ -138,6 +147,7 @@ func (d *Dense) Copy(other Matrix) {
 	if other, ok := other.(*Dense); !ok {
 		panic("mat64: incompatible matrix types.")
 	} else {
+		syncAll(d, other)
 		copy(d.data, other.data)
 	}
 }
@@ -154,11 +164,13 @@ func (d *Dense) View(rows, cols int) *Dense {
 		data:     d.data,
 		viewOf:   d,
 		fromPool: false,
+		device:   d.device,
 	}
 }
 
 // Zeros sets all the values of the matrix to zero.
 func (d *Dense) Zeros() {
+	d.sync()
 	data := d.data // avoid bounds check
 	for i := range data {
 		data[i] = 0.0
@@ -193,6 +205,7 @@ func (d *Dense) LastIndex() int {
 
 // Data returns the underlying data of the matrix, as a raw one-dimensional slice of values.
 func (d *Dense) Data() []Float {
+	d.sync()
 	return d.data
 }
 
@@ -212,82 +225,92 @@ func (d *Dense) Scalar() Float {
 	if !d.IsScalar() {
 		panic("mat64: expected scalar but the matrix contains more elements.")
 	}
+	d.sync()
 	return d.data[0]
 }
 
 // Set sets the value v at row i and column j.
 // It panics if the given indices are out of range.
 func (d *Dense) Set(i int, j int, v Float) {
-	if i >= d.rows {
-		panic("mat64: 'i' argument out of range.")
+	if checksEnabled && i >= d.rows {
+		panic(indexError("mat64: 'i' argument out of range.", "Set", i, d))
 	}
-	if j >= d.cols {
-		panic("mat64: 'j' argument out of range")
+	if checksEnabled && j >= d.cols {
+		panic(indexError("mat64: 'j' argument out of range", "Set", j, d))
 	}
+	d.sync()
 	d.data[i*d.cols+j] = v
 }
 
 // At returns the value at row i and column j.
 // It panics if the given indices are out of range.
 func (d *Dense) At(i int, j int) Float {
-	if i >= d.rows {
-		panic("mat64: 'i' argument out of range.")
+	if checksEnabled && i >= d.rows {
+		panic(indexError("mat64: 'i' argument out of range.", "At", i, d))
 	}
-	if j >= d.cols {
-		panic("mat64: 'j' argument out of range")
+	if checksEnabled && j >= d.cols {
+		panic(indexError("mat64: 'j' argument out of range", "At", j, d))
 	}
+	d.sync()
 	return d.data[i*d.cols+j]
 }
 
 // SetVec sets the value v at position i of a vector.
 // It panics if the receiver is not a vector.
 func (d *Dense) SetVec(i int, v Float) {
-	if !(d.IsVector()) {
-		panic("mat64: expected vector")
+	if checksEnabled && !(d.IsVector()) {
+		panic(dimsError("mat64: expected vector", "SetVec", d))
 	}
-	if i >= d.size {
-		panic("mat64: 'i' argument out of range.")
+	if checksEnabled && i >= d.size {
+		panic(indexError("mat64: 'i' argument out of range.", "SetVec", i, d))
 	}
+	d.sync()
 	d.data[i] = v
 }
 
 // AtVec returns the value at position i of a vector.
 // It panics if the receiver is not a vector.
 func (d *Dense) AtVec(i int) Float {
-	if !(d.IsVector()) {
-		panic("mat64: expected vector")
+	if checksEnabled && !(d.IsVector()) {
+		panic(dimsError("mat64: expected vector", "AtVec", d))
 	}
-	if i >= d.rows {
-		panic("mat64: 'i' argument out of range.")
+	if checksEnabled && i >= d.rows {
+		panic(indexError("mat64: 'i' argument out of range.", "AtVec", i, d))
 	}
+	d.sync()
 	return d.data[i]
 }
 
 // ExtractRow returns a copy of the i-th row of the matrix.
 func (d *Dense) ExtractRow(i int) Matrix {
-	if i >= d.Rows() {
-		panic("mat64: index out of range")
+	if checksEnabled && i >= d.Rows() {
+		panic(indexError("mat64: index out of range", "ExtractRow", i, d))
 	}
+	d.sync()
 	out := NewVecDense(d.data[i*d.cols : i*d.cols+d.cols])
+	out.device = d.device
 	return out
 }
 
 // ExtractColumn returns a copy of the i-th column of the matrix.
 func (d *Dense) ExtractColumn(i int) Matrix {
-	if i >= d.Columns() {
-		panic("mat64: index out of range")
+	if checksEnabled && i >= d.Columns() {
+		panic(indexError("mat64: index out of range", "ExtractColumn", i, d))
 	}
+	d.sync()
 	//out := NewEmptyVecDense(d.rows)
 	out := GetDenseWorkspace(d.rows, 1)
 	data := out.data
 	for k := range data {
 		data[k] = d.data[k*d.cols+i]
 	}
+	out.device = d.device
 	return out
 }
 
 // T returns the transpose of the matrix.
 func (d *Dense) T() Matrix {
+	d.sync()
 	r, c := d.Dims()
 	m := GetDenseWorkspace(c, r)
 	length := len(m.data)
@@ -299,6 +322,7 @@ func (d *Dense) T() Matrix {
 			index -= length - 1
 		}
 	}
+	m.device = d.device
 	return m
 }
 
@@ -308,14 +332,18 @@ func (d *Dense) Reshape(r, c int) Matrix {
 	if d.Size() != r*c {
 		panic("mat64: incompatible sizes.")
 	}
-	return NewDense(r, c, d.data)
+	d.sync()
+	out := NewDense(r, c, d.data)
+	out.device = d.device
+	return out
 }
 
 // ApplyWithAlpha executes the unary function fn, taking additional parameters alpha.
 func (d *Dense) ApplyWithAlpha(fn func(i, j int, v Float, alpha ...Float) Float, a Matrix, alpha ...Float) {
-	if !SameDims(d, a) {
-		panic("mat64: incompatible matrix dimensions.")
+	if checksEnabled && !SameDims(d, a) {
+		panic(dimsError("mat64: incompatible matrix dimensions.", "ApplyWithAlpha", d, a))
 	}
+	d.sync()
 	for i := 0; i < d.rows; i++ {
 		for j := 0; j < d.cols; j++ {
 			d.data[i*d.cols+j] = fn(i, j, a.At(i, j), alpha...)
@@ -325,14 +353,16 @@ func (d *Dense) ApplyWithAlpha(fn func(i, j int, v Float, alpha ...Float) Float,
 
 // Apply executes the unary function fn.
 func (d *Dense) Apply(fn func(i, j int, v Float) Float, a Matrix) {
-	if !SameDims(d, a) {
-		panic("mat64: incompatible matrix dimensions.")
+	if checksEnabled && !SameDims(d, a) {
+		panic(dimsError("mat64: incompatible matrix dimensions.", "Apply", d, a))
 	}
+	d.sync()
 	dData := d.data
 	r := 0
 	c := 0
 	switch aa := a.(type) {
 	case *Dense:
+		aa.sync()
 		aData := aa.data
 		lastIndex := len(aData) - 1
 		if lastIndex < 0 {
@@ -375,12 +405,14 @@ func (d *Dense) SubScalar(n Float) Matrix {
 
 // AddScalarInPlace adds the scalar to all values of the matrix.
 func (d *Dense) AddScalarInPlace(n Float) Matrix {
+	d.sync()
 	f64.AddConst(n, d.data)
 	return d
 }
 
 // SubScalarInPlace subtracts the scalar from the receiver's values.
 func (d *Dense) SubScalarInPlace(n Float) Matrix {
+	d.sync()
 	f64.AddConst(-n, d.data)
 	return d
 }
@@ -388,73 +420,71 @@ func (d *Dense) SubScalarInPlace(n Float) Matrix {
 // ProdScalarInPlace performs the in-place multiplication between the matrix and
 // the given value.
 func (d *Dense) ProdScalarInPlace(n Float) Matrix {
-	f64.ScalUnitary(n, d.data)
+	d.Device().ProdScalar(d, n, d)
 	return d
 }
 
 // ProdMatrixScalarInPlace multiplies the given matrix with the value, storing the
 // result in the receiver.
 func (d *Dense) ProdMatrixScalarInPlace(m Matrix, n Float) Matrix {
-	f64.ScalUnitaryTo(d.data, n, m.(*Dense).data)
+	d.Device().ProdScalar(m.(*Dense), n, d)
 	return d
 }
 
 // ProdScalar returns the multiplication between the matrix and the given value.
 func (d *Dense) ProdScalar(n Float) Matrix {
 	out := d.ZerosLike().(*Dense)
-	f64.ScalUnitaryTo(out.data, n, d.data)
+	d.Device().ProdScalar(d, n, out)
 	return out
 }
 
 // Add returns the addition between the receiver and another matrix.
 func (d *Dense) Add(other Matrix) Matrix {
-	if !(SameDims(d, other) ||
+	if checksEnabled && !(SameDims(d, other) ||
 		(other.Columns() == 1 && other.Rows() == d.Rows()) ||
 		(other.IsVector() && d.IsVector() && other.Size() == d.Size())) {
-		panic("mat64: matrices with not compatible size")
+		panic(dimsError("mat64: matrices with not compatible size", "Add", d, other))
 	}
-	b := other.(*Dense)
 	out := d.ZerosLike().(*Dense)
-	f64.AxpyUnitaryTo(out.data, 1.0, b.data, d.data)
+	d.Device().Add(d, other.(*Dense), out)
 	return out
 }
 
 // AddInPlace performs the in-place addition with the other matrix.
 func (d *Dense) AddInPlace(other Matrix) Matrix {
-	if !(SameDims(d, other) ||
+	if checksEnabled && !(SameDims(d, other) ||
 		(other.Columns() == 1 && other.Rows() == d.Rows()) ||
 		(other.IsVector() && d.IsVector() && other.Size() == d.Size())) {
-		panic("mat64: matrices with not compatible size")
+		panic(dimsError("mat64: matrices with not compatible size", "AddInPlace", d, other))
 	}
-	b := other.(*Dense)
-	f64.AxpyUnitary(1.0, b.data, d.data)
+	d.Device().Add(d, other.(*Dense), d)
 	return d
 }
 
 // Sub returns the subtraction of the other matrix from the receiver.
 func (d *Dense) Sub(other Matrix) Matrix {
-	if !(SameDims(d, other) ||
+	if checksEnabled && !(SameDims(d, other) ||
 		(other.Columns() == 1 && other.Rows() == d.Rows()) ||
 		(other.IsVector() && d.IsVector() && other.Size() == d.Size())) {
-		panic("mat64: matrices with not compatible size")
+		panic(dimsError("mat64: matrices with not compatible size", "Sub", d, other))
 	}
 	out := d.ZerosLike().(*Dense)
-	b := other.(*Dense)
-	f64.AxpyUnitaryTo(out.data, -1.0, b.data, d.data)
+	d.Device().Sub(d, other.(*Dense), out)
 	return out
 }
 
 // SubInPlace performs the in-place subtraction with the other matrix.
 func (d *Dense) SubInPlace(other Matrix) Matrix {
-	if !(SameDims(d, other) ||
+	if checksEnabled && !(SameDims(d, other) ||
 		(other.Columns() == 1 && other.Rows() == d.Rows()) ||
 		(other.IsVector() && d.IsVector() && other.Size() == d.Size())) {
-		panic("mat64: matrices with not compatible size")
+		panic(dimsError("mat64: matrices with not compatible size", "SubInPlace", d, other))
 	}
 	switch other := other.(type) {
 	case *Dense:
-		f64.AxpyUnitary(-1.0, other.data, d.data)
+		d.Device().Sub(d, other, d)
 	case *Sparse:
+		d.sync()
 		other.DoNonZero(func(i, j int, k Float) {
 			d.Set(i, j, d.At(i, j)-k)
 		})
@@ -464,116 +494,68 @@ func (d *Dense) SubInPlace(other Matrix) Matrix {
 
 // Prod performs the element-wise product between the receiver and the other matrix.
 func (d *Dense) Prod(other Matrix) Matrix {
-	if !(SameDims(d, other) ||
+	if checksEnabled && !(SameDims(d, other) ||
 		(other.Columns() == 1 && other.Rows() == d.Rows()) ||
 		(other.IsVector() && d.IsVector() && other.Size() == d.Size())) {
-		panic("mat64: matrices with not compatible size")
+		panic(dimsError("mat64: matrices with not compatible size", "Prod", d, other))
 	}
 
 	out := GetDenseWorkspace(d.Dims())
-	b := other.(*Dense)
-
-	// Avoid bounds checks in loop
-	dData := d.data
-	bData := b.data
-	outData := out.data
-	lastIndex := len(bData) - 1
-	if lastIndex < 0 {
-		return out
-	}
-	_ = outData[lastIndex]
-	_ = dData[lastIndex]
-	for i := lastIndex; i >= 0; i-- {
-		outData[i] = dData[i] * bData[i]
-	}
+	out.device = d.device
+	d.Device().Prod(d, other.(*Dense), out)
 	return out
 }
 
 // ProdInPlace performs the in-place element-wise product with the other matrix.
 func (d *Dense) ProdInPlace(other Matrix) Matrix {
-	if !(SameDims(d, other) ||
+	if checksEnabled && !(SameDims(d, other) ||
 		(other.Columns() == 1 && other.Rows() == d.Rows()) ||
 		(other.IsVector() && d.IsVector() && other.Size() == d.Size())) {
-		panic("mat64: matrices with not compatible size")
-	}
-	b := other.(*Dense)
-	bData := b.data
-	dData := d.data
-	for i, val := range bData {
-		dData[i] *= val
+		panic(dimsError("mat64: matrices with not compatible size", "ProdInPlace", d, other))
 	}
+	d.Device().Prod(d, other.(*Dense), d)
 	return d
 }
 
 // Div returns the result of the element-wise division of the receiver by the other matrix.
 func (d *Dense) Div(other Matrix) Matrix {
-	if !(SameDims(d, other) ||
+	if checksEnabled && !(SameDims(d, other) ||
 		(other.Columns() == 1 && other.Rows() == d.Rows()) ||
 		(other.IsVector() && d.IsVector() && other.Size() == d.Size())) {
-		panic("mat64: matrices with not compatible size")
+		panic(dimsError("mat64: matrices with not compatible size", "Div", d, other))
 	}
 	out := d.ZerosLike().(*Dense)
-	f64.DivTo(out.data, d.data, other.(*Dense).data)
+	d.Device().Div(d, other.(*Dense), out)
 	return out
 }
 
 // DivInPlace performs the in-place element-wise division of the receiver by the other matrix.
 func (d *Dense) DivInPlace(other Matrix) Matrix {
-	if !(SameDims(d, other) ||
+	if checksEnabled && !(SameDims(d, other) ||
 		(other.Columns() == 1 && other.Rows() == d.Rows()) ||
 		(other.IsVector() && d.IsVector() && other.Size() == d.Size())) {
-		panic("mat64: matrices with not compatible size")
-	}
-	b := other.(*Dense)
-	for i, val := range b.data {
-		d.data[i] *= 1.0 / val
+		panic(dimsError("mat64: matrices with not compatible size", "DivInPlace", d, other))
 	}
+	d.Device().Div(d, other.(*Dense), d)
 	return d
 }
 
 // Mul performs the multiplication row by column.
 // If A is an i×j Matrix, and B is j×k, then the resulting Matrix C = AB will be i×k.
 func (d *Dense) Mul(other Matrix) Matrix {
-	if d.Columns() != other.Rows() {
-		panic("mat64: matrices with not compatible size")
+	if checksEnabled && d.Columns() != other.Rows() {
+		panic(dimsError("mat64: matrices with not compatible size", "Mul", d, other))
 	}
 	out := GetEmptyDenseWorkspace(d.Rows(), other.Columns())
+	out.device = d.device
 
 	switch b := other.(type) {
 	case *Dense:
-		if out.cols != 1 {
-			f64.DgemmSerial(
-				false,
-				false,
-				d.rows,   // m
-				b.cols,   // n
-				d.cols,   // k
-				d.data,   // a
-				d.cols,   // lda
-				b.data,   // b
-				b.cols,   // ldb
-				out.data, // c
-				out.cols, // ldc
-				1.0,      // alpha
-			)
-			return out
-		}
-
-		f64.GemvN(
-			uintptr(d.rows), // m
-			uintptr(d.cols), // n
-			1.0,             // alpha
-			d.data,          // a
-			uintptr(d.cols), // lda
-			b.data,          // x
-			1.0,             // incX
-			0.0,             // beta
-			out.data,        // y
-			1.0,             // incY
-		)
+		d.Device().Mul(d, b, out)
 		return out
 
 	case *Sparse:
+		d.sync()
 		b.DoNonZero(func(k, j int, v Float) {
 			for i := 0; i < d.Rows(); i++ {
 				out.Set(i, j, out.At(i, j)+d.At(i, k)*v)
@@ -590,9 +572,11 @@ func (d *Dense) MulT(other Matrix) Matrix {
 		panic("mat64: matrices with not compatible size")
 	}
 	out := GetEmptyDenseWorkspace(d.Columns(), other.Columns())
+	out.device = d.device
 
 	switch b := other.(type) {
 	case *Dense:
+		syncAll(d, b)
 		if out.cols == 1 {
 			f64.GemvT(
 				uintptr(d.rows), // m
@@ -615,16 +599,96 @@ func (d *Dense) MulT(other Matrix) Matrix {
 	return out
 }
 
+// MatMulT performs the matrix multiplication d×otherᵀ, without materializing
+// the transpose of other. If d is an i×j Matrix, and other is k×j, then the
+// resulting Matrix will be i×k.
+func (d *Dense) MatMulT(other Matrix) Matrix {
+	if checksEnabled && d.Columns() != other.Columns() {
+		panic(dimsError("mat64: matrices with not compatible size", "MatMulT", d, other))
+	}
+	b, ok := other.(*Dense)
+	if !ok {
+		bt := other.T()
+		defer ReleaseMatrix(bt)
+		return d.Mul(bt)
+	}
+	if d.Device() != Host {
+		bt := b.T()
+		defer ReleaseMatrix(bt)
+		return d.Mul(bt)
+	}
+	syncAll(d, b)
+	out := GetEmptyDenseWorkspace(d.rows, b.rows)
+	if b.rows == 1 {
+		f64.GemvN(
+			uintptr(d.rows), // m
+			uintptr(d.cols), // n
+			1.0,             // alpha
+			d.data,          // a
+			uintptr(d.cols), // lda
+			b.data,          // x
+			1.0,             // incX
+			0.0,             // beta
+			out.data,        // y
+			1.0,             // incY
+		)
+		return out
+	}
+	gemm(false, true, d, b, out)
+	return out
+}
+
+// TMatMul performs the matrix multiplication dᵀ×other, without materializing
+// the transpose of d. If d is an i×j Matrix, and other is i×k, then the
+// resulting Matrix will be j×k.
+func (d *Dense) TMatMul(other Matrix) Matrix {
+	if checksEnabled && d.Rows() != other.Rows() {
+		panic(dimsError("mat64: matrices with not compatible size", "TMatMul", d, other))
+	}
+	b, ok := other.(*Dense)
+	if !ok {
+		dt := d.T()
+		defer ReleaseMatrix(dt)
+		return dt.Mul(other)
+	}
+	if d.Device() != Host {
+		dt := d.T()
+		defer ReleaseMatrix(dt)
+		return dt.Mul(b)
+	}
+	syncAll(d, b)
+	out := GetEmptyDenseWorkspace(d.cols, b.cols)
+	if b.cols == 1 {
+		f64.GemvT(
+			uintptr(d.rows), // m
+			uintptr(d.cols), // n
+			1.0,             // alpha
+			d.data,          // a
+			uintptr(d.cols), // lda
+			b.data,          // x
+			1.0,             // incX
+			0.0,             // beta
+			out.data,        // y
+			1.0,             // incY
+		)
+		return out
+	}
+	gemm(true, false, d, b, out)
+	return out
+}
+
 // DotUnitary returns the dot product of two vectors.
 func (d *Dense) DotUnitary(other Matrix) Float {
-	if d.Size() != other.Size() {
-		panic("mat64: incompatible sizes.")
+	if checksEnabled && d.Size() != other.Size() {
+		panic(dimsError("mat64: incompatible sizes.", "DotUnitary", d, other))
 	}
+	d.sync()
 	return f64.DotUnitary(d.data, other.Data())
 }
 
 // ClipInPlace clips in place each value of the matrix.
 func (d *Dense) ClipInPlace(min, max Float) Matrix {
+	d.sync()
 	data := d.data
 	for i, v := range data {
 		if v < min {
@@ -640,7 +704,9 @@ func (d *Dense) ClipInPlace(min, max Float) Matrix {
 
 // Abs returns a new matrix applying the absolute value function to all elements.
 func (d *Dense) Abs() Matrix {
+	d.sync()
 	out := GetDenseWorkspace(d.Dims())
+	out.device = d.device
 	outData := out.data
 	for i, val := range d.data {
 		outData[i] = math.Abs(val)
@@ -651,7 +717,9 @@ func (d *Dense) Abs() Matrix {
 // Pow returns a new matrix, applying the power function with given exponent to all elements
 // of the matrix.
 func (d *Dense) Pow(power Float) Matrix {
+	d.sync()
 	out := GetDenseWorkspace(d.Dims())
+	out.device = d.device
 	outData := out.data
 	for i, val := range d.data {
 		outData[i] = math.Pow(val, power)
@@ -661,7 +729,9 @@ func (d *Dense) Pow(power Float) Matrix {
 
 // Sqrt returns a new matrix applying the square root function to all elements.
 func (d *Dense) Sqrt() Matrix {
+	d.sync()
 	out := GetDenseWorkspace(d.Dims())
+	out.device = d.device
 	inData := d.data
 	lastIndex := len(inData) - 1
 	if lastIndex < 0 {
@@ -677,11 +747,13 @@ func (d *Dense) Sqrt() Matrix {
 
 // Sum returns the sum of all values of the matrix.
 func (d *Dense) Sum() Float {
+	d.sync()
 	return f64.Sum(d.data)
 }
 
 // Max returns the maximum value of the matrix.
 func (d *Dense) Max() Float {
+	d.sync()
 	max := math.Inf(-1)
 	for _, v := range d.data {
 		if v > max {
@@ -693,6 +765,7 @@ func (d *Dense) Max() Float {
 
 // Min returns the minimum value of the matrix.
 func (d *Dense) Min() Float {
+	d.sync()
 	min := math.Inf(1)
 	for _, v := range d.data {
 		if v < min {
@@ -704,7 +777,10 @@ func (d *Dense) Min() Float {
 
 // Range extracts data from the the Matrix from elements start (inclusive) and end (exclusive).
 func (d *Dense) Range(start, end int) Matrix {
-	return NewVecDense(d.data[start:end])
+	d.sync()
+	out := NewVecDense(d.data[start:end])
+	out.device = d.device
+	return out
 }
 
 // SplitV extract N vectors from the matrix d.
@@ -722,6 +798,7 @@ func (d *Dense) SplitV(sizes ...int) []Matrix {
 
 // Norm returns the vector's norm. Use pow = 2.0 to compute the Euclidean norm.
 func (d *Dense) Norm(pow Float) Float {
+	d.sync()
 	s := 0.0
 	for _, x := range d.data {
 		s += math.Pow(x, pow)
@@ -740,8 +817,8 @@ func (d *Dense) Normalize2() *Dense {
 
 // Maximum returns a new matrix containing the element-wise maxima.
 func (d *Dense) Maximum(other Matrix) Matrix {
-	if !SameDims(d, other) {
-		panic("mat64: matrix with not compatible size")
+	if checksEnabled && !SameDims(d, other) {
+		panic(dimsError("mat64: matrix with not compatible size", "Maximum", d, other))
 	}
 	out := GetDenseWorkspace(d.rows, d.cols)
 	for i := 0; i < d.rows; i++ {
@@ -755,13 +832,14 @@ func (d *Dense) Maximum(other Matrix) Matrix {
 			}
 		}
 	}
+	out.device = d.device
 	return out
 }
 
 // Minimum returns a new matrix containing the element-wise minima.
 func (d *Dense) Minimum(other Matrix) Matrix {
-	if !SameDims(d, other) {
-		panic("mat64: matrix with not compatible size")
+	if checksEnabled && !SameDims(d, other) {
+		panic(dimsError("mat64: matrix with not compatible size", "Minimum", d, other))
 	}
 	out := GetDenseWorkspace(d.rows, d.cols)
 	for i := 0; i < d.rows; i++ {
@@ -775,6 +853,7 @@ func (d *Dense) Minimum(other Matrix) Matrix {
 			}
 		}
 	}
+	out.device = d.device
 	return out
 }
 
@@ -790,6 +869,7 @@ func (d *Dense) Augment() Matrix {
 		}
 		out.Set(i, i+d.rows, 1.0)
 	}
+	out.device = d.device
 	return out
 }
 
@@ -801,6 +881,7 @@ func (d Dense) SwapInPlace(r1, r2 int) {
 	if r1 >= d.rows || r2 >= d.rows {
 		panic("mat64: index out of range")
 	}
+	d.sync()
 
 	for j := 0; j < d.cols; j++ {
 		a, b := r1*d.cols+j, r2*d.cols+j
@@ -814,6 +895,7 @@ func (d *Dense) Pivoting(row int) (Matrix, bool, []int) {
 	if d.Columns() != d.Rows() {
 		panic("mat64: matrix must be square")
 	}
+	d.sync()
 	pv := make([]int, d.cols)
 	positions := make([]int, 2)
 	for i := range pv {
@@ -839,6 +921,7 @@ func (d *Dense) Pivoting(row int) (Matrix, bool, []int) {
 	for r, c := range pv {
 		p.data[r*d.cols+c] = 1
 	}
+	p.device = d.device
 	return p, swap, positions
 }
 
@@ -876,6 +959,9 @@ func (d *Dense) LU() (l, u, p *Dense) {
 	for i := 0; i < d.cols; i++ {
 		l.data[i*d.cols+i] = 1.0
 	}
+	l.device = d.device
+	u.device = d.device
+	p.device = d.device
 	return
 }
 
@@ -885,32 +971,15 @@ func (d *Dense) Inverse() Matrix {
 		panic("mat64: matrix must be square")
 	}
 	out := NewEmptyDense(d.cols, d.cols)
-	s := NewEmptyDense(d.cols, d.cols)
-	l, u, p := d.LU()
-	for b := 0; b < d.cols; b++ {
-		// find solution of Ly = b
-		for i := 0; i < l.Rows(); i++ {
-			sum := 0.0
-			for j := 0; j < i; j++ {
-				sum += l.Data()[i*d.cols+j] * s.data[j*d.cols+b]
-			}
-			s.data[i*d.cols+b] = p.Data()[i*d.cols+b] - sum
-		}
-		// find solution of Ux = y
-		for i := d.cols - 1; i >= 0; i-- {
-			sum := 0.0
-			for j := i + 1; j < d.cols; j++ {
-				sum += u.Data()[i*d.cols+j] * out.data[j*d.cols+b]
-			}
-			out.data[i*d.cols+b] = (1.0 / u.Data()[i*d.cols+i]) * (s.data[i*d.cols+b] - sum)
-		}
-	}
+	out.device = d.device
+	d.Device().Inverse(d, out)
 	return out
 }
 
 // DoNonZero calls a function for each non-zero element of the matrix.
 // The parameters of the function are the element indices and its value.
 func (d *Dense) DoNonZero(fn func(i, j int, v Float)) {
+	d.sync()
 	for i, di := 0, 0; i < d.rows; i++ {
 		for j := 0; j < d.cols; j, di = j+1, di+1 {
 			v := d.data[di]
@@ -922,7 +991,17 @@ func (d *Dense) DoNonZero(fn func(i, j int, v Float)) {
 	}
 }
 
+// Softmax returns a new column vector containing the softmax of all the
+// values of the matrix, computed on its Device.
+func (d *Dense) Softmax() *Dense {
+	out := GetDenseWorkspace(d.size, 1)
+	out.device = d.device
+	d.Device().Softmax(d, out)
+	return out
+}
+
 // String returns a string representation of the matrix data.
 func (d *Dense) String() string {
+	d.sync()
 	return fmt.Sprintf("%v", d.data)
 }
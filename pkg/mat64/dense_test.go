@@ -43,6 +43,7 @@ func TestDense_Add(t *testing.T) {
 	})
 
 	t.Run("it panics if matrices dimensions differ", func(t *testing.T) {
+		skipIfUnchecked(t)
 		d := NewEmptyDense(2, 3)
 		other := NewEmptyDense(3, 2)
 		assert.Panics(t, func() { d.Add(other) })
@@ -59,6 +60,7 @@ func TestDense_AddInPlace(t *testing.T) {
 	})
 
 	t.Run("it panics if matrices dimensions differ", func(t *testing.T) {
+		skipIfUnchecked(t)
 		d := NewEmptyDense(2, 3)
 		other := NewEmptyDense(3, 2)
 		assert.Panics(t, func() { d.AddInPlace(other) })
@@ -75,6 +77,7 @@ func TestDense_Sub(t *testing.T) {
 	})
 
 	t.Run("it panics if matrices dimensions differ", func(t *testing.T) {
+		skipIfUnchecked(t)
 		d := NewEmptyDense(2, 3)
 		other := NewEmptyDense(3, 2)
 		assert.Panics(t, func() { d.Sub(other) })
@@ -108,6 +111,7 @@ func TestDense_SubInPlace(t *testing.T) {
 	})
 
 	t.Run("it panics if matrices dimensions differ", func(t *testing.T) {
+		skipIfUnchecked(t)
 		d := NewEmptyDense(2, 3)
 		other := NewEmptyDense(3, 2)
 		assert.Panics(t, func() { d.SubInPlace(other) })
@@ -141,6 +145,7 @@ func TestDense_Prod(t *testing.T) {
 	})
 
 	t.Run("it panics if matrices dimensions differ", func(t *testing.T) {
+		skipIfUnchecked(t)
 		d := NewEmptyDense(2, 3)
 		other := NewEmptyDense(3, 2)
 		assert.Panics(t, func() { d.Prod(other) })
@@ -157,6 +162,7 @@ func TestDense_ProdInPlace(t *testing.T) {
 	})
 
 	t.Run("it panics if matrices dimensions differ", func(t *testing.T) {
+		skipIfUnchecked(t)
 		d := NewEmptyDense(2, 3)
 		other := NewEmptyDense(3, 2)
 		assert.Panics(t, func() { d.ProdInPlace(other) })
@@ -181,6 +187,7 @@ func TestDense_Div(t *testing.T) {
 	})
 
 	t.Run("it panics if matrices dimensions differ", func(t *testing.T) {
+		skipIfUnchecked(t)
 		d := NewEmptyDense(2, 3)
 		other := NewEmptyDense(3, 2)
 		assert.Panics(t, func() { d.Div(other) })
@@ -197,6 +204,7 @@ func TestDense_DivInPlace(t *testing.T) {
 	})
 
 	t.Run("it panics if matrices dimensions differ", func(t *testing.T) {
+		skipIfUnchecked(t)
 		d := NewEmptyDense(2, 3)
 		other := NewEmptyDense(3, 2)
 		assert.Panics(t, func() { d.DivInPlace(other) })
@@ -256,6 +264,7 @@ func TestDense_Mul(t *testing.T) {
 	})
 
 	t.Run("it panics with incompatible dimensions", func(t *testing.T) {
+		skipIfUnchecked(t)
 		d := NewEmptyDense(2, 3)
 		other := NewEmptyDense(2, 4)
 		assert.Panics(t, func() { d.Mul(other) })
@@ -298,6 +307,115 @@ func TestDense_MulT(t *testing.T) {
 	})
 }
 
+func TestDense_MatMulT(t *testing.T) {
+	t.Run("matrix x matrix", func(t *testing.T) {
+		d := NewDense(2, 3, []Float{
+			1, 2, 3,
+			4, 5, 6,
+		})
+		other := NewDense(4, 3, []Float{
+			1, 0, 0,
+			0, 1, 0,
+			1, 1, 1,
+			2, 0, -1,
+		})
+		expected := d.Mul(other.T())
+		result := d.MatMulT(other)
+		assert.Equal(t, []int{2, 4}, []int{result.Rows(), result.Columns()})
+		assert.Equal(t, expected.Data(), result.Data())
+		assert.Equal(t, []Float{1, 2, 6, -1, 4, 5, 15, 2}, result.Data())
+	})
+
+	t.Run("matrix x row vector", func(t *testing.T) {
+		d := NewDense(2, 3, []Float{
+			1, 2, 3,
+			4, 5, 6,
+		})
+		other := NewDense(1, 3, []Float{1, 1, 2})
+		result := d.MatMulT(other)
+		assert.Equal(t, []int{2, 1}, []int{result.Rows(), result.Columns()})
+		assert.Equal(t, []Float{9, 21}, result.Data())
+	})
+
+	t.Run("matrix x Sparse", func(t *testing.T) {
+		d := NewDense(2, 3, []Float{
+			1, 2, 3,
+			4, 5, 6,
+		})
+		other := NewSparse(2, 3, []Float{
+			0, 1, 0,
+			2, 0, 0,
+		})
+		assert.Equal(t, []Float{2, 2, 5, 8}, d.MatMulT(other).Data())
+	})
+
+	t.Run("large matrices", func(t *testing.T) {
+		d := newBenchmarkMatrix(130, 140)
+		other := newBenchmarkMatrix(150, 140)
+		expected := d.Mul(other.T())
+		assert.InDeltaSlice(t, expected.Data(), d.MatMulT(other).Data(), 1.0e-12)
+	})
+
+	t.Run("it panics if columns differ", func(t *testing.T) {
+		d := NewEmptyDense(2, 3)
+		other := NewEmptyDense(2, 2)
+		assert.Panics(t, func() { d.MatMulT(other) })
+	})
+}
+
+func TestDense_TMatMul(t *testing.T) {
+	t.Run("matrix x matrix", func(t *testing.T) {
+		d := NewDense(3, 2, []Float{
+			1, 4,
+			2, 5,
+			3, 6,
+		})
+		other := NewDense(3, 2, []Float{
+			1, 0,
+			0, 1,
+			2, -1,
+		})
+		expected := d.T().Mul(other)
+		result := d.TMatMul(other)
+		assert.Equal(t, []int{2, 2}, []int{result.Rows(), result.Columns()})
+		assert.Equal(t, expected.Data(), result.Data())
+		assert.Equal(t, []Float{7, -1, 16, -1}, result.Data())
+	})
+
+	t.Run("matrix x column vector", func(t *testing.T) {
+		d := NewDense(3, 2, []Float{
+			1, 4,
+			2, 5,
+			3, 6,
+		})
+		other := NewVecDense([]Float{1, 1, 2})
+		assert.Equal(t, []Float{9, 21}, d.TMatMul(other).Data())
+	})
+
+	t.Run("matrix x Sparse", func(t *testing.T) {
+		d := NewDense(3, 2, []Float{
+			1, 4,
+			2, 5,
+			3, 6,
+		})
+		other := NewSparse(3, 1, []Float{0, 1, 1})
+		assert.Equal(t, []Float{5, 11}, d.TMatMul(other).Data())
+	})
+
+	t.Run("large matrices", func(t *testing.T) {
+		d := newBenchmarkMatrix(140, 130)
+		other := newBenchmarkMatrix(140, 150)
+		expected := d.T().Mul(other)
+		assert.InDeltaSlice(t, expected.Data(), d.TMatMul(other).Data(), 1.0e-12)
+	})
+
+	t.Run("it panics if rows differ", func(t *testing.T) {
+		d := NewEmptyDense(3, 2)
+		other := NewEmptyDense(2, 2)
+		assert.Panics(t, func() { d.TMatMul(other) })
+	})
+}
+
 func TestDense_Pow(t *testing.T) {
 	a := NewVecDense([]Float{0.1, 0.2, 0.3, 0.0})
 	b := a.Pow(3.0)
@@ -1219,6 +1337,7 @@ func TestDense_AtVec(t *testing.T) {
 	})
 
 	t.Run("it panics if it is not a vector", func(t *testing.T) {
+		skipIfUnchecked(t)
 		d := NewEmptyDense(5, 2)
 		assert.Panics(t, func() { d.AtVec(3) })
 	})
@@ -1238,6 +1357,7 @@ func TestDense_SetVec(t *testing.T) {
 	})
 
 	t.Run("it panics if it is not a vector", func(t *testing.T) {
+		skipIfUnchecked(t)
 		d := NewEmptyDense(5, 2)
 		assert.Panics(t, func() { d.SetVec(3, 42) })
 	})
@@ -1269,6 +1389,7 @@ func TestDense_Apply(t *testing.T) {
 	})
 
 	t.Run("it panics if matrices dimensions differ", func(t *testing.T) {
+		skipIfUnchecked(t)
 		d := NewEmptyDense(2, 3)
 		other := NewEmptyDense(3, 2)
 		f := func(i, j int, v Float) Float {
@@ -1317,6 +1438,7 @@ func TestDense_ApplyWithAlpha(t *testing.T) {
 	})
 
 	t.Run("it panics if matrices dimensions differ", func(t *testing.T) {
+		skipIfUnchecked(t)
 		d := NewEmptyDense(2, 3)
 		other := NewEmptyDense(3, 2)
 		f := func(i, j int, v Float, alpha ...Float) Float {
@@ -1395,6 +1517,7 @@ func TestDense_DotUnitary(t *testing.T) {
 	})
 
 	t.Run("it panics with incompatible dimensions", func(t *testing.T) {
+		skipIfUnchecked(t)
 		d := NewDense(1, 3, []Float{1, 2, 3})
 		other := NewDense(1, 2, []Float{10, 20})
 		assert.Panics(t, func() { d.DotUnitary(other) })
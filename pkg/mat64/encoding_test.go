@@ -104,3 +104,23 @@ func TestMatrixBinaryMarshaling(t *testing.T) {
 		require.Nil(t, decodedMatrix)
 	})
 }
+
+func TestFloat16Dense_BinaryMarshaling(t *testing.T) {
+	for _, format := range []HalfPrecision{Float16, BFloat16} {
+		t.Run(format.String(), func(t *testing.T) {
+			h := NewFloat16Dense(NewDense(2, 3, []Float{0.5, -1, 2, 0, 4, -8}), format)
+			bin, err := h.MarshalBinary()
+			require.Nil(t, err)
+			assert.Len(t, bin, 9+6*2)
+
+			decoded := new(Float16Dense)
+			require.Nil(t, decoded.UnmarshalBinary(bin))
+			assert.Equal(t, format, decoded.Format())
+			assert.Equal(t, []Float{0.5, -1, 2, 0, 4, -8}, decoded.Decode().Data())
+
+			assert.NotNil(t, decoded.UnmarshalBinary(bin[:10]))
+			bin[8] = 9
+			assert.NotNil(t, decoded.UnmarshalBinary(bin))
+		})
+	}
+}
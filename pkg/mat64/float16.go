@@ -0,0 +1,234 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mat64
+
+import (
+	"fmt"
+	"math"
+)
+
+// HalfPrecision is the enumeration-like type used to distinguish the 16-bit
+// floating-point formats.
+type HalfPrecision int
+
+const (
+	// Float16 is the IEEE 754 half-precision format (1 sign bit, 5 exponent
+	// bits, 10 mantissa bits).
+	Float16 HalfPrecision = iota
+	// BFloat16 is the "brain" floating-point format (1 sign bit, 8 exponent
+	// bits, 7 mantissa bits), which has the same range of a float32.
+	BFloat16
+)
+
+// String returns the name of the format.
+func (p HalfPrecision) String() string {
+	switch p {
+	case Float16:
+		return "float16"
+	case BFloat16:
+		return "bfloat16"
+	default:
+		return fmt.Sprintf("HalfPrecision(%d)", int(p))
+	}
+}
+
+// ParseHalfPrecision returns the HalfPrecision format with the given name
+// ("float16" or "bfloat16").
+func ParseHalfPrecision(name string) (HalfPrecision, error) {
+	switch name {
+	case "float16":
+		return Float16, nil
+	case "bfloat16":
+		return BFloat16, nil
+	default:
+		return 0, fmt.Errorf("mat64: unknown half precision format %q", name)
+	}
+}
+
+// Encode converts a float32 to the 16-bit format, rounding to the nearest even.
+func (p HalfPrecision) Encode(f float32) uint16 {
+	if p == BFloat16 {
+		return float32ToBFloat16(f)
+	}
+	return float32ToFloat16(f)
+}
+
+// Decode converts a number in the 16-bit format to float32.
+func (p HalfPrecision) Decode(h uint16) float32 {
+	if p == BFloat16 {
+		return math.Float32frombits(uint32(h) << 16)
+	}
+	return float16ToFloat32(h)
+}
+
+// Round returns the number of the 16-bit format nearest to f, e.g. to emulate
+// a computation in half precision. It overflows to ±Inf like the format.
+func (p HalfPrecision) Round(f Float) Float {
+	return Float(p.Decode(p.Encode(float32(f))))
+}
+
+// RoundInPlace rounds all the values of the matrix to the 16-bit format
+// (see Round), returning the matrix itself.
+func (p HalfPrecision) RoundInPlace(m Matrix) Matrix {
+	if d, ok := m.(*Dense); ok {
+		d.sync()
+		for i, v := range d.data {
+			d.data[i] = p.Round(v)
+		}
+		return m
+	}
+	data := m.Data()
+	for i, v := range data {
+		data[i] = p.Round(v)
+	}
+	m.SetData(data)
+	return m
+}
+
+func float32ToBFloat16(f float32) uint16 {
+	b := math.Float32bits(f)
+	if f != f { // NaN
+		return uint16(b>>16) | 0x40
+	}
+	b += 0x7fff + (b>>16)&1
+	return uint16(b >> 16)
+}
+
+func float32ToFloat16(f float32) uint16 {
+	b := math.Float32bits(f)
+	sign := uint16(b>>16) & 0x8000
+	exp := int32((b>>23)&0xff) - 127 + 15
+	mant := b & 0x7fffff
+
+	switch {
+	case (b>>23)&0xff == 0xff: // Inf or NaN
+		if mant != 0 {
+			return sign | 0x7e00
+		}
+		return sign | 0x7c00
+	case exp >= 0x1f: // overflow
+		return sign | 0x7c00
+	case exp <= 0: // subnormal or zero
+		if exp < -10 {
+			return sign
+		}
+		mant |= 0x800000
+		shift := uint32(14 - exp)
+		h := uint16(mant >> shift)
+		rem, mid := mant&(1<<shift-1), uint32(1)<<(shift-1)
+		if rem > mid || (rem == mid && h&1 == 1) {
+			h++
+		}
+		return sign | h
+	default:
+		h := sign | uint16(exp)<<10 | uint16(mant>>13)
+		rem := mant & 0x1fff
+		if rem > 0x1000 || (rem == 0x1000 && h&1 == 1) {
+			h++ // the carry may correctly overflow into the exponent
+		}
+		return h
+	}
+}
+
+func float16ToFloat32(h uint16) float32 {
+	sign := uint32(h&0x8000) << 16
+	exp := uint32(h>>10) & 0x1f
+	mant := uint32(h & 0x3ff)
+
+	switch exp {
+	case 0x1f: // Inf or NaN
+		return math.Float32frombits(sign | 0x7f800000 | mant<<13)
+	case 0:
+		if mant == 0 {
+			return math.Float32frombits(sign)
+		}
+		e := uint32(127 - 15 + 1)
+		for mant&0x400 == 0 { // normalize the subnormal number
+			mant <<= 1
+			e--
+		}
+		return math.Float32frombits(sign | e<<23 | (mant&0x3ff)<<13)
+	default:
+		return math.Float32frombits(sign | (exp+127-15)<<23 | mant<<13)
+	}
+}
+
+// Float16Dense is a compact storage for a dense matrix, whose values are kept
+// in a 16-bit floating-point format, using half of the memory of a Dense.
+// It is not a Matrix: the values must be decoded into a Dense before being
+// used in any computation.
+type Float16Dense struct {
+	rows   int
+	cols   int
+	format HalfPrecision
+	data   []uint16
+}
+
+// NewFloat16Dense returns a new Float16Dense with the values of m, encoded in
+// the given format.
+func NewFloat16Dense(m Matrix, format HalfPrecision) *Float16Dense {
+	values := m.Data()
+	data := make([]uint16, len(values))
+	for i, v := range values {
+		data[i] = format.Encode(float32(v))
+	}
+	return &Float16Dense{
+		rows:   m.Rows(),
+		cols:   m.Columns(),
+		format: format,
+		data:   data,
+	}
+}
+
+// Rows returns the number of rows of the matrix.
+func (d *Float16Dense) Rows() int {
+	return d.rows
+}
+
+// Columns returns the number of columns of the matrix.
+func (d *Float16Dense) Columns() int {
+	return d.cols
+}
+
+// Dims returns the number of rows and columns of the matrix.
+func (d *Float16Dense) Dims() (r, c int) {
+	return d.rows, d.cols
+}
+
+// Size returns the size of the matrix (rows × columns).
+func (d *Float16Dense) Size() int {
+	return len(d.data)
+}
+
+// Format returns the 16-bit format of the values.
+func (d *Float16Dense) Format() HalfPrecision {
+	return d.format
+}
+
+// At returns the decoded value at row i and column j.
+func (d *Float16Dense) At(i, j int) Float {
+	if i >= d.rows || j >= d.cols {
+		panic("mat64: index out of range")
+	}
+	return Float(d.format.Decode(d.data[i*d.cols+j]))
+}
+
+// Decode returns a new Dense matrix with the decoded values.
+func (d *Float16Dense) Decode() *Dense {
+	out := NewEmptyDense(d.rows, d.cols)
+	d.DecodeInto(out)
+	return out
+}
+
+// DecodeInto decodes the values into the out matrix, which must have the same size.
+func (d *Float16Dense) DecodeInto(out *Dense) {
+	if out.Size() != len(d.data) {
+		panic("mat64: incompatible matrix size")
+	}
+	outData := out.Data()
+	for i, h := range d.data {
+		outData[i] = Float(d.format.Decode(h))
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mat64
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHalfPrecision_Float16(t *testing.T) {
+	testCases := []struct {
+		f float32
+		h uint16
+	}{
+		{0, 0x0000},
+		{1, 0x3c00},
+		{-2, 0xc000},
+		{0.5, 0x3800},
+		{65504, 0x7bff},                 // max normal
+		{6.103515625e-05, 0x0400},       // min normal
+		{5.960464477539063e-08, 0x0001}, // min subnormal
+		{float32(math.Inf(1)), 0x7c00},
+		{float32(math.Inf(-1)), 0xfc00},
+		{1e6, 0x7c00},                  // overflow
+		{1.00048828125, 0x3c00},        // tie, rounded to even
+		{1.001953125 + 0.0001, 0x3c02}, // rounded up
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.h, Float16.Encode(tc.f), "encode %v", tc.f)
+	}
+	for _, tc := range testCases[:9] {
+		assert.Equal(t, tc.f, Float16.Decode(tc.h), "decode %#04x", tc.h)
+	}
+	nan := Float16.Decode(Float16.Encode(float32(math.NaN())))
+	assert.True(t, nan != nan)
+}
+
+func TestHalfPrecision_BFloat16(t *testing.T) {
+	assert.Equal(t, uint16(0x3f80), BFloat16.Encode(1))
+	assert.Equal(t, uint16(0xc000), BFloat16.Encode(-2))
+	assert.Equal(t, float32(1), BFloat16.Decode(0x3f80))
+	assert.Equal(t, float32(3.140625), BFloat16.Decode(BFloat16.Encode(3.14159)))
+	assert.InEpsilon(t, 1e30, BFloat16.Decode(BFloat16.Encode(1e30)), 1e-2)
+}
+
+func TestFloat16Dense(t *testing.T) {
+	m := NewDense(2, 3, []Float{0.1, 0.2, 0.3, -0.4, 0.5, 1000})
+	for _, format := range []HalfPrecision{Float16, BFloat16} {
+		t.Run(format.String(), func(t *testing.T) {
+			h := NewFloat16Dense(m, format)
+			assert.Equal(t, format, h.Format())
+			r, c := h.Dims()
+			assert.Equal(t, 2, r)
+			assert.Equal(t, 3, c)
+			assert.Equal(t, 6, h.Size())
+			assert.InDelta(t, -0.4, h.At(1, 0), 1.0e-2)
+
+			d := h.Decode()
+			assert.Equal(t, 2, d.Rows())
+			assert.Equal(t, 3, d.Columns())
+			for i, v := range m.Data() {
+				assert.InEpsilon(t, v, d.Data()[i], 1.0e-2)
+			}
+			assert.Panics(t, func() { h.DecodeInto(NewEmptyDense(3, 3)) })
+		})
+	}
+}
+
+func TestHalfPrecision_Round(t *testing.T) {
+	assert.Equal(t, Float(1), Float16.Round(1+1.0/4096))
+	assert.Equal(t, Float(1+1.0/1024), Float16.Round(1+1.0/1024))
+	assert.Equal(t, Inf(1), Float16.Round(1e5))
+	assert.InDelta(t, 1e5, BFloat16.Round(1e5), 1e5/128) // same range of a float32
+
+	m := NewVecDense([]Float{1 + 1.0/4096, -70000})
+	assert.Same(t, m, Float16.RoundInPlace(m))
+	assert.Equal(t, []Float{1, Inf(-1)}, m.Data())
+}
+
+func TestParseHalfPrecision(t *testing.T) {
+	for _, format := range []HalfPrecision{Float16, BFloat16} {
+		parsed, err := ParseHalfPrecision(format.String())
+		assert.NoError(t, err)
+		assert.Equal(t, format, parsed)
+	}
+	_, err := ParseHalfPrecision("float8")
+	assert.EqualError(t, err, `mat64: unknown half precision format "float8"`)
+}
@@ -0,0 +1,113 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package floatutils
+
+import (
+	"math"
+
+	"github.com/nlpodyssey/spago/pkg/mat64"
+	"github.com/nlpodyssey/spago/pkg/mat64/internal/asm/f64"
+)
+
+// DistanceFunc is a function measuring the distance (or the similarity) of two
+// vectors of the same length.
+type DistanceFunc func(x, y []float64) float64
+
+// DotSimilarity returns the dot product of x and y.
+func DotSimilarity(x, y []float64) float64 {
+	if len(x) != len(y) {
+		panic("floatutils: slices have different lengths")
+	}
+	return f64.DotUnitary(x, y)
+}
+
+// CosineSimilarity returns the cosine of the angle between x and y, in [-1, 1].
+// It is zero if any of them is a zero vector.
+func CosineSimilarity(x, y []float64) float64 {
+	if len(x) != len(y) {
+		panic("floatutils: slices have different lengths")
+	}
+	norms := f64.DotUnitary(x, x) * f64.DotUnitary(y, y)
+	if norms == 0 {
+		return 0
+	}
+	return f64.DotUnitary(x, y) / math.Sqrt(norms)
+}
+
+// EuclideanDistance returns the Euclidean distance between x and y.
+func EuclideanDistance(x, y []float64) float64 {
+	return math.Sqrt(SquaredEuclideanDistance(x, y))
+}
+
+// SquaredEuclideanDistance returns the squared Euclidean distance between x and y.
+func SquaredEuclideanDistance(x, y []float64) (s float64) {
+	if len(x) != len(y) {
+		panic("floatutils: slices have different lengths")
+	}
+	for i, v := range x {
+		d := v - y[i]
+		s += d * d
+	}
+	return
+}
+
+// PairwiseDistances returns the matrix of the distances between each pair of
+// vectors, computing only half of it, since fn is assumed to be symmetric.
+func PairwiseDistances(vectors [][]float64, fn DistanceFunc) [][]float64 {
+	out := MakeFloatMatrix(len(vectors), len(vectors))
+	for i, x := range vectors {
+		for j := i; j < len(vectors); j++ {
+			d := fn(x, vectors[j])
+			out[i][j], out[j][i] = d, d
+		}
+	}
+	return out
+}
+
+// PairwiseCosineSimilarities returns the matrix of the cosine similarities
+// between each pair of vectors. It is faster than PairwiseDistances with
+// CosineSimilarity, since the norms are computed only once.
+func PairwiseCosineSimilarities(vectors [][]float64) [][]float64 {
+	norms := make([]float64, len(vectors))
+	for i, x := range vectors {
+		norms[i] = math.Sqrt(f64.DotUnitary(x, x))
+	}
+	out := MakeFloatMatrix(len(vectors), len(vectors))
+	for i, x := range vectors {
+		for j := i; j < len(vectors); j++ {
+			if norms[i] == 0 || norms[j] == 0 {
+				continue
+			}
+			s := DotSimilarity(x, vectors[j]) / (norms[i] * norms[j])
+			out[i][j], out[j][i] = s, s
+		}
+	}
+	return out
+}
+
+// Rows returns the rows of the matrix as slices sharing its underlying data.
+func Rows(m *mat64.Dense) [][]float64 {
+	rows := make([][]float64, m.Rows())
+	data, cols := m.Data(), m.Columns()
+	for i := range rows {
+		rows[i] = data[i*cols : (i+1)*cols : (i+1)*cols]
+	}
+	return rows
+}
+
+// PairwiseRowDistances returns the square matrix of the distances between each
+// pair of rows of m.
+func PairwiseRowDistances(m *mat64.Dense, fn DistanceFunc) *mat64.Dense {
+	rows := Rows(m)
+	out := mat64.NewEmptyDense(len(rows), len(rows))
+	for i, x := range rows {
+		for j := i; j < len(rows); j++ {
+			d := fn(x, rows[j])
+			out.Set(i, j, d)
+			out.Set(j, i, d)
+		}
+	}
+	return out
+}
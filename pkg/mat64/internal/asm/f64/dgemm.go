@@ -112,10 +112,19 @@ func Dgemm(aTrans, bTrans bool, m, n, k int, alpha float64, a []float64, lda int
 		}
 	}
 
-	dgemmParallel(aTrans, bTrans, m, n, k, a, lda, b, ldb, c, ldc, alpha)
+	dgemmParallel(aTrans, bTrans, m, n, k, a, lda, b, ldb, c, ldc, alpha, runtime.GOMAXPROCS(0))
 }
 
-func dgemmParallel(aTrans, bTrans bool, m, n, k int, a []float64, lda int, b []float64, ldb int, c []float64, ldc int, alpha float64) {
+// DgemmParallel is a parallel matrix multiply, which uses at most the given
+// number of worker goroutines on blockSize×blockSize sub-blocks of c.
+func DgemmParallel(aTrans, bTrans bool, m, n, k int, a []float64, lda int, b []float64, ldb int, c []float64, ldc int, alpha float64, workers int) {
+	if workers < 1 {
+		workers = 1
+	}
+	dgemmParallel(aTrans, bTrans, m, n, k, a, lda, b, ldb, c, ldc, alpha, workers)
+}
+
+func dgemmParallel(aTrans, bTrans bool, m, n, k int, a []float64, lda int, b []float64, ldb int, c []float64, ldc int, alpha float64, workers int) {
 	// dgemmParallel computes a parallel matrix multiplication by partitioning
 	// a and b into sub-blocks, and updating c with the multiplication of the sub-block
 	// In all cases,
@@ -147,14 +156,14 @@ func dgemmParallel(aTrans, bTrans bool, m, n, k int, a []float64, lda int, b []f
 
 	maxKLen := k
 	parBlocks := blocks(m, blockSize) * blocks(n, blockSize)
-	if parBlocks < minParBlock {
+	if parBlocks < minParBlock || workers == 1 {
 		// The matrix multiplication is small in the dimensions where it can be
 		// computed concurrently. Just do it in serial.
 		DgemmSerial(aTrans, bTrans, m, n, k, a, lda, b, ldb, c, ldc, alpha)
 		return
 	}
 
-	nWorkers := runtime.GOMAXPROCS(0)
+	nWorkers := workers
 	if parBlocks < nWorkers {
 		nWorkers = parBlocks
 	}
@@ -0,0 +1,78 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mat64
+
+// LayerNorm computes the layer normalization of x into y:
+// y = (x - E[x]) / sqrt(Var[x] + eps) ⊙ w + b.
+// The mean and the variance are computed in a single pass (Welford's
+// algorithm). The normalized input is written to xHat, which is needed by
+// LayerNormBackward along with the returned standard deviation.
+// All the slices must have the same length.
+func LayerNorm(x, w, b []Float, eps Float, y, xHat []Float) (std Float) {
+	var mean, m2 Float
+	for i, v := range x {
+		delta := v - mean
+		mean += delta / Float(i+1)
+		m2 += delta * (v - mean)
+	}
+	std = Sqrt(m2/Float(len(x)) + eps)
+	for i, v := range x {
+		xHat[i] = (v - mean) / std
+		y[i] = xHat[i]*w[i] + b[i]
+	}
+	return std
+}
+
+// LayerNormBackward computes the gradients of the input of LayerNorm into gx
+// (if not nil), given the gradients gy of the output.
+// It returns E[gy ⊙ w ⊙ xHat], from which the gradient of eps is
+// -E[gy ⊙ w ⊙ xHat] * n / (2 std²).
+// The gradients of w and b are simply gy ⊙ xHat and gy.
+func LayerNormBackward(gy, w, xHat []Float, std Float, gx []Float) (meanProd Float) {
+	n := Float(len(gy))
+	var mean Float // E[gy ⊙ w]
+	for i, v := range gy {
+		g := v * w[i]
+		mean += g
+		meanProd += g * xHat[i]
+	}
+	mean /= n
+	meanProd /= n
+	if gx != nil {
+		for i, v := range gy {
+			gx[i] = (v*w[i] - mean - xHat[i]*meanProd) / std
+		}
+	}
+	return meanProd
+}
+
+// SoftmaxCrossEntropy returns the cross-entropy between the softmax of the
+// logits and the one-hot distribution of the target, i.e. log(Σ exp(x)) - x[target],
+// without materializing the softmax. It also returns the log-sum-exp of the
+// logits, which is needed by SoftmaxCrossEntropyBackward.
+func SoftmaxCrossEntropy(logits []Float, target int) (loss, logSumExp Float) {
+	maximum := Inf(-1)
+	for _, v := range logits {
+		if v > maximum {
+			maximum = v
+		}
+	}
+	var sum Float
+	for _, v := range logits {
+		sum += Exp(v - maximum)
+	}
+	logSumExp = maximum + Log(sum)
+	return logSumExp - logits[target], logSumExp
+}
+
+// SoftmaxCrossEntropyBackward computes into gx the gradients of the logits of
+// SoftmaxCrossEntropy, given the gradient gy of the loss:
+// gx = gy * (softmax(x) - onehot(target)).
+func SoftmaxCrossEntropyBackward(logits []Float, target int, logSumExp, gy Float, gx []Float) {
+	for i, v := range logits {
+		gx[i] = gy * Exp(v-logSumExp)
+	}
+	gx[target] -= gy
+}
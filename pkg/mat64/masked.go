@@ -0,0 +1,86 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mat64
+
+// The masked operations take a mask matrix with the same size of their
+// input, whose non-zero values mark the elements to keep, and zero values
+// mark the masked elements (e.g. the padding positions of an attention mask).
+
+// MaskedFill returns a new matrix with the values of m, where the masked
+// elements are replaced with value.
+func MaskedFill(m, mask Matrix, value Float) *Dense {
+	checkMaskSize(m, mask)
+	out := NewEmptyDense(m.Dims())
+	outData, maskData := out.Data(), mask.Data()
+	for i, v := range m.Data() {
+		if maskData[i] == 0 {
+			v = value
+		}
+		outData[i] = v
+	}
+	return out
+}
+
+// MaskedSoftmax returns a new matrix with the softmax of the unmasked
+// elements of m, and zeros in place of the masked ones.
+// If all the elements are masked, the result is filled with zeros.
+func MaskedSoftmax(m, mask Matrix) *Dense {
+	checkMaskSize(m, mask)
+	out := NewEmptyDense(m.Dims())
+	outData, maskData := out.Data(), mask.Data()
+	data := m.Data()
+
+	maximum, found := Inf(-1), false
+	for i, v := range data {
+		if maskData[i] != 0 && (!found || v > maximum) {
+			maximum, found = v, true
+		}
+	}
+	if !found {
+		return out
+	}
+	var sum Float
+	for i, v := range data {
+		if maskData[i] != 0 {
+			e := Exp(v - maximum)
+			outData[i] = e
+			sum += e
+		}
+	}
+	for i := range outData {
+		outData[i] /= sum
+	}
+	return out
+}
+
+// MaskedMean returns the mean of the unmasked elements of m, and their number.
+// If all the elements are masked, the mean is zero.
+func MaskedMean(m, mask Matrix) (mean Float, n int) {
+	checkMaskSize(m, mask)
+	maskData := mask.Data()
+	var sum Float
+	for i, v := range m.Data() {
+		if maskData[i] != 0 {
+			sum += v
+			n++
+		}
+	}
+	if n == 0 {
+		return 0, 0
+	}
+	return sum / Float(n), n
+}
+
+// ApplyMask returns a new matrix with the values of m, where the masked
+// elements are set to zero (e.g. to mask the gradients).
+func ApplyMask(m, mask Matrix) *Dense {
+	return MaskedFill(m, mask, 0)
+}
+
+func checkMaskSize(m, mask Matrix) {
+	if m.Size() != mask.Size() {
+		panic("mat64: the mask must have the same size of the matrix")
+	}
+}
@@ -0,0 +1,44 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mat64
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaskedFill(t *testing.T) {
+	m := NewDense(2, 2, []Float{1, 2, 3, 4})
+	mask := NewDense(2, 2, []Float{1, 0, 0, 1})
+	out := MaskedFill(m, mask, -9)
+	assert.Equal(t, []Float{1, -9, -9, 4}, out.Data())
+	assert.Equal(t, []Float{1, 2, 3, 4}, m.Data(), "the input must not be modified")
+	assert.Equal(t, []Float{1, 0, 0, 4}, ApplyMask(m, mask).Data())
+	assert.Panics(t, func() { MaskedFill(m, NewVecDense([]Float{1, 1}), 0) })
+}
+
+func TestMaskedSoftmax(t *testing.T) {
+	m := NewVecDense([]Float{1, 2, 1000, 3})
+	out := MaskedSoftmax(m, NewVecDense([]Float{1, 1, 0, 1}))
+	assert.InDeltaSlice(t, []Float{0.0900306, 0.2447285, 0, 0.6652410}, out.Data(), 1.0e-6)
+
+	out = MaskedSoftmax(m, NewVecDense([]Float{1, 1, 1, 1}))
+	assert.InDeltaSlice(t, []Float{0, 0, 1, 0}, out.Data(), 1.0e-6)
+
+	out = MaskedSoftmax(m, NewEmptyVecDense(4))
+	assert.Equal(t, []Float{0, 0, 0, 0}, out.Data())
+}
+
+func TestMaskedMean(t *testing.T) {
+	m := NewDense(2, 2, []Float{1, 2, 3, 4})
+	mean, n := MaskedMean(m, NewDense(2, 2, []Float{1, 0, 1, 1}))
+	assert.InDelta(t, 8.0/3.0, mean, 1.0e-6)
+	assert.Equal(t, 3, n)
+
+	mean, n = MaskedMean(m, NewEmptyDense(2, 2))
+	assert.Equal(t, Float(0), mean)
+	assert.Equal(t, 0, n)
+}
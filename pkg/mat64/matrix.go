@@ -114,6 +114,12 @@ type Matrix interface {
 	// MulT performs the matrix multiplication row by column. ATB = C, where AT is the transpose of B
 	// if A is an r x c Matrix, and B is j x k, r = j the resulting Matrix C will be c x k.
 	MulT(other Matrix) Matrix
+	// MatMulT performs the matrix multiplication A×Bᵀ, without materializing the transpose of B.
+	// If A is an i×j Matrix, and B is k×j, then the resulting Matrix will be i×k.
+	MatMulT(other Matrix) Matrix
+	// TMatMul performs the matrix multiplication Aᵀ×B, without materializing the transpose of A.
+	// If A is an i×j Matrix, and B is i×k, then the resulting Matrix will be j×k.
+	TMatMul(other Matrix) Matrix
 	// Inverse returns the inverse of the Matrix.
 	Inverse() Matrix
 	// DoNonZero calls a function for each non-zero element of the matrix.
@@ -0,0 +1,32 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rand
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLockedRand_Spawn(t *testing.T) {
+	a := NewLockedRand(42).Spawn()
+	b := NewLockedRand(42).Spawn()
+	assert.Equal(t, a.Uint64(), b.Uint64())
+
+	parent := NewLockedRand(42)
+	assert.NotEqual(t, parent.Spawn().Uint64(), parent.Spawn().Uint64())
+}
+
+func TestGetUniqueRandomInt(t *testing.T) {
+	valid := func(r int) bool { return r != 3 }
+	a := GetUniqueRandomInt(5, 10, valid, NewLockedRand(1))
+	b := GetUniqueRandomInt(5, 10, valid, NewLockedRand(1))
+	assert.Equal(t, a, b)
+	assert.NotContains(t, a, 3)
+}
+
+func TestWeightedChoice(t *testing.T) {
+	assert.Equal(t, 1, WeightedChoice([]float64{0, 1, 0}, NewLockedRand(1)))
+}
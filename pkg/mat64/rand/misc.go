@@ -22,9 +22,14 @@ func ShuffleInPlace(xs []int, generator *LockedRand) []int {
 }
 
 // WeightedChoice performs a random generation of the indices based of the probability distribution itself.
-// Please note that it uses the global random.
-func WeightedChoice(dist []float64) int {
-	rnd := rand.Float64() // // Warning: use global rand
+// If the generator is nil, it uses the global random.
+func WeightedChoice(dist []float64, generator *LockedRand) int {
+	var rnd float64
+	if generator != nil {
+		rnd = generator.Float64()
+	} else {
+		rnd = rand.Float64() // Warning: use global rand
+	}
 	cumulativeProb := 0.0
 	for i, prob := range dist {
 		cumulativeProb += prob
@@ -35,28 +40,34 @@ func WeightedChoice(dist []float64) int {
 	return 0
 }
 
-// GetUniqueRandomInt generates n mutually exclusive integers up to max, using the default random source.
+// GetUniqueRandomInt generates n mutually exclusive integers up to max.
+// If the generator is nil, it uses the global random.
 // The callback checks whether a generated number can be accepted, or not.
-func GetUniqueRandomInt(n, max int, valid func(r int) bool) []int {
+func GetUniqueRandomInt(n, max int, valid func(r int) bool, generator *LockedRand) []int {
+	intn := rand.Intn // Warning: use global rand
+	if generator != nil {
+		intn = generator.Intn
+	}
 	a := make([]int, n)
 	for i := 0; i < n; i++ {
-		r := rand.Intn(max) // Warning: use global rand
+		r := intn(max)
 		for !valid(r) || utils.ContainsInt(a, r) {
-			r = rand.Intn(max) // Warning: use global rand
+			r = intn(max)
 		}
 		a[i] = r
 	}
 	return a
 }
 
-// GetUniqueRandomIndices select n mutually exclusive indices, using the global random.
+// GetUniqueRandomIndices select n mutually exclusive indices.
+// If the generator is nil, it uses the global random.
 // The callback checks whether an extracted index can be accepted, or not.
-func GetUniqueRandomIndices(n int, indices []int, valid func(r int) bool) []int {
+func GetUniqueRandomIndices(n int, indices []int, valid func(r int) bool, generator *LockedRand) []int {
 	a := make([]int, n)
 	for i := 0; i < len(a); i++ {
-		r := ShuffleInPlace(indices, nil)[0] // Warning: use global rand
+		r := ShuffleInPlace(indices, generator)[0]
 		for !valid(r) || utils.ContainsInt(a, r) {
-			r = ShuffleInPlace(indices, nil)[0] // Warning: use global rand
+			r = ShuffleInPlace(indices, generator)[0]
 		}
 		a[i] = r
 	}
@@ -0,0 +1,38 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package multinomial
+
+import (
+	"sort"
+
+	"github.com/nlpodyssey/spago/pkg/mat64"
+	"github.com/nlpodyssey/spago/pkg/mat64/rand"
+)
+
+// Distribution draws n samples (with replacement) from the categorical
+// distribution defined by the given weights, returning the indices of the
+// extracted categories.
+// The weights must be non-negative, but they don't need to sum to one.
+// It panics if the weights don't have a positive sum.
+func Distribution(weights []mat64.Float, n int, generator *rand.LockedRand) []int {
+	cumulative := make([]mat64.Float, len(weights))
+	var sum mat64.Float
+	for i, w := range weights {
+		if w < 0 {
+			panic("multinomial: weights must be non-negative")
+		}
+		sum += w
+		cumulative[i] = sum
+	}
+	if !(sum > 0) {
+		panic("multinomial: the sum of the weights must be positive")
+	}
+	samples := make([]int, n)
+	for i := range samples {
+		p := mat64.Float(generator.Float64()) * sum
+		samples[i] = sort.Search(len(cumulative), func(j int) bool { return cumulative[j] > p })
+	}
+	return samples
+}
@@ -0,0 +1,9 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build mat_debug,!mat_unchecked
+
+package mat64
+
+const safetyMode = Debug
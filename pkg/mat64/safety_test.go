@@ -0,0 +1,44 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mat64
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// skipIfUnchecked skips the tests of the verifications compiled out in the
+// Unchecked mode.
+func skipIfUnchecked(t *testing.T) {
+	t.Helper()
+	if Safety() == Unchecked {
+		t.Skip("the dimensions are not verified in unchecked mode")
+	}
+}
+
+func TestSafety(t *testing.T) {
+	assert.Equal(t, safetyMode, Safety())
+	assert.Equal(t, "checked", Checked.String())
+	assert.Equal(t, "unchecked", Unchecked.String())
+	assert.Equal(t, "debug", Debug.String())
+	assert.Equal(t, "SafetyMode(42)", SafetyMode(42).String())
+}
+
+func TestDimsError(t *testing.T) {
+	a, b := NewEmptyDense(2, 3), NewEmptyDense(3, 2)
+	msg := "mat64: matrices with not compatible size"
+	detailed := "mat64: matrices with not compatible size (Add: 2×3, 3×2)"
+	assert.Equal(t, detailed, withShapes(msg, "Add", a, b))
+
+	if Safety() == Debug {
+		assert.Equal(t, detailed, dimsError(msg, "Add", a, b))
+		assert.PanicsWithValue(t, detailed, func() { a.Add(b) })
+		assert.PanicsWithValue(t, "mat64: 'j' argument out of range (At: index 3, shape 2×3)", func() { a.At(0, 3) })
+	} else {
+		assert.Equal(t, msg, dimsError(msg, "Add", a, b))
+		assert.Equal(t, "mat64: index out of range", indexError("mat64: index out of range", "At", 3, a))
+	}
+}
@@ -717,6 +717,7 @@ func (s *Sparse) Mul(other Matrix) Matrix {
 
 	switch b := other.(type) {
 	case *Dense:
+		b.sync()
 		s.DoNonZero(func(i, j int, v Float) {
 			for k := 0; k < b.cols; k++ {
 				out.data[i*b.cols+k] += v * b.data[j*b.cols+k]
@@ -818,6 +819,22 @@ func (s *Sparse) MulT(_ Matrix) Matrix {
 	panic("mat64: MulT not implemented for Sparse matrices")
 }
 
+// MatMulT performs the matrix multiplication s×otherᵀ.
+func (s *Sparse) MatMulT(other Matrix) Matrix {
+	if s.Columns() != other.Columns() {
+		panic("mat64: matrices with not compatible size")
+	}
+	return s.Mul(other.T())
+}
+
+// TMatMul performs the matrix multiplication sᵀ×other.
+func (s *Sparse) TMatMul(other Matrix) Matrix {
+	if s.Rows() != other.Rows() {
+		panic("mat64: matrices with not compatible size")
+	}
+	return s.T().Mul(other)
+}
+
 // Inverse returns the inverse of the matrix.
 func (s *Sparse) Inverse() Matrix {
 	panic("mat64: Sparse not implemented for Sparse matrices")
@@ -0,0 +1,54 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mat64
+
+import "sort"
+
+// ArgSort returns the indices that would sort the values of m, in ascending
+// or descending order. The sort is stable; not-a-number values are placed last.
+func ArgSort(m Matrix, descending bool) []int {
+	data := m.Data()
+	indices := make([]int, len(data))
+	for i := range indices {
+		indices[i] = i
+	}
+	sort.SliceStable(indices, func(i, j int) bool {
+		a, b := data[indices[i]], data[indices[j]]
+		if a != a || b != b { // NaN
+			return b != b && a == a
+		}
+		if descending {
+			return a > b
+		}
+		return a < b
+	})
+	return indices
+}
+
+// TopK returns a new column vector with the k greatest values of m, in
+// descending order, together with their indices in m (data in row-major order).
+// If k is greater than the size of m, all the values are returned.
+func TopK(m Matrix, k int) (Matrix, []int) {
+	if k < 0 {
+		panic("mat64: k must be non-negative")
+	}
+	indices := ArgSort(m, true)
+	if k < len(indices) {
+		indices = indices[:k]
+	}
+	return Gather(m, indices), indices
+}
+
+// Gather returns a new column vector with the values of m at the given
+// indices (data in row-major order).
+func Gather(m Matrix, indices []int) Matrix {
+	data := m.Data()
+	out := NewEmptyVecDense(len(indices))
+	outData := out.Data()
+	for i, index := range indices {
+		outData[i] = data[index]
+	}
+	return out
+}
@@ -0,0 +1,137 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ag
+
+import (
+	"fmt"
+	"strings"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/ml/ag/fn"
+)
+
+const (
+	// anomalyMaxDepth is the maximum depth of the ancestry in the reports.
+	anomalyMaxDepth = 4
+	// anomalyMaxLines is the maximum number of nodes of the ancestry in the reports.
+	anomalyMaxLines = 16
+)
+
+// WithAnomalyDetection sets whether the graph verifies that the values
+// computed by the operators, and the gradients propagated by their backward,
+// don't contain NaN or infinite values. On the first anomaly, it panics
+// describing the operator responsible for it, the shapes of its operands, and
+// its ancestry in the graph.
+// The verification is expensive, and the forward and backward are always
+// executed serially: it is meant to find the cause of a diverging training.
+func WithAnomalyDetection(enabled bool) GraphOption {
+	return func(g *Graph) {
+		g.anomalyDetection = enabled
+	}
+}
+
+// AnomalyDetectionEnabled returns whether the anomaly detection is enabled.
+func (g *Graph) AnomalyDetectionEnabled() bool {
+	return g.anomalyDetection
+}
+
+// checkValue panics if the value of the operator is invalid.
+func (g *Graph) checkValue(op *Operator) {
+	if v, ok := invalidValue(op.value); ok {
+		panic(g.anomalyReport(fmt.Sprintf("the forward of the node #%d (%s) produced %v", op.id, nodeDescription(op), v), op))
+	}
+}
+
+// checkNoGradValue panics if the value computed by f, without keeping track
+// of the operands, is invalid.
+func (g *Graph) checkNoGradValue(f fn.Function, op *Operator) {
+	if v, ok := invalidValue(op.value); ok {
+		panic(fmt.Sprintf("ag: anomaly detected: the forward of the node #%d (operator %s) produced %v", op.id, functionName(f), v))
+	}
+}
+
+// checkOutputGrad panics if the gradients the back-propagation starts from
+// are invalid.
+func (g *Graph) checkOutputGrad(node Node) {
+	if v, ok := invalidValue(node.Grad()); ok {
+		panic(g.anomalyReport(fmt.Sprintf("the output gradients of the node #%d (%s) contain %v", node.ID(), nodeDescription(node), v), node))
+	}
+}
+
+// checkOperandsGrads panics if the backward of the operator has propagated
+// invalid gradients to its operands. Since the operators are visited in
+// reverse topological order, and the gradients of each one are checked as
+// soon as it has been visited, the operator is the source of the anomaly.
+func (g *Graph) checkOperandsGrads(op *Operator) {
+	for i, x := range op.operands {
+		if !x.RequiresGrad() {
+			continue
+		}
+		if v, ok := invalidValue(x.Grad()); ok {
+			panic(g.anomalyReport(fmt.Sprintf("the backward of the node #%d (%s) propagated %v to its operand %d (node #%d)",
+				op.id, nodeDescription(op), v, i, x.ID()), op))
+		}
+	}
+}
+
+// invalidValue returns the first NaN or infinite value of the matrix, if any.
+func invalidValue(m mat.Matrix) (mat.Float, bool) {
+	if m == nil {
+		return 0, false
+	}
+	for _, v := range m.Data() {
+		if v != v || mat.IsInf(v, 0) {
+			return v, true
+		}
+	}
+	return 0, false
+}
+
+// anomalyReport returns the description of the anomaly, followed by the
+// shapes of the operands of the node and by its (truncated) ancestry.
+func (g *Graph) anomalyReport(description string, node Node) string {
+	var sb strings.Builder
+	sb.WriteString("ag: anomaly detected: ")
+	sb.WriteString(description)
+	if op, ok := node.(*Operator); ok && len(op.operands) > 0 {
+		sb.WriteString("\noperands:")
+		for _, x := range op.operands {
+			fmt.Fprintf(&sb, " #%d %s;", x.ID(), shapeOf(x.Value()))
+		}
+	}
+	sb.WriteString("\nancestry:")
+	lines := 0
+	var visit func(n Node, depth int)
+	visit = func(n Node, depth int) {
+		if lines == anomalyMaxLines {
+			return
+		}
+		lines++
+		fmt.Fprintf(&sb, "\n%s#%d %s %s", strings.Repeat("  ", depth+1), n.ID(), nodeDescription(n), shapeOf(n.Value()))
+		op, ok := n.(*Operator)
+		if !ok || len(op.operands) == 0 {
+			return
+		}
+		if depth+1 == anomalyMaxDepth {
+			fmt.Fprintf(&sb, "\n%s...", strings.Repeat("  ", depth+2))
+			return
+		}
+		for _, x := range op.operands {
+			visit(x, depth+1)
+		}
+	}
+	visit(node, 0)
+	if lines == anomalyMaxLines {
+		sb.WriteString("\n  ...")
+	}
+	return sb.String()
+}
+
+func shapeOf(m mat.Matrix) string {
+	if m == nil {
+		return "(nil)"
+	}
+	return fmt.Sprintf("%d × %d", m.Rows(), m.Columns())
+}
@@ -0,0 +1,85 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ag
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/stretchr/testify/assert"
+)
+
+func anomalyOf(f func()) (report string) {
+	defer func() {
+		if r := recover(); r != nil {
+			report = fmt.Sprint(r)
+		}
+	}()
+	f()
+	return ""
+}
+
+func TestAnomalyDetection_Forward(t *testing.T) {
+	for _, incrementalForward := range []bool{true, false} {
+		g := NewGraph(WithAnomalyDetection(true), IncrementalForward(incrementalForward), ConcurrentComputations(4))
+		assert.True(t, g.AnomalyDetectionEnabled())
+		x := g.NewVariableWithName(mat.NewVecDense([]mat.Float{1, 2}), true, "x")
+		y := g.NewVariableWithName(mat.NewVecDense([]mat.Float{1, 3}), true, "y")
+
+		report := anomalyOf(func() {
+			g.Div(x, g.Sub(y, y))
+			g.Forward()
+		})
+		assert.Equal(t, "ag: anomaly detected: the forward of the node #3 (operator Div) produced +Inf\n"+
+			"operands: #0 2 × 1; #2 2 × 1;\n"+
+			"ancestry:\n"+
+			"  #3 operator Div 2 × 1\n"+
+			"    #0 variable x 2 × 1\n"+
+			"    #2 operator Sub 2 × 1\n"+
+			"      #1 variable y 2 × 1\n"+
+			"      #1 variable y 2 × 1", report)
+	}
+}
+
+func TestAnomalyDetection_Backward(t *testing.T) {
+	g := NewGraph(WithAnomalyDetection(true), ConcurrentComputations(4))
+	x := g.NewVariableWithName(mat.NewVecDense([]mat.Float{0, 4}), true, "x")
+	z := g.ReduceSum(g.Sqrt(x))
+	assert.Equal(t, mat.Float(2), z.ScalarValue())
+
+	report := anomalyOf(func() { g.Backward(z) })
+	assert.True(t, strings.HasPrefix(report,
+		"ag: anomaly detected: the backward of the node #1 (operator Sqrt) propagated +Inf to its operand 0 (node #0)\n"), report)
+
+	report = anomalyOf(func() {
+		g.ZeroGrad()
+		g.Backward(z, OutputGrad(mat.NewScalar(mat.NaN())))
+	})
+	assert.True(t, strings.HasPrefix(report,
+		"ag: anomaly detected: the output gradients of the node #2 (operator ReduceSum) contain NaN\n"), report)
+}
+
+func TestAnomalyDetection_Ancestry(t *testing.T) {
+	g := NewGraph(WithAnomalyDetection(true))
+	x := g.NewVariable(mat.NewScalar(1), true)
+	y := x
+	for i := 0; i < 6; i++ {
+		y = g.Add(y, x)
+	}
+	report := g.anomalyReport("test", y)
+	assert.Contains(t, report, "\n          ...")
+	assert.LessOrEqual(t, strings.Count(report, "#"), anomalyMaxLines+len(y.(*Operator).operands))
+}
+
+func TestAnomalyDetection_Disabled(t *testing.T) {
+	g := NewGraph()
+	assert.False(t, g.AnomalyDetectionEnabled())
+	x := g.NewVariable(mat.NewVecDense([]mat.Float{0}), true)
+	assert.NotPanics(t, func() {
+		g.Backward(g.ReduceSum(g.Sqrt(x)))
+	})
+}
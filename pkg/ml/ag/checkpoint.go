@@ -0,0 +1,117 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ag
+
+import (
+	"sort"
+
+	"github.com/nlpodyssey/spago/pkg/ml/ag/fn"
+)
+
+// checkpoint is a segment of the graph whose intermediate values are discarded
+// after the forward, and recomputed during the backward.
+type checkpoint struct {
+	// from and to are the IDs of the first and the last node of the segment.
+	from, to int
+	// outputs contains the IDs of the nodes returned by the segment, whose values are kept.
+	outputs map[int]bool
+	// restored reports whether the intermediate values have been recomputed.
+	restored bool
+}
+
+// Checkpoint runs the segment function, which defines a portion of the graph
+// and returns its output nodes, and discards the values of the intermediate
+// nodes of the portion, keeping only the ones of the outputs.
+// The discarded values are recomputed on demand during the backward, as soon
+// as it reaches the segment, and released again once it leaves it, trading
+// compute for memory (gradient checkpointing).
+//
+// Only the output nodes can be used outside the segment, since the values of
+// the other ones are nil. The segment must not be defined while other
+// goroutines are adding nodes outside of it, and checkpoints cannot be nested.
+// The values of the dropout operators are never discarded, since their
+// forward would draw a different mask.
+func (g *Graph) Checkpoint(segment func() []Node) []Node {
+	g.mu.Lock()
+	if g.checkpointing {
+		g.mu.Unlock()
+		panic("ag: nested checkpoints are not supported")
+	}
+	g.checkpointing = true
+	from := g.maxID + 1
+	g.mu.Unlock()
+	defer func() { // even if the segment panics
+		g.mu.Lock()
+		g.checkpointing = false
+		g.mu.Unlock()
+	}()
+
+	outputs := segment()
+	g.WaitForward()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	c := &checkpoint{
+		from:    from,
+		to:      g.maxID,
+		outputs: make(map[int]bool, len(outputs)),
+	}
+	for _, output := range outputs {
+		c.outputs[output.ID()] = true
+	}
+	if c.to >= c.from {
+		g.checkpoints = append(g.checkpoints, c)
+		g.discard(c)
+	}
+	return outputs
+}
+
+// discard releases the values of the intermediate operators of the checkpoint.
+func (g *Graph) discard(c *checkpoint) {
+	for _, node := range g.nodes[c.from : c.to+1] {
+		if op, ok := node.(*Operator); ok && g.discardable(c, op) {
+			g.releaseValue(op)
+		}
+	}
+	c.restored = false
+}
+
+// restore recomputes the values of the intermediate operators of the
+// checkpoint, in order of definition.
+func (g *Graph) restore(c *checkpoint) {
+	for _, node := range g.nodes[c.from : c.to+1] {
+		if op, ok := node.(*Operator); ok && op.value == nil && op.function != nil && !op.fused {
+			op.value = g.roundToHalf(op.function.Forward())
+		}
+	}
+	c.restored = true
+}
+
+func (g *Graph) discardable(c *checkpoint, op *Operator) bool {
+	if c.outputs[op.id] || op.function == nil {
+		return false
+	}
+	_, isDropout := op.function.(*fn.Dropout)
+	return !isDropout
+}
+
+// checkpointOf returns the checkpoint containing the node with the given ID, or nil.
+func (g *Graph) checkpointOf(id int) *checkpoint {
+	// the checkpoints are sorted, since they are defined one after the other
+	i := sort.Search(len(g.checkpoints), func(i int) bool {
+		return g.checkpoints[i].to >= id
+	})
+	if i < len(g.checkpoints) && g.checkpoints[i].from <= id {
+		return g.checkpoints[i]
+	}
+	return nil
+}
+
+// discardCheckpoints releases the intermediate values of all the checkpoints.
+func (g *Graph) discardCheckpoints() {
+	for _, c := range g.checkpoints {
+		g.discard(c)
+	}
+}
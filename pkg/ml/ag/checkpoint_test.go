@@ -0,0 +1,97 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ag
+
+import (
+	"fmt"
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGraph_Checkpoint(t *testing.T) {
+	// run builds a small recurrent network, returning the gradients of w and
+	// x, and the hidden states of the intermediate steps.
+	run := func(g *Graph, checkpoint bool) (gw, gx mat.Matrix, intermediate []Node) {
+		w := g.NewVariable(mat.NewDense(2, 2, []mat.Float{0.5, -0.3, 0.8, 0.1}), true)
+		x := g.NewVariable(mat.NewVecDense([]mat.Float{0.2, -0.7}), true)
+		h := x
+		for step := 0; step < 3; step++ {
+			segment := func() []Node {
+				a := g.Tanh(g.Mul(w, h))
+				intermediate = append(intermediate, a)
+				return []Node{g.Add(g.Sigmoid(g.Mul(w, a)), a)}
+			}
+			if checkpoint {
+				h = g.Checkpoint(segment)[0]
+			} else {
+				h = segment()[0]
+			}
+		}
+		g.Backward(g.ReduceSum(h))
+		return w.Grad().Clone(), x.Grad().Clone(), intermediate
+	}
+
+	for _, size := range []int{1, 4} {
+		t.Run(fmt.Sprintf("concurrent computations %d", size), func(t *testing.T) {
+			expectedGw, expectedGx, _ := run(NewGraph(ConcurrentComputations(size)), false)
+
+			g := NewGraph(ConcurrentComputations(size))
+			gw, gx, intermediate := run(g, true)
+			assert.InDeltaSlice(t, expectedGw.Data(), gw.Data(), 1.0e-6)
+			assert.InDeltaSlice(t, expectedGx.Data(), gx.Data(), 1.0e-6)
+			for _, node := range intermediate {
+				assert.Nil(t, node.Value())
+			}
+		})
+	}
+}
+
+func TestGraph_CheckpointValues(t *testing.T) {
+	g := NewGraph()
+	x := g.NewVariable(mat.NewVecDense([]mat.Float{1, 2}), true)
+	var a Node
+	out := g.Checkpoint(func() []Node {
+		a = g.Square(x)
+		return []Node{g.ReduceSum(a)}
+	})
+	assert.Nil(t, a.Value())
+	assert.Equal(t, mat.Float(5), out[0].ScalarValue())
+
+	g.Forward()
+	assert.Nil(t, a.Value())
+	assert.Equal(t, mat.Float(5), out[0].ScalarValue())
+}
+
+func TestGraph_CheckpointNested(t *testing.T) {
+	g := NewGraph()
+	x := g.NewScalar(1)
+	assert.Panics(t, func() {
+		g.Checkpoint(func() []Node {
+			return g.Checkpoint(func() []Node {
+				return []Node{g.Exp(x)}
+			})
+		})
+	})
+}
+
+func TestGraph_CheckpointPanic(t *testing.T) {
+	g := NewGraph()
+	x := g.NewVariable(mat.NewVecDense([]mat.Float{1, 2}), true)
+	assert.Panics(t, func() {
+		g.Checkpoint(func() []Node {
+			return []Node{g.Add(x, g.NewVariable(mat.NewVecDense([]mat.Float{1, 2, 3}), false))}
+		})
+	})
+
+	// the graph is not left in checkpointing mode
+	out := g.Checkpoint(func() []Node {
+		return []Node{g.ReduceSum(g.Exp(x))}
+	})
+	assert.InDelta(t, mat.Exp(1)+mat.Exp(2), out[0].ScalarValue(), 1.0e-5)
+	g.Backward(out[0])
+	assert.InDeltaSlice(t, []mat.Float{mat.Exp(1), mat.Exp(2)}, x.Grad().Data(), 1.0e-5)
+}
@@ -0,0 +1,132 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ag
+
+import (
+	"sync"
+
+	"github.com/nlpodyssey/spago/pkg/ml/ag/fn"
+	"github.com/nlpodyssey/spago/pkg/utils/processingqueue"
+)
+
+// WithConcurrentForward enables the concurrent forward (disabled by default):
+// instead of being computed on the calling goroutine, the value of each new
+// operator is computed in background as soon as the values of its operands
+// are available, by at most n concurrent workers. This way the independent
+// branches of the graph (e.g. the heads of an attention layer, or the towers
+// of a siamese network) are computed in parallel, while the caller keeps
+// defining the graph.
+//
+// The Value() of an operator blocks until it has been computed, and a panic
+// raised by its forward is raised again by Value(). The methods operating on
+// the whole graph (e.g. Forward, Backward and Clear) wait for all the pending
+// computations (see WaitForward).
+// The same dependency tracking is used by Forward() to recompute the graph.
+//
+// The concurrent forward is disabled while the anomaly detection is enabled.
+func WithConcurrentForward(n int) GraphOption {
+	if n < 1 {
+		panic("ag: WithConcurrentForward value must be greater than zero")
+	}
+	return func(g *Graph) {
+		g.concurrentForward = processingqueue.New(n)
+	}
+}
+
+// ConcurrentForwardEnabled reports whether the values of the operators are
+// computed in background (see WithConcurrentForward).
+func (g *Graph) ConcurrentForwardEnabled() bool {
+	return g.concurrentForward != nil && !g.anomalyDetection
+}
+
+// WaitForward blocks until the values of all the operators scheduled by the
+// concurrent forward have been computed. It returns immediately if the
+// concurrent forward is disabled.
+func (g *Graph) WaitForward() {
+	g.pendingForward.Wait()
+}
+
+// pendingValue tracks the background computation of the value of an operator.
+type pendingValue struct {
+	done chan struct{}
+	// recovered is the value of the panic raised by the forward, if any.
+	recovered interface{}
+}
+
+// wait blocks until the value has been computed, raising the panic of the
+// forward again, if any.
+func (p *pendingValue) wait() {
+	<-p.done
+	if p.recovered != nil {
+		panic(p.recovered)
+	}
+}
+
+// scheduleForward computes the value of the operator in background, after the
+// values of the operands.
+func (g *Graph) scheduleForward(op *Operator, f fn.Function, operands []Node) {
+	p := &pendingValue{done: make(chan struct{})}
+	op.pending = p
+	g.pendingForward.Add(1)
+	go func() {
+		defer g.pendingForward.Done()
+		defer close(p.done)
+		defer func() {
+			p.recovered = recover()
+		}()
+		// the operands are awaited before taking a worker, so that the workers
+		// are never held by the computations which are not ready
+		for _, operand := range operands {
+			operand.Value()
+		}
+		g.concurrentForward.Run(func() {
+			op.value, op.stats = g.compute(f)
+		})
+	}()
+}
+
+// runDataflow computes the operators as soon as their operands have been
+// computed, by the workers of the concurrent forward.
+func (h *forwardHandler) runDataflow() {
+	fromTS, toTS := h.fromTimeStep, h.toTimeStep
+	done := make(map[int]chan struct{})
+	var wg sync.WaitGroup
+	var once sync.Once
+	var recovered interface{}
+	for _, node := range h.g.nodes {
+		op, isOperator := node.(*Operator)
+		if !isOperator || op.function == nil || op.fused || (op.timeStep < fromTS || (toTS != -1 && op.timeStep > toTS)) {
+			continue
+		}
+		var deps []chan struct{}
+		for _, operand := range op.operands {
+			if c, ok := done[operand.ID()]; ok {
+				deps = append(deps, c)
+			}
+		}
+		c := make(chan struct{})
+		done[op.id] = c
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer close(c)
+			defer func() {
+				if r := recover(); r != nil {
+					once.Do(func() { recovered = r })
+				}
+			}()
+			for _, dep := range deps {
+				<-dep
+			}
+			h.g.concurrentForward.Run(func() {
+				op.value, op.stats = h.g.compute(op.function)
+			})
+		}()
+	}
+	wg.Wait()
+	if recovered != nil {
+		panic(recovered)
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ag
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/ml/ag/fn"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	registerRendezvous sync.Once
+	// rendezvousArrived counts the forwards of the "rendezvous" operator.
+	rendezvousArrived int32
+	// rendezvousMet counts the forwards which have seen the other one.
+	rendezvousMet int32
+)
+
+// rendezvous returns a custom operator waiting (up to one second) for another
+// concurrent forward of the operator before returning its operand.
+func rendezvous(g *Graph, x Node) Node {
+	registerRendezvous.Do(func() {
+		fn.Register("rendezvous", 1, func(xs []mat.Matrix) mat.Matrix {
+			atomic.AddInt32(&rendezvousArrived, 1)
+			deadline := time.Now().Add(time.Second)
+			for atomic.LoadInt32(&rendezvousArrived) < 2 && time.Now().Before(deadline) {
+				time.Sleep(time.Millisecond)
+			}
+			if atomic.LoadInt32(&rendezvousArrived) >= 2 {
+				atomic.AddInt32(&rendezvousMet, 1)
+			}
+			return xs[0].Clone()
+		}, func(xs []mat.Matrix, y, gy mat.Matrix) []mat.Matrix {
+			return []mat.Matrix{gy.Clone()}
+		})
+	})
+	return g.Custom("rendezvous", x)
+}
+
+func TestWithConcurrentForward(t *testing.T) {
+	assert.Panics(t, func() { WithConcurrentForward(0) })
+	assert.False(t, NewGraph().ConcurrentForwardEnabled())
+	assert.True(t, NewGraph(WithConcurrentForward(2)).ConcurrentForwardEnabled())
+	g := NewGraph(WithConcurrentForward(2), WithAnomalyDetection(true))
+	assert.False(t, g.ConcurrentForwardEnabled())
+}
+
+// branches defines two independent branches, merged by the output.
+func branches(g *Graph) (x, w1, w2, y Node) {
+	x = g.NewVariable(mat.NewVecDense([]mat.Float{1, 2, 3}), true)
+	w1 = g.NewVariable(mat.NewDense(2, 3, []mat.Float{1, 0, -1, 0.5, 0.5, 0}), true)
+	w2 = g.NewVariable(mat.NewDense(2, 3, []mat.Float{0, 1, 0, -1, 2, 1}), true)
+	h1 := g.Tanh(g.Mul(w1, x))
+	h2 := g.Sigmoid(g.Mul(w2, x))
+	y = g.ReduceSum(g.Prod(h1, h2))
+	return
+}
+
+func TestGraph_ConcurrentForward(t *testing.T) {
+	eager := NewGraph()
+	x, w1, w2, y := branches(eager)
+	eager.Backward(y)
+
+	for _, opts := range [][]GraphOption{
+		{WithConcurrentForward(1)},
+		{WithConcurrentForward(4)},
+		{WithConcurrentForward(4), IncrementalForward(false)},
+	} {
+		g := NewGraph(opts...)
+		cx, cw1, cw2, cy := branches(g)
+		if !g.IncrementalForwardEnabled() {
+			g.Forward()
+		}
+		assert.InDelta(t, y.ScalarValue(), cy.ScalarValue(), 1.0e-6)
+		g.Backward(cy)
+		assert.InDeltaSlice(t, x.Grad().Data(), cx.Grad().Data(), 1.0e-6)
+		assert.InDeltaSlice(t, w1.Grad().Data(), cw1.Grad().Data(), 1.0e-6)
+		assert.InDeltaSlice(t, w2.Grad().Data(), cw2.Grad().Data(), 1.0e-6)
+
+		// recompute
+		g.ClearForReuse()
+		g.Forward()
+		assert.InDelta(t, y.ScalarValue(), cy.ScalarValue(), 1.0e-6)
+		g.Clear()
+	}
+}
+
+func TestGraph_ConcurrentForward_IndependentBranches(t *testing.T) {
+	atomic.StoreInt32(&rendezvousArrived, 0)
+	atomic.StoreInt32(&rendezvousMet, 0)
+	g := NewGraph(WithConcurrentForward(2))
+	defer g.Clear()
+	x := g.NewVariable(mat.NewScalar(2), true)
+	a := rendezvous(g, g.Square(x))
+	b := rendezvous(g, g.Exp(x))
+	y := g.Add(a, b)
+
+	// the first branch is not computed on the calling goroutine, otherwise
+	// the second one would not have been defined yet
+	assert.InDelta(t, 4+mat.Exp(2), y.ScalarValue(), 1.0e-5)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&rendezvousMet))
+
+	g.Backward(y)
+	assert.InDelta(t, 4+mat.Exp(2), x.Grad().Scalar(), 1.0e-5)
+}
+
+func TestGraph_ConcurrentForward_Panic(t *testing.T) {
+	g := NewGraph(WithConcurrentForward(2))
+	a := g.NewVariable(mat.NewVecDense([]mat.Float{1, 2}), false)
+	b := g.NewVariable(mat.NewVecDense([]mat.Float{1, 2, 3}), false)
+	y := g.Add(a, b)
+	z := g.Square(y)
+
+	// the panic of the forward is raised again by Value(), also by the
+	// operators depending on it
+	assert.Panics(t, func() { y.Value() })
+	assert.Panics(t, func() { z.Value() })
+	g.Clear()
+}
+
+func TestGraph_ConcurrentForward_NoGrad(t *testing.T) {
+	g := NewGraph(WithConcurrentForward(2), WithGrad(false))
+	defer g.Clear()
+	x := g.NewVariable(mat.NewVecDense([]mat.Float{1, 2}), false)
+	y := g.ReduceSum(g.Square(x))
+	assert.Equal(t, mat.Float(5), y.ScalarValue())
+	g.WaitForward()
+}
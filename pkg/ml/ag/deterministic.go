@@ -0,0 +1,40 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ag
+
+// WithDeterministicBackward sets whether the gradients are accumulated in a
+// deterministic order (disabled by default), so that the backward produces
+// bitwise-identical gradients run-to-run, e.g. for the regression tests and
+// the replication of experiments.
+//
+// When a node is the operand of many operators, its gradient is the sum of
+// their contributions; since the floating-point addition is not associative,
+// the result depends on the order in which they are accumulated, which varies
+// when the operators are back-propagated concurrently (see
+// ConcurrentComputations). With the deterministic backward, the operators are
+// always back-propagated serially, in reverse order of definition.
+//
+// The cost is the loss of the parallelism among the operators: the backward of
+// a wide graph can be several times slower on a multi-core machine. The
+// forward, whose results don't depend on the order of the computations, is
+// not affected. The determinism is also subject to the one of the matrix
+// backend (e.g. a multi-threaded BLAS library may not guarantee it).
+func WithDeterministicBackward(enabled bool) GraphOption {
+	return func(g *Graph) {
+		g.deterministicBackward = enabled
+	}
+}
+
+// DeterministicBackwardEnabled returns whether the gradients are accumulated
+// in a deterministic order (see WithDeterministicBackward).
+func (g *Graph) DeterministicBackwardEnabled() bool {
+	return g.deterministicBackward
+}
+
+// concurrentBackward reports whether the operators are back-propagated
+// concurrently.
+func (g *Graph) concurrentBackward() bool {
+	return g.processingQueue.Size() > 1 && !g.anomalyDetection && !g.deterministicBackward
+}
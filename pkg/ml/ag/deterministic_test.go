@@ -0,0 +1,67 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ag
+
+import (
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/mat32/rand"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithDeterministicBackward(t *testing.T) {
+	assert.False(t, NewGraph().DeterministicBackwardEnabled())
+	g := NewGraph(ConcurrentComputations(4), WithDeterministicBackward(true))
+	assert.True(t, g.DeterministicBackwardEnabled())
+	assert.False(t, g.concurrentBackward())
+	assert.True(t, NewGraph(ConcurrentComputations(4)).concurrentBackward())
+}
+
+// wideGraph defines many operators, at the same height, sharing the operand x.
+func wideGraph(g *Graph) (x Node, y Node) {
+	r := rand.NewLockedRand(42)
+	data := func(size int) []mat.Float {
+		out := make([]mat.Float, size)
+		for i := range out {
+			out[i] = (mat.Float(r.Float32()) - 0.5) * 1.0e3
+		}
+		return out
+	}
+	x = g.NewVariable(mat.NewVecDense(data(8)), true)
+	var outputs []Node
+	for i := 0; i < 64; i++ {
+		w := g.NewVariable(mat.NewDense(8, 8, data(64)), true)
+		outputs = append(outputs, g.ReduceSum(g.Tanh(g.Mul(w, x))), g.ReduceSum(g.Mul(g.TMatMul(w, w), x)))
+	}
+	return x, g.Sum(outputs...)
+}
+
+func TestGraph_DeterministicBackward(t *testing.T) {
+	serial := NewGraph(ConcurrentComputations(1))
+	expected, y := wideGraph(serial)
+	serial.Backward(y)
+
+	for i := 0; i < 5; i++ {
+		g := NewGraph(ConcurrentComputations(8), WithDeterministicBackward(true))
+		x, y := wideGraph(g)
+		g.Backward(y)
+		assert.Equal(t, expected.Grad().Data(), x.Grad().Data())
+	}
+}
+
+func TestGraph_DeterministicBackward_SameOperand(t *testing.T) {
+	var expected []mat.Float
+	for i := 0; i < 10; i++ {
+		g := NewGraph(WithDeterministicBackward(true))
+		a := g.NewVariable(mat.NewDense(3, 2, []mat.Float{0.1, 1.0e4, -3.3, 7.1, 1.0e-3, 2.2}), true)
+		g.Backward(g.ReduceSum(g.Mul(g.TMatMul(a, a), g.NewVariable(mat.NewVecDense([]mat.Float{1, -1}), false))))
+		if expected == nil {
+			expected = a.Grad().Data()
+			continue
+		}
+		assert.Equal(t, expected, a.Grad().Data())
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ag
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+)
+
+// exportedNode is the description of a node produced by ExportJSON.
+type exportedNode struct {
+	ID       int    `json:"id"`
+	Kind     string `json:"kind"`
+	Name     string `json:"name,omitempty"`
+	TimeStep int    `json:"timeStep"`
+	// Shape contains the rows and the columns of the value, if any.
+	Shape        []int `json:"shape,omitempty"`
+	Operands     []int `json:"operands,omitempty"`
+	RequiresGrad bool  `json:"requiresGrad"`
+	// ElapsedNs is the time spent in the forward computation, in nanoseconds
+	// (see WithTimings).
+	ElapsedNs int64 `json:"elapsedNs,omitempty"`
+}
+
+// ExportJSON writes a JSON description of the nodes of the graph, in order of
+// definition, with their kind ("variable", "operator" or "wrapper"), their name
+// (the function of the operators), the shape of their values, the IDs of the
+// operands, and the time spent in their forward computation, if recorded
+// (see WithTimings).
+func (g *Graph) ExportJSON(w io.Writer) error {
+	nodes := g.exportedNodes()
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		Nodes []exportedNode `json:"nodes"`
+	}{Nodes: nodes})
+}
+
+// ExportDOT writes the graph in the DOT language of Graphviz, with the same
+// information as ExportJSON. The variables and the wrappers are drawn as boxes.
+// For a richer visualization, see the graphviz package.
+func (g *Graph) ExportDOT(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "digraph {")
+	fmt.Fprintln(bw, "  rankdir=LR;")
+	nodes := g.exportedNodes()
+	for _, n := range nodes {
+		label := []string{fmt.Sprintf("#%d", n.ID), strings.TrimSpace(n.Kind + " " + n.Name)}
+		if n.Kind == "operator" {
+			label[1] = n.Name
+		}
+		if n.Shape != nil {
+			label = append(label, fmt.Sprintf("%d × %d", n.Shape[0], n.Shape[1]))
+		}
+		if n.ElapsedNs > 0 {
+			label = append(label, time.Duration(n.ElapsedNs).String())
+		}
+		shape := "ellipse"
+		if n.Kind != "operator" {
+			shape = "box"
+		}
+		fmt.Fprintf(bw, "  n%d [shape=%s, label=%s];\n", n.ID, shape, dotQuote(strings.Join(label, "\n")))
+	}
+	for _, n := range nodes {
+		for _, operand := range n.Operands {
+			fmt.Fprintf(bw, "  n%d -> n%d;\n", operand, n.ID)
+		}
+	}
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
+
+// exportedNodes returns the description of the nodes of the graph.
+func (g *Graph) exportedNodes() []exportedNode {
+	g.WaitForward()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	nodes := make([]exportedNode, len(g.nodes))
+	for i, node := range g.nodes {
+		n := exportedNode{
+			ID:           node.ID(),
+			TimeStep:     node.TimeStep(),
+			RequiresGrad: node.RequiresGrad(),
+		}
+		var value mat.Matrix
+		switch nt := node.(type) {
+		case *Variable:
+			n.Kind, n.Name, value = "variable", nt.Name(), nt.value
+		case *Operator:
+			n.Kind, n.Name, value = "operator", nt.Name(), nt.value
+			n.ElapsedNs = int64(nt.stats.elapsed)
+			for _, operand := range nt.operands {
+				n.Operands = append(n.Operands, operand.ID())
+			}
+		case *Wrapper:
+			n.Kind, value = "wrapper", nt.Value()
+			if named, ok := nt.GradValue.(interface{ Name() string }); ok {
+				n.Name = named.Name() // e.g. the parameters of the models
+			}
+		}
+		if value != nil {
+			n.Shape = []int{value.Rows(), value.Columns()}
+		}
+		nodes[i] = n
+	}
+	return nodes
+}
+
+// dotQuote returns s as a DOT quoted string, where the new lines are
+// centered line breaks.
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
@@ -0,0 +1,79 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ag
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/ml/ag/fn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGraph_ExportJSON(t *testing.T) {
+	g := NewGraph(WithTimings(true))
+	x := g.NewVariableWithName(mat.NewVecDense([]mat.Float{1, 2}), true, "x")
+	w := g.NewWrapNoGrad(g.NewVariable(mat.NewScalar(3), false))
+	y := g.ProdScalar(x, w)
+	assert.Greater(t, int64(y.(*Operator).Elapsed()), int64(0))
+
+	var buf bytes.Buffer
+	require.NoError(t, g.ExportJSON(&buf))
+	var out struct {
+		Nodes []exportedNode `json:"nodes"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &out))
+	require.Len(t, out.Nodes, 4)
+	assert.Equal(t, exportedNode{ID: 0, Kind: "variable", Name: "x", Shape: []int{2, 1}, RequiresGrad: true}, out.Nodes[0])
+	assert.Equal(t, "wrapper", out.Nodes[2].Kind)
+	op := out.Nodes[3]
+	assert.Equal(t, "operator", op.Kind)
+	assert.Equal(t, "ProdScalar", op.Name)
+	assert.Equal(t, []int{0, 2}, op.Operands)
+	assert.Equal(t, []int{2, 1}, op.Shape)
+	assert.Greater(t, op.ElapsedNs, int64(0))
+}
+
+func TestGraph_ExportDOT(t *testing.T) {
+	g := NewGraph()
+	x := g.NewVariableWithName(mat.NewVecDense([]mat.Float{1, 2}), true, `a"b`)
+	g.ReduceSum(g.Square(x))
+
+	var buf bytes.Buffer
+	require.NoError(t, g.ExportDOT(&buf))
+	expected := "digraph {\n" +
+		"  rankdir=LR;\n" +
+		"  n0 [shape=box, label=\"#0\\nvariable a\\\"b\\n2 × 1\"];\n" +
+		"  n1 [shape=ellipse, label=\"#1\\nSquare\\n2 × 1\"];\n" +
+		"  n2 [shape=ellipse, label=\"#2\\nReduceSum\\n1 × 1\"];\n" +
+		"  n0 -> n1;\n" +
+		"  n1 -> n2;\n" +
+		"}\n"
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestGraph_ExportJSON_CustomOperator(t *testing.T) {
+	fn.Register("test.double", 1,
+		func(xs []mat.Matrix) mat.Matrix { return xs[0].ProdScalar(2) },
+		func(xs []mat.Matrix, y, gy mat.Matrix) []mat.Matrix { return []mat.Matrix{gy.ProdScalar(2)} },
+	)
+	g := NewGraph()
+	x := g.NewVariable(mat.NewVecDense([]mat.Float{1, 2}), true)
+	y := g.Custom("test.double", x)
+	assert.InDeltaSlice(t, []mat.Float{2, 4}, y.Value().Data(), 1.0e-6)
+	g.Backward(g.ReduceSum(y))
+	assert.InDeltaSlice(t, []mat.Float{2, 2}, x.Grad().Data(), 1.0e-6)
+
+	var buf bytes.Buffer
+	require.NoError(t, g.ExportJSON(&buf))
+	var out struct {
+		Nodes []exportedNode `json:"nodes"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &out))
+	assert.Equal(t, "test.double", out.Nodes[1].Name)
+}
@@ -20,6 +20,11 @@ func NewAdd(x1, x2 Operand) *Add {
 	return &Add{x1: x1, x2: x2}
 }
 
+// OutputShape returns the shape of the output, given the shapes of the operands.
+func (r *Add) OutputShape(inputs ...Shape) (Shape, error) {
+	return elementwiseShape(inputs)
+}
+
 // Forward computes the output of the function.
 func (r *Add) Forward() mat.Matrix {
 	x1v := r.x1.Value()
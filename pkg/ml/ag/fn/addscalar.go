@@ -21,6 +21,11 @@ func NewAddScalar(x1, x2 Operand) *AddScalar {
 	return &AddScalar{x1: x1, x2: x2}
 }
 
+// OutputShape returns the shape of the output, given the shapes of the operands.
+func (r *AddScalar) OutputShape(inputs ...Shape) (Shape, error) {
+	return scalarShape(inputs)
+}
+
 // Forward computes the output of the function.
 // It doesn't backward on the scalar value x2.
 func (r *AddScalar) Forward() mat.Matrix {
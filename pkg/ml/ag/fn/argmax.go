@@ -0,0 +1,71 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fn
+
+import (
+	"fmt"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+)
+
+var _ Function = &ArgMax{}
+
+// ArgMax is a function to compute the index of the greatest element of each
+// column (AlongColumns) or row (AlongRows) of a matrix. The output is a
+// vector holding the indices, one per column or row (i.e. a scalar for a
+// vector along its column).
+// The function is not differentiable: the gradients are not propagated.
+// The first index is returned in case of ties.
+type ArgMax struct {
+	x    Operand
+	axis Axis
+}
+
+// NewArgMax returns a new ArgMax Function.
+func NewArgMax(x Operand, axis Axis) *ArgMax {
+	axis.validate()
+	return &ArgMax{x: x, axis: axis}
+}
+
+// OutputShape returns the shape of the output, given the shape of the operand.
+func (r *ArgMax) OutputShape(inputs ...Shape) (Shape, error) {
+	x := inputs[0]
+	if x.Size() == 0 {
+		return Shape{}, fmt.Errorf("%v is empty", x)
+	}
+	if r.axis == AlongRows {
+		return Shape{Rows: x.Rows, Columns: 1}, nil
+	}
+	return Shape{Rows: x.Columns, Columns: 1}, nil
+}
+
+// Forward computes the output of the function.
+func (r *ArgMax) Forward() mat.Matrix {
+	x := r.x.Value()
+	if x.Size() == 0 {
+		panic("fn: ArgMax of an empty matrix")
+	}
+	data := x.Data()
+	lines := r.axis.lines(x.Rows(), x.Columns())
+	out := mat.NewEmptyVecDense(len(lines))
+	outData := out.Data()
+	for i, line := range lines {
+		best := 0
+		for k, j := range line {
+			if data[j] > data[line[best]] {
+				best = k
+			}
+		}
+		outData[i] = mat.Float(best)
+	}
+	return out
+}
+
+// Backward does nothing, the gradients are not propagated.
+func (r *ArgMax) Backward(gy mat.Matrix) {
+	if gy.Size() != len(r.axis.lines(r.x.Value().Rows(), r.x.Value().Columns())) {
+		panic("fn: matrices with not compatible size")
+	}
+}
@@ -0,0 +1,41 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fn
+
+import (
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArgMax_Forward(t *testing.T) {
+	x := &variable{
+		value:        mat.NewVecDense([]mat.Float{0.1, 0.7, -0.2, 0.7}),
+		requiresGrad: true,
+	}
+	f := NewArgMax(x, AlongColumns)
+	y := f.Forward()
+	assert.Equal(t, []mat.Float{1}, y.Data())
+
+	f.Backward(mat.NewScalar(1))
+	assert.Nil(t, x.grad)
+
+	x = &variable{
+		value: mat.NewDense(2, 3, []mat.Float{
+			1, 5, 2,
+			3, 4, 6,
+		}),
+	}
+	assert.Equal(t, []mat.Float{1, 0, 1}, NewArgMax(x, AlongColumns).Forward().Data())
+	assert.Equal(t, []mat.Float{1, 2}, NewArgMax(x, AlongRows).Forward().Data())
+}
+
+func TestArgMax_Panics(t *testing.T) {
+	x := &variable{value: mat.NewEmptyDense(2, 3)}
+	assert.Panics(t, func() { NewArgMax(x, Axis(2)) })
+	assert.Panics(t, func() { NewArgMax(&variable{value: mat.NewEmptyVecDense(0)}, AlongColumns).Forward() })
+	assert.Panics(t, func() { NewArgMax(x, AlongRows).Backward(mat.NewEmptyVecDense(3)) })
+}
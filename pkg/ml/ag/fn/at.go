@@ -5,6 +5,7 @@
 package fn
 
 import (
+	"fmt"
 	mat "github.com/nlpodyssey/spago/pkg/mat32"
 )
 
@@ -22,6 +23,14 @@ func NewAt(x Operand, i int, j int) *At {
 	return &At{x: x, i: i, j: j}
 }
 
+// OutputShape returns the shape of the output, given the shape of the operand.
+func (r *At) OutputShape(inputs ...Shape) (Shape, error) {
+	if x := inputs[0]; r.i < 0 || r.i >= x.Rows || r.j < 0 || r.j >= x.Columns {
+		return Shape{}, fmt.Errorf("index (%d, %d) out of range of %v", r.i, r.j, x)
+	}
+	return Shape{Rows: 1, Columns: 1}, nil
+}
+
 // Forward computes the output of the function.
 func (r *At) Forward() mat.Matrix {
 	return mat.NewScalar(r.x.Value().At(r.i, r.j))
@@ -5,6 +5,7 @@
 package fn
 
 import (
+	"fmt"
 	mat "github.com/nlpodyssey/spago/pkg/mat32"
 )
 
@@ -21,6 +22,14 @@ func NewAtVec(x Operand, i int) *AtVec {
 	return &AtVec{x: x, i: i}
 }
 
+// OutputShape returns the shape of the output, given the shape of the operand.
+func (r *AtVec) OutputShape(inputs ...Shape) (Shape, error) {
+	if x := inputs[0]; r.i < 0 || r.i >= x.Size() {
+		return Shape{}, fmt.Errorf("index %d out of range of %v", r.i, x)
+	}
+	return Shape{Rows: 1, Columns: 1}, nil
+}
+
 // Forward computes the output of the function.
 func (r *AtVec) Forward() mat.Matrix {
 	return mat.NewScalar(r.x.Value().AtVec(r.i))
@@ -22,6 +22,11 @@ func NewCELU(x, alpha Operand) *CELU {
 	return &CELU{x: x, alpha: alpha}
 }
 
+// OutputShape returns the shape of the output, given the shapes of the operands.
+func (r *CELU) OutputShape(inputs ...Shape) (Shape, error) {
+	return scalarShape(inputs)
+}
+
 // Forward computes the output of the function.
 func (r *CELU) Forward() mat.Matrix {
 	y := mat.GetDenseWorkspace(r.x.Value().Dims())
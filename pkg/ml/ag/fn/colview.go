@@ -4,7 +4,11 @@
 
 package fn
 
-import mat "github.com/nlpodyssey/spago/pkg/mat32"
+import (
+	"fmt"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+)
 
 var _ Function = &ColView{}
 
@@ -22,6 +26,14 @@ func NewColView(x Operand, i int) *ColView {
 	return &ColView{x: x, i: i}
 }
 
+// OutputShape returns the shape of the output, given the shape of the operand.
+func (r *ColView) OutputShape(inputs ...Shape) (Shape, error) {
+	if x := inputs[0]; r.i < 0 || r.i >= x.Columns {
+		return Shape{}, fmt.Errorf("column %d out of range of %v", r.i, x)
+	}
+	return Shape{Rows: 1, Columns: inputs[0].Rows}, nil
+}
+
 // Forward computes the output of the function.
 func (r *ColView) Forward() mat.Matrix {
 	xv := r.x.Value()
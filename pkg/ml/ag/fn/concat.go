@@ -5,6 +5,7 @@
 package fn
 
 import (
+	"fmt"
 	mat "github.com/nlpodyssey/spago/pkg/mat32"
 )
 
@@ -24,6 +25,18 @@ func NewConcat(xs []Operand) *Concat {
 	}
 }
 
+// OutputShape returns the shape of the output, given the shapes of the operands.
+func (r *Concat) OutputShape(inputs ...Shape) (Shape, error) {
+	size := 0
+	for _, x := range inputs {
+		if !x.IsVector() {
+			return Shape{}, fmt.Errorf("%v is not a vector", x)
+		}
+		size += x.Size()
+	}
+	return Shape{Rows: size, Columns: 1}, nil
+}
+
 // Forward computes the output of the function.
 func (r *Concat) Forward() mat.Matrix {
 	r.ySize = 0 // reset output size
@@ -0,0 +1,287 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fn
+
+import (
+	"fmt"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+)
+
+var _ Function = &Conv2D{}
+
+// Conv2DConfig provides the geometry of a 2D convolution.
+//
+// The images are represented as matrices with one row for each channel, each
+// row containing the pixels of the channel in row-major order: an image with
+// C channels of size Height × Width is a C × (Height*Width) matrix.
+// The zero values of the strides, the dilations and the groups are
+// interpreted as 1.
+type Conv2DConfig struct {
+	// Height and Width are the size of the input channels.
+	Height int
+	Width  int
+	// KernelRows and KernelColumns are the size of the kernels.
+	KernelRows    int
+	KernelColumns int
+	// StrideRows and StrideColumns are the steps between the applications of the kernels.
+	StrideRows    int
+	StrideColumns int
+	// PaddingRows and PaddingColumns are the number of zeros implicitly added at both sides of the input.
+	PaddingRows    int
+	PaddingColumns int
+	// DilationRows and DilationColumns are the spacing between the elements of the kernels.
+	DilationRows    int
+	DilationColumns int
+	// Groups is the number of groups the input and output channels are split into:
+	// each group of output channels only sees the corresponding group of input channels.
+	Groups int
+}
+
+func (c Conv2DConfig) withDefaults() Conv2DConfig {
+	c.StrideRows = orOne(c.StrideRows)
+	c.StrideColumns = orOne(c.StrideColumns)
+	c.DilationRows = orOne(c.DilationRows)
+	c.DilationColumns = orOne(c.DilationColumns)
+	c.Groups = orOne(c.Groups)
+	return c
+}
+
+func orOne(v int) int {
+	if v == 0 {
+		return 1
+	}
+	return v
+}
+
+// OutputSize returns the size of the output channels.
+func (c Conv2DConfig) OutputSize() (rows, columns int) {
+	c = c.withDefaults()
+	rows = outputLength(c.Height, c.KernelRows, c.StrideRows, c.PaddingRows, c.DilationRows)
+	columns = outputLength(c.Width, c.KernelColumns, c.StrideColumns, c.PaddingColumns, c.DilationColumns)
+	return
+}
+
+func outputLength(size, kernel, stride, padding, dilation int) int {
+	return (size+2*padding-dilation*(kernel-1)-1)/stride + 1
+}
+
+func (c Conv2DConfig) validate() {
+	if c.Height <= 0 || c.Width <= 0 || c.KernelRows <= 0 || c.KernelColumns <= 0 {
+		panic("fn: the input and kernel sizes must be positive")
+	}
+	if c.StrideRows < 0 || c.StrideColumns < 0 || c.DilationRows < 0 || c.DilationColumns < 0 ||
+		c.PaddingRows < 0 || c.PaddingColumns < 0 || c.Groups < 0 {
+		panic("fn: negative convolution parameters")
+	}
+	if rows, cols := c.OutputSize(); rows <= 0 || cols <= 0 {
+		panic("fn: the kernel is larger than the padded input")
+	}
+}
+
+// im2col returns the matrix whose columns are the (flattened) receptive
+// fields of the kernel at each output position, for the given channels of x.
+// The padded elements are zeros.
+func (c Conv2DConfig) im2col(x []mat.Float, channels int) *mat.Dense {
+	outRows, outCols := c.OutputSize()
+	kernelSize := c.KernelRows * c.KernelColumns
+	cols := mat.NewEmptyDense(channels*kernelSize, outRows*outCols)
+	data := cols.Data()
+	c.forEachPosition(channels, func(row, col, index int) {
+		data[row*cols.Columns()+col] = x[index]
+	})
+	return cols
+}
+
+// col2im accumulates the elements of the matrix built by im2col back to the
+// input positions they come from.
+func (c Conv2DConfig) col2im(cols mat.Matrix, gx []mat.Float, channels int) {
+	data := cols.Data()
+	c.forEachPosition(channels, func(row, col, index int) {
+		gx[index] += data[row*cols.Columns()+col]
+	})
+}
+
+// forEachPosition calls fn for each element of the im2col matrix that is not
+// a padding, passing its row and column along with the index of the input
+// element (in the channels × pixels data) it corresponds to.
+func (c Conv2DConfig) forEachPosition(channels int, fn func(row, col, index int)) {
+	outRows, outCols := c.OutputSize()
+	for ch := 0; ch < channels; ch++ {
+		for ki := 0; ki < c.KernelRows; ki++ {
+			for kj := 0; kj < c.KernelColumns; kj++ {
+				row := (ch*c.KernelRows+ki)*c.KernelColumns + kj
+				for oi := 0; oi < outRows; oi++ {
+					i := oi*c.StrideRows - c.PaddingRows + ki*c.DilationRows
+					if i < 0 || i >= c.Height {
+						continue
+					}
+					for oj := 0; oj < outCols; oj++ {
+						j := oj*c.StrideColumns - c.PaddingColumns + kj*c.DilationColumns
+						if j < 0 || j >= c.Width {
+							continue
+						}
+						fn(row, oi*outCols+oj, (ch*c.Height+i)*c.Width+j)
+					}
+				}
+			}
+		}
+	}
+}
+
+// Conv2D is an operator to perform a 2D convolution (more precisely, a
+// cross-correlation), with stride, padding, dilation and groups.
+//
+// The input x is a C_in × (Height*Width) image (see Conv2DConfig), the weights
+// w are a C_out × (C_in/Groups * KernelRows*KernelColumns) matrix, each row
+// containing the kernels of an output channel for the input channels of its
+// group, and the optional bias b is a vector of size C_out.
+// The output is a C_out × (OutRows*OutColumns) image.
+//
+// The forward unfolds the receptive fields with im2col, and computes each
+// group with a single matrix multiplication.
+type Conv2D struct {
+	x      Operand
+	w      Operand
+	b      Operand
+	config Conv2DConfig
+	// initialized during the forward pass
+	cols []*mat.Dense
+}
+
+// NewConv2D returns a new Conv2D Function. The bias b can be nil.
+func NewConv2D(x, w, b Operand, config Conv2DConfig) *Conv2D {
+	config.validate()
+	return &Conv2D{
+		x:      x,
+		w:      w,
+		b:      b,
+		config: config.withDefaults(),
+		cols:   nil,
+	}
+}
+
+// OutputShape returns the shape of the output, given the shapes of the operands.
+func (r *Conv2D) OutputShape(inputs ...Shape) (Shape, error) {
+	x, w := inputs[0], inputs[1]
+	c := r.config
+	if x.Columns != c.Height*c.Width {
+		return Shape{}, fmt.Errorf("the input %v has %d columns, expected %d", x, x.Columns, c.Height*c.Width)
+	}
+	if x.Rows%c.Groups != 0 || w.Rows%c.Groups != 0 {
+		return Shape{}, fmt.Errorf("the channels of %v and %v are not divisible by %d groups", x, w, c.Groups)
+	}
+	if expected := x.Rows / c.Groups * c.KernelRows * c.KernelColumns; w.Columns != expected {
+		return Shape{}, fmt.Errorf("the weights %v have %d columns, expected %d", w, w.Columns, expected)
+	}
+	if len(inputs) > 2 && inputs[2].Size() != w.Rows {
+		return Shape{}, fmt.Errorf("the bias %v doesn't match %d output channels", inputs[2], w.Rows)
+	}
+	outRows, outCols := c.OutputSize()
+	return Shape{Rows: w.Rows, Columns: outRows * outCols}, nil
+}
+
+// Forward computes the output of the function.
+func (r *Conv2D) Forward() mat.Matrix {
+	x, w := r.x.Value(), r.w.Value()
+	c := r.config
+	inChannels, outChannels := x.Rows(), w.Rows()
+	kernelSize := c.KernelRows * c.KernelColumns
+	if x.Columns() != c.Height*c.Width {
+		panic(fmt.Sprintf("fn: conv2d: the input has %d columns, expected %d", x.Columns(), c.Height*c.Width))
+	}
+	if inChannels%c.Groups != 0 || outChannels%c.Groups != 0 {
+		panic("fn: conv2d: the channels are not divisible by the groups")
+	}
+	groupIn, groupOut := inChannels/c.Groups, outChannels/c.Groups
+	if w.Columns() != groupIn*kernelSize {
+		panic(fmt.Sprintf("fn: conv2d: the weights have %d columns, expected %d", w.Columns(), groupIn*kernelSize))
+	}
+	if r.b != nil && r.b.Value().Size() != outChannels {
+		panic("fn: conv2d: the bias size must match the output channels")
+	}
+
+	outRows, outCols := c.OutputSize()
+	outSize := outRows * outCols
+	y := mat.NewEmptyDense(outChannels, outSize)
+	yData := y.Data()
+	xData, wData := x.Data(), w.Data()
+	r.cols = make([]*mat.Dense, c.Groups)
+	for gr := 0; gr < c.Groups; gr++ {
+		cols := c.im2col(xData[gr*groupIn*c.Height*c.Width:], groupIn)
+		r.cols[gr] = cols
+		wg := mat.NewDense(groupOut, groupIn*kernelSize, wData[gr*groupOut*w.Columns():(gr+1)*groupOut*w.Columns()])
+		yg := wg.Mul(cols)
+		copy(yData[gr*groupOut*outSize:], yg.Data())
+		mat.ReleaseDense(wg)
+		mat.ReleaseMatrix(yg)
+	}
+	if r.b != nil {
+		bData := r.b.Value().Data()
+		for i := 0; i < outChannels; i++ {
+			row := yData[i*outSize : (i+1)*outSize]
+			for j := range row {
+				row[j] += bData[i]
+			}
+		}
+	}
+	return y
+}
+
+// Backward computes the backward pass.
+func (r *Conv2D) Backward(gy mat.Matrix) {
+	x, w := r.x.Value(), r.w.Value()
+	c := r.config
+	outChannels := w.Rows()
+	if gy.Rows() != outChannels || gy.Columns() != r.cols[0].Columns() {
+		panic("fn: matrices with not compatible size")
+	}
+	groupIn, groupOut := x.Rows()/c.Groups, outChannels/c.Groups
+	outSize := gy.Columns()
+	gyData := gy.Data()
+
+	var gx, gw mat.Matrix
+	if r.x.RequiresGrad() {
+		gx = x.ZerosLike()
+		defer mat.ReleaseMatrix(gx)
+	}
+	if r.w.RequiresGrad() {
+		gw = w.ZerosLike()
+		defer mat.ReleaseMatrix(gw)
+	}
+	for gr := 0; gr < c.Groups; gr++ {
+		gyg := mat.NewDense(groupOut, outSize, gyData[gr*groupOut*outSize:(gr+1)*groupOut*outSize])
+		if gw != nil {
+			gwg := gyg.MatMulT(r.cols[gr])
+			copy(gw.Data()[gr*groupOut*w.Columns():], gwg.Data())
+			mat.ReleaseMatrix(gwg)
+		}
+		if gx != nil {
+			wg := mat.NewDense(groupOut, w.Columns(), w.Data()[gr*groupOut*w.Columns():(gr+1)*groupOut*w.Columns()])
+			gcols := wg.TMatMul(gyg)
+			c.col2im(gcols, gx.Data()[gr*groupIn*c.Height*c.Width:], groupIn)
+			mat.ReleaseDense(wg)
+			mat.ReleaseMatrix(gcols)
+		}
+		mat.ReleaseDense(gyg)
+	}
+	if gx != nil {
+		r.x.PropagateGrad(gx)
+	}
+	if gw != nil {
+		r.w.PropagateGrad(gw)
+	}
+	if r.b != nil && r.b.RequiresGrad() {
+		gb := r.b.Value().ZerosLike()
+		defer mat.ReleaseMatrix(gb)
+		gbData := gb.Data()
+		for i := 0; i < outChannels; i++ {
+			for _, v := range gyData[i*outSize : (i+1)*outSize] {
+				gbData[i] += v
+			}
+		}
+		r.b.PropagateGrad(gb)
+	}
+}
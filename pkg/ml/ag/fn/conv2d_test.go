@@ -0,0 +1,130 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fn
+
+import (
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConv2D_StridePadding(t *testing.T) {
+	x := &variable{
+		value: mat.NewDense(2, 9, []mat.Float{
+			0.1, 0.2, 0.3, 0.4, 0.5, 0.6, 0.7, 0.8, 0.9,
+			-0.1, -0.2, -0.3, -0.4, -0.5, -0.6, -0.7, -0.8, -0.9,
+		}),
+		requiresGrad: true,
+	}
+	w := &variable{
+		value: mat.NewDense(2, 8, []mat.Float{
+			0.1, -0.2, 0.3, 0.4, 0.5, 0.6, -0.7, 0.8,
+			-0.1, 0.2, 0.3, -0.4, 0.5, -0.6, 0.7, 0.8,
+		}),
+		requiresGrad: true,
+	}
+	b := &variable{
+		value:        mat.NewVecDense([]mat.Float{0.1, -0.2}),
+		requiresGrad: true,
+	}
+	config := Conv2DConfig{
+		Height:         3,
+		Width:          3,
+		KernelRows:     2,
+		KernelColumns:  2,
+		StrideRows:     2,
+		StrideColumns:  2,
+		PaddingRows:    1,
+		PaddingColumns: 1,
+	}
+	f := NewConv2D(x, w, b, config)
+	y := f.Forward()
+
+	assert.Equal(t, 2, y.Rows())
+	assert.Equal(t, 4, y.Columns())
+	assert.InDeltaSlice(t, []mat.Float{
+		0.06, 0.18, -0.5, -0.14,
+		-0.32, -0.64, -0.72, -1.42,
+	}, y.Data(), 1.0e-6)
+
+	f.Backward(mat.NewDense(2, 4, []mat.Float{
+		0.1, 0.2, 0.3, 0.4,
+		-0.5, 0.6, 0.7, -0.8,
+	}))
+
+	assert.InDeltaSlice(t, []mat.Float{
+		0.24, 0.24, -0.16, 0.08, 0.12, -0.24, -0.16, -0.12, 0.48,
+		-0.32, 0.28, 0.64, -0.24, -0.2, 0.72, 0.8, -0.84, -0.32,
+	}, x.grad.Data(), 1.0e-6)
+	assert.InDeltaSlice(t, []mat.Float{
+		0.2, 0.36, 0.36, 0.64, -0.2, -0.36, -0.36, -0.64,
+		-0.4, -0.2, -0.52, -0.1, 0.4, 0.2, 0.52, 0.1,
+	}, w.grad.Data(), 1.0e-6)
+	assert.InDeltaSlice(t, []mat.Float{1.0, 0.0}, b.grad.Data(), 1.0e-6)
+}
+
+func TestConv2D_DilationGroups(t *testing.T) {
+	data := make([]mat.Float, 32)
+	for i := 0; i < 16; i++ {
+		data[i] = mat.Float(i+1) / 10
+		data[16+i] = -mat.Float(i+1) / 10
+	}
+	x := &variable{
+		value:        mat.NewDense(2, 16, data),
+		requiresGrad: true,
+	}
+	w := &variable{
+		value: mat.NewDense(2, 4, []mat.Float{
+			0.1, -0.2, 0.3, 0.4,
+			0.5, 0.6, -0.7, 0.8,
+		}),
+		requiresGrad: true,
+	}
+	config := Conv2DConfig{
+		Height:          4,
+		Width:           4,
+		KernelRows:      2,
+		KernelColumns:   2,
+		DilationRows:    2,
+		DilationColumns: 2,
+		Groups:          2,
+	}
+	rows, cols := config.OutputSize()
+	assert.Equal(t, 2, rows)
+	assert.Equal(t, 2, cols)
+
+	f := NewConv2D(x, w, nil, config)
+	y := f.Forward()
+
+	assert.InDeltaSlice(t, []mat.Float{
+		0.66, 0.72, 0.9, 0.96,
+		-0.48, -0.6, -0.96, -1.08,
+	}, y.Data(), 1.0e-6)
+
+	f.Backward(mat.NewDense(2, 4, []mat.Float{
+		0.1, 0.2, 0.3, 0.4,
+		-0.5, 0.6, 0.7, -0.8,
+	}))
+
+	assert.InDeltaSlice(t, []mat.Float{
+		0.01, 0.02, -0.02, -0.04, 0.03, 0.04, -0.06, -0.08, 0.03, 0.06, 0.04, 0.08, 0.09, 0.12, 0.12, 0.16,
+		-0.25, 0.3, -0.3, 0.36, 0.35, -0.4, 0.42, -0.48, 0.35, -0.42, -0.4, 0.48, -0.49, 0.56, 0.56, -0.64,
+	}, x.grad.Data(), 1.0e-6)
+	assert.InDeltaSlice(t, []mat.Float{
+		0.44, 0.64, 1.24, 1.44,
+		0.06, 0.06, 0.06, 0.06,
+	}, w.grad.Data(), 1.0e-6)
+}
+
+func TestConv2D_InvalidConfig(t *testing.T) {
+	x := &variable{value: mat.NewEmptyDense(2, 9)}
+	w := &variable{value: mat.NewEmptyDense(3, 8)}
+	config := Conv2DConfig{Height: 3, Width: 3, KernelRows: 2, KernelColumns: 2, Groups: 2}
+	assert.Panics(t, func() { NewConv2D(x, w, nil, config).Forward() })
+
+	config = Conv2DConfig{Height: 3, Width: 3, KernelRows: 4, KernelColumns: 2}
+	assert.Panics(t, func() { NewConv2D(x, w, nil, config) })
+}
@@ -0,0 +1,182 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fn
+
+import (
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+)
+
+var (
+	_ Function = &CumSum{}
+	_ Function = &CumProd{}
+)
+
+// Axis identifies the direction along which a function operates on the
+// elements of a matrix.
+type Axis int
+
+const (
+	// AlongColumns operates on each column independently, going down its
+	// rows (e.g. the elements of a column vector).
+	AlongColumns Axis = iota
+	// AlongRows operates on each row independently, going through its columns.
+	AlongRows
+)
+
+// lines returns the indices of the elements of each column (AlongColumns) or
+// row (AlongRows) of a rows × cols matrix.
+func (a Axis) lines(rows, cols int) [][]int {
+	n, length := cols, rows
+	if a == AlongRows {
+		n, length = rows, cols
+	}
+	lines := make([][]int, n)
+	for i := range lines {
+		line := make([]int, length)
+		for k := range line {
+			if a == AlongRows {
+				line[k] = i*cols + k
+			} else {
+				line[k] = k*cols + i
+			}
+		}
+		lines[i] = line
+	}
+	return lines
+}
+
+func (a Axis) validate() {
+	if a != AlongColumns && a != AlongRows {
+		panic("fn: invalid axis")
+	}
+}
+
+// CumSum is a function to compute the cumulative sum of the elements of each
+// column or row of a matrix.
+type CumSum struct {
+	x    Operand
+	axis Axis
+}
+
+// NewCumSum returns a new CumSum Function.
+func NewCumSum(x Operand, axis Axis) *CumSum {
+	axis.validate()
+	return &CumSum{x: x, axis: axis}
+}
+
+// OutputShape returns the shape of the output, given the shape of the operand.
+func (r *CumSum) OutputShape(inputs ...Shape) (Shape, error) {
+	return inputs[0], nil
+}
+
+// Forward computes the output of the function.
+func (r *CumSum) Forward() mat.Matrix {
+	x := r.x.Value()
+	y := x.ZerosLike()
+	xData, yData := x.Data(), y.Data()
+	for _, line := range r.axis.lines(x.Dims()) {
+		var sum mat.Float
+		for _, i := range line {
+			sum += xData[i]
+			yData[i] = sum
+		}
+	}
+	return y
+}
+
+// Backward computes the backward pass.
+func (r *CumSum) Backward(gy mat.Matrix) {
+	x := r.x.Value()
+	if !mat.SameDims(x, gy) {
+		panic("fn: matrices with not compatible size")
+	}
+	if r.x.RequiresGrad() {
+		gx := x.ZerosLike()
+		defer mat.ReleaseMatrix(gx)
+		gxData, gyData := gx.Data(), gy.Data()
+		for _, line := range r.axis.lines(x.Dims()) {
+			var sum mat.Float
+			for k := len(line) - 1; k >= 0; k-- {
+				sum += gyData[line[k]]
+				gxData[line[k]] = sum
+			}
+		}
+		r.x.PropagateGrad(gx)
+	}
+}
+
+// CumProd is a function to compute the cumulative product of the elements of
+// each column or row of a matrix.
+type CumProd struct {
+	x    Operand
+	axis Axis
+	// initialized during the forward pass
+	y mat.Matrix
+}
+
+// NewCumProd returns a new CumProd Function.
+func NewCumProd(x Operand, axis Axis) *CumProd {
+	axis.validate()
+	return &CumProd{x: x, axis: axis}
+}
+
+// OutputShape returns the shape of the output, given the shape of the operand.
+func (r *CumProd) OutputShape(inputs ...Shape) (Shape, error) {
+	return inputs[0], nil
+}
+
+// Forward computes the output of the function.
+func (r *CumProd) Forward() mat.Matrix {
+	x := r.x.Value()
+	y := x.ZerosLike()
+	xData, yData := x.Data(), y.Data()
+	for _, line := range r.axis.lines(x.Dims()) {
+		prod := mat.Float(1)
+		for _, i := range line {
+			prod *= xData[i]
+			yData[i] = prod
+		}
+	}
+	r.y = y
+	return y
+}
+
+// Backward computes the backward pass.
+// The gradient of the k-th element is the sum of gy[i] * y[i] / x[k] for
+// i >= k; when x[k] is zero, the products are computed explicitly.
+func (r *CumProd) Backward(gy mat.Matrix) {
+	x := r.x.Value()
+	if !mat.SameDims(x, gy) {
+		panic("fn: matrices with not compatible size")
+	}
+	if r.x.RequiresGrad() {
+		gx := x.ZerosLike()
+		defer mat.ReleaseMatrix(gx)
+		gxData, gyData := gx.Data(), gy.Data()
+		xData, yData := x.Data(), r.y.Data()
+		for _, line := range r.axis.lines(x.Dims()) {
+			var sum mat.Float // sum of gy[i] * y[i] for i >= k
+			for k := len(line) - 1; k >= 0; k-- {
+				j := line[k]
+				sum += gyData[j] * yData[j]
+				if xData[j] != 0 {
+					gxData[j] = sum / xData[j]
+					continue
+				}
+				prod := mat.Float(1) // product of x[0:i+1] without x[k]
+				if k > 0 {
+					prod = yData[line[k-1]]
+				}
+				for i := k; i < len(line); i++ {
+					if i > k {
+						prod *= xData[line[i]]
+					}
+					gxData[j] += gyData[line[i]] * prod
+				}
+			}
+		}
+		r.x.PropagateGrad(gx)
+	}
+}
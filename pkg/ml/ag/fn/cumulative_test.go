@@ -0,0 +1,62 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fn
+
+import (
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCumSum_AlongRows(t *testing.T) {
+	x := &variable{
+		value:        mat.NewDense(2, 3, []mat.Float{1, 2, 3, 4, 5, 6}),
+		requiresGrad: true,
+	}
+	f := NewCumSum(x, AlongRows)
+	assert.InDeltaSlice(t, []mat.Float{1, 3, 6, 4, 9, 15}, f.Forward().Data(), 1.0e-6)
+
+	f.Backward(mat.NewDense(2, 3, []mat.Float{1, 2, 3, 4, 5, 6}))
+	assert.InDeltaSlice(t, []mat.Float{6, 5, 3, 15, 11, 6}, x.grad.Data(), 1.0e-6)
+}
+
+func TestCumSum_AlongColumns(t *testing.T) {
+	x := &variable{
+		value:        mat.NewDense(2, 3, []mat.Float{1, 2, 3, 4, 5, 6}),
+		requiresGrad: true,
+	}
+	f := NewCumSum(x, AlongColumns)
+	assert.InDeltaSlice(t, []mat.Float{1, 2, 3, 5, 7, 9}, f.Forward().Data(), 1.0e-6)
+
+	f.Backward(mat.NewDense(2, 3, []mat.Float{1, 2, 3, 4, 5, 6}))
+	assert.InDeltaSlice(t, []mat.Float{5, 7, 9, 4, 5, 6}, x.grad.Data(), 1.0e-6)
+
+	assert.Panics(t, func() { NewCumSum(x, Axis(2)) })
+}
+
+func TestCumProd_Forward(t *testing.T) {
+	x := &variable{
+		value:        mat.NewVecDense([]mat.Float{2, 3, 4}),
+		requiresGrad: true,
+	}
+	f := NewCumProd(x, AlongColumns)
+	assert.InDeltaSlice(t, []mat.Float{2, 6, 24}, f.Forward().Data(), 1.0e-6)
+
+	f.Backward(mat.NewVecDense([]mat.Float{1, 2, 3}))
+	assert.InDeltaSlice(t, []mat.Float{43, 28, 18}, x.grad.Data(), 1.0e-6)
+}
+
+func TestCumProd_Zeros(t *testing.T) {
+	x := &variable{
+		value:        mat.NewDense(1, 4, []mat.Float{2, 0, 3, 4}),
+		requiresGrad: true,
+	}
+	f := NewCumProd(x, AlongRows)
+	assert.InDeltaSlice(t, []mat.Float{2, 0, 0, 0}, f.Forward().Data(), 1.0e-6)
+
+	f.Backward(mat.NewDense(1, 4, []mat.Float{1, 1, 1, 1}))
+	assert.InDeltaSlice(t, []mat.Float{1, 32, 0, 0}, x.grad.Data(), 1.0e-6)
+}
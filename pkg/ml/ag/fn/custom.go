@@ -0,0 +1,160 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fn
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+)
+
+var _ Function = &Custom{}
+
+// Variadic is the arity of the custom operators accepting any number of
+// operands (at least one).
+const Variadic = -1
+
+// ForwardFunc computes the value of a custom operator from the values of its
+// operands. It must not modify the operands.
+type ForwardFunc func(xs []mat.Matrix) mat.Matrix
+
+// BackwardFunc computes the gradients of the operands of a custom operator,
+// given their values, the output y and its gradient gy. It must return a
+// gradient for each operand, with the same shape of its value; the nil
+// gradients are not propagated.
+type BackwardFunc func(xs []mat.Matrix, y, gy mat.Matrix) []mat.Matrix
+
+// CustomOp is a differentiable operator defined outside of this package.
+type CustomOp struct {
+	// Name is the name the operator is registered with.
+	Name string
+	// Arity is the number of operands, or Variadic.
+	Arity int
+	// Forward computes the output.
+	Forward ForwardFunc
+	// Backward computes the gradients of the operands.
+	Backward BackwardFunc
+}
+
+var customOps = struct {
+	sync.RWMutex
+	m map[string]*CustomOp
+}{m: make(map[string]*CustomOp)}
+
+// Register makes a custom operator available by name, so that it can be
+// used in a graph (see ag.Graph.Custom) like the built-in functions.
+// It panics if the name is empty or already registered, if the arity is
+// invalid, or if forward or backward is nil.
+func Register(name string, arity int, forward ForwardFunc, backward BackwardFunc) {
+	if name == "" {
+		panic("fn: custom operator with empty name")
+	}
+	if arity < 1 && arity != Variadic {
+		panic(fmt.Sprintf("fn: custom operator %#v with invalid arity %d", name, arity))
+	}
+	if forward == nil || backward == nil {
+		panic(fmt.Sprintf("fn: custom operator %#v without forward or backward", name))
+	}
+	customOps.Lock()
+	defer customOps.Unlock()
+	if _, exists := customOps.m[name]; exists {
+		panic(fmt.Sprintf("fn: custom operator %#v already registered", name))
+	}
+	customOps.m[name] = &CustomOp{Name: name, Arity: arity, Forward: forward, Backward: backward}
+}
+
+// LookupCustom returns the custom operator registered with the given name.
+func LookupCustom(name string) (*CustomOp, bool) {
+	customOps.RLock()
+	defer customOps.RUnlock()
+	op, ok := customOps.m[name]
+	return op, ok
+}
+
+// CustomOps returns the sorted names of the registered custom operators.
+func CustomOps() []string {
+	customOps.RLock()
+	defer customOps.RUnlock()
+	names := make([]string, 0, len(customOps.m))
+	for name := range customOps.m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Custom is a Function computing a registered CustomOp.
+// It verifies the number of the operands, and the shape of the gradients
+// returned by the backward of the operator.
+//
+// Since the shape of the output of a CustomOp is only known from its value,
+// Custom doesn't implement ShapeInferrer: when the shape checking is enabled
+// (see ag.WithShapeChecking), its operands are not validated, and neither
+// are the operators depending on it until its value is computed.
+type Custom struct {
+	op *CustomOp
+	xs []Operand
+	y  mat.Matrix
+}
+
+// NewCustom returns a new Custom Function computing the operator registered
+// with the given name. It panics if the operator is not registered, or if the
+// number of operands doesn't match its arity.
+func NewCustom(name string, xs []Operand) *Custom {
+	op, ok := LookupCustom(name)
+	if !ok {
+		panic(fmt.Sprintf("fn: custom operator %#v not registered", name))
+	}
+	if (op.Arity == Variadic && len(xs) == 0) || (op.Arity != Variadic && len(xs) != op.Arity) {
+		panic(fmt.Sprintf("fn: custom operator %#v expects %d operands, found %d", name, op.Arity, len(xs)))
+	}
+	return &Custom{op: op, xs: xs}
+}
+
+// Name returns the name of the custom operator.
+func (r *Custom) Name() string {
+	return r.op.Name
+}
+
+// Forward computes the output of the function.
+func (r *Custom) Forward() mat.Matrix {
+	y := r.op.Forward(r.values())
+	if y == nil {
+		panic(fmt.Sprintf("fn: custom operator %#v returned a nil value", r.op.Name))
+	}
+	r.y = y
+	return y
+}
+
+// Backward computes the backward pass.
+func (r *Custom) Backward(gy mat.Matrix) {
+	if !(mat.SameDims(r.y, gy) || mat.VectorsOfSameSize(r.y, gy)) {
+		panic("fn: matrices with not compatible size")
+	}
+	xs := r.values()
+	gxs := r.op.Backward(xs, r.y, gy)
+	if len(gxs) != len(r.xs) {
+		panic(fmt.Sprintf("fn: custom operator %#v returned %d gradients for %d operands", r.op.Name, len(gxs), len(r.xs)))
+	}
+	for i, x := range r.xs {
+		if gxs[i] == nil || !x.RequiresGrad() {
+			continue
+		}
+		if !(mat.SameDims(xs[i], gxs[i]) || mat.VectorsOfSameSize(xs[i], gxs[i])) {
+			panic(fmt.Sprintf("fn: custom operator %#v returned a gradient of wrong size for the operand %d", r.op.Name, i))
+		}
+		x.PropagateGrad(gxs[i])
+	}
+}
+
+func (r *Custom) values() []mat.Matrix {
+	xs := make([]mat.Matrix, len(r.xs))
+	for i, x := range r.xs {
+		xs[i] = x.Value()
+	}
+	return xs
+}
@@ -0,0 +1,83 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fn
+
+import (
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	// y = x1 ⊙ x2 + x1
+	Register("test.mulAdd", 2,
+		func(xs []mat.Matrix) mat.Matrix {
+			return xs[0].Prod(xs[1]).Add(xs[0])
+		},
+		func(xs []mat.Matrix, y, gy mat.Matrix) []mat.Matrix {
+			return []mat.Matrix{
+				gy.Prod(xs[1].AddScalar(1)),
+				gy.Prod(xs[0]),
+			}
+		},
+	)
+	Register("test.badGrad", Variadic,
+		func(xs []mat.Matrix) mat.Matrix {
+			return xs[0].Clone()
+		},
+		func(xs []mat.Matrix, y, gy mat.Matrix) []mat.Matrix {
+			return []mat.Matrix{mat.NewScalar(1)}
+		},
+	)
+}
+
+func TestCustom(t *testing.T) {
+	x1 := &variable{
+		value:        mat.NewVecDense([]mat.Float{1, 2, 3}),
+		requiresGrad: true,
+	}
+	x2 := &variable{
+		value:        mat.NewVecDense([]mat.Float{4, 5, 6}),
+		requiresGrad: false,
+	}
+	f := NewCustom("test.mulAdd", []Operand{x1, x2})
+	assert.Equal(t, "test.mulAdd", f.Name())
+	y := f.Forward()
+	assert.InDeltaSlice(t, []mat.Float{5, 12, 21}, y.Data(), 1.0e-6)
+
+	f.Backward(mat.NewVecDense([]mat.Float{1, -1, 0.5}))
+	assert.InDeltaSlice(t, []mat.Float{5, -6, 3.5}, x1.grad.Data(), 1.0e-6)
+	assert.Nil(t, x2.grad)
+}
+
+func TestCustom_Checks(t *testing.T) {
+	x := &variable{
+		value:        mat.NewVecDense([]mat.Float{1, 2, 3}),
+		requiresGrad: true,
+	}
+	assert.Panics(t, func() { NewCustom("test.unknown", []Operand{x}) })
+	assert.Panics(t, func() { NewCustom("test.mulAdd", []Operand{x}) })
+	assert.Panics(t, func() { NewCustom("test.badGrad", nil) })
+
+	f := NewCustom("test.badGrad", []Operand{x})
+	f.Forward()
+	assert.Panics(t, func() { f.Backward(mat.NewVecDense([]mat.Float{1, 1, 1})) })
+}
+
+func TestRegister(t *testing.T) {
+	forward := func(xs []mat.Matrix) mat.Matrix { return xs[0] }
+	backward := func(xs []mat.Matrix, y, gy mat.Matrix) []mat.Matrix { return []mat.Matrix{gy} }
+	assert.Panics(t, func() { Register("", 1, forward, backward) })
+	assert.Panics(t, func() { Register("test.invalid", 0, forward, backward) })
+	assert.Panics(t, func() { Register("test.invalid", 1, nil, backward) })
+	assert.Panics(t, func() { Register("test.mulAdd", 2, forward, backward) })
+
+	op, ok := LookupCustom("test.mulAdd")
+	assert.True(t, ok)
+	assert.Equal(t, 2, op.Arity)
+	assert.Contains(t, CustomOps(), "test.mulAdd")
+	assert.NotContains(t, CustomOps(), "test.invalid")
+}
@@ -21,6 +21,11 @@ func NewDiv(x1, x2 Operand) *Div {
 	return &Div{x1: x1, x2: x2}
 }
 
+// OutputShape returns the shape of the output, given the shapes of the operands.
+func (r *Div) OutputShape(inputs ...Shape) (Shape, error) {
+	return elementwiseShape(inputs)
+}
+
 // Forward computes the output of the function.
 func (r *Div) Forward() mat.Matrix {
 	x1v := r.x1.Value()
@@ -21,6 +21,11 @@ func NewDivScalar(x1, x2 Operand) *DivScalar {
 	return &DivScalar{x1: x1, x2: x2}
 }
 
+// OutputShape returns the shape of the output, given the shapes of the operands.
+func (r *DivScalar) OutputShape(inputs ...Shape) (Shape, error) {
+	return scalarShape(inputs)
+}
+
 // Forward computes the output of the function.
 func (r *DivScalar) Forward() mat.Matrix {
 	return r.x1.Value().ProdScalar(1.0 / r.x2.Value().Scalar())
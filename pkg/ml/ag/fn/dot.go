@@ -20,6 +20,14 @@ func NewDot(x1, x2 Operand) *Dot {
 	return &Dot{x1: x1, x2: x2}
 }
 
+// OutputShape returns the shape of the output, given the shapes of the operands.
+func (r *Dot) OutputShape(inputs ...Shape) (Shape, error) {
+	if _, err := elementwiseShape(inputs); err != nil {
+		return Shape{}, err
+	}
+	return Shape{Rows: 1, Columns: 1}, nil
+}
+
 // Forward computes the output of the function.
 func (r *Dot) Forward() mat.Matrix {
 	x1v := r.x1.Value()
@@ -32,6 +32,11 @@ func NewDropout(x Operand, p mat.Float, randGen *rand.LockedRand) *Dropout {
 	}
 }
 
+// OutputShape returns the shape of the output, given the shape of the operand.
+func (r *Dropout) OutputShape(inputs ...Shape) (Shape, error) {
+	return inputs[0], nil
+}
+
 // Forward computes the output of the function.
 func (r *Dropout) Forward() mat.Matrix {
 	if r.q > 0.0 {
@@ -0,0 +1,353 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fn
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+)
+
+var _ Function = &Einsum{}
+
+// Einsum is an operator to perform the Einstein summation of the operands,
+// according to the subscripts of an equation in the einsum notation
+// (e.g. "ij,jk->ik", "ij,kj->ik", "i,i->", "ij->ji").
+//
+// Each subscript is a lowercase letter; an operand with zero subscripts is a
+// scalar, and with one subscript a vector. The subscripts missing from the
+// output are summed over. When the output ("->...") is omitted, it is made of
+// the subscripts occurring exactly once, in alphabetical order.
+//
+// Since matrices are 2-dimensional, an operand with two or more subscripts
+// is a matrix whose columns are indexed by the last subscript, and whose rows
+// by all the leading ones, in row-major order. For example, the operand "bij"
+// of the batched equation "bij,bjk->bik" is the matrix of (b·i) rows and j
+// columns stacking the b matrices of the batch, and so is the output "bik".
+// The sizes of the leading subscripts are inferred from the other operands
+// where possible; otherwise, they must be given with NewEinsumWithSizes.
+type Einsum struct {
+	xs     []Operand
+	inputs []string     // the subscripts of each operand
+	output string       // the subscripts of the output
+	sizes  map[byte]int // the sizes of the subscripts given explicitly
+	dims   map[byte]int // the size of each subscript, assigned during the Forward()
+	yRows  int
+	yCols  int
+}
+
+// NewEinsum returns a new Einsum Function.
+// It panics if the equation is malformed, or if it doesn't match the number of operands.
+func NewEinsum(equation string, xs ...Operand) *Einsum {
+	return NewEinsumWithSizes(equation, nil, xs...)
+}
+
+// NewEinsumWithSizes returns a new Einsum Function, with the sizes of some
+// subscripts given explicitly, e.g. map[byte]int{'b': 4} for the batch size
+// of "bij->bji", which can't be inferred from the shape of the operand.
+// It panics if the equation is malformed, or if it doesn't match the number of operands.
+func NewEinsumWithSizes(equation string, sizes map[byte]int, xs ...Operand) *Einsum {
+	inputs, output := parseEinsumEquation(equation)
+	if len(inputs) != len(xs) {
+		panic(fmt.Sprintf("fn: einsum equation %#v expects %d operands, found %d", equation, len(inputs), len(xs)))
+	}
+	return &Einsum{
+		xs:     xs,
+		inputs: inputs,
+		output: output,
+		sizes:  sizes,
+	}
+}
+
+func parseEinsumEquation(equation string) (inputs []string, output string) {
+	equation = strings.ReplaceAll(equation, " ", "")
+	explicit := strings.Contains(equation, "->")
+	lhs := equation
+	if explicit {
+		parts := strings.Split(equation, "->")
+		if len(parts) != 2 {
+			panic(fmt.Sprintf("fn: malformed einsum equation %#v", equation))
+		}
+		lhs, output = parts[0], parts[1]
+	}
+	inputs = strings.Split(lhs, ",")
+
+	counts := make(map[byte]int)
+	for _, in := range inputs {
+		validateEinsumSubscripts(equation, in)
+		for i := 0; i < len(in); i++ {
+			counts[in[i]]++
+		}
+	}
+	if !explicit {
+		var out []byte
+		for c, n := range counts {
+			if n == 1 {
+				out = append(out, c)
+			}
+		}
+		sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+		return inputs, string(out)
+	}
+	validateEinsumSubscripts(equation, output)
+	for i := 0; i < len(output); i++ {
+		if counts[output[i]] == 0 {
+			panic(fmt.Sprintf("fn: einsum output subscript %#v not found in the operands", string(output[i])))
+		}
+		if strings.IndexByte(output, output[i]) != i {
+			panic(fmt.Sprintf("fn: einsum output subscript %#v is repeated", string(output[i])))
+		}
+	}
+	return inputs, output
+}
+
+func validateEinsumSubscripts(equation, subscripts string) {
+	for i := 0; i < len(subscripts); i++ {
+		if c := subscripts[i]; c < 'a' || c > 'z' {
+			panic(fmt.Sprintf("fn: einsum equation %#v: invalid subscript %#v", equation, string(c)))
+		}
+	}
+}
+
+// OutputShape returns the shape of the output, given the shapes of the operands.
+func (r *Einsum) OutputShape(inputs ...Shape) (Shape, error) {
+	dims, err := r.inferDims(inputs)
+	if err != nil {
+		return Shape{}, err
+	}
+	return einsumShape(r.output, dims), nil
+}
+
+// Forward computes the output of the function.
+func (r *Einsum) Forward() mat.Matrix {
+	values := make([]mat.Matrix, len(r.xs))
+	shapes := make([]Shape, len(r.xs))
+	for i, x := range r.xs {
+		values[i] = x.Value()
+		shapes[i] = Shape{Rows: values[i].Rows(), Columns: values[i].Columns()}
+	}
+	dims, err := r.inferDims(shapes)
+	if err != nil {
+		panic(fmt.Sprintf("fn: einsum %v", err))
+	}
+	r.dims = dims
+	shape := einsumShape(r.output, dims)
+	r.yRows, r.yCols = shape.Rows, shape.Columns
+	y := mat.NewEmptyDense(r.yRows, r.yCols)
+	einsum(y, r.output, r.inputs, values, r.dims)
+	return y
+}
+
+// inferDims returns the size of each subscript, given the shapes of the
+// operands. The last subscript of a matrix is bound to its columns; its
+// leading subscripts are bound one at a time, whenever all the others are
+// known, so that their product matches the rows.
+func (r *Einsum) inferDims(shapes []Shape) (map[byte]int, error) {
+	dims := make(map[byte]int, len(r.sizes))
+	for c, size := range r.sizes {
+		dims[c] = size
+	}
+	bind := func(c byte, size int) error {
+		if d, ok := dims[c]; ok && d != size {
+			return fmt.Errorf("subscript %#v has incompatible sizes %d and %d", string(c), d, size)
+		}
+		dims[c] = size
+		return nil
+	}
+	for i, x := range shapes {
+		subscripts := r.inputs[i]
+		var err error
+		switch len(subscripts) {
+		case 0:
+			if x.Size() != 1 {
+				return nil, fmt.Errorf("expected scalar operand, found %v", x)
+			}
+		case 1:
+			if !x.IsVector() {
+				return nil, fmt.Errorf("expected vector operand, found %v", x)
+			}
+			err = bind(subscripts[0], x.Size())
+		default:
+			err = bind(subscripts[len(subscripts)-1], x.Columns)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	for bound := true; bound; {
+		bound = false
+		for i, x := range shapes {
+			subscripts := r.inputs[i]
+			if len(subscripts) < 2 {
+				continue
+			}
+			known, unknown, count := 1, byte(0), 0
+			for _, c := range []byte(subscripts[:len(subscripts)-1]) {
+				if d, ok := dims[c]; ok {
+					known *= d
+				} else if count == 0 || c != unknown {
+					unknown, count = c, count+1
+				} else {
+					count = 2 // a repeated unknown subscript can't be bound
+				}
+			}
+			if count == 1 && known > 0 && x.Rows%known == 0 {
+				if err := bind(unknown, x.Rows/known); err != nil {
+					return nil, err
+				}
+				bound = true
+			}
+		}
+	}
+	for i, x := range shapes {
+		subscripts := r.inputs[i]
+		if len(subscripts) < 2 {
+			continue
+		}
+		for _, c := range []byte(subscripts) {
+			if _, ok := dims[c]; !ok {
+				return nil, fmt.Errorf("can't infer the size of subscript %#v", string(c))
+			}
+		}
+		if rows := leadingSize(subscripts, dims); rows != x.Rows {
+			return nil, fmt.Errorf("operand %#v expects %d rows, found %v", subscripts, rows, x)
+		}
+	}
+	return dims, nil
+}
+
+// einsumShape returns the shape of the matrix with the given subscripts.
+func einsumShape(subscripts string, dims map[byte]int) Shape {
+	switch len(subscripts) {
+	case 0:
+		return Shape{Rows: 1, Columns: 1}
+	case 1:
+		return Shape{Rows: dims[subscripts[0]], Columns: 1}
+	default:
+		return Shape{Rows: leadingSize(subscripts, dims), Columns: dims[subscripts[len(subscripts)-1]]}
+	}
+}
+
+// leadingSize returns the product of the sizes of all the subscripts but the
+// last one, i.e. the rows of a matrix with those subscripts.
+func leadingSize(subscripts string, dims map[byte]int) int {
+	size := 1
+	for i := 0; i < len(subscripts)-1; i++ {
+		size *= dims[subscripts[i]]
+	}
+	return size
+}
+
+// Backward computes the backward pass.
+// The gradients of each operand are the Einstein summation of the output
+// gradients with all the other operands.
+func (r *Einsum) Backward(gy mat.Matrix) {
+	if gy.Rows() != r.yRows || gy.Columns() != r.yCols {
+		panic("fn: matrices with not compatible size")
+	}
+	for i, x := range r.xs {
+		if !x.RequiresGrad() {
+			continue
+		}
+		inputs := make([]string, 0, len(r.xs))
+		values := make([]mat.Matrix, 0, len(r.xs))
+		inputs = append(inputs, r.output)
+		values = append(values, gy)
+		for j, other := range r.xs {
+			if j != i {
+				inputs = append(inputs, r.inputs[j])
+				values = append(values, other.Value())
+			}
+		}
+		gx := x.Value().ZerosLike()
+		einsum(gx.(*mat.Dense), r.inputs[i], inputs, values, r.dims)
+		x.PropagateGrad(gx)
+		mat.ReleaseMatrix(gx)
+	}
+}
+
+// einsum accumulates into y (whose subscripts are given by output) the
+// Einstein summation of the values.
+func einsum(y *mat.Dense, output string, inputs []string, values []mat.Matrix, dims map[byte]int) {
+	// collect all the subscripts, in order of first appearance
+	var subscripts []byte
+	position := make(map[byte]int)
+	for _, s := range append([]string{output}, inputs...) {
+		for i := 0; i < len(s); i++ {
+			if _, ok := position[s[i]]; !ok {
+				position[s[i]] = len(subscripts)
+				subscripts = append(subscripts, s[i])
+			}
+		}
+	}
+	if len(subscripts) == 0 {
+		prod := mat.Float(1)
+		for _, v := range values {
+			prod *= v.Scalar()
+		}
+		y.Data()[0] += prod
+		return
+	}
+
+	// strides[k][p] is the stride of the subscript at position p in the k-th matrix (0 = output)
+	strides := make([][]int, len(values)+1)
+	data := make([][]mat.Float, len(values)+1)
+	strides[0], data[0] = einsumStrides(output, dims, position), y.Data()
+	for k, v := range values {
+		strides[k+1], data[k+1] = einsumStrides(inputs[k], dims, position), v.Data()
+	}
+
+	sizes := make([]int, len(subscripts))
+	for p, c := range subscripts {
+		sizes[p] = dims[c]
+		if sizes[p] == 0 {
+			return
+		}
+	}
+	index := make([]int, len(subscripts))
+	offsets := make([]int, len(data))
+	yData := data[0]
+	for {
+		prod := mat.Float(1)
+		for k := 1; k < len(data); k++ {
+			prod *= data[k][offsets[k]]
+		}
+		yData[offsets[0]] += prod
+
+		// advance the odometer
+		p := len(index) - 1
+		for ; p >= 0; p-- {
+			index[p]++
+			for k := range offsets {
+				offsets[k] += strides[k][p]
+			}
+			if index[p] < sizes[p] {
+				break
+			}
+			for k := range offsets {
+				offsets[k] -= strides[k][p] * sizes[p]
+			}
+			index[p] = 0
+		}
+		if p < 0 {
+			return
+		}
+	}
+}
+
+// einsumStrides returns the strides of the matrix, in respect of linear
+// indexing, for each subscript position. The subscripts are laid out in
+// row-major order, so the last one has stride 1.
+func einsumStrides(subscripts string, dims map[byte]int, position map[byte]int) []int {
+	strides := make([]int, len(position))
+	stride := 1
+	for i := len(subscripts) - 1; i >= 0; i-- {
+		strides[position[subscripts[i]]] += stride
+		stride *= dims[subscripts[i]]
+	}
+	return strides
+}
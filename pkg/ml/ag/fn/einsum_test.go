@@ -0,0 +1,146 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fn
+
+import (
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestEinsum_MatMul(t *testing.T) {
+	a := &variable{
+		value:        mat.NewDense(2, 3, []mat.Float{1, 2, 3, 4, 5, 6}),
+		requiresGrad: true,
+	}
+	b := &variable{
+		value:        mat.NewDense(3, 2, []mat.Float{1, 2, 3, 4, 5, 6}),
+		requiresGrad: true,
+	}
+
+	for _, eq := range []string{"ij,jk->ik", "ij,jk"} {
+		t.Run(eq, func(t *testing.T) {
+			f := NewEinsum(eq, a, b)
+			y := f.Forward()
+			assert.Equal(t, 2, y.Rows())
+			assert.Equal(t, 2, y.Columns())
+			assert.InDeltaSlice(t, []mat.Float{22, 28, 49, 64}, y.Data(), 1.0e-6)
+
+			f.Backward(mat.NewInitDense(2, 2, 1))
+			assert.InDeltaSlice(t, []mat.Float{3, 7, 11, 3, 7, 11}, a.grad.Data(), 1.0e-6)
+			assert.InDeltaSlice(t, []mat.Float{5, 5, 7, 7, 9, 9}, b.grad.Data(), 1.0e-6)
+		})
+	}
+}
+
+func TestEinsum_MulT(t *testing.T) {
+	a := &variable{
+		value:        mat.NewDense(2, 3, []mat.Float{1, 2, 3, 4, 5, 6}),
+		requiresGrad: true,
+	}
+	b := &variable{
+		value:        mat.NewDense(2, 3, []mat.Float{1, 0, 1, 0, 1, 0}),
+		requiresGrad: false,
+	}
+	f := NewEinsum("ij,kj->ik", a, b)
+	y := f.Forward()
+	assert.InDeltaSlice(t, []mat.Float{4, 2, 10, 5}, y.Data(), 1.0e-6)
+
+	f.Backward(mat.NewDense(2, 2, []mat.Float{1, 0, 0, 2}))
+	assert.InDeltaSlice(t, []mat.Float{1, 0, 1, 0, 2, 0}, a.grad.Data(), 1.0e-6)
+	assert.Nil(t, b.grad)
+}
+
+func TestEinsum_Unary(t *testing.T) {
+	x := &variable{
+		value:        mat.NewDense(2, 3, []mat.Float{1, 2, 3, 4, 5, 6}),
+		requiresGrad: true,
+	}
+
+	t.Run("transpose", func(t *testing.T) {
+		y := NewEinsum("ij->ji", x).Forward()
+		assert.Equal(t, 3, y.Rows())
+		assert.InDeltaSlice(t, []mat.Float{1, 4, 2, 5, 3, 6}, y.Data(), 1.0e-6)
+	})
+
+	t.Run("row sum", func(t *testing.T) {
+		f := NewEinsum("ij->i", x)
+		y := f.Forward()
+		assert.InDeltaSlice(t, []mat.Float{6, 15}, y.Data(), 1.0e-6)
+		f.Backward(mat.NewVecDense([]mat.Float{1, 2}))
+		assert.InDeltaSlice(t, []mat.Float{1, 1, 1, 2, 2, 2}, x.grad.Data(), 1.0e-6)
+	})
+
+	t.Run("trace", func(t *testing.T) {
+		sq := &variable{value: mat.NewDense(2, 2, []mat.Float{1, 2, 3, 4}), requiresGrad: true}
+		f := NewEinsum("ii->", sq)
+		y := f.Forward()
+		assert.InDelta(t, 5, y.Scalar(), 1.0e-6)
+		f.Backward(mat.NewScalar(2))
+		assert.InDeltaSlice(t, []mat.Float{2, 0, 0, 2}, sq.grad.Data(), 1.0e-6)
+	})
+}
+
+func TestEinsum_Dot(t *testing.T) {
+	x1 := &variable{value: mat.NewVecDense([]mat.Float{1, 2, 3}), requiresGrad: true}
+	x2 := &variable{value: mat.NewVecDense([]mat.Float{4, 5, 6}), requiresGrad: true}
+	f := NewEinsum("i,i->", x1, x2)
+	y := f.Forward()
+	assert.InDelta(t, 32, y.Scalar(), 1.0e-6)
+
+	f.Backward(mat.NewScalar(0.5))
+	assert.InDeltaSlice(t, []mat.Float{2, 2.5, 3}, x1.grad.Data(), 1.0e-6)
+	assert.InDeltaSlice(t, []mat.Float{0.5, 1, 1.5}, x2.grad.Data(), 1.0e-6)
+}
+
+func TestEinsum_BatchedMatMul(t *testing.T) {
+	// two batches of 2×3 and 3×2 matrices, stacked by rows
+	a := &variable{
+		value: mat.NewDense(4, 3, []mat.Float{
+			1, 2, 3, 4, 5, 6,
+			1, 0, 0, 0, 1, 0,
+		}),
+		requiresGrad: true,
+	}
+	b := &variable{
+		value: mat.NewDense(6, 2, []mat.Float{
+			1, 2, 3, 4, 5, 6,
+			1, 2, 3, 4, 5, 6,
+		}),
+		requiresGrad: true,
+	}
+	f := NewEinsum("bij,bjk->bik", a, b)
+	y := f.Forward()
+	assert.Equal(t, 4, y.Rows())
+	assert.Equal(t, 2, y.Columns())
+	assert.InDeltaSlice(t, []mat.Float{22, 28, 49, 64, 1, 2, 3, 4}, y.Data(), 1.0e-6)
+
+	f.Backward(mat.NewInitDense(4, 2, 1))
+	assert.InDeltaSlice(t, []mat.Float{3, 7, 11, 3, 7, 11, 3, 7, 11, 3, 7, 11}, a.grad.Data(), 1.0e-6)
+	assert.InDeltaSlice(t, []mat.Float{5, 5, 7, 7, 9, 9, 1, 1, 1, 1, 0, 0}, b.grad.Data(), 1.0e-6)
+}
+
+func TestEinsumWithSizes(t *testing.T) {
+	// a batch of two 2×3 matrices, whose batch size can't be inferred
+	x := &variable{value: mat.NewDense(4, 3, []mat.Float{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12})}
+	assert.Panics(t, func() { NewEinsum("bij->bji", x).Forward() })
+
+	y := NewEinsumWithSizes("bij->bji", map[byte]int{'b': 2}, x).Forward()
+	assert.Equal(t, 6, y.Rows())
+	assert.Equal(t, 2, y.Columns())
+	assert.InDeltaSlice(t, []mat.Float{1, 4, 2, 5, 3, 6, 7, 10, 8, 11, 9, 12}, y.Data(), 1.0e-6)
+
+	assert.Panics(t, func() { NewEinsumWithSizes("bij->bji", map[byte]int{'b': 3}, x).Forward() })
+}
+
+func TestEinsum_Panics(t *testing.T) {
+	x := &variable{value: mat.NewDense(2, 3, []mat.Float{1, 2, 3, 4, 5, 6})}
+	assert.Panics(t, func() { NewEinsum("ij,jk->ik", x) })
+	assert.Panics(t, func() { NewEinsum("ijk->ik", x).Forward() })
+	assert.Panics(t, func() { NewEinsum("ij->ii", x) })
+	assert.Panics(t, func() { NewEinsum("ij->k", x) })
+	assert.Panics(t, func() { NewEinsum("iJ->i", x) })
+	assert.Panics(t, func() { NewEinsum("ii->i", x).Forward() })
+}
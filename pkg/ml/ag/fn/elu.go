@@ -22,6 +22,11 @@ func NewELU(x, alpha Operand) *ELU {
 	return &ELU{x: x, alpha: alpha}
 }
 
+// OutputShape returns the shape of the output, given the shapes of the operands.
+func (r *ELU) OutputShape(inputs ...Shape) (Shape, error) {
+	return scalarShape(inputs)
+}
+
 // Forward computes the output of the function.
 func (r *ELU) Forward() mat.Matrix {
 	y := mat.GetDenseWorkspace(r.x.Value().Dims())
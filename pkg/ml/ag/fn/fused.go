@@ -0,0 +1,289 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fn
+
+import (
+	"fmt"
+
+	"sync"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+)
+
+var (
+	_ Function = &AffineActivation{}
+	_ Function = &BiasActivation{}
+	_ Function = &LayerNorm{}
+	_ Function = &SoftmaxCrossEntropy{}
+)
+
+// AffineActivation is a fused operator computing f(b + w·x), where f is the
+// function of an UnaryElementwise operator (e.g. Tanh or GELU).
+// It is equivalent to the chain of Mul, Add and the activation, but it
+// allocates only the output and the pre-activation, which is kept for the
+// backward.
+type AffineActivation struct {
+	b  Operand // bias (it may have a nil value)
+	w  Operand // matrix
+	x  Operand // vector
+	f  func(i, j int, v mat.Float) mat.Float
+	df func(i, j int, v mat.Float) mat.Float
+	z  mat.Matrix // the pre-activation b + w·x
+}
+
+// NewAffineActivation returns a new AffineActivation Function, applying the
+// function of the given activation.
+func NewAffineActivation(b, w, x Operand, activation *UnaryElementwise) *AffineActivation {
+	return &AffineActivation{b: b, w: w, x: x, f: activation.f, df: activation.df}
+}
+
+// OutputShape returns the shape of the output, given the shapes of the operands.
+func (r *AffineActivation) OutputShape(inputs ...Shape) (Shape, error) {
+	b, w, x := inputs[0], inputs[1], inputs[2]
+	if w.Columns != x.Rows {
+		return Shape{}, fmt.Errorf("%v · %v mismatch", w, x)
+	}
+	return elementwiseShape([]Shape{{Rows: w.Rows, Columns: x.Columns}, b})
+}
+
+// Forward computes the output of the function.
+func (r *AffineActivation) Forward() mat.Matrix {
+	if r.w.Value().Columns() != r.x.Value().Rows() {
+		panic("fn: matrices with not compatible size")
+	}
+	z := r.w.Value().Mul(r.x.Value())
+	if bv := r.b.Value(); bv != nil {
+		if !(mat.SameDims(z, bv) || mat.VectorsOfSameSize(z, bv)) {
+			panic("fn: matrices with not compatible size")
+		}
+		z.AddInPlace(bv)
+	}
+	r.z = z
+	y := mat.GetDenseWorkspace(z.Dims())
+	y.Apply(r.f, z)
+	return y
+}
+
+// Backward computes the backward pass.
+func (r *AffineActivation) Backward(gy mat.Matrix) {
+	if !(mat.SameDims(r.z, gy) || mat.VectorsOfSameSize(r.z, gy)) {
+		panic("fn: matrices with not compatible size")
+	}
+	gz := mat.GetDenseWorkspace(r.z.Dims())
+	defer mat.ReleaseDense(gz)
+	gz.Apply(r.df, r.z)
+	gz.ProdInPlace(gy)
+
+	if r.b.RequiresGrad() {
+		r.b.PropagateGrad(gz)
+	}
+	var wg sync.WaitGroup
+	// the gradients of an operand used twice are accumulated in a fixed order
+	serial := r.w == r.x
+	if r.w.RequiresGrad() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			gw := gz.MatMulT(r.x.Value())
+			defer mat.ReleaseMatrix(gw)
+			r.w.PropagateGrad(gw)
+		}()
+		if serial {
+			wg.Wait()
+		}
+	}
+	if r.x.RequiresGrad() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			gx := r.w.Value().TMatMul(gz)
+			defer mat.ReleaseMatrix(gx)
+			r.x.PropagateGrad(gx)
+		}()
+	}
+	wg.Wait()
+}
+
+// BiasActivation is a fused operator computing f(x + b), where f is the
+// function of an UnaryElementwise operator (e.g. the bias+GELU of the
+// Transformers' feed-forward layers).
+type BiasActivation struct {
+	x  Operand
+	b  Operand
+	f  func(i, j int, v mat.Float) mat.Float
+	df func(i, j int, v mat.Float) mat.Float
+	z  mat.Matrix // the pre-activation x + b
+}
+
+// NewBiasActivation returns a new BiasActivation Function, applying the
+// function of the given activation.
+func NewBiasActivation(x, b Operand, activation *UnaryElementwise) *BiasActivation {
+	return &BiasActivation{x: x, b: b, f: activation.f, df: activation.df}
+}
+
+// OutputShape returns the shape of the output, given the shapes of the operands.
+func (r *BiasActivation) OutputShape(inputs ...Shape) (Shape, error) {
+	return elementwiseShape(inputs)
+}
+
+// Forward computes the output of the function.
+func (r *BiasActivation) Forward() mat.Matrix {
+	xv, bv := r.x.Value(), r.b.Value()
+	if !(mat.SameDims(xv, bv) || mat.VectorsOfSameSize(xv, bv)) {
+		panic("fn: matrices with not compatible size")
+	}
+	r.z = xv.Add(bv)
+	y := mat.GetDenseWorkspace(r.z.Dims())
+	y.Apply(r.f, r.z)
+	return y
+}
+
+// Backward computes the backward pass.
+func (r *BiasActivation) Backward(gy mat.Matrix) {
+	if !(mat.SameDims(r.z, gy) || mat.VectorsOfSameSize(r.z, gy)) {
+		panic("fn: matrices with not compatible size")
+	}
+	gz := mat.GetDenseWorkspace(r.z.Dims())
+	defer mat.ReleaseDense(gz)
+	gz.Apply(r.df, r.z)
+	gz.ProdInPlace(gy)
+	if r.x.RequiresGrad() {
+		r.x.PropagateGrad(gz)
+	}
+	if r.b.RequiresGrad() {
+		r.b.PropagateGrad(gz)
+	}
+}
+
+// LayerNorm is a fused operator performing the layer normalization of all the
+// elements of x: y = (x - E[x]) / sqrt(Var[x] + eps) ⊙ w + b.
+// It keeps the normalized input and the standard deviation for the backward.
+type LayerNorm struct {
+	x    Operand
+	w    Operand
+	b    Operand
+	eps  Operand // scalar
+	xHat []mat.Float
+	std  mat.Float
+}
+
+// NewLayerNorm returns a new LayerNorm Function.
+func NewLayerNorm(x, w, b, eps Operand) *LayerNorm {
+	return &LayerNorm{x: x, w: w, b: b, eps: eps}
+}
+
+// OutputShape returns the shape of the output, given the shapes of the operands.
+func (r *LayerNorm) OutputShape(inputs ...Shape) (Shape, error) {
+	x := inputs[0]
+	for _, p := range inputs[1:3] {
+		if p.Size() != x.Size() {
+			return Shape{}, fmt.Errorf("%v and %v mismatch", x, p)
+		}
+	}
+	return scalarShape([]Shape{x, inputs[3]})
+}
+
+// Forward computes the output of the function.
+func (r *LayerNorm) Forward() mat.Matrix {
+	xv, wv, bv := r.x.Value(), r.w.Value(), r.b.Value()
+	if xv.Size() != wv.Size() || xv.Size() != bv.Size() {
+		panic("fn: matrices with not compatible size")
+	}
+	r.xHat = make([]mat.Float, xv.Size())
+	y := mat.GetDenseWorkspace(xv.Dims())
+	r.std = mat.LayerNorm(xv.Data(), wv.Data(), bv.Data(), r.eps.Value().Scalar(), y.Data(), r.xHat)
+	return y
+}
+
+// Backward computes the backward pass.
+func (r *LayerNorm) Backward(gy mat.Matrix) {
+	if gy.Size() != len(r.xHat) {
+		panic("fn: matrices with not compatible size")
+	}
+	g := gy.Data()
+	if r.w.RequiresGrad() {
+		gw := mat.GetDenseWorkspace(r.w.Value().Dims())
+		defer mat.ReleaseDense(gw)
+		gwData := gw.Data()
+		for i, v := range g {
+			gwData[i] = v * r.xHat[i]
+		}
+		r.w.PropagateGrad(gw)
+	}
+	if r.b.RequiresGrad() {
+		r.b.PropagateGrad(gy)
+	}
+	if !r.x.RequiresGrad() && !r.eps.RequiresGrad() {
+		return
+	}
+
+	var gx *mat.Dense
+	var gxData []mat.Float
+	if r.x.RequiresGrad() {
+		gx = mat.GetDenseWorkspace(r.x.Value().Dims())
+		defer mat.ReleaseDense(gx)
+		gxData = gx.Data()
+	}
+	meanProd := mat.LayerNormBackward(g, r.w.Value().Data(), r.xHat, r.std, gxData)
+	if gx != nil {
+		r.x.PropagateGrad(gx)
+	}
+	if r.eps.RequiresGrad() {
+		// ∂x̂/∂eps = -x̂ / (2 std²)
+		r.eps.PropagateGrad(mat.NewScalar(-meanProd * mat.Float(len(g)) / (2 * r.std * r.std)))
+	}
+}
+
+// SoftmaxCrossEntropy is a fused operator computing the cross-entropy loss
+// between the softmax of the logits x and the one-hot distribution of the
+// target class: log(Σ exp(x)) - x[target].
+// It is equivalent to the chain of Exp, ReduceSum, Log, AtVec, Neg and Add,
+// but it never materializes the softmax, and it's numerically stable.
+type SoftmaxCrossEntropy struct {
+	x         Operand
+	target    int
+	logSumExp mat.Float
+}
+
+// NewSoftmaxCrossEntropy returns a new SoftmaxCrossEntropy Function.
+func NewSoftmaxCrossEntropy(x Operand, target int) *SoftmaxCrossEntropy {
+	if target < 0 {
+		panic("fn: invalid target index")
+	}
+	return &SoftmaxCrossEntropy{x: x, target: target}
+}
+
+// OutputShape returns the shape of the output, given the shape of the operand.
+func (r *SoftmaxCrossEntropy) OutputShape(inputs ...Shape) (Shape, error) {
+	if x := inputs[0]; r.target >= x.Size() {
+		return Shape{}, fmt.Errorf("target %d out of range of %v", r.target, x)
+	}
+	return Shape{Rows: 1, Columns: 1}, nil
+}
+
+// Forward computes the output of the function.
+func (r *SoftmaxCrossEntropy) Forward() mat.Matrix {
+	x := r.x.Value()
+	if r.target >= x.Size() {
+		panic("fn: target index out of range")
+	}
+	var loss mat.Float
+	loss, r.logSumExp = mat.SoftmaxCrossEntropy(x.Data(), r.target)
+	return mat.NewScalar(loss)
+}
+
+// Backward computes the backward pass.
+func (r *SoftmaxCrossEntropy) Backward(gy mat.Matrix) {
+	if !gy.IsScalar() {
+		panic("fn: the gradient had to be a scalar")
+	}
+	if r.x.RequiresGrad() {
+		x := r.x.Value()
+		gx := mat.GetDenseWorkspace(x.Dims())
+		defer mat.ReleaseDense(gx)
+		mat.SoftmaxCrossEntropyBackward(x.Data(), r.target, r.logSumExp, gy.Scalar(), gx.Data())
+		r.x.PropagateGrad(gx)
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fn
+
+import (
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAffineActivation(t *testing.T) {
+	b := &variable{value: mat.NewVecDense([]mat.Float{0.5, 0.5}), requiresGrad: true}
+	w := &variable{value: mat.NewDense(2, 2, []mat.Float{1, 2, 3, -4}), requiresGrad: true}
+	x := &variable{value: mat.NewVecDense([]mat.Float{1, 1}), requiresGrad: true}
+
+	f := NewAffineActivation(b, w, x, NewReLU(nil).UnaryElementwise)
+	y := f.Forward()
+	assert.InDeltaSlice(t, []mat.Float{3.5, 0}, y.Data(), 1.0e-6)
+
+	f.Backward(mat.NewVecDense([]mat.Float{1, 1}))
+	assert.InDeltaSlice(t, []mat.Float{1, 0}, b.grad.Data(), 1.0e-6)
+	assert.InDeltaSlice(t, []mat.Float{1, 1, 0, 0}, w.grad.Data(), 1.0e-6)
+	assert.InDeltaSlice(t, []mat.Float{1, 2}, x.grad.Data(), 1.0e-6)
+}
+
+func TestBiasActivation(t *testing.T) {
+	x := &variable{value: mat.NewVecDense([]mat.Float{1, -2}), requiresGrad: true}
+	b := &variable{value: mat.NewVecDense([]mat.Float{0.5, 0.5}), requiresGrad: true}
+
+	f := NewBiasActivation(x, b, NewReLU(nil).UnaryElementwise)
+	y := f.Forward()
+	assert.InDeltaSlice(t, []mat.Float{1.5, 0}, y.Data(), 1.0e-6)
+
+	f.Backward(mat.NewVecDense([]mat.Float{2, 3}))
+	assert.InDeltaSlice(t, []mat.Float{2, 0}, x.grad.Data(), 1.0e-6)
+	assert.InDeltaSlice(t, []mat.Float{2, 0}, b.grad.Data(), 1.0e-6)
+}
+
+func TestLayerNorm(t *testing.T) {
+	x := &variable{value: mat.NewVecDense([]mat.Float{1, 2, 3}), requiresGrad: true}
+	w := &variable{value: mat.NewVecDense([]mat.Float{1, 1, 1}), requiresGrad: true}
+	b := &variable{value: mat.NewVecDense([]mat.Float{0, 0, 0}), requiresGrad: true}
+	eps := &variable{value: mat.NewScalar(0), requiresGrad: false}
+
+	f := NewLayerNorm(x, w, b, eps)
+	y := f.Forward()
+	assert.InDeltaSlice(t, []mat.Float{-1.224745, 0, 1.224745}, y.Data(), 1.0e-6)
+
+	f.Backward(mat.NewVecDense([]mat.Float{1, 0, 0}))
+	assert.InDeltaSlice(t, []mat.Float{0.204124, -0.408248, 0.204124}, x.grad.Data(), 1.0e-6)
+	assert.InDeltaSlice(t, []mat.Float{-1.224745, 0, 0}, w.grad.Data(), 1.0e-6)
+	assert.InDeltaSlice(t, []mat.Float{1, 0, 0}, b.grad.Data(), 1.0e-6)
+}
+
+func TestSoftmaxCrossEntropy(t *testing.T) {
+	x := &variable{value: mat.NewVecDense([]mat.Float{-0.2, 0.5, 0.1}), requiresGrad: true}
+
+	f := NewSoftmaxCrossEntropy(x, 2)
+	y := f.Forward()
+	assert.True(t, y.IsScalar())
+	assert.InDelta(t, 1.1733, y.Scalar(), 1.0e-5)
+
+	f.Backward(mat.NewScalar(2))
+	// 2 * (softmax(x) - [0, 0, 1])
+	assert.InDeltaSlice(t, []mat.Float{0.458336, 0.922975, -1.381311}, x.grad.Data(), 1.0e-5)
+
+	assert.Panics(t, func() { NewSoftmaxCrossEntropy(x, 3).Forward() })
+}
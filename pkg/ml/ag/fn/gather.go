@@ -0,0 +1,69 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fn
+
+import (
+	"fmt"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+)
+
+var _ Function = &Gather{}
+
+// Gather is a function to extract the elements of the input matrix at the
+// given indices (data in row-major order) into a column vector.
+// The same index can occur several times: its gradients are accumulated.
+type Gather struct {
+	x       Operand
+	indices []int
+}
+
+// NewGather returns a new Gather Function.
+func NewGather(x Operand, indices []int) *Gather {
+	for _, i := range indices {
+		if i < 0 {
+			panic("fn: invalid index")
+		}
+	}
+	return &Gather{x: x, indices: indices}
+}
+
+// OutputShape returns the shape of the output, given the shape of the operand.
+func (r *Gather) OutputShape(inputs ...Shape) (Shape, error) {
+	x := inputs[0]
+	for _, i := range r.indices {
+		if i >= x.Size() {
+			return Shape{}, fmt.Errorf("index %d out of range of %v", i, x)
+		}
+	}
+	return Shape{Rows: len(r.indices), Columns: 1}, nil
+}
+
+// Forward computes the output of the function.
+func (r *Gather) Forward() mat.Matrix {
+	size := r.x.Value().Size()
+	for _, i := range r.indices {
+		if i >= size {
+			panic("fn: index out of range")
+		}
+	}
+	return mat.Gather(r.x.Value(), r.indices)
+}
+
+// Backward computes the backward pass.
+func (r *Gather) Backward(gy mat.Matrix) {
+	if gy.Size() != len(r.indices) {
+		panic("fn: matrices with not compatible size")
+	}
+	if r.x.RequiresGrad() {
+		gx := r.x.Value().ZerosLike()
+		defer mat.ReleaseMatrix(gx)
+		gxData := gx.Data()
+		for k, v := range gy.Data() {
+			gxData[r.indices[k]] += v
+		}
+		r.x.PropagateGrad(gx)
+	}
+}
@@ -0,0 +1,40 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fn
+
+import (
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGather_Forward(t *testing.T) {
+	x := &variable{
+		value: mat.NewDense(2, 3, []mat.Float{
+			0.1, 0.2, 0.3,
+			0.4, 0.5, 0.6,
+		}),
+		requiresGrad: true,
+	}
+	f := NewGather(x, []int{5, 0, 5})
+	y := f.Forward()
+
+	assert.Equal(t, 3, y.Rows())
+	assert.Equal(t, 1, y.Columns())
+	assert.InDeltaSlice(t, []mat.Float{0.6, 0.1, 0.6}, y.Data(), 1.0e-6)
+
+	f.Backward(mat.NewVecDense([]mat.Float{1, 2, 3}))
+	assert.InDeltaSlice(t, []mat.Float{
+		2, 0, 0,
+		0, 0, 4,
+	}, x.grad.Data(), 1.0e-6)
+}
+
+func TestGather_OutOfRange(t *testing.T) {
+	x := &variable{value: mat.NewEmptyVecDense(3)}
+	assert.Panics(t, func() { NewGather(x, []int{-1}) })
+	assert.Panics(t, func() { NewGather(x, []int{3}).Forward() })
+}
@@ -19,6 +19,11 @@ func NewIdentity(x Operand) *Identity {
 	return &Identity{x: x}
 }
 
+// OutputShape returns the shape of the output, given the shape of the operand.
+func (r *Identity) OutputShape(inputs ...Shape) (Shape, error) {
+	return inputs[0], nil
+}
+
 // Forward computes the output of the function.
 func (r *Identity) Forward() mat.Matrix {
 	return r.x.Value().Clone()
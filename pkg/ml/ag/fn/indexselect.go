@@ -0,0 +1,78 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fn
+
+import (
+	"fmt"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+)
+
+var _ Function = &IndexSelect{}
+
+// IndexSelect is a function to extract the rows of the input matrix at the
+// given indices, stacking them into a new matrix (e.g. the lookup of a batch
+// of embeddings, or the reordering of the beams of a search).
+// The same index can occur several times: its gradients are accumulated.
+type IndexSelect struct {
+	x       Operand
+	indices []int
+}
+
+// NewIndexSelect returns a new IndexSelect Function.
+func NewIndexSelect(x Operand, indices []int) *IndexSelect {
+	for _, i := range indices {
+		if i < 0 {
+			panic("fn: invalid row index")
+		}
+	}
+	return &IndexSelect{x: x, indices: indices}
+}
+
+// OutputShape returns the shape of the output, given the shape of the operand.
+func (r *IndexSelect) OutputShape(inputs ...Shape) (Shape, error) {
+	x := inputs[0]
+	for _, i := range r.indices {
+		if i >= x.Rows {
+			return Shape{}, fmt.Errorf("row %d out of range of %v", i, x)
+		}
+	}
+	return Shape{Rows: len(r.indices), Columns: x.Columns}, nil
+}
+
+// Forward computes the output of the function.
+func (r *IndexSelect) Forward() mat.Matrix {
+	x := r.x.Value()
+	rows, cols := x.Dims()
+	y := mat.NewEmptyDense(len(r.indices), cols)
+	xData, yData := x.Data(), y.Data()
+	for k, i := range r.indices {
+		if i >= rows {
+			panic("fn: row index out of range")
+		}
+		copy(yData[k*cols:(k+1)*cols], xData[i*cols:(i+1)*cols])
+	}
+	return y
+}
+
+// Backward computes the backward pass.
+func (r *IndexSelect) Backward(gy mat.Matrix) {
+	cols := r.x.Value().Columns()
+	if gy.Rows() != len(r.indices) || gy.Columns() != cols {
+		panic("fn: matrices with not compatible size")
+	}
+	if r.x.RequiresGrad() {
+		gx := r.x.Value().ZerosLike()
+		defer mat.ReleaseMatrix(gx)
+		gxData, gyData := gx.Data(), gy.Data()
+		for k, i := range r.indices {
+			row := gxData[i*cols : (i+1)*cols]
+			for j, v := range gyData[k*cols : (k+1)*cols] {
+				row[j] += v
+			}
+		}
+		r.x.PropagateGrad(gx)
+	}
+}
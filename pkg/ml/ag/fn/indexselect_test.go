@@ -0,0 +1,48 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fn
+
+import (
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIndexSelect_Forward(t *testing.T) {
+	x := &variable{
+		value: mat.NewDense(3, 2, []mat.Float{
+			0.1, 0.2,
+			0.3, 0.4,
+			0.5, 0.6,
+		}),
+		requiresGrad: true,
+	}
+	f := NewIndexSelect(x, []int{2, 0, 2, 2})
+	y := f.Forward()
+
+	assert.Equal(t, 4, y.Rows())
+	assert.Equal(t, 2, y.Columns())
+	assert.InDeltaSlice(t, []mat.Float{
+		0.5, 0.6,
+		0.1, 0.2,
+		0.5, 0.6,
+		0.5, 0.6,
+	}, y.Data(), 1.0e-6)
+
+	f.Backward(mat.NewDense(4, 2, []mat.Float{
+		1, 2,
+		3, 4,
+		5, 6,
+		7, 8,
+	}))
+	assert.InDeltaSlice(t, []mat.Float{
+		3, 4,
+		0, 0,
+		13, 16,
+	}, x.grad.Data(), 1.0e-6)
+
+	assert.Panics(t, func() { NewIndexSelect(x, []int{3}).Forward() })
+}
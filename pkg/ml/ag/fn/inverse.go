@@ -0,0 +1,59 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fn
+
+import (
+	"fmt"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+)
+
+var _ Function = &Inverse{}
+
+// Inverse is an operator to compute the inverse of a square non-singular
+// matrix.
+type Inverse struct {
+	x   Operand
+	inv mat.Matrix // a copy of the output, used by the backward
+}
+
+// NewInverse returns a new Inverse Function.
+func NewInverse(x Operand) *Inverse {
+	return &Inverse{x: x}
+}
+
+// OutputShape returns the shape of the output, given the shape of the operand.
+func (r *Inverse) OutputShape(inputs ...Shape) (Shape, error) {
+	if x := inputs[0]; x.Rows != x.Columns {
+		return Shape{}, fmt.Errorf("%v is not square", x)
+	}
+	return inputs[0], nil
+}
+
+// Forward computes the output of the function.
+func (r *Inverse) Forward() mat.Matrix {
+	x := r.x.Value()
+	if x.Rows() != x.Columns() {
+		panic("fn: the matrix must be square")
+	}
+	r.inv = x.Inverse()
+	return r.inv.Clone()
+}
+
+// Backward computes the backward pass.
+func (r *Inverse) Backward(gy mat.Matrix) {
+	if !mat.SameDims(r.x.Value(), gy) {
+		panic("fn: matrices with not compatible size")
+	}
+	if !r.x.RequiresGrad() {
+		return
+	}
+	// gx = -yᵀ × gy × yᵀ
+	tmp := r.inv.TMatMul(gy)
+	defer mat.ReleaseMatrix(tmp)
+	gx := tmp.MatMulT(r.inv).ProdScalarInPlace(-1)
+	defer mat.ReleaseMatrix(gx)
+	r.x.PropagateGrad(gx)
+}
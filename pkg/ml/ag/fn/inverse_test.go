@@ -0,0 +1,43 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fn
+
+import (
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInverse_Forward(t *testing.T) {
+	x := &variable{
+		value: mat.NewDense(2, 2, []mat.Float{
+			4, 7,
+			2, 6,
+		}),
+		requiresGrad: true,
+	}
+	f := NewInverse(x)
+	y := f.Forward()
+
+	assert.InDeltaSlice(t, []mat.Float{
+		0.6, -0.7,
+		-0.2, 0.4,
+	}, y.Data(), 1.0e-6)
+
+	f.Backward(mat.NewDense(2, 2, []mat.Float{
+		1, 2,
+		3, 4,
+	}))
+	// gx = -yᵀ × gy × yᵀ
+	assert.InDeltaSlice(t, []mat.Float{
+		0.28, -0.16,
+		-0.16, 0.02,
+	}, x.grad.Data(), 1.0e-6)
+}
+
+func TestInverse_Panics(t *testing.T) {
+	assert.Panics(t, func() { NewInverse(&variable{value: mat.NewEmptyDense(2, 3)}).Forward() })
+}
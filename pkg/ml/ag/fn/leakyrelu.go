@@ -22,6 +22,11 @@ func NewLeakyReLU(x, alpha Operand) *LeakyReLU {
 	return &LeakyReLU{x: x, alpha: alpha}
 }
 
+// OutputShape returns the shape of the output, given the shapes of the operands.
+func (r *LeakyReLU) OutputShape(inputs ...Shape) (Shape, error) {
+	return scalarShape(inputs)
+}
+
 // Forward computes the output of the function.
 func (r *LeakyReLU) Forward() mat.Matrix {
 	y := mat.GetDenseWorkspace(r.x.Value().Dims())
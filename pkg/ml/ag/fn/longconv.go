@@ -0,0 +1,117 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fn
+
+import (
+	"fmt"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+)
+
+var _ Function = &LongConv{}
+
+// LongConv is an operator to perform a causal convolution of each channel of
+// a sequence with its own (long) kernel, in the frequency domain with the
+// FFT, in O(L log L) instead of O(L²) for a sequence of length L.
+//
+// The input x is a C × L matrix, with one row for each channel, and the
+// kernels k are a C × K matrix, with K >= L: only the first L elements of
+// each kernel are used, so the same kernels can be applied to sequences of
+// any length up to K. The output is the C × L matrix
+//
+//    y[c][t] = sum_{s=0}^{t} k[c][s] * x[c][t-s]
+type LongConv struct {
+	x Operand
+	k Operand
+	// initialized during the forward pass
+	xf [][]mat.Complex
+	kf [][]mat.Complex
+}
+
+// NewLongConv returns a new LongConv Function.
+func NewLongConv(x, k Operand) *LongConv {
+	return &LongConv{x: x, k: k}
+}
+
+// OutputShape returns the shape of the output, given the shapes of the operands.
+func (r *LongConv) OutputShape(inputs ...Shape) (Shape, error) {
+	x, k := inputs[0], inputs[1]
+	if k.Rows != x.Rows || k.Columns < x.Columns {
+		return Shape{}, fmt.Errorf("the kernels %v don't match the input %v", k, x)
+	}
+	return x, nil
+}
+
+// Forward computes the output of the function.
+func (r *LongConv) Forward() mat.Matrix {
+	x, k := r.x.Value(), r.k.Value()
+	channels, length := x.Dims()
+	if k.Rows() != channels {
+		panic("fn: longconv: the kernels must match the channels of the input")
+	}
+	if k.Columns() < length {
+		panic("fn: longconv: the kernels are shorter than the sequence")
+	}
+	n := mat.NextPowerOfTwo(2 * length) // enough for the linear (not circular) convolutions
+	y := mat.NewEmptyDense(channels, length)
+	yData, xData, kData := y.Data(), x.Data(), k.Data()
+	r.xf = make([][]mat.Complex, channels)
+	r.kf = make([][]mat.Complex, channels)
+	for c := 0; c < channels; c++ {
+		r.xf[c] = mat.RFFT(xData[c*length:(c+1)*length], n)
+		r.kf[c] = mat.RFFT(kData[c*k.Columns():c*k.Columns()+length], n)
+		prod := make([]mat.Complex, len(r.xf[c]))
+		for i := range prod {
+			prod[i] = r.xf[c][i] * r.kf[c][i]
+		}
+		copy(yData[c*length:(c+1)*length], mat.IRFFT(prod, n))
+	}
+	return y
+}
+
+// Backward computes the backward pass.
+// The gradients are the cross-correlations of the output gradients with the
+// kernels (for x) and with the input (for k), also computed with the FFT.
+func (r *LongConv) Backward(gy mat.Matrix) {
+	x, k := r.x.Value(), r.k.Value()
+	if !mat.SameDims(x, gy) {
+		panic("fn: matrices with not compatible size")
+	}
+	channels, length := x.Dims()
+	n := mat.NextPowerOfTwo(2 * length) // enough for the linear (not circular) convolutions
+	gyData := gy.Data()
+	gyf := make([][]mat.Complex, channels)
+	for c := range gyf {
+		gyf[c] = mat.RFFT(gyData[c*length:(c+1)*length], n)
+	}
+	if r.x.RequiresGrad() {
+		gx := x.ZerosLike()
+		defer mat.ReleaseMatrix(gx)
+		gxData := gx.Data()
+		for c := 0; c < channels; c++ {
+			copy(gxData[c*length:(c+1)*length], mat.IRFFT(correlate(gyf[c], r.kf[c]), n))
+		}
+		r.x.PropagateGrad(gx)
+	}
+	if r.k.RequiresGrad() {
+		gk := k.ZerosLike() // the elements beyond the length of the sequence have no gradients
+		defer mat.ReleaseMatrix(gk)
+		gkData := gk.Data()
+		for c := 0; c < channels; c++ {
+			copy(gkData[c*k.Columns():c*k.Columns()+length], mat.IRFFT(correlate(gyf[c], r.xf[c]), n))
+		}
+		r.k.PropagateGrad(gk)
+	}
+}
+
+// correlate returns the spectrum of the cross-correlation of the signals with
+// spectra a and b: a * conj(b).
+func correlate(a, b []mat.Complex) []mat.Complex {
+	y := make([]mat.Complex, len(a))
+	for i, v := range b {
+		y[i] = a[i] * complex(real(v), -imag(v))
+	}
+	return y
+}
@@ -0,0 +1,58 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fn
+
+import (
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLongConv_Forward(t *testing.T) {
+	x := &variable{
+		value: mat.NewDense(2, 3, []mat.Float{
+			1, 2, 3,
+			0.5, -1, 2,
+		}),
+		requiresGrad: true,
+	}
+	k := &variable{
+		value: mat.NewDense(2, 4, []mat.Float{
+			1, 0.5, 0.25, 9,
+			-1, 2, 0, 9,
+		}),
+		requiresGrad: true,
+	}
+	f := NewLongConv(x, k)
+	y := f.Forward()
+
+	// y[c][t] = sum_{s<=t} k[c][s] * x[c][t-s]
+	assert.InDeltaSlice(t, []mat.Float{
+		1, 2.5, 4.25,
+		-0.5, 2, -4,
+	}, y.Data(), 1.0e-5)
+
+	f.Backward(mat.NewDense(2, 3, []mat.Float{
+		1, 2, 3,
+		-1, 0, 1,
+	}))
+	// gx[c][j] = sum_{t>=j} gy[c][t] * k[c][t-j]
+	assert.InDeltaSlice(t, []mat.Float{
+		2.75, 3.5, 3,
+		1, 2, -1,
+	}, x.grad.Data(), 1.0e-5)
+	// gk[c][s] = sum_{t>=s} gy[c][t] * x[c][t-s]
+	assert.InDeltaSlice(t, []mat.Float{
+		14, 8, 3, 0,
+		1.5, -1, 0.5, 0,
+	}, k.grad.Data(), 1.0e-5)
+}
+
+func TestLongConv_Panics(t *testing.T) {
+	x := &variable{value: mat.NewEmptyDense(2, 5)}
+	assert.Panics(t, func() { NewLongConv(x, &variable{value: mat.NewEmptyDense(2, 4)}).Forward() })
+	assert.Panics(t, func() { NewLongConv(x, &variable{value: mat.NewEmptyDense(3, 5)}).Forward() })
+}
@@ -0,0 +1,48 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fn
+
+import (
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+)
+
+var _ Function = &MaskedFill{}
+
+// MaskedFill is a function to replace the masked elements of the input with
+// a constant value. The mask has the same size of the input: its zero values
+// mark the masked elements, its non-zero values the elements to keep.
+// The gradients are propagated to the kept elements only.
+type MaskedFill struct {
+	x     Operand
+	mask  mat.Matrix
+	value mat.Float
+}
+
+// NewMaskedFill returns a new MaskedFill Function.
+func NewMaskedFill(x Operand, mask mat.Matrix, value mat.Float) *MaskedFill {
+	return &MaskedFill{x: x, mask: mask, value: value}
+}
+
+// OutputShape returns the shape of the output, given the shape of the operand.
+func (r *MaskedFill) OutputShape(inputs ...Shape) (Shape, error) {
+	return maskedShape(inputs[0], r.mask)
+}
+
+// Forward computes the output of the function.
+func (r *MaskedFill) Forward() mat.Matrix {
+	return mat.MaskedFill(r.x.Value(), r.mask, r.value)
+}
+
+// Backward computes the backward pass.
+func (r *MaskedFill) Backward(gy mat.Matrix) {
+	if !mat.SameDims(r.x.Value(), gy) {
+		panic("fn: matrices with not compatible size")
+	}
+	if r.x.RequiresGrad() {
+		gx := mat.ApplyMask(gy, r.mask)
+		defer mat.ReleaseDense(gx)
+		r.x.PropagateGrad(gx)
+	}
+}
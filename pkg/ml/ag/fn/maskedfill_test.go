@@ -0,0 +1,36 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fn
+
+import (
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaskedFill_Forward(t *testing.T) {
+	x := &variable{
+		value: mat.NewDense(2, 2, []mat.Float{
+			0.1, 0.2,
+			0.3, 0.4,
+		}),
+		grad:         nil,
+		requiresGrad: true,
+	}
+	mask := mat.NewDense(2, 2, []mat.Float{
+		1, 0,
+		0, 1,
+	})
+
+	f := NewMaskedFill(x, mask, -1)
+	y := f.Forward()
+
+	assert.InDeltaSlice(t, []mat.Float{0.1, -1, -1, 0.4}, y.Data(), 1.0e-6)
+
+	f.Backward(mat.NewDense(2, 2, []mat.Float{0.5, 0.6, 0.7, 0.8}))
+
+	assert.InDeltaSlice(t, []mat.Float{0.5, 0, 0, 0.8}, x.grad.Data(), 1.0e-6)
+}
@@ -0,0 +1,60 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fn
+
+import (
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+)
+
+var _ Function = &MaskedMean{}
+
+// MaskedMean is a function to compute the mean of the unmasked elements of
+// the input, as a scalar. The mask has the same size of the input: its zero
+// values mark the masked elements, its non-zero values the elements to keep.
+// If all the elements are masked, the result is zero.
+type MaskedMean struct {
+	x    Operand
+	mask mat.Matrix
+	n    int // initialized during the forward pass (required by the backward pass)
+}
+
+// NewMaskedMean returns a new MaskedMean Function.
+func NewMaskedMean(x Operand, mask mat.Matrix) *MaskedMean {
+	return &MaskedMean{x: x, mask: mask}
+}
+
+// OutputShape returns the shape of the output, given the shape of the operand.
+func (r *MaskedMean) OutputShape(inputs ...Shape) (Shape, error) {
+	if _, err := maskedShape(inputs[0], r.mask); err != nil {
+		return Shape{}, err
+	}
+	return Shape{Rows: 1, Columns: 1}, nil
+}
+
+// Forward computes the output of the function.
+func (r *MaskedMean) Forward() mat.Matrix {
+	var mean mat.Float
+	mean, r.n = mat.MaskedMean(r.x.Value(), r.mask)
+	return mat.NewScalar(mean)
+}
+
+// Backward computes the backward pass.
+func (r *MaskedMean) Backward(gy mat.Matrix) {
+	if !gy.IsScalar() {
+		panic("fn: the gradient had to be a scalar")
+	}
+	if r.x.RequiresGrad() && r.n > 0 {
+		g := gy.Scalar() / mat.Float(r.n)
+		gx := mat.NewEmptyDense(r.x.Value().Dims())
+		defer mat.ReleaseDense(gx)
+		gxData := gx.Data()
+		for i, m := range r.mask.Data() {
+			if m != 0 {
+				gxData[i] = g
+			}
+		}
+		r.x.PropagateGrad(gx)
+	}
+}
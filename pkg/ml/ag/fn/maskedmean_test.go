@@ -0,0 +1,51 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fn
+
+import (
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaskedMean_Forward(t *testing.T) {
+	x := &variable{
+		value: mat.NewDense(2, 3, []mat.Float{
+			0.1, 0.2, 0.3,
+			0.4, 0.5, 0.6,
+		}),
+		grad:         nil,
+		requiresGrad: true,
+	}
+	mask := mat.NewDense(2, 3, []mat.Float{
+		1, 1, 1,
+		1, 0, 0,
+	})
+
+	f := NewMaskedMean(x, mask)
+	y := f.Forward()
+
+	assert.InDeltaSlice(t, []mat.Float{0.25}, y.Data(), 1.0e-6)
+
+	f.Backward(mat.NewScalar(0.8))
+
+	assert.InDeltaSlice(t, []mat.Float{
+		0.2, 0.2, 0.2,
+		0.2, 0.0, 0.0,
+	}, x.grad.Data(), 1.0e-6)
+}
+
+func TestMaskedMean_AllMasked(t *testing.T) {
+	x := &variable{
+		value:        mat.NewVecDense([]mat.Float{1, 2}),
+		grad:         nil,
+		requiresGrad: true,
+	}
+	f := NewMaskedMean(x, mat.NewEmptyVecDense(2))
+	assert.Equal(t, mat.Float(0), f.Forward().Scalar())
+	f.Backward(mat.NewScalar(1))
+	assert.Nil(t, x.grad)
+}
@@ -0,0 +1,61 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fn
+
+import (
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+)
+
+var _ Function = &MaskedSoftmax{}
+
+// MaskedSoftmax is a softmax function restricted to the unmasked elements of
+// the input, whose masked elements get a zero probability.
+// The mask has the same size of the input: its zero values mark the masked
+// elements, its non-zero values the elements to keep.
+// Compared to adding large negative constants before a Softmax, the result is
+// well defined even if all the elements are masked (all zeros).
+type MaskedSoftmax struct {
+	x    Operand
+	mask mat.Matrix
+	y    mat.Matrix // initialized during the forward pass (required by the backward pass)
+}
+
+// NewMaskedSoftmax returns a new MaskedSoftmax Function.
+func NewMaskedSoftmax(x Operand, mask mat.Matrix) *MaskedSoftmax {
+	return &MaskedSoftmax{x: x, mask: mask}
+}
+
+// OutputShape returns the shape of the output, given the shape of the operand.
+func (r *MaskedSoftmax) OutputShape(inputs ...Shape) (Shape, error) {
+	return maskedShape(inputs[0], r.mask)
+}
+
+// Forward computes the output of the function.
+func (r *MaskedSoftmax) Forward() mat.Matrix {
+	r.y = mat.MaskedSoftmax(r.x.Value(), r.mask)
+	return r.y
+}
+
+// Backward computes the backward pass.
+func (r *MaskedSoftmax) Backward(gy mat.Matrix) {
+	if !(mat.SameDims(r.x.Value(), gy) || mat.VectorsOfSameSize(r.x.Value(), gy)) {
+		panic("fn: matrices with not compatible size")
+	}
+	if r.x.RequiresGrad() {
+		// gx = y * (gy - dot(y, gy)); the masked elements have y = 0
+		y, gyData := r.y.Data(), gy.Data()
+		var dot mat.Float
+		for i, v := range y {
+			dot += v * gyData[i]
+		}
+		gx := mat.NewEmptyDense(r.x.Value().Dims())
+		defer mat.ReleaseDense(gx)
+		gxData := gx.Data()
+		for i, v := range y {
+			gxData[i] = v * (gyData[i] - dot)
+		}
+		r.x.PropagateGrad(gx)
+	}
+}
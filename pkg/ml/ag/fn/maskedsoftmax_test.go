@@ -0,0 +1,49 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fn
+
+import (
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaskedSoftmax_Forward(t *testing.T) {
+	x := &variable{
+		value:        mat.NewVecDense([]mat.Float{-0.41, -1.08, 0, 0.87, -0.19, -0.75}),
+		grad:         nil,
+		requiresGrad: true,
+	}
+	mask := mat.NewVecDense([]mat.Float{1, 1, 1, 1, 0, 0})
+
+	f := NewMaskedSoftmax(x, mask)
+	y := f.Forward()
+
+	// the same values of a Softmax of the first four elements
+	sm := NewSoftmax(&variable{
+		value:        mat.NewVecDense([]mat.Float{-0.41, -1.08, 0, 0.87}),
+		requiresGrad: true,
+	})
+	expected := sm.Forward().Data()
+	assert.InDeltaSlice(t, append(expected, 0, 0), y.Data(), 1.0e-6)
+
+	f.Backward(mat.NewVecDense([]mat.Float{0.1, -0.2, 0.3, 0.4, 0.5, 0.6}))
+	sm.Backward(mat.NewVecDense([]mat.Float{0.1, -0.2, 0.3, 0.4}))
+	expectedGrad := sm.x.(*variable).grad.Data()
+	assert.InDeltaSlice(t, append(expectedGrad, 0, 0), x.grad.Data(), 1.0e-6)
+}
+
+func TestMaskedSoftmax_AllMasked(t *testing.T) {
+	x := &variable{
+		value:        mat.NewVecDense([]mat.Float{1, 2}),
+		grad:         nil,
+		requiresGrad: true,
+	}
+	f := NewMaskedSoftmax(x, mat.NewEmptyVecDense(2))
+	assert.Equal(t, []mat.Float{0, 0}, f.Forward().Data())
+	f.Backward(mat.NewVecDense([]mat.Float{1, 1}))
+	assert.Equal(t, []mat.Float{0, 0}, x.grad.Data())
+}
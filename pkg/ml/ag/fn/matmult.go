@@ -0,0 +1,74 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fn
+
+import (
+	"fmt"
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"sync"
+)
+
+var _ Function = &MatMulT{}
+
+// MatMulT is an operator to perform the matrix multiplication x1×x2ᵀ,
+// without materializing the transpose of x2 (e.g. queries × keysᵀ).
+type MatMulT struct {
+	x1 Operand
+	x2 Operand
+}
+
+// NewMatMulT returns a new MatMulT Function.
+func NewMatMulT(x1, x2 Operand) *MatMulT {
+	return &MatMulT{x1: x1, x2: x2}
+}
+
+// OutputShape returns the shape of the output, given the shapes of the operands.
+func (r *MatMulT) OutputShape(inputs ...Shape) (Shape, error) {
+	a, b := inputs[0], inputs[1]
+	if a.Columns != b.Columns {
+		return Shape{}, fmt.Errorf("%v · (%v)ᵀ mismatch", a, b)
+	}
+	return Shape{Rows: a.Rows, Columns: b.Rows}, nil
+}
+
+// Forward computes the output of the function.
+func (r *MatMulT) Forward() mat.Matrix {
+	if r.x1.Value().Columns() != r.x2.Value().Columns() {
+		panic("fn: matrices with not compatible size")
+	}
+	return r.x1.Value().MatMulT(r.x2.Value())
+}
+
+// Backward computes the backward pass.
+func (r *MatMulT) Backward(gy mat.Matrix) {
+	if !(r.x1.Value().Rows() == gy.Rows() && r.x2.Value().Rows() == gy.Columns()) {
+		panic("fn: matrices with not compatible size")
+	}
+	var wg sync.WaitGroup
+	// the gradients of an operand used twice are accumulated in a fixed order
+	serial := r.x1 == r.x2
+	if r.x1.RequiresGrad() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			gx := gy.Mul(r.x2.Value())
+			defer mat.ReleaseMatrix(gx)
+			r.x1.PropagateGrad(gx)
+		}()
+		if serial {
+			wg.Wait()
+		}
+	}
+	if r.x2.RequiresGrad() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			gx := gy.TMatMul(r.x1.Value())
+			defer mat.ReleaseMatrix(gx)
+			r.x2.PropagateGrad(gx)
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,65 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fn
+
+import (
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestMatMulT_Forward(t *testing.T) {
+	x1 := &variable{
+		value: mat.NewDense(2, 3, []mat.Float{
+			0.1, 0.2, 0.3,
+			0.4, -0.5, 0.6,
+		}),
+		grad:         nil,
+		requiresGrad: true,
+	}
+
+	x2 := &variable{
+		value: mat.NewDense(4, 3, []mat.Float{
+			0.5, -0.1, 0.2,
+			0.0, 0.3, -0.4,
+			0.7, 0.1, 0.1,
+			-0.2, 0.2, 0.9,
+		}),
+		grad:         nil,
+		requiresGrad: true,
+	}
+
+	f := NewMatMulT(x1, x2)
+	y := f.Forward()
+
+	assert.Equal(t, []int{2, 4}, []int{y.Rows(), y.Columns()})
+	assert.InDeltaSlice(t, []mat.Float{
+		0.09, -0.06, 0.12, 0.29,
+		0.37, -0.39, 0.29, 0.36,
+	}, y.Data(), 1.0e-6)
+
+	f.Backward(mat.NewDense(2, 4, []mat.Float{
+		0.1, -0.2, 0.3, 0.4,
+		0.5, 0.0, -0.6, 0.2,
+	}))
+
+	assert.InDeltaSlice(t, []mat.Float{
+		0.18, 0.04, 0.49,
+		-0.21, -0.07, 0.22,
+	}, x1.grad.Data(), 1.0e-6)
+
+	assert.InDeltaSlice(t, []mat.Float{
+		0.21, -0.23, 0.33,
+		-0.02, -0.04, -0.06,
+		-0.21, 0.36, -0.27,
+		0.12, -0.02, 0.24,
+	}, x2.grad.Data(), 1.0e-6)
+}
+
+func TestMatMulT_Panics(t *testing.T) {
+	x1 := &variable{value: mat.NewEmptyDense(2, 3), requiresGrad: true}
+	x2 := &variable{value: mat.NewEmptyDense(4, 2), requiresGrad: true}
+	assert.Panics(t, func() { NewMatMulT(x1, x2).Forward() })
+}
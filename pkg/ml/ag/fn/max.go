@@ -20,6 +20,11 @@ func NewMax(x1, x2 Operand) *Max {
 	return &Max{x1: x1, x2: x2}
 }
 
+// OutputShape returns the shape of the output, given the shapes of the operands.
+func (r *Max) OutputShape(inputs ...Shape) (Shape, error) {
+	return elementwiseShape(inputs)
+}
+
 // Forward computes the output of the function.
 func (r *Max) Forward() mat.Matrix {
 	x1v := r.x1.Value()
@@ -5,6 +5,8 @@
 package fn
 
 import (
+	"fmt"
+
 	mat "github.com/nlpodyssey/spago/pkg/mat32"
 	"github.com/nlpodyssey/spago/pkg/utils"
 )
@@ -34,6 +36,15 @@ func NewMaxPooling(x Operand, r, c int) *MaxPooling {
 	}
 }
 
+// OutputShape returns the shape of the output, given the shape of the operand.
+func (r *MaxPooling) OutputShape(inputs ...Shape) (Shape, error) {
+	x := inputs[0]
+	if x.Rows%r.rows != 0 || x.Columns%r.cols != 0 {
+		return Shape{}, fmt.Errorf("%v is not divisible in %dx%d windows", x, r.rows, r.cols)
+	}
+	return Shape{Rows: x.Rows / r.rows, Columns: x.Columns / r.cols}, nil
+}
+
 // Forward computes the output of the function.
 func (r *MaxPooling) Forward() mat.Matrix {
 	if !(r.x.Value().Rows()%r.rows == 0 && r.x.Value().Columns()%r.cols == 0) {
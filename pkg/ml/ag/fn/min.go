@@ -20,6 +20,11 @@ func NewMin(x1, x2 Operand) *Min {
 	return &Min{x1: x1, x2: x2}
 }
 
+// OutputShape returns the shape of the output, given the shapes of the operands.
+func (r *Min) OutputShape(inputs ...Shape) (Shape, error) {
+	return elementwiseShape(inputs)
+}
+
 // Forward computes the output of the function.
 func (r *Min) Forward() mat.Matrix {
 	x1v := r.x1.Value()
@@ -6,6 +6,7 @@ package fn
 
 import (
 	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/ml/numerics"
 )
 
 // Tan is an operator to perform element-wise tangent.
@@ -316,13 +317,14 @@ func absDeriv(i, j int, v mat.Float) mat.Float {
 	}
 }
 
-// safeLog is a simple work-around that make the math.Log() safe for zero or negative values
+// safeLog is a simple work-around that make the math.Log() safe for zero or negative values.
+// The zero values are replaced by the Log tolerance of the numerics policy in use.
 func safeLog(i, j int, v mat.Float) mat.Float {
 	if v > 0.0 {
 		return mat.Log(v)
 	}
 	if v == 0.0 {
-		return mat.Log(1.0e-08)
+		return mat.Log(numerics.Current().Log)
 	}
 	panic("ag: invalid log for negative values")
 }
@@ -331,7 +333,7 @@ func safeLogDeriv(i, j int, v mat.Float) mat.Float {
 	if v > 0.0 {
 		return 1.0 / v
 	} else if v == 0.0 {
-		return 1.0 / 1.0e-08
+		return 1.0 / numerics.Current().Log
 	} else {
 		panic("ag: invalid log for negative values")
 	}
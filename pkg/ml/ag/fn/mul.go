@@ -5,6 +5,7 @@
 package fn
 
 import (
+	"fmt"
 	mat "github.com/nlpodyssey/spago/pkg/mat32"
 	"sync"
 )
@@ -22,6 +23,15 @@ func NewMul(x1, x2 Operand) *Mul {
 	return &Mul{x1: x1, x2: x2}
 }
 
+// OutputShape returns the shape of the output, given the shapes of the operands.
+func (r *Mul) OutputShape(inputs ...Shape) (Shape, error) {
+	a, b := inputs[0], inputs[1]
+	if a.Columns != b.Rows {
+		return Shape{}, fmt.Errorf("%v · %v mismatch", a, b)
+	}
+	return Shape{Rows: a.Rows, Columns: b.Columns}, nil
+}
+
 // Forward computes the output of the function.
 func (r *Mul) Forward() mat.Matrix {
 	if r.x1.Value().Columns() != r.x2.Value().Rows() {
@@ -37,33 +47,27 @@ func (r *Mul) Backward(gy mat.Matrix) {
 		panic("fn: matrices with not compatible size")
 	}
 	var wg sync.WaitGroup
+	// the gradients of an operand used twice are accumulated in a fixed order
+	serial := r.x1 == r.x2
 	if r.x1.RequiresGrad() {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			x2t := r.x2.Value().T()
-			defer mat.ReleaseMatrix(x2t)
-			gx := gy.Mul(x2t)
+			gx := gy.MatMulT(r.x2.Value())
 			defer mat.ReleaseMatrix(gx)
 			r.x1.PropagateGrad(gx)
 		}()
+		if serial {
+			wg.Wait()
+		}
 	}
 	if r.x2.RequiresGrad() {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			//r.x2.PropagateGrad(gy.T().Mul(r.x1).T()) // alternative method
-			if gy.Columns() == 1 {
-				gx := r.x1.Value().MulT(gy)
-				defer mat.ReleaseMatrix(gx)
-				r.x2.PropagateGrad(gx)
-			} else {
-				x1t := r.x1.Value().T()
-				defer mat.ReleaseMatrix(x1t)
-				gx := x1t.Mul(gy)
-				defer mat.ReleaseMatrix(gx)
-				r.x2.PropagateGrad(gx)
-			}
+			gx := r.x1.Value().TMatMul(gy)
+			defer mat.ReleaseMatrix(gx)
+			r.x2.PropagateGrad(gx)
 		}()
 	}
 	wg.Wait()
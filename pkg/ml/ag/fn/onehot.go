@@ -0,0 +1,67 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fn
+
+import mat "github.com/nlpodyssey/spago/pkg/mat32"
+
+var _ Function = &OneHot{}
+
+// OneHot is a function to compute the one-hot encoding of the indices held
+// by its operand (e.g. the output of ArgMax), each one between 0 and size-1.
+// Each index is encoded in a column (AlongColumns) or row (AlongRows) of
+// size elements, so that the output is a size×n or n×size matrix, given n
+// indices (i.e. a vector of the given size for a single index along the
+// columns). This way OneHot(ArgMax(x, axis), rows or columns of x, axis) has
+// the same shape as x.
+// The function is not differentiable: the gradients are not propagated.
+type OneHot struct {
+	x    Operand
+	size int
+	axis Axis
+}
+
+// NewOneHot returns a new OneHot Function.
+func NewOneHot(x Operand, size int, axis Axis) *OneHot {
+	if size < 1 {
+		panic("fn: OneHot size must be greater than zero")
+	}
+	axis.validate()
+	return &OneHot{x: x, size: size, axis: axis}
+}
+
+// OutputShape returns the shape of the output, given the shape of the operand.
+func (r *OneHot) OutputShape(inputs ...Shape) (Shape, error) {
+	if r.axis == AlongRows {
+		return Shape{Rows: inputs[0].Size(), Columns: r.size}, nil
+	}
+	return Shape{Rows: r.size, Columns: inputs[0].Size()}, nil
+}
+
+// Forward computes the output of the function.
+func (r *OneHot) Forward() mat.Matrix {
+	indices := r.x.Value().Data()
+	rows, cols := r.size, len(indices)
+	if r.axis == AlongRows {
+		rows, cols = cols, rows
+	}
+	out := mat.NewEmptyDense(rows, cols)
+	outData := out.Data()
+	for i, line := range r.axis.lines(rows, cols) {
+		index := int(indices[i])
+		if index < 0 || index >= r.size || mat.Float(index) != indices[i] {
+			panic("fn: OneHot index out of range")
+		}
+		outData[line[index]] = 1
+	}
+	return out
+}
+
+// Backward does nothing, the gradients are not propagated.
+func (r *OneHot) Backward(gy mat.Matrix) {
+	n := r.x.Value().Size()
+	if gy.Size() != n*r.size {
+		panic("fn: matrices with not compatible size")
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fn
+
+import (
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOneHot_Forward(t *testing.T) {
+	x := &variable{
+		value:        mat.NewScalar(2),
+		requiresGrad: true,
+	}
+	f := NewOneHot(x, 4, AlongColumns)
+	y := f.Forward()
+	assert.Equal(t, 4, y.Rows())
+	assert.Equal(t, 1, y.Columns())
+	assert.Equal(t, []mat.Float{0, 0, 1, 0}, y.Data())
+
+	f.Backward(mat.NewVecDense([]mat.Float{1, 2, 3, 4}))
+	assert.Nil(t, x.grad)
+
+	x = &variable{value: mat.NewVecDense([]mat.Float{1, 0, 1})}
+	assert.Equal(t, []mat.Float{
+		0, 1, 0,
+		1, 0, 1,
+	}, NewOneHot(x, 2, AlongColumns).Forward().Data())
+	assert.Equal(t, []mat.Float{
+		0, 1,
+		1, 0,
+		0, 1,
+	}, NewOneHot(x, 2, AlongRows).Forward().Data())
+}
+
+func TestOneHot_Panics(t *testing.T) {
+	x := &variable{value: mat.NewScalar(2)}
+	assert.Panics(t, func() { NewOneHot(x, 0, AlongColumns) })
+	assert.Panics(t, func() { NewOneHot(x, 2, Axis(2)) })
+	assert.Panics(t, func() { NewOneHot(x, 2, AlongColumns).Forward() })
+	assert.Panics(t, func() { NewOneHot(&variable{value: mat.NewScalar(0.5)}, 2, AlongColumns).Forward() })
+	assert.Panics(t, func() { NewOneHot(x, 3, AlongColumns).Backward(mat.NewEmptyVecDense(2)) })
+}
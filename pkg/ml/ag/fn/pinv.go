@@ -0,0 +1,69 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fn
+
+import mat "github.com/nlpodyssey/spago/pkg/mat32"
+
+var _ Function = &PInv{}
+
+// PInv is an operator to compute the Moore-Penrose pseudo-inverse of a
+// matrix (see mat.Dense.PInv).
+// The gradient is correct as long as small perturbations of the input don't
+// change its rank, which is always the case for a full-rank matrix.
+type PInv struct {
+	x    Operand
+	pinv *mat.Dense // a copy of the output, used by the backward
+}
+
+// NewPInv returns a new PInv Function.
+func NewPInv(x Operand) *PInv {
+	return &PInv{x: x}
+}
+
+// OutputShape returns the shape of the output, given the shape of the operand.
+func (r *PInv) OutputShape(inputs ...Shape) (Shape, error) {
+	return Shape{Rows: inputs[0].Columns, Columns: inputs[0].Rows}, nil
+}
+
+// Forward computes the output of the function.
+// It panics if the value of the operand is not a Dense matrix, or if it
+// contains NaN or infinite values.
+func (r *PInv) Forward() mat.Matrix {
+	x, ok := r.x.Value().(*mat.Dense)
+	if !ok {
+		panic("fn: PInv requires a Dense matrix")
+	}
+	pinv, err := x.PInv()
+	if err != nil {
+		panic("fn: " + err.Error())
+	}
+	r.pinv = pinv
+	return pinv.Clone()
+}
+
+// Backward computes the backward pass.
+//
+// With p = x⁺, the gradient is
+//
+//     gx = -pᵀ × gy × pᵀ + (I - x × p) × gyᵀ × p × pᵀ + pᵀ × p × gyᵀ × (I - p × x)
+func (r *PInv) Backward(gy mat.Matrix) {
+	x := r.x.Value()
+	if !(x.Columns() == gy.Rows() && x.Rows() == gy.Columns()) {
+		panic("fn: matrices with not compatible size")
+	}
+	if !r.x.RequiresGrad() {
+		return
+	}
+	p := r.pinv
+	pt := p.T()
+	gyt := gy.T()
+	gx := pt.Mul(gy).Mul(pt).ProdScalarInPlace(-1)
+	// the projections onto the orthogonal complements of the ranges of x and xᵀ
+	qx := mat.I(x.Rows()).SubInPlace(x.Mul(p))
+	qp := mat.I(x.Columns()).SubInPlace(p.Mul(x))
+	gx.AddInPlace(qx.Mul(gyt).Mul(p).Mul(pt))
+	gx.AddInPlace(pt.Mul(p).Mul(gyt).Mul(qp))
+	r.x.PropagateGrad(gx)
+}
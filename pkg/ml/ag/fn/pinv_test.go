@@ -0,0 +1,50 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fn
+
+import (
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPInv_Forward(t *testing.T) {
+	x := &variable{
+		value: mat.NewDense(3, 2, []mat.Float{
+			1, 0,
+			0, 1,
+			1, 1,
+		}),
+		requiresGrad: true,
+	}
+	f := NewPInv(x)
+	y := f.Forward()
+
+	assert.Equal(t, 2, y.Rows())
+	assert.Equal(t, 3, y.Columns())
+	assert.InDeltaSlice(t, []mat.Float{
+		2.0 / 3, -1.0 / 3, 1.0 / 3,
+		-1.0 / 3, 2.0 / 3, 1.0 / 3,
+	}, y.Data(), 1.0e-5)
+
+	f.Backward(mat.NewDense(2, 3, []mat.Float{
+		1, 0, -1,
+		0.5, 2, 0,
+	}))
+	assert.InDeltaSlice(t, []mat.Float{
+		-1.0 / 6, 7.0 / 6,
+		0.5, -2.0 / 3,
+		-1.0 / 6, -0.5,
+	}, x.grad.Data(), 1.0e-5)
+}
+
+func TestPInv_Panics(t *testing.T) {
+	assert.Panics(t, func() { NewPInv(&variable{value: mat.NewDense(1, 1, []mat.Float{mat.NaN()})}).Forward() })
+	x := &variable{value: mat.NewEmptyDense(3, 2), requiresGrad: true}
+	f := NewPInv(x)
+	f.Forward()
+	assert.Panics(t, func() { f.Backward(mat.NewEmptyDense(3, 2)) })
+}
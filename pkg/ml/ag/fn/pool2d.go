@@ -0,0 +1,203 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fn
+
+import (
+	"fmt"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+)
+
+var (
+	_ Function = &MaxPool2D{}
+	_ Function = &AvgPool2D{}
+)
+
+// Pool2DConfig provides the geometry of a 2D pooling, whose images are
+// represented as in Conv2DConfig.
+// The zero values of the strides are interpreted as the size of the kernel
+// (i.e. non-overlapping windows). The padding can't exceed half the size of
+// the kernel.
+type Pool2DConfig struct {
+	// Height and Width are the size of the input channels.
+	Height int
+	Width  int
+	// KernelRows and KernelColumns are the size of the pooling windows.
+	KernelRows    int
+	KernelColumns int
+	// StrideRows and StrideColumns are the steps between the windows.
+	StrideRows    int
+	StrideColumns int
+	// PaddingRows and PaddingColumns are the number of elements implicitly added at both sides of the input.
+	PaddingRows    int
+	PaddingColumns int
+}
+
+// conv returns the equivalent configuration of a convolution.
+func (c Pool2DConfig) conv() Conv2DConfig {
+	if c.StrideRows == 0 {
+		c.StrideRows = c.KernelRows
+	}
+	if c.StrideColumns == 0 {
+		c.StrideColumns = c.KernelColumns
+	}
+	return Conv2DConfig{
+		Height:         c.Height,
+		Width:          c.Width,
+		KernelRows:     c.KernelRows,
+		KernelColumns:  c.KernelColumns,
+		StrideRows:     c.StrideRows,
+		StrideColumns:  c.StrideColumns,
+		PaddingRows:    c.PaddingRows,
+		PaddingColumns: c.PaddingColumns,
+	}
+}
+
+// OutputSize returns the size of the output channels.
+func (c Pool2DConfig) OutputSize() (rows, columns int) {
+	return c.conv().OutputSize()
+}
+
+func (c Pool2DConfig) validate() Conv2DConfig {
+	conv := c.conv()
+	conv.validate()
+	if 2*c.PaddingRows > c.KernelRows || 2*c.PaddingColumns > c.KernelColumns {
+		panic("fn: the padding can't exceed half the size of the pooling window")
+	}
+	return conv.withDefaults()
+}
+
+// poolShape returns the shape of the output of the pooling of x, whose rows
+// are the channels.
+func poolShape(x Shape, c Conv2DConfig) (Shape, error) {
+	if x.Columns != c.Height*c.Width {
+		return Shape{}, fmt.Errorf("the input %v has %d columns, expected %d", x, x.Columns, c.Height*c.Width)
+	}
+	outRows, outCols := c.OutputSize()
+	return Shape{Rows: x.Rows, Columns: outRows * outCols}, nil
+}
+
+func checkPoolInput(x mat.Matrix, c Conv2DConfig) {
+	if x.Columns() != c.Height*c.Width {
+		panic(fmt.Sprintf("fn: pool2d: the input has %d columns, expected %d", x.Columns(), c.Height*c.Width))
+	}
+}
+
+// MaxPool2D is an operator to perform a 2D max pooling on each channel of an
+// image, with stride and padding. The padded elements are ignored.
+type MaxPool2D struct {
+	x      Operand
+	config Conv2DConfig
+	// initialized during the forward pass
+	argmax []int
+}
+
+// NewMaxPool2D returns a new MaxPool2D Function.
+func NewMaxPool2D(x Operand, config Pool2DConfig) *MaxPool2D {
+	return &MaxPool2D{
+		x:      x,
+		config: config.validate(),
+		argmax: nil,
+	}
+}
+
+// OutputShape returns the shape of the output, given the shape of the operand.
+func (r *MaxPool2D) OutputShape(inputs ...Shape) (Shape, error) {
+	return poolShape(inputs[0], r.config)
+}
+
+// Forward computes the output of the function.
+func (r *MaxPool2D) Forward() mat.Matrix {
+	x := r.x.Value()
+	checkPoolInput(x, r.config)
+	outRows, outCols := r.config.OutputSize()
+	outSize := outRows * outCols
+	kernelSize := r.config.KernelRows * r.config.KernelColumns
+	y := mat.NewInitDense(x.Rows(), outSize, mat.Inf(-1))
+	yData, xData := y.Data(), x.Data()
+	r.argmax = make([]int, y.Size())
+	r.config.forEachPosition(x.Rows(), func(row, col, index int) {
+		k := row/kernelSize*outSize + col
+		if xData[index] > yData[k] {
+			yData[k] = xData[index]
+			r.argmax[k] = index
+		}
+	})
+	return y
+}
+
+// Backward computes the backward pass.
+func (r *MaxPool2D) Backward(gy mat.Matrix) {
+	if gy.Size() != len(r.argmax) {
+		panic("fn: matrices with not compatible size")
+	}
+	if r.x.RequiresGrad() {
+		gx := r.x.Value().ZerosLike()
+		defer mat.ReleaseMatrix(gx)
+		gxData := gx.Data()
+		for k, v := range gy.Data() {
+			gxData[r.argmax[k]] += v
+		}
+		r.x.PropagateGrad(gx)
+	}
+}
+
+// AvgPool2D is an operator to perform a 2D average pooling on each channel of
+// an image, with stride and padding. The padded elements are zeros, and count
+// in the averages.
+type AvgPool2D struct {
+	x      Operand
+	config Conv2DConfig
+}
+
+// NewAvgPool2D returns a new AvgPool2D Function.
+func NewAvgPool2D(x Operand, config Pool2DConfig) *AvgPool2D {
+	return &AvgPool2D{
+		x:      x,
+		config: config.validate(),
+	}
+}
+
+// OutputShape returns the shape of the output, given the shape of the operand.
+func (r *AvgPool2D) OutputShape(inputs ...Shape) (Shape, error) {
+	return poolShape(inputs[0], r.config)
+}
+
+// Forward computes the output of the function.
+func (r *AvgPool2D) Forward() mat.Matrix {
+	x := r.x.Value()
+	checkPoolInput(x, r.config)
+	outRows, outCols := r.config.OutputSize()
+	outSize := outRows * outCols
+	kernelSize := r.config.KernelRows * r.config.KernelColumns
+	y := mat.NewEmptyDense(x.Rows(), outSize)
+	yData, xData := y.Data(), x.Data()
+	r.config.forEachPosition(x.Rows(), func(row, col, index int) {
+		yData[row/kernelSize*outSize+col] += xData[index]
+	})
+	y.ProdScalarInPlace(1 / mat.Float(kernelSize))
+	return y
+}
+
+// Backward computes the backward pass.
+func (r *AvgPool2D) Backward(gy mat.Matrix) {
+	x := r.x.Value()
+	outRows, outCols := r.config.OutputSize()
+	outSize := outRows * outCols
+	if gy.Rows() != x.Rows() || gy.Columns() != outSize {
+		panic("fn: matrices with not compatible size")
+	}
+	if r.x.RequiresGrad() {
+		kernelSize := r.config.KernelRows * r.config.KernelColumns
+		gx := x.ZerosLike()
+		defer mat.ReleaseMatrix(gx)
+		gxData, gyData := gx.Data(), gy.Data()
+		scale := 1 / mat.Float(kernelSize)
+		r.config.forEachPosition(x.Rows(), func(row, col, index int) {
+			gxData[index] += gyData[row/kernelSize*outSize+col] * scale
+		})
+		r.x.PropagateGrad(gx)
+	}
+}
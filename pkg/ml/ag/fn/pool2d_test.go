@@ -0,0 +1,87 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fn
+
+import (
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/stretchr/testify/assert"
+)
+
+func newPool2DTestInput() *variable {
+	return &variable{
+		value: mat.NewDense(2, 16, []mat.Float{
+			0.4, 0.1, -0.9, -0.5, -0.4, 0.3, 0.7, -0.3, 0.8, 0.2, 0.6, 0.7, 0.2, -0.1, 0.6, -0.2,
+			-0.1, -0.2, -0.3, -0.4, -0.5, -0.6, -0.7, -0.8, -0.9, -1.0, -1.1, -1.2, -1.3, -1.4, -1.5, -1.6,
+		}),
+		requiresGrad: true,
+	}
+}
+
+var pool2DTestConfig = Pool2DConfig{
+	Height:         4,
+	Width:          4,
+	KernelRows:     3,
+	KernelColumns:  3,
+	StrideRows:     2,
+	StrideColumns:  2,
+	PaddingRows:    1,
+	PaddingColumns: 1,
+}
+
+func TestMaxPool2D(t *testing.T) {
+	x := newPool2DTestInput()
+	f := NewMaxPool2D(x, pool2DTestConfig)
+	y := f.Forward()
+
+	assert.Equal(t, 2, y.Rows())
+	assert.Equal(t, 4, y.Columns())
+	assert.InDeltaSlice(t, []mat.Float{
+		0.4, 0.7, 0.8, 0.7,
+		-0.1, -0.2, -0.5, -0.6,
+	}, y.Data(), 1.0e-6)
+
+	f.Backward(mat.NewDense(2, 4, []mat.Float{
+		0.1, 0.2, 0.3, 0.4,
+		0.5, 0.6, 0.7, 0.8,
+	}))
+
+	assert.InDeltaSlice(t, []mat.Float{
+		0.1, 0.0, 0.0, 0.0, 0.0, 0.0, 0.6, 0.0, 0.3, 0.0, 0.0, 0.0, 0.0, 0.0, 0.0, 0.0,
+		0.5, 0.6, 0.0, 0.0, 0.7, 0.8, 0.0, 0.0, 0.0, 0.0, 0.0, 0.0, 0.0, 0.0, 0.0, 0.0,
+	}, x.grad.Data(), 1.0e-6)
+}
+
+func TestAvgPool2D(t *testing.T) {
+	x := newPool2DTestInput()
+	f := NewAvgPool2D(x, pool2DTestConfig)
+	y := f.Forward()
+
+	assert.InDeltaSlice(t, []mat.Float{
+		0.0444, -0.0667, 0.1111, 0.2778,
+		-0.1556, -0.3333, -0.6333, -1.1,
+	}, y.Data(), 1.0e-4)
+
+	f.Backward(mat.NewDense(2, 4, []mat.Float{
+		0.1, 0.2, 0.3, 0.4,
+		0.5, 0.6, 0.7, 0.8,
+	}))
+
+	assert.InDeltaSlice(t, []mat.Float{
+		0.0111, 0.0333, 0.0222, 0.0222, 0.0444, 0.1111, 0.0667, 0.0667, 0.0333, 0.0778, 0.0444, 0.0444, 0.0333, 0.0778, 0.0444, 0.0444,
+		0.0556, 0.1222, 0.0667, 0.0667, 0.1333, 0.2889, 0.1556, 0.1556, 0.0778, 0.1667, 0.0889, 0.0889, 0.0778, 0.1667, 0.0889, 0.0889,
+	}, x.grad.Data(), 1.0e-4)
+}
+
+func TestPool2DConfig_DefaultStride(t *testing.T) {
+	rows, cols := Pool2DConfig{Height: 4, Width: 6, KernelRows: 2, KernelColumns: 3}.OutputSize()
+	assert.Equal(t, 2, rows)
+	assert.Equal(t, 2, cols)
+
+	assert.Panics(t, func() {
+		NewMaxPool2D(newPool2DTestInput(), Pool2DConfig{Height: 4, Width: 4, KernelRows: 2, KernelColumns: 2, PaddingRows: 2})
+	})
+}
@@ -21,6 +21,11 @@ func NewPow(x Operand, power mat.Float) *Pow {
 	return &Pow{x: x, power: power}
 }
 
+// OutputShape returns the shape of the output, given the shape of the operand.
+func (r *Pow) OutputShape(inputs ...Shape) (Shape, error) {
+	return inputs[0], nil
+}
+
 // Forward computes the output of the function.
 func (r *Pow) Forward() mat.Matrix {
 	return r.x.Value().Pow(r.power)
@@ -31,6 +31,11 @@ func NewSquare(x Operand) *Square {
 	return &Square{Prod: &Prod{x1: x, x2: x}}
 }
 
+// OutputShape returns the shape of the output, given the shapes of the operands.
+func (r *Prod) OutputShape(inputs ...Shape) (Shape, error) {
+	return elementwiseShape(inputs)
+}
+
 // Forward computes the output of the node.
 func (r *Prod) Forward() mat.Matrix {
 	x1v := r.x1.Value()
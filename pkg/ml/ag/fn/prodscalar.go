@@ -21,6 +21,11 @@ func NewProdScalar(x1, x2 Operand) *ProdScalar {
 	return &ProdScalar{x1: x1, x2: x2}
 }
 
+// OutputShape returns the shape of the output, given the shapes of the operands.
+func (r *ProdScalar) OutputShape(inputs ...Shape) (Shape, error) {
+	return scalarShape(inputs)
+}
+
 // Forward computes the output of the node.
 func (r *ProdScalar) Forward() mat.Matrix {
 	return r.x1.Value().ProdScalar(r.x2.Value().Scalar())
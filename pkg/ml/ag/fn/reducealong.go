@@ -0,0 +1,156 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fn
+
+import (
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+)
+
+var _ Function = &ReduceAlong{}
+
+// reduction is the operation of a ReduceAlong function.
+type reduction int
+
+const (
+	reduceSum reduction = iota
+	reduceMean
+	reduceMax
+	reduceMin
+)
+
+// ReduceAlong is a function reducing each column (AlongColumns) or row
+// (AlongRows) of a matrix to a single value, by sum, mean, max or min.
+//
+// The output is a vector with one value for each column or row. With
+// keepDims, the reduced dimension is kept with size 1 instead, i.e. the
+// output of the reduction AlongColumns is a row vector (1 × columns), while
+// the output of the reduction AlongRows is a column vector (rows × 1) in
+// both cases.
+type ReduceAlong struct {
+	x         Operand
+	axis      Axis
+	keepDims  bool
+	operation reduction
+	// initialized during the forward pass (max and min only)
+	selected []int
+}
+
+// NewReduceSumAlong returns a new ReduceAlong Function summing each column
+// or row of x.
+func NewReduceSumAlong(x Operand, axis Axis, keepDims bool) *ReduceAlong {
+	return newReduceAlong(x, axis, keepDims, reduceSum)
+}
+
+// NewReduceMeanAlong returns a new ReduceAlong Function averaging each column
+// or row of x.
+func NewReduceMeanAlong(x Operand, axis Axis, keepDims bool) *ReduceAlong {
+	return newReduceAlong(x, axis, keepDims, reduceMean)
+}
+
+// NewReduceMaxAlong returns a new ReduceAlong Function selecting the greatest
+// element of each column or row of x.
+func NewReduceMaxAlong(x Operand, axis Axis, keepDims bool) *ReduceAlong {
+	return newReduceAlong(x, axis, keepDims, reduceMax)
+}
+
+// NewReduceMinAlong returns a new ReduceAlong Function selecting the smallest
+// element of each column or row of x.
+func NewReduceMinAlong(x Operand, axis Axis, keepDims bool) *ReduceAlong {
+	return newReduceAlong(x, axis, keepDims, reduceMin)
+}
+
+func newReduceAlong(x Operand, axis Axis, keepDims bool, operation reduction) *ReduceAlong {
+	axis.validate()
+	return &ReduceAlong{x: x, axis: axis, keepDims: keepDims, operation: operation}
+}
+
+// OutputShape returns the shape of the output, given the shape of the operand.
+func (r *ReduceAlong) OutputShape(inputs ...Shape) (Shape, error) {
+	x := inputs[0]
+	if r.axis == AlongRows {
+		return Shape{Rows: x.Rows, Columns: 1}, nil
+	}
+	if r.keepDims {
+		return Shape{Rows: 1, Columns: x.Columns}, nil
+	}
+	return Shape{Rows: x.Columns, Columns: 1}, nil
+}
+
+// Forward computes the output of the function.
+func (r *ReduceAlong) Forward() mat.Matrix {
+	x := r.x.Value()
+	lines := r.axis.lines(x.Dims())
+	var y mat.Matrix
+	if r.keepDims && r.axis == AlongColumns {
+		y = mat.NewEmptyDense(1, len(lines))
+	} else {
+		y = mat.NewEmptyVecDense(len(lines))
+	}
+	if r.operation == reduceMax || r.operation == reduceMin {
+		r.selected = make([]int, len(lines))
+	}
+	xData, yData := x.Data(), y.Data()
+	for i, line := range lines {
+		if len(line) == 0 {
+			if r.operation == reduceMax || r.operation == reduceMin {
+				panic("fn: cannot select from an empty line")
+			}
+			continue
+		}
+		switch r.operation {
+		case reduceSum, reduceMean:
+			var sum mat.Float
+			for _, k := range line {
+				sum += xData[k]
+			}
+			if r.operation == reduceMean {
+				sum /= mat.Float(len(line))
+			}
+			yData[i] = sum
+		case reduceMax, reduceMin:
+			best := line[0]
+			for _, k := range line[1:] {
+				if (r.operation == reduceMax && xData[k] > xData[best]) ||
+					(r.operation == reduceMin && xData[k] < xData[best]) {
+					best = k
+				}
+			}
+			r.selected[i] = best
+			yData[i] = xData[best]
+		}
+	}
+	return y
+}
+
+// Backward computes the backward pass, broadcasting the gradient of each
+// reduced value to the elements of its column or row (sum and mean), or to
+// the selected element (max and min, the first one in case of ties).
+func (r *ReduceAlong) Backward(gy mat.Matrix) {
+	x := r.x.Value()
+	lines := r.axis.lines(x.Dims())
+	if gy.Size() != len(lines) {
+		panic("fn: matrices with not compatible size")
+	}
+	if r.x.RequiresGrad() {
+		gx := x.ZerosLike()
+		defer mat.ReleaseMatrix(gx)
+		gxData, gyData := gx.Data(), gy.Data()
+		for i, line := range lines {
+			switch r.operation {
+			case reduceSum:
+				for _, k := range line {
+					gxData[k] = gyData[i]
+				}
+			case reduceMean:
+				for _, k := range line {
+					gxData[k] = gyData[i] / mat.Float(len(line))
+				}
+			case reduceMax, reduceMin:
+				gxData[r.selected[i]] = gyData[i]
+			}
+		}
+		r.x.PropagateGrad(gx)
+	}
+}
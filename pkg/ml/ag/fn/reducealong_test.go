@@ -0,0 +1,115 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fn
+
+import (
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/stretchr/testify/assert"
+)
+
+func newReduceAlongTestMatrix() *variable {
+	return &variable{
+		value: mat.NewDense(2, 3, []mat.Float{
+			1, 5, 3,
+			4, 2, 6,
+		}),
+		requiresGrad: true,
+	}
+}
+
+func TestReduceAlong_AlongColumns(t *testing.T) {
+	testCases := []struct {
+		f        func(x Operand) *ReduceAlong
+		expected []mat.Float
+		grad     []mat.Float
+	}{
+		{
+			f:        func(x Operand) *ReduceAlong { return NewReduceSumAlong(x, AlongColumns, false) },
+			expected: []mat.Float{5, 7, 9},
+			grad:     []mat.Float{1, 2, 3, 1, 2, 3},
+		},
+		{
+			f:        func(x Operand) *ReduceAlong { return NewReduceMeanAlong(x, AlongColumns, false) },
+			expected: []mat.Float{2.5, 3.5, 4.5},
+			grad:     []mat.Float{0.5, 1, 1.5, 0.5, 1, 1.5},
+		},
+		{
+			f:        func(x Operand) *ReduceAlong { return NewReduceMaxAlong(x, AlongColumns, false) },
+			expected: []mat.Float{4, 5, 6},
+			grad:     []mat.Float{0, 2, 0, 1, 0, 3},
+		},
+		{
+			f:        func(x Operand) *ReduceAlong { return NewReduceMinAlong(x, AlongColumns, false) },
+			expected: []mat.Float{1, 2, 3},
+			grad:     []mat.Float{1, 0, 3, 0, 2, 0},
+		},
+	}
+	for _, tc := range testCases {
+		x := newReduceAlongTestMatrix()
+		f := tc.f(x)
+		y := f.Forward()
+		assert.Equal(t, 3, y.Rows())
+		assert.Equal(t, 1, y.Columns())
+		assert.InDeltaSlice(t, tc.expected, y.Data(), 1.0e-6)
+
+		f.Backward(mat.NewVecDense([]mat.Float{1, 2, 3}))
+		assert.True(t, mat.SameDims(x.value, x.grad))
+		assert.InDeltaSlice(t, tc.grad, x.grad.Data(), 1.0e-6)
+	}
+}
+
+func TestReduceAlong_AlongRows(t *testing.T) {
+	x := newReduceAlongTestMatrix()
+	f := NewReduceMaxAlong(x, AlongRows, true)
+	y := f.Forward()
+	assert.Equal(t, 2, y.Rows())
+	assert.Equal(t, 1, y.Columns())
+	assert.InDeltaSlice(t, []mat.Float{5, 6}, y.Data(), 1.0e-6)
+
+	f.Backward(mat.NewVecDense([]mat.Float{1, 2}))
+	assert.InDeltaSlice(t, []mat.Float{0, 1, 0, 0, 0, 2}, x.grad.Data(), 1.0e-6)
+
+	x = newReduceAlongTestMatrix()
+	f = NewReduceMeanAlong(x, AlongRows, false)
+	assert.InDeltaSlice(t, []mat.Float{3, 4}, f.Forward().Data(), 1.0e-6)
+	f.Backward(mat.NewVecDense([]mat.Float{3, 6}))
+	assert.InDeltaSlice(t, []mat.Float{1, 1, 1, 2, 2, 2}, x.grad.Data(), 1.0e-6)
+}
+
+func TestReduceAlong_KeepDims(t *testing.T) {
+	x := newReduceAlongTestMatrix()
+	f := NewReduceSumAlong(x, AlongColumns, true)
+	y := f.Forward()
+	assert.Equal(t, 1, y.Rows())
+	assert.Equal(t, 3, y.Columns())
+	assert.InDeltaSlice(t, []mat.Float{5, 7, 9}, y.Data(), 1.0e-6)
+
+	f.Backward(mat.NewDense(1, 3, []mat.Float{1, 2, 3}))
+	assert.InDeltaSlice(t, []mat.Float{1, 2, 3, 1, 2, 3}, x.grad.Data(), 1.0e-6)
+
+	assert.Panics(t, func() { f.Backward(mat.NewVecDense([]mat.Float{1, 2})) })
+	assert.Panics(t, func() { NewReduceSumAlong(x, Axis(42), true) })
+}
+
+func TestReduceAlong_OutputShape(t *testing.T) {
+	x := newReduceAlongTestMatrix()
+	for _, tc := range []struct {
+		f        *ReduceAlong
+		expected Shape
+	}{
+		{NewReduceSumAlong(x, AlongColumns, false), Shape{Rows: 3, Columns: 1}},
+		{NewReduceSumAlong(x, AlongColumns, true), Shape{Rows: 1, Columns: 3}},
+		{NewReduceSumAlong(x, AlongRows, false), Shape{Rows: 2, Columns: 1}},
+		{NewReduceSumAlong(x, AlongRows, true), Shape{Rows: 2, Columns: 1}},
+	} {
+		shape, err := tc.f.OutputShape(Shape{Rows: 2, Columns: 3})
+		assert.NoError(t, err)
+		assert.Equal(t, tc.expected, shape)
+		y := tc.f.Forward()
+		assert.Equal(t, tc.expected, Shape{Rows: y.Rows(), Columns: y.Columns()})
+	}
+}
@@ -20,6 +20,11 @@ func NewReduceMean(x Operand) *ReduceMean {
 	return &ReduceMean{x: x}
 }
 
+// OutputShape returns the shape of the output, given the shape of the operand.
+func (r *ReduceMean) OutputShape(inputs ...Shape) (Shape, error) {
+	return Shape{Rows: 1, Columns: 1}, nil
+}
+
 // Forward computes the output of this node.
 func (r *ReduceMean) Forward() mat.Matrix {
 	return mat.NewScalar(r.x.Value().Sum() / mat.Float(r.x.Value().Size()))
@@ -31,7 +36,8 @@ func (r *ReduceMean) Backward(gy mat.Matrix) {
 		panic("fn: the gradient had to be a scalar")
 	}
 	if r.x.RequiresGrad() {
-		gx := mat.NewInitVecDense(r.x.Value().Size(), gy.Scalar()/mat.Float(r.x.Value().Size()))
+		rows, cols := r.x.Value().Dims()
+		gx := mat.NewInitDense(rows, cols, gy.Scalar()/mat.Float(r.x.Value().Size()))
 		defer mat.ReleaseDense(gx)
 		r.x.PropagateGrad(gx)
 	}
@@ -20,6 +20,11 @@ func NewReduceSum(x Operand) *ReduceSum {
 	return &ReduceSum{x: x}
 }
 
+// OutputShape returns the shape of the output, given the shape of the operand.
+func (r *ReduceSum) OutputShape(inputs ...Shape) (Shape, error) {
+	return Shape{Rows: 1, Columns: 1}, nil
+}
+
 // Forward computes the output of this function.
 func (r *ReduceSum) Forward() mat.Matrix {
 	return mat.NewScalar(r.x.Value().Sum())
@@ -31,7 +36,8 @@ func (r *ReduceSum) Backward(gy mat.Matrix) {
 		panic("fn: the gradient had to be a scalar")
 	}
 	if r.x.RequiresGrad() {
-		gx := mat.NewInitVecDense(r.x.Value().Size(), gy.Scalar())
+		rows, cols := r.x.Value().Dims()
+		gx := mat.NewInitDense(rows, cols, gy.Scalar())
 		defer mat.ReleaseDense(gx)
 		r.x.PropagateGrad(gx)
 	}
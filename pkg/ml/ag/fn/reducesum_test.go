@@ -26,3 +26,16 @@ func TestReduceSum_Forward(t *testing.T) {
 
 	assert.InDeltaSlice(t, []mat.Float{0.5, 0.5, 0.5, 0.5}, x.grad.Data(), 1.0e-6)
 }
+
+func TestReduceSum_Matrix(t *testing.T) {
+	x := &variable{
+		value:        mat.NewDense(2, 2, []mat.Float{1, 2, 3, 4}),
+		grad:         nil,
+		requiresGrad: true,
+	}
+	f := NewReduceSum(x)
+	assert.InDeltaSlice(t, []mat.Float{10}, f.Forward().Data(), 1.0e-6)
+	f.Backward(mat.NewScalar(0.5))
+	assert.True(t, mat.SameDims(x.value, x.grad))
+	assert.InDeltaSlice(t, []mat.Float{0.5, 0.5, 0.5, 0.5}, x.grad.Data(), 1.0e-6)
+}
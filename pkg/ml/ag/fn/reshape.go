@@ -5,6 +5,7 @@
 package fn
 
 import (
+	"fmt"
 	mat "github.com/nlpodyssey/spago/pkg/mat32"
 )
 
@@ -23,6 +24,14 @@ func NewReshape(x Operand, r, c int) *Reshape {
 	return &Reshape{x: x, rows: r, cols: c}
 }
 
+// OutputShape returns the shape of the output, given the shape of the operand.
+func (r *Reshape) OutputShape(inputs ...Shape) (Shape, error) {
+	if x := inputs[0]; x.Size() != r.rows*r.cols {
+		return Shape{}, fmt.Errorf("cannot reshape %v to %dx%d", x, r.rows, r.cols)
+	}
+	return Shape{Rows: r.rows, Columns: r.cols}, nil
+}
+
 // Forward computes the output of the node.
 func (r *Reshape) Forward() mat.Matrix {
 	if r.x.Value().Size() != r.rows*r.cols {
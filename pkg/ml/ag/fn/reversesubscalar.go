@@ -19,6 +19,11 @@ func NewReverseSubScalar(x1, x2 Operand) *ReverseSubScalar {
 	return &ReverseSubScalar{x1: x1, x2: x2}
 }
 
+// OutputShape returns the shape of the output, given the shapes of the operands.
+func (r *ReverseSubScalar) OutputShape(inputs ...Shape) (Shape, error) {
+	return scalarShape(inputs)
+}
+
 // Forward computes the output of the function.
 func (r *ReverseSubScalar) Forward() mat.Matrix {
 	return mat.NewInitDense(r.x1.Value().Rows(), r.x1.Value().Columns(), r.x2.Value().Scalar()).Sub(r.x1.Value())
@@ -20,6 +20,11 @@ func NewRotateR(x Operand, i int) *RotateR {
 	return &RotateR{x: x, i: i}
 }
 
+// OutputShape returns the shape of the output, given the shape of the operand.
+func (r *RotateR) OutputShape(inputs ...Shape) (Shape, error) {
+	return Shape{Rows: inputs[0].Size(), Columns: 1}, nil
+}
+
 // Forward computes the output of the function.
 func (r *RotateR) Forward() mat.Matrix {
 	xv := r.x.Value().Data()
@@ -4,7 +4,11 @@
 
 package fn
 
-import mat "github.com/nlpodyssey/spago/pkg/mat32"
+import (
+	"fmt"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+)
 
 var _ Function = &RowView{}
 
@@ -22,6 +26,14 @@ func NewRowView(x Operand, i int) *RowView {
 	return &RowView{x: x, i: i}
 }
 
+// OutputShape returns the shape of the output, given the shape of the operand.
+func (r *RowView) OutputShape(inputs ...Shape) (Shape, error) {
+	if x := inputs[0]; r.i < 0 || r.i >= x.Rows {
+		return Shape{}, fmt.Errorf("row %d out of range of %v", r.i, x)
+	}
+	return Shape{Rows: 1, Columns: inputs[0].Columns}, nil
+}
+
 // Forward computes the output of the function.
 func (r *RowView) Forward() mat.Matrix {
 	xv := r.x.Value()
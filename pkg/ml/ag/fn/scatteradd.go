@@ -0,0 +1,90 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fn
+
+import (
+	"fmt"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+)
+
+var _ Function = &ScatterAdd{}
+
+// ScatterAdd is a function to add the rows of src to the rows of x at the
+// given indices: the k-th row of src is added to the row indices[k] of x.
+// The same index can occur several times, accumulating the rows.
+// With column vectors, it operates on single elements (e.g. to spread the
+// probability mass of the label smoothing).
+type ScatterAdd struct {
+	x       Operand
+	src     Operand
+	indices []int
+}
+
+// NewScatterAdd returns a new ScatterAdd Function.
+func NewScatterAdd(x, src Operand, indices []int) *ScatterAdd {
+	for _, i := range indices {
+		if i < 0 {
+			panic("fn: invalid row index")
+		}
+	}
+	return &ScatterAdd{x: x, src: src, indices: indices}
+}
+
+// OutputShape returns the shape of the output, given the shapes of the operands.
+func (r *ScatterAdd) OutputShape(inputs ...Shape) (Shape, error) {
+	x, src := inputs[0], inputs[1]
+	if src.Rows != len(r.indices) || src.Columns != x.Columns {
+		return Shape{}, fmt.Errorf("%v and %d indices mismatch", src, len(r.indices))
+	}
+	for _, i := range r.indices {
+		if i >= x.Rows {
+			return Shape{}, fmt.Errorf("row %d out of range of %v", i, x)
+		}
+	}
+	return x, nil
+}
+
+// Forward computes the output of the function.
+func (r *ScatterAdd) Forward() mat.Matrix {
+	x, src := r.x.Value(), r.src.Value()
+	rows, cols := x.Dims()
+	if src.Rows() != len(r.indices) || src.Columns() != cols {
+		panic("fn: matrices with not compatible size")
+	}
+	y := x.Clone()
+	yData, srcData := y.Data(), src.Data()
+	for k, i := range r.indices {
+		if i >= rows {
+			panic("fn: row index out of range")
+		}
+		row := yData[i*cols : (i+1)*cols]
+		for j, v := range srcData[k*cols : (k+1)*cols] {
+			row[j] += v
+		}
+	}
+	return y
+}
+
+// Backward computes the backward pass.
+func (r *ScatterAdd) Backward(gy mat.Matrix) {
+	x := r.x.Value()
+	if !mat.SameDims(x, gy) {
+		panic("fn: matrices with not compatible size")
+	}
+	if r.x.RequiresGrad() {
+		r.x.PropagateGrad(gy)
+	}
+	if r.src.RequiresGrad() {
+		cols := x.Columns()
+		gsrc := mat.NewEmptyDense(len(r.indices), cols)
+		defer mat.ReleaseDense(gsrc)
+		gsrcData, gyData := gsrc.Data(), gy.Data()
+		for k, i := range r.indices {
+			copy(gsrcData[k*cols:(k+1)*cols], gyData[i*cols:(i+1)*cols])
+		}
+		r.src.PropagateGrad(gsrc)
+	}
+}
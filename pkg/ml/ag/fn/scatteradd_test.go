@@ -0,0 +1,61 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fn
+
+import (
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScatterAdd_Forward(t *testing.T) {
+	x := &variable{
+		value: mat.NewDense(3, 2, []mat.Float{
+			0.1, 0.2,
+			0.3, 0.4,
+			0.5, 0.6,
+		}),
+		requiresGrad: true,
+	}
+	src := &variable{
+		value: mat.NewDense(3, 2, []mat.Float{
+			1, 2,
+			3, 4,
+			5, 6,
+		}),
+		requiresGrad: true,
+	}
+	f := NewScatterAdd(x, src, []int{2, 0, 2})
+	y := f.Forward()
+
+	assert.InDeltaSlice(t, []mat.Float{
+		3.1, 4.2,
+		0.3, 0.4,
+		6.5, 8.6,
+	}, y.Data(), 1.0e-6)
+	assert.InDeltaSlice(t, []mat.Float{0.1, 0.2, 0.3, 0.4, 0.5, 0.6}, x.value.Data(), 1.0e-6)
+
+	f.Backward(mat.NewDense(3, 2, []mat.Float{
+		1, 2,
+		3, 4,
+		5, 6,
+	}))
+	assert.InDeltaSlice(t, []mat.Float{1, 2, 3, 4, 5, 6}, x.grad.Data(), 1.0e-6)
+	assert.InDeltaSlice(t, []mat.Float{
+		5, 6,
+		1, 2,
+		5, 6,
+	}, src.grad.Data(), 1.0e-6)
+}
+
+func TestScatterAdd_Elements(t *testing.T) {
+	x := &variable{value: mat.NewVecDense([]mat.Float{0, 0, 0, 0}), requiresGrad: true}
+	src := &variable{value: mat.NewVecDense([]mat.Float{0.5, 0.25, 0.25}), requiresGrad: true}
+	y := NewScatterAdd(x, src, []int{3, 1, 3}).Forward()
+	assert.InDeltaSlice(t, []mat.Float{0, 0.25, 0, 0.75}, y.Data(), 1.0e-6)
+
+	assert.Panics(t, func() { NewScatterAdd(x, src, []int{0, 1}).Forward() })
+}
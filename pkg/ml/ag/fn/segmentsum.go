@@ -0,0 +1,78 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fn
+
+import (
+	"fmt"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+)
+
+var _ Function = &SegmentSum{}
+
+// SegmentSum is a function to sum the rows of a matrix by segment: the k-th
+// row of x is added to the row segments[k] of the output, which has one row
+// for each of the n segments (the empty ones are zeros).
+// With column vectors, it sums single elements (e.g. to pool the tokens of
+// the words, or the sequences of a length bucket).
+type SegmentSum struct {
+	x        Operand
+	segments []int
+	n        int
+}
+
+// NewSegmentSum returns a new SegmentSum Function.
+func NewSegmentSum(x Operand, segments []int, n int) *SegmentSum {
+	for _, s := range segments {
+		if s < 0 || s >= n {
+			panic("fn: segment index out of range")
+		}
+	}
+	return &SegmentSum{x: x, segments: segments, n: n}
+}
+
+// OutputShape returns the shape of the output, given the shape of the operand.
+func (r *SegmentSum) OutputShape(inputs ...Shape) (Shape, error) {
+	if x := inputs[0]; x.Rows != len(r.segments) {
+		return Shape{}, fmt.Errorf("%v and %d segments mismatch", x, len(r.segments))
+	}
+	return Shape{Rows: r.n, Columns: inputs[0].Columns}, nil
+}
+
+// Forward computes the output of the function.
+func (r *SegmentSum) Forward() mat.Matrix {
+	x := r.x.Value()
+	if x.Rows() != len(r.segments) {
+		panic("fn: the number of segments must match the rows of the input")
+	}
+	cols := x.Columns()
+	y := mat.NewEmptyDense(r.n, cols)
+	yData, xData := y.Data(), x.Data()
+	for k, s := range r.segments {
+		row := yData[s*cols : (s+1)*cols]
+		for j, v := range xData[k*cols : (k+1)*cols] {
+			row[j] += v
+		}
+	}
+	return y
+}
+
+// Backward computes the backward pass.
+func (r *SegmentSum) Backward(gy mat.Matrix) {
+	x := r.x.Value()
+	cols := x.Columns()
+	if gy.Rows() != r.n || gy.Columns() != cols {
+		panic("fn: matrices with not compatible size")
+	}
+	if r.x.RequiresGrad() {
+		gx := x.ZerosLike()
+		defer mat.ReleaseMatrix(gx)
+		gxData, gyData := gx.Data(), gy.Data()
+		for k, s := range r.segments {
+			copy(gxData[k*cols:(k+1)*cols], gyData[s*cols:(s+1)*cols])
+		}
+		r.x.PropagateGrad(gx)
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fn
+
+import (
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSegmentSum_Forward(t *testing.T) {
+	x := &variable{
+		value: mat.NewDense(4, 2, []mat.Float{
+			1, 2,
+			3, 4,
+			5, 6,
+			7, 8,
+		}),
+		requiresGrad: true,
+	}
+	f := NewSegmentSum(x, []int{1, 0, 1, 1}, 3)
+	y := f.Forward()
+	assert.Equal(t, 3, y.Rows())
+	assert.InDeltaSlice(t, []mat.Float{
+		3, 4,
+		13, 16,
+		0, 0,
+	}, y.Data(), 1.0e-6)
+
+	f.Backward(mat.NewDense(3, 2, []mat.Float{
+		1, 2,
+		3, 4,
+		5, 6,
+	}))
+	assert.InDeltaSlice(t, []mat.Float{
+		3, 4,
+		1, 2,
+		3, 4,
+		3, 4,
+	}, x.grad.Data(), 1.0e-6)
+
+	assert.Panics(t, func() { NewSegmentSum(x, []int{0, 3}, 3) })
+	assert.Panics(t, func() { NewSegmentSum(x, []int{0, 1}, 3).Forward() })
+}
@@ -22,6 +22,11 @@ func NewSELU(x, alpha, scale Operand) *SELU {
 	return &SELU{x: x, alpha: alpha, scale: scale}
 }
 
+// OutputShape returns the shape of the output, given the shapes of the operands.
+func (r *SELU) OutputShape(inputs ...Shape) (Shape, error) {
+	return scalarShape(inputs)
+}
+
 // Forward computes the output of the function.
 func (r *SELU) Forward() mat.Matrix {
 	y := mat.GetDenseWorkspace(r.x.Value().Dims())
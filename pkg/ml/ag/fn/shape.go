@@ -0,0 +1,76 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fn
+
+import (
+	"fmt"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+)
+
+// Shape is the shape of a matrix.
+type Shape struct {
+	Rows, Columns int
+}
+
+// Size returns the number of elements of a matrix of this shape.
+func (s Shape) Size() int {
+	return s.Rows * s.Columns
+}
+
+// IsVector returns whether the shape is the one of a (row or column) vector.
+func (s Shape) IsVector() bool {
+	return s.Rows == 1 || s.Columns == 1
+}
+
+// String returns the shape in the form "rowsxcolumns" (e.g. "12x768").
+func (s Shape) String() string {
+	return fmt.Sprintf("%dx%d", s.Rows, s.Columns)
+}
+
+// ShapeInferrer is implemented by the functions which can infer the shape of
+// their output from the shapes of their operands, without computing it, so
+// that the shapes can be validated when the graph is defined (see
+// ag.WithShapeChecking).
+type ShapeInferrer interface {
+	// OutputShape returns the shape of the output, given the shapes of the
+	// operands in order, or an error describing why they are not compatible.
+	OutputShape(inputs ...Shape) (Shape, error)
+}
+
+// elementwiseShape returns the shape of the output of an element-wise
+// function of two operands, which must have the same shape or be vectors of
+// the same size. A single operand is used as both.
+func elementwiseShape(inputs []Shape) (Shape, error) {
+	if len(inputs) == 1 {
+		return inputs[0], nil
+	}
+	a, b := inputs[0], inputs[1]
+	if a == b || (a.IsVector() && b.IsVector() && a.Size() == b.Size()) {
+		return a, nil
+	}
+	return Shape{}, fmt.Errorf("%v and %v mismatch", a, b)
+}
+
+// scalarShape returns the shape of the output of a function of an operand and
+// one or more scalars (e.g. the parameters of an activation), which is the
+// shape of the operand.
+func scalarShape(inputs []Shape) (Shape, error) {
+	for _, s := range inputs[1:] {
+		if s.Size() != 1 {
+			return Shape{}, fmt.Errorf("%v is not a scalar", s)
+		}
+	}
+	return inputs[0], nil
+}
+
+// maskedShape returns the shape of the output of a function applying a mask
+// to the operand, which must have the same size.
+func maskedShape(x Shape, mask mat.Matrix) (Shape, error) {
+	if mask.Size() != x.Size() {
+		return Shape{}, fmt.Errorf("%v and mask of size %d mismatch", x, mask.Size())
+	}
+	return x, nil
+}
@@ -0,0 +1,120 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fn
+
+import (
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShape(t *testing.T) {
+	s := Shape{Rows: 12, Columns: 768}
+	assert.Equal(t, "12x768", s.String())
+	assert.Equal(t, 12*768, s.Size())
+	assert.False(t, s.IsVector())
+	assert.True(t, Shape{Rows: 3, Columns: 1}.IsVector())
+	assert.True(t, Shape{Rows: 1, Columns: 3}.IsVector())
+}
+
+func TestShapeInferrer_OutputShape(t *testing.T) {
+	x := &variable{value: mat.NewEmptyDense(2, 3)}
+	for _, tc := range []struct {
+		name     string
+		f        Function
+		inputs   []Shape
+		expected Shape
+	}{
+		{"Add", NewAdd(x, x), []Shape{{2, 3}, {2, 3}}, Shape{2, 3}},
+		{"Prod vectors", NewProd(x, x), []Shape{{3, 1}, {1, 3}}, Shape{3, 1}},
+		{"Square", NewSquare(x), []Shape{{2, 3}}, Shape{2, 3}},
+		{"ProdScalar", NewProdScalar(x, x), []Shape{{2, 3}, {1, 1}}, Shape{2, 3}},
+		{"Mul", NewMul(x, x), []Shape{{2, 3}, {3, 4}}, Shape{2, 4}},
+		{"MatMulT", NewMatMulT(x, x), []Shape{{2, 3}, {4, 3}}, Shape{2, 4}},
+		{"TMatMul", NewTMatMul(x, x), []Shape{{3, 2}, {3, 4}}, Shape{2, 4}},
+		{"Dot", NewDot(x, x), []Shape{{2, 3}, {2, 3}}, Shape{1, 1}},
+		{"Reshape", NewReshape(x, 3, 2), []Shape{{2, 3}}, Shape{3, 2}},
+		{"Transpose", NewTranspose(x), []Shape{{2, 3}}, Shape{3, 2}},
+		{"Vec", NewVec(x), []Shape{{2, 3}}, Shape{6, 1}},
+		{"ReduceSum", NewReduceSum(x), []Shape{{2, 3}}, Shape{1, 1}},
+		{"Softmax", NewSoftmax(x), []Shape{{1, 3}}, Shape{3, 1}},
+		{"RowView", NewRowView(x, 1), []Shape{{2, 3}}, Shape{1, 3}},
+		{"ColView", NewColView(x, 2), []Shape{{2, 3}}, Shape{1, 2}},
+		{"View", NewView(x, 1, 1, 1, 2), []Shape{{2, 3}}, Shape{1, 2}},
+		{"Concat", NewConcat([]Operand{x, x}), []Shape{{2, 1}, {1, 3}}, Shape{5, 1}},
+		{"Stack", NewStack([]Operand{x, x}), []Shape{{3, 1}, {3, 1}}, Shape{2, 3}},
+		{"Tanh", NewTanh(x), []Shape{{2, 3}}, Shape{2, 3}},
+		{"Solve", NewSolve(x, x), []Shape{{3, 3}, {3, 2}}, Shape{3, 2}},
+		{"PInv", NewPInv(x), []Shape{{2, 3}}, Shape{3, 2}},
+		{"ArgMax", NewArgMax(x, AlongRows), []Shape{{2, 3}}, Shape{2, 1}},
+		{"OneHot", NewOneHot(x, 4, AlongColumns), []Shape{{1, 1}}, Shape{4, 1}},
+		{"ELU", NewELU(x, x), []Shape{{2, 3}, {1, 1}}, Shape{2, 3}},
+		{"SoftPlus", NewSoftPlus(x, x, x), []Shape{{2, 3}, {1, 1}, {1, 1}}, Shape{2, 3}},
+		{"ToDevice", NewToDevice(x, mat.Host), []Shape{{2, 3}}, Shape{2, 3}},
+		{"CumSum", NewCumSum(x, AlongRows), []Shape{{2, 3}}, Shape{2, 3}},
+		{"Sort", NewSort(x, false), []Shape{{2, 3}}, Shape{6, 1}},
+		{"TopK", NewTopK(x, 4), []Shape{{1, 3}}, Shape{3, 1}},
+		{"SparseMax", NewSparseMax(x), []Shape{{1, 3}}, Shape{3, 1}},
+		{"Gather", NewGather(x, []int{5, 0}), []Shape{{2, 3}}, Shape{2, 1}},
+		{"IndexSelect", NewIndexSelect(x, []int{1, 1, 0}), []Shape{{2, 3}}, Shape{3, 3}},
+		{"ScatterAdd", NewScatterAdd(x, x, []int{1}), []Shape{{2, 3}, {1, 3}}, Shape{2, 3}},
+		{"SegmentSum", NewSegmentSum(x, []int{0, 2}, 3), []Shape{{2, 3}}, Shape{3, 3}},
+		{"MaskedFill", NewMaskedFill(x, mat.NewEmptyDense(3, 2), 0), []Shape{{2, 3}}, Shape{2, 3}},
+		{"MaskedMean", NewMaskedMean(x, mat.NewEmptyVecDense(6)), []Shape{{2, 3}}, Shape{1, 1}},
+		{"Where", NewWhere(x, x, x), []Shape{{2, 3}, {2, 3}, {1, 1}}, Shape{2, 3}},
+		{"MaxPooling", NewMaxPooling(x, 2, 2), []Shape{{4, 6}}, Shape{2, 3}},
+		{"Conv2D", NewConv2D(x, x, x, Conv2DConfig{Height: 4, Width: 4, KernelRows: 3, KernelColumns: 3}), []Shape{{2, 16}, {5, 18}, {5, 1}}, Shape{5, 4}},
+		{"AvgPool2D", NewAvgPool2D(x, Pool2DConfig{Height: 4, Width: 4, KernelRows: 2, KernelColumns: 2}), []Shape{{3, 16}}, Shape{3, 4}},
+		{"LongConv", NewLongConv(x, x), []Shape{{2, 3}, {2, 8}}, Shape{2, 3}},
+		{"AffineActivation", NewAffineActivation(x, x, x, NewTanh(x).UnaryElementwise), []Shape{{4, 1}, {4, 3}, {3, 1}}, Shape{4, 1}},
+		{"LayerNorm", NewLayerNorm(x, x, x, x), []Shape{{3, 1}, {3, 1}, {1, 3}, {1, 1}}, Shape{3, 1}},
+		{"SoftmaxCrossEntropy", NewSoftmaxCrossEntropy(x, 2), []Shape{{3, 1}}, Shape{1, 1}},
+		{"Einsum", NewEinsum("bij,bjk->bik", x, x), []Shape{{4, 3}, {6, 2}}, Shape{4, 2}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := tc.f.(ShapeInferrer).OutputShape(tc.inputs...)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, s)
+		})
+	}
+}
+
+func TestShapeInferrer_OutputShape_Errors(t *testing.T) {
+	x := &variable{value: mat.NewEmptyDense(2, 3)}
+	for _, tc := range []struct {
+		f        Function
+		inputs   []Shape
+		expected string
+	}{
+		{NewAdd(x, x), []Shape{{2, 3}, {3, 2}}, "2x3 and 3x2 mismatch"},
+		{NewAddScalar(x, x), []Shape{{2, 3}, {2, 1}}, "2x1 is not a scalar"},
+		{NewMul(x, x), []Shape{{12, 768}, {64, 768}}, "12x768 · 64x768 mismatch"},
+		{NewReshape(x, 4, 2), []Shape{{2, 3}}, "cannot reshape 2x3 to 4x2"},
+		{NewAt(x, 2, 0), []Shape{{2, 3}}, "index (2, 0) out of range of 2x3"},
+		{NewRowView(x, 2), []Shape{{2, 3}}, "row 2 out of range of 2x3"},
+		{NewConcat([]Operand{x}), []Shape{{2, 3}}, "2x3 is not a vector"},
+		{NewStack([]Operand{x, x}), []Shape{{3, 1}, {2, 1}}, "3x1 and 2x1 mismatch"},
+		{NewInverse(x), []Shape{{2, 3}}, "2x3 is not square"},
+		{NewSELU(x, x, x), []Shape{{2, 3}, {1, 1}, {2, 1}}, "2x1 is not a scalar"},
+		{NewGather(x, []int{6}), []Shape{{2, 3}}, "index 6 out of range of 2x3"},
+		{NewIndexSelect(x, []int{2}), []Shape{{2, 3}}, "row 2 out of range of 2x3"},
+		{NewScatterAdd(x, x, []int{0}), []Shape{{2, 3}, {1, 2}}, "1x2 and 1 indices mismatch"},
+		{NewSegmentSum(x, []int{0}, 1), []Shape{{2, 3}}, "2x3 and 1 segments mismatch"},
+		{NewMaskedSoftmax(x, mat.NewEmptyVecDense(2)), []Shape{{3, 1}}, "3x1 and mask of size 2 mismatch"},
+		{NewWhere(x, x, x), []Shape{{2, 3}, {3, 2}, {1, 1}}, "2x3 and 3x2 mismatch"},
+		{NewMaxPooling(x, 2, 2), []Shape{{3, 4}}, "3x4 is not divisible in 2x2 windows"},
+		{NewConv2D(x, x, nil, Conv2DConfig{Height: 4, Width: 4, KernelRows: 3, KernelColumns: 3}), []Shape{{2, 16}, {5, 9}}, "the weights 5x9 have 9 columns, expected 18"},
+		{NewMaxPool2D(x, Pool2DConfig{Height: 4, Width: 4, KernelRows: 2, KernelColumns: 2}), []Shape{{3, 15}}, "the input 3x15 has 15 columns, expected 16"},
+		{NewLongConv(x, x), []Shape{{2, 3}, {2, 2}}, "the kernels 2x2 don't match the input 2x3"},
+		{NewAffineActivation(x, x, x, NewTanh(x).UnaryElementwise), []Shape{{4, 1}, {4, 3}, {2, 1}}, "4x3 · 2x1 mismatch"},
+		{NewSoftmaxCrossEntropy(x, 3), []Shape{{3, 1}}, "target 3 out of range of 3x1"},
+		{NewEinsum("bij->bji", x), []Shape{{4, 3}}, "can't infer the size of subscript \"b\""},
+		{NewEinsum("ij,jk->ik", x, x), []Shape{{2, 3}, {2, 3}}, "operand \"jk\" expects 3 rows, found 2x3"},
+	} {
+		_, err := tc.f.(ShapeInferrer).OutputShape(tc.inputs...)
+		assert.EqualError(t, err, tc.expected)
+	}
+}
@@ -21,8 +21,17 @@ func NewSoftmax(x Operand) *Softmax {
 	return &Softmax{x: x}
 }
 
+// OutputShape returns the shape of the output, given the shape of the operand.
+func (r *Softmax) OutputShape(inputs ...Shape) (Shape, error) {
+	return Shape{Rows: inputs[0].Size(), Columns: 1}, nil
+}
+
 // Forward computes the output of this function.
 func (r *Softmax) Forward() mat.Matrix {
+	if x, ok := r.x.Value().(*mat.Dense); ok {
+		r.y = x.Softmax() // computed on the device of x
+		return r.y
+	}
 	r.y = mat.NewVecDense(softmax(r.x.Value().Data()))
 	return r.y
 }
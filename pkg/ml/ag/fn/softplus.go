@@ -22,6 +22,11 @@ func NewSoftPlus(x, beta, threshold Operand) *SoftPlus {
 	return &SoftPlus{x: x, beta: beta, threshold: threshold}
 }
 
+// OutputShape returns the shape of the output, given the shapes of the operands.
+func (r *SoftPlus) OutputShape(inputs ...Shape) (Shape, error) {
+	return scalarShape(inputs)
+}
+
 // Forward computes the output of the function.
 func (r *SoftPlus) Forward() mat.Matrix {
 	y := mat.GetDenseWorkspace(r.x.Value().Dims())
@@ -21,6 +21,11 @@ func NewSoftShrink(x, lambda Operand) *SoftShrink {
 	return &SoftShrink{x: x, lambda: lambda}
 }
 
+// OutputShape returns the shape of the output, given the shapes of the operands.
+func (r *SoftShrink) OutputShape(inputs ...Shape) (Shape, error) {
+	return scalarShape(inputs)
+}
+
 // Forward computes the output of the function.
 func (r *SoftShrink) Forward() mat.Matrix {
 	y := mat.GetDenseWorkspace(r.x.Value().Dims())
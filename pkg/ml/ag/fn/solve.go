@@ -0,0 +1,76 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fn
+
+import (
+	"fmt"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+)
+
+var _ Function = &Solve{}
+
+// Solve is an operator to solve the linear system a × y = b, where a is a
+// square non-singular matrix and the columns of b are the right-hand sides
+// (i.e. y = a⁻¹ × b).
+type Solve struct {
+	a   Operand
+	b   Operand
+	inv mat.Matrix // the inverse of a, computed by the forward
+}
+
+// NewSolve returns a new Solve Function.
+func NewSolve(a, b Operand) *Solve {
+	return &Solve{a: a, b: b}
+}
+
+// OutputShape returns the shape of the output, given the shapes of the operands.
+func (r *Solve) OutputShape(inputs ...Shape) (Shape, error) {
+	a, b := inputs[0], inputs[1]
+	if a.Rows != a.Columns {
+		return Shape{}, fmt.Errorf("%v is not square", a)
+	}
+	if a.Rows != b.Rows {
+		return Shape{}, fmt.Errorf("%v and %v mismatch", a, b)
+	}
+	return b, nil
+}
+
+// Forward computes the output of the function.
+func (r *Solve) Forward() mat.Matrix {
+	a, b := r.a.Value(), r.b.Value()
+	if a.Rows() != a.Columns() {
+		panic("fn: the matrix of the system must be square")
+	}
+	if a.Rows() != b.Rows() {
+		panic("fn: matrices with not compatible size")
+	}
+	r.inv = a.Inverse()
+	return r.inv.Mul(b)
+}
+
+// Backward computes the backward pass.
+func (r *Solve) Backward(gy mat.Matrix) {
+	if !(r.a.Value().Rows() == gy.Rows() && r.b.Value().Columns() == gy.Columns()) {
+		panic("fn: matrices with not compatible size")
+	}
+	if !r.a.RequiresGrad() && !r.b.RequiresGrad() {
+		return
+	}
+	// gb = a⁻ᵀ × gy
+	gb := r.inv.TMatMul(gy)
+	defer mat.ReleaseMatrix(gb)
+	if r.a.RequiresGrad() {
+		// ga = -gb × yᵀ
+		y := r.inv.Mul(r.b.Value())
+		defer mat.ReleaseMatrix(y)
+		ga := gb.MatMulT(y).ProdScalarInPlace(-1)
+		defer mat.ReleaseMatrix(ga)
+		r.a.PropagateGrad(ga)
+	}
+	if r.b.RequiresGrad() {
+		r.b.PropagateGrad(gb)
+	}
+}
@@ -0,0 +1,45 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fn
+
+import (
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSolve_Forward(t *testing.T) {
+	a := &variable{
+		value: mat.NewDense(2, 2, []mat.Float{
+			2, 1,
+			1, 3,
+		}),
+		requiresGrad: true,
+	}
+	b := &variable{
+		value:        mat.NewVecDense([]mat.Float{1, 2}),
+		requiresGrad: true,
+	}
+	f := NewSolve(a, b)
+	y := f.Forward()
+
+	assert.InDeltaSlice(t, []mat.Float{0.2, 0.6}, y.Data(), 1.0e-6)
+
+	f.Backward(mat.NewVecDense([]mat.Float{1, -1}))
+	// gb = a⁻ᵀ × gy
+	assert.InDeltaSlice(t, []mat.Float{0.8, -0.6}, b.grad.Data(), 1.0e-6)
+	// ga = -gb × yᵀ
+	assert.InDeltaSlice(t, []mat.Float{
+		-0.16, -0.48,
+		0.12, 0.36,
+	}, a.grad.Data(), 1.0e-6)
+}
+
+func TestSolve_Panics(t *testing.T) {
+	b := &variable{value: mat.NewEmptyVecDense(2)}
+	assert.Panics(t, func() { NewSolve(&variable{value: mat.NewEmptyDense(2, 3)}, b).Forward() })
+	assert.Panics(t, func() { NewSolve(&variable{value: mat.NewEmptyDense(3, 3)}, b).Forward() })
+}
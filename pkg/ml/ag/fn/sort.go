@@ -0,0 +1,48 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fn
+
+import (
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+)
+
+var _ Function = &Sort{}
+
+// Sort is a function to sort the values of the input matrix, in ascending or
+// descending order, returning them as a column vector.
+// The gradients are routed back to the original position of each element.
+type Sort struct {
+	x          Operand
+	descending bool
+	indices    []int // initialized during the forward pass
+}
+
+// NewSort returns a new Sort Function.
+func NewSort(x Operand, descending bool) *Sort {
+	return &Sort{x: x, descending: descending}
+}
+
+// Indices returns the original indices of the sorted elements (data in
+// row-major order). It is available after the forward pass.
+func (r *Sort) Indices() []int {
+	return r.indices
+}
+
+// OutputShape returns the shape of the output, given the shape of the operand.
+func (r *Sort) OutputShape(inputs ...Shape) (Shape, error) {
+	return Shape{Rows: inputs[0].Size(), Columns: 1}, nil
+}
+
+// Forward computes the output of the function.
+func (r *Sort) Forward() mat.Matrix {
+	xv := r.x.Value()
+	r.indices = mat.ArgSort(xv, r.descending)
+	return mat.Gather(xv, r.indices)
+}
+
+// Backward computes the backward pass.
+func (r *Sort) Backward(gy mat.Matrix) {
+	scatterGrad(r.x, r.indices, gy)
+}
@@ -0,0 +1,42 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fn
+
+import (
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSort_Forward(t *testing.T) {
+	x := &variable{
+		value:        mat.NewVecDense([]mat.Float{0.1, 0.9, -0.4, 0.5}),
+		grad:         nil,
+		requiresGrad: true,
+	}
+
+	f := NewSort(x, false)
+	y := f.Forward()
+
+	assert.InDeltaSlice(t, []mat.Float{-0.4, 0.1, 0.5, 0.9}, y.Data(), 1.0e-6)
+	assert.Equal(t, []int{2, 0, 3, 1}, f.Indices())
+
+	f.Backward(mat.NewVecDense([]mat.Float{1.0, 2.0, 3.0, 4.0}))
+
+	assert.InDeltaSlice(t, []mat.Float{2.0, 4.0, 1.0, 3.0}, x.grad.Data(), 1.0e-6)
+}
+
+func TestSort_ForwardDescending(t *testing.T) {
+	x := &variable{
+		value:        mat.NewVecDense([]mat.Float{0.1, 0.9, -0.4, 0.5}),
+		grad:         nil,
+		requiresGrad: true,
+	}
+
+	y := NewSort(x, true).Forward()
+
+	assert.InDeltaSlice(t, []mat.Float{0.9, 0.5, 0.1, -0.4}, y.Data(), 1.0e-6)
+}
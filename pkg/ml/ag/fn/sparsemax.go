@@ -25,6 +25,11 @@ func NewSparseMax(x Operand) *SparseMax {
 	return &SparseMax{x: x}
 }
 
+// OutputShape returns the shape of the output, given the shape of the operand.
+func (s *SparseMax) OutputShape(inputs ...Shape) (Shape, error) {
+	return Shape{Rows: inputs[0].Size(), Columns: 1}, nil
+}
+
 // Forward computes the output of the function.
 func (s *SparseMax) Forward() mat.Matrix {
 	s.y = mat.NewVecDense(sparseMax(translateInput(s.x.Value().Data())))
@@ -141,6 +146,11 @@ func sparseMaxLoss(v []mat.Float) ([]mat.Float, mat.Float) {
 	return zs, tau
 }
 
+// OutputShape returns the shape of the output, given the shape of the operand.
+func (s *SparseMaxLoss) OutputShape(inputs ...Shape) (Shape, error) {
+	return Shape{Rows: inputs[0].Size(), Columns: 1}, nil
+}
+
 // Forward computes the output of the function.
 func (s *SparseMaxLoss) Forward() mat.Matrix {
 	output, tau := sparseMaxLoss(s.x.Value().Data())
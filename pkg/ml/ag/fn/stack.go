@@ -4,7 +4,11 @@
 
 package fn
 
-import mat "github.com/nlpodyssey/spago/pkg/mat32"
+import (
+	"fmt"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+)
 
 var _ Function = &Stack{}
 
@@ -19,6 +23,19 @@ func NewStack(xs []Operand) *Stack {
 	return &Stack{xs: xs}
 }
 
+// OutputShape returns the shape of the output, given the shapes of the operands.
+func (r *Stack) OutputShape(inputs ...Shape) (Shape, error) {
+	if len(inputs) == 0 {
+		return Shape{}, fmt.Errorf("no operands")
+	}
+	for _, x := range inputs[1:] {
+		if x.Size() != inputs[0].Size() {
+			return Shape{}, fmt.Errorf("%v and %v mismatch", inputs[0], x)
+		}
+	}
+	return Shape{Rows: len(inputs), Columns: inputs[0].Size()}, nil
+}
+
 // Forward computes the output of the function.
 func (r *Stack) Forward() mat.Matrix {
 	vs := make([]mat.Matrix, len(r.xs))
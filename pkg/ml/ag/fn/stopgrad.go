@@ -0,0 +1,38 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fn
+
+import mat "github.com/nlpodyssey/spago/pkg/mat32"
+
+var _ Function = &StopGrad{}
+
+// StopGrad is an operator to perform the identity function, without
+// propagating the gradients to its operand.
+// y = x
+type StopGrad struct {
+	x Operand
+}
+
+// NewStopGrad returns a new StopGrad Function.
+func NewStopGrad(x Operand) *StopGrad {
+	return &StopGrad{x: x}
+}
+
+// OutputShape returns the shape of the output, given the shape of the operand.
+func (r *StopGrad) OutputShape(inputs ...Shape) (Shape, error) {
+	return inputs[0], nil
+}
+
+// Forward computes the output of the function.
+func (r *StopGrad) Forward() mat.Matrix {
+	return r.x.Value().Clone()
+}
+
+// Backward does nothing, the gradients are not propagated.
+func (r *StopGrad) Backward(gy mat.Matrix) {
+	if !(mat.SameDims(r.x.Value(), gy) || mat.VectorsOfSameSize(r.x.Value(), gy)) {
+		panic("fn: matrices with not compatible size")
+	}
+}
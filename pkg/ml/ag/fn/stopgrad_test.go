@@ -0,0 +1,31 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fn
+
+import (
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStopGrad_Forward(t *testing.T) {
+	x := &variable{
+		value:        mat.NewVecDense([]mat.Float{0.1, 0.2, 0.3, 0.0}),
+		grad:         nil,
+		requiresGrad: true,
+	}
+
+	f := NewStopGrad(x)
+	y := f.Forward()
+
+	assert.InDeltaSlice(t, []mat.Float{0.1, 0.2, 0.3, 0.0}, y.Data(), 1.0e-6)
+	assert.NotSame(t, x.value, y)
+
+	f.Backward(mat.NewVecDense([]mat.Float{-1.0, 0.5, 0.8, 0.0}))
+
+	assert.Nil(t, x.grad)
+	assert.Panics(t, func() { f.Backward(mat.NewVecDense([]mat.Float{1.0})) })
+}
@@ -0,0 +1,52 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fn
+
+import mat "github.com/nlpodyssey/spago/pkg/mat32"
+
+var _ Function = &StraightThrough{}
+
+// StraightThrough is an operator implementing the straight-through estimator:
+// the output is the value of x (e.g. a discrete selection, not
+// differentiable), while the gradients are passed through to the surrogate
+// (e.g. the soft probabilities the selection has been made from), as if the
+// output were the surrogate itself.
+// y = x
+type StraightThrough struct {
+	x         Operand
+	surrogate Operand
+}
+
+// NewStraightThrough returns a new StraightThrough Function.
+func NewStraightThrough(x, surrogate Operand) *StraightThrough {
+	return &StraightThrough{x: x, surrogate: surrogate}
+}
+
+// OutputShape returns the shape of the output, given the shapes of the operands.
+func (r *StraightThrough) OutputShape(inputs ...Shape) (Shape, error) {
+	if _, err := elementwiseShape(inputs); err != nil {
+		return Shape{}, err
+	}
+	return inputs[0], nil
+}
+
+// Forward computes the output of the function.
+func (r *StraightThrough) Forward() mat.Matrix {
+	if !(mat.SameDims(r.x.Value(), r.surrogate.Value()) || mat.VectorsOfSameSize(r.x.Value(), r.surrogate.Value())) {
+		panic("fn: matrices with not compatible size")
+	}
+	return r.x.Value().Clone()
+}
+
+// Backward computes the backward pass, propagating the gradients to the
+// surrogate only.
+func (r *StraightThrough) Backward(gy mat.Matrix) {
+	if !(mat.SameDims(r.surrogate.Value(), gy) || mat.VectorsOfSameSize(r.surrogate.Value(), gy)) {
+		panic("fn: matrices with not compatible size")
+	}
+	if r.surrogate.RequiresGrad() {
+		r.surrogate.PropagateGrad(gy)
+	}
+}
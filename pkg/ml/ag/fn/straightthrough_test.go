@@ -0,0 +1,37 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fn
+
+import (
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStraightThrough_Forward(t *testing.T) {
+	x := &variable{
+		value:        mat.NewVecDense([]mat.Float{0, 1, 0}),
+		requiresGrad: true,
+	}
+	surrogate := &variable{
+		value:        mat.NewVecDense([]mat.Float{0.2, 0.5, 0.3}),
+		requiresGrad: true,
+	}
+	f := NewStraightThrough(x, surrogate)
+	y := f.Forward()
+	assert.Equal(t, []mat.Float{0, 1, 0}, y.Data())
+
+	f.Backward(mat.NewVecDense([]mat.Float{1, -2, 3}))
+	assert.Nil(t, x.grad)
+	assert.Equal(t, []mat.Float{1, -2, 3}, surrogate.grad.Data())
+}
+
+func TestStraightThrough_Panics(t *testing.T) {
+	x := &variable{value: mat.NewEmptyVecDense(3)}
+	surrogate := &variable{value: mat.NewEmptyVecDense(2)}
+	assert.Panics(t, func() { NewStraightThrough(x, surrogate).Forward() })
+	assert.Panics(t, func() { NewStraightThrough(x, surrogate).Backward(mat.NewEmptyVecDense(3)) })
+}
@@ -21,6 +21,11 @@ func NewSub(x1, x2 Operand) *Sub {
 	return &Sub{x1: x1, x2: x2}
 }
 
+// OutputShape returns the shape of the output, given the shapes of the operands.
+func (r *Sub) OutputShape(inputs ...Shape) (Shape, error) {
+	return elementwiseShape(inputs)
+}
+
 // Forward computes the output of the node.
 func (r *Sub) Forward() mat.Matrix {
 	x1v := r.x1.Value()
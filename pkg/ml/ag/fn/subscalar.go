@@ -21,6 +21,11 @@ func NewSubScalar(x1, x2 Operand) *SubScalar {
 	return &SubScalar{x1: x1, x2: x2}
 }
 
+// OutputShape returns the shape of the output, given the shapes of the operands.
+func (r *SubScalar) OutputShape(inputs ...Shape) (Shape, error) {
+	return scalarShape(inputs)
+}
+
 // Forward computes the output of the node.
 func (r *SubScalar) Forward() mat.Matrix {
 	return r.x1.Value().SubScalar(r.x2.Value().Scalar())
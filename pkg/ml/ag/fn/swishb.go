@@ -24,6 +24,11 @@ func NewSwishB(x, beta Operand) *SwishB {
 	return &SwishB{x: x, beta: beta}
 }
 
+// OutputShape returns the shape of the output, given the shapes of the operands.
+func (r *SwishB) OutputShape(inputs ...Shape) (Shape, error) {
+	return scalarShape(inputs)
+}
+
 // Forward computes the output of the function.
 func (r *SwishB) Forward() mat.Matrix {
 	y := mat.GetDenseWorkspace(r.x.Value().Dims())
@@ -22,6 +22,11 @@ func NewThreshold(x, threshold, k Operand) *Threshold {
 	return &Threshold{x: x, threshold: threshold, k: k}
 }
 
+// OutputShape returns the shape of the output, given the shapes of the operands.
+func (r *Threshold) OutputShape(inputs ...Shape) (Shape, error) {
+	return scalarShape(inputs)
+}
+
 // Forward computes the output of the function.
 func (r *Threshold) Forward() mat.Matrix {
 	y := mat.GetDenseWorkspace(r.x.Value().Dims())
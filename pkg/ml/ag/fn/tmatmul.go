@@ -0,0 +1,74 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fn
+
+import (
+	"fmt"
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"sync"
+)
+
+var _ Function = &TMatMul{}
+
+// TMatMul is an operator to perform the matrix multiplication x1ᵀ×x2,
+// without materializing the transpose of x1.
+type TMatMul struct {
+	x1 Operand
+	x2 Operand
+}
+
+// NewTMatMul returns a new TMatMul Function.
+func NewTMatMul(x1, x2 Operand) *TMatMul {
+	return &TMatMul{x1: x1, x2: x2}
+}
+
+// OutputShape returns the shape of the output, given the shapes of the operands.
+func (r *TMatMul) OutputShape(inputs ...Shape) (Shape, error) {
+	a, b := inputs[0], inputs[1]
+	if a.Rows != b.Rows {
+		return Shape{}, fmt.Errorf("(%v)ᵀ · %v mismatch", a, b)
+	}
+	return Shape{Rows: a.Columns, Columns: b.Columns}, nil
+}
+
+// Forward computes the output of the function.
+func (r *TMatMul) Forward() mat.Matrix {
+	if r.x1.Value().Rows() != r.x2.Value().Rows() {
+		panic("fn: matrices with not compatible size")
+	}
+	return r.x1.Value().TMatMul(r.x2.Value())
+}
+
+// Backward computes the backward pass.
+func (r *TMatMul) Backward(gy mat.Matrix) {
+	if !(r.x1.Value().Columns() == gy.Rows() && r.x2.Value().Columns() == gy.Columns()) {
+		panic("fn: matrices with not compatible size")
+	}
+	var wg sync.WaitGroup
+	// the gradients of an operand used twice are accumulated in a fixed order
+	serial := r.x1 == r.x2
+	if r.x1.RequiresGrad() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			gx := r.x2.Value().MatMulT(gy)
+			defer mat.ReleaseMatrix(gx)
+			r.x1.PropagateGrad(gx)
+		}()
+		if serial {
+			wg.Wait()
+		}
+	}
+	if r.x2.RequiresGrad() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			gx := r.x1.Value().Mul(gy)
+			defer mat.ReleaseMatrix(gx)
+			r.x2.PropagateGrad(gx)
+		}()
+	}
+	wg.Wait()
+}
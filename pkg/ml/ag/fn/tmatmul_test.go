@@ -0,0 +1,65 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fn
+
+import (
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestTMatMul_Forward(t *testing.T) {
+	x1 := &variable{
+		value: mat.NewDense(3, 2, []mat.Float{
+			0.1, 0.2,
+			0.4, -0.5,
+			0.3, 0.6,
+		}),
+		grad:         nil,
+		requiresGrad: true,
+	}
+
+	x2 := &variable{
+		value: mat.NewDense(3, 3, []mat.Float{
+			0.5, -0.1, 0.2,
+			0.0, 0.3, -0.4,
+			0.7, 0.1, 0.1,
+		}),
+		grad:         nil,
+		requiresGrad: true,
+	}
+
+	f := NewTMatMul(x1, x2)
+	y := f.Forward()
+
+	assert.Equal(t, []int{2, 3}, []int{y.Rows(), y.Columns()})
+	assert.InDeltaSlice(t, []mat.Float{
+		0.26, 0.14, -0.11,
+		0.52, -0.11, 0.3,
+	}, y.Data(), 1.0e-6)
+
+	f.Backward(mat.NewDense(2, 3, []mat.Float{
+		0.1, -0.2, 0.3,
+		0.5, 0.0, -0.6,
+	}))
+
+	assert.InDeltaSlice(t, []mat.Float{
+		0.13, 0.13,
+		-0.18, 0.24,
+		0.08, 0.29,
+	}, x1.grad.Data(), 1.0e-6)
+
+	assert.InDeltaSlice(t, []mat.Float{
+		0.11, -0.02, -0.09,
+		-0.21, -0.08, 0.42,
+		0.33, -0.06, -0.27,
+	}, x2.grad.Data(), 1.0e-6)
+}
+
+func TestTMatMul_Panics(t *testing.T) {
+	x1 := &variable{value: mat.NewEmptyDense(3, 2), requiresGrad: true}
+	x2 := &variable{value: mat.NewEmptyDense(2, 3), requiresGrad: true}
+	assert.Panics(t, func() { NewTMatMul(x1, x2).Forward() })
+}
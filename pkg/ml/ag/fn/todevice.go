@@ -0,0 +1,49 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fn
+
+import (
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+)
+
+var _ Function = &ToDevice{}
+
+// ToDevice is a Function to associate the value of the operand with a
+// mat.Device. Use mat.Host as device to transfer the value back to the host.
+// The gradients are propagated unchanged.
+type ToDevice struct {
+	x      Operand
+	device mat.Device
+}
+
+// NewToDevice returns a new ToDevice Function.
+func NewToDevice(x Operand, device mat.Device) *ToDevice {
+	return &ToDevice{x: x, device: device}
+}
+
+// OutputShape returns the shape of the output, given the shape of the operand.
+func (r *ToDevice) OutputShape(inputs ...Shape) (Shape, error) {
+	return inputs[0], nil
+}
+
+// Forward computes the output of the function.
+// It panics if the value of the operand is not a Dense matrix.
+func (r *ToDevice) Forward() mat.Matrix {
+	x, ok := r.x.Value().(*mat.Dense)
+	if !ok {
+		panic("fn: ToDevice requires a Dense matrix")
+	}
+	return x.ToDevice(r.device)
+}
+
+// Backward computes the backward pass.
+func (r *ToDevice) Backward(gy mat.Matrix) {
+	if !mat.SameDims(r.x.Value(), gy) {
+		panic("fn: matrices with not compatible size")
+	}
+	if r.x.RequiresGrad() {
+		r.x.PropagateGrad(gy)
+	}
+}
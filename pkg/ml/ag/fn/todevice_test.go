@@ -0,0 +1,40 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fn
+
+import (
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// testDevice executes the operations on the host, under another name.
+type testDevice struct {
+	mat.Device
+}
+
+func (testDevice) Name() string { return "test" }
+
+func TestToDevice_Forward(t *testing.T) {
+	x := &variable{
+		value:        mat.NewVecDense([]mat.Float{0.1, 0.2, 0.3}),
+		grad:         nil,
+		requiresGrad: true,
+	}
+	dev := testDevice{Device: mat.Host}
+
+	f := NewToDevice(x, dev)
+	y := f.Forward()
+
+	assert.InDeltaSlice(t, []mat.Float{0.1, 0.2, 0.3}, y.Data(), 1.0e-6)
+	assert.Equal(t, mat.Device(dev), y.(*mat.Dense).Device())
+	assert.Equal(t, mat.Host, x.value.(*mat.Dense).Device())
+
+	back := NewToDevice(&variable{value: y}, mat.Host).Forward()
+	assert.Equal(t, mat.Host, back.(*mat.Dense).Device())
+
+	f.Backward(mat.NewVecDense([]mat.Float{-1.0, 0.5, 0.8}))
+	assert.InDeltaSlice(t, []mat.Float{-1.0, 0.5, 0.8}, x.grad.Data(), 1.0e-6)
+}
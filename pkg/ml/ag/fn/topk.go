@@ -0,0 +1,71 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fn
+
+import (
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+)
+
+var _ Function = &TopK{}
+
+// TopK is a function to extract the k greatest values of the input matrix,
+// in descending order, as a column vector.
+// The gradients are routed back to the selected elements only.
+type TopK struct {
+	x       Operand
+	k       int
+	indices []int // initialized during the forward pass
+}
+
+// NewTopK returns a new TopK Function.
+func NewTopK(x Operand, k int) *TopK {
+	if k < 0 {
+		panic("fn: k must be non-negative")
+	}
+	return &TopK{x: x, k: k}
+}
+
+// Indices returns the indices of the selected elements of the input matrix
+// (data in row-major order). It is available after the forward pass.
+func (r *TopK) Indices() []int {
+	return r.indices
+}
+
+// OutputShape returns the shape of the output, given the shape of the operand.
+func (r *TopK) OutputShape(inputs ...Shape) (Shape, error) {
+	size := inputs[0].Size()
+	if r.k < size {
+		size = r.k
+	}
+	return Shape{Rows: size, Columns: 1}, nil
+}
+
+// Forward computes the output of the function.
+func (r *TopK) Forward() mat.Matrix {
+	var y mat.Matrix
+	y, r.indices = mat.TopK(r.x.Value(), r.k)
+	return y
+}
+
+// Backward computes the backward pass.
+func (r *TopK) Backward(gy mat.Matrix) {
+	scatterGrad(r.x, r.indices, gy)
+}
+
+// scatterGrad propagates to x the gradients gy of the elements of x at the given indices.
+func scatterGrad(x Operand, indices []int, gy mat.Matrix) {
+	if gy.Size() != len(indices) {
+		panic("fn: matrices with not compatible size")
+	}
+	if x.RequiresGrad() {
+		gx := mat.NewEmptyDense(x.Value().Dims())
+		defer mat.ReleaseDense(gx)
+		gxData, gyData := gx.Data(), gy.Data()
+		for i, index := range indices {
+			gxData[index] += gyData[i]
+		}
+		x.PropagateGrad(gx)
+	}
+}
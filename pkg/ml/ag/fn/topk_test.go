@@ -0,0 +1,36 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fn
+
+import (
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTopK_Forward(t *testing.T) {
+	x := &variable{
+		value: mat.NewDense(2, 3, []mat.Float{
+			0.1, 0.9, -0.4,
+			0.5, 0.2, 0.7,
+		}),
+		grad:         nil,
+		requiresGrad: true,
+	}
+
+	f := NewTopK(x, 2)
+	y := f.Forward()
+
+	assert.InDeltaSlice(t, []mat.Float{0.9, 0.7}, y.Data(), 1.0e-6)
+	assert.Equal(t, []int{1, 5}, f.Indices())
+
+	f.Backward(mat.NewVecDense([]mat.Float{0.3, -0.2}))
+
+	assert.InDeltaSlice(t, []mat.Float{
+		0.0, 0.3, 0.0,
+		0.0, 0.0, -0.2,
+	}, x.grad.Data(), 1.0e-6)
+}
@@ -20,6 +20,11 @@ func NewTranspose(x Operand) *Transpose {
 	return &Transpose{x: x}
 }
 
+// OutputShape returns the shape of the output, given the shape of the operand.
+func (r *Transpose) OutputShape(inputs ...Shape) (Shape, error) {
+	return Shape{Rows: inputs[0].Columns, Columns: inputs[0].Rows}, nil
+}
+
 // Forward computes the output of the node.
 func (r *Transpose) Forward() mat.Matrix {
 	return r.x.Value().T()
@@ -17,6 +17,11 @@ type UnaryElementwise struct {
 	df func(i, j int, v mat.Float) mat.Float // derivative
 }
 
+// OutputShape returns the shape of the output, given the shape of the operand.
+func (r *UnaryElementwise) OutputShape(inputs ...Shape) (Shape, error) {
+	return inputs[0], nil
+}
+
 // Forward computes the output of this node.
 func (r *UnaryElementwise) Forward() mat.Matrix {
 	y := mat.GetDenseWorkspace(r.x.Value().Dims())
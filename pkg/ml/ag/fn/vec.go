@@ -20,6 +20,11 @@ func NewVec(x Operand) *Vec {
 	return &Vec{x: x}
 }
 
+// OutputShape returns the shape of the output, given the shape of the operand.
+func (r *Vec) OutputShape(inputs ...Shape) (Shape, error) {
+	return Shape{Rows: inputs[0].Size(), Columns: 1}, nil
+}
+
 // Forward computes the output of the node.
 func (r *Vec) Forward() mat.Matrix {
 	return r.x.Value().Reshape(r.x.Value().Size(), 1)
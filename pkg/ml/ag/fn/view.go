@@ -5,6 +5,7 @@
 package fn
 
 import (
+	"fmt"
 	mat "github.com/nlpodyssey/spago/pkg/mat32"
 )
 
@@ -24,6 +25,14 @@ func NewView(x Operand, sx, sy, lx, ly int) *View {
 	return &View{x: x, sx: sx, sy: sy, lx: lx, ly: ly}
 }
 
+// OutputShape returns the shape of the output, given the shape of the operand.
+func (r *View) OutputShape(inputs ...Shape) (Shape, error) {
+	if x := inputs[0]; r.sx < 0 || r.sy < 0 || r.sx+r.lx > x.Rows || r.sy+r.ly > x.Columns {
+		return Shape{}, fmt.Errorf("view of %dx%d at (%d, %d) out of range of %v", r.lx, r.ly, r.sx, r.sy, x)
+	}
+	return Shape{Rows: r.lx, Columns: r.ly}, nil
+}
+
 // Forward computes the output of the function.
 func (r *View) Forward() mat.Matrix {
 	y := mat.NewEmptyDense(r.lx, r.ly)
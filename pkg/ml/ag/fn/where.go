@@ -0,0 +1,111 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fn
+
+import (
+	"fmt"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+)
+
+var _ Function = &Where{}
+
+// Where is a function to select element-wise between two operands based on a
+// mask: the output takes the element of a where the condition is not zero,
+// and the element of b otherwise.
+// Either a or b can be a scalar, which is broadcast to all the elements (e.g.
+// to clamp the values of x with Where(x > max, max, x)).
+// The condition is not differentiable; the gradients are only routed to the
+// selected elements.
+type Where struct {
+	cond Operand
+	a    Operand
+	b    Operand
+}
+
+// NewWhere returns a new Where Function.
+func NewWhere(cond, a, b Operand) *Where {
+	return &Where{cond: cond, a: a, b: b}
+}
+
+// OutputShape returns the shape of the output, given the shapes of the operands.
+func (r *Where) OutputShape(inputs ...Shape) (Shape, error) {
+	cond := inputs[0]
+	for _, x := range inputs[1:] {
+		if x.Size() != 1 && x != cond {
+			return Shape{}, fmt.Errorf("%v and %v mismatch", cond, x)
+		}
+	}
+	return cond, nil
+}
+
+// Forward computes the output of the function.
+func (r *Where) Forward() mat.Matrix {
+	cond, a, b := r.cond.Value(), r.a.Value(), r.b.Value()
+	r.checkSize(cond, a, b)
+	y := cond.ZerosLike()
+	yData := y.Data()
+	for i, c := range cond.Data() {
+		if c != 0 {
+			yData[i] = elementAt(a, i)
+		} else {
+			yData[i] = elementAt(b, i)
+		}
+	}
+	return y
+}
+
+// Backward computes the backward pass.
+func (r *Where) Backward(gy mat.Matrix) {
+	cond := r.cond.Value()
+	if !mat.SameDims(cond, gy) {
+		panic("fn: matrices with not compatible size")
+	}
+	if r.a.RequiresGrad() {
+		ga := r.routeGrad(gy, r.a.Value(), true)
+		defer mat.ReleaseMatrix(ga)
+		r.a.PropagateGrad(ga)
+	}
+	if r.b.RequiresGrad() {
+		gb := r.routeGrad(gy, r.b.Value(), false)
+		defer mat.ReleaseMatrix(gb)
+		r.b.PropagateGrad(gb)
+	}
+}
+
+// routeGrad returns the gradients of the elements of x selected where the
+// condition is equal to selected, summed up if x is a broadcast scalar.
+func (r *Where) routeGrad(gy, x mat.Matrix, selected bool) mat.Matrix {
+	gx := x.ZerosLike()
+	gxData, gyData := gx.Data(), gy.Data()
+	broadcast := x.Size() == 1 && gy.Size() != 1
+	for i, c := range r.cond.Value().Data() {
+		if (c != 0) != selected {
+			continue
+		}
+		if broadcast {
+			gxData[0] += gyData[i]
+		} else {
+			gxData[i] = gyData[i]
+		}
+	}
+	return gx
+}
+
+func (r *Where) checkSize(cond, a, b mat.Matrix) {
+	for _, x := range []mat.Matrix{a, b} {
+		if x.Size() != 1 && !mat.SameDims(cond, x) {
+			panic("fn: matrices with not compatible size")
+		}
+	}
+}
+
+// elementAt returns the i-th element of x, or its only element if it's a scalar.
+func elementAt(x mat.Matrix, i int) mat.Float {
+	if x.Size() == 1 {
+		return x.Data()[0]
+	}
+	return x.Data()[i]
+}
@@ -0,0 +1,61 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package fn
+
+import (
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWhere_Forward(t *testing.T) {
+	cond := &variable{
+		value:        mat.NewDense(2, 2, []mat.Float{1, 0, 0, 1}),
+		requiresGrad: false,
+	}
+	a := &variable{
+		value:        mat.NewDense(2, 2, []mat.Float{0.1, 0.2, 0.3, 0.4}),
+		requiresGrad: true,
+	}
+	b := &variable{
+		value:        mat.NewDense(2, 2, []mat.Float{-0.1, -0.2, -0.3, -0.4}),
+		requiresGrad: true,
+	}
+	f := NewWhere(cond, a, b)
+	y := f.Forward()
+	assert.InDeltaSlice(t, []mat.Float{0.1, -0.2, -0.3, 0.4}, y.Data(), 1.0e-6)
+
+	f.Backward(mat.NewDense(2, 2, []mat.Float{1, 2, 3, 4}))
+	assert.InDeltaSlice(t, []mat.Float{1, 0, 0, 4}, a.grad.Data(), 1.0e-6)
+	assert.InDeltaSlice(t, []mat.Float{0, 2, 3, 0}, b.grad.Data(), 1.0e-6)
+	assert.Nil(t, cond.grad)
+}
+
+func TestWhere_Scalar(t *testing.T) {
+	x := &variable{
+		value:        mat.NewVecDense([]mat.Float{0.5, 1.5, -0.2, 2.0}),
+		requiresGrad: true,
+	}
+	max := &variable{
+		value:        mat.NewScalar(1.0),
+		requiresGrad: true,
+	}
+	cond := &variable{
+		value:        mat.NewVecDense([]mat.Float{0, 1, 0, 1}),
+		requiresGrad: false,
+	}
+	f := NewWhere(cond, max, x)
+	y := f.Forward()
+	assert.InDeltaSlice(t, []mat.Float{0.5, 1.0, -0.2, 1.0}, y.Data(), 1.0e-6)
+
+	f.Backward(mat.NewVecDense([]mat.Float{1, 2, 3, 4}))
+	assert.InDeltaSlice(t, []mat.Float{1, 0, 3, 0}, x.grad.Data(), 1.0e-6)
+	assert.InDeltaSlice(t, []mat.Float{6}, max.grad.Data(), 1.0e-6)
+
+	assert.Panics(t, func() {
+		NewWhere(cond, &variable{value: mat.NewVecDense([]mat.Float{1, 2})}, x).Forward()
+	})
+}
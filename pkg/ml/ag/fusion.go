@@ -0,0 +1,268 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ag
+
+import (
+	"github.com/nlpodyssey/spago/pkg/ml/ag/fn"
+)
+
+// Optimization is the enumeration-like type used for the set of the
+// optimizations of the graph (see WithOptimization).
+type Optimization int
+
+const (
+	// FuseOps fuses the most frequent chains of operators into single
+	// operators, reducing the intermediate allocations (see Graph.FuseOps).
+	FuseOps Optimization = 1 << iota
+)
+
+// WithOptimization enables the given optimizations of the graph.
+func WithOptimization(optimizations ...Optimization) GraphOption {
+	return func(g *Graph) {
+		for _, o := range optimizations {
+			g.optimizations |= o
+		}
+	}
+}
+
+// OptimizationEnabled reports whether the given optimization is enabled.
+func (g *Graph) OptimizationEnabled(o Optimization) bool {
+	return g.optimizations&o != 0
+}
+
+// FuseOps rewrites the chains of operators matching the following patterns,
+// provided that their intermediate nodes are not used elsewhere:
+//  - the affine transformation followed by an element-wise activation,
+//    f(b + W·x), into a fn.AffineActivation;
+//  - the sum of a bias followed by an element-wise activation, f(x + b)
+//    (e.g. the bias+GELU of the Transformers), into a fn.BiasActivation;
+//  - the decomposition of the layer normalization of the layernorm
+//    package into a fn.LayerNorm.
+// The last operator of a chain takes the fused function, while the values of
+// the intermediate ones are released and no longer computed by the forward:
+// they are computed only if read (e.g. with Value), so the intermediate nodes
+// held by the caller stay observable.
+//
+// With the FuseOps optimization it runs automatically at the beginning of
+// Forward(): it is most useful with IncrementalForward(false), or when a
+// graph is recomputed after ClearForReuse().
+func (g *Graph) FuseOps() {
+	g.WaitForward()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	f := &fuser{g: g, consumers: make([]int, len(g.nodes))}
+	for _, node := range g.nodes {
+		if op, ok := node.(*Operator); ok && op.function != nil && !op.fused {
+			for _, operand := range op.operands {
+				f.consumers[operand.ID()]++
+			}
+		}
+	}
+	fused := false
+	// visiting the nodes from the last one, the longest chains are matched first
+	for i := len(g.nodes) - 1; i >= 0; i-- {
+		op, ok := g.nodes[i].(*Operator)
+		if !ok || op.function == nil || op.fused {
+			continue
+		}
+		if f.fuseLayerNorm(op) || f.fuseAffineActivation(op) || f.fuseBiasActivation(op) {
+			fused = true
+		}
+	}
+	if fused {
+		g.clearCache()
+	}
+}
+
+// fuser performs the FuseOps pass.
+type fuser struct {
+	g *Graph
+	// consumers contains the number of operators using each node as operand.
+	consumers []int
+}
+
+// operator returns the node as an operator of the same time-step of root,
+// with the given number of consumers, if its function satisfies the
+// predicate; it returns nil otherwise.
+func (f *fuser) operator(root *Operator, node Node, consumers int, is func(fn.Function) bool) *Operator {
+	op, ok := node.(*Operator)
+	if !ok || op.function == nil || op.fused || op.timeStep != root.timeStep || f.consumers[op.id] != consumers || !is(op.function) {
+		return nil
+	}
+	return op
+}
+
+// replace sets the fused function of the root, and marks the intermediate
+// operators as fused.
+func (f *fuser) replace(root *Operator, function fn.Function, operands []Node, intermediates ...*Operator) {
+	for _, op := range append(intermediates, root) {
+		for _, operand := range op.operands {
+			f.consumers[operand.ID()]--
+		}
+	}
+	for _, operand := range operands {
+		f.consumers[operand.ID()]++
+	}
+	for _, op := range intermediates {
+		f.g.releaseValue(op)
+		op.fused = true
+	}
+	root.function, root.operands = function, operands
+}
+
+func (f *fuser) fuseAffineActivation(root *Operator) bool {
+	activation := elementwiseActivation(root.function)
+	if activation == nil {
+		return false
+	}
+	add := f.operator(root, root.operands[0], 1, isAdd)
+	if add == nil {
+		return false
+	}
+	b, wx := add.operands[0], add.operands[1]
+	mul := f.operator(root, wx, 1, isMul)
+	if mul == nil {
+		b, wx = wx, b
+		if mul = f.operator(root, wx, 1, isMul); mul == nil {
+			return false
+		}
+	}
+	w, x := mul.operands[0], mul.operands[1]
+	f.replace(root, fn.NewAffineActivation(b, w, x, activation), []Node{b, w, x}, add, mul)
+	return true
+}
+
+func (f *fuser) fuseBiasActivation(root *Operator) bool {
+	activation := elementwiseActivation(root.function)
+	if activation == nil {
+		return false
+	}
+	add := f.operator(root, root.operands[0], 1, isAdd)
+	if add == nil || isFakeOperand(add.operands[0]) {
+		return false
+	}
+	x, b := add.operands[0], add.operands[1]
+	f.replace(root, fn.NewBiasActivation(x, b, activation), []Node{x, b}, add)
+	return true
+}
+
+// fuseLayerNorm matches the decomposition:
+//  mean := ReduceMean(x)
+//  dev := SubScalar(x, mean)
+//  stdDev := Sqrt(Add(ReduceMean(Square(dev)), eps))
+//  y := Add(Prod(DivScalar(dev, stdDev), w), b)
+func (f *fuser) fuseLayerNorm(root *Operator) bool {
+	if !isAdd(root.function) || isFakeOperand(root.operands[0]) {
+		return false
+	}
+	prod := f.operator(root, root.operands[0], 1, isProd)
+	if prod == nil {
+		return false
+	}
+	div := f.operator(root, prod.operands[0], 1, isDivScalar)
+	if div == nil {
+		return false
+	}
+	dev := f.operator(root, div.operands[0], 2, isSubScalar)
+	stdDev := f.operator(root, div.operands[1], 1, isSqrt)
+	if dev == nil || stdDev == nil {
+		return false
+	}
+	x := dev.operands[0]
+	mean := f.operator(root, dev.operands[1], 1, isReduceMean)
+	if mean == nil || mean.operands[0] != x {
+		return false
+	}
+	addEps := f.operator(root, stdDev.operands[0], 1, isAdd)
+	if addEps == nil {
+		return false
+	}
+	variance := f.operator(root, addEps.operands[0], 1, isReduceMean)
+	if variance == nil {
+		return false
+	}
+	square := f.operator(root, variance.operands[0], 1, isSquare)
+	if square == nil || square.operands[0] != Node(dev) {
+		return false
+	}
+	w, b, eps := prod.operands[1], root.operands[1], addEps.operands[1]
+	f.replace(root, fn.NewLayerNorm(x, w, b, eps), []Node{x, w, b, eps},
+		prod, div, dev, stdDev, mean, addEps, variance, square)
+	return true
+}
+
+// elementwiseActivation returns the UnaryElementwise of the function if it is
+// an activation that can be fused, or nil.
+func elementwiseActivation(f fn.Function) *fn.UnaryElementwise {
+	switch ft := f.(type) {
+	case *fn.Tanh:
+		return ft.UnaryElementwise
+	case *fn.Sigmoid:
+		return ft.UnaryElementwise
+	case *fn.HardSigmoid:
+		return ft.UnaryElementwise
+	case *fn.HardTanh:
+		return ft.UnaryElementwise
+	case *fn.Softsign:
+		return ft.UnaryElementwise
+	case *fn.ReLU:
+		return ft.UnaryElementwise
+	case *fn.GELU:
+		return ft.UnaryElementwise
+	case *fn.Mish:
+		return ft.UnaryElementwise
+	case *fn.Swish:
+		return ft.UnaryElementwise
+	default:
+		return nil
+	}
+}
+
+// isFakeOperand reports whether the node is the variable without value
+// created by Add() for a nil operand.
+func isFakeOperand(node Node) bool {
+	v, ok := node.(*Variable)
+	return ok && v.value == nil
+}
+
+func isAdd(f fn.Function) bool {
+	_, ok := f.(*fn.Add)
+	return ok
+}
+
+func isMul(f fn.Function) bool {
+	_, ok := f.(*fn.Mul)
+	return ok
+}
+
+func isProd(f fn.Function) bool {
+	_, ok := f.(*fn.Prod)
+	return ok
+}
+
+func isSquare(f fn.Function) bool {
+	_, ok := f.(*fn.Square)
+	return ok
+}
+
+func isDivScalar(f fn.Function) bool {
+	_, ok := f.(*fn.DivScalar)
+	return ok
+}
+
+func isSubScalar(f fn.Function) bool {
+	_, ok := f.(*fn.SubScalar)
+	return ok
+}
+
+func isSqrt(f fn.Function) bool {
+	_, ok := f.(*fn.Sqrt)
+	return ok
+}
+
+func isReduceMean(f fn.Function) bool {
+	_, ok := f.(*fn.ReduceMean)
+	return ok
+}
@@ -0,0 +1,91 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ag
+
+import (
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/stretchr/testify/assert"
+)
+
+// fusionTestModel builds a layer normalization followed by an affine
+// transformation with GELU and a bias with tanh.
+func fusionTestModel(g *Graph) (y Node, params []Node) {
+	x := g.NewVariable(mat.NewVecDense([]mat.Float{0.1, -0.4, 0.7}), true)
+	w1 := g.NewVariable(mat.NewVecDense([]mat.Float{0.5, 1.2, -0.3}), true)
+	b1 := g.NewVariable(mat.NewVecDense([]mat.Float{0.1, 0.2, 0.3}), true)
+	w2 := g.NewVariable(mat.NewDense(2, 3, []mat.Float{0.2, -0.5, 0.8, 0.1, 0.4, -0.9}), true)
+	b2 := g.NewVariable(mat.NewVecDense([]mat.Float{0.05, -0.1}), true)
+	b3 := g.NewVariable(mat.NewVecDense([]mat.Float{0.3, -0.2}), true)
+
+	eps := g.Constant(1e-12)
+	mean := g.ReduceMean(x)
+	dev := g.SubScalar(x, mean)
+	stdDev := g.Sqrt(g.Add(g.ReduceMean(g.Square(dev)), eps))
+	norm := g.Add(g.Prod(g.DivScalar(dev, stdDev), w1), b1)
+
+	h := g.GELU(g.Add(b2, g.Mul(w2, norm)))
+	y = g.ReduceSum(g.Tanh(g.Add(h, b3)))
+	return y, []Node{x, w1, b1, w2, b2, b3}
+}
+
+func TestGraph_FuseOps(t *testing.T) {
+	g := NewGraph(IncrementalForward(false))
+	y, params := fusionTestModel(g)
+	g.Forward()
+	g.Backward(y)
+
+	fg := NewGraph(IncrementalForward(false), WithOptimization(FuseOps))
+	assert.True(t, fg.OptimizationEnabled(FuseOps))
+	fy, fParams := fusionTestModel(fg)
+	fg.Forward()
+	fg.Backward(fy)
+
+	assert.InDelta(t, y.ScalarValue(), fy.ScalarValue(), 1.0e-6)
+	for i, p := range params {
+		assert.InDeltaSlice(t, p.Grad().Data(), fParams[i].Grad().Data(), 1.0e-5)
+	}
+
+	names := make([]string, 0)
+	for _, node := range fg.Nodes() {
+		if op, ok := node.(*Operator); ok && op.function != nil && !op.fused {
+			names = append(names, op.Name())
+		}
+	}
+	assert.Equal(t, []string{"LayerNorm", "AffineActivation", "BiasActivation", "ReduceSum"}, names)
+}
+
+func TestGraph_FuseOpsSharedIntermediate(t *testing.T) {
+	g := NewGraph(WithOptimization(FuseOps))
+	x := g.NewVariable(mat.NewVecDense([]mat.Float{-1, 2}), true)
+	b := g.NewVariable(mat.NewVecDense([]mat.Float{0.5, 0.5}), true)
+	z := g.Add(x, b)
+	y := g.ReLU(z)
+	w := g.Prod(z, y) // z is used twice, so it must not be fused
+	g.Forward()
+
+	assert.Equal(t, "ReLU", y.(*Operator).Name())
+	assert.Equal(t, []mat.Float{0, 6.25}, w.Value().Data())
+}
+
+func TestGraph_FuseOpsObservableIntermediates(t *testing.T) {
+	g := NewGraph(IncrementalForward(false), WithOptimization(FuseOps))
+	x := g.NewVariable(mat.NewVecDense([]mat.Float{-1, 2}), true)
+	b := g.NewVariable(mat.NewVecDense([]mat.Float{0.5, 0.5}), true)
+	cell := g.Add(x, b)
+	h := g.Tanh(cell)
+	g.Forward()
+
+	assert.Equal(t, "BiasActivation", h.(*Operator).Name())
+	assert.Nil(t, cell.(*Operator).value) // not computed by the forward
+	assert.Equal(t, []mat.Float{-0.5, 2.5}, cell.Value().Data())
+	assert.InDeltaSlice(t, []mat.Float{-0.4621172, 0.9866143}, h.Value().Data(), 1.0e-6)
+
+	x.Value().SetVec(0, 1)
+	g.Forward()
+	assert.Equal(t, []mat.Float{1.5, 2.5}, cell.Value().Data())
+	assert.InDeltaSlice(t, []mat.Float{0.9051483, 0.9866143}, h.Value().Data(), 1.0e-6)
+}
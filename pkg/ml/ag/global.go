@@ -118,6 +118,11 @@ func Identity(x Node) Node {
 	return globalGraph.Identity(x)
 }
 
+// Detach returns a new operator node as a result of the fn.StopGrad function.
+func Detach(x Node) Node {
+	return globalGraph.Detach(x)
+}
+
 // Dropout returns a new operator node as a result of the fn.Dropout function.
 func Dropout(x Node, p mat.Float) Node {
 	return globalGraph.Dropout(x, p)
@@ -209,6 +214,22 @@ func MaxPooling(x Node, rows, columns int) Node {
 	return globalGraph.MaxPooling(x, rows, columns)
 }
 
+// Conv2D returns a new operator node as a result of the fn.Conv2D function.
+// The bias b can be nil.
+func Conv2D(x, w, b Node, config fn.Conv2DConfig) Node {
+	return globalGraph.Conv2D(x, w, b, config)
+}
+
+// MaxPool2D returns a new operator node as a result of the fn.MaxPool2D function.
+func MaxPool2D(x Node, config fn.Pool2DConfig) Node {
+	return globalGraph.MaxPool2D(x, config)
+}
+
+// AvgPool2D returns a new operator node as a result of the fn.AvgPool2D function.
+func AvgPool2D(x Node, config fn.Pool2DConfig) Node {
+	return globalGraph.AvgPool2D(x, config)
+}
+
 // View returns a new operator node as a result of the fn.View function.
 func View(x Node, row, column, xStride, yStride int) Node {
 	return globalGraph.View(x, row, column, xStride, yStride)
@@ -420,6 +441,26 @@ func ReduceMean(x Node) Node {
 	return globalGraph.ReduceMean(x)
 }
 
+// ReduceSumAlong returns a new operator node as a result of the fn.ReduceAlong function.
+func ReduceSumAlong(x Node, axis fn.Axis, keepDims bool) Node {
+	return globalGraph.ReduceSumAlong(x, axis, keepDims)
+}
+
+// ReduceMeanAlong returns a new operator node as a result of the fn.ReduceAlong function.
+func ReduceMeanAlong(x Node, axis fn.Axis, keepDims bool) Node {
+	return globalGraph.ReduceMeanAlong(x, axis, keepDims)
+}
+
+// ReduceMaxAlong returns a new operator node as a result of the fn.ReduceAlong function.
+func ReduceMaxAlong(x Node, axis fn.Axis, keepDims bool) Node {
+	return globalGraph.ReduceMaxAlong(x, axis, keepDims)
+}
+
+// ReduceMinAlong returns a new operator node as a result of the fn.ReduceAlong function.
+func ReduceMinAlong(x Node, axis fn.Axis, keepDims bool) Node {
+	return globalGraph.ReduceMinAlong(x, axis, keepDims)
+}
+
 // Sum returns the value that describes the sum of the sample.
 func Sum(xs ...Node) Node {
 	return globalGraph.Sum(xs...)
@@ -430,6 +471,98 @@ func Mean(xs []Node) Node {
 	return globalGraph.Mean(xs)
 }
 
+// LeastSquares returns a new operator node holding the solution of the
+// (regularized) linear least squares problem (see Graph.LeastSquares).
+func LeastSquares(a, b Node, lambda mat.Float) Node {
+	return globalGraph.LeastSquares(a, b, lambda)
+}
+
+// GumbelSoftmax returns a new operator node holding a sample of the
+// Gumbel-softmax distribution (see Graph.GumbelSoftmax).
+func GumbelSoftmax(x Node, temperature mat.Float, hard bool) Node {
+	return globalGraph.GumbelSoftmax(x, temperature, hard)
+}
+
+// LayerNorm returns a new operator node as a result of the fn.LayerNorm function.
+func LayerNorm(x, w, b, eps Node) Node {
+	return globalGraph.LayerNorm(x, w, b, eps)
+}
+
+// SoftmaxCrossEntropy returns a new operator node as a result of the fn.SoftmaxCrossEntropy function.
+func SoftmaxCrossEntropy(x Node, target int) Node {
+	return globalGraph.SoftmaxCrossEntropy(x, target)
+}
+
+// Gather returns a new operator node as a result of the fn.Gather function.
+func Gather(x Node, indices []int) Node {
+	return globalGraph.Gather(x, indices)
+}
+
+// IndexSelect returns a new operator node as a result of the fn.IndexSelect function.
+func IndexSelect(x Node, indices []int) Node {
+	return globalGraph.IndexSelect(x, indices)
+}
+
+// ScatterAdd returns a new operator node as a result of the fn.ScatterAdd function.
+func ScatterAdd(x, src Node, indices []int) Node {
+	return globalGraph.ScatterAdd(x, src, indices)
+}
+
+// Where returns a new operator node as a result of the fn.Where function.
+func Where(cond, a, b Node) Node {
+	return globalGraph.Where(cond, a, b)
+}
+
+// LongConv returns a new operator node as a result of the fn.LongConv function.
+func LongConv(x, k Node) Node {
+	return globalGraph.LongConv(x, k)
+}
+
+// Solve returns a new operator node as a result of the fn.Solve function.
+func Solve(a, b Node) Node {
+	return globalGraph.Solve(a, b)
+}
+
+// Inverse returns a new operator node as a result of the fn.Inverse function.
+func Inverse(x Node) Node {
+	return globalGraph.Inverse(x)
+}
+
+// PInv returns a new operator node as a result of the fn.PInv function.
+func PInv(x Node) Node {
+	return globalGraph.PInv(x)
+}
+
+// ArgMax returns a new operator node as a result of the fn.ArgMax function.
+func ArgMax(x Node, axis fn.Axis) Node {
+	return globalGraph.ArgMax(x, axis)
+}
+
+// OneHot returns a new operator node as a result of the fn.OneHot function.
+func OneHot(x Node, size int, axis fn.Axis) Node {
+	return globalGraph.OneHot(x, size, axis)
+}
+
+// StraightThrough returns a new operator node as a result of the fn.StraightThrough function.
+func StraightThrough(x, surrogate Node) Node {
+	return globalGraph.StraightThrough(x, surrogate)
+}
+
+// CumSum returns a new operator node as a result of the fn.CumSum function.
+func CumSum(x Node, axis fn.Axis) Node {
+	return globalGraph.CumSum(x, axis)
+}
+
+// CumProd returns a new operator node as a result of the fn.CumProd function.
+func CumProd(x Node, axis fn.Axis) Node {
+	return globalGraph.CumProd(x, axis)
+}
+
+// SegmentSum returns a new operator node as a result of the fn.SegmentSum function.
+func SegmentSum(x Node, segments []int, n int) Node {
+	return globalGraph.SegmentSum(x, segments, n)
+}
+
 // Concat returns a new operator node as a result of the fn.Concat function.
 func Concat(xs ...Node) Node {
 	return globalGraph.Concat(xs...)
@@ -439,3 +572,32 @@ func Concat(xs ...Node) Node {
 func Stack(xs ...Node) Node {
 	return globalGraph.Stack(xs...)
 }
+
+// Custom returns a new operator node as a result of the custom operator
+// registered with the given name (see fn.Register).
+func Custom(name string, xs ...Node) Node {
+	return globalGraph.Custom(name, xs...)
+}
+
+// ToDevice returns a new operator node as a result of the fn.ToDevice function,
+// transferring the value of x to the global graph's device.
+func ToDevice(x Node) Node {
+	return globalGraph.ToDevice(x)
+}
+
+// ToHost returns a new operator node as a result of the fn.ToDevice function,
+// transferring the value of x back to the host.
+func ToHost(x Node) Node {
+	return globalGraph.ToHost(x)
+}
+
+// Einsum returns a new operator node as a result of the fn.Einsum function.
+func Einsum(equation string, xs ...Node) Node {
+	return globalGraph.Einsum(equation, xs...)
+}
+
+// EinsumWithSizes returns a new operator node as a result of the fn.Einsum function,
+// with the sizes of some subscripts given explicitly (see fn.NewEinsumWithSizes).
+func EinsumWithSizes(equation string, sizes map[byte]int, xs ...Node) Node {
+	return globalGraph.EinsumWithSizes(equation, sizes, xs...)
+}
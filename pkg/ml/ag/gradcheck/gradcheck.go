@@ -0,0 +1,189 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package gradcheck validates the analytic gradients computed by the
+// backward pass against numerical gradients estimated with central finite
+// differences. It is meant to be used in the test suites of custom functions
+// (fn.Function) and models (nn.Model).
+//
+// Since the outputs may be matrices, the gradients are checked with respect
+// to a scalar projection of the output, namely the sum of its elements
+// weighted by fixed pseudo-random coefficients.
+//
+// The finite differences are sensitive to the floating-point precision: with
+// the default 32-bit build, an eps in the order of 1e-3 and a tolerance in the
+// order of 1e-2 are reasonable choices.
+package gradcheck
+
+import (
+	"fmt"
+	"strings"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/mat32/rand"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+)
+
+// weightsSeed is the seed of the coefficients used to project the output to a scalar.
+const weightsSeed = 42
+
+// Func is a function under test: it receives a graph and the input nodes,
+// and returns the output node.
+type Func func(g *ag.Graph, xs ...ag.Node) ag.Node
+
+// ModelFunc is a model under test: it receives the model reified for
+// training, and returns the output node.
+type ModelFunc func(m nn.Model) ag.Node
+
+// Mismatch describes an element whose analytic gradient differs from the
+// numerical one.
+type Mismatch struct {
+	// Input identifies the input (or the parameter) the element belongs to.
+	Input string
+	// Index is the position of the element in the input (data in row-major order).
+	Index int
+	// Analytic is the gradient computed by the backward pass.
+	Analytic mat.Float
+	// Numeric is the gradient estimated with finite differences.
+	Numeric mat.Float
+}
+
+// Error is returned when one or more gradients don't match.
+type Error struct {
+	Mismatches []Mismatch
+}
+
+// Error returns a description of the mismatching gradients.
+func (e *Error) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "gradcheck: %d mismatching gradients", len(e.Mismatches))
+	for _, m := range e.Mismatches {
+		fmt.Fprintf(&sb, "\n%s[%d]: analytic %g, numeric %g", m.Input, m.Index, m.Analytic, m.Numeric)
+	}
+	return sb.String()
+}
+
+// CheckGradients compares the gradients of the output of f with respect to
+// the given inputs, computed by the backward pass, with the ones estimated
+// with central finite differences of step eps.
+// An element is reported as mismatching when the absolute difference between
+// the two gradients exceeds tol times the greatest value among 1 and their
+// magnitudes. In that case an *Error is returned.
+// The inputs are not modified.
+func CheckGradients(f Func, inputs []mat.Matrix, eps, tol mat.Float) error {
+	values := make([]mat.Matrix, len(inputs))
+	for i, x := range inputs {
+		values[i] = x.Clone()
+	}
+	forward := func(requiresGrad bool) (*ag.Graph, []ag.Node, ag.Node) {
+		g := ag.NewGraph()
+		xs := make([]ag.Node, len(values))
+		for i, v := range values {
+			xs[i] = g.NewVariable(v, requiresGrad)
+		}
+		return g, xs, f(g, xs...)
+	}
+
+	g, xs, y := forward(true)
+	weights := outputWeights(y.Value())
+	g.Backward(y, ag.OutputGrad(weights))
+
+	targets := make([]target, len(xs))
+	for i, x := range xs {
+		targets[i] = target{name: fmt.Sprintf("input %d", i), value: values[i], grad: x.Grad()}
+	}
+	return check(targets, eps, tol, func() mat.Float {
+		_, _, y := forward(false)
+		return y.Value().Prod(weights).Sum()
+	})
+}
+
+// CheckModelGradients compares the gradients of the output of f with respect
+// to the parameters of the model m, computed by the backward pass, with the
+// ones estimated with central finite differences of step eps. The tolerance
+// is applied as in CheckGradients.
+// The parameters are perturbed in place and then restored, so they must not
+// be stored in half precision. Their gradients are zeroed before returning.
+func CheckModelGradients(m nn.Model, f ModelFunc, eps, tol mat.Float) error {
+	nn.ZeroGrad(m)
+	defer nn.ZeroGrad(m)
+
+	g := ag.NewGraph()
+	y := f(nn.ReifyForTraining(m, g))
+	weights := outputWeights(y.Value())
+	g.Backward(y, ag.OutputGrad(weights))
+
+	targets := make([]target, 0)
+	nn.ForEachDistinctParam(m, func(param nn.Param) {
+		if !param.RequiresGrad() {
+			return
+		}
+		name := param.Name()
+		if name == "" {
+			name = fmt.Sprintf("param %d", len(targets))
+		}
+		targets = append(targets, target{name: name, value: param.Value(), grad: param.Grad()})
+	})
+	return check(targets, eps, tol, func() mat.Float {
+		return f(nn.ReifyForTraining(m, ag.NewGraph())).Value().Prod(weights).Sum()
+	})
+}
+
+type target struct {
+	name  string
+	value mat.Matrix
+	grad  mat.Matrix // nil means zeros
+}
+
+// check perturbs each element of the targets, estimating the numerical
+// gradient of the loss returned by evaluate.
+func check(targets []target, eps, tol mat.Float, evaluate func() mat.Float) error {
+	var mismatches []Mismatch
+	for _, t := range targets {
+		data := t.value.Data()
+		var grad []mat.Float
+		if t.grad != nil {
+			grad = t.grad.Data()
+		}
+		for i, original := range data {
+			data[i] = original + eps
+			plus := evaluate()
+			data[i] = original - eps
+			minus := evaluate()
+			data[i] = original
+
+			numeric := (plus - minus) / (2 * eps)
+			var analytic mat.Float
+			if grad != nil {
+				analytic = grad[i]
+			}
+			scale := mat.Max(1, mat.Max(mat.Abs(analytic), mat.Abs(numeric)))
+			if mat.Abs(analytic-numeric) > tol*scale {
+				mismatches = append(mismatches, Mismatch{
+					Input:    t.name,
+					Index:    i,
+					Analytic: analytic,
+					Numeric:  numeric,
+				})
+			}
+		}
+	}
+	if len(mismatches) > 0 {
+		return &Error{Mismatches: mismatches}
+	}
+	return nil
+}
+
+// outputWeights returns the fixed pseudo-random coefficients used to project
+// an output of the same shape of y to a scalar.
+func outputWeights(y mat.Matrix) mat.Matrix {
+	rndGen := rand.NewLockedRand(weightsSeed)
+	weights := y.ZerosLike()
+	data := weights.Data()
+	for i := range data {
+		data[i] = mat.Float(rndGen.Float())*2 - 1
+	}
+	return weights
+}
@@ -0,0 +1,95 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gradcheck
+
+import (
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/nlpodyssey/spago/pkg/ml/ag/fn"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/ml/nn/linear"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	eps = 1e-3
+	tol = 1e-2
+)
+
+func TestCheckGradients(t *testing.T) {
+	x := mat.NewVecDense([]mat.Float{0.1, -0.5, 0.8, 0.3})
+	w := mat.NewDense(2, 4, []mat.Float{
+		0.4, -0.2, 0.1, 0.7,
+		-0.3, 0.5, 0.6, -0.1,
+	})
+
+	testCases := map[string]Func{
+		"Softmax": func(g *ag.Graph, xs ...ag.Node) ag.Node {
+			return g.Softmax(xs[0])
+		},
+		"Mul Tanh": func(g *ag.Graph, xs ...ag.Node) ag.Node {
+			return g.Tanh(g.Mul(xs[1], xs[0]))
+		},
+		"TopK": func(g *ag.Graph, xs ...ag.Node) ag.Node {
+			return g.TopK(g.Square(xs[0]), 2)
+		},
+	}
+	for name, f := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert.NoError(t, CheckGradients(f, []mat.Matrix{x, w}, eps, tol))
+		})
+	}
+
+	assert.Equal(t, []mat.Float{0.1, -0.5, 0.8, 0.3}, x.Data(), "the inputs must not be modified")
+}
+
+func TestCheckGradients_Mismatch(t *testing.T) {
+	x := mat.NewVecDense([]mat.Float{0.1, -0.5, 0.8})
+	f := func(g *ag.Graph, xs ...ag.Node) ag.Node {
+		return g.NewOperator(&wrongSquare{x: xs[0]}, xs[0])
+	}
+
+	err := CheckGradients(f, []mat.Matrix{x}, eps, tol)
+	require.Error(t, err)
+	gradErr, ok := err.(*Error)
+	require.True(t, ok)
+	assert.Len(t, gradErr.Mismatches, 3)
+	assert.Equal(t, "input 0", gradErr.Mismatches[0].Input)
+	assert.InDelta(t, 20*gradErr.Mismatches[0].Analytic, gradErr.Mismatches[0].Numeric, 1.0e-3)
+}
+
+func TestCheckModelGradients(t *testing.T) {
+	m := linear.New(3, 2)
+	m.W.Value().SetData([]mat.Float{
+		0.4, -0.2, 0.1,
+		-0.3, 0.5, 0.6,
+	})
+	m.B.Value().SetData([]mat.Float{0.1, -0.2})
+	x := mat.NewVecDense([]mat.Float{0.5, -0.8, 0.3})
+
+	err := CheckModelGradients(m, func(proc nn.Model) ag.Node {
+		model := proc.(*linear.Model)
+		return model.Graph().Tanh(model.Forward(model.Graph().NewVariable(x, false))[0])
+	}, eps, tol)
+	assert.NoError(t, err)
+	assert.InDeltaSlice(t, []mat.Float{0.4, -0.2, 0.1, -0.3, 0.5, 0.6}, m.W.Value().Data(), 1.0e-6)
+	assert.Nil(t, m.W.Grad())
+}
+
+// wrongSquare computes x² but returns the gradient 0.1x instead of 2x.
+type wrongSquare struct {
+	x fn.Operand
+}
+
+func (r *wrongSquare) Forward() mat.Matrix {
+	return r.x.Value().Prod(r.x.Value())
+}
+
+func (r *wrongSquare) Backward(gy mat.Matrix) {
+	r.x.PropagateGrad(gy.Prod(r.x.Value()).ProdScalar(0.1))
+}
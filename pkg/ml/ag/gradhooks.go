@@ -0,0 +1,116 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ag
+
+import (
+	"fmt"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+)
+
+// GradHook is a function called during the back-propagation with the
+// gradients of a node, returning the gradients to use instead. It can modify
+// grad in place and return it (e.g. to clip the gradients), but it must not
+// return nil.
+type GradHook func(grad mat.Matrix) mat.Matrix
+
+// NodeGradHook is like GradHook, but it is registered on the whole graph, so
+// it also receives the node.
+type NodeGradHook func(node Node, grad mat.Matrix) mat.Matrix
+
+// RegisterGradHook registers a hook on the node of the graph. The hooks of an
+// operator are called with its accumulated gradients before they are
+// propagated to its operands, while the hooks of the variables and of the
+// wrappers, which are not visited by the back-propagation, are called with
+// each gradient they receive.
+// The hooks are called in order of registration, before the ones of the graph
+// (see RegisterGlobalGradHook); they are removed by Clear, but not by
+// ClearForReuse. It must not be called during the back-propagation.
+func (g *Graph) RegisterGradHook(node Node, hook GradHook) {
+	if node.Graph() != g {
+		panic("ag: the node belongs to a different graph")
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.gradHooks == nil {
+		g.gradHooks = make(map[int][]GradHook)
+	}
+	g.gradHooks[node.ID()] = append(g.gradHooks[node.ID()], hook)
+}
+
+// RegisterGradHook registers a hook on the operator (see Graph.RegisterGradHook).
+func (r *Operator) RegisterGradHook(hook GradHook) {
+	r.graph.RegisterGradHook(r, hook)
+}
+
+// RegisterGradHook registers a hook on the variable (see Graph.RegisterGradHook).
+func (r *Variable) RegisterGradHook(hook GradHook) {
+	r.graph.RegisterGradHook(r, hook)
+}
+
+// RegisterGradHook registers a hook on the wrapper (see Graph.RegisterGradHook).
+func (r *Wrapper) RegisterGradHook(hook GradHook) {
+	r.graph.RegisterGradHook(r, hook)
+}
+
+// RegisterGlobalGradHook registers a hook called for each node of the graph,
+// as described by RegisterGradHook, after the hooks of the node.
+// Unlike these, the hooks of the graph are not removed by Clear.
+// It must not be called during the back-propagation.
+func (g *Graph) RegisterGlobalGradHook(hook NodeGradHook) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.globalGradHooks = append(g.globalGradHooks, hook)
+}
+
+// hasGradHooks returns whether any hook applies to the node with the given ID.
+func (g *Graph) hasGradHooks(id int) bool {
+	return len(g.globalGradHooks) > 0 || len(g.gradHooks[id]) > 0
+}
+
+// applyGradHooks returns the gradients of the node processed by its hooks and
+// by the ones of the graph.
+func (g *Graph) applyGradHooks(node Node, grad mat.Matrix) mat.Matrix {
+	for _, hook := range g.gradHooks[node.ID()] {
+		grad = hook(grad)
+	}
+	for _, hook := range g.globalGradHooks {
+		grad = hook(node, grad)
+	}
+	return grad
+}
+
+// PanicOnInvalidGrad is a NodeGradHook panicking when the gradients contain
+// NaN or infinite values. Since the nodes are visited in reverse topological
+// order, the panic is raised at the first node getting invalid gradients, and
+// describes it.
+func PanicOnInvalidGrad(node Node, grad mat.Matrix) mat.Matrix {
+	for _, v := range grad.Data() {
+		if v != v || mat.IsInf(v, 0) {
+			panic(fmt.Sprintf("ag: invalid gradient %v of the node #%d (%s)", v, node.ID(), nodeDescription(node)))
+		}
+	}
+	return grad
+}
+
+// nodeDescription returns the kind of the node followed by its name, if any.
+func nodeDescription(node Node) string {
+	switch n := node.(type) {
+	case *Operator:
+		return "operator " + n.Name()
+	case *Variable:
+		if n.Name() == "" {
+			return "variable"
+		}
+		return "variable " + n.Name()
+	case *Wrapper:
+		if named, ok := n.GradValue.(interface{ Name() string }); ok {
+			return "wrapper " + named.Name()
+		}
+		return "wrapper"
+	default:
+		return fmt.Sprintf("%T", node)
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ag
+
+import (
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGraph_RegisterGradHook(t *testing.T) {
+	for _, concurrency := range []int{1, 4} {
+		g := NewGraph(ConcurrentComputations(concurrency))
+		x := g.NewVariable(mat.NewVecDense([]mat.Float{1, 2, 3}), true)
+		y := g.ProdScalar(x, g.NewScalar(10))
+		z := g.ReduceSum(y)
+
+		var seen mat.Matrix
+		y.(*Operator).RegisterGradHook(func(grad mat.Matrix) mat.Matrix {
+			seen = grad.Clone()
+			return grad.ProdScalarInPlace(0.5)
+		})
+		x.(*Variable).RegisterGradHook(func(grad mat.Matrix) mat.Matrix {
+			return grad.AddScalarInPlace(1)
+		})
+		g.Backward(z)
+
+		assert.InDeltaSlice(t, []mat.Float{1, 1, 1}, seen.Data(), 1.0e-6)
+		assert.InDeltaSlice(t, []mat.Float{0.5, 0.5, 0.5}, y.Grad().Data(), 1.0e-6)
+		assert.InDeltaSlice(t, []mat.Float{6, 6, 6}, x.Grad().Data(), 1.0e-6)
+
+		g.Clear()
+		assert.False(t, g.hasGradHooks(x.ID()))
+	}
+}
+
+func TestGraph_RegisterGlobalGradHook(t *testing.T) {
+	g := NewGraph()
+	x := g.NewVariable(mat.NewVecDense([]mat.Float{1, 2}), true)
+	w := g.NewWrap(g.NewVariable(mat.NewVecDense([]mat.Float{3, 4}), true))
+	z := g.ReduceSum(g.Prod(x, w))
+
+	visited := make(map[int]bool)
+	g.RegisterGlobalGradHook(func(node Node, grad mat.Matrix) mat.Matrix {
+		visited[node.ID()] = true
+		return grad.ProdScalarInPlace(2) // doubles the gradients at each node
+	})
+	g.Backward(z)
+
+	// z -> 2, prod -> 4, then the leaves double it again, and the wrapped
+	// variable, being a node of the graph too, doubles it once more
+	assert.InDeltaSlice(t, []mat.Float{24, 32}, x.Grad().Data(), 1.0e-6)
+	assert.InDeltaSlice(t, []mat.Float{16, 32}, w.Grad().Data(), 1.0e-6)
+	assert.True(t, visited[x.ID()])
+	assert.True(t, visited[w.ID()])
+	assert.True(t, visited[z.ID()])
+}
+
+func TestPanicOnInvalidGrad(t *testing.T) {
+	g := NewGraph()
+	g.RegisterGlobalGradHook(PanicOnInvalidGrad)
+	x := g.NewVariableWithName(mat.NewVecDense([]mat.Float{0, 1}), true, "x")
+	y := g.ReduceSum(g.Sqrt(x))
+	assert.PanicsWithValue(t, "ag: invalid gradient +Inf of the node #0 (variable x)", func() {
+		g.Backward(y)
+	})
+
+	assert.NotPanics(t, func() {
+		g := NewGraph()
+		g.RegisterGlobalGradHook(PanicOnInvalidGrad)
+		x := g.NewVariable(mat.NewVecDense([]mat.Float{1, 4}), true)
+		g.Backward(g.ReduceSum(g.Sqrt(x)))
+	})
+}
@@ -5,6 +5,7 @@
 package ag
 
 import (
+	"context"
 	"fmt"
 	mat "github.com/nlpodyssey/spago/pkg/mat32"
 	"github.com/nlpodyssey/spago/pkg/mat32/rand"
@@ -13,6 +14,8 @@ import (
 	"log"
 	"runtime"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // The Graph a.k.a. expression graph or computational graph is the centerpiece of the spaGO machine learning framework.
@@ -47,6 +50,54 @@ type Graph struct {
 	// such as forward and backward steps.
 	// The default size is defaultProcessingQueueSize.
 	processingQueue processingqueue.ProcessingQueue
+	// device is the mat.Device where the values are transferred by ToDevice() (default mat.Host).
+	device mat.Device
+	// checkpoints contains the segments defined by Checkpoint(), in order of definition.
+	checkpoints []*checkpoint
+	// checkpointing is true while a segment is being defined.
+	checkpointing bool
+	// noGradDepth is greater than zero while the gradients are disabled (see
+	// WithGrad and NoGradScope). It is accessed atomically.
+	noGradDepth int32
+	// gradNodes maps the node IDs to the nodes of their gradients, built by the
+	// last Backward() with the CreateGraph option.
+	gradNodes map[int]Node
+	// timings sets whether the operators record the time spent computing their values.
+	timings bool
+	// profiling sets whether the operators record the statistics reported by Profile.
+	profiling bool
+	// gradHooks contains the hooks registered on the nodes, by node ID.
+	gradHooks map[int][]GradHook
+	// globalGradHooks contains the hooks registered on the whole graph.
+	globalGradHooks []NodeGradHook
+	// anomalyDetection sets whether the values and the gradients are verified.
+	anomalyDetection bool
+	// optimizations contains the optimizations enabled by WithOptimization.
+	optimizations Optimization
+	// concurrentForward limits the workers computing the values in background,
+	// if the concurrent forward is enabled (see WithConcurrentForward).
+	concurrentForward processingqueue.ProcessingQueue
+	// pendingForward counts the values being computed in background.
+	pendingForward sync.WaitGroup
+	// mixedPrecision sets whether the values and the gradients of the operators
+	// are rounded to halfPrecision (see WithMixedPrecision).
+	mixedPrecision bool
+	halfPrecision  mat.HalfPrecision
+	// deterministicBackward sets whether the gradients are accumulated in a
+	// deterministic order (see WithDeterministicBackward).
+	deterministicBackward bool
+	// shapeChecking sets whether the shapes of the operands of the new
+	// operators are validated (see WithShapeChecking).
+	shapeChecking bool
+	// stepWindow is the number of time-steps kept in the incremental mode
+	// (see WithStepWindow).
+	stepWindow int
+	// stepCache contains the nodes kept across the time-steps, by key (see
+	// AppendCached).
+	stepCache map[string][]Node
+	// tracingCtx contains the parent span of the spans of the layers (see
+	// WithTracing).
+	tracingCtx context.Context
 }
 
 // defaultProcessingQueueSize is the default size of Graph.processingQueue on a new Graph.
@@ -91,6 +142,37 @@ func ConcurrentComputations(value int) GraphOption {
 	}
 }
 
+// WithDevice sets the mat.Device where the values are transferred by the ToDevice() operator.
+// The operations executed on the nodes whose values are associated with the device take place
+// on the device itself (see mat.Device).
+func WithDevice(device mat.Device) GraphOption {
+	return func(g *Graph) {
+		g.device = device
+	}
+}
+
+// WithGrad sets whether the graph records the operations for the gradients
+// computation (default true). Disabling it is recommended for inference-only
+// graphs (see NoGradScope).
+func WithGrad(enabled bool) GraphOption {
+	return func(g *Graph) {
+		if enabled {
+			g.noGradDepth = 0
+		} else {
+			g.noGradDepth = 1
+		}
+	}
+}
+
+// WithTimings sets whether the operators record the time spent in the forward
+// computation of their values (default false), available via Operator.Elapsed()
+// and in the exports of the graph (see ExportDOT and ExportJSON).
+func WithTimings(enabled bool) GraphOption {
+	return func(g *Graph) {
+		g.timings = enabled
+	}
+}
+
 // NewGraph returns a new initialized graph.
 // It can take an optional random generator of type rand.Rand.
 func NewGraph(opts ...GraphOption) *Graph {
@@ -101,6 +183,7 @@ func NewGraph(opts ...GraphOption) *Graph {
 		constants:          map[mat.Float]Node{},
 		incrementalForward: true,
 		processingQueue:    processingqueue.New(defaultProcessingQueueSize),
+		device:             mat.Host,
 	}
 	g.clearCache()
 	for _, opt := range opts {
@@ -112,6 +195,27 @@ func NewGraph(opts ...GraphOption) *Graph {
 	return g
 }
 
+// GradEnabled reports whether the graph is recording the operations for the
+// gradients computation, i.e. it has not been created with WithGrad(false),
+// and no NoGradScope is running.
+func (g *Graph) GradEnabled() bool {
+	return atomic.LoadInt32(&g.noGradDepth) == 0
+}
+
+// NoGradScope runs f with the gradients disabled: the operators created in
+// the meanwhile are computed immediately, even if the incremental forward is
+// disabled, but they neither keep their function and operands nor require
+// gradients, and the wrappers created by NewWrap() don't propagate gradients.
+// Their values are released by Clear() as usual, but they cannot be
+// recomputed by Forward(), so they are lost by ClearForReuse().
+// The scopes can be nested; note that the operators created concurrently by
+// other goroutines are affected as well.
+func (g *Graph) NoGradScope(f func()) {
+	atomic.AddInt32(&g.noGradDepth, 1)
+	defer atomic.AddInt32(&g.noGradDepth, -1)
+	f()
+}
+
 // IncrementalForwardEnabled returns whether the computation happens during the graph definition.
 // See ag.IncrementalForward() option.
 func (g *Graph) IncrementalForwardEnabled() bool {
@@ -127,6 +231,7 @@ func (g *Graph) IncrementalForwardEnabled() bool {
 // make always a copy of the return value of Value() or Grad().
 // Alternatively, you can use the convenient graph's methods g.GetCopiedValue(node) and g.GetCopiedGrad(node).
 func (g *Graph) Clear() {
+	g.WaitForward()
 	g.mu.Lock()
 	defer g.mu.Unlock()
 	if g.nodes == nil {
@@ -136,6 +241,10 @@ func (g *Graph) Clear() {
 	g.curTimeStep = 0
 	g.clearCache()
 	g.releaseMemory()
+	g.checkpoints = nil
+	g.gradNodes = nil
+	g.gradHooks = nil
+	g.stepCache = nil
 
 	for _, node := range g.nodes {
 		if node, ok := node.(*Operator); ok {
@@ -159,6 +268,7 @@ func (g *Graph) clearCache() {
 // This allows you to efficiently use the graph as if it were "pre-computed" (see the ForwardAll()
 // method for this usage).
 func (g *Graph) ClearForReuse() {
+	g.WaitForward()
 	g.mu.Lock()
 	defer g.mu.Unlock()
 	if g.nodes == nil {
@@ -272,11 +382,17 @@ func (g *Graph) NewOperator(f fn.Function, operands ...Node) Node {
 				"You may consider wrapping the nodes you need with NewWrap().")
 		}
 	}
+	shape, hasShape := g.inferShape(f, operands)
+	if !g.GradEnabled() {
+		return g.newNoGradOperator(f, operands, shape, hasShape)
+	}
 	var value mat.Matrix = nil
-	if g.incrementalForward {
+	var stats opStats
+	concurrent := g.incrementalForward && g.ConcurrentForwardEnabled()
+	if g.incrementalForward && !concurrent {
 		// the calculation is out of the lock so it can run concurrently with other operators
 		g.processingQueue.Run(func() {
-			value = f.Forward()
+			value, stats = g.compute(f)
 		})
 	}
 	requiresGrad := false
@@ -302,16 +418,85 @@ func (g *Graph) NewOperator(f fn.Function, operands ...Node) Node {
 		grad:         nil,
 		hasGrad:      false,
 		requiresGrad: requiresGrad,
+		stats:        stats,
+		shape:        shape,
+		hasShape:     hasShape,
 	}
 
 	// the new ID is sequential so it corresponds to the index in g.nodes
 	g.nodes = append(g.nodes, newNode)
+	if concurrent {
+		g.scheduleForward(newNode, f, operands)
+	}
+	if g.anomalyDetection && g.incrementalForward {
+		g.checkValue(newNode)
+	}
 	return newNode
 }
 
+// newNoGradOperator creates a new operator computing its value immediately,
+// without keeping the function and the operands, and not requiring gradients.
+func (g *Graph) newNoGradOperator(f fn.Function, operands []Node, shape fn.Shape, hasShape bool) Node {
+	var value mat.Matrix
+	var stats opStats
+	concurrent := g.ConcurrentForwardEnabled()
+	if !concurrent {
+		g.processingQueue.Run(func() {
+			value, stats = g.compute(f)
+		})
+	}
+	newNode := operatorPool.Get().(*Operator)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	*newNode = Operator{
+		graph:    g,
+		timeStep: g.curTimeStep,
+		id:       g.newID(),
+		value:    value,
+		stats:    stats,
+		shape:    shape,
+		hasShape: hasShape,
+	}
+	g.nodes = append(g.nodes, newNode)
+	if concurrent {
+		g.scheduleForward(newNode, f, operands)
+	}
+	if g.anomalyDetection {
+		g.checkNoGradValue(f, newNode)
+	}
+	return newNode
+}
+
+// compute runs the forward of the function, returning the time spent if the
+// timings are enabled, and the other statistics if the profiling is enabled.
+func (g *Graph) compute(f fn.Function) (mat.Matrix, opStats) {
+	if !g.timings && !g.profiling {
+		return g.roundToHalf(f.Forward()), opStats{}
+	}
+	var before runtime.MemStats
+	if g.profiling {
+		runtime.ReadMemStats(&before)
+	}
+	start := time.Now()
+	value := g.roundToHalf(f.Forward())
+	stats := opStats{elapsed: time.Since(start)}
+	if g.profiling {
+		var after runtime.MemStats
+		runtime.ReadMemStats(&after)
+		stats.allocs = after.Mallocs - before.Mallocs
+		stats.name = functionName(f)
+	}
+	return value, stats
+}
+
 // NewWrap creates a new wrapper Node for the given value, attaching it to
-// the graph.
+// the graph. While the gradients are disabled, it is equivalent to NewWrapNoGrad.
 func (g *Graph) NewWrap(value GradValue) Node {
+	if !g.GradEnabled() {
+		return g.NewWrapNoGrad(value)
+	}
 	g.mu.Lock()
 	defer g.mu.Unlock()
 	newNode := &Wrapper{
@@ -366,6 +551,7 @@ func Range(fromTimeStep, toTimeStep int) ForwardOption {
 // Usually you don't need to execute Forward() manually in the define-by-run configuration (default).
 // If you do, all values will be recalculated. You can also choose through the Range option to recalculate only a portion of nodes.
 // Instead, it is required to obtain the value of the nodes in case the Graph has been created with IncrementalForward(false).
+// With the FuseOps optimization, the chains of operators are fused before the computation (see FuseOps()).
 func (g *Graph) Forward(opts ...ForwardOption) {
 	handler := &forwardHandler{
 		g:            g,
@@ -375,21 +561,29 @@ func (g *Graph) Forward(opts ...ForwardOption) {
 	for _, opt := range opts {
 		opt(handler)
 	}
+	g.WaitForward()
+	if g.OptimizationEnabled(FuseOps) {
+		g.FuseOps()
+	}
 
 	// Free the values that are about to be recalculated so that memory is not wasted
 	for _, node := range g.nodes {
-		if op, ok := node.(*Operator); ok {
+		if op, ok := node.(*Operator); ok && op.function != nil {
 			if op.timeStep >= handler.fromTimeStep && (handler.toTimeStep == -1 || op.timeStep <= handler.toTimeStep) {
 				g.releaseValue(op)
+				op.pending = nil
 			}
 		}
 	}
 
-	if g.processingQueue.Size() > 1 {
+	if g.ConcurrentForwardEnabled() {
+		handler.runDataflow()
+	} else if g.processingQueue.Size() > 1 && !g.anomalyDetection {
 		handler.runConcurrent()
 	} else {
 		handler.runSerial()
 	}
+	g.discardCheckpoints()
 }
 
 // BackwardOption allows to adapt the Backward() to your specific needs.
@@ -429,6 +623,7 @@ func (g *Graph) Backward(node Node, opts ...BackwardOption) {
 	if node.Graph() != g {
 		panic("ag: backward cannot be executed among nodes of different graphs")
 	}
+	g.WaitForward()
 
 	handler := &backwardHandler{
 		g:              g,
@@ -439,30 +634,40 @@ func (g *Graph) Backward(node Node, opts ...BackwardOption) {
 	for _, opt := range opts {
 		opt(handler)
 	}
+	if handler.createGraph {
+		handler.runCreateGraph()
+		return
+	}
 	if !node.HasGrad() {
 		handler.propagateOutputGrad()
 	}
-	if g.processingQueue.Size() > 1 {
+	if g.anomalyDetection {
+		g.checkOutputGrad(node)
+	}
+	if g.concurrentBackward() {
 		handler.runConcurrent()
 	} else {
 		handler.runSerial()
 	}
+	g.discardCheckpoints()
 }
 
 // BackwardAll performs full back-propagation from the last node of the graph.
 // It requires the root nodes to have assigned gradients already.
 func (g *Graph) BackwardAll() {
+	g.WaitForward()
 	handler := &backwardHandler{
 		g:              g,
 		node:           g.nodes[g.maxID],
 		outputGrad:     nil,
 		stopAtTimeStep: -1, // no stop
 	}
-	if g.processingQueue.Size() > 1 {
+	if g.concurrentBackward() {
 		handler.runConcurrent()
 	} else {
 		handler.runSerial()
 	}
+	g.discardCheckpoints()
 }
 
 // GetCopiedValue returns a copy of the value of a Node. If the value is nil, GetCopiedValue returns nil as well.
@@ -498,8 +703,13 @@ func (g *Graph) ReplaceValue(node Node, value mat.Matrix) {
 }
 
 // IncTimeStep increments the value of the graph's TimeStep by one.
+// In the incremental mode, the time-steps older than the window are collected
+// (see WithStepWindow).
 func (g *Graph) IncTimeStep() {
 	g.curTimeStep++
+	if g.stepWindow > 0 {
+		g.TruncateBackward(g.stepWindow)
+	}
 }
 
 // TimeStep is an integer value associated with the graph, which can be useful
@@ -514,6 +724,12 @@ func (g *Graph) Nodes() []Node {
 	return g.nodes
 }
 
+// Device returns the mat.Device where the values are transferred by the ToDevice() operator.
+// See ag.WithDevice() option.
+func (g *Graph) Device() mat.Device {
+	return g.device
+}
+
 // ConcurrentComputations returns the maximum number of concurrent computations handled by the Graph
 // for heavy tasks such as forward and backward steps.
 func (g *Graph) ConcurrentComputations() int {
@@ -73,6 +73,36 @@ func TestNewGraph(t *testing.T) {
 		assert.True(t, g.incrementalForward)
 		assert.Equal(t, defaultProcessingQueueSize, g.ConcurrentComputations())
 	})
+
+	t.Run("with WithDevice option", func(t *testing.T) {
+		dev := testDevice{Device: mat.Host}
+		g := NewGraph(WithDevice(dev))
+		runCommonAssertions(t, g)
+		assert.Equal(t, mat.Device(dev), g.Device())
+	})
+}
+
+// testDevice executes the operations on the host, under another name.
+type testDevice struct {
+	mat.Device
+}
+
+func (testDevice) Name() string { return "test" }
+
+func TestGraph_ToDevice(t *testing.T) {
+	dev := testDevice{Device: mat.Host}
+	g := NewGraph(WithDevice(dev))
+	x := g.NewVariable(mat.NewVecDense([]mat.Float{1, 2}), true)
+	assert.Equal(t, mat.Host, NewGraph().Device())
+
+	y := g.ToDevice(x)
+	assert.Equal(t, mat.Device(dev), y.Value().(*mat.Dense).Device())
+	z := g.ToHost(y)
+	assert.Equal(t, mat.Host, z.Value().(*mat.Dense).Device())
+	assert.Equal(t, []mat.Float{1, 2}, z.Value().Data())
+
+	g.Backward(z, OutputGrad(mat.NewVecDense([]mat.Float{0.5, -0.5})))
+	assert.Equal(t, []mat.Float{0.5, -0.5}, x.Grad().Data())
 }
 
 func TestConcurrentComputations(t *testing.T) {
@@ -321,3 +351,54 @@ func TestGraph_Forward(t *testing.T) {
 	assert.NotNil(t, op.Value())
 	assert.Equal(t, mat.Float(42.0), op.Value().Scalar())
 }
+
+func TestGraph_DropoutReproducibility(t *testing.T) {
+	run := func(concurrency int) [][]mat.Float {
+		g := NewGraph(RandSeed(42), IncrementalForward(false), ConcurrentComputations(concurrency))
+		x := g.NewVariable(mat.NewInitVecDense(100, 1), false)
+		ys := make([]Node, 10)
+		for i := range ys {
+			ys[i] = g.Dropout(x, 0.5)
+		}
+		g.Forward()
+		out := make([][]mat.Float, len(ys))
+		for i, y := range ys {
+			out[i] = y.Value().Data()
+		}
+		return out
+	}
+	expected := run(1)
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, expected, run(8))
+	}
+}
+
+func TestGraph_ReduceAlong(t *testing.T) {
+	g := NewGraph()
+	// a sequence of three vectors, pooled by mean and max
+	x := g.NewVariable(mat.NewDense(3, 2, []mat.Float{
+		1, 6,
+		3, 2,
+		5, 4,
+	}), true)
+	mean := g.ReduceMeanAlong(x, fn.AlongColumns, false)
+	max := g.ReduceMaxAlong(x, fn.AlongColumns, false)
+	assert.Equal(t, []mat.Float{3, 4}, mean.Value().Data())
+	assert.Equal(t, []mat.Float{5, 6}, max.Value().Data())
+
+	g.Backward(g.ReduceSum(g.Concat(mean, max)))
+	assert.True(t, mat.SameDims(x.Value(), x.Grad()))
+	assert.InDeltaSlice(t, []mat.Float{
+		1.0 / 3, 1 + 1.0/3,
+		1.0 / 3, 1.0 / 3,
+		1 + 1.0/3, 1.0 / 3,
+	}, x.Grad().Data(), 1.0e-6)
+}
+
+func TestGraph_ReduceSum_Matrix(t *testing.T) {
+	g := NewGraph()
+	x := g.NewVariable(mat.NewDense(2, 2, []mat.Float{1, 2, 3, 4}), true)
+	g.Backward(g.ReduceSum(x))
+	assert.True(t, mat.SameDims(x.Value(), x.Grad()))
+	assert.Equal(t, []mat.Float{1, 1, 1, 1}, x.Grad().Data())
+}
@@ -17,14 +17,17 @@ type forwardHandler struct {
 
 func (h *forwardHandler) runSerial() {
 	for _, node := range h.g.nodes {
-		if op, ok := node.(*Operator); ok {
+		if op, ok := node.(*Operator); ok && op.function != nil && !op.fused {
 			if op.timeStep < h.fromTimeStep {
 				continue
 			}
 			if h.toTimeStep != -1 && op.timeStep > h.toTimeStep {
 				continue
 			}
-			op.value = op.function.Forward()
+			op.value, op.stats = h.g.compute(op.function)
+			if h.g.anomalyDetection {
+				h.g.checkValue(op)
+			}
 		}
 	}
 }
@@ -37,13 +40,13 @@ func (h *forwardHandler) runConcurrent() {
 	for _, group := range groups {
 		for _, node := range group {
 			op, isOperator := node.(*Operator)
-			if !isOperator || (op.timeStep < fromTS || (toTS != -1 && op.timeStep > toTS)) {
+			if !isOperator || op.function == nil || op.fused || (op.timeStep < fromTS || (toTS != -1 && op.timeStep > toTS)) {
 				continue
 			}
 			wg.Add(1)
 			h.g.processingQueue.Go(func() {
 				defer wg.Done()
-				op.value = op.function.Forward()
+				op.value, op.stats = h.g.compute(op.function)
 			})
 		}
 		wg.Wait()
@@ -54,7 +57,8 @@ type backwardHandler struct {
 	g              *Graph
 	node           Node
 	outputGrad     mat.Matrix
-	stopAtTimeStep int // default -1 (full backward)
+	stopAtTimeStep int  // default -1 (full backward)
+	createGraph    bool // default false
 }
 
 func (h *backwardHandler) propagateOutputGrad() {
@@ -72,22 +76,48 @@ func (h *backwardHandler) runSerial() {
 	stopAtTimeStep := h.stopAtTimeStep
 	truncated := stopAtTimeStep > -1
 	_ = nodes[lastIndex] // avoid bounds check
+	var current *checkpoint
 	for i := lastIndex; i >= 0; i-- {
 		if truncated && nodes[i].TimeStep() <= stopAtTimeStep {
 			break
 		}
+		if current != nil && i < current.from {
+			h.g.discard(current)
+			current = nil
+		}
 		if node, ok := nodes[i].(*Operator); ok {
+			if current == nil {
+				current = h.restoreCheckpointOf(node)
+			}
 			node.backward()
+			if h.g.anomalyDetection {
+				h.g.checkOperandsGrads(node)
+			}
 		}
 	}
 }
 
+// restoreCheckpointOf recomputes the discarded values of the checkpoint
+// containing the operator, if any, returning it.
+func (h *backwardHandler) restoreCheckpointOf(op *Operator) *checkpoint {
+	if len(h.g.checkpoints) == 0 {
+		return nil
+	}
+	c := h.g.checkpointOf(op.id)
+	if c != nil && !c.restored {
+		h.g.restore(c)
+	}
+	return c
+}
+
 func (h *backwardHandler) runConcurrent() {
 	stopAtTimeStep := h.stopAtTimeStep
 	truncated := stopAtTimeStep > -1
 	groups := h.g.groupNodesByHeight()
 	lastGroupIndex := h.g.cache.height[h.node.ID()]
 	lastNodeIndex := h.node.ID()
+	// the lowest height of the nodes of each restored checkpoint
+	restored := make(map[*checkpoint]int)
 	var wg sync.WaitGroup
 	for i := lastGroupIndex; i >= 0; i-- {
 		for _, node := range groups[i] {
@@ -101,6 +131,11 @@ func (h *backwardHandler) runConcurrent() {
 			if op.id > lastNodeIndex {
 				continue
 			}
+			if c := h.restoreCheckpointOf(op); c != nil {
+				if _, ok := restored[c]; !ok {
+					restored[c] = h.minHeight(c)
+				}
+			}
 			wg.Add(1)
 			h.g.processingQueue.Go(func() {
 				defer wg.Done()
@@ -108,5 +143,23 @@ func (h *backwardHandler) runConcurrent() {
 			})
 		}
 		wg.Wait()
+		for c, minHeight := range restored {
+			if i <= minHeight {
+				h.g.discard(c)
+				delete(restored, c)
+			}
+		}
+	}
+}
+
+// minHeight returns the lowest height of the nodes of the checkpoint.
+func (h *backwardHandler) minHeight(c *checkpoint) int {
+	height := h.g.cache.height
+	min := height[c.from]
+	for _, v := range height[c.from+1 : c.to+1] {
+		if v < min {
+			min = v
+		}
 	}
+	return min
 }
@@ -0,0 +1,220 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ag
+
+import (
+	"fmt"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/ml/ag/fn"
+)
+
+// CreateGraph is a backward option that builds the gradients as nodes of the
+// graph, so that they can be differentiated in turn (higher-order gradients),
+// e.g. for gradient-penalty losses, meta-learning (MAML) and Hessian-vector
+// products. The gradient of a node is returned by Graph.GradNode().
+//
+// As usual, the values of the gradients are accumulated to the leaf nodes
+// (variables and wrappers), whereas the operators are left without gradients,
+// so that a following Backward() does not propagate them again.
+//
+// With IncrementalForward(false), the values of the gradient nodes are
+// computed by the backward itself, after the ones of the graph (see Forward()).
+//
+// Only the operators with a differentiable gradient rule are supported
+// (arithmetic, matrix products, reductions, transposition and the most
+// common element-wise functions); it panics on the others.
+func CreateGraph() BackwardOption {
+	return func(f *backwardHandler) {
+		f.createGraph = true
+	}
+}
+
+// GradNode returns the node of the gradient of x computed by the last
+// Backward() with the CreateGraph option, or nil.
+func (g *Graph) GradNode(x Node) Node {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.gradNodes[x.ID()]
+}
+
+// runCreateGraph performs the back-propagation building the gradients as nodes.
+func (h *backwardHandler) runCreateGraph() {
+	g := h.g
+	gy := h.outputGrad
+	switch {
+	case h.node.HasGrad():
+		gy = h.node.Grad()
+	case gy == nil:
+		gy = h.node.Value().OnesLike()
+	}
+	g.mu.Lock()
+	first := g.maxID + 1 // the first node of the gradients
+	g.mu.Unlock()
+	grads := map[int]Node{h.node.ID(): g.NewVariable(gy.Clone(), false)}
+
+	for i := h.node.ID(); i >= 0; i-- {
+		node := g.nodes[i]
+		if h.stopAtTimeStep > -1 && node.TimeStep() <= h.stopAtTimeStep {
+			break
+		}
+		op, ok := node.(*Operator)
+		if !ok || !op.requiresGrad {
+			continue
+		}
+		gy, ok := grads[op.id]
+		if !ok {
+			continue
+		}
+		for j, gx := range g.gradientRule(op, gy) {
+			if gx == nil {
+				continue
+			}
+			id := op.operands[j].ID()
+			if acc, ok := grads[id]; ok {
+				gx = g.Add(acc, gx)
+			}
+			grads[id] = gx
+		}
+	}
+
+	if !g.incrementalForward {
+		g.forwardFrom(first)
+	}
+
+	g.mu.Lock()
+	g.gradNodes = grads
+	g.mu.Unlock()
+	for id, gx := range grads {
+		if node := g.nodes[id]; node.RequiresGrad() {
+			if _, isOperator := node.(*Operator); !isOperator {
+				node.PropagateGrad(gx.Value())
+			}
+		}
+	}
+}
+
+// forwardFrom computes the values of the operators from the given ID
+// onwards, which are left without values by the graphs created with
+// IncrementalForward(false), so that the gradients built by the backward with
+// the CreateGraph option can be accumulated to the leaf nodes.
+func (g *Graph) forwardFrom(id int) {
+	for _, node := range g.nodes[id:] {
+		if op, ok := node.(*Operator); ok && op.function != nil && !op.fused && op.value == nil {
+			op.value, op.stats = g.compute(op.function)
+			if g.anomalyDetection {
+				g.checkValue(op)
+			}
+		}
+	}
+}
+
+// gradientRule returns the gradients of the operands of op, as nodes, given
+// the gradient gy of its output. The gradients of the operands not requiring
+// them are nil.
+func (g *Graph) gradientRule(op *Operator, gy Node) []Node {
+	xs := op.operands
+	grads := make([]Node, len(xs))
+	// set assigns the gradient of the i-th operand, if required
+	set := func(i int, f func() Node) {
+		if xs[i].RequiresGrad() {
+			grads[i] = f()
+		}
+	}
+	switch op.function.(type) {
+	case *fn.Identity:
+		set(0, func() Node { return gy })
+	case *fn.Add:
+		set(0, func() Node { return gy })
+		set(1, func() Node { return gy })
+	case *fn.Sub:
+		set(0, func() Node { return gy })
+		set(1, func() Node { return g.Neg(gy) })
+	case *fn.Prod:
+		set(0, func() Node { return g.Prod(gy, xs[1]) })
+		set(1, func() Node { return g.Prod(gy, xs[0]) })
+	case *fn.Div:
+		set(0, func() Node { return g.Div(gy, xs[1]) })
+		set(1, func() Node { return g.Neg(g.Div(g.Prod(gy, xs[0]), g.Square(xs[1]))) })
+	case *fn.AddScalar:
+		set(0, func() Node { return gy })
+		set(1, func() Node { return g.ReduceSum(gy) })
+	case *fn.SubScalar:
+		set(0, func() Node { return gy })
+		set(1, func() Node { return g.Neg(g.ReduceSum(gy)) })
+	case *fn.ReverseSubScalar:
+		set(0, func() Node { return g.Neg(gy) })
+		set(1, func() Node { return g.ReduceSum(gy) })
+	case *fn.ProdScalar:
+		set(0, func() Node { return g.ProdScalar(gy, xs[1]) })
+		set(1, func() Node { return g.ReduceSum(g.Prod(gy, xs[0])) })
+	case *fn.DivScalar:
+		set(0, func() Node { return g.DivScalar(gy, xs[1]) })
+		set(1, func() Node { return g.Neg(g.DivScalar(g.ReduceSum(g.Prod(gy, xs[0])), g.Square(xs[1]))) })
+	case *fn.Mul:
+		set(0, func() Node { return g.MatMulT(gy, xs[1]) })
+		set(1, func() Node { return g.TMatMul(xs[0], gy) })
+	case *fn.MatMulT:
+		set(0, func() Node { return g.Mul(gy, xs[1]) })
+		set(1, func() Node { return g.TMatMul(gy, xs[0]) })
+	case *fn.TMatMul:
+		set(0, func() Node { return g.MatMulT(xs[1], gy) })
+		set(1, func() Node { return g.Mul(xs[0], gy) })
+	case *fn.Dot:
+		set(0, func() Node { return g.ProdScalar(xs[1], gy) })
+		set(1, func() Node { return g.ProdScalar(xs[0], gy) })
+	case *fn.Transpose:
+		set(0, func() Node { return g.T(gy) })
+	case *fn.ReduceSum:
+		set(0, func() Node { return g.ProdScalar(g.constantLike(xs[0], 1), gy) })
+	case *fn.ReduceMean:
+		set(0, func() Node {
+			n := mat.Float(xs[0].Value().Size())
+			return g.ProdScalar(g.constantLike(xs[0], 1/n), gy)
+		})
+	case *fn.Square:
+		set(0, func() Node { return g.ProdScalar(g.Prod(gy, xs[0]), g.Constant(2)) })
+	case *fn.Sqrt:
+		set(0, func() Node { return g.Div(gy, g.ProdScalar(op, g.Constant(2))) })
+	case *fn.Exp:
+		set(0, func() Node { return g.Prod(gy, op) })
+	case *fn.Log:
+		set(0, func() Node { return g.Div(gy, xs[0]) })
+	case *fn.Neg:
+		set(0, func() Node { return g.Neg(gy) })
+	case *fn.Reciprocal:
+		set(0, func() Node { return g.Neg(g.Prod(gy, g.Square(op))) })
+	case *fn.Sin:
+		set(0, func() Node { return g.Prod(gy, g.Cos(xs[0])) })
+	case *fn.Cos:
+		set(0, func() Node { return g.Neg(g.Prod(gy, g.Sin(xs[0]))) })
+	case *fn.Tanh:
+		set(0, func() Node { return g.Prod(gy, g.ReverseSub(g.Square(op), g.Constant(1))) })
+	case *fn.Sigmoid:
+		set(0, func() Node { return g.Prod(gy, g.Prod(op, g.ReverseSub(op, g.Constant(1)))) })
+	case *fn.ReLU:
+		set(0, func() Node {
+			mask := xs[0].Value().ZerosLike()
+			mask.Apply(func(_, _ int, v mat.Float) mat.Float {
+				if v > 0 {
+					return 1
+				}
+				return 0
+			}, xs[0].Value())
+			return g.Prod(gy, g.NewVariable(mask, false))
+		})
+	case *fn.Softmax:
+		set(0, func() Node { return g.Prod(op, g.SubScalar(gy, g.ReduceSum(g.Prod(gy, op)))) })
+	default:
+		panic(fmt.Sprintf("ag: the operator %s does not support higher-order gradients", op.Name()))
+	}
+	return grads
+}
+
+// constantLike returns a new variable not requiring gradients, with the same
+// dimensions of x, filled with the given value.
+func (g *Graph) constantLike(x Node, value mat.Float) Node {
+	return g.NewVariable(mat.NewInitDense(x.Value().Rows(), x.Value().Columns(), value), false)
+}
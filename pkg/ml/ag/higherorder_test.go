@@ -0,0 +1,85 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ag
+
+import (
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGraph_BackwardCreateGraph(t *testing.T) {
+	// run builds a small network, returning the loss and the parameters
+	run := func(g *Graph) (Node, Node, Node) {
+		w := g.NewVariable(mat.NewDense(2, 3, []mat.Float{0.5, -0.3, 0.8, 0.1, 0.2, -0.6}), true)
+		x := g.NewVariable(mat.NewVecDense([]mat.Float{0.2, -0.7, 0.4}), true)
+		h := g.Tanh(g.Mul(w, x))
+		y := g.Softmax(g.Add(g.Sigmoid(h), g.Exp(g.Neg(g.Square(h)))))
+		loss := g.ReduceMean(g.Prod(y, g.Log(g.AddScalar(y, g.Constant(1)))))
+		return loss, w, x
+	}
+
+	g := NewGraph()
+	loss, w, x := run(g)
+	g.Backward(loss)
+
+	g2 := NewGraph()
+	loss2, w2, x2 := run(g2)
+	g2.Backward(loss2, CreateGraph())
+	assert.InDeltaSlice(t, w.Grad().Data(), w2.Grad().Data(), 1.0e-6)
+	assert.InDeltaSlice(t, x.Grad().Data(), x2.Grad().Data(), 1.0e-6)
+	assert.InDeltaSlice(t, w.Grad().Data(), g2.GradNode(w2).Value().Data(), 1.0e-6)
+	assert.InDeltaSlice(t, x.Grad().Data(), g2.GradNode(x2).Value().Data(), 1.0e-6)
+	assert.Nil(t, g2.GradNode(g2.Constant(1)))
+}
+
+func TestGraph_HessianVectorProduct(t *testing.T) {
+	g := NewGraph()
+	x := g.NewVariable(mat.NewVecDense([]mat.Float{1, 2, -3}), true)
+	v := g.NewVariable(mat.NewVecDense([]mat.Float{0.5, -1, 2}), false)
+	y := g.ReduceSum(g.Prod(g.Square(x), x)) // the Hessian is diag(6x)
+	g.Backward(y, CreateGraph())
+	assert.InDeltaSlice(t, []mat.Float{3, 12, 27}, g.GradNode(x).Value().Data(), 1.0e-6)
+
+	g.ZeroGrad()
+	g.Backward(g.Dot(g.GradNode(x), v))
+	assert.InDeltaSlice(t, []mat.Float{3, -12, -36}, x.Grad().Data(), 1.0e-6)
+}
+
+func TestGraph_SecondDerivative(t *testing.T) {
+	g := NewGraph()
+	x := g.NewVariable(mat.NewScalar(0.5), true)
+	g.Backward(g.Sin(x), CreateGraph())
+	dx := g.GradNode(x)
+	assert.InDelta(t, mat.Cos(0.5), dx.ScalarValue(), 1.0e-6)
+
+	g.ZeroGrad()
+	g.Backward(dx)
+	assert.InDelta(t, -mat.Sin(0.5), x.Grad().Scalar(), 1.0e-6)
+}
+
+func TestGraph_BackwardCreateGraphUnsupported(t *testing.T) {
+	g := NewGraph()
+	x := g.NewVariable(mat.NewVecDense([]mat.Float{1, 2}), true)
+	y := g.ReduceSum(g.Pow(x, 3))
+	assert.Panics(t, func() { g.Backward(y, CreateGraph()) })
+}
+
+func TestGraph_BackwardCreateGraphNotIncremental(t *testing.T) {
+	g := NewGraph(IncrementalForward(false))
+	x := g.NewVariable(mat.NewVecDense([]mat.Float{1, 2, -3}), true)
+	y := g.ReduceSum(g.Prod(g.Square(x), x))
+	g.Forward()
+	g.Backward(y, CreateGraph())
+	assert.InDeltaSlice(t, []mat.Float{3, 12, 27}, x.Grad().Data(), 1.0e-6)
+	assert.InDeltaSlice(t, []mat.Float{3, 12, 27}, g.GradNode(x).Value().Data(), 1.0e-6)
+
+	g.ZeroGrad()
+	z := g.ReduceSum(g.GradNode(x))
+	g.Forward()
+	g.Backward(z)
+	assert.InDeltaSlice(t, []mat.Float{6, 12, -18}, x.Grad().Data(), 1.0e-6)
+}
@@ -0,0 +1,81 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ag
+
+// WithStepWindow enables the incremental mode of the graph for the streaming
+// inference (e.g. the autoregressive decoding), where the graph grows by one
+// time-step at a time: at each IncTimeStep, the nodes of the time-steps older
+// than the given window are collected (see TruncateBackward), so that only
+// the last steps time-steps are kept, the new one included.
+// The nodes of the previous time-steps needed by the next ones (e.g. the
+// keys and the values of the attention) must be stored in the step cache
+// (see AppendCached), so that they are kept with their values instead of
+// being recomputed. The default value 0 disables the mode.
+//
+// A typical decoding loop looks like this:
+//
+//     g := ag.NewGraph(ag.WithGrad(false), ag.WithStepWindow(2)) // x is kept
+//     for i := 0; i < maxLength; i++ {
+//         g.IncTimeStep()
+//         k, v := decoder.KeyValue(x)
+//         g.AppendCached("k", k)
+//         g.AppendCached("v", v)
+//         x = decoder.Attend(q, g.Cached("k"), g.Cached("v"))
+//     }
+func WithStepWindow(steps int) GraphOption {
+	if steps < 0 {
+		panic("ag: WithStepWindow steps must be greater than or equal to zero")
+	}
+	return func(g *Graph) {
+		g.stepWindow = steps
+	}
+}
+
+// StepWindow returns the number of time-steps kept in the incremental mode,
+// or 0 if the mode is disabled (see WithStepWindow).
+func (g *Graph) StepWindow() int {
+	return g.stepWindow
+}
+
+// AppendCached appends the nodes to the step cache under the given key. The
+// cached nodes are kept, with their values, when the older time-steps are
+// collected by TruncateBackward or by the incremental mode (see
+// WithStepWindow), so that they can be reused by the next time-steps.
+func (g *Graph) AppendCached(key string, nodes ...Node) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.stepCache == nil {
+		g.stepCache = make(map[string][]Node)
+	}
+	g.stepCache[key] = append(g.stepCache[key], nodes...)
+}
+
+// Cached returns the nodes appended to the step cache under the given key,
+// in order of insertion.
+func (g *Graph) Cached(key string) []Node {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	nodes := g.stepCache[key]
+	return nodes[:len(nodes):len(nodes)]
+}
+
+// DropCached removes the given key from the step cache, so that its nodes
+// can be collected.
+func (g *Graph) DropCached(key string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.stepCache, key)
+}
+
+// cachedNodes returns the set of the nodes in the step cache.
+func (g *Graph) cachedNodes() map[Node]bool {
+	nodes := make(map[Node]bool)
+	for _, cached := range g.stepCache {
+		for _, node := range cached {
+			nodes[node] = true
+		}
+	}
+	return nodes
+}
@@ -0,0 +1,80 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ag
+
+import (
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// decode runs a toy autoregressive decoder for the given number of steps,
+// attending to the cached keys, and returns the outputs.
+func decode(g *Graph, w Node, steps int) []mat.Float {
+	x := g.NewScalar(1)
+	var ys []mat.Float
+	for i := 0; i < steps; i++ {
+		g.IncTimeStep()
+		g.AppendCached("k", g.Tanh(g.Prod(w, x)))
+		x = g.ReduceSum(g.Softmax(g.Concat(g.Cached("k")...)))
+		x = g.Add(x, g.Constant(mat.Float(i)))
+		ys = append(ys, x.ScalarValue())
+	}
+	return ys
+}
+
+func TestWithStepWindow(t *testing.T) {
+	w := NewGraph().NewVariable(mat.NewScalar(0.5), true)
+
+	expected := NewGraph(WithGrad(false))
+	ys := decode(expected, expected.NewWrap(w), 10)
+
+	g := NewGraph(WithGrad(false), WithStepWindow(2))
+	assert.Equal(t, 2, g.StepWindow())
+	assert.InDeltaSlice(t, ys, decode(g, g.NewWrap(w), 10), 1.0e-6)
+	assertSequentialIDs(t, g)
+
+	// the graph doesn't grow beyond the window, except for the cached nodes
+	assert.True(t, len(g.Nodes()) < len(expected.Nodes())/2)
+	assert.Len(t, g.Cached("k"), 10)
+	for _, k := range g.Cached("k") {
+		assert.NotNil(t, k.Value())
+	}
+	for _, node := range g.Nodes() {
+		if _, ok := node.(*Operator); ok {
+			assert.True(t, node.TimeStep() >= 9 || g.cachedNodes()[node])
+		}
+	}
+
+	assert.Panics(t, func() { WithStepWindow(-1) })
+}
+
+func TestGraph_AppendCached(t *testing.T) {
+	g := NewGraph()
+	a := g.NewScalar(1)
+	b := g.NewScalar(2)
+	assert.Empty(t, g.Cached("x"))
+	g.AppendCached("x", a)
+	g.AppendCached("x", b)
+	cached := g.Cached("x")
+	require.Len(t, cached, 2)
+	assert.Same(t, a, cached[0])
+	assert.Same(t, b, cached[1])
+
+	g.IncTimeStep()
+	g.TruncateBackward(0)
+	assert.Len(t, g.Nodes(), 2)
+
+	g.DropCached("x")
+	assert.Empty(t, g.Cached("x"))
+	g.TruncateBackward(0)
+	assert.Empty(t, g.Nodes())
+
+	g.AppendCached("x", g.NewScalar(3))
+	g.Clear()
+	assert.Empty(t, g.Cached("x"))
+}
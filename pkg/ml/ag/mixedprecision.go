@@ -0,0 +1,42 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ag
+
+import (
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+)
+
+// WithMixedPrecision enables the mixed-precision mode (disabled by default):
+// the values computed by the operators, and the gradients propagated to
+// them, are rounded to the given 16-bit format, while the variables and the
+// wrapped values (e.g. the parameters of the models, acting as master
+// weights) and their gradients keep the full precision of mat.Float.
+//
+// The rounding reproduces the numerics of a half-precision computation,
+// where the small gradients of the activations underflow to zero, and the
+// large values overflow to infinity with mat.Float16: the loss is usually
+// scaled to prevent it (see gd.LossScaler). The values are still stored as
+// mat.Float, so the mode doesn't reduce the memory.
+func WithMixedPrecision(format mat.HalfPrecision) GraphOption {
+	return func(g *Graph) {
+		g.mixedPrecision = true
+		g.halfPrecision = format
+	}
+}
+
+// MixedPrecision returns the 16-bit format of the mixed-precision mode, and
+// whether the mode is enabled (see WithMixedPrecision).
+func (g *Graph) MixedPrecision() (format mat.HalfPrecision, enabled bool) {
+	return g.halfPrecision, g.mixedPrecision
+}
+
+// roundToHalf rounds the values of the matrix in place to the 16-bit format
+// of the mixed-precision mode, if enabled, returning the matrix itself.
+func (g *Graph) roundToHalf(m mat.Matrix) mat.Matrix {
+	if !g.mixedPrecision || m == nil {
+		return m
+	}
+	return g.halfPrecision.RoundInPlace(m)
+}
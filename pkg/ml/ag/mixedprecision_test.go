@@ -0,0 +1,44 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ag
+
+import (
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithMixedPrecision(t *testing.T) {
+	_, enabled := NewGraph().MixedPrecision()
+	assert.False(t, enabled)
+	format, enabled := NewGraph(WithMixedPrecision(mat.BFloat16)).MixedPrecision()
+	assert.True(t, enabled)
+	assert.Equal(t, mat.BFloat16, format)
+}
+
+func TestGraph_MixedPrecision(t *testing.T) {
+	g := NewGraph(WithMixedPrecision(mat.Float16))
+	// the variables keep the full precision
+	x := g.NewVariable(mat.NewVecDense([]mat.Float{1 + 1.0/4096, 300}), true)
+	assert.Equal(t, []mat.Float{1 + 1.0/4096, 300}, x.Value().Data())
+
+	y := g.Identity(x)
+	assert.Equal(t, []mat.Float{1, 300}, y.Value().Data())
+	z := g.Square(x) // 300² overflows
+	assert.Equal(t, mat.Inf(1), z.Value().Data()[1])
+
+	// the gradients of the operators are rounded, the ones of the variables are not
+	g.Backward(y, OutputGrad(mat.NewVecDense([]mat.Float{1 + 1.0/4096, 1.0e-8})))
+	assert.Equal(t, []mat.Float{1, 0}, y.Grad().Data())
+	assert.Equal(t, []mat.Float{1, 0}, x.Grad().Data())
+	x.PropagateGrad(mat.NewVecDense([]mat.Float{1.0 / 4096, 0}))
+	assert.Equal(t, []mat.Float{1 + 1.0/4096, 0}, x.Grad().Data())
+
+	// the recomputed values are rounded as well
+	g.ClearForReuse()
+	g.Forward()
+	assert.Equal(t, []mat.Float{1, 300}, y.Value().Data())
+}
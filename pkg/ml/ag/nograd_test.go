@@ -0,0 +1,80 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ag
+
+import (
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGraph_NoGradScope(t *testing.T) {
+	g := NewGraph(IncrementalForward(false))
+	x := g.NewVariable(mat.NewVecDense([]mat.Float{1, 2}), true)
+	assert.True(t, g.GradEnabled())
+
+	var y, z Node
+	g.NoGradScope(func() {
+		assert.False(t, g.GradEnabled())
+		g.NoGradScope(func() {
+			y = g.Square(x)
+		})
+		assert.False(t, g.GradEnabled())
+		z = g.ReduceSum(y)
+	})
+	assert.True(t, g.GradEnabled())
+
+	// the values are computed immediately, despite IncrementalForward(false)
+	assert.Equal(t, []mat.Float{1, 4}, y.Value().Data())
+	assert.Equal(t, mat.Float(5), z.ScalarValue())
+	assert.False(t, z.RequiresGrad())
+	assert.Empty(t, z.(*Operator).Operands())
+	assert.Equal(t, "", z.(*Operator).Name())
+
+	g.Forward()
+	assert.Equal(t, mat.Float(5), z.ScalarValue())
+	g.Backward(z)
+	assert.Nil(t, x.Grad())
+
+	w := g.Prod(x, x)
+	assert.True(t, w.RequiresGrad())
+}
+
+func TestWithGrad(t *testing.T) {
+	g := NewGraph(WithGrad(false))
+	assert.False(t, g.GradEnabled())
+	x := g.NewVariable(mat.NewVecDense([]mat.Float{1, 2}), true)
+	w := g.NewWrap(x)
+	assert.False(t, w.RequiresGrad())
+	y := g.Prod(x, x)
+	assert.False(t, y.RequiresGrad())
+	assert.Equal(t, []mat.Float{1, 4}, y.Value().Data())
+
+	assert.True(t, NewGraph(WithGrad(true)).GradEnabled())
+}
+
+func TestGraph_Detach(t *testing.T) {
+	g := NewGraph()
+	x := g.NewVariable(mat.NewVecDense([]mat.Float{1, 2}), true)
+	d := g.Detach(g.Square(x))
+	assert.False(t, d.RequiresGrad())
+	assert.Equal(t, []mat.Float{1, 4}, d.Value().Data())
+	assert.Len(t, d.(*Operator).Operands(), 1)
+
+	// y = x^2 + stop(x^2): the gradients only flow through the first term
+	y := g.ReduceSum(g.Add(g.Square(x), d))
+	g.Backward(y)
+	assert.Equal(t, []mat.Float{2, 4}, x.Grad().Data())
+
+	// the straight-through estimator of a function (the gradient is the identity)
+	g2 := NewGraph(IncrementalForward(false))
+	x2 := g2.NewVariable(mat.NewVecDense([]mat.Float{-0.4, 1.7}), true)
+	st := g2.Add(x2, g2.Detach(g2.Sub(g2.Abs(x2), x2)))
+	g2.Forward()
+	assert.InDeltaSlice(t, []mat.Float{0.4, 1.7}, st.Value().Data(), 1.0e-6)
+	g2.Backward(st, OutputGrad(mat.NewVecDense([]mat.Float{1, 3})))
+	assert.InDeltaSlice(t, []mat.Float{1, 3}, x2.Grad().Data(), 1.0e-6)
+}
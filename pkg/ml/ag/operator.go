@@ -9,6 +9,7 @@ import (
 	"github.com/nlpodyssey/spago/pkg/ml/ag/fn"
 	"reflect"
 	"sync"
+	"time"
 )
 
 var (
@@ -35,6 +36,16 @@ type Operator struct {
 	grad         mat.Matrix // TODO: support of sparse gradients
 	hasGrad      bool
 	requiresGrad bool
+	stats        opStats // statistics of the last forward computation
+	// pending tracks the computation of the value in background, if any (see WithConcurrentForward).
+	pending *pendingValue
+	// shape is the shape of the value inferred at creation, if hasShape (see WithShapeChecking).
+	shape    fn.Shape
+	hasShape bool
+	// fused is true if the operator is an intermediate of a chain fused by
+	// FuseOps: its value is not computed by the forward, but when it's read.
+	fused   bool
+	fusedMu sync.Mutex // to compute the value of a fused operator only once
 }
 
 // ID returns the ID of the node in the graph.
@@ -43,9 +54,48 @@ func (r *Operator) ID() int {
 }
 
 // Name returns the Name of the operator.
-// The name is taken from the name of r.function via reflection.
+// The name is taken from the name of r.function via reflection, or from its
+// Name method if any (e.g. the custom operators); it is empty for the
+// operators created while the gradients are disabled.
 func (r *Operator) Name() string {
-	return reflect.ValueOf(r.function).Elem().Type().Name()
+	if r.function == nil {
+		return ""
+	}
+	return functionName(r.function)
+}
+
+// functionName returns the name of the function: the result of its Name
+// method if any, otherwise the name of its type.
+func functionName(f fn.Function) string {
+	if named, ok := f.(interface{ Name() string }); ok {
+		return named.Name()
+	}
+	return reflect.ValueOf(f).Elem().Type().Name()
+}
+
+// Elapsed returns the time spent in the last forward computation of the
+// value. It is always zero unless the graph has been created with the
+// WithTimings option, or the profiling is enabled.
+func (r *Operator) Elapsed() time.Duration {
+	r.wait()
+	return r.stats.elapsed
+}
+
+// Allocs returns the number of heap allocations performed during the last
+// forward computation of the value. It is always zero unless the profiling
+// of the graph is enabled (see Graph.EnableProfiling).
+func (r *Operator) Allocs() uint64 {
+	r.wait()
+	return r.stats.allocs
+}
+
+// opStats contains the statistics of the forward computation of an operator.
+type opStats struct {
+	elapsed time.Duration
+	allocs  uint64
+	// name is the name of the function, recorded only while profiling, since
+	// the operators created while the gradients are disabled don't keep it.
+	name string
 }
 
 // Graph returns the graph this node belongs to.
@@ -54,15 +104,38 @@ func (r *Operator) Graph() *Graph {
 }
 
 // Value returns the cached result of the function.
+// With the concurrent forward, it blocks until the value has been computed.
+// The value of an intermediate operator of a chain fused by FuseOps is
+// computed when it's first read.
 func (r *Operator) Value() mat.Matrix {
+	r.wait()
+	if r.fused {
+		r.computeFused()
+	}
 	return r.value
 }
 
+// computeFused computes the value of a fused operator, if not available.
+func (r *Operator) computeFused() {
+	r.fusedMu.Lock()
+	defer r.fusedMu.Unlock()
+	if r.value == nil {
+		r.value, r.stats = r.graph.compute(r.function)
+	}
+}
+
+// wait blocks until the value computed in background, if any, is available.
+func (r *Operator) wait() {
+	if r.pending != nil {
+		r.pending.wait()
+	}
+}
+
 // ScalarValue returns the the scalar value of the node.
 // It panics if the value is not a scalar.
 // Note that it is not possible to start the backward step from a scalar value.
 func (r *Operator) ScalarValue() mat.Float {
-	return r.value.Scalar()
+	return r.Value().Scalar()
 }
 
 // Grad returns the gradients accumulated during the backward pass.
@@ -78,9 +151,10 @@ func (r *Operator) PropagateGrad(grad mat.Matrix) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	if r.grad == nil {
-		r.grad = mat.GetEmptyDenseWorkspace(r.value.Dims()) // this could reduce the number of allocations
+		r.grad = mat.GetEmptyDenseWorkspace(r.Value().Dims()) // this could reduce the number of allocations
 	}
 	r.grad.AddInPlace(grad)
+	r.graph.roundToHalf(r.grad)
 	r.hasGrad = true
 }
 
@@ -118,5 +192,8 @@ func (r *Operator) backward() {
 	if !r.hasGrad {
 		return
 	}
+	if r.graph.hasGradHooks(r.id) {
+		r.grad = r.graph.applyGradHooks(r, r.grad)
+	}
 	r.function.Backward(r.grad)
 }
@@ -147,74 +147,160 @@ const (
 	OpConcat
 	// OpStack identifies the Graph.Stack operator.
 	OpStack
+	// OpTopK identifies the Graph.TopK operator.
+	OpTopK
+	// OpSort identifies the Graph.Sort operator.
+	OpSort
+	// OpMaskedFill identifies the Graph.MaskedFill operator.
+	OpMaskedFill
+	// OpMaskedSoftmax identifies the Graph.MaskedSoftmax operator.
+	OpMaskedSoftmax
+	// OpMaskedMean identifies the Graph.MaskedMean operator.
+	OpMaskedMean
+	// OpMatMulT identifies the Graph.MatMulT operator.
+	OpMatMulT
+	// OpTMatMul identifies the Graph.TMatMul operator.
+	OpTMatMul
+	// OpGather identifies the Graph.Gather operator.
+	OpGather
+	// OpIndexSelect identifies the Graph.IndexSelect operator.
+	OpIndexSelect
+	// OpScatterAdd identifies the Graph.ScatterAdd operator.
+	OpScatterAdd
+	// OpWhere identifies the Graph.Where operator.
+	OpWhere
+	// OpCumSum identifies the Graph.CumSum operator.
+	OpCumSum
+	// OpCumProd identifies the Graph.CumProd operator.
+	OpCumProd
+	// OpSegmentSum identifies the Graph.SegmentSum operator.
+	OpSegmentSum
+	// OpDetach identifies the Graph.Detach operator.
+	OpDetach
+	// OpLongConv identifies the Graph.LongConv operator.
+	OpLongConv
+	// OpSolve identifies the Graph.Solve operator.
+	OpSolve
+	// OpInverse identifies the Graph.Inverse operator.
+	OpInverse
+	// OpPInv identifies the Graph.PInv operator.
+	OpPInv
+	// OpArgMax identifies the Graph.ArgMax operator.
+	OpArgMax
+	// OpOneHot identifies the Graph.OneHot operator.
+	OpOneHot
+	// OpStraightThrough identifies the Graph.StraightThrough operator.
+	OpStraightThrough
+	// OpReduceSumAlong identifies the Graph.ReduceSumAlong operator.
+	OpReduceSumAlong
+	// OpReduceMeanAlong identifies the Graph.ReduceMeanAlong operator.
+	OpReduceMeanAlong
+	// OpReduceMaxAlong identifies the Graph.ReduceMaxAlong operator.
+	OpReduceMaxAlong
+	// OpReduceMinAlong identifies the Graph.ReduceMinAlong operator.
+	OpReduceMinAlong
+	// OpLayerNorm identifies the Graph.LayerNorm operator.
+	OpLayerNorm
+	// OpSoftmaxCrossEntropy identifies the Graph.SoftmaxCrossEntropy operator.
+	OpSoftmaxCrossEntropy
 )
 
 var opNameToMethodName = map[OpName]string{
-	OpIdentity:      "Identity",
-	OpDropout:       "Dropout",
-	OpAtVec:         "AtVec",
-	OpAt:            "At",
-	OpAdd:           "Add",
-	OpSub:           "Sub",
-	OpSubScalar:     "SubScalar",
-	OpAddScalar:     "AddScalar",
-	OpReverseSub:    "ReverseSub",
-	OpProd:          "Prod",
-	OpDiv:           "Div",
-	OpProdScalar:    "ProdScalar",
-	OpDivScalar:     "DivScalar",
-	OpMul:           "Mul",
-	OpDot:           "Dot",
-	OpReshape:       "Reshape",
-	OpMaxPooling:    "MaxPooling",
-	OpView:          "View",
-	OpRowView:       "RowView",
-	OpColView:       "ColView",
-	OpVec:           "Vec",
-	OpRotateR:       "RotateR",
-	OpT:             "T",
-	OpSquare:        "Square",
-	OpPow:           "Pow",
-	OpSqrt:          "Sqrt",
-	OpTan:           "Tan",
-	OpTanh:          "Tanh",
-	OpSigmoid:       "Sigmoid",
-	OpHardSigmoid:   "HardSigmoid",
-	OpHardTanh:      "HardTanh",
-	OpSoftsign:      "Softsign",
-	OpReLU:          "ReLU",
-	OpCELU:          "CELU",
-	OpGELU:          "GELU",
-	OpELU:           "ELU",
-	OpPositiveELU:   "PositiveELU",
-	OpSwishB:        "SwishB",
-	OpSwish:         "Swish",
-	OpSiLU:          "SiLU",
-	OpMish:          "Mish",
-	OpLeakyReLU:     "LeakyReLU",
-	OpSELU:          "SELU",
-	OpSoftPlus:      "SoftPlus",
-	OpSoftShrink:    "SoftShrink",
-	OpThreshold:     "Threshold",
-	OpSoftmax:       "Softmax",
-	OpLogSoftmax:    "LogSoftmax",
-	OpSparseMax:     "SparseMax",
-	OpSparseMaxLoss: "SparseMaxLoss",
-	OpSin:           "Sin",
-	OpCos:           "Cos",
-	OpExp:           "Exp",
-	OpLog:           "Log",
-	OpAbs:           "Abs",
-	OpNeg:           "Neg",
-	OpReciprocal:    "Reciprocal",
-	OpMax:           "Max",
-	OpMin:           "Min",
-	OpReduceSum:     "ReduceSum",
-	OpReduceMean:    "ReduceMean",
-	OpMean:          "Mean",
-	OpSum:           "Sum",
-	OpConcat:        "Concat",
-	OpStack:         "Stack",
+	OpIdentity:        "Identity",
+	OpDropout:         "Dropout",
+	OpAtVec:           "AtVec",
+	OpAt:              "At",
+	OpAdd:             "Add",
+	OpSub:             "Sub",
+	OpSubScalar:       "SubScalar",
+	OpAddScalar:       "AddScalar",
+	OpReverseSub:      "ReverseSub",
+	OpProd:            "Prod",
+	OpDiv:             "Div",
+	OpProdScalar:      "ProdScalar",
+	OpDivScalar:       "DivScalar",
+	OpMul:             "Mul",
+	OpDot:             "Dot",
+	OpReshape:         "Reshape",
+	OpMaxPooling:      "MaxPooling",
+	OpView:            "View",
+	OpRowView:         "RowView",
+	OpColView:         "ColView",
+	OpVec:             "Vec",
+	OpRotateR:         "RotateR",
+	OpT:               "T",
+	OpSquare:          "Square",
+	OpPow:             "Pow",
+	OpSqrt:            "Sqrt",
+	OpTan:             "Tan",
+	OpTanh:            "Tanh",
+	OpSigmoid:         "Sigmoid",
+	OpHardSigmoid:     "HardSigmoid",
+	OpHardTanh:        "HardTanh",
+	OpSoftsign:        "Softsign",
+	OpReLU:            "ReLU",
+	OpCELU:            "CELU",
+	OpGELU:            "GELU",
+	OpELU:             "ELU",
+	OpPositiveELU:     "PositiveELU",
+	OpSwishB:          "SwishB",
+	OpSwish:           "Swish",
+	OpSiLU:            "SiLU",
+	OpMish:            "Mish",
+	OpLeakyReLU:       "LeakyReLU",
+	OpSELU:            "SELU",
+	OpSoftPlus:        "SoftPlus",
+	OpSoftShrink:      "SoftShrink",
+	OpThreshold:       "Threshold",
+	OpSoftmax:         "Softmax",
+	OpLogSoftmax:      "LogSoftmax",
+	OpSparseMax:       "SparseMax",
+	OpSparseMaxLoss:   "SparseMaxLoss",
+	OpSin:             "Sin",
+	OpCos:             "Cos",
+	OpExp:             "Exp",
+	OpLog:             "Log",
+	OpAbs:             "Abs",
+	OpNeg:             "Neg",
+	OpReciprocal:      "Reciprocal",
+	OpMax:             "Max",
+	OpMin:             "Min",
+	OpReduceSum:       "ReduceSum",
+	OpReduceMean:      "ReduceMean",
+	OpMean:            "Mean",
+	OpSum:             "Sum",
+	OpConcat:          "Concat",
+	OpStack:           "Stack",
+	OpTopK:            "TopK",
+	OpSort:            "Sort",
+	OpMaskedFill:      "MaskedFill",
+	OpMaskedSoftmax:   "MaskedSoftmax",
+	OpMaskedMean:      "MaskedMean",
+	OpMatMulT:         "MatMulT",
+	OpTMatMul:         "TMatMul",
+	OpGather:          "Gather",
+	OpIndexSelect:     "IndexSelect",
+	OpScatterAdd:      "ScatterAdd",
+	OpWhere:           "Where",
+	OpCumSum:          "CumSum",
+	OpCumProd:         "CumProd",
+	OpSegmentSum:      "SegmentSum",
+	OpDetach:          "Detach",
+	OpLongConv:        "LongConv",
+	OpSolve:           "Solve",
+	OpInverse:         "Inverse",
+	OpPInv:            "PInv",
+	OpArgMax:          "ArgMax",
+	OpOneHot:          "OneHot",
+	OpStraightThrough: "StraightThrough",
+	OpReduceSumAlong:  "ReduceSumAlong",
+	OpReduceMeanAlong: "ReduceMeanAlong",
+	OpReduceMaxAlong:  "ReduceMaxAlong",
+	OpReduceMinAlong:  "ReduceMinAlong",
+
+	// fused operators
+	OpLayerNorm:           "LayerNorm",
+	OpSoftmaxCrossEntropy: "SoftmaxCrossEntropy",
 }
 
 // strToOpName is the inverse map of opNameToMethodName.
@@ -252,9 +338,28 @@ func (g *Graph) Identity(x Node) Node {
 	return g.NewOperator(fn.NewIdentity(x), x)
 }
 
+// Detach returns a new operator node as a result of the fn.StopGrad function,
+// holding a copy of the value of x, which doesn't require gradients and never
+// propagates them to x (e.g. for the target networks, the EMA teachers, and
+// the straight-through estimators, as in x + Detach(Round(x) - x)).
+func (g *Graph) Detach(x Node) Node {
+	return withoutGrad(g.NewOperator(fn.NewStopGrad(x), x))
+}
+
+// withoutGrad marks the operator y, whose function doesn't propagate the
+// gradients, as not requiring them.
+func withoutGrad(y Node) Node {
+	if op, ok := y.(*Operator); ok {
+		op.requiresGrad = false
+	}
+	return y
+}
+
 // Dropout returns a new operator node as a result of the fn.Dropout function.
+// Each operator gets its own generator spawned from the graph's one, so that the
+// dropout masks don't depend on the order of execution of concurrent computations.
 func (g *Graph) Dropout(x Node, p mat.Float) Node {
-	return g.NewOperator(fn.NewDropout(x, p, g.randGen), x)
+	return g.NewOperator(fn.NewDropout(x, p, g.randGen.Spawn()), x)
 }
 
 // AtVec returns a new operator node as a result of the fn.AtVec function.
@@ -322,6 +427,18 @@ func (g *Graph) Mul(x1 Node, x2 Node) Node {
 	return g.NewOperator(fn.NewMul(x1, x2), x1, x2)
 }
 
+// MatMulT returns a new operator node as a result of the fn.MatMulT function,
+// i.e. x1×x2ᵀ, without materializing the transpose of x2.
+func (g *Graph) MatMulT(x1 Node, x2 Node) Node {
+	return g.NewOperator(fn.NewMatMulT(x1, x2), x1, x2)
+}
+
+// TMatMul returns a new operator node as a result of the fn.TMatMul function,
+// i.e. x1ᵀ×x2, without materializing the transpose of x1.
+func (g *Graph) TMatMul(x1 Node, x2 Node) Node {
+	return g.NewOperator(fn.NewTMatMul(x1, x2), x1, x2)
+}
+
 // Dot returns a new operator node as a result of the fn.Dot function.
 func (g *Graph) Dot(x1 Node, x2 Node) Node {
 	return g.NewOperator(fn.NewDot(x1, x2), x1, x2)
@@ -347,6 +464,25 @@ func (g *Graph) MaxPooling(x Node, rows, columns int) Node {
 	return g.NewOperator(fn.NewMaxPooling(x, rows, columns), x)
 }
 
+// Conv2D returns a new operator node as a result of the fn.Conv2D function.
+// The bias b can be nil.
+func (g *Graph) Conv2D(x, w, b Node, config fn.Conv2DConfig) Node {
+	if b == nil {
+		return g.NewOperator(fn.NewConv2D(x, w, nil, config), x, w)
+	}
+	return g.NewOperator(fn.NewConv2D(x, w, b, config), x, w, b)
+}
+
+// MaxPool2D returns a new operator node as a result of the fn.MaxPool2D function.
+func (g *Graph) MaxPool2D(x Node, config fn.Pool2DConfig) Node {
+	return g.NewOperator(fn.NewMaxPool2D(x, config), x)
+}
+
+// AvgPool2D returns a new operator node as a result of the fn.AvgPool2D function.
+func (g *Graph) AvgPool2D(x Node, config fn.Pool2DConfig) Node {
+	return g.NewOperator(fn.NewAvgPool2D(x, config), x)
+}
+
 // View returns a new operator node as a result of the fn.View function.
 func (g *Graph) View(x Node, row, column, xStride, yStride int) Node {
 	return g.NewOperator(fn.NewView(x, row, column, xStride, yStride), x)
@@ -357,6 +493,139 @@ func (g *Graph) RowView(x Node, row int) Node {
 	return g.NewOperator(fn.NewRowView(x, row), x)
 }
 
+// LayerNorm returns a new operator node as a result of the fn.LayerNorm
+// function, normalizing all the elements of x in a single fused operation.
+// The scalar eps is added to the variance for numerical stability.
+func (g *Graph) LayerNorm(x, w, b, eps Node) Node {
+	return g.NewOperator(fn.NewLayerNorm(x, w, b, eps), x, w, b, eps)
+}
+
+// SoftmaxCrossEntropy returns a new operator node as a result of the
+// fn.SoftmaxCrossEntropy function, i.e. the cross-entropy loss of the
+// logits x with respect to the target class.
+func (g *Graph) SoftmaxCrossEntropy(x Node, target int) Node {
+	return g.NewOperator(fn.NewSoftmaxCrossEntropy(x, target), x)
+}
+
+// Gather returns a new operator node as a result of the fn.Gather function.
+func (g *Graph) Gather(x Node, indices []int) Node {
+	return g.NewOperator(fn.NewGather(x, indices), x)
+}
+
+// IndexSelect returns a new operator node as a result of the fn.IndexSelect function.
+func (g *Graph) IndexSelect(x Node, indices []int) Node {
+	return g.NewOperator(fn.NewIndexSelect(x, indices), x)
+}
+
+// ScatterAdd returns a new operator node as a result of the fn.ScatterAdd function.
+func (g *Graph) ScatterAdd(x, src Node, indices []int) Node {
+	return g.NewOperator(fn.NewScatterAdd(x, src, indices), x, src)
+}
+
+// Where returns a new operator node as a result of the fn.Where function,
+// selecting the elements of a where cond is not zero, and of b elsewhere.
+// Either a or b can be a scalar.
+func (g *Graph) Where(cond, a, b Node) Node {
+	return g.NewOperator(fn.NewWhere(cond, a, b), cond, a, b)
+}
+
+// LongConv returns a new operator node as a result of the fn.LongConv
+// function, convolving each channel (row) of x with the corresponding kernel
+// (row) of k, causally and in the frequency domain.
+func (g *Graph) LongConv(x, k Node) Node {
+	return g.NewOperator(fn.NewLongConv(x, k), x, k)
+}
+
+// Solve returns a new operator node as a result of the fn.Solve function,
+// solving the linear system a × y = b, where a is a square non-singular
+// matrix and the columns of b are the right-hand sides.
+func (g *Graph) Solve(a, b Node) Node {
+	return g.NewOperator(fn.NewSolve(a, b), a, b)
+}
+
+// Inverse returns a new operator node as a result of the fn.Inverse function.
+func (g *Graph) Inverse(x Node) Node {
+	return g.NewOperator(fn.NewInverse(x), x)
+}
+
+// PInv returns a new operator node as a result of the fn.PInv function,
+// holding the Moore-Penrose pseudo-inverse of x.
+func (g *Graph) PInv(x Node) Node {
+	return g.NewOperator(fn.NewPInv(x), x)
+}
+
+// ArgMax returns a new operator node as a result of the fn.ArgMax function,
+// holding the indices of the greatest elements of each column (fn.AlongColumns)
+// or row (fn.AlongRows) of x. It doesn't require gradients.
+func (g *Graph) ArgMax(x Node, axis fn.Axis) Node {
+	return withoutGrad(g.NewOperator(fn.NewArgMax(x, axis), x))
+}
+
+// OneHot returns a new operator node as a result of the fn.OneHot function,
+// holding the one-hot encoding of the indices of x in columns (fn.AlongColumns)
+// or rows (fn.AlongRows) of the given size. It doesn't require gradients.
+func (g *Graph) OneHot(x Node, size int, axis fn.Axis) Node {
+	return withoutGrad(g.NewOperator(fn.NewOneHot(x, size, axis), x))
+}
+
+// StraightThrough returns a new operator node as a result of the
+// fn.StraightThrough function, holding the value of x while passing the
+// gradients through to the surrogate (e.g. the hard selection of a hard
+// attention, and the soft attention weights).
+func (g *Graph) StraightThrough(x, surrogate Node) Node {
+	return g.NewOperator(fn.NewStraightThrough(x, surrogate), x, surrogate)
+}
+
+// CumSum returns a new operator node as a result of the fn.CumSum function,
+// holding the cumulative sums of each column (fn.AlongColumns) or row
+// (fn.AlongRows) of x.
+func (g *Graph) CumSum(x Node, axis fn.Axis) Node {
+	return g.NewOperator(fn.NewCumSum(x, axis), x)
+}
+
+// CumProd returns a new operator node as a result of the fn.CumProd function,
+// holding the cumulative products of each column (fn.AlongColumns) or row
+// (fn.AlongRows) of x.
+func (g *Graph) CumProd(x Node, axis fn.Axis) Node {
+	return g.NewOperator(fn.NewCumProd(x, axis), x)
+}
+
+// SegmentSum returns a new operator node as a result of the fn.SegmentSum
+// function, summing the rows of x into n rows, by the given segment indices.
+func (g *Graph) SegmentSum(x Node, segments []int, n int) Node {
+	return g.NewOperator(fn.NewSegmentSum(x, segments, n), x)
+}
+
+// TopK returns a new operator node as a result of the fn.TopK function,
+// holding the k greatest values of x in descending order.
+// The indices of the selected elements are the ones returned by mat.TopK().
+func (g *Graph) TopK(x Node, k int) Node {
+	return g.NewOperator(fn.NewTopK(x, k), x)
+}
+
+// Sort returns a new operator node as a result of the fn.Sort function.
+func (g *Graph) Sort(x Node, descending bool) Node {
+	return g.NewOperator(fn.NewSort(x, descending), x)
+}
+
+// MaskedFill returns a new operator node as a result of the fn.MaskedFill function.
+// The zero values of the mask mark the elements of x to replace with value.
+func (g *Graph) MaskedFill(x Node, mask mat.Matrix, value mat.Float) Node {
+	return g.NewOperator(fn.NewMaskedFill(x, mask, value), x)
+}
+
+// MaskedSoftmax returns a new operator node as a result of the fn.MaskedSoftmax function.
+// The zero values of the mask mark the elements of x to exclude.
+func (g *Graph) MaskedSoftmax(x Node, mask mat.Matrix) Node {
+	return g.NewOperator(fn.NewMaskedSoftmax(x, mask), x)
+}
+
+// MaskedMean returns a new operator node as a result of the fn.MaskedMean function.
+// The zero values of the mask mark the elements of x to exclude.
+func (g *Graph) MaskedMean(x Node, mask mat.Matrix) Node {
+	return g.NewOperator(fn.NewMaskedMean(x, mask), x)
+}
+
 // RotateR performs the right circular shift.
 // `i` is the number of places by which the elements are shifted.
 func (g *Graph) RotateR(x Node, i int) Node {
@@ -548,6 +817,34 @@ func (g *Graph) ReduceMean(x Node) Node {
 	return g.NewOperator(fn.NewReduceMean(x), x)
 }
 
+// ReduceSumAlong returns a new operator node as a result of the fn.ReduceAlong
+// function, holding the sums of each column (fn.AlongColumns) or row
+// (fn.AlongRows) of x, keeping the reduced dimension with keepDims.
+func (g *Graph) ReduceSumAlong(x Node, axis fn.Axis, keepDims bool) Node {
+	return g.NewOperator(fn.NewReduceSumAlong(x, axis, keepDims), x)
+}
+
+// ReduceMeanAlong returns a new operator node as a result of the
+// fn.ReduceAlong function, holding the means of each column (fn.AlongColumns)
+// or row (fn.AlongRows) of x, keeping the reduced dimension with keepDims.
+func (g *Graph) ReduceMeanAlong(x Node, axis fn.Axis, keepDims bool) Node {
+	return g.NewOperator(fn.NewReduceMeanAlong(x, axis, keepDims), x)
+}
+
+// ReduceMaxAlong returns a new operator node as a result of the fn.ReduceAlong
+// function, holding the greatest elements of each column (fn.AlongColumns) or
+// row (fn.AlongRows) of x, keeping the reduced dimension with keepDims.
+func (g *Graph) ReduceMaxAlong(x Node, axis fn.Axis, keepDims bool) Node {
+	return g.NewOperator(fn.NewReduceMaxAlong(x, axis, keepDims), x)
+}
+
+// ReduceMinAlong returns a new operator node as a result of the fn.ReduceAlong
+// function, holding the smallest elements of each column (fn.AlongColumns) or
+// row (fn.AlongRows) of x, keeping the reduced dimension with keepDims.
+func (g *Graph) ReduceMinAlong(x Node, axis fn.Axis, keepDims bool) Node {
+	return g.NewOperator(fn.NewReduceMinAlong(x, axis, keepDims), x)
+}
+
 // Concat returns a new operator node as a result of the fn.Concat function.
 func (g *Graph) Concat(xs ...Node) Node {
 	return g.NewOperator(fn.NewConcat(Operands(xs)), xs...)
@@ -557,3 +854,32 @@ func (g *Graph) Concat(xs ...Node) Node {
 func (g *Graph) Stack(xs ...Node) Node {
 	return g.NewOperator(fn.NewStack(Operands(xs)), xs...)
 }
+
+// Custom returns a new operator node as a result of the custom operator
+// registered with the given name (see fn.Register).
+func (g *Graph) Custom(name string, xs ...Node) Node {
+	return g.NewOperator(fn.NewCustom(name, Operands(xs)), xs...)
+}
+
+// ToDevice returns a new operator node as a result of the fn.ToDevice function,
+// transferring the value of x to the graph's device (see ag.WithDevice()).
+func (g *Graph) ToDevice(x Node) Node {
+	return g.NewOperator(fn.NewToDevice(x, g.device), x)
+}
+
+// ToHost returns a new operator node as a result of the fn.ToDevice function,
+// transferring the value of x back to the host.
+func (g *Graph) ToHost(x Node) Node {
+	return g.NewOperator(fn.NewToDevice(x, mat.Host), x)
+}
+
+// Einsum returns a new operator node as a result of the fn.Einsum function.
+func (g *Graph) Einsum(equation string, xs ...Node) Node {
+	return g.NewOperator(fn.NewEinsum(equation, Operands(xs)...), xs...)
+}
+
+// EinsumWithSizes returns a new operator node as a result of the fn.Einsum function,
+// with the sizes of some subscripts given explicitly (see fn.NewEinsumWithSizes).
+func (g *Graph) EinsumWithSizes(equation string, sizes map[byte]int, xs ...Node) Node {
+	return g.NewOperator(fn.NewEinsumWithSizes(equation, sizes, Operands(xs)...), xs...)
+}
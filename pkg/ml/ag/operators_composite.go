@@ -4,7 +4,11 @@
 
 package ag
 
-import mat "github.com/nlpodyssey/spago/pkg/mat32"
+import (
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/mat32/rand/gumbel"
+	"github.com/nlpodyssey/spago/pkg/ml/ag/fn"
+)
 
 // PositiveELU returns a new operator node as a result of ELU(x) + 1.
 func (g *Graph) PositiveELU(x Node) Node {
@@ -34,3 +38,42 @@ func (g *Graph) Mean(xs []Node) Node {
 	}
 	return g.DivScalar(sumVector, g.Constant(mat.Float(len(xs))))
 }
+
+// LeastSquares returns a new operator node holding the solution y of the
+// (regularized) linear least squares problem min ‖a × y - b‖² + lambda ‖y‖²,
+// where the columns of b are the targets. With lambda > 0 it's the closed-form
+// solution of the ridge regression, (aᵀ × a + lambda I)⁻¹ × aᵀ × b; with
+// lambda = 0 it's the minimum-norm solution a⁺ × b.
+// The gradients are propagated to both a and b, so that e.g. a ridge
+// regression head can be fitted in the graph on the features of a model.
+func (g *Graph) LeastSquares(a, b Node, lambda mat.Float) Node {
+	if lambda < 0 {
+		panic("ag: LeastSquares lambda must be greater than or equal to zero")
+	}
+	if lambda == 0 {
+		return g.Mul(g.PInv(a), b)
+	}
+	n := a.Value().Columns()
+	reg := g.NewVariable(mat.I(n).ProdScalarInPlace(lambda), false)
+	return g.Solve(g.Add(g.TMatMul(a, a), reg), g.TMatMul(a, b))
+}
+
+// GumbelSoftmax returns a new operator node holding a sample of the
+// Gumbel-softmax (or Concrete) distribution parameterized by the logits x (a
+// vector): the softmax of (x + g) / temperature, where g is Gumbel noise
+// drawn from the graph's random generator. The lower the temperature, the
+// closer the sample is to a one-hot vector.
+// If hard is true, the sample is the one-hot vector of the greatest element
+// instead, while the gradients are passed through to the soft sample (the
+// straight-through Gumbel-softmax estimator).
+func (g *Graph) GumbelSoftmax(x Node, temperature mat.Float, hard bool) Node {
+	if temperature <= 0 {
+		panic("ag: GumbelSoftmax temperature must be greater than zero")
+	}
+	noise := g.NewVariable(gumbel.Distribution(x.Value().Rows(), x.Value().Columns(), g.randGen.Spawn()), false)
+	y := g.Softmax(g.DivScalar(g.Add(x, noise), g.Constant(temperature)))
+	if !hard {
+		return y
+	}
+	return g.StraightThrough(g.OneHot(g.ArgMax(y, fn.AlongColumns), y.Value().Size(), fn.AlongColumns), y)
+}
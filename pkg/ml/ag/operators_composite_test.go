@@ -0,0 +1,58 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ag
+
+import (
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/ml/ag/fn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGraph_LeastSquares(t *testing.T) {
+	g := NewGraph()
+	// y = 2 x + 1, exactly
+	a := g.NewVariable(mat.NewDense(3, 2, []mat.Float{
+		1, 0,
+		1, 1,
+		1, 2,
+	}), true)
+	b := g.NewVariable(mat.NewVecDense([]mat.Float{1, 3, 5}), true)
+
+	w := g.LeastSquares(a, b, 0)
+	assert.InDeltaSlice(t, []mat.Float{1, 2}, w.Value().Data(), 1.0e-5)
+
+	// (aᵀa + I)⁻¹ aᵀb = [[4, 3], [3, 6]]⁻¹ [9, 13]
+	ridge := g.LeastSquares(a, b, 1)
+	assert.InDeltaSlice(t, []mat.Float{1, 5.0 / 3}, ridge.Value().Data(), 1.0e-5)
+
+	// the residuals of the exact fit don't depend on the targets
+	g.Backward(g.ReduceSum(g.Square(g.Sub(g.Mul(a, w), b))))
+	assert.InDeltaSlice(t, []mat.Float{0, 0, 0}, b.Grad().Data(), 1.0e-4)
+
+	assert.Panics(t, func() { g.LeastSquares(a, b, -1) })
+}
+
+func TestGraph_GumbelSoftmax(t *testing.T) {
+	g := NewGraph(RandSeed(42))
+	x := g.NewVariable(mat.NewVecDense([]mat.Float{1, 2, 0.5}), true)
+
+	soft := g.GumbelSoftmax(x, 0.5, false)
+	assert.InDelta(t, 1, soft.Value().Sum(), 1.0e-6)
+
+	hard := g.GumbelSoftmax(x, 0.5, true)
+	assert.InDelta(t, 1, hard.Value().Sum(), 1.0e-6)
+	assert.True(t, hard.RequiresGrad())
+	assert.False(t, g.ArgMax(hard, fn.AlongColumns).RequiresGrad())
+	assert.Equal(t, mat.Float(1), hard.Value().Data()[int(g.ArgMax(hard, fn.AlongColumns).ScalarValue())])
+
+	// the gradients are passed through the one-hot sample
+	g.Backward(g.ReduceSum(g.Prod(hard, g.NewVariable(mat.NewVecDense([]mat.Float{1, 2, 3}), false))))
+	assert.NotNil(t, x.Grad())
+	assert.Greater(t, x.Grad().Norm(2), mat.Float(0))
+
+	assert.Panics(t, func() { g.GumbelSoftmax(x, 0, false) })
+}
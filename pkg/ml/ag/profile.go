@@ -0,0 +1,155 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ag
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+	"time"
+)
+
+// EnableProfiling enables the recording, for each operator computed from now
+// on, of the time spent computing its value and of the number of heap
+// allocations, which are reported by Profile.
+// The allocations are counted process-wide, so with concurrent computations
+// they include those of the other operators computed at the same time; since
+// their counting stops the world, the profiling slows down the computations.
+// It must not be called while the graph is computing.
+func (g *Graph) EnableProfiling() {
+	g.profiling = true
+}
+
+// DisableProfiling disables the profiling enabled by EnableProfiling.
+// The statistics already recorded are kept.
+func (g *Graph) DisableProfiling() {
+	g.profiling = false
+}
+
+// ProfilingEnabled returns whether the profiling is enabled.
+func (g *Graph) ProfilingEnabled() bool {
+	return g.profiling
+}
+
+// OperatorProfile contains the statistics of the last forward computation of
+// an operator.
+type OperatorProfile struct {
+	// ID is the ID of the operator in the graph.
+	ID int
+	// Name is the name of the function of the operator.
+	Name string
+	// TimeStep is the time step of the operator.
+	TimeStep int
+	// Rows and Columns are the shape of the output.
+	Rows, Columns int
+	// Elapsed is the time spent computing the output.
+	Elapsed time.Duration
+	// Allocs is the number of heap allocations.
+	Allocs uint64
+}
+
+// OperatorTypeProfile contains the statistics aggregated by operator type.
+type OperatorTypeProfile struct {
+	// Name is the name of the functions of the operators.
+	Name string
+	// Count is the number of operators.
+	Count int
+	// Elapsed is the total time spent computing the outputs.
+	Elapsed time.Duration
+	// Allocs is the total number of heap allocations.
+	Allocs uint64
+}
+
+// Profile is the report of the profiling of a graph.
+type Profile struct {
+	// Operators contains the statistics of each profiled operator, in order
+	// of ID.
+	Operators []OperatorProfile
+	// ByType contains the statistics aggregated by operator type, sorted by
+	// decreasing time.
+	ByType []OperatorTypeProfile
+	// Elapsed is the total time spent computing the operators.
+	Elapsed time.Duration
+}
+
+// Profile returns the statistics recorded while the profiling was enabled
+// (see EnableProfiling), for the operators of the graph whose value has been
+// computed.
+func (g *Graph) Profile() *Profile {
+	g.WaitForward()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	p := &Profile{
+		Operators: make([]OperatorProfile, 0),
+		ByType:    make([]OperatorTypeProfile, 0),
+	}
+	byType := make(map[string]int) // index in p.ByType
+	for _, node := range g.nodes {
+		op, ok := node.(*Operator)
+		if !ok || op.stats.name == "" || op.value == nil {
+			continue
+		}
+		rows, cols := op.value.Dims()
+		p.Operators = append(p.Operators, OperatorProfile{
+			ID:       op.id,
+			Name:     op.stats.name,
+			TimeStep: op.timeStep,
+			Rows:     rows,
+			Columns:  cols,
+			Elapsed:  op.stats.elapsed,
+			Allocs:   op.stats.allocs,
+		})
+		i, ok := byType[op.stats.name]
+		if !ok {
+			i = len(p.ByType)
+			byType[op.stats.name] = i
+			p.ByType = append(p.ByType, OperatorTypeProfile{Name: op.stats.name})
+		}
+		p.ByType[i].Count++
+		p.ByType[i].Elapsed += op.stats.elapsed
+		p.ByType[i].Allocs += op.stats.allocs
+		p.Elapsed += op.stats.elapsed
+	}
+	sort.SliceStable(p.ByType, func(i, j int) bool {
+		return p.ByType[i].Elapsed > p.ByType[j].Elapsed
+	})
+	return p
+}
+
+// WriteReport writes a table of the statistics aggregated by operator type,
+// sorted by decreasing time, followed by the slowest operators, at most top.
+func (p *Profile) WriteReport(w io.Writer, top int) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "operator\tcount\ttime\ttime %\tmean time\tallocs\t")
+	for _, t := range p.ByType {
+		fmt.Fprintf(tw, "%s\t%d\t%v\t%.1f\t%v\t%d\t\n",
+			t.Name, t.Count, t.Elapsed, p.percentage(t.Elapsed), t.Elapsed/time.Duration(t.Count), t.Allocs)
+	}
+	if top > 0 && len(p.Operators) > 0 {
+		slowest := make([]OperatorProfile, len(p.Operators))
+		copy(slowest, p.Operators)
+		sort.SliceStable(slowest, func(i, j int) bool {
+			return slowest[i].Elapsed > slowest[j].Elapsed
+		})
+		if len(slowest) > top {
+			slowest = slowest[:top]
+		}
+		fmt.Fprintln(tw, "\t\t\t\t\t\t")
+		fmt.Fprintln(tw, "node\toperator\ttime step\tshape\ttime\tallocs\t")
+		for _, op := range slowest {
+			fmt.Fprintf(tw, "#%d\t%s\t%d\t%d × %d\t%v\t%d\t\n",
+				op.ID, op.Name, op.TimeStep, op.Rows, op.Columns, op.Elapsed, op.Allocs)
+		}
+	}
+	return tw.Flush()
+}
+
+func (p *Profile) percentage(d time.Duration) float64 {
+	if p.Elapsed == 0 {
+		return 0
+	}
+	return 100 * float64(d) / float64(p.Elapsed)
+}
@@ -0,0 +1,69 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ag
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGraph_Profile(t *testing.T) {
+	g := NewGraph()
+	x := g.NewVariable(mat.NewVecDense([]mat.Float{1, 2, 3}), true)
+	w := g.NewVariable(mat.NewDense(2, 3, []mat.Float{1, 2, 3, 4, 5, 6}), true)
+	g.Tanh(g.Mul(w, x)) // not profiled
+	assert.False(t, g.ProfilingEnabled())
+
+	g.EnableProfiling()
+	assert.True(t, g.ProfilingEnabled())
+	y := g.Mul(w, x)
+	g.Tanh(y)
+	g.Tanh(g.Square(x))
+	g.DisableProfiling()
+
+	p := g.Profile()
+	require.Len(t, p.Operators, 4)
+	assert.Equal(t, "Mul", p.Operators[0].Name)
+	assert.Equal(t, y.ID(), p.Operators[0].ID)
+	assert.Equal(t, 2, p.Operators[0].Rows)
+	assert.Equal(t, 1, p.Operators[0].Columns)
+	assert.Greater(t, int64(p.Operators[0].Elapsed), int64(0))
+	assert.Greater(t, p.Operators[0].Allocs, uint64(0))
+	assert.Equal(t, p.Operators[0].Allocs, y.(*Operator).Allocs())
+
+	require.Len(t, p.ByType, 3)
+	var total int
+	for _, typ := range p.ByType {
+		total += typ.Count
+		if typ.Name == "Tanh" {
+			assert.Equal(t, 2, typ.Count)
+		}
+	}
+	assert.Equal(t, 4, total)
+	assert.True(t, p.ByType[0].Elapsed >= p.ByType[1].Elapsed)
+
+	var buf bytes.Buffer
+	require.NoError(t, p.WriteReport(&buf, 2))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 1+3+1+1+2)
+	assert.Contains(t, lines[0], "operator")
+	assert.Contains(t, lines[5], "shape")
+	assert.Contains(t, lines[6], " × 1")
+}
+
+func TestGraph_Profile_NoGrad(t *testing.T) {
+	g := NewGraph(WithGrad(false))
+	g.EnableProfiling()
+	g.Tanh(g.NewVariable(mat.NewVecDense([]mat.Float{1, 2, 3}), false))
+
+	p := g.Profile()
+	require.Len(t, p.Operators, 1)
+	assert.Equal(t, "Tanh", p.Operators[0].Name)
+}
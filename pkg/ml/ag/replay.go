@@ -0,0 +1,88 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ag
+
+import (
+	"fmt"
+	"sync"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+)
+
+// Replayer recomputes a captured graph with new values of its inputs,
+// without building the nodes again (see Graph.Capture).
+type Replayer struct {
+	mu      sync.Mutex
+	g       *Graph
+	inputs  []*Variable
+	outputs []Node
+}
+
+// Capture returns a Replayer of the graph, that is the "trace" of the
+// computation from the input variables to the outputs, which can be
+// replayed for new inputs of the same shapes, e.g. to serve fixed-size
+// requests without building the graph of a model for each of them.
+//
+// The inputs must be variables of the graph with a value, and the graph
+// must not contain operators created while the gradients are disabled
+// (see NoGradScope), since they cannot be recomputed. The whole graph is
+// recomputed at each run, so the nodes added after the capture are
+// recomputed as well.
+func (g *Graph) Capture(inputs []Node, outputs []Node) (*Replayer, error) {
+	r := &Replayer{
+		g:       g,
+		inputs:  make([]*Variable, len(inputs)),
+		outputs: outputs,
+	}
+	for i, input := range inputs {
+		v, ok := input.(*Variable)
+		if !ok || v.graph != g || v.value == nil {
+			return nil, fmt.Errorf("ag: the input %d is not a variable of the graph with a value", i)
+		}
+		r.inputs[i] = v
+	}
+	for i, output := range outputs {
+		if output.Graph() != g {
+			return nil, fmt.Errorf("ag: the output %d doesn't belong to the graph", i)
+		}
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, node := range g.nodes {
+		if op, ok := node.(*Operator); ok && op.function == nil {
+			return nil, fmt.Errorf("ag: the operator %d cannot be recomputed", op.id)
+		}
+	}
+	return r, nil
+}
+
+// Run sets the values of the inputs, recomputes the graph, and returns a
+// copy of the values of the outputs. The values of the inputs are copied, so
+// they must have the same shapes of the captured ones.
+// It is safe for concurrent use, but the runs are serialized.
+func (r *Replayer) Run(inputs ...mat.Matrix) ([]mat.Matrix, error) {
+	if len(inputs) != len(r.inputs) {
+		return nil, fmt.Errorf("ag: expected %d inputs, found %d", len(r.inputs), len(inputs))
+	}
+	for i, input := range inputs {
+		if !mat.SameDims(input, r.inputs[i].value) {
+			return nil, fmt.Errorf("ag: the input %d has shape %d×%d, expected %d×%d", i,
+				input.Rows(), input.Columns(), r.inputs[i].value.Rows(), r.inputs[i].value.Columns())
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.g.ClearForReuse()
+	for i, input := range inputs {
+		r.inputs[i].value.SetData(input.Data())
+	}
+	r.g.Forward()
+	outputs := make([]mat.Matrix, len(r.outputs))
+	for i, output := range r.outputs {
+		outputs[i] = r.g.GetCopiedValue(output)
+	}
+	return outputs, nil
+}
@@ -0,0 +1,57 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ag
+
+import (
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGraph_Capture(t *testing.T) {
+	g := NewGraph()
+	w := g.NewVariable(mat.NewDense(2, 2, []mat.Float{1, 2, 3, 4}), false)
+	x := g.NewVariable(mat.NewVecDense([]mat.Float{1, 1}), false)
+	y := g.ReLU(g.Mul(w, x))
+	z := g.ReduceSum(y)
+	assert.Equal(t, mat.Float(10), z.ScalarValue())
+
+	r, err := g.Capture([]Node{x}, []Node{y, z})
+	require.NoError(t, err)
+
+	out, err := r.Run(mat.NewVecDense([]mat.Float{1, -1}))
+	require.NoError(t, err)
+	assert.Equal(t, []mat.Float{0, 0}, out[0].Data())
+	assert.Equal(t, mat.Float(0), out[1].Scalar())
+
+	out, err = r.Run(mat.NewVecDense([]mat.Float{2, 0}))
+	require.NoError(t, err)
+	assert.Equal(t, []mat.Float{2, 6}, out[0].Data())
+	assert.Equal(t, mat.Float(8), out[1].Scalar())
+
+	_, err = r.Run(mat.NewVecDense([]mat.Float{1, 2, 3}))
+	assert.Error(t, err)
+	_, err = r.Run()
+	assert.Error(t, err)
+}
+
+func TestGraph_CaptureErrors(t *testing.T) {
+	g := NewGraph()
+	x := g.NewVariable(mat.NewVecDense([]mat.Float{1, 2}), false)
+	y := g.Square(x)
+	_, err := g.Capture([]Node{y}, []Node{y})
+	assert.Error(t, err) // not a variable
+
+	g.NoGradScope(func() {
+		g.Square(x)
+	})
+	_, err = g.Capture([]Node{x}, []Node{y})
+	assert.Error(t, err)
+
+	_, err = g.Capture([]Node{x}, []Node{NewGraph().NewScalar(1)})
+	assert.Error(t, err)
+}
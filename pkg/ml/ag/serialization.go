@@ -0,0 +1,326 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ag
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/ml/ag/fn"
+)
+
+// graphMagic identifies the binary format of the serialized graphs, along
+// with its version.
+const graphMagic = "spaGOgraph\x01"
+
+// Kinds of the serialized nodes.
+const (
+	serializedVariable byte = iota
+	serializedOperator
+	serializedParam
+)
+
+// functionToMethodName maps the names of the functions which differ from the
+// names of the Graph methods creating them.
+var functionToMethodName = map[string]string{
+	"StopGrad":         "Detach",
+	"ReverseSubScalar": "ReverseSub",
+	"Transpose":        "T",
+}
+
+var nodeType = reflect.TypeOf((*Node)(nil)).Elem()
+
+// ParamResolver returns the value of a named parameter referenced by a
+// serialized graph (see UnmarshalBinaryGraph).
+type ParamResolver func(name string) (GradValue, error)
+
+// MarshalBinaryGraph writes a compact binary description of the graph, to
+// re-instantiate it in another process with UnmarshalBinaryGraph (e.g. to
+// build the graphs in a lightweight front-end, and execute them in a pool of
+// workers).
+//
+// The nodes are written in order of definition, along with their time steps:
+//   - the variables with their names and values;
+//   - the wrappers of named values (e.g. the parameters of the models) as
+//     references to their names, so that the values are not transferred; the
+//     other wrappers as variables holding their current values;
+//   - the operators with the Graph method creating them and their operands.
+//     Only the operators created by methods taking nodes alone (e.g. Add,
+//     Mul, Tanh, Softmax, Concat) are supported, since the other arguments
+//     are not kept in the graph. The operators created while the gradients
+//     are disabled (see NoGradScope) are written as variables holding their
+//     values.
+//
+// It returns an error if the graph contains an unsupported operator.
+func MarshalBinaryGraph(g *Graph, w io.Writer) error {
+	g.WaitForward()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	index := make(map[int]int, len(g.nodes)) // from the IDs to the positions
+	for i, node := range g.nodes {
+		index[node.ID()] = i
+	}
+
+	bw := bufio.NewWriter(w)
+	bw.WriteString(graphMagic)
+	writeUvarint(bw, uint64(len(g.nodes)))
+	for _, node := range g.nodes {
+		writeUvarint(bw, uint64(node.TimeStep()))
+		switch nt := node.(type) {
+		case *Variable:
+			if err := writeVariable(bw, nt.name, nt.value, nt.requiresGrad); err != nil {
+				return err
+			}
+		case *Wrapper:
+			named, ok := nt.GradValue.(interface{ Name() string })
+			if !ok || named.Name() == "" {
+				if err := writeVariable(bw, "", nt.Value(), false); err != nil {
+					return err
+				}
+				continue
+			}
+			bw.WriteByte(serializedParam)
+			writeString(bw, named.Name())
+			writeBool(bw, nt.wrapGrad)
+		case *Operator:
+			if nt.function == nil {
+				if err := writeVariable(bw, "", nt.value, false); err != nil {
+					return err
+				}
+				continue
+			}
+			name, err := operatorMethodName(g, nt)
+			if err != nil {
+				return err
+			}
+			bw.WriteByte(serializedOperator)
+			writeString(bw, name)
+			writeUvarint(bw, uint64(len(nt.operands)))
+			for _, operand := range nt.operands {
+				i, ok := index[operand.ID()]
+				if !ok {
+					return fmt.Errorf("ag: the operand #%d of the node #%d doesn't belong to the graph", operand.ID(), nt.id)
+				}
+				writeUvarint(bw, uint64(i))
+			}
+		default:
+			return fmt.Errorf("ag: cannot serialize the node #%d of type %T", node.ID(), node)
+		}
+	}
+	return bw.Flush()
+}
+
+// UnmarshalBinaryGraph re-instantiates a graph serialized by
+// MarshalBinaryGraph, resolving the references to the named values with the
+// given function. The graph is created with the given options: with the
+// incremental forward (default), the values of the operators are computed
+// while they are created.
+//
+// The nodes are created in the same order as the original ones, so the nodes
+// of the new graph (see Graph.Nodes) correspond to the ones of the original
+// graph at the same position.
+func UnmarshalBinaryGraph(r io.Reader, params ParamResolver, opts ...GraphOption) (*Graph, error) {
+	br := bufio.NewReader(r)
+	magic := make([]byte, len(graphMagic))
+	if _, err := io.ReadFull(br, magic); err != nil || string(magic) != graphMagic {
+		return nil, errors.New("ag: invalid serialized graph")
+	}
+	n, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, err
+	}
+	g := NewGraph(opts...)
+	nodes := make([]Node, 0, n)
+	for i := uint64(0); i < n; i++ {
+		timeStep, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, err
+		}
+		for uint64(g.TimeStep()) < timeStep {
+			g.IncTimeStep()
+		}
+		node, err := readNode(br, g, nodes, params)
+		if err != nil {
+			return nil, fmt.Errorf("ag: node %d: %w", i, err)
+		}
+		nodes = append(nodes, node)
+	}
+	return g, nil
+}
+
+func readNode(br *bufio.Reader, g *Graph, nodes []Node, params ParamResolver) (Node, error) {
+	kind, err := br.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	switch kind {
+	case serializedVariable:
+		name, err := readString(br)
+		if err != nil {
+			return nil, err
+		}
+		requiresGrad, err := readBool(br)
+		if err != nil {
+			return nil, err
+		}
+		hasValue, err := readBool(br)
+		if err != nil {
+			return nil, err
+		}
+		var value mat.Matrix
+		if hasValue {
+			if value, err = mat.UnmarshalBinaryMatrix(br); err != nil {
+				return nil, err
+			}
+		}
+		return g.NewVariableWithName(value, requiresGrad, name), nil
+	case serializedParam:
+		name, err := readString(br)
+		if err != nil {
+			return nil, err
+		}
+		wrapGrad, err := readBool(br)
+		if err != nil {
+			return nil, err
+		}
+		if params == nil {
+			return nil, fmt.Errorf("no resolver for the parameter %#v", name)
+		}
+		value, err := params(name)
+		if err != nil {
+			return nil, err
+		}
+		if wrapGrad {
+			return g.NewWrap(value), nil
+		}
+		return g.NewWrapNoGrad(value), nil
+	case serializedOperator:
+		name, err := readString(br)
+		if err != nil {
+			return nil, err
+		}
+		count, err := binary.ReadUvarint(br)
+		if err != nil {
+			return nil, err
+		}
+		operands := make([]Node, count)
+		for i := range operands {
+			j, err := binary.ReadUvarint(br)
+			if err != nil {
+				return nil, err
+			}
+			if j >= uint64(len(nodes)) {
+				return nil, fmt.Errorf("invalid operand %d", j)
+			}
+			operands[i] = nodes[j]
+		}
+		method, ok := nodeOnlyMethod(g, name, len(operands))
+		if !ok {
+			return nil, fmt.Errorf("unsupported operator %#v", name)
+		}
+		args := make([]reflect.Value, len(operands))
+		for i, operand := range operands {
+			args[i] = reflect.ValueOf(operand)
+		}
+		return method.Call(args)[0].Interface().(Node), nil
+	default:
+		return nil, fmt.Errorf("invalid kind %d", kind)
+	}
+}
+
+// operatorMethodName returns the name of the Graph method which re-creates
+// the operator from its operands alone, or an error if there is not any.
+func operatorMethodName(g *Graph, op *Operator) (string, error) {
+	switch op.function.(type) {
+	case *fn.Custom, *fn.ToDevice:
+		return "", fmt.Errorf("ag: cannot serialize the operator %s (node #%d)", op.Name(), op.id)
+	}
+	name := functionName(op.function)
+	if methodName, ok := functionToMethodName[name]; ok {
+		name = methodName
+	}
+	if _, ok := nodeOnlyMethod(g, name, len(op.operands)); !ok {
+		return "", fmt.Errorf("ag: cannot serialize the operator %s (node #%d): its arguments are not only nodes", op.Name(), op.id)
+	}
+	return name, nil
+}
+
+// nodeOnlyMethod returns the Graph method with the given name, if its
+// arguments are only nodes, and it can be called with the given number of
+// operands.
+func nodeOnlyMethod(g *Graph, name string, operands int) (reflect.Value, bool) {
+	method := reflect.ValueOf(g).MethodByName(name)
+	if !method.IsValid() {
+		return method, false
+	}
+	t := method.Type()
+	if t.NumOut() != 1 || t.Out(0) != nodeType {
+		return method, false
+	}
+	for i := 0; i < t.NumIn(); i++ {
+		in := t.In(i)
+		if t.IsVariadic() && i == t.NumIn()-1 {
+			in = in.Elem()
+		}
+		if in != nodeType {
+			return method, false
+		}
+	}
+	if t.IsVariadic() {
+		return method, operands >= t.NumIn()-1
+	}
+	return method, operands == t.NumIn()
+}
+
+func writeVariable(bw *bufio.Writer, name string, value mat.Matrix, requiresGrad bool) error {
+	bw.WriteByte(serializedVariable)
+	writeString(bw, name)
+	writeBool(bw, requiresGrad)
+	writeBool(bw, value != nil)
+	if value == nil {
+		return nil
+	}
+	return mat.MarshalBinaryMatrix(value, bw)
+}
+
+func writeUvarint(bw *bufio.Writer, v uint64) {
+	var buf [binary.MaxVarintLen64]byte
+	bw.Write(buf[:binary.PutUvarint(buf[:], v)])
+}
+
+func writeString(bw *bufio.Writer, s string) {
+	writeUvarint(bw, uint64(len(s)))
+	bw.WriteString(s)
+}
+
+func writeBool(bw *bufio.Writer, b bool) {
+	if b {
+		bw.WriteByte(1)
+	} else {
+		bw.WriteByte(0)
+	}
+}
+
+func readString(br *bufio.Reader) (string, error) {
+	n, err := binary.ReadUvarint(br)
+	if err != nil {
+		return "", err
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(br, buf); err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
+
+func readBool(br *bufio.Reader) (bool, error) {
+	b, err := br.ReadByte()
+	return b == 1, err
+}
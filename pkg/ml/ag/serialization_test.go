@@ -0,0 +1,87 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ag
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarshalBinaryGraph(t *testing.T) {
+	// the named values play the role of the parameters of a model
+	params := map[string]GradValue{
+		"w": NewGraph().NewVariableWithName(mat.NewDense(2, 2, []mat.Float{0.1, 0.2, -0.3, 0.4}), true, "w"),
+	}
+	resolver := func(name string) (GradValue, error) {
+		if p, ok := params[name]; ok {
+			return p, nil
+		}
+		return nil, errors.New("unknown parameter")
+	}
+
+	g := NewGraph()
+	x := g.NewVariableWithName(mat.NewVecDense([]mat.Float{1, 2}), false, "x")
+	w := g.NewWrap(params["w"])
+	b := g.NewWrapNoGrad(NewGraph().NewVariable(mat.NewVecDense([]mat.Float{0.5, -0.5}), false))
+	h := g.Tanh(g.Add(g.Mul(w, x), b))
+	g.IncTimeStep()
+	var c Node
+	g.NoGradScope(func() {
+		c = g.Square(x)
+	})
+	s := g.Softmax(h)
+	y := g.ReduceSum(g.Concat(s, g.T(g.T(g.ReverseSub(h, g.Detach(g.ReduceSum(c)))))))
+
+	var buf bytes.Buffer
+	require.NoError(t, MarshalBinaryGraph(g, &buf))
+
+	g2, err := UnmarshalBinaryGraph(&buf, resolver)
+	require.NoError(t, err)
+	nodes, nodes2 := g.Nodes(), g2.Nodes()
+	require.Len(t, nodes2, len(nodes))
+	for i, node := range nodes {
+		assert.Equal(t, node.Value().Data(), nodes2[i].Value().Data(), "node %d", i)
+		assert.Equal(t, node.TimeStep(), nodes2[i].TimeStep(), "node %d", i)
+		assert.Equal(t, node.RequiresGrad(), nodes2[i].RequiresGrad(), "node %d", i)
+	}
+	assert.Equal(t, "x", nodes2[x.ID()].(*Variable).Name())
+	assert.Equal(t, "Softmax", nodes2[s.ID()].(*Operator).Name())
+
+	// the references to the parameters share their values and gradients
+	g2.Backward(nodes2[y.ID()])
+	assert.NotNil(t, params["w"].Grad())
+}
+
+func TestMarshalBinaryGraph_Unsupported(t *testing.T) {
+	g := NewGraph()
+	x := g.NewVariable(mat.NewVecDense([]mat.Float{1, 2}), true)
+	g.AtVec(x, 1)
+	err := MarshalBinaryGraph(g, &bytes.Buffer{})
+	require.Error(t, err)
+	assert.True(t, strings.Contains(err.Error(), "AtVec"))
+}
+
+func TestUnmarshalBinaryGraph_Errors(t *testing.T) {
+	_, err := UnmarshalBinaryGraph(strings.NewReader("invalid"), nil)
+	assert.Error(t, err)
+
+	named := NewGraph().NewVariableWithName(mat.NewScalar(1), true, "p")
+	g := NewGraph()
+	g.Neg(g.NewWrap(named))
+	var buf bytes.Buffer
+	require.NoError(t, MarshalBinaryGraph(g, &buf))
+	_, err = UnmarshalBinaryGraph(bytes.NewReader(buf.Bytes()), nil)
+	assert.Error(t, err)
+	_, err = UnmarshalBinaryGraph(bytes.NewReader(buf.Bytes()[:buf.Len()-2]), func(string) (GradValue, error) {
+		return named, nil
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,140 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ag
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+
+	"github.com/nlpodyssey/spago/pkg/ml/ag/fn"
+)
+
+// WithShapeChecking sets whether the shapes of the operands of each new
+// operator are validated when the operator is created (disabled by default),
+// for the functions which can infer the shape of their output (see
+// fn.ShapeInferrer). An invalid operator panics with a *ShapeError,
+// describing the mismatch and where the operator has been created, instead
+// of a panic deep inside the mat package.
+//
+// The shapes are inferred along the graph, so that they are validated even
+// when the values are not computed yet (see IncrementalForward and
+// WithConcurrentForward); the operators whose shape can be neither inferred
+// nor read from their value are not validated, nor are the ones depending on
+// them.
+func WithShapeChecking(enabled bool) GraphOption {
+	return func(g *Graph) {
+		g.shapeChecking = enabled
+	}
+}
+
+// ShapeCheckingEnabled returns whether the shapes of the operands of the new
+// operators are validated (see WithShapeChecking).
+func (g *Graph) ShapeCheckingEnabled() bool {
+	return g.shapeChecking
+}
+
+// ShapeError describes the operands of an operator whose shapes are not
+// compatible (see WithShapeChecking).
+type ShapeError struct {
+	// NodeID is the ID of the operator.
+	NodeID int
+	// Function is the name of the function of the operator.
+	Function string
+	// Location is the "file:line" where the operator has been created, i.e.
+	// the first caller outside this package.
+	Location string
+	// Err is the error returned by the shape inference of the function.
+	Err error
+}
+
+// Error returns a description of the error, such as
+// "ag: Mul: 12x768 · 64x768 mismatch at node #482, created at model.go:42".
+func (e *ShapeError) Error() string {
+	return fmt.Sprintf("ag: %s: %v at node #%d, created at %s", e.Function, e.Err, e.NodeID, e.Location)
+}
+
+// Unwrap returns the error of the shape inference.
+func (e *ShapeError) Unwrap() error {
+	return e.Err
+}
+
+// inferShape returns the shape of the output of the function, given its
+// operands, if the shape checking is enabled and the shape can be inferred.
+// It panics with a *ShapeError if the shapes of the operands are not
+// compatible.
+func (g *Graph) inferShape(f fn.Function, operands []Node) (fn.Shape, bool) {
+	if !g.shapeChecking {
+		return fn.Shape{}, false
+	}
+	inferrer, ok := f.(fn.ShapeInferrer)
+	if !ok {
+		return fn.Shape{}, false
+	}
+	shapes := make([]fn.Shape, len(operands))
+	for i, operand := range operands {
+		s, ok := nodeShape(operand)
+		if !ok {
+			return fn.Shape{}, false
+		}
+		shapes[i] = s
+	}
+	shape, err := inferrer.OutputShape(shapes...)
+	if err != nil {
+		g.mu.Lock()
+		id := g.maxID + 1
+		g.mu.Unlock()
+		panic(&ShapeError{NodeID: id, Function: functionName(f), Location: callerLocation(), Err: err})
+	}
+	return shape, true
+}
+
+// nodeShape returns the shape of the node: the one inferred for an operator,
+// if any, otherwise the shape of its value, if available.
+func nodeShape(node Node) (fn.Shape, bool) {
+	op, isOperator := node.(*Operator)
+	if !isOperator {
+		value := node.Value()
+		if value == nil {
+			return fn.Shape{}, false
+		}
+		return fn.Shape{Rows: value.Rows(), Columns: value.Columns()}, true
+	}
+	if op.hasShape {
+		return op.shape, true
+	}
+	if op.pending != nil {
+		select {
+		case <-op.pending.done:
+		default:
+			return fn.Shape{}, false // it doesn't wait for the value
+		}
+	}
+	if op.value == nil {
+		return fn.Shape{}, false
+	}
+	return fn.Shape{Rows: op.value.Rows(), Columns: op.value.Columns()}, true
+}
+
+// packagePrefix is the prefix of the names of the functions of this package.
+var packagePrefix = reflect.TypeOf(Graph{}).PkgPath() + "."
+
+// callerLocation returns the "file:line" of the first caller outside this
+// package (its tests excluded).
+func callerLocation() string {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	for {
+		frame, more := frames.Next()
+		inPackage := strings.HasPrefix(frame.Function, packagePrefix) && !strings.HasSuffix(frame.File, "_test.go")
+		if !inPackage {
+			return fmt.Sprintf("%s:%d", frame.File, frame.Line)
+		}
+		if !more {
+			return "unknown"
+		}
+	}
+}
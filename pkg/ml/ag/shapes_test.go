@@ -0,0 +1,114 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ag
+
+import (
+	"errors"
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/ml/ag/fn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recoverShapeError calls f, returning the *ShapeError it panics with, if any.
+func recoverShapeError(f func()) (err *ShapeError) {
+	defer func() {
+		if r := recover(); r != nil {
+			err, _ = r.(*ShapeError)
+		}
+	}()
+	f()
+	return nil
+}
+
+func TestWithShapeChecking(t *testing.T) {
+	assert.False(t, NewGraph().ShapeCheckingEnabled())
+	assert.True(t, NewGraph(WithShapeChecking(true)).ShapeCheckingEnabled())
+
+	// without the shape checking, the panic comes from the mat package
+	g := NewGraph()
+	a := g.NewVariable(mat.NewEmptyDense(12, 768), true)
+	b := g.NewVariable(mat.NewEmptyDense(64, 768), true)
+	assert.Panics(t, func() { g.Mul(a, b) })
+	assert.Nil(t, recoverShapeError(func() { g.Mul(a, b) }))
+}
+
+func TestGraph_ShapeChecking(t *testing.T) {
+	g := NewGraph(WithShapeChecking(true))
+	a := g.NewVariable(mat.NewEmptyDense(12, 768), true)
+	b := g.NewVariable(mat.NewEmptyDense(64, 768), true)
+
+	err := recoverShapeError(func() { g.Mul(a, b) })
+	require.NotNil(t, err)
+	assert.Equal(t, 2, err.NodeID)
+	assert.Equal(t, "Mul", err.Function)
+	assert.Contains(t, err.Location, "shapes_test.go:")
+	assert.Regexp(t, `^ag: Mul: 12x768 · 64x768 mismatch at node #2, created at .*shapes_test.go:\d+$`, err.Error())
+	assert.Len(t, g.Nodes(), 2)
+
+	var shapeErr *ShapeError
+	assert.True(t, errors.As(err, &shapeErr))
+	assert.NotNil(t, errors.Unwrap(err))
+
+	// the valid operators are created as usual
+	y := g.MatMulT(a, b)
+	assert.Equal(t, 12, y.Value().Rows())
+	assert.Equal(t, 64, y.Value().Columns())
+}
+
+func TestGraph_ShapeChecking_Inferred(t *testing.T) {
+	g := NewGraph(WithShapeChecking(true), IncrementalForward(false))
+	x := g.NewVariable(mat.NewDense(2, 3, []mat.Float{1, 2, 3, 4, 5, 6}), true)
+	xxt := g.Mul(x, g.T(x)) // 2x2, not computed yet
+	y := g.Add(xxt, g.NewVariable(mat.NewEmptyDense(2, 2), false))
+
+	// the mismatch is found without the values
+	err := recoverShapeError(func() { g.Add(xxt, x) })
+	require.NotNil(t, err)
+	assert.Regexp(t, `^ag: Add: 2x2 and 2x3 mismatch at node #\d+, created at `, err.Error())
+
+	g.Forward()
+	assert.Equal(t, []mat.Float{14, 32, 32, 77}, y.Value().Data())
+}
+
+func TestGraph_ShapeChecking_NotInferred(t *testing.T) {
+	fn.Register("test.shapeless", 1,
+		func(xs []mat.Matrix) mat.Matrix { return xs[0].Clone() },
+		func(xs []mat.Matrix, y, gy mat.Matrix) []mat.Matrix { return []mat.Matrix{gy.Clone()} },
+	)
+	g := NewGraph(WithShapeChecking(true), IncrementalForward(false))
+	x := g.NewVariable(mat.NewVecDense([]mat.Float{1, 2}), true)
+	// the shape of the output of a custom operator is not known before the
+	// forward, so the operators depending on it are not validated
+	_, ok := fn.Function(fn.NewCustom("test.shapeless", []fn.Operand{x})).(fn.ShapeInferrer)
+	require.False(t, ok)
+	s := g.Custom("test.shapeless", x)
+	y := g.Add(s, g.NewVariable(mat.NewEmptyVecDense(3), false))
+	assert.NotPanics(t, func() { g.Add(y, x) })
+	assert.Panics(t, func() { g.Forward() })
+
+	// once computed, its value gives the shape
+	g = NewGraph(WithShapeChecking(true))
+	x = g.NewVariable(mat.NewVecDense([]mat.Float{1, 2}), true)
+	s = g.Custom("test.shapeless", x)
+	err := recoverShapeError(func() { g.Add(s, g.NewVariable(mat.NewEmptyVecDense(3), false)) })
+	require.NotNil(t, err)
+	assert.Regexp(t, `^ag: Add: 2x1 and 3x1 mismatch at node #\d+`, err.Error())
+}
+
+func TestGraph_ShapeChecking_Activations(t *testing.T) {
+	g := NewGraph(WithShapeChecking(true), IncrementalForward(false))
+	x := g.NewVariable(mat.NewVecDense([]mat.Float{1, 2}), true)
+	s := g.SoftPlus(x, g.Constant(1), g.Constant(20))
+	err := recoverShapeError(func() { g.Add(s, g.NewVariable(mat.NewEmptyVecDense(3), false)) })
+	require.NotNil(t, err)
+	assert.Equal(t, "Add", err.Function)
+
+	err = recoverShapeError(func() { g.SoftPlus(x, x, g.Constant(20)) })
+	require.NotNil(t, err)
+	assert.Regexp(t, `^ag: SoftPlus: 2x1 is not a scalar at node #\d+`, err.Error())
+}
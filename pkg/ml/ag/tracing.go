@@ -0,0 +1,42 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ag
+
+import (
+	"context"
+
+	"github.com/nlpodyssey/spago/pkg/utils/tracing"
+)
+
+// WithTracing enables the spans of the forward of the layers of the models
+// (see stack.Model), created with the Tracer of the tracing package as
+// children of the span contained in the context (e.g. the one of the
+// request being served).
+//
+// The spans measure the time spent defining the operators, which includes
+// the time spent computing their values unless the forward is not
+// incremental (see IncrementalForward and WithConcurrentForward).
+func WithTracing(ctx context.Context) GraphOption {
+	return func(g *Graph) {
+		g.tracingCtx = ctx
+	}
+}
+
+// TracingEnabled returns whether the spans of the layers are created (see
+// WithTracing).
+func (g *Graph) TracingEnabled() bool {
+	return g.tracingCtx != nil && tracing.Enabled()
+}
+
+// StartSpan creates a span with the given name, child of the span contained
+// in the context given to WithTracing. The span does nothing if the tracing
+// is not enabled.
+func (g *Graph) StartSpan(name string) tracing.Span {
+	if g.tracingCtx == nil {
+		return tracing.NoopSpan()
+	}
+	_, span := tracing.Start(g.tracingCtx, name)
+	return span
+}
@@ -0,0 +1,40 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ag
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nlpodyssey/spago/pkg/utils/tracing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGraph_StartSpan(t *testing.T) {
+	recorder := tracing.NewRecorder()
+
+	g := NewGraph(WithTracing(context.Background()))
+	assert.False(t, g.TracingEnabled())
+
+	tracing.SetTracer(recorder)
+	defer tracing.SetTracer(nil)
+
+	assert.False(t, NewGraph().TracingEnabled())
+	assert.Equal(t, tracing.NoopSpan(), NewGraph().StartSpan("layer.0"))
+
+	ctx, root := tracing.Start(context.Background(), "request")
+	g = NewGraph(WithTracing(ctx))
+	assert.True(t, g.TracingEnabled())
+	g.StartSpan("layer.0").End()
+	g.StartSpan("layer.1").End()
+	root.End()
+
+	spans := recorder.Spans()
+	require.Len(t, spans, 3)
+	assert.Equal(t, []string{"layer.0", "layer.1", "request"}, recorder.Names())
+	assert.Equal(t, spans[2], spans[0].Parent)
+	assert.Equal(t, spans[2], spans[1].Parent)
+}
@@ -0,0 +1,163 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ag
+
+// TruncateBackward discards the history of the graph older than the last
+// steps time-steps (see IncTimeStep), so that the graph of a recurrent model
+// trained on a long stream doesn't grow indefinitely (truncated
+// back-propagation through time).
+//
+// The operators of the previous time-steps whose values are used by the kept
+// ones become the boundaries of the graph: their values are kept, but they
+// are detached, so that the gradients are no longer propagated beyond them.
+// All the other nodes of the previous time-steps are released and must not
+// be used anymore, except the wrappers (e.g. the parameters of the reified
+// models), the constants and the nodes in the step cache (see AppendCached),
+// which are always kept.
+// The gradients of the kept operators are zeroed, since they have already
+// been propagated, while the gradients of the wrapped values are left to the
+// optimizer. The kept nodes are renumbered, so their IDs change.
+//
+// A typical training loop on a stream looks like this:
+//
+//     for _, chunk := range chunks {
+//         var losses []ag.Node
+//         for _, x := range chunk {
+//             g.IncTimeStep()
+//             h = rnn.Forward(x, h)
+//             losses = append(losses, lossOf(h))
+//         }
+//         g.Backward(g.Sum(losses...))
+//         optimizer.Optimize()
+//         g.IncTimeStep()
+//         h = g.Detach(h)
+//         g.TruncateBackward(1) // only the detached state is kept
+//     }
+//
+// It panics if steps is negative.
+func (g *Graph) TruncateBackward(steps int) {
+	if steps < 0 {
+		panic("ag: TruncateBackward steps must be greater than or equal to zero")
+	}
+	g.WaitForward()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	threshold := g.curTimeStep - steps // the nodes up to this time-step are discarded
+	constants := make(map[Node]bool, len(g.constants))
+	for _, node := range g.constants {
+		constants[node] = true
+	}
+	cached := g.cachedNodes()
+	boundaries := make(map[int]bool)
+	for _, node := range g.nodes {
+		if op, ok := node.(*Operator); ok && op.timeStep > threshold {
+			for _, operand := range op.operands {
+				if operand.TimeStep() <= threshold {
+					boundaries[operand.ID()] = true
+				}
+			}
+		}
+	}
+	keep := func(node Node) bool {
+		if _, ok := node.(*Wrapper); ok {
+			return true
+		}
+		return node.TimeStep() > threshold || boundaries[node.ID()] || constants[node] || cached[node]
+	}
+
+	newIDs := make(map[int]int, len(g.nodes))
+	nodes := make([]Node, 0, len(g.nodes))
+	for _, node := range g.nodes {
+		if keep(node) {
+			newIDs[node.ID()] = len(nodes)
+			nodes = append(nodes, node)
+		}
+	}
+	g.truncateCheckpoints(newIDs)
+	g.truncateGradHooks(newIDs)
+
+	for _, node := range g.nodes {
+		op, isOperator := node.(*Operator)
+		if !keep(node) {
+			if isOperator {
+				g.releaseValue(op)
+				g.releaseGrad(op)
+				*op = Operator{}
+				operatorPool.Put(op)
+			}
+			continue
+		}
+		if isOperator {
+			g.releaseGrad(op)
+			if op.timeStep <= threshold {
+				op.function = nil
+				op.operands = nil
+				op.requiresGrad = false
+			}
+		}
+		setID(node, newIDs[node.ID()])
+	}
+
+	g.nodes = nodes
+	g.maxID = len(nodes) - 1
+	g.gradNodes = nil
+	g.clearCache()
+}
+
+// truncateCheckpoints renumbers the checkpoints whose nodes are all kept by
+// TruncateBackward, given the new IDs of the kept nodes, and drops the other
+// ones, recomputing their discarded values first.
+func (g *Graph) truncateCheckpoints(newIDs map[int]int) {
+	checkpoints := g.checkpoints[:0]
+	for _, c := range g.checkpoints {
+		kept := 0
+		for id := c.from; id <= c.to; id++ {
+			if _, ok := newIDs[id]; ok {
+				kept++
+			}
+		}
+		if kept < c.to-c.from+1 {
+			if kept > 0 && !c.restored {
+				g.restore(c)
+			}
+			continue
+		}
+		outputs := make(map[int]bool, len(c.outputs))
+		for id := range c.outputs {
+			outputs[newIDs[id]] = true
+		}
+		c.from, c.to, c.outputs = newIDs[c.from], newIDs[c.to], outputs
+		checkpoints = append(checkpoints, c)
+	}
+	g.checkpoints = checkpoints
+}
+
+// truncateGradHooks renumbers the hooks of the nodes kept by TruncateBackward,
+// given their new IDs, and drops the other ones.
+func (g *Graph) truncateGradHooks(newIDs map[int]int) {
+	if g.gradHooks == nil {
+		return
+	}
+	hooks := make(map[int][]GradHook, len(g.gradHooks))
+	for id, h := range g.gradHooks {
+		if newID, ok := newIDs[id]; ok {
+			hooks[newID] = h
+		}
+	}
+	g.gradHooks = hooks
+}
+
+// setID sets the ID of a node of the graph.
+func setID(node Node, id int) {
+	switch n := node.(type) {
+	case *Operator:
+		n.id = id
+	case *Variable:
+		n.id = id
+	case *Wrapper:
+		n.id = id
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ag
+
+import (
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recurrence defines h = tanh(w ⊙ h + x) for each x, one per time-step.
+func recurrence(g *Graph, w, h Node, xs ...mat.Float) Node {
+	for _, x := range xs {
+		g.IncTimeStep()
+		h = g.Tanh(g.Add(g.Prod(w, h), g.NewVariable(mat.NewVecDense([]mat.Float{x, -x}), false)))
+	}
+	return h
+}
+
+func assertSequentialIDs(t *testing.T, g *Graph) {
+	for i, node := range g.Nodes() {
+		assert.Equal(t, i, node.ID())
+	}
+}
+
+func TestGraph_TruncateBackward(t *testing.T) {
+	params := NewGraph()
+	newParam := func() Node {
+		return params.NewVariable(mat.NewVecDense([]mat.Float{0.5, -0.3}), true)
+	}
+
+	// expected: the backward truncated to the last two time-steps
+	expected := NewGraph()
+	ew := newParam()
+	eh := expected.NewVariable(mat.NewVecDense([]mat.Float{0.1, 0.2}), false)
+	ey := expected.ReduceSum(recurrence(expected, expected.NewWrap(ew), eh, 0.1, 0.2, 0.3, 0.4, 0.5))
+	expected.Backward(ey, Truncate(2))
+
+	g := NewGraph()
+	w := g.NewWrap(newParam())
+	boundary := recurrence(g, w, g.NewVariable(mat.NewVecDense([]mat.Float{0.1, 0.2}), false), 0.1, 0.2, 0.3)
+	h := recurrence(g, w, boundary, 0.4, 0.5)
+	g.TruncateBackward(2)
+	assertSequentialIDs(t, g)
+
+	assert.Same(t, w, g.Nodes()[0])
+	b := g.Nodes()[1].(*Operator)
+	assert.Same(t, boundary, b)
+	assert.False(t, b.RequiresGrad())
+	assert.Empty(t, b.Operands())
+	assert.NotNil(t, b.Value())
+	for _, node := range g.Nodes()[2:] {
+		assert.True(t, node.TimeStep() > 3)
+	}
+
+	y := g.ReduceSum(h)
+	assert.InDelta(t, ey.ScalarValue(), y.ScalarValue(), 1.0e-6)
+	g.Backward(y)
+	assert.InDeltaSlice(t, ew.Grad().Data(), w.Grad().Data(), 1.0e-6)
+	assert.Nil(t, b.Grad())
+
+	// the values are recomputed from the boundaries
+	g.Forward()
+	assert.InDelta(t, ey.ScalarValue(), y.ScalarValue(), 1.0e-6)
+
+	assert.Panics(t, func() { g.TruncateBackward(-1) })
+}
+
+func TestGraph_TruncateBackward_All(t *testing.T) {
+	g := NewGraph()
+	w := g.NewWrap(NewGraph().NewVariable(mat.NewVecDense([]mat.Float{0.5, -0.3}), true))
+	recurrence(g, w, g.NewVariable(mat.NewVecDense([]mat.Float{0.1, 0.2}), false), 0.1, 0.2, 0.3)
+	g.TruncateBackward(0)
+	require.Len(t, g.Nodes(), 1)
+	assert.Same(t, w, g.Nodes()[0])
+	assertSequentialIDs(t, g)
+}
+
+func TestGraph_TruncateBackward_Stream(t *testing.T) {
+	params := NewGraph()
+	g := NewGraph()
+	w := g.NewWrap(params.NewVariable(mat.NewVecDense([]mat.Float{0.5, -0.3}), true))
+	h := g.NewVariable(mat.NewVecDense([]mat.Float{0.1, 0.2}), false)
+	two := g.Constant(2)
+
+	var sizes []int
+	for chunk := 0; chunk < 5; chunk++ {
+		var losses []Node
+		for i := 0; i < 3; i++ {
+			h = recurrence(g, w, h, mat.Float(i)/10)
+			losses = append(losses, g.ProdScalar(g.ReduceSum(h), two))
+		}
+		g.Backward(g.Sum(losses...))
+		params.ZeroGrad()
+		g.IncTimeStep()
+		h = g.Detach(h)
+		g.TruncateBackward(1)
+		assertSequentialIDs(t, g)
+		sizes = append(sizes, len(g.Nodes()))
+	}
+	// the graph doesn't grow: the wrapper, the constant, the boundary and the detached state
+	assert.Equal(t, []int{4, 4, 4, 4, 4}, sizes)
+	assert.Same(t, two, g.Constant(2))
+	assert.Equal(t, h, g.Nodes()[len(g.Nodes())-1])
+}
+
+func TestGraph_TruncateBackward_Hooks(t *testing.T) {
+	g := NewGraph()
+	x := g.NewVariable(mat.NewScalar(2), true)
+	g.IncTimeStep()
+	y := g.Square(x)
+	g.IncTimeStep()
+	z := g.Square(y)
+	var calls int
+	g.RegisterGradHook(z, func(grad mat.Matrix) mat.Matrix {
+		calls++
+		return grad
+	})
+	g.TruncateBackward(1)
+	assertSequentialIDs(t, g)
+	g.Backward(z)
+	assert.Equal(t, 1, calls)
+	assert.Nil(t, x.Grad())
+	assert.Equal(t, mat.Float(16), z.ScalarValue())
+}
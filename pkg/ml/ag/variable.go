@@ -68,6 +68,9 @@ func (r *Variable) PropagateGrad(grad mat.Matrix) {
 	if !r.requiresGrad {
 		return
 	}
+	if r.graph.hasGradHooks(r.id) {
+		grad = r.graph.applyGradHooks(r, grad.Clone())
+	}
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	if r.grad == nil {
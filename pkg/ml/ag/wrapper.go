@@ -47,6 +47,9 @@ func (r *Wrapper) PropagateGrad(gx mat.Matrix) {
 	if !r.wrapGrad {
 		return
 	}
+	if r.graph.hasGradHooks(r.id) {
+		gx = r.graph.applyGradHooks(r, gx.Clone())
+	}
 	r.GradValue.PropagateGrad(gx)
 }
 
@@ -0,0 +1,278 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package canary compares two versions of a classifier (e.g. the deployed
+// checkpoint and a new one) on a labeled dataset, reporting the deltas of
+// their metrics along with their statistical significance, so that a
+// deployment can be gated in a CI/CD pipeline:
+//
+//     report, err := canary.Evaluate(examples, baseline, candidate, canary.Options{})
+//     if err != nil {
+//         return err
+//     }
+//     report.WriteText(os.Stdout)
+//     if !report.Passed(canary.Gate{MaxAccuracyDrop: 0.01, Alpha: 0.05}) {
+//         os.Exit(1)
+//     }
+//
+// The significance of the accuracy delta is given by the exact McNemar test
+// on the examples where only one of the models is correct; the one of the
+// macro F1 delta by a paired bootstrap.
+package canary
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+
+	"github.com/nlpodyssey/spago/pkg/ml/stats"
+)
+
+// Example is a labeled example of the dataset.
+type Example struct {
+	Text  string `json:"text"`
+	Text2 string `json:"text2,omitempty"`
+	Label string `json:"label"`
+}
+
+// ReadDataset reads the examples from r, in the JSON Lines format (one JSON
+// object per line, with the fields "text", "text2" and "label"). The empty
+// lines are ignored.
+func ReadDataset(r io.Reader) ([]Example, error) {
+	var examples []Example
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var ex Example
+		if err := json.Unmarshal([]byte(text), &ex); err != nil {
+			return nil, fmt.Errorf("canary: line %d: %w", line, err)
+		}
+		if ex.Label == "" {
+			return nil, fmt.Errorf("canary: line %d: missing label", line)
+		}
+		examples = append(examples, ex)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return examples, nil
+}
+
+// Predictor returns the label predicted by a model for the example.
+type Predictor func(ex Example) (string, error)
+
+// Options provides the options of Evaluate.
+type Options struct {
+	// Bootstraps is the number of resamples of the paired bootstrap. If
+	// zero, 1000 resamples are used.
+	Bootstraps int
+	// Seed is the seed of the random resampling.
+	Seed int64
+}
+
+// Metrics are the metrics of a model on the dataset.
+type Metrics struct {
+	Accuracy float64            `json:"accuracy"`
+	MacroF1  float64            `json:"macro_f1"`
+	ClassF1  map[string]float64 `json:"class_f1"`
+}
+
+// Delta is the difference of a metric between the candidate and the
+// baseline, with its significance.
+type Delta struct {
+	Value float64 `json:"value"`
+	// PValue is the two-sided p-value of the null hypothesis that the models
+	// perform the same.
+	PValue float64 `json:"p_value"`
+	// Low and High are the bounds of the 95% confidence interval, if
+	// estimated.
+	Low  float64 `json:"low,omitempty"`
+	High float64 `json:"high,omitempty"`
+}
+
+// Report is the result of the comparison.
+type Report struct {
+	Examples  int     `json:"examples"`
+	Baseline  Metrics `json:"baseline"`
+	Candidate Metrics `json:"candidate"`
+	// Agreement is the fraction of the examples where the models predict the
+	// same label.
+	Agreement     float64 `json:"agreement"`
+	AccuracyDelta Delta   `json:"accuracy_delta"`
+	MacroF1Delta  Delta   `json:"macro_f1_delta"`
+}
+
+// Evaluate compares the predictions of the baseline and the candidate on
+// the examples.
+func Evaluate(examples []Example, baseline, candidate Predictor, opts Options) (*Report, error) {
+	if len(examples) == 0 {
+		return nil, fmt.Errorf("canary: empty dataset")
+	}
+	if opts.Bootstraps == 0 {
+		opts.Bootstraps = 1000
+	}
+	gold := make([]string, len(examples))
+	predA := make([]string, len(examples))
+	predB := make([]string, len(examples))
+	for i, ex := range examples {
+		var err error
+		gold[i] = ex.Label
+		if predA[i], err = baseline(ex); err != nil {
+			return nil, fmt.Errorf("canary: baseline: example %d: %w", i, err)
+		}
+		if predB[i], err = candidate(ex); err != nil {
+			return nil, fmt.Errorf("canary: candidate: example %d: %w", i, err)
+		}
+	}
+	return compare(gold, predA, predB, opts), nil
+}
+
+func compare(gold, predA, predB []string, opts Options) *Report {
+	labels := labelSet(gold, predA, predB)
+	all := rangeIndices(len(gold))
+	r := &Report{
+		Examples:  len(gold),
+		Baseline:  metrics(labels, gold, predA, all),
+		Candidate: metrics(labels, gold, predB, all),
+	}
+
+	var agree, onlyA, onlyB int
+	for i := range gold {
+		if predA[i] == predB[i] {
+			agree++
+		}
+		switch okA, okB := predA[i] == gold[i], predB[i] == gold[i]; {
+		case okA && !okB:
+			onlyA++
+		case okB && !okA:
+			onlyB++
+		}
+	}
+	r.Agreement = float64(agree) / float64(len(gold))
+	r.AccuracyDelta = Delta{
+		Value:  r.Candidate.Accuracy - r.Baseline.Accuracy,
+		PValue: mcNemar(onlyA, onlyB),
+	}
+	r.MacroF1Delta = bootstrap(labels, gold, predA, predB, r.Candidate.MacroF1-r.Baseline.MacroF1, opts)
+	return r
+}
+
+func metrics(labels []string, gold, pred []string, indices []int) Metrics {
+	counters := make(map[string]*stats.ClassMetrics, len(labels))
+	for _, label := range labels {
+		counters[label] = stats.NewMetricCounter()
+	}
+	correct := 0
+	for _, i := range indices {
+		if pred[i] == gold[i] {
+			correct++
+			counters[gold[i]].IncTruePos()
+			continue
+		}
+		counters[gold[i]].IncFalseNeg()
+		counters[pred[i]].IncFalsePos()
+	}
+	m := Metrics{
+		Accuracy: float64(correct) / float64(len(indices)),
+		ClassF1:  make(map[string]float64, len(labels)),
+	}
+	for _, label := range labels {
+		c := counters[label]
+		f1 := 0.0
+		if c.TruePos > 0 { // 2PR / (P + R), in double precision
+			f1 = float64(2*c.TruePos) / float64(2*c.TruePos+c.FalsePos+c.FalseNeg)
+		}
+		m.ClassF1[label] = f1
+		m.MacroF1 += f1
+	}
+	m.MacroF1 /= float64(len(labels))
+	return m
+}
+
+// mcNemar returns the two-sided p-value of the exact McNemar test, given the
+// number of examples where only the first or only the second model is correct.
+func mcNemar(onlyA, onlyB int) float64 {
+	n := onlyA + onlyB
+	if n == 0 {
+		return 1
+	}
+	k := onlyA
+	if onlyB < k {
+		k = onlyB
+	}
+	// P(X <= k) with X ~ Binomial(n, 0.5)
+	lgN, _ := math.Lgamma(float64(n + 1))
+	var p float64
+	for i := 0; i <= k; i++ {
+		lgI, _ := math.Lgamma(float64(i + 1))
+		lgNI, _ := math.Lgamma(float64(n - i + 1))
+		p += math.Exp(lgN - lgI - lgNI - float64(n)*math.Ln2)
+	}
+	return math.Min(1, 2*p)
+}
+
+// bootstrap returns the delta of the macro F1 with the p-value and the
+// confidence interval estimated by a paired bootstrap.
+func bootstrap(labels, gold, predA, predB []string, delta float64, opts Options) Delta {
+	rnd := rand.New(rand.NewSource(opts.Seed))
+	deltas := make([]float64, opts.Bootstraps)
+	indices := make([]int, len(gold))
+	var notGreater, notLess int
+	for b := range deltas {
+		for i := range indices {
+			indices[i] = rnd.Intn(len(gold))
+		}
+		d := metrics(labels, gold, predB, indices).MacroF1 - metrics(labels, gold, predA, indices).MacroF1
+		deltas[b] = d
+		if d <= 0 {
+			notGreater++
+		}
+		if d >= 0 {
+			notLess++
+		}
+	}
+	sort.Float64s(deltas)
+	tail := notGreater
+	if notLess < tail {
+		tail = notLess
+	}
+	return Delta{
+		Value:  delta,
+		PValue: math.Min(1, 2*float64(tail)/float64(len(deltas))),
+		Low:    deltas[int(0.025*float64(len(deltas)-1))],
+		High:   deltas[int(math.Ceil(0.975*float64(len(deltas)-1)))],
+	}
+}
+
+func labelSet(lists ...[]string) []string {
+	set := make(map[string]struct{})
+	for _, list := range lists {
+		for _, label := range list {
+			set[label] = struct{}{}
+		}
+	}
+	labels := make([]string, 0, len(set))
+	for label := range set {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	return labels
+}
+
+func rangeIndices(n int) []int {
+	indices := make([]int, n)
+	for i := range indices {
+		indices[i] = i
+	}
+	return indices
+}
@@ -0,0 +1,102 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package canary
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadDataset(t *testing.T) {
+	examples, err := ReadDataset(strings.NewReader(`{"text": "good", "label": "pos"}
+
+{"text": "a", "text2": "b", "label": "neg"}
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []Example{
+		{Text: "good", Label: "pos"},
+		{Text: "a", Text2: "b", Label: "neg"},
+	}, examples)
+
+	_, err = ReadDataset(strings.NewReader(`{"text": "good"}`))
+	assert.EqualError(t, err, "canary: line 1: missing label")
+	_, err = ReadDataset(strings.NewReader("{\"text\": \"a\", \"label\": \"b\"}\nnot json"))
+	assert.Error(t, err)
+}
+
+// lookup returns a Predictor returning the labels in order of the texts.
+func lookup(labels ...string) Predictor {
+	return func(ex Example) (string, error) {
+		var i int
+		for i = range labels {
+			if ex.Text == string(rune('a'+i)) {
+				break
+			}
+		}
+		return labels[i], nil
+	}
+}
+
+func dataset(labels ...string) []Example {
+	examples := make([]Example, len(labels))
+	for i, label := range labels {
+		examples[i] = Example{Text: string(rune('a' + i)), Label: label}
+	}
+	return examples
+}
+
+func TestEvaluate(t *testing.T) {
+	examples := dataset("x", "x", "y", "y")
+	report, err := Evaluate(examples, lookup("x", "y", "y", "y"), lookup("x", "x", "y", "x"), Options{Seed: 1})
+	require.NoError(t, err)
+
+	assert.Equal(t, 4, report.Examples)
+	assert.Equal(t, 0.75, report.Baseline.Accuracy)
+	assert.Equal(t, 0.75, report.Candidate.Accuracy)
+	assert.Equal(t, 0.5, report.Agreement)
+	assert.InDelta(t, 2.0/3, report.Baseline.ClassF1["x"], 1.0e-9)
+	assert.InDelta(t, 0.8, report.Baseline.ClassF1["y"], 1.0e-9)
+	assert.InDelta(t, (2.0/3+0.8)/2, report.Baseline.MacroF1, 1.0e-9)
+	assert.Equal(t, Delta{Value: 0, PValue: 1}, report.AccuracyDelta)
+	assert.LessOrEqual(t, report.MacroF1Delta.Low, report.MacroF1Delta.High)
+
+	_, err = Evaluate(nil, lookup(), lookup(), Options{})
+	assert.Error(t, err)
+
+	failing := func(Example) (string, error) { return "", errors.New("boom") }
+	_, err = Evaluate(examples, lookup("x"), failing, Options{})
+	assert.EqualError(t, err, "canary: candidate: example 0: boom")
+}
+
+func TestEvaluate_Significance(t *testing.T) {
+	gold := make([]string, 200)
+	candidate := make([]string, 200)
+	for i := range gold {
+		gold[i] = []string{"x", "y"}[i%2]
+		candidate[i] = gold[i]
+		if i%4 < 2 && i < 120 {
+			candidate[i] = []string{"y", "x"}[i%2] // 60 errors
+		}
+	}
+	report := compare(gold, gold, candidate, Options{Bootstraps: 500, Seed: 42})
+	assert.InDelta(t, -0.3, report.AccuracyDelta.Value, 1.0e-9)
+	assert.Less(t, report.AccuracyDelta.PValue, 1.0e-6)
+	assert.Less(t, report.MacroF1Delta.Value, 0.0)
+	assert.Less(t, report.MacroF1Delta.PValue, 0.01)
+	assert.Less(t, report.MacroF1Delta.High, 0.0)
+}
+
+func TestMcNemar(t *testing.T) {
+	assert.Equal(t, 1.0, mcNemar(0, 0))
+	assert.Equal(t, 1.0, mcNemar(3, 3))
+	// 2 * P(X <= 1) with X ~ Binomial(10, 0.5) = 2 * 11/1024
+	assert.InDelta(t, 22.0/1024, mcNemar(9, 1), 1.0e-9)
+	assert.InDelta(t, 22.0/1024, mcNemar(1, 9), 1.0e-9)
+	assert.Less(t, mcNemar(1000, 900), 0.05)
+}
@@ -0,0 +1,78 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package canary
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+)
+
+// Gate provides the conditions a candidate must satisfy to be deployed.
+type Gate struct {
+	// MaxAccuracyDrop is the maximum tolerated decrease of the accuracy.
+	MaxAccuracyDrop float64
+	// MaxMacroF1Drop is the maximum tolerated decrease of the macro F1.
+	MaxMacroF1Drop float64
+	// Alpha is the significance level: a decrease greater than the tolerated
+	// one fails the gate only if its p-value is less than Alpha. If zero, any
+	// such decrease fails the gate.
+	Alpha float64
+}
+
+// Failures returns the reasons why the candidate fails the gate, or nil if
+// it passes.
+func (r *Report) Failures(gate Gate) []string {
+	var failures []string
+	check := func(name string, d Delta, maxDrop float64) {
+		if d.Value >= -maxDrop {
+			return
+		}
+		if gate.Alpha > 0 && d.PValue >= gate.Alpha {
+			return
+		}
+		failures = append(failures, fmt.Sprintf("%s dropped by %.4f (p=%.4f), more than %.4f", name, -d.Value, d.PValue, maxDrop))
+	}
+	check("accuracy", r.AccuracyDelta, gate.MaxAccuracyDrop)
+	check("macro F1", r.MacroF1Delta, gate.MaxMacroF1Drop)
+	return failures
+}
+
+// Passed reports whether the candidate passes the gate.
+func (r *Report) Passed(gate Gate) bool {
+	return len(r.Failures(gate)) == 0
+}
+
+// WriteJSON writes the report in JSON format.
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteText writes the report as a human-readable table.
+func (r *Report) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "examples: %d, agreement: %.4f\n\n", r.Examples, r.Agreement)
+	fmt.Fprintln(tw, "METRIC\tBASELINE\tCANDIDATE\tDELTA\tP-VALUE\t95% CI")
+	fmt.Fprintf(tw, "accuracy\t%.4f\t%.4f\t%+.4f\t%.4f\t\n",
+		r.Baseline.Accuracy, r.Candidate.Accuracy, r.AccuracyDelta.Value, r.AccuracyDelta.PValue)
+	fmt.Fprintf(tw, "macro F1\t%.4f\t%.4f\t%+.4f\t%.4f\t[%+.4f, %+.4f]\n",
+		r.Baseline.MacroF1, r.Candidate.MacroF1, r.MacroF1Delta.Value, r.MacroF1Delta.PValue,
+		r.MacroF1Delta.Low, r.MacroF1Delta.High)
+
+	labels := make([]string, 0, len(r.Baseline.ClassF1))
+	for label := range r.Baseline.ClassF1 {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	for _, label := range labels {
+		a, b := r.Baseline.ClassF1[label], r.Candidate.ClassF1[label]
+		fmt.Fprintf(tw, "F1 %s\t%.4f\t%.4f\t%+.4f\t\t\n", label, a, b, b-a)
+	}
+	return tw.Flush()
+}
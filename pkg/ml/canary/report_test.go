@@ -0,0 +1,42 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package canary
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReport_Failures(t *testing.T) {
+	r := &Report{
+		AccuracyDelta: Delta{Value: -0.02, PValue: 0.01},
+		MacroF1Delta:  Delta{Value: -0.05, PValue: 0.2},
+	}
+	assert.Len(t, r.Failures(Gate{}), 2)
+	assert.Equal(t, []string{"accuracy dropped by 0.0200 (p=0.0100), more than 0.0100"},
+		r.Failures(Gate{MaxAccuracyDrop: 0.01, MaxMacroF1Drop: 0.01, Alpha: 0.05}))
+	assert.True(t, r.Passed(Gate{MaxAccuracyDrop: 0.03, MaxMacroF1Drop: 0.01, Alpha: 0.05}))
+	assert.False(t, r.Passed(Gate{MaxAccuracyDrop: 0.03, MaxMacroF1Drop: 0.01}))
+}
+
+func TestReport_Write(t *testing.T) {
+	r := compare([]string{"x", "y"}, []string{"x", "y"}, []string{"x", "x"}, Options{Bootstraps: 10})
+
+	var buf bytes.Buffer
+	require.NoError(t, r.WriteJSON(&buf))
+	var decoded Report
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, *r, decoded)
+
+	buf.Reset()
+	require.NoError(t, r.WriteText(&buf))
+	assert.Contains(t, buf.String(), "examples: 2, agreement: 0.5000")
+	assert.Contains(t, buf.String(), "accuracy  1.0000    0.5000     -0.5000")
+	assert.Contains(t, buf.String(), "F1 y")
+}
@@ -0,0 +1,117 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package clustering
+
+import (
+	"fmt"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+)
+
+// Linkage is the enumeration-like type used to distinguish how the distance
+// between two clusters is computed from the distances between their points.
+type Linkage int
+
+const (
+	// SingleLinkage uses the minimum distance between the points of the clusters.
+	SingleLinkage Linkage = iota
+	// CompleteLinkage uses the maximum distance between the points of the clusters.
+	CompleteLinkage
+	// AverageLinkage uses the average distance between the points of the clusters.
+	AverageLinkage
+)
+
+// String returns the name of the linkage.
+func (l Linkage) String() string {
+	switch l {
+	case SingleLinkage:
+		return "single"
+	case CompleteLinkage:
+		return "complete"
+	case AverageLinkage:
+		return "average"
+	default:
+		return fmt.Sprintf("Linkage(%d)", int(l))
+	}
+}
+
+// Agglomerative performs a hierarchical clustering of the points, starting
+// from a cluster for each point and merging the two closest clusters until
+// numClusters are left. It returns the cluster of each point; the clusters
+// are numbered in order of their first point.
+// It requires O(n²) memory and O(n³) time for n points.
+func Agglomerative(points []mat.Matrix, numClusters int, linkage Linkage, metric Metric) ([]int, error) {
+	data, err := vectorsData(points)
+	if err != nil {
+		return nil, err
+	}
+	n := len(data)
+	if numClusters <= 0 || numClusters > n {
+		return nil, fmt.Errorf("clustering: invalid number of clusters %d for %d points", numClusters, n)
+	}
+	if linkage < SingleLinkage || linkage > AverageLinkage {
+		return nil, fmt.Errorf("clustering: unknown linkage %v", linkage)
+	}
+
+	dist := distanceMatrix(data, metric)
+	parent := make([]int, n) // the cluster each point has been merged into
+	sizes := make([]int, n)
+	active := make([]bool, n)
+	for i := range parent {
+		parent[i], sizes[i], active[i] = i, 1, true
+	}
+
+	for clusters := n; clusters > numClusters; clusters-- {
+		a, b, best := -1, -1, mat.Inf(1)
+		for i := 0; i < n; i++ {
+			if !active[i] {
+				continue
+			}
+			for j := i + 1; j < n; j++ {
+				if active[j] && dist[i][j] < best {
+					a, b, best = i, j, dist[i][j]
+				}
+			}
+		}
+		// merge b into a, updating the distances (Lance-Williams formula)
+		for k := 0; k < n; k++ {
+			if !active[k] || k == a || k == b {
+				continue
+			}
+			var d mat.Float
+			switch linkage {
+			case SingleLinkage:
+				d = dist[a][k]
+				if dist[b][k] < d {
+					d = dist[b][k]
+				}
+			case CompleteLinkage:
+				d = mat.Max(dist[a][k], dist[b][k])
+			case AverageLinkage:
+				d = (mat.Float(sizes[a])*dist[a][k] + mat.Float(sizes[b])*dist[b][k]) / mat.Float(sizes[a]+sizes[b])
+			}
+			dist[a][k], dist[k][a] = d, d
+		}
+		sizes[a] += sizes[b]
+		active[b] = false
+		parent[b] = a
+	}
+
+	labels := make([]int, n)
+	ids := make(map[int]int)
+	for i := range labels {
+		root := i
+		for parent[root] != root {
+			root = parent[root]
+		}
+		id, ok := ids[root]
+		if !ok {
+			id = len(ids)
+			ids[root] = id
+		}
+		labels[i] = id
+	}
+	return labels, nil
+}
@@ -0,0 +1,49 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package clustering
+
+import (
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgglomerative(t *testing.T) {
+	for _, linkage := range []Linkage{SingleLinkage, CompleteLinkage, AverageLinkage} {
+		t.Run(linkage.String(), func(t *testing.T) {
+			labels, err := Agglomerative(newBlobs(), 3, linkage, Euclidean)
+			require.NoError(t, err)
+			assertBlobsLabels(t, labels)
+			assert.Equal(t, 0, labels[0], "the clusters are numbered in order of their first point")
+			assert.Equal(t, 1, labels[4])
+			assert.Equal(t, 2, labels[8])
+		})
+	}
+}
+
+func TestAgglomerative_Linkage(t *testing.T) {
+	// a chain of close points, and two points far apart from each other
+	points := []mat.Matrix{
+		mat.NewVecDense([]mat.Float{0}),
+		mat.NewVecDense([]mat.Float{1}),
+		mat.NewVecDense([]mat.Float{2}),
+		mat.NewVecDense([]mat.Float{3}),
+		mat.NewVecDense([]mat.Float{10}),
+	}
+	single, err := Agglomerative(points, 2, SingleLinkage, Euclidean)
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 0, 0, 0, 1}, single)
+
+	all, err := Agglomerative(points, 1, CompleteLinkage, Euclidean)
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 0, 0, 0, 0}, all)
+
+	_, err = Agglomerative(points, 6, SingleLinkage, Euclidean)
+	assert.Error(t, err)
+	_, err = Agglomerative(points, 2, Linkage(9), Euclidean)
+	assert.Error(t, err)
+}
@@ -0,0 +1,107 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package clustering provides algorithms to cluster dense vectors, such as
+// the sentence embeddings produced by bert.Model.Vectorize():
+// k-means (also in its mini-batch variant), agglomerative clustering,
+// community detection, and the silhouette score to evaluate the results.
+//
+// The points are given as a slice of vectors of the same size.
+package clustering
+
+import (
+	"fmt"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+)
+
+// Metric is the enumeration-like type used to distinguish the distance
+// functions between the points.
+type Metric int
+
+const (
+	// Euclidean is the Euclidean distance.
+	Euclidean Metric = iota
+	// Cosine is the cosine distance, i.e. one minus the cosine similarity.
+	Cosine
+)
+
+// String returns the name of the metric.
+func (m Metric) String() string {
+	switch m {
+	case Euclidean:
+		return "euclidean"
+	case Cosine:
+		return "cosine"
+	default:
+		return fmt.Sprintf("Metric(%d)", int(m))
+	}
+}
+
+// Distance returns the distance between the vectors x and y.
+func (m Metric) Distance(x, y mat.Matrix) mat.Float {
+	return m.distance(x.Data(), y.Data())
+}
+
+func (m Metric) distance(x, y []mat.Float) mat.Float {
+	switch m {
+	case Euclidean:
+		return mat.Sqrt(squaredDistance(x, y))
+	case Cosine:
+		var dot, xNorm, yNorm mat.Float
+		for i, v := range x {
+			dot += v * y[i]
+			xNorm += v * v
+			yNorm += y[i] * y[i]
+		}
+		if xNorm == 0 || yNorm == 0 {
+			return 1
+		}
+		return 1 - dot/mat.Sqrt(xNorm*yNorm)
+	default:
+		panic(fmt.Sprintf("clustering: unknown metric %v", m))
+	}
+}
+
+func squaredDistance(x, y []mat.Float) mat.Float {
+	var sum mat.Float
+	for i, v := range x {
+		d := v - y[i]
+		sum += d * d
+	}
+	return sum
+}
+
+// vectorsData returns the data of the points, checking that they are
+// vectors of the same size.
+func vectorsData(points []mat.Matrix) ([][]mat.Float, error) {
+	if len(points) == 0 {
+		return nil, fmt.Errorf("clustering: no points")
+	}
+	size := points[0].Size()
+	data := make([][]mat.Float, len(points))
+	for i, p := range points {
+		if !p.IsVector() || p.Size() != size {
+			return nil, fmt.Errorf("clustering: the points must be vectors of the same size")
+		}
+		data[i] = p.Data()
+	}
+	return data, nil
+}
+
+// distanceMatrix returns the pairwise distances between the points.
+func distanceMatrix(data [][]mat.Float, metric Metric) [][]mat.Float {
+	n := len(data)
+	d := make([][]mat.Float, n)
+	for i := range d {
+		d[i] = make([]mat.Float, n)
+	}
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			v := metric.distance(data[i], data[j])
+			d[i][j], d[j][i] = v, v
+		}
+	}
+	return d
+}
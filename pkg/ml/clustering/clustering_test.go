@@ -0,0 +1,59 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package clustering
+
+import (
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/stretchr/testify/assert"
+)
+
+// newBlobs returns three well separated groups of four points.
+func newBlobs() []mat.Matrix {
+	centers := [][]mat.Float{{0, 0}, {10, 10}, {-10, 10}}
+	offsets := [][]mat.Float{{0.5, 0}, {-0.5, 0}, {0, 0.5}, {0, -0.5}}
+	points := make([]mat.Matrix, 0, 12)
+	for _, c := range centers {
+		for _, o := range offsets {
+			points = append(points, mat.NewVecDense([]mat.Float{c[0] + o[0], c[1] + o[1]}))
+		}
+	}
+	return points
+}
+
+// assertBlobsLabels asserts that the labels partition the points of newBlobs.
+func assertBlobsLabels(t *testing.T, labels []int) {
+	t.Helper()
+	assert.Len(t, labels, 12)
+	seen := make(map[int]bool)
+	for blob := 0; blob < 3; blob++ {
+		label := labels[blob*4]
+		assert.False(t, seen[label], "each blob must have its own label")
+		seen[label] = true
+		for i := 1; i < 4; i++ {
+			assert.Equal(t, label, labels[blob*4+i])
+		}
+	}
+}
+
+func TestMetric_Distance(t *testing.T) {
+	x := mat.NewVecDense([]mat.Float{1, 0})
+	y := mat.NewVecDense([]mat.Float{0, 2})
+	assert.InDelta(t, mat.Sqrt(5), Euclidean.Distance(x, y), 1.0e-6)
+	assert.InDelta(t, 1, Cosine.Distance(x, y), 1.0e-6)
+	assert.InDelta(t, 0, Cosine.Distance(y, y.ProdScalar(3)), 1.0e-6)
+	assert.InDelta(t, 1, Cosine.Distance(x, mat.NewEmptyVecDense(2)), 1.0e-6)
+	assert.Equal(t, "cosine", Cosine.String())
+}
+
+func TestVectorsData(t *testing.T) {
+	_, err := vectorsData(nil)
+	assert.Error(t, err)
+	_, err = vectorsData([]mat.Matrix{mat.NewEmptyVecDense(2), mat.NewEmptyVecDense(3)})
+	assert.Error(t, err)
+	_, err = vectorsData([]mat.Matrix{mat.NewEmptyDense(2, 2)})
+	assert.Error(t, err)
+}
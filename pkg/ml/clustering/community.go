@@ -0,0 +1,89 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package clustering
+
+import (
+	"fmt"
+	"sort"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+)
+
+// CommunityDetection finds the groups of points (communities) in which each
+// point has a cosine similarity of at least threshold with a central point,
+// without requiring the number of clusters in advance.
+// Each point belongs to at most one community; the communities with less
+// than minSize points are discarded. The communities are sorted by
+// decreasing size, and each one lists the indices of its points, starting
+// with the central one.
+// It requires O(n²) time for n points.
+func CommunityDetection(points []mat.Matrix, threshold mat.Float, minSize int) ([][]int, error) {
+	data, err := vectorsData(points)
+	if err != nil {
+		return nil, err
+	}
+	if minSize < 1 {
+		return nil, fmt.Errorf("clustering: invalid community min size %d", minSize)
+	}
+	n := len(data)
+	dist := distanceMatrix(data, Cosine)
+
+	candidates := make([][]int, 0)
+	for i := 0; i < n; i++ {
+		neighbors := []int{i}
+		for j := 0; j < n; j++ {
+			if j != i && 1-dist[i][j] >= threshold {
+				neighbors = append(neighbors, j)
+			}
+		}
+		if len(neighbors) < minSize {
+			continue
+		}
+		sort.SliceStable(neighbors[1:], func(a, b int) bool {
+			return dist[i][neighbors[1+a]] < dist[i][neighbors[1+b]]
+		})
+		candidates = append(candidates, neighbors)
+	}
+	sort.SliceStable(candidates, func(a, b int) bool {
+		return len(candidates[a]) > len(candidates[b])
+	})
+
+	used := make([]bool, n)
+	communities := make([][]int, 0)
+	for _, candidate := range candidates {
+		community := make([]int, 0, len(candidate))
+		for _, i := range candidate {
+			if !used[i] {
+				community = append(community, i)
+			}
+		}
+		if len(community) < minSize {
+			continue
+		}
+		for _, i := range community {
+			used[i] = true
+		}
+		communities = append(communities, community)
+	}
+	sort.SliceStable(communities, func(a, b int) bool {
+		return len(communities[a]) > len(communities[b])
+	})
+	return communities, nil
+}
+
+// CommunitiesToLabels returns the community of each of the n points, or -1
+// for the points not belonging to any community.
+func CommunitiesToLabels(communities [][]int, n int) []int {
+	labels := make([]int, n)
+	for i := range labels {
+		labels[i] = -1
+	}
+	for label, community := range communities {
+		for _, i := range community {
+			labels[i] = label
+		}
+	}
+	return labels
+}
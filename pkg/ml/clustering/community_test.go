@@ -0,0 +1,38 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package clustering
+
+import (
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommunityDetection(t *testing.T) {
+	points := []mat.Matrix{
+		mat.NewVecDense([]mat.Float{1, 0.1}),
+		mat.NewVecDense([]mat.Float{0.1, 1}),
+		mat.NewVecDense([]mat.Float{1, 0}),
+		mat.NewVecDense([]mat.Float{0, 1}),
+		mat.NewVecDense([]mat.Float{2, 0.1}),
+		mat.NewVecDense([]mat.Float{-1, 0}),
+	}
+	communities, err := CommunityDetection(points, 0.9, 2)
+	require.NoError(t, err)
+	require.Len(t, communities, 2)
+	assert.ElementsMatch(t, []int{0, 2, 4}, communities[0])
+	assert.ElementsMatch(t, []int{1, 3}, communities[1])
+
+	assert.Equal(t, []int{0, 1, 0, 1, 0, -1}, CommunitiesToLabels(communities, len(points)))
+
+	communities, err = CommunityDetection(points, 0.9, 3)
+	require.NoError(t, err)
+	assert.Len(t, communities, 1)
+
+	_, err = CommunityDetection(points, 0.9, 0)
+	assert.Error(t, err)
+}
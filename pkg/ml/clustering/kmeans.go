@@ -0,0 +1,226 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package clustering
+
+import (
+	"fmt"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/mat32/rand"
+)
+
+// KMeansConfig provides configuration settings for KMeans.
+type KMeansConfig struct {
+	// K is the number of clusters.
+	K int
+	// MaxIterations is the maximum number of iterations.
+	MaxIterations int
+	// Tolerance stops the iterations when no centroid moves farther.
+	Tolerance mat.Float
+	// BatchSize, if greater than zero, enables the mini-batch k-means, which
+	// updates the centroids with a random sample of BatchSize points at each
+	// iteration, instead of the whole dataset.
+	BatchSize int
+	// Seed is the seed of the random generator used for the initialization
+	// (k-means++) and the sampling of the mini-batches.
+	Seed uint64
+}
+
+// DefaultKMeansConfig returns a KMeansConfig with k clusters and the default settings.
+func DefaultKMeansConfig(k int) KMeansConfig {
+	return KMeansConfig{
+		K:             k,
+		MaxIterations: 300,
+		Tolerance:     1.0e-4,
+		Seed:          42,
+	}
+}
+
+// KMeansResult is the result of KMeans.
+type KMeansResult struct {
+	// Labels contains the cluster of each point.
+	Labels []int
+	// Centroids contains the center of each cluster.
+	Centroids []mat.Matrix
+	// Inertia is the sum of the squared distances of the points from their centroid.
+	Inertia mat.Float
+	// Iterations is the number of iterations performed.
+	Iterations int
+}
+
+// KMeans partitions the points in config.K clusters, minimizing the
+// Euclidean distances of the points from the centroids of their cluster.
+// The centroids are initialized with k-means++.
+// To cluster by cosine similarity, normalize the points first.
+//
+// Reference for the mini-batch variant: "Web-Scale K-Means Clustering" by D.
+// Sculley, 2010.
+func KMeans(points []mat.Matrix, config KMeansConfig) (*KMeansResult, error) {
+	data, err := vectorsData(points)
+	if err != nil {
+		return nil, err
+	}
+	if config.K <= 0 || config.K > len(data) {
+		return nil, fmt.Errorf("clustering: invalid number of clusters %d for %d points", config.K, len(data))
+	}
+	rndGen := rand.NewLockedRand(config.Seed)
+	centroids := kMeansPlusPlus(data, config.K, rndGen)
+
+	var iterations int
+	if config.BatchSize > 0 {
+		iterations = miniBatchKMeans(data, centroids, config, rndGen)
+	} else {
+		iterations = lloyd(data, centroids, config)
+	}
+
+	result := &KMeansResult{
+		Labels:     make([]int, len(data)),
+		Centroids:  make([]mat.Matrix, len(centroids)),
+		Iterations: iterations,
+	}
+	for i, x := range data {
+		var dist mat.Float
+		result.Labels[i], dist = nearest(x, centroids)
+		result.Inertia += dist
+	}
+	for i, c := range centroids {
+		result.Centroids[i] = mat.NewVecDense(c)
+	}
+	return result, nil
+}
+
+// kMeansPlusPlus returns k initial centroids, each one chosen with a
+// probability proportional to its squared distance from the nearest centroid
+// already chosen.
+func kMeansPlusPlus(data [][]mat.Float, k int, rndGen *rand.LockedRand) [][]mat.Float {
+	centroids := make([][]mat.Float, 0, k)
+	centroids = append(centroids, copyVector(data[rndGen.Intn(len(data))]))
+	dists := make([]mat.Float, len(data))
+	for len(centroids) < k {
+		var sum mat.Float
+		for i, x := range data {
+			_, dists[i] = nearest(x, centroids)
+			sum += dists[i]
+		}
+		next := len(data) - 1
+		target := rndGen.Float() * sum
+		for i, d := range dists {
+			target -= d
+			if target < 0 {
+				next = i
+				break
+			}
+		}
+		if sum == 0 {
+			next = rndGen.Intn(len(data)) // all the points are already centroids
+		}
+		centroids = append(centroids, copyVector(data[next]))
+	}
+	return centroids
+}
+
+// lloyd runs the standard k-means iterations, updating the centroids in
+// place, and returns the number of iterations.
+func lloyd(data [][]mat.Float, centroids [][]mat.Float, config KMeansConfig) int {
+	dim := len(data[0])
+	labels := make([]int, len(data))
+	for i := range labels {
+		labels[i] = -1
+	}
+	sums := make([][]mat.Float, len(centroids))
+	for i := range sums {
+		sums[i] = make([]mat.Float, dim)
+	}
+	counts := make([]int, len(centroids))
+
+	for iter := 1; iter <= config.MaxIterations; iter++ {
+		changed := false
+		for i, x := range data {
+			label, _ := nearest(x, centroids)
+			if label != labels[i] {
+				labels[i], changed = label, true
+			}
+		}
+		if !changed {
+			return iter
+		}
+
+		for c := range sums {
+			counts[c] = 0
+			for j := range sums[c] {
+				sums[c][j] = 0
+			}
+		}
+		for i, x := range data {
+			counts[labels[i]]++
+			for j, v := range x {
+				sums[labels[i]][j] += v
+			}
+		}
+		var shift mat.Float
+		for c, centroid := range centroids {
+			if counts[c] == 0 {
+				continue // an empty cluster keeps its centroid
+			}
+			for j := range centroid {
+				sums[c][j] /= mat.Float(counts[c])
+			}
+			shift = mat.Max(shift, squaredDistance(centroid, sums[c]))
+			copy(centroid, sums[c])
+		}
+		if shift <= config.Tolerance*config.Tolerance {
+			return iter
+		}
+	}
+	return config.MaxIterations
+}
+
+// miniBatchKMeans runs the mini-batch k-means iterations, updating the
+// centroids in place, and returns the number of iterations.
+func miniBatchKMeans(data [][]mat.Float, centroids [][]mat.Float, config KMeansConfig, rndGen *rand.LockedRand) int {
+	counts := make([]int, len(centroids))
+	batch := make([]int, config.BatchSize)
+	labels := make([]int, config.BatchSize)
+	previous := make([]mat.Float, len(data[0]))
+
+	for iter := 1; iter <= config.MaxIterations; iter++ {
+		for i := range batch {
+			batch[i] = rndGen.Intn(len(data))
+			labels[i], _ = nearest(data[batch[i]], centroids)
+		}
+		var shift mat.Float
+		for i, index := range batch {
+			centroid := centroids[labels[i]]
+			copy(previous, centroid)
+			counts[labels[i]]++
+			eta := 1 / mat.Float(counts[labels[i]])
+			for j, v := range data[index] {
+				centroid[j] = (1-eta)*centroid[j] + eta*v
+			}
+			shift = mat.Max(shift, squaredDistance(previous, centroid))
+		}
+		if shift <= config.Tolerance*config.Tolerance {
+			return iter
+		}
+	}
+	return config.MaxIterations
+}
+
+// nearest returns the index of the centroid nearest to x, and its squared distance.
+func nearest(x []mat.Float, centroids [][]mat.Float) (int, mat.Float) {
+	best, bestDist := 0, mat.Inf(1)
+	for i, c := range centroids {
+		if d := squaredDistance(x, c); d < bestDist {
+			best, bestDist = i, d
+		}
+	}
+	return best, bestDist
+}
+
+func copyVector(v []mat.Float) []mat.Float {
+	out := make([]mat.Float, len(v))
+	copy(out, v)
+	return out
+}
@@ -0,0 +1,53 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package clustering
+
+import (
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKMeans(t *testing.T) {
+	points := newBlobs()
+	result, err := KMeans(points, DefaultKMeansConfig(3))
+	require.NoError(t, err)
+
+	assertBlobsLabels(t, result.Labels)
+	assert.Len(t, result.Centroids, 3)
+	assert.InDeltaSlice(t, []mat.Float{10, 10}, result.Centroids[result.Labels[4]].Data(), 1.0e-5)
+	assert.InDelta(t, 12*0.25, result.Inertia, 1.0e-4)
+	assert.Greater(t, result.Iterations, 0)
+}
+
+func TestKMeans_MiniBatch(t *testing.T) {
+	config := DefaultKMeansConfig(3)
+	config.BatchSize = 4
+	config.MaxIterations = 100
+	result, err := KMeans(newBlobs(), config)
+	require.NoError(t, err)
+
+	assertBlobsLabels(t, result.Labels)
+	assert.InDeltaSlice(t, []mat.Float{-10, 10}, result.Centroids[result.Labels[8]].Data(), 0.5)
+}
+
+func TestKMeans_Errors(t *testing.T) {
+	_, err := KMeans(newBlobs(), DefaultKMeansConfig(0))
+	assert.Error(t, err)
+	_, err = KMeans(newBlobs(), DefaultKMeansConfig(13))
+	assert.Error(t, err)
+}
+
+func TestKMeans_DuplicatePoints(t *testing.T) {
+	points := []mat.Matrix{
+		mat.NewVecDense([]mat.Float{1, 1}),
+		mat.NewVecDense([]mat.Float{1, 1}),
+	}
+	result, err := KMeans(points, DefaultKMeansConfig(2))
+	require.NoError(t, err)
+	assert.Equal(t, mat.Float(0), result.Inertia)
+}
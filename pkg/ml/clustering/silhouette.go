@@ -0,0 +1,83 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package clustering
+
+import (
+	"fmt"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+)
+
+// Silhouette returns the mean silhouette coefficient of the clustered points,
+// in [-1, 1]: values near 1 denote compact and well separated clusters.
+// For each point, the coefficient is (b - a) / max(a, b), where a is the mean
+// distance from the other points of its cluster, and b the mean distance
+// from the points of the nearest other cluster; it is zero for the points of
+// single-point clusters.
+// The points with a negative label (unassigned) are ignored.
+func Silhouette(points []mat.Matrix, labels []int, metric Metric) (mat.Float, error) {
+	data, err := vectorsData(points)
+	if err != nil {
+		return 0, err
+	}
+	if len(labels) != len(data) {
+		return 0, fmt.Errorf("clustering: %d labels for %d points", len(labels), len(data))
+	}
+	numClusters := 0
+	for _, label := range labels {
+		if label >= numClusters {
+			numClusters = label + 1
+		}
+	}
+	sizes := make([]int, numClusters)
+	for _, label := range labels {
+		if label >= 0 {
+			sizes[label]++
+		}
+	}
+	nonEmpty := 0
+	for _, size := range sizes {
+		if size > 0 {
+			nonEmpty++
+		}
+	}
+	if nonEmpty < 2 {
+		return 0, fmt.Errorf("clustering: the silhouette requires at least two clusters")
+	}
+
+	var sum mat.Float
+	var count int
+	sums := make([]mat.Float, numClusters)
+	for i, x := range data {
+		if labels[i] < 0 {
+			continue
+		}
+		count++
+		if sizes[labels[i]] == 1 {
+			continue
+		}
+		for c := range sums {
+			sums[c] = 0
+		}
+		for j, y := range data {
+			if j != i && labels[j] >= 0 {
+				sums[labels[j]] += metric.distance(x, y)
+			}
+		}
+		a := sums[labels[i]] / mat.Float(sizes[labels[i]]-1)
+		b := mat.Inf(1)
+		for c, s := range sums {
+			if c != labels[i] && sizes[c] > 0 {
+				if mean := s / mat.Float(sizes[c]); mean < b {
+					b = mean
+				}
+			}
+		}
+		if max := mat.Max(a, b); max > 0 {
+			sum += (b - a) / max
+		}
+	}
+	return sum / mat.Float(count), nil
+}
@@ -0,0 +1,50 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package clustering
+
+import (
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSilhouette(t *testing.T) {
+	points := []mat.Matrix{
+		mat.NewVecDense([]mat.Float{0}),
+		mat.NewVecDense([]mat.Float{1}),
+		mat.NewVecDense([]mat.Float{4}),
+		mat.NewVecDense([]mat.Float{5}),
+	}
+	score, err := Silhouette(points, []int{0, 0, 1, 1}, Euclidean)
+	require.NoError(t, err)
+	// a = 1 for all points; b = 4.5, 3.5, 3.5, 4.5
+	expected := ((4.5-1)/4.5 + (3.5-1)/3.5) / 2
+	assert.InDelta(t, expected, score, 1.0e-6)
+
+	bad, err := Silhouette(points, []int{0, 1, 0, 1}, Euclidean)
+	require.NoError(t, err)
+	assert.Less(t, bad, mat.Float(0))
+
+	// unassigned points are ignored, single-point clusters count as zero
+	score, err = Silhouette(points, []int{0, 0, 1, -1}, Euclidean)
+	require.NoError(t, err)
+	assert.InDelta(t, ((4-1)/4.0+(3-1)/3.0)/3, score, 1.0e-6)
+
+	_, err = Silhouette(points, []int{0, 0, 0, 0}, Euclidean)
+	assert.Error(t, err)
+	_, err = Silhouette(points, []int{0, 1}, Euclidean)
+	assert.Error(t, err)
+}
+
+func TestSilhouette_Blobs(t *testing.T) {
+	points := newBlobs()
+	result, err := KMeans(points, DefaultKMeansConfig(3))
+	require.NoError(t, err)
+	score, err := Silhouette(points, result.Labels, Euclidean)
+	require.NoError(t, err)
+	assert.Greater(t, score, mat.Float(0.9))
+}
@@ -37,7 +37,7 @@ func NLL(g *ag.Graph, x ag.Node, y ag.Node) ag.Node {
 // x is the raw scores for each class (logits).
 // c is the index of the gold class.
 func CrossEntropy(g *ag.Graph, x ag.Node, c int) ag.Node {
-	return g.Add(g.Neg(g.AtVec(x, c)), g.Log(g.ReduceSum(g.Exp(x))))
+	return g.SoftmaxCrossEntropy(x, c)
 }
 
 // WeightedCrossEntropy implements a weighted cross-entropy loss function.
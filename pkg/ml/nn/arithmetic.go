@@ -0,0 +1,108 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nn
+
+import (
+	"fmt"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+)
+
+// LinearCombination sets each parameter of dst to the linear combination of
+// the parameters with the same qualified name (see ForEachNamedParam) of the
+// given models, weighted by the coefficients:
+//
+//    dst = coefficients[0] * models[0] + coefficients[1] * models[1] + ...
+//
+// The models must have the same architecture as dst: it returns an error,
+// without modifying dst, if a parameter is missing or has different dims.
+// The destination can be one of the models (e.g. to scale a model in place).
+//
+// It is the building block of the parameter arithmetic, e.g. the model soups
+// and the federated averaging (see Average), the interpolation of models (see
+// Interpolate), and the task arithmetic (base + λ * (finetuned - base) is the
+// combination of base and finetuned with coefficients 1-λ and λ).
+func LinearCombination(dst Model, coefficients []mat.Float, models ...Model) error {
+	if len(models) == 0 {
+		return fmt.Errorf("nn: no models to combine")
+	}
+	if len(coefficients) != len(models) {
+		return fmt.Errorf("nn: %d coefficients for %d models", len(coefficients), len(models))
+	}
+	dstParams := namedParams(dst)
+	params := make([]map[string]Param, len(models))
+	for i, m := range models {
+		params[i] = namedParams(m)
+		if err := checkSameParams(dstParams, params[i]); err != nil {
+			return fmt.Errorf("nn: model %d: %w", i, err)
+		}
+	}
+	values := make(map[string]mat.Matrix, len(dstParams))
+	for name, p := range dstParams {
+		value := p.Value().ZerosLike()
+		for i, c := range coefficients {
+			value.AddInPlace(params[i][name].Value().ProdScalar(c))
+		}
+		values[name] = value
+	}
+	// the values are replaced at the end, since dst can be one of the models
+	for name, value := range values {
+		dstParams[name].ReplaceValue(value)
+	}
+	return nil
+}
+
+// Average sets the parameters of dst to the average of the parameters of the
+// given models (e.g. a uniform model soup, or a federated averaging round).
+func Average(dst Model, models ...Model) error {
+	coefficients := make([]mat.Float, len(models))
+	for i := range coefficients {
+		coefficients[i] = 1 / mat.Float(len(models))
+	}
+	return LinearCombination(dst, coefficients, models...)
+}
+
+// Interpolate sets the parameters of dst to the linear interpolation of the
+// parameters of a and b: dst = (1-t) * a + t * b.
+func Interpolate(dst, a, b Model, t mat.Float) error {
+	return LinearCombination(dst, []mat.Float{1 - t, t}, a, b)
+}
+
+// Scale multiplies all the parameters of the model by the factor. The tied
+// params are scaled once (see ForEachDistinctParam).
+func Scale(m Model, factor mat.Float) {
+	ForEachDistinctParam(m, func(param Param) {
+		param.ReplaceValue(param.Value().ProdScalar(factor))
+	})
+}
+
+// namedParams returns the parameters of the model indexed by their qualified
+// name.
+func namedParams(m Model) map[string]Param {
+	params := make(map[string]Param)
+	ForEachNamedParam(m, func(name string, param Param) {
+		params[name] = param
+	})
+	return params
+}
+
+// checkSameParams returns an error if the two sets of parameters don't have
+// the same names and dims.
+func checkSameParams(expected, actual map[string]Param) error {
+	if len(expected) != len(actual) {
+		return fmt.Errorf("%d parameters, expected %d", len(actual), len(expected))
+	}
+	for name, p := range expected {
+		q, ok := actual[name]
+		if !ok {
+			return fmt.Errorf("missing parameter %#v", name)
+		}
+		if !mat.SameDims(p.Value(), q.Value()) {
+			return fmt.Errorf("parameter %#v: dims %d×%d, expected %d×%d",
+				name, q.Value().Rows(), q.Value().Columns(), p.Value().Rows(), p.Value().Columns())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nn
+
+import (
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type arithmeticLayer struct {
+	BaseModel
+	W Param `spago:"type:weights"`
+	B Param `spago:"type:biases"`
+}
+
+func (m *arithmeticLayer) Forward(_ interface{}) interface{} {
+	panic("this should never be called")
+}
+
+type arithmeticModel struct {
+	BaseModel
+	Layers []*arithmeticLayer
+}
+
+func (m *arithmeticModel) Forward(_ interface{}) interface{} {
+	panic("this should never be called")
+}
+
+func newArithmeticModel(values ...mat.Float) *arithmeticModel {
+	m := &arithmeticModel{}
+	for _, v := range values {
+		m.Layers = append(m.Layers, &arithmeticLayer{
+			W: NewParam(mat.NewInitDense(2, 2, v)),
+			B: NewParam(mat.NewInitVecDense(2, -v)),
+		})
+	}
+	return m
+}
+
+func TestForEachNamedParam(t *testing.T) {
+	m := newArithmeticModel(1, 2)
+	var names []string
+	ForEachNamedParam(m, func(name string, param Param) {
+		names = append(names, name)
+	})
+	assert.Equal(t, []string{"Layers.0.W", "Layers.0.B", "Layers.1.W", "Layers.1.B"}, names)
+	assert.Equal(t, "w", m.Layers[1].W.Name())
+}
+
+func TestLinearCombination(t *testing.T) {
+	a := newArithmeticModel(1, 2)
+	b := newArithmeticModel(10, 20)
+	dst := newArithmeticModel(0, 0)
+
+	require.NoError(t, LinearCombination(dst, []mat.Float{2, 0.5}, a, b))
+	assert.Equal(t, []mat.Float{7, 7, 7, 7}, dst.Layers[0].W.Value().Data())
+	assert.Equal(t, []mat.Float{-14, -14}, dst.Layers[1].B.Value().Data())
+	assert.Equal(t, []mat.Float{1, 1, 1, 1}, a.Layers[0].W.Value().Data())
+
+	t.Run("in place", func(t *testing.T) {
+		require.NoError(t, LinearCombination(a, []mat.Float{1, -1}, a, b))
+		assert.Equal(t, []mat.Float{-9, -9, -9, -9}, a.Layers[0].W.Value().Data())
+		assert.Equal(t, []mat.Float{18, 18}, a.Layers[1].B.Value().Data())
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		assert.Error(t, LinearCombination(dst, []mat.Float{1}, a, b))
+		assert.Error(t, LinearCombination(dst, nil))
+		assert.Error(t, LinearCombination(dst, []mat.Float{1}, newArithmeticModel(1)))
+
+		c := newArithmeticModel(1, 2)
+		c.Layers[1].W = NewParam(mat.NewEmptyDense(3, 2))
+		assert.Error(t, LinearCombination(dst, []mat.Float{1}, c))
+		assert.Equal(t, []mat.Float{7, 7, 7, 7}, dst.Layers[0].W.Value().Data())
+	})
+}
+
+func TestAverage(t *testing.T) {
+	dst := newArithmeticModel(0)
+	require.NoError(t, Average(dst, newArithmeticModel(1), newArithmeticModel(2), newArithmeticModel(6)))
+	assert.InDeltaSlice(t, []mat.Float{3, 3, 3, 3}, dst.Layers[0].W.Value().Data(), 1.0e-6)
+	assert.InDeltaSlice(t, []mat.Float{-3, -3}, dst.Layers[0].B.Value().Data(), 1.0e-6)
+}
+
+func TestInterpolate(t *testing.T) {
+	dst := newArithmeticModel(0)
+	require.NoError(t, Interpolate(dst, newArithmeticModel(1), newArithmeticModel(5), 0.25))
+	assert.InDeltaSlice(t, []mat.Float{2, 2, 2, 2}, dst.Layers[0].W.Value().Data(), 1.0e-6)
+}
+
+func TestScale(t *testing.T) {
+	m := newArithmeticModel(2)
+	Scale(m, 1.5)
+	assert.InDeltaSlice(t, []mat.Float{3, 3, 3, 3}, m.Layers[0].W.Value().Data(), 1.0e-6)
+	assert.InDeltaSlice(t, []mat.Float{-3, -3}, m.Layers[0].B.Value().Data(), 1.0e-6)
+}
+
+func TestScale_TiedParams(t *testing.T) {
+	m := newArithmeticModel(1, 2)
+	TieWeights(m.Layers[0].B, m.Layers[1].B)
+	m.Layers[0].B.ReplaceValue(mat.NewVecDense([]mat.Float{1, 2}))
+	Scale(m, 2)
+	assert.Equal(t, []mat.Float{2, 4}, m.Layers[0].B.Value().Data())
+	assert.Equal(t, []mat.Float{2, 4}, m.Layers[1].B.Value().Data())
+	assert.Equal(t, []mat.Float{4, 4, 4, 4}, m.Layers[1].W.Value().Data())
+
+	vector := DumpParamsVector(m)
+	assert.Equal(t, 10, vector.Size())
+	LoadParamsVector(m, vector.ProdScalar(0.5))
+	assert.Equal(t, []mat.Float{1, 2}, m.Layers[1].B.Value().Data())
+}
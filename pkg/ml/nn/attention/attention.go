@@ -49,27 +49,51 @@ func ToQKV(xs []ag.Node) QKV {
 // This method requires that the query, the key and the value vectors have already been obtained
 // from the input sequence. The scaled factor is the square root of the dimension of the key vectors.
 func ScaledDotProductAttention(g *ag.Graph, qkv QKV, scaleFactor mat.Float, useCausalMask bool) (context []ag.Node, prob []mat.Matrix) {
+	return ScaledDotProductAttentionWithBias(g, qkv, scaleFactor, useCausalMask, 0, nil)
+}
+
+// ScaledDotProductAttentionWithBias is like ScaledDotProductAttention, but adds the position bias
+// (if not nil) to the scaled attention scores. The queries start at position offset (e.g. the
+// number of past keys), while the keys always start at zero.
+func ScaledDotProductAttentionWithBias(g *ag.Graph, qkv QKV, scaleFactor mat.Float, useCausalMask bool, offset int, bias PositionBias) (context []ag.Node, prob []mat.Matrix) {
 	context = make([]ag.Node, len(qkv.Queries))
 	prob = make([]mat.Matrix, len(qkv.Queries))
 	keys := g.Stack(qkv.Keys...)
-	values := g.T(g.Stack(qkv.Values...))
+	values := g.Stack(qkv.Values...)
 	factor := g.NewScalar(scaleFactor)
+	var keyPositions []int
+	if bias != nil {
+		keyPositions = positions(0, len(qkv.Keys))
+	}
 
 	for i, q := range qkv.Queries {
 		attScores := g.ProdScalar(g.Mul(keys, q), factor)
+		if bias != nil {
+			attScores = g.Add(attScores, bias(g, offset+i, keyPositions))
+		}
 
+		var attProb ag.Node
 		if useCausalMask && len(qkv.Queries) > 1 {
-			causalMask := MakeCausalMask(i, len(qkv.Keys)) // TODO: use external cache for causal mask?
-			attScores = g.Add(attScores, g.NewVariable(mat.NewVecDense(causalMask), false))
+			causalMask := MakeCausalAttentionMask(offset+i, len(qkv.Keys)) // TODO: use external cache for causal mask?
+			attProb = g.MaskedSoftmax(attScores, mat.NewVecDense(causalMask))
+		} else {
+			attProb = g.Softmax(attScores)
 		}
-
-		attProb := g.Softmax(attScores)
-		context[i] = g.Mul(values, attProb)
+		context[i] = g.TMatMul(values, attProb)
 		prob[i] = attProb.Value()
 	}
 	return
 }
 
+// positions returns the positions from start to end (excluded).
+func positions(start, end int) []int {
+	ps := make([]int, end-start)
+	for i := range ps {
+		ps[i] = start + i
+	}
+	return ps
+}
+
 // MakeCausalMask returns a slice of size seqLength filled with zeros until curIndex, and the rest with -inf.
 func MakeCausalMask(curIndex, seqLength int) []mat.Float {
 	causalMask := make([]mat.Float, seqLength)
@@ -79,12 +103,23 @@ func MakeCausalMask(curIndex, seqLength int) []mat.Float {
 	return causalMask
 }
 
+// MakeCausalAttentionMask returns a mask of size seqLength, to be used with the
+// masked operations (e.g. ag.Graph.MaskedSoftmax), filled with ones until
+// curIndex, and the rest with zeros.
+func MakeCausalAttentionMask(curIndex, seqLength int) []mat.Float {
+	mask := make([]mat.Float, seqLength)
+	for k := 0; k <= curIndex && k < seqLength; k++ {
+		mask[k] = 1
+	}
+	return mask
+}
+
 // ScaledDotProductAttentionConcurrent does the same thing as ScaledDotProductAttention but processes input concurrently.
 func ScaledDotProductAttentionConcurrent(g *ag.Graph, qkv QKV, scaleFactor mat.Float) (context []ag.Node, prob []mat.Matrix) {
 	context = make([]ag.Node, len(qkv.Queries))
 	prob = make([]mat.Matrix, len(qkv.Queries))
 	keys := g.Stack(qkv.Keys...)
-	values := g.T(g.Stack(qkv.Values...))
+	values := g.Stack(qkv.Values...)
 	factor := g.NewScalar(scaleFactor)
 	var wg sync.WaitGroup
 	wg.Add(len(qkv.Queries))
@@ -93,7 +128,7 @@ func ScaledDotProductAttentionConcurrent(g *ag.Graph, qkv QKV, scaleFactor mat.F
 			defer wg.Done()
 			attScores := g.ProdScalar(g.Mul(keys, q), factor)
 			attProb := g.Softmax(attScores)
-			context[i] = g.Mul(values, attProb)
+			context[i] = g.TMatMul(values, attProb)
 			prob[i] = attProb.Value()
 		}(i, q)
 	}
@@ -117,12 +152,11 @@ func LinearAttention(g *ag.Graph, qkv QKV, mappingFunction MappingFunc, eps mat.
 		attKeysSum = g.Add(attKeysSum, attKeys[i])
 	}
 
-	attKeysT := g.T(g.Stack(attKeys...))
-	kv := g.Mul(attKeysT, g.Stack(qkv.Values...))
+	kv := g.TMatMul(g.Stack(attKeys...), g.Stack(qkv.Values...))
 
 	for i := range qkv.Queries {
 		attQuery := mappingFunction(g, qkv.Queries[i])
-		n := g.T(g.Mul(g.T(attQuery), kv))
+		n := g.TMatMul(kv, attQuery)
 		d := g.Dot(attQuery, attKeysSum)
 		context[i] = g.DivScalar(n, g.AddScalar(d, g.Constant(eps)))
 	}
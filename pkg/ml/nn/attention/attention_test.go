@@ -42,6 +42,32 @@ func TestScaledDotProductAttention(t *testing.T) {
 	assert.InDeltaSlice(t, []mat.Float{2.20423303670527, 8.41210390591632, 0.152898186332002}, context[2].Value().Data(), 1.0e-5)
 }
 
+func TestScaledDotProductAttention_CausalMask(t *testing.T) {
+	g := ag.NewGraph()
+
+	xs := []ag.Node{
+		g.NewVariable(mat.NewVecDense([]mat.Float{1.1, 0.0, 2.3}), true),
+		g.NewVariable(mat.NewVecDense([]mat.Float{2.2, -0.5, 0.3}), true),
+		g.NewVariable(mat.NewVecDense([]mat.Float{3.2, 0.5, 0.4}), true),
+	}
+
+	context, probs := ScaledDotProductAttention(g, ToQKV(xs), 1.0/mat.Sqrt(3), true)
+
+	assert.InDeltaSlice(t, []mat.Float{1, 0, 0}, probs[0].Data(), 1.0e-6)
+	assert.InDeltaSlice(t, xs[0].Value().Data(), context[0].Value().Data(), 1.0e-6)
+	assert.Equal(t, mat.Float(0), probs[1].AtVec(2))
+	assert.InDelta(t, 1, probs[1].AtVec(0)+probs[1].AtVec(1), 1.0e-6)
+	assert.InDelta(t, 1, probs[2].Sum(), 1.0e-6)
+
+	g.Backward(g.ReduceSum(g.Concat(context...)))
+	assert.NotNil(t, xs[2].Grad())
+}
+
+func TestMakeCausalAttentionMask(t *testing.T) {
+	assert.Equal(t, []mat.Float{1, 1, 0, 0}, MakeCausalAttentionMask(1, 4))
+	assert.Equal(t, []mat.Float{1, 1}, MakeCausalAttentionMask(3, 2))
+}
+
 //gocyclo:ignore
 func TestScaledDotProductAttention2(t *testing.T) {
 	g := ag.NewGraph()
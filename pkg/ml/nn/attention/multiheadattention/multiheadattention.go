@@ -32,10 +32,34 @@ func init() {
 	gob.Register(&Model{})
 }
 
+// Config provides configuration settings for a multi-head attention Model.
+type Config struct {
+	Size          int
+	NumOfHeads    int
+	UseCausalMask bool
+	// Positional configures the positional scheme of the heads. With
+	// attention.ALiBi, the slope of each head is given by attention.ALiBiSlopes.
+	Positional attention.PositionalConfig
+	// SlidingWindow configures the local attention of the heads (see
+	// attention.SlidingWindowConfig).
+	SlidingWindow attention.SlidingWindowConfig
+}
+
 // New returns a new model with parameters initialized to zeros.
 func New(size, numOfHeads int, useCausalMask bool) *Model {
-	dm := size
-	dk := size / numOfHeads
+	return NewWithConfig(Config{
+		Size:          size,
+		NumOfHeads:    numOfHeads,
+		UseCausalMask: useCausalMask,
+	})
+}
+
+// NewWithConfig returns a new model with parameters initialized to zeros,
+// according to the given configuration.
+func NewWithConfig(config Config) *Model {
+	dm := config.Size
+	numOfHeads := config.NumOfHeads
+	dk := dm / numOfHeads
 	att := make([]*selfattention.Model, numOfHeads)
 	attentionConfig := selfattention.Config{
 		InputSize:     dm,
@@ -43,9 +67,18 @@ func New(size, numOfHeads int, useCausalMask bool) *Model {
 		KeySize:       dk,
 		ValueSize:     dk,
 		ScaleFactor:   1.0 / mat.Sqrt(mat.Float(dk)),
-		UseCausalMask: useCausalMask,
+		UseCausalMask: config.UseCausalMask,
+		Positional:    config.Positional,
+		SlidingWindow: config.SlidingWindow,
+	}
+	var slopes []mat.Float
+	if config.Positional.Scheme == attention.ALiBi {
+		slopes = attention.ALiBiSlopes(numOfHeads)
 	}
 	for i := 0; i < numOfHeads; i++ {
+		if slopes != nil {
+			attentionConfig.Positional.ALiBiSlope = slopes[i]
+		}
 		att[i] = selfattention.New(attentionConfig)
 	}
 	return &Model{
@@ -0,0 +1,210 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package attention
+
+import (
+	"math"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+)
+
+// PositionalScheme is the enumeration-like type used for the set of methods
+// encoding the relative positions of the queries and the keys in the
+// attention itself, instead of in the input embeddings.
+type PositionalScheme int
+
+const (
+	// NoPositions doesn't encode the positions in the attention.
+	NoPositions PositionalScheme = iota
+	// RelativePositionBias adds a learned bias to the attention scores for
+	// each bucket of relative positions, as in T5.
+	// Reference: "Exploring the Limits of Transfer Learning with a Unified
+	// Text-to-Text Transformer" by Raffel et al. (2019)
+	// (https://arxiv.org/abs/1910.10683)
+	RelativePositionBias
+	// ALiBi adds to the attention scores a penalty proportional to the
+	// distance between the query and the key.
+	// Reference: "Train Short, Test Long: Attention with Linear Biases Enables
+	// Input Length Extrapolation" by Press et al. (2021)
+	// (https://arxiv.org/abs/2108.12409)
+	ALiBi
+	// Rotary rotates the queries and the keys by angles proportional to
+	// their positions (RoPE).
+	// Reference: "RoFormer: Enhanced Transformer with Rotary Position
+	// Embedding" by Su et al. (2021) (https://arxiv.org/abs/2104.09864)
+	Rotary
+)
+
+// PositionalConfig provides the configuration of the positional scheme of
+// an attention layer.
+type PositionalConfig struct {
+	Scheme PositionalScheme
+	// NumBuckets is the number of buckets of the RelativePositionBias.
+	NumBuckets int
+	// MaxDistance is the distance from which the relative positions share
+	// the last bucket of the RelativePositionBias.
+	MaxDistance int
+	// Bidirectional distinguishes the keys preceding and following the query
+	// in the RelativePositionBias (e.g. in an encoder), and makes the
+	// distances of ALiBi symmetric.
+	Bidirectional bool
+	// ALiBiSlope is the slope of the ALiBi penalty, which differs for each
+	// head (see ALiBiSlopes).
+	ALiBiSlope mat.Float
+	// RotaryDim is the number of dimensions of the queries and the keys
+	// rotated by RoPE (the first ones). If zero, all the dimensions are rotated.
+	RotaryDim int
+	// RotaryBase is the base of the wavelengths of RoPE. If zero, 10000.
+	RotaryBase mat.Float
+}
+
+// ALiBiSlopes returns the slopes of ALiBi for the given number of heads: a
+// geometric sequence starting at 2^(-8/n) with the same ratio, for n heads
+// (interleaving the sequence for the closest power of two when n isn't).
+func ALiBiSlopes(numOfHeads int) []mat.Float {
+	powerOfTwo := 1
+	for powerOfTwo*2 <= numOfHeads {
+		powerOfTwo *= 2
+	}
+	geometric := func(n int) []mat.Float {
+		start := math.Pow(2, -8/float64(n))
+		slopes := make([]mat.Float, n)
+		for i := range slopes {
+			slopes[i] = mat.Float(math.Pow(start, float64(i+1)))
+		}
+		return slopes
+	}
+	slopes := geometric(powerOfTwo)
+	if powerOfTwo < numOfHeads {
+		extra := geometric(2 * powerOfTwo)
+		for i := 0; len(slopes) < numOfHeads; i += 2 {
+			slopes = append(slopes, extra[i])
+		}
+	}
+	return slopes
+}
+
+// RelativePositionBucket returns the bucket of the relative position of a
+// key with respect to a query (key position - query position), with the same
+// buckets of T5: half of them for the exact small distances, the others for
+// the distances growing logarithmically up to maxDistance.
+func RelativePositionBucket(relativePosition int, bidirectional bool, numBuckets, maxDistance int) int {
+	bucket := 0
+	n := -relativePosition
+	if bidirectional {
+		numBuckets /= 2
+		if n < 0 {
+			bucket += numBuckets
+			n = -n
+		}
+	} else if n < 0 {
+		n = 0
+	}
+	maxExact := numBuckets / 2
+	if n < maxExact {
+		return bucket + n
+	}
+	large := maxExact + int(math.Log(float64(n)/float64(maxExact))/
+		math.Log(float64(maxDistance)/float64(maxExact))*float64(numBuckets-maxExact))
+	if large > numBuckets-1 {
+		large = numBuckets - 1
+	}
+	return bucket + large
+}
+
+// PositionBias returns the bias of the attention scores of the query at the
+// given position, with an element for each of the keys at the given positions.
+type PositionBias func(g *ag.Graph, queryPos int, keyPositions []int) ag.Node
+
+// RelativePositionBiasFunc returns the PositionBias looking up the bias of
+// each bucket of relative positions in the given vector.
+func RelativePositionBiasFunc(config PositionalConfig, bias ag.Node) PositionBias {
+	return func(g *ag.Graph, queryPos int, keyPositions []int) ag.Node {
+		buckets := make([]int, len(keyPositions))
+		for j, keyPos := range keyPositions {
+			buckets[j] = RelativePositionBucket(keyPos-queryPos, config.Bidirectional, config.NumBuckets, config.MaxDistance)
+		}
+		return g.Gather(bias, buckets)
+	}
+}
+
+// ALiBiFunc returns the PositionBias of ALiBi with the slope of the config.
+func ALiBiFunc(config PositionalConfig) PositionBias {
+	return func(g *ag.Graph, queryPos int, keyPositions []int) ag.Node {
+		bias := make([]mat.Float, len(keyPositions))
+		for j, keyPos := range keyPositions {
+			distance := queryPos - keyPos
+			if distance < 0 {
+				if !config.Bidirectional {
+					continue // masked, if causal
+				}
+				distance = -distance
+			}
+			bias[j] = -config.ALiBiSlope * mat.Float(distance)
+		}
+		return g.NewVariable(mat.NewVecDense(bias), false)
+	}
+}
+
+// ApplyRotary rotates each vector of xs (the queries or the keys of a head)
+// by the angles of its position, the first one being at position offset.
+// The pairs of rotated dimensions are (i, i + RotaryDim/2), as in GPT-NeoX
+// and LLaMA.
+func ApplyRotary(g *ag.Graph, config PositionalConfig, xs []ag.Node, offset int) []ag.Node {
+	if len(xs) == 0 {
+		return xs
+	}
+	size := xs[0].Value().Size()
+	dim := config.RotaryDim
+	if dim == 0 {
+		dim = size
+	}
+	if dim%2 != 0 || dim > size {
+		panic("attention: invalid rotary dimension")
+	}
+	base := float64(config.RotaryBase)
+	if base == 0 {
+		base = 10000
+	}
+	half := dim / 2
+
+	// The rotation is x*cos + rotateHalf(x)*sin, where rotateHalf swaps the
+	// halves, negating the second one.
+	rotateHalf := make([]int, size)
+	for i := range rotateHalf {
+		switch {
+		case i < half:
+			rotateHalf[i] = i + half
+		case i < dim:
+			rotateHalf[i] = i - half
+		default:
+			rotateHalf[i] = i
+		}
+	}
+	ys := make([]ag.Node, len(xs))
+	for t, x := range xs {
+		cos := make([]mat.Float, size)
+		sin := make([]mat.Float, size)
+		for i := range cos {
+			if i >= dim {
+				cos[i] = 1
+				continue
+			}
+			freq := math.Pow(base, -float64(2*(i%half))/float64(dim))
+			angle := float64(offset+t) * freq
+			cos[i] = mat.Float(math.Cos(angle))
+			sin[i] = mat.Float(math.Sin(angle))
+			if i < half {
+				sin[i] = -sin[i]
+			}
+		}
+		ys[t] = g.Add(
+			g.Prod(x, g.NewVariable(mat.NewVecDense(cos), false)),
+			g.Prod(g.Gather(x, rotateHalf), g.NewVariable(mat.NewVecDense(sin), false)),
+		)
+	}
+	return ys
+}
@@ -0,0 +1,81 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package attention
+
+import (
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestALiBiSlopes(t *testing.T) {
+	assert.InDeltaSlice(t, []mat.Float{0.25, 0.0625, 0.015625, 0.00390625}, ALiBiSlopes(4), 1.0e-9)
+	assert.InDeltaSlice(t, []mat.Float{0.25, 0.0625, 0.015625, 0.00390625, 0.5, 0.125}, ALiBiSlopes(6), 1.0e-9)
+	assert.Len(t, ALiBiSlopes(12), 12)
+}
+
+func TestRelativePositionBucket(t *testing.T) {
+	assert.Equal(t, 0, RelativePositionBucket(0, true, 32, 128))
+	assert.Equal(t, 1, RelativePositionBucket(-1, true, 32, 128))
+	assert.Equal(t, 17, RelativePositionBucket(1, true, 32, 128))
+	assert.Equal(t, 15, RelativePositionBucket(-100, true, 32, 128))
+	assert.Equal(t, 15, RelativePositionBucket(-1000, true, 32, 128))
+	assert.Equal(t, 31, RelativePositionBucket(1000, true, 32, 128))
+
+	assert.Equal(t, 0, RelativePositionBucket(5, false, 32, 128))
+	assert.Equal(t, 15, RelativePositionBucket(-15, false, 32, 128))
+	assert.Equal(t, 31, RelativePositionBucket(-1000, false, 32, 128))
+}
+
+func TestApplyRotary(t *testing.T) {
+	g := ag.NewGraph()
+	x := g.NewVariable(mat.NewVecDense([]mat.Float{1, 2, 3, 4, 5}), false)
+	config := PositionalConfig{Scheme: Rotary, RotaryDim: 4}
+
+	ys := ApplyRotary(g, config, []ag.Node{x, x}, 0)
+	assert.InDeltaSlice(t, []mat.Float{1, 2, 3, 4, 5}, ys[0].Value().Data(), 1.0e-6)
+
+	// position 1: the pairs (0, 2) and (1, 3) are rotated by 1 and 0.01 radians
+	c1, s1 := mat.Cos(1), mat.Sin(1)
+	c2, s2 := mat.Cos(0.01), mat.Sin(0.01)
+	assert.InDeltaSlice(t, []mat.Float{
+		1*c1 - 3*s1,
+		2*c2 - 4*s2,
+		3*c1 + 1*s1,
+		4*c2 + 2*s2,
+		5,
+	}, ys[1].Value().Data(), 1.0e-5)
+
+	assert.Panics(t, func() { ApplyRotary(g, PositionalConfig{RotaryDim: 3}, ys, 0) })
+}
+
+func TestScaledDotProductAttentionWithBias(t *testing.T) {
+	g := ag.NewGraph()
+	zeros := func() ag.Node { return g.NewVariable(mat.NewVecDense([]mat.Float{0, 0}), false) }
+	qkv := QKV{
+		Queries: []ag.Node{zeros()},
+		Keys:    []ag.Node{zeros(), zeros(), zeros()},
+		Values: []ag.Node{
+			g.NewVariable(mat.NewVecDense([]mat.Float{1, 0}), false),
+			g.NewVariable(mat.NewVecDense([]mat.Float{0, 1}), false),
+			g.NewVariable(mat.NewVecDense([]mat.Float{0, 0}), false),
+		},
+	}
+	bias := ALiBiFunc(PositionalConfig{Scheme: ALiBi, ALiBiSlope: 1})
+	_, probs := ScaledDotProductAttentionWithBias(g, qkv, 1, true, 2, bias)
+
+	// the query at position 2 penalizes the keys by their distance
+	expected := []mat.Float{mat.Exp(-2), mat.Exp(-1), 1}
+	sum := expected[0] + expected[1] + expected[2]
+	for i := range expected {
+		expected[i] /= sum
+	}
+	assert.InDeltaSlice(t, expected, probs[0].Data(), 1.0e-6)
+
+	_, probs = ScaledDotProductAttention(g, qkv, 1, false)
+	assert.InDeltaSlice(t, []mat.Float{1.0 / 3, 1.0 / 3, 1.0 / 3}, probs[0].Data(), 1.0e-6)
+}
@@ -24,6 +24,9 @@ type Model struct {
 	Query *linear.Model
 	Key   *linear.Model
 	Value *linear.Model
+	// RelativeBias contains the bias of each bucket of relative positions,
+	// with the attention.RelativePositionBias scheme.
+	RelativeBias nn.Param `spago:"type:weights"`
 }
 
 // Config provides configuration settings for a Self-Attention Model.
@@ -34,6 +37,12 @@ type Config struct {
 	ValueSize     int
 	ScaleFactor   mat.Float
 	UseCausalMask bool
+	// Positional configures how the relative positions of the queries and the
+	// keys are encoded in the attention (none by default).
+	Positional attention.PositionalConfig
+	// SlidingWindow configures the local attention of Longformer, if its
+	// window is not zero.
+	SlidingWindow attention.SlidingWindowConfig
 }
 
 func init() {
@@ -42,23 +51,27 @@ func init() {
 
 // New returns a new model with parameters initialized to zeros.
 func New(config Config) *Model {
-	return &Model{
+	m := &Model{
 		Config: config,
 		Query:  linear.New(config.InputSize, config.QuerySize),
 		Key:    linear.New(config.InputSize, config.KeySize),
 		Value:  linear.New(config.InputSize, config.ValueSize),
 	}
+	if config.Positional.Scheme == attention.RelativePositionBias {
+		m.RelativeBias = nn.NewParam(mat.NewEmptyVecDense(config.Positional.NumBuckets))
+	}
+	return m
 }
 
 // Forward performs the forward step for each input node and returns the result.
 // It generates the queries, keys and values from the same input xs.
 func (m *Model) Forward(qkv attention.QKV) attention.Output {
 	projAtt := attention.QKV{
-		Queries: m.Query.Forward(qkv.Queries...),
-		Keys:    m.Key.Forward(qkv.Keys...),
+		Queries: m.rotate(m.Query.Forward(qkv.Queries...), 0),
+		Keys:    m.rotate(m.Key.Forward(qkv.Keys...), 0),
 		Values:  m.Value.Forward(qkv.Values...),
 	}
-	attOutput, attWeights := attention.ScaledDotProductAttention(m.Graph(), projAtt, m.ScaleFactor, m.UseCausalMask)
+	attOutput, attWeights := m.attend(projAtt, 0)
 
 	return attention.Output{
 		AttOutput:  attOutput,
@@ -73,18 +86,19 @@ func (m *Model) Forward(qkv attention.QKV) attention.Output {
 // ForwardWithPastKeysValues performs the forward step for each input node and returns the result.
 // It generates the queries, keys and values from the same input xs.
 func (m *Model) ForwardWithPastKeysValues(qkv attention.QKV, past attention.KeysValuesPair) attention.Output {
+	offset := len(past.Keys)
 	projAtt := attention.QKV{
-		Queries: m.Query.Forward(qkv.Queries...),
+		Queries: m.rotate(m.Query.Forward(qkv.Queries...), offset),
 		Keys:    append([]ag.Node{}, past.Keys...),   // this append is important
 		Values:  append([]ag.Node{}, past.Values...), // this append is important
 	}
 
 	if qkv.Keys != nil { // the qkv.Values shall not be null as well
-		projAtt.Keys = append(projAtt.Keys, m.Key.Forward(qkv.Keys...)...)
+		projAtt.Keys = append(projAtt.Keys, m.rotate(m.Key.Forward(qkv.Keys...), offset)...)
 		projAtt.Values = append(projAtt.Values, m.Value.Forward(qkv.Values...)...)
 	}
 
-	attOutput, attWeights := attention.ScaledDotProductAttention(m.Graph(), projAtt, m.ScaleFactor, m.UseCausalMask)
+	attOutput, attWeights := m.attend(projAtt, offset)
 
 	return attention.Output{
 		AttOutput:  attOutput,
@@ -95,3 +109,35 @@ func (m *Model) ForwardWithPastKeysValues(qkv attention.QKV, past attention.Keys
 		},
 	}
 }
+
+// attend performs the attention of the projected queries, keys and values,
+// the queries starting at the given position.
+func (m *Model) attend(projAtt attention.QKV, offset int) ([]ag.Node, []mat.Matrix) {
+	if m.SlidingWindow.Window > 0 {
+		return attention.SlidingWindowAttention(m.Graph(), projAtt, m.ScaleFactor, m.SlidingWindow, m.UseCausalMask, offset, m.positionBias())
+	}
+	return attention.ScaledDotProductAttentionWithBias(m.Graph(), projAtt, m.ScaleFactor, m.UseCausalMask, offset, m.positionBias())
+}
+
+// rotate applies the rotary embeddings to the projected queries or keys, if
+// enabled. The returned keys are the ones kept as past keys, so they are
+// never rotated twice.
+func (m *Model) rotate(xs []ag.Node, offset int) []ag.Node {
+	if m.Positional.Scheme != attention.Rotary {
+		return xs
+	}
+	return attention.ApplyRotary(m.Graph(), m.Positional, xs, offset)
+}
+
+// positionBias returns the bias of the attention scores of the positional
+// scheme, or nil.
+func (m *Model) positionBias() attention.PositionBias {
+	switch m.Positional.Scheme {
+	case attention.RelativePositionBias:
+		return attention.RelativePositionBiasFunc(m.Positional, m.RelativeBias)
+	case attention.ALiBi:
+		return attention.ALiBiFunc(m.Positional)
+	default:
+		return nil
+	}
+}
@@ -92,3 +92,78 @@ func newTestModel() *Model {
 	model.Query.B.Value().SetData([]mat.Float{0.3, 0.5, -0.7})
 	return model
 }
+
+func TestModel_PositionalSchemes(t *testing.T) {
+	for _, positional := range []attention.PositionalConfig{
+		{Scheme: attention.Rotary, RotaryDim: 2},
+		{Scheme: attention.ALiBi, ALiBiSlope: 0.5},
+		{Scheme: attention.RelativePositionBias, NumBuckets: 8, MaxDistance: 16},
+	} {
+		model := newTestModel()
+		model.Positional = positional
+		model.UseCausalMask = true
+		if positional.Scheme == attention.RelativePositionBias {
+			model.RelativeBias = nn.NewParam(mat.NewVecDense([]mat.Float{0.1, -0.2, 0.3, -0.4, 0.5, 0, 0, 0}))
+		}
+
+		g := ag.NewGraph()
+		proc := nn.ReifyForTraining(model, g).(*Model)
+		xs := []ag.Node{
+			g.NewVariable(mat.NewVecDense([]mat.Float{-0.8, -0.9, -0.9, 1.0}), false),
+			g.NewVariable(mat.NewVecDense([]mat.Float{0.8, -0.3, 0.5, 0.3}), false),
+			g.NewVariable(mat.NewVecDense([]mat.Float{-0.2, 0.7, 0.2, 0.4}), false),
+		}
+		full := proc.Forward(attention.ToQKV(xs))
+
+		// the incremental forward with the past keys and values must give the same outputs
+		out := proc.Forward(attention.ToQKV(xs[:1]))
+		assert.InDeltaSlice(t, full.AttOutput[0].Value().Data(), out.AttOutput[0].Value().Data(), 1.0e-6)
+		for i := 1; i < len(xs); i++ {
+			out = proc.ForwardWithPastKeysValues(attention.ToQKV(xs[i:i+1]), out.ProjKeysValues)
+			assert.InDeltaSlice(t, full.AttOutput[i].Value().Data(), out.AttOutput[0].Value().Data(), 1.0e-6)
+		}
+
+		plain := nn.ReifyForTraining(newTestModel(), g).(*Model)
+		plain.UseCausalMask = true
+		assert.NotEqual(t, plain.Forward(attention.ToQKV(xs)).AttWeights[2].Data(), full.AttWeights[2].Data())
+
+		if positional.Scheme == attention.RelativePositionBias {
+			g.Backward(g.ReduceSum(g.Concat(full.AttOutput...)))
+			assert.NotNil(t, model.RelativeBias.Grad())
+		}
+	}
+}
+
+func TestNew_RelativePositionBias(t *testing.T) {
+	model := New(Config{
+		InputSize:  4,
+		QuerySize:  2,
+		KeySize:    2,
+		ValueSize:  2,
+		Positional: attention.PositionalConfig{Scheme: attention.RelativePositionBias, NumBuckets: 32, MaxDistance: 128},
+	})
+	assert.Equal(t, 32, model.RelativeBias.Value().Size())
+	assert.Nil(t, New(Config{InputSize: 4, QuerySize: 2, KeySize: 2, ValueSize: 2}).RelativeBias)
+}
+
+func TestModel_SlidingWindow(t *testing.T) {
+	g := ag.NewGraph()
+	xs := []ag.Node{
+		g.NewVariable(mat.NewVecDense([]mat.Float{-0.8, -0.9, -0.9, 1.0}), false),
+		g.NewVariable(mat.NewVecDense([]mat.Float{0.8, -0.3, 0.5, 0.3}), false),
+		g.NewVariable(mat.NewVecDense([]mat.Float{-0.2, 0.7, 0.2, 0.4}), false),
+	}
+	expected := nn.ReifyForInference(newTestModel(), g).(*Model).Forward(attention.ToQKV(xs))
+
+	model := newTestModel()
+	model.SlidingWindow = attention.SlidingWindowConfig{Window: 2}
+	actual := nn.ReifyForInference(model, g).(*Model).Forward(attention.ToQKV(xs))
+	for i := range xs {
+		assert.InDeltaSlice(t, expected.AttOutput[i].Value().Data(), actual.AttOutput[i].Value().Data(), 1.0e-6)
+	}
+
+	model.SlidingWindow = attention.SlidingWindowConfig{Window: 1}
+	actual = nn.ReifyForInference(model, g).(*Model).Forward(attention.ToQKV(xs))
+	assert.Len(t, actual.AttWeights[0].Data(), 2)
+	assert.Len(t, actual.AttWeights[1].Data(), 3)
+}
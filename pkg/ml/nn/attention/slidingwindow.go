@@ -0,0 +1,99 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package attention
+
+import (
+	"sort"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+)
+
+// SlidingWindowConfig provides the configuration of the sliding window
+// attention of Longformer, where each query attends only to the keys within
+// a window around it, plus the global tokens: its memory is proportional to
+// the sequence length times the window size, instead of the square of the
+// sequence length.
+//
+// Reference: "Longformer: The Long-Document Transformer" by Beltagy et al.
+// (2020) (https://arxiv.org/abs/2004.05150)
+type SlidingWindowConfig struct {
+	// Window is the number of keys attended on each side of a query (only on
+	// the left with the causal mask). If zero, the attention is not local.
+	Window int
+	// GlobalTokens are the positions of the tokens attending to all the keys
+	// and attended by all the queries (e.g. [CLS], or the question in QA).
+	GlobalTokens []int
+}
+
+// WindowKeys returns the positions of the keys attended by the query at the
+// given position, in ascending order. The attention weights computed by
+// SlidingWindowAttention refer to these keys.
+func WindowKeys(config SlidingWindowConfig, queryPos, numOfKeys int, causal bool) []int {
+	last := numOfKeys - 1
+	if causal && queryPos < last {
+		last = queryPos
+	}
+	for _, p := range config.GlobalTokens {
+		if p == queryPos {
+			return positions(0, last+1)
+		}
+	}
+	first := queryPos - config.Window
+	if first < 0 {
+		first = 0
+	}
+	if !causal && queryPos+config.Window < last {
+		last = queryPos + config.Window
+	}
+	keys := positions(first, last+1)
+	extra := false
+	for _, p := range config.GlobalTokens {
+		if p >= 0 && p < numOfKeys && (p < first || p > last) && (!causal || p <= queryPos) {
+			keys = append(keys, p)
+			extra = true
+		}
+	}
+	if extra {
+		sort.Ints(keys)
+		keys = dedupSorted(keys)
+	}
+	return keys
+}
+
+// SlidingWindowAttention is like ScaledDotProductAttentionWithBias, but each
+// query attends only to the keys returned by WindowKeys. The weights of each
+// query refer to those keys.
+func SlidingWindowAttention(g *ag.Graph, qkv QKV, scaleFactor mat.Float, config SlidingWindowConfig, causal bool, offset int, bias PositionBias) (context []ag.Node, prob []mat.Matrix) {
+	context = make([]ag.Node, len(qkv.Queries))
+	prob = make([]mat.Matrix, len(qkv.Queries))
+	keys := g.Stack(qkv.Keys...)
+	values := g.Stack(qkv.Values...)
+	factor := g.NewScalar(scaleFactor)
+
+	for i, q := range qkv.Queries {
+		queryPos := offset + i
+		window := WindowKeys(config, queryPos, len(qkv.Keys), causal)
+		windowValues := g.IndexSelect(values, window)
+		attScores := g.ProdScalar(g.Mul(g.IndexSelect(keys, window), q), factor)
+		if bias != nil {
+			attScores = g.Add(attScores, bias(g, queryPos, window))
+		}
+		attProb := g.Softmax(attScores)
+		context[i] = g.TMatMul(windowValues, attProb)
+		prob[i] = attProb.Value()
+	}
+	return
+}
+
+func dedupSorted(xs []int) []int {
+	out := xs[:0]
+	for i, x := range xs {
+		if i == 0 || x != xs[i-1] {
+			out = append(out, x)
+		}
+	}
+	return out
+}
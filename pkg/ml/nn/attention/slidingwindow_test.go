@@ -0,0 +1,70 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package attention
+
+import (
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/mat32/rand"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWindowKeys(t *testing.T) {
+	config := SlidingWindowConfig{Window: 1, GlobalTokens: []int{0, 0}}
+	assert.Equal(t, []int{0, 1}, WindowKeys(SlidingWindowConfig{Window: 1}, 0, 6, false))
+	assert.Equal(t, []int{0, 2, 3, 4}, WindowKeys(config, 3, 6, false))
+	assert.Equal(t, []int{0, 4, 5}, WindowKeys(config, 5, 6, false))
+	assert.Equal(t, []int{0, 2, 3}, WindowKeys(config, 3, 6, true))
+	// the global tokens attend to all the keys
+	assert.Equal(t, []int{0, 1, 2, 3, 4, 5}, WindowKeys(config, 0, 6, false))
+	assert.Equal(t, []int{0, 1, 2}, WindowKeys(SlidingWindowConfig{Window: 1, GlobalTokens: []int{2}}, 2, 6, true))
+	// a future global token is not attended with the causal mask
+	assert.Equal(t, []int{0, 1}, WindowKeys(SlidingWindowConfig{Window: 1, GlobalTokens: []int{4}}, 1, 6, true))
+}
+
+func TestSlidingWindowAttention(t *testing.T) {
+	g := ag.NewGraph()
+	rndGen := rand.NewLockedRand(42)
+	newVectors := func(n int) []ag.Node {
+		xs := make([]ag.Node, n)
+		for i := range xs {
+			v := mat.NewEmptyVecDense(3)
+			for j := 0; j < 3; j++ {
+				v.SetVec(j, rndGen.Float()*2-1)
+			}
+			xs[i] = g.NewVariable(v, false)
+		}
+		return xs
+	}
+	qkv := QKV{Queries: newVectors(6), Keys: newVectors(6), Values: newVectors(6)}
+	config := SlidingWindowConfig{Window: 1, GlobalTokens: []int{0}}
+	bias := ALiBiFunc(PositionalConfig{ALiBiSlope: 0.5, Bidirectional: true})
+
+	context, probs := SlidingWindowAttention(g, qkv, 0.5, config, false, 0, bias)
+	for i := range qkv.Queries {
+		// the same as the global attention on the keys of the window
+		window := WindowKeys(config, i, 6, false)
+		sub := QKV{Queries: qkv.Queries[i : i+1]}
+		for _, j := range window {
+			sub.Keys = append(sub.Keys, qkv.Keys[j])
+			sub.Values = append(sub.Values, qkv.Values[j])
+		}
+		windowBias := func(g *ag.Graph, queryPos int, _ []int) ag.Node {
+			return bias(g, queryPos, window)
+		}
+		expected, expectedProbs := ScaledDotProductAttentionWithBias(g, sub, 0.5, false, i, windowBias)
+		assert.InDeltaSlice(t, expected[0].Value().Data(), context[i].Value().Data(), 1.0e-6)
+		assert.InDeltaSlice(t, expectedProbs[0].Data(), probs[i].Data(), 1.0e-6)
+	}
+
+	// a window covering the whole sequence is the global attention
+	context, _ = SlidingWindowAttention(g, qkv, 0.5, SlidingWindowConfig{Window: 6}, true, 0, nil)
+	expected, _ := ScaledDotProductAttention(g, qkv, 0.5, true)
+	for i := range expected {
+		assert.InDeltaSlice(t, expected[i].Value().Data(), context[i].Value().Data(), 1.0e-6)
+	}
+}
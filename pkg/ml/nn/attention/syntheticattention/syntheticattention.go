@@ -73,10 +73,10 @@ func (m *Model) Forward(xs ...ag.Node) []ag.Node {
 	values := g.Stack(m.Value.Forward(xs...)...)
 	rectified := g.Stack(m.FFN.Forward(xs...)...)
 	attentionWeights := m.extractAttentionWeights(length)
-	mul := g.Mul(attentionWeights, g.T(rectified))
+	mul := g.MatMulT(attentionWeights, rectified)
 	for i := 0; i < length; i++ {
 		attProb := g.Softmax(g.ColView(mul, i))
-		context[i] = g.Mul(g.T(attProb), values)
+		context[i] = g.TMatMul(attProb, values)
 		prob[i] = attProb.Value()
 	}
 	m.Attention = &ContextProb{
@@ -16,6 +16,9 @@ type BaseModel struct {
 	G *ag.Graph
 	// ProcessingMode is the processing mode for the model (training or inference).
 	ProcessingMode ProcessingMode
+	// hooks are the hooks registered on the model or on its ancestors (see
+	// RegisterForwardHook and RegisterBackwardHook).
+	hooks []*moduleHook
 }
 
 func init() {
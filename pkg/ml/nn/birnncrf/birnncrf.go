@@ -56,7 +56,6 @@ func (m *Model) Predict(xs []ag.Node) []int {
 }
 
 // NegativeLogLoss computes the negative log loss with respect to the targets.
-// TODO: the CRF backward tests are still missing
 func (m *Model) NegativeLogLoss(emissionScores []ag.Node, targets []int) ag.Node {
 	return m.CRF.NegativeLogLoss(emissionScores, targets)
 }
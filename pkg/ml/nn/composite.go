@@ -0,0 +1,166 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nn
+
+import (
+	"encoding/gob"
+	"fmt"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+)
+
+var (
+	_ StandardModel = &SequentialModel{}
+	_ StandardModel = &ResidualModel{}
+	_ StandardModel = &ParallelModel{}
+)
+
+func init() {
+	gob.Register(&SequentialModel{})
+	gob.Register(&ResidualModel{})
+	gob.Register(&ParallelModel{})
+}
+
+// SequentialModel is a composite model whose processor chains the forward of
+// its layers: the outputs of each layer are the inputs of the next one.
+type SequentialModel struct {
+	BaseModel
+	Layers []StandardModel
+}
+
+// Sequential returns a new SequentialModel of the given layers, so that a
+// feed-forward stack can be assembled declaratively:
+//
+//     m := nn.Sequential(
+//         linear.New(in, hidden),
+//         activation.New(ag.OpReLU),
+//         nn.Residual(linear.New(hidden, hidden)),
+//         linear.New(hidden, out),
+//     )
+func Sequential(layers ...StandardModel) *SequentialModel {
+	if len(layers) == 0 {
+		panic("nn: Sequential requires at least one layer")
+	}
+	return &SequentialModel{Layers: layers}
+}
+
+// Forward performs the forward of the layers in order, calling their hooks
+// (see ForwardWithHooks).
+func (m *SequentialModel) Forward(xs ...ag.Node) []ag.Node {
+	for _, layer := range m.Layers {
+		xs = ForwardWithHooks(layer, xs...)
+	}
+	return xs
+}
+
+// ResidualModel is a composite model whose processor adds the inputs to the
+// outputs of the inner model (a.k.a. skip connection).
+type ResidualModel struct {
+	BaseModel
+	Inner StandardModel
+}
+
+// Residual returns a new ResidualModel around the inner model, whose outputs
+// must have the same shape as its inputs.
+func Residual(inner StandardModel) *ResidualModel {
+	return &ResidualModel{Inner: inner}
+}
+
+// Forward returns the sum of each input with the corresponding output of
+// the inner model.
+func (m *ResidualModel) Forward(xs ...ag.Node) []ag.Node {
+	ys := ForwardWithHooks(m.Inner, xs...)
+	if len(ys) != len(xs) {
+		panic(fmt.Sprintf("nn: the residual model got %d outputs for %d inputs", len(ys), len(xs)))
+	}
+	g := m.Graph()
+	out := make([]ag.Node, len(ys))
+	for i, y := range ys {
+		out[i] = g.Add(xs[i], y)
+	}
+	return out
+}
+
+// JoinType is the enumeration-like type used for the set of methods joining
+// the outputs of the models of a ParallelModel.
+type JoinType int
+
+const (
+	// JoinConcat concatenates the outputs.
+	JoinConcat JoinType = iota
+	// JoinSum adds the outputs together.
+	JoinSum
+	// JoinProd multiplies the outputs element-wise together.
+	JoinProd
+	// JoinAvg takes the average of the outputs.
+	JoinAvg
+)
+
+// ParallelModel is a composite model whose processor performs the forward
+// of its models on the same inputs, joining their outputs position-wise.
+type ParallelModel struct {
+	BaseModel
+	Join   JoinType
+	Models []StandardModel
+}
+
+// Parallel returns a new ParallelModel of the given models, whose outputs
+// are joined with the given method.
+func Parallel(join JoinType, models ...StandardModel) *ParallelModel {
+	if len(models) == 0 {
+		panic("nn: Parallel requires at least one model")
+	}
+	return &ParallelModel{Join: join, Models: models}
+}
+
+// Forward performs the forward of each model on the inputs, and returns
+// their joined outputs. The models must return the same number of outputs.
+func (m *ParallelModel) Forward(xs ...ag.Node) []ag.Node {
+	outputs := make([][]ag.Node, len(m.Models))
+	for i, model := range m.Models {
+		outputs[i] = ForwardWithHooks(model, xs...)
+		if len(outputs[i]) != len(outputs[0]) {
+			panic(fmt.Sprintf("nn: the parallel models got %d and %d outputs", len(outputs[0]), len(outputs[i])))
+		}
+	}
+	out := make([]ag.Node, len(outputs[0]))
+	for j := range out {
+		column := make([]ag.Node, len(outputs))
+		for i := range outputs {
+			column[i] = outputs[i][j]
+		}
+		out[j] = m.join(column)
+	}
+	return out
+}
+
+func (m *ParallelModel) join(xs []ag.Node) ag.Node {
+	g := m.Graph()
+	switch m.Join {
+	case JoinConcat:
+		return g.Concat(xs...)
+	case JoinSum:
+		return sumNodes(g, xs)
+	case JoinProd:
+		y := xs[0]
+		for _, x := range xs[1:] {
+			y = g.Prod(y, x)
+		}
+		return y
+	case JoinAvg:
+		return g.DivScalar(sumNodes(g, xs), g.NewScalar(mat.Float(len(xs))))
+	default:
+		panic("nn: invalid join type")
+	}
+}
+
+func sumNodes(g *ag.Graph, xs []ag.Node) ag.Node {
+	y := xs[0]
+	for _, x := range xs[1:] {
+		y = g.Add(y, x)
+	}
+	return y
+}
@@ -0,0 +1,71 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nn
+
+import (
+	"bytes"
+	"encoding/gob"
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	gob.Register(&scaleLayer{})
+}
+
+func newScaleLayer(f mat.Float) *scaleLayer {
+	return &scaleLayer{W: NewParam(mat.NewScalar(f))}
+}
+
+func TestSequential(t *testing.T) {
+	m := Sequential(newScaleLayer(2), Residual(newScaleLayer(3)), newScaleLayer(0.5))
+	g := ag.NewGraph()
+	proc := ReifyForInference(m, g).(*SequentialModel)
+	ys := proc.Forward(g.NewScalar(1), g.NewScalar(2))
+	// ((x * 2) + (x * 2 * 3)) * 0.5
+	assert.Equal(t, mat.Float(4), ys[0].ScalarValue())
+	assert.Equal(t, mat.Float(8), ys[1].ScalarValue())
+
+	var names []string
+	ForEachNamedParam(m, func(name string, _ Param) {
+		names = append(names, name)
+	})
+	assert.Equal(t, []string{"Layers.0.W", "Layers.1.Inner.W", "Layers.2.W"}, names)
+
+	assert.Panics(t, func() { Sequential() })
+}
+
+func TestParallel(t *testing.T) {
+	for join, expected := range map[JoinType][]mat.Float{
+		JoinConcat: {2, 3},
+		JoinSum:    {5},
+		JoinProd:   {6},
+		JoinAvg:    {2.5},
+	} {
+		m := Parallel(join, newScaleLayer(2), newScaleLayer(3))
+		g := ag.NewGraph()
+		proc := ReifyForInference(m, g).(*ParallelModel)
+		ys := proc.Forward(g.NewScalar(1))
+		require.Len(t, ys, 1)
+		assert.Equal(t, expected, ys[0].Value().Data())
+	}
+	assert.Panics(t, func() { Parallel(JoinSum) })
+}
+
+func TestComposite_Serialization(t *testing.T) {
+	m := Sequential(Parallel(JoinSum, newScaleLayer(2), newScaleLayer(3)), Residual(newScaleLayer(4)))
+	var buf bytes.Buffer
+	require.NoError(t, gob.NewEncoder(&buf).Encode(m))
+	var decoded *SequentialModel
+	require.NoError(t, gob.NewDecoder(&buf).Decode(&decoded))
+
+	g := ag.NewGraph()
+	proc := ReifyForInference(decoded, g).(*SequentialModel)
+	assert.Equal(t, mat.Float(25), proc.Forward(g.NewScalar(1))[0].ScalarValue())
+}
@@ -0,0 +1,90 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package conv1d
+
+import (
+	"encoding/gob"
+	"fmt"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/ml/nn/conv1x1"
+)
+
+var (
+	_ nn.Model = &DepthwiseModel{}
+)
+
+// DepthwiseConfig provides configuration parameters for DepthwiseModel.
+type DepthwiseConfig struct {
+	Channels   int
+	KernelSize int
+	// Dilation is the distance between the positions covered by the kernel.
+	// The values less than 1 are equivalent to 1.
+	Dilation int
+	// Causal enables the causal padding.
+	Causal bool
+}
+
+// DepthwiseModel is a depthwise 1-dimensional convolution model, which
+// convolves each channel with its own kernel, with stride 1.
+type DepthwiseModel struct {
+	nn.BaseModel
+	Config DepthwiseConfig
+	// W contains the kernel of each channel in a row.
+	W nn.Param `spago:"type:weights"`
+	B nn.Param `spago:"type:biases"`
+}
+
+func init() {
+	gob.Register(&DepthwiseModel{})
+}
+
+// NewDepthwise returns a new DepthwiseModel initialized to zeros.
+func NewDepthwise(config DepthwiseConfig) *DepthwiseModel {
+	if config.KernelSize < 1 {
+		panic(fmt.Sprintf("conv1d: invalid kernel size %d", config.KernelSize))
+	}
+	return &DepthwiseModel{
+		Config: config,
+		W:      nn.NewParam(mat.NewEmptyDense(config.Channels, config.KernelSize)),
+		B:      nn.NewParam(mat.NewEmptyVecDense(config.Channels)),
+	}
+}
+
+// NewSeparable returns a new depthwise separable convolution model, i.e. a
+// DepthwiseModel followed by a pointwise (1x1) convolution mixing the
+// channels, which needs far fewer parameters and operations than a Model
+// with the same kernel size.
+func NewSeparable(config DepthwiseConfig, outputChannels int) *nn.SequentialModel {
+	return nn.Sequential(
+		NewDepthwise(config),
+		conv1x1.New(conv1x1.Config{
+			InputChannels:  config.Channels,
+			OutputChannels: outputChannels,
+		}),
+	)
+}
+
+// Forward performs the forward step. Each "x" is a channel.
+func (m *DepthwiseModel) Forward(xs ...ag.Node) []ag.Node {
+	if len(xs) != m.Config.Channels {
+		panic(fmt.Sprintf("conv1d: expected %d channels, found %d", m.Config.Channels, len(xs)))
+	}
+	g := m.Graph()
+	length := xs[0].Value().Size()
+	k := m.Config.KernelSize
+	indices := kernelIndices(length, k, m.Config.Dilation, m.Config.Causal)
+
+	ys := make([]ag.Node, len(xs))
+	for ch, x := range xs {
+		padded := g.ScatterAdd(g.NewVariable(mat.NewEmptyVecDense(length+1), false), x, rangeIndices(length))
+		unfolded := g.Reshape(g.Gather(padded, indices), length, k)
+		y := g.Mul(unfolded, g.T(g.RowView(m.W, ch)))
+		ys[ch] = g.AddScalar(y, g.AtVec(m.B, ch))
+	}
+	return ys
+}
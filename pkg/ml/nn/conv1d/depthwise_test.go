@@ -0,0 +1,59 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package conv1d
+
+import (
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/ml/nn/conv1x1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDepthwiseModel_Forward(t *testing.T) {
+	model := NewDepthwise(DepthwiseConfig{Channels: 2, KernelSize: 3, Causal: true})
+	model.W.Value().SetData([]mat.Float{
+		1, 0, 2,
+		0, -1, 1,
+	})
+	model.B.Value().SetData([]mat.Float{0.5, 0})
+
+	g := ag.NewGraph()
+	proc := nn.ReifyForTraining(model, g).(*DepthwiseModel)
+	x0 := g.NewVariable(mat.NewVecDense([]mat.Float{1, 2, 3, 4}), true)
+	x1 := g.NewVariable(mat.NewVecDense([]mat.Float{4, 3, 2, 1}), true)
+	ys := proc.Forward(x0, x1)
+	require.Len(t, ys, 2)
+	// y0[t] = x0[t-2] + 2 x0[t] + 0.5
+	assert.InDeltaSlice(t, []mat.Float{2.5, 4.5, 7.5, 10.5}, ys[0].Value().Data(), 1.0e-6)
+	// y1[t] = -x1[t-1] + x1[t]
+	assert.InDeltaSlice(t, []mat.Float{4, -1, -1, -1}, ys[1].Value().Data(), 1.0e-6)
+
+	g.Backward(g.Add(g.ReduceSum(ys[0]), g.ReduceSum(ys[1])))
+	assert.InDeltaSlice(t, []mat.Float{3, 3, 2, 2}, x0.Grad().Data(), 1.0e-6)
+	assert.InDeltaSlice(t, []mat.Float{0, 0, 0, 1}, x1.Grad().Data(), 1.0e-6)
+	assert.InDeltaSlice(t, []mat.Float{3, 6, 10, 7, 9, 10}, model.W.Grad().Data(), 1.0e-6)
+}
+
+func TestNewSeparable(t *testing.T) {
+	model := NewSeparable(DepthwiseConfig{Channels: 2, KernelSize: 2, Causal: true}, 3)
+	require.Len(t, model.Layers, 2)
+	model.Layers[0].(*DepthwiseModel).W.Value().SetData([]mat.Float{1, 1, 0, 2})
+	model.Layers[1].(*conv1x1.Model).W.Value().SetData([]mat.Float{1, 0, 0, 1, 1, 1})
+
+	g := ag.NewGraph()
+	proc := nn.ReifyForInference(model, g).(*nn.SequentialModel)
+	ys := proc.Forward(
+		g.NewVariable(mat.NewVecDense([]mat.Float{1, 2}), false),
+		g.NewVariable(mat.NewVecDense([]mat.Float{3, 4}), false),
+	)
+	require.Len(t, ys, 3)
+	assert.InDeltaSlice(t, []mat.Float{1, 3}, ys[0].Value().Data(), 1.0e-6)
+	assert.InDeltaSlice(t, []mat.Float{6, 8}, ys[1].Value().Data(), 1.0e-6)
+	assert.InDeltaSlice(t, []mat.Float{7, 11}, ys[2].Value().Data(), 1.0e-6)
+}
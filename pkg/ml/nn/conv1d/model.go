@@ -0,0 +1,131 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package conv1d implements the 1-dimensional convolution models used by the
+// convolutional sequence encoders (à la WaveNet and ByteNet): the dilated
+// convolution, whose receptive field grows exponentially with the depth when
+// the dilation is doubled at each layer, and the depthwise (separable)
+// convolution.
+//
+// As in conv1x1, the input and output nodes are the channels, each a vector
+// with an element for each position of the sequence. The outputs have the
+// same length of the inputs: the sequence is padded with zeros on both sides,
+// or only on the left with the causal padding, so that each output depends
+// only on the current and previous positions.
+//
+// Reference: "WaveNet: A Generative Model for Raw Audio" by Aaron van den Oord
+// et al. (2016) (https://arxiv.org/abs/1609.03499)
+package conv1d
+
+import (
+	"encoding/gob"
+	"fmt"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+)
+
+var (
+	_ nn.Model = &Model{}
+)
+
+// Config provides configuration parameters for Model.
+type Config struct {
+	InputChannels  int
+	OutputChannels int
+	KernelSize     int
+	// Dilation is the distance between the positions covered by the kernel.
+	// The values less than 1 are equivalent to 1 (a standard convolution).
+	Dilation int
+	// Causal enables the causal padding.
+	Causal bool
+}
+
+// Model is a dilated 1-dimensional convolution model, with stride 1.
+type Model struct {
+	nn.BaseModel
+	Config Config
+	// W contains the kernels of each output channel in a row, with the
+	// weights of the input channels for each position of the kernel.
+	W nn.Param `spago:"type:weights"`
+	B nn.Param `spago:"type:biases"`
+}
+
+func init() {
+	gob.Register(&Model{})
+}
+
+// New returns a new Model initialized to zeros.
+func New(config Config) *Model {
+	if config.KernelSize < 1 {
+		panic(fmt.Sprintf("conv1d: invalid kernel size %d", config.KernelSize))
+	}
+	return &Model{
+		Config: config,
+		W:      nn.NewParam(mat.NewEmptyDense(config.OutputChannels, config.KernelSize*config.InputChannels)),
+		B:      nn.NewParam(mat.NewEmptyVecDense(config.OutputChannels)),
+	}
+}
+
+// Forward performs the forward step. Each "x" is a channel.
+func (m *Model) Forward(xs ...ag.Node) []ag.Node {
+	if len(xs) != m.Config.InputChannels {
+		panic(fmt.Sprintf("conv1d: expected %d input channels, found %d", m.Config.InputChannels, len(xs)))
+	}
+	g := m.Graph()
+	length := xs[0].Value().Size()
+	k := m.Config.KernelSize
+
+	// The rows of the padded input are the positions, plus a last row of
+	// zeros for the padding.
+	padded := g.ScatterAdd(
+		g.NewVariable(mat.NewEmptyDense(length+1, len(xs)), false),
+		g.T(g.Stack(xs...)),
+		rangeIndices(length),
+	)
+	// Each row of the unfolded input contains the input channels at the
+	// positions covered by the kernel.
+	indices := kernelIndices(length, k, m.Config.Dilation, m.Config.Causal)
+	unfolded := g.Reshape(g.IndexSelect(padded, indices), length, k*len(xs))
+	mm := g.MatMulT(unfolded, m.W)
+
+	ys := make([]ag.Node, m.Config.OutputChannels)
+	for outCh := range ys {
+		ys[outCh] = g.AddScalar(g.ColView(mm, outCh), g.AtVec(m.B, outCh))
+	}
+	return ys
+}
+
+// kernelIndices returns, for each position of a sequence of the given
+// length, the positions covered by the kernel, or length when they fall in
+// the padding.
+func kernelIndices(length, kernelSize, dilation int, causal bool) []int {
+	if dilation < 1 {
+		dilation = 1
+	}
+	left := (kernelSize - 1) * dilation
+	if !causal {
+		left /= 2
+	}
+	indices := make([]int, 0, length*kernelSize)
+	for t := 0; t < length; t++ {
+		for j := 0; j < kernelSize; j++ {
+			i := t - left + j*dilation
+			if i < 0 || i >= length {
+				i = length
+			}
+			indices = append(indices, i)
+		}
+	}
+	return indices
+}
+
+func rangeIndices(n int) []int {
+	indices := make([]int, n)
+	for i := range indices {
+		indices[i] = i
+	}
+	return indices
+}
@@ -0,0 +1,86 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package conv1d
+
+import (
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModel_Forward(t *testing.T) {
+	newModel := func(dilation int, causal bool) *Model {
+		model := New(Config{
+			InputChannels:  2,
+			OutputChannels: 2,
+			KernelSize:     2,
+			Dilation:       dilation,
+			Causal:         causal,
+		})
+		// the weights of the two positions of the kernel, for each input channel
+		model.W.Value().SetData([]mat.Float{
+			1, 2, 3, 4,
+			0, 1, 0, -1,
+		})
+		model.B.Value().SetData([]mat.Float{0.1, 0.2})
+		return model
+	}
+	forward := func(model *Model) []ag.Node {
+		g := ag.NewGraph()
+		proc := nn.ReifyForInference(model, g).(*Model)
+		return proc.Forward(
+			g.NewVariable(mat.NewVecDense([]mat.Float{1, 2, 3, 4}), false),
+			g.NewVariable(mat.NewVecDense([]mat.Float{-1, 0, 1, 2}), false),
+		)
+	}
+
+	t.Run("causal", func(t *testing.T) {
+		ys := forward(newModel(1, true))
+		require.Len(t, ys, 2)
+		// y0[t] = x0[t-1] + 2 x1[t-1] + 3 x0[t] + 4 x1[t] + 0.1
+		assert.InDeltaSlice(t, []mat.Float{-0.9, 5.1, 15.1, 25.1}, ys[0].Value().Data(), 1.0e-6)
+		// y1[t] = x1[t-1] - x1[t] + 0.2
+		assert.InDeltaSlice(t, []mat.Float{1.2, -0.8, -0.8, -0.8}, ys[1].Value().Data(), 1.0e-6)
+	})
+
+	t.Run("causal dilated", func(t *testing.T) {
+		ys := forward(newModel(2, true))
+		// y0[t] = x0[t-2] + 2 x1[t-2] + 3 x0[t] + 4 x1[t] + 0.1
+		assert.InDeltaSlice(t, []mat.Float{-0.9, 6.1, 12.1, 22.1}, ys[0].Value().Data(), 1.0e-6)
+	})
+
+	t.Run("not causal", func(t *testing.T) {
+		ys := forward(newModel(2, false))
+		// y0[t] = x0[t-1] + 2 x1[t-1] + 3 x0[t+1] + 4 x1[t+1] + 0.1
+		assert.InDeltaSlice(t, []mat.Float{6.1, 12.1, 22.1, 5.1}, ys[0].Value().Data(), 1.0e-6)
+	})
+}
+
+func TestModel_Backward(t *testing.T) {
+	model := New(Config{InputChannels: 1, OutputChannels: 1, KernelSize: 2, Causal: true})
+	model.W.Value().SetData([]mat.Float{1, 2})
+
+	g := ag.NewGraph()
+	proc := nn.ReifyForTraining(model, g).(*Model)
+	x := g.NewVariable(mat.NewVecDense([]mat.Float{1, 2, 3}), true)
+	y := proc.Forward(x)[0]
+	g.Backward(g.ReduceSum(y))
+
+	// y[t] = x[t-1] + 2 x[t] + b
+	assert.InDeltaSlice(t, []mat.Float{3, 6}, model.W.Grad().Data(), 1.0e-6)
+	assert.InDeltaSlice(t, []mat.Float{3}, model.B.Grad().Data(), 1.0e-6)
+	assert.InDeltaSlice(t, []mat.Float{3, 3, 2}, x.Grad().Data(), 1.0e-6)
+}
+
+func TestKernelIndices(t *testing.T) {
+	assert.Equal(t, []int{3, 0, 0, 1, 1, 2}, kernelIndices(3, 2, 1, true))
+	assert.Equal(t, []int{3, 0, 3, 1, 0, 2}, kernelIndices(3, 2, 2, true))
+	assert.Equal(t, []int{3, 0, 1, 0, 1, 2, 1, 2, 3}, kernelIndices(3, 3, 0, false))
+	assert.Panics(t, func() { New(Config{InputChannels: 1, OutputChannels: 1}) })
+}
@@ -0,0 +1,71 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package conv2d implements a 2-dimensional convolution model with stride,
+// padding, dilation and groups, based on the fn.Conv2D function.
+package conv2d
+
+import (
+	"encoding/gob"
+	"fmt"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/nlpodyssey/spago/pkg/ml/ag/fn"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+)
+
+var (
+	_ nn.Model = &Model{}
+)
+
+// Config provides configuration parameters for Model.
+type Config struct {
+	fn.Conv2DConfig
+	InputChannels  int
+	OutputChannels int
+}
+
+// Model contains the serializable parameters of a 2D convolution.
+// Each input is an image represented as a matrix with one row for each
+// channel, containing its pixels in row-major order (see fn.Conv2DConfig).
+type Model struct {
+	nn.BaseModel
+	Config Config
+	// W contains the kernels of an output channel in each row.
+	W nn.Param `spago:"type:weights"`
+	B nn.Param `spago:"type:biases"`
+}
+
+func init() {
+	gob.Register(&Model{})
+}
+
+// New returns a new Model with parameters initialized to zeros.
+func New(config Config) *Model {
+	groups := config.Groups
+	if groups == 0 {
+		groups = 1
+	}
+	if config.InputChannels%groups != 0 || config.OutputChannels%groups != 0 {
+		panic(fmt.Sprintf("conv2d: the channels (%d, %d) are not divisible by the groups (%d)",
+			config.InputChannels, config.OutputChannels, groups))
+	}
+	kernelSize := config.InputChannels / groups * config.KernelRows * config.KernelColumns
+	return &Model{
+		Config: config,
+		W:      nn.NewParam(mat.NewEmptyDense(config.OutputChannels, kernelSize)),
+		B:      nn.NewParam(mat.NewEmptyVecDense(config.OutputChannels)),
+	}
+}
+
+// Forward performs the forward step for each input image and returns the
+// output images.
+func (m *Model) Forward(xs ...ag.Node) []ag.Node {
+	g := m.Graph()
+	conv := func(x ag.Node) ag.Node {
+		return g.Conv2D(x, m.W, m.B, m.Config.Conv2DConfig)
+	}
+	return ag.Map(conv, xs)
+}
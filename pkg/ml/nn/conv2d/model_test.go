@@ -0,0 +1,88 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package conv2d
+
+import (
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/mat32/rand"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/nlpodyssey/spago/pkg/ml/ag/fn"
+	"github.com/nlpodyssey/spago/pkg/ml/ag/gradcheck"
+	"github.com/nlpodyssey/spago/pkg/ml/initializers"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestModel() *Model {
+	model := New(Config{
+		Conv2DConfig: fn.Conv2DConfig{
+			Height:         4,
+			Width:          5,
+			KernelRows:     3,
+			KernelColumns:  2,
+			StrideRows:     2,
+			PaddingRows:    1,
+			PaddingColumns: 1,
+			Groups:         2,
+		},
+		InputChannels:  2,
+		OutputChannels: 4,
+	})
+	rndGen := rand.NewLockedRand(42)
+	initializers.Uniform(model.W.Value(), -0.5, 0.5, rndGen)
+	initializers.Uniform(model.B.Value(), -0.5, 0.5, rndGen)
+	return model
+}
+
+func newTestImage() mat.Matrix {
+	x := mat.NewEmptyDense(2, 20)
+	initializers.Uniform(x, -1, 1, rand.NewLockedRand(7))
+	return x
+}
+
+func TestNew(t *testing.T) {
+	model := newTestModel()
+	assert.Equal(t, 4, model.W.Value().Rows())
+	assert.Equal(t, 6, model.W.Value().Columns())
+	assert.Equal(t, 4, model.B.Value().Size())
+
+	assert.Panics(t, func() {
+		New(Config{Conv2DConfig: fn.Conv2DConfig{Groups: 2}, InputChannels: 3, OutputChannels: 4})
+	})
+}
+
+func TestModel_Forward(t *testing.T) {
+	model := newTestModel()
+	g := ag.NewGraph()
+	defer g.Clear()
+	proc := nn.ReifyForInference(model, g).(*Model)
+
+	ys := proc.Forward(g.NewVariable(newTestImage(), false), g.NewVariable(newTestImage(), false))
+	require.Len(t, ys, 2)
+	assert.Equal(t, 4, ys[0].Value().Rows())
+	assert.Equal(t, 2*6, ys[0].Value().Columns())
+	assert.Equal(t, ys[0].Value().Data(), ys[1].Value().Data())
+}
+
+func TestModel_Gradients(t *testing.T) {
+	model := newTestModel()
+	x := newTestImage()
+	err := gradcheck.CheckModelGradients(model, func(proc nn.Model) ag.Node {
+		m := proc.(*Model)
+		g := m.Graph()
+		y := m.Forward(g.NewVariable(x, false))[0]
+		return g.AvgPool2D(g.Tanh(y), fn.Pool2DConfig{Height: 2, Width: 6, KernelRows: 2, KernelColumns: 2})
+	}, 1e-3, 1e-2)
+	assert.NoError(t, err)
+
+	err = gradcheck.CheckGradients(func(g *ag.Graph, xs ...ag.Node) ag.Node {
+		y := g.Conv2D(xs[0], xs[1], xs[2], model.Config.Conv2DConfig)
+		return g.MaxPool2D(y, fn.Pool2DConfig{Height: 2, Width: 6, KernelRows: 2, KernelColumns: 3, PaddingColumns: 1})
+	}, []mat.Matrix{x, model.W.Value(), model.B.Value()}, 1e-3, 1e-2)
+	assert.NoError(t, err)
+}
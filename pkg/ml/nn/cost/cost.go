@@ -0,0 +1,317 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package cost estimates the latency and the memory of the forward of a
+// model on the current machine, as a function of the number of tokens of the
+// input, so that a service can do admission control, or choose between a
+// small and a large model for each request.
+//
+// The Estimator is calibrated with micro-benchmarks: the forward is run at a
+// few input sizes, and the measured costs are fitted with polynomials of
+// degree up to two in the number of tokens (the cost of the self-attention
+// is quadratic), whose coefficients are never negative:
+//
+//     small, _ := cost.Calibrate(cost.StandardForward(smallModel, 768), cost.NewDefaultConfig())
+//     large, _ := cost.Calibrate(cost.StandardForward(largeModel, 768), cost.NewDefaultConfig())
+//     budget := cost.Cost{Latency: 50 * time.Millisecond}
+//     switch cost.Select(len(tokens), budget, large, small) {
+//     case 0: // serve with the large model
+//     case 1: // serve with the small model
+//     default: // reject the request
+//     }
+package cost
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+	"unsafe"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/utils/governor"
+)
+
+// Forward builds and runs the forward of a model on the graph, for an input
+// of the given number of tokens.
+type Forward func(g *ag.Graph, tokens int)
+
+// StandardForward returns a Forward reifying the model for inference and
+// running it on a sequence of vectors of the given size, one for each token.
+func StandardForward(m nn.StandardModel, inputSize int) Forward {
+	return func(g *ag.Graph, tokens int) {
+		xs := make([]ag.Node, tokens)
+		for i := range xs {
+			xs[i] = g.NewVariable(mat.NewInitVecDense(inputSize, 0.5), false)
+		}
+		nn.ReifyForInference(m, g).(nn.StandardModel).Forward(xs...)
+	}
+}
+
+// Config provides the configuration of the calibration.
+type Config struct {
+	// Sizes are the numbers of tokens of the benchmarked inputs. At least
+	// three distinct sizes are needed to fit the quadratic costs.
+	Sizes []int
+	// Warmup is the number of runs of each size before the measurements.
+	Warmup int
+	// Repetitions is the number of measured runs of each size, whose median
+	// latency is taken.
+	Repetitions int
+}
+
+// NewDefaultConfig returns a new Config benchmarking inputs from 8 to 256
+// tokens, with a run of warmup and five measured runs each.
+func NewDefaultConfig() Config {
+	return Config{
+		Sizes:       []int{8, 32, 64, 128, 256},
+		Warmup:      1,
+		Repetitions: 5,
+	}
+}
+
+// Cost is the cost of the forward of an input.
+type Cost struct {
+	// Latency is the time taken by the forward.
+	Latency time.Duration
+	// Bytes is the memory held by the values of the nodes of the graph, i.e.
+	// the activations, excluding the parameters of the model, which are
+	// shared by the requests.
+	Bytes int64
+}
+
+// Fits reports whether the cost is within the budget. The zero fields of
+// the budget are unlimited.
+func (c Cost) Fits(budget Cost) bool {
+	return (budget.Latency == 0 || c.Latency <= budget.Latency) &&
+		(budget.Bytes == 0 || c.Bytes <= budget.Bytes)
+}
+
+// Measurement is the cost measured for an input size during the calibration.
+type Measurement struct {
+	Tokens int
+	Cost   Cost
+}
+
+// Estimator estimates the cost of the forward of a model from the number of
+// tokens of the input. It is safe for concurrent use.
+type Estimator struct {
+	// Measurements are the costs measured during the calibration.
+	Measurements []Measurement
+	latency      polynomial
+	bytes        polynomial
+}
+
+// Calibrate runs the forward at each size of the configuration, and returns
+// the Estimator fitting the measured costs. It returns an error if the
+// configuration is invalid.
+//
+// The calibration takes a few times the latency of the largest input, and
+// should be run on the machine serving the model, when it is idle.
+func Calibrate(forward Forward, config Config) (*Estimator, error) {
+	if len(config.Sizes) == 0 {
+		return nil, errors.New("cost: no input sizes to benchmark")
+	}
+	if config.Repetitions < 1 {
+		return nil, errors.New("cost: the repetitions must be greater than zero")
+	}
+	measurements := make([]Measurement, len(config.Sizes))
+	for i, tokens := range config.Sizes {
+		if tokens < 1 {
+			return nil, errors.New("cost: the input sizes must be greater than zero")
+		}
+		for j := 0; j < config.Warmup; j++ {
+			run(forward, tokens)
+		}
+		latencies := make([]time.Duration, config.Repetitions)
+		var bytes int64
+		for j := range latencies {
+			latencies[j], bytes = run(forward, tokens)
+		}
+		sort.Slice(latencies, func(a, b int) bool { return latencies[a] < latencies[b] })
+		measurements[i] = Measurement{
+			Tokens: tokens,
+			Cost:   Cost{Latency: latencies[len(latencies)/2], Bytes: bytes},
+		}
+	}
+	return NewEstimator(measurements), nil
+}
+
+// run runs the forward on a new graph, and returns its latency and the
+// memory held by the values of the nodes, except the parameters.
+func run(forward Forward, tokens int) (time.Duration, int64) {
+	g := ag.NewGraph()
+	defer g.Clear()
+	start := time.Now()
+	forward(g, tokens)
+	g.WaitForward()
+	latency := time.Since(start)
+	var size int
+	for _, n := range g.Nodes() {
+		if _, ok := n.(*ag.Wrapper); ok {
+			continue // the values of the parameters
+		}
+		if v := n.Value(); v != nil {
+			size += v.Size()
+		}
+	}
+	return latency, int64(size) * int64(unsafe.Sizeof(mat.Float(0)))
+}
+
+// NewEstimator returns a new Estimator fitting the given measurements, e.g.
+// the ones of a previous calibration on the same machine.
+func NewEstimator(measurements []Measurement) *Estimator {
+	xs := make([]float64, len(measurements))
+	latencies := make([]float64, len(measurements))
+	bytes := make([]float64, len(measurements))
+	for i, m := range measurements {
+		xs[i] = float64(m.Tokens)
+		latencies[i] = float64(m.Cost.Latency)
+		bytes[i] = float64(m.Cost.Bytes)
+	}
+	return &Estimator{
+		Measurements: measurements,
+		latency:      fit(xs, latencies),
+		bytes:        fit(xs, bytes),
+	}
+}
+
+// Estimate returns the estimated cost of an input of the given number of
+// tokens.
+func (e *Estimator) Estimate(tokens int) Cost {
+	x := float64(tokens)
+	return Cost{
+		Latency: time.Duration(math.Ceil(e.latency.at(x))),
+		Bytes:   int64(math.Ceil(e.bytes.at(x))),
+	}
+}
+
+// MaxTokens returns the maximum number of tokens of an input whose estimated
+// cost fits in the budget (see Cost.Fits), up to limit, or zero if none.
+func (e *Estimator) MaxTokens(budget Cost, limit int) int {
+	// The estimated costs never decrease with the tokens.
+	return sort.Search(limit, func(n int) bool {
+		return !e.Estimate(n + 1).Fits(budget)
+	})
+}
+
+// Governor returns a governor.Estimator of the memory of the requests,
+// converting their size in bytes to tokens.
+func (e *Estimator) Governor(bytesPerToken float64) governor.Estimator {
+	return func(requestSize int64) int64 {
+		if requestSize < 0 {
+			requestSize = 0
+		}
+		return e.Estimate(int(math.Ceil(float64(requestSize) / bytesPerToken))).Bytes
+	}
+}
+
+// Select returns the index of the first estimator whose estimated cost of
+// an input of the given number of tokens fits in the budget, or -1 if none.
+// The estimators are given in order of preference, e.g. from the most
+// accurate model to the fastest one.
+func Select(tokens int, budget Cost, estimators ...*Estimator) int {
+	for i, e := range estimators {
+		if e.Estimate(tokens).Fits(budget) {
+			return i
+		}
+	}
+	return -1
+}
+
+// polynomial contains the coefficients of c0 + c1 x + c2 x².
+type polynomial [3]float64
+
+func (p polynomial) at(x float64) float64 {
+	return p[0] + p[1]*x + p[2]*x*x
+}
+
+// fit returns the polynomial with non-negative coefficients fitting the
+// points with the least squared error, so that the estimates are never
+// negative nor decrease with the tokens, even with noisy measurements.
+//
+// Since there are only three coefficients, the fit is the best one of the
+// unconstrained least squares of each subset of the terms whose solution is
+// non-negative.
+func fit(xs, ys []float64) polynomial {
+	var best polynomial
+	bestErr := math.Inf(1)
+	for subset := 1; subset < 1<<3; subset++ {
+		p, ok := leastSquares(xs, ys, subset)
+		if !ok {
+			continue
+		}
+		var err float64
+		for i, x := range xs {
+			d := p.at(x) - ys[i]
+			err += d * d
+		}
+		if err < bestErr {
+			best, bestErr = p, err
+		}
+	}
+	return best
+}
+
+// leastSquares returns the least squares polynomial of the terms in the
+// subset (a bit mask of the powers of x), solving the normal equations. It
+// returns false if they are singular, or if a coefficient is negative.
+func leastSquares(xs, ys []float64, subset int) (polynomial, bool) {
+	var powers []int
+	for k := 0; k < 3; k++ {
+		if subset&(1<<k) != 0 {
+			powers = append(powers, k)
+		}
+	}
+	n := len(powers)
+	// a is the augmented matrix of the normal equations.
+	a := make([][]float64, n)
+	for r := range a {
+		a[r] = make([]float64, n+1)
+		for i, x := range xs {
+			for c := range powers {
+				a[r][c] += math.Pow(x, float64(powers[r]+powers[c]))
+			}
+			a[r][n] += math.Pow(x, float64(powers[r])) * ys[i]
+		}
+	}
+	var scale float64
+	for r := range a {
+		for c := 0; c < n; c++ {
+			scale = math.Max(scale, math.Abs(a[r][c]))
+		}
+	}
+	// Gauss-Jordan elimination with partial pivoting.
+	for c := 0; c < n; c++ {
+		pivot := c
+		for r := c + 1; r < n; r++ {
+			if math.Abs(a[r][c]) > math.Abs(a[pivot][c]) {
+				pivot = r
+			}
+		}
+		if math.Abs(a[pivot][c]) <= 1e-12*scale {
+			return polynomial{}, false
+		}
+		a[c], a[pivot] = a[pivot], a[c]
+		for r := 0; r < n; r++ {
+			if r == c {
+				continue
+			}
+			f := a[r][c] / a[c][c]
+			for k := c; k <= n; k++ {
+				a[r][k] -= f * a[c][k]
+			}
+		}
+	}
+	var p polynomial
+	for i, k := range powers {
+		p[k] = a[i][n] / a[i][i]
+		if p[k] < 0 {
+			return polynomial{}, false
+		}
+	}
+	return p, true
+}
@@ -0,0 +1,119 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cost
+
+import (
+	"testing"
+	"time"
+	"unsafe"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/nlpodyssey/spago/pkg/ml/nn/linear"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestEstimator returns an Estimator of 1ms + 10µs per token + 1µs per
+// squared token, and of 1KiB per token.
+func newTestEstimator() *Estimator {
+	var measurements []Measurement
+	for _, n := range []int{10, 20, 50, 100} {
+		measurements = append(measurements, Measurement{
+			Tokens: n,
+			Cost: Cost{
+				Latency: time.Millisecond + time.Duration(n)*10*time.Microsecond + time.Duration(n*n)*time.Microsecond,
+				Bytes:   int64(n) << 10,
+			},
+		})
+	}
+	return NewEstimator(measurements)
+}
+
+func TestEstimator_Estimate(t *testing.T) {
+	e := newTestEstimator()
+	c := e.Estimate(200)
+	assert.InDelta(t, float64(43*time.Millisecond), float64(c.Latency), float64(time.Microsecond))
+	assert.Equal(t, int64(200<<10), c.Bytes)
+}
+
+func TestFit(t *testing.T) {
+	xs := []float64{1, 2, 3, 4}
+
+	// exact
+	p := fit(xs, []float64{3, 9, 19, 33})
+	assert.InDeltaSlice(t, []float64{1, 0, 2}, p[:], 1e-9)
+
+	// the decreasing costs are fitted with a constant
+	p = fit(xs, []float64{10, 8, 6, 4})
+	assert.InDeltaSlice(t, []float64{7, 0, 0}, p[:], 1e-9)
+
+	// too few sizes for the quadratic term
+	p = fit([]float64{2, 2, 4}, []float64{5, 5, 9})
+	assert.InDelta(t, 9, p.at(4), 1e-6)
+	for _, c := range p {
+		assert.GreaterOrEqual(t, c, 0.0)
+	}
+
+	assert.Equal(t, polynomial{}, fit(nil, nil))
+}
+
+func TestEstimator_MaxTokens(t *testing.T) {
+	e := newTestEstimator()
+	assert.Equal(t, 100, e.MaxTokens(Cost{Bytes: 100 << 10}, 1000))
+	assert.Equal(t, 1000, e.MaxTokens(Cost{}, 1000))
+	assert.Equal(t, 0, e.MaxTokens(Cost{Latency: time.Millisecond}, 1000))
+	// 1ms + 10µs n + 1µs n² <= 3ms
+	assert.Equal(t, 40, e.MaxTokens(Cost{Latency: 3 * time.Millisecond}, 1000))
+}
+
+func TestSelect(t *testing.T) {
+	small := NewEstimator([]Measurement{
+		{Tokens: 10, Cost: Cost{Latency: time.Millisecond}},
+		{Tokens: 20, Cost: Cost{Latency: 2 * time.Millisecond}},
+	})
+	large := NewEstimator([]Measurement{
+		{Tokens: 10, Cost: Cost{Latency: 10 * time.Millisecond}},
+		{Tokens: 20, Cost: Cost{Latency: 20 * time.Millisecond}},
+	})
+	budget := Cost{Latency: 15 * time.Millisecond}
+	assert.Equal(t, 0, Select(10, budget, large, small))
+	assert.Equal(t, 1, Select(100, budget, large, small))
+	assert.Equal(t, -1, Select(1000, budget, large, small))
+}
+
+func TestEstimator_Governor(t *testing.T) {
+	estimate := newTestEstimator().Governor(4)
+	assert.Equal(t, int64(25<<10), estimate(100))
+	assert.Equal(t, int64(0), estimate(-1))
+}
+
+func TestCalibrate(t *testing.T) {
+	model := linear.New(4, 3)
+	e, err := Calibrate(StandardForward(model, 4), Config{
+		Sizes:       []int{1, 2, 4, 8},
+		Repetitions: 3,
+	})
+	require.NoError(t, err)
+	require.Len(t, e.Measurements, 4)
+	assert.Equal(t, 8, e.Measurements[3].Tokens)
+	for _, m := range e.Measurements {
+		assert.Greater(t, int64(m.Cost.Latency), int64(0))
+	}
+
+	// the memory is proportional to the tokens, the parameters excluded
+	assert.Equal(t, int64(0), e.Estimate(0).Bytes)
+	assert.Equal(t, 100*e.Measurements[0].Cost.Bytes, e.Estimate(100).Bytes)
+	assert.Equal(t, int64(0), e.Measurements[0].Cost.Bytes%int64(unsafe.Sizeof(mat.Float(0))))
+	assert.Greater(t, int64(e.Estimate(100).Latency), int64(0))
+
+	forward := func(g *ag.Graph, tokens int) {}
+	_, err = Calibrate(forward, Config{Repetitions: 1})
+	assert.Error(t, err)
+	_, err = Calibrate(forward, Config{Sizes: []int{1}})
+	assert.Error(t, err)
+	_, err = Calibrate(forward, Config{Sizes: []int{0}, Repetitions: 1})
+	assert.Error(t, err)
+}
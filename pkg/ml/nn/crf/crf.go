@@ -8,6 +8,7 @@ import (
 	"encoding/gob"
 	mat "github.com/nlpodyssey/spago/pkg/mat32"
 	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/nlpodyssey/spago/pkg/ml/ag/fn"
 	"github.com/nlpodyssey/spago/pkg/ml/nn"
 )
 
@@ -27,7 +28,8 @@ func init() {
 	gob.Register(&Model{})
 }
 
-// New returns a new convolution Model, initialized according to the given configuration.
+// New returns a new CRF Model of the given number of labels, whose transition
+// scores are initialized to zeros.
 func New(size int) *Model {
 	return &Model{
 		Size:             size,
@@ -45,7 +47,11 @@ func (m *Model) Decode(emissionScores []ag.Node) []int {
 	return Viterbi(m.TransitionScores.Value(), emissionScores)
 }
 
-// NegativeLogLoss computes the negative log loss with respect to the targets.
+// NegativeLogLoss computes the negative log-likelihood of the targets, i.e.
+// the difference between the total score of all the possible sequences (see
+// totalScore) and the score of the target sequence. It is differentiable with
+// respect to both the emission scores and the transition scores, so that the
+// CRF can be trained end-to-end with the model computing the emissions.
 func (m *Model) NegativeLogLoss(emissionScores []ag.Node, target []int) ag.Node {
 	goldScore := m.goldScore(emissionScores, target)
 	totalScore := m.totalScore(emissionScores)
@@ -66,51 +72,29 @@ func (m *Model) goldScore(emissionScores []ag.Node, target []int) ag.Node {
 	return goldScore
 }
 
-func (m *Model) totalScore(predicted []ag.Node) ag.Node {
+// totalScore computes the logarithm of the sum of the exponentiated scores of
+// all the possible sequences (a.k.a. partition function), by the forward
+// algorithm. The sums are performed in log-space, so that the scores can be
+// arbitrarily large without overflowing.
+func (m *Model) totalScore(emissionScores []ag.Node) ag.Node {
 	g := m.Graph()
-	totalVector := m.totalScoreStart(predicted[0])
-	for i := 1; i < len(predicted); i++ {
-		totalVector = m.totalScoreStep(totalVector, nn.SeparateVec(g, predicted[i]))
-	}
-	totalVector = m.totalScoreEnd(totalVector)
-	return g.Log(g.ReduceSum(g.Concat(totalVector...)))
-
-}
-
-func (m *Model) totalScoreStart(stepVec ag.Node) []ag.Node {
-	firstTransitionScores := m.Scores[0]
-	scores := make([]ag.Node, m.Size)
-	g := m.Graph()
-	for i := 0; i < m.Size; i++ {
-		scores[i] = g.Add(g.AtVec(stepVec, i), firstTransitionScores[i+1])
-	}
-	return scores
-}
-
-func (m *Model) totalScoreEnd(stepVec []ag.Node) []ag.Node {
-	scores := make([]ag.Node, m.Size)
-	g := m.Graph()
-	for i := 0; i < m.Size; i++ {
-		vecTrans := g.Add(stepVec[i], m.Scores[i+1][0])
-		scores[i] = g.Add(scores[i], g.Exp(vecTrans))
+	w := m.TransitionScores
+	// alpha[j] is the log-sum-exp of the scores of the prefixes ending with j
+	alpha := g.Add(emissionScores[0], g.T(g.View(w, 0, 1, 1, m.Size))) // start transitions
+	for _, stepVec := range emissionScores[1:] {
+		next := make([]ag.Node, m.Size)
+		for j := range next {
+			next[j] = logSumExp(g, g.Add(alpha, g.View(w, 1, j+1, m.Size, 1))) // transitions to j
+		}
+		alpha = g.Add(g.Concat(next...), stepVec)
 	}
-	return scores
+	return logSumExp(g, g.Add(alpha, g.View(w, 1, 0, m.Size, 1))) // end transitions
 }
 
-func (m *Model) totalScoreStep(totalVec []ag.Node, stepVec []ag.Node) []ag.Node {
-	scores := make([]ag.Node, m.Size)
-	g := m.Graph()
-	for i := 0; i < m.Size; i++ {
-		nodei := totalVec[i]
-		transitionScores := m.Scores[i+1]
-		for j := 0; j < m.Size; j++ {
-			vecSum := g.Add(nodei, stepVec[j])
-			vecTrans := g.Add(vecSum, transitionScores[j+1])
-			scores[j] = g.Add(scores[j], g.Exp(vecTrans))
-		}
-	}
-	for i := 0; i < m.Size; i++ {
-		scores[i] = g.Log(scores[i])
-	}
-	return scores
+// logSumExp returns the logarithm of the sum of the exponentials of the
+// elements of the column vector x, shifted by their maximum for numerical
+// stability.
+func logSumExp(g *ag.Graph, x ag.Node) ag.Node {
+	shift := g.ReduceMaxAlong(x, fn.AlongColumns, false)
+	return g.Add(shift, g.Log(g.ReduceSum(g.Exp(g.SubScalar(x, shift)))))
 }
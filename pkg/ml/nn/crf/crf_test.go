@@ -9,6 +9,7 @@ import (
 	"github.com/nlpodyssey/spago/pkg/ml/ag"
 	"github.com/nlpodyssey/spago/pkg/ml/nn"
 	"github.com/stretchr/testify/assert"
+	"math"
 	"testing"
 )
 
@@ -81,6 +82,175 @@ func TestModel_Loss(t *testing.T) {
 	assert.InDeltaSlice(t, []mat.Float{2.37258}, loss.Value().Data(), 0.00001)
 }
 
+func TestModel_LargeScores(t *testing.T) {
+	model := newTestModel()
+	g := ag.NewGraph()
+	proc := nn.ReifyForTraining(model, g).(*Model)
+
+	emissions := testEmissions()
+	xs := make([]ag.Node, len(emissions))
+	for i, e := range emissions {
+		for j := range e {
+			e[j] *= 100 // the exponentials would overflow
+		}
+		xs[i] = g.NewVariable(mat.NewVecDense(toFloats(e)), true)
+	}
+
+	y := proc.totalScore(xs)
+	assert.False(t, mat.IsInf(y.ScalarValue(), 0))
+	assert.InDelta(t, bruteForceTotalScore(testTransitions(model), emissions), y.ScalarValue(), 1.0e-3)
+}
+
+func TestModel_LossGradients(t *testing.T) {
+	model := newTestModel()
+	g := ag.NewGraph()
+	proc := nn.ReifyForTraining(model, g).(*Model)
+
+	emissions := testEmissions()
+	gold := []int{0, 0, 1, 0, 3}
+	xs := make([]ag.Node, len(emissions))
+	for i, e := range emissions {
+		xs[i] = g.NewVariable(mat.NewVecDense(toFloats(e)), true)
+	}
+	loss := proc.NegativeLogLoss(xs, gold)
+	g.Backward(loss)
+
+	transitions := testTransitions(model)
+	assert.InDelta(t, bruteForceLoss(transitions, emissions, gold), loss.ScalarValue(), 1.0e-4)
+
+	// the gradients are compared with the finite differences of the loss
+	const eps = 1.0e-4
+	for i, e := range emissions {
+		for j := range e {
+			e[j] += eps
+			plus := bruteForceLoss(transitions, emissions, gold)
+			e[j] -= 2 * eps
+			minus := bruteForceLoss(transitions, emissions, gold)
+			e[j] += eps
+			assert.InDelta(t, (plus-minus)/(2*eps), xs[i].Grad().AtVec(j), 1.0e-3)
+		}
+	}
+	for i, row := range transitions {
+		for j := range row {
+			row[j] += eps
+			plus := bruteForceLoss(transitions, emissions, gold)
+			row[j] -= 2 * eps
+			minus := bruteForceLoss(transitions, emissions, gold)
+			row[j] += eps
+			assert.InDelta(t, (plus-minus)/(2*eps), model.TransitionScores.Grad().At(i, j), 1.0e-3)
+		}
+	}
+}
+
+func TestModel_Training(t *testing.T) {
+	model := New(4)
+	emissions := testEmissions()
+	gold := []int{0, 0, 1, 0, 3}
+
+	var firstLoss, lastLoss mat.Float
+	for epoch := 0; epoch < 50; epoch++ {
+		g := ag.NewGraph()
+		proc := nn.ReifyForTraining(model, g).(*Model)
+		xs := make([]ag.Node, len(emissions))
+		for i, e := range emissions {
+			xs[i] = g.NewVariable(mat.NewVecDense(toFloats(e)), false)
+		}
+		loss := proc.NegativeLogLoss(xs, gold)
+		g.Backward(loss)
+		model.TransitionScores.Value().SubInPlace(model.TransitionScores.Grad().ProdScalar(0.5))
+		model.TransitionScores.ZeroGrad()
+		if epoch == 0 {
+			firstLoss = loss.ScalarValue()
+		}
+		lastLoss = loss.ScalarValue()
+	}
+	assert.Less(t, lastLoss, firstLoss)
+
+	g := ag.NewGraph()
+	proc := nn.ReifyForInference(model, g).(*Model)
+	xs := make([]ag.Node, len(emissions))
+	for i, e := range emissions {
+		xs[i] = g.NewVariable(mat.NewVecDense(toFloats(e)), false)
+	}
+	assert.Equal(t, gold, proc.Decode(xs)) // the transitions learned to override the emissions
+}
+
+func testEmissions() [][]float64 {
+	return [][]float64{
+		{1.7, 0.2, -0.3, 0.5},
+		{2.0, -3.5, 0.1, 2.0},
+		{-2.5, 3.2, -0.2, -0.3},
+		{3.3, -0.9, 2.7, -2.7},
+		{0.5, 0.2, 0.4, 1.4},
+	}
+}
+
+func toFloats(xs []float64) []mat.Float {
+	ys := make([]mat.Float, len(xs))
+	for i, x := range xs {
+		ys[i] = mat.Float(x)
+	}
+	return ys
+}
+
+// bruteForceLoss computes the negative log-likelihood of the gold sequence by
+// enumerating all the possible sequences.
+func bruteForceLoss(transitions, emissions [][]float64, gold []int) float64 {
+	return bruteForceTotalScore(transitions, emissions) - sequenceScore(transitions, emissions, gold)
+}
+
+// bruteForceTotalScore computes the log-sum-exp of the scores of all the
+// possible sequences.
+func bruteForceTotalScore(transitions, emissions [][]float64) float64 {
+	size := len(transitions) - 1
+	var scores []float64
+	seq := make([]int, len(emissions))
+	var enumerate func(i int)
+	enumerate = func(i int) {
+		if i == len(seq) {
+			scores = append(scores, sequenceScore(transitions, emissions, seq))
+			return
+		}
+		for y := 0; y < size; y++ {
+			seq[i] = y
+			enumerate(i + 1)
+		}
+	}
+	enumerate(0)
+
+	max := math.Inf(-1)
+	for _, s := range scores {
+		max = math.Max(max, s)
+	}
+	var sum float64
+	for _, s := range scores {
+		sum += math.Exp(s - max)
+	}
+	return max + math.Log(sum)
+}
+
+func sequenceScore(transitions, emissions [][]float64, seq []int) float64 {
+	s := transitions[0][seq[0]+1] + transitions[seq[len(seq)-1]+1][0]
+	for i, y := range seq {
+		s += emissions[i][y]
+		if i > 0 {
+			s += transitions[seq[i-1]+1][y+1]
+		}
+	}
+	return s
+}
+
+func testTransitions(model *Model) [][]float64 {
+	transitions := make([][]float64, model.Size+1)
+	for i := range transitions {
+		transitions[i] = make([]float64, model.Size+1)
+		for j := range transitions[i] {
+			transitions[i][j] = float64(model.TransitionScores.Value().At(i, j))
+		}
+	}
+	return transitions
+}
+
 func newTestModel() *Model {
 	model := New(4)
 	model.TransitionScores.Value().SetData([]mat.Float{
@@ -0,0 +1,181 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nn
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/mat32/rand"
+)
+
+// DiagnosticsConfig provides configuration settings for Diagnose.
+type DiagnosticsConfig struct {
+	// PowerIterations is the number of iterations of the power method
+	// estimating the spectral norms.
+	PowerIterations int
+	// ConditionNumbers enables the computation of the condition numbers of the
+	// matrices, by singular value decomposition, which is expensive for the
+	// large ones.
+	ConditionNumbers bool
+	// DeadThreshold is the greatest norm of the weights of a dead unit.
+	DeadThreshold mat.Float
+}
+
+// NewDefaultDiagnosticsConfig returns a new DiagnosticsConfig with the usual
+// settings: 20 power iterations, the condition numbers, and the units with
+// weights of norm up to 1e-6 considered dead.
+func NewDefaultDiagnosticsConfig() DiagnosticsConfig {
+	return DiagnosticsConfig{
+		PowerIterations:  20,
+		ConditionNumbers: true,
+		DeadThreshold:    1.0e-6,
+	}
+}
+
+// ParamDiagnostics contains the numerical-health statistics of a parameter.
+type ParamDiagnostics struct {
+	// Name is the qualified name of the parameter (see ForEachNamedParam).
+	Name string
+	// Rows and Columns are the shape of the value.
+	Rows, Columns int
+	// SpectralNorm is an estimate of the largest singular value (the norm,
+	// for a vector), from which the Lipschitz constant of a deep stack of
+	// layers can be bounded.
+	SpectralNorm mat.Float
+	// ConditionNumber is the ratio of the largest to the smallest singular
+	// value of a matrix, which is +Inf for a rank-deficient one. It is zero
+	// for the vectors, or if not computed.
+	ConditionNumber mat.Float
+	// DeadUnits is the number of rows of a matrix (i.e. the output units of
+	// a linear layer) whose weights have all vanished (see
+	// DiagnosticsConfig.DeadThreshold). It is zero for the vectors.
+	DeadUnits int
+	// NonFinite is the number of NaN or infinite values.
+	NonFinite int
+}
+
+// Diagnostics is the report of the numerical health of the parameters of a
+// model, helpful to debug the training plateaus of deep stacks (e.g.
+// exploding spectral norms, ill-conditioned or collapsed layers).
+type Diagnostics struct {
+	// Params contains the statistics of each parameter, in order of traversal.
+	Params []ParamDiagnostics
+}
+
+// Diagnose computes the numerical-health statistics of all the parameters of
+// the model (including sub-params), on demand. The parameters are not
+// modified. The statistics of the parameters with non-finite values are not
+// computed, except NonFinite. The tied params are reported once, with the
+// name of the first of them (see TieWeights).
+func Diagnose(m Model, config DiagnosticsConfig) *Diagnostics {
+	if config.PowerIterations < 1 {
+		panic("nn: the number of power iterations must be greater than zero")
+	}
+	d := &Diagnostics{Params: make([]ParamDiagnostics, 0)}
+	seen := make(map[Param]bool)
+	ForEachNamedParam(m, func(name string, param Param) {
+		if owner := TiedOwner(param); !seen[owner] {
+			seen[owner] = true
+			d.Params = append(d.Params, diagnoseParam(name, param.Value(), config))
+		}
+	})
+	return d
+}
+
+func diagnoseParam(name string, value mat.Matrix, config DiagnosticsConfig) ParamDiagnostics {
+	rows, cols := value.Dims()
+	p := ParamDiagnostics{Name: name, Rows: rows, Columns: cols}
+	for _, v := range value.Data() {
+		if v != v || mat.IsInf(v, 0) {
+			p.NonFinite++
+		}
+	}
+	if p.NonFinite > 0 || value.Size() == 0 {
+		return p
+	}
+	w := mat.NewDense(rows, cols, value.Data())
+	if rows == 1 || cols == 1 {
+		p.SpectralNorm = w.Norm(2)
+		return p
+	}
+	p.SpectralNorm = SpectralNorm(w, config.PowerIterations)
+	if config.ConditionNumbers {
+		if c, err := w.ConditionNumber(); err == nil {
+			p.ConditionNumber = c
+		}
+	}
+	for i := 0; i < rows; i++ {
+		if w.ExtractRow(i).Norm(2) <= config.DeadThreshold {
+			p.DeadUnits++
+		}
+	}
+	return p
+}
+
+// SpectralNorm returns an estimate of the largest singular value of the
+// matrix, computed with the given number of iterations of the power method,
+// starting from a fixed pseudo-random vector.
+func SpectralNorm(w mat.Matrix, iterations int) mat.Float {
+	generator := rand.NewLockedRand(42)
+	data := make([]mat.Float, w.Columns())
+	for i := range data {
+		data[i] = mat.Float(generator.Float32()) - 0.5
+	}
+	var v mat.Matrix = mat.NewVecDense(data)
+	var sigma mat.Float
+	for i := 0; i < iterations; i++ {
+		norm := v.Norm(2)
+		if norm == 0 {
+			return 0
+		}
+		u := w.Mul(v.ProdScalarInPlace(1 / norm))
+		sigma = u.Norm(2)
+		v = w.TMatMul(u)
+	}
+	return sigma
+}
+
+// DeadUnits returns the indices of the units which are never active (i.e.
+// not greater than the threshold, e.g. zero for the ReLU) in all the given
+// activations, e.g. the outputs of a layer on a batch of examples. All the
+// activations must have the same size.
+func DeadUnits(activations []mat.Matrix, threshold mat.Float) []int {
+	if len(activations) == 0 {
+		return nil
+	}
+	size := activations[0].Size()
+	alive := make([]bool, size)
+	for _, a := range activations {
+		if a.Size() != size {
+			panic("nn: activations with different sizes")
+		}
+		for i, v := range a.Data() {
+			if v > threshold {
+				alive[i] = true
+			}
+		}
+	}
+	dead := make([]int, 0)
+	for i, ok := range alive {
+		if !ok {
+			dead = append(dead, i)
+		}
+	}
+	return dead
+}
+
+// WriteReport writes a table of the statistics of the parameters.
+func (d *Diagnostics) WriteReport(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "param\tshape\tspectral norm\tcondition number\tdead units\tnon-finite\t")
+	for _, p := range d.Params {
+		fmt.Fprintf(tw, "%s\t%d × %d\t%g\t%g\t%d\t%d\t\n",
+			p.Name, p.Rows, p.Columns, p.SpectralNorm, p.ConditionNumber, p.DeadUnits, p.NonFinite)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,102 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nn
+
+import (
+	"bytes"
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiagnose(t *testing.T) {
+	m := newWideArithmeticModel(3, 2)
+	m.Layers[0].W.Value().SetData([]mat.Float{
+		3, 0,
+		0, 1,
+	})
+	m.Layers[0].B.Value().SetData([]mat.Float{3, 4})
+	m.Layers[1].W.Value().SetData([]mat.Float{
+		1, 2,
+		0, 0,
+	})
+	m.Layers[2].W.Value().SetData([]mat.Float{
+		1, mat.NaN(),
+		mat.Inf(1), 0,
+	})
+
+	d := Diagnose(m, NewDefaultDiagnosticsConfig())
+	require.Len(t, d.Params, 6)
+
+	p := d.Params[0]
+	assert.Equal(t, "Layers.0.W", p.Name)
+	assert.Equal(t, 2, p.Rows)
+	assert.Equal(t, 2, p.Columns)
+	assert.InDelta(t, 3, p.SpectralNorm, 1.0e-4)
+	assert.InDelta(t, 3, p.ConditionNumber, 1.0e-4)
+	assert.Equal(t, 0, p.DeadUnits)
+	assert.Equal(t, 0, p.NonFinite)
+
+	p = d.Params[1]
+	assert.Equal(t, "Layers.0.B", p.Name)
+	assert.InDelta(t, 5, p.SpectralNorm, 1.0e-6)
+	assert.Equal(t, mat.Float(0), p.ConditionNumber)
+
+	p = d.Params[2]
+	assert.InDelta(t, mat.Sqrt(5), p.SpectralNorm, 1.0e-4)
+	assert.Equal(t, mat.Inf(1), p.ConditionNumber)
+	assert.Equal(t, 1, p.DeadUnits)
+
+	p = d.Params[4]
+	assert.Equal(t, 2, p.NonFinite)
+	assert.Equal(t, mat.Float(0), p.SpectralNorm)
+
+	config := NewDefaultDiagnosticsConfig()
+	config.ConditionNumbers = false
+	assert.Equal(t, mat.Float(0), Diagnose(m, config).Params[0].ConditionNumber)
+	config.PowerIterations = 0
+	assert.Panics(t, func() { Diagnose(m, config) })
+
+	var buf bytes.Buffer
+	require.NoError(t, d.WriteReport(&buf))
+	assert.Contains(t, buf.String(), "Layers.1.W")
+	assert.Contains(t, buf.String(), "spectral norm")
+}
+
+func TestDiagnose_TiedParams(t *testing.T) {
+	m := newWideArithmeticModel(2, 2)
+	TieWeights(m.Layers[0].W, m.Layers[1].W)
+	d := Diagnose(m, NewDefaultDiagnosticsConfig())
+	names := make([]string, len(d.Params))
+	for i, p := range d.Params {
+		names[i] = p.Name
+	}
+	assert.Equal(t, []string{"Layers.0.W", "Layers.0.B", "Layers.1.B"}, names)
+}
+
+func TestSpectralNorm(t *testing.T) {
+	w := mat.NewDense(3, 2, []mat.Float{
+		1, 2,
+		3, 4,
+		5, 6,
+	})
+	_, s, _, err := w.SVD()
+	require.NoError(t, err)
+	assert.InDelta(t, s[0], SpectralNorm(w, 20), 1.0e-4)
+	assert.Equal(t, mat.Float(0), SpectralNorm(mat.NewEmptyDense(2, 2), 20))
+}
+
+func TestDeadUnits(t *testing.T) {
+	activations := []mat.Matrix{
+		mat.NewVecDense([]mat.Float{0, 1, 0, 0}),
+		mat.NewVecDense([]mat.Float{0, 0, 2, 0}),
+	}
+	assert.Equal(t, []int{0, 3}, DeadUnits(activations, 0))
+	assert.Equal(t, []int{0, 1, 3}, DeadUnits(activations, 1))
+	assert.Nil(t, DeadUnits(nil, 0))
+	assert.Panics(t, func() { DeadUnits(append(activations, mat.NewEmptyVecDense(2)), 0) })
+}
@@ -0,0 +1,82 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nn
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Freeze sets the parameters of the model (including sub-params) whose
+// qualified names (see ForEachNamedParam) match any of the glob patterns as
+// not requiring gradients, so that they are not trained, e.g.:
+//
+//     nn.Freeze(model, "Embeddings.*", "Encoder.Layers.[0-5].*")
+//
+// The patterns follow the syntax of path.Match, case-insensitively, where '*'
+// matches any sequence of characters, dots included. Without patterns, all the
+// parameters are frozen. It returns the number of parameters matched.
+// It panics if a pattern is malformed.
+func Freeze(m Model, patterns ...string) int {
+	return setRequiresGrad(m, globMatcher(patterns), false)
+}
+
+// Unfreeze sets the parameters of the model whose qualified names match any of
+// the glob patterns as requiring gradients, e.g. to gradually unfreeze the
+// layers of a pre-trained model during the fine-tuning (see Freeze).
+func Unfreeze(m Model, patterns ...string) int {
+	return setRequiresGrad(m, globMatcher(patterns), true)
+}
+
+// FreezeMatching sets the parameters of the model (including sub-params) whose
+// qualified names match the regular expression as not requiring gradients.
+// It returns the number of parameters matched.
+func FreezeMatching(m Model, re *regexp.Regexp) int {
+	return setRequiresGrad(m, re.MatchString, false)
+}
+
+// UnfreezeMatching sets the parameters of the model (including sub-params)
+// whose qualified names match the regular expression as requiring gradients.
+// It returns the number of parameters matched.
+func UnfreezeMatching(m Model, re *regexp.Regexp) int {
+	return setRequiresGrad(m, re.MatchString, true)
+}
+
+func setRequiresGrad(m Model, match func(name string) bool, value bool) int {
+	n := 0
+	ForEachNamedParam(m, func(name string, param Param) {
+		if match(name) {
+			param.SetRequiresGrad(value)
+			n++
+		}
+	})
+	return n
+}
+
+// globMatcher returns a function reporting whether a name matches any of the
+// patterns, or all the names if there are no patterns.
+func globMatcher(patterns []string) func(name string) bool {
+	lower := make([]string, len(patterns))
+	for i, p := range patterns {
+		lower[i] = strings.ToLower(p)
+		if _, err := path.Match(lower[i], ""); err != nil {
+			panic(fmt.Sprintf("nn: invalid pattern %q", p))
+		}
+	}
+	return func(name string) bool {
+		if len(lower) == 0 {
+			return true
+		}
+		name = strings.ToLower(name)
+		for _, p := range lower {
+			if ok, _ := path.Match(p, name); ok {
+				return true
+			}
+		}
+		return false
+	}
+}
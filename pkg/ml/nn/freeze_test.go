@@ -0,0 +1,50 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nn
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func requiringGrad(m Model) []string {
+	names := make([]string, 0)
+	ForEachNamedParam(m, func(name string, param Param) {
+		if param.RequiresGrad() {
+			names = append(names, name)
+		}
+	})
+	return names
+}
+
+func TestFreeze(t *testing.T) {
+	m := newArithmeticModel(1, 2, 3)
+
+	assert.Equal(t, 4, Freeze(m, "layers.0.*", "Layers.1.*"))
+	assert.Equal(t, []string{"Layers.2.W", "Layers.2.B"}, requiringGrad(m))
+
+	assert.Equal(t, 2, Unfreeze(m, "Layers.[01].W"))
+	assert.Equal(t, []string{"Layers.0.W", "Layers.1.W", "Layers.2.W", "Layers.2.B"}, requiringGrad(m))
+
+	assert.Equal(t, 0, Freeze(m, "Layers.3.*"))
+	assert.Equal(t, 6, Freeze(m))
+	assert.Empty(t, requiringGrad(m))
+	assert.Equal(t, 6, Unfreeze(m))
+	assert.Len(t, requiringGrad(m), 6)
+
+	assert.Panics(t, func() { Freeze(m, "Layers.[") })
+}
+
+func TestFreezeMatching(t *testing.T) {
+	m := newArithmeticModel(1, 2, 3)
+
+	assert.Equal(t, 3, FreezeMatching(m, regexp.MustCompile(`\.B$`)))
+	assert.Equal(t, []string{"Layers.0.W", "Layers.1.W", "Layers.2.W"}, requiringGrad(m))
+
+	assert.Equal(t, 2, UnfreezeMatching(m, regexp.MustCompile(`^Layers\.2\.`)))
+	assert.Equal(t, []string{"Layers.0.W", "Layers.1.W", "Layers.2.W", "Layers.2.B"}, requiringGrad(m))
+}
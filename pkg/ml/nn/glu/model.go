@@ -0,0 +1,119 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package glu implements the feed-forward blocks based on the gated linear
+// units (GLU) and their variants, as found in the position-wise feed-forward
+// networks of recent transformers (e.g. SwiGLU in LLaMA, GEGLU in T5 v1.1):
+//
+//     y = Output(act(Gate(x)) ⊙ Value(x))
+//
+// where the activation of the gate is the sigmoid for GLU, GELU for GEGLU,
+// and SiLU (the swish with β = 1) for SwiGLU.
+//
+// Reference: "GLU Variants Improve Transformer" by Noam Shazeer (2020)
+// (https://arxiv.org/abs/2002.05202)
+package glu
+
+import (
+	"encoding/gob"
+
+	"github.com/nlpodyssey/spago/pkg/mat32/rand"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/nlpodyssey/spago/pkg/ml/initializers"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/ml/nn/activation"
+	"github.com/nlpodyssey/spago/pkg/ml/nn/linear"
+)
+
+var (
+	_ nn.Model = &Model{}
+)
+
+// Config provides configuration parameters for Model.
+type Config struct {
+	// InputSize is the size of the input vectors.
+	InputSize int
+	// HiddenSize is the size of the gated units.
+	HiddenSize int
+	// OutputSize is the size of the output vectors.
+	OutputSize int
+	// Activation is applied to the gate.
+	Activation ag.OpName
+	// Bias enables the biases of the projections. When disabled, the biases
+	// are kept to zero, without gradients.
+	Bias bool
+}
+
+// Model contains the serializable parameters.
+type Model struct {
+	nn.BaseModel
+	Config Config
+	Gate   *linear.Model
+	Value  *linear.Model
+	Act    *activation.Model
+	Output *linear.Model
+}
+
+func init() {
+	gob.Register(&Model{})
+}
+
+// New returns a new Model initialized to zeros.
+func New(config Config) *Model {
+	return &Model{
+		Config: config,
+		Gate:   newLinear(config.InputSize, config.HiddenSize, config.Bias),
+		Value:  newLinear(config.InputSize, config.HiddenSize, config.Bias),
+		Act:    activation.New(config.Activation),
+		Output: newLinear(config.HiddenSize, config.OutputSize, config.Bias),
+	}
+}
+
+// NewGLU returns a new Model with sigmoid gates and without biases, as in
+// the reference paper, initialized to zeros.
+func NewGLU(in, hidden, out int) *Model {
+	return New(Config{InputSize: in, HiddenSize: hidden, OutputSize: out, Activation: ag.OpSigmoid})
+}
+
+// NewGEGLU returns a new Model with GELU gates and without biases,
+// initialized to zeros.
+func NewGEGLU(in, hidden, out int) *Model {
+	return New(Config{InputSize: in, HiddenSize: hidden, OutputSize: out, Activation: ag.OpGELU})
+}
+
+// NewSwiGLU returns a new Model with SiLU gates and without biases, as in the
+// feed-forward networks of LLaMA, initialized to zeros.
+func NewSwiGLU(in, hidden, out int) *Model {
+	return New(Config{InputSize: in, HiddenSize: hidden, OutputSize: out, Activation: ag.OpSiLU})
+}
+
+func newLinear(in, out int, bias bool) *linear.Model {
+	return linear.New(in, out, linear.BiasGrad(bias))
+}
+
+// Initialize initializes the weights of the projections with the Xavier
+// initialization, and the biases with zeros.
+func (m *Model) Initialize(seed uint64) {
+	r := rand.NewLockedRand(seed)
+	for _, l := range []*linear.Model{m.Gate, m.Value, m.Output} {
+		initializers.XavierUniform(l.W.Value(), 1, r)
+		initializers.Zeros(l.B.Value())
+	}
+}
+
+// Forward performs the forward step for each input node and returns the result.
+func (m *Model) Forward(xs ...ag.Node) []ag.Node {
+	ys := make([]ag.Node, len(xs))
+	for i, x := range xs {
+		ys[i] = m.forward(x)
+	}
+	return ys
+}
+
+func (m *Model) forward(x ag.Node) ag.Node {
+	g := m.Graph()
+	gate := m.Act.Forward(m.Gate.Forward(x)...)[0]
+	h := g.Prod(gate, m.Value.Forward(x)[0])
+	return m.Output.Forward(h)[0]
+}
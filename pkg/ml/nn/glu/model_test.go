@@ -0,0 +1,117 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package glu
+
+import (
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/nlpodyssey/spago/pkg/ml/ag/gradcheck"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestModel(activation ag.OpName, bias bool) *Model {
+	model := New(Config{
+		InputSize:  3,
+		HiddenSize: 2,
+		OutputSize: 2,
+		Activation: activation,
+		Bias:       bias,
+	})
+	model.Gate.W.Value().SetData([]mat.Float{
+		0.5, -0.3, 0.8,
+		-0.6, 0.2, 0.1,
+	})
+	model.Value.W.Value().SetData([]mat.Float{
+		0.1, 0.4, -0.2,
+		0.7, -0.5, 0.3,
+	})
+	model.Output.W.Value().SetData([]mat.Float{
+		0.9, -0.4,
+		0.3, 0.6,
+	})
+	if bias {
+		model.Gate.B.Value().SetData([]mat.Float{0.1, -0.2})
+		model.Value.B.Value().SetData([]mat.Float{0.3, 0.0})
+		model.Output.B.Value().SetData([]mat.Float{-0.1, 0.2})
+	}
+	return model
+}
+
+func TestModel_Forward(t *testing.T) {
+	sigmoid := func(x mat.Float) mat.Float { return 1 / (1 + mat.Exp(-x)) }
+	gelu := func(x mat.Float) mat.Float {
+		return 0.5 * x * (1 + mat.Tanh(mat.Sqrt(2/mat.Pi)*(x+0.044715*x*x*x)))
+	}
+	silu := func(x mat.Float) mat.Float { return x * sigmoid(x) }
+
+	for _, tc := range []struct {
+		activation ag.OpName
+		f          func(x mat.Float) mat.Float
+		bias       bool
+	}{
+		{ag.OpSigmoid, sigmoid, false},
+		{ag.OpGELU, gelu, false},
+		{ag.OpSiLU, silu, false},
+		{ag.OpSiLU, silu, true},
+	} {
+		model := newTestModel(tc.activation, tc.bias)
+		g := ag.NewGraph()
+		x := g.NewVariable(mat.NewVecDense([]mat.Float{0.4, -0.8, 0.6}), false)
+		y := nn.ReifyForInference(model, g).(*Model).Forward(x)[0]
+
+		gate := model.Gate.W.Value().Mul(x.Value()).Add(model.Gate.B.Value())
+		value := model.Value.W.Value().Mul(x.Value()).Add(model.Value.B.Value())
+		h := value.Clone()
+		for i := range h.Data() {
+			h.Data()[i] *= tc.f(gate.Data()[i])
+		}
+		expected := model.Output.W.Value().Mul(h).Add(model.Output.B.Value())
+		assert.InDeltaSlice(t, expected.Data(), y.Value().Data(), 1.0e-5, "%v", tc.activation)
+	}
+}
+
+func TestModel_Gradients(t *testing.T) {
+	for _, bias := range []bool{false, true} {
+		model := newTestModel(ag.OpSiLU, bias)
+		x := mat.NewVecDense([]mat.Float{0.4, -0.8, 0.6})
+		err := gradcheck.CheckModelGradients(model, func(proc nn.Model) ag.Node {
+			m := proc.(*Model)
+			g := m.Graph()
+			return g.ReduceSum(g.Square(m.Forward(g.NewVariable(x, false))[0]))
+		}, 1e-3, 1e-2)
+		assert.NoError(t, err)
+	}
+}
+
+func TestNewVariants(t *testing.T) {
+	for activation, model := range map[ag.OpName]*Model{
+		ag.OpSigmoid: NewGLU(4, 8, 3),
+		ag.OpGELU:    NewGEGLU(4, 8, 3),
+		ag.OpSiLU:    NewSwiGLU(4, 8, 3),
+	} {
+		assert.Equal(t, activation, model.Act.Activation)
+		assert.Equal(t, 8, model.Gate.W.Value().Rows())
+		assert.Equal(t, 4, model.Value.W.Value().Columns())
+		assert.Equal(t, 3, model.Output.W.Value().Rows())
+		assert.False(t, model.Output.B.RequiresGrad())
+	}
+}
+
+func TestModel_Initialize(t *testing.T) {
+	model := NewSwiGLU(4, 8, 3)
+	model.Initialize(42)
+	nn.ForEachNamedParam(model, func(name string, p nn.Param) {
+		if p.Value().Columns() == 1 {
+			assert.Equal(t, mat.Float(0), p.Value().Sum(), name)
+			return
+		}
+		require.NotEqual(t, mat.Float(0), p.Value().Norm(2), name)
+	})
+	assert.NotEqual(t, model.Gate.W.Value().Data(), model.Value.W.Value().Data())
+}
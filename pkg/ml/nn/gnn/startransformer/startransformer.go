@@ -15,6 +15,7 @@ import (
 	"github.com/nlpodyssey/spago/pkg/ml/nn/attention"
 	"github.com/nlpodyssey/spago/pkg/ml/nn/linear"
 	"github.com/nlpodyssey/spago/pkg/ml/nn/normalization/layernorm"
+	"github.com/nlpodyssey/spago/pkg/ml/numerics"
 	"sync"
 )
 
@@ -119,7 +120,7 @@ func (m *Model) satelliteAttention(query ag.Node, context []ag.Node) ag.Node {
 		Keys:    m.Key.Forward(context...),
 		Values:  m.Value.Forward(context...),
 	}
-	return nn.ToNode(attention.LinearAttention(m.Graph(), attIn, attMappingFunc, 1e-12))
+	return nn.ToNode(attention.LinearAttention(m.Graph(), attIn, attMappingFunc, numerics.Current().Division))
 }
 
 func (m *Model) updateRelayNode(prevS ag.Node, ht []ag.Node) ag.Node {
@@ -134,7 +135,7 @@ func (m *Model) relayAttention(query ag.Node, context []ag.Node) ag.Node {
 		Keys:    m.RelayKey.Forward(context...),
 		Values:  m.RelayValue.Forward(context...),
 	}
-	return attention.LinearAttention(m.Graph(), attIn, attMappingFunc, 1e-12)[0]
+	return attention.LinearAttention(m.Graph(), attIn, attMappingFunc, numerics.Current().Division)[0]
 }
 
 func attMappingFunc(g *ag.Graph, x ag.Node) ag.Node {
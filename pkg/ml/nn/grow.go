@@ -0,0 +1,140 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nn
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/mat32/rand"
+	"github.com/nlpodyssey/spago/pkg/mat32/rand/normal"
+)
+
+// GrowOptions provides the options to initialize a larger model from a
+// smaller one.
+type GrowOptions struct {
+	// SourceName maps the qualified name of a parameter of the larger model
+	// (see ForEachNamedParam) to the name of the parameter of the smaller
+	// model it is initialized from, or to the empty string to leave it as it
+	// is. If nil, the names are the same (see also DuplicateLayers and
+	// StackLayers).
+	SourceName func(name string) string
+	// Noise is the standard deviation of the Gaussian noise added to the
+	// values, to break the symmetry of the replicated units. Zero means no noise.
+	Noise mat.Float
+	// Generator is the random generator of the noise.
+	Generator *rand.LockedRand
+}
+
+// Grow initializes the parameters of dst, a deeper and/or wider version of
+// src, from the (trained) parameters of src, in the style of Net2Net, so that
+// the training of the larger model can start from the smaller one.
+//
+// Each value is tiled (see GrowMatrix): the units of the wider layers are
+// copies of the original ones. Since the tiling of a vector keeps its mean and
+// variance, and the weights of the tiled input units are divided among the
+// copies, the linear layers and the layer normalizations compute the same
+// outputs on the tiled inputs; the function of the model is however only
+// approximately preserved (e.g. the attention heads are split differently).
+//
+// It returns an error, before modifying dst, if a parameter has no source, or
+// its source is larger than it.
+func Grow(dst, src Model, options GrowOptions) error {
+	sourceName := options.SourceName
+	if sourceName == nil {
+		sourceName = func(name string) string { return name }
+	}
+	srcParams := namedParams(src)
+	values := make(map[Param]mat.Matrix)
+	var err error
+	ForEachNamedParam(dst, func(name string, param Param) {
+		if err != nil {
+			return
+		}
+		srcName := sourceName(name)
+		if srcName == "" {
+			return
+		}
+		srcParam, ok := srcParams[srcName]
+		if !ok {
+			err = fmt.Errorf("nn: grow: missing source %#v of parameter %#v", srcName, name)
+			return
+		}
+		rows, cols := param.Value().Dims()
+		if srcParam.Value().Rows() > rows || srcParam.Value().Columns() > cols {
+			err = fmt.Errorf("nn: grow: the source %#v of parameter %#v is larger than it", srcName, name)
+			return
+		}
+		values[param] = GrowMatrix(srcParam.Value(), rows, cols, options)
+	})
+	if err != nil {
+		return err
+	}
+	for param, value := range values {
+		param.ReplaceValue(value)
+	}
+	return nil
+}
+
+// GrowMatrix returns a new rows × cols matrix tiling the given one: the
+// element (i, j) is initialized from the element (i mod m.Rows(), j mod
+// m.Columns()). The matrices (not the vectors) are also divided by the number
+// of times their columns are tiled, so that the product with a tiled vector
+// doesn't change. The noise of the options is added to the result.
+func GrowMatrix(m mat.Matrix, rows, cols int, options GrowOptions) mat.Matrix {
+	srcRows, srcCols := m.Dims()
+	scale := mat.Float(srcCols) / mat.Float(cols)
+	y := mat.NewEmptyDense(rows, cols)
+	yData, data := y.Data(), m.Data()
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			yData[i*cols+j] = data[(i%srcRows)*srcCols+j%srcCols] * scale
+		}
+	}
+	if options.Noise != 0 {
+		dist := normal.New(options.Noise, 0, options.Generator)
+		for i := range yData {
+			yData[i] += dist.Next()
+		}
+	}
+	return y
+}
+
+// DuplicateLayers returns a GrowOptions.SourceName function to grow the depth
+// of a model, initializing the i-th of the dstLen elements of the slice with
+// the given qualified name (e.g. "Layers") from the element i*srcLen/dstLen of
+// the source: each layer is repeated consecutively.
+func DuplicateLayers(name string, srcLen, dstLen int) func(string) string {
+	return mapLayers(name, func(i int) int { return i * srcLen / dstLen })
+}
+
+// StackLayers returns a GrowOptions.SourceName function to grow the depth of
+// a model, initializing the i-th element of the slice with the given
+// qualified name (e.g. "Layers") from the element i mod srcLen of the source:
+// the whole stack of layers is repeated on top of itself.
+func StackLayers(name string, srcLen int) func(string) string {
+	return mapLayers(name, func(i int) int { return i % srcLen })
+}
+
+func mapLayers(name string, index func(i int) int) func(string) string {
+	prefix := name + "."
+	return func(paramName string) string {
+		if !strings.HasPrefix(paramName, prefix) {
+			return paramName
+		}
+		rest := paramName[len(prefix):]
+		end := strings.IndexByte(rest, '.')
+		if end == -1 {
+			end = len(rest)
+		}
+		i, err := strconv.Atoi(rest[:end])
+		if err != nil {
+			return paramName
+		}
+		return prefix + strconv.Itoa(index(i)) + rest[end:]
+	}
+}
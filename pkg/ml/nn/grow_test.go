@@ -0,0 +1,82 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nn
+
+import (
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/mat32/rand"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newWideArithmeticModel(layers, size int) *arithmeticModel {
+	m := &arithmeticModel{}
+	for i := 0; i < layers; i++ {
+		m.Layers = append(m.Layers, &arithmeticLayer{
+			W: NewParam(mat.NewEmptyDense(size, size)),
+			B: NewParam(mat.NewEmptyVecDense(size)),
+		})
+	}
+	return m
+}
+
+func TestGrow(t *testing.T) {
+	src := newArithmeticModel(1, 2)
+	dst := newWideArithmeticModel(4, 4)
+	require.NoError(t, Grow(dst, src, GrowOptions{
+		SourceName: DuplicateLayers("Layers", 2, 4),
+	}))
+	for i, expected := range []mat.Float{1, 1, 2, 2} {
+		assert.InDeltaSlice(t, mat.NewInitDense(4, 4, expected/2).Data(), dst.Layers[i].W.Value().Data(), 1.0e-6)
+		assert.InDeltaSlice(t, mat.NewInitVecDense(4, -expected).Data(), dst.Layers[i].B.Value().Data(), 1.0e-6)
+	}
+
+	t.Run("stacked layers with noise", func(t *testing.T) {
+		dst := newWideArithmeticModel(4, 2)
+		require.NoError(t, Grow(dst, src, GrowOptions{
+			SourceName: StackLayers("Layers", 2),
+			Noise:      0.01,
+			Generator:  rand.NewLockedRand(42),
+		}))
+		for i, expected := range []mat.Float{1, 2, 1, 2} {
+			assert.InDeltaSlice(t, mat.NewInitDense(2, 2, expected).Data(), dst.Layers[i].W.Value().Data(), 0.1)
+			assert.NotEqual(t, mat.NewInitDense(2, 2, expected).Data(), dst.Layers[i].W.Value().Data())
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		dst := newWideArithmeticModel(4, 4)
+		assert.Error(t, Grow(dst, src, GrowOptions{}))
+		assert.Equal(t, mat.NewEmptyDense(4, 4).Data(), dst.Layers[0].W.Value().Data())
+		assert.Error(t, Grow(newArithmeticModel(1, 2), newWideArithmeticModel(2, 4), GrowOptions{}))
+	})
+}
+
+func TestGrowMatrix(t *testing.T) {
+	m := mat.NewDense(2, 2, []mat.Float{
+		1, 2,
+		3, 4,
+	})
+	y := GrowMatrix(m, 3, 4, GrowOptions{})
+	assert.Equal(t, 3, y.Rows())
+	assert.InDeltaSlice(t, []mat.Float{
+		0.5, 1, 0.5, 1,
+		1.5, 2, 1.5, 2,
+		0.5, 1, 0.5, 1,
+	}, y.Data(), 1.0e-6)
+}
+
+func TestStackLayers(t *testing.T) {
+	f := StackLayers("Layers", 2)
+	assert.Equal(t, "Layers.1.W", f("Layers.3.W"))
+	assert.Equal(t, "Layers.0", f("Layers.2"))
+	assert.Equal(t, "Other.3.W", f("Other.3.W"))
+	assert.Equal(t, "Layers.x.W", f("Layers.x.W"))
+
+	g := DuplicateLayers("Encoder.Layers", 3, 6)
+	assert.Equal(t, "Encoder.Layers.2.B", g("Encoder.Layers.5.B"))
+}
@@ -2,12 +2,21 @@
 // Use of this source code is governed by a BSD-style
 // license that can be found in the LICENSE file.
 
+// Package highway implements the highway layers, which learn to mix a
+// transformation of their input with the input itself through a transform
+// gate, so that they can be stacked deeply.
+//
+// Reference: "Highway Networks" by Rupesh Kumar Srivastava, Klaus Greff and
+// Jürgen Schmidhuber (2015) (https://arxiv.org/abs/1505.00387)
 package highway
 
 import (
 	"encoding/gob"
+
 	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/mat32/rand"
 	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/nlpodyssey/spago/pkg/ml/initializers"
 	"github.com/nlpodyssey/spago/pkg/ml/nn"
 )
 
@@ -40,6 +49,19 @@ func New(in int, activation ag.OpName) *Model {
 	}
 }
 
+// Initialize initializes the weights with the Xavier initialization, the
+// biases of the transformation with zeros, and the biases of the transform
+// gate with the given value. A negative value (the reference paper suggests
+// from -1 to -3) initially biases the layer towards carrying its input,
+// which eases the training of deep stacks.
+func (m *Model) Initialize(seed uint64, gateBias mat.Float) {
+	r := rand.NewLockedRand(seed)
+	initializers.XavierUniform(m.WIn.Value(), initializers.Gain(m.Activation), r)
+	initializers.XavierUniform(m.WT.Value(), initializers.Gain(ag.OpSigmoid), r)
+	initializers.Zeros(m.BIn.Value())
+	initializers.Constant(m.BT.Value(), gateBias)
+}
+
 // Forward performs the forward step for each input node and returns the result.
 func (m *Model) Forward(xs ...ag.Node) []ag.Node {
 	ys := make([]ag.Node, len(xs))
@@ -52,6 +52,21 @@ func TestModel_Forward(t *testing.T) {
 	}, model.BT.Grad().Data(), 1.0e-06)
 }
 
+func TestModel_Initialize(t *testing.T) {
+	model := New(4, ag.OpTanh)
+	model.Initialize(42, -2)
+	assert.Equal(t, []mat.Float{0, 0, 0, 0}, model.BIn.Value().Data())
+	assert.Equal(t, []mat.Float{-2, -2, -2, -2}, model.BT.Value().Data())
+	assert.NotEqual(t, 0, model.WIn.Value().Sum())
+	assert.NotEqual(t, 0, model.WT.Value().Sum())
+
+	// the gate mostly carries the input
+	g := ag.NewGraph()
+	x := g.NewVariable(mat.NewVecDense([]mat.Float{0.1, -0.2, 0.3, -0.1}), false)
+	y := nn.ReifyForInference(model, g).(*Model).Forward(x)[0]
+	assert.InDeltaSlice(t, x.Value().Data(), y.Value().Data(), 0.1)
+}
+
 func newTestModel() *Model {
 
 	model := New(4, ag.OpTanh)
@@ -0,0 +1,146 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nn
+
+import (
+	"sync"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+)
+
+// ForwardHook is a function called after the forward of a module, with its
+// qualified name (see ForEachNamedParam), its inputs and its outputs. It is
+// useful to log the activations or to extract the features computed by the
+// intermediate layers (e.g. of a transformer).
+type ForwardHook func(name string, inputs, outputs []ag.Node)
+
+// BackwardHook is a function called during the back-propagation with the
+// gradients of an output of a module, identified by its qualified name. The
+// gradients must not be modified.
+type BackwardHook func(name string, output ag.Node, grad mat.Matrix)
+
+// moduleHook is a hook registered on a module or on one of its ancestors.
+type moduleHook struct {
+	// name is the qualified name of the module, relative to the model the
+	// hook has been registered on.
+	name     string
+	forward  ForwardHook
+	backward BackwardHook
+}
+
+// hookable is implemented by the models embedding BaseModel.
+type hookable interface {
+	baseModel() *BaseModel
+}
+
+func (m *BaseModel) baseModel() *BaseModel {
+	return m
+}
+
+// hooksMu avoids data races between the registration of the hooks and the
+// forward of the modules.
+var hooksMu sync.RWMutex
+
+// hookList returns the hooks of the model.
+func (m *BaseModel) hookList() []*moduleHook {
+	hooksMu.RLock()
+	defer hooksMu.RUnlock()
+	return m.hooks
+}
+
+// RegisterForwardHook registers a hook called after the forward of the model
+// and of each of its sub-models (see ForwardWithHooks), without modifying
+// their code. The hooks registered on a model are inherited by its
+// processors (see Reify). It returns a function removing the hook from the
+// model, and so from the processors reified afterwards.
+func RegisterForwardHook(m Model, hook ForwardHook) (remove func()) {
+	return registerHook(m, func(name string) *moduleHook {
+		return &moduleHook{name: name, forward: hook}
+	})
+}
+
+// RegisterBackwardHook registers a hook called during the back-propagation
+// with the gradients of the outputs of the model and of each of its
+// sub-models, as described by RegisterForwardHook. The gradients are
+// observed through ag.Graph.RegisterGradHook, during the forward.
+func RegisterBackwardHook(m Model, hook BackwardHook) (remove func()) {
+	return registerHook(m, func(name string) *moduleHook {
+		return &moduleHook{name: name, backward: hook}
+	})
+}
+
+func registerHook(m Model, newHook func(name string) *moduleHook) func() {
+	hooksMu.Lock()
+	defer hooksMu.Unlock()
+	added := make(map[*BaseModel]*moduleHook)
+	add := func(name string, sub Model) {
+		if h, ok := sub.(hookable); ok {
+			base := h.baseModel()
+			if _, ok := added[base]; ok {
+				return // shared module
+			}
+			hook := newHook(name)
+			base.hooks = append(base.hooks[:len(base.hooks):len(base.hooks)], hook)
+			added[base] = hook
+		}
+	}
+	add("", m)
+	pt := newParamsTraversal(func(Param) {}, true)
+	pt.modelCallback = add
+	pt.walk(m)
+
+	return func() {
+		hooksMu.Lock()
+		defer hooksMu.Unlock()
+		for base, hook := range added {
+			for i, h := range base.hooks {
+				if h == hook {
+					base.hooks = append(base.hooks[:i:i], base.hooks[i+1:]...)
+					break
+				}
+			}
+		}
+	}
+}
+
+// ForwardWithHooks performs the forward of the model, calling the hooks
+// registered on it (see RegisterForwardHook and RegisterBackwardHook). The
+// generic containers, such as stack.Model, call the forward of their layers
+// through it, so that the hooks don't require changes to the layers.
+func ForwardWithHooks(m StandardModel, xs ...ag.Node) []ag.Node {
+	h, ok := m.(hookable)
+	if !ok {
+		return m.Forward(xs...)
+	}
+	hooks := h.baseModel().hookList()
+	if len(hooks) == 0 {
+		return m.Forward(xs...)
+	}
+
+	ys := m.Forward(xs...)
+	for _, hook := range hooks {
+		if hook.forward != nil {
+			hook.forward(hook.name, xs, ys)
+		}
+		if hook.backward != nil {
+			registerBackwardHook(hook, ys)
+		}
+	}
+	return ys
+}
+
+func registerBackwardHook(hook *moduleHook, ys []ag.Node) {
+	for _, y := range ys {
+		if y == nil || y.Graph() == nil {
+			continue
+		}
+		y := y
+		y.Graph().RegisterGradHook(y, func(grad mat.Matrix) mat.Matrix {
+			hook.backward(hook.name, y, grad)
+			return grad
+		})
+	}
+}
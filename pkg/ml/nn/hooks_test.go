@@ -0,0 +1,90 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nn
+
+import (
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type scaleLayer struct {
+	BaseModel
+	W Param
+}
+
+func (m *scaleLayer) Forward(xs ...ag.Node) []ag.Node {
+	ys := make([]ag.Node, len(xs))
+	for i, x := range xs {
+		ys[i] = m.Graph().ProdScalar(x, m.W)
+	}
+	return ys
+}
+
+type scaleStack struct {
+	BaseModel
+	Layers []StandardModel
+}
+
+func (m *scaleStack) Forward(xs ...ag.Node) []ag.Node {
+	for _, layer := range m.Layers {
+		xs = ForwardWithHooks(layer, xs...)
+	}
+	return xs
+}
+
+func newScaleStack(factors ...mat.Float) *scaleStack {
+	m := &scaleStack{}
+	for _, f := range factors {
+		m.Layers = append(m.Layers, &scaleLayer{W: NewParam(mat.NewScalar(f))})
+	}
+	return m
+}
+
+func TestRegisterForwardHook(t *testing.T) {
+	m := newScaleStack(2, 3)
+	activations := make(map[string]mat.Float)
+	remove := RegisterForwardHook(m, func(name string, inputs, outputs []ag.Node) {
+		require.Len(t, outputs, 1)
+		activations[name] = outputs[0].ScalarValue()
+	})
+
+	g := ag.NewGraph()
+	proc := ReifyForInference(m, g).(*scaleStack)
+	y := ForwardWithHooks(proc, g.NewScalar(1))
+	assert.Equal(t, mat.Float(6), y[0].ScalarValue())
+	assert.Equal(t, map[string]mat.Float{"": 6, "Layers.0": 2, "Layers.1": 6}, activations)
+
+	remove()
+	activations = make(map[string]mat.Float)
+	proc = ReifyForInference(m, g).(*scaleStack)
+	ForwardWithHooks(proc, g.NewScalar(1))
+	assert.Empty(t, activations)
+
+	// registered on a processor
+	RegisterForwardHook(proc.Layers[1], func(name string, inputs, outputs []ag.Node) {
+		activations[name] = inputs[0].ScalarValue()
+	})
+	ForwardWithHooks(proc, g.NewScalar(1))
+	assert.Equal(t, map[string]mat.Float{"": 2}, activations)
+	assert.Empty(t, m.Layers[1].(*scaleLayer).hooks)
+}
+
+func TestRegisterBackwardHook(t *testing.T) {
+	m := newScaleStack(2, 3)
+	grads := make(map[string]mat.Float)
+	RegisterBackwardHook(m, func(name string, output ag.Node, grad mat.Matrix) {
+		grads[name] = grad.Scalar()
+	})
+
+	g := ag.NewGraph()
+	proc := ReifyForTraining(m, g).(*scaleStack)
+	y := ForwardWithHooks(proc, g.NewVariable(mat.NewScalar(1), true))
+	g.Backward(g.ProdScalar(y[0], g.NewScalar(10)))
+	assert.Equal(t, map[string]mat.Float{"": 10, "Layers.0": 30, "Layers.1": 10}, grads)
+}
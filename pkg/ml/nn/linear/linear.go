@@ -22,6 +22,8 @@ type Model struct {
 	nn.BaseModel
 	W nn.Param `spago:"type:weights"`
 	B nn.Param `spago:"type:biases"`
+	// QW is the int8 quantized copy of W used in inference mode, if set (see Quantize).
+	QW *mat.QInt8Dense `spago:"scope:model"`
 }
 
 // Option allows to configure a new Model with your specific needs.
@@ -50,6 +52,19 @@ func New(in, out int, options ...Option) *Model {
 	return model
 }
 
+// Quantize sets QW to the int8 quantization of the current weights, so that
+// the inference runs with the int8 matrix multiplication, dynamically
+// quantizing the input. The training still uses (and updates) W: Quantize
+// must be called again after any change to the weights.
+func (m *Model) Quantize(scheme mat.QuantizationScheme) {
+	m.QW = mat.QuantizeDense(m.W.Value(), scheme)
+}
+
+// Dequantize removes the int8 weights, restoring the full precision inference.
+func (m *Model) Dequantize() {
+	m.QW = nil
+}
+
 // Forward performs the forward step for each input node and returns the result.
 func (m *Model) Forward(xs ...ag.Node) []ag.Node {
 	if len(xs) > 1 && m.Graph().ConcurrentComputations() > 1 {
@@ -82,5 +97,16 @@ func (m *Model) fwdConcurrent(xs []ag.Node) []ag.Node {
 
 // y = w (dot) x + b
 func (m *Model) forward(x ag.Node) ag.Node {
+	if m.QW != nil && m.Mode() == nn.Inference {
+		return m.forwardQuantized(x)
+	}
 	return nn.Affine(m.Graph(), m.B, m.W, x)
 }
+
+// forwardQuantized computes the affine transformation with the int8 weights.
+// The result is a constant node: no gradients are propagated.
+func (m *Model) forwardQuantized(x ag.Node) ag.Node {
+	y := m.QW.MulDense(x.Value())
+	y.AddInPlace(m.B.Value())
+	return m.Graph().NewVariable(y, false)
+}
@@ -62,3 +62,28 @@ func newTestModel() *Model {
 	model.B.Value().SetData([]mat.Float{0.4, 0.0, -0.3, 0.8, -0.4})
 	return model
 }
+
+func TestModel_Quantize(t *testing.T) {
+	model := newTestModel()
+	x := mat.NewVecDense([]mat.Float{-0.8, -0.9, -0.9, 1.0})
+
+	g := ag.NewGraph()
+	expected := nn.ReifyForInference(model, g).(*Model).Forward(g.NewVariable(x, false))[0].Value()
+
+	model.Quantize(mat.PerRow)
+	assert.Equal(t, mat.PerRow, model.QW.Scheme())
+
+	g = ag.NewGraph()
+	y := nn.ReifyForInference(model, g).(*Model).Forward(g.NewVariable(x, false))[0]
+	assert.False(t, y.RequiresGrad())
+	assert.InDeltaSlice(t, expected.Data(), y.Value().Data(), 0.05)
+
+	// the training keeps using the full precision weights
+	g = ag.NewGraph()
+	y = nn.ReifyForTraining(model, g).(*Model).Forward(g.NewVariable(x, true))[0]
+	assert.True(t, y.RequiresGrad())
+	assert.Equal(t, expected.Data(), y.Value().Data())
+
+	model.Dequantize()
+	assert.Nil(t, model.QW)
+}
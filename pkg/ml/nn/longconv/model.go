@@ -0,0 +1,112 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package longconv implements a sequence layer based on long convolutions,
+// in the style of the structured state space (S4) models: each channel of the
+// sequence is convolved with a learned kernel as long as the whole sequence,
+// in the frequency domain (see fn.LongConv).
+//
+// It can replace the self-attention of an encoder for very long sequences,
+// where the quadratic cost of the attention is too expensive on CPU: its cost
+// is O(L log L) for a sequence of length L.
+//
+// Unlike S4, the kernels are learned directly, instead of being generated by
+// a state space model.
+//
+// Reference: "Efficiently Modeling Long Sequences with Structured State
+// Spaces" by Albert Gu, Karan Goel and Christopher Ré (2021)
+// (https://arxiv.org/abs/2111.00396)
+package longconv
+
+import (
+	"encoding/gob"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/mat32/rand"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/nlpodyssey/spago/pkg/ml/initializers"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/ml/nn/activation"
+	"github.com/nlpodyssey/spago/pkg/ml/nn/linear"
+)
+
+var (
+	_ nn.Model = &Model{}
+)
+
+// Config provides configuration parameters for Model.
+type Config struct {
+	// Size is the size of the input and output vectors (the channels).
+	Size int
+	// MaxLength is the length of the kernels, i.e. the maximum length of the sequences.
+	MaxLength int
+	// Activation is applied to the convolutions, before the output projection.
+	Activation ag.OpName
+}
+
+// Model contains the serializable parameters.
+type Model struct {
+	nn.BaseModel
+	Config Config
+	// Kernel contains the kernel of each channel in a row.
+	Kernel nn.Param `spago:"type:weights"`
+	// D contains the weights of the skip connection of each channel.
+	D      nn.Param `spago:"type:weights"`
+	Act    *activation.Model
+	Output *linear.Model
+}
+
+func init() {
+	gob.Register(&Model{})
+}
+
+// New returns a new Model initialized to zeros.
+func New(config Config) *Model {
+	return &Model{
+		Config: config,
+		Kernel: nn.NewParam(mat.NewEmptyDense(config.Size, config.MaxLength)),
+		D:      nn.NewParam(mat.NewEmptyVecDense(config.Size)),
+		Act:    activation.New(config.Activation),
+		Output: linear.New(config.Size, config.Size),
+	}
+}
+
+// Initialize initializes the kernels with random values decaying
+// exponentially, at rates spaced geometrically from 1/MaxLength (for the
+// channels capturing the longest dependencies) to 1 (for the most local
+// ones), the skip connections with ones, and the output projection with the
+// Xavier initialization.
+func (m *Model) Initialize(seed uint64) {
+	r := rand.NewLockedRand(seed)
+	size, length := m.Config.Size, m.Config.MaxLength
+	kernel := m.Kernel.Value()
+	initializers.Normal(kernel, 0, 1, r)
+	for c := 0; c < size; c++ {
+		exponent := mat.Float(1)
+		if size > 1 {
+			exponent = 1 - mat.Float(c)/mat.Float(size-1)
+		}
+		rate := mat.Pow(1/mat.Float(length), exponent)
+		for s := 0; s < length; s++ {
+			kernel.Set(c, s, kernel.At(c, s)*mat.Exp(-rate*mat.Float(s)))
+		}
+	}
+	initializers.Constant(m.D.Value(), 1)
+	initializers.XavierUniform(m.Output.W.Value(), 1, r)
+}
+
+// Forward performs the forward step for each input node and returns the result.
+func (m *Model) Forward(xs ...ag.Node) []ag.Node {
+	if len(xs) == 0 {
+		return nil
+	}
+	g := m.Graph()
+	conv := g.LongConv(g.T(g.Stack(xs...)), m.Kernel) // channels × length
+	ys := make([]ag.Node, len(xs))
+	for t, x := range xs {
+		h := g.Add(g.T(g.ColView(conv, t)), g.Prod(m.D, x))
+		ys[t] = m.Output.Forward(m.Act.Forward(h)...)[0]
+	}
+	return ys
+}
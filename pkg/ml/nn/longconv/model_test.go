@@ -0,0 +1,95 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package longconv
+
+import (
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/mat32/rand"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/nlpodyssey/spago/pkg/ml/ag/gradcheck"
+	"github.com/nlpodyssey/spago/pkg/ml/initializers"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestModel() *Model {
+	model := New(Config{
+		Size:       3,
+		MaxLength:  8,
+		Activation: ag.OpTanh,
+	})
+	model.Initialize(42)
+	return model
+}
+
+func newTestSequence(length int) []mat.Matrix {
+	r := rand.NewLockedRand(7)
+	xs := make([]mat.Matrix, length)
+	for i := range xs {
+		xs[i] = mat.NewEmptyVecDense(3)
+		initializers.Uniform(xs[i], -1, 1, r)
+	}
+	return xs
+}
+
+func TestModel_Initialize(t *testing.T) {
+	model := newTestModel()
+	assert.Equal(t, 3, model.Kernel.Value().Rows())
+	assert.Equal(t, 8, model.Kernel.Value().Columns())
+	assert.Equal(t, []mat.Float{1, 1, 1}, model.D.Value().Data())
+
+	// the last channel decays at rate 1, the first one at rate 1/8
+	assert.Less(t, float64(mat.Abs(model.Kernel.Value().At(2, 7))), 0.01)
+	assert.Greater(t, float64(mat.Abs(model.Kernel.Value().At(0, 7))), 0.01)
+}
+
+func TestModel_Forward(t *testing.T) {
+	model := newTestModel()
+	forward := func(xs []mat.Matrix) []ag.Node {
+		g := ag.NewGraph()
+		proc := nn.ReifyForInference(model, g).(*Model)
+		return proc.Forward(variables(g, xs)...)
+	}
+	xs := newTestSequence(5)
+	ys := forward(xs)
+	require.Len(t, ys, 5)
+	assert.Equal(t, 3, ys[0].Value().Rows())
+	assert.Equal(t, 1, ys[0].Value().Columns())
+
+	// the convolution is causal: the outputs don't depend on the following inputs
+	xs2 := newTestSequence(5)
+	xs2[4] = mat.NewVecDense([]mat.Float{9, 9, 9})
+	ys2 := forward(xs2)
+	for t2 := 0; t2 < 4; t2++ {
+		assert.InDeltaSlice(t, ys[t2].Value().Data(), ys2[t2].Value().Data(), 1.0e-6)
+	}
+	assert.NotEqual(t, ys[4].Value().Data(), ys2[4].Value().Data())
+
+	assert.Nil(t, forward(nil))
+	assert.Panics(t, func() { forward(newTestSequence(9)) })
+}
+
+func TestModel_Gradients(t *testing.T) {
+	model := newTestModel()
+	xs := newTestSequence(5)
+	err := gradcheck.CheckModelGradients(model, func(proc nn.Model) ag.Node {
+		m := proc.(*Model)
+		g := m.Graph()
+		ys := m.Forward(variables(g, xs)...)
+		return g.ReduceSum(g.Square(g.Concat(ys...)))
+	}, 1e-3, 1e-2)
+	assert.NoError(t, err)
+}
+
+func variables(g *ag.Graph, xs []mat.Matrix) []ag.Node {
+	nodes := make([]ag.Node, len(xs))
+	for i, x := range xs {
+		nodes[i] = g.NewVariable(x, false)
+	}
+	return nodes
+}
@@ -59,6 +59,7 @@ type StandardModel interface {
 
 // Reify returns a new "reified" model (a.k.a. processor) to execute the forward step.
 func Reify(m Model, g *ag.Graph, mode ProcessingMode) Model {
+	restorePendingTies(m)
 	return newReifier(g, mode).reify(m)
 }
 
@@ -84,6 +85,13 @@ func ForEachParamStrict(m Model, callback func(param Param)) {
 	newParamsTraversal(callback, false).walk(m)
 }
 
+// ForEachNamedParam iterate all the parameters of a model also exploring the sub-parameters recursively,
+// passing the qualified name of each one: the names of the fields, the indices of the slices and the
+// keys of the maps leading to it, separated by dots (e.g. "Layers.0.W").
+func ForEachNamedParam(m Model, callback func(name string, param Param)) {
+	newNamedParamsTraversal(callback, true).walk(m)
+}
+
 // ZeroGrad set the gradients of all model's parameters (including sub-params) to zeros.
 func ZeroGrad(m Model) {
 	ForEachParam(m, func(param Param) {
@@ -99,9 +107,10 @@ func ClearSupport(m Model) {
 }
 
 // DumpParamsVector dumps all params of a Model into a single Dense vector.
+// The tied params are dumped once (see ForEachDistinctParam).
 func DumpParamsVector(model Model) mat.Matrix {
 	data := make([]mat.Float, 0)
-	ForEachParam(model, func(param Param) {
+	ForEachDistinctParam(model, func(param Param) {
 		data = append(data, param.Value().Data()...)
 	})
 	return mat.NewVecDense(data)
@@ -111,7 +120,7 @@ func DumpParamsVector(model Model) mat.Matrix {
 func LoadParamsVector(model Model, vector mat.Matrix) {
 	data := vector.Data()
 	offset := 0
-	ForEachParam(model, func(param Param) {
+	ForEachDistinctParam(model, func(param Param) {
 		size := param.Value().Size()
 		param.Value().SetData(data[offset : offset+size])
 		offset += size
@@ -14,6 +14,7 @@ import (
 	mat "github.com/nlpodyssey/spago/pkg/mat32"
 	"github.com/nlpodyssey/spago/pkg/ml/ag"
 	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/ml/numerics"
 )
 
 var (
@@ -49,7 +50,7 @@ func New(scale mat.Float) *Model {
 func (m *Model) InitProcessor() {
 	g := m.Graph()
 	m.consts = consts{
-		eps: g.Constant(1e-10),
+		eps: g.Constant(numerics.Current().Norm),
 		one: g.Constant(1.0),
 		k:   g.Constant(0.1),
 		c:   g.Constant(m.Scale),
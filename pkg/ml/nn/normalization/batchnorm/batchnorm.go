@@ -9,6 +9,7 @@ import (
 	mat "github.com/nlpodyssey/spago/pkg/mat32"
 	"github.com/nlpodyssey/spago/pkg/ml/ag"
 	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/ml/numerics"
 )
 
 var (
@@ -25,7 +26,6 @@ type Model struct {
 	Momentum nn.Param `spago:"type:undefined"`
 }
 
-const epsilon = 1e-5
 const defaultMomentum = 0.9
 
 func init() {
@@ -35,7 +35,7 @@ func init() {
 // NewWithMomentum returns a new model with supplied size and momentum.
 func NewWithMomentum(size int, momentum mat.Float) *Model {
 	return &Model{
-		W:        nn.NewParam(mat.NewInitVecDense(size,epsilon)),
+		W:        nn.NewParam(mat.NewInitVecDense(size, numerics.Current().BatchNorm)),
 		B:        nn.NewParam(mat.NewEmptyVecDense(size)),
 		Mean:     nn.NewParam(mat.NewEmptyVecDense(size), nn.RequiresGrad(false)),
 		StdDev:   nn.NewParam(mat.NewEmptyVecDense(size), nn.RequiresGrad(false)),
@@ -65,7 +65,7 @@ func (m *Model) forwardTraining(xs []ag.Node) []ag.Node {
 }
 
 func (m *Model) process(g *ag.Graph, xs []ag.Node, devVector ag.Node, meanVector ag.Node) []ag.Node {
-	devVector = g.Div(m.W, g.AddScalar(devVector, g.NewScalar(epsilon)))
+	devVector = g.Div(m.W, g.AddScalar(devVector, g.NewScalar(numerics.Current().BatchNorm)))
 	ys := make([]ag.Node, len(xs))
 	for i, x := range xs {
 		ys[i] = g.Add(g.Prod(g.Sub(x, meanVector), devVector), m.B)
@@ -98,7 +98,7 @@ func (m *Model) mean(xs []ag.Node) ag.Node {
 		sumVector = g.Add(sumVector, xs[i])
 	}
 
-	return g.DivScalar(sumVector, g.NewScalar(mat.Float(len(xs))+epsilon))
+	return g.DivScalar(sumVector, g.NewScalar(mat.Float(len(xs))+numerics.Current().BatchNorm))
 }
 
 // StdDev computes the standard deviation of the input.
@@ -109,6 +109,6 @@ func (m *Model) stdDev(meanVector ag.Node, xs []ag.Node) ag.Node {
 		diffVector := g.Square(g.Sub(meanVector, x))
 		devVector = g.Add(devVector, diffVector)
 	}
-	devVector = g.Sqrt(g.DivScalar(devVector, g.NewScalar(mat.Float(len(xs))+epsilon)))
+	devVector = g.Sqrt(g.DivScalar(devVector, g.NewScalar(mat.Float(len(xs))+numerics.Current().BatchNorm)))
 	return devVector
 }
@@ -12,6 +12,7 @@ import (
 	"encoding/gob"
 	"github.com/nlpodyssey/spago/pkg/ml/ag"
 	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/ml/numerics"
 )
 
 var (
@@ -36,7 +37,7 @@ func New() *Model {
 func (m *Model) Forward(xs ...ag.Node) []ag.Node {
 	g := m.Graph()
 	ys := make([]ag.Node, len(xs))
-	eps := g.NewScalar(1e-10)
+	eps := g.NewScalar(numerics.Current().Norm)
 	for i, x := range xs {
 		norm := g.Sqrt(g.ReduceSum(g.Square(x)))
 		ys[i] = g.DivScalar(x, g.AddScalar(norm, eps))
@@ -13,6 +13,7 @@ import (
 	mat "github.com/nlpodyssey/spago/pkg/mat32"
 	"github.com/nlpodyssey/spago/pkg/ml/ag"
 	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/ml/numerics"
 )
 
 var (
@@ -40,15 +41,13 @@ func New(size int) *Model {
 
 // Forward performs the forward step for each input node and returns the result.
 // y = (x - E\[x\]) / sqrt(VAR\[x\] + [EPS]) * g + b
+// EPS is the LayerNorm tolerance of the numerics policy in use.
 func (m *Model) Forward(xs ...ag.Node) []ag.Node {
 	g := m.Graph()
-	eps := g.Constant(1e-12) // avoid underflow errors
+	eps := g.Constant(numerics.Current().LayerNorm) // avoid underflow errors
 	ys := make([]ag.Node, len(xs))
 	for i, x := range xs {
-		mean := g.ReduceMean(x)
-		dev := g.SubScalar(x, mean)
-		stdDev := g.Sqrt(g.Add(g.ReduceMean(g.Square(dev)), eps))
-		ys[i] = g.Add(g.Prod(g.DivScalar(dev, stdDev), m.W), m.B)
+		ys[i] = g.LayerNorm(x, m.W, m.B, eps)
 	}
 	return ys
 }
@@ -8,6 +8,7 @@ import (
 	mat "github.com/nlpodyssey/spago/pkg/mat32"
 	"github.com/nlpodyssey/spago/pkg/ml/ag"
 	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/ml/numerics"
 	"github.com/stretchr/testify/assert"
 	"testing"
 )
@@ -31,6 +32,21 @@ func TestModel_Forward(t *testing.T) {
 	assert.InDeltaSlice(t, []mat.Float{-1.0, -0.2, 0.4, 0.6}, model.B.Grad().Data(), 1.0e-06)
 }
 
+func TestModel_ForwardWithNumericsPolicy(t *testing.T) {
+	policy := numerics.Default()
+	policy.LayerNorm = 0.615 // the variance of x is 0.385
+	previous := numerics.Set(policy)
+	defer numerics.Set(previous)
+
+	model := newTestModel()
+	g := ag.NewGraph()
+	x := g.NewVariable(mat.NewVecDense([]mat.Float{0.4, 0.8, -0.7, -0.5}), true)
+	y := nn.ToNode(nn.ReifyForInference(model, g).(*Model).Forward(x))
+
+	// (x - 0) / sqrt(0.385 + 0.615) * w + b
+	assert.InDeltaSlice(t, []mat.Float{1.06, 0.2, -0.69, -0.2}, y.Value().Data(), 1.0e-06)
+}
+
 func newTestModel() *Model {
 	model := New(4)
 	model.W.Value().SetData([]mat.Float{0.4, 0.0, -0.3, 0.8})
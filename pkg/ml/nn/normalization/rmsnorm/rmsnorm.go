@@ -13,6 +13,7 @@ import (
 	mat "github.com/nlpodyssey/spago/pkg/mat32"
 	"github.com/nlpodyssey/spago/pkg/ml/ag"
 	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/ml/numerics"
 )
 
 var (
@@ -41,7 +42,7 @@ func New(size int) *Model {
 // Forward performs the forward step for each input node and returns the result.
 func (m *Model) Forward(xs ...ag.Node) []ag.Node {
 	g := m.Graph()
-	eps := g.Constant(1e-10)
+	eps := g.Constant(numerics.Current().Norm)
 	ys := make([]ag.Node, len(xs))
 	for i, x := range xs {
 		rms := g.Sqrt(g.ReduceMean(g.Square(x)))
@@ -9,6 +9,7 @@ import (
 	mat "github.com/nlpodyssey/spago/pkg/mat32"
 	"github.com/nlpodyssey/spago/pkg/ml/ag"
 	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/ml/numerics"
 )
 
 var (
@@ -35,7 +36,7 @@ func New(size int) *Model {
 // Forward performs the forward step for each input node and returns the result.
 func (m *Model) Forward(xs ...ag.Node) []ag.Node {
 	g := m.Graph()
-	eps := g.Constant(1e-10)
+	eps := g.Constant(numerics.Current().Norm)
 	ys := make([]ag.Node, len(xs))
 	for i, x := range xs {
 		norm := g.Sqrt(g.ReduceSum(g.Square(x)))
@@ -14,6 +14,11 @@ import (
 )
 
 // Param is the interface for a Model parameter.
+//
+// The matrix returned by Value() can be updated in place (e.g. by the
+// initializers), also when the Param is stored in half precision: in that
+// case, it is a decoded copy encoded back when the Param is reified, updated
+// or marshaled (see HalfPrecision).
 type Param interface {
 	ag.Node // it implies fn.Operand and ag.GradValue too
 
@@ -63,7 +68,13 @@ type param struct {
 	payload      *Payload   // additional data used for example by gradient-descend optimization methods
 	hasGrad      bool
 	requiresGrad bool
-	storage      *kvdb.KeyValueDB // default nil
+	storage      *kvdb.KeyValueDB  // default nil
+	half         *mat.Float16Dense // default nil; if set, it replaces value
+	decoded      *mat.Dense        // the decoded half, while accessed through Value() (see HalfPrecision)
+	halfMu       sync.Mutex        // guards half and decoded
+	tiedTo       *param            // default nil; if set, its storage is used (see TieWeights)
+	tieKey       uint64            // identifies the tied params once serialized (zero if not tied)
+	tiePending   bool              // true if decoded without the param it is tied to (see RestoreTiedWeights)
 }
 
 // ParamOption allows to configure a new Param with your specific needs.
@@ -85,6 +96,49 @@ func SetStorage(storage *kvdb.KeyValueDB) ParamOption {
 	}
 }
 
+// HalfPrecision is an option to store the value of a Param in a 16-bit
+// floating-point format, halving the memory of the value at rest; the binary
+// marshaling keeps the 16-bit format.
+//
+// The value is decoded on the fly when accessed, into a full precision copy:
+// a reified Param decodes it once per graph, and the copy lives as long as
+// the graph. Param.Value() decodes it on the first call and keeps the
+// decoded matrix, so that the in-place updates made through it (e.g. by the
+// initializers) are not lost. The decoded matrix is encoded back into half
+// precision, and released, when the Param is reified, updated with
+// ApplyDelta, marshaled, or converted again with ConvertToHalfPrecision:
+// until then, the Param takes more memory than in full precision.
+func HalfPrecision(format mat.HalfPrecision) ParamOption {
+	return func(p *param) {
+		p.halfMu.Lock()
+		defer p.halfMu.Unlock()
+		var value mat.Matrix = p.value
+		if p.half != nil {
+			p.flushHalf()
+			value = p.half.Decode()
+		}
+		if value == nil {
+			return
+		}
+		p.half = mat.NewFloat16Dense(value, format)
+		p.value = nil
+	}
+}
+
+// ConvertToHalfPrecision applies the HalfPrecision option to all the params of
+// the model, e.g. after loading a pre-trained model to be used for inference.
+// The params already in half precision are converted to the given format,
+// releasing their decoded values.
+func ConvertToHalfPrecision(m Model, format mat.HalfPrecision) {
+	ForEachParam(m, func(p Param) {
+		if p, ok := p.(*param); ok {
+			p.mu.Lock()
+			HalfPrecision(format)(p)
+			p.mu.Unlock()
+		}
+	})
+}
+
 // NewParam returns a new param.
 func NewParam(value mat.Matrix, opts ...ParamOption) Param {
 	p := &param{
@@ -124,15 +178,29 @@ func (r *param) Type() ParamsType {
 }
 
 // Value returns the value of the delegate itself.
+// If the param is stored in half precision, the decoded value is returned,
+// decoding it on the first call (see HalfPrecision).
 func (r *param) Value() mat.Matrix {
+	if r.tiedTo != nil {
+		return r.root().Value()
+	}
+	if r.half != nil {
+		r.halfMu.Lock()
+		defer r.halfMu.Unlock()
+		return r.decodeHalf()
+	}
 	return r.value
 }
 
 // ReplaceValue replaces the value of the parameter and clears the support structure.
 func (r *param) ReplaceValue(value mat.Matrix) {
+	if r.tiedTo != nil {
+		r.root().ReplaceValue(value)
+		return
+	}
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	r.value = value
+	r.setValue(value)
 	r.payload = nil
 	if r.storage != nil {
 		r.updateStorage()
@@ -143,23 +211,30 @@ func (r *param) ReplaceValue(value mat.Matrix) {
 // It panics if the value is not a scalar.
 // Note that it is not possible to start the backward step from a scalar value.
 func (r *param) ScalarValue() mat.Float {
-	return r.value.Scalar()
+	return r.Value().Scalar()
 }
 
 // Grad returns the gradients accumulated during the backward pass.
 func (r *param) Grad() mat.Matrix {
+	if r.tiedTo != nil {
+		return r.root().Grad()
+	}
 	return r.grad
 }
 
 // PropagateGrad accumulate the gradients
 func (r *param) PropagateGrad(grad mat.Matrix) {
+	if r.tiedTo != nil {
+		r.root().PropagateGrad(grad)
+		return
+	}
 	if !r.requiresGrad {
 		return
 	}
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	if r.grad == nil {
-		r.grad = mat.GetEmptyDenseWorkspace(r.value.Dims()) // this could reduce the number of allocations
+		r.grad = mat.GetEmptyDenseWorkspace(r.dims()) // this could reduce the number of allocations
 	}
 	r.grad.AddInPlace(grad)
 	r.hasGrad = true
@@ -167,21 +242,35 @@ func (r *param) PropagateGrad(grad mat.Matrix) {
 
 // HasGrad returns true if there are accumulated gradients.
 func (r *param) HasGrad() bool {
+	if r.tiedTo != nil {
+		return r.root().HasGrad()
+	}
 	return r.hasGrad
 }
 
 // RequiresGrad returns true if the param requires gradients.
 func (r *param) RequiresGrad() bool {
+	if r.tiedTo != nil {
+		return r.root().RequiresGrad()
+	}
 	return r.requiresGrad
 }
 
 // SetRequiresGrad is an option to specify whether a Param should be trained or not.
 func (r *param) SetRequiresGrad(value bool) {
+	if r.tiedTo != nil {
+		r.root().SetRequiresGrad(value)
+		return
+	}
 	r.requiresGrad = value
 }
 
 // ZeroGrad clears the gradients.
 func (r *param) ZeroGrad() {
+	if r.tiedTo != nil {
+		r.root().ZeroGrad()
+		return
+	}
 	if r.grad == nil {
 		return
 	}
@@ -194,9 +283,20 @@ func (r *param) ZeroGrad() {
 
 // ApplyDelta updates the value of the underlying storage applying the delta.
 func (r *param) ApplyDelta(delta mat.Matrix) {
+	if r.tiedTo != nil {
+		r.root().ApplyDelta(delta)
+		return
+	}
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	r.Value().SubInPlace(delta)
+	if r.half != nil {
+		r.halfMu.Lock()
+		r.decodeHalf().SubInPlace(delta)
+		r.flushHalf()
+		r.halfMu.Unlock()
+	} else {
+		r.value.SubInPlace(delta)
+	}
 	if r.storage != nil {
 		r.updateStorage()
 	}
@@ -204,6 +304,9 @@ func (r *param) ApplyDelta(delta mat.Matrix) {
 
 // Payload returns the optimizer support structure (can be nil).
 func (r *param) Payload() *Payload {
+	if r.tiedTo != nil {
+		return r.root().Payload()
+	}
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	return r.payload
@@ -212,6 +315,10 @@ func (r *param) Payload() *Payload {
 // SetPayload is a thread safe operation to set the given Payload on the
 // receiver Param.
 func (r *param) SetPayload(payload *Payload) {
+	if r.tiedTo != nil {
+		r.root().SetPayload(payload)
+		return
+	}
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	r.payload = payload
@@ -222,6 +329,10 @@ func (r *param) SetPayload(payload *Payload) {
 
 // ClearPayload clears the support structure.
 func (r *param) ClearPayload() {
+	if r.tiedTo != nil {
+		r.root().ClearPayload()
+		return
+	}
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	r.payload = nil
@@ -230,6 +341,57 @@ func (r *param) ClearPayload() {
 	}
 }
 
+// root returns the param whose storage is used by the receiver: the param it
+// is tied to (see TieWeights), or the receiver itself.
+func (r *param) root() *param {
+	for r.tiedTo != nil {
+		r = r.tiedTo
+	}
+	return r
+}
+
+// setValue sets the value, keeping the half precision storage if enabled.
+func (r *param) setValue(value mat.Matrix) {
+	if r.half != nil {
+		r.halfMu.Lock()
+		defer r.halfMu.Unlock()
+		r.half = mat.NewFloat16Dense(value, r.half.Format())
+		r.decoded = nil
+		return
+	}
+	r.value = value
+}
+
+// decodeHalf returns the decoded value of the half precision storage,
+// decoding it if needed. It must be called with halfMu locked.
+func (r *param) decodeHalf() *mat.Dense {
+	if r.decoded == nil {
+		r.decoded = r.half.Decode()
+	}
+	return r.decoded
+}
+
+// flushHalf encodes the decoded value, if any, back into the half precision
+// storage, and releases it. It must be called with halfMu locked.
+func (r *param) flushHalf() {
+	if r.decoded == nil {
+		return
+	}
+	r.half = mat.NewFloat16Dense(r.decoded, r.half.Format())
+	r.decoded = nil
+}
+
+// dims returns the dimensions of the value, without decoding it.
+func (r *param) dims() (rows, cols int) {
+	if r.tiedTo != nil {
+		return r.root().dims()
+	}
+	if r.half != nil {
+		return r.half.Dims()
+	}
+	return r.value.Dims()
+}
+
 func (r *param) updateStorage() {
 	if r.storage == nil {
 		return
@@ -263,11 +425,39 @@ func (r *param) TimeStep() int {
 }
 
 // wrappedParam returns a new wrappedParam from the param itself.
+// If the param is stored in half precision, its value is decoded once here,
+// after encoding back the updates made through Param.Value().
+// The params tied to another one are wrapped around the storage of the latter,
+// so that the gradients are accumulated into it.
 func (r *param) wrappedParam(g *ag.Graph) *wrappedParam {
-	if r.requiresGrad {
-		return &wrappedParam{param: r, Node: g.NewWrap(r)}
+	root := r.root()
+	var gv ag.GradValue = root
+	if root.half != nil {
+		root.halfMu.Lock()
+		root.flushHalf()
+		gv = &decodedParam{param: root, value: root.half.Decode()}
+		root.halfMu.Unlock()
 	}
-	return &wrappedParam{param: r, Node: g.NewWrapNoGrad(r)}
+	if root.requiresGrad {
+		return &wrappedParam{param: r, Node: g.NewWrap(gv)}
+	}
+	return &wrappedParam{param: r, Node: g.NewWrapNoGrad(gv)}
+}
+
+// decodedParam is a param stored in half precision, along with its decoded value.
+type decodedParam struct {
+	*param
+	value mat.Matrix
+}
+
+// Value returns the decoded value.
+func (r *decodedParam) Value() mat.Matrix {
+	return r.value
+}
+
+// ScalarValue returns the the scalar value of the node.
+func (r *decodedParam) ScalarValue() mat.Float {
+	return r.value.Scalar()
 }
 
 var _ Param = &wrappedParam{}
@@ -278,6 +468,16 @@ type wrappedParam struct {
 	Node ag.Node
 }
 
+// Value dispatches the call to the Node.
+func (r *wrappedParam) Value() mat.Matrix {
+	return r.Node.Value()
+}
+
+// ScalarValue dispatches the call to the Node.
+func (r *wrappedParam) ScalarValue() mat.Float {
+	return r.Node.ScalarValue()
+}
+
 // ID dispatches the call to the Node.
 func (r *wrappedParam) ID() int {
 	return r.Node.ID()
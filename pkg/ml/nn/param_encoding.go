@@ -13,6 +13,7 @@ import (
 	"io"
 	"io/ioutil"
 	"log"
+	"sync/atomic"
 )
 
 // init registers the param implementation with the gob subsystem - so that it knows how to encode and decode
@@ -21,12 +22,36 @@ func init() {
 	gob.Register(&param{})
 }
 
+// The params sharing the same storage (see TieWeights) are marshaled with the
+// key of the tie, preceded by one of these markers, and the values stored in
+// half precision (see HalfPrecision) are preceded by binaryHalfValue. None of
+// them can be confused with the type of the matrix the other values start with.
+const (
+	binaryHalfValue byte = 0xfd
+	binaryTieOwner  byte = 0xfe
+	binaryTiedParam byte = 0xff
+)
+
 // MarshalBinary marshals a param into binary form.
+// A param tied to another one is marshaled without its value, and a param
+// stored in half precision is marshaled in its 16-bit format, without
+// decoding it.
 func (r *param) MarshalBinary() ([]byte, error) {
 	buf := new(bytes.Buffer)
 
-	err := mat.MarshalBinaryMatrix(r.value, buf)
-	if err != nil {
+	if r.tieKey != 0 {
+		key := make([]byte, 8)
+		binary.LittleEndian.PutUint64(key, r.tieKey)
+		if r.tiedTo != nil || r.tiePending {
+			buf.WriteByte(binaryTiedParam)
+			buf.Write(key)
+			return buf.Bytes(), nil
+		}
+		buf.WriteByte(binaryTieOwner)
+		buf.Write(key)
+	}
+
+	if err := r.marshalBinaryValue(buf); err != nil {
 		return nil, err
 	}
 
@@ -49,8 +74,20 @@ func (r *param) UnmarshalBinary(data []byte) error {
 	var err error
 	buf := bytes.NewReader(data)
 
-	r.value, err = mat.UnmarshalBinaryMatrix(buf)
-	if err != nil {
+	if len(data) > 0 && (data[0] == binaryTieOwner || data[0] == binaryTiedParam) {
+		if len(data) < 9 {
+			return fmt.Errorf("nn: invalid tied param encoding")
+		}
+		r.tieKey = binary.LittleEndian.Uint64(data[1:9])
+		if data[0] == binaryTiedParam {
+			r.tiePending = true
+			atomic.AddInt64(&pendingTies, 1)
+			return nil
+		}
+		buf = bytes.NewReader(data[9:])
+	}
+
+	if err := r.unmarshalBinaryValue(buf); err != nil {
 		return err
 	}
 
@@ -70,6 +107,66 @@ func (r *param) UnmarshalBinary(data []byte) error {
 	return r.payload.UnmarshalBinary(pBin)
 }
 
+// marshalBinaryValue writes the value, or its half precision storage preceded
+// by binaryHalfValue, after encoding back the updates made through Value().
+func (r *param) marshalBinaryValue(w *bytes.Buffer) error {
+	if r.half == nil {
+		return mat.MarshalBinaryMatrix(r.Value(), w)
+	}
+	r.halfMu.Lock()
+	r.flushHalf()
+	half := r.half
+	r.halfMu.Unlock()
+
+	bin, err := half.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	binLen := make([]byte, 4)
+	binary.LittleEndian.PutUint32(binLen, uint32(len(bin)))
+	w.WriteByte(binaryHalfValue)
+	w.Write(binLen)
+	w.Write(bin)
+	return nil
+}
+
+// unmarshalBinaryValue reads the value written by marshalBinaryValue,
+// restoring the half precision storage if it was stored in half precision.
+func (r *param) unmarshalBinaryValue(buf *bytes.Reader) error {
+	marker, err := buf.ReadByte()
+	if err != nil {
+		return err
+	}
+	if marker != binaryHalfValue {
+		_ = buf.UnreadByte() // it's the type of the matrix
+		value, err := mat.UnmarshalBinaryMatrix(buf)
+		if err != nil {
+			return err
+		}
+		r.setValue(value)
+		return nil
+	}
+
+	binLenBytes := make([]byte, 4)
+	if _, err = io.ReadFull(buf, binLenBytes); err != nil {
+		return err
+	}
+	bin := make([]byte, binary.LittleEndian.Uint32(binLenBytes))
+	if _, err = io.ReadFull(buf, bin); err != nil {
+		return err
+	}
+	half := new(mat.Float16Dense)
+	if err = half.UnmarshalBinary(bin); err != nil {
+		return err
+	}
+	r.halfMu.Lock()
+	defer r.halfMu.Unlock()
+	r.half = half
+	r.decoded = nil
+	r.value = nil
+	return nil
+}
+
 // MarshalBinaryParam encodes a Param into binary form.
 func MarshalBinaryParam(p Param, w io.Writer) error {
 	if p == nil {
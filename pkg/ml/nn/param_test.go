@@ -0,0 +1,116 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nn
+
+import (
+	"bytes"
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHalfPrecision(t *testing.T) {
+	for _, format := range []mat.HalfPrecision{mat.Float16, mat.BFloat16} {
+		t.Run(format.String(), func(t *testing.T) {
+			p := NewParam(mat.NewVecDense([]mat.Float{0.5, -1, 2}), HalfPrecision(format)).(*param)
+			assert.Nil(t, p.value)
+			assert.NotNil(t, p.half)
+			assert.Equal(t, []mat.Float{0.5, -1, 2}, p.Value().Data())
+
+			p.ApplyDelta(mat.NewVecDense([]mat.Float{0.5, 1, 1}))
+			assert.Equal(t, []mat.Float{0, -2, 1}, p.Value().Data())
+			assert.NotNil(t, p.half)
+
+			p.PropagateGrad(mat.NewVecDense([]mat.Float{1, 2, 3}))
+			assert.Equal(t, []mat.Float{1, 2, 3}, p.Grad().Data())
+
+			p.ReplaceValue(mat.NewVecDense([]mat.Float{4, 5, 6}))
+			assert.Equal(t, []mat.Float{4, 5, 6}, p.Value().Data())
+			assert.Equal(t, format, p.half.Format())
+		})
+	}
+}
+
+func TestHalfPrecision_Reified(t *testing.T) {
+	type TestModel struct {
+		BaseModelTest
+		W Param
+	}
+	m := &TestModel{W: NewParam(mat.NewVecDense([]mat.Float{1, 2, 3}))}
+	ConvertToHalfPrecision(m, mat.BFloat16)
+	assert.NotNil(t, m.W.(*param).half)
+
+	g := ag.NewGraph()
+	proc := ReifyForInference(m, g).(*TestModel)
+	value := proc.W.Value()
+	assert.Equal(t, []mat.Float{1, 2, 3}, value.Data())
+	assert.Same(t, value, proc.W.Value()) // decoded only once per graph
+
+	y := g.ProdScalar(proc.W, g.NewScalar(2))
+	assert.Equal(t, []mat.Float{2, 4, 6}, y.Value().Data())
+}
+
+func TestHalfPrecision_InPlaceUpdates(t *testing.T) {
+	type TestModel struct {
+		BaseModelTest
+		W Param
+	}
+	m := &TestModel{W: NewParam(mat.NewVecDense([]mat.Float{1, 2, 3}), HalfPrecision(mat.Float16))}
+	p := m.W.(*param)
+
+	// the decoded value is kept, along with its in-place updates
+	m.W.Value().SetData([]mat.Float{4, 5, 6})
+	m.W.Value().Data()[0] = 7
+	assert.Same(t, m.W.Value(), m.W.Value())
+	assert.Equal(t, []mat.Float{7, 5, 6}, m.W.Value().Data())
+
+	// and encoded back when reified, releasing the decoded value
+	proc := ReifyForInference(m, ag.NewGraph()).(*TestModel)
+	assert.Equal(t, []mat.Float{7, 5, 6}, proc.W.Value().Data())
+	assert.Nil(t, p.decoded)
+	assert.Equal(t, []mat.Float{7, 5, 6}, p.half.Decode().Data())
+
+	m.W.Value().Data()[1] = 1
+	m.W.ApplyDelta(mat.NewVecDense([]mat.Float{1, 1, 1}))
+	assert.Nil(t, p.decoded)
+	assert.Equal(t, []mat.Float{6, 0, 5}, p.half.Decode().Data())
+
+	m.W.Value().Data()[2] = 2
+	ConvertToHalfPrecision(m, mat.BFloat16)
+	assert.Nil(t, p.decoded)
+	assert.Equal(t, mat.BFloat16, p.half.Format())
+	assert.Equal(t, []mat.Float{6, 0, 2}, m.W.Value().Data())
+
+	var buf bytes.Buffer
+	m.W.Value().Data()[0] = 3
+	require.NoError(t, MarshalBinaryParam(m.W, &buf))
+	decoded, err := UnmarshalBinaryParam(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, []mat.Float{3, 0, 2}, decoded.Value().Data())
+}
+
+func TestHalfPrecision_Encoding(t *testing.T) {
+	p := NewParam(mat.NewVecDense([]mat.Float{0.5, -1, 2}), HalfPrecision(mat.Float16))
+	var buf bytes.Buffer
+	require.NoError(t, MarshalBinaryParam(p, &buf))
+
+	decoded, err := UnmarshalBinaryParam(&buf)
+	require.NoError(t, err)
+	require.NotNil(t, decoded.(*param).half)
+	assert.Equal(t, mat.Float16, decoded.(*param).half.Format())
+	assert.Equal(t, []mat.Float{0.5, -1, 2}, decoded.Value().Data())
+
+	// the updates made through Value() are encoded back, releasing the decoded copy
+	decoded.Value().Data()[0] = 4
+	buf.Reset()
+	require.NoError(t, MarshalBinaryParam(decoded, &buf))
+	assert.Nil(t, decoded.(*param).decoded)
+	decoded, err = UnmarshalBinaryParam(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, []mat.Float{4, -1, 2}, decoded.Value().Data())
+}
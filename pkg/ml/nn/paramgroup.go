@@ -0,0 +1,104 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nn
+
+import (
+	"fmt"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+)
+
+// ParamGroup is a set of parameters sharing the same optimization
+// hyperparameters (e.g. the embeddings, the transformer layers and the
+// classification head of a model, fine-tuned with different learning rates).
+type ParamGroup struct {
+	// Name identifies the group.
+	Name string
+	// Params are the parameters of the group.
+	Params []Param
+	// LRScale multiplies the learning rate of the optimization method for the
+	// parameters of the group. The zero value stands for 1, so that a group
+	// declared as a struct literal is optimized as usual: use Frozen to
+	// exclude its parameters from the optimization.
+	LRScale mat.Float
+	// WeightDecay is the rate of the decoupled weight decay: at each
+	// optimization step, the values of the parameters are multiplied by
+	// (1 - WeightDecay) before the update (0 by default).
+	WeightDecay mat.Float
+	// Frozen excludes the parameters of the group from the optimization: their
+	// gradients are discarded.
+	Frozen bool
+}
+
+// NewParamGroup returns a new ParamGroup with the default hyperparameters.
+func NewParamGroup(name string, params ...Param) *ParamGroup {
+	return &ParamGroup{
+		Name:    name,
+		Params:  params,
+		LRScale: 1,
+	}
+}
+
+// ParamGroupsGetter is implemented by any value that has the ParamGroups
+// method, which should return the groups of parameters of one or more models.
+type ParamGroupsGetter interface {
+	ParamGroups() []*ParamGroup
+}
+
+var (
+	_ ParamsGetter      = ParamGroups{}
+	_ ParamGroupsGetter = ParamGroups{}
+)
+
+// ParamGroups is a list of ParamGroup, which can be optimized by a gradient
+// descent optimizer in place of a flat list of parameters.
+type ParamGroups []*ParamGroup
+
+// Params returns the parameters of all the groups, in order.
+func (gs ParamGroups) Params() []Param {
+	params := make([]Param, 0)
+	for _, g := range gs {
+		params = append(params, g.Params...)
+	}
+	return params
+}
+
+// ParamGroups returns the groups.
+func (gs ParamGroups) ParamGroups() []*ParamGroup {
+	return gs
+}
+
+// Group returns the group with the given name, or nil if it doesn't exist.
+func (gs ParamGroups) Group(name string) *ParamGroup {
+	for _, g := range gs {
+		if g.Name == name {
+			return g
+		}
+	}
+	return nil
+}
+
+// Assign appends the parameters of the model (including sub-params) to the
+// groups, by the name of the group returned by assign for the qualified name
+// of each parameter (see ForEachNamedParam), e.g.:
+//
+//     groups.Assign(model, func(name string) string {
+//         if strings.HasPrefix(name, "Embeddings.") {
+//             return "embeddings"
+//         }
+//         return "encoder"
+//     })
+//
+// It panics if a group doesn't exist.
+func (gs ParamGroups) Assign(m Model, assign func(name string) string) {
+	ForEachNamedParam(m, func(name string, param Param) {
+		groupName := assign(name)
+		g := gs.Group(groupName)
+		if g == nil {
+			panic(fmt.Sprintf("nn: the param group %q of %q doesn't exist", groupName, name))
+		}
+		g.Params = append(g.Params, param)
+	})
+}
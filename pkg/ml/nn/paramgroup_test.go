@@ -0,0 +1,58 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nn
+
+import (
+	"strings"
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewParamGroup(t *testing.T) {
+	p := NewParam(mat.NewScalar(1))
+	g := NewParamGroup("head", p)
+	assert.Equal(t, "head", g.Name)
+	assert.Equal(t, []Param{p}, g.Params)
+	assert.Equal(t, mat.Float(1), g.LRScale)
+	assert.Equal(t, mat.Float(0), g.WeightDecay)
+	assert.False(t, g.Frozen)
+}
+
+func TestParamGroups(t *testing.T) {
+	p := NewParam(mat.NewScalar(1))
+	q := NewParam(mat.NewScalar(2))
+	r := NewParam(mat.NewScalar(3))
+	groups := ParamGroups{
+		NewParamGroup("a", p, q),
+		NewParamGroup("b"),
+		NewParamGroup("c", r),
+	}
+	assert.Equal(t, []Param{p, q, r}, groups.Params())
+	assert.Len(t, groups.ParamGroups(), 3)
+	assert.Same(t, groups[2], groups.Group("c"))
+	assert.Nil(t, groups.Group("d"))
+}
+
+func TestParamGroups_Assign(t *testing.T) {
+	m := newWideArithmeticModel(2, 2)
+	groups := ParamGroups{
+		NewParamGroup("first"),
+		NewParamGroup("others"),
+	}
+	groups.Assign(m, func(name string) string {
+		if strings.HasPrefix(name, "Layers.0.") {
+			return "first"
+		}
+		return "others"
+	})
+	assert.Equal(t, []Param{m.Layers[0].W, m.Layers[0].B}, groups.Group("first").Params)
+	assert.Equal(t, []Param{m.Layers[1].W, m.Layers[1].B}, groups.Group("others").Params)
+
+	assert.Panics(t, func() {
+		groups.Assign(m, func(string) string { return "missing" })
+	})
+}
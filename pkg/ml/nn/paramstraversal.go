@@ -9,6 +9,7 @@ import (
 	"github.com/nlpodyssey/spago/pkg/nlp/embeddings/syncmap"
 	"github.com/nlpodyssey/spago/pkg/utils"
 	"reflect"
+	"strconv"
 	"strings"
 	"sync"
 )
@@ -18,18 +19,40 @@ import (
 // If exploreSubModels is true, every nested Model and its parameters are
 // also visited.
 type paramsTraversal struct {
-	callback         func(param Param)
+	callback         func(path string, param Param)
 	exploreSubModels bool
+	// modelCallback, if set, is invoked for each nested Model visited.
+	modelCallback func(path string, m Model)
+	// path is the qualified name of the item being visited.
+	path string
 }
 
 // newParamsTraversal returns a new paramsTraversal.
 func newParamsTraversal(callback func(param Param), exploreSubModels bool) paramsTraversal {
+	return newNamedParamsTraversal(func(_ string, param Param) {
+		callback(param)
+	}, exploreSubModels)
+}
+
+// newNamedParamsTraversal returns a new paramsTraversal whose callback also
+// receives the qualified name of each parameter.
+func newNamedParamsTraversal(callback func(path string, param Param), exploreSubModels bool) paramsTraversal {
 	return paramsTraversal{
 		callback:         callback,
 		exploreSubModels: exploreSubModels,
 	}
 }
 
+// child returns the traversal of an item nested in the current one.
+func (pt paramsTraversal) child(name string) paramsTraversal {
+	if pt.path == "" {
+		pt.path = name
+	} else {
+		pt.path = pt.path + "." + name
+	}
+	return pt
+}
+
 // walk iterates through all the parameters of m.
 // TODO: don't loop the field every time, use a lazy initialized "params list" instead
 func (pt paramsTraversal) walk(m interface{}) {
@@ -41,11 +64,11 @@ func (pt paramsTraversal) walk(m interface{}) {
 		v := reflect.ValueOf(field)
 		switch v.Kind() {
 		case reflect.Struct, reflect.Ptr, reflect.Interface:
-			pt.walkStructOrPtr(field, name, tag)
+			pt.child(name).walkStructOrPtr(field, name, tag)
 		case reflect.Slice:
-			pt.walkSlice(v, name, tag)
+			pt.child(name).walkSlice(v, name, tag)
 		case reflect.Map:
-			pt.walkMap(v, name, tag)
+			pt.child(name).walkMap(v, name, tag)
 		}
 	})
 }
@@ -58,8 +81,13 @@ func (pt paramsTraversal) walkStructOrPtr(item interface{}, name string, tag mod
 	switch itemT := item.(type) {
 	case *param:
 		pt.walkParam(itemT, name, tag)
+	case reparameterization:
+		pt.walk(itemT) // the underlying params
 	case Model:
 		if pt.exploreSubModels {
+			if pt.modelCallback != nil {
+				pt.modelCallback(pt.path, itemT)
+			}
 			pt.walk(item)
 		}
 	case *sync.Map:
@@ -88,10 +116,11 @@ func (pt paramsTraversal) walkSyncMap(i *sync.Map, name string, tag moduleFieldT
 			return false // skip map if the key is not a string or an int
 		}
 
+		child := pt.child(key.(string))
 		name := strings.ToLower(fmt.Sprintf("%s.%s", name, key))
 		switch reflect.ValueOf(value).Kind() {
 		case reflect.Struct, reflect.Ptr, reflect.Interface:
-			pt.walkStructOrPtr(value, name, tag)
+			child.walkStructOrPtr(value, name, tag)
 		default:
 			return false // skip
 		}
@@ -105,7 +134,7 @@ func (pt paramsTraversal) walkSlice(v reflect.Value, name string, tag moduleFiel
 		p := v.Index(i)
 		switch p.Kind() {
 		case reflect.Struct, reflect.Ptr, reflect.Interface:
-			pt.walkStructOrPtr(p.Interface(), name, tag)
+			pt.child(strconv.Itoa(i)).walkStructOrPtr(p.Interface(), name, tag)
 		default:
 			return // skip
 		}
@@ -125,10 +154,11 @@ func (pt paramsTraversal) walkMap(v reflect.Value, name string, tag moduleFieldT
 			return // skip map if the key is not a string or an int
 		}
 
+		child := pt.child(key)
 		name := strings.ToLower(fmt.Sprintf("%s.%s", name, key))
 		switch mapRange.Value().Kind() {
 		case reflect.Struct, reflect.Ptr, reflect.Interface:
-			pt.walkStructOrPtr(mapRange.Value().Interface(), name, tag)
+			child.walkStructOrPtr(mapRange.Value().Interface(), name, tag)
 		default:
 			return // skip
 		}
@@ -140,5 +170,5 @@ func (pt paramsTraversal) walkParam(item *param, name string, tag moduleFieldTag
 		item.SetName(strings.ToLower(name))
 	}
 	item.SetType(tag.paramType())
-	pt.callback(item)
+	pt.callback(pt.path, item)
 }
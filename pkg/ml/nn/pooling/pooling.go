@@ -7,6 +7,7 @@ package pooling
 import (
 	"encoding/gob"
 	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/nlpodyssey/spago/pkg/ml/ag/fn"
 	"github.com/nlpodyssey/spago/pkg/ml/nn"
 )
 
@@ -42,3 +43,55 @@ func (m *MaxPooling) Forward(xs ...ag.Node) []ag.Node {
 	}
 	return ag.Map(pooled, xs)
 }
+
+var (
+	_ nn.Model = &MaxPool2D{}
+	_ nn.Model = &AvgPool2D{}
+)
+
+// MaxPool2D is a parameter-free model performing the 2D max pooling of each
+// channel of images represented as in fn.Conv2DConfig.
+type MaxPool2D struct {
+	nn.BaseModel
+	Config fn.Pool2DConfig
+}
+
+// AvgPool2D is a parameter-free model performing the 2D average pooling of
+// each channel of images represented as in fn.Conv2DConfig.
+type AvgPool2D struct {
+	nn.BaseModel
+	Config fn.Pool2DConfig
+}
+
+func init() {
+	gob.Register(&MaxPool2D{})
+	gob.Register(&AvgPool2D{})
+}
+
+// NewMax2D returns a new MaxPool2D model.
+func NewMax2D(config fn.Pool2DConfig) *MaxPool2D {
+	return &MaxPool2D{Config: config}
+}
+
+// NewAvg2D returns a new AvgPool2D model.
+func NewAvg2D(config fn.Pool2DConfig) *AvgPool2D {
+	return &AvgPool2D{Config: config}
+}
+
+// Forward performs the forward step for each input image and returns the result.
+func (m *MaxPool2D) Forward(xs ...ag.Node) []ag.Node {
+	g := m.Graph()
+	pooled := func(x ag.Node) ag.Node {
+		return g.MaxPool2D(x, m.Config)
+	}
+	return ag.Map(pooled, xs)
+}
+
+// Forward performs the forward step for each input image and returns the result.
+func (m *AvgPool2D) Forward(xs ...ag.Node) []ag.Node {
+	g := m.Graph()
+	pooled := func(x ag.Node) ag.Node {
+		return g.AvgPool2D(x, m.Config)
+	}
+	return ag.Map(pooled, xs)
+}
@@ -0,0 +1,31 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pooling
+
+import (
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/nlpodyssey/spago/pkg/ml/ag/fn"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPool2D_Forward(t *testing.T) {
+	g := ag.NewGraph()
+	defer g.Clear()
+	config := fn.Pool2DConfig{Height: 2, Width: 4, KernelRows: 2, KernelColumns: 2}
+	x := g.NewVariable(mat.NewDense(1, 8, []mat.Float{
+		1, 2, 3, 4,
+		5, 6, 7, 9,
+	}), false)
+
+	maxPool := nn.ReifyForInference(NewMax2D(config), g).(*MaxPool2D)
+	assert.Equal(t, []mat.Float{6, 9}, maxPool.Forward(x)[0].Value().Data())
+
+	avgPool := nn.ReifyForInference(NewAvg2D(config), g).(*AvgPool2D)
+	assert.Equal(t, []mat.Float{3.5, 5.75}, avgPool.Forward(x)[0].Value().Data())
+}
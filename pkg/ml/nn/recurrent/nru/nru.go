@@ -18,6 +18,7 @@ import (
 	"github.com/nlpodyssey/spago/pkg/ml/ag"
 	"github.com/nlpodyssey/spago/pkg/ml/nn"
 	"github.com/nlpodyssey/spago/pkg/ml/nn/normalization/layernorm"
+	"github.com/nlpodyssey/spago/pkg/ml/numerics"
 	"log"
 )
 
@@ -243,7 +244,7 @@ func normalization(g *ag.Graph, xs []ag.Node, p int) []ag.Node {
 	for i := 0; i < dim0; i++ {
 		tmp[i] = make([]ag.Node, dim1)
 	}
-	eps := g.NewScalar(1e-10)
+	eps := g.NewScalar(numerics.Current().Norm)
 	for j := 0; j < dim1; j++ {
 		vec := make([]ag.Node, dim0)
 		for i := 0; i < dim0; i++ {
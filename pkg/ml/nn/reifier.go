@@ -73,10 +73,15 @@ func (r *reifier) reifyStructField(sourceField, destField reflect.Value, tag mod
 		destField.Set(reflect.ValueOf(r.g))
 	case ProcessingMode:
 		destField.Set(reflect.ValueOf(r.mode))
-	case BaseModel, *BaseModel:
+	case BaseModel:
 		destField.Set(reflect.ValueOf(r.reifyStruct(sourceFieldT)))
+		destField.Addr().Interface().(*BaseModel).hooks = sourceFieldT.hookList() // unexported
+	case *BaseModel:
+		dest := r.reifyStruct(sourceFieldT).(*BaseModel)
+		dest.hooks = sourceFieldT.hookList() // unexported
+		destField.Set(reflect.ValueOf(dest))
 	case Param:
-		destField.Set(reflect.ValueOf(r.reifyParam(sourceFieldT.(*param))))
+		destField.Set(reflect.ValueOf(r.reifyAnyParam(sourceFieldT)))
 	case []Param:
 		destField.Set(reflect.ValueOf(r.reifyParamSlice(sourceFieldT)))
 	case Model:
@@ -125,10 +130,19 @@ func (r *reifier) reifyParam(sourceField *param) Param {
 	return sourceField.wrappedParam(r.g)
 }
 
+// reifyAnyParam reifies a param created by NewParam, or a reparameterization
+// (see WeightNorm), whose value is computed from its reified underlying params.
+func (r *reifier) reifyAnyParam(sourceField Param) Param {
+	if rp, ok := sourceField.(reparameterization); ok {
+		return &reparamNode{reparameterization: rp, Node: rp.node(r)}
+	}
+	return r.reifyParam(sourceField.(*param))
+}
+
 func (r *reifier) reifyParamSlice(sourceField []Param) []Param {
 	result := make([]Param, len(sourceField))
 	for i := 0; i < len(sourceField); i++ {
-		result[i] = r.reifyParam(sourceField[i].(*param))
+		result[i] = r.reifyAnyParam(sourceField[i])
 	}
 	return result
 }
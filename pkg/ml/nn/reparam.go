@@ -0,0 +1,387 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nn
+
+import (
+	"encoding/gob"
+	"sync"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/mat32/rand"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/nlpodyssey/spago/pkg/ml/numerics"
+)
+
+var (
+	_ reparameterization = &weightNorm{}
+	_ reparameterization = &spectralNorm{}
+)
+
+func init() {
+	gob.Register(&weightNorm{})
+	gob.Register(&spectralNorm{})
+}
+
+// reparameterization is implemented by the params whose value is computed
+// from other params at each forward (see WeightNorm and SpectralNormalization). Only
+// the underlying params are visited (see ForEachParam), so they are the ones
+// trained by the optimizers and serialized along with the model.
+type reparameterization interface {
+	Param
+	// node returns the value of the param, computed in the graph of the
+	// reifier from the underlying params.
+	node(r *reifier) ag.Node
+}
+
+// reparamBase implements the methods of Param which are not delegated to
+// the underlying params: a reparameterization accumulates no gradients and
+// has no optimizer payload on its own.
+type reparamBase struct {
+	name  string
+	pType ParamsType
+}
+
+// Name returns the params name (can be empty string).
+func (r *reparamBase) Name() string {
+	return r.name
+}
+
+// SetName set the params name (can be empty string).
+func (r *reparamBase) SetName(name string) {
+	r.name = name
+}
+
+// Type returns the params type (weights, biases, undefined).
+func (r *reparamBase) Type() ParamsType {
+	return r.pType
+}
+
+// SetType set the params type (weights, biases, undefined).
+func (r *reparamBase) SetType(pType ParamsType) {
+	r.pType = pType
+}
+
+// Grad returns always nil, since the gradients are accumulated by the
+// underlying params.
+func (r *reparamBase) Grad() mat.Matrix {
+	return nil
+}
+
+// HasGrad returns always false (see Grad).
+func (r *reparamBase) HasGrad() bool {
+	return false
+}
+
+// PropagateGrad panics, since the gradients are propagated to the underlying
+// params through the graph.
+func (r *reparamBase) PropagateGrad(_ mat.Matrix) {
+	panic("nn: cannot propagate the gradients of a reparameterized param outside a graph")
+}
+
+// ZeroGrad does nothing (see Grad).
+func (r *reparamBase) ZeroGrad() {}
+
+// ApplyDelta panics, since the underlying params are the ones to update.
+func (r *reparamBase) ApplyDelta(_ mat.Matrix) {
+	panic("nn: cannot apply a delta to a reparameterized param")
+}
+
+// Payload returns always nil (see ApplyDelta).
+func (r *reparamBase) Payload() *Payload {
+	return nil
+}
+
+// SetPayload panics (see ApplyDelta).
+func (r *reparamBase) SetPayload(_ *Payload) {
+	panic("nn: cannot set the payload of a reparameterized param")
+}
+
+// ClearPayload does nothing (see ApplyDelta).
+func (r *reparamBase) ClearPayload() {}
+
+// Graph returns always nil since the param is not associated with any graph.
+func (r *reparamBase) Graph() *ag.Graph {
+	return nil
+}
+
+// ID returns always -1 since the param is not associated with any graph.
+func (r *reparamBase) ID() int {
+	return -1
+}
+
+// TimeStep returns always 0 since the param is not associated with any graph.
+func (r *reparamBase) TimeStep() int {
+	return 0
+}
+
+// weightNorm is the reparameterization of WeightNorm.
+type weightNorm struct {
+	reparamBase
+	// V is the direction of the rows of the weights.
+	V Param `spago:"type:weights"`
+	// G is the magnitude of the rows of the weights.
+	G Param `spago:"type:weights"`
+}
+
+// WeightNorm returns a new Param reparameterizing the weights of p as the
+// product of a magnitude and a direction, decoupled from each other
+// (Salimans and Kingma, 2016): w_i = g_i * v_i / ||v_i||, for each row i.
+// It replaces p in the model, e.g.:
+//
+//     layer.W = nn.WeightNorm(layer.W)
+//
+// The underlying params V and G, initialized so that the weights are the
+// same as p, are the ones trained and serialized: the weights are computed
+// from them in the graph at each forward.
+func WeightNorm(p Param) Param {
+	value := p.Value()
+	return &weightNorm{
+		reparamBase: reparamBase{name: p.Name(), pType: p.Type()},
+		V:           NewParam(value.Clone(), RequiresGrad(p.RequiresGrad())),
+		G:           NewParam(rowNorms(value), RequiresGrad(p.RequiresGrad())),
+	}
+}
+
+// Value returns the weights computed from the underlying params.
+func (r *weightNorm) Value() mat.Matrix {
+	v := r.V.Value()
+	norms := rowNorms(v)
+	out := v.Clone()
+	rows, cols := out.Dims()
+	for i := 0; i < rows; i++ {
+		scale := r.G.Value().AtVec(i) / norms.AtVec(i)
+		for j := 0; j < cols; j++ {
+			out.Set(i, j, out.At(i, j)*scale)
+		}
+	}
+	return out
+}
+
+// ScalarValue returns the scalar value of the weights.
+// It panics if the value is not a scalar.
+func (r *weightNorm) ScalarValue() mat.Float {
+	return r.Value().Scalar()
+}
+
+// ReplaceValue replaces the underlying params with the ones of the given
+// weights.
+func (r *weightNorm) ReplaceValue(value mat.Matrix) {
+	r.V.ReplaceValue(value.Clone())
+	r.G.ReplaceValue(rowNorms(value))
+}
+
+// RequiresGrad returns true if the underlying params require gradients.
+func (r *weightNorm) RequiresGrad() bool {
+	return r.V.RequiresGrad() || r.G.RequiresGrad()
+}
+
+// SetRequiresGrad sets whether the underlying params require gradients.
+func (r *weightNorm) SetRequiresGrad(value bool) {
+	r.V.SetRequiresGrad(value)
+	r.G.SetRequiresGrad(value)
+}
+
+func (r *weightNorm) node(re *reifier) ag.Node {
+	g := re.g
+	v, magnitude := re.reifyAnyParam(r.V), re.reifyAnyParam(r.G)
+	cols := v.Value().Columns()
+	norms := g.Sqrt(g.Mul(g.Square(v), g.NewVariable(mat.NewInitVecDense(cols, 1), false)))
+	scale := g.Div(magnitude, norms)
+	return g.Prod(v, g.Mul(scale, g.NewVariable(mat.NewInitDense(1, cols, 1), false)))
+}
+
+// rowNorms returns the Euclidean norms of the rows of m.
+func rowNorms(m mat.Matrix) mat.Matrix {
+	rows, cols := m.Dims()
+	data := m.Data()
+	out := mat.NewEmptyVecDense(rows)
+	for i := 0; i < rows; i++ {
+		out.SetVec(i, mat.NewVecDense(data[i*cols:(i+1)*cols]).Norm(2))
+	}
+	return out
+}
+
+// spectralNormInitIterations is the number of power iterations performed by
+// SpectralNormalization on the initial weights, so that the estimate of the spectral
+// norm is accurate from the first forward.
+const spectralNormInitIterations = 15
+
+// spectralNorm is the reparameterization of SpectralNormalization.
+type spectralNorm struct {
+	reparamBase
+	// W contains the weights before the normalization.
+	W Param `spago:"type:weights"`
+	// U is the estimate of the first left singular vector of W.
+	U mat.Matrix
+	// Iterations is the number of power iterations at each training forward.
+	Iterations int
+	mu         sync.Mutex
+}
+
+// SpectralNormalization returns a new Param reparameterizing the weights of p as
+// themselves divided by their spectral norm (Miyato et al., 2018), i.e.
+// their largest singular value, estimated with the given number of power
+// iterations at each forward in training mode. It replaces p in the model,
+// e.g.:
+//
+//     layer.W = nn.SpectralNormalization(layer.W, 1)
+//
+// The underlying param W, initialized to p, is the one trained and
+// serialized, along with the estimate of its first left singular vector,
+// which is not trained but updated by the power iterations. In inference
+// mode the estimate is used as it is.
+//
+// As usual, the gradients are not propagated through the power iterations.
+// It panics if iterations is less than one.
+func SpectralNormalization(p Param, iterations int) Param {
+	if iterations < 1 {
+		panic("nn: SpectralNormalization requires at least one power iteration")
+	}
+	value := p.Value()
+	rows := value.Rows()
+	rnd := rand.NewLockedRand(42)
+	u := mat.NewEmptyVecDense(rows)
+	for i := 0; i < rows; i++ {
+		u.SetVec(i, rnd.NormFloat32())
+	}
+	r := &spectralNorm{
+		reparamBase: reparamBase{name: p.Name(), pType: p.Type()},
+		W:           NewParam(value.Clone(), RequiresGrad(p.RequiresGrad())),
+		Iterations:  iterations,
+	}
+	r.U, _ = powerIteration(value, normalize2(u), spectralNormInitIterations)
+	return r
+}
+
+// Value returns the weights divided by their spectral norm, estimated
+// without updating the singular vectors.
+func (r *spectralNorm) Value() mat.Matrix {
+	w := r.W.Value()
+	r.mu.Lock()
+	u, v := powerIteration(w, r.U, 0)
+	r.mu.Unlock()
+	sigma := u.DotUnitary(w.Mul(v))
+	return w.ProdScalar(1 / sigma)
+}
+
+// ScalarValue returns the scalar value of the weights.
+// It panics if the value is not a scalar.
+func (r *spectralNorm) ScalarValue() mat.Float {
+	return r.Value().Scalar()
+}
+
+// ReplaceValue replaces the underlying weights, which are divided by their
+// spectral norm at the next forward.
+func (r *spectralNorm) ReplaceValue(value mat.Matrix) {
+	r.W.ReplaceValue(value.Clone())
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.U, _ = powerIteration(value, r.U, spectralNormInitIterations)
+}
+
+// RequiresGrad returns true if the underlying weights require gradients.
+func (r *spectralNorm) RequiresGrad() bool {
+	return r.W.RequiresGrad()
+}
+
+// SetRequiresGrad sets whether the underlying weights require gradients.
+func (r *spectralNorm) SetRequiresGrad(value bool) {
+	r.W.SetRequiresGrad(value)
+}
+
+func (r *spectralNorm) node(re *reifier) ag.Node {
+	g := re.g
+	w := re.reifyAnyParam(r.W)
+	iterations := 0
+	if re.mode == Training {
+		iterations = r.Iterations
+	}
+	r.mu.Lock()
+	u, v := powerIteration(w.Value(), r.U, iterations)
+	if iterations > 0 {
+		r.U = u
+	}
+	r.mu.Unlock()
+	un, vn := g.NewVariable(u, false), g.NewVariable(v, false)
+	sigma := g.Mul(g.T(un), g.Mul(w, vn))
+	return g.DivScalar(w, sigma)
+}
+
+// powerIteration performs the given number of power iterations on w,
+// starting from the estimate u of its first left singular vector, and
+// returns the estimates of its first left and right singular vectors.
+func powerIteration(w, u mat.Matrix, iterations int) (mat.Matrix, mat.Matrix) {
+	wt := w.T()
+	v := normalize2(wt.Mul(u))
+	for i := 0; i < iterations; i++ {
+		u = normalize2(w.Mul(v))
+		v = normalize2(wt.Mul(u))
+	}
+	return u, v
+}
+
+// normalize2 returns x divided by its Euclidean norm.
+func normalize2(x mat.Matrix) mat.Matrix {
+	return x.ProdScalar(1 / (x.Norm(2) + numerics.Current().Norm))
+}
+
+var _ Param = &reparamNode{}
+
+// reparamNode enriches a reparameterized Param with the Node of its value
+// computed in a graph.
+type reparamNode struct {
+	reparameterization
+	Node ag.Node
+}
+
+// Value dispatches the call to the Node.
+func (r *reparamNode) Value() mat.Matrix {
+	return r.Node.Value()
+}
+
+// ScalarValue dispatches the call to the Node.
+func (r *reparamNode) ScalarValue() mat.Float {
+	return r.Node.ScalarValue()
+}
+
+// ID dispatches the call to the Node.
+func (r *reparamNode) ID() int {
+	return r.Node.ID()
+}
+
+// Graph dispatches the call to the Node.
+func (r *reparamNode) Graph() *ag.Graph {
+	return r.Node.Graph()
+}
+
+// TimeStep dispatches the call to the Node.
+func (r *reparamNode) TimeStep() int {
+	return r.Node.TimeStep()
+}
+
+// Grad dispatches the call to the Node.
+func (r *reparamNode) Grad() mat.Matrix {
+	return r.Node.Grad()
+}
+
+// PropagateGrad dispatches the call to the Node.
+func (r *reparamNode) PropagateGrad(gx mat.Matrix) {
+	r.Node.PropagateGrad(gx)
+}
+
+// HasGrad dispatches the call to the Node.
+func (r *reparamNode) HasGrad() bool {
+	return r.Node.HasGrad()
+}
+
+// RequiresGrad dispatches the call to the Node.
+func (r *reparamNode) RequiresGrad() bool {
+	return r.Node.RequiresGrad()
+}
+
+// ZeroGrad dispatches the call to the Node.
+func (r *reparamNode) ZeroGrad() {
+	r.Node.ZeroGrad()
+}
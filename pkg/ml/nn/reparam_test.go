@@ -0,0 +1,159 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nn
+
+import (
+	"bytes"
+	"encoding/gob"
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newReparamTestModel(reparameterize func(p Param) Param) *arithmeticModel {
+	return &arithmeticModel{Layers: []*arithmeticLayer{{
+		W: reparameterize(NewParam(mat.NewDense(2, 3, []mat.Float{
+			0.5, -1.0, 2.0,
+			1.5, 0.3, -0.7,
+		}))),
+		B: NewParam(mat.NewVecDense([]mat.Float{0.1, -0.2})),
+	}}}
+}
+
+// reparamTestCoefficients weights the values of the reparameterized params
+// in the loss of the tests, so that each one has a different gradient.
+var reparamTestCoefficients = mat.NewDense(2, 3, []mat.Float{
+	1.0, -2.0, 0.5,
+	0.3, 0.7, -1.1,
+})
+
+// assertReparamGradients checks the gradients of the underlying params of the
+// layer with finite differences of the loss sum(c ⊙ W).
+func assertReparamGradients(t *testing.T, m *arithmeticModel) {
+	g := ag.NewGraph()
+	proc := ReifyForTraining(m, g).(*arithmeticModel)
+	w := proc.Layers[0].W
+	g.Backward(g.ReduceSum(g.Prod(w, g.NewVariable(reparamTestCoefficients, false))))
+
+	loss := func() mat.Float {
+		return m.Layers[0].W.Value().Prod(reparamTestCoefficients).Sum()
+	}
+	const eps = 1e-2
+	n := 0
+	ForEachNamedParam(m.Layers[0], func(name string, p Param) {
+		if name == "B" {
+			return
+		}
+		n++
+		require.True(t, p.HasGrad(), name)
+		value := p.Value()
+		for i, x := range value.Data() {
+			value.Data()[i] = x + eps
+			plus := loss()
+			value.Data()[i] = x - eps
+			minus := loss()
+			value.Data()[i] = x
+			assert.InDelta(t, (plus-minus)/(2*eps), p.Grad().Data()[i], 1e-2, "%s[%d]", name, i)
+		}
+		p.ZeroGrad()
+	})
+	assert.Greater(t, n, 0)
+}
+
+func TestWeightNorm(t *testing.T) {
+	m := newReparamTestModel(WeightNorm)
+	w := m.Layers[0].W
+	assert.InDeltaSlice(t, []mat.Float{0.5, -1.0, 2.0, 1.5, 0.3, -0.7}, w.Value().Data(), 1e-6)
+
+	var names []string
+	ForEachNamedParam(m, func(name string, p Param) {
+		names = append(names, name)
+	})
+	assert.Equal(t, []string{"Layers.0.W.V", "Layers.0.W.G", "Layers.0.B"}, names)
+	assert.Equal(t, 6+2+2, Summary(m).TotalParams)
+
+	g := ag.NewGraph()
+	proc := ReifyForInference(m, g).(*arithmeticModel)
+	assert.InDeltaSlice(t, w.Value().Data(), proc.Layers[0].W.Value().Data(), 1e-6)
+
+	// Doubling the magnitude doubles the weights, whatever the direction.
+	wn := w.(*weightNorm)
+	wn.G.ReplaceValue(wn.G.Value().ProdScalar(2))
+	wn.V.ReplaceValue(wn.V.Value().ProdScalar(3))
+	assert.InDeltaSlice(t, []mat.Float{1.0, -2.0, 4.0, 3.0, 0.6, -1.4}, w.Value().Data(), 1e-5)
+
+	w.ReplaceValue(mat.NewDense(2, 3, []mat.Float{3, 0, 4, 0, 1, 0}))
+	assert.Equal(t, []mat.Float{5, 1}, wn.G.Value().Data())
+	assert.Panics(t, func() { w.ApplyDelta(mat.NewEmptyDense(2, 3)) })
+
+	w.SetRequiresGrad(false)
+	assert.False(t, wn.V.RequiresGrad())
+	assert.False(t, wn.G.RequiresGrad())
+}
+
+func TestWeightNorm_Gradients(t *testing.T) {
+	assertReparamGradients(t, newReparamTestModel(WeightNorm))
+}
+
+func TestSpectralNormalization(t *testing.T) {
+	m := newReparamTestModel(func(p Param) Param {
+		return SpectralNormalization(p, 1)
+	})
+	w := m.Layers[0].W
+	sn := w.(*spectralNorm)
+	assert.InDelta(t, 1.0, SpectralNorm(w.Value(), 50), 1e-4)
+
+	var names []string
+	ForEachNamedParam(m, func(name string, p Param) {
+		names = append(names, name)
+	})
+	assert.Equal(t, []string{"Layers.0.W.W", "Layers.0.B"}, names)
+
+	// The power iterations update the estimate in training mode only.
+	sn.W.ReplaceValue(sn.W.Value().ProdScalar(-1).AddScalar(1))
+	u := sn.U.Clone()
+	ReifyForInference(m, ag.NewGraph())
+	assert.Equal(t, u.Data(), sn.U.Data())
+	for i := 0; i < 20; i++ {
+		ReifyForTraining(m, ag.NewGraph())
+	}
+	assert.NotEqual(t, u.Data(), sn.U.Data())
+	proc := ReifyForInference(m, ag.NewGraph()).(*arithmeticModel)
+	assert.InDelta(t, 1.0, SpectralNorm(proc.Layers[0].W.Value(), 50), 1e-4)
+
+	assert.Panics(t, func() { SpectralNormalization(NewParam(mat.NewEmptyDense(2, 2)), 0) })
+}
+
+func TestSpectralNormalization_Gradients(t *testing.T) {
+	assertReparamGradients(t, newReparamTestModel(func(p Param) Param {
+		return SpectralNormalization(p, 1)
+	}))
+}
+
+func TestReparameterization_Serialization(t *testing.T) {
+	for name, reparameterize := range map[string]func(p Param) Param{
+		"WeightNorm": WeightNorm,
+		"SpectralNormalization": func(p Param) Param {
+			return SpectralNormalization(p, 1)
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			m := newReparamTestModel(reparameterize)
+			var buf bytes.Buffer
+			require.NoError(t, gob.NewEncoder(&buf).Encode(m))
+
+			var decoded *arithmeticModel
+			require.NoError(t, gob.NewDecoder(&buf).Decode(&decoded))
+			assert.IsType(t, m.Layers[0].W, decoded.Layers[0].W)
+			assert.InDeltaSlice(t, m.Layers[0].W.Value().Data(), decoded.Layers[0].W.Value().Data(), 1e-6)
+
+			proc := ReifyForInference(decoded, ag.NewGraph()).(*arithmeticModel)
+			assert.InDeltaSlice(t, m.Layers[0].W.Value().Data(), proc.Layers[0].W.Value().Data(), 1e-6)
+		})
+	}
+}
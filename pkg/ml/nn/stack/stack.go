@@ -6,6 +6,7 @@ package stack
 
 import (
 	"encoding/gob"
+	"fmt"
 	"github.com/nlpodyssey/spago/pkg/ml/ag"
 	"github.com/nlpodyssey/spago/pkg/ml/nn"
 )
@@ -46,10 +47,27 @@ func (m *Model) LastLayer() nn.Model {
 }
 
 // Forward performs the forward step for each input node and returns the result.
+// The forward of the layers calls their hooks, if any (see nn.RegisterForwardHook).
+// If the tracing is enabled on the graph (see ag.WithTracing), the forward of
+// each layer is recorded in a span named after its index.
 func (m *Model) Forward(xs ...ag.Node) []ag.Node {
-	ys := m.Layers[0].Forward(xs...)
+	if m.G != nil && m.G.TracingEnabled() {
+		return m.tracedForward(xs...)
+	}
+	ys := nn.ForwardWithHooks(m.Layers[0], xs...)
 	for i := 1; i < len(m.Layers); i++ {
-		ys = m.Layers[i].Forward(ys...)
+		ys = nn.ForwardWithHooks(m.Layers[i], ys...)
+	}
+	return ys
+}
+
+func (m *Model) tracedForward(xs ...ag.Node) []ag.Node {
+	ys := xs
+	for i, layer := range m.Layers {
+		span := m.G.StartSpan(fmt.Sprintf("layer.%d", i))
+		span.SetAttribute("type", fmt.Sprintf("%T", layer))
+		ys = nn.ForwardWithHooks(layer, ys...)
+		span.End()
 	}
 	return ys
 }
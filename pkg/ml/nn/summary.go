@@ -0,0 +1,177 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nn
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"unsafe"
+
+	"github.com/dustin/go-humanize"
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+)
+
+// ParamSummary is the summary of a parameter.
+type ParamSummary struct {
+	// Name is the qualified name of the parameter (see ForEachNamedParam).
+	Name string
+	// Rows and Columns are the shape of the value.
+	Rows, Columns int
+	// Trainable reports whether the parameter requires gradients.
+	Trainable bool
+	// Bytes is the memory occupied by the value (two bytes per element for
+	// the parameters stored in half precision).
+	Bytes int
+}
+
+// ModuleSummary is the summary of a module (the model or one of its
+// sub-models).
+type ModuleSummary struct {
+	// Name is the qualified name of the module, empty for the model.
+	Name string
+	// Type is the Go type of the module (e.g. "*linear.Model").
+	Type string
+	// Depth is the level of nesting of the module, zero for the model.
+	Depth int
+	// Params are the parameters of the module, sub-modules excluded.
+	Params []ParamSummary
+	// TotalParams is the number of values of the parameters of the module,
+	// sub-modules included.
+	TotalParams int
+	// TrainableParams is the number of values of the trainable parameters of
+	// the module, sub-modules included.
+	TrainableParams int
+	// Bytes is the memory occupied by the values of the parameters of the
+	// module, sub-modules included.
+	Bytes int
+}
+
+// ModelSummary is the structured report of the architecture of a model: its
+// modules, in order of traversal, with their parameters. The parameters
+// shared by more than one module are counted once in the totals of their
+// common ancestors, as are the ones tied together (see TieWeights).
+type ModelSummary struct {
+	// Modules are the model and its sub-models, in depth-first order.
+	Modules []ModuleSummary
+	// TotalParams is the number of values of all the parameters.
+	TotalParams int
+	// TrainableParams is the number of values of the trainable parameters.
+	TrainableParams int
+	// FrozenParams is the number of values of the parameters which don't
+	// require gradients.
+	FrozenParams int
+	// Bytes is the memory occupied by the values of all the parameters.
+	Bytes int
+}
+
+// Summary returns the summary of the model, similar to the one of Keras, to
+// sanity-check its architecture.
+func Summary(m Model) *ModelSummary {
+	s := &ModelSummary{Modules: []ModuleSummary{{Type: fmt.Sprintf("%T", m)}}}
+	modules := map[string]int{"": 0}
+	// parent returns the index of the innermost module containing the item
+	// with the given qualified name.
+	parent := func(name string) int {
+		for {
+			if i := strings.LastIndex(name, "."); i >= 0 {
+				name = name[:i]
+			} else {
+				name = ""
+			}
+			if i, ok := modules[name]; ok {
+				return i
+			}
+		}
+	}
+	parents := []int{-1}
+	seen := []map[Param]bool{make(map[Param]bool)}
+	pt := newNamedParamsTraversal(func(name string, p Param) {
+		ps := summarizeParam(name, p)
+		i := parent(name)
+		s.Modules[i].Params = append(s.Modules[i].Params, ps)
+		size := ps.Rows * ps.Columns
+		owner := TiedOwner(p)
+		for ; i >= 0; i = parents[i] {
+			if seen[i][owner] {
+				continue
+			}
+			seen[i][owner] = true
+			s.Modules[i].TotalParams += size
+			s.Modules[i].Bytes += ps.Bytes
+			if ps.Trainable {
+				s.Modules[i].TrainableParams += size
+			}
+		}
+	}, true)
+	pt.modelCallback = func(name string, sub Model) {
+		i := parent(name)
+		modules[name] = len(s.Modules)
+		parents = append(parents, i)
+		seen = append(seen, make(map[Param]bool))
+		s.Modules = append(s.Modules, ModuleSummary{
+			Name:  name,
+			Type:  fmt.Sprintf("%T", sub),
+			Depth: s.Modules[i].Depth + 1,
+		})
+	}
+	pt.walk(m)
+
+	root := s.Modules[0]
+	s.TotalParams = root.TotalParams
+	s.TrainableParams = root.TrainableParams
+	s.FrozenParams = root.TotalParams - root.TrainableParams
+	s.Bytes = root.Bytes
+	return s
+}
+
+func summarizeParam(name string, p Param) ParamSummary {
+	ps := ParamSummary{Name: name, Trainable: p.RequiresGrad()}
+	elementSize := int(unsafe.Sizeof(mat.Float(0)))
+	if r, ok := p.(*param); ok {
+		r = r.root()
+		r.mu.Lock()
+		ps.Rows, ps.Columns = r.dims()
+		if r.half != nil {
+			elementSize = 2
+		}
+		r.mu.Unlock()
+	} else {
+		ps.Rows, ps.Columns = p.Value().Dims()
+	}
+	ps.Bytes = ps.Rows * ps.Columns * elementSize
+	return ps
+}
+
+// String returns the table of the modules of the model, with the shapes of
+// their parameters, followed by the totals.
+func (s *ModelSummary) String() string {
+	var sb strings.Builder
+	tw := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "module\ttype\tshape\tparams\ttrainable\tmemory\t")
+	for _, m := range s.Modules {
+		indent := strings.Repeat("  ", m.Depth)
+		name := m.Name
+		if name == "" {
+			name = "(model)"
+		}
+		fmt.Fprintf(tw, "%s%s\t%s\t\t%d\t%d\t%s\t\n", indent, name, m.Type,
+			m.TotalParams, m.TrainableParams, humanize.Bytes(uint64(m.Bytes)))
+		for _, p := range m.Params {
+			trainable := 0
+			if p.Trainable {
+				trainable = p.Rows * p.Columns
+			}
+			fmt.Fprintf(tw, "%s  %s\t\t%d × %d\t%d\t%d\t%s\t\n", indent, strings.TrimPrefix(p.Name, m.Name+"."),
+				p.Rows, p.Columns, p.Rows*p.Columns, trainable, humanize.Bytes(uint64(p.Bytes)))
+		}
+	}
+	tw.Flush()
+	fmt.Fprintf(&sb, "Total params: %d\n", s.TotalParams)
+	fmt.Fprintf(&sb, "Trainable params: %d\n", s.TrainableParams)
+	fmt.Fprintf(&sb, "Frozen params: %d\n", s.FrozenParams)
+	fmt.Fprintf(&sb, "Memory: %s\n", humanize.Bytes(uint64(s.Bytes)))
+	return sb.String()
+}
@@ -0,0 +1,60 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nn
+
+import (
+	"strings"
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummary(t *testing.T) {
+	m := newArithmeticModel(1, 2, 3)
+	m.Layers[0].W = NewParam(mat.NewEmptyDense(2, 3), RequiresGrad(false))
+	m.Layers[1].W = NewParam(mat.NewEmptyDense(2, 2), HalfPrecision(mat.Float16))
+	m.Layers[2].B = m.Layers[1].B // shared
+
+	s := Summary(m)
+	require.Len(t, s.Modules, 4)
+
+	root := s.Modules[0]
+	assert.Equal(t, "", root.Name)
+	assert.Equal(t, "*nn.arithmeticModel", root.Type)
+	assert.Equal(t, 0, root.Depth)
+	assert.Empty(t, root.Params)
+	assert.Equal(t, 18, root.TotalParams)
+	assert.Equal(t, 12, root.TrainableParams)
+	assert.Equal(t, 64, root.Bytes)
+
+	layer := s.Modules[1]
+	assert.Equal(t, "Layers.0", layer.Name)
+	assert.Equal(t, "*nn.arithmeticLayer", layer.Type)
+	assert.Equal(t, 1, layer.Depth)
+	assert.Equal(t, []ParamSummary{
+		{Name: "Layers.0.W", Rows: 2, Columns: 3, Trainable: false, Bytes: 24},
+		{Name: "Layers.0.B", Rows: 2, Columns: 1, Trainable: true, Bytes: 8},
+	}, layer.Params)
+	assert.Equal(t, 8, layer.TotalParams)
+	assert.Equal(t, 2, layer.TrainableParams)
+	assert.Equal(t, 32, layer.Bytes)
+
+	assert.Equal(t, 8, s.Modules[2].Params[0].Bytes) // half precision
+	assert.Equal(t, 6, s.Modules[3].TotalParams)     // with the shared param
+
+	assert.Equal(t, 18, s.TotalParams)
+	assert.Equal(t, 12, s.TrainableParams)
+	assert.Equal(t, 6, s.FrozenParams)
+	assert.Equal(t, 24+8+8+8+16, s.Bytes)
+
+	str := s.String()
+	assert.True(t, strings.HasPrefix(str, "module"))
+	assert.Contains(t, str, "  Layers.1  *nn.arithmeticLayer")
+	assert.Contains(t, str, "    W  ")
+	assert.Contains(t, str, "2 × 3")
+	assert.Contains(t, str, "Total params: 18\nTrainable params: 12\nFrozen params: 6\nMemory: 64 B\n")
+}
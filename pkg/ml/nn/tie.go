@@ -0,0 +1,163 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nn
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	// pendingTies counts the decoded params whose tie is yet to be restored,
+	// so that Reify looks for them only when needed.
+	pendingTies int64
+	// tiesMu avoids data races when the ties of a model are restored by
+	// concurrent reifications.
+	tiesMu sync.Mutex
+)
+
+// TieWeights ties the param b to the param a (e.g. the output projection of a
+// language model to its input embeddings), so that b shares the storage of a:
+// the value, the gradients, which accumulate into one matrix, the optimizer
+// payload and whether the gradients are required. The previous value of b is
+// discarded.
+//
+// Once serialized, the value is stored only by a; when the model is decoded,
+// the tie is restored by RestoreTiedWeights, which Reify calls automatically.
+//
+// It panics if the params have different shapes, if they are not created by
+// NewParam (or reified), or if b is stored in a kvdb.KeyValueDB.
+func TieWeights(a, b Param) {
+	pa, pb := unwrapParam(a), unwrapParam(b)
+	root := pa.root()
+	if pb.root() == root {
+		return
+	}
+	if pb.storage != nil || root.storage != nil {
+		panic("nn: cannot tie params with a storage")
+	}
+	ar, ac := root.dims()
+	br, bc := pb.dims()
+	if ar != br || ac != bc {
+		panic(fmt.Sprintf("nn: cannot tie params of shapes %d×%d and %d×%d", ar, ac, br, bc))
+	}
+
+	pb.mu.Lock()
+	defer pb.mu.Unlock()
+	if root.tieKey == 0 {
+		root.tieKey = newTieKey()
+	}
+	pb.tiedTo = root
+	pb.tieKey = root.tieKey
+	pb.value, pb.half, pb.decoded, pb.grad, pb.payload = nil, nil, nil, nil, nil
+	pb.hasGrad = false
+}
+
+// TiedOwner returns the param whose storage is shared by p (see TieWeights),
+// or p itself if it is not tied to another param. It is useful to visit the
+// distinct storages of a model, e.g. to update each of them once.
+func TiedOwner(p Param) Param {
+	if r, ok := p.(*param); ok {
+		return r.root()
+	}
+	if r, ok := p.(*wrappedParam); ok {
+		return r.param.root()
+	}
+	return p
+}
+
+// ForEachDistinctParam is like ForEachParam, but it visits only the first of
+// the params sharing the same storage (see TieWeights). It is the traversal
+// to use for updating the values, which would be updated once for each tied
+// param otherwise.
+func ForEachDistinctParam(m Model, callback func(param Param)) {
+	seen := make(map[Param]bool)
+	ForEachParam(m, func(p Param) {
+		if owner := TiedOwner(p); !seen[owner] {
+			seen[owner] = true
+			callback(p)
+		}
+	})
+}
+
+// RestoreTiedWeights restores the ties of the params of a decoded model,
+// which are serialized without their value (see TieWeights). It returns an
+// error if a param is tied to another which is not part of the model.
+func RestoreTiedWeights(m Model) error {
+	if unresolved := restoreTies(m); len(unresolved) > 0 {
+		return fmt.Errorf("nn: the param tied to %q is not part of the model", unresolved[0].name)
+	}
+	return nil
+}
+
+// restorePendingTies restores the ties of the params of the model, if there
+// are decoded params whose tie is yet to be restored. The params tied to
+// others which are not part of the model (e.g. if m is a sub-model) are left
+// as they are.
+func restorePendingTies(m Model) {
+	if atomic.LoadInt64(&pendingTies) > 0 {
+		restoreTies(m)
+	}
+}
+
+// restoreTies restores the ties of the params of the model, returning the
+// params whose tie could not be restored.
+func restoreTies(m Model) []*param {
+	tiesMu.Lock()
+	defer tiesMu.Unlock()
+	owners := make(map[uint64]*param)
+	pending := make([]*param, 0)
+	ForEachParam(m, func(p Param) {
+		r, ok := p.(*param)
+		if !ok || r.tieKey == 0 {
+			return
+		}
+		if r.tiePending {
+			pending = append(pending, r)
+		} else if r.tiedTo == nil {
+			owners[r.tieKey] = r
+		}
+	})
+	unresolved := make([]*param, 0)
+	for _, r := range pending {
+		owner, ok := owners[r.tieKey]
+		if !ok {
+			unresolved = append(unresolved, r)
+			continue
+		}
+		r.tiedTo = owner
+		r.tiePending = false
+		atomic.AddInt64(&pendingTies, -1)
+	}
+	return unresolved
+}
+
+func unwrapParam(p Param) *param {
+	switch r := p.(type) {
+	case *param:
+		return r
+	case *wrappedParam:
+		return r.param
+	default:
+		panic(fmt.Sprintf("nn: cannot tie params of type %T", p))
+	}
+}
+
+// newTieKey returns a random key, which identifies the params sharing the
+// same storage in the serialized models.
+func newTieKey() uint64 {
+	var b [8]byte
+	for {
+		if _, err := rand.Read(b[:]); err != nil {
+			panic(err)
+		}
+		if key := binary.LittleEndian.Uint64(b[:]); key != 0 {
+			return key
+		}
+	}
+}
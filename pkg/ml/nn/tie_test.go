@@ -0,0 +1,75 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nn
+
+import (
+	"bytes"
+	"encoding/gob"
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	gob.Register(&arithmeticModel{})
+}
+
+func TestTieWeights(t *testing.T) {
+	m := newArithmeticModel(1, 2)
+	a, b := m.Layers[0].W, m.Layers[1].W
+	TieWeights(a, b)
+	assert.Equal(t, a.Value(), b.Value())
+	assert.Equal(t, a, TiedOwner(b))
+	assert.Equal(t, a, TiedOwner(a))
+
+	a.ApplyDelta(mat.NewInitDense(2, 2, 1))
+	assert.Equal(t, []mat.Float{0, 0, 0, 0}, b.Value().Data())
+
+	g := ag.NewGraph()
+	proc := ReifyForTraining(m, g).(*arithmeticModel)
+	y := g.Add(proc.Layers[0].W, g.ProdScalar(proc.Layers[1].W, g.NewScalar(2)))
+	g.Backward(g.ReduceSum(y))
+	assert.Equal(t, []mat.Float{3, 3, 3, 3}, a.Grad().Data())
+	assert.Equal(t, a.Grad(), b.Grad())
+
+	b.ZeroGrad()
+	assert.False(t, a.HasGrad())
+	b.SetRequiresGrad(false)
+	assert.False(t, a.RequiresGrad())
+
+	s := Summary(m)
+	assert.Equal(t, 8, s.TotalParams)
+
+	assert.Panics(t, func() { TieWeights(m.Layers[0].W, m.Layers[0].B) })
+}
+
+func TestTieWeights_Serialization(t *testing.T) {
+	encode := func(m *arithmeticModel) []byte {
+		var buf bytes.Buffer
+		require.NoError(t, gob.NewEncoder(&buf).Encode(m))
+		return buf.Bytes()
+	}
+
+	m := newWideArithmeticModel(2, 16)
+	untied := len(encode(m))
+	TieWeights(m.Layers[0].W, m.Layers[1].W)
+	m.Layers[0].W.ReplaceValue(mat.NewInitDense(16, 16, 3))
+	data := encode(m)
+	assert.LessOrEqual(t, len(data), untied-16*16*4)
+
+	var decoded *arithmeticModel
+	require.NoError(t, gob.NewDecoder(bytes.NewReader(data)).Decode(&decoded))
+	ReifyForInference(decoded, ag.NewGraph())
+	assert.Equal(t, decoded.Layers[0].W, TiedOwner(decoded.Layers[1].W))
+	assert.Equal(t, mat.NewInitDense(16, 16, 3).Data(), decoded.Layers[1].W.Value().Data())
+
+	var partial *arithmeticModel
+	require.NoError(t, gob.NewDecoder(bytes.NewReader(data)).Decode(&partial))
+	partial.Layers = partial.Layers[1:]
+	assert.Error(t, RestoreTiedWeights(partial))
+}
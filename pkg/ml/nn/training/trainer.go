@@ -0,0 +1,347 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package training provides a generic training loop of the models, handling
+// the epochs, the shuffling of the examples, the batching, the optimization
+// steps, the progress reporting, the validation, the early stopping and the
+// recovery from the divergences.
+package training
+
+import (
+	"fmt"
+	"io"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/mat32/rand"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd"
+	"github.com/nlpodyssey/spago/pkg/utils"
+)
+
+// LossFunc returns the loss of the i-th example of the dataset, computed by
+// the model reified for training on the graph of the current batch (see
+// nn.Model.Graph). It can return nil to skip the example.
+type LossFunc func(m nn.Model, i int) ag.Node
+
+// ValidationFunc returns the score of the model at the end of an epoch (e.g.
+// the accuracy on the validation set, or the opposite of the validation
+// loss): the greater the better.
+type ValidationFunc func(epoch int) mat.Float
+
+// BatchReport is the report of a training batch.
+type BatchReport struct {
+	// Epoch is the index of the epoch, starting from zero.
+	Epoch int
+	// Batch is the index of the batch in the epoch, starting from zero.
+	Batch int
+	// Examples is the number of examples of the batch, skipped ones excluded.
+	Examples int
+	// Loss is the mean loss of the examples of the batch.
+	Loss mat.Float
+	// Diverged reports whether the loss has diverged, so that the training
+	// has been rolled back to the last checkpoint (see WithWatchdog).
+	Diverged bool
+}
+
+// EpochReport is the report of a training epoch.
+type EpochReport struct {
+	// Epoch is the index of the epoch, starting from zero.
+	Epoch int
+	// Loss is the mean loss of the examples of the epoch.
+	Loss mat.Float
+	// Validated reports whether the model has been validated (see WithValidation).
+	Validated bool
+	// Score is the validation score.
+	Score mat.Float
+	// Best reports whether the validation score is the best one so far.
+	Best bool
+}
+
+// Summary is the summary of a training.
+type Summary struct {
+	// Epochs is the number of epochs performed.
+	Epochs int
+	// BestEpoch is the index of the epoch with the best validation score, or
+	// -1 if the model has not been validated.
+	BestEpoch int
+	// BestScore is the best validation score.
+	BestScore mat.Float
+	// EarlyStopped reports whether the training has been stopped because the
+	// validation score didn't improve (see EarlyStopping).
+	EarlyStopped bool
+	// Recoveries is the number of times the training has been rolled back
+	// after a divergence (see WithWatchdog).
+	Recoveries int
+	// Diverged reports whether the training has been stopped because it kept
+	// diverging after the maximum number of recoveries.
+	Diverged bool
+	// Err is the error of the checkpoints of the watchdog, if any, which
+	// stopped the training.
+	Err error
+}
+
+// Trainer implements a generic training loop: at each epoch, the examples of
+// the dataset are optionally shuffled and split into batches; the losses of
+// the examples of each batch are computed on a new graph, and the optimizer
+// is stepped on their sum. The optimizer beats the occurrence of each
+// example, batch and epoch (see gd.GradientDescent.IncExample), so that its
+// clock and schedulers follow the training.
+type Trainer struct {
+	model     nn.Model
+	optimizer *gd.GradientDescent
+	size      int
+	loss      LossFunc
+	epochs    int
+	batchSize int
+	rand      *rand.LockedRand
+	graphOpts []ag.GraphOption
+	validate  ValidationFunc
+	patience  int
+	onBatch   []func(r BatchReport)
+	onEpoch   []func(r EpochReport)
+	progress  io.Writer
+	watchdog  *watchdog
+}
+
+// Option allows to configure a new Trainer with your specific needs.
+type Option func(*Trainer)
+
+// Epochs sets the number of epochs (default 1).
+func Epochs(n int) Option {
+	if n < 1 {
+		panic("training: the number of epochs must be greater than zero")
+	}
+	return func(t *Trainer) {
+		t.epochs = n
+	}
+}
+
+// BatchSize sets the number of examples of each batch (default 1).
+func BatchSize(n int) Option {
+	if n < 1 {
+		panic("training: the batch size must be greater than zero")
+	}
+	return func(t *Trainer) {
+		t.batchSize = n
+	}
+}
+
+// Shuffle sets the generator shuffling the examples at each epoch. By
+// default, the examples are visited in order.
+// The order of the examples of an epoch depends only on the state of the
+// generator at its beginning, which can be captured at the end of the
+// previous epoch to resume the training (see rand.CaptureState).
+func Shuffle(generator *rand.LockedRand) Option {
+	return func(t *Trainer) {
+		t.rand = generator
+	}
+}
+
+// GraphOptions sets the options of the graph of each batch.
+func GraphOptions(opts ...ag.GraphOption) Option {
+	return func(t *Trainer) {
+		t.graphOpts = opts
+	}
+}
+
+// WithValidation sets the function validating the model at the end of each
+// epoch.
+func WithValidation(f ValidationFunc) Option {
+	return func(t *Trainer) {
+		t.validate = f
+	}
+}
+
+// EarlyStopping stops the training when the validation score hasn't improved
+// for the given number of consecutive epochs (see WithValidation).
+func EarlyStopping(patience int) Option {
+	if patience < 1 {
+		panic("training: the patience must be greater than zero")
+	}
+	return func(t *Trainer) {
+		t.patience = patience
+	}
+}
+
+// OnBatchEnd adds a callback called at the end of each batch.
+func OnBatchEnd(f func(r BatchReport)) Option {
+	return func(t *Trainer) {
+		t.onBatch = append(t.onBatch, f)
+	}
+}
+
+// OnEpochEnd adds a callback called at the end of each epoch, after the
+// validation (e.g. to save the model when its score is the best one).
+func OnEpochEnd(f func(r EpochReport)) Option {
+	return func(t *Trainer) {
+		t.onEpoch = append(t.onEpoch, f)
+	}
+}
+
+// WithProgress sets the writer of the progress of the training, reported at
+// the end of each epoch.
+func WithProgress(w io.Writer) Option {
+	return func(t *Trainer) {
+		t.progress = w
+	}
+}
+
+// New returns a new Trainer of the model, optimized by the given optimizer on
+// a dataset of the given size, with the loss of each example computed by the
+// loss function.
+// It panics if the early stopping is set without the validation.
+func New(model nn.Model, optimizer *gd.GradientDescent, size int, loss LossFunc, opts ...Option) *Trainer {
+	t := &Trainer{
+		model:     model,
+		optimizer: optimizer,
+		size:      size,
+		loss:      loss,
+		epochs:    1,
+		batchSize: 1,
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	if t.patience > 0 && t.validate == nil {
+		panic("training: the early stopping requires the validation")
+	}
+	return t
+}
+
+// Train executes the training, returning its summary.
+func (t *Trainer) Train() Summary {
+	summary := Summary{BestEpoch: -1}
+	if t.watchdog != nil {
+		if summary.Err = t.watchdog.init(t.model); summary.Err != nil {
+			return summary
+		}
+	}
+	waiting := 0
+	for epoch := 0; epoch < t.epochs; epoch++ {
+		indices := utils.MakeIndices(t.size)
+		if t.rand != nil {
+			rand.ShuffleInPlace(indices, t.rand)
+		}
+		report := EpochReport{Epoch: epoch}
+		examples := 0
+		for batch, start := 0, 0; start < t.size; batch, start = batch+1, start+t.batchSize {
+			end := utils.MinInt(start+t.batchSize, t.size)
+			r := t.trainBatch(indices[start:end])
+			r.Epoch, r.Batch = epoch, batch
+			if r.Examples > 0 && t.watchdog != nil {
+				summary.Err = t.watch(&r, &summary)
+			}
+			if !r.Diverged {
+				report.Loss += r.Loss * mat.Float(r.Examples)
+				examples += r.Examples
+			}
+			for _, f := range t.onBatch {
+				f(r)
+			}
+			if summary.Diverged || summary.Err != nil {
+				return summary
+			}
+		}
+		if examples > 0 {
+			report.Loss /= mat.Float(examples)
+		}
+		t.optimizer.IncEpoch()
+		summary.Epochs++
+
+		if t.validate != nil {
+			report.Validated = true
+			report.Score = t.validate(epoch)
+			if summary.BestEpoch == -1 || report.Score > summary.BestScore {
+				summary.BestEpoch, summary.BestScore = epoch, report.Score
+				report.Best = true
+				waiting = 0
+			} else {
+				waiting++
+			}
+		}
+		t.report(report)
+		for _, f := range t.onEpoch {
+			f(report)
+		}
+		if t.patience > 0 && waiting >= t.patience {
+			summary.EarlyStopped = true
+			break
+		}
+	}
+	return summary
+}
+
+// trainBatch computes the losses of the given examples on a new graph, and
+// optimizes the model.
+func (t *Trainer) trainBatch(indices []int) BatchReport {
+	g := ag.NewGraph(t.graphOpts...)
+	defer g.Clear()
+	m := nn.ReifyForTraining(t.model, g)
+	losses := make([]ag.Node, 0, len(indices))
+	for _, i := range indices {
+		if loss := t.loss(m, i); loss != nil {
+			losses = append(losses, loss)
+			t.optimizer.IncExample()
+		}
+	}
+	t.optimizer.IncBatch()
+	if len(losses) == 0 {
+		return BatchReport{}
+	}
+	loss := g.Sum(losses...)
+	g.Backward(loss)
+	t.optimizer.Optimize()
+	return BatchReport{
+		Examples: len(losses),
+		Loss:     loss.ScalarValue() / mat.Float(len(losses)),
+	}
+}
+
+// watch checks the batch with the watchdog, recovering the training if it
+// has diverged.
+func (t *Trainer) watch(r *BatchReport, summary *Summary) error {
+	w := t.watchdog
+	if !w.diverged(r.Loss) {
+		return w.observe(r.Loss)
+	}
+	r.Diverged = true
+	if w.exhausted() {
+		summary.Diverged = true
+		t.reportf("epoch %d, batch %d: loss %g diverged too many times, the training is stopped",
+			r.Epoch+1, r.Batch+1, r.Loss)
+		return nil
+	}
+	scale, err := w.recover(t.optimizer.LRScale())
+	if err != nil {
+		return err
+	}
+	summary.Recoveries++
+	t.optimizer.SetLRScale(scale)
+	t.reportf("epoch %d, batch %d: loss %g diverged, rolled back to the last checkpoint (learning rate scale %g)",
+		r.Epoch+1, r.Batch+1, r.Loss, scale)
+	return nil
+}
+
+// reportf writes a line of the progress of the training, if enabled.
+func (t *Trainer) reportf(format string, a ...interface{}) {
+	if t.progress != nil {
+		fmt.Fprintf(t.progress, format+"\n", a...)
+	}
+}
+
+// report writes the progress of the training at the end of an epoch.
+func (t *Trainer) report(r EpochReport) {
+	if t.progress == nil {
+		return
+	}
+	line := fmt.Sprintf("epoch %d/%d: loss %.6f", r.Epoch+1, t.epochs, r.Loss)
+	if r.Validated {
+		line += fmt.Sprintf(", score %.6f", r.Score)
+		if r.Best {
+			line += " (best)"
+		}
+	}
+	fmt.Fprintln(t.progress, line)
+}
@@ -0,0 +1,135 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package training
+
+import (
+	"bytes"
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/mat32/rand"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/nlpodyssey/spago/pkg/ml/losses"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/ml/nn/linear"
+	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd"
+	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd/sgd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// regression is a dataset of examples of y = 2x + 1.
+var regression = []mat.Float{-1, -0.5, 0, 0.5, 1, 1.5}
+
+func regressionLoss(m nn.Model, i int) ag.Node {
+	g := m.Graph()
+	x := regression[i]
+	y := m.(*linear.Model).Forward(g.NewScalar(x))[0]
+	return losses.MSE(g, y, g.NewScalar(2*x+1), false)
+}
+
+func newTestTrainer(opts ...Option) (*Trainer, *linear.Model, *gd.Clock) {
+	model := linear.New(1, 1)
+	clock := gd.NewClock()
+	optimizer := gd.NewOptimizer(sgd.New(sgd.NewConfig(0.1, 0, false)), nn.NewDefaultParamsIterator(model), gd.WithClock(clock))
+	return New(model, optimizer, len(regression), regressionLoss, opts...), model, clock
+}
+
+func TestTrainer_Train(t *testing.T) {
+	var batches []BatchReport
+	var epochs []EpochReport
+	trainer, model, clock := newTestTrainer(
+		Epochs(50),
+		BatchSize(4),
+		Shuffle(rand.NewLockedRand(42)),
+		OnBatchEnd(func(r BatchReport) { batches = append(batches, r) }),
+		OnEpochEnd(func(r EpochReport) { epochs = append(epochs, r) }),
+	)
+	summary := trainer.Train()
+	assert.Equal(t, Summary{Epochs: 50, BestEpoch: -1}, summary)
+
+	assert.InDelta(t, 2, model.W.Value().Scalar(), 1.0e-3)
+	assert.InDelta(t, 1, model.B.Value().Scalar(), 1.0e-3)
+
+	require.Len(t, batches, 100)
+	assert.Equal(t, BatchReport{Epoch: 0, Batch: 1, Examples: 2, Loss: batches[1].Loss}, batches[1])
+	require.Len(t, epochs, 50)
+	assert.True(t, epochs[49].Loss < epochs[0].Loss)
+	assert.False(t, epochs[0].Validated)
+	assert.Equal(t, gd.Time{Epochs: 50, Batches: 100, Steps: 100, Examples: 300}, clock.Now())
+}
+
+func TestTrainer_EarlyStopping(t *testing.T) {
+	scores := []mat.Float{0.1, 0.3, 0.2, 0.3, 0.25, 0.9}
+	var saved []int
+	progress := new(bytes.Buffer)
+	trainer, _, _ := newTestTrainer(
+		Epochs(6),
+		WithValidation(func(epoch int) mat.Float { return scores[epoch] }),
+		EarlyStopping(3),
+		OnEpochEnd(func(r EpochReport) {
+			if r.Best {
+				saved = append(saved, r.Epoch)
+			}
+		}),
+		WithProgress(progress),
+	)
+	summary := trainer.Train()
+	assert.Equal(t, Summary{Epochs: 5, BestEpoch: 1, BestScore: 0.3, EarlyStopped: true}, summary)
+	assert.Equal(t, []int{0, 1}, saved)
+	assert.Contains(t, progress.String(), "epoch 2/6: loss ")
+	assert.Contains(t, progress.String(), ", score 0.300000 (best)\n")
+	assert.Equal(t, 5, bytes.Count(progress.Bytes(), []byte("\n")))
+}
+
+func TestTrainer_SkippedExamples(t *testing.T) {
+	model := linear.New(1, 1)
+	optimizer := gd.NewOptimizer(sgd.New(sgd.NewConfig(0.1, 0, false)), nn.NewDefaultParamsIterator(model))
+	var batches []BatchReport
+	trainer := New(model, optimizer, len(regression), func(m nn.Model, i int) ag.Node {
+		if i < 3 {
+			return nil
+		}
+		return regressionLoss(m, i)
+	}, BatchSize(3), OnBatchEnd(func(r BatchReport) { batches = append(batches, r) }))
+	trainer.Train()
+	require.Len(t, batches, 2)
+	assert.Equal(t, BatchReport{Epoch: 0, Batch: 0}, batches[0])
+	assert.Equal(t, 3, batches[1].Examples)
+}
+
+func TestNew(t *testing.T) {
+	assert.Panics(t, func() { newTestTrainer(EarlyStopping(2)) })
+	assert.Panics(t, func() { Epochs(0) })
+	assert.Panics(t, func() { BatchSize(0) })
+	assert.Panics(t, func() { EarlyStopping(0) })
+}
+
+func TestTrainer_Resume(t *testing.T) {
+	var order []int
+	newTrainer := func(generator *rand.LockedRand, epochs int) *Trainer {
+		model := linear.New(1, 1)
+		optimizer := gd.NewOptimizer(sgd.New(sgd.NewConfig(0.1, 0, false)), nn.NewDefaultParamsIterator(model))
+		return New(model, optimizer, len(regression), func(m nn.Model, i int) ag.Node {
+			order = append(order, i)
+			return regressionLoss(m, i)
+		}, Epochs(epochs), Shuffle(generator))
+	}
+
+	newTrainer(rand.NewLockedRand(42), 4).Train()
+	expected := order
+
+	// the state of the generator is captured at the end of the second epoch
+	order = nil
+	generator := rand.NewLockedRand(42)
+	newTrainer(generator, 2).Train()
+	state, err := rand.CaptureState(map[string]*rand.LockedRand{"shuffle": generator})
+	require.NoError(t, err)
+
+	resumed := rand.NewLockedRand(1)
+	require.NoError(t, state.Restore(map[string]*rand.LockedRand{"shuffle": resumed}))
+	newTrainer(resumed, 2).Train()
+	assert.Equal(t, expected, order)
+}
@@ -0,0 +1,229 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package training
+
+import (
+	"errors"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+)
+
+// Checkpointer saves and restores the state of the training, i.e. the values
+// of the parameters of the model along with the support structures of the
+// optimizer (see nn.Payload).
+type Checkpointer interface {
+	// Save saves the current state, replacing the previous one.
+	Save() error
+	// Restore restores the last saved state.
+	Restore() error
+}
+
+// WatchdogConfig provides the configuration of the watchdog of a Trainer
+// (see WithWatchdog).
+type WatchdogConfig struct {
+	// CheckpointEvery is the number of healthy batches between the
+	// checkpoints of the training.
+	CheckpointEvery int
+	// SpikeFactor defines a loss spike: a loss greater than SpikeFactor times
+	// the moving average of the previous ones. Zero disables the detection
+	// of the spikes, so that only the non-finite losses are divergences.
+	SpikeFactor mat.Float
+	// Warmup is the number of healthy batches before the spikes are
+	// detected, so that the moving average has settled.
+	Warmup int
+	// Smoothing is the weight of the previous losses in their exponential
+	// moving average, in [0, 1).
+	Smoothing mat.Float
+	// LRDecay multiplies the learning rate at each recovery (see
+	// gd.GradientDescent.SetLRScale).
+	LRDecay mat.Float
+	// MaxRecoveries is the number of recoveries after which the training is
+	// stopped, since it keeps diverging.
+	MaxRecoveries int
+	// Checkpointer saves and restores the state of the training. If nil, the
+	// state is kept in memory.
+	Checkpointer Checkpointer
+}
+
+// NewDefaultWatchdogConfig returns a new WatchdogConfig with the default
+// values: a checkpoint every 100 batches, spikes of 10 times the average loss
+// after 20 batches, the learning rate halved at each recovery, and at most 5
+// recoveries.
+func NewDefaultWatchdogConfig() WatchdogConfig {
+	return WatchdogConfig{
+		CheckpointEvery: 100,
+		SpikeFactor:     10,
+		Warmup:          20,
+		Smoothing:       0.9,
+		LRDecay:         0.5,
+		MaxRecoveries:   5,
+	}
+}
+
+// WithWatchdog sets a watchdog recovering the training from its divergences,
+// so that long runs don't need to be restarted by hand. The watchdog saves a
+// checkpoint of the training every WatchdogConfig.CheckpointEvery healthy
+// batches (and at its beginning); when the loss of a batch is not finite or
+// spikes, it restores the last checkpoint, reduces the learning rate, and
+// resumes the training from the next batch.
+//
+// The diverged batches are excluded from the loss of the epoch, and reported
+// (see BatchReport.Diverged). The training is stopped after too many
+// recoveries, or if the checkpoints fail (see Summary).
+//
+// It panics if the configuration is invalid.
+func WithWatchdog(config WatchdogConfig) Option {
+	switch {
+	case config.CheckpointEvery < 1:
+		panic("training: the watchdog checkpoint interval must be greater than zero")
+	case config.SpikeFactor < 0 || (config.SpikeFactor > 0 && config.SpikeFactor <= 1):
+		panic("training: the watchdog spike factor must be zero or greater than one")
+	case config.Smoothing < 0 || config.Smoothing >= 1:
+		panic("training: the watchdog smoothing must be in [0, 1)")
+	case config.LRDecay <= 0 || config.LRDecay > 1:
+		panic("training: the watchdog learning rate decay must be in (0, 1]")
+	case config.MaxRecoveries < 0:
+		panic("training: the watchdog maximum recoveries must not be negative")
+	}
+	return func(t *Trainer) {
+		t.watchdog = &watchdog{config: config}
+	}
+}
+
+// watchdog detects the divergences of the training of a Trainer.
+type watchdog struct {
+	config WatchdogConfig
+	// average is the moving average of the healthy losses.
+	average mat.Float
+	// healthy is the number of healthy batches.
+	healthy int
+	// sinceCheckpoint is the number of healthy batches since the last
+	// checkpoint.
+	sinceCheckpoint int
+	// recoveries is the number of recoveries.
+	recoveries int
+}
+
+// init sets the default checkpointer of the model, if needed, and saves the
+// initial checkpoint.
+func (w *watchdog) init(m nn.Model) error {
+	if w.config.Checkpointer == nil {
+		w.config.Checkpointer = NewMemoryCheckpointer(m)
+	}
+	return w.config.Checkpointer.Save()
+}
+
+// diverged reports whether the loss of a batch is a divergence.
+func (w *watchdog) diverged(loss mat.Float) bool {
+	if loss != loss || mat.IsInf(loss, 0) { // NaN or infinite
+		return true
+	}
+	return w.config.SpikeFactor > 0 && w.healthy >= w.config.Warmup &&
+		w.average > 0 && loss > w.config.SpikeFactor*w.average
+}
+
+// observe updates the moving average with the loss of a healthy batch, and
+// saves a checkpoint if it's time to.
+func (w *watchdog) observe(loss mat.Float) error {
+	if w.healthy == 0 {
+		w.average = loss
+	} else {
+		w.average = w.config.Smoothing*w.average + (1-w.config.Smoothing)*loss
+	}
+	w.healthy++
+	w.sinceCheckpoint++
+	if w.sinceCheckpoint < w.config.CheckpointEvery {
+		return nil
+	}
+	w.sinceCheckpoint = 0
+	return w.config.Checkpointer.Save()
+}
+
+// recover restores the last checkpoint, and returns the new scale of the
+// learning rate given the current one.
+func (w *watchdog) recover(lrScale mat.Float) (mat.Float, error) {
+	w.recoveries++
+	w.sinceCheckpoint = 0
+	if err := w.config.Checkpointer.Restore(); err != nil {
+		return lrScale, err
+	}
+	return lrScale * w.config.LRDecay, nil
+}
+
+// exhausted reports whether the maximum number of recoveries is reached.
+func (w *watchdog) exhausted() bool {
+	return w.recoveries >= w.config.MaxRecoveries
+}
+
+var _ Checkpointer = &MemoryCheckpointer{}
+
+var errMismatchingCheckpoint = errors.New("training: the parameters of the model don't match the checkpoint")
+
+// MemoryCheckpointer is a Checkpointer keeping a copy of the values and of
+// the payloads of the parameters of a model in memory, once for the tied
+// parameters (see nn.ForEachDistinctParam).
+type MemoryCheckpointer struct {
+	model    nn.Model
+	saved    bool
+	values   []mat.Matrix
+	payloads []*nn.Payload
+}
+
+// NewMemoryCheckpointer returns a new MemoryCheckpointer of the model.
+func NewMemoryCheckpointer(m nn.Model) *MemoryCheckpointer {
+	return &MemoryCheckpointer{model: m}
+}
+
+// Save copies the values and the payloads of the parameters.
+func (c *MemoryCheckpointer) Save() error {
+	c.saved, c.values, c.payloads = true, c.values[:0], c.payloads[:0]
+	nn.ForEachDistinctParam(c.model, func(p nn.Param) {
+		c.values = append(c.values, p.Value().Clone())
+		c.payloads = append(c.payloads, clonePayload(p.Payload()))
+	})
+	return nil
+}
+
+// Restore replaces the values and the payloads of the parameters with the
+// saved ones. It returns an error if nothing has been saved, or if the
+// parameters of the model have changed since.
+func (c *MemoryCheckpointer) Restore() error {
+	if !c.saved {
+		return errors.New("training: no checkpoint to restore")
+	}
+	i := 0
+	var err error
+	nn.ForEachDistinctParam(c.model, func(p nn.Param) {
+		if err != nil {
+			return
+		}
+		if i >= len(c.values) || !mat.SameDims(p.Value(), c.values[i]) {
+			err = errMismatchingCheckpoint
+			return
+		}
+		p.ReplaceValue(c.values[i].Clone())
+		if payload := clonePayload(c.payloads[i]); payload != nil {
+			p.SetPayload(payload)
+		}
+		i++
+	})
+	if err == nil && i != len(c.values) {
+		err = errMismatchingCheckpoint
+	}
+	return err
+}
+
+// clonePayload returns a deep copy of the payload, or nil.
+func clonePayload(p *nn.Payload) *nn.Payload {
+	if p == nil {
+		return nil
+	}
+	data := make([]mat.Matrix, len(p.Data))
+	for i, m := range p.Data {
+		data[i] = m.Clone()
+	}
+	return &nn.Payload{Label: p.Label, Data: data}
+}
@@ -0,0 +1,180 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package training
+
+import (
+	"bytes"
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/ml/nn/linear"
+	"github.com/nlpodyssey/spago/pkg/ml/nn/stack"
+	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd"
+	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd/sgd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFaultyTrainer returns a trainer of the regression whose loss is
+// multiplied by the factor returned by fault for each call, e.g. NaN to make
+// the training diverge.
+func newFaultyTrainer(fault func(call int) mat.Float, opts ...Option) (*Trainer, *linear.Model) {
+	model := linear.New(1, 1)
+	optimizer := gd.NewOptimizer(sgd.New(sgd.NewConfig(0.1, 0.9, false)), nn.NewDefaultParamsIterator(model))
+	call := 0
+	loss := func(m nn.Model, i int) ag.Node {
+		call++
+		g := m.Graph()
+		return g.ProdScalar(regressionLoss(m, i), g.NewScalar(fault(call)))
+	}
+	return New(model, optimizer, len(regression), loss, opts...), model
+}
+
+func watchdogTestConfig() WatchdogConfig {
+	config := NewDefaultWatchdogConfig()
+	config.CheckpointEvery = 6
+	config.Warmup = 6
+	return config
+}
+
+func TestTrainer_Watchdog(t *testing.T) {
+	for name, factor := range map[string]mat.Float{
+		"NaN":   mat.NaN(),
+		"Inf":   mat.Inf(1),
+		"spike": 1.0e6,
+	} {
+		factor := factor
+		t.Run(name, func(t *testing.T) {
+			var diverged []BatchReport
+			var progress bytes.Buffer
+			trainer, model := newFaultyTrainer(
+				func(call int) mat.Float {
+					if call == 40 {
+						return factor
+					}
+					return 1
+				},
+				Epochs(100),
+				WithWatchdog(watchdogTestConfig()),
+				WithProgress(&progress),
+				OnBatchEnd(func(r BatchReport) {
+					if r.Diverged {
+						diverged = append(diverged, r)
+					}
+				}),
+			)
+			summary := trainer.Train()
+			require.NoError(t, summary.Err)
+			assert.Equal(t, 1, summary.Recoveries)
+			assert.False(t, summary.Diverged)
+			assert.Equal(t, 100, summary.Epochs)
+			require.Len(t, diverged, 1)
+			assert.Equal(t, 6, diverged[0].Epoch)
+			assert.Equal(t, 3, diverged[0].Batch)
+			assert.Equal(t, mat.Float(0.5), trainer.optimizer.LRScale())
+			assert.Contains(t, progress.String(), "epoch 7, batch 4: loss")
+			assert.Contains(t, progress.String(), "rolled back to the last checkpoint (learning rate scale 0.5)")
+
+			assert.InDelta(t, 2, model.W.Value().Scalar(), 1.0e-3)
+			assert.InDelta(t, 1, model.B.Value().Scalar(), 1.0e-3)
+		})
+	}
+}
+
+func TestTrainer_WatchdogExhausted(t *testing.T) {
+	config := watchdogTestConfig()
+	config.MaxRecoveries = 3
+	trainer, model := newFaultyTrainer(
+		func(call int) mat.Float {
+			if call > 20 {
+				return mat.NaN()
+			}
+			return 1
+		},
+		Epochs(100),
+		WithWatchdog(config),
+	)
+	summary := trainer.Train()
+	require.NoError(t, summary.Err)
+	assert.True(t, summary.Diverged)
+	assert.Equal(t, 3, summary.Recoveries)
+	assert.Equal(t, 3, summary.Epochs)
+	assert.Equal(t, mat.Float(0.125), trainer.optimizer.LRScale())
+	assert.False(t, mat.IsInf(model.W.Value().Scalar(), 0))
+}
+
+func TestWatchdog_Diverged(t *testing.T) {
+	w := &watchdog{config: watchdogTestConfig()}
+	w.config.Checkpointer = NewMemoryCheckpointer(linear.New(1, 1))
+	assert.True(t, w.diverged(mat.NaN()))
+	assert.True(t, w.diverged(mat.Inf(-1)))
+
+	// The spikes are not detected until the moving average has settled.
+	for i := 0; i < w.config.Warmup; i++ {
+		assert.False(t, w.diverged(100), i)
+		require.NoError(t, w.observe(1))
+	}
+	assert.True(t, w.diverged(100))
+	assert.False(t, w.diverged(5))
+
+	w.config.SpikeFactor = 0
+	assert.False(t, w.diverged(100))
+	assert.True(t, w.diverged(mat.NaN()))
+}
+
+func TestWithWatchdog(t *testing.T) {
+	for name, edit := range map[string]func(c *WatchdogConfig){
+		"CheckpointEvery": func(c *WatchdogConfig) { c.CheckpointEvery = 0 },
+		"SpikeFactor":     func(c *WatchdogConfig) { c.SpikeFactor = 0.5 },
+		"Smoothing":       func(c *WatchdogConfig) { c.Smoothing = 1 },
+		"LRDecay":         func(c *WatchdogConfig) { c.LRDecay = 0 },
+		"MaxRecoveries":   func(c *WatchdogConfig) { c.MaxRecoveries = -1 },
+	} {
+		config := NewDefaultWatchdogConfig()
+		edit(&config)
+		assert.Panics(t, func() { WithWatchdog(config) }, name)
+	}
+	config := NewDefaultWatchdogConfig()
+	config.SpikeFactor = 0
+	assert.NotPanics(t, func() { WithWatchdog(config) })
+}
+
+func TestMemoryCheckpointer(t *testing.T) {
+	model := linear.New(1, 2)
+	checkpointer := NewMemoryCheckpointer(model)
+	assert.Error(t, checkpointer.Restore())
+
+	model.W.ReplaceValue(mat.NewDense(2, 1, []mat.Float{1, 2}))
+	model.W.SetPayload(&nn.Payload{Label: gd.SGD, Data: []mat.Matrix{mat.NewDense(2, 1, []mat.Float{3, 4})}})
+	require.NoError(t, checkpointer.Save())
+
+	model.W.ReplaceValue(mat.NewDense(2, 1, []mat.Float{5, 6}))
+	model.W.SetPayload(&nn.Payload{Label: gd.SGD, Data: []mat.Matrix{mat.NewDense(2, 1, []mat.Float{7, 8})}})
+	for i := 0; i < 2; i++ {
+		require.NoError(t, checkpointer.Restore())
+		assert.Equal(t, []mat.Float{1, 2}, model.W.Value().Data())
+		assert.Equal(t, []mat.Float{3, 4}, model.W.Payload().Data[0].Data())
+		model.W.Value().Data()[0] = 9
+		model.W.Payload().Data[0].Data()[0] = 9
+	}
+
+	model.W.ReplaceValue(mat.NewEmptyDense(3, 1))
+	assert.Error(t, checkpointer.Restore())
+}
+
+func TestMemoryCheckpointer_TiedParams(t *testing.T) {
+	l1, l2 := linear.New(2, 2), linear.New(2, 2)
+	nn.TieWeights(l1.W, l2.W)
+	checkpointer := NewMemoryCheckpointer(stack.New(l1, l2))
+	l1.W.ReplaceValue(mat.NewDense(2, 2, []mat.Float{1, 2, 3, 4}))
+	require.NoError(t, checkpointer.Save())
+	assert.Len(t, checkpointer.values, 3) // the tied weights are saved once
+
+	l2.W.ReplaceValue(mat.NewEmptyDense(2, 2))
+	require.NoError(t, checkpointer.Restore())
+	assert.Equal(t, []mat.Float{1, 2, 3, 4}, l2.W.Value().Data())
+}
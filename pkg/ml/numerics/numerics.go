@@ -0,0 +1,132 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package numerics provides the numeric tolerances used across the layers,
+// the operators and the optimizers, such as the epsilon added to the
+// variance by the layer normalization.
+//
+// The tolerances are gathered in a Policy, so that they can be set at once
+// to the ones of another framework, which is often needed to reproduce the
+// outputs of a model ported from it:
+//
+//     numerics.Set(numerics.PyTorch())
+//
+// The layers read the policy in use at each forward, whereas the optimizers
+// read it when their default configuration is created.
+package numerics
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+)
+
+// Policy contains the numeric tolerances.
+type Policy struct {
+	// LayerNorm is added to the variance by the layer normalization.
+	LayerNorm mat.Float
+	// BatchNorm is added to the standard deviation and to the counts by the
+	// batch normalization.
+	BatchNorm mat.Float
+	// Norm is added to the norm (or the standard deviation) of the input by
+	// the other normalizations, i.e. RMSNorm, ScaleNorm, FixNorm, the simple
+	// layer normalization and AdaNorm, and by the NRU.
+	Norm mat.Float
+	// Division is added to the denominators of the linear attention, e.g. the
+	// RLA and the Star-Transformer.
+	Division mat.Float
+	// Log replaces the zero arguments of the logarithm, so that its value and
+	// its derivative are finite.
+	Log mat.Float
+	// Optimizer is the epsilon of the default configuration of the adaptive
+	// optimizers, i.e. Adam, RAdam, AdaGrad and RMSProp.
+	Optimizer mat.Float
+}
+
+// Default returns the default policy of spaGO.
+func Default() Policy {
+	return Policy{
+		LayerNorm: 1.0e-12,
+		BatchNorm: 1.0e-5,
+		Norm:      1.0e-10,
+		Division:  1.0e-12,
+		Log:       1.0e-8,
+		Optimizer: 1.0e-8,
+	}
+}
+
+// PyTorch returns the Default policy with the default tolerances of the
+// PyTorch layers and optimizers.
+func PyTorch() Policy {
+	p := Default()
+	p.LayerNorm = 1.0e-5
+	p.BatchNorm = 1.0e-5
+	p.Optimizer = 1.0e-8
+	return p
+}
+
+// TensorFlow returns the Default policy with the default tolerances of the
+// Keras layers and optimizers of TensorFlow.
+func TensorFlow() Policy {
+	p := Default()
+	p.LayerNorm = 1.0e-3
+	p.BatchNorm = 1.0e-3
+	p.Optimizer = 1.0e-7
+	return p
+}
+
+// Validate returns an error if any tolerance is negative.
+func (p Policy) Validate() error {
+	values := []struct {
+		name  string
+		value mat.Float
+	}{
+		{"LayerNorm", p.LayerNorm},
+		{"BatchNorm", p.BatchNorm},
+		{"Norm", p.Norm},
+		{"Division", p.Division},
+		{"Log", p.Log},
+		{"Optimizer", p.Optimizer},
+	}
+	for _, v := range values {
+		if v.value < 0 {
+			return fmt.Errorf("numerics: negative %s tolerance %g", v.name, v.value)
+		}
+	}
+	return nil
+}
+
+var (
+	current atomic.Value
+	// mu serializes the calls to Set.
+	mu sync.Mutex
+)
+
+func init() {
+	current.Store(Default())
+}
+
+// Current returns the policy in use.
+func Current() Policy {
+	return current.Load().(Policy)
+}
+
+// Set sets the policy in use, returning the previous one. It panics if the
+// policy is invalid.
+//
+// The policy is global: it should be set before the models are used (e.g.
+// right after loading a ported checkpoint), since the graphs being built
+// while it changes may mix the old and the new tolerances.
+func Set(p Policy) (previous Policy) {
+	if err := p.Validate(); err != nil {
+		panic(err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	previous = Current()
+	current.Store(p)
+	return previous
+}
@@ -0,0 +1,39 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package numerics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSet(t *testing.T) {
+	assert.Equal(t, Default(), Current())
+
+	previous := Set(PyTorch())
+	assert.Equal(t, Default(), previous)
+	assert.Equal(t, PyTorch(), Current())
+	assert.Equal(t, float32(1.0e-5), float32(Current().LayerNorm))
+
+	Set(previous)
+	assert.Equal(t, Default(), Current())
+}
+
+func TestSet_Invalid(t *testing.T) {
+	p := Default()
+	p.Norm = -1
+	assert.EqualError(t, p.Validate(), "numerics: negative Norm tolerance -1")
+	assert.Panics(t, func() { Set(p) })
+	assert.Equal(t, Default(), Current())
+}
+
+func TestPresets(t *testing.T) {
+	for _, p := range []Policy{Default(), PyTorch(), TensorFlow()} {
+		assert.NoError(t, p.Validate())
+	}
+	assert.Equal(t, float32(1.0e-3), float32(TensorFlow().LayerNorm))
+	assert.Equal(t, float32(1.0e-7), float32(TensorFlow().Optimizer))
+}
@@ -30,6 +30,8 @@ type DifferentialEvolution struct {
 	bestSolution *ScoredVector
 	// Optimization state
 	state *State
+	// The generator of random numbers used to mutate the hyper-parameters
+	rndGen *rand.LockedRand
 }
 
 // State represents a status of the differential evolution process.
@@ -111,6 +113,7 @@ func NewOptimizer(
 			CurOptimizationStep:     0,
 			countBestScoreUnchanged: 0,
 		},
+		rndGen: rand.NewLockedRand(config.Seed),
 	}
 }
 
@@ -167,7 +170,7 @@ func (o *DifferentialEvolution) evaluateTrials() {
 			member.TargetScore = member.TrialScore
 			member.TargetVector = member.DonorVector.Clone()
 			if o.Adaptive {
-				member.MutateHyperParams(0.1, 0.9) // TODO: get arguments from the config
+				member.MutateHyperParams(0.1, 0.9, o.rndGen) // TODO: get arguments from the config
 			}
 		}
 	}
@@ -37,15 +37,15 @@ type MemberHyperParams struct {
 
 // MutateHyperParams mutates the hyper-parameters according to l and u.
 // Suggested values: l = 0.1, u = 0.9.
-func (a *MemberHyperParams) MutateHyperParams(l, u mat.Float) {
-	if rand.Float() < 0.1 {
-		a.MutationFactor = l + rand.Float()*u
+func (a *MemberHyperParams) MutateHyperParams(l, u mat.Float, rndGen *rand.LockedRand) {
+	if rndGen.Float() < 0.1 {
+		a.MutationFactor = l + rndGen.Float()*u
 	}
-	if rand.Float() < 0.1 {
-		a.CrossoverRate = rand.Float()
+	if rndGen.Float() < 0.1 {
+		a.CrossoverRate = rndGen.Float()
 	}
-	if rand.Float() < 0.1 {
-		a.WeightFactor = l + rand.Float()*u
+	if rndGen.Float() < 0.1 {
+		a.WeightFactor = l + rndGen.Float()*u
 	}
 }
 
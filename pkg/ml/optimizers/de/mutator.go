@@ -19,23 +19,26 @@ var _ Mutator = &RandomMutation{}
 
 // RandomMutation implements a random mutation operation.
 type RandomMutation struct {
-	Bound mat.Float
+	Bound  mat.Float
+	rndGen *rand.LockedRand
 }
 
 // NewRandomMutation returns a new RandomMutation.
-func NewRandomMutation(bound mat.Float) *RandomMutation {
+func NewRandomMutation(bound mat.Float, rndGen *rand.LockedRand) *RandomMutation {
 	return &RandomMutation{
-		Bound: bound,
+		Bound:  bound,
+		rndGen: rndGen,
 	}
 }
 
 // Mutate executes the mutation generating a "donor vector" for every element of the population.
 // For each vector xi in the current generation, called target vector, a vector yi, called donor vector, is obtained
 // as linear combination of some vectors in the population selected according to DE/rand/1 strategy, where
-//   yi = clip(xa + MutationFactor * (xb − xc))
+//
+//	yi = clip(xa + MutationFactor * (xb − xc))
 func (m *RandomMutation) Mutate(p *Population) {
 	for i, member := range p.Members {
-		extracted := rand.GetUniqueRandomInt(3, len(p.Members), func(r int) bool { return r != i })
+		extracted := rand.GetUniqueRandomInt(3, len(p.Members), func(r int) bool { return r != i }, m.rndGen)
 		xc := p.Members[extracted[2]].TargetVector
 		xb := p.Members[extracted[1]].TargetVector
 		xa := p.Members[extracted[0]].TargetVector
@@ -50,34 +53,38 @@ var _ Mutator = &DeglMutation{}
 // DeglMutation implements Differential Evolution with Global and Local Neighborhoods mutation strategy.
 //
 // Reference:
-//   "Design of Two-Channel Quadrature Mirror Filter Banks Using Differential Evolution with Global and Local Neighborhoods"
-//   Authors: Pradipta Ghosh, Hamim Zafar, Joydeep Banerjee, Swagatam Das (2011)
-//   (https://www.springerprofessional.de/en/design-of-two-channel-quadrature-mirror-filter-banks-using-diffe/3805398)
+//
+//	"Design of Two-Channel Quadrature Mirror Filter Banks Using Differential Evolution with Global and Local Neighborhoods"
+//	Authors: Pradipta Ghosh, Hamim Zafar, Joydeep Banerjee, Swagatam Das (2011)
+//	(https://www.springerprofessional.de/en/design-of-two-channel-quadrature-mirror-filter-banks-using-diffe/3805398)
 type DeglMutation struct {
 	NeighborhoodRadius mat.Float
 	Bound              mat.Float
+	rndGen             *rand.LockedRand
 }
 
 // NewDeglMutation returns a new DeglMutation.
-func NewDeglMutation(NeighborhoodRadius, bound mat.Float) *DeglMutation {
+func NewDeglMutation(NeighborhoodRadius, bound mat.Float, rndGen *rand.LockedRand) *DeglMutation {
 	return &DeglMutation{
 		NeighborhoodRadius: NeighborhoodRadius,
 		Bound:              bound,
+		rndGen:             rndGen,
 	}
 }
 
 // Mutate calculate the mutated vector (donor vector) as:
-//    G = xi + MutationFactor (best − xi) + MutationFactor (xa − xb)
-//    L = xi + MutationFactor (bestNeighbor − xi) + MutationFactor (xc − xd)
-//    yi = clip(w * L + (1-w) * G)
+//
+//	G = xi + MutationFactor (best − xi) + MutationFactor (xa − xb)
+//	L = xi + MutationFactor (bestNeighbor − xi) + MutationFactor (xc − xd)
+//	yi = clip(w * L + (1-w) * G)
 func (m *DeglMutation) Mutate(p *Population) {
 	windowSize := int(mat.Float(len(p.Members)) * m.NeighborhoodRadius)
 	bestIndex, _ := p.FindBest(0, len(p.Members)-1, mat.Inf(+1), 0)
 	for i, member := range p.Members {
 		except := func(r int) bool { return r != i }
-		extracted := rand.GetUniqueRandomInt(2, len(p.Members), except)
+		extracted := rand.GetUniqueRandomInt(2, len(p.Members), except, m.rndGen)
 		neighbors := utils.GetNeighborsIndices(len(p.Members), i, windowSize)
-		extractedNeighbors := rand.GetUniqueRandomIndices(2, neighbors, except)
+		extractedNeighbors := rand.GetUniqueRandomIndices(2, neighbors, except, m.rndGen)
 		bestNeighborIndex, _ := p.FindBestNeighbor(i, windowSize)
 		bestNeighbor := p.Members[bestNeighborIndex].TargetVector
 		best := p.Members[bestIndex].TargetVector
@@ -13,7 +13,7 @@ import (
 
 func TestRandomMutator(t *testing.T) {
 	population := newTestMutator()
-	mutation := NewRandomMutation(6.0)
+	mutation := NewRandomMutation(6.0, rand.NewLockedRand(1))
 	mutation.Mutate(population)
 
 	assert.InDeltaSlice(t, []mat.Float{0.85, -0.1, 2.1, 0.2, 2.85}, population.Members[0].DonorVector.Data(), 0.0001)
@@ -30,19 +30,19 @@ func TestRandomMutator(t *testing.T) {
 
 func TestDeglMutator(t *testing.T) {
 	population := newTestMutator()
-	mutation := NewDeglMutation(0.3, 6.0)
+	mutation := NewDeglMutation(0.3, 6.0, rand.NewLockedRand(1))
 	mutation.Mutate(population)
 
-	assert.InDeltaSlice(t, []mat.Float{-1.35, 0.05, 0.15, 1.075, 1.875}, population.Members[0].DonorVector.Data(), 0.0001)
-	assert.InDeltaSlice(t, []mat.Float{-0.975, -0.7, 0.65, -0.35, 2.3}, population.Members[1].DonorVector.Data(), 0.0001)
-	assert.InDeltaSlice(t, []mat.Float{1.4, -2.025, 1.05, 1.625, 2.2}, population.Members[2].DonorVector.Data(), 0.0001)
-	assert.InDeltaSlice(t, []mat.Float{1, -0.375, 0.575, 1.275, 1.625}, population.Members[3].DonorVector.Data(), 0.0001)
-	assert.InDeltaSlice(t, []mat.Float{-0.9, -1.675, 0.95, -1.4, 2.425}, population.Members[4].DonorVector.Data(), 0.0001)
-	assert.InDeltaSlice(t, []mat.Float{-0.85, -0.05, 0.525, -2.5, -0.4}, population.Members[5].DonorVector.Data(), 0.0001)
-	assert.InDeltaSlice(t, []mat.Float{-1.125, 0.725, 0.75, -3.525, -0.7}, population.Members[6].DonorVector.Data(), 0.0001)
-	assert.InDeltaSlice(t, []mat.Float{1.025, -2.375, 0.15, 3.175, 2.225}, population.Members[7].DonorVector.Data(), 0.0001)
-	assert.InDeltaSlice(t, []mat.Float{1.15, -0.3, 0.05, 0.325, 0.425}, population.Members[8].DonorVector.Data(), 0.0001)
-	assert.InDeltaSlice(t, []mat.Float{-0.275, -0.625, -0.675, 0.075, 0.175}, population.Members[9].DonorVector.Data(), 0.0001)
+	assert.InDeltaSlice(t, []mat.Float{-1.7, 0.725, 0.125, -0.975, 1.325}, population.Members[0].DonorVector.Data(), 0.0001)
+	assert.InDeltaSlice(t, []mat.Float{-1.65, 1.175, -0.5, -0.4, 0.375}, population.Members[1].DonorVector.Data(), 0.0001)
+	assert.InDeltaSlice(t, []mat.Float{1.525, -1.35, 0.2, 0.925, 0.45}, population.Members[2].DonorVector.Data(), 0.0001)
+	assert.InDeltaSlice(t, []mat.Float{0.375, 0.875, -0.85, 1.625, -0.8}, population.Members[3].DonorVector.Data(), 0.0001)
+	assert.InDeltaSlice(t, []mat.Float{1.125, -2.325, 1.45, -0.475, 2.075}, population.Members[4].DonorVector.Data(), 0.0001)
+	assert.InDeltaSlice(t, []mat.Float{0.175, 0.2, -0.15, 0.3, -0.475}, population.Members[5].DonorVector.Data(), 0.0001)
+	assert.InDeltaSlice(t, []mat.Float{-0.9, 0.4, -0.525, -1.775, -2.025}, population.Members[6].DonorVector.Data(), 0.0001)
+	assert.InDeltaSlice(t, []mat.Float{0.1, -0.725, 0.05, 1.65, 1.475}, population.Members[7].DonorVector.Data(), 0.0001)
+	assert.InDeltaSlice(t, []mat.Float{-0.85, 0.35, -0.825, 0.7, 0.525}, population.Members[8].DonorVector.Data(), 0.0001)
+	assert.InDeltaSlice(t, []mat.Float{-0.15, -1.35, 1.175, -1.275, 2.625}, population.Members[9].DonorVector.Data(), 0.0001)
 }
 
 func newTestMutator() *Population {
@@ -7,6 +7,7 @@ package adagrad
 import (
 	mat "github.com/nlpodyssey/spago/pkg/mat32"
 	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/ml/numerics"
 	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd"
 )
 
@@ -28,10 +29,11 @@ func NewConfig(lr, epsilon mat.Float) Config {
 }
 
 // NewDefaultConfig returns a new Config with generically reasonable default values.
+// Epsilon, which keeps sqrt(m) + eps away from zero, comes from numerics.Current().Optimizer.
 func NewDefaultConfig() Config {
 	return Config{
 		LR:      0.01,
-		Epsilon: 1.0e-8,
+		Epsilon: numerics.Current().Optimizer,
 	}
 }
 
@@ -7,6 +7,7 @@ package adam
 import (
 	mat "github.com/nlpodyssey/spago/pkg/mat32"
 	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/ml/numerics"
 	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd"
 )
 
@@ -38,12 +39,13 @@ func NewConfig(stepSize, beta1, beta2, epsilon mat.Float) Config {
 }
 
 // NewDefaultConfig returns a new Config with generically reasonable default values.
+// Epsilon is read from numerics.Current().Optimizer at each call.
 func NewDefaultConfig() Config {
 	return Config{
 		StepSize: 0.001,
 		Beta1:    0.9,
 		Beta2:    0.999,
-		Epsilon:  1.0e-8,
+		Epsilon:  numerics.Current().Optimizer,
 	}
 }
 
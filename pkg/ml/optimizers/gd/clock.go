@@ -0,0 +1,178 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gd
+
+import (
+	"sync"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd/decay"
+)
+
+var (
+	_ EpochScheduler   = &Clock{}
+	_ BatchScheduler   = &Clock{}
+	_ ExampleScheduler = &Clock{}
+)
+
+// Unit is a unit of the training time measured by a Clock.
+type Unit int
+
+const (
+	// Epochs counts the epochs.
+	Epochs Unit = iota
+	// Batches counts the batches.
+	Batches
+	// Steps counts the optimization steps.
+	Steps
+	// Examples counts the examples.
+	Examples
+	// Tokens counts the tokens seen.
+	Tokens
+)
+
+// Time is the training time measured by a Clock, in all the units. It can be
+// stored in the metadata of the checkpoints of a model, to resume the training
+// from the same time (see Clock.Restore).
+type Time struct {
+	Epochs   int `json:"epochs"`
+	Batches  int `json:"batches"`
+	Steps    int `json:"steps"`
+	Examples int `json:"examples"`
+	Tokens   int `json:"tokens"`
+}
+
+// Get returns the time in the given unit.
+func (t Time) Get(u Unit) int {
+	switch u {
+	case Epochs:
+		return t.Epochs
+	case Batches:
+		return t.Batches
+	case Steps:
+		return t.Steps
+	case Examples:
+		return t.Examples
+	case Tokens:
+		return t.Tokens
+	default:
+		panic("gd: invalid time unit")
+	}
+}
+
+// Clock is the training clock, shared by the optimizers (see WithClock), the
+// schedules of the learning rate (see DecayedLR), the callbacks (see Every)
+// and the checkpoints, so that all of them agree on the training time, also
+// when the training is resumed.
+type Clock struct {
+	mu        sync.Mutex
+	time      Time
+	callbacks []clockCallback
+}
+
+type clockCallback struct {
+	unit     Unit
+	interval int
+	f        func(t Time)
+}
+
+// NewClock returns a new Clock starting from zero.
+func NewClock() *Clock {
+	return &Clock{}
+}
+
+// Now returns the current time.
+func (c *Clock) Now() Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.time
+}
+
+// Restore sets the current time, e.g. from the metadata of a checkpoint,
+// without calling the callbacks.
+func (c *Clock) Restore(t Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.time = t
+}
+
+// Every registers a callback, called each time the clock reaches a multiple
+// of the interval in the given unit (e.g. logging every 100 steps, or saving
+// a checkpoint at the end of each epoch). The callbacks are called in order
+// of registration, with the time after the increment.
+// It panics if the interval is not positive.
+func (c *Clock) Every(u Unit, interval int, callback func(t Time)) {
+	if interval < 1 {
+		panic("gd: the interval of the callback must be greater than zero")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.callbacks = append(c.callbacks, clockCallback{unit: u, interval: interval, f: callback})
+}
+
+// IncEpoch beats the occurrence of a new epoch.
+func (c *Clock) IncEpoch() {
+	c.add(Epochs, 1)
+}
+
+// IncBatch beats the occurrence of a new batch.
+func (c *Clock) IncBatch() {
+	c.add(Batches, 1)
+}
+
+// IncStep beats the occurrence of a new optimization step.
+func (c *Clock) IncStep() {
+	c.add(Steps, 1)
+}
+
+// IncExample beats the occurrence of a new example.
+func (c *Clock) IncExample() {
+	c.add(Examples, 1)
+}
+
+// AddTokens beats the occurrence of n new tokens.
+func (c *Clock) AddTokens(n int) {
+	c.add(Tokens, n)
+}
+
+// add advances the time in the given unit, and calls the callbacks whose
+// interval has been reached, after releasing the lock, so that they can use
+// the clock.
+func (c *Clock) add(u Unit, n int) {
+	c.mu.Lock()
+	prev := c.time.Get(u)
+	switch u {
+	case Epochs:
+		c.time.Epochs += n
+	case Batches:
+		c.time.Batches += n
+	case Steps:
+		c.time.Steps += n
+	case Examples:
+		c.time.Examples += n
+	case Tokens:
+		c.time.Tokens += n
+	}
+	now := c.time
+	var due []func(t Time)
+	for _, cb := range c.callbacks {
+		if cb.unit == u && now.Get(u)/cb.interval > prev/cb.interval {
+			due = append(due, cb.f)
+		}
+	}
+	c.mu.Unlock()
+
+	for _, f := range due {
+		f(now)
+	}
+}
+
+// DecayedLR returns the learning rate decayed from the initial one by the
+// given function, at the current time in the given unit, starting from 1
+// (see decay.At). Being derived from the clock only, the learning rate is
+// consistent after the training is resumed.
+func (c *Clock) DecayedLR(fn decay.Function, init mat.Float, u Unit) mat.Float {
+	return decay.At(fn, init, c.Now().Get(u)+1)
+}
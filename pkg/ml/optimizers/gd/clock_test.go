@@ -0,0 +1,109 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gd
+
+import (
+	"encoding/json"
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd/decay/exponential"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClock(t *testing.T) {
+	c := NewClock()
+	assert.Equal(t, Time{}, c.Now())
+	c.IncEpoch()
+	c.IncBatch()
+	c.IncBatch()
+	c.IncStep()
+	c.IncExample()
+	c.AddTokens(7)
+	assert.Equal(t, Time{Epochs: 1, Batches: 2, Steps: 1, Examples: 1, Tokens: 7}, c.Now())
+	assert.Equal(t, 2, c.Now().Get(Batches))
+	assert.Panics(t, func() { c.Now().Get(Unit(42)) })
+}
+
+func TestClock_Every(t *testing.T) {
+	c := NewClock()
+	var steps, tokens []int
+	c.Every(Steps, 2, func(t Time) { steps = append(steps, t.Steps) })
+	c.Every(Tokens, 10, func(t Time) {
+		tokens = append(tokens, c.Now().Tokens) // the clock can be used
+	})
+	for i := 0; i < 5; i++ {
+		c.IncStep()
+		c.AddTokens(6)
+	}
+	assert.Equal(t, []int{2, 4}, steps)
+	assert.Equal(t, []int{12, 24, 30}, tokens)
+	assert.Panics(t, func() { c.Every(Steps, 0, func(Time) {}) })
+}
+
+func TestClock_Restore(t *testing.T) {
+	c := NewClock()
+	c.IncStep()
+	c.AddTokens(3)
+	data, err := json.Marshal(c.Now()) // e.g. the metadata of a checkpoint
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"epochs":0,"batches":0,"steps":1,"examples":0,"tokens":3}`, string(data))
+
+	var called bool
+	resumed := NewClock()
+	resumed.Every(Steps, 1, func(Time) { called = true })
+	var now Time
+	require.NoError(t, json.Unmarshal(data, &now))
+	resumed.Restore(now)
+	assert.Equal(t, c.Now(), resumed.Now())
+	assert.False(t, called)
+}
+
+func TestClock_DecayedLR(t *testing.T) {
+	fn := exponential.New(0.01, 0.001, 10)
+	c := NewClock()
+	assert.InDelta(t, 0.01, c.DecayedLR(fn, 0.01, Epochs), 1.0e-6)
+	c.IncEpoch()
+	assert.InDelta(t, 0.00774264, c.DecayedLR(fn, 0.01, Epochs), 1.0e-6)
+	c.IncEpoch()
+	assert.InDelta(t, 0.00599484, c.DecayedLR(fn, 0.01, Epochs), 1.0e-6)
+}
+
+func TestGradientDescent_WithClock(t *testing.T) {
+	c := NewClock()
+	p := nn.NewParam(mat.NewScalar(1))
+	optimizer := NewOptimizer(plainGD{}, paramsList{p}, WithClock(c))
+	optimizer.IncEpoch()
+	optimizer.IncBatch()
+	optimizer.IncExample()
+	p.PropagateGrad(mat.NewScalar(1))
+	optimizer.Optimize()
+	assert.Equal(t, Time{Epochs: 1, Batches: 1, Steps: 1, Examples: 1}, c.Now())
+}
+
+func TestCoordinator_WithCoordinatorClock(t *testing.T) {
+	newCoordinator := func(c *Clock) *Coordinator {
+		return NewCoordinator([]*GradientDescent{
+			NewOptimizer(plainGD{}, paramsList{nn.NewParam(mat.NewScalar(1))}),
+			NewOptimizer(plainGD{}, paramsList{nn.NewParam(mat.NewScalar(1))}),
+		}, WithSchedule(NewAlternating(2, 1)), WithCoordinatorClock(c))
+	}
+	c := NewClock()
+	coordinator := newCoordinator(c)
+	assert.Same(t, c, coordinator.Clock())
+	coordinator.Step()
+	coordinator.Step()
+	coordinator.IncBatch()
+	assert.Equal(t, 2, coordinator.GlobalStep())
+	assert.Equal(t, Time{Steps: 2, Batches: 1}, c.Now())
+
+	// the schedule resumes from the same turn
+	resumed := NewClock()
+	resumed.Restore(c.Now())
+	assert.Equal(t, coordinator.Next(), newCoordinator(resumed).Next())
+	assert.Equal(t, []int{1}, coordinator.Next())
+}
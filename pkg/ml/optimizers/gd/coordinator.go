@@ -0,0 +1,224 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gd
+
+import (
+	"sync"
+
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+)
+
+var (
+	_ EpochScheduler   = &Coordinator{}
+	_ BatchScheduler   = &Coordinator{}
+	_ ExampleScheduler = &Coordinator{}
+)
+
+// Schedule decides which optimizers of a Coordinator are stepped at each
+// global step.
+type Schedule interface {
+	// Optimizers returns the indices of the optimizers to step at the given
+	// global step (starting from zero), in order, given the number of
+	// optimizers.
+	Optimizers(step, n int) []int
+}
+
+// InOrder is a Schedule stepping all the optimizers at each global step, in
+// order (e.g. the encoder and the head of a model, trained on the same loss).
+type InOrder struct{}
+
+// Optimizers returns the indices of all the optimizers.
+func (InOrder) Optimizers(_, n int) []int {
+	indices := make([]int, n)
+	for i := range indices {
+		indices[i] = i
+	}
+	return indices
+}
+
+// Alternating is a Schedule stepping one optimizer at a time, in turn, for
+// the given number of consecutive global steps each (e.g. five steps of the
+// discriminator of a GAN for each step of the generator).
+type Alternating struct {
+	steps  []int
+	period int
+}
+
+// NewAlternating returns a new Alternating schedule, stepping the i-th
+// optimizer for steps[i] consecutive global steps. It panics if a number of
+// steps is not positive.
+func NewAlternating(steps ...int) *Alternating {
+	period := 0
+	for _, s := range steps {
+		if s < 1 {
+			panic("gd: the number of alternating steps must be greater than zero")
+		}
+		period += s
+	}
+	return &Alternating{steps: steps, period: period}
+}
+
+// Optimizers returns the index of the optimizer in turn at the given step.
+// It panics if the number of optimizers doesn't match the number of steps
+// of the schedule.
+func (a *Alternating) Optimizers(step, n int) []int {
+	if n != len(a.steps) {
+		panic("gd: the alternating schedule doesn't match the number of optimizers")
+	}
+	pos := step % a.period
+	for i, s := range a.steps {
+		if pos < s {
+			return []int{i}
+		}
+		pos -= s
+	}
+	return nil // unreachable
+}
+
+// Coordinator coordinates several GradientDescent optimizers over disjoint
+// sets of parameters (e.g. the encoder and the head of a model, or the
+// generator and the discriminator of a GAN), stepping them according to a
+// Schedule, and counting the global steps with a Clock.
+//
+// A typical training step of a GAN looks like this:
+//
+//     switch c.Next()[0] {
+//     case 0:
+//         g.Backward(discriminatorLoss)
+//     case 1:
+//         g.Backward(generatorLoss)
+//     }
+//     c.Step()
+type Coordinator struct {
+	optimizers []*GradientDescent
+	schedule   Schedule
+	mu         sync.Mutex
+	// clock counts the global steps.
+	clock *Clock
+	// steps is the number of steps of each optimizer.
+	steps []int
+}
+
+// CoordinatorOption allows to configure a new Coordinator with your specific needs.
+type CoordinatorOption func(*Coordinator)
+
+// WithSchedule sets the Schedule of the optimizers (InOrder by default).
+func WithSchedule(schedule Schedule) CoordinatorOption {
+	return func(c *Coordinator) {
+		c.schedule = schedule
+	}
+}
+
+// WithCoordinatorClock sets the Clock advanced at each global step, and at
+// each example, batch and epoch beaten by the Coordinator (a new one by
+// default). The Schedule follows the steps of the clock, so that it resumes
+// from the same turn when the clock is restored.
+func WithCoordinatorClock(clock *Clock) CoordinatorOption {
+	return func(c *Coordinator) {
+		c.clock = clock
+	}
+}
+
+// NewCoordinator returns a new Coordinator of the given optimizers.
+// It panics if there are no optimizers, or if their sets of parameters are
+// not disjoint.
+func NewCoordinator(optimizers []*GradientDescent, opts ...CoordinatorOption) *Coordinator {
+	if len(optimizers) == 0 {
+		panic("gd: the coordinator requires at least one optimizer")
+	}
+	owners := make(map[nn.Param]int)
+	for i, o := range optimizers {
+		for _, param := range o.paramsGetter.Params() {
+			if j, ok := owners[param]; ok && j != i {
+				panic("gd: the optimizers must have disjoint sets of parameters")
+			}
+			owners[param] = i
+		}
+	}
+	c := &Coordinator{
+		optimizers: optimizers,
+		schedule:   InOrder{},
+		clock:      NewClock(),
+		steps:      make([]int, len(optimizers)),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Next returns the indices of the optimizers which will be stepped by the
+// next Step, in order, so that only the losses they need can be computed.
+func (c *Coordinator) Next() []int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.schedule.Optimizers(c.clock.Now().Steps, len(c.optimizers))
+}
+
+// Step optimizes the parameters of the optimizers scheduled for the current
+// global step, in order, and increments the global step. The gradients of
+// the parameters of the other optimizers are zeroed, so that they don't
+// accumulate until their turn (e.g. the gradients of the generator computed
+// from the loss of the discriminator).
+func (c *Coordinator) Step() {
+	c.mu.Lock()
+	scheduled := make([]bool, len(c.optimizers))
+	for _, i := range c.schedule.Optimizers(c.clock.Now().Steps, len(c.optimizers)) {
+		c.optimizers[i].Optimize()
+		c.steps[i]++
+		scheduled[i] = true
+	}
+	for i, o := range c.optimizers {
+		if !scheduled[i] {
+			o.discardGrads()
+		}
+	}
+	c.mu.Unlock()
+	c.clock.IncStep() // the callbacks may use the coordinator
+}
+
+// GlobalStep returns the number of global steps performed so far.
+func (c *Coordinator) GlobalStep() int {
+	return c.clock.Now().Steps
+}
+
+// Clock returns the Clock of the Coordinator.
+func (c *Coordinator) Clock() *Clock {
+	return c.clock
+}
+
+// Steps returns the number of steps performed so far by the i-th optimizer.
+func (c *Coordinator) Steps(i int) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.steps[i]
+}
+
+// IncExample beats the occurrence of a new example for all the optimizers,
+// stepped or not, so that their schedulers share the same count.
+func (c *Coordinator) IncExample() {
+	for _, o := range c.optimizers {
+		o.IncExample()
+	}
+	c.clock.IncExample()
+}
+
+// IncBatch beats the occurrence of a new batch for all the optimizers,
+// stepped or not, so that their schedulers share the same count.
+func (c *Coordinator) IncBatch() {
+	for _, o := range c.optimizers {
+		o.IncBatch()
+	}
+	c.clock.IncBatch()
+}
+
+// IncEpoch beats the occurrence of a new epoch for all the optimizers,
+// stepped or not, so that their schedulers share the same count.
+func (c *Coordinator) IncEpoch() {
+	for _, o := range c.optimizers {
+		o.IncEpoch()
+	}
+	c.clock.IncEpoch()
+}
@@ -0,0 +1,91 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gd
+
+import (
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInOrder(t *testing.T) {
+	assert.Equal(t, []int{0, 1, 2}, InOrder{}.Optimizers(5, 3))
+}
+
+func TestAlternating(t *testing.T) {
+	s := NewAlternating(2, 1)
+	var turns []int
+	for step := 0; step < 6; step++ {
+		turns = append(turns, s.Optimizers(step, 2)...)
+	}
+	assert.Equal(t, []int{0, 0, 1, 0, 0, 1}, turns)
+	assert.Panics(t, func() { s.Optimizers(0, 3) })
+	assert.Panics(t, func() { NewAlternating(1, 0) })
+}
+
+func TestNewCoordinator(t *testing.T) {
+	p := nn.NewParam(mat.NewScalar(1))
+	q := nn.NewParam(mat.NewScalar(2))
+	assert.Panics(t, func() { NewCoordinator(nil) })
+	assert.Panics(t, func() {
+		NewCoordinator([]*GradientDescent{
+			NewOptimizer(plainGD{}, paramsList{p, q}),
+			NewOptimizer(plainGD{}, paramsList{q}),
+		})
+	})
+	assert.NotPanics(t, func() {
+		NewCoordinator([]*GradientDescent{
+			NewOptimizer(plainGD{}, paramsList{p}),
+			NewOptimizer(plainGD{}, paramsList{q}),
+		})
+	})
+}
+
+func TestCoordinator_Step(t *testing.T) {
+	encoder := nn.NewParam(mat.NewVecDense([]mat.Float{1, 2}))
+	head := nn.NewParam(mat.NewScalar(3))
+	c := NewCoordinator([]*GradientDescent{
+		NewOptimizer(plainGD{}, paramsList{encoder}, ConcurrentComputations(1)),
+		NewOptimizer(plainGD{}, paramsList{head}, ConcurrentComputations(1)),
+	})
+
+	assert.Equal(t, []int{0, 1}, c.Next())
+	encoder.PropagateGrad(mat.NewVecDense([]mat.Float{1, 1}))
+	head.PropagateGrad(mat.NewScalar(1))
+	c.Step()
+	assert.Equal(t, []mat.Float{0, 1}, encoder.Value().Data())
+	assert.Equal(t, mat.Float(2), head.Value().Scalar())
+	assert.Equal(t, 1, c.GlobalStep())
+	assert.Equal(t, 1, c.Steps(0))
+	assert.Equal(t, 1, c.Steps(1))
+}
+
+func TestCoordinator_Alternating(t *testing.T) {
+	discriminator := nn.NewParam(mat.NewScalar(10))
+	generator := nn.NewParam(mat.NewScalar(20))
+	c := NewCoordinator([]*GradientDescent{
+		NewOptimizer(plainGD{}, paramsList{discriminator}, ConcurrentComputations(1)),
+		NewOptimizer(plainGD{}, paramsList{generator}, ConcurrentComputations(1)),
+	}, WithSchedule(NewAlternating(2, 1)))
+
+	for step := 0; step < 6; step++ {
+		next := c.Next()
+		assert.Len(t, next, 1)
+		// both the params receive gradients at every step
+		discriminator.PropagateGrad(mat.NewScalar(1))
+		generator.PropagateGrad(mat.NewScalar(1))
+		c.Step()
+		// the gradients of the optimizer not in turn are discarded
+		assert.False(t, discriminator.HasGrad())
+		assert.False(t, generator.HasGrad())
+	}
+	assert.Equal(t, mat.Float(6), discriminator.Value().Scalar())
+	assert.Equal(t, mat.Float(18), generator.Value().Scalar())
+	assert.Equal(t, 6, c.GlobalStep())
+	assert.Equal(t, 4, c.Steps(0))
+	assert.Equal(t, 2, c.Steps(1))
+}
@@ -11,3 +11,14 @@ type Function interface {
 	// Decay calculates the decay of the learning rate lr at time t.
 	Decay(lr mat.Float, t int) mat.Float
 }
+
+// At returns the learning rate at time t, decaying the initial learning rate
+// init by the function at each time from 1 to t, as a scheduler updating it
+// at each time would do.
+func At(fn Function, init mat.Float, t int) mat.Float {
+	lr := init
+	for i := 1; i <= t; i++ {
+		lr = fn.Decay(lr, i)
+	}
+	return lr
+}
@@ -0,0 +1,29 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package decay
+
+import (
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/stretchr/testify/assert"
+)
+
+// halving halves the learning rate at each time after the first one.
+type halving struct{}
+
+func (halving) Decay(lr mat.Float, t int) mat.Float {
+	if t > 1 {
+		return lr / 2
+	}
+	return lr
+}
+
+func TestAt(t *testing.T) {
+	assert.Equal(t, mat.Float(8), At(halving{}, 8, 0))
+	assert.Equal(t, mat.Float(8), At(halving{}, 8, 1))
+	assert.Equal(t, mat.Float(4), At(halving{}, 8, 2))
+	assert.Equal(t, mat.Float(1), At(halving{}, 8, 4))
+}
@@ -19,10 +19,18 @@ type GradientDescent struct {
 	gradClipper      clipper.GradClipper
 	paramsGetter     nn.ParamsGetter
 	paramsToOptimize []nn.Param
+	// groupsToOptimize contains the group of each param to optimize.
+	groupsToOptimize []*nn.ParamGroup
 	// processingQueue allows proper handling for computationally heavy operations
 	// such as the params update step.
 	// The default size is defaultProcessingQueueSize.
 	processingQueue processingqueue.ProcessingQueue
+	// lossScaler unscales the gradients before the update, if set (see WithLossScaler).
+	lossScaler *LossScaler
+	// clock is the training clock, if set (see WithClock).
+	clock *Clock
+	// lrScale multiplies the learning rate of all the params (see SetLRScale).
+	lrScale mat.Float
 }
 
 // defaultProcessingQueueSize is the default size of GradientDescent.processingQueue on a new optimizer.
@@ -61,13 +69,35 @@ func ConcurrentComputations(value int) Option {
 	}
 }
 
+// WithLossScaler is an option to unscale the gradients of the params with the
+// given LossScaler before the gradient clipping and the update. When the
+// gradients overflow, the update is skipped and the gradients are zeroed.
+func WithLossScaler(scaler *LossScaler) Option {
+	return func(f *GradientDescent) {
+		f.lossScaler = scaler
+	}
+}
+
+// WithClock is an option to advance the given Clock at each optimization
+// step, and at each example, batch and epoch beaten by the optimizer.
+// If the optimizer is coordinated with others, the clock must be set on the
+// Coordinator instead (see WithCoordinatorClock).
+func WithClock(clock *Clock) Option {
+	return func(f *GradientDescent) {
+		f.clock = clock
+	}
+}
+
 // NewOptimizer returns a new GradientDescent optimizer. The gradient clipper can be set to nil.
+// If the params getter is also a nn.ParamGroupsGetter (e.g. nn.ParamGroups), the params are
+// optimized by group, with the hyperparameters of each one.
 func NewOptimizer(method Method, paramsIterator nn.ParamsGetter, opts ...Option) *GradientDescent {
 	optimizer := &GradientDescent{
 		method:           method,
 		paramsGetter:     paramsIterator,
 		paramsToOptimize: make([]nn.Param, 0),
 		processingQueue:  processingqueue.New(defaultProcessingQueueSize),
+		lrScale:          1,
 	}
 	for _, opt := range opts {
 		opt(optimizer)
@@ -75,24 +105,102 @@ func NewOptimizer(method Method, paramsIterator nn.ParamsGetter, opts ...Option)
 	return optimizer
 }
 
-// Optimize optimize the params, applying the optional gradient clipping.
+// Optimize optimize the params, applying the optional loss scaling and
+// gradient clipping.
 // After the optimization the params have zero gradients.
 func (o *GradientDescent) Optimize() {
-	o.paramsToOptimize = o.paramsGetter.Params()
-	if o.paramsToOptimize == nil {
+	if o.clock != nil {
+		defer o.clock.IncStep()
+	}
+	o.collectParams()
+	defer func() {
+		o.paramsToOptimize = nil
+		o.groupsToOptimize = nil
+	}()
+	if len(o.paramsToOptimize) == 0 {
+		return
+	}
+	if o.lossScaler != nil && !o.lossScaler.unscale(o.paramsToOptimize) {
+		o.zeroGrads()
 		return
 	}
 	o.clipGrads()
 	o.updateParams()
-	o.paramsToOptimize = nil
+}
+
+// LRScale returns the scale of the learning rate of all the params (1 by
+// default).
+func (o *GradientDescent) LRScale() mat.Float {
+	return o.lrScale
+}
+
+// SetLRScale sets the scale multiplying the learning rate of all the params,
+// along with the one of their group (see nn.ParamGroup), e.g. to reduce the
+// learning rate of any method after a divergence of the training. It must
+// not be called during an optimization step.
+func (o *GradientDescent) SetLRScale(scale mat.Float) {
+	o.lrScale = scale
+}
+
+// collectParams collects the params to optimize, with their groups,
+// discarding the gradients of the frozen ones.
+func (o *GradientDescent) collectParams() {
+	// the params tied together (see nn.TieWeights) are updated once
+	seen := make(map[nn.Param]bool)
+	getter, ok := o.paramsGetter.(nn.ParamGroupsGetter)
+	if !ok {
+		group := nn.NewParamGroup("", o.paramsGetter.Params()...)
+		for _, param := range group.Params {
+			if owner := nn.TiedOwner(param); !seen[owner] {
+				seen[owner] = true
+				o.paramsToOptimize = append(o.paramsToOptimize, param)
+				o.groupsToOptimize = append(o.groupsToOptimize, group)
+			}
+		}
+		return
+	}
+	for _, group := range getter.ParamGroups() {
+		for _, param := range group.Params {
+			if group.Frozen {
+				param.ZeroGrad()
+				continue
+			}
+			if owner := nn.TiedOwner(param); !seen[owner] {
+				seen[owner] = true
+				o.paramsToOptimize = append(o.paramsToOptimize, param)
+				o.groupsToOptimize = append(o.groupsToOptimize, group)
+			}
+		}
+	}
+}
+
+// update applies the optimization method to the param, according to the
+// hyperparameters of its group.
+func (o *GradientDescent) update(param nn.Param, group *nn.ParamGroup) {
+	delta := o.method.Delta(param) // important: don't release delta here
+	if group.WeightDecay != 0 {
+		decay := param.Value().ProdScalar(group.WeightDecay)
+		defer mat.ReleaseMatrix(decay)
+		param.ApplyDelta(decay)
+	}
+	scale := o.lrScale
+	if group.LRScale != 0 { // the zero value stands for 1
+		scale *= group.LRScale
+	}
+	if scale != 1 {
+		scaled := delta.ProdScalar(scale)
+		defer mat.ReleaseMatrix(scaled)
+		param.ApplyDelta(scaled)
+		return
+	}
+	param.ApplyDelta(delta)
 }
 
 // updateParamsSerial applies the optimization method to all the observed parameters.
 func (o *GradientDescent) updateParamsSerial() {
-	for _, param := range o.paramsToOptimize {
+	for i, param := range o.paramsToOptimize {
 		if param.HasGrad() {
-			delta := o.method.Delta(param) // important: don't release delta here
-			param.ApplyDelta(delta)
+			o.update(param, o.groupsToOptimize[i])
 			param.ZeroGrad()
 		}
 	}
@@ -101,23 +209,37 @@ func (o *GradientDescent) updateParamsSerial() {
 // updateParams applies the optimization method to all the observed parameters concurrently.
 func (o *GradientDescent) updateParams() {
 	var wg sync.WaitGroup
-	for _, param := range o.paramsToOptimize {
+	for i, param := range o.paramsToOptimize {
 		if !param.HasGrad() {
 			continue
 		}
 		wg.Add(1)
-		go func(param nn.Param) {
+		go func(param nn.Param, group *nn.ParamGroup) {
 			defer wg.Done()
 			o.processingQueue.Run(func() {
-				delta := o.method.Delta(param)
-				param.ApplyDelta(delta)
+				o.update(param, group)
 			})
 			param.ZeroGrad()
-		}(param)
+		}(param, o.groupsToOptimize[i])
 	}
 	wg.Wait()
 }
 
+// zeroGrads sets the gradients of all the observed parameters to zero.
+func (o *GradientDescent) zeroGrads() {
+	for _, param := range o.paramsToOptimize {
+		param.ZeroGrad()
+	}
+}
+
+// discardGrads sets the gradients of all the params to zero, without
+// optimizing them.
+func (o *GradientDescent) discardGrads() {
+	for _, param := range o.paramsGetter.Params() {
+		param.ZeroGrad()
+	}
+}
+
 // clipGrad applies the gradient clipping to all the observed parameters.
 func (o *GradientDescent) clipGrads() {
 	if o.gradClipper == nil {
@@ -137,6 +259,9 @@ func (o *GradientDescent) IncExample() {
 	if method, ok := o.method.(ExampleScheduler); ok {
 		method.IncExample()
 	}
+	if o.clock != nil {
+		o.clock.IncExample()
+	}
 }
 
 // IncBatch beats the occurrence of a new batch.
@@ -144,6 +269,9 @@ func (o *GradientDescent) IncBatch() {
 	if method, ok := o.method.(BatchScheduler); ok {
 		method.IncBatch()
 	}
+	if o.clock != nil {
+		o.clock.IncBatch()
+	}
 }
 
 // IncEpoch beats the occurrence of a new epoch.
@@ -151,4 +279,7 @@ func (o *GradientDescent) IncEpoch() {
 	if method, ok := o.method.(EpochScheduler); ok {
 		method.IncEpoch()
 	}
+	if o.clock != nil {
+		o.clock.IncEpoch()
+	}
 }
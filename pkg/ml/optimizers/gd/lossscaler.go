@@ -0,0 +1,128 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gd
+
+import (
+	"sync"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+)
+
+// LossScalerConfig provides configuration settings for a LossScaler.
+type LossScalerConfig struct {
+	// InitialScale is the scale of the loss before the first step.
+	InitialScale mat.Float
+	// GrowthFactor multiplies the scale after GrowthInterval consecutive steps
+	// without overflows.
+	GrowthFactor mat.Float
+	// BackoffFactor multiplies the scale after an overflow.
+	BackoffFactor mat.Float
+	// GrowthInterval is the number of consecutive steps without overflows
+	// after which the scale grows.
+	GrowthInterval int
+}
+
+// NewDefaultLossScalerConfig returns a new LossScalerConfig with the usual
+// settings: the scale starts from 2^16, is halved at each overflow, and is
+// doubled after 2000 steps without overflows.
+func NewDefaultLossScalerConfig() LossScalerConfig {
+	return LossScalerConfig{
+		InitialScale:   65536,
+		GrowthFactor:   2,
+		BackoffFactor:  0.5,
+		GrowthInterval: 2000,
+	}
+}
+
+// LossScaler implements the dynamic loss scaling of the mixed-precision
+// training (see ag.WithMixedPrecision): the loss is multiplied by a scale
+// before the backward, so that the small gradients don't underflow in half
+// precision, and the gradients of the parameters are divided by the same
+// scale before the optimization step.
+// When the gradients overflow, the step is skipped and the scale is reduced;
+// after a number of steps without overflows, the scale grows again.
+//
+// It is used by the GradientDescent created with the WithLossScaler option:
+//
+//     g.Backward(loss, scaler.OutputGrad())
+//     optimizer.Optimize()
+type LossScaler struct {
+	config LossScalerConfig
+	mu     sync.Mutex
+	scale  mat.Float
+	// goodSteps is the number of consecutive steps without overflows.
+	goodSteps int
+	// skippedSteps is the number of steps skipped because of an overflow.
+	skippedSteps int
+}
+
+// NewLossScaler returns a new LossScaler.
+// It panics if the configuration is not valid.
+func NewLossScaler(config LossScalerConfig) *LossScaler {
+	if config.InitialScale <= 0 || config.GrowthFactor < 1 || config.BackoffFactor <= 0 ||
+		config.BackoffFactor >= 1 || config.GrowthInterval < 1 {
+		panic("gd: invalid loss scaler configuration")
+	}
+	return &LossScaler{
+		config: config,
+		scale:  config.InitialScale,
+	}
+}
+
+// Scale returns the current scale of the loss.
+func (s *LossScaler) Scale() mat.Float {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.scale
+}
+
+// SkippedSteps returns the number of optimization steps skipped so far
+// because of an overflow of the gradients.
+func (s *LossScaler) SkippedSteps() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.skippedSteps
+}
+
+// OutputGrad returns the option of the backward starting from the scaled
+// gradient of the loss, which must be a scalar.
+func (s *LossScaler) OutputGrad() ag.BackwardOption {
+	return ag.OutputGrad(mat.NewScalar(s.Scale()))
+}
+
+// unscale divides the gradients of the params by the scale, updating the
+// scale for the next step. It returns false, leaving the gradients
+// untouched, if any of them contains NaN or infinite values, in which case
+// the optimization step must be skipped.
+func (s *LossScaler) unscale(params []nn.Param) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, param := range params {
+		if !param.HasGrad() {
+			continue
+		}
+		for _, v := range param.Grad().Data() {
+			if v != v || mat.IsInf(v, 0) {
+				s.scale *= s.config.BackoffFactor
+				s.goodSteps = 0
+				s.skippedSteps++
+				return false
+			}
+		}
+	}
+	for _, param := range params {
+		if param.HasGrad() {
+			param.Grad().ProdScalarInPlace(1 / s.scale)
+		}
+	}
+	s.goodSteps++
+	if s.goodSteps == s.config.GrowthInterval {
+		s.scale *= s.config.GrowthFactor
+		s.goodSteps = 0
+	}
+	return true
+}
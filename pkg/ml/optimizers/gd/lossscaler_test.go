@@ -0,0 +1,100 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gd
+
+import (
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/stretchr/testify/assert"
+)
+
+// plainGD is a Method applying the gradients as they are.
+type plainGD struct{}
+
+func (plainGD) Label() int                      { return None }
+func (plainGD) Delta(param nn.Param) mat.Matrix { return param.Grad().Clone() }
+func (plainGD) NewSupport(r, c int) *nn.Payload { return &nn.Payload{Label: None} }
+
+type paramsList []nn.Param
+
+func (p paramsList) Params() []nn.Param { return p }
+
+func TestNewLossScaler(t *testing.T) {
+	s := NewLossScaler(NewDefaultLossScalerConfig())
+	assert.Equal(t, mat.Float(65536), s.Scale())
+	assert.Equal(t, 0, s.SkippedSteps())
+
+	for _, config := range []LossScalerConfig{
+		{InitialScale: 0, GrowthFactor: 2, BackoffFactor: 0.5, GrowthInterval: 1},
+		{InitialScale: 1, GrowthFactor: 0.5, BackoffFactor: 0.5, GrowthInterval: 1},
+		{InitialScale: 1, GrowthFactor: 2, BackoffFactor: 1, GrowthInterval: 1},
+		{InitialScale: 1, GrowthFactor: 2, BackoffFactor: 0.5, GrowthInterval: 0},
+	} {
+		assert.Panics(t, func() { NewLossScaler(config) })
+	}
+}
+
+func TestGradientDescent_WithLossScaler(t *testing.T) {
+	scaler := NewLossScaler(LossScalerConfig{
+		InitialScale:   8,
+		GrowthFactor:   2,
+		BackoffFactor:  0.5,
+		GrowthInterval: 2,
+	})
+	p := nn.NewParam(mat.NewVecDense([]mat.Float{1, 2}))
+	optimizer := NewOptimizer(plainGD{}, paramsList{p}, WithLossScaler(scaler), ConcurrentComputations(1))
+
+	step := func(grads ...mat.Float) {
+		p.PropagateGrad(mat.NewVecDense(grads))
+		optimizer.Optimize()
+	}
+
+	step(8, 4) // unscaled: 1, 0.5
+	assert.Equal(t, []mat.Float{0, 1.5}, p.Value().Data())
+	assert.False(t, p.HasGrad())
+	assert.Equal(t, mat.Float(8), scaler.Scale())
+
+	step(8, 8) // second step without overflows: the scale grows
+	assert.Equal(t, []mat.Float{-1, 0.5}, p.Value().Data())
+	assert.Equal(t, mat.Float(16), scaler.Scale())
+
+	step(mat.Inf(1), 1) // overflow: the step is skipped
+	assert.Equal(t, []mat.Float{-1, 0.5}, p.Value().Data())
+	assert.False(t, p.HasGrad())
+	assert.Equal(t, mat.Float(8), scaler.Scale())
+	assert.Equal(t, 1, scaler.SkippedSteps())
+
+	step(mat.NaN(), 1)
+	assert.Equal(t, mat.Float(4), scaler.Scale())
+	assert.Equal(t, 2, scaler.SkippedSteps())
+}
+
+func TestLossScaler_MixedPrecision(t *testing.T) {
+	scaler := NewLossScaler(NewDefaultLossScalerConfig())
+	const c = 1.0e-4
+	grad := func(opts ...ag.BackwardOption) mat.Float {
+		g := ag.NewGraph(ag.WithMixedPrecision(mat.Float16))
+		x := g.NewVariable(mat.NewScalar(1), true)
+		y := g.ProdScalar(g.ProdScalar(g.ProdScalar(x, g.Constant(c)), g.Constant(c)), g.Constant(c))
+		g.Backward(y, opts...)
+		return x.Grad().Scalar()
+	}
+	// the gradient of the intermediate operator (1e-8) underflows in float16
+	assert.Equal(t, mat.Float(0), grad())
+
+	// the initial scale overflows the largest float16 (65504), so it is reduced
+	p := nn.NewParam(mat.NewScalar(0))
+	p.PropagateGrad(mat.NewScalar(grad(scaler.OutputGrad())))
+	assert.False(t, scaler.unscale([]nn.Param{p}))
+	assert.Equal(t, mat.Float(32768), scaler.Scale())
+
+	p.ZeroGrad()
+	p.PropagateGrad(mat.NewScalar(grad(scaler.OutputGrad())))
+	assert.True(t, scaler.unscale([]nn.Param{p}))
+	assert.InDelta(t, c*c*c, p.Grad().Scalar(), 1.0e-14)
+}
@@ -0,0 +1,89 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gd
+
+import (
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGradientDescent_ParamGroups(t *testing.T) {
+	embeddings := nn.NewParam(mat.NewScalar(10))
+	encoder := nn.NewParam(mat.NewScalar(10))
+	head := nn.NewParam(mat.NewScalar(10))
+
+	groups := nn.ParamGroups{
+		nn.NewParamGroup("embeddings", embeddings),
+		nn.NewParamGroup("encoder", encoder),
+		nn.NewParamGroup("head", head),
+	}
+	groups.Group("embeddings").Frozen = true
+	groups.Group("encoder").LRScale = 0.5
+	groups.Group("head").WeightDecay = 0.1
+
+	for _, concurrency := range []int{1, 4} {
+		for _, p := range groups.Params() {
+			p.Value().SetData([]mat.Float{10})
+			p.PropagateGrad(mat.NewScalar(2))
+		}
+		NewOptimizer(plainGD{}, groups, ConcurrentComputations(concurrency)).Optimize()
+
+		assert.Equal(t, mat.Float(10), embeddings.Value().Scalar())
+		assert.InDelta(t, 9, encoder.Value().Scalar(), 1.0e-6)
+		assert.InDelta(t, 7, head.Value().Scalar(), 1.0e-6)
+		for _, p := range groups.Params() {
+			assert.False(t, p.HasGrad())
+		}
+	}
+}
+
+func TestGradientDescent_FlatParams(t *testing.T) {
+	p := nn.NewParam(mat.NewScalar(10))
+	p.PropagateGrad(mat.NewScalar(2))
+	NewOptimizer(plainGD{}, paramsList{p}).Optimize()
+	assert.InDelta(t, 8, p.Value().Scalar(), 1.0e-6)
+	assert.False(t, p.HasGrad())
+}
+
+func TestGradientDescent_TiedParams(t *testing.T) {
+	a := nn.NewParam(mat.NewScalar(10))
+	b := nn.NewParam(mat.NewScalar(0))
+	nn.TieWeights(a, b)
+	a.PropagateGrad(mat.NewScalar(1))
+	b.PropagateGrad(mat.NewScalar(1))
+	NewOptimizer(plainGD{}, paramsList{a, b}).Optimize()
+	assert.InDelta(t, 8, a.Value().Scalar(), 1.0e-6)
+	assert.InDelta(t, 8, b.Value().Scalar(), 1.0e-6)
+	assert.False(t, b.HasGrad())
+}
+
+func TestGradientDescent_LRScale(t *testing.T) {
+	p := nn.NewParam(mat.NewScalar(10))
+	q := nn.NewParam(mat.NewScalar(10))
+	groups := nn.ParamGroups{nn.NewParamGroup("p", p), nn.NewParamGroup("q", q)}
+	groups.Group("q").LRScale = 0.5
+
+	optimizer := NewOptimizer(plainGD{}, groups)
+	assert.Equal(t, mat.Float(1), optimizer.LRScale())
+	optimizer.SetLRScale(0.25)
+	p.PropagateGrad(mat.NewScalar(4))
+	q.PropagateGrad(mat.NewScalar(4))
+	optimizer.Optimize()
+	assert.InDelta(t, 9, p.Value().Scalar(), 1.0e-6)
+	assert.InDelta(t, 9.5, q.Value().Scalar(), 1.0e-6)
+}
+
+func TestGradientDescent_ZeroLRScale(t *testing.T) {
+	p := nn.NewParam(mat.NewScalar(10))
+	groups := nn.ParamGroups{&nn.ParamGroup{Name: "p", Params: []nn.Param{p}}}
+
+	optimizer := NewOptimizer(plainGD{}, groups)
+	p.PropagateGrad(mat.NewScalar(4))
+	optimizer.Optimize()
+	assert.InDelta(t, 6, p.Value().Scalar(), 1.0e-6)
+}
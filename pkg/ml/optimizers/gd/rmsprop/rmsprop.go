@@ -7,6 +7,7 @@ package rmsprop
 import (
 	mat "github.com/nlpodyssey/spago/pkg/mat32"
 	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/ml/numerics"
 	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd"
 )
 
@@ -30,10 +31,11 @@ func NewConfig(lr, epsilon, decay mat.Float) Config {
 }
 
 // NewDefaultConfig returns a new Config with generically reasonable default values.
+// numerics.Current().Optimizer provides the Epsilon (e.g. 1e-7 under numerics.TensorFlow).
 func NewDefaultConfig() Config {
 	return Config{
 		LR:      0.001,
-		Epsilon: 1e-08,
+		Epsilon: numerics.Current().Optimizer,
 		Decay:   0.95,
 	}
 }
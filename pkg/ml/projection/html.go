@@ -0,0 +1,133 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package projection
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+)
+
+// HTMLOptions provides configuration settings for WriteHTML.
+type HTMLOptions struct {
+	// Title is the title of the page.
+	Title string
+	// Labels, if not empty, contains the label of each point, shown when the
+	// mouse is over the point.
+	Labels []string
+	// Groups, if not empty, contains the group of each point (e.g. its
+	// cluster), which determines its color.
+	Groups []int
+	// ShowLabels writes the labels next to the points.
+	ShowLabels bool
+}
+
+const (
+	htmlPlotSize    = 800
+	htmlPlotPadding = 20
+)
+
+// htmlPalette contains the colors of the groups.
+var htmlPalette = []string{
+	"#1f77b4", "#ff7f0e", "#2ca02c", "#d62728", "#9467bd",
+	"#8c564b", "#e377c2", "#7f7f7f", "#bcbd22", "#17becf",
+}
+
+var htmlTemplate = template.Must(template.New("projection").Parse(`<!doctype html>
+<html lang="">
+<head>
+	<meta charset="utf-8">
+	<title>{{.Title}}</title>
+	<style>
+		body { font-family: sans-serif; margin: 1em; }
+		circle { fill-opacity: 0.7; }
+		circle:hover { fill-opacity: 1; stroke: #000; }
+		text { font-size: 10px; fill: #333; }
+	</style>
+</head>
+<body>
+	<h1>{{.Title}}</h1>
+	<svg width="{{.Size}}" height="{{.Size}}" viewBox="0 0 {{.Size}} {{.Size}}">
+		<rect width="100%" height="100%" fill="#fff" stroke="#ddd"/>
+		{{- range .Points}}
+		<circle cx="{{.X}}" cy="{{.Y}}" r="4" fill="{{.Color}}">{{if .Label}}<title>{{.Label}}</title>{{end}}</circle>
+		{{- if and $.ShowLabels .Label}}
+		<text x="{{.LabelX}}" y="{{.Y}}">{{.Label}}</text>
+		{{- end}}
+		{{- end}}
+	</svg>
+</body>
+</html>
+`))
+
+type htmlPoint struct {
+	X, Y, LabelX string
+	Color        string
+	Label        string
+}
+
+// WriteHTML writes a standalone HTML page with the scatter plot of the
+// points, scaled to fit the plot.
+func WriteHTML(w io.Writer, points []Point, options HTMLOptions) error {
+	if len(options.Labels) != 0 && len(options.Labels) != len(points) {
+		return fmt.Errorf("projection: %d labels for %d points", len(options.Labels), len(points))
+	}
+	if len(options.Groups) != 0 && len(options.Groups) != len(points) {
+		return fmt.Errorf("projection: %d groups for %d points", len(options.Groups), len(points))
+	}
+
+	minX, minY := mat.Inf(1), mat.Inf(1)
+	maxX, maxY := mat.Inf(-1), mat.Inf(-1)
+	for _, p := range points {
+		if p.X < minX {
+			minX = p.X
+		}
+		if p.Y < minY {
+			minY = p.Y
+		}
+		maxX, maxY = mat.Max(maxX, p.X), mat.Max(maxY, p.Y)
+	}
+	// the same scale on both axes preserves the distances
+	scale := mat.Float(htmlPlotSize - 2*htmlPlotPadding)
+	if span := mat.Max(maxX-minX, maxY-minY); span > 0 {
+		scale /= span
+	}
+
+	data := struct {
+		Title      string
+		Size       int
+		ShowLabels bool
+		Points     []htmlPoint
+	}{
+		Title:      options.Title,
+		Size:       htmlPlotSize,
+		ShowLabels: options.ShowLabels,
+		Points:     make([]htmlPoint, len(points)),
+	}
+	for i, p := range points {
+		x := htmlPlotPadding + (p.X-minX)*scale
+		y := htmlPlotSize - htmlPlotPadding - (p.Y-minY)*scale // the y axis points up
+		hp := htmlPoint{
+			X:      fmt.Sprintf("%.2f", x),
+			Y:      fmt.Sprintf("%.2f", y),
+			LabelX: fmt.Sprintf("%.2f", x+6),
+			Color:  htmlPalette[0],
+		}
+		if len(options.Labels) != 0 {
+			hp.Label = options.Labels[i]
+		}
+		if len(options.Groups) != 0 {
+			if group := options.Groups[i]; group >= 0 {
+				hp.Color = htmlPalette[group%len(htmlPalette)]
+			} else {
+				hp.Color = "#cccccc" // e.g. the points not assigned to any cluster
+			}
+		}
+		data.Points[i] = hp
+	}
+	return htmlTemplate.Execute(w, data)
+}
@@ -0,0 +1,42 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package projection
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteHTML(t *testing.T) {
+	points := []Point{{X: 0, Y: 0}, {X: 1, Y: 1}, {X: 2, Y: 0}}
+	var buf bytes.Buffer
+	err := WriteHTML(&buf, points, HTMLOptions{
+		Title:      "Embeddings",
+		Labels:     []string{"cat", "dog", "<car>"},
+		Groups:     []int{0, 0, -1},
+		ShowLabels: true,
+	})
+	require.NoError(t, err)
+	out := buf.String()
+
+	assert.Contains(t, out, "<title>Embeddings</title>")
+	assert.Equal(t, 3, strings.Count(out, "<circle"))
+	assert.Contains(t, out, `<circle cx="20.00" cy="780.00" r="4" fill="#1f77b4"><title>cat</title></circle>`)
+	assert.Contains(t, out, `cx="400.00" cy="400.00"`)
+	assert.Contains(t, out, `fill="#cccccc"`)
+	assert.Contains(t, out, "&lt;car&gt;")
+	assert.NotContains(t, out, "<car>")
+}
+
+func TestWriteHTML_Errors(t *testing.T) {
+	var buf bytes.Buffer
+	points := []Point{{X: 0, Y: 0}, {X: 1, Y: 1}}
+	assert.Error(t, WriteHTML(&buf, points, HTMLOptions{Labels: []string{"a"}}))
+	assert.Error(t, WriteHTML(&buf, points, HTMLOptions{Groups: []int{1, 2, 3}}))
+}
@@ -0,0 +1,86 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package projection
+
+import (
+	"fmt"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+)
+
+// PCA is a principal component analysis fitted on a set of points.
+type PCA struct {
+	// Mean is the mean of the points.
+	Mean *mat.Dense
+	// Components contains a principal axis for each row, in order of
+	// decreasing explained variance.
+	Components *mat.Dense
+	// Variances contains the variance explained by each component.
+	Variances []mat.Float
+}
+
+// FitPCA computes the first numComponents principal components of the points,
+// from the exact singular value decomposition of the centered data.
+func FitPCA(points []mat.Matrix, numComponents int) (*PCA, error) {
+	data, err := vectorsData(points)
+	if err != nil {
+		return nil, err
+	}
+	n, dim := len(data), len(data[0])
+	if numComponents <= 0 || numComponents > dim || numComponents > n {
+		return nil, fmt.Errorf("projection: invalid number of components %d for %d points of size %d",
+			numComponents, n, dim)
+	}
+
+	mean := mat.NewEmptyVecDense(dim)
+	for _, x := range data {
+		mean.AddInPlace(mat.NewVecDense(x))
+	}
+	mean.ProdScalarInPlace(1 / mat.Float(n))
+	centered := mat.NewEmptyDense(n, dim)
+	for i, x := range data {
+		for j, v := range x {
+			centered.Set(i, j, v-mean.AtVec(j))
+		}
+	}
+
+	_, s, vt, err := centered.SVD()
+	if err != nil {
+		return nil, err
+	}
+	pca := &PCA{
+		Mean:       mean,
+		Components: mat.NewEmptyDense(numComponents, dim),
+		Variances:  make([]mat.Float, numComponents),
+	}
+	for k := 0; k < numComponents; k++ {
+		for j := 0; j < dim; j++ {
+			pca.Components.Set(k, j, vt.At(k, j))
+		}
+		if n > 1 {
+			pca.Variances[k] = s[k] * s[k] / mat.Float(n-1)
+		}
+	}
+	return pca, nil
+}
+
+// Transform projects the vector x onto the principal components.
+func (p *PCA) Transform(x mat.Matrix) mat.Matrix {
+	return p.Components.Mul(mat.NewVecDense(x.Data()).Sub(p.Mean))
+}
+
+// PCA2D projects the points onto their first two principal components.
+func PCA2D(points []mat.Matrix) ([]Point, error) {
+	pca, err := FitPCA(points, 2)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Point, len(points))
+	for i, x := range points {
+		y := pca.Transform(x)
+		out[i] = Point{X: y.AtVec(0), Y: y.AtVec(1)}
+	}
+	return out, nil
+}
@@ -0,0 +1,104 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package projection
+
+import (
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFitPCA(t *testing.T) {
+	// points on the line y = 2x in the plane z = 1, with a small uncorrelated noise on z
+	points := []mat.Matrix{
+		mat.NewVecDense([]mat.Float{-2, -4, 1}),
+		mat.NewVecDense([]mat.Float{-1, -2, 0.9}),
+		mat.NewVecDense([]mat.Float{0, 0, 1.2}),
+		mat.NewVecDense([]mat.Float{1, 2, 0.9}),
+		mat.NewVecDense([]mat.Float{2, 4, 1}),
+	}
+	pca, err := FitPCA(points, 2)
+	require.NoError(t, err)
+
+	assert.InDeltaSlice(t, []mat.Float{0, 0, 1}, pca.Mean.Data(), 1.0e-6)
+	axis := pca.Components.ExtractRow(0).Data()
+	if axis[0] < 0 {
+		axis = pca.Components.ExtractRow(0).ProdScalar(-1).Data()
+	}
+	sqrt5 := mat.Sqrt(5)
+	assert.InDeltaSlice(t, []mat.Float{1 / sqrt5, 2 / sqrt5, 0}, axis, 1.0e-3)
+	assert.InDelta(t, 12.5, pca.Variances[0], 1.0e-4)
+	assert.True(t, pca.Variances[1] < 0.1)
+
+	y := pca.Transform(points[4])
+	assert.Equal(t, 2, y.Size())
+	assert.InDelta(t, 2*sqrt5, mat.Abs(y.AtVec(0)), 1.0e-4)
+}
+
+func TestFitPCA_Errors(t *testing.T) {
+	_, err := FitPCA(nil, 2)
+	assert.Error(t, err)
+	points := []mat.Matrix{mat.NewVecDense([]mat.Float{1, 2}), mat.NewVecDense([]mat.Float{3, 4})}
+	_, err = FitPCA(points, 3)
+	assert.Error(t, err)
+	_, err = FitPCA(append(points, mat.NewVecDense([]mat.Float{1})), 1)
+	assert.Error(t, err)
+}
+
+func TestPCA2D(t *testing.T) {
+	out, err := PCA2D(newBlobs())
+	require.NoError(t, err)
+	assert.Len(t, out, 12)
+	assertSeparated(t, out)
+}
+
+// newBlobs returns three well separated groups of four points each.
+func newBlobs() []mat.Matrix {
+	centers := [][]mat.Float{
+		{10, 0, 0, 0, 5},
+		{0, 10, 0, 0, -5},
+		{0, 0, 10, 5, 0},
+	}
+	offsets := [][]mat.Float{
+		{0.5, 0, 0, 0, 0},
+		{0, -0.5, 0, 0, 0},
+		{0, 0, 0.5, 0, 0},
+		{0, 0, 0, -0.5, 0.5},
+	}
+	points := make([]mat.Matrix, 0, 12)
+	for _, c := range centers {
+		for _, o := range offsets {
+			p := make([]mat.Float, len(c))
+			for i := range p {
+				p[i] = c[i] + o[i]
+			}
+			points = append(points, mat.NewVecDense(p))
+		}
+	}
+	return points
+}
+
+// assertSeparated checks that the projected blobs are farther from each other
+// than their points.
+func assertSeparated(t *testing.T, points []Point) {
+	dist := func(a, b Point) mat.Float {
+		return mat.Sqrt((a.X-b.X)*(a.X-b.X) + (a.Y-b.Y)*(a.Y-b.Y))
+	}
+	var maxIntra mat.Float
+	minInter := mat.Inf(1)
+	for i := range points {
+		for j := i + 1; j < len(points); j++ {
+			d := dist(points[i], points[j])
+			if i/4 == j/4 {
+				maxIntra = mat.Max(maxIntra, d)
+			} else if d < minInter {
+				minInter = d
+			}
+		}
+	}
+	assert.Greater(t, float64(minInter), float64(maxIntra))
+}
@@ -0,0 +1,39 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package projection provides dimensionality reduction methods to map dense
+// vectors, such as learned embeddings, onto a plane for their inspection:
+// the exact principal component analysis (PCA), and t-SNE. The resulting
+// points can be exported as a standalone HTML scatter plot with WriteHTML.
+//
+// The input points are given as a slice of vectors of the same size.
+package projection
+
+import (
+	"fmt"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+)
+
+// Point is a point of the plane.
+type Point struct {
+	X, Y mat.Float
+}
+
+// vectorsData returns the data of the points, checking that they are
+// vectors of the same size.
+func vectorsData(points []mat.Matrix) ([][]mat.Float, error) {
+	if len(points) == 0 {
+		return nil, fmt.Errorf("projection: no points")
+	}
+	size := points[0].Size()
+	data := make([][]mat.Float, len(points))
+	for i, p := range points {
+		if !p.IsVector() || p.Size() != size {
+			return nil, fmt.Errorf("projection: the points must be vectors of the same size")
+		}
+		data[i] = p.Data()
+	}
+	return data, nil
+}
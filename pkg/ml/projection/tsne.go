@@ -0,0 +1,217 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package projection
+
+import (
+	"fmt"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/mat32/rand"
+)
+
+// TSNEConfig provides configuration settings for TSNE.
+type TSNEConfig struct {
+	// Perplexity is the effective number of neighbors of each point; it must
+	// be less than the number of points.
+	Perplexity mat.Float
+	// LearningRate is the learning rate of the gradient descent.
+	LearningRate mat.Float
+	// MaxIterations is the number of iterations of the gradient descent.
+	MaxIterations int
+	// EarlyExaggeration multiplies the input similarities during the first
+	// ExaggerationIterations iterations, to better separate the clusters.
+	EarlyExaggeration mat.Float
+	// ExaggerationIterations is the number of iterations of early exaggeration.
+	ExaggerationIterations int
+	// Seed is the seed of the random generator used for the initialization.
+	Seed uint64
+}
+
+// DefaultTSNEConfig returns a TSNEConfig with the default settings.
+func DefaultTSNEConfig() TSNEConfig {
+	return TSNEConfig{
+		Perplexity:             30,
+		LearningRate:           200,
+		MaxIterations:          1000,
+		EarlyExaggeration:      12,
+		ExaggerationIterations: 250,
+		Seed:                   42,
+	}
+}
+
+// TSNE projects the points onto the plane with t-distributed stochastic
+// neighbor embedding, which preserves the local neighborhoods rather than
+// the global distances.
+// The similarities are computed exactly, requiring O(n²) memory and time per
+// iteration for n points: it is meant for a few thousands of points.
+//
+// Reference: "Visualizing Data using t-SNE" by L. van der Maaten and G.
+// Hinton, 2008.
+func TSNE(points []mat.Matrix, config TSNEConfig) ([]Point, error) {
+	data, err := vectorsData(points)
+	if err != nil {
+		return nil, err
+	}
+	n := len(data)
+	if config.Perplexity <= 0 || config.Perplexity >= mat.Float(n) {
+		return nil, fmt.Errorf("projection: invalid perplexity %g for %d points", config.Perplexity, n)
+	}
+	p := jointProbabilities(data, config.Perplexity)
+
+	rndGen := rand.NewLockedRand(config.Seed)
+	y := make([]Point, n)
+	for i := range y {
+		y[i] = Point{X: 1.0e-4 * rndGen.NormFloat32(), Y: 1.0e-4 * rndGen.NormFloat32()}
+	}
+	updates := make([]Point, n)
+	gains := make([]Point, n)
+	for i := range gains {
+		gains[i] = Point{X: 1, Y: 1}
+	}
+	num := make([][]mat.Float, n)
+	for i := range num {
+		num[i] = make([]mat.Float, n)
+	}
+
+	for iter := 0; iter < config.MaxIterations; iter++ {
+		exaggeration, momentum := mat.Float(1), mat.Float(0.8)
+		if iter < config.ExaggerationIterations {
+			exaggeration, momentum = config.EarlyExaggeration, 0.5
+		}
+
+		// Student t-distribution similarities of the projected points
+		var sum mat.Float
+		for i := 0; i < n; i++ {
+			for j := i + 1; j < n; j++ {
+				dx, dy := y[i].X-y[j].X, y[i].Y-y[j].Y
+				v := 1 / (1 + dx*dx + dy*dy)
+				num[i][j], num[j][i] = v, v
+				sum += 2 * v
+			}
+		}
+
+		for i := 0; i < n; i++ {
+			var grad Point
+			for j := 0; j < n; j++ {
+				if j == i {
+					continue
+				}
+				q := mat.Max(num[i][j]/sum, 1.0e-12)
+				f := 4 * (exaggeration*p[i][j] - q) * num[i][j]
+				grad.X += f * (y[i].X - y[j].X)
+				grad.Y += f * (y[i].Y - y[j].Y)
+			}
+			updateGain(&gains[i].X, grad.X, updates[i].X)
+			updateGain(&gains[i].Y, grad.Y, updates[i].Y)
+			updates[i].X = momentum*updates[i].X - config.LearningRate*gains[i].X*grad.X
+			updates[i].Y = momentum*updates[i].Y - config.LearningRate*gains[i].Y*grad.Y
+		}
+
+		var mean Point
+		for i := range y {
+			y[i].X += updates[i].X
+			y[i].Y += updates[i].Y
+			mean.X += y[i].X
+			mean.Y += y[i].Y
+		}
+		for i := range y {
+			y[i].X -= mean.X / mat.Float(n)
+			y[i].Y -= mean.Y / mat.Float(n)
+		}
+	}
+	return y, nil
+}
+
+// updateGain increases the gain when the gradient changes the direction of
+// the update, and decreases it otherwise (delta-bar-delta).
+func updateGain(gain *mat.Float, grad, update mat.Float) {
+	if (grad > 0) != (update > 0) {
+		*gain += 0.2
+	} else {
+		*gain *= 0.8
+	}
+	if *gain < 0.01 {
+		*gain = 0.01
+	}
+}
+
+// jointProbabilities returns the symmetric similarities of the points, from
+// Gaussian conditional distributions whose bandwidths are found by binary
+// search to match the perplexity.
+func jointProbabilities(data [][]mat.Float, perplexity mat.Float) [][]mat.Float {
+	n := len(data)
+	dist := make([][]mat.Float, n)
+	for i := range dist {
+		dist[i] = make([]mat.Float, n)
+	}
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			var d mat.Float
+			for k, v := range data[i] {
+				diff := v - data[j][k]
+				d += diff * diff
+			}
+			dist[i][j], dist[j][i] = d, d
+		}
+	}
+
+	targetEntropy := mat.Log(perplexity)
+	cond := make([][]mat.Float, n)
+	for i := 0; i < n; i++ {
+		cond[i] = make([]mat.Float, n)
+		beta, minBeta, maxBeta := mat.Float(1), mat.Float(0), mat.Inf(1)
+		for step := 0; step < 100; step++ {
+			// the distances are shifted by the minimum one for numerical stability
+			minDist := mat.Inf(1)
+			for j, d := range dist[i] {
+				if j != i && d < minDist {
+					minDist = d
+				}
+			}
+			var sum, weighted mat.Float
+			for j, d := range dist[i] {
+				if j == i {
+					cond[i][j] = 0
+					continue
+				}
+				cond[i][j] = mat.Exp(-(d - minDist) * beta)
+				sum += cond[i][j]
+				weighted += (d - minDist) * cond[i][j]
+			}
+			entropy := mat.Log(sum) + beta*weighted/sum
+			for j := range cond[i] {
+				cond[i][j] /= sum
+			}
+			diff := entropy - targetEntropy
+			if mat.Abs(diff) < 1.0e-5 {
+				break
+			}
+			if diff > 0 {
+				minBeta = beta
+				if maxBeta == mat.Inf(1) {
+					beta *= 2
+				} else {
+					beta = (beta + maxBeta) / 2
+				}
+			} else {
+				maxBeta = beta
+				beta = (beta + minBeta) / 2
+			}
+		}
+	}
+
+	p := make([][]mat.Float, n)
+	for i := range p {
+		p[i] = make([]mat.Float, n)
+	}
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			if j != i {
+				p[i][j] = mat.Max((cond[i][j]+cond[j][i])/mat.Float(2*n), 1.0e-12)
+			}
+		}
+	}
+	return p
+}
@@ -0,0 +1,52 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package projection
+
+import (
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTSNE(t *testing.T) {
+	config := DefaultTSNEConfig()
+	config.Perplexity = 3
+	config.MaxIterations = 500
+	out, err := TSNE(newBlobs(), config)
+	require.NoError(t, err)
+	assert.Len(t, out, 12)
+	assertSeparated(t, out)
+
+	again, err := TSNE(newBlobs(), config)
+	require.NoError(t, err)
+	assert.Equal(t, out, again, "the result must be deterministic given the seed")
+}
+
+func TestTSNE_Errors(t *testing.T) {
+	config := DefaultTSNEConfig()
+	_, err := TSNE(newBlobs(), config) // perplexity 30 with 12 points
+	assert.Error(t, err)
+	config.Perplexity = 0
+	_, err = TSNE(newBlobs(), config)
+	assert.Error(t, err)
+}
+
+func TestJointProbabilities(t *testing.T) {
+	data := make([][]mat.Float, 0)
+	for _, p := range newBlobs() {
+		data = append(data, p.Data())
+	}
+	p := jointProbabilities(data, 3)
+	var sum mat.Float
+	for i := range p {
+		for j := range p[i] {
+			assert.InDelta(t, p[i][j], p[j][i], 1.0e-7)
+			sum += p[i][j]
+		}
+	}
+	assert.InDelta(t, 1, sum, 1.0e-4)
+}
@@ -0,0 +1,54 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package annotators provides the composition of rule-based (regular
+// expressions, dictionaries) and model-based text annotators into a single
+// pipeline, whose outputs are merged into one set of annotations according
+// to a conflict-resolution policy.
+package annotators
+
+import (
+	"github.com/nlpodyssey/spago/pkg/nlp/document"
+)
+
+// Annotation is a labeled span of text.
+// The offsets are expressed in characters (runes), consistently with the
+// tokenizers package.
+type Annotation = document.Annotation
+
+// Annotator is implemented by any value that can annotate a text.
+type Annotator interface {
+	// Name returns the name of the annotator, used as Source of the annotations.
+	Name() string
+	// Annotate returns the annotations found in the text.
+	Annotate(text string) ([]Annotation, error)
+}
+
+// AnnotatorFunc adapts an ordinary function to the Annotator interface. It is
+// mostly useful to wrap model-based annotators (e.g. sequencelabeler.Model).
+type AnnotatorFunc struct {
+	// AnnotatorName is the name of the annotator.
+	AnnotatorName string
+	// Func is the annotation function.
+	Func func(text string) ([]Annotation, error)
+}
+
+var _ Annotator = AnnotatorFunc{}
+
+// Name returns the name of the annotator.
+func (a AnnotatorFunc) Name() string {
+	return a.AnnotatorName
+}
+
+// Annotate calls a.Func(text), setting the Source of the annotations.
+func (a AnnotatorFunc) Annotate(text string) ([]Annotation, error) {
+	annotations, err := a.Func(text)
+	if err != nil {
+		return nil, err
+	}
+	for i := range annotations {
+		annotations[i].Source = a.AnnotatorName
+	}
+	return annotations, nil
+}
@@ -0,0 +1,163 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package annotators
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/nlpodyssey/spago/pkg/nlp/document"
+	"github.com/nlpodyssey/spago/pkg/utils/tracing"
+)
+
+// ConflictPolicy is the enumeration-like type used to distinguish different
+// strategies to resolve the conflicts between overlapping annotations.
+type ConflictPolicy int
+
+const (
+	// Priority resolves the conflicts in favor of the annotation produced by
+	// the annotator which comes first in the Pipeline.
+	Priority ConflictPolicy = iota
+	// Confidence resolves the conflicts in favor of the annotation with the
+	// highest confidence. Ties are resolved by Priority.
+	Confidence
+	// Longest resolves the conflicts in favor of the longest annotation.
+	// Ties are resolved by Confidence.
+	Longest
+	// KeepAll doesn't resolve the conflicts: all the annotations are returned.
+	KeepAll
+)
+
+// Pipeline runs multiple annotators (rule-based and model-based) on the same
+// text, merging their outputs into one set of annotations.
+type Pipeline struct {
+	annotators []Annotator
+	policy     ConflictPolicy
+}
+
+var _ Annotator = &Pipeline{}
+
+// NewPipeline returns a new Pipeline. The order of the annotators is relevant
+// for the Priority conflict policy, where the first annotator has the highest
+// priority.
+func NewPipeline(policy ConflictPolicy, annotators ...Annotator) *Pipeline {
+	return &Pipeline{
+		annotators: annotators,
+		policy:     policy,
+	}
+}
+
+// Name returns the name of the pipeline.
+func (p *Pipeline) Name() string {
+	return "pipeline"
+}
+
+// Annotate runs all the annotators concurrently, and returns the merged
+// annotations sorted by position. The annotations keep the Source of the
+// annotator which produced them.
+func (p *Pipeline) Annotate(text string) ([]Annotation, error) {
+	return p.AnnotateContext(context.Background(), text)
+}
+
+// AnnotateContext is like Annotate, recording the annotation of each
+// annotator and the merge as spans, children of the one contained in the
+// context (see the tracing package).
+func (p *Pipeline) AnnotateContext(ctx context.Context, text string) ([]Annotation, error) {
+	results := make([][]Annotation, len(p.annotators))
+	errs := make([]error, len(p.annotators))
+	var wg sync.WaitGroup
+	wg.Add(len(p.annotators))
+	for i, annotator := range p.annotators {
+		go func(i int, annotator Annotator) {
+			defer wg.Done()
+			_, span := tracing.Start(ctx, "annotators."+annotator.Name())
+			results[i], errs[i] = annotator.Annotate(text)
+			span.SetAttribute("annotations", len(results[i]))
+			tracing.EndWithError(span, errs[i])
+		}(i, annotator)
+	}
+	wg.Wait()
+
+	var candidates []candidate
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("annotators: %s: %w", p.annotators[i].Name(), err)
+		}
+		for _, a := range results[i] {
+			candidates = append(candidates, candidate{Annotation: a, priority: i})
+		}
+	}
+	_, span := tracing.Start(ctx, "annotators.merge")
+	defer span.End()
+	return p.merge(candidates), nil
+}
+
+// Process annotates the text of the document, replacing its entities with the
+// merged annotations.
+func (p *Pipeline) Process(doc *document.Document) error {
+	annotations, err := p.Annotate(doc.Text)
+	if err != nil {
+		return err
+	}
+	doc.Entities = annotations
+	return nil
+}
+
+// candidate is an Annotation along with the priority of its annotator
+// (lower is better).
+type candidate struct {
+	Annotation
+	priority int
+}
+
+// merge selects the annotations according to the conflict policy.
+func (p *Pipeline) merge(candidates []candidate) []Annotation {
+	if p.policy != KeepAll {
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return p.better(candidates[i], candidates[j])
+		})
+	}
+	selected := make([]Annotation, 0, len(candidates))
+	for _, c := range candidates {
+		if p.policy != KeepAll && overlapsAny(c.Annotation, selected) {
+			continue
+		}
+		selected = append(selected, c.Annotation)
+	}
+	sort.SliceStable(selected, func(i, j int) bool {
+		if selected[i].Start != selected[j].Start {
+			return selected[i].Start < selected[j].Start
+		}
+		return selected[i].End < selected[j].End
+	})
+	return selected
+}
+
+// better reports whether a wins over b, according to the conflict policy.
+func (p *Pipeline) better(a, b candidate) bool {
+	switch p.policy {
+	case Longest:
+		if la, lb := a.End-a.Start, b.End-b.Start; la != lb {
+			return la > lb
+		}
+		fallthrough
+	case Confidence:
+		if a.Confidence != b.Confidence {
+			return a.Confidence > b.Confidence
+		}
+	}
+	return a.priority < b.priority
+}
+
+func overlapsAny(a Annotation, others []Annotation) bool {
+	for _, other := range others {
+		if a.Overlaps(other) {
+			return true
+		}
+	}
+	return false
+}
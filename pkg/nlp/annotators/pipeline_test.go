@@ -0,0 +1,137 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package annotators
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/nlpodyssey/spago/pkg/nlp/gazetteer"
+	"github.com/nlpodyssey/spago/pkg/utils/tracing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testText = "Contact Anna Rossi at anna@example.com from Città di Roma"
+
+func newTestAnnotators(t *testing.T) (Annotator, Annotator, Annotator) {
+	email := NewRegexAnnotator("regex", RegexRule{
+		Label:   "EMAIL",
+		Pattern: regexp.MustCompile(`[a-z]+@[a-z]+\.[a-z]+`),
+	})
+
+	g := gazetteer.New([]string{"LOC"}, true)
+	require.NoError(t, g.Add("LOC", "Roma"))
+	dict := NewDictionaryAnnotator("dictionary", g, nil)
+
+	model := AnnotatorFunc{
+		AnnotatorName: "model",
+		Func: func(text string) ([]Annotation, error) {
+			return []Annotation{
+				{Start: 8, End: 18, Text: "Anna Rossi", Label: "PER", Confidence: 0.9},
+				{Start: 22, End: 26, Text: "anna", Label: "PER", Confidence: 0.6},
+				{Start: 44, End: 57, Text: "Città di Roma", Label: "ORG", Confidence: 0.7},
+			}, nil
+		},
+	}
+	return email, dict, model
+}
+
+func TestRegexAnnotator(t *testing.T) {
+	email, _, _ := newTestAnnotators(t)
+	annotations, err := email.Annotate("é a@b.it")
+	require.NoError(t, err)
+	assert.Equal(t, []Annotation{
+		{Start: 2, End: 8, Text: "a@b.it", Label: "EMAIL", Confidence: 1, Source: "regex"},
+	}, annotations)
+}
+
+func TestDictionaryAnnotator(t *testing.T) {
+	_, dict, _ := newTestAnnotators(t)
+	annotations, err := dict.Annotate(testText)
+	require.NoError(t, err)
+	assert.Equal(t, []Annotation{
+		{Start: 53, End: 57, Text: "Roma", Label: "LOC", Confidence: 1, Source: "dictionary"},
+	}, annotations)
+}
+
+func TestPipeline_Annotate(t *testing.T) {
+	email, dict, model := newTestAnnotators(t)
+
+	t.Run("Priority", func(t *testing.T) {
+		annotations, err := NewPipeline(Priority, email, dict, model).Annotate(testText)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"PER", "EMAIL", "LOC"}, labels(annotations))
+		assert.Equal(t, "model", annotations[0].Source)
+	})
+
+	t.Run("Confidence", func(t *testing.T) {
+		annotations, err := NewPipeline(Confidence, model, email, dict).Annotate(testText)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"PER", "EMAIL", "LOC"}, labels(annotations))
+	})
+
+	t.Run("Longest", func(t *testing.T) {
+		annotations, err := NewPipeline(Longest, email, dict, model).Annotate(testText)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"PER", "EMAIL", "ORG"}, labels(annotations))
+	})
+
+	t.Run("KeepAll", func(t *testing.T) {
+		annotations, err := NewPipeline(KeepAll, email, dict, model).Annotate(testText)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"PER", "PER", "EMAIL", "ORG", "LOC"}, labels(annotations))
+	})
+
+	t.Run("error", func(t *testing.T) {
+		failing := AnnotatorFunc{
+			AnnotatorName: "failing",
+			Func: func(string) ([]Annotation, error) {
+				return nil, errors.New("boom")
+			},
+		}
+		_, err := NewPipeline(Priority, email, failing).Annotate(testText)
+		assert.EqualError(t, err, "annotators: failing: boom")
+	})
+}
+
+func TestPipeline_AnnotateContext(t *testing.T) {
+	recorder := tracing.NewRecorder()
+	tracing.SetTracer(recorder)
+	defer tracing.SetTracer(nil)
+
+	email, dict, model := newTestAnnotators(t)
+	ctx, root := tracing.Start(context.Background(), "request")
+	_, err := NewPipeline(Priority, email, dict, model).AnnotateContext(ctx, testText)
+	require.NoError(t, err)
+	root.End()
+
+	spans := recorder.Spans()
+	require.Len(t, spans, 5)
+	assert.ElementsMatch(t, []string{"annotators.regex", "annotators.dictionary", "annotators.model"},
+		recorder.Names()[:3])
+	assert.Equal(t, []string{"annotators.merge", "request"}, recorder.Names()[3:])
+	counts := make(map[string]interface{})
+	for _, s := range spans[:4] {
+		assert.Equal(t, "request", s.Parent.Name)
+		counts[s.Name] = s.Attributes["annotations"]
+	}
+	assert.Equal(t, map[string]interface{}{
+		"annotators.regex":      1,
+		"annotators.dictionary": 1,
+		"annotators.model":      3,
+		"annotators.merge":      nil,
+	}, counts)
+}
+
+func labels(annotations []Annotation) []string {
+	out := make([]string, len(annotations))
+	for i, a := range annotations {
+		out[i] = a.Label
+	}
+	return out
+}
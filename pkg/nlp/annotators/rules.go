@@ -0,0 +1,114 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package annotators
+
+import (
+	"regexp"
+	"unicode/utf8"
+
+	"github.com/nlpodyssey/spago/pkg/nlp/gazetteer"
+	"github.com/nlpodyssey/spago/pkg/nlp/tokenizers"
+	"github.com/nlpodyssey/spago/pkg/nlp/tokenizers/basetokenizer"
+)
+
+// RegexRule associates a regular expression with a label.
+type RegexRule struct {
+	Label   string
+	Pattern *regexp.Regexp
+}
+
+// RegexAnnotator is a rule-based Annotator which labels all the
+// non-overlapping matches of a list of regular expressions.
+// The annotations have confidence 1.
+type RegexAnnotator struct {
+	name  string
+	rules []RegexRule
+}
+
+var _ Annotator = &RegexAnnotator{}
+
+// NewRegexAnnotator returns a new RegexAnnotator.
+func NewRegexAnnotator(name string, rules ...RegexRule) *RegexAnnotator {
+	return &RegexAnnotator{
+		name:  name,
+		rules: rules,
+	}
+}
+
+// Name returns the name of the annotator.
+func (a *RegexAnnotator) Name() string {
+	return a.name
+}
+
+// Annotate returns the matches of all the rules found in the text.
+func (a *RegexAnnotator) Annotate(text string) ([]Annotation, error) {
+	var annotations []Annotation
+	for _, rule := range a.rules {
+		for _, loc := range rule.Pattern.FindAllStringIndex(text, -1) {
+			if loc[0] == loc[1] {
+				continue // skip empty matches
+			}
+			start := utf8.RuneCountInString(text[:loc[0]])
+			annotations = append(annotations, Annotation{
+				Start:      start,
+				End:        start + utf8.RuneCountInString(text[loc[0]:loc[1]]),
+				Text:       text[loc[0]:loc[1]],
+				Label:      rule.Label,
+				Confidence: 1,
+				Source:     a.name,
+			})
+		}
+	}
+	return annotations, nil
+}
+
+// DictionaryAnnotator is a rule-based Annotator which labels the entries of a
+// gazetteer.Gazetteer found in the text, after tokenization.
+// The annotations have confidence 1.
+type DictionaryAnnotator struct {
+	name      string
+	gazetteer *gazetteer.Gazetteer
+	tokenizer tokenizers.Tokenizer
+}
+
+var _ Annotator = &DictionaryAnnotator{}
+
+// NewDictionaryAnnotator returns a new DictionaryAnnotator.
+// If tokenizer is nil, a basetokenizer.BaseTokenizer is used.
+func NewDictionaryAnnotator(name string, g *gazetteer.Gazetteer, tokenizer tokenizers.Tokenizer) *DictionaryAnnotator {
+	if tokenizer == nil {
+		tokenizer = basetokenizer.New()
+	}
+	return &DictionaryAnnotator{
+		name:      name,
+		gazetteer: g,
+		tokenizer: tokenizer,
+	}
+}
+
+// Name returns the name of the annotator.
+func (a *DictionaryAnnotator) Name() string {
+	return a.name
+}
+
+// Annotate returns all the dictionary entries found in the text.
+func (a *DictionaryAnnotator) Annotate(text string) ([]Annotation, error) {
+	tokens := a.tokenizer.Tokenize(text)
+	runes := []rune(text)
+	var annotations []Annotation
+	for _, match := range a.gazetteer.Match(tokenizers.GetStrings(tokens)) {
+		start := tokens[match.Start].Offsets.Start
+		end := tokens[match.End-1].Offsets.End
+		annotations = append(annotations, Annotation{
+			Start:      start,
+			End:        end,
+			Text:       string(runes[start:end]),
+			Label:      match.Label,
+			Confidence: 1,
+			Source:     a.name,
+		})
+	}
+	return annotations, nil
+}
@@ -0,0 +1,21 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package annotators
+
+import (
+	"github.com/nlpodyssey/spago/pkg/nlp/sequencelabeler"
+)
+
+// NewSequenceLabelerAnnotator returns a model-based Annotator which labels the
+// entities recognized by a sequencelabeler.Model.
+// Since the model doesn't provide any score, the annotations have confidence 1.
+func NewSequenceLabelerAnnotator(name string, model *sequencelabeler.Model) Annotator {
+	return AnnotatorFunc{
+		AnnotatorName: name,
+		Func: func(text string) ([]Annotation, error) {
+			return model.AnalyzeDocument(text).Entities, nil
+		},
+	}
+}
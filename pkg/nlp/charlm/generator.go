@@ -6,6 +6,7 @@ package charlm
 
 import (
 	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/mat32/rand"
 	"github.com/nlpodyssey/spago/pkg/ml/ag"
 	"github.com/nlpodyssey/spago/pkg/ml/nn"
 	"github.com/nlpodyssey/spago/pkg/utils"
@@ -15,7 +16,8 @@ import (
 // Generator is a character-level language model text generator.
 type Generator struct {
 	GeneratorConfig
-	model *Model
+	model  *Model
+	rndGen *rand.LockedRand
 }
 
 // GeneratorConfig provides configuration settings for a Character-level Language Model Generator.
@@ -23,6 +25,8 @@ type GeneratorConfig struct {
 	MaxCharacters int
 	StopAtEOS     bool
 	Temperature   mat.Float
+	// Seed is the seed of the generator of random numbers used to sample the characters.
+	Seed uint64
 }
 
 // NewGenerator returns a new Generator.
@@ -30,6 +34,7 @@ func NewGenerator(model *Model, config GeneratorConfig) *Generator {
 	return &Generator{
 		GeneratorConfig: config,
 		model:           model,
+		rndGen:          rand.NewLockedRand(config.Seed),
 	}
 }
 
@@ -64,7 +69,7 @@ func (m *Generator) GenerateText(prefix string) (text string, logProb mat.Float)
 func (m *Generator) generateNext(proc *Model, xs ...string) (next string, prob mat.Float) {
 	lastIndex := len(xs) - 1
 	prediction := proc.Forward(xs).([]ag.Node)[lastIndex].Value().Data() // keep the last prediction only
-	index := sample(prediction, m.Temperature)
+	index := sample(prediction, m.Temperature, m.rndGen)
 	next = m.model.Vocabulary.MustTerm(index)
 	prob = prediction[index]
 	return
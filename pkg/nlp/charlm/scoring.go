@@ -0,0 +1,71 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package charlm
+
+import (
+	"runtime"
+	"sync"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/nlp/lmscoring"
+	"github.com/nlpodyssey/spago/pkg/utils"
+	"github.com/nlpodyssey/spago/pkg/utils/processingqueue"
+)
+
+var _ lmscoring.Scorer = &Model{}
+
+// Score returns the score of the text, whose characters are predicted one by
+// one from the preceding ones, starting from the sequence separator.
+// The sequence separator ending the text is scored as well.
+func (m *Model) Score(text string) (lmscoring.Score, error) {
+	sequence := []string{m.SequenceSeparator}
+	sequence = append(sequence, utils.SplitByRune(text)...)
+	sequence = append(sequence, m.SequenceSeparator)
+	return m.scoreSequence(sequence, 1), nil
+}
+
+// ScoreContinuations returns the score of the characters of each candidate,
+// which is appended to the prefix as is. The candidates are scored
+// concurrently.
+func (m *Model) ScoreContinuations(prefix string, candidates []string) ([]lmscoring.Score, error) {
+	head := append([]string{m.SequenceSeparator}, utils.SplitByRune(prefix)...)
+	scores := make([]lmscoring.Score, len(candidates))
+	pq := processingqueue.New(runtime.NumCPU())
+	var wg sync.WaitGroup
+	wg.Add(len(candidates))
+	for i, candidate := range candidates {
+		i, candidate := i, candidate
+		pq.Go(func() {
+			defer wg.Done()
+			sequence := append(append([]string{}, head...), utils.SplitByRune(candidate)...)
+			scores[i] = m.scoreSequence(sequence, len(head))
+		})
+	}
+	wg.Wait()
+	return scores, nil
+}
+
+// scoreSequence returns the score of the characters of the sequence from the
+// given index onwards.
+func (m *Model) scoreSequence(sequence []string, from int) lmscoring.Score {
+	score := lmscoring.Score{
+		Tokens:   sequence[from:],
+		LogProbs: make([]mat.Float, len(sequence)-from),
+	}
+	if len(score.LogProbs) == 0 {
+		return score
+	}
+	g := ag.NewGraph()
+	defer g.Clear()
+	proc := nn.ReifyForInference(m, g).(*Model)
+	predictions := proc.Forward(sequence[:len(sequence)-1]).([]ag.Node)
+	targets := targetsIds(sequence, m.Vocabulary, m.UnknownToken)
+	for i := from; i < len(sequence); i++ {
+		score.LogProbs[i-from] = lmscoring.LogSoftmaxAt(predictions[i-1].Value().Data(), targets[i-1])
+	}
+	return score
+}
@@ -8,6 +8,7 @@ import (
 	mat "github.com/nlpodyssey/spago/pkg/mat32"
 	"github.com/nlpodyssey/spago/pkg/mat32/floatutils"
 	"github.com/nlpodyssey/spago/pkg/mat32/rand"
+	"github.com/nlpodyssey/spago/pkg/mat32/rand/multinomial"
 	"github.com/nlpodyssey/spago/pkg/nlp/vocabulary"
 )
 
@@ -26,17 +27,9 @@ func targetsIds(sequence []string, vocab *vocabulary.Vocabulary, unknownToken st
 
 // sample extracts the next character from the probability multinomial distribution.
 // Note that the softmax must NOT have been applied to the prediction values.
-func sample(prediction []mat.Float, temperature mat.Float) int {
+func sample(prediction []mat.Float, temperature mat.Float, rndGen *rand.LockedRand) int {
 	for i := range prediction {
 		prediction[i] *= 1.0 / temperature
 	}
-	prediction = floatutils.SoftMax(prediction)
-	p := rand.Float() // TODO: use a local random generator?
-	for i, x := range prediction {
-		p -= x
-		if p < 0 {
-			return i
-		}
-	}
-	return 0 // TODO: should panic here?
+	return multinomial.Distribution(floatutils.SoftMax(prediction), 1, rndGen)[0]
 }
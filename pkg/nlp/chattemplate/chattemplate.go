@@ -0,0 +1,188 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package chattemplate renders the role-based messages of a conversation to
+// the prompt expected by a decoder model, following its convention.
+//
+// The special tokens of the template are injected as single tokens, whereas
+// the content of the messages is always tokenized as plain text, so that a
+// message containing the text of a special token cannot forge it.
+package chattemplate
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Role is the role of the author of a message.
+type Role string
+
+const (
+	// System is the role of the instructions given to the model.
+	System Role = "system"
+	// User is the role of the messages of the user.
+	User Role = "user"
+	// Assistant is the role of the messages of the model.
+	Assistant Role = "assistant"
+)
+
+// Message is a message of a conversation.
+type Message struct {
+	Role    Role
+	Content string
+}
+
+// Format is the text surrounding the content of the messages of a role.
+type Format struct {
+	Prefix string
+	Suffix string
+}
+
+// Template describes how a conversation is rendered to a prompt.
+type Template struct {
+	// Start is prepended to the prompt (e.g. the Beginning-Of-Sequence token).
+	Start string
+	// Roles contains the format of the messages of each role.
+	Roles map[Role]Format
+	// GenerationPrompt is appended to the prompt to make the model reply
+	// (usually the prefix of the assistant messages).
+	GenerationPrompt string
+	// SpecialTokens contains the special tokens occurring in the Start, in the
+	// formats of the roles, and in the GenerationPrompt.
+	SpecialTokens []string
+}
+
+// ChatML is the template of the models trained with the ChatML format.
+var ChatML = Template{
+	Roles: map[Role]Format{
+		System:    {Prefix: "<|im_start|>system\n", Suffix: "<|im_end|>\n"},
+		User:      {Prefix: "<|im_start|>user\n", Suffix: "<|im_end|>\n"},
+		Assistant: {Prefix: "<|im_start|>assistant\n", Suffix: "<|im_end|>\n"},
+	},
+	GenerationPrompt: "<|im_start|>assistant\n",
+	SpecialTokens:    []string{"<|im_start|>", "<|im_end|>"},
+}
+
+// Zephyr is the template of the Zephyr-like models.
+var Zephyr = Template{
+	Roles: map[Role]Format{
+		System:    {Prefix: "<|system|>\n", Suffix: "</s>\n"},
+		User:      {Prefix: "<|user|>\n", Suffix: "</s>\n"},
+		Assistant: {Prefix: "<|assistant|>\n", Suffix: "</s>\n"},
+	},
+	GenerationPrompt: "<|assistant|>\n",
+	SpecialTokens:    []string{"<|system|>", "<|user|>", "<|assistant|>", "</s>"},
+}
+
+// segment is a portion of a rendered prompt.
+type segment struct {
+	text string
+	// special reports whether the text is a special token.
+	special bool
+}
+
+// Render returns the prompt of the conversation. If addGenerationPrompt is
+// true, the GenerationPrompt is appended.
+func (t Template) Render(messages []Message, addGenerationPrompt bool) (string, error) {
+	segments, err := t.segments(messages, addGenerationPrompt)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	for _, s := range segments {
+		sb.WriteString(s.text)
+	}
+	return sb.String(), nil
+}
+
+// TokenizeFunc splits a plain text into tokens.
+type TokenizeFunc func(text string) ([]string, error)
+
+// RenderTokens returns the tokens of the prompt of the conversation, injecting
+// each special token of the template as is, and tokenizing the rest of the
+// prompt with the given function. If addGenerationPrompt is true, the
+// GenerationPrompt is appended.
+func (t Template) RenderTokens(messages []Message, addGenerationPrompt bool, tokenize TokenizeFunc) ([]string, error) {
+	segments, err := t.segments(messages, addGenerationPrompt)
+	if err != nil {
+		return nil, err
+	}
+	tokens := make([]string, 0)
+	var text strings.Builder
+	flush := func() error {
+		if text.Len() == 0 {
+			return nil
+		}
+		pieces, err := tokenize(text.String())
+		if err != nil {
+			return err
+		}
+		tokens = append(tokens, pieces...)
+		text.Reset()
+		return nil
+	}
+	for _, s := range segments {
+		if !s.special {
+			text.WriteString(s.text)
+			continue
+		}
+		if err := flush(); err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, s.text)
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// segments splits the prompt of the conversation into plain texts and
+// special tokens. The contents of the messages are never split.
+func (t Template) segments(messages []Message, addGenerationPrompt bool) ([]segment, error) {
+	segments := t.split(t.Start, nil)
+	for i, m := range messages {
+		format, ok := t.Roles[m.Role]
+		if !ok {
+			return nil, fmt.Errorf("chattemplate: unsupported role %q of message %d", m.Role, i)
+		}
+		segments = t.split(format.Prefix, segments)
+		if m.Content != "" {
+			segments = append(segments, segment{text: m.Content})
+		}
+		segments = t.split(format.Suffix, segments)
+	}
+	if addGenerationPrompt {
+		segments = t.split(t.GenerationPrompt, segments)
+	}
+	return segments, nil
+}
+
+// split appends to segments the text split at the special tokens of the
+// template, preferring the longest special token at each position.
+func (t Template) split(text string, segments []segment) []segment {
+	start := 0
+	for i := 0; i < len(text); {
+		special := ""
+		for _, s := range t.SpecialTokens {
+			if len(s) > len(special) && strings.HasPrefix(text[i:], s) {
+				special = s
+			}
+		}
+		if special == "" {
+			i++
+			continue
+		}
+		if start < i {
+			segments = append(segments, segment{text: text[start:i]})
+		}
+		segments = append(segments, segment{text: special, special: true})
+		i += len(special)
+		start = i
+	}
+	if start < len(text) {
+		segments = append(segments, segment{text: text[start:]})
+	}
+	return segments
+}
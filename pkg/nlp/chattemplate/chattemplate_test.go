@@ -0,0 +1,57 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package chattemplate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var conversation = []Message{
+	{Role: System, Content: "Be brief."},
+	{Role: User, Content: "Hi!"},
+	{Role: Assistant, Content: "Hello."},
+	{Role: User, Content: "Bye <|im_end|>"},
+}
+
+func TestTemplate_Render(t *testing.T) {
+	actual, err := ChatML.Render(conversation, true)
+	require.NoError(t, err)
+	assert.Equal(t, "<|im_start|>system\nBe brief.<|im_end|>\n"+
+		"<|im_start|>user\nHi!<|im_end|>\n"+
+		"<|im_start|>assistant\nHello.<|im_end|>\n"+
+		"<|im_start|>user\nBye <|im_end|><|im_end|>\n"+
+		"<|im_start|>assistant\n", actual)
+
+	actual, err = Zephyr.Render(conversation[1:2], false)
+	require.NoError(t, err)
+	assert.Equal(t, "<|user|>\nHi!</s>\n", actual)
+
+	_, err = ChatML.Render([]Message{{Role: "tool", Content: "x"}}, false)
+	assert.Error(t, err)
+}
+
+func TestTemplate_RenderTokens(t *testing.T) {
+	// each plain text becomes a single token
+	tokenize := func(text string) ([]string, error) {
+		return []string{text}, nil
+	}
+	template := ChatML
+	template.Start = "<s>"
+	template.SpecialTokens = append([]string{"<s>"}, template.SpecialTokens...)
+
+	actual, err := template.RenderTokens(conversation[1:], true, tokenize)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"<s>",
+		"<|im_start|>", "user\nHi!", "<|im_end|>", "\n",
+		"<|im_start|>", "assistant\nHello.", "<|im_end|>", "\n",
+		// the special token in the content is not injected
+		"<|im_start|>", "user\nBye <|im_end|>", "<|im_end|>", "\n",
+		"<|im_start|>", "assistant\n",
+	}, actual)
+}
@@ -0,0 +1,170 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package document provides the data model shared by the NLP pipelines as
+// input and output: a Document is a text enriched with tokens, sentences,
+// entities, relations, categories and arbitrary metadata.
+//
+// The sequence labeler (AnalyzeDocument), the annotators pipeline and the
+// joint NLU model produce documents, while the responses of the BERT and BART
+// servers keep their own wire format and are converted to documents by their
+// Document methods.
+//
+// All offsets are expressed in characters (runes), consistently with the
+// tokenizers package, and the End offsets are exclusive.
+// A Document can be serialized to JSON as is.
+package document
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/nlp/tokenizers"
+)
+
+// Document is a text along with the information extracted from it.
+type Document struct {
+	// Text is the original text.
+	Text string `json:"text"`
+	// Tokens is the list of tokens of the text.
+	Tokens []Token `json:"tokens,omitempty"`
+	// Sentences is the list of sentences of the text.
+	Sentences []Span `json:"sentences,omitempty"`
+	// Entities is the list of labeled spans of the text (e.g. named entities).
+	Entities []Annotation `json:"entities,omitempty"`
+	// Relations is the list of relations between the entities.
+	Relations []Relation `json:"relations,omitempty"`
+	// Categories is the list of labels assigned to the whole text (e.g. by a classifier).
+	Categories []Category `json:"categories,omitempty"`
+	// Metadata contains arbitrary pipeline-specific information.
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// Span is a portion of text, delimited by its character offsets.
+type Span struct {
+	// Start is the offset of the first character of the span.
+	Start int `json:"start"`
+	// End is the offset of the last character of the span (exclusive).
+	End int `json:"end"`
+}
+
+// Token is a token of the text, optionally labeled (e.g. with a POS tag).
+type Token struct {
+	Text  string `json:"text"`
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+	Label string `json:"label,omitempty"`
+}
+
+// Annotation is a labeled span of text.
+type Annotation struct {
+	// Start is the offset of the first character of the span.
+	Start int `json:"start"`
+	// End is the offset of the last character of the span (exclusive).
+	End int `json:"end"`
+	// Text is the text of the span.
+	Text string `json:"text"`
+	// Label is the label of the span (e.g. "PER", "EMAIL").
+	Label string `json:"label"`
+	// Confidence is the confidence of the annotation, in the range [0, 1].
+	Confidence mat.Float `json:"confidence"`
+	// Source is the name of the annotator which produced the annotation.
+	Source string `json:"source,omitempty"`
+}
+
+// Overlaps reports whether the two annotations share at least one character.
+func (a Annotation) Overlaps(other Annotation) bool {
+	return a.Start < other.End && other.Start < a.End
+}
+
+// Relation is a labeled and directed relation between two entities.
+type Relation struct {
+	// Label is the label of the relation (e.g. "works_for").
+	Label string `json:"label"`
+	// Head is the index of the head entity in Document.Entities.
+	Head int `json:"head"`
+	// Tail is the index of the tail entity in Document.Entities.
+	Tail int `json:"tail"`
+	// Confidence is the confidence of the relation, in the range [0, 1].
+	Confidence mat.Float `json:"confidence"`
+}
+
+// Category is a label assigned to the whole text, with its confidence.
+type Category struct {
+	Label      string    `json:"label"`
+	Confidence mat.Float `json:"confidence"`
+}
+
+// New returns a new Document with the given text.
+func New(text string) *Document {
+	return &Document{Text: text}
+}
+
+// SetTokens sets the tokens of the document from the output of a tokenizer.
+func (d *Document) SetTokens(tokens []tokenizers.StringOffsetsPair) {
+	d.Tokens = make([]Token, len(tokens))
+	for i, t := range tokens {
+		d.Tokens[i] = Token{
+			Text:  t.String,
+			Start: t.Offsets.Start,
+			End:   t.Offsets.End,
+		}
+	}
+}
+
+// TokenStrings returns the text of the tokens.
+func (d *Document) TokenStrings() []string {
+	out := make([]string, len(d.Tokens))
+	for i, t := range d.Tokens {
+		out[i] = t.Text
+	}
+	return out
+}
+
+// SpanText returns the portion of text delimited by the span.
+func (d *Document) SpanText(s Span) string {
+	return string([]rune(d.Text)[s.Start:s.End])
+}
+
+// SetMetadata sets the value of a metadata key, initializing the map if needed.
+func (d *Document) SetMetadata(key string, value interface{}) {
+	if d.Metadata == nil {
+		d.Metadata = make(map[string]interface{})
+	}
+	d.Metadata[key] = value
+}
+
+// Validate checks that all the offsets lie within the text and that the
+// relations refer to existing entities.
+func (d *Document) Validate() error {
+	length := utf8.RuneCountInString(d.Text)
+	check := func(kind string, i, start, end int) error {
+		if start < 0 || start > end || end > length {
+			return fmt.Errorf("document: %s %d: invalid offsets [%d, %d) for text of length %d", kind, i, start, end, length)
+		}
+		return nil
+	}
+	for i, t := range d.Tokens {
+		if err := check("token", i, t.Start, t.End); err != nil {
+			return err
+		}
+	}
+	for i, s := range d.Sentences {
+		if err := check("sentence", i, s.Start, s.End); err != nil {
+			return err
+		}
+	}
+	for i, e := range d.Entities {
+		if err := check("entity", i, e.Start, e.End); err != nil {
+			return err
+		}
+	}
+	for i, r := range d.Relations {
+		if r.Head < 0 || r.Head >= len(d.Entities) || r.Tail < 0 || r.Tail >= len(d.Entities) {
+			return fmt.Errorf("document: relation %d: invalid entity indices (%d, %d)", i, r.Head, r.Tail)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package document
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/nlpodyssey/spago/pkg/nlp/tokenizers/basetokenizer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestDocument() *Document {
+	doc := New("Anna lavora a Città di Roma.")
+	doc.SetTokens(basetokenizer.New().Tokenize(doc.Text))
+	doc.Sentences = []Span{{Start: 0, End: 28}}
+	doc.Entities = []Annotation{
+		{Start: 0, End: 4, Text: "Anna", Label: "PER", Confidence: 1},
+		{Start: 14, End: 27, Text: "Città di Roma", Label: "ORG", Confidence: 0.8},
+	}
+	doc.Relations = []Relation{{Label: "works_for", Head: 0, Tail: 1, Confidence: 0.9}}
+	doc.SetMetadata("lang", "it")
+	return doc
+}
+
+func TestDocument_SetTokens(t *testing.T) {
+	doc := newTestDocument()
+	assert.Equal(t, []string{"Anna", "lavora", "a", "Città", "di", "Roma", "."}, doc.TokenStrings())
+	assert.Equal(t, Token{Text: "Città", Start: 14, End: 19}, doc.Tokens[3])
+	assert.Equal(t, "Città di Roma", doc.SpanText(Span{Start: 14, End: 27}))
+}
+
+func TestDocument_JSON(t *testing.T) {
+	doc := newTestDocument()
+	data, err := json.Marshal(doc)
+	require.NoError(t, err)
+
+	var decoded Document
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, doc, &decoded)
+
+	data, err = json.Marshal(New("foo"))
+	require.NoError(t, err)
+	assert.Equal(t, `{"text":"foo"}`, string(data))
+}
+
+func TestDocument_Validate(t *testing.T) {
+	doc := newTestDocument()
+	assert.NoError(t, doc.Validate())
+
+	doc.Entities[1].End = 29
+	assert.EqualError(t, doc.Validate(), "document: entity 1: invalid offsets [14, 29) for text of length 28")
+
+	doc = newTestDocument()
+	doc.Relations[0].Tail = 2
+	assert.EqualError(t, doc.Validate(), "document: relation 0: invalid entity indices (0, 2)")
+}
+
+func TestAnnotation_Overlaps(t *testing.T) {
+	a := Annotation{Start: 0, End: 4}
+	assert.True(t, a.Overlaps(Annotation{Start: 3, End: 5}))
+	assert.False(t, a.Overlaps(Annotation{Start: 4, End: 5}))
+}
@@ -0,0 +1,195 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package postprocessing provides the post-processing of sentence (or word)
+// embeddings, fitted on a sample of embeddings and then applied to each new
+// one at encode time: mean removal, PCA whitening with optional
+// dimensionality truncation, and "all-but-the-top".
+// Such transformations make the embeddings more isotropic, which improves
+// the cosine similarity based retrieval, especially with mean-pooled
+// embeddings.
+package postprocessing
+
+import (
+	"encoding/gob"
+	"fmt"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/ml/projection"
+	"github.com/nlpodyssey/spago/pkg/utils"
+)
+
+// Method is the enumeration-like type used to distinguish the
+// post-processing methods.
+type Method int
+
+const (
+	// MeanRemoval subtracts the mean embedding.
+	MeanRemoval Method = iota
+	// Whitening subtracts the mean embedding and transforms the result so that
+	// its covariance matrix is the identity, optionally keeping only the
+	// first Config.Dimensions principal components.
+	//
+	// Reference: "Whitening Sentence Representations for Better Semantics and
+	// Faster Retrieval" by J. Su, J. Cao, W. Liu and Y. Ou, 2021.
+	Whitening
+	// AllButTheTop subtracts the mean embedding and removes the projections on
+	// the first Config.Dimensions principal components, which encode mostly
+	// the frequency of the words rather than their meaning.
+	//
+	// Reference: "All-but-the-Top: Simple and Effective Postprocessing for Word
+	// Representations" by J. Mu and P. Viswanath, 2018.
+	AllButTheTop
+)
+
+// String returns the name of the method.
+func (m Method) String() string {
+	switch m {
+	case MeanRemoval:
+		return "mean-removal"
+	case Whitening:
+		return "whitening"
+	case AllButTheTop:
+		return "all-but-the-top"
+	default:
+		return fmt.Sprintf("Method(%d)", int(m))
+	}
+}
+
+// Config provides configuration settings for a PostProcessor.
+type Config struct {
+	// Method is the post-processing method.
+	Method Method
+	// Dimensions is the number of output dimensions for Whitening (zero
+	// keeps them all), or the number of removed principal components for
+	// AllButTheTop (zero uses a hundredth of the input dimensions, rounded
+	// up). It is ignored by MeanRemoval.
+	Dimensions int
+	// Epsilon is added to the variances for numerical stability by Whitening.
+	Epsilon mat.Float
+}
+
+// DefaultConfig returns a Config with the given method and the default settings.
+func DefaultConfig(method Method) Config {
+	return Config{
+		Method:  method,
+		Epsilon: 1.0e-6,
+	}
+}
+
+// PostProcessor applies the post-processing fitted on a sample of embeddings.
+type PostProcessor struct {
+	Config Config
+	// Mean is the mean embedding of the sample.
+	Mean *mat.Dense
+	// Components contains a principal component for each row: for Whitening
+	// it is scaled by the inverse of the standard deviation, for AllButTheTop
+	// it is a unit vector to project out. It is nil for MeanRemoval.
+	Components *mat.Dense
+}
+
+func init() {
+	gob.Register(&PostProcessor{})
+}
+
+// Fit returns a new PostProcessor fitted on the sample of embeddings, which
+// must be vectors of the same size.
+func Fit(sample []mat.Matrix, config Config) (*PostProcessor, error) {
+	if len(sample) == 0 {
+		return nil, fmt.Errorf("postprocessing: empty sample")
+	}
+	dim := sample[0].Size()
+	maxComponents := dim
+	if len(sample) < maxComponents {
+		maxComponents = len(sample)
+	}
+
+	numComponents := 1
+	switch config.Method {
+	case MeanRemoval:
+	case Whitening:
+		numComponents = config.Dimensions
+		if numComponents == 0 {
+			numComponents = maxComponents
+		}
+	case AllButTheTop:
+		numComponents = config.Dimensions
+		if numComponents == 0 {
+			numComponents = (dim + 99) / 100
+		}
+	default:
+		return nil, fmt.Errorf("postprocessing: unknown method %v", config.Method)
+	}
+	if numComponents <= 0 || numComponents > maxComponents {
+		return nil, fmt.Errorf("postprocessing: invalid number of dimensions %d for %d embeddings of size %d",
+			numComponents, len(sample), dim)
+	}
+
+	pca, err := projection.FitPCA(sample, numComponents)
+	if err != nil {
+		return nil, err
+	}
+	p := &PostProcessor{
+		Config: config,
+		Mean:   pca.Mean,
+	}
+	switch config.Method {
+	case Whitening:
+		p.Components = pca.Components
+		for k, variance := range pca.Variances {
+			scale := 1 / mat.Sqrt(variance+config.Epsilon)
+			for j := 0; j < dim; j++ {
+				p.Components.Set(k, j, p.Components.At(k, j)*scale)
+			}
+		}
+	case AllButTheTop:
+		p.Components = pca.Components
+	}
+	return p, nil
+}
+
+// Apply returns the post-processed embedding.
+func (p *PostProcessor) Apply(x mat.Matrix) mat.Matrix {
+	centered := mat.NewVecDense(x.Data()).Sub(p.Mean)
+	switch p.Config.Method {
+	case Whitening:
+		return p.Components.Mul(centered)
+	case AllButTheTop:
+		projections := p.Components.Mul(centered)
+		return centered.Sub(p.Components.T().Mul(projections))
+	default:
+		return centered
+	}
+}
+
+// ApplyAll returns the post-processed embeddings.
+func (p *PostProcessor) ApplyAll(xs []mat.Matrix) []mat.Matrix {
+	out := make([]mat.Matrix, len(xs))
+	for i, x := range xs {
+		out[i] = p.Apply(x)
+	}
+	return out
+}
+
+// OutputSize returns the size of the post-processed embeddings.
+func (p *PostProcessor) OutputSize() int {
+	if p.Config.Method == Whitening {
+		return p.Components.Rows()
+	}
+	return p.Mean.Size()
+}
+
+// Save writes the PostProcessor to file.
+func (p *PostProcessor) Save(filename string) error {
+	return utils.SerializeToFile(filename, p)
+}
+
+// Load reads a PostProcessor from file.
+func Load(filename string) (*PostProcessor, error) {
+	p := new(PostProcessor)
+	if err := utils.DeserializeFromFile(filename, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
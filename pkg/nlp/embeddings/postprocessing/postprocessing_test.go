@@ -0,0 +1,120 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package postprocessing
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/mat32/rand"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFit_MeanRemoval(t *testing.T) {
+	sample := newSample()
+	p, err := Fit(sample, DefaultConfig(MeanRemoval))
+	require.NoError(t, err)
+	assert.Nil(t, p.Components)
+	assert.Equal(t, 4, p.OutputSize())
+
+	mean := mat.NewEmptyVecDense(4)
+	for _, y := range p.ApplyAll(sample) {
+		mean.AddInPlace(y)
+	}
+	assert.InDeltaSlice(t, []mat.Float{0, 0, 0, 0}, mean.Data(), 1.0e-3)
+}
+
+func TestFit_Whitening(t *testing.T) {
+	sample := newSample()
+	p, err := Fit(sample, DefaultConfig(Whitening))
+	require.NoError(t, err)
+	assert.Equal(t, 4, p.OutputSize())
+	assertIdentityCovariance(t, p.ApplyAll(sample), 4)
+
+	config := DefaultConfig(Whitening)
+	config.Dimensions = 2
+	p, err = Fit(sample, config)
+	require.NoError(t, err)
+	assert.Equal(t, 2, p.OutputSize())
+	out := p.ApplyAll(sample)
+	assert.Equal(t, 2, out[0].Size())
+	assertIdentityCovariance(t, out, 2)
+}
+
+func TestFit_AllButTheTop(t *testing.T) {
+	sample := newSample()
+	config := DefaultConfig(AllButTheTop)
+	config.Dimensions = 1
+	p, err := Fit(sample, config)
+	require.NoError(t, err)
+	assert.Equal(t, 4, p.OutputSize())
+
+	// the output has no component along the top direction
+	top := p.Components.ExtractRow(0)
+	for _, y := range p.ApplyAll(sample) {
+		assert.InDelta(t, 0, top.DotUnitary(y), 1.0e-3)
+	}
+
+	p, err = Fit(sample, DefaultConfig(AllButTheTop))
+	require.NoError(t, err)
+	assert.Equal(t, 1, p.Components.Rows())
+}
+
+func TestFit_Errors(t *testing.T) {
+	_, err := Fit(nil, DefaultConfig(Whitening))
+	assert.Error(t, err)
+	config := DefaultConfig(Whitening)
+	config.Dimensions = 5
+	_, err = Fit(newSample(), config)
+	assert.Error(t, err)
+	_, err = Fit(newSample(), DefaultConfig(Method(42)))
+	assert.Error(t, err)
+}
+
+func TestPostProcessor_Save(t *testing.T) {
+	dir, err := ioutil.TempDir("", "postprocessing")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	sample := newSample()
+	p, err := Fit(sample, DefaultConfig(Whitening))
+	require.NoError(t, err)
+	filename := filepath.Join(dir, "whitening.bin")
+	require.NoError(t, p.Save(filename))
+
+	loaded, err := Load(filename)
+	require.NoError(t, err)
+	assert.Equal(t, Whitening, loaded.Config.Method)
+	assert.InDeltaSlice(t, p.Apply(sample[0]).Data(), loaded.Apply(sample[0]).Data(), 1.0e-6)
+}
+
+// newSample returns anisotropic embeddings, with a large common offset and a
+// dominant direction.
+func newSample() []mat.Matrix {
+	rndGen := rand.NewLockedRand(42)
+	sample := make([]mat.Matrix, 200)
+	for i := range sample {
+		x := make([]mat.Float, 4)
+		main := 5 * rndGen.NormFloat32()
+		for j := range x {
+			x[j] = 3 + main*mat.Float(j+1)/4 + rndGen.NormFloat32()
+		}
+		sample[i] = mat.NewVecDense(x)
+	}
+	return sample
+}
+
+func assertIdentityCovariance(t *testing.T, xs []mat.Matrix, size int) {
+	cov := mat.NewEmptyDense(size, size)
+	for _, x := range xs {
+		cov.AddInPlace(x.Mul(x.T()))
+	}
+	cov.ProdScalarInPlace(1 / mat.Float(len(xs)-1))
+	assert.InDeltaSlice(t, mat.I(size).Data(), cov.Data(), 1.0e-2)
+}
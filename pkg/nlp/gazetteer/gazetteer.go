@@ -0,0 +1,158 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package gazetteer provides dictionary-based features for sequence labeling.
+//
+// A Gazetteer stores lists of (possibly multi-token) entries, each associated
+// with a label (e.g. "LOC", "ORG"), in a trie of tokens. Given a sequence of
+// words, all the entries occurring in it are matched, and every word receives
+// a "begin" or "inside" feature for each label of the matching entries.
+//
+// The Model turns such features into trainable per-word embeddings. It
+// satisfies the stackedembeddings.WordsEncoderProcessor interface, so it can
+// be easily combined with other (e.g. contextual) word representations.
+package gazetteer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Match is an entry of the Gazetteer found in a sequence of words.
+type Match struct {
+	// Start is the index of the first word of the match.
+	Start int
+	// End is the index of the last word of the match (exclusive).
+	End int
+	// Label is the label associated with the matching entry.
+	Label string
+}
+
+// Gazetteer is a collection of labeled entries, stored in a trie of tokens.
+type Gazetteer struct {
+	// Labels is the list of the known labels. Each label is identified by its index.
+	Labels []string
+	// Lowercase reports whether entries and words are lowercased before matching.
+	Lowercase bool
+	// Root is the root node of the trie.
+	Root *TrieNode
+}
+
+// TrieNode is a node of the Gazetteer trie.
+type TrieNode struct {
+	// Children maps the next token of an entry to the related node.
+	Children map[string]*TrieNode
+	// Labels contains the indices of the labels of the entries ending at this node.
+	Labels []int
+}
+
+// New returns a new empty Gazetteer for the given labels.
+func New(labels []string, lowercase bool) *Gazetteer {
+	return &Gazetteer{
+		Labels:    labels,
+		Lowercase: lowercase,
+		Root:      newTrieNode(),
+	}
+}
+
+func newTrieNode() *TrieNode {
+	return &TrieNode{Children: make(map[string]*TrieNode)}
+}
+
+// LabelIndex returns the index of the given label, or -1 if it is unknown.
+func (g *Gazetteer) LabelIndex(label string) int {
+	for i, l := range g.Labels {
+		if l == label {
+			return i
+		}
+	}
+	return -1
+}
+
+// Add adds an entry, made of one or more tokens, with the given label.
+// It returns an error if the label is unknown or the entry is empty.
+func (g *Gazetteer) Add(label string, tokens ...string) error {
+	labelIndex := g.LabelIndex(label)
+	if labelIndex == -1 {
+		return fmt.Errorf("gazetteer: unknown label %#v", label)
+	}
+	if len(tokens) == 0 {
+		return fmt.Errorf("gazetteer: empty entry for label %#v", label)
+	}
+	node := g.Root
+	for _, token := range tokens {
+		token = g.normalize(token)
+		child, ok := node.Children[token]
+		if !ok {
+			child = newTrieNode()
+			node.Children[token] = child
+		}
+		node = child
+	}
+	for _, l := range node.Labels {
+		if l == labelIndex {
+			return nil // already present
+		}
+	}
+	node.Labels = append(node.Labels, labelIndex)
+	return nil
+}
+
+// Load reads the entries from r, adding them to the Gazetteer.
+// Each line must contain a label and an entry separated by a tab character;
+// the tokens of the entry are separated by whitespaces. Empty lines are skipped.
+func (g *Gazetteer) Load(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		fields := strings.SplitN(line, "\t", 2)
+		if len(fields) != 2 {
+			return fmt.Errorf("gazetteer: malformed line %d: %#v", lineNumber, line)
+		}
+		if err := g.Add(fields[0], strings.Fields(fields[1])...); err != nil {
+			return fmt.Errorf("gazetteer: line %d: %w", lineNumber, err)
+		}
+	}
+	return scanner.Err()
+}
+
+// Match returns all the entries occurring in the sequence of words, sorted by
+// start index and then by length. Overlapping matches are all reported.
+func (g *Gazetteer) Match(words []string) []Match {
+	normalized := make([]string, len(words))
+	for i, word := range words {
+		normalized[i] = g.normalize(word)
+	}
+	var matches []Match
+	for start := range normalized {
+		node := g.Root
+		for end := start; end < len(normalized); end++ {
+			next, ok := node.Children[normalized[end]]
+			if !ok {
+				break
+			}
+			node = next
+			for _, labelIndex := range node.Labels {
+				matches = append(matches, Match{
+					Start: start,
+					End:   end + 1,
+					Label: g.Labels[labelIndex],
+				})
+			}
+		}
+	}
+	return matches
+}
+
+func (g *Gazetteer) normalize(token string) string {
+	if g.Lowercase {
+		return strings.ToLower(token)
+	}
+	return token
+}
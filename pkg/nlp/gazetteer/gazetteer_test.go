@@ -0,0 +1,85 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gazetteer
+
+import (
+	"strings"
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestGazetteer(t *testing.T) *Gazetteer {
+	g := New([]string{"LOC", "ORG"}, true)
+	err := g.Load(strings.NewReader("LOC\tNew York\nLOC\tNew York City\n\nORG\tNew York Times\nLOC\tRome\n"))
+	require.NoError(t, err)
+	return g
+}
+
+func TestGazetteer_Add(t *testing.T) {
+	g := New([]string{"LOC"}, false)
+	assert.NoError(t, g.Add("LOC", "Rome"))
+	assert.NoError(t, g.Add("LOC", "Rome"))
+	assert.Equal(t, []int{0}, g.Root.Children["Rome"].Labels)
+	assert.Error(t, g.Add("PER", "John"))
+	assert.Error(t, g.Add("LOC"))
+}
+
+func TestGazetteer_Load(t *testing.T) {
+	g := New([]string{"LOC"}, false)
+	assert.Error(t, g.Load(strings.NewReader("LOC Rome\n")))
+	assert.Error(t, g.Load(strings.NewReader("PER\tJohn\n")))
+}
+
+func TestGazetteer_Match(t *testing.T) {
+	g := newTestGazetteer(t)
+	matches := g.Match(strings.Fields("From new York City to rome"))
+	assert.Equal(t, []Match{
+		{Start: 1, End: 3, Label: "LOC"},
+		{Start: 1, End: 4, Label: "LOC"},
+		{Start: 5, End: 6, Label: "LOC"},
+	}, matches)
+
+	assert.Empty(t, g.Match(strings.Fields("New Jersey")))
+}
+
+func TestGazetteer_Features(t *testing.T) {
+	g := newTestGazetteer(t)
+	features := g.Features(strings.Fields("the New York Times"))
+	assert.Len(t, features, 4)
+	assert.Equal(t, []mat.Float{0, 0, 0, 0}, features[0].Data())
+	assert.Equal(t, []mat.Float{1, 0, 1, 0}, features[1].Data())
+	assert.Equal(t, []mat.Float{0, 1, 0, 1}, features[2].Data())
+	assert.Equal(t, []mat.Float{0, 0, 0, 1}, features[3].Data())
+}
+
+func TestModel_Encode(t *testing.T) {
+	model := NewModel(Config{Labels: []string{"LOC", "ORG"}, Size: 2, Lowercase: true})
+	require.NoError(t, model.Gazetteer.Add("LOC", "Rome"))
+	require.NoError(t, model.Gazetteer.Add("ORG", "FAO", "Rome"))
+	model.W.Value().SetData([]mat.Float{
+		0.1, 0.2, 0.3, 0.4,
+		0.5, 0.6, 0.7, 0.8,
+	})
+
+	g := ag.NewGraph()
+	proc := nn.ReifyForTraining(model, g).(*Model)
+	ys := proc.Encode(strings.Fields("FAO Rome ."))
+
+	assert.Len(t, ys, 3)
+	assert.InDeltaSlice(t, []mat.Float{0.3, 0.7}, ys[0].Value().Data(), 1.0e-6)
+	assert.InDeltaSlice(t, []mat.Float{0.5, 1.3}, ys[1].Value().Data(), 1.0e-6)
+	assert.InDeltaSlice(t, []mat.Float{0.0, 0.0}, ys[2].Value().Data(), 1.0e-6)
+
+	g.Backward(ys[1], ag.OutputGrad(mat.NewVecDense([]mat.Float{1, 2})))
+	assert.InDeltaSlice(t, []mat.Float{
+		1, 0, 0, 1,
+		2, 0, 0, 2,
+	}, model.W.Grad().Data(), 1.0e-6)
+}
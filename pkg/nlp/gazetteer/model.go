@@ -0,0 +1,87 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gazetteer
+
+import (
+	"encoding/gob"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/nlp/stackedembeddings"
+)
+
+var (
+	_ nn.Model                                = &Model{}
+	_ stackedembeddings.WordsEncoderProcessor = &Model{}
+)
+
+// Config provides configuration settings for a gazetteer Model.
+type Config struct {
+	// Labels is the list of the labels of the Gazetteer entries.
+	Labels []string
+	// Size of the output embedding vectors.
+	Size int
+	// Lowercase reports whether entries and words are lowercased before matching.
+	Lowercase bool
+}
+
+// Model encodes the Gazetteer features of each word into an embedding.
+// Each label has two features, "begin" and "inside": the embedding of a word
+// is the sum of the embeddings of its active features (zero if none).
+type Model struct {
+	nn.BaseModel
+	Config
+	Gazetteer *Gazetteer
+	// W is the Size × (2 × len(Labels)) matrix of the feature embeddings.
+	W nn.Param `spago:"type:weights"`
+}
+
+func init() {
+	gob.Register(&Model{})
+}
+
+// NewModel returns a new Model with an empty Gazetteer, and the feature
+// embeddings initialized to zeros.
+func NewModel(config Config) *Model {
+	return &Model{
+		Config:    config,
+		Gazetteer: New(config.Labels, config.Lowercase),
+		W:         nn.NewParam(mat.NewEmptyDense(config.Size, FeaturesSize(len(config.Labels)))),
+	}
+}
+
+// FeaturesSize returns the number of features for the given number of labels.
+func FeaturesSize(labels int) int {
+	return 2 * labels
+}
+
+// Features returns, for each word, the multi-hot vector of its Gazetteer
+// features. The features of the label i are at index 2i ("begin") and 2i+1
+// ("inside").
+func (g *Gazetteer) Features(words []string) []*mat.Dense {
+	features := make([]*mat.Dense, len(words))
+	for i := range features {
+		features[i] = mat.NewEmptyVecDense(FeaturesSize(len(g.Labels)))
+	}
+	for _, match := range g.Match(words) {
+		labelIndex := g.LabelIndex(match.Label)
+		features[match.Start].SetVec(2*labelIndex, 1)
+		for i := match.Start + 1; i < match.End; i++ {
+			features[i].SetVec(2*labelIndex+1, 1)
+		}
+	}
+	return features
+}
+
+// Encode transforms a string sequence into an encoded representation.
+func (m *Model) Encode(words []string) []ag.Node {
+	g := m.Graph()
+	out := make([]ag.Node, len(words))
+	for i, features := range m.Gazetteer.Features(words) {
+		out[i] = g.Mul(m.W, g.NewVariable(features, false))
+	}
+	return out
+}
@@ -0,0 +1,165 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package jointnlu provides a joint model for intent classification and slot
+// filling, the two core tasks of the natural language understanding (NLU) of
+// chatbots and voice assistants.
+//
+// A shared bidirectional LSTM encodes the words of the utterance. Its states
+// feed both a CRF layer, which labels the slots (BIOES or BIO scheme), and an
+// intent classifier applied to their average.
+//
+// Reference: "A Bi-model based RNN Semantic Frame Parsing Model for Intent
+// Detection and Slot Filling" by Yu Wang, Yilin Shen and Hongxia Jin, 2018.
+// (https://arxiv.org/abs/1812.10235)
+package jointnlu
+
+import (
+	"encoding/gob"
+	"runtime"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/mat32/floatutils"
+	"github.com/nlpodyssey/spago/pkg/mat32/rand"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/nlpodyssey/spago/pkg/ml/initializers"
+	"github.com/nlpodyssey/spago/pkg/ml/losses"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/ml/nn/birnn"
+	"github.com/nlpodyssey/spago/pkg/ml/nn/crf"
+	"github.com/nlpodyssey/spago/pkg/ml/nn/linear"
+	"github.com/nlpodyssey/spago/pkg/nlp/document"
+	"github.com/nlpodyssey/spago/pkg/nlp/stackedembeddings"
+	"github.com/nlpodyssey/spago/pkg/nlp/tokenizers"
+	"github.com/nlpodyssey/spago/pkg/nlp/tokenizers/basetokenizer"
+)
+
+var (
+	_ nn.Model = &Model{}
+)
+
+// Config provides configuration settings for a joint NLU Model.
+type Config struct {
+	// EncodingSize is the size of the word encodings produced by the embeddings layer.
+	EncodingSize int
+	// HiddenSize is the size of the hidden state of each direction of the shared encoder.
+	HiddenSize int
+	// Intents is the list of the intent labels.
+	Intents []string
+	// Slots is the list of the slot labels, in BIOES or BIO scheme (e.g. "O", "B-city", "E-city").
+	Slots []string
+}
+
+// Model implements a joint intent classification and slot filling model.
+type Model struct {
+	nn.BaseModel
+	Config           Config
+	EmbeddingsLayer  *stackedembeddings.Model
+	Encoder          *birnn.Model
+	SlotScorer       *linear.Model
+	CRF              *crf.Model
+	IntentClassifier *linear.Model
+}
+
+func init() {
+	gob.Register(&Model{})
+}
+
+// New returns a new Model, using the given embeddings layer to encode the words.
+func New(config Config, embeddingsLayer *stackedembeddings.Model) *Model {
+	return &Model{
+		Config:           config,
+		EmbeddingsLayer:  embeddingsLayer,
+		Encoder:          birnn.NewBiLSTM(config.EncodingSize, config.HiddenSize, birnn.Concat),
+		SlotScorer:       linear.New(2*config.HiddenSize, len(config.Slots)),
+		CRF:              crf.New(len(config.Slots)),
+		IntentClassifier: linear.New(2*config.HiddenSize, len(config.Intents)),
+	}
+}
+
+// Initialize initializes the weights of the Model m using the given random generator.
+func (m *Model) Initialize(rndGen *rand.LockedRand) {
+	nn.ForEachParam(m, func(param nn.Param) {
+		if param.Type() == nn.Weights {
+			initializers.XavierUniform(param.Value(), 1, rndGen)
+		}
+	})
+}
+
+// Forward returns the scores of the intents (logits) and the emission scores
+// of the slots for each word.
+func (m *Model) Forward(words []string) (intentScores ag.Node, slotScores []ag.Node) {
+	states := m.Encoder.Forward(m.EmbeddingsLayer.Encode(words)...)
+	intentScores = m.IntentClassifier.Forward(m.Graph().Mean(states))[0]
+	slotScores = m.SlotScorer.Forward(states...)
+	return
+}
+
+// Loss returns the sum of the intent cross-entropy loss and the slots CRF
+// negative log-likelihood, with respect to the gold intent and slots indices.
+func (m *Model) Loss(words []string, intent int, slots []int) ag.Node {
+	intentScores, slotScores := m.Forward(words)
+	g := m.Graph()
+	return g.Add(
+		losses.CrossEntropy(g, intentScores, intent),
+		m.CRF.NegativeLogLoss(slotScores, slots),
+	)
+}
+
+// Prediction is the output of the Model for a sequence of words.
+type Prediction struct {
+	// Intent is the predicted intent.
+	Intent string
+	// IntentDistribution contains the probability of each intent.
+	IntentDistribution []mat.Float
+	// Slots contains the predicted slot label of each word.
+	Slots []string
+}
+
+// Predict returns the most likely intent and slot labels.
+func (m *Model) Predict(words []string) Prediction {
+	intentScores, slotScores := m.Forward(words)
+	distribution := floatutils.SoftMax(intentScores.Value().Data())
+	slots := m.CRF.Decode(slotScores)
+	prediction := Prediction{
+		Intent:             m.Config.Intents[floatutils.ArgMax(distribution)],
+		IntentDistribution: distribution,
+		Slots:              make([]string, len(slots)),
+	}
+	for i, label := range slots {
+		prediction.Slots[i] = m.Config.Slots[label]
+	}
+	return prediction
+}
+
+// Parse analyzes the text, returning a new document.Document whose categories
+// are the intents sorted by confidence, whose tokens are labeled with the
+// slots and whose entities are the filled slots.
+// If tokenizer is nil, a basetokenizer.BaseTokenizer is used.
+func (m *Model) Parse(text string, tokenizer tokenizers.Tokenizer) *document.Document {
+	if tokenizer == nil {
+		tokenizer = basetokenizer.New()
+	}
+	doc := document.New(text)
+	doc.SetTokens(tokenizer.Tokenize(text))
+	if len(doc.Tokens) == 0 {
+		return doc
+	}
+
+	g := ag.NewGraph(ag.ConcurrentComputations(runtime.NumCPU()))
+	defer g.Clear()
+	proc := nn.ReifyForInference(m, g).(*Model)
+	prediction := proc.Predict(doc.TokenStrings())
+
+	doc.Categories = make([]document.Category, len(m.Config.Intents))
+	for i, intent := range m.Config.Intents {
+		doc.Categories[i] = document.Category{Label: intent, Confidence: prediction.IntentDistribution[i]}
+	}
+	sortCategories(doc.Categories)
+	for i, label := range prediction.Slots {
+		doc.Tokens[i].Label = label
+	}
+	doc.Entities = slotsToEntities(doc)
+	return doc
+}
@@ -0,0 +1,112 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jointnlu
+
+import (
+	"strings"
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/mat32/rand"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/ml/nn/linear"
+	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd"
+	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd/adam"
+	"github.com/nlpodyssey/spago/pkg/nlp/document"
+	"github.com/nlpodyssey/spago/pkg/nlp/stackedembeddings"
+	"github.com/stretchr/testify/assert"
+)
+
+// oneHotEncoder encodes the words as one-hot vectors.
+type oneHotEncoder struct {
+	nn.BaseModel
+	Vocabulary []string
+}
+
+func (m *oneHotEncoder) Encode(words []string) []ag.Node {
+	out := make([]ag.Node, len(words))
+	for i, word := range words {
+		v := mat.NewEmptyVecDense(len(m.Vocabulary))
+		for j, term := range m.Vocabulary {
+			if term == word {
+				v.SetVec(j, 1)
+			}
+		}
+		out[i] = m.Graph().NewVariable(v, false)
+	}
+	return out
+}
+
+type example struct {
+	text   string
+	intent int
+	slots  []int
+}
+
+func TestModel_Train(t *testing.T) {
+	vocabulary := strings.Fields("book a flight to rome new york what is the weather in")
+	config := Config{
+		EncodingSize: 8,
+		HiddenSize:   8,
+		Intents:      []string{"book_flight", "get_weather"},
+		Slots:        []string{"O", "S-city", "B-city", "E-city"},
+	}
+	model := New(config, &stackedembeddings.Model{
+		WordsEncoders:   []stackedembeddings.WordsEncoderProcessor{&oneHotEncoder{Vocabulary: vocabulary}},
+		ProjectionLayer: linear.New(len(vocabulary), config.EncodingSize),
+	})
+	model.Initialize(rand.NewLockedRand(42))
+
+	dataset := []example{
+		{"book a flight to rome", 0, []int{0, 0, 0, 0, 1}},
+		{"book a flight to new york", 0, []int{0, 0, 0, 0, 2, 3}},
+		{"what is the weather in rome", 1, []int{0, 0, 0, 0, 0, 1}},
+		{"what is the weather in new york", 1, []int{0, 0, 0, 0, 0, 2, 3}},
+	}
+	optimizer := gd.NewOptimizer(adam.New(adam.NewConfig(0.01, 0.9, 0.999, 1.0e-8)), nn.NewDefaultParamsIterator(model))
+	for epoch := 0; epoch < 60; epoch++ {
+		for _, ex := range dataset {
+			g := ag.NewGraph()
+			proc := nn.ReifyForTraining(model, g).(*Model)
+			g.Backward(proc.Loss(strings.Fields(ex.text), ex.intent, ex.slots))
+			optimizer.Optimize()
+			g.Clear()
+		}
+	}
+
+	doc := model.Parse("what is the weather in new york", nil)
+	assert.Equal(t, "get_weather", doc.Categories[0].Label)
+	assert.Greater(t, doc.Categories[0].Confidence, doc.Categories[1].Confidence)
+	assert.Equal(t, "E-city", doc.Tokens[6].Label)
+	assert.Equal(t, []document.Annotation{
+		{Start: 23, End: 31, Text: "new york", Label: "city", Confidence: 1},
+	}, doc.Entities)
+
+	doc = model.Parse("book a flight to rome", nil)
+	assert.Equal(t, "book_flight", doc.Categories[0].Label)
+	assert.Equal(t, []document.Annotation{
+		{Start: 17, End: 21, Text: "rome", Label: "city", Confidence: 1},
+	}, doc.Entities)
+}
+
+func TestSlotsToEntities(t *testing.T) {
+	doc := document.New("fly from new york to rome via la")
+	doc.Tokens = []document.Token{
+		{Text: "fly", Start: 0, End: 3, Label: "O"},
+		{Text: "from", Start: 4, End: 8, Label: "O"},
+		{Text: "new", Start: 9, End: 12, Label: "B-from"},
+		{Text: "york", Start: 13, End: 17, Label: "I-from"},
+		{Text: "to", Start: 18, End: 20, Label: "O"},
+		{Text: "rome", Start: 21, End: 25, Label: "S-to"},
+		{Text: "via", Start: 26, End: 29, Label: "O"},
+		{Text: "la", Start: 30, End: 32, Label: "I-via"},
+	}
+	assert.Equal(t, []document.Annotation{
+		{Start: 9, End: 17, Text: "new york", Label: "from", Confidence: 1},
+		{Start: 21, End: 25, Text: "rome", Label: "to", Confidence: 1},
+		{Start: 30, End: 32, Text: "la", Label: "via", Confidence: 1},
+	}, slotsToEntities(doc))
+}
@@ -0,0 +1,60 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jointnlu
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/nlpodyssey/spago/pkg/nlp/document"
+)
+
+// slotsToEntities merges the labeled tokens of the document into entities,
+// according to the BIOES (or BIO) scheme. Inconsistent sequences are resolved
+// leniently: an "I-" or "E-" label which doesn't continue a slot of the same
+// type starts a new one.
+func slotsToEntities(doc *document.Document) []document.Annotation {
+	entities := make([]document.Annotation, 0)
+	var cur *document.Annotation
+	flush := func() {
+		if cur != nil {
+			cur.Text = doc.SpanText(document.Span{Start: cur.Start, End: cur.End})
+			entities = append(entities, *cur)
+			cur = nil
+		}
+	}
+	for _, token := range doc.Tokens {
+		prefix, slot := splitLabel(token.Label)
+		switch {
+		case prefix == "O":
+			flush()
+			continue
+		case prefix == "B" || prefix == "S" || cur == nil || cur.Label != slot:
+			flush()
+			cur = &document.Annotation{Start: token.Start, Label: slot, Confidence: 1}
+		}
+		cur.End = token.End
+		if prefix == "E" || prefix == "S" {
+			flush()
+		}
+	}
+	flush()
+	return entities
+}
+
+// splitLabel splits a label such as "B-city" into its prefix and slot name.
+func splitLabel(label string) (prefix, slot string) {
+	if i := strings.IndexByte(label, '-'); i == 1 {
+		return label[:1], label[2:]
+	}
+	return "O", ""
+}
+
+// sortCategories sorts the categories by descending confidence.
+func sortCategories(categories []document.Category) {
+	sort.SliceStable(categories, func(i, j int) bool {
+		return categories[i].Confidence > categories[j].Confidence
+	})
+}
@@ -0,0 +1,360 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package lda provides topic modeling with Latent Dirichlet Allocation (LDA),
+// trained with online variational Bayes, so that large corpora can be
+// processed in a single streaming pass of mini-batches.
+//
+// Reference: "Online Learning for Latent Dirichlet Allocation" by Matthew D.
+// Hoffman, David M. Blei and Francis Bach, 2010.
+// (https://papers.nips.cc/paper/2010/hash/71f6278d140af599e06ad9bf1ba03cb0-Abstract.html)
+package lda
+
+import (
+	"encoding/gob"
+	"fmt"
+	"math"
+	"sort"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/mat32/rand"
+	"github.com/nlpodyssey/spago/pkg/nlp/corpora"
+	"github.com/nlpodyssey/spago/pkg/nlp/tokenizers"
+	"github.com/nlpodyssey/spago/pkg/nlp/vocabulary"
+	"github.com/nlpodyssey/spago/pkg/utils"
+)
+
+// Config provides configuration settings for an LDA Model.
+type Config struct {
+	// NumTopics is the number of topics.
+	NumTopics int
+	// Alpha is the parameter of the Dirichlet prior on the topics of a document.
+	Alpha mat.Float
+	// Eta is the parameter of the Dirichlet prior on the words of a topic.
+	Eta mat.Float
+	// Tau0 slows down the early updates (learning rate delay).
+	Tau0 mat.Float
+	// Kappa is the forgetting rate, in (0.5, 1]: the weight of the t-th
+	// update is (Tau0 + t)^-Kappa.
+	Kappa mat.Float
+	// CorpusSize is the (estimated) total number of documents, used to scale
+	// the statistics of each mini-batch. If it is zero, the mini-batches are
+	// not scaled.
+	CorpusSize int
+	// MaxIterations is the maximum number of iterations of the inference of
+	// the topics of a document.
+	MaxIterations int
+	// Tolerance stops the inference of the topics of a document when the mean
+	// change of its variational parameters is lower.
+	Tolerance mat.Float
+}
+
+// DefaultConfig returns a Config with the given number of topics, and the
+// default settings of the reference paper.
+func DefaultConfig(numTopics int) Config {
+	return Config{
+		NumTopics:     numTopics,
+		Alpha:         1 / mat.Float(numTopics),
+		Eta:           1 / mat.Float(numTopics),
+		Tau0:          1024,
+		Kappa:         0.7,
+		MaxIterations: 100,
+		Tolerance:     1.0e-3,
+	}
+}
+
+// Model is an LDA topic model.
+type Model struct {
+	Config
+	// Vocabulary contains the words known by the model.
+	Vocabulary *vocabulary.Vocabulary
+	// Lambda contains the variational parameters of the word distribution of
+	// each topic (a row for each topic, a column for each word).
+	Lambda *mat.Dense
+	// Updates is the number of mini-batches processed so far.
+	Updates int
+}
+
+func init() {
+	gob.Register(&Model{})
+}
+
+// New returns a new Model for the words of the given vocabulary, whose
+// parameters are randomly initialized with the given generator.
+func New(config Config, vocab *vocabulary.Vocabulary, rndGen *rand.LockedRand) *Model {
+	lambda := mat.NewEmptyDense(config.NumTopics, vocab.Size())
+	data := lambda.Data()
+	for i := range data {
+		// approximately Gamma(100, 1/100), as in the reference implementation
+		data[i] = mat.Max(1+0.1*rndGen.NormFloat32(), 0.01)
+	}
+	return &Model{
+		Config:     config,
+		Vocabulary: vocab,
+		Lambda:     lambda,
+	}
+}
+
+// NewVocabulary returns a new vocabulary with the words occurring at least
+// minCount times in the documents, sorted by decreasing frequency.
+func NewVocabulary(docs [][]string, minCount int) *vocabulary.Vocabulary {
+	counts := make(map[string]int)
+	for _, doc := range docs {
+		for _, word := range doc {
+			counts[word]++
+		}
+	}
+	words := make([]string, 0, len(counts))
+	for word, count := range counts {
+		if count >= minCount {
+			words = append(words, word)
+		}
+	}
+	sort.Slice(words, func(i, j int) bool {
+		if counts[words[i]] != counts[words[j]] {
+			return counts[words[i]] > counts[words[j]]
+		}
+		return words[i] < words[j]
+	})
+	return vocabulary.New(words)
+}
+
+// Document is the bag-of-words representation of a document.
+type Document struct {
+	// IDs contains the vocabulary ids of the distinct words.
+	IDs []int
+	// Counts contains the number of occurrences of each word.
+	Counts []mat.Float
+}
+
+// NewDocument returns the Document with the given words. The words out of
+// the vocabulary are ignored.
+func (m *Model) NewDocument(words []string) Document {
+	index := make(map[int]int)
+	var doc Document
+	for _, word := range words {
+		id, ok := m.Vocabulary.ID(word)
+		if !ok {
+			continue
+		}
+		if i, ok := index[id]; ok {
+			doc.Counts[i]++
+			continue
+		}
+		index[id] = len(doc.IDs)
+		doc.IDs = append(doc.IDs, id)
+		doc.Counts = append(doc.Counts, 1)
+	}
+	return doc
+}
+
+// Train performs a single pass of online training over the corpus, in which
+// each line is a document split into words by the tokenizer.
+// The model is updated every batchSize documents.
+func (m *Model) Train(corpus corpora.TextCorpusIterator, tokenizer tokenizers.Tokenizer, batchSize int) {
+	batch := make([]Document, 0, batchSize)
+	corpus.ForEachLine(func(_ int, line string) {
+		batch = append(batch, m.NewDocument(tokenizers.GetStrings(tokenizer.Tokenize(line))))
+		if len(batch) == batchSize {
+			m.Update(batch)
+			batch = batch[:0]
+		}
+	})
+	if len(batch) > 0 {
+		m.Update(batch)
+	}
+}
+
+// Update performs an online variational Bayes step on a mini-batch of documents.
+func (m *Model) Update(batch []Document) {
+	if len(batch) == 0 {
+		return
+	}
+	expElogBeta := m.expElogBeta()
+	stats := make([]float64, len(expElogBeta))
+	for _, doc := range batch {
+		m.inferGamma(doc, expElogBeta, stats)
+	}
+
+	corpusSize := m.CorpusSize
+	if corpusSize <= 0 {
+		corpusSize = len(batch)
+	}
+	scale := float64(corpusSize) / float64(len(batch))
+	rho := math.Pow(float64(m.Tau0)+float64(m.Updates), -float64(m.Kappa))
+	eta := float64(m.Eta)
+
+	lambda := m.Lambda.Data()
+	for i := range lambda {
+		target := eta + scale*stats[i]*expElogBeta[i]
+		lambda[i] = mat.Float((1-rho)*float64(lambda[i]) + rho*target)
+	}
+	m.Updates++
+}
+
+// Infer returns the topic distribution of the document.
+func (m *Model) Infer(doc Document) []mat.Float {
+	return normalize(m.inferGamma(doc, m.expElogBeta(), nil))
+}
+
+// InferWords returns the topic distribution of the document with the given words.
+func (m *Model) InferWords(words []string) []mat.Float {
+	return m.Infer(m.NewDocument(words))
+}
+
+// Topic returns the word distribution of the k-th topic, indexed by the
+// vocabulary ids.
+func (m *Model) Topic(k int) []mat.Float {
+	row := m.Lambda.Data()[k*m.Lambda.Columns() : (k+1)*m.Lambda.Columns()]
+	values := make([]float64, len(row))
+	for i, v := range row {
+		values[i] = float64(v)
+	}
+	return normalize(values)
+}
+
+// WordProbability is a word with its probability in a topic.
+type WordProbability struct {
+	Word        string
+	Probability mat.Float
+}
+
+// TopWords returns the n most probable words of the k-th topic.
+func (m *Model) TopWords(k, n int) []WordProbability {
+	topic := m.Topic(k)
+	indices := mat.ArgSort(mat.NewVecDense(topic), true)
+	if n > len(indices) {
+		n = len(indices)
+	}
+	out := make([]WordProbability, n)
+	for i, id := range indices[:n] {
+		out[i] = WordProbability{Word: m.Vocabulary.MustTerm(id), Probability: topic[id]}
+	}
+	return out
+}
+
+// Save serializes the model to file.
+func (m *Model) Save(filename string) error {
+	return utils.SerializeToFile(filename, m)
+}
+
+// LoadModel loads a Model from file.
+func LoadModel(filename string) (*Model, error) {
+	m := new(Model)
+	if err := utils.DeserializeFromFile(filename, m); err != nil {
+		return nil, fmt.Errorf("lda: error during model deserialization: %w", err)
+	}
+	return m, nil
+}
+
+// expElogBeta returns exp(E[log β]) for each topic and word, where β is the
+// word distribution of a topic, flattened in the same layout of Lambda.
+func (m *Model) expElogBeta() []float64 {
+	numWords := m.Lambda.Columns()
+	lambda := m.Lambda.Data()
+	out := make([]float64, len(lambda))
+	for k := 0; k < m.NumTopics; k++ {
+		row := lambda[k*numWords : (k+1)*numWords]
+		var sum float64
+		for _, v := range row {
+			sum += float64(v)
+		}
+		psiSum := digamma(sum)
+		for w, v := range row {
+			out[k*numWords+w] = math.Exp(digamma(float64(v)) - psiSum)
+		}
+	}
+	return out
+}
+
+// inferGamma returns the variational parameters of the topic distribution of
+// the document (E-step). If stats is not nil, the sufficient statistics of
+// the document are added to it.
+func (m *Model) inferGamma(doc Document, expElogBeta, stats []float64) []float64 {
+	numTopics, numWords := m.NumTopics, m.Lambda.Columns()
+	alpha := float64(m.Alpha)
+	gamma := make([]float64, numTopics)
+	last := make([]float64, numTopics)
+	expElogTheta := make([]float64, numTopics)
+	phiNorm := make([]float64, len(doc.IDs))
+
+	update := func() {
+		dirichletExpectation(gamma, expElogTheta)
+		for i, w := range doc.IDs {
+			sum := 1.0e-100
+			for k, t := range expElogTheta {
+				sum += t * expElogBeta[k*numWords+w]
+			}
+			phiNorm[i] = sum
+		}
+	}
+
+	for k := range gamma {
+		gamma[k] = 1
+	}
+	update()
+	for iter := 0; iter < m.MaxIterations; iter++ {
+		copy(last, gamma)
+		for k, t := range expElogTheta {
+			var sum float64
+			for i, w := range doc.IDs {
+				sum += float64(doc.Counts[i]) / phiNorm[i] * expElogBeta[k*numWords+w]
+			}
+			gamma[k] = alpha + t*sum
+		}
+		update()
+		var change float64
+		for k, g := range gamma {
+			change += math.Abs(g - last[k])
+		}
+		if change/float64(numTopics) < float64(m.Tolerance) {
+			break
+		}
+	}
+
+	if stats != nil {
+		for k, t := range expElogTheta {
+			for i, w := range doc.IDs {
+				stats[k*numWords+w] += t * float64(doc.Counts[i]) / phiNorm[i]
+			}
+		}
+	}
+	return gamma
+}
+
+// dirichletExpectation sets out to exp(E[log θ]), where θ ~ Dir(alpha).
+func dirichletExpectation(alpha, out []float64) {
+	var sum float64
+	for _, a := range alpha {
+		sum += a
+	}
+	psiSum := digamma(sum)
+	for i, a := range alpha {
+		out[i] = math.Exp(digamma(a) - psiSum)
+	}
+}
+
+// digamma returns the logarithmic derivative of the gamma function at x > 0,
+// using the recurrence ψ(x) = ψ(x+1) - 1/x and the asymptotic expansion.
+func digamma(x float64) float64 {
+	var result float64
+	for ; x < 6; x++ {
+		result -= 1 / x
+	}
+	f := 1 / (x * x)
+	return result + math.Log(x) - 0.5/x -
+		f*(1.0/12-f*(1.0/120-f*(1.0/252-f*(1.0/240-f*(1.0/132)))))
+}
+
+func normalize(values []float64) []mat.Float {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	out := make([]mat.Float, len(values))
+	for i, v := range values {
+		out[i] = mat.Float(v / sum)
+	}
+	return out
+}
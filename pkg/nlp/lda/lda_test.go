@@ -0,0 +1,144 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lda
+
+import (
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/mat32/rand"
+	"github.com/nlpodyssey/spago/pkg/nlp/tokenizers/basetokenizer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	fruitWords = []string{"apple", "banana", "juice", "orange", "fruit", "sweet"}
+	carWords   = []string{"car", "engine", "wheel", "road", "drive", "fuel"}
+)
+
+type linesCorpus []string
+
+func (c linesCorpus) ForEachLine(callback func(i int, line string)) {
+	for i, line := range c {
+		callback(i+1, line)
+	}
+}
+
+func newTestCorpus(rndGen *rand.LockedRand, size int) linesCorpus {
+	corpus := make(linesCorpus, size)
+	for i := range corpus {
+		words := fruitWords
+		if i%2 == 1 {
+			words = carWords
+		}
+		doc := make([]string, 10)
+		for j := range doc {
+			doc[j] = words[rndGen.Intn(len(words))]
+		}
+		corpus[i] = strings.Join(doc, " ")
+	}
+	return corpus
+}
+
+func newTestModel(t *testing.T) *Model {
+	rndGen := rand.NewLockedRand(42)
+	corpus := newTestCorpus(rndGen, 100)
+	docs := make([][]string, len(corpus))
+	for i, line := range corpus {
+		docs[i] = strings.Fields(line)
+	}
+	vocab := NewVocabulary(docs, 1)
+	require.Equal(t, 12, vocab.Size())
+
+	config := DefaultConfig(2)
+	config.Tau0 = 1
+	config.CorpusSize = len(corpus)
+	model := New(config, vocab, rndGen)
+	for epoch := 0; epoch < 10; epoch++ {
+		model.Train(corpus, basetokenizer.New(), 20)
+	}
+	assert.Equal(t, 50, model.Updates)
+	return model
+}
+
+func TestModel_Train(t *testing.T) {
+	model := newTestModel(t)
+
+	fruit := model.InferWords([]string{"apple", "juice", "banana", "unknown"})
+	car := model.InferWords([]string{"engine", "road", "wheel"})
+	assert.InDelta(t, 1, fruit[0]+fruit[1], 1.0e-5)
+	assert.InDelta(t, 1, car[0]+car[1], 1.0e-5)
+
+	fruitTopic := 0
+	if fruit[1] > fruit[0] {
+		fruitTopic = 1
+	}
+	carTopic := 1 - fruitTopic
+	assert.Greater(t, fruit[fruitTopic], mat.Float(0.8))
+	assert.Greater(t, car[carTopic], mat.Float(0.8))
+
+	top := model.TopWords(fruitTopic, len(fruitWords))
+	for _, w := range top {
+		assert.Contains(t, fruitWords, w.Word)
+	}
+	top = model.TopWords(carTopic, 100)
+	assert.Len(t, top, 12)
+	for _, w := range top[:len(carWords)] {
+		assert.Contains(t, carWords, w.Word)
+	}
+
+	var sum mat.Float
+	for _, p := range model.Topic(carTopic) {
+		sum += p
+	}
+	assert.InDelta(t, 1, sum, 1.0e-5)
+}
+
+func TestModel_NewDocument(t *testing.T) {
+	model := New(DefaultConfig(2), NewVocabulary([][]string{{"a", "b", "b"}}, 1), rand.NewLockedRand(1))
+	doc := model.NewDocument([]string{"b", "c", "a", "b"})
+	assert.Equal(t, []int{0, 1}, doc.IDs) // "b" is the most frequent word
+	assert.Equal(t, []mat.Float{2, 1}, doc.Counts)
+
+	empty := model.Infer(model.NewDocument([]string{"c"}))
+	assert.InDeltaSlice(t, []mat.Float{0.5, 0.5}, empty, 1.0e-6)
+}
+
+func TestModel_Save(t *testing.T) {
+	model := newTestModel(t)
+	dir, err := ioutil.TempDir("", "spago-lda-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "model.bin")
+	require.NoError(t, model.Save(filename))
+	loaded, err := LoadModel(filename)
+	require.NoError(t, err)
+
+	assert.Equal(t, model.Config, loaded.Config)
+	assert.Equal(t, model.Updates, loaded.Updates)
+	assert.Equal(t, model.Vocabulary.Items(), loaded.Vocabulary.Items())
+	assert.Equal(t, model.Lambda.Data(), loaded.Lambda.Data())
+
+	words := []string{"apple", "engine", "juice"}
+	assert.Equal(t, model.InferWords(words), loaded.InferWords(words))
+
+	_, err = LoadModel(filepath.Join(dir, "missing.bin"))
+	assert.Error(t, err)
+}
+
+func TestDigamma(t *testing.T) {
+	const eulerGamma = 0.5772156649015329
+	assert.InDelta(t, -eulerGamma, digamma(1), 1.0e-10)
+	assert.InDelta(t, -eulerGamma-2*math.Ln2, digamma(0.5), 1.0e-10)
+	assert.InDelta(t, 1-eulerGamma, digamma(2), 1.0e-10)
+	assert.InDelta(t, math.Log(1000)-0.5/1000, digamma(1000), 1.0e-7)
+}
@@ -0,0 +1,98 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package lmscoring defines the API to score texts with language models,
+// e.g. to rerank the hypotheses of speech recognition or machine translation
+// systems, or to filter noisy data.
+//
+// The causal language models (e.g. charlm.Model) compute the log-probability
+// of each token given the preceding ones; the masked language models (e.g.
+// bert.Model) compute the pseudo-log-likelihood, i.e. the log-probability of
+// each token given all the others, masking one token at a time.
+package lmscoring
+
+import (
+	"sort"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+)
+
+// Scorer is implemented by the language models able to score texts.
+type Scorer interface {
+	// Score returns the score of the whole text.
+	Score(text string) (Score, error)
+	// ScoreContinuations returns the score of each candidate continuation of
+	// the prefix, computed on the tokens of the candidate only.
+	ScoreContinuations(prefix string, candidates []string) ([]Score, error)
+}
+
+// Score is the score of a text given by a language model.
+type Score struct {
+	// Tokens contains the scored tokens.
+	Tokens []string
+	// LogProbs contains the (natural) log-probability of each token.
+	LogProbs []mat.Float
+}
+
+// LogProb returns the log-probability of the text, i.e. the sum of the
+// log-probabilities of its tokens.
+func (s Score) LogProb() mat.Float {
+	var sum mat.Float
+	for _, lp := range s.LogProbs {
+		sum += lp
+	}
+	return sum
+}
+
+// MeanLogProb returns the mean log-probability of the tokens, which doesn't
+// penalize the longer texts. It is zero if there are no tokens.
+func (s Score) MeanLogProb() mat.Float {
+	if len(s.LogProbs) == 0 {
+		return 0
+	}
+	return s.LogProb() / mat.Float(len(s.LogProbs))
+}
+
+// Perplexity returns the perplexity of the text, i.e. the exponential of the
+// negative mean log-probability of its tokens.
+func (s Score) Perplexity() mat.Float {
+	return mat.Exp(-s.MeanLogProb())
+}
+
+// Rank returns the indices of the scores sorted from the best to the worst,
+// by log-probability, or by mean log-probability if normalize is true.
+func Rank(scores []Score, normalize bool) []int {
+	values := make([]mat.Float, len(scores))
+	for i, s := range scores {
+		if normalize {
+			values[i] = s.MeanLogProb()
+		} else {
+			values[i] = s.LogProb()
+		}
+	}
+	indices := make([]int, len(scores))
+	for i := range indices {
+		indices[i] = i
+	}
+	sort.SliceStable(indices, func(a, b int) bool {
+		return values[indices[a]] > values[indices[b]]
+	})
+	return indices
+}
+
+// LogSoftmaxAt returns the log-probability of the i-th element of the
+// distribution given by the (unnormalized) logits.
+func LogSoftmaxAt(logits []mat.Float, i int) mat.Float {
+	max := logits[0]
+	for _, v := range logits[1:] {
+		if v > max {
+			max = v
+		}
+	}
+	var sum mat.Float
+	for _, v := range logits {
+		sum += mat.Exp(v - max)
+	}
+	return logits[i] - max - mat.Log(sum)
+}
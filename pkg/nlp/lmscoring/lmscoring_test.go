@@ -0,0 +1,43 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package lmscoring
+
+import (
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScore(t *testing.T) {
+	s := Score{
+		Tokens:   []string{"a", "b"},
+		LogProbs: []mat.Float{mat.Log(0.5), mat.Log(0.125)},
+	}
+	assert.InDelta(t, mat.Log(0.0625), s.LogProb(), 1.0e-6)
+	assert.InDelta(t, mat.Log(0.25), s.MeanLogProb(), 1.0e-6)
+	assert.InDelta(t, 4, s.Perplexity(), 1.0e-5)
+
+	assert.Equal(t, mat.Float(0), Score{}.MeanLogProb())
+}
+
+func TestRank(t *testing.T) {
+	scores := []Score{
+		{LogProbs: []mat.Float{-1, -1, -1}}, // sum -3, mean -1
+		{LogProbs: []mat.Float{-2}},         // sum -2, mean -2
+		{LogProbs: []mat.Float{-0.5, -2}},   // sum -2.5, mean -1.25
+	}
+	assert.Equal(t, []int{1, 2, 0}, Rank(scores, false))
+	assert.Equal(t, []int{0, 2, 1}, Rank(scores, true))
+}
+
+func TestLogSoftmaxAt(t *testing.T) {
+	logits := []mat.Float{1, 2, 3}
+	probs := []mat.Float{0.09003057, 0.24472847, 0.66524096}
+	for i, p := range probs {
+		assert.InDelta(t, mat.Log(p), LogSoftmaxAt(logits, i), 1.0e-5)
+	}
+	assert.InDelta(t, mat.Log(0.5), LogSoftmaxAt([]mat.Float{1000, 1000}, 0), 1.0e-5)
+}
@@ -0,0 +1,98 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pii
+
+import (
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/nlpodyssey/spago/pkg/nlp/annotators"
+	"github.com/nlpodyssey/spago/pkg/nlp/safety"
+)
+
+// IDPatterns returns the patterns of common identification numbers: IBAN
+// bank accounts (verified with their mod-97 checksum) and US social security
+// numbers (excluding the never assigned ones).
+func IDPatterns() []safety.Pattern {
+	return []safety.Pattern{
+		{Category: "iban", Regexp: regexp.MustCompile(`\b[A-Z]{2}\d{2}(?: ?[A-Z0-9]{4}){2,7}(?: ?[A-Z0-9]{1,3})?\b`), Validate: validIBAN},
+		{Category: "ssn", Regexp: regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`), Validate: validSSN},
+	}
+}
+
+// DefaultPatterns returns the IDPatterns followed by the safety.PIIPatterns
+// (emails, payment cards, phones and IP addresses).
+func DefaultPatterns() []safety.Pattern {
+	return append(IDPatterns(), safety.PIIPatterns()...)
+}
+
+func validIBAN(s string) bool {
+	s = strings.ReplaceAll(s, " ", "")
+	var digits strings.Builder
+	for _, r := range s[4:] + s[:4] {
+		if r >= 'A' && r <= 'Z' {
+			digits.WriteString(strconv.Itoa(int(r-'A') + 10))
+		} else {
+			digits.WriteRune(r)
+		}
+	}
+	n, ok := new(big.Int).SetString(digits.String(), 10)
+	return ok && n.Mod(n, big.NewInt(97)).Int64() == 1
+}
+
+func validSSN(s string) bool {
+	area, group, serial := s[0:3], s[4:6], s[7:]
+	return area != "000" && area != "666" && area[0] != '9' && group != "00" && serial != "0000"
+}
+
+// PatternAnnotator is an annotators.Annotator of the matches of validated
+// regular expressions, labeled with their uppercase category (e.g. "EMAIL").
+// The matches overlapping the ones of a previous pattern are discarded.
+type PatternAnnotator struct {
+	filter *safety.PatternFilter
+}
+
+var _ annotators.Annotator = &PatternAnnotator{}
+
+// NewPatternAnnotator returns a new PatternAnnotator.
+func NewPatternAnnotator(name string, patterns []safety.Pattern) *PatternAnnotator {
+	return &PatternAnnotator{filter: safety.NewPatternFilter(name, patterns)}
+}
+
+// Name returns the name of the annotator.
+func (a *PatternAnnotator) Name() string {
+	return a.filter.Name()
+}
+
+// Annotate returns the matches of the patterns.
+func (a *PatternAnnotator) Annotate(text string) ([]annotators.Annotation, error) {
+	findings, err := a.filter.Check(text)
+	if err != nil {
+		return nil, err
+	}
+	runes := []rune(text)
+	annotations := make([]annotators.Annotation, len(findings))
+	for i, f := range findings {
+		annotations[i] = annotators.Annotation{
+			Start:      f.Start,
+			End:        f.End,
+			Text:       string(runes[f.Start:f.End]),
+			Label:      strings.ToUpper(f.Category),
+			Confidence: f.Score,
+			Source:     a.Name(),
+		}
+	}
+	return annotations, nil
+}
+
+// NewDetector returns an annotators.Pipeline of the DefaultPatterns followed
+// by the given annotators (e.g. a NER), resolving the conflicts in favor of
+// the patterns.
+func NewDetector(others ...annotators.Annotator) *annotators.Pipeline {
+	all := append([]annotators.Annotator{NewPatternAnnotator("patterns", DefaultPatterns())}, others...)
+	return annotators.NewPipeline(annotators.Priority, all...)
+}
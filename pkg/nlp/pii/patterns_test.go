@@ -0,0 +1,36 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pii
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPatternAnnotator(t *testing.T) {
+	a := NewPatternAnnotator("patterns", DefaultPatterns())
+	text := "IBAN GB82 WEST 1234 5698 7654 32 (not GB00WEST12345698765432), SSN 123-45-6789 (not 666-45-6789), mail a@b.io"
+	annotations, err := a.Annotate(text)
+	require.NoError(t, err)
+
+	actual := make(map[string]string)
+	for _, ann := range annotations {
+		assert.Equal(t, ann.Text, string([]rune(text)[ann.Start:ann.End]))
+		assert.Equal(t, "patterns", ann.Source)
+		actual[ann.Text] = ann.Label
+	}
+	assert.Equal(t, map[string]string{
+		"GB82 WEST 1234 5698 7654 32": "IBAN",
+		"123-45-6789":                 "SSN",
+		"a@b.io":                      "EMAIL",
+	}, actual)
+}
+
+func TestValidIBAN(t *testing.T) {
+	assert.True(t, validIBAN("DE89370400440532013000"))
+	assert.False(t, validIBAN("DE89370400440532013001"))
+}
@@ -0,0 +1,193 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package pii implements the detection and anonymization of the personal
+// data (PII) in a text.
+//
+// The detection is delegated to an annotators.Annotator, usually the one
+// returned by NewDetector, which combines the validated regular expressions
+// of DefaultPatterns with model-based annotators such as a NER
+// (see annotators.NewSequenceLabelerAnnotator). The Anonymizer then replaces
+// each entity according to the Strategy of its label, optionally returning
+// the mapping needed to restore the pseudonymized entities.
+//
+// The entities are located by the document.Span of their annotations.
+package pii
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/nlpodyssey/spago/pkg/nlp/annotators"
+)
+
+// Strategy is the enumeration-like type used to distinguish how an
+// Anonymizer replaces the entities.
+type Strategy int
+
+const (
+	// Redact replaces the entities with their label (e.g. "[EMAIL]").
+	// It is not reversible.
+	Redact Strategy = iota
+	// Pseudonymize replaces the entities with a numbered pseudonym of their
+	// label (e.g. "<EMAIL_1>"), the same one for all the occurrences of the
+	// same text within a call to Anonymize.
+	Pseudonymize
+	// Keep leaves the entities unchanged.
+	Keep
+)
+
+// String returns the name of the strategy.
+func (s Strategy) String() string {
+	switch s {
+	case Redact:
+		return "redact"
+	case Pseudonymize:
+		return "pseudonymize"
+	case Keep:
+		return "keep"
+	default:
+		return fmt.Sprintf("Strategy(%d)", int(s))
+	}
+}
+
+// ParseStrategy returns the Strategy with the given name.
+func ParseStrategy(name string) (Strategy, error) {
+	for _, s := range []Strategy{Redact, Pseudonymize, Keep} {
+		if s.String() == name {
+			return s, nil
+		}
+	}
+	return 0, fmt.Errorf("pii: unknown strategy %#v", name)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s Strategy) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Entity is a detected entity along with its replacement.
+type Entity struct {
+	annotators.Annotation
+	// Replacement is the text replacing the entity in the anonymized text.
+	Replacement string `json:"replacement"`
+	// Strategy is the strategy applied to the entity.
+	Strategy Strategy `json:"strategy"`
+}
+
+// Result is the result of an Anonymizer.
+type Result struct {
+	// Text is the anonymized text.
+	Text string `json:"text"`
+	// Entities contains the detected entities, sorted by offsets. Their Text
+	// is only set when the Anonymizer is Reversible.
+	Entities []Entity `json:"entities"`
+	// Mapping maps the pseudonyms to the original texts. It is only set when
+	// the Anonymizer is Reversible.
+	Mapping map[string]string `json:"mapping,omitempty"`
+}
+
+// Anonymizer replaces the personal data found by an annotator.
+// It is safe for concurrent use if the annotator is.
+type Anonymizer struct {
+	detector annotators.Annotator
+	// Strategies sets the strategy of specific labels.
+	Strategies map[string]Strategy
+	// DefaultStrategy is the strategy of the labels without a specific one.
+	DefaultStrategy Strategy
+	// Reversible reports whether the results include the original texts of
+	// the entities and the mapping to restore the pseudonyms. Since they
+	// contain the personal data, it should be enabled only where the results
+	// are handled as such.
+	Reversible bool
+}
+
+// New returns a new Anonymizer of the entities found by the detector, which
+// redacts all of them by default.
+func New(detector annotators.Annotator) *Anonymizer {
+	return &Anonymizer{
+		detector:        detector,
+		Strategies:      make(map[string]Strategy),
+		DefaultStrategy: Redact,
+	}
+}
+
+// Strategy returns the strategy of the label.
+func (a *Anonymizer) Strategy(label string) Strategy {
+	if s, ok := a.Strategies[label]; ok {
+		return s
+	}
+	return a.DefaultStrategy
+}
+
+// Anonymize detects the entities of the text and replaces them. Where the
+// entities overlap, the first (and then the longest) one is used.
+func (a *Anonymizer) Anonymize(text string) (*Result, error) {
+	annotations, err := a.detector.Annotate(text)
+	if err != nil {
+		return nil, fmt.Errorf("pii: %w", err)
+	}
+	sort.SliceStable(annotations, func(i, j int) bool {
+		if annotations[i].Start != annotations[j].Start {
+			return annotations[i].Start < annotations[j].Start
+		}
+		return annotations[i].End > annotations[j].End
+	})
+
+	runes := []rune(text)
+	result := &Result{Entities: make([]Entity, 0, len(annotations))}
+	if a.Reversible {
+		result.Mapping = make(map[string]string)
+	}
+	pseudonyms := make(map[string]string) // by label and original text
+	counts := make(map[string]int)        // by label
+	var sb strings.Builder
+	last := 0
+	for _, ann := range annotations {
+		if ann.Start < last {
+			continue // overlapping
+		}
+		original := string(runes[ann.Start:ann.End])
+		entity := Entity{Annotation: ann, Strategy: a.Strategy(ann.Label)}
+		switch entity.Strategy {
+		case Redact:
+			entity.Replacement = fmt.Sprintf("[%s]", ann.Label)
+		case Pseudonymize:
+			key := ann.Label + "\x00" + original
+			pseudonym, ok := pseudonyms[key]
+			if !ok {
+				counts[ann.Label]++
+				pseudonym = fmt.Sprintf("<%s_%d>", ann.Label, counts[ann.Label])
+				pseudonyms[key] = pseudonym
+			}
+			entity.Replacement = pseudonym
+			if a.Reversible {
+				result.Mapping[pseudonym] = original
+			}
+		default:
+			entity.Replacement = original
+		}
+		if !a.Reversible {
+			entity.Text = ""
+		}
+		sb.WriteString(string(runes[last:ann.Start]))
+		sb.WriteString(entity.Replacement)
+		last = ann.End
+		result.Entities = append(result.Entities, entity)
+	}
+	sb.WriteString(string(runes[last:]))
+	result.Text = sb.String()
+	return result, nil
+}
+
+// Deanonymize restores the pseudonyms of an anonymized text (or of a text
+// derived from it, e.g. a translation) with the original texts of the mapping.
+func Deanonymize(text string, mapping map[string]string) string {
+	pairs := make([]string, 0, 2*len(mapping))
+	for pseudonym, original := range mapping {
+		pairs = append(pairs, pseudonym, original)
+	}
+	return strings.NewReplacer(pairs...).Replace(text)
+}
@@ -0,0 +1,100 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pii
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/nlpodyssey/spago/pkg/nlp/annotators"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeNER labels the occurrences of "Anna" and "Mario Rossi" as PER.
+var fakeNER = annotators.AnnotatorFunc{
+	AnnotatorName: "ner",
+	Func: func(text string) ([]annotators.Annotation, error) {
+		var annotations []annotators.Annotation
+		for _, name := range []string{"Anna", "Mario Rossi"} {
+			offset := 0
+			for {
+				i := strings.Index(text[offset:], name)
+				if i < 0 {
+					break
+				}
+				start := len([]rune(text[:offset+i]))
+				annotations = append(annotations, annotators.Annotation{
+					Start: start, End: start + len([]rune(name)), Text: name, Label: "PER", Confidence: 0.9,
+				})
+				offset += i + len(name)
+			}
+		}
+		return annotations, nil
+	},
+}
+
+const testText = "Anna wrote to Mario Rossi (mario@rossi.it): Anna's number is 333 123 4567."
+
+func TestAnonymizer_Redact(t *testing.T) {
+	result, err := New(NewDetector(fakeNER)).Anonymize(testText)
+	require.NoError(t, err)
+	assert.Equal(t, "[PER] wrote to [PER] ([EMAIL]): [PER]'s number is [PHONE].", result.Text)
+	assert.Nil(t, result.Mapping)
+	require.Len(t, result.Entities, 5)
+	for _, e := range result.Entities {
+		assert.Equal(t, "", e.Text, "the personal data must not be returned")
+		assert.Equal(t, Redact, e.Strategy)
+	}
+}
+
+func TestAnonymizer_Pseudonymize(t *testing.T) {
+	a := New(NewDetector(fakeNER))
+	a.DefaultStrategy = Pseudonymize
+	a.Strategies["PHONE"] = Redact
+	a.Reversible = true
+
+	result, err := a.Anonymize(testText)
+	require.NoError(t, err)
+	assert.Equal(t, "<PER_1> wrote to <PER_2> (<EMAIL_1>): <PER_1>'s number is [PHONE].", result.Text)
+	assert.Equal(t, map[string]string{
+		"<PER_1>":   "Anna",
+		"<PER_2>":   "Mario Rossi",
+		"<EMAIL_1>": "mario@rossi.it",
+	}, result.Mapping)
+	assert.Equal(t, "mario@rossi.it", result.Entities[2].Text)
+	assert.Equal(t, 27, result.Entities[2].Start)
+
+	assert.Equal(t, "Anna wrote to Mario Rossi (mario@rossi.it): Anna's number is [PHONE].",
+		Deanonymize(result.Text, result.Mapping))
+}
+
+func TestAnonymizer_Keep(t *testing.T) {
+	a := New(NewDetector(fakeNER))
+	a.Strategies["PER"] = Keep
+	result, err := a.Anonymize(testText)
+	require.NoError(t, err)
+	assert.Equal(t, "Anna wrote to Mario Rossi ([EMAIL]): Anna's number is [PHONE].", result.Text)
+}
+
+func TestAnonymizer_Error(t *testing.T) {
+	failing := annotators.AnnotatorFunc{
+		AnnotatorName: "failing",
+		Func:          func(string) ([]annotators.Annotation, error) { return nil, errors.New("boom") },
+	}
+	_, err := New(failing).Anonymize(testText)
+	assert.EqualError(t, err, "pii: boom")
+}
+
+func TestParseStrategy(t *testing.T) {
+	for _, s := range []Strategy{Redact, Pseudonymize, Keep} {
+		parsed, err := ParseStrategy(s.String())
+		require.NoError(t, err)
+		assert.Equal(t, s, parsed)
+	}
+	_, err := ParseStrategy("encrypt")
+	assert.Error(t, err)
+}
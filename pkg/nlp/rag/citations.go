@@ -0,0 +1,170 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rag
+
+import (
+	"regexp"
+	"strconv"
+	"unicode"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/nlp/document"
+	"github.com/nlpodyssey/spago/pkg/nlp/retrieval"
+	"github.com/nlpodyssey/spago/pkg/nlp/tokenizers"
+)
+
+// Citation is a span of an answer attributed to a passage.
+type Citation struct {
+	// Span is the span of the answer.
+	document.Span
+	// Passage is the index of the passage in the retrieved ones.
+	Passage int `json:"passage"`
+	// Source is the span of the longest sequence of terms of the answer span
+	// occurring in the passage; it is empty if they have no terms in common.
+	Source document.Span `json:"source"`
+	// Score is the fraction of the terms of the span occurring in the
+	// passage, or 1 if the passage is explicitly cited by a marker.
+	Score mat.Float `json:"score"`
+	// Explicit reports whether the span contains a marker citing the passage
+	// by number (e.g. "[2]", see DefaultTemplate).
+	Explicit bool `json:"explicit"`
+}
+
+var citationMarker = regexp.MustCompile(`\[(\d+)\]`)
+
+// Attribute splits the text in sentences, and attributes each one to the
+// passages it cites explicitly with a marker "[n]", if any, or otherwise to
+// the passage containing the highest fraction of its terms, provided that it
+// is at least minScore.
+func Attribute(text string, passages []retrieval.Result, minScore mat.Float) []Citation {
+	citations := make([]Citation, 0)
+	if len(passages) == 0 {
+		return citations
+	}
+	passageTerms := make([][]tokenizers.StringOffsetsPair, len(passages))
+	for i, p := range passages {
+		passageTerms[i] = retrieval.TermTokens(p.Text)
+	}
+
+	runes := []rune(text)
+	for _, span := range sentences(runes) {
+		spanText := string(runes[span.Start:span.End])
+		spanTerms := retrieval.TermTokens(citationMarker.ReplaceAllString(spanText, ""))
+
+		explicit := false
+		for _, m := range citationMarker.FindAllStringSubmatch(spanText, -1) {
+			n, err := strconv.Atoi(m[1])
+			if err != nil || n < 1 || n > len(passages) {
+				continue
+			}
+			explicit = true
+			start, end := longestCommonRun(spanTerms, passageTerms[n-1])
+			citations = append(citations, Citation{
+				Span:     document.Span{Start: span.Start, End: span.End},
+				Passage:  n - 1,
+				Source:   document.Span{Start: start, End: end},
+				Score:    1,
+				Explicit: true,
+			})
+		}
+		if explicit || len(spanTerms) == 0 {
+			continue
+		}
+
+		best, bestScore := -1, mat.Float(0)
+		for i, terms := range passageTerms {
+			if score := overlap(spanTerms, terms); score > bestScore {
+				best, bestScore = i, score
+			}
+		}
+		if best == -1 || bestScore < minScore {
+			continue
+		}
+		start, end := longestCommonRun(spanTerms, passageTerms[best])
+		citations = append(citations, Citation{
+			Span:    document.Span{Start: span.Start, End: span.End},
+			Passage: best,
+			Source:  document.Span{Start: start, End: end},
+			Score:   bestScore,
+		})
+	}
+	return citations
+}
+
+// sentences returns the offsets of the sentences of the text, ending with
+// '.', '!' or '?' followed by a space, or with a line break, without the
+// surrounding spaces.
+func sentences(text []rune) []tokenizers.OffsetsType {
+	spans := make([]tokenizers.OffsetsType, 0)
+	add := func(start, end int) {
+		for start < end && unicode.IsSpace(text[start]) {
+			start++
+		}
+		for end > start && unicode.IsSpace(text[end-1]) {
+			end--
+		}
+		if start < end {
+			spans = append(spans, tokenizers.OffsetsType{Start: start, End: end})
+		}
+	}
+	start := 0
+	for i, r := range text {
+		switch {
+		case r == '\n':
+			add(start, i)
+			start = i + 1
+		case (r == '.' || r == '!' || r == '?') && (i+1 == len(text) || unicode.IsSpace(text[i+1])):
+			add(start, i+1)
+			start = i + 1
+		}
+	}
+	add(start, len(text))
+	return spans
+}
+
+// overlap returns the fraction of the distinct terms of the span occurring in
+// the passage.
+func overlap(span, passage []tokenizers.StringOffsetsPair) mat.Float {
+	inPassage := make(map[string]bool, len(passage))
+	for _, t := range passage {
+		inPassage[t.String] = true
+	}
+	distinct := make(map[string]bool, len(span))
+	found := 0
+	for _, t := range span {
+		if distinct[t.String] {
+			continue
+		}
+		distinct[t.String] = true
+		if inPassage[t.String] {
+			found++
+		}
+	}
+	return mat.Float(found) / mat.Float(len(distinct))
+}
+
+// longestCommonRun returns the offsets in the passage of the longest
+// sequence of consecutive terms occurring in the span too.
+func longestCommonRun(span, passage []tokenizers.StringOffsetsPair) (start, end int) {
+	// lengths[j] is the length of the common run ending at passage[j-1]
+	lengths := make([]int, len(passage)+1)
+	bestLen, bestEnd := 0, 0
+	for i := range span {
+		for j := len(passage); j >= 1; j-- {
+			if span[i].String != passage[j-1].String {
+				lengths[j] = 0
+				continue
+			}
+			lengths[j] = lengths[j-1] + 1
+			if lengths[j] > bestLen {
+				bestLen, bestEnd = lengths[j], j
+			}
+		}
+	}
+	if bestLen == 0 {
+		return 0, 0
+	}
+	return passage[bestEnd-bestLen].Offsets.Start, passage[bestEnd-1].Offsets.End
+}
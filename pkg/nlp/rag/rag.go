@@ -0,0 +1,160 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package rag implements the retrieval-augmented generation: the passages
+// relevant to a query are retrieved, rendered along with the query into the
+// prompt of a generator (e.g. a BART seq2seq model), and the spans of the
+// generated answer are attributed to the passages they come from, as
+// document.Span values.
+package rag
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/nlp/retrieval"
+)
+
+// Generator is implemented by any value that generates a text from an input
+// text, such as the seq2seq task of BART.
+type Generator interface {
+	Generate(text string) (string, error)
+}
+
+// GeneratorFunc is an adapter to use an ordinary function as Generator.
+type GeneratorFunc func(text string) (string, error)
+
+// Generate calls f(text).
+func (f GeneratorFunc) Generate(text string) (string, error) {
+	return f(text)
+}
+
+// DefaultTemplate is the default template of the prompt, following the
+// "question: ... context: ..." convention of the generative QA models, with
+// the passages numbered from 1 so that the model can cite them.
+const DefaultTemplate = "question: {{.Query}} context:{{range .Passages}} [{{.Number}}] {{.Text}}{{end}}"
+
+// PromptData is the data the template of the prompt is executed with.
+type PromptData struct {
+	// Query is the query.
+	Query string
+	// Passages contains the retrieved passages.
+	Passages []PromptPassage
+}
+
+// PromptPassage is a passage of the PromptData.
+type PromptPassage struct {
+	// Number is the position of the passage, starting from 1.
+	Number int
+	// ID is the ID of the passage.
+	ID string
+	// Text is the text of the passage.
+	Text string
+}
+
+// Config provides configuration settings for a Pipeline.
+type Config struct {
+	// TopK is the default number of passages to retrieve.
+	TopK int
+	// Template is the text/template of the prompt, executed with PromptData.
+	Template string
+	// MinCitationScore is the minimum fraction of the terms of a span of the
+	// answer that must occur in a passage to attribute the span to it.
+	MinCitationScore mat.Float
+}
+
+// DefaultConfig returns a Config with the default settings.
+func DefaultConfig() Config {
+	return Config{
+		TopK:             3,
+		Template:         DefaultTemplate,
+		MinCitationScore: 0.5,
+	}
+}
+
+// Pipeline is a retrieval-augmented generation pipeline.
+// It is safe for concurrent use if the Retriever and the Generator are.
+type Pipeline struct {
+	retriever retrieval.Retriever
+	generator Generator
+	config    Config
+	template  *template.Template
+}
+
+// New returns a new Pipeline, or an error if the template is invalid.
+func New(retriever retrieval.Retriever, generator Generator, config Config) (*Pipeline, error) {
+	if config.TopK <= 0 {
+		return nil, fmt.Errorf("rag: invalid number of passages %d", config.TopK)
+	}
+	t, err := template.New("prompt").Parse(config.Template)
+	if err != nil {
+		return nil, fmt.Errorf("rag: invalid template: %w", err)
+	}
+	return &Pipeline{
+		retriever: retriever,
+		generator: generator,
+		config:    config,
+		template:  t,
+	}, nil
+}
+
+// Answer is the result of a Pipeline.
+type Answer struct {
+	// Query is the query.
+	Query string `json:"query"`
+	// Text is the generated answer.
+	Text string `json:"text"`
+	// Prompt is the input of the generator.
+	Prompt string `json:"prompt"`
+	// Passages contains the retrieved passages.
+	Passages []retrieval.Result `json:"passages"`
+	// Citations contains the spans of Text attributed to the Passages.
+	Citations []Citation `json:"citations"`
+}
+
+// Answer retrieves the k passages most relevant to the query, or the
+// configured number if k is not positive, and generates the answer from the
+// prompt built with them.
+func (p *Pipeline) Answer(query string, k int) (*Answer, error) {
+	if k <= 0 {
+		k = p.config.TopK
+	}
+	passages, err := p.retriever.Retrieve(query, k)
+	if err != nil {
+		return nil, err
+	}
+	prompt, err := p.Prompt(query, passages)
+	if err != nil {
+		return nil, err
+	}
+	text, err := p.generator.Generate(prompt)
+	if err != nil {
+		return nil, err
+	}
+	return &Answer{
+		Query:     query,
+		Text:      text,
+		Prompt:    prompt,
+		Passages:  passages,
+		Citations: Attribute(text, passages, p.config.MinCitationScore),
+	}, nil
+}
+
+// Prompt returns the prompt for the query and the passages.
+func (p *Pipeline) Prompt(query string, passages []retrieval.Result) (string, error) {
+	data := PromptData{
+		Query:    query,
+		Passages: make([]PromptPassage, len(passages)),
+	}
+	for i, passage := range passages {
+		data.Passages[i] = PromptPassage{Number: i + 1, ID: passage.ID, Text: passage.Text}
+	}
+	var sb strings.Builder
+	if err := p.template.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("rag: %w", err)
+	}
+	return sb.String(), nil
+}
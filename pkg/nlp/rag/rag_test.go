@@ -0,0 +1,92 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rag
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nlpodyssey/spago/pkg/nlp/document"
+	"github.com/nlpodyssey/spago/pkg/nlp/retrieval"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var passages = []retrieval.Passage{
+	{ID: "eiffel", Text: "The Eiffel Tower was completed in 1889 in Paris."},
+	{ID: "rome", Text: "Rome is the capital of Italy."},
+	{ID: "paris", Text: "Paris is the capital of France."},
+}
+
+func TestPipeline_Answer(t *testing.T) {
+	index := retrieval.NewBM25Index(passages, retrieval.DefaultBM25Config())
+	var prompt string
+	generator := GeneratorFunc(func(text string) (string, error) {
+		prompt = text
+		return "Paris is the capital of France. It was completed in 1889 [2].", nil
+	})
+	p, err := New(index, generator, DefaultConfig())
+	require.NoError(t, err)
+
+	answer, err := p.Answer("When was the tower of Paris completed?", 2)
+	require.NoError(t, err)
+	assert.Equal(t, prompt, answer.Prompt)
+	require.Len(t, answer.Passages, 2)
+	assert.Equal(t, "eiffel", answer.Passages[0].ID)
+	assert.Equal(t, "paris", answer.Passages[1].ID)
+	assert.Equal(t, "question: When was the tower of Paris completed? context:"+
+		" [1] The Eiffel Tower was completed in 1889 in Paris."+
+		" [2] Paris is the capital of France.", prompt)
+
+	require.Len(t, answer.Citations, 2)
+	assert.Equal(t, Citation{
+		Span: document.Span{Start: 0, End: 31}, Passage: 1, Source: document.Span{Start: 0, End: 30}, Score: 1,
+	}, answer.Citations[0])
+	assert.Equal(t, Citation{
+		Span: document.Span{Start: 32, End: 61}, Passage: 1, Source: document.Span{}, Score: 1, Explicit: true,
+	}, answer.Citations[1])
+
+	answer, err = p.Answer("Rome", 0)
+	require.NoError(t, err)
+	assert.Len(t, answer.Passages, 1)
+}
+
+func TestPipeline_Errors(t *testing.T) {
+	index := retrieval.NewBM25Index(passages, retrieval.DefaultBM25Config())
+	failing := GeneratorFunc(func(string) (string, error) {
+		return "", errors.New("boom")
+	})
+
+	config := DefaultConfig()
+	config.Template = "{{.Query"
+	_, err := New(index, failing, config)
+	assert.Error(t, err)
+
+	p, err := New(index, failing, DefaultConfig())
+	require.NoError(t, err)
+	_, err = p.Answer("Rome", 1)
+	assert.EqualError(t, err, "boom")
+}
+
+func TestAttribute(t *testing.T) {
+	results := []retrieval.Result{
+		{Passage: passages[0]},
+		{Passage: passages[1]},
+	}
+	text := "The tower was completed in 1889!\nBananas are yellow. Rome is the capital [9]."
+	citations := Attribute(text, results, 0.5)
+	require.Len(t, citations, 2)
+	assert.Equal(t, 0, citations[0].Passage)
+	assert.Equal(t, "The tower was completed in 1889!", string([]rune(text)[citations[0].Start:citations[0].End]))
+	assert.Equal(t, "Tower was completed in 1889", passages[0].Text[citations[0].Source.Start:citations[0].Source.End])
+	assert.InDelta(t, 1.0, citations[0].Score, 1.0e-6)
+
+	// the marker [9] is not valid, so the sentence is attributed by overlap
+	assert.Equal(t, 1, citations[1].Passage)
+	assert.False(t, citations[1].Explicit)
+	assert.Equal(t, "Rome is the capital", passages[1].Text[citations[1].Source.Start:citations[1].Source.End])
+
+	assert.Empty(t, Attribute(text, nil, 0.5))
+}
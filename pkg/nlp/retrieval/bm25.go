@@ -0,0 +1,124 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package retrieval
+
+import (
+	"fmt"
+	"sort"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+)
+
+var _ Retriever = &BM25Index{}
+
+// BM25Config provides configuration settings for a BM25Index.
+type BM25Config struct {
+	// K1 controls the saturation of the term frequencies.
+	K1 mat.Float
+	// B controls the normalization by the length of the passages, from 0
+	// (none) to 1 (full).
+	B mat.Float
+}
+
+// DefaultBM25Config returns the usual BM25 settings.
+func DefaultBM25Config() BM25Config {
+	return BM25Config{K1: 1.2, B: 0.75}
+}
+
+// BM25Index is an in-memory inverted index ranking the passages with the
+// Okapi BM25 function of the terms of the query (see Terms).
+// It is safe for concurrent use.
+type BM25Index struct {
+	config    BM25Config
+	passages  []Passage
+	lengths   []int
+	avgLength mat.Float
+	postings  map[string][]posting
+}
+
+type posting struct {
+	passage   int
+	frequency int
+}
+
+// NewBM25Index returns a new BM25Index of the passages.
+func NewBM25Index(passages []Passage, config BM25Config) *BM25Index {
+	index := &BM25Index{
+		config:   config,
+		passages: passages,
+		lengths:  make([]int, len(passages)),
+		postings: make(map[string][]posting),
+	}
+	var total int
+	for i, p := range passages {
+		terms := Terms(p.Text)
+		index.lengths[i] = len(terms)
+		total += len(terms)
+		frequencies := make(map[string]int)
+		for _, term := range terms {
+			frequencies[term]++
+		}
+		for term, frequency := range frequencies {
+			index.postings[term] = append(index.postings[term], posting{passage: i, frequency: frequency})
+		}
+	}
+	if len(passages) > 0 {
+		index.avgLength = mat.Float(total) / mat.Float(len(passages))
+	}
+	return index
+}
+
+// Len returns the number of passages.
+func (idx *BM25Index) Len() int {
+	return len(idx.passages)
+}
+
+// Retrieve returns the k passages with the highest score, sorted by
+// decreasing score. The passages not containing any term of the query are
+// never returned.
+func (idx *BM25Index) Retrieve(query string, k int) ([]Result, error) {
+	if k <= 0 {
+		return nil, fmt.Errorf("retrieval: invalid number of passages %d", k)
+	}
+	n := mat.Float(len(idx.passages))
+	scores := make(map[int]mat.Float)
+	seen := make(map[string]bool)
+	for _, term := range Terms(query) {
+		if seen[term] {
+			continue
+		}
+		seen[term] = true
+		postings := idx.postings[term]
+		if len(postings) == 0 {
+			continue
+		}
+		df := mat.Float(len(postings))
+		idf := mat.Log(1 + (n-df+0.5)/(df+0.5))
+		for _, p := range postings {
+			tf := mat.Float(p.frequency)
+			norm := idx.config.K1 * (1 - idx.config.B + idx.config.B*mat.Float(idx.lengths[p.passage])/idx.avgLength)
+			scores[p.passage] += idf * tf * (idx.config.K1 + 1) / (tf + norm)
+		}
+	}
+
+	ranked := make([]int, 0, len(scores))
+	for i := range scores {
+		ranked = append(ranked, i)
+	}
+	sort.Slice(ranked, func(a, b int) bool {
+		if scores[ranked[a]] != scores[ranked[b]] {
+			return scores[ranked[a]] > scores[ranked[b]]
+		}
+		return ranked[a] < ranked[b] // the order of the passages breaks the ties
+	})
+	if len(ranked) > k {
+		ranked = ranked[:k]
+	}
+	results := make([]Result, len(ranked))
+	for j, i := range ranked {
+		results[j] = Result{Passage: idx.passages[i], Score: scores[i]}
+	}
+	return results, nil
+}
@@ -0,0 +1,43 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package retrieval
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var passages = []Passage{
+	{ID: "a", Text: "The Eiffel Tower is in Paris."},
+	{ID: "b", Text: "Paris is the capital of France, and Paris is large."},
+	{ID: "c", Text: "Rome is the capital of Italy."},
+	{ID: "d", Text: "Bananas are yellow."},
+}
+
+func TestBM25Index_Retrieve(t *testing.T) {
+	index := NewBM25Index(passages, DefaultBM25Config())
+	assert.Equal(t, 4, index.Len())
+
+	results, err := index.Retrieve("capital of France?", 2)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "b", results[0].ID)
+	assert.Equal(t, "c", results[1].ID)
+	assert.Greater(t, results[0].Score, results[1].Score)
+
+	results, err = index.Retrieve("PARIS tower", 10)
+	require.NoError(t, err)
+	require.Len(t, results, 2) // only the passages with a term of the query
+	assert.Equal(t, "a", results[0].ID)
+
+	results, err = index.Retrieve("kiwi", 3)
+	require.NoError(t, err)
+	assert.Empty(t, results)
+
+	_, err = index.Retrieve("paris", 0)
+	assert.Error(t, err)
+}
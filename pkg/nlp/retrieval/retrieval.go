@@ -0,0 +1,90 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package retrieval provides the retrieval of the passages of a collection
+// relevant to a query, e.g. to ground the generation of an answer on them
+// (see the rag package).
+package retrieval
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/nlp/tokenizers"
+	"github.com/nlpodyssey/spago/pkg/nlp/tokenizers/basetokenizer"
+)
+
+// Passage is a unit of text that can be retrieved.
+type Passage struct {
+	// ID identifies the passage in the collection.
+	ID string `json:"id"`
+	// Text is the text of the passage.
+	Text string `json:"text"`
+}
+
+// Result is a passage retrieved for a query.
+type Result struct {
+	Passage
+	// Score is the relevance of the passage to the query; it can be compared
+	// only with the scores of the same Retriever.
+	Score mat.Float `json:"score"`
+}
+
+// Retriever is implemented by any value that retrieves passages.
+type Retriever interface {
+	// Retrieve returns the k passages most relevant to the query, sorted by
+	// decreasing score.
+	Retrieve(query string, k int) ([]Result, error)
+}
+
+// LoadPassages reads the passages from r, one per line, skipping the empty
+// lines. The ID of each passage is its line number.
+func LoadPassages(r io.Reader) ([]Passage, error) {
+	passages := make([]Passage, 0)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		passages = append(passages, Passage{ID: strconv.Itoa(lineNumber), Text: line})
+	}
+	return passages, scanner.Err()
+}
+
+var termTokenizer = basetokenizer.New()
+
+// Terms returns the lowercase words and numbers of the text, ignoring the
+// punctuation, as used for the lexical matching.
+func Terms(text string) []string {
+	return tokenizers.GetStrings(TermTokens(text))
+}
+
+// TermTokens is like Terms, but it returns the terms along with their
+// offsets in the text.
+func TermTokens(text string) []tokenizers.StringOffsetsPair {
+	tokens := termTokenizer.Tokenize(text)
+	terms := make([]tokenizers.StringOffsetsPair, 0, len(tokens))
+	for _, token := range tokens {
+		if isTerm(token.String) {
+			token.String = strings.ToLower(token.String)
+			terms = append(terms, token)
+		}
+	}
+	return terms
+}
+
+func isTerm(token string) bool {
+	for _, r := range token {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,26 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package retrieval
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadPassages(t *testing.T) {
+	passages, err := LoadPassages(strings.NewReader("first passage\n\n  second passage  \n"))
+	require.NoError(t, err)
+	assert.Equal(t, []Passage{
+		{ID: "1", Text: "first passage"},
+		{ID: "3", Text: "second passage"},
+	}, passages)
+}
+
+func TestTerms(t *testing.T) {
+	assert.Equal(t, []string{"the", "eiffel", "tower", "1889"}, Terms("The Eiffel Tower (1889)!"))
+}
@@ -0,0 +1,295 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package safety
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/nlp/document"
+	"github.com/nlpodyssey/spago/pkg/nlp/tokenizers"
+	"github.com/nlpodyssey/spago/pkg/nlp/tokenizers/basetokenizer"
+)
+
+var (
+	_ Filter = &Blocklist{}
+	_ Filter = &PatternFilter{}
+	_ Filter = &LexiconClassifier{}
+)
+
+var wordTokenizer = basetokenizer.New()
+
+// words returns the lowercase tokens of the text.
+func words(text string) []tokenizers.StringOffsetsPair {
+	tokens := wordTokenizer.Tokenize(text)
+	for i := range tokens {
+		tokens[i].String = strings.ToLower(tokens[i].String)
+	}
+	return tokens
+}
+
+// Blocklist is a Filter finding the occurrences of a list of words and
+// phrases, matched case-insensitively on whole tokens.
+type Blocklist struct {
+	name    string
+	phrases map[string][][]string // by first word
+}
+
+// NewBlocklist returns a new Blocklist of the given words and phrases.
+func NewBlocklist(name string, phrases []string) *Blocklist {
+	b := &Blocklist{name: name, phrases: make(map[string][][]string)}
+	for _, phrase := range phrases {
+		ws := tokenizers.GetStrings(words(phrase))
+		if len(ws) > 0 {
+			b.phrases[ws[0]] = append(b.phrases[ws[0]], ws)
+		}
+	}
+	return b
+}
+
+// LoadBlocklist returns a new Blocklist of the phrases read from r, one per
+// line, skipping the empty lines and the ones starting with '#'.
+func LoadBlocklist(name string, r io.Reader) (*Blocklist, error) {
+	phrases := make([]string, 0)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		phrases = append(phrases, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return NewBlocklist(name, phrases), nil
+}
+
+// Name returns the name of the filter.
+func (b *Blocklist) Name() string {
+	return b.name
+}
+
+// Check returns the occurrences of the phrases, with category "blocklist".
+// Where several phrases match at the same position, the longest one is used.
+func (b *Blocklist) Check(text string) ([]Finding, error) {
+	tokens := words(text)
+	findings := make([]Finding, 0)
+	for i := 0; i < len(tokens); i++ {
+		longest := 0
+		for _, phrase := range b.phrases[tokens[i].String] {
+			if len(phrase) > longest && matchesAt(tokens, i, phrase) {
+				longest = len(phrase)
+			}
+		}
+		if longest == 0 {
+			continue
+		}
+		findings = append(findings, Finding{
+			Filter:   b.name,
+			Category: "blocklist",
+			Span:     document.Span{Start: tokens[i].Offsets.Start, End: tokens[i+longest-1].Offsets.End},
+			Score:    1,
+		})
+		i += longest - 1
+	}
+	return findings, nil
+}
+
+func matchesAt(tokens []tokenizers.StringOffsetsPair, i int, phrase []string) bool {
+	if i+len(phrase) > len(tokens) {
+		return false
+	}
+	for j, w := range phrase {
+		if tokens[i+j].String != w {
+			return false
+		}
+	}
+	return true
+}
+
+// Pattern is a regular expression finding a category of unsafe spans.
+type Pattern struct {
+	// Category is the category of the findings.
+	Category string
+	// Regexp is the regular expression.
+	Regexp *regexp.Regexp
+	// Validate, if not nil, discards the matches for which it returns false.
+	Validate func(match string) bool
+}
+
+// PIIPatterns returns the patterns of the most common personal data: email
+// addresses, phone numbers, payment card numbers (verified with the Luhn
+// checksum) and IPv4 addresses.
+func PIIPatterns() []Pattern {
+	return []Pattern{
+		{Category: "email", Regexp: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)},
+		{Category: "card", Regexp: regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`), Validate: luhn},
+		{Category: "phone", Regexp: regexp.MustCompile(`(?:\+\d{1,3}[ .-]?)?(?:\(\d{2,4}\)[ .-]?)?\d{2,4}[ .-]\d{3,4}[ .-]?\d{3,4}\b`)},
+		{Category: "ip", Regexp: regexp.MustCompile(`\b(?:(?:25[0-5]|2[0-4]\d|1?\d?\d)\.){3}(?:25[0-5]|2[0-4]\d|1?\d?\d)\b`)},
+	}
+}
+
+// PatternFilter is a Filter finding the matches of regular expressions.
+// The matches of a pattern overlapping the ones of a previous pattern are
+// discarded.
+type PatternFilter struct {
+	name     string
+	patterns []Pattern
+}
+
+// NewPatternFilter returns a new PatternFilter of the patterns.
+func NewPatternFilter(name string, patterns []Pattern) *PatternFilter {
+	return &PatternFilter{name: name, patterns: patterns}
+}
+
+// Name returns the name of the filter.
+func (p *PatternFilter) Name() string {
+	return p.name
+}
+
+// Check returns the matches of the patterns.
+func (p *PatternFilter) Check(text string) ([]Finding, error) {
+	findings := make([]Finding, 0)
+	taken := make([]bool, len(text)) // the bytes already matched
+	for _, pattern := range p.patterns {
+	matches:
+		for _, m := range pattern.Regexp.FindAllStringIndex(text, -1) {
+			if pattern.Validate != nil && !pattern.Validate(text[m[0]:m[1]]) {
+				continue
+			}
+			for i := m[0]; i < m[1]; i++ {
+				if taken[i] {
+					continue matches
+				}
+			}
+			for i := m[0]; i < m[1]; i++ {
+				taken[i] = true
+			}
+			findings = append(findings, Finding{
+				Filter:   p.name,
+				Category: pattern.Category,
+				Span:     document.Span{Start: utf8.RuneCountInString(text[:m[0]]), End: utf8.RuneCountInString(text[:m[1]])},
+				Score:    1,
+			})
+		}
+	}
+	return findings, nil
+}
+
+// luhn reports whether the digits of the string satisfy the Luhn checksum.
+func luhn(s string) bool {
+	var sum, n int
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if n%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return n > 0 && sum%10 == 0
+}
+
+// LexiconClassifier is a small logistic regression classifier of the
+// sentences of a text (e.g. toxic or not), whose features are the counts of
+// its words. A sentence is a finding when its probability reaches the
+// threshold; its span is the whole sentence.
+type LexiconClassifier struct {
+	name      string
+	category  string
+	weights   map[string]mat.Float
+	bias      mat.Float
+	threshold mat.Float
+}
+
+// NewLexiconClassifier returns a new LexiconClassifier with the given weights
+// of the (lowercase) words, bias and threshold.
+func NewLexiconClassifier(name, category string, weights map[string]mat.Float, bias, threshold mat.Float) *LexiconClassifier {
+	return &LexiconClassifier{
+		name:      name,
+		category:  category,
+		weights:   weights,
+		bias:      bias,
+		threshold: threshold,
+	}
+}
+
+// LoadLexiconWeights reads the weights of a LexiconClassifier from r: each
+// line contains a word and its weight separated by a tab character, and the
+// special word "<bias>" sets the bias. Empty lines are skipped.
+func LoadLexiconWeights(r io.Reader) (weights map[string]mat.Float, bias mat.Float, err error) {
+	weights = make(map[string]mat.Float)
+	scanner := bufio.NewScanner(r)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		if len(fields) != 2 {
+			return nil, 0, fmt.Errorf("safety: malformed line %d: %#v", lineNumber, line)
+		}
+		w, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			return nil, 0, fmt.Errorf("safety: line %d: %w", lineNumber, err)
+		}
+		if fields[0] == "<bias>" {
+			bias = mat.Float(w)
+			continue
+		}
+		weights[strings.ToLower(fields[0])] = mat.Float(w)
+	}
+	return weights, bias, scanner.Err()
+}
+
+// Name returns the name of the filter.
+func (c *LexiconClassifier) Name() string {
+	return c.name
+}
+
+// Check returns the sentences classified as unsafe.
+func (c *LexiconClassifier) Check(text string) ([]Finding, error) {
+	findings := make([]Finding, 0)
+	tokens := words(text)
+	start := 0
+	for i, token := range tokens {
+		if i < len(tokens)-1 && !isSentenceEnd(token.String) {
+			continue
+		}
+		sentence := tokens[start : i+1]
+		start = i + 1
+		z := c.bias
+		for _, t := range sentence {
+			z += c.weights[t.String]
+		}
+		if p := 1 / (1 + mat.Exp(-z)); p >= c.threshold {
+			findings = append(findings, Finding{
+				Filter:   c.name,
+				Category: c.category,
+				Span:     document.Span{Start: sentence[0].Offsets.Start, End: sentence[len(sentence)-1].Offsets.End},
+				Score:    p,
+			})
+		}
+	}
+	return findings, nil
+}
+
+func isSentenceEnd(token string) bool {
+	return token == "." || token == "!" || token == "?"
+}
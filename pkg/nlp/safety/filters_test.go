@@ -0,0 +1,75 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package safety
+
+import (
+	"strings"
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlocklist(t *testing.T) {
+	b, err := LoadBlocklist("words", strings.NewReader("# comment\nbad\nbad words\n\n"))
+	require.NoError(t, err)
+	assert.Equal(t, "words", b.Name())
+
+	findings, err := b.Check("No BAD words here, just bad. Badly done.")
+	require.NoError(t, err)
+	require.Len(t, findings, 2)
+	assert.Equal(t, 3, findings[0].Start)
+	assert.Equal(t, 12, findings[0].End) // the longest phrase
+	assert.Equal(t, 24, findings[1].Start)
+	assert.Equal(t, 27, findings[1].End)
+}
+
+func TestPatternFilter_PII(t *testing.T) {
+	f := NewPatternFilter("pii", PIIPatterns())
+	text := "Mail anna.b@mail.co.uk or call +39 333 123 4567. Card 4111 1111 1111 1111, not 4111111111111112. Server 192.168.1.10."
+	findings, err := f.Check(text)
+	require.NoError(t, err)
+
+	runes := []rune(text)
+	actual := make(map[string]string)
+	for _, finding := range findings {
+		actual[string(runes[finding.Start:finding.End])] = finding.Category
+	}
+	assert.Equal(t, map[string]string{
+		"anna.b@mail.co.uk":   "email",
+		"+39 333 123 4567":    "phone",
+		"4111 1111 1111 1111": "card",
+		"192.168.1.10":        "ip",
+	}, actual)
+}
+
+func TestPatternFilter_RuneOffsets(t *testing.T) {
+	f := NewPatternFilter("pii", PIIPatterns())
+	findings, err := f.Check("àè a@b.io")
+	require.NoError(t, err)
+	require.Len(t, findings, 1)
+	assert.Equal(t, 3, findings[0].Start)
+	assert.Equal(t, 9, findings[0].End)
+}
+
+func TestLexiconClassifier(t *testing.T) {
+	weights, bias, err := LoadLexiconWeights(strings.NewReader("<bias>\t-2\nidiot\t3\nStupid\t2.5\n"))
+	require.NoError(t, err)
+	assert.Equal(t, mat.Float(-2), bias)
+	assert.Equal(t, mat.Float(2.5), weights["stupid"])
+
+	c := NewLexiconClassifier("toxicity", "toxic", weights, bias, 0.5)
+	findings, err := c.Check("Hello there. You idiot! Have a nice day")
+	require.NoError(t, err)
+	require.Len(t, findings, 1)
+	assert.Equal(t, "toxic", findings[0].Category)
+	assert.Equal(t, 13, findings[0].Start)
+	assert.Equal(t, 23, findings[0].End)
+	assert.InDelta(t, 0.731, findings[0].Score, 1.0e-3)
+
+	_, _, err = LoadLexiconWeights(strings.NewReader("word"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,175 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package safety implements the content filters applied to the generated
+// texts before they are returned: each Filter finds the unsafe spans of a
+// text (e.g. blocklisted words, personal data, toxic content), and a Guard
+// applies the action configured for it, flagging the findings, redacting
+// their spans or rejecting the whole text.
+//
+// The spans of the findings are document.Span values.
+package safety
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/nlp/document"
+)
+
+// Action is the enumeration-like type used to distinguish what a Guard does
+// with the findings of a Filter.
+type Action int
+
+const (
+	// Flag reports the findings, leaving the text unchanged.
+	Flag Action = iota
+	// Redact replaces the spans of the findings.
+	Redact
+	// Reject rejects the whole text.
+	Reject
+)
+
+// String returns the name of the action.
+func (a Action) String() string {
+	switch a {
+	case Flag:
+		return "flag"
+	case Redact:
+		return "redact"
+	case Reject:
+		return "reject"
+	default:
+		return fmt.Sprintf("Action(%d)", int(a))
+	}
+}
+
+// ParseAction returns the Action with the given name.
+func ParseAction(name string) (Action, error) {
+	for _, a := range []Action{Flag, Redact, Reject} {
+		if a.String() == name {
+			return a, nil
+		}
+	}
+	return 0, fmt.Errorf("safety: unknown action %#v", name)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (a Action) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// Finding is an unsafe span of a text.
+type Finding struct {
+	// Filter is the name of the filter.
+	Filter string `json:"filter"`
+	// Category is the kind of the finding (e.g. "email").
+	Category string `json:"category"`
+	// Span is the unsafe span of the text.
+	document.Span
+	// Score is the confidence of the finding, in [0, 1].
+	Score mat.Float `json:"score"`
+	// Action is the action applied by the Guard.
+	Action Action `json:"action"`
+}
+
+// Filter is implemented by any value that finds the unsafe spans of a text.
+type Filter interface {
+	// Name returns the name of the filter.
+	Name() string
+	// Check returns the unsafe spans of the text.
+	Check(text string) ([]Finding, error)
+}
+
+// Rule associates a Filter with the Action of a Guard.
+type Rule struct {
+	Filter Filter
+	Action Action
+}
+
+// DefaultRedaction is the default replacement of the redacted spans.
+const DefaultRedaction = "[REDACTED]"
+
+// Guard is the filter stage of the generated texts.
+// It is safe for concurrent use if the filters are.
+type Guard struct {
+	rules []Rule
+	// Redaction is the text replacing the redacted spans.
+	Redaction string
+}
+
+// NewGuard returns a new Guard applying the rules.
+func NewGuard(rules ...Rule) *Guard {
+	return &Guard{rules: rules, Redaction: DefaultRedaction}
+}
+
+// Result is the result of a Guard.
+type Result struct {
+	// Text is the filtered text, or empty if the text is rejected.
+	Text string `json:"text"`
+	// Rejected reports whether a rule with the Reject action found something.
+	Rejected bool `json:"rejected"`
+	// Findings contains the findings of all the filters, sorted by offsets.
+	Findings []Finding `json:"findings"`
+}
+
+// Flagged reports whether there is any finding.
+func (r *Result) Flagged() bool {
+	return len(r.Findings) > 0
+}
+
+// Apply runs all the filters on the text and applies their actions.
+func (g *Guard) Apply(text string) (*Result, error) {
+	result := &Result{Text: text, Findings: make([]Finding, 0)}
+	for _, rule := range g.rules {
+		findings, err := rule.Filter.Check(text)
+		if err != nil {
+			return nil, fmt.Errorf("safety: filter %s: %w", rule.Filter.Name(), err)
+		}
+		for _, f := range findings {
+			f.Action = rule.Action
+			result.Findings = append(result.Findings, f)
+			if rule.Action == Reject {
+				result.Rejected = true
+			}
+		}
+	}
+	sort.SliceStable(result.Findings, func(i, j int) bool {
+		a, b := result.Findings[i], result.Findings[j]
+		if a.Start != b.Start {
+			return a.Start < b.Start
+		}
+		return a.End < b.End
+	})
+	if result.Rejected {
+		result.Text = ""
+	} else {
+		result.Text = g.redact(text, result.Findings)
+	}
+	return result, nil
+}
+
+// redact replaces the spans of the findings with the Redact action, merging
+// the overlapping ones. The findings must be sorted by offsets.
+func (g *Guard) redact(text string, findings []Finding) string {
+	runes := []rune(text)
+	var sb strings.Builder
+	last := 0 // the end of the last redacted span
+	for _, f := range findings {
+		if f.Action != Redact || f.End <= last {
+			continue
+		}
+		if f.Start > last {
+			sb.WriteString(string(runes[last:f.Start]))
+		}
+		if f.Start >= last {
+			sb.WriteString(g.Redaction)
+		}
+		last = f.End
+	}
+	sb.WriteString(string(runes[last:]))
+	return sb.String()
+}
@@ -0,0 +1,71 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package safety
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nlpodyssey/spago/pkg/nlp/document"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type failingFilter struct{}
+
+func (failingFilter) Name() string { return "failing" }
+
+func (failingFilter) Check(string) ([]Finding, error) { return nil, errors.New("boom") }
+
+func TestGuard_Apply(t *testing.T) {
+	text := "Write to john@example.com, you silly goose."
+	blocklist := NewBlocklist("words", []string{"silly goose"})
+	pii := NewPatternFilter("pii", PIIPatterns())
+
+	result, err := NewGuard(Rule{Filter: pii, Action: Redact}, Rule{Filter: blocklist, Action: Flag}).Apply(text)
+	require.NoError(t, err)
+	assert.Equal(t, "Write to [REDACTED], you silly goose.", result.Text)
+	assert.False(t, result.Rejected)
+	assert.True(t, result.Flagged())
+	assert.Equal(t, []Finding{
+		{Filter: "pii", Category: "email", Span: document.Span{Start: 9, End: 25}, Score: 1, Action: Redact},
+		{Filter: "words", Category: "blocklist", Span: document.Span{Start: 31, End: 42}, Score: 1, Action: Flag},
+	}, result.Findings)
+
+	result, err = NewGuard(Rule{Filter: pii, Action: Flag}, Rule{Filter: blocklist, Action: Reject}).Apply(text)
+	require.NoError(t, err)
+	assert.True(t, result.Rejected)
+	assert.Equal(t, "", result.Text)
+
+	result, err = NewGuard(Rule{Filter: pii, Action: Redact}).Apply("Nothing to see.")
+	require.NoError(t, err)
+	assert.False(t, result.Flagged())
+	assert.Equal(t, "Nothing to see.", result.Text)
+
+	_, err = NewGuard(Rule{Filter: failingFilter{}, Action: Flag}).Apply(text)
+	assert.EqualError(t, err, "safety: filter failing: boom")
+}
+
+func TestGuard_Redact_Overlapping(t *testing.T) {
+	g := NewGuard()
+	g.Redaction = "*"
+	actual := g.redact("aé bb cc dd", []Finding{
+		{Span: document.Span{Start: 0, End: 5}, Action: Redact},
+		{Span: document.Span{Start: 3, End: 8}, Action: Redact},
+		{Span: document.Span{Start: 4, End: 5}, Action: Redact},
+		{Span: document.Span{Start: 9, End: 11}, Action: Flag},
+	})
+	assert.Equal(t, "* dd", actual)
+}
+
+func TestParseAction(t *testing.T) {
+	for _, a := range []Action{Flag, Redact, Reject} {
+		parsed, err := ParseAction(a.String())
+		require.NoError(t, err)
+		assert.Equal(t, a, parsed)
+	}
+	_, err := ParseAction("ignore")
+	assert.Error(t, err)
+}
@@ -19,6 +19,7 @@ import (
 	"github.com/nlpodyssey/spago/pkg/ml/nn/recurrent/lstm"
 	"github.com/nlpodyssey/spago/pkg/nlp/charlm"
 	"github.com/nlpodyssey/spago/pkg/nlp/contextualstringembeddings"
+	"github.com/nlpodyssey/spago/pkg/nlp/document"
 	"github.com/nlpodyssey/spago/pkg/nlp/embeddings"
 	"github.com/nlpodyssey/spago/pkg/nlp/stackedembeddings"
 	"github.com/nlpodyssey/spago/pkg/nlp/tokenizers"
@@ -172,6 +173,29 @@ func (m *Model) Analyze(text string, mergeEntities bool, filterNotEntities bool)
 	}
 }
 
+// AnalyzeDocument returns a new document.Document with the tokens of the text,
+// labeled with the predicted tags, and the recognized entities.
+func (m *Model) AnalyzeDocument(text string) *document.Document {
+	tokens := m.Analyze(text, false, false).Tokens
+	doc := document.New(text)
+	doc.Tokens = make([]document.Token, len(tokens))
+	for i, t := range tokens {
+		doc.Tokens[i] = document.Token(t)
+	}
+	entities := m.filterNotEntities(m.mergeEntities(tokens))
+	doc.Entities = make([]document.Annotation, len(entities))
+	for i, e := range entities {
+		doc.Entities[i] = document.Annotation{
+			Start:      e.Start,
+			End:        e.End,
+			Text:       e.Text,
+			Label:      e.Label,
+			Confidence: 1,
+		}
+	}
+	return doc
+}
+
 // Forward performs the forward step for each input and returns the result.
 func (m *Model) Forward(tokens []tokenizers.StringOffsetsPair) []Token {
 	words := tokenizers.GetStrings(tokens)
@@ -0,0 +1,240 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package spellcheck provides a character-level sequence-to-sequence model
+// for spelling correction, together with a noisy channel that generates the
+// training examples by corrupting a corpus of correct texts.
+//
+// A bidirectional LSTM encodes the characters of the (possibly misspelled)
+// input. An LSTM decoder generates the corrected text one character at a
+// time, attending to the encoder states with a dot-product attention
+// (Luong et al., 2015).
+package spellcheck
+
+import (
+	"encoding/gob"
+	"runtime"
+	"strings"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/mat32/floatutils"
+	"github.com/nlpodyssey/spago/pkg/mat32/rand"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/nlpodyssey/spago/pkg/ml/initializers"
+	"github.com/nlpodyssey/spago/pkg/ml/losses"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/ml/nn/birnn"
+	"github.com/nlpodyssey/spago/pkg/ml/nn/linear"
+	"github.com/nlpodyssey/spago/pkg/ml/nn/recurrent/lstm"
+	"github.com/nlpodyssey/spago/pkg/nlp/vocabulary"
+	"github.com/nlpodyssey/spago/pkg/utils"
+)
+
+var (
+	_ nn.Model = &Model{}
+)
+
+const (
+	// StartToken is the token which starts the decoding.
+	StartToken = "<s>"
+	// EndToken is the token which ends both the input and the output sequences.
+	EndToken = "</s>"
+	// UnknownToken is the token used for the characters out of the vocabulary.
+	UnknownToken = "<unk>"
+)
+
+// Config provides configuration settings for a spelling correction Model.
+type Config struct {
+	// EmbeddingSize is the size of the character embeddings.
+	EmbeddingSize int
+	// HiddenSize is the size of the hidden state of the decoder, and of each
+	// direction of the encoder.
+	HiddenSize int
+	// MaxLengthFactor limits the length of the corrections to this factor of
+	// the length of the input (plus one).
+	MaxLengthFactor mat.Float
+}
+
+// DefaultConfig returns a Config suitable for correcting words and short sentences.
+func DefaultConfig() Config {
+	return Config{
+		EmbeddingSize:   32,
+		HiddenSize:      128,
+		MaxLengthFactor: 1.5,
+	}
+}
+
+// Model implements a character-level sequence-to-sequence spelling correction model.
+type Model struct {
+	nn.BaseModel
+	Config
+	Vocabulary     *vocabulary.Vocabulary
+	Embeddings     []nn.Param `spago:"type:weights;scope:model"`
+	Encoder        *birnn.Model
+	Decoder        *lstm.Model
+	Query          *linear.Model
+	Output         *linear.Model
+	UsedEmbeddings map[int]ag.Node `spago:"scope:processor"`
+}
+
+func init() {
+	gob.Register(&Model{})
+}
+
+// New returns a new Model for the characters of the given vocabulary.
+// The special tokens StartToken, EndToken and UnknownToken are added to the
+// vocabulary, if missing.
+func New(config Config, vocab *vocabulary.Vocabulary) *Model {
+	for _, token := range []string{StartToken, EndToken, UnknownToken} {
+		vocab.Add(token)
+	}
+	embeddings := make([]nn.Param, vocab.Size())
+	for i := range embeddings {
+		embeddings[i] = nn.NewParam(mat.NewEmptyVecDense(config.EmbeddingSize))
+	}
+	return &Model{
+		Config:     config,
+		Vocabulary: vocab,
+		Embeddings: embeddings,
+		Encoder:    birnn.NewBiLSTM(config.EmbeddingSize, config.HiddenSize, birnn.Concat),
+		Decoder:    lstm.New(config.EmbeddingSize, config.HiddenSize),
+		Query:      linear.New(config.HiddenSize, 2*config.HiddenSize),
+		Output:     linear.New(3*config.HiddenSize, vocab.Size()),
+	}
+}
+
+// NewVocabulary returns a new vocabulary with the characters of the given texts.
+func NewVocabulary(texts ...string) *vocabulary.Vocabulary {
+	vocab := vocabulary.New(nil)
+	for _, text := range texts {
+		for _, char := range utils.SplitByRune(text) {
+			vocab.Add(char)
+		}
+	}
+	return vocab
+}
+
+// Initialize initializes the weights of the Model m using the given random generator.
+func (m *Model) Initialize(rndGen *rand.LockedRand) {
+	nn.ForEachParam(m, func(param nn.Param) {
+		if param.Type() == nn.Weights {
+			initializers.XavierUniform(param.Value(), 1, rndGen)
+		}
+	})
+}
+
+// InitProcessor initializes the embeddings cache used by the processor.
+func (m *Model) InitProcessor() {
+	m.UsedEmbeddings = make(map[int]ag.Node)
+}
+
+// Loss returns the average cross-entropy loss of the decoder generating the
+// target from the source, with teacher forcing.
+// The processor must be used for a single sequence.
+func (m *Model) Loss(source, target string) ag.Node {
+	encoded := m.Encode(source)
+	chars := utils.SplitByRune(target)
+	inputs := append([]string{StartToken}, chars...)
+	golds := append(chars, EndToken)
+
+	logits := make([]ag.Node, len(inputs))
+	for i, input := range inputs {
+		logits[i] = m.decode(input, encoded)
+	}
+	return losses.CrossEntropySeq(m.Graph(), logits, m.ids(golds), true)
+}
+
+// Encode returns the encoder states for the characters of the source text,
+// followed by the EndToken.
+func (m *Model) Encode(source string) []ag.Node {
+	chars := append(utils.SplitByRune(source), EndToken)
+	return m.Encoder.Forward(m.embeddings(chars)...)
+}
+
+// decode performs a decoding step, returning the scores (logits) of the next
+// character given the previous one.
+func (m *Model) decode(prev string, encoded []ag.Node) ag.Node {
+	g := m.Graph()
+	h := m.Decoder.Forward(m.embeddings([]string{prev})...)[0]
+	q := m.Query.Forward(h)[0]
+	scores := make([]ag.Node, len(encoded))
+	for i, e := range encoded {
+		scores[i] = g.Dot(q, e)
+	}
+	attention := g.Softmax(g.Concat(scores...))
+	var context ag.Node
+	for i, e := range encoded {
+		context = g.Add(context, g.ProdScalar(e, g.AtVec(attention, i)))
+	}
+	return m.Output.Forward(g.Concat(h, context))[0]
+}
+
+// Correction is a corrected text with its confidence.
+type Correction struct {
+	// Text is the corrected text.
+	Text string
+	// Confidence is the geometric mean of the probabilities of the generated
+	// characters (end of sequence included).
+	Confidence mat.Float
+}
+
+// Correct returns the correction of the text, generated with greedy decoding.
+func (m *Model) Correct(text string) Correction {
+	g := ag.NewGraph(ag.ConcurrentComputations(runtime.NumCPU()))
+	defer g.Clear()
+	return nn.ReifyForInference(m, g).(*Model).correct(text)
+}
+
+func (m *Model) correct(text string) Correction {
+	encoded := m.Encode(text)
+	maxLength := int(mat.Float(len([]rune(text)))*m.MaxLengthFactor) + 1
+	endID := m.Vocabulary.MustID(EndToken)
+
+	var sb strings.Builder
+	var logProb mat.Float
+	prev := StartToken
+	steps := 0
+	for steps < maxLength {
+		probs := floatutils.SoftMax(m.decode(prev, encoded).Value().Data())
+		best := floatutils.ArgMax(probs)
+		logProb += mat.Log(probs[best])
+		steps++
+		if best == endID {
+			break
+		}
+		prev = m.Vocabulary.MustTerm(best)
+		if prev != StartToken && prev != UnknownToken {
+			sb.WriteString(prev)
+		}
+	}
+	return Correction{
+		Text:       sb.String(),
+		Confidence: mat.Exp(logProb / mat.Float(steps)),
+	}
+}
+
+func (m *Model) embeddings(chars []string) []ag.Node {
+	out := make([]ag.Node, len(chars))
+	for i, id := range m.ids(chars) {
+		embedding, ok := m.UsedEmbeddings[id]
+		if !ok {
+			embedding = m.Graph().NewWrap(m.Embeddings[id])
+			m.UsedEmbeddings[id] = embedding
+		}
+		out[i] = embedding
+	}
+	return out
+}
+
+func (m *Model) ids(chars []string) []int {
+	out := make([]int, len(chars))
+	for i, char := range chars {
+		id, ok := m.Vocabulary.ID(char)
+		if !ok {
+			id = m.Vocabulary.MustID(UnknownToken)
+		}
+		out[i] = id
+	}
+	return out
+}
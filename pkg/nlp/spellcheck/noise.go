@@ -0,0 +1,119 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package spellcheck
+
+import (
+	"strings"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/mat32/rand"
+)
+
+// NoiseConfig provides configuration settings for a NoiseGenerator.
+type NoiseConfig struct {
+	// ErrorRate is the probability of introducing an error at each character.
+	ErrorRate mat.Float
+	// Alphabet contains the characters used for insertions and substitutions.
+	// If empty, the characters of the text being corrupted are used.
+	Alphabet string
+	// Neighbors maps a character to the ones which are likely to be typed in
+	// its place (e.g. the adjacent keys of a keyboard). When available, they
+	// are preferred for substitutions and insertions.
+	Neighbors map[rune]string
+}
+
+// DefaultNoiseConfig returns a NoiseConfig with a 5% error rate and the
+// neighbors of the QWERTY keyboard layout.
+func DefaultNoiseConfig() NoiseConfig {
+	return NoiseConfig{
+		ErrorRate: 0.05,
+		Neighbors: QwertyNeighbors(),
+	}
+}
+
+// QwertyNeighbors returns the adjacent keys of each letter of the QWERTY
+// keyboard layout.
+func QwertyNeighbors() map[rune]string {
+	rows := []string{"qwertyuiop", "asdfghjkl", "zxcvbnm"}
+	neighbors := make(map[rune]string)
+	for r, row := range rows {
+		for c, key := range row {
+			var sb strings.Builder
+			for dr := -1; dr <= 1; dr++ {
+				if r+dr < 0 || r+dr >= len(rows) {
+					continue
+				}
+				other := rows[r+dr]
+				for dc := -1; dc <= 1; dc++ {
+					if (dr == 0 && dc == 0) || c+dc < 0 || c+dc >= len(other) {
+						continue
+					}
+					sb.WriteByte(other[c+dc])
+				}
+			}
+			neighbors[key] = sb.String()
+		}
+	}
+	return neighbors
+}
+
+// NoiseGenerator implements a noisy channel, which corrupts correct texts
+// with typing errors (deletions, insertions, substitutions and
+// transpositions of characters), to generate the training examples of a
+// correction Model.
+type NoiseGenerator struct {
+	NoiseConfig
+	rndGen *rand.LockedRand
+}
+
+// NewNoiseGenerator returns a new NoiseGenerator, which draws the errors
+// using the given random generator.
+func NewNoiseGenerator(config NoiseConfig, rndGen *rand.LockedRand) *NoiseGenerator {
+	return &NoiseGenerator{
+		NoiseConfig: config,
+		rndGen:      rndGen,
+	}
+}
+
+// Corrupt returns a copy of the text with random typing errors.
+func (n *NoiseGenerator) Corrupt(text string) string {
+	src := []rune(text)
+	alphabet := []rune(n.Alphabet)
+	if len(alphabet) == 0 {
+		alphabet = src
+	}
+	out := make([]rune, 0, len(src)+1)
+	for i := 0; i < len(src); i++ {
+		if mat.Float(n.rndGen.Float()) >= n.ErrorRate {
+			out = append(out, src[i])
+			continue
+		}
+		switch n.rndGen.Intn(4) {
+		case 0: // deletion
+		case 1: // insertion
+			out = append(out, src[i], n.similar(src[i], alphabet))
+		case 2: // substitution
+			out = append(out, n.similar(src[i], alphabet))
+		case 3: // transposition
+			if i+1 < len(src) {
+				out = append(out, src[i+1], src[i])
+				i++
+			} else {
+				out = append(out, src[i])
+			}
+		}
+	}
+	return string(out)
+}
+
+// similar returns a random neighbor of r, if any, otherwise a random
+// character of the alphabet.
+func (n *NoiseGenerator) similar(r rune, alphabet []rune) rune {
+	if neighbors, ok := n.Neighbors[r]; ok && len(neighbors) > 0 {
+		candidates := []rune(neighbors)
+		return candidates[n.rndGen.Intn(len(candidates))]
+	}
+	return alphabet[n.rndGen.Intn(len(alphabet))]
+}
@@ -0,0 +1,76 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package spellcheck
+
+import (
+	"testing"
+
+	"github.com/nlpodyssey/spago/pkg/mat32/rand"
+	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd/adam"
+	"github.com/stretchr/testify/assert"
+)
+
+type sliceCorpus []string
+
+func (c sliceCorpus) ForEachLine(callback func(i int, line string)) {
+	for i, line := range c {
+		callback(i+1, line)
+	}
+}
+
+func TestNoiseGenerator_Corrupt(t *testing.T) {
+	text := "the quick brown fox jumps over the lazy dog"
+
+	t.Run("no errors", func(t *testing.T) {
+		noise := NewNoiseGenerator(NoiseConfig{ErrorRate: 0}, rand.NewLockedRand(1))
+		assert.Equal(t, text, noise.Corrupt(text))
+	})
+
+	t.Run("reproducible errors", func(t *testing.T) {
+		config := NoiseConfig{ErrorRate: 0.3, Neighbors: QwertyNeighbors()}
+		a := NewNoiseGenerator(config, rand.NewLockedRand(1)).Corrupt(text)
+		b := NewNoiseGenerator(config, rand.NewLockedRand(1)).Corrupt(text)
+		assert.Equal(t, a, b)
+		assert.NotEqual(t, text, a)
+	})
+
+	t.Run("alphabet", func(t *testing.T) {
+		noise := NewNoiseGenerator(NoiseConfig{ErrorRate: 1, Alphabet: "x"}, rand.NewLockedRand(1))
+		for _, r := range noise.Corrupt("aaaaaaaa") {
+			assert.Contains(t, "ax", string(r))
+		}
+	})
+}
+
+func TestQwertyNeighbors(t *testing.T) {
+	neighbors := QwertyNeighbors()
+	assert.ElementsMatch(t, []rune("was"), []rune(neighbors['q']))
+	assert.ElementsMatch(t, []rune("rtyfhvbn"), []rune(neighbors['g']))
+}
+
+func TestTrainer(t *testing.T) {
+	words := sliceCorpus{"cat", "dog", "cow"}
+	config := Config{EmbeddingSize: 8, HiddenSize: 16, MaxLengthFactor: 2}
+	model := New(config, NewVocabulary(words...))
+	model.Initialize(rand.NewLockedRand(42))
+
+	trainingConfig := TrainingConfig{
+		Seed:             1,
+		Epochs:           60,
+		BatchSize:        1,
+		GradientClipping: 5,
+		UpdateMethod:     adam.NewConfig(0.01, 0.9, 0.999, 1.0e-8),
+		Noise:            NoiseConfig{ErrorRate: 0.2},
+	}
+	loss := NewTrainer(trainingConfig, words, model).Train()
+	assert.Less(t, float64(loss), 0.5)
+
+	for _, word := range words {
+		correction := model.Correct(word)
+		assert.Equal(t, word, correction.Text)
+		assert.Greater(t, float64(correction.Confidence), 0.5)
+		assert.LessOrEqual(t, float64(correction.Confidence), 1.0)
+	}
+}
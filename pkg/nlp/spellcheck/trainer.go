@@ -0,0 +1,126 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package spellcheck
+
+import (
+	"log"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/mat32/rand"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd"
+	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd/adam"
+	"github.com/nlpodyssey/spago/pkg/ml/optimizers/gd/gdmbuilder"
+	"github.com/nlpodyssey/spago/pkg/nlp/corpora"
+	"github.com/nlpodyssey/spago/pkg/utils"
+)
+
+// TrainingConfig provides configuration settings for a spelling correction Trainer.
+type TrainingConfig struct {
+	Seed             uint64
+	Epochs           int
+	BatchSize        int
+	GradientClipping mat.Float
+	UpdateMethod     gd.MethodConfig
+	// Noise configures the noisy channel which generates the misspelled inputs.
+	Noise NoiseConfig
+	// ModelPath is where the model is serialized at the end of each epoch.
+	// If empty, the model is not serialized.
+	ModelPath string
+}
+
+// DefaultTrainingConfig returns a TrainingConfig using the Adam optimizer and
+// the DefaultNoiseConfig.
+func DefaultTrainingConfig() TrainingConfig {
+	return TrainingConfig{
+		Seed:             42,
+		Epochs:           10,
+		BatchSize:        16,
+		GradientClipping: 5.0,
+		UpdateMethod:     adam.NewDefaultConfig(),
+		Noise:            DefaultNoiseConfig(),
+	}
+}
+
+// Trainer implements the training process of a spelling correction Model:
+// each line of the corpus, which is assumed to be correct, is corrupted by
+// the noisy channel and the model learns to restore it.
+type Trainer struct {
+	TrainingConfig
+	randGen   *rand.LockedRand
+	corpus    corpora.TextCorpusIterator
+	model     *Model
+	noise     *NoiseGenerator
+	optimizer *gd.GradientDescent
+}
+
+// NewTrainer returns a new Trainer.
+func NewTrainer(config TrainingConfig, corpus corpora.TextCorpusIterator, model *Model) *Trainer {
+	randGen := rand.NewLockedRand(config.Seed)
+	return &Trainer{
+		TrainingConfig: config,
+		randGen:        randGen,
+		corpus:         corpus,
+		model:          model,
+		noise:          NewNoiseGenerator(config.Noise, randGen.Spawn()),
+		optimizer: gd.NewOptimizer(
+			gdmbuilder.NewMethod(config.UpdateMethod),
+			nn.NewDefaultParamsIterator(model),
+			gd.ClipGradByNorm(config.GradientClipping, 2.0)),
+	}
+}
+
+// Train executes the training process, returning the average loss of the last epoch.
+func (t *Trainer) Train() mat.Float {
+	var avgLoss mat.Float
+	for epoch := 0; epoch < t.Epochs; epoch++ {
+		avgLoss = t.trainEpoch()
+		log.Printf("spellcheck: epoch %d, average loss %.6f", epoch, avgLoss)
+		t.optimizer.IncEpoch()
+		if t.ModelPath != "" {
+			if err := utils.SerializeToFile(t.ModelPath, t.model); err != nil {
+				panic("spellcheck: error during model serialization.")
+			}
+		}
+	}
+	return avgLoss
+}
+
+func (t *Trainer) trainEpoch() mat.Float {
+	var totalLoss mat.Float
+	count := 0
+	t.corpus.ForEachLine(func(_ int, line string) {
+		if line == "" {
+			return
+		}
+		if count%t.BatchSize == 0 {
+			t.optimizer.IncBatch()
+		}
+		t.optimizer.IncExample()
+		totalLoss += t.trainExample(t.noise.Corrupt(line), line)
+		count++
+		if count%t.BatchSize == 0 {
+			t.optimizer.Optimize()
+		}
+	})
+	if count%t.BatchSize != 0 {
+		t.optimizer.Optimize()
+	}
+	if count == 0 {
+		return 0
+	}
+	return totalLoss / mat.Float(count)
+}
+
+// trainExample accumulates the gradients of the loss of a single example.
+func (t *Trainer) trainExample(source, target string) mat.Float {
+	g := ag.NewGraph(ag.Rand(t.randGen))
+	defer g.Clear()
+	proc := nn.ReifyForTraining(t.model, g).(*Model)
+	loss := proc.Loss(source, target)
+	g.Backward(loss)
+	return loss.ScalarValue()
+}
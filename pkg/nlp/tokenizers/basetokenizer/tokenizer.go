@@ -84,6 +84,13 @@ func (t *BaseTokenizer) Tokenize(text string) []tokenizers.StringOffsetsPair {
 
 // splitOn splits the given string as the `shouldSplit` predicate dictates.
 // It keeps track of the offsets.
+// EncodeWithOptions tokenizes the text pair and builds its Encoding, without
+// any special tokens. If text2 is empty, a single sequence is encoded.
+// Padding requires opts.PadToken to be set.
+func (t *BaseTokenizer) EncodeWithOptions(text, text2 string, opts tokenizers.EncodeOptions) (*tokenizers.Encoding, error) {
+	return tokenizers.Encode(t, tokenizers.Template{}, text, text2, opts)
+}
+
 func (t *BaseTokenizer) splitOn(text string, shouldSplit func(rune) bool, includeSplitToken bool) []tokenizers.StringOffsetsPair {
 	words := make([]tokenizers.StringOffsetsPair, 0)
 	word := make([]rune, 0)
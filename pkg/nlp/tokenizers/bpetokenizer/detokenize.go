@@ -0,0 +1,52 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bpetokenizer
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// runeToByte is the inverse of the byte-level mapping of the pre-tokenizer,
+// which replaces each byte with a printable rune (e.g. the space with "Ġ").
+var runeToByte = make(map[rune]byte, 0x100)
+
+func init() {
+	n := 0
+	for i := 0; i < 0x100; i++ {
+		if (i >= '!' && i <= '~') || (i >= 0xA1 && i <= 0xAC) || (i >= 0xAE && i <= 0xFF) {
+			runeToByte[rune(i)] = byte(i)
+		} else {
+			runeToByte[rune(0x100+n)] = byte(i)
+			n++
+		}
+	}
+}
+
+// Detokenize converts the tokens back into a text, decoding the byte-level
+// representation of the tokens (e.g. "Ġ" becomes a space). The bytes not
+// forming valid UTF-8 sequences, as in the case of a character split across
+// the last tokens, are replaced with the Unicode replacement character.
+// The start, end and padding tokens are skipped.
+func (t *BPETokenizer) Detokenize(tokens []string) string {
+	var buf []byte
+	for _, token := range tokens {
+		switch token {
+		case DefaultStartToken, DefaultEndToken, DefaultPadToken:
+			continue
+		}
+		for _, r := range token {
+			if b, ok := runeToByte[r]; ok {
+				buf = append(buf, b)
+			} else {
+				buf = append(buf, string(r)...)
+			}
+		}
+	}
+	if utf8.Valid(buf) {
+		return string(buf)
+	}
+	return strings.ToValidUTF8(string(buf), string(utf8.RuneError))
+}
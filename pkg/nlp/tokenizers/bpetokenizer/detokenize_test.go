@@ -0,0 +1,23 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bpetokenizer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBPETokenizer_Detokenize(t *testing.T) {
+	tokenizer, err := NewFromModelFolder("testdata/dummy-roberta-model")
+	require.NoError(t, err)
+
+	assert.Equal(t, "Hello world!", tokenizer.Detokenize([]string{"<s>", "Hello", "Ġworld", "!", "</s>", "<pad>"}))
+	assert.Equal(t, " two  spaces\nnew line", tokenizer.Detokenize([]string{"Ġtwo", "Ġ", "Ġspaces", "Ċ", "new", "Ġline"}))
+	// "é" is encoded as the bytes 0xC3 0xA9, i.e. "Ã" and "©"
+	assert.Equal(t, "café", tokenizer.Detokenize([]string{"caf", "Ã", "©"}))
+	assert.Equal(t, "caf�", tokenizer.Detokenize([]string{"caf", "Ã"}))
+}
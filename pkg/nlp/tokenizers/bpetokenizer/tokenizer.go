@@ -48,6 +48,24 @@ const (
 	defaultUnknownFusionEnabled    = false
 )
 
+const (
+	// DefaultStartToken is the default start of sequence token.
+	DefaultStartToken = "<s>"
+	// DefaultEndToken is the default end of sequence token.
+	DefaultEndToken = "</s>"
+	// DefaultPadToken is the default padding token.
+	DefaultPadToken = "<pad>"
+)
+
+// Template is the RoBERTa-style template, in the form "<s> first </s>" or
+// "<s> first </s></s> second </s>", which is also used by BART.
+var Template = tokenizers.Template{
+	Start:     []string{DefaultStartToken},
+	Sep:       []string{DefaultEndToken},
+	PairStart: []string{DefaultEndToken},
+	End:       []string{DefaultEndToken},
+}
+
 // NewFromModelFolder returns a new BPETokenizer built from a
 // pre-trained Roberta-compatible model, given the path to the
 // folder containing the separate model and configuration files.
@@ -169,3 +187,23 @@ func (t *BPETokenizer) Encode(text string) (*encodings.Encoding, error) {
 	}
 	return encoding, nil
 }
+
+// EncodeWithOptions tokenizes the text pair and builds its Encoding with the
+// RoBERTa-style Template. If text2 is empty, a single sequence is encoded.
+// The padding token defaults to DefaultPadToken.
+func (t *BPETokenizer) EncodeWithOptions(text, text2 string, opts tokenizers.EncodeOptions) (*tokenizers.Encoding, error) {
+	if opts.PadToken == "" {
+		opts.PadToken = DefaultPadToken
+	}
+	first, err := t.Tokenize(text)
+	if err != nil {
+		return nil, err
+	}
+	var second []tokenizers.StringOffsetsPair
+	if text2 != "" {
+		if second, err = t.Tokenize(text2); err != nil {
+			return nil, err
+		}
+	}
+	return tokenizers.EncodeTokens(first, second, Template, opts)
+}
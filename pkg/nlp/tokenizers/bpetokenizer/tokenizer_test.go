@@ -35,3 +35,28 @@ func TestNewFromModelFolder(t *testing.T) {
 		t.Errorf("expected:\n  %#v\nactual:\n  %#v\n", expected, actual)
 	}
 }
+
+func TestBPETokenizer_EncodeWithOptions(t *testing.T) {
+	tokenizer, err := NewFromModelFolder("testdata/dummy-roberta-model")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actual, err := tokenizer.EncodeWithOptions("related", "unrelated", tokenizers.EncodeOptions{
+		MaxLength:           8,
+		Padding:             tokenizers.PadToMaxLength,
+		ReturnAttentionMask: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedTokens := []string{"<s>", "related", "</s>", "</s>", "unrelated", "</s>", "<pad>", "<pad>"}
+	if !reflect.DeepEqual(actual.Tokens, expectedTokens) {
+		t.Errorf("expected:\n  %#v\nactual:\n  %#v\n", expectedTokens, actual.Tokens)
+	}
+	expectedMask := []int{1, 1, 1, 1, 1, 1, 0, 0}
+	if !reflect.DeepEqual(actual.AttentionMask, expectedMask) {
+		t.Errorf("expected:\n  %#v\nactual:\n  %#v\n", expectedMask, actual.AttentionMask)
+	}
+}
@@ -0,0 +1,27 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tokenizers
+
+import "strings"
+
+var spacesCleaner = strings.NewReplacer(
+	" .", ".",
+	" ?", "?",
+	" !", "!",
+	" ,", ",",
+	" ' ", "'",
+	" n't", "n't",
+	" 'm", "'m",
+	" 's", "'s",
+	" 've", "'ve",
+	" 're", "'re",
+)
+
+// CleanUpSpaces removes the spaces introduced by joining the tokens before
+// the punctuation and the English contractions (e.g. "do n't ." becomes
+// "don't.").
+func CleanUpSpaces(text string) string {
+	return spacesCleaner.Replace(text)
+}
@@ -0,0 +1,17 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tokenizers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCleanUpSpaces(t *testing.T) {
+	assert.Equal(t, "I don't know, it's fine. Really?!",
+		CleanUpSpaces("I do n't know , it 's fine . Really ? !"))
+	assert.Equal(t, "no changes", CleanUpSpaces("no changes"))
+}
@@ -0,0 +1,212 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tokenizers
+
+import "fmt"
+
+// PaddingStrategy is the enumeration-like type used to distinguish how the
+// encodings are padded.
+type PaddingStrategy int
+
+const (
+	// DoNotPad never adds padding tokens.
+	DoNotPad PaddingStrategy = iota
+	// PadToMaxLength pads each encoding to EncodeOptions.MaxLength.
+	PadToMaxLength
+	// PadToLongest pads the encodings of a batch to the length of the
+	// longest one (see PadBatch).
+	PadToLongest
+)
+
+// EncodeOptions provides the settings used to turn a text, or a text pair,
+// into an Encoding.
+type EncodeOptions struct {
+	// MaxLength is the maximum number of tokens of an encoding, special
+	// tokens included. Zero means no limit.
+	MaxLength int
+	// Truncation is the strategy used when the tokens exceed MaxLength.
+	Truncation TruncationStrategy
+	// TruncationSide is the side of the sequences from which tokens are removed.
+	TruncationSide TruncationSide
+	// Padding is the padding strategy.
+	Padding PaddingStrategy
+	// PadToken is the padding token. If empty, the tokenizers use their own default.
+	PadToken string
+	// ReturnTokenTypeIDs sets whether to fill Encoding.TokenTypeIDs.
+	ReturnTokenTypeIDs bool
+	// ReturnAttentionMask sets whether to fill Encoding.AttentionMask.
+	ReturnAttentionMask bool
+}
+
+// Template describes the special tokens which a model expects around a single
+// sequence, "Start first Sep", or a pair of sequences,
+// "Start first Sep PairStart second End".
+// The zero value adds no special tokens.
+type Template struct {
+	// Start contains the tokens preceding the first sequence (e.g. "[CLS]").
+	Start []string
+	// Sep contains the tokens following the first sequence (e.g. "[SEP]").
+	Sep []string
+	// PairStart contains the tokens preceding the second sequence.
+	PairStart []string
+	// End contains the tokens following the second sequence.
+	End []string
+}
+
+// NumSpecialTokens returns the number of special tokens added to a single
+// sequence, or to a pair of sequences.
+func (t Template) NumSpecialTokens(pair bool) int {
+	n := len(t.Start) + len(t.Sep)
+	if pair {
+		n += len(t.PairStart) + len(t.End)
+	}
+	return n
+}
+
+// Encoding is the model input built from a single text or a text pair.
+type Encoding struct {
+	// Tokens contains the token strings, special and padding tokens included.
+	Tokens []string
+	// TokenTypeIDs contains the segment of each token: 0 for the first text
+	// (with its special tokens and the padding), 1 for the second one.
+	// It is nil unless EncodeOptions.ReturnTokenTypeIDs is set.
+	TokenTypeIDs []int
+	// AttentionMask contains 1 for each token to attend to and 0 for each
+	// padding token. It is nil unless EncodeOptions.ReturnAttentionMask is set.
+	AttentionMask []int
+	// Padding is the number of padding tokens at the end of Tokens.
+	Padding int
+	// First contains the (possibly truncated) tokens of the first text.
+	First []StringOffsetsPair
+	// Second contains the (possibly truncated) tokens of the second text.
+	Second []StringOffsetsPair
+	// secondOffset is the index, within Tokens, of the first token of the
+	// second sequence.
+	secondOffset int
+}
+
+// SecondOffset returns the index, within Tokens, of the first token of the
+// second sequence (i.e. following the first sequence and the special tokens
+// in between, as for a single sequence).
+func (e *Encoding) SecondOffset() int {
+	return e.secondOffset
+}
+
+// Encode tokenizes the text pair with the given tokenizer and builds its
+// Encoding according to the template and the options. If text2 is empty, a
+// single sequence is encoded.
+func Encode(tokenizer Tokenizer, template Template, text, text2 string, opts EncodeOptions) (*Encoding, error) {
+	first := tokenizer.Tokenize(text)
+	var second []StringOffsetsPair
+	if text2 != "" {
+		second = tokenizer.Tokenize(text2)
+	}
+	return EncodeTokens(first, second, template, opts)
+}
+
+// EncodeTokens builds the Encoding of already tokenized sequences according
+// to the template and the options. If second is empty, a single sequence is
+// encoded.
+func EncodeTokens(first, second []StringOffsetsPair, template Template, opts EncodeOptions) (*Encoding, error) {
+	if opts.Padding == PadToMaxLength && opts.MaxLength <= 0 {
+		return nil, fmt.Errorf("tokenizers: padding to max length requires a max length")
+	}
+	pair := len(second) > 0
+	if opts.MaxLength > 0 {
+		var err error
+		maxLength := opts.MaxLength - template.NumSpecialTokens(pair)
+		first, second, err = truncatePair(first, second, maxLength, opts.Truncation, opts.TruncationSide)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	e := &Encoding{First: first, Second: second}
+	appendTokens := func(tokens []string, tokenType int) {
+		e.Tokens = append(e.Tokens, tokens...)
+		for range tokens {
+			e.TokenTypeIDs = append(e.TokenTypeIDs, tokenType)
+		}
+	}
+	appendTokens(template.Start, 0)
+	appendTokens(GetStrings(first), 0)
+	appendTokens(template.Sep, 0)
+	e.secondOffset = len(e.Tokens) + len(template.PairStart)
+	if pair {
+		appendTokens(template.PairStart, 1)
+		appendTokens(GetStrings(second), 1)
+		appendTokens(template.End, 1)
+	}
+	e.AttentionMask = make([]int, len(e.Tokens))
+	for i := range e.AttentionMask {
+		e.AttentionMask[i] = 1
+	}
+
+	if opts.Padding == PadToMaxLength {
+		if err := e.pad(opts.MaxLength, opts.PadToken); err != nil {
+			return nil, err
+		}
+	}
+	if !opts.ReturnTokenTypeIDs {
+		e.TokenTypeIDs = nil
+	}
+	if !opts.ReturnAttentionMask {
+		e.AttentionMask = nil
+	}
+	return e, nil
+}
+
+// PadBatch pads the encodings of a batch to the same length, if
+// opts.Padding is PadToLongest; otherwise it does nothing.
+// The encodings must have been built with the same options.
+func PadBatch(encodings []*Encoding, opts EncodeOptions) error {
+	if opts.Padding != PadToLongest {
+		return nil
+	}
+	longest := 0
+	for _, e := range encodings {
+		if len(e.Tokens) > longest {
+			longest = len(e.Tokens)
+		}
+	}
+	for _, e := range encodings {
+		if err := e.pad(longest, opts.PadToken); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pad appends padding tokens until the encoding reaches the given length.
+func (e *Encoding) pad(length int, padToken string) error {
+	n := length - len(e.Tokens)
+	if n <= 0 {
+		return nil
+	}
+	if padToken == "" {
+		return fmt.Errorf("tokenizers: missing padding token")
+	}
+	for i := 0; i < n; i++ {
+		e.Tokens = append(e.Tokens, padToken)
+		if e.TokenTypeIDs != nil {
+			e.TokenTypeIDs = append(e.TokenTypeIDs, 0)
+		}
+		if e.AttentionMask != nil {
+			e.AttentionMask = append(e.AttentionMask, 0)
+		}
+	}
+	e.Padding += n
+	return nil
+}
+
+// Unpadded returns the tokens and the token type ids (if any) without the
+// padding tokens.
+func (e *Encoding) Unpadded() ([]string, []int) {
+	n := len(e.Tokens) - e.Padding
+	if e.TokenTypeIDs == nil {
+		return e.Tokens[:n], nil
+	}
+	return e.Tokens[:n], e.TokenTypeIDs[:n]
+}
@@ -0,0 +1,116 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tokenizers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testTemplate = Template{
+	Start:     []string{"<s>"},
+	Sep:       []string{"</s>"},
+	PairStart: []string{"</s>"},
+	End:       []string{"</s>"},
+}
+
+type whitespaceTokenizer struct{}
+
+func (whitespaceTokenizer) Tokenize(text string) []StringOffsetsPair {
+	var out []StringOffsetsPair
+	start := 0
+	for _, field := range strings.Split(text, " ") {
+		out = append(out, StringOffsetsPair{
+			String:  field,
+			Offsets: OffsetsType{Start: start, End: start + len(field)},
+		})
+		start += len(field) + 1
+	}
+	return out
+}
+
+func TestTemplate_NumSpecialTokens(t *testing.T) {
+	assert.Equal(t, 2, testTemplate.NumSpecialTokens(false))
+	assert.Equal(t, 4, testTemplate.NumSpecialTokens(true))
+	assert.Equal(t, 0, Template{}.NumSpecialTokens(true))
+}
+
+func TestEncode(t *testing.T) {
+	tokenizer := whitespaceTokenizer{}
+
+	t.Run("pair with token types and mask", func(t *testing.T) {
+		e, err := Encode(tokenizer, testTemplate, "a b", "c", EncodeOptions{
+			ReturnTokenTypeIDs:  true,
+			ReturnAttentionMask: true,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"<s>", "a", "b", "</s>", "</s>", "c", "</s>"}, e.Tokens)
+		assert.Equal(t, []int{0, 0, 0, 0, 1, 1, 1}, e.TokenTypeIDs)
+		assert.Equal(t, []int{1, 1, 1, 1, 1, 1, 1}, e.AttentionMask)
+		assert.Equal(t, 0, e.Padding)
+		assert.Equal(t, 5, e.SecondOffset())
+		assert.Equal(t, "c", e.Tokens[e.SecondOffset()])
+	})
+
+	t.Run("truncation left", func(t *testing.T) {
+		e, err := Encode(tokenizer, testTemplate, "a b c d", "", EncodeOptions{
+			MaxLength:      4,
+			TruncationSide: TruncateLeft,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"<s>", "c", "d", "</s>"}, e.Tokens)
+		assert.Equal(t, OffsetsType{Start: 4, End: 5}, e.First[0].Offsets)
+		assert.Nil(t, e.TokenTypeIDs)
+		assert.Nil(t, e.AttentionMask)
+	})
+
+	t.Run("padding to max length", func(t *testing.T) {
+		e, err := Encode(tokenizer, Template{}, "a b", "c", EncodeOptions{
+			MaxLength:           5,
+			Padding:             PadToMaxLength,
+			PadToken:            "<pad>",
+			ReturnTokenTypeIDs:  true,
+			ReturnAttentionMask: true,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a", "b", "c", "<pad>", "<pad>"}, e.Tokens)
+		assert.Equal(t, []int{0, 0, 1, 0, 0}, e.TokenTypeIDs)
+		assert.Equal(t, []int{1, 1, 1, 0, 0}, e.AttentionMask)
+		assert.Equal(t, 2, e.Padding)
+
+		tokens, tokenTypeIDs := e.Unpadded()
+		assert.Equal(t, []string{"a", "b", "c"}, tokens)
+		assert.Equal(t, []int{0, 0, 1}, tokenTypeIDs)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := Encode(tokenizer, Template{}, "a", "", EncodeOptions{Padding: PadToMaxLength})
+		assert.Error(t, err)
+		_, err = Encode(tokenizer, Template{}, "a", "", EncodeOptions{MaxLength: 3, Padding: PadToMaxLength})
+		assert.EqualError(t, err, "tokenizers: missing padding token")
+		_, err = Encode(tokenizer, testTemplate, "a b", "", EncodeOptions{MaxLength: 3, Truncation: DoNotTruncate})
+		assert.Error(t, err)
+	})
+}
+
+func TestPadBatch(t *testing.T) {
+	opts := EncodeOptions{Padding: PadToLongest, PadToken: "<pad>", ReturnAttentionMask: true}
+	var batch []*Encoding
+	for _, text := range []string{"a b c", "a", "a b"} {
+		e, err := Encode(whitespaceTokenizer{}, testTemplate, text, "", opts)
+		require.NoError(t, err)
+		batch = append(batch, e)
+	}
+	require.NoError(t, PadBatch(batch, opts))
+
+	assert.Equal(t, []string{"<s>", "a", "</s>", "<pad>", "<pad>"}, batch[1].Tokens)
+	assert.Equal(t, []int{1, 1, 1, 0, 0}, batch[1].AttentionMask)
+	for _, e := range batch {
+		assert.Len(t, e.Tokens, 5)
+	}
+}
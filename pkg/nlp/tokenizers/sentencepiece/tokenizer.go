@@ -7,6 +7,7 @@ package sentencepiece
 import (
 	"fmt"
 	"github.com/nlpodyssey/gotokenizers/vocabulary"
+	"github.com/nlpodyssey/spago/pkg/nlp/tokenizers"
 	"github.com/nlpodyssey/spago/pkg/nlp/tokenizers/sentencepiece/internal/sentencepiece"
 	"path/filepath"
 	"strings"
@@ -15,6 +16,20 @@ import (
 const defaultUnknownToken = "<unk>"
 const defaultSeparator = "▁"
 
+const (
+	// DefaultEndToken is the default end of sequence token.
+	DefaultEndToken = "</s>"
+	// DefaultPadToken is the default padding token.
+	DefaultPadToken = "<pad>"
+)
+
+// Template is the template of the sequence-to-sequence models, in the form
+// "first </s>" or "first </s> second </s>".
+var Template = tokenizers.Template{
+	Sep: []string{DefaultEndToken},
+	End: []string{DefaultEndToken},
+}
+
 // Tokenizer is a Sentence Piece tokenizer.
 type Tokenizer struct {
 	sp    *sentencepiece.Sentencepiece
@@ -52,6 +67,31 @@ func (t *Tokenizer) Tokenize(text string) []string {
 	return result
 }
 
+// EncodeWithOptions tokenizes the text pair and builds its Encoding with the
+// sequence-to-sequence Template. If text2 is empty, a single sequence is
+// encoded. The padding token defaults to DefaultPadToken.
+// Since the sentence-piece tokens are not aligned to the original text, the
+// offsets of Encoding.First and Encoding.Second are left empty.
+func (t *Tokenizer) EncodeWithOptions(text, text2 string, opts tokenizers.EncodeOptions) (*tokenizers.Encoding, error) {
+	if opts.PadToken == "" {
+		opts.PadToken = DefaultPadToken
+	}
+	first := withoutOffsets(t.Tokenize(text))
+	var second []tokenizers.StringOffsetsPair
+	if text2 != "" {
+		second = withoutOffsets(t.Tokenize(text2))
+	}
+	return tokenizers.EncodeTokens(first, second, Template, opts)
+}
+
+func withoutOffsets(tokens []string) []tokenizers.StringOffsetsPair {
+	out := make([]tokenizers.StringOffsetsPair, len(tokens))
+	for i, token := range tokens {
+		out[i] = tokenizers.StringOffsetsPair{String: token}
+	}
+	return out
+}
+
 // TokensToIDs returns a list of token IDs from a list of string tokens.
 // It panics if a token is not found in the vocabulary and no unknown token is found.
 func (t *Tokenizer) TokensToIDs(tokens []string) []int {
@@ -0,0 +1,125 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tokenizers
+
+import "fmt"
+
+// TruncationStrategy is the enumeration-like type used to distinguish how a
+// pair of token sequences is shortened to fit a maximum length.
+type TruncationStrategy int
+
+const (
+	// LongestFirst removes one token at a time from the longest sequence,
+	// until the pair fits.
+	LongestFirst TruncationStrategy = iota
+	// OnlyFirst removes tokens from the first sequence only.
+	OnlyFirst
+	// OnlySecond removes tokens from the second sequence only (e.g. the
+	// passage of a question-passage pair).
+	OnlySecond
+	// DoNotTruncate never removes tokens: sequences exceeding the maximum
+	// length are reported as an error.
+	DoNotTruncate
+)
+
+// String returns the name of the strategy.
+func (s TruncationStrategy) String() string {
+	switch s {
+	case LongestFirst:
+		return "longest-first"
+	case OnlyFirst:
+		return "only-first"
+	case OnlySecond:
+		return "only-second"
+	case DoNotTruncate:
+		return "do-not-truncate"
+	default:
+		return fmt.Sprintf("TruncationStrategy(%d)", int(s))
+	}
+}
+
+// TruncationSide is the enumeration-like type used to distinguish the side
+// of a sequence from which the tokens are removed.
+type TruncationSide int
+
+const (
+	// TruncateRight removes the tokens from the end of the sequences.
+	TruncateRight TruncationSide = iota
+	// TruncateLeft removes the tokens from the beginning of the sequences.
+	TruncateLeft
+)
+
+// TruncatePair shortens the first and second sequences, removing tokens from
+// their end according to the strategy, so that their total length doesn't
+// exceed maxLength.
+// The second sequence can be empty, in which case the first one is truncated
+// regardless of the strategy, unless DoNotTruncate is used.
+// The input slices are not modified; the returned ones share their
+// underlying arrays.
+// An error is returned if the sequences can't be fit with the given strategy.
+func TruncatePair(
+	first, second []StringOffsetsPair,
+	maxLength int,
+	strategy TruncationStrategy,
+) ([]StringOffsetsPair, []StringOffsetsPair, error) {
+	return truncatePair(first, second, maxLength, strategy, TruncateRight)
+}
+
+func truncatePair(
+	first, second []StringOffsetsPair,
+	maxLength int,
+	strategy TruncationStrategy,
+	side TruncationSide,
+) ([]StringOffsetsPair, []StringOffsetsPair, error) {
+	a, b, err := truncatedLengths(len(first), len(second), maxLength, strategy)
+	if err != nil {
+		return nil, nil, err
+	}
+	if side == TruncateLeft {
+		return first[len(first)-a:], second[len(second)-b:], nil
+	}
+	return first[:a], second[:b], nil
+}
+
+// truncatedLengths returns the lengths of the first and second sequences
+// after the truncation.
+func truncatedLengths(a, b, maxLength int, strategy TruncationStrategy) (int, int, error) {
+	if maxLength < 0 {
+		return 0, 0, fmt.Errorf("tokenizers: invalid max length %d", maxLength)
+	}
+	exceeding := a + b - maxLength
+	if exceeding <= 0 {
+		return a, b, nil
+	}
+	if b == 0 && strategy != DoNotTruncate {
+		strategy = OnlyFirst
+	}
+
+	switch strategy {
+	case LongestFirst:
+		for ; exceeding > 0; exceeding-- {
+			if a > b {
+				a--
+			} else {
+				b--
+			}
+		}
+		return a, b, nil
+	case OnlyFirst:
+		if exceeding > a {
+			return 0, 0, fmt.Errorf("tokenizers: the second sequence alone exceeds the max length %d", maxLength)
+		}
+		return a - exceeding, b, nil
+	case OnlySecond:
+		if exceeding > b {
+			return 0, 0, fmt.Errorf("tokenizers: the first sequence alone exceeds the max length %d", maxLength)
+		}
+		return a, b - exceeding, nil
+	case DoNotTruncate:
+		return 0, 0, fmt.Errorf("tokenizers: sequence length %d exceeds the max length %d", a+b, maxLength)
+	default:
+		return 0, 0, fmt.Errorf("tokenizers: unknown truncation strategy %v", strategy)
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tokenizers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTruncatePair(t *testing.T) {
+	tokens := func(n int) []StringOffsetsPair {
+		return make([]StringOffsetsPair, n)
+	}
+
+	testCases := []struct {
+		strategy     TruncationStrategy
+		first        int
+		second       int
+		maxLength    int
+		expectFirst  int
+		expectSecond int
+		expectError  bool
+	}{
+		{LongestFirst, 3, 4, 10, 3, 4, false},
+		{LongestFirst, 6, 3, 6, 3, 3, false},
+		{LongestFirst, 6, 4, 5, 3, 2, false},
+		{LongestFirst, 6, 0, 4, 4, 0, false},
+		{OnlyFirst, 6, 4, 7, 3, 4, false},
+		{OnlyFirst, 2, 6, 5, 0, 0, true},
+		{OnlySecond, 2, 6, 5, 2, 3, false},
+		{OnlySecond, 6, 2, 5, 0, 0, true},
+		{OnlySecond, 6, 0, 5, 5, 0, false},
+		{DoNotTruncate, 2, 2, 4, 2, 2, false},
+		{DoNotTruncate, 2, 3, 4, 0, 0, true},
+	}
+
+	for _, tc := range testCases {
+		first, second, err := TruncatePair(tokens(tc.first), tokens(tc.second), tc.maxLength, tc.strategy)
+		if tc.expectError {
+			assert.Error(t, err, tc.strategy)
+			continue
+		}
+		require.NoError(t, err, tc.strategy)
+		assert.Len(t, first, tc.expectFirst, tc.strategy)
+		assert.Len(t, second, tc.expectSecond, tc.strategy)
+	}
+}
+
+func TestTruncationStrategy_String(t *testing.T) {
+	assert.Equal(t, "longest-first", LongestFirst.String())
+	assert.Equal(t, "only-second", OnlySecond.String())
+	assert.Equal(t, "TruncationStrategy(9)", TruncationStrategy(9).String())
+}
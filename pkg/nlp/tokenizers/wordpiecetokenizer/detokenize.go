@@ -0,0 +1,33 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wordpiecetokenizer
+
+import (
+	"strings"
+
+	"github.com/nlpodyssey/spago/pkg/nlp/tokenizers"
+)
+
+// Detokenize converts the word pieces back into a text, joining the pieces
+// starting with the split prefix ("##") to the previous ones, and cleaning up
+// the spaces before the punctuation (see tokenizers.CleanUpSpaces).
+// The special tokens, except the unknown token, are skipped.
+func (t *WordPieceTokenizer) Detokenize(tokens []string) string {
+	var sb strings.Builder
+	for _, token := range tokens {
+		if token != t.unkToken && (IsDefaultSpecial(token) || token == DefaultPadToken) {
+			continue
+		}
+		if strings.HasPrefix(token, t.splitPrefix) {
+			sb.WriteString(token[len(t.splitPrefix):])
+			continue
+		}
+		if sb.Len() > 0 {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(token)
+	}
+	return tokenizers.CleanUpSpaces(sb.String())
+}
@@ -0,0 +1,19 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wordpiecetokenizer
+
+import (
+	"testing"
+
+	"github.com/nlpodyssey/spago/pkg/nlp/vocabulary"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWordPieceTokenizer_Detokenize(t *testing.T) {
+	tokenizer := New(vocabulary.New(nil))
+	tokens := []string{"[CLS]", "the", "un", "##aff", "##able", "man", "does", "n't", "[UNK]", "it", ".", "[SEP]", "[PAD]"}
+	assert.Equal(t, "the unaffable man doesn't [UNK] it.", tokenizer.Detokenize(tokens))
+	assert.Equal(t, "aff", tokenizer.Detokenize([]string{"##aff"}))
+}
@@ -0,0 +1,61 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wordpiecetokenizer
+
+import (
+	"github.com/nlpodyssey/spago/pkg/nlp/tokenizers"
+)
+
+// Template is the BERT-style template, in the form "[CLS] first [SEP]" or
+// "[CLS] first [SEP] second [SEP]".
+var Template = tokenizers.Template{
+	Start: []string{DefaultClassToken},
+	Sep:   []string{DefaultSequenceSeparator},
+	End:   []string{DefaultSequenceSeparator},
+}
+
+// Encoding is the BERT-style input built from a single text or a text pair,
+// in the form "[CLS] first [SEP]" or "[CLS] first [SEP] second [SEP]".
+type Encoding = tokenizers.Encoding
+
+// NewEncoding builds the Encoding of the given token sequences, with the
+// token type ids, adding the special tokens of the Template. If second is
+// empty, a single sequence is encoded.
+func NewEncoding(first, second []tokenizers.StringOffsetsPair) *Encoding {
+	e, err := tokenizers.EncodeTokens(first, second, Template, tokenizers.EncodeOptions{
+		ReturnTokenTypeIDs: true,
+	})
+	if err != nil {
+		panic(err) // never happens without truncation and padding
+	}
+	return e
+}
+
+// EncodeWithOptions tokenizes the text pair and builds its Encoding with the
+// BERT-style Template. If text2 is empty, a single sequence is encoded.
+// The padding token defaults to DefaultPadToken.
+func (t *WordPieceTokenizer) EncodeWithOptions(text, text2 string, opts tokenizers.EncodeOptions) (*tokenizers.Encoding, error) {
+	if opts.PadToken == "" {
+		opts.PadToken = DefaultPadToken
+	}
+	return tokenizers.Encode(t, Template, text, text2, opts)
+}
+
+// EncodePair tokenizes the text pair and builds its Encoding, with the token
+// type ids. If text2 is empty, a single sequence is encoded.
+// When maxLength is greater than zero, the texts are truncated according to
+// the strategy so that the encoding, special tokens included, doesn't exceed
+// maxLength tokens.
+func (t *WordPieceTokenizer) EncodePair(
+	text, text2 string,
+	maxLength int,
+	strategy tokenizers.TruncationStrategy,
+) (*Encoding, error) {
+	return t.EncodeWithOptions(text, text2, tokenizers.EncodeOptions{
+		MaxLength:          maxLength,
+		Truncation:         strategy,
+		ReturnTokenTypeIDs: true,
+	})
+}
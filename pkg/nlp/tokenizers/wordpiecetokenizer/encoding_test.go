@@ -0,0 +1,90 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package wordpiecetokenizer
+
+import (
+	"testing"
+
+	"github.com/nlpodyssey/spago/pkg/nlp/tokenizers"
+	"github.com/nlpodyssey/spago/pkg/nlp/vocabulary"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestTokenizer() *WordPieceTokenizer {
+	return New(vocabulary.New([]string{
+		DefaultClassToken, DefaultSequenceSeparator, DefaultUnknownToken, DefaultMaskToken,
+		"who", "is", "the", "cat", "sat", "on", "mat", "?", ".",
+	}))
+}
+
+func TestWordPieceTokenizer_EncodePair(t *testing.T) {
+	tokenizer := newTestTokenizer()
+
+	t.Run("single sequence", func(t *testing.T) {
+		encoding, err := tokenizer.EncodePair("the cat sat", "", 0, tokenizers.LongestFirst)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"[CLS]", "the", "cat", "sat", "[SEP]"}, encoding.Tokens)
+		assert.Equal(t, []int{0, 0, 0, 0, 0}, encoding.TokenTypeIDs)
+		assert.Empty(t, encoding.Second)
+	})
+
+	t.Run("pair", func(t *testing.T) {
+		encoding, err := tokenizer.EncodePair("who sat?", "the cat sat on the mat.", 0, tokenizers.LongestFirst)
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"[CLS]", "who", "sat", "?", "[SEP]", "the", "cat", "sat", "on", "the", "mat", ".", "[SEP]",
+		}, encoding.Tokens)
+		assert.Equal(t, []int{0, 0, 0, 0, 0, 1, 1, 1, 1, 1, 1, 1, 1}, encoding.TokenTypeIDs)
+		assert.Equal(t, 5, encoding.SecondOffset())
+		assert.Equal(t, "the", encoding.Tokens[encoding.SecondOffset()])
+	})
+
+	t.Run("longest-first truncation", func(t *testing.T) {
+		encoding, err := tokenizer.EncodePair("who sat?", "the cat sat on the mat.", 9, tokenizers.LongestFirst)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"[CLS]", "who", "sat", "?", "[SEP]", "the", "cat", "sat", "[SEP]"}, encoding.Tokens)
+		assert.Len(t, encoding.First, 3)
+		assert.Len(t, encoding.Second, 3)
+	})
+
+	t.Run("only-second truncation", func(t *testing.T) {
+		encoding, err := tokenizer.EncodePair("who sat?", "the cat sat on the mat.", 9, tokenizers.OnlySecond)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"[CLS]", "who", "sat", "?", "[SEP]", "the", "cat", "sat", "[SEP]"}, encoding.Tokens)
+		assert.Equal(t, []int{0, 0, 0, 0, 0, 1, 1, 1, 1}, encoding.TokenTypeIDs)
+		assert.Equal(t, tokenizers.OffsetsType{Start: 8, End: 11}, encoding.Second[2].Offsets)
+	})
+
+	t.Run("padding", func(t *testing.T) {
+		encoding, err := tokenizer.EncodeWithOptions("the cat", "", tokenizers.EncodeOptions{
+			MaxLength:           6,
+			Padding:             tokenizers.PadToMaxLength,
+			ReturnAttentionMask: true,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"[CLS]", "the", "cat", "[SEP]", "[PAD]", "[PAD]"}, encoding.Tokens)
+		assert.Equal(t, []int{1, 1, 1, 1, 0, 0}, encoding.AttentionMask)
+		assert.Nil(t, encoding.TokenTypeIDs)
+	})
+
+	t.Run("first sequence too long", func(t *testing.T) {
+		_, err := tokenizer.EncodePair("the cat sat on the mat.", "who sat?", 6, tokenizers.OnlySecond)
+		assert.Error(t, err)
+	})
+}
+
+func TestNewEncoding(t *testing.T) {
+	tokenizer := newTestTokenizer()
+	encoding := NewEncoding(tokenizer.Tokenize("who sat?"), tokenizer.Tokenize("the cat"))
+	assert.Equal(t, []string{"[CLS]", "who", "sat", "?", "[SEP]", "the", "cat", "[SEP]"}, encoding.Tokens)
+	assert.Equal(t, []int{0, 0, 0, 0, 0, 1, 1, 1}, encoding.TokenTypeIDs)
+	assert.Equal(t, 5, encoding.SecondOffset())
+	assert.Nil(t, encoding.AttentionMask)
+
+	encoding = NewEncoding(tokenizer.Tokenize("the cat"), nil)
+	assert.Equal(t, []string{"[CLS]", "the", "cat", "[SEP]"}, encoding.Tokens)
+	assert.Equal(t, 4, encoding.SecondOffset())
+}
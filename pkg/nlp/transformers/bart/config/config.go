@@ -64,7 +64,8 @@ type Config struct {
 	NumBeams                   int               `json:"num_beams"`
 	MaxLength                  int               `json:"max_length"`
 	BadWordsIDs                [][]int           `json:"bad_words_ids"`
-	Training                   bool              `json:"training"` // Custom for spaGO
+	AttentionWindow            int               `json:"attention_window"` // local attention of the encoder, as in LED
+	Training                   bool              `json:"training"`         // Custom for spaGO
 }
 
 // Load loads a BART model Config from file.
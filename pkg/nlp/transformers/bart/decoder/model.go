@@ -73,8 +73,10 @@ func makeLayers(config config.Config) []*layer.Layer {
 // KeysValuesPairs contains the layer.KeysValuesPairs for each decoding layer.
 type KeysValuesPairs = []layer.KeysValuesPairs
 
-func getPastSequenceLength(pkv KeysValuesPairs) int {
-	if pkv == nil {
+// PastSequenceLength returns the length of the sequence already decoded
+// with the given cache.
+func PastSequenceLength(pkv KeysValuesPairs) int {
+	if len(pkv) == 0 || len(pkv[0].SelfAttKeyValues) == 0 {
 		return 0
 	}
 	return len(pkv[0].SelfAttKeyValues[0].Values)
@@ -87,7 +89,7 @@ func (m *Model) Decode(
 	pastKeysValuesPairs KeysValuesPairs,
 ) ([]ag.Node, KeysValuesPairs) {
 
-	embedPos := m.PositionalEncoder.Encode(makePositions(len(xs), getPastSequenceLength(pastKeysValuesPairs)))
+	embedPos := m.PositionalEncoder.Encode(makePositions(len(xs), PastSequenceLength(pastKeysValuesPairs)))
 	ys := m.add(xs, embedPos)
 
 	if m.Config.NormalizeEmbedding {
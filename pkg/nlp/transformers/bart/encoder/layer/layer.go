@@ -39,7 +39,7 @@ func init() {
 func NewLayer(config config.Config) *Layer {
 	return &Layer{
 		Config:                 config,
-		SelfAttention:          multiheadattention.New(config.DModel, config.EncoderAttentionHeads, false), // TODO: config.AttentionDropout
+		SelfAttention:          newSelfAttention(config), // TODO: config.AttentionDropout
 		SelfAttentionLayerNorm: layernorm.New(config.DModel),
 		FFN: stack.New(
 			linear.New(config.DModel, config.EncoderFFNDim),
@@ -62,24 +62,40 @@ func mustGetOpName(str string) ag.OpName {
 
 // Forward performs the forward step for each input node and returns the result.
 func (m *Layer) Forward(xs ...ag.Node) []ag.Node {
-	selfAtt := m.selfAttentionBlock(xs)
+	out, _ := m.ForwardWithPastKeysValues(xs, nil)
+	return out
+}
+
+// ForwardWithPastKeysValues is like Forward, but the inputs attend also to
+// the given projected keys and values of the self-attention of the past
+// inputs (nil if none). It also returns the projected keys and values of the
+// past inputs followed by the ones of the new inputs.
+func (m *Layer) ForwardWithPastKeysValues(
+	xs []ag.Node,
+	past multiheadattention.KeysValuesPairs,
+) ([]ag.Node, multiheadattention.KeysValuesPairs) {
+	selfAtt, kv := m.selfAttentionBlock(xs, past)
 	out := m.fullyConnectedBlock(selfAtt)
 	// TODO: limit output values if any Inf or NaN
-	return out
+	return out, kv
 }
 
-func (m *Layer) selfAttentionBlock(xs []ag.Node) []ag.Node {
+func (m *Layer) selfAttentionBlock(
+	xs []ag.Node,
+	past multiheadattention.KeysValuesPairs,
+) ([]ag.Node, multiheadattention.KeysValuesPairs) {
 	residual := m.copy(xs)
 	if m.Config.NormalizeBefore {
 		xs = m.SelfAttentionLayerNorm.Forward(xs...)
 	}
-	xs = m.SelfAttention.Forward(attention.ToQKV(xs)).AttOutput // TODO: key_padding_mask
+	att := m.SelfAttention.ForwardWithPastKeysValues(attention.ToQKV(xs), past) // TODO: key_padding_mask
+	xs = att.AttOutput
 	// TODO: xs = m.Dropout(xs) // config.Dropout
 	xs = add(m.Graph(), residual, xs)
 	if !m.Config.NormalizeBefore {
 		xs = m.SelfAttentionLayerNorm.Forward(xs...)
 	}
-	return xs
+	return xs, att.ProjKeysValues
 }
 
 func (m *Layer) fullyConnectedBlock(xs []ag.Node) []ag.Node {
@@ -110,3 +126,19 @@ func add(g *ag.Graph, a []ag.Node, b []ag.Node) []ag.Node {
 	}
 	return c
 }
+
+func newSelfAttention(config config.Config) *multiheadattention.Model {
+	var window attention.SlidingWindowConfig
+	if config.AttentionWindow > 0 {
+		window = attention.SlidingWindowConfig{
+			Window:       config.AttentionWindow / 2,
+			GlobalTokens: []int{0}, // <s>
+		}
+	}
+	return multiheadattention.NewWithConfig(multiheadattention.Config{
+		Size:          config.DModel,
+		NumOfHeads:    config.EncoderAttentionHeads,
+		UseCausalMask: false,
+		SlidingWindow: window,
+	})
+}
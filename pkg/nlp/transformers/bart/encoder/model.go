@@ -8,6 +8,7 @@ import (
 	"encoding/gob"
 	"github.com/nlpodyssey/spago/pkg/ml/ag"
 	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/ml/nn/attention/multiheadattention"
 	"github.com/nlpodyssey/spago/pkg/ml/nn/normalization/layernorm"
 	"github.com/nlpodyssey/spago/pkg/ml/nn/stack"
 	"github.com/nlpodyssey/spago/pkg/nlp/transformers/bart/config"
@@ -15,7 +16,6 @@ import (
 	"github.com/nlpodyssey/spago/pkg/nlp/transformers/bart/positionalencoder"
 	"github.com/nlpodyssey/spago/pkg/nlp/transformers/bart/positionalencoder/learnedpositionalencoder"
 	"github.com/nlpodyssey/spago/pkg/nlp/transformers/bart/positionalencoder/sinusoidalpositionalencoder"
-	"github.com/nlpodyssey/spago/pkg/utils"
 )
 
 var (
@@ -66,19 +66,59 @@ func New(config config.Config) *Model {
 	}
 }
 
+// KeysValuesPairs contains the projected keys and values of the
+// self-attention of each encoding layer.
+type KeysValuesPairs = []multiheadattention.KeysValuesPairs
+
+// PastSequenceLength returns the length of the sequence already encoded with
+// the given keys and values.
+func PastSequenceLength(pkv KeysValuesPairs) int {
+	if len(pkv) == 0 || len(pkv[0]) == 0 {
+		return 0
+	}
+	return len(pkv[0][0].Keys)
+}
+
 // Encode performs the forward step for each input node and returns the result.
 func (m *Model) Encode(xs []ag.Node) []ag.Node {
-	embedPos := m.PositionalEncoder.Encode(utils.MakeIndices(len(xs)))
+	ys, _ := m.EncodeWithPast(xs, nil)
+	return ys // TODO: return all hidden states?
+}
+
+// EncodeWithPast is like Encode, but the inputs follow the ones already
+// encoded with the given keys and values (nil at the start of the sequence),
+// to which they attend. The past inputs are not encoded anew, so they don't
+// attend to the new ones. It also returns the keys and values of the whole
+// sequence.
+func (m *Model) EncodeWithPast(xs []ag.Node, past KeysValuesPairs) ([]ag.Node, KeysValuesPairs) {
+	embedPos := m.PositionalEncoder.Encode(makePositions(len(xs), PastSequenceLength(past)))
 	ys := add(m.Graph(), xs, embedPos)
 	if m.Config.NormalizeEmbedding {
 		ys = m.EmbeddingLayerNorm.Forward(ys...)
 		// TODO: ys = m.Dropout(ys)
 	}
-	ys = m.Layers.Forward(ys...)
+	next := make(KeysValuesPairs, len(m.Layers.Layers))
+	for i, l := range m.Layers.Layers {
+		var layerPast multiheadattention.KeysValuesPairs
+		if past != nil {
+			layerPast = past[i]
+		}
+		ys, next[i] = l.(*layer.Layer).ForwardWithPastKeysValues(ys, layerPast)
+	}
 	if m.Config.FinalLayerNorm {
 		ys = m.LayerNorm.Forward(ys...)
 	}
-	return ys // TODO: return all hidden states?
+	return ys, next
+}
+
+// makePositions returns a slice of the given size, where each element has
+// the same value of its own index position plus the offset.
+func makePositions(size, offset int) []int {
+	indices := make([]int, size)
+	for i := range indices {
+		indices[i] = i + offset
+	}
+	return indices
 }
 
 func add(g *ag.Graph, a []ag.Node, b []ag.Node) []ag.Node {
@@ -59,6 +59,21 @@ func (m *Model) PredictNext(
 
 // Generate generates sequences using generation-search decoding.
 func (m *Model) Generate(inputIDs []int) []int {
+	return m.GenerateConstrained(inputIDs, nil)
+}
+
+// GenerateConstrained generates sequences using generation-search decoding,
+// restricting the generated tokens with the given constraint (e.g. a
+// generation.PrefixTrie), if not nil.
+func (m *Model) GenerateConstrained(inputIDs []int, constraint generation.PrefixConstraint) []int {
+	return m.newGenerator(constraint).Generate(inputIDs)
+}
+
+func (m *Model) newGenerator(constraint generation.PrefixConstraint) *generation.Generator {
+	return generation.NewGenerator(m.generatorConfig(constraint), m)
+}
+
+func (m *Model) generatorConfig(constraint generation.PrefixConstraint) generation.GeneratorConfig {
 	incrementalForward := m.Graph().IncrementalForwardEnabled()
 
 	maxConcurrentComputations := runtime.NumCPU()
@@ -66,7 +81,7 @@ func (m *Model) Generate(inputIDs []int) []int {
 		maxConcurrentComputations = runtime.NumCPU() / 2
 	}
 
-	generator := generation.NewGenerator(generation.GeneratorConfig{
+	return generation.GeneratorConfig{
 		NumBeams:                  m.BART.Config.NumBeams,
 		MinLength:                 0,
 		MaxLength:                 m.BART.Config.MaxLength,
@@ -79,11 +94,47 @@ func (m *Model) Generate(inputIDs []int) []int {
 		LengthPenalty:             1.0,
 		EarlyStopping:             false,
 		BadWordsIDs:               m.BART.Config.BadWordsIDs,
+		PrefixConstraint:          constraint,
 		MaxConcurrentComputations: maxConcurrentComputations,
 		IncrementalForward:        incrementalForward,
-	}, m)
+	}
+}
+
+// GenerateBatch generates a sequence for each input, decoding them in
+// lockstep (see generation.Generator.GenerateBatch).
+func (m *Model) GenerateBatch(batch [][]int) [][]int {
+	return m.newGenerator(nil).GenerateBatch(batch)
+}
+
+// GenerateInSession is like Generate, but it retains the encoded input and
+// the decoder cache of the first step in the session, reusing them when the
+// same input is generated again (see generation.Generator.GenerateInSession).
+func (m *Model) GenerateInSession(inputIDs []int, sessions *generation.SessionCache, sessionID string) []int {
+	return m.newGenerator(nil).GenerateInSession(inputIDs, sessions, sessionID)
+}
+
+// GenerateInSessionIncremental is like GenerateInSession, but an input
+// extending the one of the session is encoded only from the new input IDs,
+// retaining also the keys and values of the encoder self-attention. The
+// earlier inputs don't attend to the new ones, so the output approximates the
+// one of Generate (see generation.GeneratorConfig.IncrementalSessionEncoding).
+func (m *Model) GenerateInSessionIncremental(inputIDs []int, sessions *generation.SessionCache, sessionID string) []int {
+	config := m.generatorConfig(nil)
+	config.IncrementalSessionEncoding = true
+	return generation.NewGenerator(config, m).GenerateInSession(inputIDs, sessions, sessionID)
+}
+
+// GenerateCached is like GenerateInSession, but the state is retained in the
+// cache keyed by the input, so that the encoder is skipped when the same input
+// is generated again (see generation.Generator.GenerateCached).
+func (m *Model) GenerateCached(inputIDs []int, cache *generation.SessionCache) []int {
+	return m.newGenerator(nil).GenerateCached(inputIDs, cache)
+}
 
-	return generator.Generate(inputIDs)
+// ScoreTokens returns the confidence of each token of a sequence generated
+// from the input IDs (see generation.Generator.ScoreTokens).
+func (m *Model) ScoreTokens(inputIDs, outputIDs []int, alternatives int) []generation.TokenScore {
+	return m.newGenerator(nil).ScoreTokens(inputIDs, outputIDs, alternatives)
 }
 
 // Encode satisfies pkg/nlp/transformers/generation/Encoder.
@@ -94,7 +145,7 @@ func (m *Model) Encode(InputIDs []int) []ag.Node {
 // Decode satisfies pkg/nlp/transformers/generation/Decoder.
 func (m *Model) Decode(encodedInput []ag.Node, inputIDs []int, pastCache generation.Cache) (ag.Node, generation.Cache) {
 	pastKeysValues, _ := pastCache.(decoder.KeysValuesPairs)
-	if pastKeysValues != nil {
+	if decoder.PastSequenceLength(pastKeysValues) > 0 {
 		// cut input ids if past is used
 		inputIDs = inputIDs[len(inputIDs)-1:]
 	}
@@ -0,0 +1,134 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package conditionalgeneration
+
+import (
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/nlpodyssey/spago/pkg/ml/nn/attention"
+	"github.com/nlpodyssey/spago/pkg/ml/nn/attention/multiheadattention"
+	"github.com/nlpodyssey/spago/pkg/nlp/transformers/bart/decoder"
+	"github.com/nlpodyssey/spago/pkg/nlp/transformers/bart/decoder/layer"
+	"github.com/nlpodyssey/spago/pkg/nlp/transformers/bart/encoder"
+	"github.com/nlpodyssey/spago/pkg/nlp/transformers/generation"
+)
+
+var (
+	_ generation.StatefulDecoder    = &Model{}
+	_ generation.IncrementalEncoder = &Model{}
+)
+
+// decoderState contains the values of the projected keys and values of the
+// self-attention and of the cross-attention, for each decoding layer and
+// attention head.
+type decoderState struct {
+	selfAtt  [][]keysValues
+	crossAtt [][]keysValues
+}
+
+// encoderState contains the values of the projected keys and values of the
+// self-attention, for each encoding layer and attention head.
+type encoderState [][]keysValues
+
+type keysValues struct {
+	keys   []mat.Matrix
+	values []mat.Matrix
+}
+
+// ExportCache returns a copy of the self-attention and cross-attention keys
+// and values of the cache.
+func (m *Model) ExportCache(cache generation.Cache) (interface{}, int) {
+	pkv, _ := cache.(decoder.KeysValuesPairs)
+	size := 0
+	state := decoderState{
+		selfAtt:  make([][]keysValues, len(pkv)),
+		crossAtt: make([][]keysValues, len(pkv)),
+	}
+	for i, layerCache := range pkv {
+		state.selfAtt[i] = m.exportKeysValues(layerCache.SelfAttKeyValues, &size)
+		state.crossAtt[i] = m.exportKeysValues(layerCache.CrossAttKeyValues, &size)
+	}
+	return state, size
+}
+
+// ImportCache returns the cache of the state exported by ExportCache.
+func (m *Model) ImportCache(state interface{}) generation.Cache {
+	decState := state.(decoderState)
+	pkv := make(decoder.KeysValuesPairs, len(decState.selfAtt))
+	for i := range pkv {
+		pkv[i] = layer.KeysValuesPairs{
+			SelfAttKeyValues:  m.importKeysValues(decState.selfAtt[i]),
+			CrossAttKeyValues: m.importKeysValues(decState.crossAtt[i]),
+		}
+	}
+	return pkv
+}
+
+// EncodeIncremental satisfies pkg/nlp/transformers/generation/IncrementalEncoder.
+// The cache contains the self-attention keys and values of the encoder.
+func (m *Model) EncodeIncremental(inputIDs []int, pastCache generation.Cache) ([]ag.Node, generation.Cache) {
+	past, _ := pastCache.(encoder.KeysValuesPairs)
+	return m.BART.EncodeWithPast(inputIDs, past)
+}
+
+// ExportEncoderCache returns a copy of the self-attention keys and values of
+// the encoder cache.
+func (m *Model) ExportEncoderCache(cache generation.Cache) (interface{}, int) {
+	pkv, _ := cache.(encoder.KeysValuesPairs)
+	size := 0
+	state := make(encoderState, len(pkv))
+	for i, layerCache := range pkv {
+		state[i] = m.exportKeysValues(layerCache, &size)
+	}
+	return state, size
+}
+
+// ImportEncoderCache returns the encoder cache of the state exported by
+// ExportEncoderCache.
+func (m *Model) ImportEncoderCache(state interface{}) generation.Cache {
+	encState := state.(encoderState)
+	pkv := make(encoder.KeysValuesPairs, len(encState))
+	for i, heads := range encState {
+		pkv[i] = m.importKeysValues(heads)
+	}
+	return pkv
+}
+
+// exportKeysValues returns a copy of the values of the keys and values of
+// each attention head, adding their size in bytes to size.
+func (m *Model) exportKeysValues(pairs multiheadattention.KeysValuesPairs, size *int) []keysValues {
+	g := m.Graph()
+	copyValues := func(nodes []ag.Node) []mat.Matrix {
+		values := make([]mat.Matrix, len(nodes))
+		for i, n := range nodes {
+			values[i] = g.GetCopiedValue(n)
+			*size += values[i].Size() * 4
+		}
+		return values
+	}
+	heads := make([]keysValues, len(pairs))
+	for h, kv := range pairs {
+		heads[h] = keysValues{keys: copyValues(kv.Keys), values: copyValues(kv.Values)}
+	}
+	return heads
+}
+
+// importKeysValues returns the keys and values of each attention head,
+// restored on the graph of the model.
+func (m *Model) importKeysValues(heads []keysValues) multiheadattention.KeysValuesPairs {
+	g := m.Graph()
+	newNodes := func(values []mat.Matrix) []ag.Node {
+		nodes := make([]ag.Node, len(values))
+		for i, v := range values {
+			nodes[i] = g.NewVariable(v.Clone(), false)
+		}
+		return nodes
+	}
+	pairs := make(multiheadattention.KeysValuesPairs, len(heads))
+	for h, kv := range heads {
+		pairs[h] = attention.KeysValuesPair{Keys: newNodes(kv.keys), Values: newNodes(kv.values)}
+	}
+	return pairs
+}
@@ -68,6 +68,13 @@ func (m *Model) Encode(inputIDs []int) []ag.Node {
 	return m.Encoder.Encode(m.useScaledEmbeddings(m.Embeddings.Encode(intToStringSlice(inputIDs))))
 }
 
+// EncodeWithPast performs the BART encoding of the inputs following the ones
+// already encoded with the given keys and values (see
+// encoder.Model.EncodeWithPast).
+func (m *Model) EncodeWithPast(inputIDs []int, past encoder.KeysValuesPairs) ([]ag.Node, encoder.KeysValuesPairs) {
+	return m.Encoder.EncodeWithPast(m.useScaledEmbeddings(m.Embeddings.Encode(intToStringSlice(inputIDs))), past)
+}
+
 // Decode performs the BART decoding.
 func (m *Model) Decode(
 	inputIDs []int,
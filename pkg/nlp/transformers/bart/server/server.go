@@ -9,15 +9,21 @@ import (
 	"context"
 	"encoding/json"
 	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/nlp/retrieval"
+	"github.com/nlpodyssey/spago/pkg/nlp/safety"
 	"github.com/nlpodyssey/spago/pkg/nlp/tokenizers/bpetokenizer"
 	"github.com/nlpodyssey/spago/pkg/nlp/tokenizers/sentencepiece"
 	"github.com/nlpodyssey/spago/pkg/nlp/transformers/bart/head/conditionalgeneration"
 	"github.com/nlpodyssey/spago/pkg/nlp/transformers/bart/head/sequenceclassification"
 	"github.com/nlpodyssey/spago/pkg/nlp/transformers/bart/server/grpcapi"
 	"github.com/nlpodyssey/spago/pkg/nlp/transformers/bart/tasks"
+	"github.com/nlpodyssey/spago/pkg/nlp/transformers/bart/tasks/seq2seq"
+	"github.com/nlpodyssey/spago/pkg/nlp/transformers/generation"
+	"github.com/nlpodyssey/spago/pkg/utils/governor"
 	"github.com/nlpodyssey/spago/pkg/utils/grpcutils"
 	"github.com/nlpodyssey/spago/pkg/utils/httputils"
 	"github.com/nlpodyssey/spago/pkg/webui/bartnli"
+	"google.golang.org/grpc"
 	"net/http"
 )
 
@@ -28,6 +34,23 @@ type Server struct {
 	spTokenizer     *sentencepiece.Tokenizer
 	TimeoutSeconds  int
 	MaxRequestBytes int
+	// Sessions, if not nil, retains the states of the generation requests
+	// with a session ID across the requests.
+	Sessions *generation.SessionCache
+	// EncoderCache, if not nil, retains the states of the generation
+	// requests without a session ID, keyed by their input, so that the
+	// same input is not encoded again.
+	EncoderCache *generation.SessionCache
+	// Retriever, if not nil, enables the retrieval-augmented generation,
+	// retrieving the passages the answers are generated from.
+	Retriever retrieval.Retriever
+	// Guard, if not nil, filters the generated texts.
+	Guard *safety.Guard
+	// Governor, if not nil, limits the estimated memory of the requests
+	// served by StartDefaultServer and StartDefaultHTTPServer, queuing or
+	// rejecting the ones exceeding its budget. It should track the
+	// Sessions and the EncoderCache (see governor.New).
+	Governor *governor.Governor
 
 	// UnimplementedBARTServer must be embedded to have forward compatible implementations for gRPC.
 	grpcapi.UnimplementedBARTServer
@@ -53,12 +76,17 @@ func NewServer(
 
 // StartDefaultServer is used to start a basic BART gRPC server.
 func (s *Server) StartDefaultServer(grpcAddress, tlsCert, tlsKey string, tlsDisable bool) {
+	var interceptors []grpc.UnaryServerInterceptor
+	if s.Governor != nil {
+		interceptors = append(interceptors, s.Governor.UnaryServerInterceptor())
+	}
 	grpcServer := grpcutils.NewGRPCServer(grpcutils.GRPCServerConfig{
-		TLSDisable:      tlsDisable,
-		TLSCert:         tlsCert,
-		TLSKey:          tlsKey,
-		TimeoutSeconds:  s.TimeoutSeconds,
-		MaxRequestBytes: s.MaxRequestBytes,
+		TLSDisable:        tlsDisable,
+		TLSCert:           tlsCert,
+		TLSKey:            tlsKey,
+		TimeoutSeconds:    s.TimeoutSeconds,
+		MaxRequestBytes:   s.MaxRequestBytes,
+		UnaryInterceptors: interceptors,
 	})
 	grpcapi.RegisterBARTServer(grpcServer, s)
 	grpcutils.RunGRPCServer(grpcAddress, grpcServer)
@@ -76,10 +104,18 @@ func (s *Server) StartDefaultHTTPServer(address, tlsCert, tlsKey string, tlsDisa
 		mux.HandleFunc("/classify-nli", s.ClassifyNLIHandler)
 	case *conditionalgeneration.Model:
 		mux.HandleFunc("/generate", s.GenerateHandler)
+		if s.Retriever != nil {
+			mux.HandleFunc("/rag", s.RAGHandler)
+		}
 	default:
 		panic("bart: invalid model type")
 	}
 
+	var handler http.Handler = mux
+	if s.Governor != nil {
+		handler = s.Governor.Handler(mux)
+	}
+
 	go httputils.RunHTTPServer(httputils.HTTPServerConfig{
 		Address:         address,
 		TLSDisable:      tlsDisable,
@@ -87,7 +123,7 @@ func (s *Server) StartDefaultHTTPServer(address, tlsCert, tlsKey string, tlsDisa
 		TLSKey:          tlsKey,
 		TimeoutSeconds:  s.TimeoutSeconds,
 		MaxRequestBytes: s.MaxRequestBytes,
-	}, mux)
+	}, handler)
 }
 
 // Classify handles a classification request over gRPC.
@@ -111,8 +147,8 @@ func (s *Server) ClassifyNLI(_ context.Context, req *grpcapi.ClassifyNLIRequest)
 }
 
 // Generate handles a conditional generation request over gRPC.
-func (s *Server) Generate(_ context.Context, req *grpcapi.GenerateRequest) (*grpcapi.GenerateReply, error) {
-	result, err := s.generate(req.GetText())
+func (s *Server) Generate(ctx context.Context, req *grpcapi.GenerateRequest) (*grpcapi.GenerateReply, error) {
+	result, err := s.generate(ctx, req.GetText(), "", false, 0)
 	if err != nil {
 		return nil, err
 	}
@@ -129,6 +165,12 @@ type body struct {
 	HypothesisTemplate string   `json:"hypothesis_template"`
 	PossibleLabels     []string `json:"possible_labels"`
 	MultiClass         bool     `json:"multi_class"`
+	// Following fields used by Generate
+	SessionID    string `json:"session_id"`
+	Confidence   bool   `json:"confidence"`
+	Alternatives int    `json:"alternatives"`
+	// Following field used by RAG
+	TopK int `json:"top_k"`
 }
 
 // ClassifyHandler handles a classify request over HTTP.
@@ -207,7 +249,40 @@ func (s *Server) GenerateHandler(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	result, err := s.generate(content.Text)
+	result, err := s.generate(req.Context(), content.Text, content.SessionID, content.Confidence, content.Alternatives)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	_, pretty := req.URL.Query()["pretty"]
+	response, err := Dump(result, pretty)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	_, err = w.Write(response)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+// RAGHandler handles a retrieval-augmented generation request over HTTP,
+// answering the query in the text field.
+func (s *Server) RAGHandler(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*") // that's intended for testing purposes only
+	w.Header().Set("Content-Type", "application/json")
+
+	var content body
+	err := json.NewDecoder(req.Body).Decode(&content)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := s.answer(req.Context(), content.Text, content.TopK)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -231,6 +306,14 @@ func (s *Server) GenerateHandler(w http.ResponseWriter, req *http.Request) {
 // generation response data.
 type GenerateResponse struct {
 	Text string `json:"text"`
+	// Rejected reports whether the generated text has been rejected by the
+	// safety filters of the server.
+	Rejected bool `json:"rejected,omitempty"`
+	// Findings contains the findings of the safety filters of the server.
+	Findings []safety.Finding `json:"findings,omitempty"`
+	// Tokens contains the confidence of each generated token, if requested.
+	// It's omitted if the safety filters have changed the text.
+	Tokens []seq2seq.TokenConfidence `json:"tokens,omitempty"`
 	// Took is the number of milliseconds it took the server to execute the request.
 	Took int64 `json:"took"`
 }
@@ -5,26 +5,70 @@
 package server
 
 import (
+	"context"
 	"github.com/nlpodyssey/spago/pkg/nlp/transformers/bart/head/conditionalgeneration"
 	"github.com/nlpodyssey/spago/pkg/nlp/transformers/bart/tasks/seq2seq"
+	"github.com/nlpodyssey/spago/pkg/utils/tracing"
 	"time"
 )
 
-func (s *Server) generate(text string) (*GenerateResponse, error) {
+// generate generates a new text from the input. If the server retains the
+// sessions and the session ID is not empty, the state of the session is reused;
+// otherwise, the encoder cache of the server is used, if any.
+// The generated text is filtered by the Guard of the server, if any.
+// If confidence is true, the response contains the confidence of each
+// generated token, along with the given number of alternatives.
+// The stages are recorded as spans, children of the one contained in the
+// context (see the tracing package).
+func (s *Server) generate(
+	ctx context.Context,
+	text string,
+	sessionID string,
+	confidence bool,
+	alternatives int,
+) (_ *GenerateResponse, err error) {
 	start := time.Now()
+	ctx, span := tracing.Start(ctx, "bart.Generate")
+	defer func() { tracing.EndWithError(span, err) }()
 
 	task := seq2seq.BartForConditionalGeneration{
 		Model:     s.model.(*conditionalgeneration.Model),
 		Tokenizer: s.spTokenizer,
+		Context:   ctx,
+	}
+	var tokens []seq2seq.TokenConfidence
+	if confidence {
+		task.OnConfidence = func(tcs []seq2seq.TokenConfidence) { tokens = tcs }
+		task.Alternatives = alternatives
 	}
 
-	generated, err := task.Generate(text)
+	var generated string
+	if s.Sessions != nil && sessionID != "" {
+		generated, err = task.GenerateInSession(text, s.Sessions, sessionID)
+	} else if s.EncoderCache != nil {
+		generated, err = task.GenerateCached(text, s.EncoderCache)
+	} else {
+		generated, err = task.Generate(text)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	return &GenerateResponse{
-		Text: generated,
-		Took: time.Since(start).Milliseconds(),
-	}, nil
+	response := &GenerateResponse{Text: generated, Tokens: tokens}
+	if s.Guard != nil {
+		_, span := tracing.Start(ctx, "bart.postprocess")
+		filtered, err := s.Guard.Apply(generated)
+		span.End()
+		if err != nil {
+			return nil, err
+		}
+		if filtered.Rejected || filtered.Text != generated {
+			response.Tokens = nil // they would disclose the filtered text
+		}
+		response.Text = filtered.Text
+		response.Rejected = filtered.Rejected
+		response.Findings = filtered.Findings
+	}
+	response.Took = time.Since(start).Milliseconds()
+	return response, nil
 }
@@ -0,0 +1,48 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/nlpodyssey/spago/pkg/nlp/rag"
+)
+
+// RAGResponse is a JSON-serializable structure which holds server
+// retrieval-augmented generation response data.
+type RAGResponse struct {
+	*rag.Answer
+	// Took is the number of milliseconds it took the server to execute the request.
+	Took int64 `json:"took"`
+}
+
+// answer generates the answer to the query from the topK passages retrieved
+// by the Retriever of the server (or the default number if topK is zero).
+// The generation uses the encoder cache of the server, if any.
+func (s *Server) answer(ctx context.Context, query string, topK int) (*RAGResponse, error) {
+	start := time.Now()
+
+	generator := rag.GeneratorFunc(func(text string) (string, error) {
+		result, err := s.generate(ctx, text, "", false, 0)
+		if err != nil {
+			return "", err
+		}
+		return result.Text, nil
+	})
+	pipeline, err := rag.New(s.Retriever, generator, rag.DefaultConfig())
+	if err != nil {
+		return nil, err
+	}
+	answer, err := pipeline.Answer(query, topK)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RAGResponse{
+		Answer: answer,
+		Took:   time.Since(start).Milliseconds(),
+	}, nil
+}
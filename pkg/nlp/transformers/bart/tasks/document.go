@@ -0,0 +1,23 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tasks
+
+import "github.com/nlpodyssey/spago/pkg/nlp/document"
+
+// Document returns the classification of the text as a document.Document,
+// whose categories are the classes of the distribution, or the predicted
+// class alone if the distribution is empty.
+func (r *ClassifyResponse) Document(text string) *document.Document {
+	doc := document.New(text)
+	if len(r.Distribution) == 0 {
+		doc.Categories = []document.Category{{Label: r.Class, Confidence: r.Confidence}}
+		return doc
+	}
+	doc.Categories = make([]document.Category, len(r.Distribution))
+	for i, c := range r.Distribution {
+		doc.Categories[i] = document.Category{Label: c.Class, Confidence: c.Confidence}
+	}
+	return doc
+}
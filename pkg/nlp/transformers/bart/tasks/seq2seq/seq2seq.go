@@ -5,12 +5,17 @@
 package seq2seq
 
 import (
+	"context"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
 	"github.com/nlpodyssey/spago/pkg/ml/ag"
 	"github.com/nlpodyssey/spago/pkg/ml/nn"
 	"github.com/nlpodyssey/spago/pkg/nlp/tokenizers/sentencepiece"
 	"github.com/nlpodyssey/spago/pkg/nlp/transformers/bart/config"
 	"github.com/nlpodyssey/spago/pkg/nlp/transformers/bart/head/conditionalgeneration"
 	"github.com/nlpodyssey/spago/pkg/nlp/transformers/bart/loader"
+	"github.com/nlpodyssey/spago/pkg/nlp/transformers/generation"
+	"github.com/nlpodyssey/spago/pkg/utils/tracing"
 )
 
 var _ nn.Model = &BartForConditionalGeneration{}
@@ -21,6 +26,35 @@ var _ nn.Model = &BartForConditionalGeneration{}
 type BartForConditionalGeneration struct {
 	*conditionalgeneration.Model
 	Tokenizer *sentencepiece.Tokenizer
+	// Context, if not nil, contains the parent span of the spans of the
+	// tokenization, generation and detokenization of a request (see the
+	// tracing package).
+	Context context.Context
+	// OnConfidence, if not nil, is called with the confidence of each generated
+	// token (see generation.Generator.ScoreTokens), e.g. for routing the
+	// uncertain generations to human review.
+	OnConfidence func(tokens []TokenConfidence)
+	// Alternatives is the number of most likely tokens reported for each
+	// generated token, if OnConfidence is not nil.
+	Alternatives int
+}
+
+// TokenConfidence is the confidence of the model in a generated token.
+type TokenConfidence struct {
+	Token string `json:"token"`
+	// Probability is the probability of the token given the previous ones.
+	Probability mat.Float `json:"probability"`
+	// Entropy is the entropy of the distribution of the token. The higher,
+	// the more uncertain the model is.
+	Entropy mat.Float `json:"entropy"`
+	// Alternatives contains the most likely tokens, if requested.
+	Alternatives []TokenProbability `json:"alternatives,omitempty"`
+}
+
+// TokenProbability is the probability of a candidate token.
+type TokenProbability struct {
+	Token       string    `json:"token"`
+	Probability mat.Float `json:"probability"`
 }
 
 // LoadModel loads a BartForConditionalGeneration from file.
@@ -41,34 +75,138 @@ func LoadModel(modelPath string) (*BartForConditionalGeneration, error) {
 
 // Generate generates new texts starting from the input.
 func (t *BartForConditionalGeneration) Generate(text string) (string, error) {
-	g := ag.NewGraph(ag.IncrementalForward(false))
+	return t.generate(text, func(proc *conditionalgeneration.Model, tokenIDs []int) []int {
+		return proc.Generate(tokenIDs)
+	})
+}
+
+// GenerateInSession is like Generate, but it reuses the state retained by the
+// session when the same text is generated again (see generation.SessionCache).
+func (t *BartForConditionalGeneration) GenerateInSession(
+	text string,
+	sessions *generation.SessionCache,
+	sessionID string,
+) (string, error) {
+	return t.generate(text, func(proc *conditionalgeneration.Model, tokenIDs []int) []int {
+		return proc.GenerateInSession(tokenIDs, sessions, sessionID)
+	})
+}
+
+// GenerateCached is like Generate, but it skips the encoder when the same
+// text has been generated before, reusing its state retained by the cache
+// (see generation.Generator.GenerateCached).
+func (t *BartForConditionalGeneration) GenerateCached(text string, cache *generation.SessionCache) (string, error) {
+	return t.generate(text, func(proc *conditionalgeneration.Model, tokenIDs []int) []int {
+		return proc.GenerateCached(tokenIDs, cache)
+	})
+}
+
+func (t *BartForConditionalGeneration) generate(
+	text string,
+	generate func(proc *conditionalgeneration.Model, tokenIDs []int) []int,
+) (string, error) {
+	ctx := t.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	g := ag.NewGraph(ag.IncrementalForward(false), ag.WithTracing(ctx))
 	defer g.Clear()
 
 	proc := nn.ReifyForInference(t.Model, g).(*conditionalgeneration.Model)
 	bartConfig := proc.BART.Config
 
+	_, span := tracing.Start(ctx, "bart.tokenize")
 	tokens := t.Tokenizer.Tokenize(text)
 	tokenIDs := t.Tokenizer.TokensToIDs(tokens)
+	span.SetAttribute("tokens", len(tokenIDs))
+	span.End()
 
 	tokenIDs = append(tokenIDs, bartConfig.EosTokenID)
 
-	rawGeneratedIDs := proc.Generate(tokenIDs)
-	generatedIDs := t.stripBadTokens(rawGeneratedIDs, bartConfig)
+	_, span = tracing.Start(ctx, "bart.generate")
+	rawGeneratedIDs := generate(proc, tokenIDs)
+	span.SetAttribute("tokens", len(rawGeneratedIDs))
+	span.End()
 
+	if t.OnConfidence != nil {
+		_, span = tracing.Start(ctx, "bart.confidence")
+		scores := proc.ScoreTokens(tokenIDs, rawGeneratedIDs, t.Alternatives)
+		t.OnConfidence(t.tokenConfidences(scores, bartConfig))
+		span.End()
+	}
+
+	_, span = tracing.Start(ctx, "bart.detokenize")
+	generatedIDs := t.stripBadTokens(rawGeneratedIDs, bartConfig)
 	generatedTokens := t.Tokenizer.IDsToTokens(generatedIDs)
 	generatedText := t.Tokenizer.Detokenize(generatedTokens)
+	span.End()
 
 	return generatedText, nil
 }
 
+// GenerateBatch generates a new text for each input, decoding them together
+// on the same graph. The results are returned in the same order of the inputs.
+func (t *BartForConditionalGeneration) GenerateBatch(texts []string) ([]string, error) {
+	g := ag.NewGraph(ag.IncrementalForward(false))
+	defer g.Clear()
+
+	proc := nn.ReifyForInference(t.Model, g).(*conditionalgeneration.Model)
+	bartConfig := proc.BART.Config
+
+	batch := make([][]int, len(texts))
+	for i, text := range texts {
+		tokenIDs := t.Tokenizer.TokensToIDs(t.Tokenizer.Tokenize(text))
+		batch[i] = append(tokenIDs, bartConfig.EosTokenID)
+	}
+
+	generated := proc.GenerateBatch(batch)
+	results := make([]string, len(generated))
+	for i, rawGeneratedIDs := range generated {
+		generatedIDs := t.stripBadTokens(rawGeneratedIDs, bartConfig)
+		results[i] = t.Tokenizer.Detokenize(t.Tokenizer.IDsToTokens(generatedIDs))
+	}
+	return results, nil
+}
+
 func (t *BartForConditionalGeneration) stripBadTokens(ids []int, bartConfig config.Config) []int {
 	result := make([]int, 0, len(ids))
 	for _, id := range ids {
-		if id == bartConfig.EosTokenID || id == bartConfig.PadTokenID || id == bartConfig.BosTokenID ||
-			id == bartConfig.DecoderStartTokenID {
+		if isBadToken(id, bartConfig) {
 			continue
 		}
 		result = append(result, id)
 	}
 	return result
 }
+
+// tokenConfidences converts the scores of the generated tokens, skipping the
+// ones stripped from the generated text.
+func (t *BartForConditionalGeneration) tokenConfidences(
+	scores []generation.TokenScore,
+	bartConfig config.Config,
+) []TokenConfidence {
+	result := make([]TokenConfidence, 0, len(scores))
+	for _, score := range scores {
+		if isBadToken(score.TokenID, bartConfig) {
+			continue
+		}
+		tc := TokenConfidence{
+			Token:       t.Tokenizer.IDsToTokens([]int{score.TokenID})[0],
+			Probability: score.Probability,
+			Entropy:     score.Entropy,
+		}
+		for _, alt := range score.Alternatives {
+			tc.Alternatives = append(tc.Alternatives, TokenProbability{
+				Token:       t.Tokenizer.IDsToTokens([]int{alt.TokenID})[0],
+				Probability: alt.Probability,
+			})
+		}
+		result = append(result, tc)
+	}
+	return result
+}
+
+func isBadToken(id int, bartConfig config.Config) bool {
+	return id == bartConfig.EosTokenID || id == bartConfig.PadTokenID || id == bartConfig.BosTokenID ||
+		id == bartConfig.DecoderStartTokenID
+}
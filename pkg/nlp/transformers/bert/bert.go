@@ -11,6 +11,7 @@ import (
 	"github.com/nlpodyssey/spago/pkg/ml/ag"
 	"github.com/nlpodyssey/spago/pkg/ml/nn"
 	"github.com/nlpodyssey/spago/pkg/ml/nn/linear"
+	"github.com/nlpodyssey/spago/pkg/nlp/tokenizers"
 	"github.com/nlpodyssey/spago/pkg/nlp/vocabulary"
 	"github.com/nlpodyssey/spago/pkg/utils"
 	"log"
@@ -45,7 +46,8 @@ type Config struct {
 	TypeVocabSize         int               `json:"type_vocab_size"`
 	VocabSize             int               `json:"vocab_size"`
 	ID2Label              map[string]string `json:"id2label"`
-	Training              bool              `json:"training"` // Custom for spaGO
+	AttentionWindow       int               `json:"attention_window"` // local attention, as in Longformer
+	Training              bool              `json:"training"`         // Custom for spaGO
 }
 
 func init() {
@@ -77,7 +79,8 @@ type Model struct {
 	Predictor       *Predictor
 	Discriminator   *Discriminator // used by "ELECTRA" training method
 	Pooler          *Pooler
-	SeqRelationship *linear.Model
+	SeqRelationship *linear.Model // next sentence prediction (NSP) head
+	SentenceOrder   *linear.Model // sentence order prediction (SOP) head, used by ALBERT
 	SpanClassifier  *SpanClassifier
 	Classifier      *Classifier
 }
@@ -102,6 +105,7 @@ func NewDefaultBERT(config Config, embeddingsStoragePath string) *Model {
 			IntermediateSize:       config.IntermediateSize,
 			IntermediateActivation: ag.OpGELU,
 			NumOfLayers:            config.NumHiddenLayers,
+			AttentionWindow:        config.AttentionWindow,
 		}),
 		Predictor: NewPredictor(PredictorConfig{
 			InputSize:        config.HiddenSize,
@@ -121,6 +125,7 @@ func NewDefaultBERT(config Config, embeddingsStoragePath string) *Model {
 			OutputSize: config.HiddenSize,
 		}),
 		SeqRelationship: linear.New(config.HiddenSize, 2),
+		SentenceOrder:   linear.New(config.HiddenSize, 2),
 		SpanClassifier: NewSpanClassifier(SpanClassifierConfig{
 			InputSize: config.HiddenSize,
 		}),
@@ -184,6 +189,35 @@ func (m *Model) Encode(tokens []string) []ag.Node {
 	return m.Encoder.Forward(tokensEncoding...)
 }
 
+// EncodeWithTokenTypes transforms a string sequence into an encoded
+// representation, using the given token type (segment) of each token.
+func (m *Model) EncodeWithTokenTypes(tokens []string, tokenTypeIDs []int) []ag.Node {
+	tokensEncoding := m.Embeddings.EncodeWithTokenTypes(tokens, tokenTypeIDs)
+	return m.Encoder.Forward(tokensEncoding...)
+}
+
+// EncodePair transforms the given Encoding, built from a single text or a
+// text pair, into an encoded representation. The padding tokens are ignored.
+// If the encoding has no token type ids, they are inferred from the sequence
+// separators.
+func (m *Model) EncodePair(encoding *tokenizers.Encoding) []ag.Node {
+	tokens, tokenTypeIDs := encoding.Unpadded()
+	if tokenTypeIDs == nil {
+		return m.Encode(tokens)
+	}
+	return m.EncodeWithTokenTypes(tokens, tokenTypeIDs)
+}
+
+// encodeOptions returns the tokenizers.EncodeOptions which fit the inputs
+// within the maximum number of positions of the model.
+func (m *Model) encodeOptions(strategy tokenizers.TruncationStrategy) tokenizers.EncodeOptions {
+	return tokenizers.EncodeOptions{
+		MaxLength:          m.Embeddings.MaxPositions,
+		Truncation:         strategy,
+		ReturnTokenTypeIDs: true,
+	}
+}
+
 // PredictMasked performs a masked prediction task. It returns the predictions
 // for indices associated to the masked nodes.
 func (m *Model) PredictMasked(transformed []ag.Node, masked []int) map[int]ag.Node {
@@ -59,15 +59,25 @@ func (p Answers) Sort() {
 // Answer returns a slice of candidate answers for the given question-passage pair.
 // The answers are sorted by confidence level in descending order.
 func (m *Model) Answer(question string, passage string) Answers {
+	answers, _ := m.AnswerWithEntropy(question, passage)
+	return answers
+}
+
+// AnswerWithEntropy is like Answer, but it also returns the entropy of the
+// distribution of all the candidate answers, before the unlikely ones are
+// filtered out. The higher, the more ambiguous the passage is.
+func (m *Model) AnswerWithEntropy(question string, passage string) (Answers, mat.Float) {
 	tokenizer := wordpiecetokenizer.New(m.Vocabulary)
-	questionTokens := tokenizer.Tokenize(question)
-	passageTokens := tokenizer.Tokenize(passage)
-	tokenized := mixQuestionAndPassageTokens(questionTokens, passageTokens)
+	encoding, err := tokenizer.EncodeWithOptions(question, passage, m.encodeOptions(tokenizers.OnlySecond))
+	if err != nil || len(encoding.Second) == 0 {
+		return nil, 0 // the question alone doesn't fit the model, or the passage is empty
+	}
+	questionTokens, passageTokens := encoding.First, encoding.Second
 
 	g := ag.NewGraph(ag.ConcurrentComputations(runtime.NumCPU()))
 	defer g.Clear()
 	proc := nn.ReifyForInference(m, g).(*Model)
-	encoded := proc.Encode(tokenized)
+	encoded := proc.EncodePair(encoding)
 
 	startLogits, endLogits := proc.SpanClassifier.Classify(encoded)
 	startLogits, endLogits = adjustLogitsForInference(startLogits, endLogits, questionTokens, passageTokens)
@@ -83,24 +93,17 @@ func (m *Model) Answer(question string, passage string) Answers {
 	)
 
 	if len(candidateAnswers) == 0 {
-		return nil
+		return nil, 0
 	}
 
-	answers := assignScoresAndFilterUnlikelyCandidates(candidateAnswers, scores)
+	probs := floatutils.SoftMax(scores)
+	answers := assignScoresAndFilterUnlikelyCandidates(candidateAnswers, probs)
 
 	sort.Sort(sort.Reverse(answers))
 	if len(answers) > defaultMaxAnswers {
 		answers = answers[:defaultMaxAnswers]
 	}
-	return answers
-}
-
-func mixQuestionAndPassageTokens(question, passage []tokenizers.StringOffsetsPair) []string {
-	cls := wordpiecetokenizer.DefaultClassToken
-	sep := wordpiecetokenizer.DefaultSequenceSeparator
-	tokenized := append([]string{cls}, append(tokenizers.GetStrings(question), sep)...)
-	tokenized = append(tokenized, append(tokenizers.GetStrings(passage), sep)...)
-	return tokenized
+	return answers, floatutils.Entropy(probs)
 }
 
 func adjustLogitsForInference(
@@ -160,8 +163,7 @@ func searchCandidateAnswers(
 	return candidateAnswers, scores
 }
 
-func assignScoresAndFilterUnlikelyCandidates(candidates Answers, scores []mat.Float) Answers {
-	probs := floatutils.SoftMax(scores)
+func assignScoresAndFilterUnlikelyCandidates(candidates Answers, probs []mat.Float) Answers {
 	answers := make(Answers, 0)
 	for i, candidate := range candidates {
 		if probs[i] >= defaultMinConfidence {
@@ -0,0 +1,108 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bert
+
+import (
+	"fmt"
+	"runtime"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/mat32/floatutils"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/ml/nn/linear"
+	"github.com/nlpodyssey/spago/pkg/nlp/tokenizers"
+	"github.com/nlpodyssey/spago/pkg/nlp/tokenizers/wordpiecetokenizer"
+)
+
+// CoherenceHead is the enumeration-like type used to distinguish the
+// pre-training heads which can score the coherence of two sentences.
+type CoherenceHead int
+
+const (
+	// NextSentencePrediction is the BERT head predicting whether the second
+	// sentence follows the first one in the original text.
+	NextSentencePrediction CoherenceHead = iota
+	// SentenceOrderPrediction is the ALBERT head predicting whether two
+	// consecutive sentences are in their original order.
+	SentenceOrderPrediction
+)
+
+// String returns the name of the head.
+func (h CoherenceHead) String() string {
+	switch h {
+	case NextSentencePrediction:
+		return "nsp"
+	case SentenceOrderPrediction:
+		return "sop"
+	default:
+		return fmt.Sprintf("CoherenceHead(%d)", int(h))
+	}
+}
+
+// head returns the linear model of the coherence head, or an error if its
+// weights haven't been loaded from the pre-trained checkpoint (they are all
+// zeros).
+func (m *Model) head(h CoherenceHead) (*linear.Model, error) {
+	var head *linear.Model
+	switch h {
+	case NextSentencePrediction:
+		head = m.SeqRelationship
+	case SentenceOrderPrediction:
+		head = m.SentenceOrder
+	default:
+		return nil, fmt.Errorf("bert: unknown coherence head %d", int(h))
+	}
+	if head == nil || (head.W.Value().Max() == 0 && head.W.Value().Min() == 0) {
+		return nil, fmt.Errorf("bert: the model doesn't contain the %s head", h)
+	}
+	return head, nil
+}
+
+// HasCoherenceHead reports whether the model contains the weights of the head.
+func (m *Model) HasCoherenceHead(h CoherenceHead) bool {
+	_, err := m.head(h)
+	return err == nil
+}
+
+// Coherence returns the probability, estimated by the given pre-training
+// head, that the second sentence coherently follows the first one (i.e. it
+// is the next sentence, or the two sentences are in the right order).
+func (m *Model) Coherence(first, second string, h CoherenceHead) (mat.Float, error) {
+	if _, err := m.head(h); err != nil {
+		return 0, err
+	}
+	tokenizer := wordpiecetokenizer.New(m.Vocabulary)
+	encoding, err := tokenizer.EncodeWithOptions(first, second, m.encodeOptions(tokenizers.LongestFirst))
+	if err != nil {
+		return 0, err
+	}
+
+	g := ag.NewGraph(ag.ConcurrentComputations(runtime.NumCPU()))
+	defer g.Clear()
+	proc := nn.ReifyForInference(m, g).(*Model)
+	head, _ := proc.head(h)
+	logits := head.Forward(proc.Pool(proc.EncodePair(encoding)))[0]
+	// In both heads, the label 0 means that the sentences are coherent.
+	return floatutils.SoftMax(logits.Value().Data())[0], nil
+}
+
+// CoherenceScores returns the coherence of each pair of consecutive
+// sentences, e.g. to find the boundaries of the topics of a text, or to rerank
+// the candidate continuations of a passage.
+func (m *Model) CoherenceScores(sentences []string, h CoherenceHead) ([]mat.Float, error) {
+	if len(sentences) < 2 {
+		return []mat.Float{}, nil
+	}
+	scores := make([]mat.Float, len(sentences)-1)
+	for i := range scores {
+		score, err := m.Coherence(sentences[i], sentences[i+1], h)
+		if err != nil {
+			return nil, err
+		}
+		scores[i] = score
+	}
+	return scores, nil
+}
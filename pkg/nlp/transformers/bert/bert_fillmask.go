@@ -0,0 +1,112 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bert
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"unicode"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/mat32/floatutils"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/nlp/tokenizers"
+	"github.com/nlpodyssey/spago/pkg/nlp/tokenizers/wordpiecetokenizer"
+)
+
+// ErrNoMask is returned by FillMask when the text doesn't contain any mask token.
+var ErrNoMask = errors.New("bert: the text doesn't contain any " + wordpiecetokenizer.DefaultMaskToken + " token")
+
+// FillMaskCandidate is a candidate token for a mask.
+type FillMaskCandidate struct {
+	// Token is the predicted word piece.
+	Token string `json:"token"`
+	// Score is the probability of the token.
+	Score mat.Float `json:"score"`
+	// Sequence is the text with the mask replaced by the token.
+	Sequence string `json:"sequence"`
+}
+
+// FillMaskResult contains the candidates for a mask of the text.
+type FillMaskResult struct {
+	// Start and End are the character offsets of the mask in the text.
+	Start int `json:"start"`
+	End   int `json:"end"`
+	// Candidates are the most probable tokens, in descending order of score.
+	Candidates []FillMaskCandidate `json:"candidates"`
+}
+
+// FillMask predicts the topK most probable tokens for each mask (i.e.
+// `[MASK]`) of the text, returning them with their probability and the text
+// completed with each of them. When the text contains several masks, the
+// other ones are left as they are in the completed texts.
+func (m *Model) FillMask(text string, topK int) ([]FillMaskResult, error) {
+	if topK < 1 {
+		return nil, fmt.Errorf("bert: invalid top-k %d", topK)
+	}
+	origTokens := wordpiecetokenizer.New(m.Vocabulary).Tokenize(text)
+	tokenized := pad(tokenizers.GetStrings(origTokens))
+	if len(tokenized) > m.Embeddings.MaxPositions {
+		return nil, fmt.Errorf("bert: the sequence length %d exceeds the maximum number of positions %d",
+			len(tokenized), m.Embeddings.MaxPositions)
+	}
+	masked := make([]int, 0)
+	for i, token := range tokenized {
+		if token == wordpiecetokenizer.DefaultMaskToken {
+			masked = append(masked, i)
+		}
+	}
+	if len(masked) == 0 {
+		return nil, ErrNoMask
+	}
+
+	g := ag.NewGraph(ag.ConcurrentComputations(runtime.NumCPU()))
+	defer g.Clear()
+	proc := nn.ReifyForInference(m, g).(*Model)
+	predictions := proc.PredictMasked(proc.Encode(tokenized), masked)
+
+	runes := []rune(text)
+	results := make([]FillMaskResult, len(masked))
+	for i, tokenID := range masked {
+		offsets := origTokens[tokenID-1].Offsets // skip CLS
+		probs := mat.NewVecDense(floatutils.SoftMax(predictions[tokenID].Value().Data()))
+		scores, indices := mat.TopK(probs, topK)
+		result := FillMaskResult{
+			Start:      offsets.Start,
+			End:        offsets.End,
+			Candidates: make([]FillMaskCandidate, len(indices)),
+		}
+		for j, index := range indices {
+			word, ok := m.Vocabulary.Term(index)
+			if !ok {
+				word = wordpiecetokenizer.DefaultUnknownToken // if this is returned, there's a misalignment with the vocabulary
+			}
+			result.Candidates[j] = FillMaskCandidate{
+				Token:    word,
+				Score:    scores.AtVec(j),
+				Sequence: fillSpan(runes, offsets.Start, offsets.End, word),
+			}
+		}
+		results[i] = result
+	}
+	return results, nil
+}
+
+// fillSpan returns the text with the span replaced by the word piece. The
+// continuation word pieces (e.g. "##ing") are joined to the previous word,
+// removing the whitespace before the span.
+func fillSpan(runes []rune, start, end int, piece string) string {
+	prefix := runes[:start]
+	if strings.HasPrefix(piece, wordpiecetokenizer.DefaultSplitPrefix) {
+		piece = piece[len(wordpiecetokenizer.DefaultSplitPrefix):]
+		for len(prefix) > 0 && unicode.IsSpace(prefix[len(prefix)-1]) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return string(prefix) + piece + string(runes[end:])
+}
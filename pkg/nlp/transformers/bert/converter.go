@@ -108,6 +108,7 @@ func (c *huggingFacePreTrainedConverter) convert() error {
 	c.addToModelMapping(mapPredictor(c.model.Predictor))
 	c.addToModelMapping(mapPooler(c.model.Pooler))
 	c.addToModelMapping(mapSeqRelationship(c.model.SeqRelationship))
+	c.addToModelMapping(mapSentenceOrder(c.model.SentenceOrder))
 	c.addToModelMapping(mapEmbeddingsLayerNorm(c.model.Embeddings.Norm))
 	c.addToModelMapping(mapEmbeddingsProjection(c.model.Embeddings.Projector))
 	c.addToModelMapping(mapBertEncoder(c.model.Encoder))
@@ -125,6 +126,9 @@ func (c *huggingFacePreTrainedConverter) convert() error {
 		}
 	}
 
+	log.Printf("Next sentence prediction head found: %t", c.modelMapping["cls.seq_relationship.weight"].used)
+	log.Printf("Sentence order prediction head found: %t", c.modelMapping["sop_classifier.classifier.weight"].used)
+
 	log.Printf("Report possible mapping anomalies...")
 	for key, value := range c.modelMapping {
 		if !value.used {
@@ -322,6 +326,15 @@ func mapSeqRelationship(seqRelationship *linear.Model) map[string]mat.Matrix {
 	return paramsMap
 }
 
+// mapSentenceOrder maps the sentence order prediction head of the ALBERT
+// pre-training checkpoints.
+func mapSentenceOrder(sentenceOrder *linear.Model) map[string]mat.Matrix {
+	paramsMap := make(map[string]mat.Matrix)
+	paramsMap["sop_classifier.classifier.weight"] = sentenceOrder.W.Value()
+	paramsMap["sop_classifier.classifier.bias"] = sentenceOrder.B.Value()
+	return paramsMap
+}
+
 func mapEmbeddingsLayerNorm(embeddingsNorm *layernorm.Model) map[string]mat.Matrix {
 	paramsMap := make(map[string]mat.Matrix)
 	paramsMap["bert.embeddings.LayerNorm.weight"] = embeddingsNorm.W.Value()
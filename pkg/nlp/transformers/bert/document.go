@@ -0,0 +1,51 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bert
+
+import "github.com/nlpodyssey/spago/pkg/nlp/document"
+
+// Document returns the classification of the text as a document.Document,
+// whose categories are the classes of the distribution, or the predicted
+// class alone if the distribution is empty.
+func (r *ClassifyResponse) Document(text string) *document.Document {
+	doc := document.New(text)
+	if len(r.Distribution) == 0 {
+		doc.Categories = []document.Category{{Label: r.Class, Confidence: r.Confidence}}
+		return doc
+	}
+	doc.Categories = make([]document.Category, len(r.Distribution))
+	for i, c := range r.Distribution {
+		doc.Categories[i] = document.Category{Label: c.Class, Confidence: c.Confidence}
+	}
+	return doc
+}
+
+// Document returns the labeled tokens of the text as a document.Document.
+func (r *Response) Document(text string) *document.Document {
+	doc := document.New(text)
+	doc.Tokens = make([]document.Token, len(r.Tokens))
+	for i, t := range r.Tokens {
+		doc.Tokens[i] = document.Token{Text: t.Text, Start: t.Start, End: t.End, Label: t.Label}
+	}
+	return doc
+}
+
+// Document returns the answers found in the passage as the entities of a
+// document.Document, labeled "ANSWER".
+func (r *QuestionAnsweringResponse) Document(passage string) *document.Document {
+	doc := document.New(passage)
+	doc.Entities = make([]document.Annotation, len(r.Answers))
+	for i, a := range r.Answers {
+		doc.Entities[i] = document.Annotation{
+			Start:      a.Start,
+			End:        a.End,
+			Text:       a.Text,
+			Label:      "ANSWER",
+			Confidence: a.Confidence,
+			Source:     "bert",
+		}
+	}
+	return doc
+}
@@ -92,19 +92,38 @@ func newProjector(in, out int) *linear.Model {
 }
 
 // Encode transforms a string sequence into an encoded representation.
+// The token type of each word is inferred from the sequence separators:
+// the words following the n-th separator belong to the (n+1)-th segment.
 func (m *Embeddings) Encode(words []string) []ag.Node {
+	return m.EncodeWithTokenTypes(words, inferTokenTypeIDs(words))
+}
+
+// EncodeWithTokenTypes transforms a string sequence into an encoded
+// representation, using the given token type (segment) of each word.
+func (m *Embeddings) EncodeWithTokenTypes(words []string, tokenTypeIDs []int) []ag.Node {
+	if len(tokenTypeIDs) != len(words) {
+		panic("bert: the number of token type ids must match the number of words")
+	}
 	encoded := make([]ag.Node, len(words))
 	wordEmbeddings := m.getWordEmbeddings(words)
-	sequenceIndex := 0
 	for i := 0; i < len(words); i++ {
 		encoded[i] = wordEmbeddings[i]
 		encoded[i] = m.Graph().Add(encoded[i], m.Graph().NewWrap(m.Position[i]))
-		encoded[i] = m.Graph().Add(encoded[i], m.TokenType[sequenceIndex])
-		if words[i] == wordpiecetokenizer.DefaultSequenceSeparator {
+		encoded[i] = m.Graph().Add(encoded[i], m.TokenType[tokenTypeIDs[i]])
+	}
+	return m.useProjection(m.Norm.Forward(encoded...))
+}
+
+func inferTokenTypeIDs(words []string) []int {
+	out := make([]int, len(words))
+	sequenceIndex := 0
+	for i, word := range words {
+		out[i] = sequenceIndex
+		if word == wordpiecetokenizer.DefaultSequenceSeparator {
 			sequenceIndex++
 		}
 	}
-	return m.useProjection(m.Norm.Forward(encoded...))
+	return out
 }
 
 func (m *Embeddings) getWordEmbeddings(words []string) []ag.Node {
@@ -17,6 +17,7 @@ import (
 	"github.com/nlpodyssey/spago/pkg/ml/ag"
 	"github.com/nlpodyssey/spago/pkg/ml/nn"
 	"github.com/nlpodyssey/spago/pkg/ml/nn/activation"
+	"github.com/nlpodyssey/spago/pkg/ml/nn/attention"
 	"github.com/nlpodyssey/spago/pkg/ml/nn/attention/multiheadattention"
 	"github.com/nlpodyssey/spago/pkg/ml/nn/linear"
 	"github.com/nlpodyssey/spago/pkg/ml/nn/normalization/layernorm"
@@ -35,6 +36,10 @@ type EncoderConfig struct {
 	IntermediateSize       int
 	IntermediateActivation ag.OpName
 	NumOfLayers            int
+	// AttentionWindow is the size of the sliding window of the local
+	// attention (see attention.SlidingWindowConfig), where the [CLS] token is
+	// global. If zero, the attention is global.
+	AttentionWindow int
 }
 
 // Encoder is a BERT Encoder model.
@@ -53,12 +58,8 @@ func NewBertEncoder(config EncoderConfig) *Encoder {
 		EncoderConfig: config,
 		Model: stack.Make(config.NumOfLayers, func(i int) nn.StandardModel {
 			return &EncoderLayer{
-				MultiHeadAttention: multiheadattention.New(
-					config.Size,
-					config.NumOfAttentionHeads,
-					false, // don't use causal mask
-				),
-				NormAttention: layernorm.New(config.Size),
+				MultiHeadAttention: newMultiHeadAttention(config),
+				NormAttention:      layernorm.New(config.Size),
 				FFN: stack.New(
 					linear.New(config.Size, config.IntermediateSize),
 					activation.New(config.IntermediateActivation),
@@ -75,12 +76,8 @@ func NewBertEncoder(config EncoderConfig) *Encoder {
 // In this variant the stack of N identical BERT encoder layers share the same parameters.
 func NewAlbertEncoder(config EncoderConfig) *Encoder {
 	sharedLayer := &EncoderLayer{
-		MultiHeadAttention: multiheadattention.New(
-			config.Size,
-			config.NumOfAttentionHeads,
-			false, // don't use causal mask
-		),
-		NormAttention: layernorm.New(config.Size),
+		MultiHeadAttention: newMultiHeadAttention(config),
+		NormAttention:      layernorm.New(config.Size),
 		FFN: stack.New(
 			linear.New(config.Size, config.IntermediateSize),
 			activation.New(config.IntermediateActivation),
@@ -95,3 +92,19 @@ func NewAlbertEncoder(config EncoderConfig) *Encoder {
 		}),
 	}
 }
+
+func newMultiHeadAttention(config EncoderConfig) *multiheadattention.Model {
+	var window attention.SlidingWindowConfig
+	if config.AttentionWindow > 0 {
+		window = attention.SlidingWindowConfig{
+			Window:       config.AttentionWindow / 2,
+			GlobalTokens: []int{0}, // [CLS]
+		}
+	}
+	return multiheadattention.NewWithConfig(multiheadattention.Config{
+		Size:          config.Size,
+		NumOfHeads:    config.NumOfAttentionHeads,
+		UseCausalMask: false,
+		SlidingWindow: window,
+	})
+}
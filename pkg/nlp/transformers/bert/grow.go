@@ -0,0 +1,63 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bert
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+)
+
+const (
+	// encoderLayersName is the qualified name of the layers of the encoder.
+	encoderLayersName = "Encoder.Model.Layers"
+	// wordsName is the qualified name of the word embeddings, whose
+	// parameters are kept in the storage.
+	wordsName = "Embeddings.Words"
+)
+
+// GrowFrom initializes the model, a deeper and/or wider BERT (i.e. with more
+// layers, or larger hidden and intermediate sizes), from the smaller trained
+// model src (see nn.Grow), so that progressive training strategies can start
+// from it instead of from scratch.
+//
+// Unless options.SourceName is set, each layer of the encoder of src is
+// repeated consecutively (see nn.DuplicateLayers). The word embeddings are
+// copied from the storage of src to the one of m, which must be writable.
+func (m *Model) GrowFrom(src *Model, options nn.GrowOptions) error {
+	if m.Embeddings.Words.ReadOnly {
+		return fmt.Errorf("bert: grow: the word embeddings storage is read-only")
+	}
+	sourceName := options.SourceName
+	if sourceName == nil {
+		sourceName = nn.DuplicateLayers(encoderLayersName, src.Config.NumHiddenLayers, m.Config.NumHiddenLayers)
+	}
+	options.SourceName = func(name string) string {
+		if strings.HasPrefix(name, wordsName+".") {
+			return "" // the stored embeddings are copied below
+		}
+		return sourceName(name)
+	}
+	if err := nn.Grow(m, src, options); err != nil {
+		return err
+	}
+
+	words, err := src.Embeddings.Words.Storage.Keys()
+	if err != nil {
+		return err
+	}
+	size := m.Embeddings.Words.Size
+	for _, word := range words {
+		embedding := src.Embeddings.Words.GetStoredEmbedding(word)
+		m.Embeddings.Words.SetEmbedding(word, nn.GrowMatrix(embedding.Value(), size, 1, options))
+	}
+	src.Embeddings.Words.ClearUsedEmbeddings()
+
+	if m.Vocabulary == nil {
+		m.Vocabulary = src.Vocabulary
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bert
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/nlp/lmscoring"
+	"github.com/nlpodyssey/spago/pkg/nlp/tokenizers"
+	"github.com/nlpodyssey/spago/pkg/nlp/tokenizers/wordpiecetokenizer"
+	"github.com/nlpodyssey/spago/pkg/utils/processingqueue"
+)
+
+var _ lmscoring.Scorer = &Model{}
+
+// Score returns the pseudo-log-likelihood of the word pieces of the text,
+// each one predicted by the masked language model from all the others.
+// The masked sequences, one for each word piece, are processed concurrently.
+//
+// Reference: "Masked Language Model Scoring" by J. Salazar, D. Liang, T. Q.
+// Nguyen and K. Kirchhoff, 2020.
+func (m *Model) Score(text string) (lmscoring.Score, error) {
+	tokens := tokenizers.GetStrings(wordpiecetokenizer.New(m.Vocabulary).Tokenize(text))
+	return m.pseudoLogLikelihood(pad(tokens), 1, len(tokens)+1)
+}
+
+// ScoreContinuations returns the pseudo-log-likelihood of the word pieces of
+// each candidate, which follow the ones of the prefix (i.e. the candidate is
+// a new word).
+func (m *Model) ScoreContinuations(prefix string, candidates []string) ([]lmscoring.Score, error) {
+	tokenizer := wordpiecetokenizer.New(m.Vocabulary)
+	head := tokenizers.GetStrings(tokenizer.Tokenize(prefix))
+	scores := make([]lmscoring.Score, len(candidates))
+	for i, candidate := range candidates {
+		tail := tokenizers.GetStrings(tokenizer.Tokenize(candidate))
+		tokens := pad(append(append([]string{}, head...), tail...))
+		var err error
+		scores[i], err = m.pseudoLogLikelihood(tokens, len(head)+1, len(head)+len(tail)+1)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return scores, nil
+}
+
+// pseudoLogLikelihood returns the score of the tokens in the range [from, to),
+// masking one of them at a time.
+func (m *Model) pseudoLogLikelihood(tokens []string, from, to int) (lmscoring.Score, error) {
+	if len(tokens) > m.Embeddings.MaxPositions {
+		return lmscoring.Score{}, fmt.Errorf("bert: the sequence length %d exceeds the maximum number of positions %d",
+			len(tokens), m.Embeddings.MaxPositions)
+	}
+	score := lmscoring.Score{
+		Tokens:   tokens[from:to],
+		LogProbs: make([]mat.Float, to-from),
+	}
+	pq := processingqueue.New(runtime.NumCPU())
+	var wg sync.WaitGroup
+	wg.Add(to - from)
+	for i := from; i < to; i++ {
+		i := i
+		pq.Go(func() {
+			defer wg.Done()
+			target, ok := m.Vocabulary.ID(tokens[i])
+			if !ok {
+				target = m.Vocabulary.MustID(wordpiecetokenizer.DefaultUnknownToken)
+			}
+			masked := append([]string{}, tokens...)
+			masked[i] = wordpiecetokenizer.DefaultMaskToken
+
+			g := ag.NewGraph()
+			defer g.Clear()
+			proc := nn.ReifyForInference(m, g).(*Model)
+			encoded := proc.Encode(masked)
+			logits := proc.PredictMasked(encoded, []int{i})[i].Value().Data()
+			score.LogProbs[i-from] = lmscoring.LogSoftmaxAt(logits, target)
+		})
+	}
+	wg.Wait()
+	return score, nil
+}
@@ -11,11 +11,15 @@ import (
 	"net/http"
 	"sort"
 
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/nlp/embeddings/postprocessing"
 	"github.com/nlpodyssey/spago/pkg/nlp/tokenizers/wordpiecetokenizer"
 	"github.com/nlpodyssey/spago/pkg/nlp/transformers/bert/grpcapi"
+	"github.com/nlpodyssey/spago/pkg/utils/governor"
 	"github.com/nlpodyssey/spago/pkg/utils/grpcutils"
 	"github.com/nlpodyssey/spago/pkg/utils/httputils"
 	"github.com/nlpodyssey/spago/pkg/webui/bertqa"
+	"google.golang.org/grpc"
 )
 
 // TODO: This code needs to be refactored. Pull requests are welcome!
@@ -25,6 +29,14 @@ type Server struct {
 	model           *Model
 	TimeoutSeconds  int
 	MaxRequestBytes int
+	// EmbeddingsPostProcessor, if not nil, is applied to the sentence
+	// embeddings returned by the "encode" requests. It should have been
+	// fitted on embeddings obtained with the same pooling strategy.
+	EmbeddingsPostProcessor *postprocessing.PostProcessor
+	// Governor, if not nil, limits the estimated memory of the requests
+	// served by StartDefaultServer, queuing or rejecting the ones exceeding
+	// its budget.
+	Governor *governor.Governor
 
 	// UnimplementedBERTServer must be embedded to have forward compatible implementations for gRPC.
 	grpcapi.UnimplementedBERTServer
@@ -46,11 +58,20 @@ func (s *Server) StartDefaultServer(address, grpcAddress, tlsCert, tlsKey string
 	mux.HandleFunc("/bert-classify-ui", bertclassification.Handler)
 	mux.HandleFunc("/discriminate", s.DiscriminateHandler)
 	mux.HandleFunc("/predict", s.PredictHandler)
+	mux.HandleFunc("/fill-mask", s.FillMaskHandler)
+	mux.HandleFunc("/coherence", s.CoherenceHandler)
 	mux.HandleFunc("/answer", s.QaHandler)
 	mux.HandleFunc("/tag", s.LabelerHandler)
 	mux.HandleFunc("/classify", s.ClassifyHandler)
 	mux.HandleFunc("/encode", s.SentenceEncoderHandler)
 
+	var handler http.Handler = mux
+	var interceptors []grpc.UnaryServerInterceptor
+	if s.Governor != nil {
+		handler = s.Governor.Handler(mux)
+		interceptors = append(interceptors, s.Governor.UnaryServerInterceptor())
+	}
+
 	go httputils.RunHTTPServer(httputils.HTTPServerConfig{
 		Address:         address,
 		TLSDisable:      tlsDisable,
@@ -58,14 +79,15 @@ func (s *Server) StartDefaultServer(address, grpcAddress, tlsCert, tlsKey string
 		TLSKey:          tlsKey,
 		TimeoutSeconds:  s.TimeoutSeconds,
 		MaxRequestBytes: s.MaxRequestBytes,
-	}, mux)
+	}, handler)
 
 	grpcServer := grpcutils.NewGRPCServer(grpcutils.GRPCServerConfig{
-		TLSDisable:      tlsDisable,
-		TLSCert:         tlsCert,
-		TLSKey:          tlsKey,
-		TimeoutSeconds:  s.TimeoutSeconds,
-		MaxRequestBytes: s.MaxRequestBytes,
+		TLSDisable:        tlsDisable,
+		TLSCert:           tlsCert,
+		TLSKey:            tlsKey,
+		TimeoutSeconds:    s.TimeoutSeconds,
+		MaxRequestBytes:   s.MaxRequestBytes,
+		UnaryInterceptors: interceptors,
 	})
 	grpcapi.RegisterBERTServer(grpcServer, s)
 	grpcutils.RunGRPCServer(grpcAddress, grpcServer)
@@ -82,6 +104,8 @@ type Body struct {
 type QABody struct {
 	Question string `json:"question"`
 	Passage  string `json:"passage"`
+	// Entropy enables the entropy of the candidate answers in the response.
+	Entropy bool `json:"entropy"`
 }
 
 func pad(words []string) []string {
@@ -106,6 +130,9 @@ const DefaultPredictedLabel = "PREDICTED"
 // question-answering server response.
 type QuestionAnsweringResponse struct {
 	Answers Answers `json:"answers"`
+	// Entropy is the entropy of the distribution of the candidate answers, if
+	// requested. The higher, the more ambiguous the answers are.
+	Entropy mat.Float `json:"entropy,omitempty"`
 	// Took is the number of milliseconds it took the server to execute the request.
 	Took int64 `json:"took"`
 }
@@ -123,6 +150,13 @@ type Token struct {
 	Start int    `json:"start"`
 	End   int    `json:"end"`
 	Label string `json:"label"`
+	// Confidence is the probability of the label, if requested.
+	Confidence mat.Float `json:"confidence,omitempty"`
+	// Entropy is the entropy of the distribution of the labels, if requested.
+	// The higher, the more uncertain the label is.
+	Entropy mat.Float `json:"entropy,omitempty"`
+	// Alternatives contains the most likely labels, if requested.
+	Alternatives []ClassConfidencePair `json:"alternatives,omitempty"`
 }
 
 // TokenSlice is a slice of Token elements, which implements the sort.Interface.
@@ -26,11 +26,14 @@ func (s *Server) QaHandler(w http.ResponseWriter, req *http.Request) {
 	}
 
 	start := time.Now()
-	answers := s.model.Answer(body.Question, body.Passage)
+	answers, entropy := s.model.AnswerWithEntropy(body.Question, body.Passage)
 	result := &QuestionAnsweringResponse{
 		Answers: answers,
 		Took:    time.Since(start).Milliseconds(),
 	}
+	if body.Entropy {
+		result.Entropy = entropy
+	}
 
 	_, pretty := req.URL.Query()["pretty"]
 	response, err := Dump(result, pretty)
@@ -33,7 +33,11 @@ func (s *Server) ClassifyHandler(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	result := s.classify(body.Text, body.Text2)
+	result, err := s.classify(body.Text, body.Text2)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	_, pretty := req.URL.Query()["pretty"]
 	response, err := Dump(result, pretty)
 	if err != nil {
@@ -66,7 +70,10 @@ type ClassifyResponse struct {
 // Classify handles a classification request over gRPC.
 // TODO(evanmcclure@gmail.com) Reuse the gRPC message type for HTTP requests.
 func (s *Server) Classify(_ context.Context, req *grpcapi.ClassifyRequest) (*grpcapi.ClassifyReply, error) {
-	result := s.classify(req.GetText(), req.GetText2())
+	result, err := s.classify(req.GetText(), req.GetText2())
+	if err != nil {
+		return nil, err
+	}
 	return classificationFrom(result), nil
 }
 
@@ -87,28 +94,25 @@ func classificationFrom(resp *ClassifyResponse) *grpcapi.ClassifyReply {
 	}
 }
 
-func (s *Server) getTokenized(text, text2 string) []string {
-	cls := wordpiecetokenizer.DefaultClassToken
-	sep := wordpiecetokenizer.DefaultSequenceSeparator
+func (s *Server) getEncoding(text, text2 string) (*tokenizers.Encoding, error) {
 	tokenizer := wordpiecetokenizer.New(s.model.Vocabulary)
-	tokenized := append([]string{cls}, append(tokenizers.GetStrings(tokenizer.Tokenize(text)), sep)...)
-	if text2 != "" {
-		tokenized = append(tokenized, append(tokenizers.GetStrings(tokenizer.Tokenize(text2)), sep)...)
-	}
-	return tokenized
+	return tokenizer.EncodeWithOptions(text, text2, s.model.encodeOptions(tokenizers.LongestFirst))
 }
 
 // TODO: This method is too long; it needs to be refactored.
 // For the textual inference task, text is the premise and text2 is the hypothesis.
-func (s *Server) classify(text string, text2 string) *ClassifyResponse {
+func (s *Server) classify(text string, text2 string) (*ClassifyResponse, error) {
 	start := time.Now()
 
-	tokenized := s.getTokenized(text, text2)
+	encoding, err := s.getEncoding(text, text2)
+	if err != nil {
+		return nil, err
+	}
 
 	g := ag.NewGraph(ag.ConcurrentComputations(runtime.NumCPU()))
 	defer g.Clear()
 	proc := nn.ReifyForInference(s.model, g).(*Model)
-	encoded := proc.Encode(tokenized)
+	encoded := proc.EncodePair(encoding)
 
 	logits := proc.SequenceClassification(encoded)
 	probs := floatutils.SoftMax(logits.Value().Data())
@@ -132,5 +136,5 @@ func (s *Server) classify(text string, text2 string) *ClassifyResponse {
 		Confidence:   probs[best],
 		Distribution: distribution,
 		Took:         time.Since(start).Milliseconds(),
-	}
+	}, nil
 }
@@ -0,0 +1,84 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bert
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+)
+
+// CoherenceBody is the JSON-serializable expected request body for BERT
+// sentence-coherence requests.
+type CoherenceBody struct {
+	// Sentences are scored pairwise, each one with the next one.
+	Sentences []string `json:"sentences"`
+	// Head is the pre-training head: "nsp" (default) or "sop".
+	Head string `json:"head"`
+}
+
+// CoherenceResponse is the JSON-serializable server response for BERT
+// sentence-coherence requests.
+type CoherenceResponse struct {
+	// Scores contains the coherence of each pair of consecutive sentences.
+	Scores []mat.Float `json:"scores"`
+	// Took is the number of milliseconds it took the server to execute the request.
+	Took int64 `json:"took"`
+}
+
+// CoherenceHandler handles a sentence-coherence request over HTTP.
+func (s *Server) CoherenceHandler(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*") // that's intended for testing purposes only
+	w.Header().Set("Content-Type", "application/json")
+
+	var body CoherenceBody
+	err := json.NewDecoder(req.Body).Decode(&body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	start := time.Now()
+	head, err := parseCoherenceHead(body.Head)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	scores, err := s.model.CoherenceScores(body.Sentences, head)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	result := CoherenceResponse{
+		Scores: scores,
+		Took:   time.Since(start).Milliseconds(),
+	}
+	_, pretty := req.URL.Query()["pretty"]
+	response, err := Dump(result, pretty)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	_, err = w.Write(response)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func parseCoherenceHead(name string) (CoherenceHead, error) {
+	switch name {
+	case "", NextSentencePrediction.String():
+		return NextSentencePrediction, nil
+	case SentenceOrderPrediction.String():
+		return SentenceOrderPrediction, nil
+	default:
+		return 0, fmt.Errorf("bert: unknown coherence head %#v", name)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"fmt"
 	mat "github.com/nlpodyssey/spago/pkg/mat32"
 	"github.com/nlpodyssey/spago/pkg/nlp/transformers/bert/grpcapi"
+	"github.com/nlpodyssey/spago/pkg/utils/tracing"
 	"net/http"
 	"time"
 )
@@ -26,7 +27,7 @@ func (s *Server) SentenceEncoderHandler(w http.ResponseWriter, req *http.Request
 		return
 	}
 
-	result, err := s.encode(body.Text, body.PoolingStrategy)
+	result, err := s.encode(req.Context(), body.Text, body.PoolingStrategy)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -54,8 +55,8 @@ type EncodeResponse struct {
 
 // Encode handles an encoding request over gRPC.
 // TODO(evanmcclure@gmail.com) Reuse the gRPC message type for HTTP requests.
-func (s *Server) Encode(_ context.Context, req *grpcapi.EncodeRequest) (*grpcapi.EncodeReply, error) {
-	result, err := s.encode(req.GetText(), req.GetPoolingStrategy())
+func (s *Server) Encode(ctx context.Context, req *grpcapi.EncodeRequest) (*grpcapi.EncodeReply, error) {
+	result, err := s.encode(ctx, req.GetText(), req.GetPoolingStrategy())
 	if err != nil {
 		return nil, err
 	}
@@ -71,16 +72,23 @@ func (s *Server) Encode(_ context.Context, req *grpcapi.EncodeRequest) (*grpcapi
 	}, nil
 }
 
-func (s *Server) encode(text string, poolingStrategy grpcapi.EncodeRequest_PoolingStrategy) (*EncodeResponse, error) {
+func (s *Server) encode(ctx context.Context, text string, poolingStrategy grpcapi.EncodeRequest_PoolingStrategy) (_ *EncodeResponse, err error) {
 	start := time.Now()
+	ctx, span := tracing.Start(ctx, "bert.Encode")
+	defer func() { tracing.EndWithError(span, err) }()
 	ps, err := getPoolingStrategyFromEncodeRequest(poolingStrategy)
 	if err != nil {
 		return nil, err
 	}
-	encoded, err := s.model.Vectorize(text, ps)
+	encoded, err := s.model.VectorizeContext(ctx, text, ps)
 	if err != nil {
 		return nil, err
 	}
+	if s.EmbeddingsPostProcessor != nil {
+		_, span := tracing.Start(ctx, "bert.postprocess")
+		encoded = s.EmbeddingsPostProcessor.Apply(encoded)
+		span.End()
+	}
 	return &EncodeResponse{
 		Data: encoded.Data(),
 		Took: time.Since(start).Milliseconds(),
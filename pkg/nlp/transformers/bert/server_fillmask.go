@@ -0,0 +1,67 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package bert
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// DefaultFillMaskTopK is the default number of candidates of the fill-mask requests.
+const DefaultFillMaskTopK = 5
+
+// FillMaskBody is the JSON-serializable expected request body for BERT fill-mask requests.
+type FillMaskBody struct {
+	Text string `json:"text"`
+	// TopK is the number of candidates for each mask (DefaultFillMaskTopK if zero).
+	TopK int `json:"top_k"`
+}
+
+// FillMaskResponse is the JSON-serializable server response for BERT fill-mask requests.
+type FillMaskResponse struct {
+	Masks []FillMaskResult `json:"masks"`
+	// Took is the number of milliseconds it took the server to execute the request.
+	Took int64 `json:"took"`
+}
+
+// FillMaskHandler handles a fill-mask request over HTTP.
+func (s *Server) FillMaskHandler(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*") // that's intended for testing purposes only
+	w.Header().Set("Content-Type", "application/json")
+
+	var body FillMaskBody
+	err := json.NewDecoder(req.Body).Decode(&body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if body.TopK == 0 {
+		body.TopK = DefaultFillMaskTopK
+	}
+
+	start := time.Now()
+	masks, err := s.model.FillMask(body.Text, body.TopK)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	result := FillMaskResponse{
+		Masks: masks,
+		Took:  time.Since(start).Milliseconds(),
+	}
+	_, pretty := req.URL.Query()["pretty"]
+	response, err := Dump(result, pretty)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	_, err = w.Write(response)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
@@ -23,6 +23,11 @@ import (
 type LabelerOptionsType struct {
 	MergeEntities     bool `json:"mergeEntities"`     // default false
 	FilterNotEntities bool `json:"filterNotEntities"` // default false
+	// Confidence enables the confidence and the entropy of each token, e.g. for
+	// routing the uncertain ones to human review.
+	Confidence bool `json:"confidence"` // default false
+	// Alternatives is the number of most likely labels reported for each token.
+	Alternatives int `json:"alternatives"` // default 0
 }
 
 // TokenClassifierBody provides JSON-serializable parameters for BERT "tag" (labeler) requests.
@@ -43,7 +48,7 @@ func (s *Server) LabelerHandler(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	result := s.label(body.Text, body.Options.MergeEntities, body.Options.FilterNotEntities)
+	result := s.Label(body.Text, body.Options)
 
 	_, pretty := req.URL.Query()["pretty"]
 	response, err := Dump(result, pretty)
@@ -59,8 +64,9 @@ func (s *Server) LabelerHandler(w http.ResponseWriter, req *http.Request) {
 	}
 }
 
+// Label labels the tokens of the text with the token classifier of the model.
 // TODO: This method is too long; it needs to be refactored.
-func (s *Server) label(text string, merge bool, filter bool) *Response {
+func (s *Server) Label(text string, options LabelerOptionsType) *Response {
 	start := time.Now()
 
 	tokenizer := wordpiecetokenizer.New(s.model.Vocabulary)
@@ -88,39 +94,57 @@ func (s *Server) label(text string, merge bool, filter bool) *Response {
 		}
 	}
 
+	labels := s.model.Classifier.Config.Labels
 	retTokens := make([]Token, 0)
 	for i, logits := range proc.TokenClassification(avgEncoded) {
 		probs := floatutils.SoftMax(logits.Value().Data())
 		best := floatutils.ArgMax(probs)
-		retTokens = append(retTokens, Token{
+		token := Token{
 			Text:  groupedTokens[i].String,
 			Start: groupedTokens[i].Offsets.Start,
 			End:   groupedTokens[i].Offsets.End,
-			Label: s.model.Classifier.Config.Labels[best],
-		})
+			Label: labels[best],
+		}
+		if options.Confidence {
+			token.Confidence = probs[best]
+			token.Entropy = floatutils.Entropy(probs)
+		}
+		for _, j := range floatutils.ArgMaxK(probs, options.Alternatives) {
+			token.Alternatives = append(token.Alternatives, ClassConfidencePair{
+				Class:      labels[j],
+				Confidence: probs[j],
+			})
+		}
+		retTokens = append(retTokens, token)
 	}
-	if merge {
+	if options.MergeEntities {
 		retTokens = mergeEntities(text, retTokens)
 	}
-	if filter {
+	if options.FilterNotEntities {
 		retTokens = filterNotEntities(retTokens)
 	}
 	return &Response{Tokens: retTokens, Took: time.Since(start).Milliseconds()}
 }
 
+// The merged entities get the lowest confidence and the highest entropy of
+// their tokens, and the alternatives of their first token.
 // TODO: make sure that the input label sequence is valid
 func mergeEntities(text string, tokens []Token) []Token {
 	newTokens := make([]Token, 0)
 	var buf *tokenizers.StringOffsetsPair
+	var merged Token // holds the confidence of the buffered entity
 	flush := func() {
 		if buf != nil {
 			startOffset := buf.Offsets.Start
 			endOffset := buf.Offsets.End
 			newTokens = append(newTokens, Token{
-				Text:  strings.Trim(string([]rune(text)[startOffset:endOffset]), " "),
-				Start: startOffset,
-				End:   endOffset,
-				Label: buf.String,
+				Text:         strings.Trim(string([]rune(text)[startOffset:endOffset]), " "),
+				Start:        startOffset,
+				End:          endOffset,
+				Label:        buf.String,
+				Confidence:   merged.Confidence,
+				Entropy:      merged.Entropy,
+				Alternatives: merged.Alternatives,
 			})
 		}
 		buf = nil
@@ -139,9 +163,16 @@ func mergeEntities(text string, tokens []Token) []Token {
 					End:   token.End,
 				},
 			}
+			merged = token
 		case 'I':
 			if buf != nil {
 				buf.Offsets.End = token.End
+				if token.Confidence < merged.Confidence {
+					merged.Confidence = token.Confidence
+				}
+				if token.Entropy > merged.Entropy {
+					merged.Entropy = token.Entropy
+				}
 			} else { // same as 'B'
 				buf = &tokenizers.StringOffsetsPair{
 					String: fmt.Sprintf("%s", token.Label[2:]), // copy
@@ -150,6 +181,7 @@ func mergeEntities(text string, tokens []Token) []Token {
 						End:   token.End,
 					},
 				}
+				merged = token
 			}
 		}
 	}
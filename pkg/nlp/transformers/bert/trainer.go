@@ -80,15 +80,18 @@ func (t *Trainer) Train() {
 	})
 }
 
-func (t *Trainer) tokenize(text string) []string {
+func (t *Trainer) tokenize(text string) ([]string, error) {
 	tokenizer := wordpiecetokenizer.New(t.model.Vocabulary)
-	tokenized := append(tokenizers.GetStrings(tokenizer.Tokenize(text)), wordpiecetokenizer.DefaultSequenceSeparator)
-	return append([]string{wordpiecetokenizer.DefaultClassToken}, tokenized...)
+	encoding, err := tokenizer.EncodeWithOptions(text, "", t.model.encodeOptions(tokenizers.DoNotTruncate))
+	if err != nil {
+		return nil, err
+	}
+	return encoding.Tokens, nil
 }
 
 func (t *Trainer) trainPassage(text string) {
-	tokenized := t.tokenize(text)
-	if len(tokenized) > t.model.Embeddings.MaxPositions {
+	tokenized, err := t.tokenize(text)
+	if err != nil {
 		return // skip, sequence too long
 	}
 
@@ -5,12 +5,14 @@
 package bert
 
 import (
+	"context"
 	"fmt"
 	mat "github.com/nlpodyssey/spago/pkg/mat32"
 	"github.com/nlpodyssey/spago/pkg/ml/ag"
 	"github.com/nlpodyssey/spago/pkg/ml/nn"
 	"github.com/nlpodyssey/spago/pkg/nlp/tokenizers"
 	"github.com/nlpodyssey/spago/pkg/nlp/tokenizers/wordpiecetokenizer"
+	"github.com/nlpodyssey/spago/pkg/utils/tracing"
 	"runtime"
 )
 
@@ -30,11 +32,23 @@ const (
 
 // Vectorize transforms the text into a dense vector representation.
 func (m *Model) Vectorize(text string, poolingStrategy PoolingStrategy) (mat.Matrix, error) {
+	return m.VectorizeContext(context.Background(), text, poolingStrategy)
+}
+
+// VectorizeContext is like Vectorize, recording the spans of the tokenization
+// and of the encoding (with the ones of the layers) as children of the span
+// contained in the context (see the tracing package).
+func (m *Model) VectorizeContext(ctx context.Context, text string, poolingStrategy PoolingStrategy) (mat.Matrix, error) {
+	_, span := tracing.Start(ctx, "bert.tokenize")
 	tokenizer := wordpiecetokenizer.New(m.Vocabulary)
 	origTokens := tokenizer.Tokenize(text)
 	tokenized := pad(tokenizers.GetStrings(origTokens))
+	span.SetAttribute("tokens", len(tokenized))
+	span.End()
 
-	g := ag.NewGraph(ag.ConcurrentComputations(runtime.NumCPU()))
+	ctx, span = tracing.Start(ctx, "bert.encode")
+	defer span.End()
+	g := ag.NewGraph(ag.ConcurrentComputations(runtime.NumCPU()), ag.WithTracing(ctx))
 	defer g.Clear()
 	proc := nn.ReifyForInference(m, g).(*Model)
 	encoded := proc.Encode(tokenized)
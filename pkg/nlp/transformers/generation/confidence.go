@@ -0,0 +1,78 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package generation
+
+import (
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/mat32/floatutils"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+)
+
+// TokenScore is the confidence of the model in a generated token.
+type TokenScore struct {
+	// TokenID is the ID of the generated token.
+	TokenID int `json:"token_id"`
+	// Probability is the probability of the token given the previous ones.
+	Probability mat.Float `json:"probability"`
+	// Entropy is the entropy of the distribution of the next token at this
+	// step. The higher, the more uncertain the model is.
+	Entropy mat.Float `json:"entropy"`
+	// Alternatives contains the most likely tokens at this step, if requested.
+	Alternatives []TokenProbability `json:"alternatives,omitempty"`
+}
+
+// TokenProbability is the probability of a candidate token.
+type TokenProbability struct {
+	TokenID     int       `json:"token_id"`
+	Probability mat.Float `json:"probability"`
+}
+
+// ScoreTokens returns the score of each token of a sequence generated from
+// the input IDs (e.g. by Generate), the decoder start token excluded, along
+// with the given number of alternatives for each step.
+//
+// The sequence is decoded again with teacher forcing, so that the probability
+// of each token is the one predicted by the model given the previous ones,
+// regardless of the beam which generated it. The scores don't take into
+// account the bad words and the prefix constraint.
+func (b *Generator) ScoreTokens(inputIDs, outputIDs []int, alternatives int) []TokenScore {
+	if !b.config.IsEncoderDecoder {
+		panic("generator: unsupported architecture")
+	}
+	if len(outputIDs) < 2 {
+		return nil
+	}
+	g := b.model.Graph()
+
+	encodedInput := b.model.Encode(inputIDs)
+	var cache Cache
+	logits := make([]ag.Node, len(outputIDs)-1)
+	for i := range logits {
+		// the decoding of each step depends on the cache of the previous one
+		logits[i], cache = b.model.Decode(encodedInput, outputIDs[:i+1], cache)
+		logits[i] = b.adjustLogitsDuringGeneration(logits[i], i+1)
+	}
+	if !b.config.IncrementalForward {
+		b.performForward()
+	}
+
+	scores := make([]TokenScore, len(logits))
+	for i, x := range logits {
+		probs := floatutils.SoftMax(g.GetCopiedValue(x).Data())
+		tokenID := outputIDs[i+1]
+		scores[i] = TokenScore{
+			TokenID:     tokenID,
+			Probability: probs[tokenID],
+			Entropy:     floatutils.Entropy(probs),
+		}
+		for _, id := range floatutils.ArgMaxK(probs, alternatives) {
+			scores[i].Alternatives = append(scores[i].Alternatives, TokenProbability{
+				TokenID:     id,
+				Probability: probs[id],
+			})
+		}
+	}
+	return scores
+}
@@ -0,0 +1,59 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package generation
+
+import (
+	"math"
+	"testing"
+
+	"github.com/nlpodyssey/spago/pkg/mat32/floatutils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerator_ScoreTokens(t *testing.T) {
+	for _, incrementalForward := range []bool{true, false} {
+		inputIDs := []int{3, 4}
+		outputIDs := newToyGenerator(incrementalForward).Generate(inputIDs)
+		assert.Equal(t, []int{0, 3, 3, 2}, outputIDs)
+
+		scores := newToyGenerator(incrementalForward).ScoreTokens(inputIDs, outputIDs, 2)
+		assert.Len(t, scores, 3)
+
+		for i, score := range scores {
+			expected := toyProbabilities(inputIDs, outputIDs[:i+1])
+			best := floatutils.ArgMax(expected)
+			assert.Equal(t, outputIDs[i+1], score.TokenID)
+			assert.InDelta(t, expected[score.TokenID], score.Probability, 1.0e-6)
+			assert.InDelta(t, floatutils.Entropy(expected), score.Entropy, 1.0e-6)
+			assert.Len(t, score.Alternatives, 2)
+			assert.Equal(t, best, score.Alternatives[0].TokenID)
+			assert.InDelta(t, expected[best], score.Alternatives[0].Probability, 1.0e-6)
+			assert.True(t, score.Alternatives[1].Probability <= score.Alternatives[0].Probability)
+		}
+	}
+}
+
+func TestGenerator_ScoreTokensWithoutTokens(t *testing.T) {
+	assert.Nil(t, newToyGenerator(true).ScoreTokens([]int{3}, []int{0}, 1))
+}
+
+// toyProbabilities returns the distribution of the next token predicted by
+// the toyModel, the pad token excluded.
+func toyProbabilities(inputIDs, decodingInputIDs []int) []float32 {
+	var sum int
+	for _, id := range inputIDs {
+		sum += id
+	}
+	target := (sum + len(decodingInputIDs)) % toyVocabSize
+	if len(decodingInputIDs) > len(inputIDs) {
+		target = 2 // EOS
+	}
+	logits := make([]float32, toyVocabSize)
+	for k := range logits {
+		logits[k] = -float32(math.Abs(float64(k - target)))
+	}
+	logits[1] = float32(math.Inf(-1)) // pad
+	return floatutils.SoftMax(logits)
+}
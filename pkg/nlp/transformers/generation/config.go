@@ -39,9 +39,18 @@ type GeneratorConfig struct {
 	EarlyStopping bool
 	// BadWordsIDs is a list of token IDs that are not allowed to be generated.
 	BadWordsIDs [][]int
+	// PrefixConstraint, if not nil, restricts the tokens that can be generated
+	// after each prefix (see PrefixTrie).
+	PrefixConstraint PrefixConstraint
 	// MaxConcurrentComputations is the maximum number of concurrent computations
 	// handled by the generation search algorithm.
 	MaxConcurrentComputations int
 	// IncrementalForward indicates the graph usage mode.
 	IncrementalForward bool
+	// IncrementalSessionEncoding enables GenerateInSession to encode an input
+	// extending the one of the session only from the new input IDs, if the
+	// model implements IncrementalEncoder. Since the earlier inputs don't
+	// attend to the new ones, the output only approximates the one of
+	// Generate. By default, the state is reused only for the same input.
+	IncrementalSessionEncoding bool
 }
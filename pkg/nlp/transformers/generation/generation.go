@@ -48,71 +48,326 @@ func (b *Generator) Generate(inputIDs []int) []int {
 		b.performForward()
 	}
 
-	return b.beamSearch(NewScorer(b.config), encodedInput)
-}
-
-func (b *Generator) beamSearch(scorer *Scorer, encodedInput []ag.Node) []int {
-	var (
-		numBeams         = b.config.NumBeams
-		beamScores       = b.makeInitBeamScores()
-		decodingInputIDs = b.makeStartDecodingInputForBeamDecoding()
-		scores           = make([]Scores, numBeams)
-		cache            = make([]Cache, numBeams)
-		curLen           = len(decodingInputIDs[0])
-	)
-
-	for curLen < b.config.MaxLength {
-		scores, cache = b.generateNext(encodedInput, decodingInputIDs, cache)
-		nextTokenScores := b.inhibitInvalidTokens(decodingInputIDs, scores)
-		updateTokensScores(nextTokenScores, beamScores)
-		scoredTokens := b.getTopKScoredTokens(nextTokenScores)
-		beamOutputs := scorer.Process(decodingInputIDs, scoredTokens)
-		beamScores = beamOutputs.nextBeamScores
-		decodingInputIDs = makeNewInputIDs(decodingInputIDs, beamOutputs)
-		cache = reorderCache(cache, beamOutputs.nextBeamIndices)
-
-		if scorer.IsDone() {
-			break
+	return b.beamSearch(NewScorer(b.config), encodedInput, nil, nil)
+}
+
+// GenerateInSession is like Generate, but it reuses the state retained by the
+// session, and retains the new one. If the input IDs are the same of the
+// state, the encoder is skipped, and so is the first decoding step if the
+// model implements StatefulDecoder; otherwise the input is encoded anew.
+//
+// With IncrementalSessionEncoding, if the model implements IncrementalEncoder,
+// an input extending the one of the state (e.g. the history of a chat with a
+// new turn, even if the EOS token which ended the previous input is no longer
+// the last) is encoded only from the new input IDs, which attend to the
+// earlier ones, while the earlier ones, not encoded anew, don't attend to the
+// new ones. A final EOS token is thus encoded apart, so that the next input
+// can extend the state.
+func (b *Generator) GenerateInSession(inputIDs []int, sessions *SessionCache, sessionID string) []int {
+	return b.generateInSession(inputIDs, sessions, sessionID, b.config.IncrementalSessionEncoding)
+}
+
+// GenerateCached is like Generate, but it retains the state of the inputs in
+// the cache, keyed by their InputKey, so that the encoder is skipped entirely
+// when an input is generated again (e.g. the same retrieved context of
+// different requests).
+func (b *Generator) GenerateCached(inputIDs []int, cache *SessionCache) []int {
+	return b.generateInSession(inputIDs, cache, InputKey(inputIDs), false)
+}
+
+func (b *Generator) generateInSession(inputIDs []int, sessions *SessionCache, sessionID string, incremental bool) []int {
+	if !b.config.IsEncoderDecoder {
+		panic("generator: unsupported architecture")
+	}
+	statefulDecoder, isStateful := b.model.(StatefulDecoder)
+	incrementalEncoder, isIncremental := b.model.(IncrementalEncoder)
+	isIncremental = isIncremental && incremental
+
+	state, ok := sessions.Get(sessionID)
+	if ok && equalIDs(state.InputIDs, inputIDs) {
+		s := b.newSearchState(NewScorer(b.config), b.importEncoded(state.Encoded), nil)
+		if isStateful && state.Cache != nil && state.FirstScores != nil {
+			initialCache := statefulDecoder.ImportCache(state.Cache)
+			for i := range s.cache {
+				s.cache[i] = initialCache
+			}
+			s.firstScores = state.FirstScores
+		}
+		b.search([]*searchState{s})
+		return s.result
+	}
+
+	var encodedInput []ag.Node
+	var encoderCache Cache
+	var encoderCacheLength int
+	switch {
+	case isIncremental && ok && state.EncoderCache != nil && hasPrefix(inputIDs, state.InputIDs[:state.EncoderCacheLength]):
+		n := state.EncoderCacheLength
+		pastCache := incrementalEncoder.ImportEncoderCache(state.EncoderCache)
+		encodedSuffix, cache, length := b.encodeIncremental(incrementalEncoder, inputIDs[n:], pastCache)
+		encodedInput = append(b.importEncoded(state.Encoded[:n]), encodedSuffix...)
+		encoderCache, encoderCacheLength = cache, n+length
+	case isIncremental:
+		encodedInput, encoderCache, encoderCacheLength = b.encodeIncremental(incrementalEncoder, inputIDs, nil)
+	default:
+		encodedInput = b.model.Encode(inputIDs)
+	}
+	if !b.config.IncrementalForward {
+		b.performForward()
+	}
+
+	g := b.model.Graph()
+	newState := &SessionState{
+		InputIDs: append([]int(nil), inputIDs...),
+		Encoded:  make([]mat.Matrix, len(encodedInput)),
+	}
+	for i, x := range encodedInput {
+		newState.Encoded[i] = g.GetCopiedValue(x)
+	}
+	if encoderCache != nil {
+		newState.EncoderCache, newState.EncoderCacheSize = incrementalEncoder.ExportEncoderCache(encoderCache)
+		newState.EncoderCacheLength = encoderCacheLength
+	}
+	return b.beamSearch(NewScorer(b.config), encodedInput, nil, func(cache Cache, scores Scores) {
+		if isStateful {
+			newState.Cache, newState.CacheSize = statefulDecoder.ExportCache(cache)
+			newState.FirstScores = scores
+		}
+		sessions.Put(sessionID, newState)
+	})
+}
+
+// encodeIncremental encodes the input IDs following the ones of the past
+// cache. A final EOS token is encoded apart, so that the returned cache, and
+// its length, cover only the input IDs before it.
+func (b *Generator) encodeIncremental(encoder IncrementalEncoder, inputIDs []int, pastCache Cache) ([]ag.Node, Cache, int) {
+	n := len(inputIDs)
+	if n == 0 {
+		return nil, pastCache, 0
+	}
+	if inputIDs[n-1] != b.config.EOSTokenID {
+		encoded, cache := encoder.EncodeIncremental(inputIDs, pastCache)
+		return encoded, cache, n
+	}
+	var encoded []ag.Node
+	cache := pastCache
+	if n > 1 {
+		encoded, cache = encoder.EncodeIncremental(inputIDs[:n-1], pastCache)
+	}
+	eos, _ := encoder.EncodeIncremental(inputIDs[n-1:], cache)
+	return append(encoded, eos...), cache, n - 1
+}
+
+// importEncoded returns the encoded input restored from the values retained
+// by a session.
+func (b *Generator) importEncoded(values []mat.Matrix) []ag.Node {
+	g := b.model.Graph()
+	encoded := make([]ag.Node, len(values))
+	for i, value := range values {
+		encoded[i] = g.NewVariable(value.Clone(), false)
+	}
+	return encoded
+}
+
+func equalIDs(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i, id := range a {
+		if b[i] != id {
+			return false
 		}
-		curLen++
 	}
+	return true
+}
 
-	return scorer.Finalize(decodingInputIDs, beamScores)
+func hasPrefix(ids, prefix []int) bool {
+	return len(ids) >= len(prefix) && equalIDs(ids[:len(prefix)], prefix)
 }
 
-func (b *Generator) generateNext(
+// beamSearch performs the generation search. The initialCache, if not nil, is
+// used by every beam at the first step, whose resulting cache and scores (of
+// the first beam) are passed to onFirstStep, if not nil.
+func (b *Generator) beamSearch(
+	scorer *Scorer,
 	encodedInput []ag.Node,
-	decodingInputIDs [][]int,
-	pastCache []Cache,
-) ([]Scores, []Cache) {
-	numBeams := b.config.NumBeams
-	logProbs := make([]ag.Node, numBeams)
-	logits := make([]ag.Node, numBeams)
-	scores := make([]Scores, numBeams)
-	nextCache := make([]Cache, numBeams)
+	initialCache Cache,
+	onFirstStep func(cache Cache, scores Scores),
+) []int {
+	state := b.newSearchState(scorer, encodedInput, initialCache)
+	state.onFirstStep = onFirstStep
+	b.search([]*searchState{state})
+	return state.result
+}
 
+// GenerateBatch generates a sequence for each input, like Generate.
+// The sequences are decoded in lockstep on the same graph: at each step, the
+// next tokens of the beams of all the unfinished sequences are predicted
+// concurrently, and their forward is computed at once; the finished sequences
+// leave the batch. Since each sequence is a list of nodes, the inputs of
+// different lengths don't need any padding or mask.
+// The results are returned in the same order of the inputs.
+func (b *Generator) GenerateBatch(batch [][]int) [][]int {
+	if !b.config.IsEncoderDecoder {
+		panic("generator: unsupported architecture")
+	}
+	encodedInputs := make([][]ag.Node, len(batch))
 	var wg sync.WaitGroup
-	wg.Add(numBeams)
-	for i := 0; i < numBeams; i++ {
-		i := i // redefine `i` in the inner scope, for using it in the goroutine
+	wg.Add(len(batch))
+	for i, inputIDs := range batch {
+		i, inputIDs := i, inputIDs
 		b.processingQueue.Go(func() {
 			defer wg.Done()
-			logits[i], nextCache[i] = b.model.Decode(encodedInput, decodingInputIDs[i], pastCache[i])
-			logits[i] = b.adjustLogitsDuringGeneration(logits[i], len(decodingInputIDs[i]))
-			logProbs[i] = b.model.Graph().LogSoftmax(logits[i])
+			encodedInputs[i] = b.model.Encode(inputIDs)
 		})
 	}
 	wg.Wait()
-
 	if !b.config.IncrementalForward {
 		b.performForward()
 	}
 
-	for i := 0; i < numBeams; i++ {
-		scores[i] = b.model.Graph().GetCopiedValue(logProbs[i])
+	states := make([]*searchState, len(batch))
+	for i, encodedInput := range encodedInputs {
+		states[i] = b.newSearchState(NewScorer(b.config), encodedInput, nil)
+	}
+	b.search(states)
+
+	results := make([][]int, len(states))
+	for i, state := range states {
+		results[i] = state.result
+	}
+	return results
+}
+
+// searchState is the state of the generation search of a sequence.
+type searchState struct {
+	scorer           *Scorer
+	encodedInput     []ag.Node
+	beamScores       []mat.Float
+	decodingInputIDs [][]int
+	cache            []Cache
+	curLen           int
+	done             bool
+	onFirstStep      func(cache Cache, scores Scores)
+	// firstScores, if not nil, are the scores of the first decoding step,
+	// which is skipped since the cache is already the resulting one.
+	firstScores Scores
+	// result is the generated sequence, set when the search is done.
+	result []int
+}
+
+func (b *Generator) newSearchState(scorer *Scorer, encodedInput []ag.Node, initialCache Cache) *searchState {
+	s := &searchState{
+		scorer:           scorer,
+		encodedInput:     encodedInput,
+		beamScores:       b.makeInitBeamScores(),
+		decodingInputIDs: b.makeStartDecodingInputForBeamDecoding(),
+		cache:            make([]Cache, b.config.NumBeams),
+	}
+	s.curLen = len(s.decodingInputIDs[0])
+	for i := range s.cache {
+		s.cache[i] = initialCache
+	}
+	return s
+}
+
+// search performs the generation search of the sequences in lockstep.
+func (b *Generator) search(states []*searchState) {
+	for _, s := range states {
+		if s.curLen >= b.config.MaxLength {
+			b.finalize(s)
+		}
 	}
+	for {
+		active := make([]*searchState, 0, len(states))
+		for _, s := range states {
+			if !s.done {
+				active = append(active, s)
+			}
+		}
+		if len(active) == 0 {
+			return
+		}
+		scores := b.generateNext(active)
+		for i, s := range active {
+			b.update(s, scores[i])
+		}
+	}
+}
 
-	return scores, nextCache
+// update advances the search with the scores of the next tokens.
+func (b *Generator) update(s *searchState, scores []Scores) {
+	if s.onFirstStep != nil {
+		s.onFirstStep(s.cache[0], scores[0].Clone())
+		s.onFirstStep = nil
+	}
+	nextTokenScores := b.inhibitInvalidTokens(s.decodingInputIDs, scores)
+	updateTokensScores(nextTokenScores, s.beamScores)
+	scoredTokens := b.getTopKScoredTokens(nextTokenScores)
+	beamOutputs := s.scorer.Process(s.decodingInputIDs, scoredTokens)
+	s.beamScores = beamOutputs.nextBeamScores
+	s.decodingInputIDs = makeNewInputIDs(s.decodingInputIDs, beamOutputs)
+	s.cache = reorderCache(s.cache, beamOutputs.nextBeamIndices)
+
+	s.curLen++
+	if s.scorer.IsDone() || s.curLen >= b.config.MaxLength {
+		b.finalize(s)
+	}
+}
+
+func (b *Generator) finalize(s *searchState) {
+	s.result = s.scorer.Finalize(s.decodingInputIDs, s.beamScores)
+	s.done = true
+}
+
+// generateNext decodes the next token of each beam of the sequences, setting
+// their next cache, and returns the scores of each beam of each sequence.
+func (b *Generator) generateNext(states []*searchState) [][]Scores {
+	numBeams := b.config.NumBeams
+	logProbs := make([][]ag.Node, len(states))
+
+	var wg sync.WaitGroup
+	for si, s := range states {
+		if s.firstScores != nil {
+			continue
+		}
+		encodedInput, decodingInputIDs, pastCache := s.encodedInput, s.decodingInputIDs, s.cache
+		logProbs[si] = make([]ag.Node, numBeams)
+		nextCache := make([]Cache, numBeams)
+		wg.Add(numBeams)
+		for i := 0; i < numBeams; i++ {
+			i, logProbs := i, logProbs[si] // redefine the variables in the inner scope, for using them in the goroutine
+			b.processingQueue.Go(func() {
+				defer wg.Done()
+				var logits ag.Node
+				logits, nextCache[i] = b.model.Decode(encodedInput, decodingInputIDs[i], pastCache[i])
+				logits = b.adjustLogitsDuringGeneration(logits, len(decodingInputIDs[i]))
+				logProbs[i] = b.model.Graph().LogSoftmax(logits)
+			})
+		}
+		s.cache = nextCache
+	}
+	wg.Wait()
+
+	if !b.config.IncrementalForward {
+		b.performForward()
+	}
+
+	scores := make([][]Scores, len(states))
+	for si, s := range states {
+		scores[si] = make([]Scores, numBeams)
+		if s.firstScores != nil {
+			for i := range scores[si] {
+				scores[si][i] = s.firstScores.Clone()
+			}
+			s.firstScores = nil
+			continue
+		}
+		for i, x := range logProbs[si] {
+			scores[si][i] = b.model.Graph().GetCopiedValue(x)
+		}
+	}
+	return scores
 }
 
 func (b *Generator) performForward() {
@@ -0,0 +1,207 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package generation
+
+import (
+	"sync/atomic"
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/stretchr/testify/assert"
+)
+
+const toyVocabSize = 6
+
+// toyModel is an EncoderDecoder predicting the token (sum of the input IDs +
+// length of the decoded sequence) mod toyVocabSize, and the EOS token
+// after as many tokens as the input IDs.
+type toyModel struct {
+	g         *ag.Graph
+	encodings int
+}
+
+func (m *toyModel) Graph() *ag.Graph {
+	return m.g
+}
+
+func (m *toyModel) Encode(inputIDs []int) []ag.Node {
+	m.encodings++
+	ys := make([]ag.Node, len(inputIDs))
+	for i, id := range inputIDs {
+		ys[i] = m.g.NewScalar(mat.Float(id))
+	}
+	return ys
+}
+
+func (m *toyModel) Decode(encodedInput []ag.Node, decodingInputIDs []int, _ Cache) (ag.Node, Cache) {
+	var sum int
+	for _, x := range encodedInput {
+		sum += int(x.ScalarValue())
+	}
+	target := (sum + len(decodingInputIDs)) % toyVocabSize
+	if len(decodingInputIDs) > len(encodedInput) {
+		target = 2 // EOS
+	}
+	logits := make([]mat.Float, toyVocabSize)
+	for k := range logits {
+		logits[k] = -mat.Abs(mat.Float(k - target))
+	}
+	return m.g.NewVariable(mat.NewVecDense(logits), false), nil
+}
+
+func newToyGenerator(incrementalForward bool) *Generator {
+	model := &toyModel{g: ag.NewGraph(ag.IncrementalForward(incrementalForward))}
+	return NewGenerator(GeneratorConfig{
+		NumBeams:                  2,
+		MaxLength:                 8,
+		IsEncoderDecoder:          true,
+		EOSTokenID:                2,
+		PadTokenID:                1,
+		VocabSize:                 toyVocabSize,
+		DecoderStartTokenID:       0,
+		LengthPenalty:             1,
+		MaxConcurrentComputations: 2,
+		IncrementalForward:        incrementalForward,
+	}, model)
+}
+
+// toySessionModel is a toyModel implementing IncrementalEncoder, recording
+// the encoded input IDs, and StatefulDecoder, counting the first decoding
+// steps. Its caches are the numbers of encoded and decoded IDs.
+type toySessionModel struct {
+	*toyModel
+	encoded    []int
+	firstSteps int32
+}
+
+func (m *toySessionModel) EncodeIncremental(inputIDs []int, pastCache Cache) ([]ag.Node, Cache) {
+	m.encoded = append(m.encoded, inputIDs...)
+	pastLength, _ := pastCache.(int)
+	return m.Encode(inputIDs), pastLength + len(inputIDs)
+}
+
+func (m *toySessionModel) ExportEncoderCache(cache Cache) (interface{}, int) {
+	return cache, 8
+}
+
+func (m *toySessionModel) ImportEncoderCache(state interface{}) Cache {
+	return state
+}
+
+func (m *toySessionModel) Decode(encodedInput []ag.Node, decodingInputIDs []int, pastCache Cache) (ag.Node, Cache) {
+	if len(decodingInputIDs) == 1 {
+		atomic.AddInt32(&m.firstSteps, 1)
+	} else if pastCache != len(decodingInputIDs)-1 {
+		panic("toy: unexpected cache")
+	}
+	logits, _ := m.toyModel.Decode(encodedInput, decodingInputIDs, nil)
+	return logits, len(decodingInputIDs)
+}
+
+func (m *toySessionModel) ExportCache(cache Cache) (interface{}, int) {
+	return cache, 8
+}
+
+func (m *toySessionModel) ImportCache(state interface{}) Cache {
+	return state
+}
+
+func TestGenerator_GenerateBatch(t *testing.T) {
+	batch := [][]int{{3, 4}, {5}, {1, 1, 3, 4, 5, 5, 5, 5, 5}, {4, 4, 0}}
+	for _, incrementalForward := range []bool{true, false} {
+		expected := make([][]int, len(batch))
+		for i, inputIDs := range batch {
+			expected[i] = newToyGenerator(incrementalForward).Generate(inputIDs)
+		}
+		assert.Equal(t, []int{0, 3, 3, 2}, expected[0])
+
+		actual := newToyGenerator(incrementalForward).GenerateBatch(batch)
+		assert.Equal(t, expected, actual)
+	}
+}
+
+func TestGenerator_GenerateCached(t *testing.T) {
+	for _, incrementalForward := range []bool{true, false} {
+		cache := NewSessionCache(0, 0)
+		expected := newToyGenerator(incrementalForward).Generate([]int{3, 4})
+
+		generator := newToyGenerator(incrementalForward)
+		model := generator.model.(*toyModel)
+		assert.Equal(t, expected, generator.GenerateCached([]int{3, 4}, cache))
+		assert.Equal(t, 1, model.encodings)
+		assert.Equal(t, expected, generator.GenerateCached([]int{3, 4}, cache))
+		assert.Equal(t, 1, model.encodings)
+
+		generator.GenerateCached([]int{5}, cache)
+		assert.Equal(t, 2, model.encodings)
+		assert.Equal(t, 2, cache.Len())
+	}
+}
+
+func TestGenerator_GenerateInSession(t *testing.T) {
+	for _, incrementalForward := range []bool{true, false} {
+		sessions := NewSessionCache(0, 0)
+		generator := newToyGenerator(incrementalForward)
+		model := &toySessionModel{toyModel: generator.model.(*toyModel)}
+		generator.model = model
+
+		turns := [][]int{
+			{3, 4, 2},
+			{3, 4, 5, 2},
+			{3, 4, 5, 2},
+		}
+		encodings := []int{1, 2, 2}
+		firstSteps := []int32{2, 4, 4}
+		for i, inputIDs := range turns {
+			expected := newToyGenerator(incrementalForward).Generate(inputIDs)
+			assert.Equal(t, expected, generator.GenerateInSession(inputIDs, sessions, "chat"), "turn %d", i)
+			assert.Equal(t, encodings[i], model.encodings, "turn %d", i)
+			assert.Equal(t, firstSteps[i], model.firstSteps, "turn %d", i)
+		}
+		assert.Empty(t, model.encoded) // the whole input is encoded anew
+
+		state, _ := sessions.Get("chat")
+		assert.Nil(t, state.EncoderCache)
+		assert.Equal(t, 1, state.Cache)
+	}
+}
+
+func TestGenerator_GenerateInSession_IncrementalEncoding(t *testing.T) {
+	for _, incrementalForward := range []bool{true, false} {
+		sessions := NewSessionCache(0, 0)
+		generator := newToyGenerator(incrementalForward)
+		generator.config.IncrementalSessionEncoding = true
+		model := &toySessionModel{toyModel: generator.model.(*toyModel)}
+		generator.model = model
+
+		turns := [][]int{
+			{3, 4, 2},
+			{3, 4, 5, 2},
+			{3, 4, 5, 2},
+			{3, 4, 5, 3},
+		}
+		encoded := [][]int{
+			{3, 4, 2},
+			{3, 4, 2, 5, 2},
+			{3, 4, 2, 5, 2},
+			{3, 4, 2, 5, 2, 3},
+		}
+		firstSteps := []int32{2, 4, 4, 6}
+		for i, inputIDs := range turns {
+			expected := newToyGenerator(incrementalForward).Generate(inputIDs)
+			assert.Equal(t, expected, generator.GenerateInSession(inputIDs, sessions, "chat"), "turn %d", i)
+			assert.Equal(t, encoded[i], model.encoded, "turn %d", i)
+			assert.Equal(t, firstSteps[i], model.firstSteps, "turn %d", i)
+		}
+
+		state, _ := sessions.Get("chat")
+		assert.Equal(t, 4, state.EncoderCache)
+		assert.Equal(t, 4, state.EncoderCacheLength)
+		assert.Equal(t, 1, state.Cache)
+		assert.NotNil(t, state.FirstScores)
+		assert.Equal(t, 1, sessions.Len())
+	}
+}
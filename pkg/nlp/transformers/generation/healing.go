@@ -0,0 +1,54 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package generation
+
+import "strings"
+
+var _ PrefixConstraint = &TokenHealing{}
+
+// TokenHealing is a PrefixConstraint implementing the token healing at the
+// boundary between a prompt and the generated text: the last token of the
+// prompt is removed, and the first generated token is restricted to the ones
+// starting with its text, so that the model can choose a better tokenization
+// (e.g. "http" + ":" + "//" instead of "http" + "://").
+type TokenHealing struct {
+	// Prefix is the text of the removed token.
+	Prefix string
+	// allowed contains the IDs of the tokens starting with Prefix.
+	allowed []int
+}
+
+// HealPrompt removes the last token from the prompt, returning the remaining
+// IDs and the TokenHealing constraint for the generation. The terms contain
+// the text of each token of the vocabulary, indexed by ID.
+// The prompt is returned unchanged, with a nil constraint, if it is empty.
+func HealPrompt(promptIDs []int, terms []string) ([]int, *TokenHealing) {
+	if len(promptIDs) == 0 {
+		return promptIDs, nil
+	}
+	last := promptIDs[len(promptIDs)-1]
+	return promptIDs[:len(promptIDs)-1], NewTokenHealing(terms[last], terms)
+}
+
+// NewTokenHealing returns a new TokenHealing constraint allowing as first
+// token the ones whose term starts with the given prefix.
+func NewTokenHealing(prefix string, terms []string) *TokenHealing {
+	allowed := make([]int, 0)
+	for id, term := range terms {
+		if strings.HasPrefix(term, prefix) {
+			allowed = append(allowed, id)
+		}
+	}
+	return &TokenHealing{Prefix: prefix, allowed: allowed}
+}
+
+// AllowedTokens returns the tokens starting with Prefix for the first
+// generated token, and nil (no restriction) afterwards.
+func (h *TokenHealing) AllowedTokens(prefix []int) []int {
+	if len(prefix) > 0 {
+		return nil
+	}
+	return h.allowed
+}
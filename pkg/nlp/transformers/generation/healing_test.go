@@ -0,0 +1,24 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package generation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealPrompt(t *testing.T) {
+	terms := []string{"http", ":", "://", "//", "https", "ftp"}
+	prompt, healing := HealPrompt([]int{5, 0}, terms)
+	assert.Equal(t, []int{5}, prompt)
+	assert.Equal(t, "http", healing.Prefix)
+	assert.Equal(t, []int{0, 4}, healing.AllowedTokens(nil))
+	assert.Nil(t, healing.AllowedTokens([]int{4}))
+
+	prompt, healing = HealPrompt(nil, terms)
+	assert.Empty(t, prompt)
+	assert.Nil(t, healing)
+}
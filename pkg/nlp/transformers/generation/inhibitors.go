@@ -18,6 +18,31 @@ func (b *Generator) inhibitInvalidTokens(inputIDs [][]int, scores []Scores) []Sc
 	if len(b.config.BadWordsIDs) > 0 {
 		scores = b.processBadWordsScores(inputIDs, scores)
 	}
+	if b.config.PrefixConstraint != nil {
+		scores = b.processPrefixConstraintScores(inputIDs, scores)
+	}
+	return scores
+}
+
+func (b *Generator) processPrefixConstraintScores(inputIDs [][]int, scores []Scores) []Scores {
+	for idx, slice := range inputIDs {
+		// the first token is the decoder start token
+		allowed := b.config.PrefixConstraint.AllowedTokens(slice[1:])
+		if allowed == nil {
+			continue
+		}
+		data := scores[idx].Data()
+		allowedScores := make([]mat.Float, len(allowed))
+		for i, tokenID := range allowed {
+			allowedScores[i] = data[tokenID]
+		}
+		for i := range data {
+			data[i] = mat.Inf(-1)
+		}
+		for i, tokenID := range allowed {
+			data[tokenID] = allowedScores[i]
+		}
+	}
 	return scores
 }
 
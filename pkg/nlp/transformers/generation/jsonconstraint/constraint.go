@@ -0,0 +1,104 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonconstraint
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/nlpodyssey/spago/pkg/nlp/transformers/generation"
+)
+
+var _ generation.PrefixConstraint = &Constraint{}
+
+// Constraint is a generation.PrefixConstraint restricting the generated
+// tokens to a compact JSON document matching a Schema.
+//
+// The matching state of each prefix is cached, so that the cost of each
+// generation step is independent of the length of the prefix; it is safe for
+// concurrent use.
+type Constraint struct {
+	schema *Schema
+	// terms contains the decoded text of each token.
+	terms      []string
+	endTokenID int
+	mu         sync.Mutex
+	states     map[string]*Matcher
+}
+
+// New returns a new Constraint for the given schema. The terms contain the
+// decoded text of each token of the vocabulary, indexed by ID; the tokens
+// decoding to an empty text are never allowed. The endTokenID (usually the
+// End-Of-Sequence token) is allowed only once the document is complete, and
+// it is the only allowed token after a closed object, array or string.
+func New(schema *Schema, terms []string, endTokenID int) *Constraint {
+	return &Constraint{
+		schema:     schema,
+		terms:      terms,
+		endTokenID: endTokenID,
+		states:     map[string]*Matcher{"": NewMatcher(schema)},
+	}
+}
+
+// AllowedTokens returns the IDs of the tokens allowed after the prefix.
+func (c *Constraint) AllowedTokens(prefix []int) []int {
+	m := c.matcher(prefix)
+	if m == nil {
+		return []int{}
+	}
+	if m.Done() {
+		return []int{c.endTokenID}
+	}
+	allowed := make([]int, 0)
+	for id, term := range c.terms {
+		if id == c.endTokenID || term == "" {
+			continue
+		}
+		if m.Clone().Feed(term) {
+			allowed = append(allowed, id)
+		}
+	}
+	if m.Complete() {
+		allowed = append(allowed, c.endTokenID)
+	}
+	return allowed
+}
+
+// matcher returns the Matcher after the prefix, or nil if the prefix is not
+// valid. The Matcher must not be modified.
+func (c *Constraint) matcher(prefix []int) *Matcher {
+	key := prefixKey(prefix)
+	c.mu.Lock()
+	m, ok := c.states[key]
+	c.mu.Unlock()
+	if ok {
+		return m
+	}
+	if len(prefix) == 0 {
+		return nil
+	}
+	last := prefix[len(prefix)-1]
+	parent := c.matcher(prefix[:len(prefix)-1])
+	if parent != nil && last >= 0 && last < len(c.terms) && last != c.endTokenID && c.terms[last] != "" {
+		m = parent.Clone()
+		if !m.Feed(c.terms[last]) {
+			m = nil
+		}
+	}
+	c.mu.Lock()
+	c.states[key] = m
+	c.mu.Unlock()
+	return m
+}
+
+func prefixKey(prefix []int) string {
+	var sb strings.Builder
+	for _, id := range prefix {
+		sb.WriteString(strconv.Itoa(id))
+		sb.WriteByte(',')
+	}
+	return sb.String()
+}
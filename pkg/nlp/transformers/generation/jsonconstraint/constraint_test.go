@@ -0,0 +1,40 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonconstraint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConstraint(t *testing.T) {
+	s, err := ParseSchema([]byte(`{
+		"type": "object",
+		"properties": {"ok": {"type": "boolean"}},
+		"required": ["ok"]
+	}`))
+	require.NoError(t, err)
+	terms := []string{"</s>", `{"`, "ok", `":`, "true", "false", "}", "1", ""}
+	c := New(s, terms, 0)
+
+	assert.Equal(t, []int{1}, c.AllowedTokens(nil))
+	assert.Equal(t, []int{2}, c.AllowedTokens([]int{1}))
+	assert.Equal(t, []int{3}, c.AllowedTokens([]int{1, 2}))
+	assert.Equal(t, []int{4, 5}, c.AllowedTokens([]int{1, 2, 3}))
+	assert.Equal(t, []int{6}, c.AllowedTokens([]int{1, 2, 3, 5}))
+	assert.Equal(t, []int{0}, c.AllowedTokens([]int{1, 2, 3, 5, 6}))
+	assert.Equal(t, []int{}, c.AllowedTokens([]int{7}))
+	assert.Equal(t, []int{}, c.AllowedTokens([]int{1, 2, 3, 5, 6, 0}))
+}
+
+func TestConstraintNumber(t *testing.T) {
+	terms := []string{"</s>", "1", "2", ".", "a"}
+	c := New(&Schema{Type: "integer"}, terms, 0)
+	assert.Equal(t, []int{1, 2}, c.AllowedTokens(nil))
+	assert.Equal(t, []int{1, 2, 0}, c.AllowedTokens([]int{1}))
+	assert.Equal(t, []int{1, 2, 0}, c.AllowedTokens([]int{1, 2}))
+}
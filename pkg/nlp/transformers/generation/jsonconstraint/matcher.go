@@ -0,0 +1,483 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonconstraint
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+type frameKind int
+
+const (
+	valueFrame frameKind = iota
+	objectFrame
+	arrayFrame
+	stringFrame
+	numberFrame
+	literalFrame
+)
+
+// states of the object frames
+const (
+	objStart      = iota // after "{"
+	objInKey             // reading a key
+	objKeyDone           // after a key, expecting ":"
+	objInValue           // reading a value
+	objAfterValue        // after a value, expecting "," or "}"
+	objAfterComma        // after ",", expecting a key
+)
+
+// states of the array frames
+const (
+	arrStart      = iota // after "["
+	arrInValue           // reading a value
+	arrAfterValue        // after a value, expecting "," or "]"
+	arrAfterComma        // after ",", expecting a value
+)
+
+// states of the string frames
+const (
+	strNormal = iota
+	strEscape
+	strUnicode // reading the hexadecimal digits of a "\u" escape
+)
+
+// states of the number frames
+const (
+	numStart     = iota
+	numMinus     // after "-"
+	numZero      // after a leading "0" (terminal)
+	numInt       // integer digits (terminal)
+	numDot       // after "."
+	numFrac      // fraction digits (terminal)
+	numExp       // after "e"
+	numExpSign   // after the sign of the exponent
+	numExpDigits // exponent digits (terminal)
+)
+
+// frame is an element of the stack of the Matcher.
+type frame struct {
+	kind   frameKind
+	schema *Schema
+	state  int
+	// next is the index of the first property of an object not yet generated.
+	next int
+	// key is the last key read by an object.
+	key string
+	// candidates contains the allowed contents of a string (JSON-escaped),
+	// or nil if any content is allowed.
+	candidates []string
+	// buf is the content of a string read so far, if there are candidates.
+	buf string
+	// hex counts the hexadecimal digits of a "\u" escape.
+	hex int
+	// integer reports whether a number must be an integer.
+	integer bool
+	// literal and pos are the literal to match and the position reached.
+	literal string
+	pos     int
+}
+
+// Matcher incrementally validates a text, character by character, as a
+// compact JSON document (i.e. without insignificant whitespace) matching a
+// Schema.
+type Matcher struct {
+	stack []frame
+}
+
+// NewMatcher returns a new Matcher for the given schema.
+func NewMatcher(schema *Schema) *Matcher {
+	return &Matcher{stack: []frame{{kind: valueFrame, schema: schema}}}
+}
+
+// Clone returns a copy of the Matcher.
+func (m *Matcher) Clone() *Matcher {
+	stack := make([]frame, len(m.stack), len(m.stack)+4)
+	copy(stack, m.stack)
+	return &Matcher{stack: stack}
+}
+
+// Feed advances the Matcher with the text, reporting whether it is still a
+// valid prefix of a document. After a failure, the state of the Matcher is
+// undefined.
+func (m *Matcher) Feed(text string) bool {
+	for _, r := range text {
+		if !m.feedRune(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// Complete reports whether the text fed so far is a complete document.
+func (m *Matcher) Complete() bool {
+	if len(m.stack) == 0 {
+		return true
+	}
+	return len(m.stack) == 1 && m.stack[0].kind == numberFrame && numberTerminal(m.stack[0].state)
+}
+
+// Done reports whether the document is complete and cannot be extended.
+func (m *Matcher) Done() bool {
+	return len(m.stack) == 0
+}
+
+func (m *Matcher) top() *frame {
+	return &m.stack[len(m.stack)-1]
+}
+
+func (m *Matcher) push(f frame) {
+	m.stack = append(m.stack, f)
+}
+
+// pop removes the top frame, notifying the parent that its value is complete.
+func (m *Matcher) pop(value string) {
+	m.stack = m.stack[:len(m.stack)-1]
+	if len(m.stack) == 0 {
+		return
+	}
+	parent := m.top()
+	switch parent.kind {
+	case objectFrame:
+		if parent.state == objInKey {
+			parent.key = value
+			parent.state = objKeyDone
+		} else {
+			parent.state = objAfterValue
+		}
+	case arrayFrame:
+		parent.state = arrAfterValue
+	}
+}
+
+func (m *Matcher) feedRune(r rune) bool {
+	for len(m.stack) > 0 {
+		f := m.top()
+		switch f.kind {
+		case valueFrame:
+			return m.startValue(f, r)
+		case stringFrame:
+			return m.feedString(f, r)
+		case literalFrame:
+			if f.pos >= len(f.literal) || rune(f.literal[f.pos]) != r {
+				return false
+			}
+			f.pos++
+			if f.pos == len(f.literal) {
+				m.pop("")
+			}
+			return true
+		case numberFrame:
+			if feedNumber(f, r) {
+				return true
+			}
+			if !numberTerminal(f.state) {
+				return false
+			}
+			m.pop("")
+			continue // the rune belongs to the parent
+		case objectFrame:
+			return m.feedObject(f, r)
+		case arrayFrame:
+			return m.feedArray(f, r)
+		}
+	}
+	return false // the document is already complete
+}
+
+func (m *Matcher) startValue(f *frame, r rune) bool {
+	s := f.schema
+	allows := func(types ...string) bool {
+		if s == nil || s.Type == "" {
+			return true
+		}
+		for _, t := range types {
+			if s.Type == t {
+				return true
+			}
+		}
+		return false
+	}
+	switch {
+	case r == '{' && allows("object"):
+		*f = frame{kind: objectFrame, schema: s, state: objStart}
+	case r == '[' && allows("array"):
+		*f = frame{kind: arrayFrame, schema: s, state: arrStart}
+	case r == '"' && allows("string"):
+		*f = frame{kind: stringFrame, schema: s}
+		if s != nil && len(s.Enum) > 0 {
+			f.candidates = escapeAll(s.Enum)
+		}
+	case (r == '-' || (r >= '0' && r <= '9')) && allows("number", "integer"):
+		*f = frame{kind: numberFrame, schema: s, state: numStart, integer: s != nil && s.Type == "integer"}
+		return feedNumber(f, r)
+	case (r == 't' || r == 'f') && allows("boolean"):
+		*f = frame{kind: literalFrame, schema: s, literal: map[rune]string{'t': "true", 'f': "false"}[r], pos: 1}
+	case r == 'n' && allows("null"):
+		*f = frame{kind: literalFrame, schema: s, literal: "null", pos: 1}
+	default:
+		return false
+	}
+	return true
+}
+
+func (m *Matcher) feedString(f *frame, r rune) bool {
+	switch f.state {
+	case strEscape:
+		switch r {
+		case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
+			f.state = strNormal
+		case 'u':
+			f.state, f.hex = strUnicode, 0
+		default:
+			return false
+		}
+	case strUnicode:
+		if !isHex(r) {
+			return false
+		}
+		if f.hex++; f.hex == 4 {
+			f.state = strNormal
+		}
+	default:
+		if r == '"' {
+			if f.candidates != nil && !contains(f.candidates, f.buf) {
+				return false
+			}
+			m.pop(f.buf)
+			return true
+		}
+		if r < 0x20 {
+			return false
+		}
+		if r == '\\' {
+			f.state = strEscape
+		}
+	}
+	if f.candidates != nil {
+		f.buf += string(r)
+		return hasPrefix(f.candidates, f.buf)
+	}
+	return true
+}
+
+func (m *Matcher) feedObject(f *frame, r rune) bool {
+	switch f.state {
+	case objStart, objAfterComma:
+		if r == '}' && f.state == objStart && !remainingRequired(f) {
+			m.pop("")
+			return true
+		}
+		if r != '"' {
+			return false
+		}
+		keys := allowedKeys(f)
+		if keys != nil && len(keys) == 0 {
+			return false
+		}
+		f.state = objInKey
+		m.push(frame{kind: stringFrame, candidates: keys})
+		return true
+	case objKeyDone:
+		if r != ':' {
+			return false
+		}
+		f.state = objInValue
+		m.push(frame{kind: valueFrame, schema: selectProperty(f)})
+		return true
+	case objAfterValue:
+		switch {
+		case r == '}' && !remainingRequired(f):
+			m.pop("")
+			return true
+		case r == ',':
+			if keys := allowedKeys(f); keys != nil && len(keys) == 0 {
+				return false
+			}
+			f.state = objAfterComma
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Matcher) feedArray(f *frame, r rune) bool {
+	switch f.state {
+	case arrStart, arrAfterValue:
+		if r == ']' {
+			m.pop("")
+			return true
+		}
+		if f.state == arrAfterValue {
+			if r != ',' {
+				return false
+			}
+			f.state = arrAfterComma
+			return true
+		}
+	}
+	// the rune starts a new item
+	var items *Schema
+	if f.schema != nil {
+		items = f.schema.Items
+	}
+	f.state = arrInValue
+	m.push(frame{kind: valueFrame, schema: items})
+	return m.startValue(m.top(), r)
+}
+
+// allowedKeys returns the (escaped) keys that can follow in the object, or nil
+// if any key is allowed.
+func allowedKeys(f *frame) []string {
+	if f.schema == nil || len(f.schema.Properties) == 0 {
+		return nil
+	}
+	keys := make([]string, 0)
+	for _, p := range f.schema.Properties[f.next:] {
+		keys = append(keys, escape(p.Name))
+		if p.Required {
+			break
+		}
+	}
+	return keys
+}
+
+// selectProperty advances the object past the property of the last key,
+// returning its schema.
+func selectProperty(f *frame) *Schema {
+	if f.schema == nil || len(f.schema.Properties) == 0 {
+		return nil
+	}
+	for i := f.next; i < len(f.schema.Properties); i++ {
+		if escape(f.schema.Properties[i].Name) == f.key {
+			f.next = i + 1
+			return f.schema.Properties[i].Schema
+		}
+	}
+	return nil
+}
+
+func remainingRequired(f *frame) bool {
+	if f.schema == nil {
+		return false
+	}
+	for _, p := range f.schema.Properties[f.next:] {
+		if p.Required {
+			return true
+		}
+	}
+	return false
+}
+
+func feedNumber(f *frame, r rune) bool {
+	digit := r >= '0' && r <= '9'
+	exp := (r == 'e' || r == 'E') && !f.integer
+	switch f.state {
+	case numStart:
+		switch {
+		case r == '-':
+			f.state = numMinus
+		case r == '0':
+			f.state = numZero
+		case digit:
+			f.state = numInt
+		default:
+			return false
+		}
+	case numMinus:
+		switch {
+		case r == '0':
+			f.state = numZero
+		case digit:
+			f.state = numInt
+		default:
+			return false
+		}
+	case numZero, numInt:
+		switch {
+		case digit && f.state == numInt:
+		case r == '.' && !f.integer:
+			f.state = numDot
+		case exp:
+			f.state = numExp
+		default:
+			return false
+		}
+	case numDot:
+		if !digit {
+			return false
+		}
+		f.state = numFrac
+	case numFrac:
+		switch {
+		case digit:
+		case exp:
+			f.state = numExp
+		default:
+			return false
+		}
+	case numExp:
+		switch {
+		case r == '+' || r == '-':
+			f.state = numExpSign
+		case digit:
+			f.state = numExpDigits
+		default:
+			return false
+		}
+	case numExpSign, numExpDigits:
+		if !digit {
+			return false
+		}
+		f.state = numExpDigits
+	}
+	return true
+}
+
+func numberTerminal(state int) bool {
+	return state == numZero || state == numInt || state == numFrac || state == numExpDigits
+}
+
+func isHex(r rune) bool {
+	return (r >= '0' && r <= '9') || (r >= 'a' && r <= 'f') || (r >= 'A' && r <= 'F')
+}
+
+// escape returns the content of the JSON string representing s.
+func escape(s string) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s) // a string can always be encoded
+	out := strings.TrimSuffix(buf.String(), "\n")
+	return out[1 : len(out)-1]
+}
+
+func escapeAll(values []string) []string {
+	out := make([]string, len(values))
+	for i, v := range values {
+		out[i] = escape(v)
+	}
+	return out
+}
+
+func contains(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func hasPrefix(values []string, prefix string) bool {
+	for _, v := range values {
+		if strings.HasPrefix(v, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonconstraint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatcherAnyValue(t *testing.T) {
+	valid := []string{
+		`null`, `true`, `false`, `0`, `-12.5e+3`, `"a\"bè"`,
+		`[]`, `[1,"x",[null]]`, `{}`, `{"a":{"b":[true]}}`,
+	}
+	for _, text := range valid {
+		m := NewMatcher(nil)
+		assert.True(t, m.Feed(text), text)
+		assert.True(t, m.Complete(), text)
+	}
+	invalid := []string{
+		`nul1`, `01`, `-`, `1.`, `1e`, `"\x"`, `"\u12g"`, `[1,]`, `{"a"}`,
+		`{"a":1,}`, `{ "a":1}`, `[1]]`, "\"a\nb\"",
+	}
+	for _, text := range invalid {
+		m := NewMatcher(nil)
+		assert.False(t, m.Feed(text) && m.Complete(), text)
+	}
+}
+
+func TestMatcherPrefix(t *testing.T) {
+	m := NewMatcher(nil)
+	require.True(t, m.Feed(`{"a":[1`))
+	assert.False(t, m.Complete())
+	assert.True(t, m.Clone().Feed(`2]}`))
+	assert.True(t, m.Clone().Feed(`,2]}`))
+	assert.False(t, m.Clone().Feed(`}`))
+
+	n := NewMatcher(nil)
+	require.True(t, n.Feed(`12`))
+	assert.True(t, n.Complete())
+	assert.False(t, n.Done())
+	require.True(t, n.Feed(`3`))
+	assert.False(t, n.Feed(`}`))
+}
+
+func TestMatcherSchema(t *testing.T) {
+	s, err := ParseSchema([]byte(`{
+		"type": "object",
+		"properties": {
+			"name": {"type": "string"},
+			"age": {"type": "integer"},
+			"color": {"type": "string", "enum": ["red", "green"]},
+			"tags": {"type": "array", "items": {"type": "boolean"}}
+		},
+		"required": ["name", "color"]
+	}`))
+	require.NoError(t, err)
+
+	valid := []string{
+		`{"name":"x","color":"red"}`,
+		`{"name":"x","age":-3,"color":"green","tags":[true,false]}`,
+		`{"name":"","color":"red","tags":[]}`,
+	}
+	for _, text := range valid {
+		m := NewMatcher(s)
+		assert.True(t, m.Feed(text), text)
+		assert.True(t, m.Done(), text)
+	}
+	invalid := []string{
+		`{"color":"red"}`,                         // missing required property
+		`{"name":"x"}`,                            // missing required property
+		`{"age":1,"name":"x","color":"red"}`,      // wrong order
+		`{"name":"x","age":1.5,"color":"red"}`,    // not an integer
+		`{"name":"x","color":"blue"}`,             // not in the enum
+		`{"name":"x","color":"re"}`,               // prefix of an enum value
+		`{"name":"x","color":"red","tags":[1]}`,   // wrong item type
+		`{"name":"x","color":"red","other":true}`, // unknown property
+		`{"name":1,"color":"red"}`,                // wrong type
+		`["name"]`,                                // not an object
+	}
+	for _, text := range invalid {
+		m := NewMatcher(s)
+		assert.False(t, m.Feed(text) && m.Complete(), text)
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package jsonconstraint provides a generation.PrefixConstraint forcing the
+// generated text to be a valid JSON document matching a (subset of) JSON Schema.
+package jsonconstraint
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Schema is the subset of JSON Schema supported by the constraint.
+// A nil Schema, or one without Type, matches any JSON value.
+type Schema struct {
+	// Type is one of "object", "array", "string", "number", "integer",
+	// "boolean" and "null".
+	Type string
+	// Properties contains the properties of an object, which are generated
+	// in this order. An object without properties can have any property.
+	Properties []Property
+	// Items is the schema of the items of an array.
+	Items *Schema
+	// Enum, if not empty, contains the allowed values of a string.
+	Enum []string
+}
+
+// Property is a property of an object.
+type Property struct {
+	Name     string
+	Schema   *Schema
+	Required bool
+}
+
+// jsonSchema is the JSON representation of a Schema.
+type jsonSchema struct {
+	Type       string          `json:"type"`
+	Properties json.RawMessage `json:"properties"`
+	Required   []string        `json:"required"`
+	Items      json.RawMessage `json:"items"`
+	Enum       []string        `json:"enum"`
+}
+
+// ParseSchema parses a JSON Schema, keeping the order of the properties.
+func ParseSchema(data []byte) (*Schema, error) {
+	var js jsonSchema
+	if err := json.Unmarshal(data, &js); err != nil {
+		return nil, fmt.Errorf("jsonconstraint: %w", err)
+	}
+	switch js.Type {
+	case "", "object", "array", "string", "number", "integer", "boolean", "null":
+	default:
+		return nil, fmt.Errorf("jsonconstraint: unsupported type %q", js.Type)
+	}
+	s := &Schema{Type: js.Type, Enum: js.Enum}
+	if len(js.Items) > 0 {
+		items, err := ParseSchema(js.Items)
+		if err != nil {
+			return nil, err
+		}
+		s.Items = items
+	}
+	if len(js.Properties) > 0 {
+		properties, err := parseProperties(js.Properties)
+		if err != nil {
+			return nil, err
+		}
+		required := make(map[string]bool, len(js.Required))
+		for _, name := range js.Required {
+			required[name] = true
+		}
+		for i := range properties {
+			properties[i].Required = required[properties[i].Name]
+		}
+		s.Properties = properties
+	}
+	return s, nil
+}
+
+// parseProperties parses the object of the properties in order.
+func parseProperties(data []byte) ([]Property, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if t, err := dec.Token(); err != nil || t != json.Delim('{') {
+		return nil, fmt.Errorf("jsonconstraint: invalid properties")
+	}
+	properties := make([]Property, 0)
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("jsonconstraint: %w", err)
+		}
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, fmt.Errorf("jsonconstraint: %w", err)
+		}
+		schema, err := ParseSchema(raw)
+		if err != nil {
+			return nil, err
+		}
+		properties = append(properties, Property{Name: t.(string), Schema: schema})
+	}
+	return properties, nil
+}
@@ -0,0 +1,41 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package jsonconstraint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSchema(t *testing.T) {
+	s, err := ParseSchema([]byte(`{
+		"type": "object",
+		"properties": {
+			"name": {"type": "string"},
+			"age": {"type": "integer"},
+			"tags": {"type": "array", "items": {"type": "string", "enum": ["a", "b"]}}
+		},
+		"required": ["name", "tags"]
+	}`))
+	require.NoError(t, err)
+	assert.Equal(t, "object", s.Type)
+	require.Len(t, s.Properties, 3)
+	assert.Equal(t, Property{Name: "name", Schema: &Schema{Type: "string"}, Required: true}, s.Properties[0])
+	assert.Equal(t, Property{Name: "age", Schema: &Schema{Type: "integer"}}, s.Properties[1])
+	assert.Equal(t, "tags", s.Properties[2].Name)
+	assert.True(t, s.Properties[2].Required)
+	assert.Equal(t, &Schema{Type: "string", Enum: []string{"a", "b"}}, s.Properties[2].Schema.Items)
+}
+
+func TestParseSchemaErrors(t *testing.T) {
+	_, err := ParseSchema([]byte(`{"type": "tuple"}`))
+	assert.Error(t, err)
+	_, err = ParseSchema([]byte(`{"type": "object", "properties": {"x": {"type": "foo"}}}`))
+	assert.Error(t, err)
+	_, err = ParseSchema([]byte(`not json`))
+	assert.Error(t, err)
+}
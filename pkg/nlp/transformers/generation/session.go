@@ -0,0 +1,223 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package generation
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+)
+
+// StatefulDecoder is a Decoder whose cache can be retained across the
+// graphs of different requests (see SessionCache).
+type StatefulDecoder interface {
+	// ExportCache returns a graph-independent copy of the cache resulting from
+	// the first decoding step (e.g. the projected keys and values of the
+	// self-attention and of the cross-attention), and its size in bytes.
+	ExportCache(cache Cache) (state interface{}, size int)
+	// ImportCache returns the cache resulting from the first decoding step,
+	// restored from the exported state on the graph of the model.
+	ImportCache(state interface{}) Cache
+}
+
+// IncrementalEncoder is an Encoder able to extend the encoding of an input
+// with new input IDs, so that the input of a session can grow across the
+// requests (e.g. the history of a chat) without being encoded anew.
+type IncrementalEncoder interface {
+	// EncodeIncremental encodes the input IDs following the ones of the past
+	// cache (nil at the start of the input), to which they attend, returning
+	// the cache of the whole input. The earlier inputs, which are not encoded
+	// anew, don't attend to the new ones.
+	EncodeIncremental(inputIDs []int, pastCache Cache) ([]ag.Node, Cache)
+	// ExportEncoderCache returns a graph-independent copy of the cache, and
+	// its size in bytes.
+	ExportEncoderCache(cache Cache) (state interface{}, size int)
+	// ImportEncoderCache returns the cache restored from the exported state on
+	// the graph of the model.
+	ImportEncoderCache(state interface{}) Cache
+}
+
+// InputKey returns a hash of the input IDs, used as the session ID of the
+// inputs retained by GenerateCached. A collision can't produce a wrong
+// output, since a state is reused only if its input IDs are the same.
+func InputKey(inputIDs []int) string {
+	h := sha256.New()
+	buf := make([]byte, 8)
+	for _, id := range inputIDs {
+		binary.LittleEndian.PutUint64(buf, uint64(id))
+		h.Write(buf)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// SessionState is the graph-independent state of a session.
+type SessionState struct {
+	// InputIDs is the encoded input.
+	InputIDs []int
+	// Encoded contains the values of the encoded input.
+	Encoded []mat.Matrix
+	// EncoderCache is the state exported by an IncrementalEncoder, or nil.
+	EncoderCache interface{}
+	// EncoderCacheLength is the number of input IDs covered by EncoderCache,
+	// which excludes the final EOS token.
+	EncoderCacheLength int
+	// EncoderCacheSize is the size in bytes of EncoderCache.
+	EncoderCacheSize int
+	// Cache is the state exported by a StatefulDecoder, or nil.
+	Cache interface{}
+	// CacheSize is the size in bytes of Cache.
+	CacheSize int
+	// FirstScores contains the scores of the first decoding step, resulting
+	// in Cache.
+	FirstScores Scores
+}
+
+// Size returns the approximate size of the state in bytes.
+func (s *SessionState) Size() int {
+	size := s.EncoderCacheSize + s.CacheSize + len(s.InputIDs)*8
+	for _, m := range s.Encoded {
+		size += m.Size() * 4
+	}
+	if s.FirstScores != nil {
+		size += s.FirstScores.Size() * 4
+	}
+	return size
+}
+
+// SessionCache retains the states of the sessions (e.g. the conversations of
+// a chat) across the requests to a server, so that the encoding of an input
+// submitted again in the same session is not computed anew, nor the first
+// decoding step if the model implements StatefulDecoder. A different input
+// replaces the state of the session, unless it extends the previous one (e.g.
+// the history of a chat with a new turn) and the generator opts in to the
+// approximate IncrementalSessionEncoding.
+//
+// The sessions expire when they are not used for the TTL, and the least
+// recently used ones are evicted when the total size of the states exceeds
+// the memory budget. It is safe for concurrent use.
+type SessionCache struct {
+	mu       sync.Mutex
+	ttl      time.Duration
+	maxBytes int
+	size     int
+	lru      *list.List // of *sessionEntry, the most recently used first
+	sessions map[string]*list.Element
+	now      func() time.Time
+}
+
+type sessionEntry struct {
+	id      string
+	state   *SessionState
+	size    int
+	expires time.Time
+}
+
+// NewSessionCache returns a new SessionCache with the given time-to-live of
+// the sessions, and memory budget in bytes. A zero ttl or maxBytes means no
+// limit.
+func NewSessionCache(ttl time.Duration, maxBytes int) *SessionCache {
+	return &SessionCache{
+		ttl:      ttl,
+		maxBytes: maxBytes,
+		lru:      list.New(),
+		sessions: make(map[string]*list.Element),
+		now:      time.Now,
+	}
+}
+
+// Get returns the state of the session, renewing its expiration.
+func (c *SessionCache) Get(id string) (*SessionState, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.evictExpired()
+	elem, ok := c.sessions[id]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*sessionEntry)
+	entry.expires = c.expiration()
+	c.lru.MoveToFront(elem)
+	return entry.state, true
+}
+
+// Put sets the state of the session, evicting the least recently used
+// sessions if the memory budget is exceeded. A state larger than the whole
+// budget is not retained.
+func (c *SessionCache) Put(id string, state *SessionState) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.remove(id)
+	size := state.Size()
+	if c.maxBytes > 0 && size > c.maxBytes {
+		return
+	}
+	entry := &sessionEntry{id: id, state: state, size: size, expires: c.expiration()}
+	c.sessions[id] = c.lru.PushFront(entry)
+	c.size += size
+	c.evictExpired()
+	for c.maxBytes > 0 && c.size > c.maxBytes {
+		c.remove(c.lru.Back().Value.(*sessionEntry).id)
+	}
+}
+
+// Delete removes the session.
+func (c *SessionCache) Delete(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.remove(id)
+}
+
+// Len returns the number of retained sessions.
+func (c *SessionCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// Size returns the total size in bytes of the retained states.
+func (c *SessionCache) Size() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.size
+}
+
+func (c *SessionCache) expiration() time.Time {
+	if c.ttl <= 0 {
+		return time.Time{}
+	}
+	return c.now().Add(c.ttl)
+}
+
+// evictExpired removes the expired sessions, starting from the least
+// recently used, which expire first.
+func (c *SessionCache) evictExpired() {
+	if c.ttl <= 0 {
+		return
+	}
+	now := c.now()
+	for elem := c.lru.Back(); elem != nil; elem = c.lru.Back() {
+		entry := elem.Value.(*sessionEntry)
+		if now.Before(entry.expires) {
+			return
+		}
+		c.remove(entry.id)
+	}
+}
+
+func (c *SessionCache) remove(id string) {
+	elem, ok := c.sessions[id]
+	if !ok {
+		return
+	}
+	c.size -= elem.Value.(*sessionEntry).size
+	c.lru.Remove(elem)
+	delete(c.sessions, id)
+}
@@ -0,0 +1,81 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package generation
+
+import (
+	"testing"
+	"time"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSessionState(size int) *SessionState {
+	// 8 bytes for the input ID, 4 bytes for each value
+	return &SessionState{InputIDs: []int{1}, Encoded: []mat.Matrix{mat.NewEmptyVecDense((size - 8) / 4)}}
+}
+
+func TestSessionCache_TTL(t *testing.T) {
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewSessionCache(time.Minute, 0)
+	c.now = func() time.Time { return now }
+
+	c.Put("a", newTestSessionState(16))
+	now = now.Add(30 * time.Second)
+	c.Put("b", newTestSessionState(16))
+	assert.Equal(t, 2, c.Len())
+	assert.Equal(t, 32, c.Size())
+
+	now = now.Add(40 * time.Second)
+	_, ok := c.Get("a")
+	assert.False(t, ok)
+	state, ok := c.Get("b") // renews the expiration
+	require.True(t, ok)
+	assert.Equal(t, []int{1}, state.InputIDs)
+
+	now = now.Add(50 * time.Second)
+	_, ok = c.Get("b")
+	assert.True(t, ok)
+	now = now.Add(61 * time.Second)
+	_, ok = c.Get("b")
+	assert.False(t, ok)
+	assert.Equal(t, 0, c.Size())
+}
+
+func TestSessionCache_MemoryBudget(t *testing.T) {
+	c := NewSessionCache(0, 40)
+	c.Put("a", newTestSessionState(16))
+	c.Put("b", newTestSessionState(16))
+	_, _ = c.Get("a") // "b" becomes the least recently used
+	c.Put("c", newTestSessionState(16))
+
+	_, ok := c.Get("b")
+	assert.False(t, ok)
+	_, ok = c.Get("a")
+	assert.True(t, ok)
+	_, ok = c.Get("c")
+	assert.True(t, ok)
+	assert.Equal(t, 32, c.Size())
+
+	c.Put("a", newTestSessionState(24)) // replaces the state
+	assert.Equal(t, 40, c.Size())
+
+	c.Put("d", newTestSessionState(48)) // larger than the budget
+	_, ok = c.Get("d")
+	assert.False(t, ok)
+	assert.Equal(t, 2, c.Len())
+
+	c.Delete("a")
+	assert.Equal(t, 1, c.Len())
+	assert.Equal(t, 16, c.Size())
+}
+
+func TestInputKey(t *testing.T) {
+	assert.Equal(t, InputKey([]int{1, 2, 3}), InputKey([]int{1, 2, 3}))
+	assert.NotEqual(t, InputKey([]int{1, 2, 3}), InputKey([]int{1, 2}))
+	assert.NotEqual(t, InputKey([]int{1, 2}), InputKey([]int{2, 1}))
+	assert.Len(t, InputKey(nil), 64)
+}
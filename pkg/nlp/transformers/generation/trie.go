@@ -0,0 +1,94 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package generation
+
+import "sort"
+
+var _ PrefixConstraint = &PrefixTrie{}
+
+// PrefixTrie is a PrefixConstraint restricting the generated sequences to a
+// closed set of token sequences, such as entity names or slot values.
+type PrefixTrie struct {
+	root *trieNode
+	// EndTokenID is the ID of the token allowed after a complete sequence
+	// (usually the End-Of-Sequence token), or -1 for none.
+	EndTokenID int
+}
+
+type trieNode struct {
+	children map[int]*trieNode
+	// tokens contains the keys of children in ascending order.
+	tokens []int
+	end    bool
+}
+
+// NewPrefixTrie returns a new PrefixTrie containing the given sequences, with
+// the given EndTokenID.
+func NewPrefixTrie(sequences [][]int, endTokenID int) *PrefixTrie {
+	t := &PrefixTrie{
+		root:       newTrieNode(),
+		EndTokenID: endTokenID,
+	}
+	for _, sequence := range sequences {
+		t.Add(sequence)
+	}
+	return t
+}
+
+func newTrieNode() *trieNode {
+	return &trieNode{children: make(map[int]*trieNode)}
+}
+
+// Add adds a sequence to the trie.
+func (t *PrefixTrie) Add(sequence []int) {
+	node := t.root
+	for _, token := range sequence {
+		child, ok := node.children[token]
+		if !ok {
+			child = newTrieNode()
+			node.children[token] = child
+			i := sort.SearchInts(node.tokens, token)
+			node.tokens = append(node.tokens, 0)
+			copy(node.tokens[i+1:], node.tokens[i:])
+			node.tokens[i] = token
+		}
+		node = child
+	}
+	node.end = true
+}
+
+// Contains reports whether the trie contains the complete sequence.
+func (t *PrefixTrie) Contains(sequence []int) bool {
+	node := t.find(sequence)
+	return node != nil && node.end
+}
+
+// AllowedTokens returns the IDs of the tokens continuing the prefix in any of
+// the sequences of the trie, in ascending order, followed by EndTokenID if the
+// prefix is a complete sequence.
+func (t *PrefixTrie) AllowedTokens(prefix []int) []int {
+	node := t.find(prefix)
+	if node == nil {
+		return []int{}
+	}
+	allowed := make([]int, len(node.tokens), len(node.tokens)+1)
+	copy(allowed, node.tokens)
+	if node.end && t.EndTokenID >= 0 {
+		allowed = append(allowed, t.EndTokenID)
+	}
+	return allowed
+}
+
+// find returns the node reached following the prefix, or nil.
+func (t *PrefixTrie) find(prefix []int) *trieNode {
+	node := t.root
+	for _, token := range prefix {
+		node = node.children[token]
+		if node == nil {
+			return nil
+		}
+	}
+	return node
+}
@@ -0,0 +1,52 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package generation
+
+import (
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrefixTrie(t *testing.T) {
+	trie := NewPrefixTrie([][]int{
+		{5, 3, 8},
+		{5, 1},
+		{5},
+		{7, 2},
+	}, 0)
+
+	assert.Equal(t, []int{5, 7}, trie.AllowedTokens(nil))
+	assert.Equal(t, []int{1, 3, 0}, trie.AllowedTokens([]int{5}))
+	assert.Equal(t, []int{8}, trie.AllowedTokens([]int{5, 3}))
+	assert.Equal(t, []int{0}, trie.AllowedTokens([]int{5, 3, 8}))
+	assert.Equal(t, []int{}, trie.AllowedTokens([]int{5, 2}))
+
+	assert.True(t, trie.Contains([]int{5, 1}))
+	assert.False(t, trie.Contains([]int{5, 3}))
+	assert.False(t, trie.Contains([]int{9}))
+
+	trie.EndTokenID = -1
+	assert.Equal(t, []int{1, 3}, trie.AllowedTokens([]int{5}))
+}
+
+func TestGenerator_processPrefixConstraintScores(t *testing.T) {
+	b := &Generator{config: GeneratorConfig{
+		PrefixConstraint: NewPrefixTrie([][]int{{1, 2}, {3}}, 0),
+	}}
+	scores := []Scores{
+		mat.NewVecDense([]mat.Float{-1, -2, -3, -4}),
+		mat.NewVecDense([]mat.Float{-1, -2, -3, -4}),
+	}
+	inputIDs := [][]int{
+		{2},    // decoder start token only
+		{2, 1}, // first token of the sequence {1, 2}
+	}
+	scores = b.processPrefixConstraintScores(inputIDs, scores)
+	inf := mat.Inf(-1)
+	assert.Equal(t, []mat.Float{inf, -2, inf, -4}, scores[0].Data())
+	assert.Equal(t, []mat.Float{inf, inf, -3, inf}, scores[1].Data())
+}
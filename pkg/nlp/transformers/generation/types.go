@@ -35,3 +35,11 @@ type Scores = mat.Matrix
 
 // Cache is just an alias of interface{}
 type Cache interface{}
+
+// PrefixConstraint restricts the tokens that can be generated after a prefix.
+type PrefixConstraint interface {
+	// AllowedTokens returns the IDs of the tokens allowed after the given
+	// generated tokens (the decoder start token excluded). An empty result
+	// forbids any token, whereas nil allows any token.
+	AllowedTokens(prefix []int) []int
+}
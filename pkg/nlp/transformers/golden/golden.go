@@ -0,0 +1,214 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package golden provides golden-output regression tests for the pretrained
+// models: a Fixture stores the outputs of a model on a few small inputs, so
+// that a later version of the library can verify it still reproduces them
+// within a tolerance, instead of silently changing the accuracy of the model.
+//
+// A fixture is generated once, with the version of the library the model
+// has been released (or validated) with, and stored along with the model:
+//
+//     runner, err := golden.NewRunner(modelPath)
+//     fixture, err := golden.Generate(runner, golden.DefaultInputs, golden.DefaultTolerance)
+//     err = fixture.Save(filepath.Join(modelPath, golden.DefaultFixtureFile))
+//
+// Then, after upgrading the library, it is verified against the same model:
+//
+//     fixture, err := golden.Load(filepath.Join(modelPath, golden.DefaultFixtureFile))
+//     mismatches, err := fixture.Verify(runner)
+package golden
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"sort"
+)
+
+const (
+	// DefaultFixtureFile is the default filename of a fixture in the model
+	// directory.
+	DefaultFixtureFile = "golden.json"
+	// DefaultTolerance is the default maximum absolute difference between
+	// the stored and the current outputs.
+	DefaultTolerance = 1e-4
+)
+
+// DefaultInputs are the default inputs of the generated fixtures: a few
+// short texts of different lengths, casing and punctuation.
+var DefaultInputs = []string{
+	"Hello world!",
+	"The quick brown fox jumps over the lazy dog.",
+	"Rome is the capital of Italy, and Paris is the capital of France.",
+	"I can't believe it's already 2021...",
+}
+
+// Runner computes the outputs of a model for an input text. The outputs are
+// identified by name, e.g. "encoding" or "logits"; discrete outputs, such as
+// the IDs of the generated tokens, are returned as float values too.
+type Runner interface {
+	// Architecture returns the name of the architecture of the model.
+	Architecture() string
+	// Run returns the named outputs of the model for the input text.
+	Run(input string) (map[string][]float64, error)
+}
+
+// Fixture contains the golden outputs of a model.
+type Fixture struct {
+	// Architecture is the name of the architecture of the model.
+	Architecture string `json:"architecture"`
+	// Tolerance is the maximum absolute difference allowed between the
+	// stored and the current outputs.
+	Tolerance float64 `json:"tolerance"`
+	Cases     []Case  `json:"cases"`
+}
+
+// Case contains the golden outputs of a model for an input text.
+type Case struct {
+	Input   string               `json:"input"`
+	Outputs map[string][]float64 `json:"outputs"`
+}
+
+// Mismatch describes an output which is not reproduced within the
+// tolerance.
+type Mismatch struct {
+	// Input is the input text of the case.
+	Input string
+	// Output is the name of the output.
+	Output string
+	// Reason describes the mismatch.
+	Reason string
+	// MaxAbsDiff is the maximum absolute difference between the stored and
+	// the current values, if they have the same length.
+	MaxAbsDiff float64
+}
+
+// String returns a human-readable description of the mismatch.
+func (m Mismatch) String() string {
+	return fmt.Sprintf("%q: output %q %s", m.Input, m.Output, m.Reason)
+}
+
+// Generate runs the model on the inputs, and returns a new Fixture with its
+// outputs.
+func Generate(runner Runner, inputs []string, tolerance float64) (*Fixture, error) {
+	if tolerance < 0 {
+		return nil, fmt.Errorf("golden: invalid tolerance %g", tolerance)
+	}
+	fixture := &Fixture{
+		Architecture: runner.Architecture(),
+		Tolerance:    tolerance,
+		Cases:        make([]Case, len(inputs)),
+	}
+	for i, input := range inputs {
+		outputs, err := runner.Run(input)
+		if err != nil {
+			return nil, fmt.Errorf("golden: error running %q: %w", input, err)
+		}
+		fixture.Cases[i] = Case{Input: input, Outputs: outputs}
+	}
+	return fixture, nil
+}
+
+// Verify runs the model on the inputs of the fixture, and returns the
+// outputs which are not reproduced within the tolerance, if any. An error is
+// returned if the architecture of the model doesn't match the one of the
+// fixture, or the model fails to run.
+func (f *Fixture) Verify(runner Runner) ([]Mismatch, error) {
+	if a := runner.Architecture(); a != f.Architecture {
+		return nil, fmt.Errorf("golden: the fixture is for %q, not %q", f.Architecture, a)
+	}
+	var mismatches []Mismatch
+	for _, c := range f.Cases {
+		outputs, err := runner.Run(c.Input)
+		if err != nil {
+			return nil, fmt.Errorf("golden: error running %q: %w", c.Input, err)
+		}
+		mismatches = append(mismatches, c.compare(outputs, f.Tolerance)...)
+	}
+	return mismatches, nil
+}
+
+// compare returns the mismatches between the golden outputs and the given
+// ones, in order of output name.
+func (c Case) compare(outputs map[string][]float64, tolerance float64) []Mismatch {
+	names := make([]string, 0, len(c.Outputs))
+	for name := range c.Outputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var mismatches []Mismatch
+	for _, name := range names {
+		expected := c.Outputs[name]
+		actual, ok := outputs[name]
+		if !ok {
+			mismatches = append(mismatches, Mismatch{Input: c.Input, Output: name, Reason: "is missing"})
+			continue
+		}
+		if len(actual) != len(expected) {
+			mismatches = append(mismatches, Mismatch{
+				Input:  c.Input,
+				Output: name,
+				Reason: fmt.Sprintf("has %d values, expected %d", len(actual), len(expected)),
+			})
+			continue
+		}
+		maxDiff, at := maxAbsDiff(expected, actual)
+		if maxDiff > tolerance {
+			mismatches = append(mismatches, Mismatch{
+				Input:      c.Input,
+				Output:     name,
+				Reason:     fmt.Sprintf("differs by %g at %d (%g instead of %g)", maxDiff, at, actual[at], expected[at]),
+				MaxAbsDiff: maxDiff,
+			})
+		}
+	}
+	return mismatches
+}
+
+// maxAbsDiff returns the maximum absolute difference between the values of
+// a and b, with its index. NaN values are only equal to each other.
+func maxAbsDiff(a, b []float64) (float64, int) {
+	max, at := 0.0, 0
+	for i := range a {
+		var diff float64
+		switch {
+		case math.IsNaN(a[i]) || math.IsNaN(b[i]):
+			if math.IsNaN(a[i]) != math.IsNaN(b[i]) {
+				diff = math.Inf(1)
+			}
+		case a[i] == b[i]: // also infinite values
+		default:
+			diff = math.Abs(a[i] - b[i])
+		}
+		if diff > max {
+			max, at = diff, i
+		}
+	}
+	return max, at
+}
+
+// Save writes the fixture to a JSON file.
+func (f *Fixture) Save(filename string) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, append(data, '\n'), 0644)
+}
+
+// Load reads a fixture from a JSON file.
+func Load(filename string) (*Fixture, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	f := &Fixture{}
+	if err := json.Unmarshal(data, f); err != nil {
+		return nil, fmt.Errorf("golden: cannot parse %s: %w", filename, err)
+	}
+	return f, nil
+}
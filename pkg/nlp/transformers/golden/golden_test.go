@@ -0,0 +1,144 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golden
+
+import (
+	"errors"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRunner returns the length of the input, scaled by the given factor,
+// and the codes of its runes.
+type fakeRunner struct {
+	architecture string
+	scale        float64
+	fail         bool
+}
+
+func (r fakeRunner) Architecture() string {
+	return r.architecture
+}
+
+func (r fakeRunner) Run(input string) (map[string][]float64, error) {
+	if r.fail {
+		return nil, errors.New("failure")
+	}
+	codes := make([]float64, 0, len(input))
+	for _, c := range input {
+		codes = append(codes, float64(c))
+	}
+	return map[string][]float64{
+		"length": {float64(len(input)) * r.scale},
+		"codes":  codes,
+	}, nil
+}
+
+func TestGenerate(t *testing.T) {
+	fixture, err := Generate(fakeRunner{architecture: "fake", scale: 1}, []string{"ab", "c"}, 0.1)
+	require.NoError(t, err)
+	assert.Equal(t, &Fixture{
+		Architecture: "fake",
+		Tolerance:    0.1,
+		Cases: []Case{
+			{Input: "ab", Outputs: map[string][]float64{"length": {2}, "codes": {97, 98}}},
+			{Input: "c", Outputs: map[string][]float64{"length": {1}, "codes": {99}}},
+		},
+	}, fixture)
+
+	_, err = Generate(fakeRunner{fail: true}, []string{"ab"}, 0.1)
+	assert.Error(t, err)
+	_, err = Generate(fakeRunner{}, []string{"ab"}, -1)
+	assert.Error(t, err)
+}
+
+func TestFixture_Verify(t *testing.T) {
+	fixture, err := Generate(fakeRunner{architecture: "fake", scale: 1}, []string{"ab", "c"}, 0.1)
+	require.NoError(t, err)
+
+	t.Run("within tolerance", func(t *testing.T) {
+		mismatches, err := fixture.Verify(fakeRunner{architecture: "fake", scale: 1.04})
+		require.NoError(t, err)
+		assert.Empty(t, mismatches)
+	})
+
+	t.Run("beyond tolerance", func(t *testing.T) {
+		mismatches, err := fixture.Verify(fakeRunner{architecture: "fake", scale: 1.2})
+		require.NoError(t, err)
+		require.Len(t, mismatches, 2)
+		assert.Equal(t, "ab", mismatches[0].Input)
+		assert.Equal(t, "length", mismatches[0].Output)
+		assert.InDelta(t, 0.4, mismatches[0].MaxAbsDiff, 1e-9)
+		assert.Equal(t, "c", mismatches[1].Input)
+		assert.InDelta(t, 0.2, mismatches[1].MaxAbsDiff, 1e-9)
+	})
+
+	t.Run("missing and resized outputs", func(t *testing.T) {
+		f := &Fixture{
+			Architecture: "fake",
+			Cases: []Case{{Input: "ab", Outputs: map[string][]float64{
+				"codes":  {97},
+				"length": {2},
+				"other":  {0},
+			}}},
+		}
+		mismatches, err := f.Verify(fakeRunner{architecture: "fake", scale: 1})
+		require.NoError(t, err)
+		require.Len(t, mismatches, 2)
+		assert.Equal(t, "codes", mismatches[0].Output)
+		assert.Equal(t, "has 2 values, expected 1", mismatches[0].Reason)
+		assert.Equal(t, "other", mismatches[1].Output)
+		assert.Equal(t, "is missing", mismatches[1].Reason)
+	})
+
+	t.Run("different architecture", func(t *testing.T) {
+		_, err := fixture.Verify(fakeRunner{architecture: "other", scale: 1})
+		assert.Error(t, err)
+	})
+
+	t.Run("failing runner", func(t *testing.T) {
+		_, err := fixture.Verify(fakeRunner{architecture: "fake", fail: true})
+		assert.Error(t, err)
+	})
+}
+
+func TestMaxAbsDiff(t *testing.T) {
+	nan, inf := math.NaN(), math.Inf(1)
+	diff, at := maxAbsDiff([]float64{1, 2, 3}, []float64{1, 2.5, 2})
+	assert.Equal(t, 1.0, diff)
+	assert.Equal(t, 2, at)
+	diff, _ = maxAbsDiff([]float64{nan, inf}, []float64{nan, inf})
+	assert.Equal(t, 0.0, diff)
+	diff, at = maxAbsDiff([]float64{1, nan}, []float64{1, 1})
+	assert.True(t, math.IsInf(diff, 1))
+	assert.Equal(t, 1, at)
+}
+
+func TestFixture_SaveLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "golden")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, DefaultFixtureFile)
+
+	fixture, err := Generate(fakeRunner{architecture: "fake", scale: 0.1}, DefaultInputs, DefaultTolerance)
+	require.NoError(t, err)
+	require.NoError(t, fixture.Save(filename))
+
+	loaded, err := Load(filename)
+	require.NoError(t, err)
+	assert.Equal(t, fixture, loaded)
+
+	require.NoError(t, ioutil.WriteFile(filename, []byte("{"), 0644))
+	_, err = Load(filename)
+	assert.Error(t, err)
+	_, err = Load(filepath.Join(dir, "missing.json"))
+	assert.Error(t, err)
+}
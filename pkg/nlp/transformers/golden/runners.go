@@ -0,0 +1,208 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golden
+
+import (
+	"fmt"
+	"path/filepath"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/nlpodyssey/spago/pkg/ml/ag"
+	"github.com/nlpodyssey/spago/pkg/ml/nn"
+	"github.com/nlpodyssey/spago/pkg/nlp/tokenizers"
+	"github.com/nlpodyssey/spago/pkg/nlp/tokenizers/bpetokenizer"
+	"github.com/nlpodyssey/spago/pkg/nlp/tokenizers/sentencepiece"
+	"github.com/nlpodyssey/spago/pkg/nlp/tokenizers/wordpiecetokenizer"
+	"github.com/nlpodyssey/spago/pkg/nlp/transformers/bart"
+	"github.com/nlpodyssey/spago/pkg/nlp/transformers/bart/head/conditionalgeneration"
+	"github.com/nlpodyssey/spago/pkg/nlp/transformers/bart/head/sequenceclassification"
+	"github.com/nlpodyssey/spago/pkg/nlp/transformers/bart/loader"
+	"github.com/nlpodyssey/spago/pkg/nlp/transformers/bert"
+	"github.com/nlpodyssey/spago/pkg/nlp/transformers/huggingface"
+)
+
+// ModelRunner is a Runner of a pretrained model, which must be closed when
+// it's no longer used.
+type ModelRunner interface {
+	Runner
+	// Close releases the resources of the model.
+	Close()
+}
+
+// NewRunner loads the pretrained model at the given path, and returns a
+// Runner of its supported outputs, depending on its architecture:
+//
+//     - "bert" and "electra": the mean and the [CLS] encodings, and the
+//       sequence classification logits if the model has labels;
+//     - "bart": the mean encoding of the encoder;
+//     - "bart/sequence-classification": the classification logits;
+//     - "marian/conditional-generation" (or "bart/..."): the mean encoding
+//       of the encoder and the IDs of the generated tokens.
+func NewRunner(modelPath string) (ModelRunner, error) {
+	config, err := huggingface.ReadCommonModelConfig(filepath.Join(modelPath, huggingface.ModelConfigFilename))
+	if err != nil {
+		return nil, err
+	}
+	switch config.ModelType {
+	case "bert", "electra":
+		model, err := bert.LoadModel(modelPath)
+		if err != nil {
+			return nil, err
+		}
+		return &bertRunner{modelType: config.ModelType, model: model}, nil
+	case "bart", "marian":
+		model, err := loader.Load(modelPath)
+		if err != nil {
+			return nil, err
+		}
+		r := &bartRunner{modelType: config.ModelType, model: model}
+		switch model.(type) {
+		case *conditionalgeneration.Model:
+			r.spTokenizer, err = sentencepiece.NewFromModelFolder(modelPath, false)
+		default:
+			r.bpeTokenizer, err = bpetokenizer.NewFromModelFolder(modelPath)
+		}
+		if err != nil {
+			model.Close()
+			return nil, err
+		}
+		return r, nil
+	default:
+		return nil, fmt.Errorf("golden: unsupported model type %q", config.ModelType)
+	}
+}
+
+type bertRunner struct {
+	modelType string
+	model     *bert.Model
+}
+
+// Architecture returns the model type.
+func (r *bertRunner) Architecture() string {
+	return r.modelType
+}
+
+// Close closes the embeddings of the model.
+func (r *bertRunner) Close() {
+	r.model.Close()
+}
+
+// Run returns the "encoding_mean" and "encoding_cls" outputs, and the
+// "logits" of the sequence classification if the model has labels.
+func (r *bertRunner) Run(input string) (map[string][]float64, error) {
+	g := ag.NewGraph()
+	defer g.Clear()
+	proc := nn.ReifyForInference(r.model, g).(*bert.Model)
+	tokens := tokenizers.GetStrings(wordpiecetokenizer.New(r.model.Vocabulary).Tokenize(input))
+	tokens = append([]string{wordpiecetokenizer.DefaultClassToken}, tokens...)
+	encoded := proc.Encode(append(tokens, wordpiecetokenizer.DefaultSequenceSeparator))
+
+	outputs := map[string][]float64{
+		"encoding_mean": toFloat64(g.GetCopiedValue(g.Mean(encoded))),
+		"encoding_cls":  toFloat64(g.GetCopiedValue(encoded[0])),
+	}
+	if len(r.model.Config.ID2Label) > 0 {
+		outputs["logits"] = toFloat64(g.GetCopiedValue(proc.SequenceClassification(encoded)))
+	}
+	return outputs, nil
+}
+
+type bartRunner struct {
+	modelType    string
+	model        nn.Model
+	bpeTokenizer *bpetokenizer.BPETokenizer
+	spTokenizer  *sentencepiece.Tokenizer
+}
+
+// Architecture returns the model type, followed by the head of the model.
+func (r *bartRunner) Architecture() string {
+	switch r.model.(type) {
+	case *sequenceclassification.Model:
+		return r.modelType + "/sequence-classification"
+	case *conditionalgeneration.Model:
+		return r.modelType + "/conditional-generation"
+	default:
+		return r.modelType
+	}
+}
+
+// Close closes the embeddings of the model.
+func (r *bartRunner) Close() {
+	r.model.Close()
+}
+
+// Run returns the outputs of the head of the model (see NewRunner).
+func (r *bartRunner) Run(input string) (map[string][]float64, error) {
+	switch m := r.model.(type) {
+	case *sequenceclassification.Model:
+		inputIDs, err := r.bpeInputIDs(m.BART, input)
+		if err != nil {
+			return nil, err
+		}
+		g := ag.NewGraph()
+		defer g.Clear()
+		proc := nn.ReifyForInference(m, g).(*sequenceclassification.Model)
+		return map[string][]float64{
+			"logits": toFloat64(g.GetCopiedValue(proc.Classify(inputIDs))),
+		}, nil
+	case *conditionalgeneration.Model:
+		tokens := r.spTokenizer.Tokenize(input)
+		inputIDs := append(r.spTokenizer.TokensToIDs(tokens), m.BART.Config.EosTokenID)
+		encoding := r.encoderMean(m, inputIDs)
+		g := ag.NewGraph(ag.IncrementalForward(false))
+		defer g.Clear()
+		proc := nn.ReifyForInference(m, g).(*conditionalgeneration.Model)
+		generated := proc.Generate(inputIDs)
+		ids := make([]float64, len(generated))
+		for i, id := range generated {
+			ids[i] = float64(id)
+		}
+		return map[string][]float64{
+			"encoding_mean": encoding,
+			"generated_ids": ids,
+		}, nil
+	case *bart.Model:
+		inputIDs, err := r.bpeInputIDs(m, input)
+		if err != nil {
+			return nil, err
+		}
+		return map[string][]float64{
+			"encoding_mean": r.encoderMean(m, inputIDs),
+		}, nil
+	default:
+		return nil, fmt.Errorf("golden: unsupported BART model %T", m)
+	}
+}
+
+// encoderMean returns the mean of the encodings of the encoder of the model,
+// which must implement the Encode method of bart.Model.
+func (r *bartRunner) encoderMean(m nn.Model, inputIDs []int) []float64 {
+	g := ag.NewGraph()
+	defer g.Clear()
+	proc := nn.ReifyForInference(m, g).(interface {
+		Encode(inputIDs []int) []ag.Node
+	})
+	return toFloat64(g.GetCopiedValue(g.Mean(proc.Encode(inputIDs))))
+}
+
+// bpeInputIDs returns the IDs of the tokens of the input, between the
+// beginning and the end of sequence tokens.
+func (r *bartRunner) bpeInputIDs(m *bart.Model, input string) ([]int, error) {
+	encoded, err := r.bpeTokenizer.Encode(input)
+	if err != nil {
+		return nil, err
+	}
+	inputIDs := append([]int{m.Config.BosTokenID}, encoded.IDs...)
+	return append(inputIDs, m.Config.EosTokenID), nil
+}
+
+func toFloat64(m mat.Matrix) []float64 {
+	data := m.Data()
+	out := make([]float64, len(data))
+	for i, v := range data {
+		out[i] = float64(v)
+	}
+	return out
+}
@@ -0,0 +1,63 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golden
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ModelsEnv is the environment variable with the paths of the pretrained
+// models verified by TestPretrainedModels, separated by the OS path list
+// separator (e.g. ':' on Unix). Each model must contain a fixture in
+// DefaultFixtureFile.
+const ModelsEnv = "SPAGO_GOLDEN_MODELS"
+
+// TestPretrainedModels verifies that the pretrained models reproduce their
+// golden outputs. It's skipped unless the models are given in ModelsEnv,
+// since they are not part of the repository:
+//
+//     SPAGO_GOLDEN_MODELS=~/.spago/bert-base-cased go test ./pkg/nlp/transformers/golden
+func TestPretrainedModels(t *testing.T) {
+	paths := filepath.SplitList(os.Getenv(ModelsEnv))
+	if len(paths) == 0 {
+		t.Skipf("%s is not set", ModelsEnv)
+	}
+	for _, modelPath := range paths {
+		modelPath := modelPath
+		t.Run(filepath.Base(modelPath), func(t *testing.T) {
+			fixture, err := Load(filepath.Join(modelPath, DefaultFixtureFile))
+			require.NoError(t, err)
+			runner, err := NewRunner(modelPath)
+			require.NoError(t, err)
+			defer runner.Close()
+
+			mismatches, err := fixture.Verify(runner)
+			require.NoError(t, err)
+			for _, m := range mismatches {
+				t.Error(m)
+			}
+		})
+	}
+}
+
+func TestNewRunner(t *testing.T) {
+	dir, err := ioutil.TempDir("", "golden")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	_, err = NewRunner(dir)
+	assert.Error(t, err, "missing configuration")
+
+	config := []byte(`{"model_type": "gpt2"}`)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "config.json"), config, 0644))
+	_, err = NewRunner(dir)
+	assert.EqualError(t, err, `golden: unsupported model type "gpt2"`)
+}
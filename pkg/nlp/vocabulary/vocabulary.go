@@ -76,9 +76,7 @@ func (c *Vocabulary) MustID(term string) int {
 
 // Term returns the term given the ID, and whether or not it was found in the vocabulary.
 func (c *Vocabulary) Term(id int) (string, bool) {
-	size := atomic.LoadInt64(&c.maxID)
-	maxID := int(size)
-	if id >= maxID {
+	if id < 0 || id > int(atomic.LoadInt64(&c.maxID)) {
 		return "", false
 	}
 	return c.inverse[id], true
@@ -108,8 +106,7 @@ func (c *Vocabulary) Add(term string) int {
 
 // Size returns the size of the vocabulary.
 func (c *Vocabulary) Size() int {
-	size := atomic.LoadInt64(&c.maxID)
-	return int(size)
+	return int(atomic.LoadInt64(&c.maxID)) + 1
 }
 
 // LongestPrefix returns the longest term in the vocabulary that is the prefix of the input term
@@ -23,6 +23,24 @@ func TestNew(t *testing.T) {
 	}
 }
 
+func TestVocabulary_Size(t *testing.T) {
+	voc := vocabulary.New(nil)
+	assert.Equal(t, 0, voc.Size())
+	voc.Add("word1")
+	voc.Add("word2")
+	voc.Add("word1")
+	assert.Equal(t, 2, voc.Size())
+	assert.Equal(t, len(voc.Items()), voc.Size())
+
+	term, ok := voc.Term(1)
+	assert.True(t, ok)
+	assert.Equal(t, "word2", term)
+	_, ok = voc.Term(2)
+	assert.False(t, ok)
+	_, ok = voc.Term(-1)
+	assert.False(t, ok)
+}
+
 func TestVocabulary_LongestPrefix(t *testing.T) {
 	items := []string{"a", "aa", "aaa", "bbbb"}
 	voc := vocabulary.New(items)
@@ -0,0 +1,347 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package batch streams a large corpus through a processing function (e.g.
+// a model pipeline) with a pool of workers, for offline enrichment jobs.
+//
+// Each record of the input is written to the output (JSON Lines) with the
+// result of the function, in the same order of the input. The progress is
+// checkpointed periodically, so that an interrupted job resumes from the
+// last checkpoint when it is run again:
+//
+//     stats, err := batch.Run(ctx, batch.Config{
+//         Input:   "corpus.jsonl",
+//         Output:  "enriched.jsonl",
+//         Workers: 8,
+//     }, func(ctx context.Context, r batch.Record) (interface{}, error) {
+//         return classify(r.String("text"))
+//     })
+package batch
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"runtime"
+	"sync"
+)
+
+// Processor processes a record, returning its result. A failure is recorded
+// in the output, and the job goes on.
+type Processor func(ctx context.Context, record Record) (interface{}, error)
+
+// Config provides the configuration of a job.
+type Config struct {
+	// Input is the path of the corpus.
+	Input string
+	// Format is the format of the corpus. If empty, it is inferred from the
+	// extension of the Input (see FormatFromPath).
+	Format Format
+	// Output is the path of the output (JSON Lines).
+	Output string
+	// Checkpoint is the path of the checkpoint of the progress. If empty, it
+	// is the Output with the ".checkpoint" suffix.
+	Checkpoint string
+	// Workers is the number of records processed concurrently. If zero, it
+	// is the number of CPUs.
+	Workers int
+	// CheckpointEvery is the number of records written between checkpoints.
+	// If zero, it is 1000.
+	CheckpointEvery int
+	// ResultField is the name of the field of the result in the output. If
+	// empty, it is "result".
+	ResultField string
+	// ErrorField is the name of the field of the error in the output, for the
+	// records which failed. If empty, it is "error".
+	ErrorField string
+	// Progress, if not nil, is called at each checkpoint.
+	Progress func(stats Stats)
+}
+
+func (c Config) withDefaults() Config {
+	if c.Format == "" {
+		c.Format = FormatFromPath(c.Input)
+	}
+	if c.Checkpoint == "" {
+		c.Checkpoint = c.Output + ".checkpoint"
+	}
+	if c.Workers <= 0 {
+		c.Workers = runtime.NumCPU()
+	}
+	if c.CheckpointEvery <= 0 {
+		c.CheckpointEvery = 1000
+	}
+	if c.ResultField == "" {
+		c.ResultField = "result"
+	}
+	if c.ErrorField == "" {
+		c.ErrorField = "error"
+	}
+	return c
+}
+
+// Stats are the statistics of a job.
+type Stats struct {
+	// Skipped is the number of records processed by the previous runs.
+	Skipped int `json:"skipped"`
+	// Processed is the number of records processed by this run.
+	Processed int `json:"processed"`
+	// Failed is the number of records of this run whose processing failed.
+	Failed int `json:"failed"`
+}
+
+// checkpoint is the progress of a job, written as JSON.
+type checkpoint struct {
+	Input string `json:"input"`
+	// Records is the number of records written to the output.
+	Records int `json:"records"`
+	// OutputBytes is the size of the output with those records; anything
+	// after it has been written after the checkpoint, and is discarded.
+	OutputBytes int64 `json:"output_bytes"`
+}
+
+// job is a record to be processed, with its result.
+type job struct {
+	index  int
+	record Record
+	result interface{}
+	err    error
+}
+
+// Run runs the job, returning when the whole input has been processed or
+// the context is done. In the latter case, the records processed so far are
+// checkpointed, and the context error is returned: running the job again
+// with the same configuration resumes it.
+func Run(ctx context.Context, config Config, process Processor) (Stats, error) {
+	config = config.withDefaults()
+
+	cp, resuming, err := readCheckpoint(config.Checkpoint)
+	if err != nil {
+		return Stats{}, err
+	}
+	if resuming && cp.Input != config.Input {
+		return Stats{}, fmt.Errorf("batch: the checkpoint %q refers to the input %q", config.Checkpoint, cp.Input)
+	}
+	cp.Input = config.Input
+
+	in, err := os.Open(config.Input)
+	if err != nil {
+		return Stats{}, err
+	}
+	defer in.Close()
+	reader, err := NewReader(in, config.Format)
+	if err != nil {
+		return Stats{}, err
+	}
+	for i := 0; i < cp.Records; i++ {
+		if _, err := reader.Next(); err != nil {
+			if err == io.EOF {
+				err = fmt.Errorf("batch: the input has fewer records than the checkpoint (%d)", cp.Records)
+			}
+			return Stats{}, err
+		}
+	}
+
+	out, err := openOutput(config.Output, cp.OutputBytes)
+	if err != nil {
+		return Stats{}, err
+	}
+	defer out.Close()
+
+	r := &runner{
+		config: config,
+		cp:     cp,
+		out:    out,
+		writer: bufio.NewWriter(out),
+		stats:  Stats{Skipped: cp.Records},
+	}
+	err = r.run(ctx, reader, process)
+	return r.stats, err
+}
+
+func readCheckpoint(path string) (cp checkpoint, exists bool, err error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return checkpoint{}, false, nil
+	}
+	if err != nil {
+		return checkpoint{}, false, err
+	}
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return checkpoint{}, false, fmt.Errorf("batch: invalid checkpoint %q: %w", path, err)
+	}
+	return cp, true, nil
+}
+
+// openOutput opens the output, discarding anything after the given size.
+func openOutput(path string, size int64) (*os.File, error) {
+	out, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err := out.Truncate(size); err != nil {
+		_ = out.Close()
+		return nil, err
+	}
+	if _, err := out.Seek(size, io.SeekStart); err != nil {
+		_ = out.Close()
+		return nil, err
+	}
+	return out, nil
+}
+
+// runner is the state of a run.
+type runner struct {
+	config Config
+	cp     checkpoint
+	out    *os.File
+	writer *bufio.Writer
+	stats  Stats
+}
+
+func (r *runner) run(parent context.Context, reader Reader, process Processor) error {
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	// the slots bound the records in flight, including the ones waiting to be
+	// written in order after a slower one
+	slots := make(chan struct{}, 4*r.config.Workers)
+	jobs := make(chan *job, r.config.Workers)
+	results := make(chan *job, r.config.Workers)
+
+	var readErr error
+	go func() {
+		defer close(jobs)
+		for index := r.cp.Records; ; index++ {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			record, err := reader.Next()
+			if err != nil {
+				if err != io.EOF {
+					readErr = err
+					cancel()
+				}
+				return
+			}
+			jobs <- &job{index: index, record: record}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	wg.Add(r.config.Workers)
+	for i := 0; i < r.config.Workers; i++ {
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				j.result, j.err = process(ctx, j.record)
+				results <- j
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	var writeErr error
+	pending := make(map[int]*job)
+	next := r.cp.Records
+	for j := range results {
+		pending[j.index] = j
+		for writeErr == nil {
+			j, ok := pending[next]
+			if !ok {
+				break
+			}
+			if j.err != nil && ctx.Err() != nil {
+				// the processing has been interrupted: this record and the
+				// following ones are processed again when the job is resumed
+				writeErr = ctx.Err()
+				break
+			}
+			delete(pending, next)
+			next++
+			if writeErr = r.write(j); writeErr == nil && (next-r.cp.Records)%r.config.CheckpointEvery == 0 {
+				writeErr = r.checkpoint(next)
+			}
+			<-slots
+		}
+		if writeErr != nil {
+			cancel()
+		}
+	}
+
+	if writeErr != nil && !isContextError(writeErr) {
+		return writeErr // the output can't be trusted after the last checkpoint
+	}
+	if err := r.checkpoint(next); err != nil {
+		return err
+	}
+	if readErr != nil {
+		return readErr
+	}
+	return parent.Err()
+}
+
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// write writes the record with its result to the output.
+func (r *runner) write(j *job) error {
+	output := make(Record, len(j.record)+1)
+	for k, v := range j.record {
+		output[k] = v
+	}
+	r.stats.Processed++
+	if j.err != nil {
+		r.stats.Failed++
+		output[r.config.ErrorField] = j.err.Error()
+	} else {
+		output[r.config.ResultField] = j.result
+	}
+	data, err := json.Marshal(output)
+	if err != nil {
+		return err
+	}
+	n, err := r.writer.Write(append(data, '\n'))
+	r.cp.OutputBytes += int64(n)
+	return err
+}
+
+// checkpoint flushes the output and writes the checkpoint of the records
+// written so far.
+func (r *runner) checkpoint(records int) error {
+	if err := r.writer.Flush(); err != nil {
+		return err
+	}
+	if err := r.out.Sync(); err != nil {
+		return err
+	}
+	r.cp.Records = records
+	data, err := json.Marshal(r.cp)
+	if err != nil {
+		return err
+	}
+	// the checkpoint is replaced atomically
+	tmp := r.config.Checkpoint + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, r.config.Checkpoint); err != nil {
+		return err
+	}
+	if r.config.Progress != nil {
+		r.config.Progress(r.stats)
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package batch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeCorpus(t *testing.T, dir string, n int) string {
+	var sb strings.Builder
+	for i := 0; i < n; i++ {
+		_, _ = fmt.Fprintf(&sb, "{\"id\": %d, \"text\": \"t%d\"}\n", i, i)
+	}
+	path := filepath.Join(dir, "corpus.jsonl")
+	require.NoError(t, ioutil.WriteFile(path, []byte(sb.String()), 0644))
+	return path
+}
+
+func readOutput(t *testing.T, path string) []Record {
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	var records []Record
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var record Record
+		require.NoError(t, json.Unmarshal([]byte(line), &record))
+		records = append(records, record)
+	}
+	return records
+}
+
+func upper(_ context.Context, r Record) (interface{}, error) {
+	if r["id"].(float64) == 3 {
+		return nil, fmt.Errorf("bad record")
+	}
+	return strings.ToUpper(r.String("text")), nil
+}
+
+func TestRun(t *testing.T) {
+	dir, err := ioutil.TempDir("", "batch")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	output := filepath.Join(dir, "out.jsonl")
+	var checkpoints int
+	stats, err := Run(context.Background(), Config{
+		Input:           writeCorpus(t, dir, 50),
+		Output:          output,
+		Workers:         4,
+		CheckpointEvery: 10,
+		Progress:        func(Stats) { checkpoints++ },
+	}, upper)
+	require.NoError(t, err)
+	assert.Equal(t, Stats{Processed: 50, Failed: 1}, stats)
+	assert.Equal(t, 6, checkpoints) // every 10 records, and at the end
+
+	records := readOutput(t, output)
+	require.Len(t, records, 50)
+	for i, r := range records {
+		assert.Equal(t, float64(i), r["id"]) // in the same order of the input
+		if i == 3 {
+			assert.Equal(t, "bad record", r["error"])
+			assert.NotContains(t, r, "result")
+			continue
+		}
+		assert.Equal(t, fmt.Sprintf("T%d", i), r["result"])
+	}
+}
+
+func TestRun_Resume(t *testing.T) {
+	dir, err := ioutil.TempDir("", "batch")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	input := writeCorpus(t, dir, 50)
+	output := filepath.Join(dir, "out.jsonl")
+	config := Config{
+		Input:           input,
+		Output:          output,
+		Workers:         3,
+		CheckpointEvery: 7,
+	}
+
+	// the job is interrupted after 20 records
+	ctx, cancel := context.WithCancel(context.Background())
+	var count int32
+	stats, err := Run(ctx, config, func(ctx context.Context, r Record) (interface{}, error) {
+		if atomic.AddInt32(&count, 1) > 20 {
+			cancel()
+			return nil, ctx.Err()
+		}
+		return upper(ctx, r)
+	})
+	assert.Equal(t, context.Canceled, err)
+	assert.True(t, stats.Processed <= 20)
+	assert.Equal(t, 0, stats.Skipped)
+
+	// a partial write after the checkpoint is discarded
+	f, err := os.OpenFile(output, os.O_APPEND|os.O_WRONLY, 0644)
+	require.NoError(t, err)
+	_, _ = f.WriteString("{\"id\": 99, \"tr")
+	require.NoError(t, f.Close())
+
+	resumed, err := Run(context.Background(), config, upper)
+	require.NoError(t, err)
+	assert.Equal(t, stats.Processed, resumed.Skipped)
+	assert.Equal(t, 50, resumed.Skipped+resumed.Processed)
+
+	records := readOutput(t, output)
+	require.Len(t, records, 50)
+	for i, r := range records {
+		assert.Equal(t, float64(i), r["id"])
+	}
+
+	// a finished job has nothing left to do
+	again, err := Run(context.Background(), config, upper)
+	require.NoError(t, err)
+	assert.Equal(t, Stats{Skipped: 50}, again)
+}
+
+func TestRun_CheckpointOfAnotherInput(t *testing.T) {
+	dir, err := ioutil.TempDir("", "batch")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	output := filepath.Join(dir, "out.jsonl")
+	_, err = Run(context.Background(), Config{Input: writeCorpus(t, dir, 2), Output: output}, upper)
+	require.NoError(t, err)
+
+	other := filepath.Join(dir, "other.jsonl")
+	require.NoError(t, ioutil.WriteFile(other, []byte("{}\n"), 0644))
+	_, err = Run(context.Background(), Config{Input: other, Output: output}, upper)
+	assert.Error(t, err)
+}
+
+func TestRun_CSV(t *testing.T) {
+	dir, err := ioutil.TempDir("", "batch")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	input := filepath.Join(dir, "corpus.csv")
+	require.NoError(t, ioutil.WriteFile(input, []byte("text\nhello\nworld\n"), 0644))
+	output := filepath.Join(dir, "out.jsonl")
+	_, err = Run(context.Background(), Config{Input: input, Output: output, ResultField: "upper"},
+		func(_ context.Context, r Record) (interface{}, error) {
+			return strings.ToUpper(r.String("text")), nil
+		})
+	require.NoError(t, err)
+	assert.Equal(t, []Record{{"text": "hello", "upper": "HELLO"}, {"text": "world", "upper": "WORLD"}}, readOutput(t, output))
+}
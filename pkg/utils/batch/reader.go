@@ -0,0 +1,114 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package batch
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// Record is a record of the corpus, e.g. {"text": "..."}.
+type Record map[string]interface{}
+
+// String returns the value of the field as a string, or the empty string if
+// the field is missing or it isn't a string.
+func (r Record) String(field string) string {
+	s, _ := r[field].(string)
+	return s
+}
+
+// Format is the format of a corpus.
+type Format string
+
+const (
+	// JSONL is the JSON Lines format: one JSON object per line. The empty
+	// lines are ignored.
+	JSONL Format = "jsonl"
+	// CSV is the comma-separated values format, whose first row is the header
+	// with the names of the fields.
+	CSV Format = "csv"
+)
+
+// FormatFromPath returns the format of a file from its extension: CSV for
+// ".csv" and JSONL otherwise.
+func FormatFromPath(path string) Format {
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		return CSV
+	}
+	return JSONL
+}
+
+// Reader reads the records of a corpus one at a time.
+type Reader interface {
+	// Next returns the next record, or io.EOF at the end of the corpus.
+	Next() (Record, error)
+}
+
+// NewReader returns a new Reader of the corpus in the given format.
+func NewReader(r io.Reader, format Format) (Reader, error) {
+	switch format {
+	case JSONL:
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+		return &jsonlReader{scanner: scanner}, nil
+	case CSV:
+		return &csvReader{reader: csv.NewReader(r)}, nil
+	default:
+		return nil, fmt.Errorf("batch: invalid format %q", format)
+	}
+}
+
+type jsonlReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+func (r *jsonlReader) Next() (Record, error) {
+	for r.scanner.Scan() {
+		r.line++
+		text := strings.TrimSpace(r.scanner.Text())
+		if text == "" {
+			continue
+		}
+		var record Record
+		if err := json.Unmarshal([]byte(text), &record); err != nil {
+			return nil, fmt.Errorf("batch: line %d: %w", r.line, err)
+		}
+		return record, nil
+	}
+	if err := r.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+type csvReader struct {
+	reader *csv.Reader
+	header []string
+}
+
+func (r *csvReader) Next() (Record, error) {
+	if r.header == nil {
+		header, err := r.reader.Read()
+		if err != nil {
+			return nil, err
+		}
+		r.header = header
+	}
+	row, err := r.reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	record := make(Record, len(row))
+	for i, value := range row {
+		record[r.header[i]] = value
+	}
+	return record, nil
+}
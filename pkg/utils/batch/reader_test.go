@@ -0,0 +1,59 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package batch
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readAll(t *testing.T, r Reader) []Record {
+	var records []Record
+	for {
+		record, err := r.Next()
+		if err == io.EOF {
+			return records
+		}
+		require.NoError(t, err)
+		records = append(records, record)
+	}
+}
+
+func TestNewReader_JSONL(t *testing.T) {
+	r, err := NewReader(strings.NewReader("{\"text\": \"a\", \"id\": 1}\n\n{\"text\": \"b\"}\n"), JSONL)
+	require.NoError(t, err)
+	records := readAll(t, r)
+	assert.Equal(t, []Record{{"text": "a", "id": 1.0}, {"text": "b"}}, records)
+	assert.Equal(t, "a", records[0].String("text"))
+	assert.Equal(t, "", records[0].String("id"))
+	assert.Equal(t, "", records[0].String("missing"))
+
+	r, _ = NewReader(strings.NewReader("{\"text\": \"a\"}\n{\"text\"\n"), JSONL)
+	_, err = r.Next()
+	require.NoError(t, err)
+	_, err = r.Next()
+	assert.EqualError(t, err, "batch: line 2: unexpected end of JSON input")
+}
+
+func TestNewReader_CSV(t *testing.T) {
+	r, err := NewReader(strings.NewReader("id,text\n1,a\n2,\"b, c\"\n"), CSV)
+	require.NoError(t, err)
+	assert.Equal(t, []Record{{"id": "1", "text": "a"}, {"id": "2", "text": "b, c"}}, readAll(t, r))
+}
+
+func TestNewReader_InvalidFormat(t *testing.T) {
+	_, err := NewReader(strings.NewReader(""), "xml")
+	assert.EqualError(t, err, `batch: invalid format "xml"`)
+}
+
+func TestFormatFromPath(t *testing.T) {
+	assert.Equal(t, CSV, FormatFromPath("corpus.CSV"))
+	assert.Equal(t, JSONL, FormatFromPath("corpus.jsonl"))
+	assert.Equal(t, JSONL, FormatFromPath("corpus"))
+}
@@ -0,0 +1,528 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package columnar
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	flatbuffers "github.com/google/flatbuffers/go"
+)
+
+// The values of the Apache Arrow IPC format (see Message.fbs and Schema.fbs
+// of the Arrow format).
+const (
+	arrowMagic               = "ARROW1"
+	arrowContinuation uint32 = 0xFFFFFFFF
+
+	arrowHeaderSchema          byte = 1
+	arrowHeaderDictionaryBatch byte = 2
+	arrowHeaderRecordBatch     byte = 3
+
+	arrowTypeNull            byte = 1
+	arrowTypeInt             byte = 2
+	arrowTypeFloatingPoint   byte = 3
+	arrowTypeBinary          byte = 4
+	arrowTypeUtf8            byte = 5
+	arrowTypeBool            byte = 6
+	arrowTypeDecimal         byte = 7
+	arrowTypeDate            byte = 8
+	arrowTypeTime            byte = 9
+	arrowTypeTimestamp       byte = 10
+	arrowTypeInterval        byte = 11
+	arrowTypeList            byte = 12
+	arrowTypeStruct          byte = 13
+	arrowTypeUnion           byte = 14
+	arrowTypeFixedSizeBinary byte = 15
+	arrowTypeFixedSizeList   byte = 16
+	arrowTypeMap             byte = 17
+	arrowTypeDuration        byte = 18
+	arrowTypeLargeBinary     byte = 19
+	arrowTypeLargeUtf8       byte = 20
+	arrowTypeLargeList       byte = 21
+)
+
+// arrowField is a top-level field of an Arrow schema.
+type arrowField struct {
+	Field
+	// supported reports whether the values of the field can be decoded.
+	supported bool
+	// width is the size in bytes of the values (or of the offsets of the
+	// strings).
+	width  int
+	signed bool
+	// nodes and buffers are the numbers of field nodes and buffers of the
+	// field in a record batch, children included.
+	nodes, buffers int
+}
+
+// arrowColumn is a column of a record batch.
+type arrowColumn struct {
+	field     *arrowField
+	nullCount int
+	validity  []byte
+	offsets   []byte
+	data      []byte
+}
+
+// ArrowReader reads the rows of an Apache Arrow dataset in the IPC file or
+// stream format (e.g. written by pyarrow.ipc.new_file() or
+// pyarrow.ipc.new_stream()), one record batch at a time.
+//
+// The columns of the types Bool, Int (including the dates, times, timestamps
+// and durations), FloatingPoint (single or double precision), Utf8 and
+// Binary are supported. The compressed and the dictionary-encoded record
+// batches are not supported.
+type ArrowReader struct {
+	r      *bufio.Reader
+	closer io.Closer
+	fields []arrowField
+	schema *Schema
+	// selected are the indices of the fields of the selected columns.
+	selected []int
+	where    Predicate
+	// whereSchema and whereFields are the columns of the predicate.
+	whereSchema *Schema
+	whereFields []int
+	// columns are the columns of the current record batch, and length is
+	// its number of rows.
+	columns []arrowColumn
+	length  int
+	row     int
+}
+
+// NewArrowReader returns a new ArrowReader of the Arrow dataset in the IPC
+// file or stream format, reading its schema. The file format is read
+// sequentially, ignoring its footer.
+func NewArrowReader(r io.Reader, opts ...Option) (*ArrowReader, error) {
+	ar := &ArrowReader{r: bufio.NewReader(r)}
+	if magic, err := ar.r.Peek(len(arrowMagic)); err == nil && string(magic) == arrowMagic {
+		if _, err := ar.r.Discard(8); err != nil {
+			return nil, err
+		}
+	}
+	header, msg, _, err := ar.readMessage()
+	if err == io.EOF {
+		return nil, errors.New("columnar: missing Arrow schema")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if header != arrowHeaderSchema {
+		return nil, errors.New("columnar: the first Arrow message is not a schema")
+	}
+	if err := ar.init(msg, newOptions(opts)); err != nil {
+		return nil, err
+	}
+	return ar, nil
+}
+
+// Schema returns the schema of the rows read.
+func (ar *ArrowReader) Schema() *Schema {
+	return ar.schema
+}
+
+// Close closes the file opened by Open, if any.
+func (ar *ArrowReader) Close() error {
+	if ar.closer == nil {
+		return nil
+	}
+	return ar.closer.Close()
+}
+
+// Next returns the next row satisfying the predicate, or io.EOF at the end
+// of the dataset.
+func (ar *ArrowReader) Next() (Row, error) {
+	for {
+		for ; ar.row < ar.length; ar.row++ {
+			if ar.where != nil && !ar.where.Match(ar.newRow(ar.whereSchema, ar.whereFields, ar.row)) {
+				continue
+			}
+			row := ar.newRow(ar.schema, ar.selected, ar.row)
+			ar.row++
+			return row, nil
+		}
+		if err := ar.nextBatch(); err != nil {
+			return Row{}, err
+		}
+	}
+}
+
+func (ar *ArrowReader) newRow(schema *Schema, fields []int, i int) Row {
+	values := make([]interface{}, len(fields))
+	for j, f := range fields {
+		values[j] = ar.columns[f].value(i)
+	}
+	return Row{schema: schema, values: values}
+}
+
+// init initializes the fields of the reader from the schema message.
+func (ar *ArrowReader) init(msg flatbuffers.Table, opts *options) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New("columnar: invalid Arrow schema")
+		}
+	}()
+	var schema flatbuffers.Table
+	msg.Union(&schema, flatbuffers.UOffsetT(msg.Offset(8)))
+	if schema.GetInt16Slot(4, 0) != 0 {
+		return errors.New("columnar: big-endian Arrow data is not supported")
+	}
+	fields := tableVector(schema, 6)
+	ar.fields = make([]arrowField, len(fields))
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		if ar.fields[i], err = newArrowField(f); err != nil {
+			return err
+		}
+		names[i] = ar.fields[i].Name
+	}
+	if ar.selected, err = ar.supportedColumns(names, opts.columns); err != nil {
+		return err
+	}
+	ar.schema = ar.newSchema(ar.selected)
+	if opts.where != nil {
+		ar.where = opts.where
+		if ar.whereFields, err = ar.supportedColumns(names, opts.where.Columns()); err != nil {
+			return err
+		}
+		ar.whereSchema = ar.newSchema(ar.whereFields)
+	}
+	return nil
+}
+
+func (ar *ArrowReader) supportedColumns(names, columns []string) ([]int, error) {
+	indices, err := selectColumns(names, columns)
+	if err != nil {
+		return nil, err
+	}
+	for _, i := range indices {
+		if !ar.fields[i].supported {
+			return nil, fmt.Errorf("columnar: the type of the Arrow column %q is not supported", names[i])
+		}
+	}
+	return indices, nil
+}
+
+func (ar *ArrowReader) newSchema(indices []int) *Schema {
+	fields := make([]Field, len(indices))
+	for i, j := range indices {
+		fields[i] = ar.fields[j].Field
+	}
+	return newSchema(fields)
+}
+
+// newArrowField returns the arrowField of a Field table.
+func newArrowField(t flatbuffers.Table) (arrowField, error) {
+	f := arrowField{
+		Field: Field{
+			Name:     tableString(t, 4),
+			Nullable: t.GetBoolSlot(6, false),
+		},
+		nodes: 1,
+	}
+	typeID := t.GetByteSlot(8, 0)
+	var typ flatbuffers.Table
+	if o := t.Offset(10); o != 0 {
+		t.Union(&typ, flatbuffers.UOffsetT(o))
+	}
+	if t.Offset(12) != 0 { // dictionary-encoded, with the indices only
+		f.buffers = 2
+		return f, nil
+	}
+
+	switch typeID {
+	case arrowTypeNull:
+	case arrowTypeInt:
+		f.buffers, f.supported, f.Type = 2, true, Int
+		f.width, f.signed = int(typ.GetInt32Slot(4, 0))/8, typ.GetBoolSlot(6, false)
+	case arrowTypeDate:
+		f.buffers, f.supported, f.Type, f.signed = 2, true, Int, true
+		f.width = 4 << typ.GetInt16Slot(4, 1) // DAY (int32) or MILLISECOND (int64)
+	case arrowTypeTime:
+		f.buffers, f.supported, f.Type, f.signed = 2, true, Int, true
+		f.width = int(typ.GetInt32Slot(6, 32)) / 8
+	case arrowTypeTimestamp, arrowTypeDuration:
+		f.buffers, f.supported, f.Type, f.signed, f.width = 2, true, Int, true, 8
+	case arrowTypeFloatingPoint:
+		f.buffers, f.Type = 2, Float
+		switch typ.GetInt16Slot(4, 0) {
+		case 1:
+			f.supported, f.width = true, 4
+		case 2:
+			f.supported, f.width = true, 8
+		}
+	case arrowTypeBool:
+		f.buffers, f.supported, f.Type = 2, true, Bool
+	case arrowTypeBinary, arrowTypeUtf8:
+		f.buffers, f.supported, f.Type, f.width = 3, true, String, 4
+	case arrowTypeLargeBinary, arrowTypeLargeUtf8:
+		f.buffers, f.supported, f.Type, f.width = 3, true, String, 8
+	case arrowTypeDecimal, arrowTypeInterval, arrowTypeFixedSizeBinary:
+		f.buffers = 2
+	case arrowTypeList, arrowTypeLargeList, arrowTypeMap:
+		f.buffers = 2
+	case arrowTypeStruct, arrowTypeFixedSizeList:
+		f.buffers = 1
+	case arrowTypeUnion:
+		f.buffers = 1 + int(typ.GetInt16Slot(4, 0)) // sparse or dense
+	default:
+		return f, fmt.Errorf("columnar: unknown type of the Arrow column %q", f.Name)
+	}
+	if f.supported && f.width != 1 && f.width != 2 && f.width != 4 && f.width != 8 && f.Type != Bool {
+		return f, fmt.Errorf("columnar: invalid width of the Arrow column %q", f.Name)
+	}
+
+	for _, child := range tableVector(t, 14) {
+		c, err := newArrowField(child)
+		if err != nil {
+			return f, err
+		}
+		f.nodes += c.nodes
+		f.buffers += c.buffers
+	}
+	return f, nil
+}
+
+// tableString returns the string in the given slot.
+func tableString(t flatbuffers.Table, slot flatbuffers.VOffsetT) string {
+	o := flatbuffers.UOffsetT(t.Offset(slot))
+	if o == 0 {
+		return ""
+	}
+	return string(t.ByteVector(t.Pos + o))
+}
+
+// tableVector returns the tables of the vector in the given slot.
+func tableVector(t flatbuffers.Table, slot flatbuffers.VOffsetT) []flatbuffers.Table {
+	o := flatbuffers.UOffsetT(t.Offset(slot))
+	if o == 0 {
+		return nil
+	}
+	tables := make([]flatbuffers.Table, t.VectorLen(o))
+	for i := range tables {
+		tables[i] = flatbuffers.Table{Bytes: t.Bytes, Pos: t.Indirect(t.Vector(o) + flatbuffers.UOffsetT(i*4))}
+	}
+	return tables
+}
+
+// readMessage reads the next encapsulated message, returning the type of its
+// header, the Message table and the body, or io.EOF at the end of the
+// stream.
+func (ar *ArrowReader) readMessage() (byte, flatbuffers.Table, []byte, error) {
+	var prefix [4]byte
+	if _, err := io.ReadFull(ar.r, prefix[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			err = errors.New("columnar: truncated Arrow message")
+		}
+		return 0, flatbuffers.Table{}, nil, err
+	}
+	metadataLen := binary.LittleEndian.Uint32(prefix[:])
+	if metadataLen == arrowContinuation {
+		if _, err := io.ReadFull(ar.r, prefix[:]); err != nil {
+			return 0, flatbuffers.Table{}, nil, errors.New("columnar: truncated Arrow message")
+		}
+		metadataLen = binary.LittleEndian.Uint32(prefix[:])
+	}
+	if metadataLen == 0 { // end of the stream
+		return 0, flatbuffers.Table{}, nil, io.EOF
+	}
+	metadata := make([]byte, metadataLen)
+	if _, err := io.ReadFull(ar.r, metadata); err != nil {
+		return 0, flatbuffers.Table{}, nil, errors.New("columnar: truncated Arrow message")
+	}
+	header, msg, bodyLen, err := parseArrowMessage(metadata)
+	if err != nil {
+		return 0, flatbuffers.Table{}, nil, err
+	}
+	body := make([]byte, bodyLen)
+	if _, err := io.ReadFull(ar.r, body); err != nil {
+		return 0, flatbuffers.Table{}, nil, errors.New("columnar: truncated Arrow message")
+	}
+	return header, msg, body, nil
+}
+
+func parseArrowMessage(metadata []byte) (header byte, msg flatbuffers.Table, bodyLen int64, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New("columnar: invalid Arrow message")
+		}
+	}()
+	msg = flatbuffers.Table{Bytes: metadata, Pos: flatbuffers.GetUOffsetT(metadata)}
+	header = msg.GetByteSlot(6, 0)
+	bodyLen = msg.GetInt64Slot(10, 0)
+	if bodyLen < 0 || bodyLen > math.MaxInt32 || (header != 0 && msg.Offset(8) == 0) {
+		return 0, msg, 0, errors.New("columnar: invalid Arrow message")
+	}
+	return header, msg, bodyLen, nil
+}
+
+// nextBatch reads the next record batch, skipping the other messages.
+func (ar *ArrowReader) nextBatch() error {
+	for {
+		header, msg, body, err := ar.readMessage()
+		if err != nil {
+			return err
+		}
+		switch header {
+		case arrowHeaderRecordBatch:
+			return ar.setBatch(msg, body)
+		case arrowHeaderSchema:
+			return errors.New("columnar: unexpected Arrow schema")
+		}
+	}
+}
+
+// setBatch sets the columns of the record batch.
+func (ar *ArrowReader) setBatch(msg flatbuffers.Table, body []byte) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New("columnar: invalid Arrow record batch")
+		}
+	}()
+	var batch flatbuffers.Table
+	msg.Union(&batch, flatbuffers.UOffsetT(msg.Offset(8)))
+	if batch.Offset(10) != 0 {
+		return errors.New("columnar: compressed Arrow record batches are not supported")
+	}
+	length := batch.GetInt64Slot(4, 0)
+	nodes := flatbuffers.UOffsetT(batch.Offset(6))
+	buffers := flatbuffers.UOffsetT(batch.Offset(8))
+	if length < 0 || length > math.MaxInt32 {
+		return errors.New("columnar: invalid Arrow record batch")
+	}
+
+	node, buffer := 0, 0
+	numNodes, numBuffers := 0, 0
+	if nodes != 0 {
+		numNodes = batch.VectorLen(nodes)
+	}
+	if buffers != 0 {
+		numBuffers = batch.VectorLen(buffers)
+	}
+	getBuffer := func(i int) []byte {
+		pos := batch.Vector(buffers) + flatbuffers.UOffsetT(i*16)
+		offset, size := batch.GetInt64(pos), batch.GetInt64(pos+8)
+		if offset < 0 || size < 0 || offset+size > int64(len(body)) {
+			panic("columnar: buffer out of range")
+		}
+		return body[offset : offset+size]
+	}
+
+	columns := make([]arrowColumn, len(ar.fields))
+	for i := range ar.fields {
+		f := &ar.fields[i]
+		if node+f.nodes > numNodes || buffer+f.buffers > numBuffers {
+			return errors.New("columnar: invalid Arrow record batch")
+		}
+		pos := batch.Vector(nodes) + flatbuffers.UOffsetT(node*16)
+		if batch.GetInt64(pos) != length {
+			return errors.New("columnar: invalid Arrow record batch")
+		}
+		columns[i] = arrowColumn{
+			field:     f,
+			nullCount: int(batch.GetInt64(pos + 8)),
+		}
+		if f.supported {
+			c := &columns[i]
+			c.validity = getBuffer(buffer)
+			if f.Type == String {
+				c.offsets, c.data = getBuffer(buffer+1), getBuffer(buffer+2)
+			} else {
+				c.data = getBuffer(buffer + 1)
+			}
+			if err := c.validate(int(length)); err != nil {
+				return err
+			}
+		}
+		node += f.nodes
+		buffer += f.buffers
+	}
+	ar.columns, ar.length, ar.row = columns, int(length), 0
+	return nil
+}
+
+// validate checks that the buffers of the column hold the given number of
+// values.
+func (c *arrowColumn) validate(length int) error {
+	if c.nullCount > 0 && len(c.validity)*8 < length {
+		return fmt.Errorf("columnar: truncated Arrow column %q", c.field.Name)
+	}
+	switch c.field.Type {
+	case Bool:
+		if len(c.data)*8 < length {
+			return fmt.Errorf("columnar: truncated Arrow column %q", c.field.Name)
+		}
+	case String:
+		if len(c.offsets) < (length+1)*c.field.width {
+			return fmt.Errorf("columnar: truncated Arrow column %q", c.field.Name)
+		}
+		for i := 0; i <= length; i++ {
+			if o := c.offset(i); o < 0 || o > int64(len(c.data)) || (i > 0 && o < c.offset(i-1)) {
+				return fmt.Errorf("columnar: invalid offsets of the Arrow column %q", c.field.Name)
+			}
+		}
+	default:
+		if len(c.data) < length*c.field.width {
+			return fmt.Errorf("columnar: truncated Arrow column %q", c.field.Name)
+		}
+	}
+	return nil
+}
+
+// value returns the i-th value of the column, or nil if it is null.
+func (c *arrowColumn) value(i int) interface{} {
+	if c.nullCount > 0 && c.validity[i>>3]&(1<<(i&7)) == 0 {
+		return nil
+	}
+	f := c.field
+	switch f.Type {
+	case Bool:
+		return c.data[i>>3]&(1<<(i&7)) != 0
+	case String:
+		return string(c.data[c.offset(i):c.offset(i+1)])
+	case Float:
+		if f.width == 4 {
+			return float64(math.Float32frombits(binary.LittleEndian.Uint32(c.data[i*4:])))
+		}
+		return math.Float64frombits(binary.LittleEndian.Uint64(c.data[i*8:]))
+	default:
+		return decodeInt(c.data[i*f.width:], f.width, f.signed)
+	}
+}
+
+func (c *arrowColumn) offset(i int) int64 {
+	if c.field.width == 4 {
+		return int64(int32(binary.LittleEndian.Uint32(c.offsets[i*4:])))
+	}
+	return int64(binary.LittleEndian.Uint64(c.offsets[i*8:]))
+}
+
+// decodeInt decodes a little-endian integer of the given width.
+func decodeInt(b []byte, width int, signed bool) int64 {
+	switch width {
+	case 1:
+		if signed {
+			return int64(int8(b[0]))
+		}
+		return int64(b[0])
+	case 2:
+		if signed {
+			return int64(int16(binary.LittleEndian.Uint16(b)))
+		}
+		return int64(binary.LittleEndian.Uint16(b))
+	case 4:
+		if signed {
+			return int64(int32(binary.LittleEndian.Uint32(b)))
+		}
+		return int64(binary.LittleEndian.Uint32(b))
+	default:
+		return int64(binary.LittleEndian.Uint64(b))
+	}
+}
@@ -0,0 +1,315 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package columnar
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"testing"
+
+	flatbuffers "github.com/google/flatbuffers/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewArrowReader(t *testing.T) {
+	_, err := NewArrowReader(bytes.NewReader(testArrowStream(true)))
+	assert.EqualError(t, err, `columnar: the type of the Arrow column "tags" is not supported`)
+
+	_, err = NewArrowReader(bytes.NewReader(testArrowStream(true)), Columns("id", "foo"))
+	assert.EqualError(t, err, `columnar: the column "foo" doesn't exist`)
+
+	_, err = NewArrowReader(bytes.NewReader(nil))
+	assert.Error(t, err)
+
+	r, err := NewArrowReader(bytes.NewReader(testArrowStream(true)), Columns("label", "id"))
+	require.NoError(t, err)
+	assert.Equal(t, []Field{
+		{Name: "label", Type: String},
+		{Name: "id", Type: Int},
+	}, r.Schema().Fields)
+	assert.NoError(t, r.Close())
+}
+
+func TestArrowReader_Next(t *testing.T) {
+	for name, data := range map[string][]byte{
+		"stream":        testArrowStream(true),
+		"legacy stream": testArrowStream(false),
+		"file":          testArrowFile(),
+	} {
+		t.Run(name, func(t *testing.T) {
+			r, err := NewArrowReader(bytes.NewReader(data), Columns("id", "text", "score", "label", "ok"))
+			require.NoError(t, err)
+			rows, err := ReadAll(r)
+			require.NoError(t, err)
+			assert.Equal(t, testRows, valuesOf(rows))
+			assert.Equal(t, "ccc", rows[2].String("text"))
+			assert.True(t, rows[1].IsNull("text"))
+		})
+	}
+}
+
+func TestArrowReader_Where(t *testing.T) {
+	r, err := NewArrowReader(bytes.NewReader(testArrowStream(true)),
+		Columns("id"),
+		Where(And(Eq("label", "pos"), NotNull("text"))))
+	require.NoError(t, err)
+	rows, err := ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, [][]interface{}{{int64(1)}, {int64(3)}}, valuesOf(rows))
+
+	_, err = NewArrowReader(bytes.NewReader(testArrowStream(true)), Where(NotNull("tags")))
+	assert.Error(t, err)
+}
+
+func TestArrowReader_Truncated(t *testing.T) {
+	data := testArrowStream(true)
+	r, err := NewArrowReader(bytes.NewReader(data[:len(data)-40]), Columns("id"))
+	require.NoError(t, err)
+	_, err = ReadAll(r)
+	assert.Error(t, err)
+}
+
+// testRows are the values of the columns id, text, score, label and ok of the
+// test datasets.
+var testRows = [][]interface{}{
+	{int64(1), "a", 0.5, "pos", true},
+	{int64(2), nil, 1.5, "neg", false},
+	{int64(3), "ccc", 2.5, "pos", true},
+	{int64(4), "dd", 3.5, "neg", false},
+	{int64(5), "e", 4.5, "neg", false},
+}
+
+func valuesOf(rows []Row) [][]interface{} {
+	values := make([][]interface{}, len(rows))
+	for i, r := range rows {
+		values[i] = r.Values()
+	}
+	return values
+}
+
+// testArrowFile returns the test dataset in the Arrow IPC file format.
+func testArrowFile() []byte {
+	var buf bytes.Buffer
+	buf.WriteString("ARROW1\x00\x00")
+	buf.Write(testArrowStream(true))
+	footer := []byte("not a real footer") // ignored by the reader
+	buf.Write(footer)
+	binary.Write(&buf, binary.LittleEndian, int32(len(footer)))
+	buf.WriteString("ARROW1")
+	return buf.Bytes()
+}
+
+// testArrowStream returns the test dataset in the Arrow IPC stream format,
+// with or without the continuation markers, in two record batches, with the
+// columns id (int32), text (nullable utf8), tags (unsupported list of utf8),
+// score (double), label (utf8) and ok (bool).
+func testArrowStream(continuation bool) []byte {
+	var buf bytes.Buffer
+	writeArrowMessage(&buf, testArrowSchema(), nil, continuation)
+	for _, batch := range [][]arrowTestArray{
+		{
+			int32Array(1, 2, 3),
+			utf8Array("a", nil, "ccc"),
+			listArray(0, 1, 1, 2), utf8Array("x", "y"),
+			float64Array(0.5, 1.5, 2.5),
+			utf8Array("pos", "neg", "pos"),
+			boolArray(true, false, true),
+		},
+		{
+			int32Array(4, 5),
+			utf8Array("dd", "e"),
+			listArray(0, 0, 0), utf8Array(),
+			float64Array(3.5, 4.5),
+			utf8Array("neg", "neg"),
+			boolArray(false, false),
+		},
+	} {
+		header, body := testArrowBatch(batch)
+		writeArrowMessage(&buf, header, body, continuation)
+	}
+	if continuation {
+		binary.Write(&buf, binary.LittleEndian, arrowContinuation)
+	}
+	binary.Write(&buf, binary.LittleEndian, uint32(0))
+	return buf.Bytes()
+}
+
+// arrowTestArray is an array of a record batch.
+type arrowTestArray struct {
+	length, nullCount int64
+	buffers           [][]byte
+}
+
+func int32Array(values ...int32) arrowTestArray {
+	data := make([]byte, 4*len(values))
+	for i, v := range values {
+		binary.LittleEndian.PutUint32(data[i*4:], uint32(v))
+	}
+	return arrowTestArray{length: int64(len(values)), buffers: [][]byte{nil, data}}
+}
+
+func float64Array(values ...float64) arrowTestArray {
+	data := make([]byte, 8*len(values))
+	for i, v := range values {
+		binary.LittleEndian.PutUint64(data[i*8:], math.Float64bits(v))
+	}
+	return arrowTestArray{length: int64(len(values)), buffers: [][]byte{nil, data}}
+}
+
+func boolArray(values ...bool) arrowTestArray {
+	data := make([]byte, (len(values)+7)/8)
+	for i, v := range values {
+		if v {
+			data[i/8] |= 1 << (i % 8)
+		}
+	}
+	return arrowTestArray{length: int64(len(values)), buffers: [][]byte{nil, data}}
+}
+
+func utf8Array(values ...interface{}) arrowTestArray {
+	a := arrowTestArray{length: int64(len(values))}
+	validity := make([]byte, (len(values)+7)/8)
+	offsets := make([]byte, 4*(len(values)+1))
+	var data []byte
+	for i, v := range values {
+		if s, ok := v.(string); ok {
+			validity[i/8] |= 1 << (i % 8)
+			data = append(data, s...)
+		} else {
+			a.nullCount++
+		}
+		binary.LittleEndian.PutUint32(offsets[(i+1)*4:], uint32(len(data)))
+	}
+	if a.nullCount == 0 {
+		validity = nil
+	}
+	a.buffers = [][]byte{validity, offsets, data}
+	return a
+}
+
+func listArray(offsets ...int32) arrowTestArray {
+	a := int32Array(offsets...)
+	a.length--
+	return a
+}
+
+// testArrowSchema returns the Message table of the schema of the test dataset.
+func testArrowSchema() []byte {
+	b := flatbuffers.NewBuilder(256)
+	emptyTable := func() flatbuffers.UOffsetT {
+		b.StartObject(0)
+		return b.EndObject()
+	}
+	b.StartObject(2) // Int
+	b.PrependBoolSlot(1, true, false)
+	b.PrependInt32Slot(0, 32, 0)
+	intType := b.EndObject()
+	b.StartObject(1) // FloatingPoint
+	b.PrependInt16Slot(0, 2, 0)
+	doubleType := b.EndObject()
+
+	tagsChild := buildArrowField(b, "item", true, arrowTypeUtf8, emptyTable(), nil)
+	fields := []flatbuffers.UOffsetT{
+		buildArrowField(b, "id", false, arrowTypeInt, intType, nil),
+		buildArrowField(b, "text", true, arrowTypeUtf8, emptyTable(), nil),
+		buildArrowField(b, "tags", false, arrowTypeList, emptyTable(), []flatbuffers.UOffsetT{tagsChild}),
+		buildArrowField(b, "score", false, arrowTypeFloatingPoint, doubleType, nil),
+		buildArrowField(b, "label", false, arrowTypeUtf8, emptyTable(), nil),
+		buildArrowField(b, "ok", false, arrowTypeBool, emptyTable(), nil),
+	}
+	vector := buildOffsetVector(b, fields)
+	b.StartObject(4) // Schema
+	b.PrependUOffsetTSlot(1, vector, 0)
+	schema := b.EndObject()
+	return finishArrowMessage(b, arrowHeaderSchema, schema, 0)
+}
+
+func buildArrowField(b *flatbuffers.Builder, name string, nullable bool, typeID byte, typ flatbuffers.UOffsetT, children []flatbuffers.UOffsetT) flatbuffers.UOffsetT {
+	n := b.CreateString(name)
+	var vector flatbuffers.UOffsetT
+	if children != nil {
+		vector = buildOffsetVector(b, children)
+	}
+	b.StartObject(7) // Field
+	b.PrependUOffsetTSlot(5, vector, 0)
+	b.PrependUOffsetTSlot(3, typ, 0)
+	b.PrependByteSlot(2, typeID, 0)
+	b.PrependBoolSlot(1, nullable, false)
+	b.PrependUOffsetTSlot(0, n, 0)
+	return b.EndObject()
+}
+
+func buildOffsetVector(b *flatbuffers.Builder, offsets []flatbuffers.UOffsetT) flatbuffers.UOffsetT {
+	b.StartVector(4, len(offsets), 4)
+	for i := len(offsets) - 1; i >= 0; i-- {
+		b.PrependUOffsetT(offsets[i])
+	}
+	return b.EndVector(len(offsets))
+}
+
+// testArrowBatch returns the Message table of a record batch of the arrays
+// (in depth-first order), and its body.
+func testArrowBatch(arrays []arrowTestArray) ([]byte, []byte) {
+	var body []byte
+	var buffers [][2]int64
+	for _, a := range arrays {
+		for _, buf := range a.buffers {
+			buffers = append(buffers, [2]int64{int64(len(body)), int64(len(buf))})
+			body = append(body, buf...)
+			for len(body)%8 != 0 {
+				body = append(body, 0)
+			}
+		}
+	}
+
+	b := flatbuffers.NewBuilder(256)
+	b.StartVector(16, len(arrays), 8)
+	for i := len(arrays) - 1; i >= 0; i-- {
+		b.Prep(8, 16) // FieldNode
+		b.PrependInt64(arrays[i].nullCount)
+		b.PrependInt64(arrays[i].length)
+	}
+	nodes := b.EndVector(len(arrays))
+	b.StartVector(16, len(buffers), 8)
+	for i := len(buffers) - 1; i >= 0; i-- {
+		b.Prep(8, 16) // Buffer
+		b.PrependInt64(buffers[i][1])
+		b.PrependInt64(buffers[i][0])
+	}
+	vector := b.EndVector(len(buffers))
+	b.StartObject(4) // RecordBatch
+	b.PrependUOffsetTSlot(2, vector, 0)
+	b.PrependUOffsetTSlot(1, nodes, 0)
+	b.PrependInt64Slot(0, arrays[0].length, 0)
+	batch := b.EndObject()
+	return finishArrowMessage(b, arrowHeaderRecordBatch, batch, int64(len(body))), body
+}
+
+func finishArrowMessage(b *flatbuffers.Builder, headerType byte, header flatbuffers.UOffsetT, bodyLen int64) []byte {
+	b.StartObject(5) // Message
+	b.PrependInt64Slot(3, bodyLen, 0)
+	b.PrependUOffsetTSlot(2, header, 0)
+	b.PrependByteSlot(1, headerType, 0)
+	b.PrependInt16Slot(0, 4, 0)
+	b.Finish(b.EndObject())
+	return b.FinishedBytes()
+}
+
+// writeArrowMessage writes an encapsulated message.
+func writeArrowMessage(w io.Writer, metadata, body []byte, continuation bool) {
+	prefixLen := 4
+	if continuation {
+		prefixLen = 8
+		binary.Write(w, binary.LittleEndian, arrowContinuation)
+	}
+	padded := (prefixLen+len(metadata)+7)&^7 - prefixLen
+	binary.Write(w, binary.LittleEndian, uint32(padded))
+	w.Write(metadata)
+	w.Write(make([]byte, padded-len(metadata)))
+	w.Write(body)
+}
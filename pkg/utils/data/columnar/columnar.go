@@ -0,0 +1,290 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package columnar reads the datasets stored in the columnar formats of the
+// data warehouses (Apache Parquet and Apache Arrow IPC files), streaming their
+// rows so that they can be fed to the training of the models.
+//
+// Only the flat columns of the primitive types are supported, read as the
+// types Bool, Int, Float and String. The columns to read can be selected,
+// and a Predicate (e.g. on the label or on the text of the examples) is
+// evaluated before decoding the other columns, skipping the whole Parquet
+// row groups whose statistics rule out any match:
+//
+//     r, err := columnar.Open("train.parquet",
+//         columnar.Columns("text", "label"),
+//         columnar.Where(columnar.In("label", "positive", "negative")))
+//     if err != nil {
+//         return err
+//     }
+//     defer r.Close()
+//     rows, err := columnar.ReadAll(r)
+package columnar
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+)
+
+// Type is the type of the values of a column.
+type Type int
+
+const (
+	// Bool is the type of the boolean values (bool).
+	Bool Type = iota
+	// Int is the type of the integer values (int64).
+	Int
+	// Float is the type of the floating-point values (float64).
+	Float
+	// String is the type of the string and binary values (string).
+	String
+)
+
+// String returns the name of the type.
+func (t Type) String() string {
+	switch t {
+	case Bool:
+		return "bool"
+	case Int:
+		return "int"
+	case Float:
+		return "float"
+	case String:
+		return "string"
+	default:
+		return fmt.Sprintf("Type(%d)", int(t))
+	}
+}
+
+// Field describes a column.
+type Field struct {
+	// Name is the name of the column.
+	Name string
+	// Type is the type of the values.
+	Type Type
+	// Nullable reports whether the column can contain null values.
+	Nullable bool
+}
+
+// Schema describes the columns of the rows read.
+type Schema struct {
+	// Fields are the columns, in order.
+	Fields []Field
+	index  map[string]int
+}
+
+func newSchema(fields []Field) *Schema {
+	s := &Schema{Fields: fields, index: make(map[string]int, len(fields))}
+	for i, f := range fields {
+		s.index[f.Name] = i
+	}
+	return s
+}
+
+// Index returns the index of the column with the given name, or -1 if it
+// doesn't exist.
+func (s *Schema) Index(name string) int {
+	if i, ok := s.index[name]; ok {
+		return i
+	}
+	return -1
+}
+
+// Row is a row of a dataset. The values are of the Go types corresponding to
+// the Type of the columns, or nil for the null ones.
+type Row struct {
+	schema *Schema
+	values []interface{}
+}
+
+// Schema returns the schema of the row.
+func (r Row) Schema() *Schema {
+	return r.schema
+}
+
+// Values returns the values of the row, in the order of the columns.
+func (r Row) Values() []interface{} {
+	return r.values
+}
+
+// Value returns the value of the column with the given name, or nil if it is
+// null. It panics if the column doesn't exist.
+func (r Row) Value(name string) interface{} {
+	i := r.schema.Index(name)
+	if i < 0 {
+		panic(fmt.Sprintf("columnar: the column %q doesn't exist", name))
+	}
+	return r.values[i]
+}
+
+// IsNull reports whether the value of the column is null.
+func (r Row) IsNull(name string) bool {
+	return r.Value(name) == nil
+}
+
+// Bool returns the value of a Bool column (false if null).
+func (r Row) Bool(name string) bool {
+	v, _ := r.typed(name, Bool).(bool)
+	return v
+}
+
+// Int returns the value of an Int column (0 if null).
+func (r Row) Int(name string) int64 {
+	v, _ := r.typed(name, Int).(int64)
+	return v
+}
+
+// Float returns the value of a Float or Int column (0 if null).
+func (r Row) Float(name string) mat.Float {
+	switch v := r.Value(name).(type) {
+	case float64:
+		return mat.Float(v)
+	case int64:
+		return mat.Float(v)
+	case nil:
+		return 0
+	default:
+		panic(fmt.Sprintf("columnar: the column %q is not numeric", name))
+	}
+}
+
+// String returns the value of a String column ("" if null).
+func (r Row) String(name string) string {
+	v, _ := r.typed(name, String).(string)
+	return v
+}
+
+func (r Row) typed(name string, t Type) interface{} {
+	v := r.Value(name)
+	if r.schema.Fields[r.schema.Index(name)].Type != t {
+		panic(fmt.Sprintf("columnar: the column %q is not of type %s", name, t))
+	}
+	return v
+}
+
+// Reader is implemented by the readers of the datasets.
+type Reader interface {
+	// Schema returns the schema of the rows read.
+	Schema() *Schema
+	// Next returns the next row, or io.EOF at the end of the dataset.
+	Next() (Row, error)
+	// Close releases the resources of the reader.
+	Close() error
+}
+
+// ReadAll reads all the remaining rows, e.g. to train a model on a dataset
+// which fits in memory, visiting the examples by index.
+func ReadAll(r Reader) ([]Row, error) {
+	rows := make([]Row, 0)
+	for {
+		row, err := r.Next()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+}
+
+// options are the options of the readers.
+type options struct {
+	columns []string
+	where   Predicate
+}
+
+// Option allows to configure a reader with your specific needs.
+type Option func(*options)
+
+// Columns selects the columns to read, in order. By default, all the columns
+// are read.
+func Columns(names ...string) Option {
+	return func(o *options) {
+		o.columns = names
+	}
+}
+
+// Where sets the predicate the rows must satisfy. The columns of the
+// predicate don't need to be selected.
+func Where(p Predicate) Option {
+	return func(o *options) {
+		o.where = p
+	}
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// selectColumns returns the indices of the columns with the given names among
+// the available ones, or all of them if no names are given.
+func selectColumns(available []string, names []string) ([]int, error) {
+	if names == nil {
+		indices := make([]int, len(available))
+		for i := range indices {
+			indices[i] = i
+		}
+		return indices, nil
+	}
+	indices := make([]int, len(names))
+	for i, name := range names {
+		indices[i] = -1
+		for j, a := range available {
+			if a == name {
+				indices[i] = j
+				break
+			}
+		}
+		if indices[i] < 0 {
+			return nil, fmt.Errorf("columnar: the column %q doesn't exist", name)
+		}
+	}
+	return indices, nil
+}
+
+const parquetMagic = "PAR1"
+
+// Open opens a Parquet or an Arrow IPC (file or stream) dataset, detected by
+// its content.
+func Open(filename string, opts ...Option) (Reader, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	magic := make([]byte, len(parquetMagic))
+	if _, err := io.ReadFull(f, magic); err == nil && bytes.Equal(magic, []byte(parquetMagic)) {
+		info, err := f.Stat()
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		r, err := NewParquetReader(f, info.Size(), opts...)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		r.closer = f
+		return r, nil
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		f.Close()
+		return nil, err
+	}
+	r, err := NewArrowReader(f, opts...)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	r.closer = f
+	return r, nil
+}
@@ -0,0 +1,65 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package columnar
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	mat "github.com/nlpodyssey/spago/pkg/mat32"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRow(t *testing.T) {
+	schema := newSchema([]Field{
+		{Name: "ok", Type: Bool},
+		{Name: "id", Type: Int},
+		{Name: "score", Type: Float, Nullable: true},
+		{Name: "text", Type: String},
+	})
+	assert.Equal(t, 2, schema.Index("score"))
+	assert.Equal(t, -1, schema.Index("foo"))
+
+	row := Row{schema: schema, values: []interface{}{true, int64(3), nil, "foo"}}
+	assert.Same(t, schema, row.Schema())
+	assert.True(t, row.Bool("ok"))
+	assert.Equal(t, int64(3), row.Int("id"))
+	assert.Equal(t, mat.Float(3), row.Float("id"))
+	assert.Equal(t, mat.Float(0), row.Float("score"))
+	assert.True(t, row.IsNull("score"))
+	assert.Equal(t, "foo", row.String("text"))
+
+	assert.Panics(t, func() { row.Value("foo") })
+	assert.Panics(t, func() { row.Int("text") })
+	assert.Panics(t, func() { row.Float("text") })
+}
+
+func TestOpen(t *testing.T) {
+	dir, err := ioutil.TempDir("", "spago-columnar-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	parquetData, _ := testParquetFile()
+	for name, data := range map[string][]byte{
+		"train.parquet": parquetData,
+		"train.arrow":   testArrowFile(),
+		"train.arrows":  testArrowStream(true),
+	} {
+		filename := filepath.Join(dir, name)
+		require.NoError(t, ioutil.WriteFile(filename, data, 0644))
+		r, err := Open(filename, Columns("label"), Where(Eq("id", 4)))
+		require.NoError(t, err, name)
+		rows, err := ReadAll(r)
+		require.NoError(t, err, name)
+		assert.Equal(t, [][]interface{}{{"neg"}}, valuesOf(rows), name)
+		assert.NoError(t, r.Close())
+	}
+
+	_, err = Open(filepath.Join(dir, "missing.parquet"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,666 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package columnar
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"strings"
+
+	"github.com/golang/snappy"
+)
+
+// The values of the Apache Parquet format (see parquet.thrift).
+const (
+	parquetBoolean           int64 = 0
+	parquetInt32             int64 = 1
+	parquetInt64             int64 = 2
+	parquetInt96             int64 = 3
+	parquetFloat             int64 = 4
+	parquetDouble            int64 = 5
+	parquetByteArray         int64 = 6
+	parquetFixedLenByteArray int64 = 7
+
+	parquetRequired int64 = 0
+	parquetOptional int64 = 1
+	parquetRepeated int64 = 2
+
+	parquetUncompressed int64 = 0
+	parquetSnappy       int64 = 1
+	parquetGzip         int64 = 2
+
+	parquetDataPage       int64 = 0
+	parquetDictionaryPage int64 = 2
+	parquetDataPageV2     int64 = 3
+
+	parquetPlain           int64 = 0
+	parquetPlainDictionary int64 = 2
+	parquetRLE             int64 = 3
+	parquetRLEDictionary   int64 = 8
+)
+
+// parquetColumn is a leaf column of a Parquet schema.
+type parquetColumn struct {
+	Field
+	// supported reports whether the values of the column can be decoded.
+	supported  bool
+	physical   int64
+	typeLength int
+}
+
+// ParquetReader reads the rows of an Apache Parquet file, one row group at a
+// time.
+//
+// The flat (i.e. not nested nor repeated) columns of all the physical types
+// but INT96 are supported, compressed with Snappy or Gzip, or uncompressed,
+// in the PLAIN and dictionary encodings of the data pages (v1 and v2), e.g.
+// as written by pyarrow.parquet.write_table() with the default options.
+//
+// When a Predicate is set, the row groups whose statistics rule out any match
+// are skipped, and the other selected columns of a row group are decoded only
+// if any row satisfies the predicate.
+type ParquetReader struct {
+	r       io.ReaderAt
+	closer  io.Closer
+	columns []parquetColumn
+	schema  *Schema
+	// selected are the indices of the columns selected.
+	selected []int
+	where    Predicate
+	// whereSchema and whereColumns are the columns of the predicate.
+	whereSchema  *Schema
+	whereColumns []int
+	rowGroups    []thriftValues
+	// rowGroup is the index of the next row group.
+	rowGroup int
+	// values are the values of the decoded columns of the current row group,
+	// and rows are the indices of its rows satisfying the predicate.
+	values map[int][]interface{}
+	rows   []int
+	row    int
+}
+
+// NewParquetReader returns a new ParquetReader of the Parquet file of the
+// given size, reading its metadata.
+func NewParquetReader(r io.ReaderAt, size int64, opts ...Option) (*ParquetReader, error) {
+	footerLen := int64(8)
+	if size < int64(len(parquetMagic))+footerLen {
+		return nil, errors.New("columnar: invalid Parquet file")
+	}
+	footer := make([]byte, footerLen)
+	if _, err := r.ReadAt(footer, size-footerLen); err != nil {
+		return nil, err
+	}
+	if string(footer[4:]) != parquetMagic {
+		return nil, errors.New("columnar: invalid Parquet file")
+	}
+	metadataLen := int64(binary.LittleEndian.Uint32(footer))
+	if metadataLen > size-footerLen-int64(len(parquetMagic)) {
+		return nil, errors.New("columnar: invalid Parquet file")
+	}
+	metadata := make([]byte, metadataLen)
+	if _, err := r.ReadAt(metadata, size-footerLen-metadataLen); err != nil {
+		return nil, err
+	}
+	fileMetadata, _, err := decodeThrift(metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	pr := &ParquetReader{r: r}
+	if err := pr.init(fileMetadata, newOptions(opts)); err != nil {
+		return nil, err
+	}
+	return pr, nil
+}
+
+// Schema returns the schema of the rows read.
+func (pr *ParquetReader) Schema() *Schema {
+	return pr.schema
+}
+
+// Close closes the file opened by Open, if any.
+func (pr *ParquetReader) Close() error {
+	if pr.closer == nil {
+		return nil
+	}
+	return pr.closer.Close()
+}
+
+// Next returns the next row satisfying the predicate, or io.EOF at the end
+// of the file.
+func (pr *ParquetReader) Next() (Row, error) {
+	for pr.row == len(pr.rows) {
+		if pr.rowGroup == len(pr.rowGroups) {
+			return Row{}, io.EOF
+		}
+		if err := pr.loadRowGroup(pr.rowGroups[pr.rowGroup]); err != nil {
+			return Row{}, err
+		}
+		pr.rowGroup++
+	}
+	i := pr.rows[pr.row]
+	pr.row++
+	return pr.newRow(pr.schema, pr.selected, i), nil
+}
+
+func (pr *ParquetReader) newRow(schema *Schema, columns []int, i int) Row {
+	values := make([]interface{}, len(columns))
+	for j, c := range columns {
+		values[j] = pr.values[c][i]
+	}
+	return Row{schema: schema, values: values}
+}
+
+// init initializes the columns of the reader from the file metadata.
+func (pr *ParquetReader) init(metadata thriftValues, opts *options) error {
+	elements := metadata.list(2)
+	if len(elements) == 0 {
+		return errors.New("columnar: missing Parquet schema")
+	}
+	root, ok := elements[0].(thriftValues)
+	if !ok {
+		return errInvalidThrift
+	}
+	pos := 1
+	for i := int64(0); i < root.int(5); i++ {
+		if err := pr.addColumns(elements, &pos, nil); err != nil {
+			return err
+		}
+	}
+	for _, rg := range metadata.list(4) {
+		rowGroup, ok := rg.(thriftValues)
+		if !ok || len(rowGroup.list(1)) != len(pr.columns) {
+			return errInvalidThrift
+		}
+		pr.rowGroups = append(pr.rowGroups, rowGroup)
+	}
+
+	names := make([]string, len(pr.columns))
+	for i, c := range pr.columns {
+		names[i] = c.Name
+	}
+	var err error
+	if pr.selected, err = pr.supportedColumns(names, opts.columns); err != nil {
+		return err
+	}
+	pr.schema = pr.newSchema(pr.selected)
+	if opts.where != nil {
+		pr.where = opts.where
+		if pr.whereColumns, err = pr.supportedColumns(names, opts.where.Columns()); err != nil {
+			return err
+		}
+		pr.whereSchema = pr.newSchema(pr.whereColumns)
+	}
+	return nil
+}
+
+// addColumns adds the leaf columns of the schema element at the given
+// position, depth-first, with their names joined by dots.
+func (pr *ParquetReader) addColumns(elements []interface{}, pos *int, path []string) error {
+	if *pos >= len(elements) || len(path) > maxThriftDepth {
+		return errInvalidThrift
+	}
+	e, ok := elements[*pos].(thriftValues)
+	if !ok {
+		return errInvalidThrift
+	}
+	*pos++
+	path = append(path, string(e.bytes(4)))
+	if children := e.int(5); children > 0 {
+		for i := int64(0); i < children; i++ {
+			if err := pr.addColumns(elements, pos, path); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	c := parquetColumn{
+		Field: Field{
+			Name:     strings.Join(path, "."),
+			Nullable: e.int(3) == parquetOptional,
+		},
+		physical:   e.int(1),
+		typeLength: int(e.int(2)),
+	}
+	c.supported = len(path) == 1 && e.int(3) != parquetRepeated
+	switch c.physical {
+	case parquetBoolean:
+		c.Type = Bool
+	case parquetInt32, parquetInt64:
+		c.Type = Int
+	case parquetFloat, parquetDouble:
+		c.Type = Float
+	case parquetByteArray, parquetFixedLenByteArray:
+		c.Type = String
+	default:
+		c.supported = false
+	}
+	pr.columns = append(pr.columns, c)
+	return nil
+}
+
+func (pr *ParquetReader) supportedColumns(names, columns []string) ([]int, error) {
+	indices, err := selectColumns(names, columns)
+	if err != nil {
+		return nil, err
+	}
+	for _, i := range indices {
+		if !pr.columns[i].supported {
+			return nil, fmt.Errorf("columnar: the Parquet column %q is not supported", names[i])
+		}
+	}
+	return indices, nil
+}
+
+func (pr *ParquetReader) newSchema(indices []int) *Schema {
+	fields := make([]Field, len(indices))
+	for i, j := range indices {
+		fields[i] = pr.columns[j].Field
+	}
+	return newSchema(fields)
+}
+
+// loadRowGroup decodes the columns of the rows of the row group satisfying
+// the predicate.
+func (pr *ParquetReader) loadRowGroup(rowGroup thriftValues) error {
+	numRows := rowGroup.int(3)
+	if numRows < 0 || numRows > math.MaxInt32 {
+		return errInvalidThrift
+	}
+	chunks := rowGroup.list(1)
+	pr.values = make(map[int][]interface{})
+	pr.rows, pr.row = nil, 0
+
+	if pr.where != nil {
+		stats := make(map[string]columnStats)
+		for _, i := range pr.whereColumns {
+			if s, ok := pr.columnStats(&pr.columns[i], chunks[i]); ok {
+				stats[pr.columns[i].Name] = s
+			}
+		}
+		if !pr.where.mayMatch(stats) {
+			return nil
+		}
+		for _, i := range pr.whereColumns {
+			if err := pr.loadColumn(i, chunks[i], int(numRows)); err != nil {
+				return err
+			}
+		}
+		for j := 0; j < int(numRows); j++ {
+			if pr.where.Match(pr.newRow(pr.whereSchema, pr.whereColumns, j)) {
+				pr.rows = append(pr.rows, j)
+			}
+		}
+		if len(pr.rows) == 0 {
+			return nil
+		}
+	} else {
+		pr.rows = make([]int, numRows)
+		for j := range pr.rows {
+			pr.rows[j] = j
+		}
+	}
+
+	for _, i := range pr.selected {
+		if err := pr.loadColumn(i, chunks[i], int(numRows)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// columnStats returns the statistics of the column chunk, if any.
+func (pr *ParquetReader) columnStats(c *parquetColumn, chunk interface{}) (columnStats, bool) {
+	cc, ok := chunk.(thriftValues)
+	if !ok {
+		return columnStats{}, false
+	}
+	metadata := cc.structure(3)
+	if !metadata.has(12) {
+		return columnStats{}, false
+	}
+	statistics := metadata.structure(12)
+	s := columnStats{
+		nullCount:    statistics.int(3),
+		hasNullCount: statistics.has(3),
+		numValues:    metadata.int(5),
+	}
+	min, max := statistics.bytes(6), statistics.bytes(5)
+	if !statistics.has(6) && c.Type != String && c.Type != Bool {
+		// the deprecated statistics are sorted correctly for the numbers only
+		min, max = statistics.bytes(2), statistics.bytes(1)
+	}
+	if min != nil && max != nil && c.Type != Bool {
+		s.min, s.max = decodeStat(c, min), decodeStat(c, max)
+	}
+	return s, true
+}
+
+// decodeStat decodes a PLAIN-encoded value of the statistics, without the
+// length of the byte arrays.
+func decodeStat(c *parquetColumn, b []byte) interface{} {
+	if c.Type == String {
+		return string(b)
+	}
+	values, err := decodePlain(c, b, 1)
+	if err != nil {
+		return nil
+	}
+	return values[0]
+}
+
+// loadColumn decodes the values of a column chunk, if not already decoded.
+func (pr *ParquetReader) loadColumn(i int, chunk interface{}, numRows int) error {
+	if _, ok := pr.values[i]; ok {
+		return nil
+	}
+	c := &pr.columns[i]
+	cc, ok := chunk.(thriftValues)
+	if !ok {
+		return errInvalidThrift
+	}
+	if cc.has(1) {
+		return fmt.Errorf("columnar: the Parquet column %q is in an external file", c.Name)
+	}
+	metadata := cc.structure(3)
+	start := metadata.int(9)
+	if offset := metadata.int(11); metadata.has(11) && offset > 0 && offset < start {
+		start = offset
+	}
+	size := metadata.int(7)
+	if start < 0 || size < 0 || size > math.MaxInt32 {
+		return errInvalidThrift
+	}
+	data := make([]byte, size)
+	if _, err := pr.r.ReadAt(data, start); err != nil {
+		return err
+	}
+	values, err := decodeColumnChunk(c, metadata.int(4), data, numRows)
+	if err != nil {
+		return err
+	}
+	pr.values[i] = values
+	return nil
+}
+
+// decodeColumnChunk decodes the pages of a column chunk, compressed with the
+// given codec, with the given number of values.
+func decodeColumnChunk(c *parquetColumn, codec int64, data []byte, numValues int) ([]interface{}, error) {
+	values := make([]interface{}, 0, numValues)
+	var dictionary []interface{}
+	for len(values) < numValues {
+		if len(data) == 0 {
+			return nil, fmt.Errorf("columnar: truncated Parquet column %q", c.Name)
+		}
+		header, n, err := decodeThrift(data)
+		if err != nil {
+			return nil, err
+		}
+		size := header.int(3)
+		if size < 0 || size > int64(len(data)-n) {
+			return nil, fmt.Errorf("columnar: truncated Parquet column %q", c.Name)
+		}
+		page := data[n : n+int(size)]
+		data = data[n+int(size):]
+		uncompressedSize := header.int(2)
+
+		switch header.int(1) {
+		case parquetDictionaryPage:
+			page, err := decompress(codec, page, uncompressedSize)
+			if err != nil {
+				return nil, err
+			}
+			if dictionary, err = decodePlain(c, page, int(header.structure(7).int(1))); err != nil {
+				return nil, err
+			}
+		case parquetDataPage:
+			page, err := decompress(codec, page, uncompressedSize)
+			if err != nil {
+				return nil, err
+			}
+			h := header.structure(5)
+			var defLevels []int
+			if c.Nullable {
+				if len(page) < 4 {
+					return nil, fmt.Errorf("columnar: truncated Parquet column %q", c.Name)
+				}
+				n := int(binary.LittleEndian.Uint32(page))
+				if n < 0 || n > len(page)-4 {
+					return nil, fmt.Errorf("columnar: truncated Parquet column %q", c.Name)
+				}
+				if defLevels, err = decodeHybrid(page[4:4+n], 1, int(h.int(1))); err != nil {
+					return nil, err
+				}
+				page = page[4+n:]
+			}
+			if values, err = appendValues(values, c, h.int(2), page, defLevels, int(h.int(1)), dictionary); err != nil {
+				return nil, err
+			}
+		case parquetDataPageV2:
+			h := header.structure(8)
+			defLen, repLen := h.int(5), h.int(6)
+			if defLen < 0 || repLen < 0 || defLen+repLen > int64(len(page)) {
+				return nil, fmt.Errorf("columnar: truncated Parquet column %q", c.Name)
+			}
+			var defLevels []int
+			if c.Nullable {
+				if defLevels, err = decodeHybrid(page[repLen:repLen+defLen], 1, int(h.int(1))); err != nil {
+					return nil, err
+				}
+			}
+			page := page[repLen+defLen:]
+			if h.bool(7, true) {
+				if page, err = decompress(codec, page, uncompressedSize-defLen-repLen); err != nil {
+					return nil, err
+				}
+			}
+			if values, err = appendValues(values, c, h.int(4), page, defLevels, int(h.int(1)), dictionary); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if len(values) != numValues {
+		return nil, fmt.Errorf("columnar: invalid number of values of the Parquet column %q", c.Name)
+	}
+	return values, nil
+}
+
+// decompress decompresses a page.
+func decompress(codec int64, page []byte, uncompressedSize int64) ([]byte, error) {
+	switch codec {
+	case parquetUncompressed:
+		return page, nil
+	case parquetSnappy:
+		return snappy.Decode(nil, page)
+	case parquetGzip:
+		r, err := gzip.NewReader(bytes.NewReader(page))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		return ioutil.ReadAll(io.LimitReader(r, uncompressedSize))
+	default:
+		return nil, fmt.Errorf("columnar: unsupported Parquet compression codec %d", codec)
+	}
+}
+
+// appendValues appends the values of a data page, with the given definition
+// levels (nil if the column is required), to the values.
+func appendValues(values []interface{}, c *parquetColumn, encoding int64, data []byte, defLevels []int, numValues int, dictionary []interface{}) ([]interface{}, error) {
+	numNonNull := numValues
+	if defLevels != nil {
+		numNonNull = 0
+		for _, l := range defLevels {
+			numNonNull += l
+		}
+	}
+
+	var nonNull []interface{}
+	var err error
+	switch encoding {
+	case parquetPlain:
+		nonNull, err = decodePlain(c, data, numNonNull)
+	case parquetPlainDictionary, parquetRLEDictionary:
+		if len(data) == 0 && numNonNull == 0 {
+			break
+		}
+		if len(data) == 0 {
+			return nil, fmt.Errorf("columnar: truncated Parquet column %q", c.Name)
+		}
+		var indices []int
+		if indices, err = decodeHybrid(data[1:], int(data[0]), numNonNull); err != nil {
+			return nil, err
+		}
+		nonNull = make([]interface{}, numNonNull)
+		for i, index := range indices {
+			if index >= len(dictionary) {
+				return nil, fmt.Errorf("columnar: invalid dictionary index of the Parquet column %q", c.Name)
+			}
+			nonNull[i] = dictionary[index]
+		}
+	case parquetRLE:
+		if c.Type != Bool || len(data) < 4 {
+			return nil, fmt.Errorf("columnar: invalid RLE encoding of the Parquet column %q", c.Name)
+		}
+		var bits []int
+		if bits, err = decodeHybrid(data[4:], 1, numNonNull); err != nil {
+			return nil, err
+		}
+		nonNull = make([]interface{}, numNonNull)
+		for i, b := range bits {
+			nonNull[i] = b != 0
+		}
+	default:
+		return nil, fmt.Errorf("columnar: unsupported encoding %d of the Parquet column %q", encoding, c.Name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if defLevels == nil {
+		return append(values, nonNull...), nil
+	}
+	j := 0
+	for _, l := range defLevels {
+		if l == 0 {
+			values = append(values, nil)
+		} else {
+			values = append(values, nonNull[j])
+			j++
+		}
+	}
+	return values, nil
+}
+
+// decodePlain decodes the given number of PLAIN-encoded values.
+func decodePlain(c *parquetColumn, data []byte, n int) ([]interface{}, error) {
+	truncated := fmt.Errorf("columnar: truncated Parquet column %q", c.Name)
+	if n < 0 {
+		return nil, truncated
+	}
+	width := 0
+	switch c.physical {
+	case parquetBoolean:
+		if len(data)*8 < n {
+			return nil, truncated
+		}
+	case parquetInt32, parquetFloat:
+		width = 4
+	case parquetInt64, parquetDouble:
+		width = 8
+	case parquetFixedLenByteArray:
+		width = c.typeLength
+	}
+	if width > 0 && len(data)/width < n {
+		return nil, truncated
+	}
+	if c.physical == parquetByteArray && len(data)/4 < n {
+		return nil, truncated
+	}
+
+	values := make([]interface{}, n)
+	for i := range values {
+		switch c.physical {
+		case parquetBoolean:
+			values[i] = data[i>>3]&(1<<(i&7)) != 0
+		case parquetInt32:
+			values[i] = int64(int32(binary.LittleEndian.Uint32(data[i*4:])))
+		case parquetInt64:
+			values[i] = int64(binary.LittleEndian.Uint64(data[i*8:]))
+		case parquetFloat:
+			values[i] = float64(math.Float32frombits(binary.LittleEndian.Uint32(data[i*4:])))
+		case parquetDouble:
+			values[i] = math.Float64frombits(binary.LittleEndian.Uint64(data[i*8:]))
+		case parquetFixedLenByteArray:
+			values[i] = string(data[i*width : (i+1)*width])
+		case parquetByteArray:
+			if len(data) < 4 {
+				return nil, truncated
+			}
+			size := binary.LittleEndian.Uint32(data)
+			if uint64(size) > uint64(len(data)-4) {
+				return nil, truncated
+			}
+			values[i] = string(data[4 : 4+size])
+			data = data[4+size:]
+		}
+	}
+	return values, nil
+}
+
+// decodeHybrid decodes n values of the given bit width in the hybrid
+// run-length and bit-packing encoding of Parquet.
+func decodeHybrid(data []byte, bitWidth, n int) ([]int, error) {
+	if bitWidth < 0 || bitWidth > 32 || n < 0 {
+		return nil, errors.New("columnar: invalid Parquet run-length encoding")
+	}
+	values := make([]int, 0, n)
+	byteWidth := (bitWidth + 7) / 8
+	for len(values) < n {
+		header, k := binary.Uvarint(data)
+		if k <= 0 {
+			return nil, errors.New("columnar: invalid Parquet run-length encoding")
+		}
+		data = data[k:]
+		if header&1 == 0 { // run-length
+			count := header >> 1
+			if len(data) < byteWidth {
+				return nil, errors.New("columnar: invalid Parquet run-length encoding")
+			}
+			var b [4]byte
+			copy(b[:], data[:byteWidth])
+			v := int(binary.LittleEndian.Uint32(b[:]))
+			data = data[byteWidth:]
+			for i := uint64(0); i < count && len(values) < n; i++ {
+				values = append(values, v)
+			}
+			continue
+		}
+		groups := header >> 1
+		if groups*uint64(bitWidth) > uint64(len(data)) {
+			return nil, errors.New("columnar: invalid Parquet run-length encoding")
+		}
+		size := int(groups) * bitWidth
+		for i := 0; i < int(groups)*8 && len(values) < n; i++ {
+			v := 0
+			for j := 0; j < bitWidth; j++ {
+				bit := i*bitWidth + j
+				if data[bit>>3]&(1<<(bit&7)) != 0 {
+					v |= 1 << j
+				}
+			}
+			values = append(values, v)
+		}
+		data = data[size:]
+	}
+	return values, nil
+}
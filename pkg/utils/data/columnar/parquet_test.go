@@ -0,0 +1,509 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package columnar
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/golang/snappy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewParquetReader(t *testing.T) {
+	data, _ := testParquetFile()
+	_, err := NewParquetReader(bytes.NewReader(data), int64(len(data)))
+	assert.EqualError(t, err, `columnar: the Parquet column "nested.x" is not supported`)
+
+	_, err = NewParquetReader(bytes.NewReader(data[:100]), 100)
+	assert.Error(t, err)
+
+	r, err := NewParquetReader(bytes.NewReader(data), int64(len(data)), Columns("text", "id"))
+	require.NoError(t, err)
+	assert.Equal(t, []Field{
+		{Name: "text", Type: String, Nullable: true},
+		{Name: "id", Type: Int},
+	}, r.Schema().Fields)
+	assert.NoError(t, r.Close())
+}
+
+func TestParquetReader_Next(t *testing.T) {
+	data, _ := testParquetFile()
+	r, err := NewParquetReader(bytes.NewReader(data), int64(len(data)), Columns("id", "text", "score", "label", "ok"))
+	require.NoError(t, err)
+	rows, err := ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, testRows, valuesOf(rows))
+}
+
+func TestParquetReader_Where(t *testing.T) {
+	data, chunkOffsets := testParquetFile()
+
+	t.Run("row group skipped by statistics", func(t *testing.T) {
+		ra := &recordingReaderAt{data: data}
+		r, err := NewParquetReader(ra, int64(len(data)), Columns("id", "text"), Where(Eq("label", "pos")))
+		require.NoError(t, err)
+		rows, err := ReadAll(r)
+		require.NoError(t, err)
+		assert.Equal(t, [][]interface{}{{int64(1), "a"}, {int64(3), "ccc"}}, valuesOf(rows))
+		for _, offset := range chunkOffsets[1] {
+			assert.NotContains(t, ra.offsets, offset)
+		}
+	})
+
+	t.Run("row group skipped by deprecated statistics", func(t *testing.T) {
+		ra := &recordingReaderAt{data: data}
+		r, err := NewParquetReader(ra, int64(len(data)), Columns("label"), Where(In("id", 5, 6)))
+		require.NoError(t, err)
+		rows, err := ReadAll(r)
+		require.NoError(t, err)
+		assert.Equal(t, [][]interface{}{{"neg"}}, valuesOf(rows))
+		for _, offset := range chunkOffsets[0] {
+			assert.NotContains(t, ra.offsets, offset)
+		}
+	})
+
+	t.Run("columns not decoded without matches", func(t *testing.T) {
+		ra := &recordingReaderAt{data: data}
+		r, err := NewParquetReader(ra, int64(len(data)), Columns("score"),
+			Where(Func(func(r Row) bool { return len(r.String("text")) > 2 }, "text")))
+		require.NoError(t, err)
+		rows, err := ReadAll(r)
+		require.NoError(t, err)
+		assert.Equal(t, [][]interface{}{{2.5}}, valuesOf(rows))
+		assert.NotContains(t, ra.offsets, chunkOffsets[1][3]) // score
+	})
+}
+
+func TestParquetReader_Truncated(t *testing.T) {
+	data, chunkOffsets := testParquetFile()
+	corrupted := append([]byte{}, data...)
+	for i := chunkOffsets[1][0]; i < chunkOffsets[1][1]; i++ {
+		corrupted[i] = 0xFF
+	}
+	r, err := NewParquetReader(bytes.NewReader(corrupted), int64(len(corrupted)), Columns("id"))
+	require.NoError(t, err)
+	_, err = ReadAll(r)
+	assert.Error(t, err)
+}
+
+func TestDecodeThrift(t *testing.T) {
+	data := encodeThrift(tStruct{
+		{1, 42},
+		{2, "foo"},
+		{20, []interface{}{true, false}}, // long form
+		{21, tStruct{{1, -3.5}}},
+	})
+	// a map, skipped
+	data = append(data[:len(data)-1], 0x1B, 0x01, 0x85, 0x01, 'k', 0x02, thriftStop)
+
+	s, n, err := decodeThrift(append(data, 0xAA))
+	require.NoError(t, err)
+	assert.Equal(t, len(data), n)
+	assert.Equal(t, int64(42), s.int(1))
+	assert.Equal(t, []byte("foo"), s.bytes(2))
+	assert.Equal(t, []interface{}{true, false}, s.list(20))
+	assert.Equal(t, -3.5, s.structure(21)[1])
+	assert.False(t, s.has(22))
+	assert.Len(t, s, 4)
+
+	_, _, err = decodeThrift(data[:len(data)-3])
+	assert.Error(t, err)
+}
+
+func TestDecodeHybrid(t *testing.T) {
+	data := []byte{
+		0x06, 0x05, // run of 3 × 5
+		0x03, 0x88, 0xC6, 0xFA, // 8 bit-packed values 0..7
+	}
+	values, err := decodeHybrid(data, 3, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []int{5, 5, 5, 0, 1, 2, 3, 4, 5, 6}, values)
+
+	_, err = decodeHybrid(data, 3, 12)
+	assert.Error(t, err)
+}
+
+// recordingReaderAt records the offsets read.
+type recordingReaderAt struct {
+	data    []byte
+	offsets []int64
+}
+
+func (r *recordingReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	r.offsets = append(r.offsets, off)
+	return bytes.NewReader(r.data).ReadAt(p, off)
+}
+
+// testParquetColumn describes a column of the test Parquet file.
+type testParquetColumn struct {
+	element  tStruct
+	physical int64
+	optional bool
+	codec    int64
+	// write returns the pages of a column chunk, and the size of the
+	// dictionary page.
+	write func(c testParquetColumn, values []interface{}) ([]byte, int)
+	// stats returns the statistics of a column chunk.
+	stats func(values []interface{}) tStruct
+}
+
+// testParquetFile returns the test dataset in the Parquet format, in two row
+// groups, with the columns id (int64), text (optional byte array), nested.x
+// (unsupported), score (double), label (byte array) and ok (boolean), and the
+// offsets of the column chunks of each row group.
+func testParquetFile() ([]byte, [][]int64) {
+	columns := []testParquetColumn{
+		{physical: parquetInt64, codec: parquetUncompressed, write: writePlainPage,
+			stats: func(values []interface{}) tStruct {
+				return tStruct{
+					{1, encodePlain(parquetInt64, values[len(values)-1:])},
+					{2, encodePlain(parquetInt64, values[:1])},
+				}
+			}},
+		{physical: parquetByteArray, optional: true, codec: parquetSnappy, write: writeDictionaryPages},
+		{physical: parquetInt32, codec: parquetUncompressed, write: writePlainPage},
+		{physical: parquetDouble, codec: parquetGzip, write: writePlainPage},
+		{physical: parquetByteArray, codec: parquetSnappy, write: writeV2Page,
+			stats: func(values []interface{}) tStruct {
+				min, max := values[0].(string), values[0].(string)
+				for _, v := range values {
+					if s := v.(string); s < min {
+						min = s
+					} else if s > max {
+						max = s
+					}
+				}
+				return tStruct{{3, 0}, {5, max}, {6, min}}
+			}},
+		{physical: parquetBoolean, codec: parquetUncompressed, write: writeV2Page},
+	}
+	names := [][]string{{"id"}, {"text"}, {"nested", "x"}, {"score"}, {"label"}, {"ok"}}
+	columnValues := func(rows [][]interface{}, i int) []interface{} {
+		values := make([]interface{}, len(rows))
+		for j, row := range rows {
+			switch i {
+			case 2:
+				values[j] = int64(0)
+			case 0, 1:
+				values[j] = row[i]
+			default:
+				values[j] = row[i-1]
+			}
+		}
+		return values
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(parquetMagic)
+	var rowGroups []interface{}
+	var offsets [][]int64
+	for _, rows := range [][][]interface{}{testRows[:3], testRows[3:]} {
+		var chunks []interface{}
+		offsets = append(offsets, nil)
+		for i, c := range columns {
+			values := columnValues(rows, i)
+			pages, dictionarySize := c.write(c, values)
+			offset := int64(buf.Len())
+			offsets[len(offsets)-1] = append(offsets[len(offsets)-1], offset)
+			buf.Write(pages)
+			paths := make([]interface{}, len(names[i]))
+			for j, name := range names[i] {
+				paths[j] = name
+			}
+			metadata := tStruct{
+				{1, c.physical},
+				{2, []interface{}{0}},
+				{3, paths},
+				{4, c.codec},
+				{5, len(values)},
+				{6, len(pages)},
+				{7, len(pages)},
+				{9, offset + int64(dictionarySize)},
+			}
+			if dictionarySize > 0 {
+				metadata = append(metadata, tField{11, offset})
+			}
+			if c.stats != nil {
+				metadata = append(metadata, tField{12, c.stats(values)})
+			}
+			chunks = append(chunks, tStruct{{2, offset}, {3, metadata}})
+		}
+		rowGroups = append(rowGroups, tStruct{{1, chunks}, {2, 0}, {3, len(rows)}})
+	}
+
+	element := func(name string, c testParquetColumn) tStruct {
+		repetition := parquetRequired
+		if c.optional {
+			repetition = parquetOptional
+		}
+		return tStruct{{1, c.physical}, {3, repetition}, {4, name}}
+	}
+	schema := []interface{}{
+		tStruct{{4, "schema"}, {5, 6}},
+		element("id", columns[0]),
+		element("text", columns[1]),
+		tStruct{{3, parquetRequired}, {4, "nested"}, {5, 1}},
+		element("x", columns[2]),
+		element("score", columns[3]),
+		element("label", columns[4]),
+		element("ok", columns[5]),
+	}
+	metadata := encodeThrift(tStruct{
+		{1, 1},
+		{2, schema},
+		{3, len(testRows)},
+		{4, rowGroups},
+		{6, "spago test"},
+	})
+	buf.Write(metadata)
+	binary.Write(&buf, binary.LittleEndian, uint32(len(metadata)))
+	buf.WriteString(parquetMagic)
+	return buf.Bytes(), offsets
+}
+
+func writePlainPage(c testParquetColumn, values []interface{}) ([]byte, int) {
+	var page []byte
+	var nonNull []interface{}
+	if c.optional {
+		levels, notNull := encodeDefinitionLevels(values)
+		page = make([]byte, 4)
+		binary.LittleEndian.PutUint32(page, uint32(len(levels)))
+		page, nonNull = append(page, levels...), notNull
+	} else {
+		nonNull = values
+	}
+	page = append(page, encodePlain(c.physical, nonNull)...)
+	header := tStruct{{1, len(values)}, {2, parquetPlain}, {3, parquetRLE}, {4, parquetRLE}}
+	return writePage(parquetDataPage, 5, header, page, compress(c.codec, page)), 0
+}
+
+func writeDictionaryPages(c testParquetColumn, values []interface{}) ([]byte, int) {
+	levels, nonNull := encodeDefinitionLevels(values)
+	var dictionary []interface{}
+	indices := make(map[interface{}]int)
+	data := []byte{8} // bit width
+	for _, v := range nonNull {
+		if _, ok := indices[v]; !ok {
+			indices[v] = len(dictionary)
+			dictionary = append(dictionary, v)
+		}
+		data = append(data, 0x02, byte(indices[v])) // runs of 1
+	}
+	plain := encodePlain(c.physical, dictionary)
+	dictionaryPage := writePage(parquetDictionaryPage, 7, tStruct{{1, len(dictionary)}, {2, parquetPlain}}, plain, compress(c.codec, plain))
+
+	page := make([]byte, 4)
+	binary.LittleEndian.PutUint32(page, uint32(len(levels)))
+	page = append(append(page, levels...), data...)
+	header := tStruct{{1, len(values)}, {2, parquetRLEDictionary}, {3, parquetRLE}, {4, parquetRLE}}
+	dataPage := writePage(parquetDataPage, 5, header, page, compress(c.codec, page))
+	return append(dictionaryPage, dataPage...), len(dictionaryPage)
+}
+
+func writeV2Page(c testParquetColumn, values []interface{}) ([]byte, int) {
+	encoding := parquetPlain
+	data := encodePlain(c.physical, values)
+	if c.physical == parquetBoolean {
+		encoding = parquetRLE
+		bits := encodeBitPacked(values)
+		data = make([]byte, 4)
+		binary.LittleEndian.PutUint32(data, uint32(len(bits)))
+		data = append(data, bits...)
+	}
+	header := tStruct{{1, len(values)}, {2, 0}, {3, len(values)}, {4, encoding}, {5, 0}, {6, 0}}
+	return writePage(parquetDataPageV2, 8, header, data, compress(c.codec, data)), 0
+}
+
+func writePage(pageType int64, headerID int16, header tStruct, page, compressed []byte) []byte {
+	return append(encodeThrift(tStruct{
+		{1, pageType},
+		{2, len(page)},
+		{3, len(compressed)},
+		{headerID, header},
+	}), compressed...)
+}
+
+func compress(codec int64, data []byte) []byte {
+	switch codec {
+	case parquetSnappy:
+		return snappy.Encode(nil, data)
+	case parquetGzip:
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		w.Write(data)
+		w.Close()
+		return buf.Bytes()
+	default:
+		return data
+	}
+}
+
+// encodeDefinitionLevels returns the definition levels of the values,
+// bit-packed, and the values not null.
+func encodeDefinitionLevels(values []interface{}) ([]byte, []interface{}) {
+	levels := make([]interface{}, len(values))
+	var nonNull []interface{}
+	for i, v := range values {
+		levels[i] = v != nil
+		if v != nil {
+			nonNull = append(nonNull, v)
+		}
+	}
+	return encodeBitPacked(levels), nonNull
+}
+
+// encodeBitPacked encodes the booleans in the hybrid encoding of bit width 1.
+func encodeBitPacked(values []interface{}) []byte {
+	groups := (len(values) + 7) / 8
+	data := []byte{byte(groups<<1 | 1)}
+	data = append(data, make([]byte, groups)...)
+	for i, v := range values {
+		if v.(bool) {
+			data[1+i/8] |= 1 << (i % 8)
+		}
+	}
+	return data
+}
+
+func encodePlain(physical int64, values []interface{}) []byte {
+	var buf bytes.Buffer
+	switch physical {
+	case parquetBoolean:
+		data := make([]byte, (len(values)+7)/8)
+		for i, v := range values {
+			if v.(bool) {
+				data[i/8] |= 1 << (i % 8)
+			}
+		}
+		buf.Write(data)
+	default:
+		for _, v := range values {
+			switch physical {
+			case parquetInt32:
+				binary.Write(&buf, binary.LittleEndian, int32(v.(int64)))
+			case parquetInt64:
+				binary.Write(&buf, binary.LittleEndian, v.(int64))
+			case parquetDouble:
+				binary.Write(&buf, binary.LittleEndian, math.Float64bits(v.(float64)))
+			case parquetByteArray:
+				binary.Write(&buf, binary.LittleEndian, uint32(len(v.(string))))
+				buf.WriteString(v.(string))
+			}
+		}
+	}
+	return buf.Bytes()
+}
+
+// tStruct is a structure encoded in the Thrift compact protocol, with the
+// fields in increasing order of identifier.
+type tStruct []tField
+
+type tField struct {
+	id    int16
+	value interface{}
+}
+
+func encodeThrift(s tStruct) []byte {
+	var buf bytes.Buffer
+	writeThriftStruct(&buf, s)
+	return buf.Bytes()
+}
+
+func writeThriftStruct(buf *bytes.Buffer, s tStruct) {
+	last := int16(0)
+	for _, f := range s {
+		typ := thriftType(f.value)
+		if delta := f.id - last; delta > 0 && delta <= 15 {
+			buf.WriteByte(byte(delta)<<4 | typ)
+		} else {
+			buf.WriteByte(typ)
+			writeZigzag(buf, int64(f.id))
+		}
+		last = f.id
+		if _, ok := f.value.(bool); !ok {
+			writeThriftValue(buf, f.value)
+		}
+	}
+	buf.WriteByte(thriftStop)
+}
+
+func thriftType(v interface{}) byte {
+	switch x := v.(type) {
+	case bool:
+		if x {
+			return thriftTrue
+		}
+		return thriftFalse
+	case int:
+		return thriftI32
+	case int64:
+		return thriftI64
+	case float64:
+		return thriftDouble
+	case string, []byte:
+		return thriftBinary
+	case []interface{}:
+		return thriftList
+	case tStruct:
+		return thriftStruct
+	default:
+		panic("unsupported value")
+	}
+}
+
+func writeThriftValue(buf *bytes.Buffer, v interface{}) {
+	switch x := v.(type) {
+	case bool:
+		if x {
+			buf.WriteByte(thriftTrue)
+		} else {
+			buf.WriteByte(thriftFalse)
+		}
+	case int:
+		writeZigzag(buf, int64(x))
+	case int64:
+		writeZigzag(buf, x)
+	case float64:
+		binary.Write(buf, binary.LittleEndian, math.Float64bits(x))
+	case string:
+		writeThriftValue(buf, []byte(x))
+	case []byte:
+		buf.Write(varint(uint64(len(x))))
+		buf.Write(x)
+	case []interface{}:
+		elemType := byte(thriftI32)
+		if len(x) > 0 {
+			elemType = thriftType(x[0])
+		}
+		if elemType == thriftFalse {
+			elemType = thriftTrue
+		}
+		if len(x) < 15 {
+			buf.WriteByte(byte(len(x))<<4 | elemType)
+		} else {
+			buf.WriteByte(0xF0 | elemType)
+			buf.Write(varint(uint64(len(x))))
+		}
+		for _, e := range x {
+			writeThriftValue(buf, e)
+		}
+	case tStruct:
+		writeThriftStruct(buf, x)
+	}
+}
+
+func writeZigzag(buf *bytes.Buffer, v int64) {
+	buf.Write(varint(uint64(v<<1) ^ uint64(v>>63)))
+}
+
+func varint(v uint64) []byte {
+	b := make([]byte, binary.MaxVarintLen64)
+	return b[:binary.PutUvarint(b, v)]
+}
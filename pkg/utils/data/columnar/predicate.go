@@ -0,0 +1,298 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package columnar
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Predicate is a condition on the values of some columns of a row, which is
+// evaluated before decoding the other columns.
+type Predicate interface {
+	// Columns returns the names of the columns the predicate depends on.
+	Columns() []string
+	// Match reports whether the row satisfies the predicate. The row
+	// contains the values of the columns of the predicate.
+	Match(r Row) bool
+	// mayMatch reports whether any row of a group with the given statistics
+	// of the columns (if known) may satisfy the predicate.
+	mayMatch(stats map[string]columnStats) bool
+}
+
+// columnStats are the statistics of the values of a column in a group of
+// rows (e.g. a Parquet row group).
+type columnStats struct {
+	// min and max are the bounds of the values, if not nil.
+	min, max interface{}
+	// nullCount is the number of null values, if hasNullCount.
+	nullCount    int64
+	hasNullCount bool
+	// numValues is the number of values, including the null ones.
+	numValues int64
+}
+
+// mayContain reports whether the value may be among the values with the
+// statistics.
+func (s columnStats) mayContain(v interface{}) bool {
+	if s.hasNullCount && s.nullCount == s.numValues {
+		return false
+	}
+	if s.min == nil || s.max == nil {
+		return true
+	}
+	if c, ok := compareValues(v, s.min); ok && c < 0 {
+		return false
+	}
+	if c, ok := compareValues(v, s.max); ok && c > 0 {
+		return false
+	}
+	return true
+}
+
+// Eq returns a Predicate satisfied by the rows where the column is equal to
+// the value (e.g. a label).
+func Eq(column string, value interface{}) Predicate {
+	return In(column, value)
+}
+
+// In returns a Predicate satisfied by the rows where the column is equal to
+// any of the values (e.g. a set of labels).
+func In(column string, values ...interface{}) Predicate {
+	p := &in{column: column, values: make([]interface{}, len(values))}
+	for i, v := range values {
+		p.values[i] = normalizeValue(v)
+	}
+	return p
+}
+
+type in struct {
+	column string
+	values []interface{}
+}
+
+func (p *in) Columns() []string {
+	return []string{p.column}
+}
+
+func (p *in) Match(r Row) bool {
+	v := r.Value(p.column)
+	if v == nil {
+		return false
+	}
+	for _, x := range p.values {
+		if c, ok := compareValues(v, x); ok && c == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *in) mayMatch(stats map[string]columnStats) bool {
+	s, ok := stats[p.column]
+	if !ok {
+		return true
+	}
+	for _, x := range p.values {
+		if s.mayContain(x) {
+			return true
+		}
+	}
+	return false
+}
+
+// NotNull returns a Predicate satisfied by the rows where the column is not
+// null (e.g. the examples with a text).
+func NotNull(column string) Predicate {
+	return notNull(column)
+}
+
+type notNull string
+
+func (p notNull) Columns() []string {
+	return []string{string(p)}
+}
+
+func (p notNull) Match(r Row) bool {
+	return r.Value(string(p)) != nil
+}
+
+func (p notNull) mayMatch(stats map[string]columnStats) bool {
+	s, ok := stats[string(p)]
+	return !ok || !s.hasNullCount || s.nullCount < s.numValues
+}
+
+// And returns a Predicate satisfied by the rows satisfying all the
+// predicates.
+func And(predicates ...Predicate) Predicate {
+	return and(predicates)
+}
+
+type and []Predicate
+
+func (p and) Columns() []string {
+	return joinColumns(p)
+}
+
+func (p and) Match(r Row) bool {
+	for _, x := range p {
+		if !x.Match(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func (p and) mayMatch(stats map[string]columnStats) bool {
+	for _, x := range p {
+		if !x.mayMatch(stats) {
+			return false
+		}
+	}
+	return true
+}
+
+// Or returns a Predicate satisfied by the rows satisfying any of the
+// predicates.
+func Or(predicates ...Predicate) Predicate {
+	return or(predicates)
+}
+
+type or []Predicate
+
+func (p or) Columns() []string {
+	return joinColumns(p)
+}
+
+func (p or) Match(r Row) bool {
+	for _, x := range p {
+		if x.Match(r) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p or) mayMatch(stats map[string]columnStats) bool {
+	for _, x := range p {
+		if x.mayMatch(stats) {
+			return true
+		}
+	}
+	return false
+}
+
+// Func returns a Predicate satisfied by the rows for which the function
+// returns true (e.g. the examples with a text of a given length). The
+// function receives the values of the given columns.
+func Func(f func(r Row) bool, columns ...string) Predicate {
+	return &function{f: f, columns: columns}
+}
+
+type function struct {
+	f       func(r Row) bool
+	columns []string
+}
+
+func (p *function) Columns() []string {
+	return p.columns
+}
+
+func (p *function) Match(r Row) bool {
+	return p.f(r)
+}
+
+func (p *function) mayMatch(map[string]columnStats) bool {
+	return true
+}
+
+func joinColumns(predicates []Predicate) []string {
+	seen := make(map[string]bool)
+	columns := make([]string, 0)
+	for _, p := range predicates {
+		for _, c := range p.Columns() {
+			if !seen[c] {
+				seen[c] = true
+				columns = append(columns, c)
+			}
+		}
+	}
+	sort.Strings(columns)
+	return columns
+}
+
+// normalizeValue converts the value to the Go type of the corresponding Type.
+// It panics if the value is not of a supported type.
+func normalizeValue(v interface{}) interface{} {
+	switch x := v.(type) {
+	case bool, int64, float64, string:
+		return x
+	case int:
+		return int64(x)
+	case int8:
+		return int64(x)
+	case int16:
+		return int64(x)
+	case int32:
+		return int64(x)
+	case uint:
+		return int64(x)
+	case uint8:
+		return int64(x)
+	case uint16:
+		return int64(x)
+	case uint32:
+		return int64(x)
+	case uint64:
+		return int64(x)
+	case float32:
+		return float64(x)
+	case []byte:
+		return string(x)
+	default:
+		panic(fmt.Sprintf("columnar: unsupported value of type %T", v))
+	}
+}
+
+// compareValues compares two normalized values, returning false if they are
+// not comparable.
+func compareValues(a, b interface{}) (int, bool) {
+	switch x := a.(type) {
+	case int64:
+		switch y := b.(type) {
+		case int64:
+			return compareOrdered(x < y, x > y), true
+		case float64:
+			return compareOrdered(float64(x) < y, float64(x) > y), true
+		}
+	case float64:
+		switch y := b.(type) {
+		case float64:
+			return compareOrdered(x < y, x > y), true
+		case int64:
+			return compareOrdered(x < float64(y), x > float64(y)), true
+		}
+	case string:
+		if y, ok := b.(string); ok {
+			return compareOrdered(x < y, x > y), true
+		}
+	case bool:
+		if y, ok := b.(bool); ok {
+			return compareOrdered(!x && y, x && !y), true
+		}
+	}
+	return 0, false
+}
+
+func compareOrdered(less, greater bool) int {
+	switch {
+	case less:
+		return -1
+	case greater:
+		return 1
+	default:
+		return 0
+	}
+}
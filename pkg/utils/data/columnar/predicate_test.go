@@ -0,0 +1,74 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package columnar
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPredicate_Match(t *testing.T) {
+	schema := newSchema([]Field{
+		{Name: "label", Type: String},
+		{Name: "id", Type: Int},
+		{Name: "text", Type: String, Nullable: true},
+	})
+	row := Row{schema: schema, values: []interface{}{"pos", int64(3), nil}}
+
+	assert.True(t, Eq("label", "pos").Match(row))
+	assert.False(t, Eq("label", "neg").Match(row))
+	assert.True(t, In("id", 1, 3.0).Match(row))
+	assert.False(t, In("id", uint8(4)).Match(row))
+	assert.False(t, In("text", "").Match(row))
+	assert.False(t, NotNull("text").Match(row))
+	assert.True(t, NotNull("id").Match(row))
+	assert.False(t, And(Eq("label", "pos"), NotNull("text")).Match(row))
+	assert.True(t, Or(Eq("label", "neg"), Eq("id", 3)).Match(row))
+	assert.True(t, Func(func(r Row) bool { return r.Int("id") > 2 }, "id").Match(row))
+
+	assert.Equal(t, []string{"id", "label", "text"}, And(Eq("label", "pos"), Or(NotNull("text"), Eq("id", 1))).Columns())
+	assert.Panics(t, func() { Eq("label", struct{}{}) })
+}
+
+func TestPredicate_mayMatch(t *testing.T) {
+	stats := map[string]columnStats{
+		"label": {min: "b", max: "d", hasNullCount: true, numValues: 10},
+		"id":    {min: int64(10), max: int64(20)},
+		"text":  {nullCount: 5, hasNullCount: true, numValues: 5},
+	}
+	assert.True(t, Eq("label", "c").mayMatch(stats))
+	assert.False(t, Eq("label", "a").mayMatch(stats))
+	assert.False(t, Eq("label", "e").mayMatch(stats))
+	assert.True(t, In("id", 1, 15).mayMatch(stats))
+	assert.False(t, In("id", 1, 21.5).mayMatch(stats))
+	assert.False(t, Eq("text", "foo").mayMatch(stats))
+	assert.False(t, NotNull("text").mayMatch(stats))
+	assert.True(t, NotNull("label").mayMatch(stats))
+	assert.True(t, Eq("unknown", 1).mayMatch(stats))
+	assert.False(t, And(Eq("label", "c"), Eq("id", 5)).mayMatch(stats))
+	assert.True(t, Or(Eq("label", "a"), Eq("id", 15)).mayMatch(stats))
+	assert.True(t, Func(func(Row) bool { return false }, "id").mayMatch(stats))
+}
+
+func TestCompareValues(t *testing.T) {
+	for _, c := range []struct {
+		a, b     interface{}
+		expected int
+		ok       bool
+	}{
+		{int64(1), int64(2), -1, true},
+		{int64(2), 1.5, 1, true},
+		{1.5, int64(1), 1, true},
+		{1.5, 1.5, 0, true},
+		{"a", "b", -1, true},
+		{true, false, 1, true},
+		{"1", int64(1), 0, false},
+	} {
+		actual, ok := compareValues(c.a, c.b)
+		assert.Equal(t, c.ok, ok)
+		assert.Equal(t, c.expected, actual)
+	}
+}
@@ -0,0 +1,211 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package columnar
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// The types of the Thrift compact protocol, used by the metadata of the
+// Parquet files.
+const (
+	thriftStop   byte = 0
+	thriftTrue   byte = 1
+	thriftFalse  byte = 2
+	thriftByte   byte = 3
+	thriftI16    byte = 4
+	thriftI32    byte = 5
+	thriftI64    byte = 6
+	thriftDouble byte = 7
+	thriftBinary byte = 8
+	thriftList   byte = 9
+	thriftSet    byte = 10
+	thriftMap    byte = 11
+	thriftStruct byte = 12
+)
+
+// maxThriftDepth limits the nesting of the decoded structures.
+const maxThriftDepth = 64
+
+var errInvalidThrift = errors.New("columnar: invalid Parquet metadata")
+
+// thriftValues is a structure decoded from the Thrift compact protocol,
+// mapping the field identifiers to the values: bool, int64, float64, []byte,
+// []interface{} (lists and sets) or thriftValues (structures). The maps are
+// skipped.
+type thriftValues map[int16]interface{}
+
+// decodeThrift decodes a structure in the Thrift compact protocol, returning
+// the number of bytes read.
+func decodeThrift(data []byte) (_ thriftValues, n int, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errInvalidThrift
+		}
+	}()
+	d := &thriftDecoder{data: data}
+	s := d.readStruct(0)
+	return s, d.pos, nil
+}
+
+// thriftDecoder decodes the Thrift compact protocol, panicking on invalid
+// data.
+type thriftDecoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *thriftDecoder) readByte() byte {
+	b := d.data[d.pos]
+	d.pos++
+	return b
+}
+
+func (d *thriftDecoder) readVarint() uint64 {
+	v, n := binary.Uvarint(d.data[d.pos:])
+	if n <= 0 {
+		panic(errInvalidThrift)
+	}
+	d.pos += n
+	return v
+}
+
+func (d *thriftDecoder) readZigzag() int64 {
+	v := d.readVarint()
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+func (d *thriftDecoder) readBinary() []byte {
+	n := d.readVarint()
+	if n > uint64(len(d.data)-d.pos) {
+		panic(errInvalidThrift)
+	}
+	b := d.data[d.pos : d.pos+int(n)]
+	d.pos += int(n)
+	return b
+}
+
+func (d *thriftDecoder) readStruct(depth int) thriftValues {
+	if depth > maxThriftDepth {
+		panic(errInvalidThrift)
+	}
+	s := make(thriftValues)
+	var id int16
+	for {
+		b := d.readByte()
+		typ := b & 0x0F
+		if typ == thriftStop {
+			return s
+		}
+		if delta := int16(b >> 4); delta != 0 {
+			id += delta
+		} else {
+			id = int16(d.readZigzag())
+		}
+		switch typ {
+		case thriftTrue:
+			s[id] = true
+		case thriftFalse:
+			s[id] = false
+		default:
+			if v := d.readValue(typ, depth); v != nil {
+				s[id] = v
+			}
+		}
+	}
+}
+
+func (d *thriftDecoder) readValue(typ byte, depth int) interface{} {
+	switch typ {
+	case thriftTrue, thriftFalse: // in the lists
+		return d.readByte() == thriftTrue
+	case thriftByte:
+		return int64(int8(d.readByte()))
+	case thriftI16, thriftI32, thriftI64:
+		return d.readZigzag()
+	case thriftDouble:
+		v := math.Float64frombits(binary.LittleEndian.Uint64(d.data[d.pos:]))
+		d.pos += 8
+		return v
+	case thriftBinary:
+		return d.readBinary()
+	case thriftList, thriftSet:
+		b := d.readByte()
+		size := uint64(b >> 4)
+		if size == 15 {
+			size = d.readVarint()
+		}
+		if size > uint64(len(d.data)-d.pos) { // each element takes a byte at least
+			panic(errInvalidThrift)
+		}
+		list := make([]interface{}, size)
+		for i := range list {
+			list[i] = d.readValue(b&0x0F, depth+1)
+		}
+		return list
+	case thriftMap:
+		size := d.readVarint()
+		if size == 0 {
+			return nil
+		}
+		if size > uint64(len(d.data)-d.pos) {
+			panic(errInvalidThrift)
+		}
+		b := d.readByte()
+		for i := uint64(0); i < size; i++ {
+			d.readValue(b>>4, depth+1)
+			d.readValue(b&0x0F, depth+1)
+		}
+		return nil
+	case thriftStruct:
+		return d.readStruct(depth + 1)
+	default:
+		panic(errInvalidThrift)
+	}
+}
+
+// int returns the integer field with the given identifier, or 0.
+func (s thriftValues) int(id int16) int64 {
+	v, _ := s[id].(int64)
+	return v
+}
+
+// has reports whether the structure contains the field.
+func (s thriftValues) has(id int16) bool {
+	_, ok := s[id]
+	return ok
+}
+
+// bool returns the boolean field with the given identifier, or the default
+// value.
+func (s thriftValues) bool(id int16, def bool) bool {
+	if v, ok := s[id].(bool); ok {
+		return v
+	}
+	return def
+}
+
+// bytes returns the binary field with the given identifier, or nil.
+func (s thriftValues) bytes(id int16) []byte {
+	v, _ := s[id].([]byte)
+	return v
+}
+
+// list returns the list field with the given identifier, or nil.
+func (s thriftValues) list(id int16) []interface{} {
+	v, _ := s[id].([]interface{})
+	return v
+}
+
+// structure returns the structure field with the given identifier, or an
+// empty structure.
+func (s thriftValues) structure(id int16) thriftValues {
+	if v, ok := s[id].(thriftValues); ok {
+		return v
+	}
+	return thriftValues{}
+}
@@ -14,7 +14,8 @@ import (
 // The class is given by the callback for each i-th element up to size.
 // The size of each batch depends on number of classes (batchFactor * nClasses).
 // Each batch consists in a list of indices.
-func GenerateBatches(size, batchFactor int, class func(i int) int) [][]int {
+// The random choices are drawn from the given generator (or the global random if nil).
+func GenerateBatches(size, batchFactor int, class func(i int) int, rndGen *rand.LockedRand) [][]int {
 	groupsByClass := make(map[int][]int)
 	for i := 0; i < size; i++ {
 		c := class(i)
@@ -34,7 +35,7 @@ func GenerateBatches(size, batchFactor int, class func(i int) int) [][]int {
 	}
 	k := 0
 	for k < size {
-		class := rand.WeightedChoice(distribution) // this uses the global random
+		class := rand.WeightedChoice(distribution, rndGen)
 		if len(groupsByClass[class]) > 0 {
 			var exampleIndex int
 			exampleIndex, groupsByClass[class] = groupsByClass[class][0], groupsByClass[class][1:] // pop
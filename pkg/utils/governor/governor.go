@@ -0,0 +1,234 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package governor limits the memory used by the requests served by a
+// model, so that a burst of requests is queued or rejected instead of
+// making the process run out of memory.
+//
+// The Governor tracks the estimated memory of the requests in flight (e.g.
+// their graphs) and the size of the long-lived caches (e.g. the states of
+// the generation sessions); a new request is admitted only if its estimated
+// memory fits in the budget, otherwise it waits in a queue or is rejected:
+//
+//     gov := governor.New(governor.Config{
+//         MaxBytes:  4 << 30,
+//         MaxQueued: 32,
+//         MaxWait:   5 * time.Second,
+//         Estimate:  governor.LinearEstimator(64<<20, 1<<20),
+//     }, sessions)
+//     http.ListenAndServe(":8080", gov.Handler(mux))
+package governor
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrOverloaded is returned when the memory budget is exceeded and the
+	// request can't wait in the queue, or it has waited too long.
+	ErrOverloaded = errors.New("governor: memory budget exceeded")
+	// ErrRequestTooLarge is returned when the estimated memory of a request
+	// exceeds the whole budget.
+	ErrRequestTooLarge = errors.New("governor: the request exceeds the memory budget")
+)
+
+// Sizer is implemented by the long-lived structures whose memory counts
+// against the budget, such as generation.SessionCache.
+type Sizer interface {
+	// Size returns the approximate size in bytes.
+	Size() int
+}
+
+// Estimator returns the estimated memory in bytes needed to serve a request
+// of the given size in bytes.
+type Estimator func(requestSize int64) int64
+
+// LinearEstimator returns an Estimator of base bytes plus perByte bytes for
+// each byte of the request, e.g. the memory of the activations of the graph
+// for each character of the text.
+func LinearEstimator(base, perByte int64) Estimator {
+	return func(requestSize int64) int64 {
+		if requestSize < 0 {
+			requestSize = 0
+		}
+		return base + perByte*requestSize
+	}
+}
+
+// Config provides the configuration of a Governor.
+type Config struct {
+	// MaxBytes is the memory budget of the requests in flight, including the
+	// size of the tracked structures.
+	MaxBytes int64
+	// MaxQueued is the maximum number of requests waiting for the budget to
+	// be available. The others are rejected: zero rejects the requests as
+	// soon as the budget is exceeded.
+	MaxQueued int
+	// MaxWait is the maximum time a request waits in the queue. If zero, it
+	// waits until its context is done.
+	MaxWait time.Duration
+	// Estimate returns the estimated memory of a request, used by Handler and
+	// UnaryServerInterceptor. If nil, the size of the request is used.
+	Estimate Estimator
+}
+
+// Governor admits the requests while their estimated memory fits in the
+// budget. It is safe for concurrent use.
+type Governor struct {
+	config   Config
+	mu       sync.Mutex
+	inFlight int64
+	sizers   []Sizer
+	// queue contains the *waiter of the queued requests, in order of arrival.
+	queue *list.List
+}
+
+// waiter is a request waiting in the queue.
+type waiter struct {
+	bytes int64
+	ready chan struct{}
+}
+
+// New returns a new Governor, tracking the size of the given structures.
+func New(config Config, sizers ...Sizer) *Governor {
+	if config.MaxBytes <= 0 {
+		panic("governor: MaxBytes must be greater than zero")
+	}
+	if config.Estimate == nil {
+		config.Estimate = LinearEstimator(0, 1)
+	}
+	return &Governor{
+		config: config,
+		sizers: sizers,
+		queue:  list.New(),
+	}
+}
+
+// Track adds a structure whose size counts against the budget.
+func (g *Governor) Track(s Sizer) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.sizers = append(g.sizers, s)
+}
+
+// Acquire reserves the given bytes for a request, waiting in the queue if
+// the budget is exceeded. It returns a function releasing the reservation,
+// to be called when the request has been served, or ErrOverloaded,
+// ErrRequestTooLarge, or the error of the context.
+//
+// A request is admitted when its memory fits in the budget along with the
+// requests in flight and the tracked structures, or when there are no
+// requests in flight, so that the requests are served even if the tracked
+// structures alone exceed the budget.
+func (g *Governor) Acquire(ctx context.Context, bytes int64) (release func(), err error) {
+	if bytes > g.config.MaxBytes {
+		return nil, ErrRequestTooLarge
+	}
+	g.mu.Lock()
+	if g.queue.Len() == 0 && g.fits(bytes) {
+		g.inFlight += bytes
+		g.mu.Unlock()
+		return g.releaseFunc(bytes), nil
+	}
+	if g.queue.Len() >= g.config.MaxQueued {
+		g.mu.Unlock()
+		return nil, ErrOverloaded
+	}
+	w := &waiter{bytes: bytes, ready: make(chan struct{})}
+	elem := g.queue.PushBack(w)
+	g.mu.Unlock()
+
+	var timeout <-chan time.Time
+	if g.config.MaxWait > 0 {
+		timer := time.NewTimer(g.config.MaxWait)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case <-w.ready:
+		return g.releaseFunc(bytes), nil
+	case <-timeout:
+		err = ErrOverloaded
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	select {
+	case <-w.ready: // admitted in the meantime
+		g.inFlight -= bytes
+		g.admit()
+	default:
+		g.queue.Remove(elem)
+		g.admit() // the next ones may fit now
+	}
+	return nil, err
+}
+
+// fits reports whether the bytes fit in the budget. It must be called with
+// the lock held.
+func (g *Governor) fits(bytes int64) bool {
+	return g.inFlight == 0 || g.inFlight+g.trackedBytes()+bytes <= g.config.MaxBytes
+}
+
+func (g *Governor) trackedBytes() int64 {
+	var size int64
+	for _, s := range g.sizers {
+		size += int64(s.Size())
+	}
+	return size
+}
+
+// admit admits the queued requests which fit in the budget, in order of
+// arrival. It must be called with the lock held.
+func (g *Governor) admit() {
+	for g.queue.Len() > 0 {
+		front := g.queue.Front()
+		w := front.Value.(*waiter)
+		if !g.fits(w.bytes) {
+			return
+		}
+		g.queue.Remove(front)
+		g.inFlight += w.bytes
+		close(w.ready)
+	}
+}
+
+func (g *Governor) releaseFunc(bytes int64) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			g.mu.Lock()
+			defer g.mu.Unlock()
+			g.inFlight -= bytes
+			g.admit()
+		})
+	}
+}
+
+// Stats are the statistics of a Governor.
+type Stats struct {
+	// InFlight is the estimated memory of the requests in flight.
+	InFlight int64
+	// Tracked is the size of the tracked structures.
+	Tracked int64
+	// Queued is the number of requests waiting in the queue.
+	Queued int
+}
+
+// Stats returns the current statistics.
+func (g *Governor) Stats() Stats {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return Stats{
+		InFlight: g.inFlight,
+		Tracked:  g.trackedBytes(),
+		Queued:   g.queue.Len(),
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package governor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type sizer int
+
+func (s *sizer) Size() int {
+	return int(*s)
+}
+
+func TestGovernor_Acquire(t *testing.T) {
+	g := New(Config{MaxBytes: 100})
+
+	release1, err := g.Acquire(context.Background(), 60)
+	require.NoError(t, err)
+	release2, err := g.Acquire(context.Background(), 40)
+	require.NoError(t, err)
+	assert.Equal(t, Stats{InFlight: 100}, g.Stats())
+
+	_, err = g.Acquire(context.Background(), 1)
+	assert.Equal(t, ErrOverloaded, err)
+	_, err = g.Acquire(context.Background(), 101)
+	assert.Equal(t, ErrRequestTooLarge, err)
+
+	release1()
+	release1() // no-op
+	release2()
+	assert.Equal(t, Stats{}, g.Stats())
+}
+
+func TestGovernor_Queue(t *testing.T) {
+	g := New(Config{MaxBytes: 100, MaxQueued: 2})
+	release, err := g.Acquire(context.Background(), 80)
+	require.NoError(t, err)
+
+	admitted := make(chan int64, 2)
+	for i, bytes := range []int64{60, 50} {
+		i, bytes := i, bytes
+		go func() {
+			r, err := g.Acquire(context.Background(), bytes)
+			if assert.NoError(t, err) {
+				admitted <- bytes
+				r()
+			}
+		}()
+		require.Eventually(t, func() bool { return g.Stats().Queued == i+1 }, time.Second, time.Millisecond)
+	}
+	assert.Equal(t, 2, g.Stats().Queued)
+
+	// the queue is full
+	_, err = g.Acquire(context.Background(), 1)
+	assert.Equal(t, ErrOverloaded, err)
+
+	release()
+	// admitted in order of arrival
+	assert.Equal(t, int64(60), <-admitted)
+	assert.Equal(t, int64(50), <-admitted)
+	require.Eventually(t, func() bool { return g.Stats() == Stats{} }, time.Second, time.Millisecond)
+}
+
+func TestGovernor_Wait(t *testing.T) {
+	g := New(Config{MaxBytes: 100, MaxQueued: 1, MaxWait: 10 * time.Millisecond})
+	release, err := g.Acquire(context.Background(), 100)
+	require.NoError(t, err)
+	defer release()
+
+	_, err = g.Acquire(context.Background(), 10)
+	assert.Equal(t, ErrOverloaded, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = g.Acquire(ctx, 10)
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, 0, g.Stats().Queued)
+}
+
+func TestGovernor_Track(t *testing.T) {
+	cache := sizer(70)
+	g := New(Config{MaxBytes: 100})
+	g.Track(&cache)
+
+	// admitted since there are no requests in flight
+	release1, err := g.Acquire(context.Background(), 40)
+	require.NoError(t, err)
+	defer release1()
+	assert.Equal(t, Stats{InFlight: 40, Tracked: 70}, g.Stats())
+
+	_, err = g.Acquire(context.Background(), 10)
+	assert.Equal(t, ErrOverloaded, err)
+
+	cache = 50
+	release2, err := g.Acquire(context.Background(), 10)
+	require.NoError(t, err)
+	release2()
+}
+
+func TestLinearEstimator(t *testing.T) {
+	e := LinearEstimator(100, 2)
+	assert.Equal(t, int64(120), e(10))
+	assert.Equal(t, int64(100), e(-1))
+}
@@ -0,0 +1,56 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package governor
+
+import (
+	"context"
+	"net/http"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// Handler is HTTP middleware admitting the requests through the Governor,
+// with the memory estimated from their content length. The rejected
+// requests get the status 503 Service Unavailable (or 413 Request Entity Too
+// Large if they could never be admitted).
+func (g *Governor) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		release, err := g.Acquire(req.Context(), g.config.Estimate(req.ContentLength))
+		switch err {
+		case nil:
+			defer release()
+			next.ServeHTTP(w, req)
+		case ErrRequestTooLarge:
+			http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		default:
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		}
+	})
+}
+
+// UnaryServerInterceptor returns a gRPC interceptor admitting the requests
+// through the Governor, with the memory estimated from the size of their
+// message. The rejected requests get the code ResourceExhausted.
+func (g *Governor) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		var size int64
+		if m, ok := req.(proto.Message); ok {
+			size = int64(proto.Size(m))
+		}
+		release, err := g.Acquire(ctx, g.config.Estimate(size))
+		if err != nil {
+			if err == ErrOverloaded || err == ErrRequestTooLarge {
+				return nil, status.Error(codes.ResourceExhausted, err.Error())
+			}
+			return nil, status.FromContextError(err).Err()
+		}
+		defer release()
+		return handler(ctx, req)
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package governor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestGovernor_Handler(t *testing.T) {
+	g := New(Config{MaxBytes: 100, Estimate: LinearEstimator(10, 2)})
+	var inFlight int64
+	h := g.Handler(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		inFlight = g.Stats().InFlight
+	}))
+
+	serve := func(body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+		return rec
+	}
+
+	assert.Equal(t, http.StatusOK, serve("abc").Code)
+	assert.Equal(t, int64(16), inFlight)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, serve(strings.Repeat("a", 50)).Code)
+
+	release, err := g.Acquire(context.Background(), 90)
+	require.NoError(t, err)
+	defer release()
+	rec := serve("abc")
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "1", rec.Header().Get("Retry-After"))
+}
+
+func TestGovernor_UnaryServerInterceptor(t *testing.T) {
+	g := New(Config{MaxBytes: 100})
+	interceptor := g.UnaryServerInterceptor()
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return g.Stats().InFlight, nil
+	}
+
+	req := wrapperspb.String("hello")
+	resp, err := interceptor(context.Background(), req, &grpc.UnaryServerInfo{}, handler)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), resp)
+
+	_, err = interceptor(context.Background(), wrapperspb.String(strings.Repeat("a", 200)), &grpc.UnaryServerInfo{}, handler)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
@@ -20,6 +20,9 @@ type GRPCServerConfig struct {
 	TLSKey          string
 	TimeoutSeconds  int
 	MaxRequestBytes int
+	// UnaryInterceptors are chained around the handlers of the unary calls
+	// (e.g. governor.Governor.UnaryServerInterceptor).
+	UnaryInterceptors []grpc.UnaryServerInterceptor
 }
 
 // NewGRPCServer returns grpc.Server objects, optionally configured for TLS.
@@ -35,6 +38,10 @@ func createServerOptions(config GRPCServerConfig) []grpc.ServerOption {
 		grpc.ConnectionTimeout(time.Duration(config.TimeoutSeconds) * time.Second),
 	}
 
+	if len(config.UnaryInterceptors) > 0 {
+		options = append(options, grpc.ChainUnaryInterceptor(config.UnaryInterceptors...))
+	}
+
 	if !config.TLSDisable {
 		creds, err := credentials.NewServerTLSFromFile(config.TLSCert, config.TLSKey)
 		if err != nil {
@@ -0,0 +1,377 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package routing provides a registry of the versions of a served model,
+// routing the requests between them according to a Policy, so that a new
+// checkpoint can be validated in production before replacing the current one.
+//
+// A Policy splits the traffic between the versions by weight (A/B testing),
+// and optionally mirrors the requests to a shadow version, whose responses
+// are compared with the served ones in the background and never returned to
+// the clients:
+//
+//     reg := routing.NewRegistry()
+//     reg.Register("v1", http.HandlerFunc(serverV1.ClassifyHandler))
+//     reg.Register("v2", http.HandlerFunc(serverV2.ClassifyHandler))
+//     reg.SetPolicy(routing.Policy{
+//         Splits: map[string]float64{"v1": 0.9, "v2": 0.1},
+//     })
+//     // or, to validate v2 without serving it:
+//     reg.SetPolicy(routing.Policy{
+//         Splits: map[string]float64{"v1": 1},
+//         Shadow: "v2",
+//     })
+//     http.Handle("/classify", reg)
+package routing
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"math/rand"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// VersionHeader is the response header containing the version which served
+// the request.
+const VersionHeader = "X-Model-Version"
+
+// DefaultIgnoredKeys are the keys ignored by the default comparison of the
+// responses (see Policy): "took" is the execution time in milliseconds
+// included in the responses of the spaGO servers.
+var DefaultIgnoredKeys = []string{"took"}
+
+// Policy provides the routing policy of a Registry.
+type Policy struct {
+	// Splits maps the versions to their weights: each request is served by a
+	// version chosen at random in proportion to the weights, which don't need
+	// to sum up to one.
+	Splits map[string]float64
+	// Shadow is the version the requests are mirrored to, asynchronously. If
+	// empty, the shadow mode is disabled.
+	Shadow string
+	// ShadowRate is the fraction of the requests mirrored to the shadow
+	// version. If zero, all the requests are mirrored.
+	ShadowRate float64
+	// Compare reports whether the response bodies of the served and of the
+	// shadow version agree. If nil, they are compared by JSONEqualIgnoring the
+	// IgnoredKeys.
+	Compare func(served, shadow []byte) bool
+	// IgnoredKeys are the keys of the JSON objects, at any depth, which are
+	// not compared by the default comparison, e.g. the execution times of the
+	// responses. If nil, DefaultIgnoredKeys is used.
+	IgnoredKeys []string
+	// OnDisagreement is called, in the background, with the requests whose
+	// responses disagree. If nil, they are logged with the standard logger.
+	OnDisagreement func(Disagreement)
+}
+
+// Disagreement describes a request whose shadow response differs from the
+// served one.
+type Disagreement struct {
+	Served, Shadow                 string
+	Method, Path                   string
+	Request                        []byte
+	ServedStatus, ShadowStatus     int
+	ServedResponse, ShadowResponse []byte
+	ShadowLatency                  time.Duration
+}
+
+// Stats are the statistics of a version.
+type Stats struct {
+	// Served is the number of requests served by the version.
+	Served int
+	// Shadowed is the number of requests mirrored to the version.
+	Shadowed int
+	// Disagreements is the number of mirrored requests whose response
+	// disagreed with the served one.
+	Disagreements int
+}
+
+// Registry is an http.Handler routing the requests between the registered
+// versions of a model. It is safe for concurrent use.
+type Registry struct {
+	mu       sync.RWMutex
+	versions map[string]http.Handler
+	policy   Policy
+	// names and cumulative are the versions of the splits with their
+	// cumulative normalized weights.
+	names      []string
+	cumulative []float64
+	stats      map[string]*Stats
+	rnd        *rand.Rand
+	rndMu      sync.Mutex
+	shadowWG   sync.WaitGroup
+}
+
+// NewRegistry returns a new empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		versions: make(map[string]http.Handler),
+		stats:    make(map[string]*Stats),
+		rnd:      rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// Register adds a version, or replaces the handler of an existing one. The
+// requests are routed to the version only once it appears in the Policy.
+func (r *Registry) Register(version string, h http.Handler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.versions[version] = h
+	if _, ok := r.stats[version]; !ok {
+		r.stats[version] = &Stats{}
+	}
+}
+
+// Unregister removes a version. It returns an error if the version is used
+// by the current Policy.
+func (r *Registry) Unregister(version string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.policy.Splits[version]; ok || r.policy.Shadow == version {
+		return fmt.Errorf("routing: version %q is in use by the policy", version)
+	}
+	delete(r.versions, version)
+	delete(r.stats, version)
+	return nil
+}
+
+// Versions returns the registered versions, sorted by name.
+func (r *Registry) Versions() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	versions := make([]string, 0, len(r.versions))
+	for v := range r.versions {
+		versions = append(versions, v)
+	}
+	sort.Strings(versions)
+	return versions
+}
+
+// SetPolicy replaces the routing policy. It returns an error if the policy
+// refers to versions that are not registered, or has no positive weight.
+func (r *Registry) SetPolicy(p Policy) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	names := make([]string, 0, len(p.Splits))
+	var total float64
+	for v, w := range p.Splits {
+		if _, ok := r.versions[v]; !ok {
+			return fmt.Errorf("routing: unknown version %q", v)
+		}
+		if w < 0 {
+			return fmt.Errorf("routing: negative weight for version %q", v)
+		}
+		if w > 0 {
+			names = append(names, v)
+			total += w
+		}
+	}
+	if total == 0 {
+		return fmt.Errorf("routing: the policy has no positive weight")
+	}
+	if _, ok := r.versions[p.Shadow]; p.Shadow != "" && !ok {
+		return fmt.Errorf("routing: unknown shadow version %q", p.Shadow)
+	}
+	if p.ShadowRate < 0 || p.ShadowRate > 1 {
+		return fmt.Errorf("routing: invalid shadow rate %g", p.ShadowRate)
+	}
+	sort.Strings(names) // deterministic routing for a given random source
+	cumulative := make([]float64, len(names))
+	var acc float64
+	for i, v := range names {
+		acc += p.Splits[v] / total
+		cumulative[i] = acc
+	}
+	cumulative[len(cumulative)-1] = 1
+	r.policy, r.names, r.cumulative = p, names, cumulative
+	return nil
+}
+
+// Policy returns the current routing policy.
+func (r *Registry) Policy() Policy {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.policy
+}
+
+// Stats returns the statistics of the registered versions.
+func (r *Registry) Stats() map[string]Stats {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	stats := make(map[string]Stats, len(r.stats))
+	for v, s := range r.stats {
+		stats[v] = *s
+	}
+	return stats
+}
+
+// Wait waits for the completion of the shadow requests in progress.
+func (r *Registry) Wait() {
+	r.shadowWG.Wait()
+}
+
+func (r *Registry) random() float64 {
+	r.rndMu.Lock()
+	defer r.rndMu.Unlock()
+	return r.rnd.Float64()
+}
+
+// ServeHTTP serves the request with a version chosen according to the
+// policy, mirroring it to the shadow version if enabled.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	if len(r.names) == 0 {
+		r.mu.Unlock()
+		http.Error(w, "routing: no policy", http.StatusServiceUnavailable)
+		return
+	}
+	x := r.random()
+	i := sort.SearchFloat64s(r.cumulative, x)
+	if i == len(r.names) {
+		i--
+	}
+	served := r.names[i]
+	handler := r.versions[served]
+	r.stats[served].Served++
+	policy := r.policy
+	var shadow http.Handler
+	if policy.Shadow != "" && policy.Shadow != served &&
+		(policy.ShadowRate == 0 || r.random() < policy.ShadowRate) {
+		shadow = r.versions[policy.Shadow]
+		r.stats[policy.Shadow].Shadowed++
+	}
+	r.mu.Unlock()
+
+	w.Header().Set(VersionHeader, served)
+	if shadow == nil {
+		handler.ServeHTTP(w, req)
+		return
+	}
+
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	tee := &teeWriter{ResponseWriter: w, status: http.StatusOK}
+	handler.ServeHTTP(tee, req)
+
+	// The shadow request must not depend on the context of the served one,
+	// which is canceled when ServeHTTP returns.
+	shadowReq := req.Clone(contextWithoutCancel{req.Context()})
+	shadowReq.Body = ioutil.NopCloser(bytes.NewReader(body))
+	r.shadowWG.Add(1)
+	go func() {
+		defer r.shadowWG.Done()
+		r.runShadow(policy, served, shadow, shadowReq, body, tee)
+	}()
+}
+
+func (r *Registry) runShadow(p Policy, served string, h http.Handler, req *http.Request, body []byte, tee *teeWriter) {
+	rec := &recorder{header: make(http.Header), status: http.StatusOK}
+	start := time.Now()
+	func() {
+		defer func() {
+			if err := recover(); err != nil {
+				rec.status = http.StatusInternalServerError
+				rec.body.Reset()
+				fmt.Fprint(&rec.body, err)
+			}
+		}()
+		h.ServeHTTP(rec, req)
+	}()
+	latency := time.Since(start)
+
+	compare := p.Compare
+	if compare == nil {
+		ignoredKeys := p.IgnoredKeys
+		if ignoredKeys == nil {
+			ignoredKeys = DefaultIgnoredKeys
+		}
+		compare = JSONEqualIgnoring(ignoredKeys...)
+	}
+	if tee.status == rec.status && compare(tee.body.Bytes(), rec.body.Bytes()) {
+		return
+	}
+	r.mu.Lock()
+	if s, ok := r.stats[p.Shadow]; ok {
+		s.Disagreements++
+	}
+	r.mu.Unlock()
+
+	d := Disagreement{
+		Served:         served,
+		Shadow:         p.Shadow,
+		Method:         req.Method,
+		Path:           req.URL.Path,
+		Request:        body,
+		ServedStatus:   tee.status,
+		ShadowStatus:   rec.status,
+		ServedResponse: tee.body.Bytes(),
+		ShadowResponse: rec.body.Bytes(),
+		ShadowLatency:  latency,
+	}
+	if p.OnDisagreement != nil {
+		p.OnDisagreement(d)
+		return
+	}
+	log.Printf("routing: %s %s: version %q (%d) disagrees with %q (%d)\n",
+		d.Method, d.Path, d.Shadow, d.ShadowStatus, d.Served, d.ServedStatus)
+}
+
+// JSONEqual reports whether a and b are equal JSON values, regardless of
+// the formatting and of the order of the keys of the objects. If they are not
+// valid JSON, they are compared byte by byte.
+func JSONEqual(a, b []byte) bool {
+	return JSONEqualIgnoring()(a, b)
+}
+
+// JSONEqualIgnoring returns a comparison like JSONEqual, which ignores the
+// given keys of the JSON objects, at any depth.
+func JSONEqualIgnoring(keys ...string) func(a, b []byte) bool {
+	ignored := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		ignored[key] = true
+	}
+	return func(a, b []byte) bool {
+		var x, y interface{}
+		if json.Unmarshal(a, &x) != nil || json.Unmarshal(b, &y) != nil {
+			return bytes.Equal(a, b)
+		}
+		xb, _ := json.Marshal(withoutKeys(x, ignored))
+		yb, _ := json.Marshal(withoutKeys(y, ignored))
+		return bytes.Equal(xb, yb)
+	}
+}
+
+// withoutKeys removes the ignored keys from the objects of the decoded JSON
+// value, recursively.
+func withoutKeys(v interface{}, ignored map[string]bool) interface{} {
+	if len(ignored) == 0 {
+		return v
+	}
+	switch vt := v.(type) {
+	case map[string]interface{}:
+		for key, value := range vt {
+			if ignored[key] {
+				delete(vt, key)
+				continue
+			}
+			vt[key] = withoutKeys(value, ignored)
+		}
+	case []interface{}:
+		for i, value := range vt {
+			vt[i] = withoutKeys(value, ignored)
+		}
+	}
+	return v
+}
@@ -0,0 +1,209 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package routing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/nlpodyssey/spago/pkg/nlp/transformers/bert"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func echoHandler(prefix string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := ioutil.ReadAll(req.Body)
+		fmt.Fprintf(w, `{"version": %q, "text": %q}`, prefix, body)
+	})
+}
+
+func serve(r *Registry, body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/classify", strings.NewReader(body)))
+	return rec
+}
+
+func TestRegistry_SetPolicy(t *testing.T) {
+	r := NewRegistry()
+	r.Register("v1", echoHandler("v1"))
+	r.Register("v2", echoHandler("v2"))
+	assert.Equal(t, []string{"v1", "v2"}, r.Versions())
+
+	assert.Error(t, r.SetPolicy(Policy{Splits: map[string]float64{"v3": 1}}))
+	assert.Error(t, r.SetPolicy(Policy{Splits: map[string]float64{"v1": 0}}))
+	assert.Error(t, r.SetPolicy(Policy{Splits: map[string]float64{"v1": -1, "v2": 2}}))
+	assert.Error(t, r.SetPolicy(Policy{Splits: map[string]float64{"v1": 1}, Shadow: "v3"}))
+	assert.Error(t, r.SetPolicy(Policy{Splits: map[string]float64{"v1": 1}, ShadowRate: 2}))
+
+	assert.Equal(t, http.StatusServiceUnavailable, serve(r, "x").Code)
+
+	require.NoError(t, r.SetPolicy(Policy{Splits: map[string]float64{"v1": 1}, Shadow: "v2"}))
+	assert.Error(t, r.Unregister("v1"))
+	assert.Error(t, r.Unregister("v2"))
+}
+
+func TestRegistry_Splits(t *testing.T) {
+	r := NewRegistry()
+	r.rnd = rand.New(rand.NewSource(42))
+	r.Register("v1", echoHandler("v1"))
+	r.Register("v2", echoHandler("v2"))
+	require.NoError(t, r.SetPolicy(Policy{Splits: map[string]float64{"v1": 3, "v2": 1}}))
+
+	counts := make(map[string]int)
+	for i := 0; i < 1000; i++ {
+		rec := serve(r, "x")
+		require.Equal(t, http.StatusOK, rec.Code)
+		version := rec.Header().Get(VersionHeader)
+		assert.Contains(t, rec.Body.String(), fmt.Sprintf(`"version": %q`, version))
+		counts[version]++
+	}
+	assert.InDelta(t, 750, counts["v1"], 50)
+	assert.InDelta(t, 250, counts["v2"], 50)
+
+	stats := r.Stats()
+	assert.Equal(t, counts["v1"], stats["v1"].Served)
+	assert.Equal(t, counts["v2"], stats["v2"].Served)
+}
+
+func TestRegistry_Shadow(t *testing.T) {
+	r := NewRegistry()
+	var mu sync.Mutex
+	var shadowCtxErr error
+	r.Register("v1", echoHandler("v1"))
+	r.Register("v2", http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := ioutil.ReadAll(req.Body)
+		mu.Lock()
+		shadowCtxErr = req.Context().Err()
+		mu.Unlock()
+		if string(body) == "disagree" {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+		fmt.Fprintf(w, `{"text":%q, "version":"v1"}`, body)
+	}))
+
+	var disagreements []Disagreement
+	require.NoError(t, r.SetPolicy(Policy{
+		Splits: map[string]float64{"v1": 1},
+		Shadow: "v2",
+		OnDisagreement: func(d Disagreement) {
+			mu.Lock()
+			defer mu.Unlock()
+			disagreements = append(disagreements, d)
+		},
+	}))
+
+	rec := serve(r, "agree")
+	assert.Equal(t, "v1", rec.Header().Get(VersionHeader))
+	assert.JSONEq(t, `{"version":"v1","text":"agree"}`, rec.Body.String())
+	rec = serve(r, "disagree")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	r.Wait()
+
+	assert.NoError(t, shadowCtxErr)
+	require.Len(t, disagreements, 1)
+	d := disagreements[0]
+	assert.Equal(t, "v1", d.Served)
+	assert.Equal(t, "v2", d.Shadow)
+	assert.Equal(t, "/classify", d.Path)
+	assert.Equal(t, "disagree", string(d.Request))
+	assert.Equal(t, http.StatusOK, d.ServedStatus)
+	assert.Equal(t, http.StatusBadRequest, d.ShadowStatus)
+
+	stats := r.Stats()
+	assert.Equal(t, Stats{Served: 2}, stats["v1"])
+	assert.Equal(t, Stats{Shadowed: 2, Disagreements: 1}, stats["v2"])
+}
+
+func TestRegistry_ShadowPanic(t *testing.T) {
+	r := NewRegistry()
+	r.Register("v1", echoHandler("v1"))
+	r.Register("v2", http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic("boom")
+	}))
+	var got Disagreement
+	require.NoError(t, r.SetPolicy(Policy{
+		Splits:         map[string]float64{"v1": 1},
+		Shadow:         "v2",
+		OnDisagreement: func(d Disagreement) { got = d },
+	}))
+	assert.Equal(t, http.StatusOK, serve(r, "x").Code)
+	r.Wait()
+	assert.Equal(t, http.StatusInternalServerError, got.ShadowStatus)
+	assert.Equal(t, "boom", string(got.ShadowResponse))
+}
+
+func TestJSONEqual(t *testing.T) {
+	assert.True(t, JSONEqual([]byte(`{"a": 1, "b": [2]}`), []byte(`{"b":[2],"a":1}`)))
+	assert.False(t, JSONEqual([]byte(`{"a": 1}`), []byte(`{"a": 2}`)))
+	assert.True(t, JSONEqual([]byte("plain"), []byte("plain")))
+	assert.False(t, JSONEqual([]byte("plain"), []byte(`"plain"`)))
+}
+
+func TestJSONEqualIgnoring(t *testing.T) {
+	response := func(class string, took int64) []byte {
+		data, err := json.Marshal(bert.ClassifyResponse{
+			Class:        class,
+			Confidence:   0.9,
+			Distribution: []bert.ClassConfidencePair{{Class: class, Confidence: 0.9}},
+			Took:         took,
+		})
+		require.NoError(t, err)
+		return data
+	}
+	equal := JSONEqualIgnoring(DefaultIgnoredKeys...)
+	assert.True(t, equal(response("positive", 12), response("positive", 31)))
+	assert.False(t, equal(response("positive", 12), response("negative", 12)))
+	assert.False(t, JSONEqual(response("positive", 12), response("positive", 31)))
+	assert.True(t, equal([]byte(`[{"a": 1, "took": 2}]`), []byte(`[{"took": 3, "a": 1}]`)))
+}
+
+func TestRegistry_ShadowIgnoresTimings(t *testing.T) {
+	r := NewRegistry()
+	handler := func(took int) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			fmt.Fprintf(w, `{"class": "positive", "took": %d}`, took)
+		})
+	}
+	r.Register("v1", handler(12))
+	r.Register("v2", handler(31))
+	var disagreements int
+	require.NoError(t, r.SetPolicy(Policy{
+		Splits:         map[string]float64{"v1": 1},
+		Shadow:         "v2",
+		OnDisagreement: func(Disagreement) { disagreements++ },
+	}))
+	serve(r, "text")
+	r.Wait()
+	assert.Equal(t, 0, disagreements)
+
+	require.NoError(t, r.SetPolicy(Policy{
+		Splits:         map[string]float64{"v1": 1},
+		Shadow:         "v2",
+		IgnoredKeys:    []string{},
+		OnDisagreement: func(Disagreement) { disagreements++ },
+	}))
+	serve(r, "text")
+	r.Wait()
+	assert.Equal(t, 1, disagreements)
+}
+
+func TestContextWithoutCancel(t *testing.T) {
+	type key struct{}
+	parent, cancel := context.WithCancel(context.WithValue(context.Background(), key{}, 1))
+	ctx := contextWithoutCancel{parent}
+	cancel()
+	assert.NoError(t, ctx.Err())
+	assert.Nil(t, ctx.Done())
+	assert.Equal(t, 1, ctx.Value(key{}))
+}
@@ -0,0 +1,72 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package routing
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"time"
+)
+
+// teeWriter is an http.ResponseWriter copying the response of the served
+// version, to compare it with the shadow one.
+type teeWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (w *teeWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status, w.wroteHeader = status, true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *teeWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+// recorder is an http.ResponseWriter recording the response of the shadow
+// version.
+type recorder struct {
+	header      http.Header
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (r *recorder) Header() http.Header {
+	return r.header
+}
+
+func (r *recorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status, r.wroteHeader = status, true
+	}
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	return r.body.Write(b)
+}
+
+// contextWithoutCancel keeps the values of the parent context, but is never
+// canceled.
+type contextWithoutCancel struct {
+	parent context.Context
+}
+
+func (contextWithoutCancel) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (contextWithoutCancel) Done() <-chan struct{}       { return nil }
+func (contextWithoutCancel) Err() error                  { return nil }
+
+func (c contextWithoutCancel) Value(key interface{}) interface{} {
+	return c.parent.Value(key)
+}
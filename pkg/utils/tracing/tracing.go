@@ -0,0 +1,208 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package tracing records the spans of the stages of the pipelines (e.g.
+// tokenization, encoding and post-processing) and, optionally, of the forward
+// of the layers of the models, so that the latency of the requests served in
+// production can be attributed to specific stages.
+//
+// The spans are created by the Tracer set with SetTracer, which by default
+// discards them. The interfaces are shaped after the ones of OpenTelemetry,
+// so that an adapter is a few lines of code:
+//
+//     type otelTracer struct{ trace.Tracer }
+//
+//     func (t otelTracer) Start(ctx context.Context, name string) (context.Context, tracing.Span) {
+//         ctx, span := t.Tracer.Start(ctx, name)
+//         return ctx, otelSpan{span}
+//     }
+//
+//     type otelSpan struct{ trace.Span }
+//
+//     func (s otelSpan) End()                  { s.Span.End() }
+//     func (s otelSpan) RecordError(err error) { s.Span.RecordError(err) }
+//     func (s otelSpan) SetAttribute(key string, value interface{}) {
+//         s.Span.SetAttributes(attribute.Any(key, value))
+//     }
+//
+//     tracing.SetTracer(otelTracer{otel.Tracer("spago")})
+package tracing
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Tracer creates the spans.
+type Tracer interface {
+	// Start creates a span with the given name, child of the span contained
+	// in the context, if any. The returned context contains the new span.
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is a timed operation of a trace.
+type Span interface {
+	// End completes the span.
+	End()
+	// SetAttribute sets an attribute of the span (e.g. the number of tokens).
+	SetAttribute(key string, value interface{})
+	// RecordError records an error as an event of the span.
+	RecordError(err error)
+}
+
+var (
+	mu     sync.RWMutex
+	tracer Tracer = noopTracer{}
+)
+
+// SetTracer sets the Tracer used by the package functions. A nil Tracer
+// disables the tracing (the default).
+func SetTracer(t Tracer) {
+	if t == nil {
+		t = noopTracer{}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	tracer = t
+}
+
+// GetTracer returns the Tracer used by the package functions.
+func GetTracer() Tracer {
+	mu.RLock()
+	defer mu.RUnlock()
+	return tracer
+}
+
+// Enabled reports whether a Tracer has been set, so that the callers can
+// avoid to prepare the names and the attributes of the spans otherwise.
+func Enabled() bool {
+	_, noop := GetTracer().(noopTracer)
+	return !noop
+}
+
+// Start creates a span with the Tracer set with SetTracer.
+func Start(ctx context.Context, name string) (context.Context, Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return GetTracer().Start(ctx, name)
+}
+
+// EndWithError records the error, if not nil, and ends the span. It is
+// convenient to end the span of a function with a named error result:
+//
+//     ctx, span := tracing.Start(ctx, "stage")
+//     defer func() { tracing.EndWithError(span, err) }()
+func EndWithError(span Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+	}
+	span.End()
+}
+
+// NoopSpan returns a Span which does nothing.
+func NoopSpan() Span {
+	return noopSpan{}
+}
+
+type noopTracer struct{}
+
+func (noopTracer) Start(ctx context.Context, _ string) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) End()                                 {}
+func (noopSpan) SetAttribute(_ string, _ interface{}) {}
+func (noopSpan) RecordError(_ error)                  {}
+
+// Recorder is a Tracer which keeps the ended spans in memory, to verify the
+// instrumentation in the tests or to inspect the latency of a few requests
+// without a tracing backend.
+type Recorder struct {
+	mu    sync.Mutex
+	spans []*RecordedSpan
+}
+
+// RecordedSpan is a span recorded by a Recorder.
+type RecordedSpan struct {
+	// Name is the name of the span.
+	Name string
+	// Parent is the parent span, or nil for a root span.
+	Parent *RecordedSpan
+	// Attributes are the attributes of the span.
+	Attributes map[string]interface{}
+	// Errors are the errors recorded by the span.
+	Errors []error
+	// Start and EndTime are the times when the span started and ended.
+	Start, EndTime time.Time
+	recorder       *Recorder
+}
+
+// NewRecorder returns a new Recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{spans: make([]*RecordedSpan, 0)}
+}
+
+type recordedSpanKey struct{}
+
+// Start creates a span, child of the recorded span contained in the context,
+// if any.
+func (r *Recorder) Start(ctx context.Context, name string) (context.Context, Span) {
+	parent, _ := ctx.Value(recordedSpanKey{}).(*RecordedSpan)
+	s := &RecordedSpan{
+		Name:       name,
+		Parent:     parent,
+		Attributes: make(map[string]interface{}),
+		Start:      time.Now(),
+		recorder:   r,
+	}
+	return context.WithValue(ctx, recordedSpanKey{}, s), s
+}
+
+// Spans returns the ended spans, in order of ending.
+func (r *Recorder) Spans() []*RecordedSpan {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*RecordedSpan(nil), r.spans...)
+}
+
+// Names returns the names of the ended spans, in order of ending.
+func (r *Recorder) Names() []string {
+	spans := r.Spans()
+	names := make([]string, len(spans))
+	for i, s := range spans {
+		names[i] = s.Name
+	}
+	return names
+}
+
+// Duration returns the time elapsed from the start to the end of the span.
+func (s *RecordedSpan) Duration() time.Duration {
+	return s.EndTime.Sub(s.Start)
+}
+
+// End completes the span, adding it to the spans of the Recorder.
+func (s *RecordedSpan) End() {
+	s.recorder.mu.Lock()
+	defer s.recorder.mu.Unlock()
+	s.EndTime = time.Now()
+	s.recorder.spans = append(s.recorder.spans, s)
+}
+
+// SetAttribute sets an attribute of the span.
+func (s *RecordedSpan) SetAttribute(key string, value interface{}) {
+	s.recorder.mu.Lock()
+	defer s.recorder.mu.Unlock()
+	s.Attributes[key] = value
+}
+
+// RecordError records an error of the span.
+func (s *RecordedSpan) RecordError(err error) {
+	s.recorder.mu.Lock()
+	defer s.recorder.mu.Unlock()
+	s.Errors = append(s.Errors, err)
+}
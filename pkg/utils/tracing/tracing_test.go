@@ -0,0 +1,57 @@
+// Copyright 2021 spaGO Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStart(t *testing.T) {
+	assert.False(t, Enabled())
+	ctx, span := Start(context.Background(), "noop")
+	assert.Equal(t, context.Background(), ctx)
+	assert.Equal(t, NoopSpan(), span)
+
+	recorder := NewRecorder()
+	SetTracer(recorder)
+	defer SetTracer(nil)
+	assert.True(t, Enabled())
+	assert.Equal(t, recorder, GetTracer())
+
+	ctx, root := Start(nil, "root")
+	_, child := Start(ctx, "child")
+	child.SetAttribute("tokens", 3)
+	child.End()
+	root.End()
+
+	spans := recorder.Spans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, []string{"child", "root"}, recorder.Names())
+	assert.Equal(t, spans[1], spans[0].Parent)
+	assert.Nil(t, spans[1].Parent)
+	assert.Equal(t, map[string]interface{}{"tokens": 3}, spans[0].Attributes)
+	assert.True(t, spans[1].Duration() >= spans[0].Duration())
+
+	SetTracer(nil)
+	assert.False(t, Enabled())
+}
+
+func TestEndWithError(t *testing.T) {
+	recorder := NewRecorder()
+	_, span := recorder.Start(context.Background(), "ok")
+	EndWithError(span, nil)
+	_, span = recorder.Start(context.Background(), "failed")
+	EndWithError(span, errors.New("boom"))
+
+	spans := recorder.Spans()
+	require.Len(t, spans, 2)
+	assert.Empty(t, spans[0].Errors)
+	assert.EqualError(t, spans[1].Errors[0], "boom")
+}